@@ -3,8 +3,11 @@ package main
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
+	"io"
 	"log"
 	"os"
 	"strings"
@@ -33,12 +36,15 @@ type BootstrapConfig struct {
 	AdminEmail    string
 	AdminPassword string
 	AdminName     string
+	AdminRole     domain.UserRole
+	Force         bool // Reset the role and password of an existing user with the same email
 	OrgName       string
 	OrgDomain     string
 	MaxUsers      int
 	MaxAgents     int
 	DatabaseURL   string
 	SkipPrompts   bool
+	Output        string // text, or json to print a bootstrapResult to stdout and progress to stderr
 }
 
 func main() {
@@ -50,82 +56,105 @@ func main() {
 	flag.StringVar(&config.AdminEmail, "admin-email", "", "Admin user email address")
 	flag.StringVar(&config.AdminPassword, "admin-password", "", "Admin user password")
 	flag.StringVar(&config.AdminName, "admin-name", "System Administrator", "Admin user display name")
+	role := flag.String("role", string(domain.RoleAdmin), "Role of the user (admin, manager, member, viewer)")
+	flag.BoolVar(&config.Force, "force", false, "Reset the role and password of an existing user with the same email")
 	flag.StringVar(&config.OrgName, "org-name", "", "Organization name")
 	flag.StringVar(&config.OrgDomain, "org-domain", "localhost", "Organization domain")
 	flag.IntVar(&config.MaxUsers, "max-users", 100, "Maximum users allowed")
 	flag.IntVar(&config.MaxAgents, "max-agents", 1000, "Maximum agents allowed")
 	flag.StringVar(&config.DatabaseURL, "database-url", os.Getenv("DATABASE_URL"), "PostgreSQL connection URL")
 	flag.BoolVar(&config.SkipPrompts, "yes", false, "Skip confirmation prompts")
+	flag.StringVar(&config.Output, "output", "text", "Output format (text, json); json prints the result to stdout and progress to stderr")
 	flag.Parse()
+	config.AdminRole = domain.UserRole(*role)
+
+	// Human-readable progress goes to stderr when stdout carries the JSON result
+	out := io.Writer(os.Stdout)
+	if config.Output == "json" {
+		out = os.Stderr
+	}
 
 	// Print banner
-	fmt.Print(banner)
+	fmt.Fprint(out, banner)
 
 	// Validate configuration
 	if err := validateConfig(config); err != nil {
-		log.Fatalf("❌ Configuration error: %v", err)
+		exitWithError(config, "Configuration error: %v", err)
 	}
 
 	// Connect to database
-	fmt.Println("📊 Connecting to database...")
+	fmt.Fprintln(out, "📊 Connecting to database...")
 	db, err := sql.Open("postgres", config.DatabaseURL)
 	if err != nil {
-		log.Fatalf("❌ Failed to connect to database: %v", err)
+		exitWithError(config, "Failed to connect to database: %v", err)
 	}
 	defer db.Close()
 
 	// Test connection
 	if err := db.Ping(); err != nil {
-		log.Fatalf("❌ Failed to ping database: %v", err)
+		exitWithError(config, "Failed to ping database: %v", err)
 	}
 
 	// Check if bootstrap already completed
-	if isBootstrapped(db) {
-		fmt.Println("⚠️  System already bootstrapped!")
+	alreadyBootstrapped := isBootstrapped(db)
+	if alreadyBootstrapped {
+		fmt.Fprintln(out, "⚠️  System already bootstrapped!")
 		if !config.SkipPrompts {
-			fmt.Print("Do you want to create another admin user? (yes/no): ")
+			fmt.Fprint(out, "Do you want to add another user? (yes/no): ")
 			var response string
 			fmt.Scanln(&response)
 			if strings.ToLower(response) != "yes" && strings.ToLower(response) != "y" {
-				fmt.Println("❌ Bootstrap cancelled")
-				return
+				exitCancelled(config, out)
 			}
 		}
 	}
 
 	// Show configuration summary
-	fmt.Println("\n📋 Bootstrap Configuration:")
-	fmt.Printf("   • Admin Email:    %s\n", config.AdminEmail)
-	fmt.Printf("   • Admin Name:     %s\n", config.AdminName)
-	fmt.Printf("   • Organization:   %s\n", config.OrgName)
-	fmt.Printf("   • Domain:         %s\n", config.OrgDomain)
-	fmt.Printf("   • Max Users:      %d\n", config.MaxUsers)
-	fmt.Printf("   • Max Agents:     %d\n", config.MaxAgents)
+	fmt.Fprintln(out, "\n📋 Bootstrap Configuration:")
+	fmt.Fprintf(out, "   • Admin Email:    %s\n", config.AdminEmail)
+	fmt.Fprintf(out, "   • Admin Name:     %s\n", config.AdminName)
+	fmt.Fprintf(out, "   • Role:           %s\n", config.AdminRole)
+	fmt.Fprintf(out, "   • Organization:   %s\n", config.OrgName)
+	fmt.Fprintf(out, "   • Domain:         %s\n", config.OrgDomain)
+	fmt.Fprintf(out, "   • Max Users:      %d\n", config.MaxUsers)
+	fmt.Fprintf(out, "   • Max Agents:     %d\n", config.MaxAgents)
 
 	// Confirm
 	if !config.SkipPrompts {
-		fmt.Print("\n⚠️  This will create the initial admin user and organization. Continue? (yes/no): ")
+		fmt.Fprint(out, "\n⚠️  This will create the user and, if missing, the organization. Continue? (yes/no): ")
 		var response string
 		fmt.Scanln(&response)
 		if strings.ToLower(response) != "yes" && strings.ToLower(response) != "y" {
-			fmt.Println("❌ Bootstrap cancelled")
-			return
+			exitCancelled(config, out)
 		}
 	}
 
 	// Run bootstrap
-	fmt.Println("\n🚀 Starting bootstrap process...")
+	fmt.Fprintln(out, "\n🚀 Starting bootstrap process...")
 
-	if err := runBootstrap(context.Background(), db, config); err != nil {
-		log.Fatalf("❌ Bootstrap failed: %v", err)
+	result, err := runBootstrap(context.Background(), db, config, out)
+	if err != nil {
+		exitWithError(config, "Bootstrap failed: %v", err)
 	}
+	result.AlreadyBootstrapped = alreadyBootstrapped
+	result.Status = bootstrapStatusCompleted
 
-	fmt.Println("\n✅ Bootstrap completed successfully!")
-	fmt.Printf("\n🔐 Admin Credentials:\n")
-	fmt.Printf("   Email:    %s\n", config.AdminEmail)
-	fmt.Printf("   Password: %s\n", config.AdminPassword)
-	fmt.Printf("\n🌐 You can now log in at: http://localhost:3000/login\n")
-	fmt.Println("\n⚠️  IMPORTANT: Please change the admin password after first login!")
+	fmt.Fprintln(out, "\n✅ Bootstrap completed successfully!")
+	if config.Output == "json" {
+		if err := printJSONResult(os.Stdout, result); err != nil {
+			exitWithError(config, "Failed to write result: %v", err)
+		}
+		return
+	}
+	if result.UserStatus == userUnchanged {
+		fmt.Fprintf(out, "\nℹ️  %s already exists and was left unchanged (use --force to reset its role and password)\n", config.AdminEmail)
+		return
+	}
+	fmt.Fprintf(out, "\n🔐 Credentials:\n")
+	fmt.Fprintf(out, "   Email:    %s\n", config.AdminEmail)
+	fmt.Fprintf(out, "   Password: %s\n", config.AdminPassword)
+	fmt.Fprintf(out, "\n🌐 You can now log in at: http://localhost:3000/login\n")
+	fmt.Fprintln(out, "\n⚠️  IMPORTANT: Please change the password after first login!")
 }
 
 func validateConfig(config *BootstrapConfig) error {
@@ -141,6 +170,16 @@ func validateConfig(config *BootstrapConfig) error {
 		return fmt.Errorf("organization name is required (use --org-name)")
 	}
 
+	switch config.AdminRole {
+	case domain.RoleAdmin, domain.RoleManager, domain.RoleMember, domain.RoleViewer:
+	default:
+		return fmt.Errorf("invalid role %q (use admin, manager, member or viewer)", config.AdminRole)
+	}
+
+	if config.Output != "text" && config.Output != "json" {
+		return fmt.Errorf("invalid output %q (use text or json)", config.Output)
+	}
+
 	if config.DatabaseURL == "" {
 		return fmt.Errorf("database URL is required (use --database-url or set DATABASE_URL env var)")
 	}
@@ -164,23 +203,92 @@ func isBootstrapped(db *sql.DB) bool {
 	return value == "true"
 }
 
-func runBootstrap(ctx context.Context, db *sql.DB, config *BootstrapConfig) error {
+// userStatus is what bootstrap did with the requested user
+type userStatus string
+
+const (
+	userCreated   userStatus = "created"
+	userUpdated   userStatus = "updated"   // An existing user was reset because of --force
+	userUnchanged userStatus = "unchanged" // An existing user was left alone
+)
+
+// bootstrapStatus is how a bootstrap run ended
+type bootstrapStatus string
+
+const (
+	bootstrapStatusCompleted bootstrapStatus = "completed"
+	bootstrapStatusCancelled bootstrapStatus = "cancelled" // The operator declined a confirmation prompt
+	bootstrapStatusError     bootstrapStatus = "error"
+)
+
+// bootstrapResult is what a bootstrap run did; --output json prints it to stdout
+type bootstrapResult struct {
+	Status              bootstrapStatus `json:"status"`
+	OrganizationID      uuid.UUID       `json:"organizationId"`
+	OrganizationCreated bool            `json:"organizationCreated"`
+	UserID              uuid.UUID       `json:"userId"`
+	UserEmail           string          `json:"userEmail"`
+	UserRole            domain.UserRole `json:"userRole"` // The user's role after the run
+	UserStatus          userStatus      `json:"userStatus"`
+	AlreadyBootstrapped bool            `json:"alreadyBootstrapped"` // Whether an earlier run had completed
+	BootstrapCompleted  bool            `json:"bootstrapCompleted"`
+}
+
+// printJSONResult writes result to w as a single line of JSON
+func printJSONResult(w io.Writer, result *bootstrapResult) error {
+	return json.NewEncoder(w).Encode(result)
+}
+
+// bootstrapFailure is what --output json prints to stdout when a run does not complete
+type bootstrapFailure struct {
+	Status bootstrapStatus `json:"status"`
+	Error  string          `json:"error,omitempty"`
+}
+
+// printJSONFailure writes a cancelled or error status to w as a single line of JSON
+func printJSONFailure(w io.Writer, status bootstrapStatus, message string) error {
+	return json.NewEncoder(w).Encode(bootstrapFailure{Status: status, Error: message})
+}
+
+// exitWithError logs the error and exits with status 1. With --output json the error is
+// also printed to stdout so scripts reading the result always get one.
+func exitWithError(config *BootstrapConfig, format string, args ...interface{}) {
+	message := fmt.Sprintf(format, args...)
+	if config.Output == "json" {
+		_ = printJSONFailure(os.Stdout, bootstrapStatusError, message)
+	}
+	log.Fatalf("❌ %s", message)
+}
+
+// exitCancelled reports that the operator declined a prompt and exits with status 1
+func exitCancelled(config *BootstrapConfig, out io.Writer) {
+	fmt.Fprintln(out, "❌ Bootstrap cancelled")
+	if config.Output == "json" {
+		_ = printJSONFailure(os.Stdout, bootstrapStatusCancelled, "")
+	}
+	os.Exit(1)
+}
+
+// runBootstrap creates the organization if missing and the user, writing progress to out
+func runBootstrap(ctx context.Context, db *sql.DB, config *BootstrapConfig, out io.Writer) (*bootstrapResult, error) {
 	// Start transaction
 	tx, err := db.Begin()
 	if err != nil {
-		return fmt.Errorf("failed to start transaction: %w", err)
+		return nil, fmt.Errorf("failed to start transaction: %w", err)
 	}
 	defer tx.Rollback()
 
 	// 1. Check if organization exists
-	fmt.Println("1️⃣  Checking organization...")
+	fmt.Fprintln(out, "1️⃣  Checking organization...")
 	var orgID uuid.UUID
-	query := `SELECT id FROM organizations WHERE domain = $1`
-	err = tx.QueryRow(query, config.OrgDomain).Scan(&orgID)
+	var passwordPolicyJSON []byte
+	query := `SELECT id, password_policy FROM organizations WHERE domain = $1`
+	err = tx.QueryRow(query, config.OrgDomain).Scan(&orgID, &passwordPolicyJSON)
 
+	result := &bootstrapResult{UserEmail: config.AdminEmail}
 	if err != nil {
 		// Organization doesn't exist, create it
-		fmt.Printf("   Creating organization '%s'...\n", config.OrgName)
+		fmt.Fprintf(out, "   Creating organization '%s'...\n", config.OrgName)
 		orgID = uuid.New()
 		query = `
 			INSERT INTO organizations (id, name, domain, plan_type, max_agents, max_users, is_active)
@@ -188,24 +296,33 @@ func runBootstrap(ctx context.Context, db *sql.DB, config *BootstrapConfig) erro
 		`
 		_, err = tx.Exec(query, orgID, config.OrgName, config.OrgDomain, "enterprise", config.MaxAgents, config.MaxUsers, true)
 		if err != nil {
-			return fmt.Errorf("failed to create organization: %w", err)
+			return nil, fmt.Errorf("failed to create organization: %w", err)
 		}
-		fmt.Println("   ✓ Organization created")
+		fmt.Fprintln(out, "   ✓ Organization created")
+		result.OrganizationCreated = true
 	} else {
-		fmt.Printf("   ✓ Organization exists (ID: %s)\n", orgID)
+		fmt.Fprintf(out, "   ✓ Organization exists (ID: %s)\n", orgID)
 	}
-
-	// 2. Hash password
-	fmt.Println("2️⃣  Hashing password...")
-	passwordHasher := auth.NewPasswordHasher()
+	result.OrganizationID = orgID
+
+	// 2. Hash password (an existing organization's password policy applies to the admin as well)
+	fmt.Fprintln(out, "2️⃣  Hashing password...")
+	passwordPolicy := domain.DefaultPasswordPolicy()
+	if len(passwordPolicyJSON) > 0 {
+		if err := json.Unmarshal(passwordPolicyJSON, &passwordPolicy); err != nil {
+			return nil, fmt.Errorf("failed to read organization password policy: %w", err)
+		}
+	}
+	passwordHasher := auth.NewPasswordHasherWithPolicy(passwordPolicy)
 	passwordHash, err := passwordHasher.HashPassword(config.AdminPassword)
 	if err != nil {
-		return fmt.Errorf("failed to hash password: %w", err)
+		return nil, fmt.Errorf("failed to hash password: %w", err)
 	}
-	fmt.Println("   ✓ Password hashed")
+	fmt.Fprintln(out, "   ✓ Password hashed")
 
-	// 3. Create admin user
-	fmt.Println("3️⃣  Creating admin user...")
+	// 3. Create the user. Bootstrap only adds users: an existing user with the same email keeps
+	// its role and password unless --force is given, so re-running it never demotes an admin.
+	fmt.Fprintf(out, "3️⃣  Creating %s user...\n", config.AdminRole)
 	userID := uuid.New()
 	providerID := fmt.Sprintf("local-%s", userID.String())
 
@@ -216,17 +333,17 @@ func runBootstrap(ctx context.Context, db *sql.DB, config *BootstrapConfig) erro
 		) VALUES (
 			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, NOW(), NOW()
 		)
-		ON CONFLICT (organization_id, email) DO UPDATE
-		SET role = $5, password_hash = $8, email_verified = $9, force_password_change = $10, updated_at = NOW()
+		ON CONFLICT (organization_id, email) DO NOTHING
 		RETURNING id
 	`
 
+	result.UserStatus, result.UserRole = userCreated, config.AdminRole
 	err = tx.QueryRow(query,
 		userID,
 		orgID,
 		config.AdminEmail,
 		config.AdminName,
-		domain.RoleAdmin,
+		config.AdminRole,
 		"local",
 		providerID,
 		passwordHash,
@@ -234,13 +351,37 @@ func runBootstrap(ctx context.Context, db *sql.DB, config *BootstrapConfig) erro
 		true,  // force_password_change - user must change default password
 	).Scan(&userID)
 
-	if err != nil {
-		return fmt.Errorf("failed to create admin user: %w", err)
+	if errors.Is(err, sql.ErrNoRows) {
+		var existingRole domain.UserRole
+		query = `SELECT id, role FROM users WHERE organization_id = $1 AND email = $2`
+		if err := tx.QueryRow(query, orgID, config.AdminEmail).Scan(&userID, &existingRole); err != nil {
+			return nil, fmt.Errorf("failed to load existing user: %w", err)
+		}
+
+		if config.Force {
+			query = `
+				UPDATE users
+				SET role = $1, password_hash = $2, email_verified = $3, force_password_change = $4, updated_at = NOW()
+				WHERE id = $5
+			`
+			if _, err := tx.Exec(query, config.AdminRole, passwordHash, true, true, userID); err != nil {
+				return nil, fmt.Errorf("failed to reset existing user: %w", err)
+			}
+			result.UserStatus = userUpdated
+			fmt.Fprintf(out, "   ✓ Existing user reset from %s to %s (ID: %s)\n", existingRole, config.AdminRole, userID)
+		} else {
+			result.UserStatus, result.UserRole = userUnchanged, existingRole
+			fmt.Fprintf(out, "   ✓ User already exists as %s (ID: %s), left unchanged\n", existingRole, userID)
+		}
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to create user: %w", err)
+	} else {
+		fmt.Fprintf(out, "   ✓ User created (ID: %s)\n", userID)
 	}
-	fmt.Printf("   ✓ Admin user created (ID: %s)\n", userID)
+	result.UserID = userID
 
 	// 4. Mark bootstrap as completed
-	fmt.Println("4️⃣  Updating system configuration...")
+	fmt.Fprintln(out, "4️⃣  Updating system configuration...")
 	query = `
 		INSERT INTO system_config (key, value, description, updated_at)
 		VALUES ('bootstrap_completed', 'true', 'Initial admin bootstrap completed', NOW())
@@ -249,14 +390,15 @@ func runBootstrap(ctx context.Context, db *sql.DB, config *BootstrapConfig) erro
 	`
 	_, err = tx.Exec(query)
 	if err != nil {
-		return fmt.Errorf("failed to update system config: %w", err)
+		return nil, fmt.Errorf("failed to update system config: %w", err)
 	}
-	fmt.Println("   ✓ System configuration updated")
+	fmt.Fprintln(out, "   ✓ System configuration updated")
 
 	// Commit transaction
 	if err := tx.Commit(); err != nil {
-		return fmt.Errorf("failed to commit transaction: %w", err)
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
 	}
+	result.BootstrapCompleted = true
 
-	return nil
+	return result, nil
 }
@@ -0,0 +1,183 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"io"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newBootstrapTestConfig(role domain.UserRole, force bool) *BootstrapConfig {
+	return &BootstrapConfig{
+		AdminEmail:    "ops@example.com",
+		AdminPassword: "Correct-Horse-Battery-9",
+		AdminName:     "Ops",
+		AdminRole:     role,
+		Force:         force,
+		OrgName:       "Example",
+		OrgDomain:     "example.com",
+		Output:        "text",
+	}
+}
+
+// expectExistingOrganization expects the organization lookup and returns the organization ID
+func expectExistingOrganization(mock sqlmock.Sqlmock) uuid.UUID {
+	orgID := uuid.New()
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT id, password_policy FROM organizations")).
+		WithArgs("example.com").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "password_policy"}).AddRow(orgID, nil))
+	return orgID
+}
+
+// expectUserConflict expects the user insert to hit an existing user with role
+func expectUserConflict(mock sqlmock.Sqlmock, orgID, userID uuid.UUID, role domain.UserRole) {
+	mock.ExpectQuery(regexp.QuoteMeta("ON CONFLICT (organization_id, email) DO NOTHING")).
+		WillReturnError(sql.ErrNoRows)
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT id, role FROM users")).
+		WithArgs(orgID, "ops@example.com").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "role"}).AddRow(userID, role))
+}
+
+func expectBootstrapCompleted(mock sqlmock.Sqlmock) {
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO system_config")).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+}
+
+func TestRunBootstrap_CreatesNonAdminUser(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	orgID := expectExistingOrganization(mock)
+	userID := uuid.New()
+	mock.ExpectQuery(regexp.QuoteMeta("INSERT INTO users")).
+		WithArgs(sqlmock.AnyArg(), orgID, "ops@example.com", "Ops", domain.RoleMember, "local", sqlmock.AnyArg(), sqlmock.AnyArg(), true, true).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(userID))
+	expectBootstrapCompleted(mock)
+
+	result, err := runBootstrap(context.Background(), db, newBootstrapTestConfig(domain.RoleMember, false), io.Discard)
+	require.NoError(t, err)
+	assert.Equal(t, userCreated, result.UserStatus)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRunBootstrap_LeavesExistingUserUnchanged(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	// An existing admin is neither demoted nor given a new password; any UPDATE would fail the mock
+	orgID := expectExistingOrganization(mock)
+	expectUserConflict(mock, orgID, uuid.New(), domain.RoleAdmin)
+	expectBootstrapCompleted(mock)
+
+	result, err := runBootstrap(context.Background(), db, newBootstrapTestConfig(domain.RoleViewer, false), io.Discard)
+	require.NoError(t, err)
+	assert.Equal(t, userUnchanged, result.UserStatus)
+	assert.Equal(t, domain.RoleAdmin, result.UserRole)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRunBootstrap_ForceResetsExistingUser(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	orgID := expectExistingOrganization(mock)
+	userID := uuid.New()
+	expectUserConflict(mock, orgID, userID, domain.RoleViewer)
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE users")).
+		WithArgs(domain.RoleAdmin, sqlmock.AnyArg(), true, true, userID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	expectBootstrapCompleted(mock)
+
+	result, err := runBootstrap(context.Background(), db, newBootstrapTestConfig(domain.RoleAdmin, true), io.Discard)
+	require.NoError(t, err)
+	assert.Equal(t, userUpdated, result.UserStatus)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRunBootstrap_JSONOutput(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	userID := uuid.New()
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT id, password_policy FROM organizations")).
+		WithArgs("example.com").
+		WillReturnError(sql.ErrNoRows)
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO organizations")).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(regexp.QuoteMeta("INSERT INTO users")).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(userID))
+	expectBootstrapCompleted(mock)
+
+	var progress, stdout bytes.Buffer
+	result, err := runBootstrap(context.Background(), db, newBootstrapTestConfig(domain.RoleAdmin, false), &progress)
+	require.NoError(t, err)
+	result.Status = bootstrapStatusCompleted
+	require.NoError(t, printJSONResult(&stdout, result))
+
+	// Progress stays out of the JSON stream
+	assert.Contains(t, progress.String(), "Creating organization")
+	var output map[string]interface{}
+	require.NoError(t, json.Unmarshal(stdout.Bytes(), &output))
+	assert.Equal(t, map[string]interface{}{
+		"status":              "completed",
+		"organizationId":      result.OrganizationID.String(),
+		"organizationCreated": true,
+		"userId":              userID.String(),
+		"userEmail":           "ops@example.com",
+		"userRole":            "admin",
+		"userStatus":          "created",
+		"alreadyBootstrapped": false,
+		"bootstrapCompleted":  true,
+	}, output)
+	assert.NotEqual(t, uuid.Nil, result.OrganizationID)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPrintJSONFailure(t *testing.T) {
+	var stdout bytes.Buffer
+	require.NoError(t, printJSONFailure(&stdout, bootstrapStatusError, "Failed to ping database: connection refused"))
+	require.NoError(t, printJSONFailure(&stdout, bootstrapStatusCancelled, ""))
+
+	lines := strings.Split(strings.TrimSpace(stdout.String()), "\n")
+	require.Len(t, lines, 2)
+	assert.JSONEq(t, `{"status":"error","error":"Failed to ping database: connection refused"}`, lines[0])
+	assert.JSONEq(t, `{"status":"cancelled"}`, lines[1])
+}
+
+func TestValidateConfig_RejectsUnknownRole(t *testing.T) {
+	config := newBootstrapTestConfig("superuser", false)
+	config.DatabaseURL = "postgres://localhost/aim"
+
+	assert.ErrorContains(t, validateConfig(config), "invalid role")
+
+	config.AdminRole = domain.RoleManager
+	assert.NoError(t, validateConfig(config))
+}
+
+func TestValidateConfig_RejectsUnknownOutput(t *testing.T) {
+	config := newBootstrapTestConfig(domain.RoleAdmin, false)
+	config.DatabaseURL = "postgres://localhost/aim"
+
+	config.Output = "yaml"
+	assert.ErrorContains(t, validateConfig(config), "invalid output")
+
+	config.Output = "json"
+	assert.NoError(t, validateConfig(config))
+}
@@ -3,7 +3,9 @@ package main
 import (
 	"context"
 	"database/sql"
+	"flag"
 	"fmt"
+	"io"
 	"log"
 	"os"
 	"path/filepath"
@@ -12,6 +14,7 @@ import (
 	"time"
 
 	_ "github.com/lib/pq"
+	"github.com/opena2a/identity/backend/internal/infrastructure/database"
 )
 
 const (
@@ -26,13 +29,25 @@ const (
 	colorWhite  = "\033[37m"
 )
 
+// consolidatedSchemaFile is applied instead of the incremental migrations on a fresh database
+const consolidatedSchemaFile = "V1__consolidated_schema.sql"
+
 type Migration struct {
 	Version  string
 	Filename string
 	SQL      string
+	Checksum string
 }
 
 func main() {
+	down := flag.Int("down", 0, "Roll back the last N applied migrations using their .down.sql files")
+	dryRun := flag.Bool("dry-run", false, "Print the pending migrations and their SQL without changing the database")
+	flag.Parse()
+
+	if *dryRun && *down > 0 {
+		log.Fatal("❌ -dry-run cannot be combined with -down")
+	}
+
 	// Get database URL from environment
 	databaseURL := os.Getenv("DATABASE_URL")
 	if databaseURL == "" {
@@ -58,11 +73,29 @@ func main() {
 	fmt.Printf("%s  AIM Database Migration System%s\n", colorCyan, colorReset)
 	fmt.Printf("%s════════════════════════════════════════%s\n\n", colorCyan, colorReset)
 
+	if *dryRun {
+		if err := dryRunMigrations(ctx, db, "migrations", os.Stdout); err != nil {
+			log.Fatalf("❌ Dry run failed: %v", err)
+		}
+		return
+	}
+
 	// Create schema_migrations table if it doesn't exist
 	if err := ensureMigrationsTable(ctx, db); err != nil {
 		log.Fatalf("❌ Failed to create migrations table: %v", err)
 	}
 
+	if *down > 0 {
+		if err := rollbackMigrations(ctx, db, "migrations", *down); err != nil {
+			log.Fatalf("❌ Failed to roll back migrations: %v", err)
+		}
+
+		fmt.Printf("\n%s════════════════════════════════════════%s\n", colorGreen, colorReset)
+		fmt.Printf("%s  ✅ Rolled back %d migration(s)%s\n", colorGreen, *down, colorReset)
+		fmt.Printf("%s════════════════════════════════════════%s\n\n", colorGreen, colorReset)
+		return
+	}
+
 	// Check if database is empty (fresh deployment)
 	isFresh, err := isDatabaseFresh(ctx, db)
 	if err != nil {
@@ -73,14 +106,14 @@ func main() {
 		fmt.Printf("%s🆕 Fresh database detected%s\n", colorGreen, colorReset)
 		fmt.Printf("   Using consolidated V1 schema for fast deployment\n\n")
 		
-		if err := applyConsolidatedSchema(ctx, db); err != nil {
+		if err := applyConsolidatedSchema(ctx, db, "migrations"); err != nil {
 			log.Fatalf("❌ Failed to apply consolidated schema: %v", err)
 		}
 	} else {
 		fmt.Printf("%s📦 Existing database detected%s\n", colorYellow, colorReset)
 		fmt.Printf("   Using incremental migrations\n\n")
 		
-		if err := applyIncrementalMigrations(ctx, db); err != nil {
+		if err := applyIncrementalMigrations(ctx, db, "migrations"); err != nil {
 			log.Fatalf("❌ Failed to apply incremental migrations: %v", err)
 		}
 	}
@@ -97,8 +130,10 @@ func ensureMigrationsTable(ctx context.Context, db *sql.DB) error {
 			applied_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
 		)
 	`
-	_, err := db.ExecContext(ctx, query)
-	return err
+	if _, err := db.ExecContext(ctx, query); err != nil {
+		return err
+	}
+	return database.EnsureMigrationChecksumColumn(ctx, db)
 }
 
 func isDatabaseFresh(ctx context.Context, db *sql.DB) (bool, error) {
@@ -119,9 +154,9 @@ func isDatabaseFresh(ctx context.Context, db *sql.DB) (bool, error) {
 	return !exists, nil
 }
 
-func applyConsolidatedSchema(ctx context.Context, db *sql.DB) error {
+func applyConsolidatedSchema(ctx context.Context, db *sql.DB, dir string) error {
 	// Read V1 consolidated schema
-	schemaPath := "migrations/V1__consolidated_schema.sql"
+	schemaPath := filepath.Join(dir, consolidatedSchemaFile)
 	
 	content, err := os.ReadFile(schemaPath)
 	if err != nil {
@@ -149,7 +184,7 @@ func applyConsolidatedSchema(ctx context.Context, db *sql.DB) error {
 	return nil
 }
 
-func applyIncrementalMigrations(ctx context.Context, db *sql.DB) error {
+func applyIncrementalMigrations(ctx context.Context, db *sql.DB, dir string) error {
 	// Get already applied migrations
 	applied, err := getAppliedMigrations(ctx, db)
 	if err != nil {
@@ -157,11 +192,16 @@ func applyIncrementalMigrations(ctx context.Context, db *sql.DB) error {
 	}
 
 	// Read all migration files
-	migrations, err := readMigrationFiles("migrations")
+	migrations, err := readMigrationFiles(dir)
 	if err != nil {
 		return fmt.Errorf("failed to read migration files: %w", err)
 	}
 
+	// Fail if an applied migration was edited
+	if err := database.VerifyMigrationChecksums(ctx, db, migrationChecksums(migrations)); err != nil {
+		return err
+	}
+
 	// Filter out already applied migrations
 	pending := filterPendingMigrations(migrations, applied)
 
@@ -217,6 +257,11 @@ func readMigrationFiles(dir string) ([]Migration, error) {
 			continue
 		}
 
+		// Down migrations are only applied by -down
+		if strings.HasSuffix(file.Name(), ".down.sql") {
+			continue
+		}
+
 		// Skip consolidated schema (only for fresh deployments)
 		if strings.HasPrefix(file.Name(), "V1__consolidated") {
 			continue
@@ -228,13 +273,14 @@ func readMigrationFiles(dir string) ([]Migration, error) {
 			return nil, fmt.Errorf("failed to read %s: %w", file.Name(), err)
 		}
 
-		// Extract version from filename (e.g., "001_initial_schema.sql" -> "001")
-		version := strings.TrimSuffix(file.Name(), ".sql")
+		// Extract version from filename (e.g., "001_initial_schema.sql" -> "001_initial_schema")
+		version := migrationBaseName(file.Name())
 
 		migrations = append(migrations, Migration{
 			Version:  version,
 			Filename: file.Name(),
 			SQL:      string(content),
+			Checksum: database.MigrationChecksum(content),
 		})
 	}
 
@@ -246,6 +292,17 @@ func readMigrationFiles(dir string) ([]Migration, error) {
 	return migrations, nil
 }
 
+// migrationChecksums maps every migration to its checksum. The server records versions as
+// filenames, this tool without the extension; both are mapped so either kind of row is checked.
+func migrationChecksums(migrations []Migration) map[string]string {
+	checksums := make(map[string]string, 2*len(migrations))
+	for _, m := range migrations {
+		checksums[m.Version] = m.Checksum
+		checksums[m.Filename] = m.Checksum
+	}
+	return checksums
+}
+
 func filterPendingMigrations(migrations []Migration, applied map[string]bool) []Migration {
 	var pending []Migration
 	for _, m := range migrations {
@@ -270,11 +327,217 @@ func applyMigration(ctx context.Context, db *sql.DB, migration Migration) error
 
 	// Record migration
 	_, err = tx.ExecContext(ctx,
-		"INSERT INTO schema_migrations (version, applied_at) VALUES ($1, $2)",
-		migration.Version, time.Now())
+		"INSERT INTO schema_migrations (version, applied_at, checksum) VALUES ($1, $2, $3)",
+		migration.Version, time.Now(), migration.Checksum)
 	if err != nil {
 		return err
 	}
 
 	return tx.Commit()
 }
+
+// migrationBaseName strips the extension from a migration filename or recorded version, so
+// "001_init.sql", "001_init.up.sql" and "001_init" all map to "001_init". The server records
+// versions with the extension, this tool without.
+func migrationBaseName(name string) string {
+	name = strings.TrimSuffix(name, ".sql")
+	return strings.TrimSuffix(name, ".up")
+}
+
+// readDownMigrations returns the .down.sql files in dir keyed by the base name of the migration they revert
+func readDownMigrations(dir string) (map[string]Migration, error) {
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	downs := make(map[string]Migration)
+	for _, file := range files {
+		if file.IsDir() || !strings.HasSuffix(file.Name(), ".down.sql") {
+			continue
+		}
+
+		content, err := os.ReadFile(filepath.Join(dir, file.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", file.Name(), err)
+		}
+
+		version := strings.TrimSuffix(file.Name(), ".down.sql")
+		downs[version] = Migration{
+			Version:  version,
+			Filename: file.Name(),
+			SQL:      string(content),
+		}
+	}
+
+	return downs, nil
+}
+
+// rollbackMigrations reverts the last n applied migrations, newest first, by running their
+// .down.sql files and deleting their schema_migrations rows. Everything runs in one transaction:
+// if any step fails, nothing is rolled back. Every migration must have a down file.
+func rollbackMigrations(ctx context.Context, db *sql.DB, dir string, n int) error {
+	rows, err := db.QueryContext(ctx,
+		"SELECT version FROM schema_migrations ORDER BY applied_at DESC, version DESC LIMIT $1", n)
+	if err != nil {
+		return fmt.Errorf("failed to get applied migrations: %w", err)
+	}
+	var versions []string
+	for rows.Next() {
+		var version string
+		if err := rows.Scan(&version); err != nil {
+			rows.Close()
+			return err
+		}
+		versions = append(versions, version)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	if len(versions) < n {
+		return fmt.Errorf("only %d migration(s) applied, cannot roll back %d", len(versions), n)
+	}
+
+	downs, err := readDownMigrations(dir)
+	if err != nil {
+		return fmt.Errorf("failed to read down migrations: %w", err)
+	}
+
+	// Check every down file exists before touching the schema
+	plan := make([]Migration, 0, len(versions))
+	for _, version := range versions {
+		down, ok := downs[migrationBaseName(version)]
+		if !ok {
+			return fmt.Errorf("no down migration for %s (expected %s.down.sql)", version, migrationBaseName(version))
+		}
+		down.Version = version // Delete the row exactly as it was recorded
+		plan = append(plan, down)
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	for _, down := range plan {
+		fmt.Printf("%s◀ Reverting: %s%s\n", colorBlue, down.Filename, colorReset)
+
+		if _, err := tx.ExecContext(ctx, down.SQL); err != nil {
+			return fmt.Errorf("failed to execute %s: %w", down.Filename, err)
+		}
+		if _, err := tx.ExecContext(ctx, "DELETE FROM schema_migrations WHERE version = $1", down.Version); err != nil {
+			return fmt.Errorf("failed to remove migration record %s: %w", down.Version, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	fmt.Printf("%s  ✓ Reverted %d migration(s)%s\n", colorGreen, len(plan), colorReset)
+	return nil
+}
+
+// dryRunMigrations prints what a real run would do: whether the database is treated as fresh or
+// incremental, and the SQL of every migration that would be applied. It only reads from the
+// database; schema_migrations is not created or written.
+func dryRunMigrations(ctx context.Context, db *sql.DB, dir string, w io.Writer) error {
+	fmt.Fprintf(w, "%s🔍 Dry run: no changes will be made%s\n\n", colorCyan, colorReset)
+
+	isFresh, err := isDatabaseFresh(ctx, db)
+	if err != nil {
+		return fmt.Errorf("failed to check database state: %w", err)
+	}
+
+	if isFresh {
+		fmt.Fprintf(w, "%s🆕 Fresh database: would apply the consolidated V1 schema%s\n\n", colorGreen, colorReset)
+		content, err := os.ReadFile(filepath.Join(dir, consolidatedSchemaFile))
+		if err != nil {
+			return fmt.Errorf("failed to read consolidated schema: %w", err)
+		}
+		printMigrationSQL(w, consolidatedSchemaFile, string(content))
+		return nil
+	}
+
+	fmt.Fprintf(w, "%s📦 Existing database: would apply incremental migrations%s\n\n", colorYellow, colorReset)
+
+	migrations, err := readMigrationFiles(dir)
+	if err != nil {
+		return fmt.Errorf("failed to read migration files: %w", err)
+	}
+
+	applied := map[string]bool{}
+	tracked, err := hasMigrationsTable(ctx, db)
+	if err != nil {
+		return err
+	}
+	if tracked {
+		if applied, err = getAppliedMigrations(ctx, db); err != nil {
+			return fmt.Errorf("failed to get applied migrations: %w", err)
+		}
+		if err := reportModifiedMigrations(ctx, db, migrations, w); err != nil {
+			return err
+		}
+	}
+
+	pending := filterPendingMigrations(migrations, applied)
+	if len(pending) == 0 {
+		fmt.Fprintf(w, "%s✓ No pending migrations%s\n", colorGreen, colorReset)
+		return nil
+	}
+
+	fmt.Fprintf(w, "%s📝 %d pending migration(s):%s\n", colorYellow, len(pending), colorReset)
+	for _, migration := range pending {
+		fmt.Fprintf(w, "   • %s\n", migration.Filename)
+	}
+	fmt.Fprintln(w)
+	for _, migration := range pending {
+		printMigrationSQL(w, migration.Filename, migration.SQL)
+	}
+	return nil
+}
+
+// reportModifiedMigrations fails like a real run would if an applied migration was edited. It
+// is skipped while schema_migrations has no checksum column yet.
+func reportModifiedMigrations(ctx context.Context, db *sql.DB, migrations []Migration, w io.Writer) error {
+	var hasChecksums bool
+	err := db.QueryRowContext(ctx, `
+		SELECT EXISTS (
+			SELECT FROM information_schema.columns
+			WHERE table_schema = 'public'
+			AND table_name = 'schema_migrations'
+			AND column_name = 'checksum'
+		)
+	`).Scan(&hasChecksums)
+	if err != nil || !hasChecksums {
+		return err
+	}
+
+	modified, _, err := database.CompareMigrationChecksums(ctx, db, migrationChecksums(migrations))
+	if err != nil {
+		return err
+	}
+	if len(modified) > 0 {
+		fmt.Fprintf(w, "%s✗ Applied migrations were modified: %s%s\n", colorRed, strings.Join(modified, ", "), colorReset)
+		return database.ErrMigrationChecksumMismatch
+	}
+	return nil
+}
+
+func hasMigrationsTable(ctx context.Context, db *sql.DB) (bool, error) {
+	var exists bool
+	err := db.QueryRowContext(ctx, `SELECT to_regclass('public.schema_migrations') IS NOT NULL`).Scan(&exists)
+	if err != nil {
+		return false, fmt.Errorf("failed to check for schema_migrations: %w", err)
+	}
+	return exists, nil
+}
+
+func printMigrationSQL(w io.Writer, filename, content string) {
+	fmt.Fprintf(w, "%s── %s ──%s\n", colorBlue, filename, colorReset)
+	fmt.Fprintln(w, strings.TrimSpace(content))
+	fmt.Fprintln(w)
+}
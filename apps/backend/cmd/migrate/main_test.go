@@ -0,0 +1,249 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/opena2a/identity/backend/internal/infrastructure/database"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeMigrations creates a migrations directory holding the given files
+func writeMigrations(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644))
+	}
+	return dir
+}
+
+func TestReadMigrationFiles_SkipsDownMigrations(t *testing.T) {
+	dir := writeMigrations(t, map[string]string{
+		"001_create_widgets.sql":      "CREATE TABLE widgets (id INT);",
+		"002_add_color.up.sql":        "ALTER TABLE widgets ADD COLUMN color TEXT;",
+		"002_add_color.down.sql":      "ALTER TABLE widgets DROP COLUMN color;",
+		"001_create_widgets.down.sql": "DROP TABLE widgets;",
+	})
+
+	migrations, err := readMigrationFiles(dir)
+	require.NoError(t, err)
+	require.Len(t, migrations, 2)
+	assert.Equal(t, "001_create_widgets", migrations[0].Version)
+	assert.Equal(t, "002_add_color", migrations[1].Version)
+}
+
+func TestApplyIncrementalMigrations_DetectsModifiedAppliedFile(t *testing.T) {
+	original := "CREATE TABLE widgets (id INT);"
+	dir := writeMigrations(t, map[string]string{
+		"001_create_widgets.sql": "CREATE TABLE widgets (id BIGINT);", // edited after it was applied
+		"002_add_color.sql":      "ALTER TABLE widgets ADD COLUMN color TEXT;",
+	})
+
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT version FROM schema_migrations")).
+		WillReturnRows(sqlmock.NewRows([]string{"version"}).AddRow("001_create_widgets"))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT version, checksum FROM schema_migrations")).
+		WillReturnRows(sqlmock.NewRows([]string{"version", "checksum"}).
+			AddRow("001_create_widgets", database.MigrationChecksum([]byte(original))))
+
+	// The pending 002 migration must not run
+	err = applyIncrementalMigrations(context.Background(), db, dir)
+	assert.ErrorIs(t, err, database.ErrMigrationChecksumMismatch)
+	assert.ErrorContains(t, err, "001_create_widgets")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDryRunMigrations_WritesNothing(t *testing.T) {
+	dir := writeMigrations(t, map[string]string{
+		"001_create_widgets.sql": "CREATE TABLE widgets (id INT);",
+		"002_add_color.sql":      "ALTER TABLE widgets ADD COLUMN color TEXT;",
+	})
+
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	// Only reads are expected: any INSERT, CREATE or ALTER fails the expectations
+	mock.ExpectQuery(regexp.QuoteMeta("table_name = 'organizations'")).
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+	mock.ExpectQuery(regexp.QuoteMeta("to_regclass('public.schema_migrations')")).
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT version FROM schema_migrations")).
+		WillReturnRows(sqlmock.NewRows([]string{"version"}).AddRow("001_create_widgets"))
+	mock.ExpectQuery(regexp.QuoteMeta("column_name = 'checksum'")).
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT version, checksum FROM schema_migrations")).
+		WillReturnRows(sqlmock.NewRows([]string{"version", "checksum"}).
+			AddRow("001_create_widgets", database.MigrationChecksum([]byte("CREATE TABLE widgets (id INT);"))))
+
+	var out bytes.Buffer
+	require.NoError(t, dryRunMigrations(context.Background(), db, dir, &out))
+	assert.NoError(t, mock.ExpectationsWereMet())
+
+	assert.Contains(t, out.String(), "Existing database")
+	assert.Contains(t, out.String(), "1 pending migration(s)")
+	assert.Contains(t, out.String(), "ALTER TABLE widgets ADD COLUMN color TEXT;")
+	assert.NotContains(t, out.String(), "CREATE TABLE widgets")
+}
+
+func TestDryRunMigrations_FreshDatabase(t *testing.T) {
+	dir := writeMigrations(t, map[string]string{
+		consolidatedSchemaFile:   "CREATE TABLE organizations (id UUID);",
+		"001_create_widgets.sql": "CREATE TABLE widgets (id INT);",
+	})
+
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	// schema_migrations does not exist yet and must not be created
+	mock.ExpectQuery(regexp.QuoteMeta("table_name = 'organizations'")).
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+
+	var out bytes.Buffer
+	require.NoError(t, dryRunMigrations(context.Background(), db, dir, &out))
+	assert.NoError(t, mock.ExpectationsWereMet())
+
+	assert.Contains(t, out.String(), "Fresh database")
+	assert.Contains(t, out.String(), "CREATE TABLE organizations (id UUID);")
+}
+
+func TestRollbackMigrations_RevertsNewestFirstInOneTransaction(t *testing.T) {
+	dir := writeMigrations(t, map[string]string{
+		"001_create_widgets.sql":      "CREATE TABLE widgets (id INT);",
+		"001_create_widgets.down.sql": "DROP TABLE widgets;",
+		"002_add_color.sql":           "ALTER TABLE widgets ADD COLUMN color TEXT;",
+		"002_add_color.down.sql":      "ALTER TABLE widgets DROP COLUMN color;",
+	})
+
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	// Versions recorded by the server include the extension; both forms must resolve
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT version FROM schema_migrations")).
+		WithArgs(2).
+		WillReturnRows(sqlmock.NewRows([]string{"version"}).AddRow("002_add_color.sql").AddRow("001_create_widgets"))
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("ALTER TABLE widgets DROP COLUMN color;")).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(regexp.QuoteMeta("DELETE FROM schema_migrations")).WithArgs("002_add_color.sql").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta("DROP TABLE widgets;")).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(regexp.QuoteMeta("DELETE FROM schema_migrations")).WithArgs("001_create_widgets").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	require.NoError(t, rollbackMigrations(context.Background(), db, dir, 2))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRollbackMigrations_FailureRollsBackTransaction(t *testing.T) {
+	dir := writeMigrations(t, map[string]string{
+		"001_create_widgets.down.sql": "DROP TABLE widgets;",
+		"002_add_color.down.sql":      "ALTER TABLE widgets DROP COLUMN color;",
+	})
+
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT version FROM schema_migrations")).
+		WithArgs(2).
+		WillReturnRows(sqlmock.NewRows([]string{"version"}).AddRow("002_add_color").AddRow("001_create_widgets"))
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("ALTER TABLE widgets DROP COLUMN color;")).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(regexp.QuoteMeta("DELETE FROM schema_migrations")).WithArgs("002_add_color").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta("DROP TABLE widgets;")).WillReturnError(fmt.Errorf("table is referenced"))
+	mock.ExpectRollback()
+
+	err = rollbackMigrations(context.Background(), db, dir, 2)
+	assert.ErrorContains(t, err, "001_create_widgets.down.sql")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRollbackMigrations_MissingDownFile(t *testing.T) {
+	dir := writeMigrations(t, map[string]string{
+		"002_add_color.down.sql": "ALTER TABLE widgets DROP COLUMN color;",
+	})
+
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	// Nothing is executed when any migration in the range cannot be reverted
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT version FROM schema_migrations")).
+		WithArgs(2).
+		WillReturnRows(sqlmock.NewRows([]string{"version"}).AddRow("002_add_color").AddRow("001_create_widgets"))
+
+	err = rollbackMigrations(context.Background(), db, dir, 2)
+	assert.ErrorContains(t, err, "001_create_widgets.down.sql")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// TestMigrateUpAndDown_TempDatabase applies, rolls back and re-applies a migration against a real
+// Postgres, then checks that editing the applied file is detected.
+// Set MIGRATE_TEST_DATABASE_URL to a server where the user may create databases to run it.
+func TestMigrateUpAndDown_TempDatabase(t *testing.T) {
+	adminURL := os.Getenv("MIGRATE_TEST_DATABASE_URL")
+	if adminURL == "" || testing.Short() {
+		t.Skip("set MIGRATE_TEST_DATABASE_URL to run against a temporary database")
+	}
+	ctx := context.Background()
+
+	admin, err := sql.Open("postgres", adminURL)
+	require.NoError(t, err)
+	defer admin.Close()
+
+	name := fmt.Sprintf("migrate_test_%d", time.Now().UnixNano())
+	_, err = admin.ExecContext(ctx, "CREATE DATABASE "+name)
+	require.NoError(t, err)
+	defer admin.ExecContext(ctx, "DROP DATABASE IF EXISTS "+name)
+
+	tempURL, err := url.Parse(adminURL)
+	require.NoError(t, err)
+	tempURL.Path = "/" + name
+	db, err := sql.Open("postgres", tempURL.String())
+	require.NoError(t, err)
+	defer db.Close()
+
+	dir := writeMigrations(t, map[string]string{
+		"001_create_widgets.sql":      "CREATE TABLE widgets (id INT PRIMARY KEY);",
+		"001_create_widgets.down.sql": "DROP TABLE widgets;",
+	})
+	tableExists := func() bool {
+		var exists bool
+		require.NoError(t, db.QueryRowContext(ctx, "SELECT to_regclass('public.widgets') IS NOT NULL").Scan(&exists))
+		return exists
+	}
+
+	require.NoError(t, ensureMigrationsTable(ctx, db))
+	require.NoError(t, applyIncrementalMigrations(ctx, db, dir))
+	require.True(t, tableExists())
+
+	require.NoError(t, rollbackMigrations(ctx, db, dir, 1))
+	assert.False(t, tableExists())
+	applied, err := getAppliedMigrations(ctx, db)
+	require.NoError(t, err)
+	assert.Empty(t, applied)
+
+	// The rolled back migration is pending again
+	require.NoError(t, applyIncrementalMigrations(ctx, db, dir))
+	assert.True(t, tableExists())
+
+	// Editing it after it was applied is detected
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "001_create_widgets.sql"), []byte("CREATE TABLE widgets (id BIGINT PRIMARY KEY);"), 0o644))
+	err = applyIncrementalMigrations(ctx, db, dir)
+	assert.ErrorIs(t, err, database.ErrMigrationChecksumMismatch)
+}
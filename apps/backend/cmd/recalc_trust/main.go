@@ -0,0 +1,87 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"flag"
+	"log"
+	"os"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	_ "github.com/lib/pq"
+	"github.com/opena2a/identity/backend/internal/application"
+	"github.com/opena2a/identity/backend/internal/infrastructure/repository"
+)
+
+// Recalculate every agent's trust score, e.g. after the trust weights change. Each score is stored
+// like a single recalculation, so the change shows up in the agent's trust score history.
+func main() {
+	databaseURL := flag.String("database-url", os.Getenv("DATABASE_URL"), "PostgreSQL connection URL")
+	orgID := flag.String("org", "", "Only recalculate agents in this organization ID (default: all active organizations)")
+	batchSize := flag.Int("batch-size", application.DefaultTrustRecalculationBatchSize, "Agents loaded per batch")
+	flag.Parse()
+
+	if *databaseURL == "" {
+		log.Fatal("❌ DATABASE_URL environment variable or -database-url is required")
+	}
+
+	db, err := sql.Open("postgres", *databaseURL)
+	if err != nil {
+		log.Fatalf("❌ Failed to connect to database: %v", err)
+	}
+	defer db.Close()
+
+	if err := db.Ping(); err != nil {
+		log.Fatalf("❌ Failed to ping database: %v", err)
+	}
+
+	calculator := application.NewTrustCalculatorWithVerification(
+		repository.NewTrustScoreRepository(db),
+		repository.NewAPIKeyRepository(db),
+		repository.NewAuditLogRepository(db),
+		repository.NewCapabilityRepository(sqlx.NewDb(db, "postgres")),
+		repository.NewAgentRepository(db),
+		repository.NewAlertRepository(db, application.AlertDedupWindowFromEnv()),
+		repository.NewVerificationEventRepository(db),
+		repository.NewOrganizationRepository(db),
+		repository.NewMCPServerRepository(db),
+		nil, // Default trust score drop thresholds
+		repository.NewOutboxRepository(db),
+	)
+
+	ctx := context.Background()
+
+	var summaries []*application.TrustRecalculationSummary
+	if *orgID != "" {
+		id, parseErr := uuid.Parse(*orgID)
+		if parseErr != nil {
+			log.Fatalf("❌ Invalid organization ID: %v", parseErr)
+		}
+		var summary *application.TrustRecalculationSummary
+		summary, err = calculator.RecalculateOrganization(ctx, id, *batchSize)
+		summaries = append(summaries, summary)
+	} else {
+		summaries, err = calculator.RecalculateAll(ctx, *batchSize)
+	}
+
+	for _, summary := range summaries {
+		logSummary(summary)
+	}
+	if err != nil {
+		log.Fatalf("❌ Trust score recalculation failed: %v", err)
+	}
+	log.Printf("✅ Trust scores recalculated for %d organization(s)", len(summaries))
+}
+
+func logSummary(summary *application.TrustRecalculationSummary) {
+	log.Printf("📊 Organization %s: %d/%d agents recalculated, %d changed, %d failed (%dms)",
+		summary.OrganizationID,
+		summary.Recalculated, summary.TotalAgents,
+		summary.Changed, summary.Failed,
+		summary.DurationMs,
+	)
+	for _, agentID := range summary.FailedAgentIDs {
+		log.Printf("⚠️  Agent %s could not be recalculated", agentID)
+	}
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"flag"
+	"log"
+	"os"
+	"time"
+
+	"github.com/google/uuid"
+	_ "github.com/lib/pq"
+	"github.com/opena2a/identity/backend/internal/application"
+	"github.com/opena2a/identity/backend/internal/infrastructure/repository"
+)
+
+// Purge audit logs, verification events and resolved alerts older than each organization's
+// retention policy. Run it from cron, or set DATA_RETENTION_INTERVAL to let the server do it.
+func main() {
+	databaseURL := flag.String("database-url", os.Getenv("DATABASE_URL"), "PostgreSQL connection URL")
+	orgID := flag.String("org", "", "Only enforce retention for this organization ID (default: all active organizations)")
+	batchSize := flag.Int("batch-size", application.DefaultDataRetentionBatchSize, "Rows deleted per statement")
+	flag.Parse()
+
+	if *databaseURL == "" {
+		log.Fatal("❌ DATABASE_URL environment variable or -database-url is required")
+	}
+
+	db, err := sql.Open("postgres", *databaseURL)
+	if err != nil {
+		log.Fatalf("❌ Failed to connect to database: %v", err)
+	}
+	defer db.Close()
+
+	if err := db.Ping(); err != nil {
+		log.Fatalf("❌ Failed to ping database: %v", err)
+	}
+
+	orgRepo := repository.NewOrganizationRepository(db)
+	service := application.NewDataRetentionService(
+		repository.NewDataRetentionRepository(db),
+		orgRepo,
+		repository.NewUserRepository(db),
+		repository.NewAuditLogRepository(db),
+		*batchSize,
+	)
+
+	ctx := context.Background()
+	now := time.Now()
+
+	var summaries []*application.DataRetentionSummary
+	if *orgID != "" {
+		id, parseErr := uuid.Parse(*orgID)
+		if parseErr != nil {
+			log.Fatalf("❌ Invalid organization ID: %v", parseErr)
+		}
+		org, getErr := orgRepo.GetByID(id)
+		if getErr != nil {
+			log.Fatalf("❌ Failed to load organization: %v", getErr)
+		}
+		var summary *application.DataRetentionSummary
+		summary, err = service.EnforceOrganizationRetention(ctx, org, now)
+		summaries = append(summaries, summary)
+	} else {
+		summaries, err = service.EnforceRetention(ctx, now)
+	}
+
+	for _, summary := range summaries {
+		logSummary(summary)
+	}
+	if err != nil {
+		log.Fatalf("❌ Data retention failed: %v", err)
+	}
+	log.Printf("✅ Data retention enforced for %d organization(s)", len(summaries))
+}
+
+func logSummary(summary *application.DataRetentionSummary) {
+	policy := summary.Policy
+	log.Printf("🗑️  Organization %s: %d audit logs (> %dd), %d verification events (> %dd), %d resolved alerts (> %dd)",
+		summary.OrganizationID,
+		summary.AuditLogsPurged, policy.AuditLogDays,
+		summary.VerificationEventsPurged, policy.VerificationEventDays,
+		summary.ResolvedAlertsPurged, policy.ResolvedAlertDays,
+	)
+}
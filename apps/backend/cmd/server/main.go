@@ -27,13 +27,24 @@ import (
 	"github.com/opena2a/identity/backend/internal/domain"
 	"github.com/opena2a/identity/backend/internal/infrastructure/auth"
 	"github.com/opena2a/identity/backend/internal/infrastructure/cache"
+	infracrypto "github.com/opena2a/identity/backend/internal/infrastructure/crypto"
+	"github.com/opena2a/identity/backend/internal/infrastructure/database"
 	"github.com/opena2a/identity/backend/internal/infrastructure/email"
+	"github.com/opena2a/identity/backend/internal/infrastructure/logging"
 	"github.com/opena2a/identity/backend/internal/infrastructure/metrics"
+	"github.com/opena2a/identity/backend/internal/infrastructure/oauth"
 	"github.com/opena2a/identity/backend/internal/infrastructure/repository"
 	"github.com/opena2a/identity/backend/internal/interfaces/http/handlers"
 	"github.com/opena2a/identity/backend/internal/interfaces/http/middleware"
 )
 
+// buildVersion is reported by the health and status endpoints.
+// Release builds override it with -ldflags "-X main.buildVersion=<version>".
+var buildVersion = "1.0.0"
+
+// backgroundTaskShutdownTimeout bounds how long shutdown waits for in-flight background work
+const backgroundTaskShutdownTimeout = 30 * time.Second
+
 // @title Agent Identity Management API
 // @version 1.0
 // @description production-ready identity verification and security platform for AI agents and MCP servers
@@ -54,6 +65,9 @@ func main() {
 		log.Println("No .env file found in project root, using environment variables")
 	}
 
+	// Structured JSON logging (LOG_LEVEL controls verbosity)
+	logging.Init()
+
 	// Load configuration
 	cfg, err := config.Load()
 	if err != nil {
@@ -74,6 +88,12 @@ func main() {
 	}
 	log.Println("✅ Database migrations completed successfully")
 
+	// Readiness reports 503 while the schema lags behind these files, e.g. during a rolling deploy
+	migrationFiles, err := getMigrationFiles()
+	if err != nil {
+		log.Printf("⚠️  Failed to list migration files for readiness checks: %v", err)
+	}
+
 	// Initialize Redis (optional - used for caching only)
 	redisClient, err := initRedis(cfg)
 	if err != nil {
@@ -108,8 +128,38 @@ func main() {
 		cacheService = nil
 	}
 
+	// Per-agent / per-organization rate limits (shared via Redis when available)
+	var rateLimitStore middleware.TokenBucketStore
+	if cacheService != nil {
+		rateLimitStore = cacheService
+	}
+	rateLimiter := middleware.NewRateLimiter(cfg.RateLimit.AgentRequests, cfg.RateLimit.OrgRequests, cfg.RateLimit.Window, rateLimitStore)
+
+	// Idempotency-Key support for verification creation (shared via Redis when available)
+	var idempotencyStore middleware.IdempotencyStore
+	if cacheService != nil {
+		idempotencyStore = cacheService
+	}
+	idempotency := middleware.IdempotencyMiddleware(idempotencyStore, middleware.DefaultIdempotencyTTL)
+
 	// Initialize infrastructure services
-	jwtService := auth.NewJWTService()
+	jwtSigningKeys, err := auth.LoadJWTSigningKeys(cfg.JWT.SigningKeys)
+	if err != nil {
+		log.Fatal("Failed to load JWT signing keys:", err)
+	}
+	jwtService, err := auth.NewJWTServiceWithConfig(auth.JWTConfig{
+		Secret:          cfg.JWT.Secret,
+		AccessTokenTTL:  cfg.JWT.AccessTokenTTL,
+		RefreshTokenTTL: cfg.JWT.RefreshTokenTTL,
+		Issuer:          cfg.JWT.Issuer,
+		Audience:        cfg.JWT.Audience,
+		SigningKeys:     jwtSigningKeys,
+		SigningKeyID:    cfg.JWT.SigningKeyID,
+		RejectHS256:     cfg.JWT.RejectHS256,
+	})
+	if err != nil {
+		log.Fatal("Failed to initialize JWT service:", err)
+	}
 
 	// Initialize email service
 	emailService, err := initEmailService()
@@ -122,39 +172,101 @@ func main() {
 	// Initialize application services
 	services, keyVault := initServices(db, repos, cacheService, oauthRepo, jwtService, emailService)
 
+	// Encrypt webhook secrets stored in plaintext before any delivery is signed with them
+	if encrypted, err := services.Webhook.EncryptPlaintextSecrets(context.Background()); err != nil {
+		log.Fatal("Failed to encrypt webhook secrets:", err)
+	} else if encrypted > 0 {
+		log.Printf("🔐 Encrypted %d plaintext webhook secrets", encrypted)
+	}
+
+	// Background workers stop when the server shuts down; see the graceful shutdown below
+	tasks := services.BackgroundTasks
+
+	// Retry failed webhook deliveries in the background
+	tasks.Go(func(ctx context.Context) {
+		services.Webhook.StartRetryWorker(ctx, application.WebhookRetryPollInterval)
+	})
+
+	// Dispatch alerts and webhook events queued in the outbox alongside business changes
+	tasks.Go(func(ctx context.Context) {
+		services.OutboxRelay.StartRelay(ctx, application.OutboxRelayPollInterval)
+	})
+
+	// Warn about agent keys that are about to expire
+	keyExpiryScanInterval, keyExpiryLeadTime := application.KeyExpiryScanSettingsFromEnv()
+	tasks.Go(func(ctx context.Context) {
+		services.Agent.StartKeyExpiryScanner(ctx, keyExpiryScanInterval, keyExpiryLeadTime)
+	})
+
+	// Probe MCP server URLs so the dashboard knows which servers are reachable
+	tasks.Go(func(ctx context.Context) {
+		services.MCP.StartHealthCheckPoller(ctx, application.MCPHealthCheckIntervalFromEnv())
+	})
+
+	// Reject capability requests nobody reviewed in time and remind admins about stale ones
+	capabilityRequestSweepInterval, _ := application.CapabilityRequestExpirySettingsFromEnv()
+	tasks.Go(func(ctx context.Context) {
+		services.CapabilityRequest.StartExpirySweeper(ctx, capabilityRequestSweepInterval)
+	})
+
+	// Disable API keys once they expire
+	tasks.Go(func(ctx context.Context) {
+		services.APIKey.StartExpirySweeper(ctx, application.APIKeyExpirySweepInterval)
+	})
+
+	// Snapshot compliance check results per framework for the compliance score history
+	tasks.Go(func(ctx context.Context) {
+		services.Compliance.StartComplianceCheckScheduler(ctx, application.ComplianceCheckIntervalFromEnv())
+	})
+
+	// Purge data past each organization's retention policy (opt-in; otherwise run cmd/retention)
+	if interval := application.DataRetentionIntervalFromEnv(); interval > 0 {
+		tasks.Go(func(ctx context.Context) {
+			services.DataRetention.StartRetentionScheduler(ctx, interval)
+		})
+	}
+
+	// Write buffered verification events in batches; shutdown flushes what is left
+	if services.VerificationEventBuffer != nil {
+		tasks.Go(services.VerificationEventBuffer.Run)
+	}
+
 	// Initialize handlers
-	h := initHandlers(services, repos, jwtService, keyVault, cfg, db)
+	h := initHandlers(services, repos, jwtService, keyVault, cfg, db, rateLimiter)
 
 	// Create Fiber app
 	app := fiber.New(fiber.Config{
 		AppName:           "Agent Identity Management",
 		ServerHeader:      "AIM/1.0",
 		ErrorHandler:      customErrorHandler,
-		ReadTimeout:       30 * time.Second,
-		WriteTimeout:      30 * time.Second,
+		ReadTimeout:       cfg.HTTP.ReadTimeout,
+		WriteTimeout:      cfg.HTTP.WriteTimeout,
+		BodyLimit:         cfg.HTTP.BodyLimit,
 		ReadBufferSize:    16384, // 16KB header buffer (default is 4096) for OAuth callback URLs
 		DisableKeepalive:  false,
 		StreamRequestBody: false,
 	})
+	app.Server().HeaderReceived = middleware.RouteLimitsHook(routeLimits(cfg.HTTP))
 
 	// Prometheus metrics endpoint (no auth required)
 	// CRITICAL: Must be registered BEFORE Prometheus middleware to avoid circular recording
 	app.Get("/metrics", metrics.PrometheusHandler())
 
 	// Global middleware
+	app.Use(middleware.RequestIDMiddleware())
 	app.Use(middleware.RecoveryMiddleware())
 	app.Use(middleware.LoggerMiddleware())
 	app.Use(metrics.PrometheusMiddleware())   // Prometheus metrics collection
 	app.Use(middleware.AnalyticsTracking(db)) // Real-time API call tracking
-	// app.Use(middleware.RequestLoggerMiddleware())
 
 	// CORS with allowed origins from environment
 	// IMPORTANT: Frontend ALWAYS runs on port 3000, backend on port 8080
 	allowedOrigins := []string{
 		"http://localhost:3000",
 	}
-	if customOrigins := os.Getenv("ALLOWED_ORIGINS"); customOrigins != "" {
-		allowedOrigins = []string{customOrigins}
+	// ALLOWED_ORIGINS is a comma-separated list; entries may use wildcard subdomains (https://*.example.com)
+	if customOrigins := middleware.ParseAllowedOrigins(os.Getenv("ALLOWED_ORIGINS")); len(customOrigins) > 0 {
+		allowedOrigins = customOrigins
 	}
 	app.Use(middleware.CORSMiddleware(allowedOrigins))
 
@@ -167,33 +279,10 @@ func main() {
 		})
 	})
 
-	app.Get("/health/ready", func(c fiber.Ctx) error {
-		// Check database
-		if err := db.Ping(); err != nil {
-			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{
-				"ready": false,
-				"error": "database unavailable",
-			})
-		}
+	app.Get("/health/ready", readinessHandler(db, redisClient, migrationFiles))
 
-		// Check Redis (optional - skip if not configured)
-		redisStatus := "not configured"
-		if redisClient != nil {
-			ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
-			defer cancel()
-			if err := redisClient.Ping(ctx).Err(); err != nil {
-				redisStatus = "unavailable (optional)"
-			} else {
-				redisStatus = "connected"
-			}
-		}
-
-		return c.JSON(fiber.Map{
-			"ready":    true,
-			"database": "connected",
-			"redis":    redisStatus,
-		})
-	})
+	// Public keys for verifying RS256 access tokens (no auth required)
+	app.Get("/.well-known/jwks.json", h.Auth.GetJWKS)
 
 	// System status endpoint (no auth required)
 	app.Get("/api/v1/status", func(c fiber.Ctx) error {
@@ -229,7 +318,7 @@ func main() {
 
 		return c.JSON(fiber.Map{
 			"status":      "operational",
-			"version":     "1.0.0",
+			"version":     buildVersion,
 			"environment": environment,
 			"uptime":      time.Since(startTime).Seconds(),
 			"services": fiber.Map{
@@ -247,11 +336,7 @@ func main() {
 	})
 
 	// ✅ Action verification for SDK (signature-based auth, NO API key required)
-	// IMPORTANT: Register directly on app (not through group) to avoid API key middleware
-	// These endpoints verify Ed25519 signatures instead of requiring API keys
-	app.Post("/api/v1/sdk-api/verifications", middleware.RateLimitMiddleware(), h.Verification.CreateVerification)
-	app.Get("/api/v1/sdk-api/verifications/:id", middleware.RateLimitMiddleware(), h.Verification.GetVerification)
-	app.Post("/api/v1/sdk-api/verifications/:id/result", middleware.RateLimitMiddleware(), h.Verification.SubmitVerificationResult)
+	setupSDKVerificationRoutes(app, h, idempotency)
 
 	// ⭐ SDK API routes - MUST be at app level to avoid middleware inheritance
 	// These routes use Ed25519 agent authentication for SDK/programmatic access
@@ -259,6 +344,7 @@ func main() {
 	sdkAPI := app.Group("/api/v1/sdk-api")
 	sdkAPI.Use(middleware.Ed25519AgentMiddleware(services.Agent)) // Validates agent signatures, passes through JWT
 	sdkAPI.Use(middleware.RateLimitMiddleware())
+	sdkAPI.Use(middleware.ScopedRateLimitMiddleware(rateLimiter))                               // Per-agent token bucket
 	sdkAPI.Get("/agents/:identifier", h.Agent.GetAgentByIdentifier)                             // Get agent by ID or name (SDK)
 	sdkAPI.Post("/agents/:id/capabilities", h.Capability.GrantCapability)                       // SDK capability reporting
 	sdkAPI.Post("/agents/:id/capability-requests", h.CapabilityRequest.CreateCapabilityRequest) // SDK capability request creation
@@ -269,7 +355,12 @@ func main() {
 
 	// API v1 routes (JWT authenticated)
 	v1 := app.Group("/api/v1")
-	setupRoutes(v1, h, services, jwtService, repos.SDKToken, db)
+	sdkTokenTracking := middleware.NewSDKTokenTrackingMiddleware(repos.SDKToken)
+	tasks.Go(func(ctx context.Context) {
+		// Write usage counts of SDK tokens that went idle within their throttle interval
+		sdkTokenTracking.StartFlusher(ctx)
+	})
+	setupRoutes(v1, h, services, jwtService, sdkTokenTracking, db, rateLimiter, idempotency)
 
 	// Start server
 	port := cfg.Server.Port
@@ -299,6 +390,11 @@ func main() {
 		log.Fatal("Server forced to shutdown:", err)
 	}
 
+	// Stop the schedulers and let in-flight background work finish before the database closes
+	if err := tasks.Shutdown(backgroundTaskShutdownTimeout); err != nil {
+		log.Printf("⚠️  Background tasks did not finish: %v", err)
+	}
+
 	log.Println("Server exited")
 }
 
@@ -323,8 +419,9 @@ func initDatabase(cfg *config.Config) (*sql.DB, error) {
 	db.SetMaxIdleConns(cfg.Database.MaxConnections / 2)
 	db.SetConnMaxLifetime(cfg.Database.ConnMaxLifetime)
 
-	// Test connection
-	if err := db.Ping(); err != nil {
+	// Test connection, giving Postgres time to come up when started alongside it
+	if err := database.PingWithRetry(context.Background(), db, cfg.Database.ConnectAttempts, cfg.Database.ConnectRetryInterval); err != nil {
+		db.Close()
 		return nil, err
 	}
 
@@ -368,8 +465,17 @@ type Repositories struct {
 	VerificationEvent  *repository.VerificationEventRepositorySimple
 	Tag                *repository.TagRepository
 	SDKToken           domain.SDKTokenRepository
+	RefreshToken       *repository.RefreshTokenRepository
+	PasswordReset      *repository.PasswordResetTokenRepository
+	TwoFactor          *repository.TwoFactorRepository
 	Capability         domain.CapabilityRepository
-	CapabilityRequest  domain.CapabilityRequestRepository // ✅ For capability expansion approval workflow
+	CapabilityRequest  domain.CapabilityRequestRepository  // ✅ For capability expansion approval workflow
+	AgentBaseline      *repository.AgentBaselineRepository // ✅ For config drift baselines
+	Compliance         *repository.ComplianceViolationRepository
+	ComplianceSnapshot *repository.ComplianceCheckSnapshotRepository
+	DataRetention      *repository.DataRetentionRepository
+	Outbox             *repository.OutboxRepository
+	PolicySimulation   *repository.PolicySimulationRepository
 }
 
 func initRepositories(db *sql.DB) (*Repositories, *repository.OAuthRepositoryPostgres) {
@@ -386,7 +492,7 @@ func initRepositories(db *sql.DB) (*Repositories, *repository.OAuthRepositoryPos
 		APIKey:             repository.NewAPIKeyRepository(db),
 		TrustScore:         repository.NewTrustScoreRepository(db),
 		AuditLog:           repository.NewAuditLogRepository(db),
-		Alert:              repository.NewAlertRepository(db),
+		Alert:              repository.NewAlertRepository(db, application.AlertDedupWindowFromEnv()),
 		MCPServer:          repository.NewMCPServerRepository(db),
 		MCPCapability:      repository.NewMCPServerCapabilityRepository(db), // ✅ For MCP server capabilities
 		MCPAttestation:     repository.NewMCPAttestationRepository(db),      // ✅ For agent attestation of MCPs
@@ -397,8 +503,17 @@ func initRepositories(db *sql.DB) (*Repositories, *repository.OAuthRepositoryPos
 		VerificationEvent:  repository.NewVerificationEventRepository(db),
 		Tag:                repository.NewTagRepository(db),
 		SDKToken:           repository.NewSDKTokenRepository(db),
+		RefreshToken:       repository.NewRefreshTokenRepository(db),
+		PasswordReset:      repository.NewPasswordResetTokenRepository(db),
+		TwoFactor:          repository.NewTwoFactorRepository(db),
 		Capability:         repository.NewCapabilityRepository(dbx),
 		CapabilityRequest:  repository.NewCapabilityRequestRepository(dbx), // ✅ For capability expansion approval workflow
+		AgentBaseline:      repository.NewAgentBaselineRepository(db),
+		Compliance:         repository.NewComplianceViolationRepository(db),
+		ComplianceSnapshot: repository.NewComplianceCheckSnapshotRepository(db),
+		DataRetention:      repository.NewDataRetentionRepository(db),
+		Outbox:             repository.NewOutboxRepository(db),
+		PolicySimulation:   repository.NewPolicySimulationRepository(db),
 	}, oauthRepo
 }
 
@@ -421,9 +536,19 @@ type Services struct {
 	Registration      *application.RegistrationService // ✅ Email/password registration workflow (replaced OAuth)
 	Tag               *application.TagService
 	SDKToken          *application.SDKTokenService
+	RefreshToken      *application.RefreshTokenService
+	TwoFactor         *application.TwoFactorService
 	Capability        *application.CapabilityService
 	CapabilityRequest *application.CapabilityRequestService // ✅ For capability expansion approval workflow
 	Detection         *application.DetectionService         // ✅ For MCP auto-detection (SDK + Direct API)
+	ReplayGuard       *application.VerificationReplayGuard  // Timestamp skew + replay checks for signed verifications
+	LoginLockout      *application.LoginLockout             // Brute-force protection for password logins
+	SDKTokenRecovery  *application.SDKTokenRecoveryGuard    // Proof-of-possession + failure lockout for SDK token recovery
+	DataRetention     *application.DataRetentionService
+	OutboxRelay       *application.OutboxRelay
+	BackgroundTasks   *application.BackgroundTasks // Goroutines drained on graceful shutdown
+
+	VerificationEventBuffer *application.BufferedVerificationEventRepository // nil unless VERIFICATION_EVENT_BUFFER_SIZE is set
 }
 
 func initServices(db *sql.DB, repos *Repositories, cacheService *cache.RedisCache, oauthRepo *repository.OAuthRepositoryPostgres, jwtService *auth.JWTService, emailService domain.EmailService) (*Services, *crypto.KeyVault) {
@@ -434,16 +559,41 @@ func initServices(db *sql.DB, repos *Repositories, cacheService *cache.RedisCach
 	}
 	log.Println("✅ KeyVault initialized for automatic key generation")
 
+	// Cache VerifyAction's agent and capability reads, and optionally its allow decisions, in Redis
+	// when available. Services write agents, capabilities and user deactivations through these
+	// repositories, which invalidate both caches.
+	var agentLookupStore application.AgentLookupStore
+	var verificationDecisionStore application.VerificationDecisionStore
+	if cacheService != nil {
+		agentLookupStore = cacheService
+		verificationDecisionStore = cacheService
+	}
+	agentLookupCache := application.NewAgentLookupCache(agentLookupStore, application.AgentLookupCacheTTLFromEnv())
+	verificationDecisionCache := application.NewVerificationDecisionCache(verificationDecisionStore, application.VerificationDecisionCacheTTLFromEnv())
+	if verificationDecisionCache != nil {
+		log.Println("✅ Verification decision cache enabled")
+	}
+	agentCacheInvalidator := application.AgentCacheInvalidators{agentLookupCache, verificationDecisionCache}
+	agentRepo := application.NewInvalidatingAgentRepository(repos.Agent, agentCacheInvalidator)
+	capabilityRepo := application.NewInvalidatingCapabilityRepository(repos.Capability, agentCacheInvalidator)
+	userRepo := application.NewInvalidatingUserRepository(repos.User, agentCacheInvalidator)
+	// Alerts are created through the outbox so one raised mid-request survives a crash; only the
+	// relay that creates them uses repos.Alert directly
+	alertRepo := application.NewOutboxAlertRepository(repos.Alert, repos.Outbox)
+
 	// ✅ Initialize Security Policy Service for policy-based enforcement
 	securityPolicyService := application.NewSecurityPolicyService(
 		repos.SecurityPolicy,
-		repos.Alert,
+		alertRepo,
 		repos.AuditLog,
+		repos.VerificationEvent, // ✅ For verification rate baselines in unusual activity detection
+		repos.AgentBaseline,     // ✅ For config drift against the verified baseline
+		repos.PolicySimulation,  // Would-block records of simulate-mode policies
 	)
 
 	// Create services
 	authService := application.NewAuthService(
-		repos.User,
+		userRepo,
 		repos.Organization,
 		repos.APIKey,
 		securityPolicyService, // ✅ For auto-creating default policies
@@ -451,7 +601,7 @@ func initServices(db *sql.DB, repos *Repositories, cacheService *cache.RedisCach
 	)
 
 	adminService := application.NewAdminService(
-		repos.User,
+		userRepo,
 		repos.Organization,
 	)
 
@@ -461,51 +611,83 @@ func initServices(db *sql.DB, repos *Repositories, cacheService *cache.RedisCach
 		repos.TrustScore,
 		repos.APIKey,
 		repos.AuditLog,
-		repos.Capability,
-		repos.Agent,             // For fetching agent data
-		repos.Alert,             // For security alerts scoring
+		capabilityRepo,
+		agentRepo,               // For fetching agent data
+		alertRepo,               // For security alerts scoring
 		repos.VerificationEvent, // For real verification statistics
+		repos.Organization,      // For per-organization trust weights and decay half-life
+		repos.MCPServer,         // For the MCP servers factor
+		securityPolicyService,   // For per-organization trust score drop thresholds
+		repos.Outbox,            // Queues trust_score_drop webhook events
 	)
 
 	// ✅ Initialize drift detection service BEFORE verification event service
 	driftDetectionService := application.NewDriftDetectionService(
-		repos.Agent,
-		repos.Alert,
+		agentRepo,
+		alertRepo,
 	)
 
+	// Buffer verification event inserts when VERIFICATION_EVENT_BUFFER_SIZE is set; main flushes the
+	// buffer in the background and on shutdown
+	var verificationEventRepo domain.VerificationEventRepository = repos.VerificationEvent
+	var verificationEventBuffer *application.BufferedVerificationEventRepository
+	if size := application.VerificationEventBufferSizeFromEnv(); size > 0 {
+		verificationEventBuffer = application.NewBufferedVerificationEventRepository(repos.VerificationEvent, size, 0, 0)
+		verificationEventRepo = verificationEventBuffer
+	}
+
 	// ✅ Initialize verification event service BEFORE agent service
 	verificationEventService := application.NewVerificationEventService(
-		repos.VerificationEvent,
-		repos.Agent,
+		verificationEventRepo,
+		agentRepo,
 		driftDetectionService,
 	)
 
+	_, capabilityRequestTTL := application.CapabilityRequestExpirySettingsFromEnv()
+	capabilityRequestService := application.NewCapabilityRequestService(
+		repos.CapabilityRequest,
+		capabilityRepo,
+		agentRepo,
+		userRepo,
+		repos.Organization,
+		emailService, // ✅ For capability request expiry and reminder emails
+		capabilityRequestTTL,
+	)
+
 	agentService := application.NewAgentService(
-		repos.Agent,
+		agentRepo,
 		trustCalculator,
 		repos.TrustScore,
 		keyVault,                 // ✅ NEW: Inject KeyVault for automatic key generation
-		repos.Alert,              // ✅ NEW: Inject AlertRepository for security alerts
+		alertRepo,                // ✅ NEW: Inject AlertRepository for security alerts
 		securityPolicyService,    // ✅ NEW: Inject SecurityPolicyService for policy evaluation
-		repos.Capability,         // ✅ NEW: Inject CapabilityRepository for capability checks
+		capabilityRepo,           // ✅ NEW: Inject CapabilityRepository for capability checks
 		verificationEventService, // ✅ NEW: Inject VerificationEventService for creating verification events
+		repos.Organization,       // ✅ NEW: Inject OrganizationRepository for auto-verification settings
+		agentLookupCache,
+		verificationDecisionCache,
+		capabilityRequestService, // Files declared capabilities for approval when the organization requires it
 	)
 
 	apiKeyService := application.NewAPIKeyService(
 		repos.APIKey,
-		repos.Agent,
+		agentRepo,
+		repos.AuditLog,
 	)
 
 	alertService := application.NewAlertService(
-		repos.Alert,
-		repos.Agent,
+		alertRepo,
+		agentRepo,
 		db,
 	)
 
 	complianceService := application.NewComplianceService(
 		repos.AuditLog,
-		repos.Agent,
-		repos.User,
+		agentRepo,
+		userRepo,
+		repos.Organization,
+		repos.Compliance,
+		repos.ComplianceSnapshot,
 	)
 
 	// ✅ Initialize MCP capability service BEFORE MCP service
@@ -523,6 +705,7 @@ func initServices(db *sql.DB, repos *Repositories, cacheService *cache.RedisCach
 		repos.MCPCapability,      // ✅ For creating SDK capabilities
 		repos.AgentMCPConnection, // ✅ For tracking agent-MCP connections
 		repos.Agent,              // ✅ For connected agents tracking
+		repos.MCPAttestation,     // ✅ For attestation-derived trust
 	)
 
 	// ✅ Initialize MCP Attestation Service for agent attestation of MCPs
@@ -532,30 +715,33 @@ func initServices(db *sql.DB, repos *Repositories, cacheService *cache.RedisCach
 		repos.MCPServer,
 		repos.User,
 		repos.AgentMCPConnection,
+		mcpService,
 	)
 
 	securityService := application.NewSecurityService(
 		repos.Security,
 		repos.Agent,
-		repos.Alert, // ✅ For converting alerts to threats (NO MOCK DATA!)
+		alertRepo, // ✅ For converting alerts to threats (NO MOCK DATA!)
 	)
 
 	webhookService := application.NewWebhookService(
 		repos.Webhook,
+		keyVault, // Webhook signing secrets are encrypted at rest
 	)
 
 	// Initialize RegistrationService for email/password user registration workflow
 	registrationService := application.NewRegistrationService(
 		oauthRepo, // Still uses oauth_repository for now (will be renamed in later step)
-		repos.User,
+		userRepo,
 		repos.Organization, // ✅ NEW: Organization repository for auto-creating orgs
 		auditService,
 		emailService, // ✅ NEW: Email service for password reset and admin notifications
+		repos.PasswordReset,
 	)
 
 	tagService := application.NewTagService(
 		repos.Tag,
-		repos.Agent,
+		agentRepo,
 		repos.MCPServer,
 	)
 
@@ -563,24 +749,59 @@ func initServices(db *sql.DB, repos *Repositories, cacheService *cache.RedisCach
 		repos.SDKToken,
 	)
 
+	refreshTokenService := application.NewRefreshTokenService(
+		repos.RefreshToken,
+	)
+
+	twoFactorService := application.NewTwoFactorService(
+		repos.TwoFactor,
+		keyVault, // TOTP secrets are encrypted at rest
+	)
+
 	capabilityService := application.NewCapabilityService(
-		repos.Capability,
-		repos.Agent,
+		capabilityRepo,
+		agentRepo,
 		repos.AuditLog,
 		trustCalculator,
 		repos.TrustScore,
-	)
-
-	capabilityRequestService := application.NewCapabilityRequestService(
-		repos.CapabilityRequest,
-		repos.Capability,
-		repos.Agent,
+		agentCacheInvalidator, // Revocations drop the agent's cached authorization data
+		repos.Organization,    // Per-organization violation penalties
 	)
 
 	detectionService := application.NewDetectionService(
 		db,
 		trustCalculator, // ✅ NEW: Inject trust calculator for proper risk assessment
-		repos.Agent,     // ✅ NEW: Inject agent repository to fetch agent data
+		agentRepo,       // ✅ NEW: Inject agent repository to fetch agent data
+	)
+
+	// Signed verification requests: timestamp skew check and replay cache (shared via Redis when available)
+	var signatureReplayStore application.SignatureReplayStore
+	if cacheService != nil {
+		signatureReplayStore = cacheService
+	}
+	replayGuard := application.NewVerificationReplayGuard(signatureReplayStore, application.VerificationClockSkewFromEnv())
+
+	// Failed login counters and lockouts (shared via Redis when available)
+	var loginAttemptStore application.LoginAttemptStore
+	if cacheService != nil {
+		loginAttemptStore = cacheService
+	}
+	loginLockoutThreshold, loginLockoutDuration := application.LoginLockoutSettingsFromEnv()
+	loginLockout := application.NewLoginLockout(loginAttemptStore, loginLockoutThreshold, loginLockoutDuration, userRepo, alertRepo)
+	sdkTokenRecoveryGuard := application.NewSDKTokenRecoveryGuard(agentRepo, alertRepo, replayGuard, loginAttemptStore)
+
+	dataRetentionService := application.NewDataRetentionService(
+		repos.DataRetention,
+		repos.Organization,
+		userRepo,
+		repos.AuditLog,
+		application.DefaultDataRetentionBatchSize,
+	)
+
+	outboxRelay := application.NewOutboxRelay(
+		repos.Outbox,
+		repos.Alert,
+		webhookService,
 	)
 
 	return &Services{
@@ -602,9 +823,19 @@ func initServices(db *sql.DB, repos *Repositories, cacheService *cache.RedisCach
 		Registration:      registrationService, // ✅ Email/password registration workflow (replaced OAuth)
 		Tag:               tagService,
 		SDKToken:          sdkTokenService,
+		RefreshToken:      refreshTokenService,
+		TwoFactor:         twoFactorService,
 		Capability:        capabilityService,
 		CapabilityRequest: capabilityRequestService, // ✅ For capability expansion approval workflow
 		Detection:         detectionService,         // ✅ For MCP auto-detection (SDK + Direct API)
+		ReplayGuard:       replayGuard,
+		LoginLockout:      loginLockout,
+		SDKTokenRecovery:  sdkTokenRecoveryGuard,
+		DataRetention:     dataRetentionService,
+		OutboxRelay:       outboxRelay,
+		BackgroundTasks:   application.NewBackgroundTasks(),
+
+		VerificationEventBuffer: verificationEventBuffer,
 	}, keyVault
 }
 
@@ -623,24 +854,28 @@ type Handlers struct {
 	Webhook            *handlers.WebhookHandler
 	Verification       *handlers.VerificationHandler // ✅ For POST /verifications endpoint
 	VerificationEvent  *handlers.VerificationEventHandler
+	OAuthVerification  *handlers.OAuthVerificationHandler // POST /verifications/oauth (token introspection)
 	PublicAgent        *handlers.PublicAgentHandler
 	PublicRegistration *handlers.PublicRegistrationHandler
 	Tag                *handlers.TagHandler
 	SDK                *handlers.SDKHandler
 	SDKToken           *handlers.SDKTokenHandler
 	AuthRefresh        *handlers.AuthRefreshHandler
+	TwoFactor          *handlers.TwoFactorHandler
 	SDKTokenRecovery   *handlers.SDKTokenRecoveryHandler
 	Capability         *handlers.CapabilityHandler
 	Detection          *handlers.DetectionHandler          // ✅ For MCP auto-detection (SDK + Direct API)
 	CapabilityRequest  *handlers.CapabilityRequestHandlers // ✅ For capability request approval
 }
 
-func initHandlers(services *Services, repos *Repositories, jwtService *auth.JWTService, keyVault *crypto.KeyVault, cfg *config.Config, db *sql.DB) *Handlers {
+func initHandlers(services *Services, repos *Repositories, jwtService *auth.JWTService, keyVault *crypto.KeyVault, cfg *config.Config, db *sql.DB, rateLimiter *middleware.RateLimiter) *Handlers {
 	return &Handlers{
 		Auth: handlers.NewAuthHandler(
 			services.Auth,
 			jwtService,
 			repos.Organization,
+			services.LoginLockout,
+			services.TwoFactor,
 		),
 		Agent: handlers.NewAgentHandler(
 			services.Agent,
@@ -715,6 +950,14 @@ func initHandlers(services *Services, repos *Repositories, jwtService *auth.JWTS
 			services.Alert,
 			services.Trust,
 			services.VerificationEvent,
+			services.ReplayGuard,
+			services.BackgroundTasks,
+			infracrypto.NewDIDVerifier(nil),
+			rateLimiter, // The SDK verification route is not behind ScopedRateLimitMiddleware
+		),
+		OAuthVerification: handlers.NewOAuthVerificationHandler(
+			services.Agent,
+			application.NewOAuthVerificationService(oauthTokenIntrospector(cfg.OAuth.Introspection), services.VerificationEvent),
 		),
 		VerificationEvent: handlers.NewVerificationEventHandler(
 			services.VerificationEvent,
@@ -728,6 +971,8 @@ func initHandlers(services *Services, repos *Repositories, jwtService *auth.JWTS
 			services.Registration, // ✅ Renamed from OAuth to Registration
 			services.Auth,
 			jwtService,
+			services.LoginLockout,
+			services.TwoFactor,
 		),
 		Tag: handlers.NewTagHandler(
 			services.Tag,
@@ -742,10 +987,15 @@ func initHandlers(services *Services, repos *Repositories, jwtService *auth.JWTS
 		AuthRefresh: handlers.NewAuthRefreshHandler(
 			jwtService,
 			services.SDKToken,
+			services.RefreshToken,
+		),
+		TwoFactor: handlers.NewTwoFactorHandler(
+			services.TwoFactor,
 		),
 		SDKTokenRecovery: handlers.NewSDKTokenRecoveryHandler(
 			services.SDKToken,
 			jwtService,
+			services.SDKTokenRecovery,
 		),
 		Capability: handlers.NewCapabilityHandler(
 			services.Capability,
@@ -784,10 +1034,45 @@ func initEmailService() (domain.EmailService, error) {
 	return service, nil
 }
 
-func setupRoutes(v1 fiber.Router, h *Handlers, services *Services, jwtService *auth.JWTService, sdkTokenRepo domain.SDKTokenRepository, db *sql.DB) {
-	// SDK Token Tracking Middleware - TEMPORARILY DISABLED for debugging
-	// sdkTokenTrackingMiddleware := middleware.NewSDKTokenTrackingMiddleware(sdkTokenRepo)
-	// v1.Use(sdkTokenTrackingMiddleware.Handler()) // Apply to all API routes
+// oauthTokenIntrospector returns the introspector OAuth verifications check tokens with, or nil
+// (OAuth verification off) when no introspection endpoint is configured
+func oauthTokenIntrospector(cfg config.OAuthIntrospectionConfig) application.TokenIntrospector {
+	if cfg.URL == "" {
+		return nil
+	}
+	return oauth.NewTokenIntrospector(cfg.URL, cfg.ClientID, cfg.ClientSecret, cfg.Timeout)
+}
+
+// routeLimits lists the routes whose timeouts or body limit differ from the server defaults
+func routeLimits(cfg config.HTTPConfig) []middleware.RouteLimits {
+	return []middleware.RouteLimits{
+		// SDK downloads are large zip archives
+		{Path: "/api/v1/sdk/*", WriteTimeout: cfg.SDKWriteTimeout},
+		{Path: "/api/v1/agents/:id/sdk", WriteTimeout: cfg.SDKWriteTimeout},
+		// Exports stream every matching row
+		{Path: "/api/v1/compliance/export", WriteTimeout: cfg.ExportWriteTimeout},
+		{Path: "/api/v1/compliance/audit-log/export", WriteTimeout: cfg.ExportWriteTimeout},
+		{Path: "/api/v1/agents/:id/audit-logs/export", WriteTimeout: cfg.ExportWriteTimeout},
+		{Path: "/api/v1/admin/security-policies/export", WriteTimeout: cfg.ExportWriteTimeout},
+		// Bulk endpoints take many records in one body
+		{Path: "/api/v1/agents/bulk", BodyLimit: cfg.BulkBodyLimit},
+		{Path: "/api/v1/admin/security-policies/import", BodyLimit: cfg.BulkBodyLimit},
+	}
+}
+
+// setupSDKVerificationRoutes registers the action verification endpoints the SDKs call.
+// IMPORTANT: Register directly on app (not through group) to avoid API key middleware
+// These endpoints verify Ed25519 signatures instead of requiring API keys
+func setupSDKVerificationRoutes(app fiber.Router, h *Handlers, idempotency fiber.Handler) {
+	app.Post("/api/v1/sdk-api/verifications", h.Verification.CreateVerification, middleware.RateLimitMiddleware(), idempotency)
+	app.Get("/api/v1/sdk-api/verifications/:id", h.Verification.GetVerification, middleware.RateLimitMiddleware())
+	app.Post("/api/v1/sdk-api/verifications/:id/result", h.Verification.SubmitVerificationResult, middleware.RateLimitMiddleware())
+}
+
+func setupRoutes(v1 fiber.Router, h *Handlers, services *Services, jwtService *auth.JWTService, sdkTokenTrackingMiddleware *middleware.SDKTokenTrackingMiddleware, db *sql.DB, rateLimiter *middleware.RateLimiter, idempotency fiber.Handler) {
+	// SDK Token Tracking Middleware - records last use, IP and user agent of the token in X-SDK-Token
+	// once the route's own middleware has authenticated the request
+	v1.Use(sdkTokenTrackingMiddleware.Handler()) // Apply to all API routes
 
 	// ✅ Public routes (NO authentication required) - Self-registration API
 	public := v1.Group("/public")
@@ -805,14 +1090,16 @@ func setupRoutes(v1 fiber.Router, h *Handlers, services *Services, jwtService *a
 	auth := v1.Group("/auth")
 	auth.Post("/login/local", h.Auth.LocalLogin) // Local email/password login
 	auth.Post("/logout", h.Auth.Logout)
-	auth.Post("/refresh", h.AuthRefresh.RefreshToken)                 // Refresh access token (with token rotation)
-	auth.Post("/sdk/recover", h.SDKTokenRecovery.RecoverRevokedToken) // Recover revoked SDK tokens (zero downtime!)
+	auth.Post("/refresh", h.AuthRefresh.RefreshToken)                                                         // Refresh access token (with token rotation)
+	auth.Post("/sdk/recover", h.SDKTokenRecovery.RecoverRevokedToken, middleware.StrictRateLimitMiddleware()) // Recover revoked SDK tokens (signed by one of the user's agents)
 
 	// Authenticated auth routes (authentication required)
 	authProtected := v1.Group("/auth")
 	authProtected.Use(middleware.AuthMiddleware(jwtService)) // Apply middleware using Use() instead of inline
 	authProtected.Get("/me", h.Auth.Me)
 	authProtected.Post("/change-password", h.Auth.ChangePassword)
+	authProtected.Post("/2fa/enroll", h.TwoFactor.Enroll)
+	authProtected.Post("/2fa/verify", h.TwoFactor.Verify)
 
 	// Organization routes (authentication required)
 	organizations := v1.Group("/organizations")
@@ -841,6 +1128,7 @@ func setupRoutes(v1 fiber.Router, h *Handlers, services *Services, jwtService *a
 	detection.Use(middleware.Ed25519AgentMiddleware(services.Agent)) // ✅ Try Ed25519 first (for SDK agents)
 	detection.Use(middleware.AuthMiddleware(jwtService))             // ✅ Fallback to JWT (for web UI)
 	detection.Use(middleware.RateLimitMiddleware())
+	detection.Use(middleware.ScopedRateLimitMiddleware(rateLimiter))
 	detection.Post("/agents/:id/report", h.Detection.ReportDetection)
 	detection.Get("/agents/:id/status", h.Detection.GetDetectionStatus) // ✅ Now accessible from web UI with JWT
 	// ⭐ Agent Capability Detection endpoints - Report detected agent capabilities
@@ -850,53 +1138,66 @@ func setupRoutes(v1 fiber.Router, h *Handlers, services *Services, jwtService *a
 	// Agents routes - All other agent endpoints with dual authentication (Ed25519 or JWT)
 	agents := v1.Group("/agents")
 	agents.Use(middleware.Ed25519AgentMiddleware(services.Agent)) // ✅ Try Ed25519 first (for SDK agents)
+	agents.Use(middleware.OptionalAPIKeyMiddleware(db))           // API keys (scoped integrations)
 	agents.Use(middleware.AuthMiddleware(jwtService))             // ✅ Fallback to JWT (for web UI)
+	agents.Use(middleware.APIKeyScopeMiddleware())                // agents:read / agents:write for API keys
 	agents.Use(middleware.RateLimitMiddleware())
+	agents.Use(middleware.ScopedRateLimitMiddleware(rateLimiter))
 	agents.Get("/", h.Agent.ListAgents)
-	agents.Post("/", middleware.MemberMiddleware(), h.Agent.CreateAgent)
+	agents.Post("/", h.Agent.CreateAgent, middleware.MemberMiddleware())
+	agents.Post("/bulk", h.Agent.CreateAgentsBulk, middleware.MemberMiddleware())
 	agents.Get("/:id", h.Agent.GetAgent)
-	agents.Put("/:id", middleware.MemberMiddleware(), h.Agent.UpdateAgent)
-	agents.Delete("/:id", middleware.ManagerMiddleware(), h.Agent.DeleteAgent)
-	agents.Post("/:id/verify", middleware.ManagerMiddleware(), h.Agent.VerifyAgent)
+	agents.Put("/:id", h.Agent.UpdateAgent, middleware.MemberMiddleware())
+	agents.Delete("/:id", h.Agent.DeleteAgent, middleware.ManagerMiddleware())
+	agents.Post("/:id/verify", h.Agent.VerifyAgent, middleware.ManagerMiddleware())
 	// Agent lifecycle management endpoints
-	agents.Post("/:id/suspend", middleware.ManagerMiddleware(), h.Agent.SuspendAgent)
-	agents.Post("/:id/reactivate", middleware.ManagerMiddleware(), h.Agent.ReactivateAgent)
-	agents.Post("/:id/rotate-credentials", middleware.MemberMiddleware(), h.Agent.RotateCredentials)
-	agents.Put("/:id/keys", middleware.MemberMiddleware(), h.Agent.UpdateAgentKeys) // SDK key registration
+	agents.Post("/:id/suspend", h.Agent.SuspendAgent, middleware.ManagerMiddleware())
+	agents.Post("/:id/reactivate", h.Agent.ReactivateAgent, middleware.ManagerMiddleware())
+	agents.Post("/:id/compromise", h.Agent.CompromiseAgent, middleware.ManagerMiddleware())
+	agents.Post("/:id/uncompromise", h.Agent.UncompromiseAgent, middleware.ManagerMiddleware())
+	agents.Post("/:id/rotate-credentials", h.Agent.RotateCredentials, middleware.MemberMiddleware())
+	agents.Put("/:id/keys", h.Agent.UpdateAgentKeys, middleware.MemberMiddleware()) // SDK key registration
+	agents.Put("/:id/labels", h.Agent.UpdateAgentLabels, middleware.MemberMiddleware())
+	agents.Put("/:id/oauth-client", h.Agent.UpdateAgentOAuthClient, middleware.ManagerMiddleware()) // OAuth client whose tokens verify the agent
 	// Runtime verification endpoints - CORE functionality
 	agents.Post("/:id/verify-action", h.Agent.VerifyAction)
 	agents.Post("/:id/log-action/:audit_id", h.Agent.LogActionResult)
 	// SDK download endpoint - Download Python/Node.js/Go SDK with embedded credentials
-	agents.Get("/:id/sdk", h.Agent.DownloadSDK)
+	// Agents signing with their Ed25519 credentials (e.g. from CI) may only fetch their own SDK
+	agents.Get("/:id/sdk", h.Agent.DownloadSDK, middleware.AgentSelfMiddleware())
 	// Credentials endpoint - Get raw Ed25519 public/private keys for manual integration
 	agents.Get("/:id/credentials", h.Agent.GetCredentials)
 	// MCP Server relationship management - "talks_to" endpoints
 	agents.Get("/:id/mcp-servers", h.MCPAttestation.GetAgentMCPServers)                                        // ✅ Get MCP servers agent is connected to (via attestation)
-	agents.Put("/:id/mcp-servers", middleware.MemberMiddleware(), h.Agent.AddMCPServersToAgent)                // Add MCP servers (bulk)
-	agents.Delete("/:id/mcp-servers/:mcp_id", middleware.MemberMiddleware(), h.Agent.RemoveMCPServerFromAgent) // Remove single MCP
-	agents.Post("/:id/mcp-servers/detect", middleware.MemberMiddleware(), h.Agent.DetectAndMapMCPServers)      // Auto-detect MCPs from config
+	agents.Put("/:id/mcp-servers", h.Agent.AddMCPServersToAgent, middleware.MemberMiddleware())                // Add MCP servers (bulk)
+	agents.Delete("/:id/mcp-servers/:mcp_id", h.Agent.RemoveMCPServerFromAgent, middleware.MemberMiddleware()) // Remove single MCP
+	agents.Post("/:id/mcp-servers/detect", h.Agent.DetectAndMapMCPServers, middleware.MemberMiddleware())      // Auto-detect MCPs from config
+	// Auto-detect MCPs from an uploaded config (web users; the config lives on their machine)
+	agents.Post("/:id/mcp-servers/detect-upload", h.Agent.DetectAndMapMCPServersFromUpload, middleware.MemberMiddleware())
 	// Trust Score management - RESTful endpoints under /agents/:id/trust-score/*
 	agents.Get("/:id/trust-score", h.Agent.GetAgentTrustScore)                                                      // Get current trust score
 	agents.Get("/:id/trust-score/history", h.Agent.GetAgentTrustScoreHistory)                                       // Get trust score history
-	agents.Put("/:id/trust-score", middleware.AdminMiddleware(), h.Agent.UpdateAgentTrustScore)                     // Manually update score (admin)
-	agents.Post("/:id/trust-score/recalculate", middleware.ManagerMiddleware(), h.Agent.RecalculateAgentTrustScore) // Recalculate score
+	agents.Put("/:id/trust-score", h.Agent.UpdateAgentTrustScore, middleware.AdminMiddleware())                     // Manually update score (admin)
+	agents.Post("/:id/trust-score/recalculate", h.Agent.RecalculateAgentTrustScore, middleware.ManagerMiddleware()) // Recalculate score
 	// Agent security endpoints - Key vault and audit logs per agent
-	agents.Get("/:id/key-vault", h.Agent.GetAgentKeyVault)   // Get agent's key vault info (public key, expiration, rotation status)
-	agents.Get("/:id/audit-logs", h.Agent.GetAgentAuditLogs) // Get audit logs for specific agent (with pagination)
+	agents.Get("/:id/key-vault", h.Agent.GetAgentKeyVault)             // Get agent's key vault info (public key, expiration, rotation status)
+	agents.Get("/:id/audit-logs", h.Agent.GetAgentAuditLogs)           // Get audit logs for specific agent (with pagination)
+	agents.Get("/:id/audit-logs/export", h.Agent.ExportAgentAuditLogs) // Stream the agent's full audit trail as CSV or JSON
 
 	// API keys routes (authentication required)
 	apiKeys := v1.Group("/api-keys")
 	apiKeys.Use(middleware.AuthMiddleware(jwtService))
 	apiKeys.Use(middleware.RateLimitMiddleware())
+	apiKeys.Use(middleware.ScopedRateLimitMiddleware(rateLimiter))
 	apiKeys.Get("/", h.APIKey.ListAPIKeys)
-	apiKeys.Post("/", middleware.MemberMiddleware(), h.APIKey.CreateAPIKey)
-	apiKeys.Patch("/:id/disable", middleware.MemberMiddleware(), h.APIKey.DisableAPIKey)
-	apiKeys.Delete("/:id", middleware.MemberMiddleware(), h.APIKey.DeleteAPIKey)
+	apiKeys.Post("/", h.APIKey.CreateAPIKey, middleware.MemberMiddleware())
+	apiKeys.Patch("/:id/disable", h.APIKey.DisableAPIKey, middleware.MemberMiddleware())
+	apiKeys.Delete("/:id", h.APIKey.DeleteAPIKey, middleware.MemberMiddleware())
 
 	// Trust score routes (authentication required)
 	trust := v1.Group("/trust-score")
 	trust.Use(middleware.AuthMiddleware(jwtService))
-	trust.Post("/calculate/:id", middleware.ManagerMiddleware(), h.TrustScore.CalculateTrustScore)
+	trust.Post("/calculate/:id", h.TrustScore.CalculateTrustScore, middleware.ManagerMiddleware())
 	trust.Get("/agents/:id", h.TrustScore.GetTrustScore)
 	trust.Get("/agents/:id/breakdown", h.TrustScore.GetTrustScoreBreakdown) // Detailed breakdown with weights and contributions
 	trust.Get("/agents/:id/history", h.TrustScore.GetTrustScoreHistory)
@@ -906,6 +1207,7 @@ func setupRoutes(v1 fiber.Router, h *Handlers, services *Services, jwtService *a
 	admin.Use(middleware.AuthMiddleware(jwtService))
 	admin.Use(middleware.AdminMiddleware())
 	admin.Use(middleware.RateLimitMiddleware())
+	admin.Use(middleware.ScopedRateLimitMiddleware(rateLimiter))
 
 	// User management
 	admin.Get("/users", h.Admin.ListUsers)
@@ -923,11 +1225,19 @@ func setupRoutes(v1 fiber.Router, h *Handlers, services *Services, jwtService *a
 	admin.Post("/registration-requests/:id/approve", h.Admin.ApproveRegistrationRequest)
 	admin.Post("/registration-requests/:id/reject", h.Admin.RejectRegistrationRequest)
 
-	// Organization settings (read-only - no SSO auto-approve in Community)
+	// Organization settings (read-only apart from the settings below - no SSO auto-approve in Community)
 	admin.Get("/organization/settings", h.Admin.GetOrganizationSettings)
+	admin.Put("/organization/password-policy", h.Admin.UpdatePasswordPolicy)
+	admin.Put("/organization/trust-decay", h.Admin.UpdateTrustDecayHalfLife)
+	admin.Put("/organization/auto-verify", h.Admin.UpdateAutoVerify)
+	admin.Put("/organization/capability-approval", h.Admin.UpdateCapabilityApproval)
+	admin.Put("/organization/email-branding", h.Admin.UpdateEmailBranding)
+	admin.Put("/trust-config", h.Admin.UpdateTrustConfig) // Per-organization trust score factor weights
+	admin.Put("/trust-config/violation-penalties", h.Admin.UpdateViolationPenalties)
 
 	// Audit logs
 	admin.Get("/audit-logs", h.Admin.GetAuditLogs)
+	admin.Get("/audit-logs/verify", h.Admin.VerifyAuditChain)
 
 	// Alerts
 	admin.Get("/alerts", h.Admin.GetAlerts)
@@ -939,13 +1249,19 @@ func setupRoutes(v1 fiber.Router, h *Handlers, services *Services, jwtService *a
 	// Dashboard stats
 	admin.Get("/dashboard/stats", h.Admin.GetDashboardStats)
 
+	// Trust scores - recalculate every agent after the trust weights change
+	admin.Post("/trust-score/recalculate-all", h.TrustScore.RecalculateAllTrustScores)
+
 	// Security Policy Management routes (admin only)
 	admin.Get("/security-policies", h.SecurityPolicy.ListPolicies)
+	admin.Get("/security-policies/export", h.SecurityPolicy.ExportPolicies) // Before /:id so "export" is not read as an ID
 	admin.Get("/security-policies/:id", h.SecurityPolicy.GetPolicy)
 	admin.Post("/security-policies", h.SecurityPolicy.CreatePolicy)
+	admin.Post("/security-policies/import", h.SecurityPolicy.ImportPolicies)
 	admin.Put("/security-policies/:id", h.SecurityPolicy.UpdatePolicy)
 	admin.Delete("/security-policies/:id", h.SecurityPolicy.DeletePolicy)
 	admin.Patch("/security-policies/:id/toggle", h.SecurityPolicy.TogglePolicy)
+	admin.Get("/security-policies/:id/simulation-report", h.SecurityPolicy.GetSimulationReport)
 
 	// Capability Request Management routes (admin only)
 	admin.Get("/capability-requests", h.CapabilityRequest.ListCapabilityRequests)
@@ -964,12 +1280,15 @@ func setupRoutes(v1 fiber.Router, h *Handlers, services *Services, jwtService *a
 	compliance.Use(middleware.AuthMiddleware(jwtService))
 	compliance.Use(middleware.AdminMiddleware())
 	compliance.Use(middleware.RateLimitMiddleware()) // Changed from StrictRateLimitMiddleware to allow multiple simultaneous requests
+	compliance.Use(middleware.ScopedRateLimitMiddleware(rateLimiter))
 	compliance.Get("/status", h.Compliance.GetComplianceStatus)
 	compliance.Get("/metrics", h.Compliance.GetComplianceMetrics)
 	compliance.Get("/audit-log/access-review", h.Compliance.GetAccessReview)
+	compliance.Get("/audit-log/export", h.Compliance.ExportAuditLog) // Stream audit logs as CSV or JSON
 	compliance.Get("/access-review", h.Compliance.GetAccessReview)
 	compliance.Post("/check", h.Compliance.RunComplianceCheck)
 	compliance.Get("/export", h.Compliance.ExportComplianceReport) // Export compliance report
+	compliance.Get("/history", h.Compliance.GetComplianceHistory)  // Compliance score timeline from scheduled checks
 	// Data retention and violations endpoints removed
 
 	// MCP Server routes (authentication required)
@@ -979,25 +1298,31 @@ func setupRoutes(v1 fiber.Router, h *Handlers, services *Services, jwtService *a
 	mcpServersAgentAuth := v1.Group("/mcp-servers")
 	mcpServersAgentAuth.Use(middleware.Ed25519AgentMiddleware(services.Agent)) // Ed25519 signature verification
 	mcpServersAgentAuth.Use(middleware.RateLimitMiddleware())
+	mcpServersAgentAuth.Use(middleware.ScopedRateLimitMiddleware(rateLimiter))
 	mcpServersAgentAuth.Post("/:id/attest", h.MCPAttestation.AttestMCP)               // ✅ Submit agent attestation (Ed25519 signed)
 	mcpServersAgentAuth.Get("/:id/attestations", h.MCPAttestation.GetMCPAttestations) // ✅ Get all attestations for this MCP
 	mcpServersAgentAuth.Get("/:id/agents", h.MCPAttestation.GetConnectedAgents)       // ✅ Get agents connected to this MCP (via attestation)
 
 	// Standard MCP Server management endpoints - Use JWT authentication (user-to-backend)
 	mcpServers := v1.Group("/mcp-servers")
+	mcpServers.Use(middleware.OptionalAPIKeyMiddleware(db))
 	mcpServers.Use(middleware.AuthMiddleware(jwtService))
+	mcpServers.Use(middleware.APIKeyScopeMiddleware())
 	mcpServers.Use(middleware.RateLimitMiddleware())
+	mcpServers.Use(middleware.ScopedRateLimitMiddleware(rateLimiter))
 	mcpServers.Get("/", h.MCP.ListMCPServers)
-	mcpServers.Post("/", middleware.MemberMiddleware(), h.MCP.CreateMCPServer)
+	mcpServers.Post("/", h.MCP.CreateMCPServer, middleware.MemberMiddleware())
 	mcpServers.Get("/:id", h.MCP.GetMCPServer)
-	mcpServers.Put("/:id", middleware.MemberMiddleware(), h.MCP.UpdateMCPServer)
-	mcpServers.Delete("/:id", middleware.ManagerMiddleware(), h.MCP.DeleteMCPServer)
-	mcpServers.Post("/:id/verify", middleware.ManagerMiddleware(), h.MCP.VerifyMCPServer)
-	mcpServers.Post("/:id/keys", middleware.MemberMiddleware(), h.MCP.AddPublicKey)
+	mcpServers.Put("/:id", h.MCP.UpdateMCPServer, middleware.MemberMiddleware())
+	mcpServers.Delete("/:id", h.MCP.DeleteMCPServer, middleware.ManagerMiddleware())
+	mcpServers.Post("/:id/verify", h.MCP.VerifyMCPServer, middleware.ManagerMiddleware())
+	mcpServers.Post("/:id/keys", h.MCP.AddPublicKey, middleware.MemberMiddleware())
 	mcpServers.Get("/:id/verification-status", h.MCP.GetVerificationStatus)
-	mcpServers.Get("/:id/capabilities", h.MCP.GetMCPServerCapabilities)                                    // ✅ Get detected capabilities
-	mcpServers.Get("/:id/verification-events", h.MCP.GetMCPVerificationEvents)                             // ✅ Get verification events for MCP server
-	mcpServers.Post("/:id/manual-attest", middleware.MemberMiddleware(), h.MCPAttestation.ManualAttestMCP) // ✅ Manual attestation (non-SDK users)
+	mcpServers.Get("/:id/health", h.MCP.GetMCPServerHealth)                                                                   // Last health check (refresh=true probes now)
+	mcpServers.Get("/:id/capabilities", h.MCP.GetMCPServerCapabilities)                                                       // ✅ Get detected capabilities
+	mcpServers.Get("/:id/verification-events", h.MCP.GetMCPVerificationEvents)                                                // ✅ Get verification events for MCP server
+	mcpServers.Post("/:id/manual-attest", h.MCPAttestation.ManualAttestMCP, middleware.MemberMiddleware())                    // ✅ Manual attestation (non-SDK users)
+	mcpServers.Delete("/:id/attestations/:attestationId", h.MCPAttestation.RevokeAttestation, middleware.ManagerMiddleware()) // Revoke an attestation
 	// Runtime verification endpoint - CORE functionality
 	mcpServers.Post("/:id/verify-action", h.MCP.VerifyMCPAction)
 
@@ -1006,6 +1331,7 @@ func setupRoutes(v1 fiber.Router, h *Handlers, services *Services, jwtService *a
 	security.Use(middleware.AuthMiddleware(jwtService))
 	security.Use(middleware.ManagerMiddleware())
 	security.Use(middleware.RateLimitMiddleware())
+	security.Use(middleware.ScopedRateLimitMiddleware(rateLimiter))
 	security.Get("/dashboard", h.Security.GetSecurityDashboard)
 	security.Get("/alerts", h.Security.ListSecurityAlerts)
 	security.Get("/threats", h.Security.GetThreats)
@@ -1016,29 +1342,40 @@ func setupRoutes(v1 fiber.Router, h *Handlers, services *Services, jwtService *a
 	analytics := v1.Group("/analytics")
 	analytics.Use(middleware.AuthMiddleware(jwtService))
 	analytics.Use(middleware.RateLimitMiddleware())
+	analytics.Use(middleware.ScopedRateLimitMiddleware(rateLimiter))
 	analytics.Get("/dashboard", h.Analytics.GetDashboardStats) // Viewer-accessible dashboard stats
 	analytics.Get("/usage", h.Analytics.GetUsageStatistics)
 	analytics.Get("/activity", h.Analytics.GetActivitySummary)
 	analytics.Get("/trends", h.Analytics.GetTrustScoreTrends)
 	analytics.Get("/verification-activity", h.Analytics.GetVerificationActivity) // New endpoint for chart
+	analytics.Get("/latency", h.Analytics.GetVerificationLatency)
+	analytics.Get("/api-usage", h.Analytics.GetAPIUsage)
+	analytics.Get("/topology", h.Analytics.GetTopology)
 	analytics.Get("/agents/activity", h.Analytics.GetAgentActivity)
 
 	// Webhook routes (authentication required)
 	webhooks := v1.Group("/webhooks")
 	webhooks.Use(middleware.AuthMiddleware(jwtService))
 	webhooks.Use(middleware.RateLimitMiddleware())
-	webhooks.Post("/", middleware.MemberMiddleware(), h.Webhook.CreateWebhook)
+	webhooks.Use(middleware.ScopedRateLimitMiddleware(rateLimiter))
+	webhooks.Post("/", h.Webhook.CreateWebhook, middleware.MemberMiddleware())
 	webhooks.Get("/", h.Webhook.ListWebhooks)
 	webhooks.Get("/:id", h.Webhook.GetWebhook)
-	webhooks.Put("/:id", middleware.MemberMiddleware(), h.Webhook.UpdateWebhook) // Update webhook
-	webhooks.Delete("/:id", middleware.MemberMiddleware(), h.Webhook.DeleteWebhook)
-	webhooks.Post("/:id/test", h.Webhook.TestWebhook) // Test webhook endpoint
+	webhooks.Put("/:id", h.Webhook.UpdateWebhook, middleware.MemberMiddleware()) // Update webhook
+	webhooks.Delete("/:id", h.Webhook.DeleteWebhook, middleware.MemberMiddleware())
+	webhooks.Post("/:id/test", h.Webhook.TestWebhook, middleware.MemberMiddleware())                  // Test webhook endpoint
+	webhooks.Get("/:id/deliveries", h.Webhook.GetWebhookDeliveries)                                   // Delivery history and retry status
+	webhooks.Post("/:id/rotate-secret", h.Webhook.RotateWebhookSecret, middleware.MemberMiddleware()) // Secret is returned once
 
 	// Verification routes (authentication required) - Agent action verification
 	verifications := v1.Group("/verifications")
+	verifications.Use(middleware.OptionalAPIKeyMiddleware(db))
 	verifications.Use(middleware.AuthMiddleware(jwtService))
+	verifications.Use(middleware.APIKeyScopeMiddleware())
 	verifications.Use(middleware.RateLimitMiddleware())
-	verifications.Post("/", h.Verification.CreateVerification)                 // Request verification for agent action
+	verifications.Use(middleware.ScopedRateLimitMiddleware(rateLimiter))
+	verifications.Post("/", h.Verification.CreateVerification, idempotency)    // Request verification for agent action (Idempotency-Key aware)
+	verifications.Post("/oauth", h.OAuthVerification.VerifyOAuthToken)         // Introspect an agent's OAuth access token
 	verifications.Get("/:id", h.Verification.GetVerification)                  // Get verification status by ID
 	verifications.Post("/:id/result", h.Verification.SubmitVerificationResult) // Submit verification result
 
@@ -1046,40 +1383,45 @@ func setupRoutes(v1 fiber.Router, h *Handlers, services *Services, jwtService *a
 	verificationEvents := v1.Group("/verification-events")
 	verificationEvents.Use(middleware.AuthMiddleware(jwtService))
 	verificationEvents.Use(middleware.RateLimitMiddleware())
+	verificationEvents.Use(middleware.ScopedRateLimitMiddleware(rateLimiter))
 	verificationEvents.Get("/", h.VerificationEvent.ListVerificationEvents)
 	verificationEvents.Get("/recent", h.VerificationEvent.GetRecentEvents)
+	verificationEvents.Get("/stream", h.VerificationEvent.StreamVerificationEvents) // Server-Sent Events for live dashboards
 	verificationEvents.Get("/statistics", h.VerificationEvent.GetStatistics)
-	verificationEvents.Get("/stats", h.VerificationEvent.GetVerificationStats)           // ✅ Get aggregated verification stats
+	verificationEvents.Get("/stats", h.VerificationEvent.GetVerificationStats) // ✅ Get aggregated verification stats
+	verificationEvents.Get("/stats/by-protocol", h.VerificationEvent.GetStatisticsByProtocol)
 	verificationEvents.Get("/agent/:id", h.VerificationEvent.GetAgentVerificationEvents) // ✅ Get events for specific agent
 	verificationEvents.Get("/mcp/:id", h.VerificationEvent.GetMCPVerificationEvents)     // ✅ Get events for specific MCP server
 	verificationEvents.Get("/:id", h.VerificationEvent.GetVerificationEvent)
-	verificationEvents.Post("/", middleware.MemberMiddleware(), h.VerificationEvent.CreateVerificationEvent)
-	verificationEvents.Delete("/:id", middleware.ManagerMiddleware(), h.VerificationEvent.DeleteVerificationEvent)
+	verificationEvents.Post("/", h.VerificationEvent.CreateVerificationEvent, middleware.MemberMiddleware())
+	verificationEvents.Delete("/:id", h.VerificationEvent.DeleteVerificationEvent, middleware.ManagerMiddleware())
 
 	// Tag routes (authentication required)
 	tags := v1.Group("/tags")
 	tags.Use(middleware.AuthMiddleware(jwtService))
 	tags.Use(middleware.RateLimitMiddleware())
+	tags.Use(middleware.ScopedRateLimitMiddleware(rateLimiter))
 	tags.Get("/", h.Tag.GetTags)
-	tags.Post("/", middleware.MemberMiddleware(), h.Tag.CreateTag)
-	tags.Put("/:id", middleware.MemberMiddleware(), h.Tag.UpdateTag)
+	tags.Post("/", h.Tag.CreateTag, middleware.MemberMiddleware())
+	tags.Put("/:id", h.Tag.UpdateTag, middleware.MemberMiddleware())
 	tags.Get("/popular", h.Tag.GetPopularTags)
 	tags.Get("/search", h.Tag.SearchTags)
-	tags.Delete("/:id", middleware.ManagerMiddleware(), h.Tag.DeleteTag)
+	tags.Delete("/:id", h.Tag.DeleteTag, middleware.ManagerMiddleware())
 
 	// Agent tag routes (under /agents/:id/tags)
 	agents.Get("/:id/tags", h.Tag.GetAgentTags)
-	agents.Post("/:id/tags", middleware.MemberMiddleware(), h.Tag.AddTagsToAgent)
-	agents.Delete("/:id/tags/:tagId", middleware.MemberMiddleware(), h.Tag.RemoveTagFromAgent)
+	agents.Post("/:id/tags", h.Tag.AddTagsToAgent, middleware.MemberMiddleware())
+	agents.Delete("/:id/tags/:tagId", h.Tag.RemoveTagFromAgent, middleware.MemberMiddleware())
 	agents.Get("/:id/tags/suggestions", h.Tag.SuggestTagsForAgent)
 
 	// Agent capability routes (under /agents/:id/capabilities)
 	agents.Get("/:id/capabilities", h.Capability.GetAgentCapabilities)
-	agents.Post("/:id/capabilities", middleware.ManagerMiddleware(), h.Capability.GrantCapability)
-	agents.Delete("/:id/capabilities/:capabilityId", middleware.ManagerMiddleware(), h.Capability.RevokeCapability)
+	agents.Post("/:id/capabilities", h.Capability.GrantCapability, middleware.ManagerMiddleware())
+	agents.Delete("/:id/capabilities/:capabilityId", h.Capability.RevokeCapability, middleware.ManagerMiddleware())
 
 	// Agent violation routes (under /agents/:id/violations)
 	agents.Get("/:id/violations", h.Capability.GetViolationsByAgent)
+	agents.Get("/:id/violations/stats", h.Capability.GetViolationStats)
 
 	// Capabilities routes (authentication required) - List all available capability types
 	capabilities := v1.Group("/capabilities")
@@ -1090,14 +1432,66 @@ func setupRoutes(v1 fiber.Router, h *Handlers, services *Services, jwtService *a
 	capabilityRequests := v1.Group("/capability-requests")
 	capabilityRequests.Use(middleware.AuthMiddleware(jwtService))
 	capabilityRequests.Use(middleware.RateLimitMiddleware())
+	capabilityRequests.Use(middleware.ScopedRateLimitMiddleware(rateLimiter))
 
 	// MCP server tag routes (under /mcp-servers/:id/tags)
 	mcpServers.Get("/:id/tags", h.Tag.GetMCPServerTags)
-	mcpServers.Post("/:id/tags", middleware.MemberMiddleware(), h.Tag.AddTagsToMCPServer)
-	mcpServers.Delete("/:id/tags/:tagId", middleware.MemberMiddleware(), h.Tag.RemoveTagFromMCPServer)
+	mcpServers.Post("/:id/tags", h.Tag.AddTagsToMCPServer, middleware.MemberMiddleware())
+	mcpServers.Delete("/:id/tags/:tagId", h.Tag.RemoveTagFromMCPServer, middleware.MemberMiddleware())
 	mcpServers.Get("/:id/tags/suggestions", h.Tag.SuggestTagsForMCPServer)
 }
 
+// readinessHandler serves /health/ready: the database must answer and have every migration in
+// migrationFiles applied. Redis is optional and only reported.
+func readinessHandler(db *sql.DB, redisClient *redis.Client, migrationFiles []string) fiber.Handler {
+	return func(c fiber.Ctx) error {
+		// Check database
+		if err := db.Ping(); err != nil {
+			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{
+				"ready":   false,
+				"error":   "database unavailable",
+				"version": buildVersion,
+			})
+		}
+
+		// Check the schema is current, so traffic is not served against a half-migrated database
+		migrations, err := database.CheckMigrationStatus(c.Context(), db, migrationFiles)
+		if err != nil {
+			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{
+				"ready":   false,
+				"error":   "migration status unavailable",
+				"version": buildVersion,
+			})
+		}
+
+		// Check Redis (optional - skip if not configured)
+		redisStatus := "not configured"
+		if redisClient != nil {
+			ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+			defer cancel()
+			if err := redisClient.Ping(ctx).Err(); err != nil {
+				redisStatus = "unavailable (optional)"
+			} else {
+				redisStatus = "connected"
+			}
+		}
+
+		status := fiber.StatusOK
+		if migrations.State != database.MigrationsUpToDate {
+			status = fiber.StatusServiceUnavailable
+		}
+
+		return c.Status(status).JSON(fiber.Map{
+			"ready":            status == fiber.StatusOK,
+			"database":         "connected",
+			"redis":            redisStatus,
+			"migrations":       migrations.State,
+			"migrationVersion": migrations.LatestApplied,
+			"version":          buildVersion,
+		})
+	}
+}
+
 func customErrorHandler(c fiber.Ctx, err error) error {
 	code := fiber.StatusInternalServerError
 	message := "Internal Server Error"
@@ -1138,6 +1532,23 @@ func runMigrations(db *sql.DB) error {
 		return nil
 	}
 
+	// Read every migration file so edits to already-applied ones can be detected
+	contents := make(map[string][]byte, len(files))
+	checksums := make(map[string]string, len(files))
+	for _, file := range files {
+		content, err := ioutil.ReadFile(filepath.Join("migrations", file))
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", file, err)
+		}
+		contents[file] = content
+		checksums[getMigrationVersion(file)] = database.MigrationChecksum(content)
+	}
+
+	// Refuse to start if an applied migration was edited: the schema would silently diverge
+	if err := database.VerifyMigrationChecksums(context.Background(), db, checksums); err != nil {
+		return err
+	}
+
 	// Get applied migrations from database
 	applied, err := getAppliedMigrations(db)
 	if err != nil {
@@ -1154,12 +1565,7 @@ func runMigrations(db *sql.DB) error {
 		}
 
 		log.Printf("🔄 Applying %s...", file)
-
-		// Read migration file
-		content, err := ioutil.ReadFile(filepath.Join("migrations", file))
-		if err != nil {
-			return fmt.Errorf("failed to read %s: %w", file, err)
-		}
+		content := contents[file]
 
 		// Execute migration in a transaction for safety
 		tx, err := db.Begin()
@@ -1174,7 +1580,7 @@ func runMigrations(db *sql.DB) error {
 		}
 
 		// Record migration
-		if _, err := tx.Exec("INSERT INTO schema_migrations (version) VALUES ($1)", version); err != nil {
+		if _, err := tx.Exec("INSERT INTO schema_migrations (version, checksum) VALUES ($1, $2)", version, checksums[version]); err != nil {
 			tx.Rollback()
 			return fmt.Errorf("failed to record migration %s: %w", version, err)
 		}
@@ -1206,8 +1612,10 @@ func createMigrationsTable(db *sql.DB) error {
 			applied_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
 		)
 	`
-	_, err := db.Exec(query)
-	return err
+	if _, err := db.Exec(query); err != nil {
+		return err
+	}
+	return database.EnsureMigrationChecksumColumn(context.Background(), db)
 }
 
 // getMigrationFiles returns sorted list of .up.sql migration files
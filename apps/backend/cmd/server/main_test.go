@@ -0,0 +1,221 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gofiber/fiber/v3"
+	"github.com/gofiber/fiber/v3/middleware/recover"
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/domain"
+	"github.com/opena2a/identity/backend/internal/infrastructure/auth"
+	"github.com/opena2a/identity/backend/internal/interfaces/http/middleware"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func readinessTestRequest(t *testing.T, appliedVersions ...string) (int, map[string]interface{}) {
+	db, mock, err := sqlmock.New(sqlmock.MonitorPingsOption(true))
+	require.NoError(t, err)
+	defer db.Close()
+
+	rows := sqlmock.NewRows([]string{"version"})
+	for _, version := range appliedVersions {
+		rows.AddRow(version)
+	}
+	mock.ExpectPing()
+	mock.ExpectQuery("SELECT version FROM schema_migrations").WillReturnRows(rows)
+
+	app := fiber.New()
+	app.Get("/health/ready", readinessHandler(db, nil, []string{"001_initial_schema.sql", "002_add_tags.sql"}))
+
+	resp, err := app.Test(httptest.NewRequest("GET", "/health/ready", nil))
+	require.NoError(t, err)
+
+	var body map[string]interface{}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+	assert.NoError(t, mock.ExpectationsWereMet())
+	return resp.StatusCode, body
+}
+
+func TestReadiness_MigrationsUpToDate(t *testing.T) {
+	status, body := readinessTestRequest(t, "001_initial_schema.sql", "002_add_tags.sql")
+
+	assert.Equal(t, fiber.StatusOK, status)
+	assert.Equal(t, true, body["ready"])
+	assert.Equal(t, "up_to_date", body["migrations"])
+	assert.Equal(t, "002_add_tags.sql", body["migrationVersion"])
+	assert.Equal(t, buildVersion, body["version"])
+}
+
+func TestReadiness_MigrationsPending(t *testing.T) {
+	status, body := readinessTestRequest(t, "001_initial_schema.sql")
+
+	assert.Equal(t, fiber.StatusServiceUnavailable, status)
+	assert.Equal(t, false, body["ready"])
+	assert.Equal(t, "pending", body["migrations"])
+	assert.Equal(t, "001_initial_schema.sql", body["migrationVersion"])
+	assert.Equal(t, buildVersion, body["version"])
+}
+
+// routeTestApp registers the API routes with nil handlers and services; requests that get past
+// a route's middleware reach a nil handler and are answered 500 by the recover middleware
+func routeTestApp(t *testing.T) (*fiber.App, *auth.JWTService) {
+	jwtService, err := auth.NewJWTServiceWithConfig(auth.JWTConfig{
+		Secret:          "route-test-secret",
+		AccessTokenTTL:  time.Hour,
+		RefreshTokenTTL: time.Hour,
+	})
+	require.NoError(t, err)
+
+	app := fiber.New()
+	app.Use(recover.New())
+	rateLimiter := middleware.NewRateLimiter(0, 0, time.Minute, nil)
+	idempotency := middleware.IdempotencyMiddleware(middleware.NewMemoryIdempotencyStore(), middleware.DefaultIdempotencyTTL)
+	setupSDKVerificationRoutes(app, &Handlers{}, idempotency)
+	setupRoutes(app.Group("/api/v1"), &Handlers{}, &Services{}, jwtService, middleware.NewSDKTokenTrackingMiddleware(nil), nil, rateLimiter, idempotency)
+	return app, jwtService
+}
+
+func TestRoutes_AgentCompromiseRequiresManager(t *testing.T) {
+	app, jwtService := routeTestApp(t)
+	token, err := jwtService.GenerateAccessToken(uuid.NewString(), uuid.NewString(), "member@example.com", string(domain.RoleMember))
+	require.NoError(t, err)
+
+	for _, action := range []string{"compromise", "uncompromise"} {
+		req := httptest.NewRequest("POST", "/api/v1/agents/"+uuid.NewString()+"/"+action, nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		assert.Equal(t, fiber.StatusForbidden, resp.StatusCode, action)
+	}
+}
+
+func TestRoutes_ManagerChangesRejectMember(t *testing.T) {
+	app, jwtService := routeTestApp(t)
+	token, err := jwtService.GenerateAccessToken(uuid.NewString(), uuid.NewString(), "member@example.com", string(domain.RoleMember))
+	require.NoError(t, err)
+
+	id := uuid.NewString()
+	for _, route := range []struct{ method, path string }{
+		{"DELETE", "/api/v1/agents/" + id},
+		{"POST", "/api/v1/agents/" + id + "/verify"},
+		{"POST", "/api/v1/agents/" + id + "/suspend"},
+		{"POST", "/api/v1/agents/" + id + "/reactivate"},
+		{"PUT", "/api/v1/agents/" + id + "/trust-score"},
+		{"POST", "/api/v1/agents/" + id + "/trust-score/recalculate"},
+		{"POST", "/api/v1/agents/" + id + "/capabilities"},
+		{"DELETE", "/api/v1/agents/" + id + "/capabilities/" + uuid.NewString()},
+		{"POST", "/api/v1/trust-score/calculate/" + id},
+		{"DELETE", "/api/v1/mcp-servers/" + id},
+		{"POST", "/api/v1/mcp-servers/" + id + "/verify"},
+		{"DELETE", "/api/v1/verification-events/" + id},
+		{"DELETE", "/api/v1/tags/" + id},
+	} {
+		req := httptest.NewRequest(route.method, route.path, nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		assert.Equal(t, fiber.StatusForbidden, resp.StatusCode, route.method+" "+route.path)
+	}
+}
+
+func TestRoutes_AgentChangesRequireMember(t *testing.T) {
+	app, jwtService := routeTestApp(t)
+	token, err := jwtService.GenerateAccessToken(uuid.NewString(), uuid.NewString(), "viewer@example.com", string(domain.RoleViewer))
+	require.NoError(t, err)
+
+	id := uuid.NewString()
+	for _, route := range []struct{ method, path string }{
+		{"POST", "/api/v1/agents/"},
+		{"PUT", "/api/v1/agents/" + id},
+		{"POST", "/api/v1/agents/" + id + "/rotate-credentials"},
+		{"PUT", "/api/v1/agents/" + id + "/keys"},
+		{"PUT", "/api/v1/agents/" + id + "/mcp-servers"},
+		{"POST", "/api/v1/agents/" + id + "/tags"},
+		{"POST", "/api/v1/api-keys/"},
+		{"DELETE", "/api/v1/api-keys/" + id},
+		{"POST", "/api/v1/mcp-servers/"},
+		{"PUT", "/api/v1/mcp-servers/" + id},
+		{"POST", "/api/v1/mcp-servers/" + id + "/tags"},
+		{"POST", "/api/v1/verification-events/"},
+		{"POST", "/api/v1/tags/"},
+	} {
+		req := httptest.NewRequest(route.method, route.path, nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		assert.Equal(t, fiber.StatusForbidden, resp.StatusCode, route.method+" "+route.path)
+	}
+}
+
+func TestRoutes_WebhookChangesRequireMember(t *testing.T) {
+	app, jwtService := routeTestApp(t)
+	token, err := jwtService.GenerateAccessToken(uuid.NewString(), uuid.NewString(), "viewer@example.com", string(domain.RoleViewer))
+	require.NoError(t, err)
+
+	webhookID := uuid.NewString()
+	for _, route := range []struct{ method, path string }{
+		{"POST", "/api/v1/webhooks/"},
+		{"PUT", "/api/v1/webhooks/" + webhookID},
+		{"DELETE", "/api/v1/webhooks/" + webhookID},
+		{"POST", "/api/v1/webhooks/" + webhookID + "/test"},
+		{"POST", "/api/v1/webhooks/" + webhookID + "/rotate-secret"},
+	} {
+		req := httptest.NewRequest(route.method, route.path, nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		assert.Equal(t, fiber.StatusForbidden, resp.StatusCode, route.method+" "+route.path)
+	}
+}
+
+func TestRoutes_BulkAgentCreateRequiresMember(t *testing.T) {
+	app, jwtService := routeTestApp(t)
+	token, err := jwtService.GenerateAccessToken(uuid.NewString(), uuid.NewString(), "viewer@example.com", string(domain.RoleViewer))
+	require.NoError(t, err)
+
+	req := httptest.NewRequest("POST", "/api/v1/agents/bulk", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusForbidden, resp.StatusCode)
+}
+
+func TestRoutes_SDKTokenRecoveryIsRateLimited(t *testing.T) {
+	app, _ := routeTestApp(t)
+
+	var lastStatus int
+	for i := 0; i < 11; i++ {
+		resp, err := app.Test(httptest.NewRequest("POST", "/api/v1/auth/sdk/recover", nil))
+		require.NoError(t, err)
+		lastStatus = resp.StatusCode
+	}
+	assert.Equal(t, fiber.StatusTooManyRequests, lastStatus)
+}
+
+func TestRoutes_SDKVerificationLookupsAreRateLimited(t *testing.T) {
+	verificationID := uuid.NewString()
+	for _, route := range []struct{ method, path string }{
+		{"GET", "/api/v1/sdk-api/verifications/" + verificationID},
+		{"POST", "/api/v1/sdk-api/verifications/" + verificationID + "/result"},
+	} {
+		app, _ := routeTestApp(t)
+
+		var lastStatus int
+		for i := 0; i < 101; i++ {
+			resp, err := app.Test(httptest.NewRequest(route.method, route.path, nil))
+			require.NoError(t, err)
+			lastStatus = resp.StatusCode
+		}
+		assert.Equal(t, fiber.StatusTooManyRequests, lastStatus, route.method+" "+route.path)
+	}
+}
@@ -2,6 +2,7 @@ package application
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 
@@ -9,6 +10,24 @@ import (
 	"github.com/opena2a/identity/backend/internal/domain"
 )
 
+// ErrInvalidPasswordPolicy is returned when a password policy update is out of bounds
+var ErrInvalidPasswordPolicy = errors.New("invalid password policy")
+
+// ErrInvalidTrustDecayHalfLife is returned when a trust decay half-life update is out of bounds
+var ErrInvalidTrustDecayHalfLife = errors.New("invalid trust decay half-life")
+
+// ErrInvalidTrustWeights is returned when trust weights are out of bounds or do not add up to 1.0
+var ErrInvalidTrustWeights = errors.New("invalid trust weights")
+
+// ErrInvalidAutoVerifyMinTrust is returned when an auto-verification trust threshold is out of range
+var ErrInvalidAutoVerifyMinTrust = errors.New("invalid auto-verification trust threshold")
+
+// ErrInvalidViolationPenalties is returned when a violation penalty is out of bounds
+var ErrInvalidViolationPenalties = errors.New("invalid violation penalties")
+
+// ErrInvalidEmailBranding is returned when an email logo URL or locale is not valid
+var ErrInvalidEmailBranding = errors.New("invalid email branding")
+
 // AdminService handles administrative operations
 type AdminService struct {
 	userRepo domain.UserRepository
@@ -52,7 +71,7 @@ func (s *AdminService) ApproveUser(ctx context.Context, userID, adminID uuid.UUI
 	user.ApprovedBy = &adminID
 	user.ApprovedAt = &now
 
-	if err := s.userRepo.Update(user); err != nil {
+	if err := s.userRepo.UpdateWithinQuota(user); err != nil {
 		return fmt.Errorf("failed to approve user: %w", err)
 	}
 
@@ -137,7 +156,7 @@ func (s *AdminService) ActivateUser(ctx context.Context, userID, adminID uuid.UU
 	}
 	user.UpdatedAt = now
 
-	if err := s.userRepo.Update(user); err != nil {
+	if err := s.userRepo.UpdateWithinQuota(user); err != nil {
 		return fmt.Errorf("failed to activate user: %w", err)
 	}
 
@@ -197,3 +216,140 @@ func (s *AdminService) PermanentlyDeleteUser(ctx context.Context, userID, adminI
 func (s *AdminService) GetOrganizationSettings(ctx context.Context, orgID uuid.UUID) (*domain.Organization, error) {
 	return s.orgRepo.GetByID(orgID)
 }
+
+// UpdatePasswordPolicy replaces the organization's password policy
+func (s *AdminService) UpdatePasswordPolicy(ctx context.Context, orgID uuid.UUID, policy domain.PasswordPolicy) (*domain.Organization, error) {
+	if err := policy.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidPasswordPolicy, err)
+	}
+
+	org, err := s.orgRepo.GetByID(orgID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get organization: %w", err)
+	}
+
+	org.PasswordPolicy = &policy
+	if err := s.orgRepo.Update(org); err != nil {
+		return nil, fmt.Errorf("failed to update password policy: %w", err)
+	}
+
+	return org, nil
+}
+
+// UpdateTrustDecayHalfLife sets how many days of inactivity halve an agent's activity-derived trust (0 disables decay)
+func (s *AdminService) UpdateTrustDecayHalfLife(ctx context.Context, orgID uuid.UUID, days int) (*domain.Organization, error) {
+	if err := domain.ValidateTrustDecayHalfLifeDays(days); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidTrustDecayHalfLife, err)
+	}
+
+	org, err := s.orgRepo.GetByID(orgID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get organization: %w", err)
+	}
+
+	org.TrustDecayHalfLifeDays = days
+	if err := s.orgRepo.Update(org); err != nil {
+		return nil, fmt.Errorf("failed to update trust decay half-life: %w", err)
+	}
+
+	return org, nil
+}
+
+// UpdateAutoVerify sets whether newly created agents are verified automatically and the trust
+// score (0-1) they need for it. Agents created earlier keep their status.
+func (s *AdminService) UpdateAutoVerify(ctx context.Context, orgID uuid.UUID, enabled bool, minTrust float64) (*domain.Organization, error) {
+	if err := domain.ValidateAutoVerifyMinTrust(minTrust); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidAutoVerifyMinTrust, err)
+	}
+
+	org, err := s.orgRepo.GetByID(orgID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get organization: %w", err)
+	}
+
+	org.AutoVerifyEnabled = enabled
+	org.AutoVerifyMinTrust = minTrust
+	if err := s.orgRepo.Update(org); err != nil {
+		return nil, fmt.Errorf("failed to update auto-verification settings: %w", err)
+	}
+
+	return org, nil
+}
+
+// UpdateCapabilityApproval sets whether capabilities declared by newly registered agents need admin
+// approval instead of being auto-granted. Agents registered earlier keep their capabilities.
+func (s *AdminService) UpdateCapabilityApproval(ctx context.Context, orgID uuid.UUID, required bool) (*domain.Organization, error) {
+	org, err := s.orgRepo.GetByID(orgID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get organization: %w", err)
+	}
+
+	org.RequireCapabilityApproval = required
+	if err := s.orgRepo.Update(org); err != nil {
+		return nil, fmt.Errorf("failed to update capability approval setting: %w", err)
+	}
+
+	return org, nil
+}
+
+// UpdateEmailBranding sets the logo shown in the organization's emails and the locale of emails
+// that are not sent in response to a request carrying the recipient's language. Empty values
+// remove the logo and use the default locale.
+func (s *AdminService) UpdateEmailBranding(ctx context.Context, orgID uuid.UUID, logoURL, locale string) (*domain.Organization, error) {
+	if err := domain.ValidateEmailBranding(logoURL, locale); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidEmailBranding, err)
+	}
+
+	org, err := s.orgRepo.GetByID(orgID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get organization: %w", err)
+	}
+
+	org.LogoURL = logoURL
+	org.EmailLocale = locale
+	if err := s.orgRepo.Update(org); err != nil {
+		return nil, fmt.Errorf("failed to update email branding: %w", err)
+	}
+
+	return org, nil
+}
+
+// UpdateTrustWeights replaces the organization's trust score category weights. Scores calculated from
+// now on use them; existing scores change once they are recalculated.
+func (s *AdminService) UpdateTrustWeights(ctx context.Context, orgID uuid.UUID, weights domain.TrustWeights) (*domain.Organization, error) {
+	if err := weights.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidTrustWeights, err)
+	}
+
+	org, err := s.orgRepo.GetByID(orgID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get organization: %w", err)
+	}
+
+	org.TrustWeights = &weights
+	if err := s.orgRepo.Update(org); err != nil {
+		return nil, fmt.Errorf("failed to update trust weights: %w", err)
+	}
+
+	return org, nil
+}
+
+// UpdateViolationPenalties replaces the organization's trust score penalties for capability
+// violations. They apply to violations recorded from now on.
+func (s *AdminService) UpdateViolationPenalties(ctx context.Context, orgID uuid.UUID, penalties domain.ViolationPenalties) (*domain.Organization, error) {
+	if err := penalties.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidViolationPenalties, err)
+	}
+
+	org, err := s.orgRepo.GetByID(orgID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get organization: %w", err)
+	}
+
+	org.ViolationPenalties = &penalties
+	if err := s.orgRepo.Update(org); err != nil {
+		return nil, fmt.Errorf("failed to update violation penalties: %w", err)
+	}
+
+	return org, nil
+}
@@ -0,0 +1,214 @@
+package application
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAdminService_UpdatePasswordPolicy(t *testing.T) {
+	mockOrgRepo := new(MockOrganizationRepository)
+	service := NewAdminService(nil, mockOrgRepo)
+
+	orgID := uuid.New()
+	policy := domain.PasswordPolicy{MinLength: 14, RequireNumber: true, DisallowCommonPasswords: true}
+
+	mockOrgRepo.On("GetByID", orgID).Return(&domain.Organization{ID: orgID}, nil)
+	mockOrgRepo.On("Update", mock.MatchedBy(func(org *domain.Organization) bool {
+		return org.PasswordPolicy != nil && *org.PasswordPolicy == policy
+	})).Return(nil)
+
+	org, err := service.UpdatePasswordPolicy(context.Background(), orgID, policy)
+	require.NoError(t, err)
+	assert.Equal(t, policy, org.EffectivePasswordPolicy())
+	mockOrgRepo.AssertExpectations(t)
+}
+
+func TestAdminService_UpdatePasswordPolicy_RejectsShortMinimum(t *testing.T) {
+	mockOrgRepo := new(MockOrganizationRepository)
+	service := NewAdminService(nil, mockOrgRepo)
+
+	_, err := service.UpdatePasswordPolicy(context.Background(), uuid.New(), domain.PasswordPolicy{MinLength: 6})
+	assert.ErrorIs(t, err, ErrInvalidPasswordPolicy)
+	mockOrgRepo.AssertNotCalled(t, "Update", mock.Anything)
+}
+
+func TestAdminService_UpdateTrustDecayHalfLife(t *testing.T) {
+	mockOrgRepo := new(MockOrganizationRepository)
+	service := NewAdminService(nil, mockOrgRepo)
+
+	orgID := uuid.New()
+	mockOrgRepo.On("GetByID", orgID).Return(&domain.Organization{ID: orgID, TrustDecayHalfLifeDays: 90}, nil)
+	mockOrgRepo.On("Update", mock.MatchedBy(func(org *domain.Organization) bool {
+		return org.TrustDecayHalfLifeDays == 30
+	})).Return(nil)
+
+	org, err := service.UpdateTrustDecayHalfLife(context.Background(), orgID, 30)
+	require.NoError(t, err)
+	assert.Equal(t, 30, org.TrustDecayHalfLifeDays)
+	mockOrgRepo.AssertExpectations(t)
+}
+
+func TestAdminService_UpdateTrustDecayHalfLife_RejectsOutOfRange(t *testing.T) {
+	mockOrgRepo := new(MockOrganizationRepository)
+	service := NewAdminService(nil, mockOrgRepo)
+
+	_, err := service.UpdateTrustDecayHalfLife(context.Background(), uuid.New(), 3)
+	assert.ErrorIs(t, err, ErrInvalidTrustDecayHalfLife)
+	mockOrgRepo.AssertNotCalled(t, "Update", mock.Anything)
+}
+
+func TestAdminService_UpdateEmailBranding(t *testing.T) {
+	mockOrgRepo := new(MockOrganizationRepository)
+	service := NewAdminService(nil, mockOrgRepo)
+
+	orgID := uuid.New()
+	mockOrgRepo.On("GetByID", orgID).Return(&domain.Organization{ID: orgID}, nil)
+	mockOrgRepo.On("Update", mock.MatchedBy(func(org *domain.Organization) bool {
+		return org.LogoURL == "https://cdn.example.com/acme.png" && org.EmailLocale == "es-MX"
+	})).Return(nil)
+
+	_, err := service.UpdateEmailBranding(context.Background(), orgID, "https://cdn.example.com/acme.png", "es-MX")
+	require.NoError(t, err)
+	mockOrgRepo.AssertExpectations(t)
+}
+
+func TestAdminService_UpdateEmailBranding_RejectsInvalidValues(t *testing.T) {
+	for name, values := range map[string][2]string{
+		"http logo":     {"http://cdn.example.com/acme.png", ""},
+		"relative logo": {"/acme.png", ""},
+		"bad locale":    {"", "es MX"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			mockOrgRepo := new(MockOrganizationRepository)
+			service := NewAdminService(nil, mockOrgRepo)
+
+			_, err := service.UpdateEmailBranding(context.Background(), uuid.New(), values[0], values[1])
+			assert.ErrorIs(t, err, ErrInvalidEmailBranding)
+			mockOrgRepo.AssertNotCalled(t, "Update", mock.Anything)
+		})
+	}
+}
+
+func TestOrganization_EffectivePasswordPolicy_DefaultsWhenUnset(t *testing.T) {
+	assert.Equal(t, domain.DefaultPasswordPolicy(), (&domain.Organization{}).EffectivePasswordPolicy())
+}
+
+func TestAdminService_UpdateTrustWeights(t *testing.T) {
+	mockOrgRepo := new(MockOrganizationRepository)
+	service := NewAdminService(nil, mockOrgRepo)
+
+	orgID := uuid.New()
+	weights := domain.TrustWeights{Verification: 0.4, Violations: 0.3, KeyAge: 0.1, MCP: 0.1, Alerts: 0.1}
+	mockOrgRepo.On("GetByID", orgID).Return(&domain.Organization{ID: orgID}, nil)
+	mockOrgRepo.On("Update", mock.MatchedBy(func(org *domain.Organization) bool {
+		return org.TrustWeights != nil && *org.TrustWeights == weights
+	})).Return(nil)
+
+	org, err := service.UpdateTrustWeights(context.Background(), orgID, weights)
+	require.NoError(t, err)
+	assert.Equal(t, weights, org.EffectiveTrustWeights())
+	mockOrgRepo.AssertExpectations(t)
+}
+
+func TestAdminService_UpdateTrustWeights_RejectsInvalidWeights(t *testing.T) {
+	tests := []struct {
+		name    string
+		weights domain.TrustWeights
+	}{
+		{"does not add up to 1", domain.TrustWeights{Verification: 0.5, Alerts: 0.4}},
+		{"negative weight", domain.TrustWeights{Verification: 1.2, Alerts: -0.2}},
+		{"all zero", domain.TrustWeights{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockOrgRepo := new(MockOrganizationRepository)
+			service := NewAdminService(nil, mockOrgRepo)
+
+			_, err := service.UpdateTrustWeights(context.Background(), uuid.New(), tt.weights)
+			assert.ErrorIs(t, err, ErrInvalidTrustWeights)
+			mockOrgRepo.AssertNotCalled(t, "Update", mock.Anything)
+		})
+	}
+}
+
+func TestAdminService_UpdateCapabilityApproval(t *testing.T) {
+	mockOrgRepo := new(MockOrganizationRepository)
+	service := NewAdminService(nil, mockOrgRepo)
+
+	orgID := uuid.New()
+	mockOrgRepo.On("GetByID", orgID).Return(&domain.Organization{ID: orgID}, nil)
+	mockOrgRepo.On("Update", mock.MatchedBy(func(org *domain.Organization) bool {
+		return org.RequireCapabilityApproval
+	})).Return(nil)
+
+	org, err := service.UpdateCapabilityApproval(context.Background(), orgID, true)
+	require.NoError(t, err)
+	assert.True(t, org.RequireCapabilityApproval)
+	mockOrgRepo.AssertExpectations(t)
+}
+
+func TestAdminService_UpdateAutoVerify(t *testing.T) {
+	mockOrgRepo := new(MockOrganizationRepository)
+	service := NewAdminService(nil, mockOrgRepo)
+
+	orgID := uuid.New()
+	mockOrgRepo.On("GetByID", orgID).Return(&domain.Organization{ID: orgID, AutoVerifyEnabled: true}, nil)
+	mockOrgRepo.On("Update", mock.MatchedBy(func(org *domain.Organization) bool {
+		return !org.AutoVerifyEnabled && org.AutoVerifyMinTrust == 0.7
+	})).Return(nil)
+
+	org, err := service.UpdateAutoVerify(context.Background(), orgID, false, 0.7)
+	require.NoError(t, err)
+	assert.False(t, org.AutoVerifyEnabled)
+	assert.Equal(t, 0.7, org.AutoVerifyMinTrust)
+	mockOrgRepo.AssertExpectations(t)
+}
+
+func TestAdminService_UpdateAutoVerify_RejectsThresholdOutOfRange(t *testing.T) {
+	for _, minTrust := range []float64{-0.1, 1.1, math.NaN()} {
+		mockOrgRepo := new(MockOrganizationRepository)
+		service := NewAdminService(nil, mockOrgRepo)
+
+		_, err := service.UpdateAutoVerify(context.Background(), uuid.New(), true, minTrust)
+		assert.ErrorIs(t, err, ErrInvalidAutoVerifyMinTrust)
+		mockOrgRepo.AssertNotCalled(t, "Update", mock.Anything)
+	}
+}
+
+func TestAdminService_UpdateViolationPenalties(t *testing.T) {
+	mockOrgRepo := new(MockOrganizationRepository)
+	service := NewAdminService(nil, mockOrgRepo)
+
+	orgID := uuid.New()
+	penalties := domain.DefaultViolationPenalties()
+	penalties.Blocked.Critical = 40
+	mockOrgRepo.On("GetByID", orgID).Return(&domain.Organization{ID: orgID}, nil)
+	mockOrgRepo.On("Update", mock.MatchedBy(func(org *domain.Organization) bool {
+		return org.ViolationPenalties != nil && org.ViolationPenalties.Blocked.Critical == 40
+	})).Return(nil)
+
+	org, err := service.UpdateViolationPenalties(context.Background(), orgID, penalties)
+	require.NoError(t, err)
+	assert.Equal(t, -40, org.EffectiveViolationPenalties().TrustScoreImpact(domain.ViolationSeverityCritical, true))
+	mockOrgRepo.AssertExpectations(t)
+}
+
+func TestAdminService_UpdateViolationPenalties_RejectsOutOfRange(t *testing.T) {
+	mockOrgRepo := new(MockOrganizationRepository)
+	service := NewAdminService(nil, mockOrgRepo)
+
+	penalties := domain.DefaultViolationPenalties()
+	penalties.Alert.Low = -5
+	_, err := service.UpdateViolationPenalties(context.Background(), uuid.New(), penalties)
+	assert.ErrorIs(t, err, ErrInvalidViolationPenalties)
+	assert.ErrorContains(t, err, "alert.low")
+	mockOrgRepo.AssertNotCalled(t, "Update", mock.Anything)
+}
@@ -0,0 +1,27 @@
+package application
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/infrastructure/logging"
+)
+
+// agentActivityRecorder bumps an agent's last_active timestamp. domain.AgentRepository and
+// *repository.AgentRepository implement it.
+type agentActivityRecorder interface {
+	UpdateLastActive(ctx context.Context, agentID uuid.UUID) error
+}
+
+// recordAgentActivity marks the agent as active now. Every place an agent proves it is alive
+// (verifying an action, reporting a verification event, attesting an MCP server) goes through
+// here so last_active means the same thing everywhere. Failures are logged, never returned:
+// activity tracking must not fail the operation that triggered it.
+func recordAgentActivity(ctx context.Context, recorder agentActivityRecorder, agentID uuid.UUID) {
+	if recorder == nil {
+		return
+	}
+	if err := recorder.UpdateLastActive(ctx, agentID); err != nil {
+		logging.FromContext(ctx).Warn("failed to update agent last_active", "agent_id", agentID, "error", err)
+	}
+}
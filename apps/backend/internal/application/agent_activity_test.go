@@ -0,0 +1,170 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/domain"
+	"github.com/opena2a/identity/backend/internal/infrastructure/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func newActivityTestService(agentID uuid.UUID) (*VerificationEventService, *MockAgentRepository) {
+	mockEventRepo := new(MockVerificationEventRepository)
+	mockEventRepo.On("Create", mock.AnythingOfType("*domain.VerificationEvent")).Return(nil)
+	mockAgentRepo := new(MockAgentRepository)
+	mockAgentRepo.On("GetByID", agentID).Return(&domain.Agent{ID: agentID, DisplayName: "Agent", TrustScore: 80}, nil)
+
+	return NewVerificationEventService(mockEventRepo, mockAgentRepo, nil), mockAgentRepo
+}
+
+func TestVerificationEventService_LogVerificationEvent_UpdatesLastActive(t *testing.T) {
+	agentID := uuid.New()
+	service, mockAgentRepo := newActivityTestService(agentID)
+	mockAgentRepo.On("UpdateLastActive", mock.Anything, agentID).Return(nil).Once()
+
+	_, err := service.LogVerificationEvent(
+		context.Background(), uuid.New(), agentID,
+		domain.VerificationProtocolA2A, domain.VerificationTypeCapability, domain.VerificationEventStatusSuccess,
+		12, domain.InitiatorTypeAgent, nil, nil,
+	)
+	require.NoError(t, err)
+	mockAgentRepo.AssertExpectations(t)
+}
+
+func TestVerificationEventService_CreateVerificationEvent_UpdatesLastActive(t *testing.T) {
+	agentID := uuid.New()
+	service, mockAgentRepo := newActivityTestService(agentID)
+	mockAgentRepo.On("UpdateLastActive", mock.Anything, agentID).Return(nil).Once()
+
+	_, err := service.CreateVerificationEvent(context.Background(), &CreateVerificationEventRequest{
+		OrganizationID:   uuid.New(),
+		AgentID:          agentID,
+		Protocol:         domain.VerificationProtocolA2A,
+		VerificationType: domain.VerificationTypeIdentity,
+		Status:           domain.VerificationEventStatusSuccess,
+		InitiatorType:    domain.InitiatorTypeAgent,
+	})
+	require.NoError(t, err)
+	mockAgentRepo.AssertExpectations(t)
+}
+
+func TestVerificationEventService_CreateVerificationEvent_SystemEventKeepsLastActive(t *testing.T) {
+	agentID := uuid.New()
+	service, mockAgentRepo := newActivityTestService(agentID)
+
+	_, err := service.CreateVerificationEvent(context.Background(), &CreateVerificationEventRequest{
+		OrganizationID:   uuid.New(),
+		AgentID:          agentID,
+		Protocol:         domain.VerificationProtocolA2A,
+		VerificationType: domain.VerificationTypeIdentity,
+		Status:           domain.VerificationEventStatusSuccess,
+		InitiatorType:    domain.InitiatorTypeSystem,
+	})
+	require.NoError(t, err)
+	mockAgentRepo.AssertNotCalled(t, "UpdateLastActive", mock.Anything, mock.Anything)
+}
+
+func TestVerificationEventService_LastActiveFailureDoesNotFailEvent(t *testing.T) {
+	agentID := uuid.New()
+	service, mockAgentRepo := newActivityTestService(agentID)
+	mockAgentRepo.On("UpdateLastActive", mock.Anything, agentID).Return(errors.New("database unavailable"))
+
+	event, err := service.LogVerificationEvent(
+		context.Background(), uuid.New(), agentID,
+		domain.VerificationProtocolA2A, domain.VerificationTypeCapability, domain.VerificationEventStatusFailed,
+		0, domain.InitiatorTypeAgent, nil, nil,
+	)
+	require.NoError(t, err)
+	assert.NotNil(t, event)
+}
+
+func TestMCPAttestationService_VerifyAndRecordAttestation_UpdatesLastActive(t *testing.T) {
+	now := time.Now().UTC()
+	service, sqlMock, agent, server, req := newSQLAttestationService(t, now)
+
+	sqlMock.ExpectQuery("INSERT INTO mcp_attestations").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}).AddRow(uuid.New(), now))
+	sqlMock.ExpectExec(regexp.QuoteMeta("SET last_active = NOW()")).WithArgs(agent.ID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	sqlMock.ExpectQuery("FROM mcp_attestations").WithArgs(server.ID).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	sqlMock.ExpectQuery("FROM agent_mcp_connections").WithArgs(agent.ID, server.ID).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	sqlMock.ExpectQuery("INSERT INTO agent_mcp_connections").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "created_at", "updated_at"}).AddRow(uuid.New(), now, now))
+
+	resp, err := service.VerifyAndRecordAttestation(context.Background(), server.ID, req)
+	require.NoError(t, err)
+	assert.True(t, resp.Success)
+	assert.NoError(t, sqlMock.ExpectationsWereMet())
+}
+
+func TestMCPAttestationService_VerifyAndRecordAttestation_RejectsRecordedSignature(t *testing.T) {
+	now := time.Now().UTC()
+	service, sqlMock, _, server, req := newSQLAttestationService(t, now)
+
+	// The unique signature index turns the insert into a no-op
+	sqlMock.ExpectQuery(regexp.QuoteMeta("ON CONFLICT (signature) WHERE agent_id IS NOT NULL DO NOTHING")).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}))
+
+	_, err := service.VerifyAndRecordAttestation(context.Background(), server.ID, req)
+	assert.ErrorIs(t, err, ErrAttestationReplayed)
+	assert.NoError(t, sqlMock.ExpectationsWereMet())
+}
+
+// newSQLAttestationService returns an attestation service backed by sqlmock that expects the
+// agent and MCP server lookups of a signed attestation request
+func newSQLAttestationService(t *testing.T, now time.Time) (*MCPAttestationService, sqlmock.Sqlmock, *domain.Agent, *domain.MCPServer, *AttestMCPRequest) {
+	t.Helper()
+	fixture, agent, server, key, payload := newAttestationFixture(t, now)
+	req := signAttestation(t, key, payload)
+
+	db, sqlMock, err := sqlmock.New()
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	agentRepo := repository.NewAgentRepository(db)
+	service := &MCPAttestationService{
+		attestationRepo: repository.NewMCPAttestationRepository(db),
+		agentRepo:       agentRepo,
+		mcpRepo:         repository.NewMCPServerRepository(db),
+		cryptoService:   fixture.cryptoService,
+		activity:        agentRepo,
+	}
+
+	sqlMock.ExpectQuery("FROM agents").WithArgs(agent.ID).WillReturnRows(sqlmock.NewRows([]string{
+		"id", "organization_id", "name", "display_name", "description", "agent_type", "status", "version",
+		"public_key", "encrypted_private_key", "key_algorithm", "certificate_url", "repository_url", "documentation_url",
+		"trust_score", "verified_at", "talks_to", "capabilities", "labels", "created_at", "updated_at", "created_by", "last_active",
+		"deleted_at", "is_compromised", "compromised_at", "compromised_by", "compromise_reason",
+		"oauth_client_id",
+	}).AddRow(
+		agent.ID, uuid.New(), "agent", "Agent", "", "ai_agent", domain.AgentStatusVerified, "1.0.0",
+		*agent.PublicKey, nil, "ed25519", nil, nil, nil,
+		80.0, now, []byte("[]"), []byte("[]"), []byte("{}"), now, now, uuid.New(), nil,
+		nil, false, nil, nil, nil,
+		"",
+	))
+	sqlMock.ExpectQuery("FROM mcp_servers").WithArgs(server.ID).WillReturnRows(sqlmock.NewRows([]string{
+		"id", "organization_id", "name", "description", "url", "version",
+		"public_key", "status", "is_verified", "last_verified_at", "verification_url",
+		"capabilities", "trust_score", "registered_by_agent", "created_by", "created_at", "updated_at",
+		"verification_method", "attestation_count", "confidence_score", "last_attested_at",
+		"last_health_check", "health_status",
+	}).AddRow(
+		server.ID, uuid.New(), "filesystem", "", server.URL, "1.0.0",
+		"", "verified", true, nil, "",
+		[]byte("[]"), 0.0, nil, uuid.New(), now, now,
+		"agent_attestation", 0, 0.0, nil,
+		nil, "unknown",
+	))
+	return service, sqlMock, agent, server, req
+}
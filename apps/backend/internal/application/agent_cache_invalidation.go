@@ -0,0 +1,157 @@
+package application
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/domain"
+)
+
+// AgentCacheInvalidator drops cached authorization data for an agent, so its next verification
+// sees its current status and grants. Services call it right after mutations that take access
+// away, such as revoking a capability or suspending the agent. *AgentLookupCache and
+// *VerificationDecisionCache implement it.
+type AgentCacheInvalidator interface {
+	InvalidateAgent(ctx context.Context, agentID uuid.UUID)
+}
+
+// AgentCacheInvalidators invalidates every cache in the list
+type AgentCacheInvalidators []AgentCacheInvalidator
+
+// InvalidateAgent invalidates agentID in every cache
+func (l AgentCacheInvalidators) InvalidateAgent(ctx context.Context, agentID uuid.UUID) {
+	for _, invalidator := range l {
+		invalidator.InvalidateAgent(ctx, agentID)
+	}
+}
+
+// NewInvalidatingAgentRepository wraps repo so agent writes invalidate the agent
+func NewInvalidatingAgentRepository(repo domain.AgentRepository, invalidator AgentCacheInvalidator) domain.AgentRepository {
+	return &invalidatingAgentRepository{AgentRepository: repo, invalidator: invalidator}
+}
+
+// NewInvalidatingCapabilityRepository wraps repo so capability grants, revocations and deletions
+// invalidate the agent they belong to
+func NewInvalidatingCapabilityRepository(repo domain.CapabilityRepository, invalidator AgentCacheInvalidator) domain.CapabilityRepository {
+	return &invalidatingCapabilityRepository{CapabilityRepository: repo, invalidator: invalidator}
+}
+
+// NewInvalidatingUserRepository wraps repo so the agents suspended by a user deactivation are invalidated
+func NewInvalidatingUserRepository(repo domain.UserRepository, invalidator AgentCacheInvalidator) domain.UserRepository {
+	return &invalidatingUserRepository{UserRepository: repo, invalidator: invalidator}
+}
+
+// invalidatingAgentRepository invalidates the agent after every write except UpdateLastActive,
+// which VerifyAction itself triggers and which does not affect its decision
+type invalidatingAgentRepository struct {
+	domain.AgentRepository
+	invalidator AgentCacheInvalidator
+}
+
+func (r *invalidatingAgentRepository) Update(agent *domain.Agent) error {
+	err := r.AgentRepository.Update(agent)
+	r.invalidator.InvalidateAgent(context.Background(), agent.ID)
+	return err
+}
+
+func (r *invalidatingAgentRepository) SoftDelete(id uuid.UUID) error {
+	err := r.AgentRepository.SoftDelete(id)
+	r.invalidator.InvalidateAgent(context.Background(), id)
+	return err
+}
+
+func (r *invalidatingAgentRepository) Delete(id uuid.UUID) error {
+	err := r.AgentRepository.Delete(id)
+	r.invalidator.InvalidateAgent(context.Background(), id)
+	return err
+}
+
+func (r *invalidatingAgentRepository) UpdateTrustScore(id uuid.UUID, newScore float64) error {
+	err := r.AgentRepository.UpdateTrustScore(id, newScore)
+	r.invalidator.InvalidateAgent(context.Background(), id)
+	return err
+}
+
+func (r *invalidatingAgentRepository) UpdateLabels(id uuid.UUID, labels map[string]string) error {
+	err := r.AgentRepository.UpdateLabels(id, labels)
+	r.invalidator.InvalidateAgent(context.Background(), id)
+	return err
+}
+
+func (r *invalidatingAgentRepository) UpdateOAuthClientID(id uuid.UUID, clientID string) error {
+	err := r.AgentRepository.UpdateOAuthClientID(id, clientID)
+	r.invalidator.InvalidateAgent(context.Background(), id)
+	return err
+}
+
+func (r *invalidatingAgentRepository) MarkAsCompromised(id uuid.UUID) error {
+	err := r.AgentRepository.MarkAsCompromised(id)
+	r.invalidator.InvalidateAgent(context.Background(), id)
+	return err
+}
+
+func (r *invalidatingAgentRepository) MarkCompromisedBy(id, userID uuid.UUID, reason string, at time.Time, messages ...*domain.OutboxMessage) error {
+	err := r.AgentRepository.MarkCompromisedBy(id, userID, reason, at, messages...)
+	r.invalidator.InvalidateAgent(context.Background(), id)
+	return err
+}
+
+func (r *invalidatingAgentRepository) ClearCompromised(id uuid.UUID, messages ...*domain.OutboxMessage) error {
+	err := r.AgentRepository.ClearCompromised(id, messages...)
+	r.invalidator.InvalidateAgent(context.Background(), id)
+	return err
+}
+
+func (r *invalidatingAgentRepository) UpdateStatus(agent *domain.Agent, messages ...*domain.OutboxMessage) error {
+	err := r.AgentRepository.UpdateStatus(agent, messages...)
+	r.invalidator.InvalidateAgent(context.Background(), agent.ID)
+	return err
+}
+
+// invalidatingCapabilityRepository invalidates the owning agent after capability writes
+type invalidatingCapabilityRepository struct {
+	domain.CapabilityRepository
+	invalidator AgentCacheInvalidator
+}
+
+func (r *invalidatingCapabilityRepository) CreateCapability(capability *domain.AgentCapability) error {
+	err := r.CapabilityRepository.CreateCapability(capability)
+	r.invalidator.InvalidateAgent(context.Background(), capability.AgentID)
+	return err
+}
+
+func (r *invalidatingCapabilityRepository) RevokeCapability(id uuid.UUID, revokedAt time.Time) error {
+	return r.invalidateOwner(id, func() error { return r.CapabilityRepository.RevokeCapability(id, revokedAt) })
+}
+
+func (r *invalidatingCapabilityRepository) DeleteCapability(id uuid.UUID) error {
+	return r.invalidateOwner(id, func() error { return r.CapabilityRepository.DeleteCapability(id) })
+}
+
+// invalidateOwner runs write and then invalidates the agent that owns capability id. If the
+// capability cannot be loaded there is no agent to invalidate.
+func (r *invalidatingCapabilityRepository) invalidateOwner(id uuid.UUID, write func() error) error {
+	capability, lookupErr := r.CapabilityRepository.GetCapabilityByID(id)
+	err := write()
+	if lookupErr == nil && capability != nil {
+		r.invalidator.InvalidateAgent(context.Background(), capability.AgentID)
+	}
+	return err
+}
+
+// invalidatingUserRepository invalidates the agents suspended with their creator
+type invalidatingUserRepository struct {
+	domain.UserRepository
+	invalidator AgentCacheInvalidator
+}
+
+func (r *invalidatingUserRepository) DeactivateWithCascade(userID uuid.UUID, at time.Time) (*domain.UserDeactivationCascade, error) {
+	cascade, err := r.UserRepository.DeactivateWithCascade(userID, at)
+	if cascade != nil {
+		for _, agentID := range cascade.SuspendedAgentIDs {
+			r.invalidator.InvalidateAgent(context.Background(), agentID)
+		}
+	}
+	return cascade, err
+}
@@ -0,0 +1,143 @@
+package application
+
+import (
+	"context"
+	"os"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/domain"
+	"github.com/opena2a/identity/backend/internal/infrastructure/logging"
+)
+
+// DefaultAgentLookupCacheTTL is how long VerifyAction reuses a cached agent record and its active
+// capabilities; override with AGENT_LOOKUP_CACHE_TTL (Go duration)
+const DefaultAgentLookupCacheTTL = 30 * time.Second
+
+// Key prefixes for VerifyAction lookups in the cache store
+const (
+	agentLookupAgentPrefix        = "verify_action:agent:"
+	agentLookupCapabilitiesPrefix = "verify_action:capabilities:"
+	agentLookupCatalogPrefix      = "verify_action:catalog:"
+)
+
+// AgentLookupCacheTTLFromEnv reads AGENT_LOOKUP_CACHE_TTL, falling back to the default
+func AgentLookupCacheTTLFromEnv() time.Duration {
+	if value, err := time.ParseDuration(os.Getenv("AGENT_LOOKUP_CACHE_TTL")); err == nil && value > 0 {
+		return value
+	}
+	return DefaultAgentLookupCacheTTL
+}
+
+// AgentLookupStore stores JSON-encoded values with a TTL.
+// *cache.RedisCache implements it so invalidations reach every server instance.
+type AgentLookupStore interface {
+	Get(ctx context.Context, key string, dest interface{}) error
+	Set(ctx context.Context, key string, value interface{}, ttl time.Duration) error
+	Delete(ctx context.Context, key string) error
+}
+
+// AgentLookupCache caches the agent record and active capabilities VerifyAction reads on every
+// call. Entries are dropped whenever the agent, its capabilities or its creator's account change
+// through the invalidating repositories (see NewInvalidatingAgentRepository), so a grant applies
+// to the very next verification. Writes that bypass those repositories are picked up once the
+// TTL expires.
+//
+// A nil *AgentLookupCache is valid and reads straight from the repositories.
+type AgentLookupCache struct {
+	store AgentLookupStore
+	ttl   time.Duration
+}
+
+// NewAgentLookupCache creates a lookup cache. It returns nil, which disables caching, when store
+// is nil; ttl <= 0 uses the default.
+func NewAgentLookupCache(store AgentLookupStore, ttl time.Duration) *AgentLookupCache {
+	if store == nil {
+		return nil
+	}
+	if ttl <= 0 {
+		ttl = DefaultAgentLookupCacheTTL
+	}
+	return &AgentLookupCache{store: store, ttl: ttl}
+}
+
+// Agent returns the agent from the cache, loading it from repo on a miss. The cached copy is
+// JSON-encoded, so fields hidden from JSON (such as key material) are not set on a hit.
+func (c *AgentLookupCache) Agent(ctx context.Context, repo domain.AgentRepository, agentID uuid.UUID) (*domain.Agent, error) {
+	if c == nil {
+		return repo.GetByID(agentID)
+	}
+
+	key := agentLookupAgentPrefix + agentID.String()
+	var cached domain.Agent
+	if err := c.store.Get(ctx, key, &cached); err == nil {
+		return &cached, nil
+	}
+
+	agent, err := repo.GetByID(agentID)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.store.Set(ctx, key, agent, c.ttl); err != nil {
+		logging.FromContext(ctx).Debug("failed to cache agent", "agent_id", agentID, "error", err)
+	}
+	return agent, nil
+}
+
+// ActiveCapabilities returns the agent's active capabilities from the cache, loading them from
+// repo on a miss. Callers still check per-grant expiry, since a cached grant may expire.
+func (c *AgentLookupCache) ActiveCapabilities(ctx context.Context, repo domain.CapabilityRepository, agentID uuid.UUID) ([]*domain.AgentCapability, error) {
+	if c == nil {
+		return repo.GetActiveCapabilitiesByAgentID(agentID)
+	}
+
+	key := agentLookupCapabilitiesPrefix + agentID.String()
+	var cached []*domain.AgentCapability
+	if err := c.store.Get(ctx, key, &cached); err == nil {
+		return cached, nil
+	}
+
+	capabilities, err := repo.GetActiveCapabilitiesByAgentID(agentID)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.store.Set(ctx, key, capabilities, c.ttl); err != nil {
+		logging.FromContext(ctx).Debug("failed to cache agent capabilities", "agent_id", agentID, "error", err)
+	}
+	return capabilities, nil
+}
+
+// CapabilityCatalog returns the organization's capability catalog (or the default catalog) from
+// the cache, loading it from repo on a miss. Catalog edits are picked up once the TTL expires.
+func (c *AgentLookupCache) CapabilityCatalog(ctx context.Context, repo domain.CapabilityRepository, orgID uuid.UUID) ([]*domain.CapabilityCatalogEntry, error) {
+	if c == nil {
+		return capabilityCatalog(repo, orgID)
+	}
+
+	key := agentLookupCatalogPrefix + orgID.String()
+	var cached []*domain.CapabilityCatalogEntry
+	if err := c.store.Get(ctx, key, &cached); err == nil {
+		return cached, nil
+	}
+
+	catalog, err := capabilityCatalog(repo, orgID)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.store.Set(ctx, key, catalog, c.ttl); err != nil {
+		logging.FromContext(ctx).Debug("failed to cache capability catalog", "organization_id", orgID, "error", err)
+	}
+	return catalog, nil
+}
+
+// InvalidateAgent drops the cached agent and capabilities
+func (c *AgentLookupCache) InvalidateAgent(ctx context.Context, agentID uuid.UUID) {
+	if c == nil {
+		return
+	}
+	for _, key := range []string{agentLookupAgentPrefix + agentID.String(), agentLookupCapabilitiesPrefix + agentID.String()} {
+		if err := c.store.Delete(ctx, key); err != nil {
+			logging.FromContext(ctx).Warn("failed to invalidate cached agent lookup", "agent_id", agentID, "key", key, "error", err)
+		}
+	}
+}
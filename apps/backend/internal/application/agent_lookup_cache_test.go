@@ -0,0 +1,271 @@
+package application
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeLookupStore mimics RedisCache's JSON Get/Set/Delete/Exists
+type fakeLookupStore struct {
+	values map[string][]byte
+}
+
+func newFakeLookupStore() *fakeLookupStore {
+	return &fakeLookupStore{values: make(map[string][]byte)}
+}
+
+func (s *fakeLookupStore) Get(ctx context.Context, key string, dest interface{}) error {
+	data, ok := s.values[key]
+	if !ok {
+		return fmt.Errorf("cache miss: %s", key)
+	}
+	return json.Unmarshal(data, dest)
+}
+
+func (s *fakeLookupStore) Set(ctx context.Context, key string, value interface{}, ttl time.Duration) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	s.values[key] = data
+	return nil
+}
+
+func (s *fakeLookupStore) Delete(ctx context.Context, key string) error {
+	delete(s.values, key)
+	return nil
+}
+
+func (s *fakeLookupStore) Exists(ctx context.Context, key string) (bool, error) {
+	_, ok := s.values[key]
+	return ok, nil
+}
+
+func TestAgentLookupCache_MissLoadsThenHitSkipsRepository(t *testing.T) {
+	mockAgentRepo := new(MockAgentRepository)
+	mockCapabilityRepo := new(MockCapabilityRepository)
+	lookup := NewAgentLookupCache(newFakeLookupStore(), time.Minute)
+	ctx := context.Background()
+
+	agent := createTestAgentForService()
+	grants := []*domain.AgentCapability{{ID: uuid.New(), AgentID: agent.ID, CapabilityType: "file:read"}}
+	mockAgentRepo.On("GetByID", agent.ID).Return(agent, nil).Once()
+	mockCapabilityRepo.On("GetActiveCapabilitiesByAgentID", agent.ID).Return(grants, nil).Once()
+
+	for i := 0; i < 3; i++ {
+		cachedAgent, err := lookup.Agent(ctx, mockAgentRepo, agent.ID)
+		require.NoError(t, err)
+		assert.Equal(t, agent.ID, cachedAgent.ID)
+		assert.Equal(t, agent.Status, cachedAgent.Status)
+
+		capabilities, err := lookup.ActiveCapabilities(ctx, mockCapabilityRepo, agent.ID)
+		require.NoError(t, err)
+		require.Len(t, capabilities, 1)
+		assert.Equal(t, "file:read", capabilities[0].CapabilityType)
+	}
+
+	// Only the first round reached the repositories
+	mockAgentRepo.AssertExpectations(t)
+	mockCapabilityRepo.AssertExpectations(t)
+}
+
+func TestAgentLookupCache_NilCacheReadsRepository(t *testing.T) {
+	mockAgentRepo := new(MockAgentRepository)
+	lookup := NewAgentLookupCache(nil, time.Minute)
+	require.Nil(t, lookup)
+
+	agent := createTestAgentForService()
+	mockAgentRepo.On("GetByID", agent.ID).Return(agent, nil).Twice()
+
+	for i := 0; i < 2; i++ {
+		got, err := lookup.Agent(context.Background(), mockAgentRepo, agent.ID)
+		require.NoError(t, err)
+		assert.Same(t, agent, got)
+	}
+	mockAgentRepo.AssertExpectations(t)
+}
+
+func TestAgentLookupCache_AgentUpdateInvalidates(t *testing.T) {
+	mockAgentRepo := new(MockAgentRepository)
+	lookup := NewAgentLookupCache(newFakeLookupStore(), time.Minute)
+	agentRepo := NewInvalidatingAgentRepository(mockAgentRepo, lookup)
+	ctx := context.Background()
+
+	agent := createTestAgentForService()
+	suspended := *agent
+	suspended.Status = domain.AgentStatusSuspended
+	mockAgentRepo.On("GetByID", agent.ID).Return(agent, nil).Once()
+	mockAgentRepo.On("GetByID", agent.ID).Return(&suspended, nil).Once()
+	mockAgentRepo.On("Update", &suspended).Return(nil)
+
+	cached, err := lookup.Agent(ctx, agentRepo, agent.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.AgentStatusVerified, cached.Status)
+
+	require.NoError(t, agentRepo.Update(&suspended))
+
+	cached, err = lookup.Agent(ctx, agentRepo, agent.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.AgentStatusSuspended, cached.Status)
+	mockAgentRepo.AssertExpectations(t)
+}
+
+func TestAgentLookupCache_RevokeInvalidatesOwningAgent(t *testing.T) {
+	mockCapabilityRepo := new(MockCapabilityRepository)
+	lookup := NewAgentLookupCache(newFakeLookupStore(), time.Minute)
+	capabilityRepo := NewInvalidatingCapabilityRepository(mockCapabilityRepo, lookup)
+	ctx := context.Background()
+
+	agentID := uuid.New()
+	grant := &domain.AgentCapability{ID: uuid.New(), AgentID: agentID, CapabilityType: "file:read"}
+	mockCapabilityRepo.On("GetActiveCapabilitiesByAgentID", agentID).Return([]*domain.AgentCapability{grant}, nil).Once()
+	mockCapabilityRepo.On("GetActiveCapabilitiesByAgentID", agentID).Return([]*domain.AgentCapability{}, nil).Once()
+	mockCapabilityRepo.On("GetCapabilityByID", grant.ID).Return(grant, nil)
+	mockCapabilityRepo.On("RevokeCapability", grant.ID, mock.Anything).Return(nil)
+
+	capabilities, err := lookup.ActiveCapabilities(ctx, capabilityRepo, agentID)
+	require.NoError(t, err)
+	require.Len(t, capabilities, 1)
+
+	require.NoError(t, capabilityRepo.RevokeCapability(grant.ID, time.Now()))
+
+	capabilities, err = lookup.ActiveCapabilities(ctx, capabilityRepo, agentID)
+	require.NoError(t, err)
+	assert.Empty(t, capabilities)
+	mockCapabilityRepo.AssertExpectations(t)
+}
+
+func TestAgentService_VerifyAction_NoStaleDenialAfterGrant(t *testing.T) {
+	mockAgentRepo := new(MockAgentRepository)
+	mockCapabilityRepo := new(MockCapabilityRepository)
+	mockPolicyRepo := new(AgentServiceMockSecurityPolicyRepository)
+	mockAlertRepo := new(MockAlertRepository)
+
+	lookup := NewAgentLookupCache(newFakeLookupStore(), time.Hour)
+	capabilityRepo := NewInvalidatingCapabilityRepository(mockCapabilityRepo, lookup)
+	service := &AgentService{
+		agentRepo:      NewInvalidatingAgentRepository(mockAgentRepo, lookup),
+		capabilityRepo: capabilityRepo,
+		policyService:  &SecurityPolicyService{policyRepo: mockPolicyRepo, alertRepo: mockAlertRepo},
+		alertRepo:      mockAlertRepo,
+		lookupCache:    lookup,
+	}
+
+	agent := createTestAgentForService()
+	grant := &domain.AgentCapability{ID: uuid.New(), AgentID: agent.ID, CapabilityType: "file:read", GrantedAt: time.Now()}
+	mockAgentRepo.On("GetByID", agent.ID).Return(agent, nil)
+	mockCapabilityRepo.On("GetActiveCapabilitiesByAgentID", agent.ID).Return([]*domain.AgentCapability{}, nil).Once()
+	mockCapabilityRepo.On("GetActiveCapabilitiesByAgentID", agent.ID).Return([]*domain.AgentCapability{grant}, nil).Once()
+	mockCapabilityRepo.On("CreateCapability", grant).Return(nil)
+	mockPolicyRepo.On("GetActiveByOrganization", agent.OrganizationID).Return([]*domain.SecurityPolicy{}, nil).Maybe()
+	mockPolicyRepo.On("GetByType", agent.OrganizationID, mock.Anything).Return([]*domain.SecurityPolicy{}, nil).Maybe()
+
+	ctx := context.Background()
+	allowed, reason, _, err := service.VerifyAction(ctx, agent.ID, "file:read", "/test.txt", nil)
+	require.NoError(t, err)
+	assert.False(t, allowed)
+	assert.Contains(t, reason, "no granted capabilities")
+
+	// The grant invalidates the cached (empty) capabilities, which would otherwise be reused for an hour
+	require.NoError(t, capabilityRepo.CreateCapability(grant))
+
+	allowed, _, _, err = service.VerifyAction(ctx, agent.ID, "file:read", "/test.txt", nil)
+	require.NoError(t, err)
+	assert.True(t, allowed)
+	mockCapabilityRepo.AssertExpectations(t)
+}
+
+// newCachedVerifyActionService builds an AgentService whose repositories do not invalidate the
+// cache themselves, so only the services' explicit invalidation hooks can keep it fresh
+func newCachedVerifyActionService(lookup *AgentLookupCache, agent *domain.Agent) (*AgentService, *MockAgentRepository, *MockCapabilityRepository) {
+	mockAgentRepo := new(MockAgentRepository)
+	mockCapabilityRepo := new(MockCapabilityRepository)
+	mockPolicyRepo := new(AgentServiceMockSecurityPolicyRepository)
+	mockAlertRepo := new(MockAlertRepository)
+	mockPolicyRepo.On("GetActiveByOrganization", agent.OrganizationID).Return([]*domain.SecurityPolicy{}, nil).Maybe()
+	mockPolicyRepo.On("GetByType", agent.OrganizationID, mock.Anything).Return([]*domain.SecurityPolicy{}, nil).Maybe()
+
+	service := &AgentService{
+		agentRepo:        mockAgentRepo,
+		capabilityRepo:   mockCapabilityRepo,
+		policyService:    &SecurityPolicyService{policyRepo: mockPolicyRepo, alertRepo: mockAlertRepo},
+		alertRepo:        mockAlertRepo,
+		lookupCache:      lookup,
+		cacheInvalidator: lookup,
+	}
+	return service, mockAgentRepo, mockCapabilityRepo
+}
+
+func TestCapabilityService_RevokeCapability_InvalidatesCachedCapabilities(t *testing.T) {
+	lookup := NewAgentLookupCache(newFakeLookupStore(), time.Hour)
+	agent := createTestAgentForService()
+	agentService, mockAgentRepo, mockCapabilityRepo := newCachedVerifyActionService(lookup, agent)
+
+	grant := &domain.AgentCapability{ID: uuid.New(), AgentID: agent.ID, CapabilityType: "file:read", GrantedAt: time.Now()}
+	mockAgentRepo.On("GetByID", agent.ID).Return(agent, nil)
+	mockCapabilityRepo.On("GetActiveCapabilitiesByAgentID", agent.ID).Return([]*domain.AgentCapability{grant}, nil).Once()
+	mockCapabilityRepo.On("GetActiveCapabilitiesByAgentID", agent.ID).Return([]*domain.AgentCapability{}, nil).Once()
+	mockCapabilityRepo.On("GetCapabilityByID", grant.ID).Return(grant, nil)
+	mockCapabilityRepo.On("RevokeCapability", grant.ID, mock.Anything).Return(nil)
+	mockAuditRepo := new(AgentServiceMockAuditLogRepository)
+	mockAuditRepo.On("Create", mock.Anything).Return(nil)
+	mockTrustCalc := new(AgentServiceMockTrustScoreCalculator)
+	mockTrustCalc.On("Calculate", mock.Anything).Return(nil, errors.New("skip recalculation"))
+
+	capabilityService := &CapabilityService{
+		capabilityRepo:   mockCapabilityRepo,
+		agentRepo:        mockAgentRepo,
+		auditRepo:        mockAuditRepo,
+		trustCalc:        mockTrustCalc,
+		cacheInvalidator: lookup,
+	}
+
+	ctx := context.Background()
+	allowed, _, _, err := agentService.VerifyAction(ctx, agent.ID, "file:read", "/test.txt", nil)
+	require.NoError(t, err)
+	require.True(t, allowed)
+
+	require.NoError(t, capabilityService.RevokeCapability(ctx, grant.ID, nil))
+
+	allowed, reason, _, err := agentService.VerifyAction(ctx, agent.ID, "file:read", "/test.txt", nil)
+	require.NoError(t, err)
+	assert.False(t, allowed)
+	assert.Contains(t, reason, "no granted capabilities")
+	mockCapabilityRepo.AssertExpectations(t)
+}
+
+func TestAgentService_SuspendAgent_InvalidatesCachedAgent(t *testing.T) {
+	lookup := NewAgentLookupCache(newFakeLookupStore(), time.Hour)
+	agent := createTestAgentForService()
+	service, mockAgentRepo, mockCapabilityRepo := newCachedVerifyActionService(lookup, agent)
+
+	grant := &domain.AgentCapability{ID: uuid.New(), AgentID: agent.ID, CapabilityType: "file:read", GrantedAt: time.Now()}
+	mockAgentRepo.On("GetByID", agent.ID).Return(agent, nil)
+	mockAgentRepo.On("UpdateStatus", agent, mock.Anything).Return(nil)
+	mockCapabilityRepo.On("GetActiveCapabilitiesByAgentID", agent.ID).Return([]*domain.AgentCapability{grant}, nil)
+	mockTrustCalc := new(AgentServiceMockTrustScoreCalculator)
+	mockTrustCalc.On("Calculate", agent).Return(nil, errors.New("skip recalculation"))
+	service.trustCalc = mockTrustCalc
+
+	ctx := context.Background()
+	allowed, _, _, err := service.VerifyAction(ctx, agent.ID, "file:read", "/test.txt", nil)
+	require.NoError(t, err)
+	require.True(t, allowed)
+
+	require.NoError(t, service.SuspendAgent(ctx, agent.ID))
+
+	allowed, reason, _, err := service.VerifyAction(ctx, agent.ID, "file:read", "/test.txt", nil)
+	require.NoError(t, err)
+	assert.False(t, allowed)
+	assert.Contains(t, reason, "not verified")
+}
@@ -2,14 +2,22 @@ package application
 
 import (
 	"context"
-	"encoding/json"
+	"encoding/base64"
+	"errors"
 	"fmt"
+	"log/slog"
+	"math"
 	"os"
+	"path"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/opena2a/identity/backend/internal/crypto"
 	"github.com/opena2a/identity/backend/internal/domain"
+	"github.com/opena2a/identity/backend/internal/infrastructure/logging"
+	"github.com/opena2a/identity/backend/internal/infrastructure/metrics"
 )
 
 // AgentService handles agent business logic
@@ -17,11 +25,17 @@ type AgentService struct {
 	agentRepo                domain.AgentRepository
 	trustCalc                domain.TrustScoreCalculator
 	trustScoreRepo           domain.TrustScoreRepository
-	keyVault                 *crypto.KeyVault            // ✅ For secure private key storage
-	alertRepo                domain.AlertRepository      // ✅ For creating security alerts
-	policyService            *SecurityPolicyService      // ✅ For policy-based enforcement
-	capabilityRepo           domain.CapabilityRepository // ✅ For checking agent capabilities
-	verificationEventService *VerificationEventService   // ✅ For creating verification events
+	keyVault                 *crypto.KeyVault              // ✅ For secure private key storage
+	alertRepo                domain.AlertRepository        // ✅ For creating security alerts
+	policyService            *SecurityPolicyService        // ✅ For policy-based enforcement
+	capabilityRepo           domain.CapabilityRepository   // ✅ For checking agent capabilities
+	verificationEventService *VerificationEventService     // ✅ For creating verification events
+	orgRepo                  domain.OrganizationRepository // ✅ For per-organization auto-verification settings
+	lookupCache              *AgentLookupCache             // Caches VerifyAction's agent and capability reads; nil disables
+	decisionCache            *VerificationDecisionCache    // Caches VerifyAction's allow decisions; nil disables
+	cacheInvalidator         AgentCacheInvalidator         // Drops cached authorization data on suspension
+	capabilityRequestService *CapabilityRequestService     // Files declared capabilities for approval when the organization requires it
+	now                      func() time.Time              // Clock for capability expiry; nil means time.Now
 }
 
 // NewAgentService creates a new agent service
@@ -34,6 +48,10 @@ func NewAgentService(
 	policyService *SecurityPolicyService, // ✅ NEW: Security Policy Service
 	capabilityRepo domain.CapabilityRepository, // ✅ NEW: CapabilityRepository for capability checks
 	verificationEventService *VerificationEventService, // ✅ NEW: For creating verification events
+	orgRepo domain.OrganizationRepository, // ✅ NEW: For per-organization auto-verification settings
+	lookupCache *AgentLookupCache, // Optional: caches VerifyAction's agent and capability reads
+	decisionCache *VerificationDecisionCache, // Optional: caches VerifyAction's allow decisions
+	capabilityRequestService *CapabilityRequestService, // For organizations that require capability approval
 ) *AgentService {
 	return &AgentService{
 		agentRepo:                agentRepo,
@@ -44,9 +62,34 @@ func NewAgentService(
 		policyService:            policyService,
 		capabilityRepo:           capabilityRepo,
 		verificationEventService: verificationEventService,
+		orgRepo:                  orgRepo,
+		lookupCache:              lookupCache,
+		decisionCache:            decisionCache,
+		cacheInvalidator:         AgentCacheInvalidators{lookupCache, decisionCache},
+		capabilityRequestService: capabilityRequestService,
+		now:                      time.Now,
 	}
 }
 
+// clock returns the current time from the injected clock, falling back to time.Now
+func (s *AgentService) clock() time.Time {
+	if s.now != nil {
+		return s.now()
+	}
+	return time.Now()
+}
+
+// MaxBulkAgents caps the number of agents accepted by a single bulk create request
+const MaxBulkAgents = 500
+
+var (
+	ErrBulkAgentsEmpty         = errors.New("at least one agent is required")
+	ErrBulkAgentsLimitExceeded = fmt.Errorf("bulk create is limited to %d agents per request", MaxBulkAgents)
+	ErrBulkAgentsAborted       = errors.New("atomic bulk create aborted")
+	ErrAgentAlreadyCompromised = errors.New("agent is already marked as compromised")
+	ErrAgentNotCompromised     = errors.New("agent is not marked as compromised")
+)
+
 // CreateAgentRequest represents agent creation request
 type CreateAgentRequest struct {
 	Name             string           `json:"name"`
@@ -64,6 +107,107 @@ type CreateAgentRequest struct {
 
 // CreateAgent creates a new agent
 func (s *AgentService) CreateAgent(ctx context.Context, req *CreateAgentRequest, orgID, userID uuid.UUID) (*domain.Agent, error) {
+	if err := s.checkAgentQuota(orgID, 1); err != nil {
+		return nil, err
+	}
+
+	// Organizations can raise the auto-verification trust threshold or disable it entirely,
+	// and require approval of declared capabilities
+	settings := s.getRegistrationSettings(orgID)
+
+	agent, err := s.buildAgent(req, orgID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.agentRepo.Create(agent); err != nil {
+		return nil, fmt.Errorf("failed to create agent: %w", err)
+	}
+
+	s.onboardAgent(ctx, agent, req.Capabilities, orgID, userID, settings)
+
+	return agent, nil
+}
+
+// BulkCreateAgentResult reports the outcome of a single item in a bulk create request
+type BulkCreateAgentResult struct {
+	Index   int           `json:"index"`
+	Success bool          `json:"success"`
+	Agent   *domain.Agent `json:"agent,omitempty"`
+	Error   string        `json:"error,omitempty"`
+}
+
+// CreateAgentsBulk creates up to MaxBulkAgents agents in a single transaction.
+// Invalid items are reported per index; with atomic set, any failure aborts the whole batch.
+func (s *AgentService) CreateAgentsBulk(ctx context.Context, reqs []*CreateAgentRequest, atomic bool, orgID, userID uuid.UUID) ([]*BulkCreateAgentResult, error) {
+	if len(reqs) == 0 {
+		return nil, ErrBulkAgentsEmpty
+	}
+	if len(reqs) > MaxBulkAgents {
+		return nil, ErrBulkAgentsLimitExceeded
+	}
+
+	settings := s.getRegistrationSettings(orgID)
+
+	results := make([]*BulkCreateAgentResult, len(reqs))
+	agents := make([]*domain.Agent, 0, len(reqs))
+	agentIndexes := make([]int, 0, len(reqs))
+	for i, req := range reqs {
+		results[i] = &BulkCreateAgentResult{Index: i}
+
+		if req == nil {
+			results[i].Error = "agent definition is required"
+			continue
+		}
+
+		agent, err := s.buildAgent(req, orgID, userID)
+		if err != nil {
+			results[i].Error = err.Error()
+			continue
+		}
+		agents = append(agents, agent)
+		agentIndexes = append(agentIndexes, i)
+	}
+
+	// Atomic batches are all-or-nothing, so a single invalid item rejects the batch
+	if atomic && len(agents) != len(reqs) {
+		return results, ErrBulkAgentsAborted
+	}
+
+	// The batch is rejected as a whole if it does not fit in the organization's max_agents
+	if err := s.checkAgentQuota(orgID, len(agents)); err != nil {
+		return nil, err
+	}
+
+	if len(agents) > 0 {
+		itemErrors, err := s.agentRepo.CreateBatch(agents, atomic)
+		if err != nil {
+			if atomic {
+				return results, fmt.Errorf("%w: %v", ErrBulkAgentsAborted, err)
+			}
+			return nil, fmt.Errorf("failed to create agents: %w", err)
+		}
+
+		for j, agent := range agents {
+			result := results[agentIndexes[j]]
+			if j < len(itemErrors) && itemErrors[j] != nil {
+				result.Error = fmt.Sprintf("failed to create agent: %v", itemErrors[j])
+				continue
+			}
+
+			// Trust scoring, auto-verification and capability grants still run per agent
+			s.onboardAgent(ctx, agent, reqs[agentIndexes[j]].Capabilities, orgID, userID, settings)
+			result.Success = true
+			result.Agent = agent
+		}
+	}
+
+	return results, nil
+}
+
+// buildAgent validates a create request and assembles the agent with its keys (not yet persisted).
+// New agents are always pending; onboardAgent verifies the ones that qualify once they are stored.
+func (s *AgentService) buildAgent(req *CreateAgentRequest, orgID, userID uuid.UUID) (*domain.Agent, error) {
 	// Validate inputs
 	if req.Name == "" || req.DisplayName == "" {
 		return nil, fmt.Errorf("name and display_name are required")
@@ -73,6 +217,13 @@ func (s *AgentService) CreateAgent(ctx context.Context, req *CreateAgentRequest,
 		return nil, fmt.Errorf("invalid agent_type")
 	}
 
+	// Declared capabilities are auto-granted, so they must be in the organization's catalog
+	if s.capabilityRepo != nil {
+		if err := ensureInCapabilityCatalog(s.capabilityRepo, orgID, req.Capabilities); err != nil {
+			return nil, err
+		}
+	}
+
 	// ✅ KEY MANAGEMENT - Support both SDK-provided and auto-generated keys
 	var publicKeyBase64 string
 	var encryptedPrivateKey string
@@ -107,10 +258,8 @@ func (s *AgentService) CreateAgent(ctx context.Context, req *CreateAgentRequest,
 	}
 
 	// Create agent with keys (SDK-provided or auto-generated)
-	// ✅ AUTO-VERIFIED: Agents created via authenticated channels (SDK/API/Dashboard)
-	// are automatically verified since the creator is already authenticated.
-	// This removes friction from the development workflow while maintaining security.
-	// Admins can still suspend/revoke agents if needed.
+	// Agents start pending so a failure between the insert and auto-verification never leaves
+	// an unchecked agent verified; admins can still verify pending agents manually.
 	agent := &domain.Agent{
 		OrganizationID:   orgID,
 		Name:             req.Name,
@@ -125,7 +274,7 @@ func (s *AgentService) CreateAgent(ctx context.Context, req *CreateAgentRequest,
 		DocumentationURL: req.DocumentationURL,
 		TalksTo:          req.TalksTo,      // MCP servers this agent communicates with
 		Capabilities:     req.Capabilities, // ✅ Store detected capabilities from SDK
-		Status:           domain.AgentStatusVerified, // ✅ Auto-verified for authenticated users
+		Status:           domain.AgentStatusPending,
 		CreatedBy:        userID,
 	}
 
@@ -134,39 +283,62 @@ func (s *AgentService) CreateAgent(ctx context.Context, req *CreateAgentRequest,
 		agent.EncryptedPrivateKey = &encryptedPrivateKey // ✅ Encrypted storage (never exposed in API)
 	}
 
-	if err := s.agentRepo.Create(agent); err != nil {
-		return nil, fmt.Errorf("failed to create agent: %w", err)
-	}
+	return agent, nil
+}
+
+// onboardAgent runs the post-creation steps for a persisted agent: initial trust score,
+// auto-verification and auto-granting (or requesting approval of) declared capabilities
+func (s *AgentService) onboardAgent(ctx context.Context, agent *domain.Agent, capabilities []string, orgID, userID uuid.UUID, settings agentRegistrationSettings) {
+	autoVerifyEnabled, autoVerifyMinTrust := settings.autoVerifyEnabled, settings.autoVerifyMinTrust
 
 	// Calculate initial trust score
 	trustScore, err := s.trustCalc.Calculate(agent)
 	if err != nil {
 		// Log error but don't fail the creation
-		fmt.Printf("Warning: failed to calculate trust score: %v\n", err)
+		logging.FromContext(ctx).Warn("failed to calculate trust score", "agent_id", agent.ID, "error", err)
 	} else {
 		agent.TrustScore = trustScore.Score
 		if err := s.agentRepo.Update(agent); err != nil {
-			fmt.Printf("Warning: failed to update trust score: %v\n", err)
+			logging.FromContext(ctx).Warn("failed to update trust score", "agent_id", agent.ID, "error", err)
 		}
 		if err := s.trustScoreRepo.Create(trustScore); err != nil {
-			fmt.Printf("Warning: failed to save trust score: %v\n", err)
+			logging.FromContext(ctx).Warn("failed to save trust score", "agent_id", agent.ID, "error", err)
 		}
 	}
 
 	// ✅ AUTO-VERIFICATION: Automatically verify agent if it meets basic criteria
 	// This eliminates manual verification step for legitimate agents
-	shouldAutoVerify := s.shouldAutoVerifyAgent(agent)
+	// Agents that do not qualify stay pending for manual review
+	shouldAutoVerify := autoVerifyEnabled && s.shouldAutoVerifyAgent(agent, autoVerifyMinTrust)
 	if shouldAutoVerify {
-		now := time.Now()
+		now := time.Now().UTC()
 		agent.Status = domain.AgentStatusVerified
 		agent.VerifiedAt = &now
 
-		if err := s.agentRepo.Update(agent); err != nil {
-			fmt.Printf("Warning: failed to auto-verify agent: %v\n", err)
+		// Announce the verification like a manual one, in the same transaction as the status change
+		webhookMessage, err := domain.NewWebhookOutboxMessage(orgID, domain.WebhookEventAgentVerified, domain.WebhookResourceAgent, domain.AgentStatusWebhookData{
+			AgentID:            agent.ID,
+			AgentName:          agent.Name,
+			PreviousStatus:     domain.AgentStatusPending,
+			Status:             domain.AgentStatusVerified,
+			PreviousTrustScore: agent.TrustScore,
+			TrustScore:         agent.TrustScore,
+			ChangedAt:          now,
+		})
+		if err == nil {
+			err = s.agentRepo.UpdateStatus(agent, webhookMessage)
+		}
+		if err != nil {
+			// The stored agent is still pending, so do not report it verified
+			logging.FromContext(ctx).Warn("failed to auto-verify agent", "agent_id", agent.ID, "error", err)
+			agent.Status = domain.AgentStatusPending
+			agent.VerifiedAt = nil
+			shouldAutoVerify = false
 		} else {
-			fmt.Printf("✅ Agent %s auto-verified (trust score: %.2f)\n", agent.Name, agent.TrustScore)
+			logging.FromContext(ctx).Info("agent auto-verified", "agent_id", agent.ID, "agent_name", agent.Name, "trust_score", agent.TrustScore)
 		}
-
+	}
+	if shouldAutoVerify {
 		// ✅ CREATE VERIFICATION EVENT for dashboard chart
 		// This populates the Agent Verification Activity chart
 		if s.verificationEventService != nil {
@@ -183,9 +355,9 @@ func (s *AgentService) CreateAgent(ctx context.Context, req *CreateAgentRequest,
 			}
 
 			if _, err := s.verificationEventService.CreateVerificationEvent(ctx, verificationReq); err != nil {
-				fmt.Printf("⚠️  Warning: failed to create verification event: %v\n", err)
+				logging.FromContext(ctx).Warn("failed to create verification event", "agent_id", agent.ID, "error", err)
 			} else {
-				fmt.Printf("✅ Created verification event for agent %s\n", agent.Name)
+				logging.FromContext(ctx).Debug("created verification event", "agent_id", agent.ID)
 			}
 		}
 
@@ -195,16 +367,22 @@ func (s *AgentService) CreateAgent(ctx context.Context, req *CreateAgentRequest,
 			agent.TrustScore = updatedTrustScore.Score
 			s.agentRepo.Update(agent)
 			s.trustScoreRepo.Create(updatedTrustScore)
-			fmt.Printf("✅ Updated trust score after verification: %.2f\n", agent.TrustScore)
+			logging.FromContext(ctx).Info("updated trust score after verification", "agent_id", agent.ID, "trust_score", agent.TrustScore)
 		}
 	}
 
+	// Organizations that require capability approval get pending requests instead of grants;
+	// the agent cannot perform any action until an admin approves them
+	if len(capabilities) > 0 && settings.requireCapabilityApproval {
+		s.requestDeclaredCapabilities(ctx, agent, capabilities, userID)
+	}
+
 	// ✅ AUTO-GRANT CAPABILITIES: Auto-grant declared capabilities during registration
 	// This eliminates admin approval bottleneck - users can start using agents immediately!
 	// Admins only approve capability UPDATES, not initial registration.
-	if len(req.Capabilities) > 0 {
+	if len(capabilities) > 0 && !settings.requireCapabilityApproval {
 		grantedCount := 0
-		for _, capabilityType := range req.Capabilities {
+		for _, capabilityType := range capabilities {
 			capabilityRecord := &domain.AgentCapability{
 				AgentID:        agent.ID,
 				CapabilityType: capabilityType,
@@ -213,41 +391,44 @@ func (s *AgentService) CreateAgent(ctx context.Context, req *CreateAgentRequest,
 			}
 
 			if err := s.capabilityRepo.CreateCapability(capabilityRecord); err != nil {
-				fmt.Printf("⚠️  Warning: failed to auto-grant capability '%s': %v\n", capabilityType, err)
+				logging.FromContext(ctx).Warn("failed to auto-grant capability", "agent_id", agent.ID, "capability", capabilityType, "error", err)
 			} else {
 				grantedCount++
 			}
 		}
 
 		if grantedCount > 0 {
-			fmt.Printf("✅ Auto-granted %d capabilities for agent %s: %v\n", grantedCount, agent.Name, req.Capabilities)
+			logging.FromContext(ctx).Info("auto-granted capabilities", "agent_id", agent.ID, "count", grantedCount, "capabilities", capabilities)
 		}
 	}
 
-	return agent, nil
+	// ✅ Snapshot the verified configuration for config drift policies
+	if agent.Status == domain.AgentStatusVerified {
+		s.captureBaseline(ctx, agent)
+	}
 }
 
 // shouldAutoVerifyAgent determines if an agent meets criteria for automatic verification
 // Auto-verification criteria:
-// 1. Has valid cryptographic keys (public + encrypted private key)
-// 2. Trust score >= 0.3 (30% minimum threshold)
-// 3. Has required metadata (name, description, type)
-func (s *AgentService) shouldAutoVerifyAgent(agent *domain.Agent) bool {
-	// ✅ Check 1: Must have cryptographic keys
-	if agent.PublicKey == nil || agent.EncryptedPrivateKey == nil {
-		fmt.Printf("⚠️  Agent %s cannot be auto-verified: missing cryptographic keys\n", agent.Name)
+// 1. Has a public key (generated server-side with an encrypted private key, or provided by the SDK)
+// 2. Trust score >= minTrust (organization threshold, 0 by default)
+// 3. Has required metadata (name and display name)
+func (s *AgentService) shouldAutoVerifyAgent(agent *domain.Agent, minTrust float64) bool {
+	// ✅ Check 1: Must have a public key; SDK-registered agents keep their private key client-side
+	if agent.PublicKey == nil || *agent.PublicKey == "" {
+		slog.Info("agent cannot be auto-verified: missing cryptographic keys", "agent_id", agent.ID)
 		return false
 	}
 
-	// ✅ Check 2: Trust score must be >= 0.3 (30%)
-	if agent.TrustScore < 0.3 {
-		fmt.Printf("⚠️  Agent %s cannot be auto-verified: trust score too low (%.2f < 0.3)\n", agent.Name, agent.TrustScore)
+	// ✅ Check 2: Trust score must meet the organization threshold
+	if agent.TrustScore < minTrust {
+		slog.Info("agent cannot be auto-verified: trust score too low", "agent_id", agent.ID, "trust_score", agent.TrustScore, "min_trust", minTrust)
 		return false
 	}
 
 	// ✅ Check 3: Must have required metadata
-	if agent.Name == "" || agent.DisplayName == "" || agent.Description == "" {
-		fmt.Printf("⚠️  Agent %s cannot be auto-verified: missing required metadata\n", agent.Name)
+	if agent.Name == "" || agent.DisplayName == "" {
+		slog.Info("agent cannot be auto-verified: missing required metadata", "agent_id", agent.ID)
 		return false
 	}
 
@@ -255,6 +436,113 @@ func (s *AgentService) shouldAutoVerifyAgent(agent *domain.Agent) bool {
 	return true
 }
 
+// requestDeclaredCapabilities files approval requests for an agent's declared capabilities.
+// Without a capability request service nothing is granted or requested, so the agent stays
+// without capabilities rather than being granted them unapproved.
+func (s *AgentService) requestDeclaredCapabilities(ctx context.Context, agent *domain.Agent, capabilities []string, userID uuid.UUID) {
+	if s.capabilityRequestService == nil {
+		logging.FromContext(ctx).Warn("capability approval required but requests are unavailable, no capabilities granted", "agent_id", agent.ID)
+		return
+	}
+
+	requested, err := s.capabilityRequestService.RequestDeclaredCapabilities(ctx, agent, capabilities, userID)
+	if err != nil {
+		logging.FromContext(ctx).Warn("failed to request capability approval", "agent_id", agent.ID, "error", err)
+	}
+	if requested > 0 {
+		logging.FromContext(ctx).Info("requested approval of declared capabilities", "agent_id", agent.ID, "count", requested, "capabilities", capabilities)
+	}
+}
+
+// requestAddedCapabilities files approval requests for capabilities an update adds. As at
+// registration, nothing is granted when capability requests are unavailable.
+func (s *AgentService) requestAddedCapabilities(ctx context.Context, agent *domain.Agent, capabilities []string, userID uuid.UUID) {
+	if s.capabilityRequestService == nil {
+		logging.FromContext(ctx).Warn("capability approval required but requests are unavailable, no capabilities granted", "agent_id", agent.ID)
+		return
+	}
+
+	requested, err := s.capabilityRequestService.RequestAddedCapabilities(ctx, agent, capabilities, userID)
+	if err != nil {
+		logging.FromContext(ctx).Warn("failed to request capability approval", "agent_id", agent.ID, "error", err)
+	}
+	if requested > 0 {
+		logging.FromContext(ctx).Info("requested approval of added capabilities", "agent_id", agent.ID, "count", requested, "capabilities", capabilities)
+	}
+}
+
+// agentRegistrationSettings are the organization settings that shape agent registration
+type agentRegistrationSettings struct {
+	autoVerifyEnabled         bool
+	autoVerifyMinTrust        float64
+	requireCapabilityApproval bool
+}
+
+// getRegistrationSettings returns the organization's auto-verification and capability approval
+// settings. Without an organization repository the defaults apply (auto-verify every agent, auto-grant
+// capabilities); if the organization cannot be loaded, capabilities require approval, since the
+// organization may have asked for it.
+func (s *AgentService) getRegistrationSettings(orgID uuid.UUID) agentRegistrationSettings {
+	defaults := agentRegistrationSettings{autoVerifyEnabled: true, autoVerifyMinTrust: domain.DefaultAutoVerifyMinTrust}
+	if s.orgRepo == nil {
+		return defaults
+	}
+
+	org, err := s.orgRepo.GetByID(orgID)
+	if err != nil || org == nil {
+		slog.Warn("failed to load agent registration settings, using defaults", "org_id", orgID, "error", err)
+		defaults.requireCapabilityApproval = true
+		return defaults
+	}
+
+	return agentRegistrationSettings{
+		autoVerifyEnabled:         org.AutoVerifyEnabled,
+		autoVerifyMinTrust:        org.AutoVerifyMinTrust,
+		requireCapabilityApproval: org.RequireCapabilityApproval,
+	}
+}
+
+// checkAgentQuota returns a *domain.QuotaExceededError if creating n more agents would exceed
+// the organization's max_agents. Soft-deleted agents do not count against the limit. This only
+// rejects early; the repository re-checks the quota under a lock on the organization when inserting.
+func (s *AgentService) checkAgentQuota(orgID uuid.UUID, n int) error {
+	if s.orgRepo == nil || n == 0 {
+		return nil
+	}
+
+	org, err := s.orgRepo.GetByID(orgID)
+	if err != nil {
+		return fmt.Errorf("failed to load organization limits: %w", err)
+	}
+	if org.MaxAgents <= 0 {
+		return nil // Unlimited, no need to count
+	}
+
+	count, err := s.agentRepo.CountByOrganization(orgID)
+	if err != nil {
+		return fmt.Errorf("failed to count organization agents: %w", err)
+	}
+	return org.CheckAgentQuota(count, n)
+}
+
+// getKeyExpiry returns when a key issued now for an agent in orgID expires,
+// using the organization's key_rotation_days (default 365)
+func (s *AgentService) getKeyExpiry(orgID uuid.UUID, now time.Time) time.Time {
+	days := domain.DefaultKeyRotationDays
+	if s.orgRepo != nil {
+		org, err := s.orgRepo.GetByID(orgID)
+		switch {
+		case err != nil || org == nil:
+			slog.Warn("failed to load key rotation settings, using default", "org_id", orgID, "days", days, "error", err)
+		case domain.ValidateKeyRotationDays(org.KeyRotationDays) != nil:
+			slog.Warn("invalid key_rotation_days, using default", "org_id", orgID, "key_rotation_days", org.KeyRotationDays, "days", days)
+		default:
+			days = org.KeyRotationDays
+		}
+	}
+	return now.AddDate(0, 0, days)
+}
+
 // GetAgent retrieves an agent by ID
 func (s *AgentService) GetAgent(ctx context.Context, id uuid.UUID) (*domain.Agent, error) {
 	return s.agentRepo.GetByID(id)
@@ -265,13 +553,122 @@ func (s *AgentService) ListAgents(ctx context.Context, orgID uuid.UUID) ([]*doma
 	return s.agentRepo.GetByOrganization(orgID)
 }
 
-// UpdateAgent updates an agent
-func (s *AgentService) UpdateAgent(ctx context.Context, id uuid.UUID, req *CreateAgentRequest) (*domain.Agent, error) {
+const (
+	// DefaultAgentPageSize is used when a list request does not specify a limit
+	DefaultAgentPageSize = 100
+	// MaxAgentPageSize caps the number of agents returned in a single page
+	MaxAgentPageSize = 500
+)
+
+// ErrInvalidAgentCursor is returned when a pagination cursor cannot be decoded
+var ErrInvalidAgentCursor = errors.New("invalid cursor")
+
+// ListAgentsParams controls which page of agents is returned
+type ListAgentsParams struct {
+	Limit  int
+	Offset int
+	Cursor string // Opaque cursor from a previous page; takes precedence over Offset
+	Filter domain.AgentSearchFilter
+}
+
+// ListAgentsResult is one page of agents
+type ListAgentsResult struct {
+	Agents     []*domain.Agent
+	Total      int
+	NextCursor string // Empty when there are no more pages
+}
+
+// ListAgentsPage lists one page of agents for an organization
+func (s *AgentService) ListAgentsPage(ctx context.Context, orgID uuid.UUID, params ListAgentsParams) (*ListAgentsResult, error) {
+	limit := params.Limit
+	if limit <= 0 {
+		limit = DefaultAgentPageSize
+	}
+	if limit > MaxAgentPageSize {
+		limit = MaxAgentPageSize
+	}
+	offset := params.Offset
+	if offset < 0 {
+		offset = 0
+	}
+
+	var after *domain.AgentCursor
+	if params.Cursor != "" {
+		cursor, err := decodeAgentCursor(params.Cursor)
+		if err != nil {
+			return nil, err
+		}
+		after = cursor
+		offset = 0
+	}
+
+	// Fetch one extra row to know whether another page exists
+	var agents []*domain.Agent
+	var total int
+	var err error
+	if params.Filter.IsEmpty() {
+		agents, total, err = s.agentRepo.GetByOrganizationPaginated(orgID, limit+1, offset, after)
+	} else {
+		agents, total, err = s.agentRepo.Search(orgID, params.Filter, limit+1, offset, after)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	result := &ListAgentsResult{Agents: agents, Total: total}
+	if len(agents) > limit {
+		result.Agents = agents[:limit]
+		last := result.Agents[limit-1]
+		result.NextCursor = encodeAgentCursor(domain.AgentCursor{CreatedAt: last.CreatedAt, ID: last.ID})
+	}
+	if result.Agents == nil {
+		result.Agents = []*domain.Agent{}
+	}
+
+	return result, nil
+}
+
+// encodeAgentCursor serializes a cursor as URL-safe base64 of "created_at|id"
+func encodeAgentCursor(cursor domain.AgentCursor) string {
+	raw := cursor.CreatedAt.UTC().Format(time.RFC3339Nano) + "|" + cursor.ID.String()
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// decodeAgentCursor parses a cursor produced by encodeAgentCursor
+func decodeAgentCursor(encoded string) (*domain.AgentCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, ErrInvalidAgentCursor
+	}
+	parts := strings.SplitN(string(raw), "|", 2)
+	if len(parts) != 2 {
+		return nil, ErrInvalidAgentCursor
+	}
+	createdAt, err := time.Parse(time.RFC3339Nano, parts[0])
+	if err != nil {
+		return nil, ErrInvalidAgentCursor
+	}
+	id, err := uuid.Parse(parts[1])
+	if err != nil {
+		return nil, ErrInvalidAgentCursor
+	}
+	return &domain.AgentCursor{CreatedAt: createdAt, ID: id}, nil
+}
+
+// UpdateAgent updates an agent on behalf of userID. Capabilities missing from req.Capabilities are
+// revoked; added ones are granted, or requested for approval if the organization requires it.
+func (s *AgentService) UpdateAgent(ctx context.Context, id uuid.UUID, req *CreateAgentRequest, userID uuid.UUID) (*domain.Agent, error) {
 	agent, err := s.agentRepo.GetByID(id)
 	if err != nil {
 		return nil, err
 	}
 
+	if s.capabilityRepo != nil {
+		if err := ensureInCapabilityCatalog(s.capabilityRepo, agent.OrganizationID, req.Capabilities); err != nil {
+			return nil, err
+		}
+	}
+
 	// Update fields
 	if req.DisplayName != "" {
 		agent.DisplayName = req.DisplayName
@@ -305,7 +702,7 @@ func (s *AgentService) UpdateAgent(ctx context.Context, id uuid.UUID, req *Creat
 		// Get current capabilities
 		currentCaps, err := s.capabilityRepo.GetCapabilitiesByAgentID(id)
 		if err != nil {
-			fmt.Printf("⚠️  Warning: failed to get current capabilities: %v\n", err)
+			logging.FromContext(ctx).Warn("failed to get current capabilities", "agent_id", id, "error", err)
 		}
 
 		// Build map of current capability types
@@ -322,20 +719,31 @@ func (s *AgentService) UpdateAgent(ctx context.Context, id uuid.UUID, req *Creat
 			requestedCapTypes[capType] = true
 		}
 
-		// Add new capabilities that don't exist
+		var addedCapTypes []string
 		for _, capType := range req.Capabilities {
 			if _, exists := currentCapTypes[capType]; !exists {
-				capabilityRecord := &domain.AgentCapability{
-					AgentID:        id,
-					CapabilityType: capType,
-					GrantedBy:      &agent.CreatedBy, // Use agent creator as granter
-					GrantedAt:      time.Now(),
-				}
-				if err := s.capabilityRepo.CreateCapability(capabilityRecord); err != nil {
-					fmt.Printf("⚠️  Warning: failed to add capability '%s': %v\n", capType, err)
-				} else {
-					fmt.Printf("✅ Added capability '%s' to agent %s\n", capType, agent.Name)
-				}
+				addedCapTypes = append(addedCapTypes, capType)
+			}
+		}
+
+		// Organizations that require capability approval get pending requests instead of grants
+		if len(addedCapTypes) > 0 && s.getRegistrationSettings(agent.OrganizationID).requireCapabilityApproval {
+			s.requestAddedCapabilities(ctx, agent, addedCapTypes, userID)
+			addedCapTypes = nil
+		}
+
+		// Add new capabilities that don't exist
+		for _, capType := range addedCapTypes {
+			capabilityRecord := &domain.AgentCapability{
+				AgentID:        id,
+				CapabilityType: capType,
+				GrantedBy:      &agent.CreatedBy, // Use agent creator as granter
+				GrantedAt:      time.Now(),
+			}
+			if err := s.capabilityRepo.CreateCapability(capabilityRecord); err != nil {
+				logging.FromContext(ctx).Warn("failed to add capability", "agent_id", id, "capability", capType, "error", err)
+			} else {
+				logging.FromContext(ctx).Info("added capability", "agent_id", id, "capability", capType)
 			}
 		}
 
@@ -344,9 +752,9 @@ func (s *AgentService) UpdateAgent(ctx context.Context, id uuid.UUID, req *Creat
 			if !requestedCapTypes[capType] {
 				now := time.Now()
 				if err := s.capabilityRepo.RevokeCapability(cap.ID, now); err != nil {
-					fmt.Printf("⚠️  Warning: failed to revoke capability '%s': %v\n", capType, err)
+					logging.FromContext(ctx).Warn("failed to revoke capability", "agent_id", id, "capability", capType, "error", err)
 				} else {
-					fmt.Printf("🗑️  Revoked capability '%s' from agent %s\n", capType, agent.Name)
+					logging.FromContext(ctx).Info("revoked capability", "agent_id", id, "capability", capType)
 				}
 			}
 		}
@@ -362,11 +770,22 @@ func (s *AgentService) UpdateAgent(ctx context.Context, id uuid.UUID, req *Creat
 	return agent, nil
 }
 
-// DeleteAgent deletes an agent
+// DeleteAgent soft-deletes an agent. The row is kept so audit logs and
+// compliance history still resolve; the agent no longer appears in listings.
 func (s *AgentService) DeleteAgent(ctx context.Context, id uuid.UUID) error {
+	return s.agentRepo.SoftDelete(id)
+}
+
+// HardDeleteAgent permanently removes an agent, including soft-deleted ones
+func (s *AgentService) HardDeleteAgent(ctx context.Context, id uuid.UUID) error {
 	return s.agentRepo.Delete(id)
 }
 
+// GetAgentIncludingDeleted retrieves an agent by ID even if it was soft-deleted
+func (s *AgentService) GetAgentIncludingDeleted(ctx context.Context, id uuid.UUID) (*domain.Agent, error) {
+	return s.agentRepo.GetByIDIncludingDeleted(id)
+}
+
 // VerifyAgent verifies an agent
 func (s *AgentService) VerifyAgent(ctx context.Context, id uuid.UUID) error {
 	agent, err := s.agentRepo.GetByID(id)
@@ -374,21 +793,11 @@ func (s *AgentService) VerifyAgent(ctx context.Context, id uuid.UUID) error {
 		return err
 	}
 
-	now := time.Now()
-	agent.Status = domain.AgentStatusVerified
-	agent.VerifiedAt = &now
-
-	if err := s.agentRepo.Update(agent); err != nil {
+	if err := s.transitionStatus(ctx, agent, domain.AgentStatusVerified, domain.WebhookEventAgentVerified); err != nil {
 		return fmt.Errorf("failed to verify agent: %w", err)
 	}
 
-	// Recalculate trust score
-	trustScore, err := s.trustCalc.Calculate(agent)
-	if err == nil {
-		agent.TrustScore = trustScore.Score
-		s.agentRepo.Update(agent)
-		s.trustScoreRepo.Create(trustScore)
-	}
+	s.captureBaseline(ctx, agent)
 
 	return nil
 }
@@ -447,33 +856,25 @@ func (s *AgentService) UpdateTrustScore(ctx context.Context, agentID uuid.UUID,
 
 // checkAndCreateTrustScoreDropAlert checks for significant trust score drops and creates alerts
 func (s *AgentService) checkAndCreateTrustScoreDropAlert(ctx context.Context, agent *domain.Agent, previousScore, currentScore float64) {
-	// Configuration thresholds
-	const (
-		significantDropThreshold = 0.1 // 10% drop triggers warning
-		criticalDropThreshold    = 0.2 // 20% drop triggers critical
-		lowScoreThreshold        = 0.5 // 50% trust score threshold
-	)
-
-	// Calculate drop
-	if previousScore <= 0 {
-		return // No meaningful comparison
+	// Thresholds are tunable per organization through a trust_score_drop policy
+	thresholds := DefaultTrustScoreDropThresholds
+	if s.policyService != nil {
+		thresholds = s.policyService.GetTrustScoreDropThresholds(ctx, agent)
 	}
-
-	drop := previousScore - currentScore
-	if drop <= 0 {
-		return // Score increased or stayed the same
+	severity, ok := thresholds.DropSeverity(previousScore, currentScore)
+	if !ok {
+		return // No significant drop
 	}
 
-	dropPercentage := drop / previousScore
-
+	drop := previousScore - currentScore
 	var alert *domain.Alert
 	agentName := agent.DisplayName
 	if agentName == "" {
 		agentName = agent.Name
 	}
 
-	// Critical drop (>20% OR score dropped below 50%)
-	if dropPercentage >= criticalDropThreshold || (drop > 0 && currentScore < lowScoreThreshold) {
+	// Critical drop (default >20% OR score dropped below 50%)
+	if severity == domain.AlertSeverityCritical {
 		alert = &domain.Alert{
 			OrganizationID: agent.OrganizationID,
 			AlertType:      domain.AlertTrustScoreDrop,
@@ -483,8 +884,8 @@ func (s *AgentService) checkAndCreateTrustScoreDropAlert(ctx context.Context, ag
 			ResourceType:   "agent",
 			ResourceID:     agent.ID,
 		}
-	} else if dropPercentage >= significantDropThreshold {
-		// Significant drop (>10%)
+	} else {
+		// Significant drop (default >10%)
 		alert = &domain.Alert{
 			OrganizationID: agent.OrganizationID,
 			AlertType:      domain.AlertTrustScoreDrop,
@@ -496,10 +897,6 @@ func (s *AgentService) checkAndCreateTrustScoreDropAlert(ctx context.Context, ag
 		}
 	}
 
-	if alert == nil {
-		return // No significant drop
-	}
-
 	// Check for existing unacknowledged alert to avoid duplicates
 	existing, _ := s.alertRepo.GetUnacknowledged(agent.OrganizationID)
 	for _, a := range existing {
@@ -531,8 +928,9 @@ func (s *AgentService) HasCapability(ctx context.Context, agentID uuid.UUID, act
 	}
 
 	// Check if action matches any capability
+	now := time.Now()
 	for _, capability := range capabilities {
-		if s.matchesCapability(actionType, resource, capability.CapabilityType) {
+		if s.matchesGrant(actionType, resource, capability, now) {
 			return true, nil
 		}
 	}
@@ -540,6 +938,49 @@ func (s *AgentService) HasCapability(ctx context.Context, agentID uuid.UUID, act
 	return false, nil
 }
 
+// ValidateVerifyAction checks a verify-action request; field errors reject it. An action type
+// outside the organization's capability catalog is not rejected, since SDKs still send legacy
+// names such as "read_file": VerifyAction records it as a capability violation unless the agent
+// holds a matching grant. inCatalog reports whether the action type is in the catalog, so callers
+// can warn about it.
+func (s *AgentService) ValidateVerifyAction(ctx context.Context, orgID uuid.UUID, actionType string, resource string) (fieldErrors []domain.FieldError, inCatalog bool, err error) {
+	if actionType == "" {
+		fieldErrors = append(fieldErrors, domain.FieldError{Field: "action_type", Message: "action_type is required"})
+	}
+	if len(resource) > domain.MaxVerifyActionResourceLength {
+		fieldErrors = append(fieldErrors, domain.FieldError{
+			Field:   "resource",
+			Message: fmt.Sprintf("resource must be at most %d bytes", domain.MaxVerifyActionResourceLength),
+		})
+	}
+	if len(fieldErrors) > 0 {
+		return fieldErrors, false, nil
+	}
+
+	catalog, err := s.lookupCache.CapabilityCatalog(ctx, s.capabilityRepo, orgID)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to load capability catalog: %w", err)
+	}
+	return nil, catalogAllowsAction(catalog, actionType), nil
+}
+
+// catalogAllowsAction reports whether actionType matches the action part of a catalog capability
+// type, using the same patterns as granted capabilities (e.g. "read_*", "file:read:/data/*")
+func catalogAllowsAction(catalog []*domain.CapabilityCatalogEntry, actionType string) bool {
+	for _, entry := range catalog {
+		capability := entry.CapabilityType
+		if matchesActionPattern(actionType, capability) {
+			return true
+		}
+		for i := 1; i < len(capability); i++ {
+			if capability[i] == ':' && matchesActionPattern(actionType, capability[:i]) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 // VerifyAction verifies if an agent can perform an action
 // ✅ CRITICAL SECURITY FUNCTION - EchoLeak Prevention
 // This is the core defense mechanism that prevented CVE-2025-32711 (EchoLeak) attack
@@ -552,8 +993,14 @@ func (s *AgentService) VerifyAction(
 ) (allowed bool, reason string, auditID uuid.UUID, err error) {
 	auditID = uuid.New()
 
-	// 1. Fetch agent
-	agent, err := s.agentRepo.GetByID(agentID)
+	// 0. Reuse a recent allow for the same action and resource (opt-in; suspension, compromise
+	// and revocations put the agent on the cache's revocation list, which bypasses it)
+	if cachedReason, ok := s.decisionCache.Allowed(ctx, agentID, actionType, resource); ok {
+		return true, cachedReason, auditID, nil
+	}
+
+	// 1. Fetch agent (cached briefly; every agent write invalidates it)
+	agent, err := s.lookupCache.Agent(ctx, s.agentRepo, agentID)
 	if err != nil {
 		return false, "Agent not found", uuid.Nil, err
 	}
@@ -568,6 +1015,14 @@ func (s *AgentService) VerifyAction(
 		return false, "Agent is marked as compromised - all actions denied", auditID, nil
 	}
 
+	// 3.1 Check if agent key has expired
+	// KeyExpiresAt always refers to the CURRENT public key; rotation sets a fresh expiry,
+	// so agents inside a PreviousPublicKey grace period are unaffected by this check.
+	if agent.KeyExpiresAt != nil && time.Now().After(*agent.KeyExpiresAt) {
+		s.createKeyExpiredAlert(agent)
+		return false, "Agent key expired - rotate credentials", auditID, nil
+	}
+
 	// 4. ✅ CAPABILITY-BASED ACCESS CONTROL (CBAC)
 	// This is what prevents EchoLeak and similar attacks
 	//
@@ -586,7 +1041,8 @@ func (s *AgentService) VerifyAction(
 	// - Unclear approval chains (full audit trail via granted_by, granted_at)
 
 	// ✅ Fetch GRANTED capabilities (single source of truth for enforcement)
-	activeCapabilities, err := s.capabilityRepo.GetActiveCapabilitiesByAgentID(agentID)
+	// Cached like the agent; grants and revocations invalidate it, so no stale denial follows a grant
+	activeCapabilities, err := s.lookupCache.ActiveCapabilities(ctx, s.capabilityRepo, agentID)
 	if err != nil {
 		return false, fmt.Sprintf("Failed to fetch agent capabilities: %v", err), auditID, err
 	}
@@ -595,9 +1051,14 @@ func (s *AgentService) VerifyAction(
 	capabilityTypes := []string{}
 	hasCapability := false
 
+	now := s.clock()
 	for _, capability := range activeCapabilities {
+		// Expired grants no longer count as granted
+		if capability.IsExpired(now) {
+			continue
+		}
 		capabilityTypes = append(capabilityTypes, capability.CapabilityType)
-		if s.matchesCapability(actionType, resource, capability.CapabilityType) {
+		if s.matchesGrant(actionType, resource, capability, now) {
 			hasCapability = true
 		}
 	}
@@ -617,7 +1078,7 @@ func (s *AgentService) VerifyAction(
 		)
 		if err != nil {
 			// Policy evaluation failed - use safe default (block + alert)
-			fmt.Printf("⚠️  Policy evaluation failed: %v, using safe default (block + alert)\n", err)
+			logging.FromContext(ctx).Warn("policy evaluation failed, using safe default (block + alert)", "agent_id", agentID, "error", err)
 			shouldBlock = true
 			shouldAlert = true
 			policyName = "default_policy"
@@ -649,15 +1110,16 @@ func (s *AgentService) VerifyAction(
 			}
 
 			if err := s.alertRepo.Create(alert); err != nil {
-				fmt.Printf("⚠️  Warning: failed to create security alert: %v\n", err)
+				logging.FromContext(ctx).Warn("failed to create security alert", "agent_id", agentID, "error", err)
 			} else {
-				fmt.Printf("🚨 SECURITY ALERT: Capability violation for agent %s (policy: %s, action: %s)\n",
-					agent.Name, policyName, map[bool]string{true: "BLOCKED", false: "MONITORED"}[shouldBlock])
+				logging.FromContext(ctx).Warn("security alert: capability violation",
+					"agent_id", agentID, "policy", policyName, "action", actionType, "blocked", shouldBlock)
 			}
 		}
 
 		// 📝 CREATE VIOLATION RECORD for dashboard tracking
 		// This ensures the Violations tab shows all capability violations
+		violationSeverity := s.calculateViolationSeverity(agent, shouldBlock)
 		violation := &domain.CapabilityViolation{
 			AgentID:             agentID,
 			AttemptedCapability: actionType,
@@ -666,18 +1128,19 @@ func (s *AgentService) VerifyAction(
 				"attempted_action":     actionType,
 				"resource":             resource,
 			},
-			Severity:         s.calculateViolationSeverity(agent, shouldBlock),
-			TrustScoreImpact: s.calculateTrustScoreImpact(shouldBlock),
+			Severity:         violationSeverity,
+			TrustScoreImpact: s.calculateTrustScoreImpact(agent.OrganizationID, violationSeverity, shouldBlock),
 			IsBlocked:        shouldBlock,
 			SourceIP:         nil, // Could be passed from context if available
 			RequestMetadata:  metadata,
 		}
 
 		if err := s.capabilityRepo.CreateViolation(violation); err != nil {
-			fmt.Printf("⚠️  Warning: failed to create violation record: %v\n", err)
+			logging.FromContext(ctx).Warn("failed to create violation record", "agent_id", agentID, "error", err)
 		} else {
-			fmt.Printf("📝 VIOLATION RECORDED: Agent %s attempted %s (blocked: %v)\n",
-				agent.Name, actionType, shouldBlock)
+			metrics.RecordCapabilityViolation(violation.Severity)
+			logging.FromContext(ctx).Warn("capability violation recorded",
+				"agent_id", agentID, "action", actionType, "blocked", shouldBlock)
 		}
 
 		// ✅ APPLY TRUST SCORE IMPACT directly from violation
@@ -695,10 +1158,11 @@ func (s *AgentService) VerifyAction(
 
 		// Update agent's trust score in database
 		if err := s.agentRepo.UpdateTrustScore(agentID, newScore); err != nil {
-			fmt.Printf("⚠️  Warning: failed to update agent trust score: %v\n", err)
+			logging.FromContext(ctx).Warn("failed to update agent trust score", "agent_id", agentID, "error", err)
 		} else {
-			fmt.Printf("✅ Trust score updated after violation: %.2f%% → %.2f%% (impact: %d%%) for agent %s\n",
-				agent.TrustScore*100, newScore*100, violation.TrustScoreImpact, agent.Name)
+			metrics.UpdateTrustScore(agentID.String(), agent.Name, newScore)
+			logging.FromContext(ctx).Info("trust score updated after violation",
+				"agent_id", agentID, "previous_score", agent.TrustScore, "trust_score", newScore, "impact_percent", violation.TrustScoreImpact)
 		}
 
 		// Return enforcement decision from policy
@@ -709,8 +1173,8 @@ func (s *AgentService) VerifyAction(
 			), auditID, nil
 		} else {
 			// Policy says alert-only mode - allow the action but log it
-			fmt.Printf("⚠️  Capability violation ALLOWED by policy '%s' (alert-only mode): %s attempting %s\n",
-				policyName, agent.Name, actionType)
+			logging.FromContext(ctx).Warn("capability violation allowed by policy (alert-only mode)",
+				"agent_id", agentID, "policy", policyName, "action", actionType)
 			return true, fmt.Sprintf(
 				"Action allowed by security policy '%s' (alert-only mode) - capability violation logged",
 				policyName,
@@ -726,7 +1190,7 @@ func (s *AgentService) VerifyAction(
 		ctx, agent, actionType, resource, auditID,
 	)
 	if err != nil {
-		fmt.Printf("⚠️  Trust score policy evaluation failed: %v\n", err)
+		logging.FromContext(ctx).Warn("trust score policy evaluation failed", "agent_id", agentID, "error", err)
 	}
 	if trustScoreAlert {
 		s.createPolicyAlert(agent, "Trust Score Low", trustScorePolicyName, trustScoreBlocked,
@@ -740,16 +1204,18 @@ func (s *AgentService) VerifyAction(
 	}
 
 	// 6.2 Data Exfiltration Policy Evaluation
-	exfilBlocked, exfilAlert, exfilPolicyName, err := s.policyService.EvaluateDataExfiltration(
+	exfilBlocked, exfilAlert, exfilPolicyName, exfilDetails, err := s.policyService.EvaluateDataExfiltration(
 		ctx, agent, actionType, resource, auditID,
 	)
 	if err != nil {
-		fmt.Printf("⚠️  Data exfiltration policy evaluation failed: %v\n", err)
+		logging.FromContext(ctx).Warn("data exfiltration policy evaluation failed", "agent_id", agentID, "error", err)
 	}
 	if exfilAlert {
+		if exfilDetails == "" {
+			exfilDetails = fmt.Sprintf("Suspected data exfiltration pattern detected: %s on %s", actionType, resource)
+		}
 		s.createPolicyAlert(agent, "Data Exfiltration Attempt", exfilPolicyName, exfilBlocked,
-			fmt.Sprintf("Suspected data exfiltration pattern detected: %s on %s", actionType, resource),
-			domain.AlertSeverityCritical, auditID)
+			exfilDetails, domain.AlertSeverityCritical, auditID)
 	}
 	if exfilBlocked {
 		return false, fmt.Sprintf(
@@ -758,12 +1224,12 @@ func (s *AgentService) VerifyAction(
 		), auditID, nil
 	}
 
-	// 6.3 Unusual Activity Policy Evaluation (stub - needs historical data)
+	// 6.3 Unusual Activity Policy Evaluation
 	unusualBlocked, unusualAlert, unusualPolicyName, err := s.policyService.EvaluateUnusualActivity(
 		ctx, agent, actionType, resource, auditID,
 	)
 	if err != nil {
-		fmt.Printf("⚠️  Unusual activity policy evaluation failed: %v\n", err)
+		logging.FromContext(ctx).Warn("unusual activity policy evaluation failed", "agent_id", agentID, "error", err)
 	}
 	if unusualAlert {
 		s.createPolicyAlert(agent, "Unusual Activity", unusualPolicyName, unusualBlocked,
@@ -776,16 +1242,19 @@ func (s *AgentService) VerifyAction(
 		), auditID, nil
 	}
 
-	// 6.4 Config Drift Policy Evaluation (stub - needs baseline)
-	driftBlocked, driftAlert, driftPolicyName, err := s.policyService.EvaluateConfigDrift(
+	// 6.4 Config Drift Policy Evaluation
+	driftBlocked, driftAlert, driftPolicyName, driftDetails, err := s.policyService.EvaluateConfigDrift(
 		ctx, agent, actionType, resource, auditID,
 	)
 	if err != nil {
-		fmt.Printf("⚠️  Config drift policy evaluation failed: %v\n", err)
+		logging.FromContext(ctx).Warn("config drift policy evaluation failed", "agent_id", agentID, "error", err)
 	}
 	if driftAlert {
+		if driftDetails == "" {
+			driftDetails = "Agent configuration has drifted from baseline"
+		}
 		s.createPolicyAlert(agent, "Configuration Drift", driftPolicyName, driftBlocked,
-			"Agent configuration has drifted from baseline", domain.AlertSeverityWarning, auditID)
+			driftDetails, domain.AlertSeverityWarning, auditID)
 	}
 	if driftBlocked {
 		return false, fmt.Sprintf(
@@ -799,7 +1268,7 @@ func (s *AgentService) VerifyAction(
 		ctx, agent, actionType, resource, auditID,
 	)
 	if err != nil {
-		fmt.Printf("⚠️  Unauthorized access policy evaluation failed: %v\n", err)
+		logging.FromContext(ctx).Warn("unauthorized access policy evaluation failed", "agent_id", agentID, "error", err)
 	}
 	if unauthAlert {
 		s.createPolicyAlert(agent, "Unauthorized Access Attempt", unauthPolicyName, unauthBlocked,
@@ -812,32 +1281,130 @@ func (s *AgentService) VerifyAction(
 		), auditID, nil
 	}
 
+	// 6.6 MCP Allowlist Policy Evaluation
+	mcpBlocked, mcpAlert, mcpPolicyName, mcpDetails, err := s.policyService.EvaluateMCPAllowlist(
+		ctx, agent, actionType, metadata, auditID,
+	)
+	if err != nil {
+		logging.FromContext(ctx).Warn("MCP allowlist policy evaluation failed", "agent_id", agentID, "error", err)
+	}
+	if mcpAlert {
+		s.createPolicyAlert(agent, "Unregistered MCP Server", mcpPolicyName, mcpBlocked,
+			mcpDetails, domain.AlertSeverityHigh, auditID)
+	}
+	if mcpBlocked {
+		return false, fmt.Sprintf(
+			"Action blocked by MCP allowlist policy '%s': %s",
+			mcpPolicyName, mcpDetails,
+		), auditID, nil
+	}
+
 	// 7. ✅ ALL POLICIES PASSED - Action is allowed
-	return true, "Action matches registered capabilities and passes all security policies", auditID, nil
+	reason = "Action matches registered capabilities and passes all security policies"
+	s.decisionCache.RememberAllow(ctx, agentID, actionType, resource, reason)
+	return true, reason, auditID, nil
 }
 
 // matchesCapability checks if an action matches a registered capability
-// Supports exact matching and wildcard patterns
+// Supports exact matching, wildcard patterns and resource-scoped capabilities.
+//
+// Resource-scoped capabilities have the form "action:resourceGlob", e.g. "read_file:/data/*".
+// The action portion is matched against actionType and the glob against the resource
+// using path-style wildcards ("*" stays within one path segment, a trailing "/**"
+// matches any depth). Capabilities without a resource scope keep the legacy behavior.
 func (s *AgentService) matchesCapability(actionType string, resource string, capability string) bool {
-	// Exact match
-	if actionType == capability {
+	// Legacy: the whole capability is an action pattern (e.g. "file:read", "read_*"). A scoped
+	// capability is never matched whole, or an action type of "read_file:/data/*" would pass
+	// with any resource.
+	if !hasResourceScope(capability) {
+		return matchesActionPattern(actionType, capability)
+	}
+
+	// Resource-scoped: try every colon as the action/resource separator so that
+	// actions which themselves contain colons (e.g. "file:read:/data/*") still work
+	for i := 0; i < len(capability); i++ {
+		if capability[i] != ':' {
+			continue
+		}
+		actionPattern, resourceGlob := capability[:i], capability[i+1:]
+		if actionPattern == "" || resourceGlob == "" {
+			continue
+		}
+		if matchesActionPattern(actionType, actionPattern) && matchesResourceGlob(resource, resourceGlob) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// hasResourceScope reports whether a capability carries a path-style resource glob after a colon
+// ("read_file:/data/*"). Legacy action patterns such as "file:read" and "file:*" do not.
+func hasResourceScope(capability string) bool {
+	colon := strings.Index(capability, ":")
+	return colon >= 0 && strings.Contains(capability[colon+1:], "/")
+}
+
+// matchesGrant checks an action against a granted capability, honoring the grant's
+// per-capability expiry and, when set, its granted resource scope
+func (s *AgentService) matchesGrant(actionType string, resource string, capability *domain.AgentCapability, now time.Time) bool {
+	if capability.IsExpired(now) {
+		return false
+	}
+	if !s.matchesCapability(actionType, resource, capability.CapabilityType) {
+		return false
+	}
+	if scope := capability.GrantedScope(); scope != "" {
+		return matchesResourceGlob(resource, scope)
+	}
+	return true
+}
+
+// matchesActionPattern matches an action type against an exact or trailing-wildcard pattern
+// (e.g., "read_*" matches "read_email", "read_file")
+func matchesActionPattern(actionType string, pattern string) bool {
+	if actionType == pattern {
 		return true
 	}
 
-	// Wildcard patterns (e.g., "read_*" matches "read_email", "read_file")
-	if len(capability) > 0 && capability[len(capability)-1] == '*' {
-		prefix := capability[:len(capability)-1]
-		if len(actionType) >= len(prefix) && actionType[:len(prefix)] == prefix {
+	if strings.HasSuffix(pattern, "*") {
+		return strings.HasPrefix(actionType, strings.TrimSuffix(pattern, "*"))
+	}
+
+	return false
+}
+
+// matchesResourceGlob matches a resource path against a path-style glob
+func matchesResourceGlob(resource string, glob string) bool {
+	if resource == "" {
+		return false
+	}
+
+	// Normalize the resource so "/data/../etc/passwd" cannot escape a "/data/**" scope
+	if strings.HasPrefix(resource, "/") {
+		resource = path.Clean(resource)
+	}
+
+	if glob == "**" {
+		return true
+	}
+
+	// Recursive match: "/data/**" matches "/data" and everything below it
+	if strings.HasSuffix(glob, "/**") {
+		base := strings.TrimSuffix(glob, "/**")
+		if matched, err := path.Match(base, resource); err == nil && matched {
 			return true
 		}
+		for dir := resource; dir != "/" && dir != "." && dir != ""; dir = path.Dir(dir) {
+			if matched, err := path.Match(base, dir); err == nil && matched {
+				return true
+			}
+		}
+		return false
 	}
 
-	// Future: Add more sophisticated pattern matching here
-	// - Resource-based matching (e.g., "read:/data/*")
-	// - Time-based capabilities
-	// - Context-aware matching
-
-	return false
+	matched, err := path.Match(glob, resource)
+	return err == nil && matched
 }
 
 // LogActionResult logs the outcome of a verified action
@@ -891,7 +1458,7 @@ func (s *AgentService) LogActionResult(
 		)
 		if err != nil {
 			// Log but don't fail - audit logging shouldn't break business logic
-			fmt.Printf("Warning: Failed to record action result audit log: %v\n", err)
+			logging.FromContext(ctx).Warn("failed to record action result audit log", "agent_id", agentID, "error", err)
 		}
 	}
 
@@ -915,7 +1482,7 @@ func (s *AgentService) LogActionResult(
 				CreatedAt:      time.Now(),
 			}
 			if err := s.alertRepo.Create(alert); err != nil {
-				fmt.Printf("Warning: Failed to create action failure alert: %v\n", err)
+				logging.FromContext(ctx).Warn("failed to create action failure alert", "agent_id", agentID, "error", err)
 			}
 		}
 	}
@@ -1132,9 +1699,10 @@ func (s *AgentService) GetAgentMCPServers(
 
 // DetectMCPServersRequest represents request to auto-detect MCP servers from config
 type DetectMCPServersRequest struct {
-	ConfigPath   string `json:"config_path"`   // Path to Claude Desktop config file
-	AutoRegister bool   `json:"auto_register"` // Whether to auto-register discovered MCPs
-	DryRun       bool   `json:"dry_run"`       // Preview changes without applying
+	ConfigPath   string `json:"config_path"`      // Path to the editor's MCP config file
+	Source       string `json:"source,omitempty"` // Config format: claude_desktop_config, cursor or vscode (inferred when empty)
+	AutoRegister bool   `json:"auto_register"`    // Whether to auto-register discovered MCPs
+	DryRun       bool   `json:"dry_run"`          // Preview changes without applying
 }
 
 // DetectedMCPServer represents an MCP server detected from config
@@ -1143,8 +1711,9 @@ type DetectedMCPServer struct {
 	Command    string                 `json:"command"`
 	Args       []string               `json:"args"`
 	Env        map[string]string      `json:"env,omitempty"`
-	Confidence float64                `json:"confidence"` // 0-100
-	Source     string                 `json:"source"`     // "claude_desktop_config"
+	URL        string                 `json:"url,omitempty"` // Remote servers declared by URL instead of command
+	Confidence float64                `json:"confidence"`    // 0-100
+	Source     string                 `json:"source"`        // Config format, e.g. "claude_desktop_config", "cursor", "vscode"
 	Metadata   map[string]interface{} `json:"metadata,omitempty"`
 }
 
@@ -1158,7 +1727,9 @@ type DetectMCPServersResult struct {
 	ErrorsEncountered []string            `json:"errors_encountered,omitempty"`
 }
 
-// DetectMCPServersFromConfig auto-detects MCP servers from Claude Desktop config
+// DetectMCPServersFromConfig auto-detects MCP servers from an MCP config file (Claude Desktop, Cursor
+// or VS Code) on the server.
+// Used by the CLI; web users upload their config through DetectMCPServersFromUpload instead.
 func (s *AgentService) DetectMCPServersFromConfig(
 	ctx context.Context,
 	agentID uuid.UUID,
@@ -1172,12 +1743,52 @@ func (s *AgentService) DetectMCPServersFromConfig(
 		return nil, fmt.Errorf("config_path is required")
 	}
 
-	// 2. Parse Claude Desktop config file
-	detectedServers, err := s.parseClaudeDesktopConfig(req.ConfigPath)
+	// 2. Parse the config file
+	detectedServers, err := s.parseMCPConfigFile(req.ConfigPath, req.Source)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse config file: %w", err)
 	}
 
+	return s.mapDetectedMCPServers(ctx, agentID, detectedServers, req, mcpService, orgID, userID)
+}
+
+// ErrInvalidMCPConfig is returned when an uploaded config can't be parsed as the requested format
+var ErrInvalidMCPConfig = errors.New("invalid MCP config")
+
+// DetectMCPServersFromUpload auto-detects MCP servers from the contents of an MCP config
+// uploaded by the user. req.ConfigPath is ignored.
+func (s *AgentService) DetectMCPServersFromUpload(
+	ctx context.Context,
+	agentID uuid.UUID,
+	configData []byte,
+	req *DetectMCPServersRequest,
+	mcpService *MCPService,
+	orgID uuid.UUID,
+	userID uuid.UUID,
+) (*DetectMCPServersResult, error) {
+	detectedServers, err := ParseMCPConfigBytes(req.Source, configData)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidMCPConfig, err)
+	}
+	for i := range detectedServers {
+		detectedServers[i].Metadata = map[string]interface{}{
+			"config_source": "upload",
+		}
+	}
+
+	return s.mapDetectedMCPServers(ctx, agentID, detectedServers, req, mcpService, orgID, userID)
+}
+
+// mapDetectedMCPServers optionally registers detected MCP servers and adds them to the agent's talks_to list
+func (s *AgentService) mapDetectedMCPServers(
+	ctx context.Context,
+	agentID uuid.UUID,
+	detectedServers []DetectedMCPServer,
+	req *DetectMCPServersRequest,
+	mcpService *MCPService,
+	orgID uuid.UUID,
+	userID uuid.UUID,
+) (*DetectMCPServersResult, error) {
 	// 3. If dry run, return immediately with detected servers
 	if req.DryRun {
 		return &DetectMCPServersResult{
@@ -1194,13 +1805,17 @@ func (s *AgentService) DetectMCPServersFromConfig(
 	if req.AutoRegister {
 		for _, detected := range detectedServers {
 			// Try to register the MCP server
-			// Note: CreateMCPServerRequest expects URL, but Claude config uses command/args
-			// We'll use the name as a placeholder URL for now
+			// Note: CreateMCPServerRequest expects URL, but local servers are declared by command/args
+			// We'll use the name as a placeholder URL for those
 			registerReq := &CreateMCPServerRequest{
 				Name:        detected.Name,
-				Description: fmt.Sprintf("Auto-detected from Claude Desktop config. Command: %s", detected.Command),
+				Description: fmt.Sprintf("Auto-detected from %s config. Command: %s", detected.Source, detected.Command),
 				URL:         fmt.Sprintf("mcp://%s", detected.Name), // Placeholder URL for local MCP servers
 			}
+			if detected.URL != "" {
+				registerReq.Description = fmt.Sprintf("Auto-detected from %s config", detected.Source)
+				registerReq.URL = detected.URL
+			}
 
 			_, err := mcpService.CreateMCPServer(ctx, registerReq, orgID, userID, nil)
 			if err != nil {
@@ -1237,8 +1852,9 @@ func (s *AgentService) DetectMCPServersFromConfig(
 	}, nil
 }
 
-// parseClaudeDesktopConfig parses Claude Desktop config JSON file
-func (s *AgentService) parseClaudeDesktopConfig(configPath string) ([]DetectedMCPServer, error) {
+// parseMCPConfigFile parses an MCP config file. When source is empty it is inferred from the
+// path (.cursor/, .vscode/, VS Code user settings) and then from the file contents.
+func (s *AgentService) parseMCPConfigFile(configPath, source string) ([]DetectedMCPServer, error) {
 	// Expand tilde (~) in path to home directory
 	if len(configPath) > 0 && configPath[0] == '~' {
 		homeDir, err := os.UserHomeDir()
@@ -1254,34 +1870,17 @@ func (s *AgentService) parseClaudeDesktopConfig(configPath string) ([]DetectedMC
 		return nil, fmt.Errorf("failed to read config file: %w", err)
 	}
 
-	// Parse JSON
-	var config struct {
-		MCPServers map[string]struct {
-			Command string            `json:"command"`
-			Args    []string          `json:"args"`
-			Env     map[string]string `json:"env"`
-		} `json:"mcpServers"`
+	if source == "" {
+		source = mcpConfigSourceFromPath(configPath)
 	}
-
-	if err := json.Unmarshal(data, &config); err != nil {
-		return nil, fmt.Errorf("failed to parse config JSON: %w", err)
+	detectedServers, err := ParseMCPConfigBytes(source, data)
+	if err != nil {
+		return nil, err
 	}
-
-	// Convert to DetectedMCPServer structs
-	detectedServers := []DetectedMCPServer{}
-	for name, serverConfig := range config.MCPServers {
-		detected := DetectedMCPServer{
-			Name:       name,
-			Command:    serverConfig.Command,
-			Args:       serverConfig.Args,
-			Env:        serverConfig.Env,
-			Confidence: 100.0, // High confidence for config file detection
-			Source:     "claude_desktop_config",
-			Metadata: map[string]interface{}{
-				"config_path": configPath,
-			},
+	for i := range detectedServers {
+		detectedServers[i].Metadata = map[string]interface{}{
+			"config_path": configPath,
 		}
-		detectedServers = append(detectedServers, detected)
 	}
 
 	return detectedServers, nil
@@ -1299,19 +1898,11 @@ func (s *AgentService) SuspendAgent(ctx context.Context, id uuid.UUID) error {
 		return fmt.Errorf("agent not found: %w", err)
 	}
 
-	// Update status to suspended
-	agent.Status = domain.AgentStatusSuspended
-
-	if err := s.agentRepo.Update(agent); err != nil {
+	if err := s.transitionStatus(ctx, agent, domain.AgentStatusSuspended, domain.WebhookEventAgentSuspended); err != nil {
 		return fmt.Errorf("failed to suspend agent: %w", err)
 	}
-
-	// Recalculate trust score (suspension affects trust)
-	trustScore, err := s.trustCalc.Calculate(agent)
-	if err == nil {
-		agent.TrustScore = trustScore.Score
-		s.agentRepo.Update(agent)
-		s.trustScoreRepo.Create(trustScore)
+	if s.cacheInvalidator != nil {
+		s.cacheInvalidator.InvalidateAgent(ctx, id)
 	}
 
 	return nil
@@ -1324,26 +1915,160 @@ func (s *AgentService) ReactivateAgent(ctx context.Context, id uuid.UUID) error
 		return fmt.Errorf("agent not found: %w", err)
 	}
 
-	// Update status to verified
-	now := time.Now()
-	agent.Status = domain.AgentStatusVerified
-	agent.VerifiedAt = &now
-
-	if err := s.agentRepo.Update(agent); err != nil {
+	if err := s.transitionStatus(ctx, agent, domain.AgentStatusVerified, domain.WebhookEventAgentReactivated); err != nil {
 		return fmt.Errorf("failed to reactivate agent: %w", err)
 	}
 
-	// Recalculate trust score (reactivation affects trust)
+	s.captureBaseline(ctx, agent)
+
+	return nil
+}
+
+// transitionStatus moves the agent to status and recalculates its trust score for it. The agent
+// is persisted together with an event webhook when the status actually changes, so downstream
+// systems only hear about real transitions.
+func (s *AgentService) transitionStatus(ctx context.Context, agent *domain.Agent, status domain.AgentStatus, event domain.WebhookEvent) error {
+	previousStatus, previousTrustScore := agent.Status, agent.TrustScore
+	now := time.Now().UTC()
+	agent.Status = status
+	if status == domain.AgentStatusVerified {
+		agent.VerifiedAt = &now
+	}
+
+	// Recalculate trust score (the status change affects trust)
 	trustScore, err := s.trustCalc.Calculate(agent)
-	if err == nil {
+	if err != nil {
+		logging.FromContext(ctx).Warn("failed to recalculate trust score", "agent_id", agent.ID, "error", err)
+		trustScore = nil
+	} else {
 		agent.TrustScore = trustScore.Score
-		s.agentRepo.Update(agent)
-		s.trustScoreRepo.Create(trustScore)
 	}
 
+	var messages []*domain.OutboxMessage
+	if previousStatus != status {
+		webhookMessage, err := domain.NewWebhookOutboxMessage(agent.OrganizationID, event, domain.WebhookResourceAgent, domain.AgentStatusWebhookData{
+			AgentID:            agent.ID,
+			AgentName:          agent.Name,
+			PreviousStatus:     previousStatus,
+			Status:             status,
+			PreviousTrustScore: previousTrustScore,
+			TrustScore:         agent.TrustScore,
+			ChangedAt:          now,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to build %s webhook event: %w", event, err)
+		}
+		messages = append(messages, webhookMessage)
+	}
+
+	if err := s.agentRepo.UpdateStatus(agent, messages...); err != nil {
+		return err
+	}
+	if trustScore != nil {
+		s.trustScoreRepo.Create(trustScore)
+	}
 	return nil
 }
 
+// agentCompromiseWebhookData is the payload of agent.compromised and agent.uncompromised events
+type agentCompromiseWebhookData struct {
+	AgentID        uuid.UUID          `json:"agentId"`
+	AgentName      string             `json:"agentName"`
+	Compromised    bool               `json:"compromised"`
+	Reason         string             `json:"reason,omitempty"`
+	PreviousStatus domain.AgentStatus `json:"previousStatus"`
+	Status         domain.AgentStatus `json:"status"`
+	TrustScore     float64            `json:"trustScore"`
+	ChangedBy      uuid.UUID          `json:"changedBy"`
+	ChangedAt      time.Time          `json:"changedAt"`
+}
+
+// CompromiseAgent flags the agent as compromised by userID and suspends it. A critical alert and
+// an agent.compromised webhook event are queued in the same transaction, and the agent's cached
+// authorization data is dropped so VerifyAction denies its very next action.
+func (s *AgentService) CompromiseAgent(ctx context.Context, id, userID uuid.UUID, reason string) (*domain.Agent, error) {
+	agent, err := s.agentRepo.GetByID(id)
+	if err != nil {
+		return nil, fmt.Errorf("agent not found: %w", err)
+	}
+	if agent.IsCompromised {
+		return nil, ErrAgentAlreadyCompromised
+	}
+
+	now := time.Now().UTC()
+	description := fmt.Sprintf(
+		"Agent '%s' was flagged as compromised and suspended. All of its actions are denied until the flag is cleared and the agent is reactivated.",
+		agent.DisplayName,
+	)
+	if reason != "" {
+		description += " Reason: " + reason
+	}
+	alertMessage, err := domain.NewAlertOutboxMessage(&domain.Alert{
+		OrganizationID: agent.OrganizationID,
+		AlertType:      domain.AlertSecurityBreach,
+		Severity:       domain.AlertSeverityCritical,
+		Title:          fmt.Sprintf("Agent Compromised: %s", agent.DisplayName),
+		Description:    description,
+		ResourceType:   "agent",
+		ResourceID:     agent.ID,
+		IsAcknowledged: false,
+		CreatedAt:      now,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to build compromise alert: %w", err)
+	}
+	webhookMessage, err := domain.NewWebhookOutboxMessage(agent.OrganizationID, domain.WebhookEventAgentCompromised, domain.WebhookResourceAgent,
+		agentCompromiseWebhookData{
+			AgentID: agent.ID, AgentName: agent.Name, Compromised: true, Reason: reason,
+			PreviousStatus: agent.Status, Status: domain.AgentStatusSuspended, TrustScore: agent.TrustScore,
+			ChangedBy: userID, ChangedAt: now,
+		})
+	if err != nil {
+		return nil, fmt.Errorf("failed to build compromise webhook event: %w", err)
+	}
+
+	if err := s.agentRepo.MarkCompromisedBy(id, userID, reason, now, alertMessage, webhookMessage); err != nil {
+		return nil, fmt.Errorf("failed to mark agent as compromised: %w", err)
+	}
+	if s.cacheInvalidator != nil {
+		s.cacheInvalidator.InvalidateAgent(ctx, id)
+	}
+	logging.FromContext(ctx).Warn("agent flagged as compromised", "agent_id", id, "user_id", userID)
+
+	return s.agentRepo.GetByID(id)
+}
+
+// UncompromiseAgent clears the agent's compromise flag and queues an agent.uncompromised webhook
+// event. The agent stays suspended; ReactivateAgent restores it once it is trusted again.
+func (s *AgentService) UncompromiseAgent(ctx context.Context, id, userID uuid.UUID) (*domain.Agent, error) {
+	agent, err := s.agentRepo.GetByID(id)
+	if err != nil {
+		return nil, fmt.Errorf("agent not found: %w", err)
+	}
+	if !agent.IsCompromised {
+		return nil, ErrAgentNotCompromised
+	}
+
+	webhookMessage, err := domain.NewWebhookOutboxMessage(agent.OrganizationID, domain.WebhookEventAgentUncompromised, domain.WebhookResourceAgent,
+		agentCompromiseWebhookData{
+			AgentID: agent.ID, AgentName: agent.Name, Compromised: false,
+			PreviousStatus: agent.Status, Status: agent.Status, TrustScore: agent.TrustScore,
+			ChangedBy: userID, ChangedAt: time.Now().UTC(),
+		})
+	if err != nil {
+		return nil, fmt.Errorf("failed to build uncompromise webhook event: %w", err)
+	}
+
+	if err := s.agentRepo.ClearCompromised(id, webhookMessage); err != nil {
+		return nil, fmt.Errorf("failed to clear compromise flag: %w", err)
+	}
+	if s.cacheInvalidator != nil {
+		s.cacheInvalidator.InvalidateAgent(ctx, id)
+	}
+
+	return s.agentRepo.GetByID(id)
+}
+
 // RotateCredentials rotates an agent's cryptographic credentials by generating new Ed25519 keypair
 func (s *AgentService) RotateCredentials(ctx context.Context, id uuid.UUID) (publicKey, privateKey string, err error) {
 	// 1. Fetch agent
@@ -1379,8 +2104,8 @@ func (s *AgentService) RotateCredentials(ctx context.Context, id uuid.UUID) (pub
 	now := time.Now()
 	agent.KeyCreatedAt = &now
 
-	// Set key expiration to 1 year from now (standard practice)
-	keyExpiry := time.Now().AddDate(1, 0, 0)
+	// Set key expiration from the organization's rotation policy (default 1 year)
+	keyExpiry := s.getKeyExpiry(agent.OrganizationID, now)
 	agent.KeyExpiresAt = &keyExpiry
 
 	// Increment rotation count
@@ -1420,8 +2145,8 @@ func (s *AgentService) UpdateAgentPublicKey(ctx context.Context, agentID uuid.UU
 	now := time.Now()
 	agent.KeyCreatedAt = &now
 
-	// Set key expiration to 1 year from now
-	keyExpiry := time.Now().AddDate(1, 0, 0)
+	// Set key expiration from the organization's rotation policy (default 1 year)
+	keyExpiry := s.getKeyExpiry(agent.OrganizationID, now)
 	agent.KeyExpiresAt = &keyExpiry
 
 	// Increment rotation count
@@ -1435,6 +2160,33 @@ func (s *AgentService) UpdateAgentPublicKey(ctx context.Context, agentID uuid.UU
 	return nil
 }
 
+// UpdateAgentLabels validates and replaces an agent's labels
+func (s *AgentService) UpdateAgentLabels(ctx context.Context, agentID uuid.UUID, labels map[string]string) (*domain.Agent, error) {
+	if err := domain.ValidateAgentLabels(labels); err != nil {
+		return nil, err
+	}
+
+	if err := s.agentRepo.UpdateLabels(agentID, labels); err != nil {
+		return nil, fmt.Errorf("failed to update agent labels: %w", err)
+	}
+
+	return s.agentRepo.GetByID(agentID)
+}
+
+// UpdateAgentOAuthClientID sets the OAuth client whose tokens verify the agent; "" clears it
+func (s *AgentService) UpdateAgentOAuthClientID(ctx context.Context, agentID uuid.UUID, clientID string) (*domain.Agent, error) {
+	clientID = strings.TrimSpace(clientID)
+	if len(clientID) > domain.MaxOAuthClientIDLength {
+		return nil, fmt.Errorf("%w: at most %d characters", domain.ErrInvalidOAuthClientID, domain.MaxOAuthClientIDLength)
+	}
+
+	if err := s.agentRepo.UpdateOAuthClientID(agentID, clientID); err != nil {
+		return nil, fmt.Errorf("failed to update agent OAuth client: %w", err)
+	}
+
+	return s.agentRepo.GetByID(agentID)
+}
+
 // UpdateLastActive updates the last_active timestamp for an agent
 func (s *AgentService) UpdateLastActive(ctx context.Context, agentID uuid.UUID) error {
 	return s.agentRepo.UpdateLastActive(ctx, agentID)
@@ -1463,13 +2215,25 @@ func (s *AgentService) calculateViolationSeverity(agent *domain.Agent, isBlocked
 }
 
 // calculateTrustScoreImpact calculates the trust score penalty for a violation
-func (s *AgentService) calculateTrustScoreImpact(isBlocked bool) int {
-	if isBlocked {
-		// Blocked violations have higher impact
-		return -10
+func (s *AgentService) calculateTrustScoreImpact(orgID uuid.UUID, severity string, isBlocked bool) int {
+	// Organizations tune the penalty per severity, separately for blocked and alert-only violations
+	return violationPenalties(s.orgRepo, orgID).TrustScoreImpact(severity, isBlocked)
+}
+
+// violationPenalties returns the organization's violation trust score penalties, falling back to
+// the defaults when they cannot be loaded
+func violationPenalties(orgRepo domain.OrganizationRepository, orgID uuid.UUID) domain.ViolationPenalties {
+	if orgRepo == nil {
+		return domain.DefaultViolationPenalties()
+	}
+
+	org, err := orgRepo.GetByID(orgID)
+	if err != nil || org == nil {
+		slog.Warn("failed to load violation penalties, using defaults", "org_id", orgID, "error", err)
+		return domain.DefaultViolationPenalties()
 	}
-	// Alert-only violations have lower impact
-	return -5
+
+	return org.EffectiveViolationPenalties()
 }
 
 // createPolicyAlert creates a security alert for policy violations
@@ -1505,13 +2269,191 @@ func (s *AgentService) createPolicyAlert(
 	}
 
 	if err := s.alertRepo.Create(alert); err != nil {
-		fmt.Printf("⚠️  Warning: failed to create security alert: %v\n", err)
+		slog.Warn("failed to create security alert", "agent_id", agent.ID, "error", err)
 	} else {
-		fmt.Printf("🚨 SECURITY ALERT: %s for agent %s (policy: %s, action: %s)\n",
-			alertType, agent.Name, policyName, map[bool]string{true: "BLOCKED", false: "MONITORED"}[isBlocked])
+		slog.Warn("security alert",
+			"alert_type", alertType, "agent_id", agent.ID, "policy", policyName, "blocked", isBlocked)
 	}
 }
 
+// captureBaseline records the agent's verified configuration; failures are logged, not returned
+func (s *AgentService) captureBaseline(ctx context.Context, agent *domain.Agent) {
+	if s.policyService == nil {
+		return
+	}
+	if err := s.policyService.CaptureAgentBaseline(ctx, agent); err != nil {
+		logging.FromContext(ctx).Warn("failed to capture baseline", "agent_id", agent.ID, "error", err)
+	}
+}
+
+// createKeyExpiredAlert creates a warning alert when an agent with an expired key attempts an action
+func (s *AgentService) createKeyExpiredAlert(agent *domain.Agent) {
+	if s.alertRepo == nil {
+		return
+	}
+
+	// Check for existing unacknowledged alert to avoid one alert per denied action
+	existing, _ := s.alertRepo.GetUnacknowledgedByResourceID(agent.ID)
+	for _, a := range existing {
+		if a.AlertType == domain.AlertKeyExpired {
+			return
+		}
+	}
+
+	agentName := agent.DisplayName
+	if agentName == "" {
+		agentName = agent.Name
+	}
+
+	alert := &domain.Alert{
+		ID:             uuid.New(),
+		OrganizationID: agent.OrganizationID,
+		AlertType:      domain.AlertKeyExpired,
+		Severity:       domain.AlertSeverityWarning,
+		Title:          fmt.Sprintf("Agent Key Expired: %s", agentName),
+		Description: fmt.Sprintf(
+			"Agent '%s' attempted an action with a key that expired at %s. All actions are denied until credentials are rotated.",
+			agentName, agent.KeyExpiresAt.Format(time.RFC3339),
+		),
+		ResourceType:   "agent",
+		ResourceID:     agent.ID,
+		IsAcknowledged: false,
+		CreatedAt:      time.Now(),
+	}
+
+	if err := s.alertRepo.Create(alert); err != nil {
+		slog.Warn("failed to create key expired alert", "agent_id", agent.ID, "error", err)
+	}
+}
+
+// Key expiry scan defaults; override with KEY_EXPIRY_SCAN_INTERVAL (Go duration)
+// and KEY_EXPIRY_ALERT_LEAD_DAYS
+const (
+	DefaultKeyExpiryScanInterval  = time.Hour
+	DefaultKeyExpiryAlertLeadDays = 14
+)
+
+// KeyExpiryScanSettingsFromEnv returns the key expiry scan interval and alert lead time
+func KeyExpiryScanSettingsFromEnv() (interval, leadTime time.Duration) {
+	interval = DefaultKeyExpiryScanInterval
+	if value, err := time.ParseDuration(os.Getenv("KEY_EXPIRY_SCAN_INTERVAL")); err == nil && value > 0 {
+		interval = value
+	}
+
+	leadDays := DefaultKeyExpiryAlertLeadDays
+	if value, err := strconv.Atoi(os.Getenv("KEY_EXPIRY_ALERT_LEAD_DAYS")); err == nil && value > 0 {
+		leadDays = value
+	}
+
+	return interval, time.Duration(leadDays) * 24 * time.Hour
+}
+
+// StartKeyExpiryScanner runs ScanExpiringKeys every interval until ctx is cancelled
+func (s *AgentService) StartKeyExpiryScanner(ctx context.Context, interval, leadTime time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := s.ScanExpiringKeys(ctx, time.Now(), leadTime); err != nil {
+				logging.FromContext(ctx).Warn("key expiry scanner failed", "error", err)
+			}
+		}
+	}
+}
+
+// ScanExpiringKeys creates a warning alert for every agent whose key expires
+// within leadTime of now. Each agent is alerted at most once per expiry window:
+// agents with an unacknowledged key-expiring alert, or one raised since the
+// window opened, are skipped. Returns the number of alerts created.
+func (s *AgentService) ScanExpiringKeys(ctx context.Context, now time.Time, leadTime time.Duration) (int, error) {
+	if s.alertRepo == nil {
+		return 0, nil
+	}
+
+	agents, err := s.agentRepo.GetByKeyExpiringBetween(now, now.Add(leadTime))
+	if err != nil {
+		return 0, err
+	}
+
+	created := 0
+	for _, agent := range agents {
+		if agent.KeyExpiresAt == nil {
+			continue
+		}
+
+		windowStart := agent.KeyExpiresAt.Add(-leadTime)
+		alerted, err := s.hasKeyExpiringAlert(agent.ID, windowStart)
+		if err != nil {
+			logging.FromContext(ctx).Warn("failed to check key expiring alerts", "agent_id", agent.ID, "error", err)
+			continue
+		}
+		if alerted {
+			continue
+		}
+
+		agentName := agent.DisplayName
+		if agentName == "" {
+			agentName = agent.Name
+		}
+		daysLeft := int(math.Ceil(agent.KeyExpiresAt.Sub(now).Hours() / 24))
+
+		alert := &domain.Alert{
+			ID:             uuid.New(),
+			OrganizationID: agent.OrganizationID,
+			AlertType:      domain.AlertKeyExpiring,
+			Severity:       domain.AlertSeverityWarning,
+			Title:          fmt.Sprintf("Agent Key Expiring: %s", agentName),
+			Description: fmt.Sprintf(
+				"Agent '%s' has a key that expires at %s (in %d day(s)). Rotate its credentials before then or all of its actions will be denied.",
+				agentName, agent.KeyExpiresAt.Format(time.RFC3339), daysLeft,
+			),
+			ResourceType:   "agent",
+			ResourceID:     agent.ID,
+			IsAcknowledged: false,
+			CreatedAt:      now,
+		}
+
+		if err := s.alertRepo.Create(alert); err != nil {
+			logging.FromContext(ctx).Warn("failed to create key expiring alert", "agent_id", agent.ID, "error", err)
+			continue
+		}
+		created++
+	}
+
+	return created, nil
+}
+
+// hasKeyExpiringAlert reports whether the agent already has an unacknowledged
+// key-expiring alert or one created since windowStart
+func (s *AgentService) hasKeyExpiringAlert(agentID uuid.UUID, windowStart time.Time) (bool, error) {
+	unacknowledged, err := s.alertRepo.GetUnacknowledgedByResourceID(agentID)
+	if err != nil {
+		return false, err
+	}
+	for _, a := range unacknowledged {
+		if a.AlertType == domain.AlertKeyExpiring {
+			return true, nil
+		}
+	}
+
+	// Alerts are returned newest first, so recent ones are within the first page
+	recent, err := s.alertRepo.GetByResourceID(agentID, 20, 0)
+	if err != nil {
+		return false, err
+	}
+	for _, a := range recent {
+		if a.AlertType == domain.AlertKeyExpiring && !a.CreatedAt.Before(windowStart) {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
 // CreateCapabilityViolation creates a capability violation record for dashboard tracking
 func (s *AgentService) CreateCapabilityViolation(
 	ctx context.Context,
@@ -1540,27 +2482,24 @@ func (s *AgentService) CreateCapabilityViolation(
 
 	// Map alert severity to violation severity (frontend expects: low, medium, high, critical)
 	violationSeverity := "low" // Default
-	trustImpact := -5          // Default for low severity
 
 	switch severity {
 	case "critical":
 		violationSeverity = "critical"
-		trustImpact = -15
 	case "high":
 		violationSeverity = "high"
-		trustImpact = -10
 	case "warning":
 		violationSeverity = "medium"
-		trustImpact = -7
 	case "info":
 		violationSeverity = "low"
-		trustImpact = -5
 	default:
 		// If severity doesn't match known values, treat as low
 		violationSeverity = "low"
-		trustImpact = -5
 	}
 
+	// SDK-reported violations are alert-only: the action was not blocked
+	trustImpact := s.calculateTrustScoreImpact(agent.OrganizationID, violationSeverity, false)
+
 	// Create violation record
 	violation := &domain.CapabilityViolation{
 		AgentID:             agentID,
@@ -1580,22 +2519,24 @@ func (s *AgentService) CreateCapabilityViolation(
 	if err := s.capabilityRepo.CreateViolation(violation); err != nil {
 		return fmt.Errorf("failed to create violation: %w", err)
 	}
+	metrics.RecordCapabilityViolation(violation.Severity)
 
 	// Recalculate trust score breakdown after violation
 	// This ensures the trust_scores table stays in sync with agents.trust_score
 	updatedScore, err := s.trustCalc.Calculate(agent)
 	if err != nil {
-		fmt.Printf("⚠️  Warning: failed to recalculate trust score: %v\n", err)
+		logging.FromContext(ctx).Warn("failed to recalculate trust score", "agent_id", agentID, "error", err)
 	} else {
 		// Store the new score breakdown
 		if err := s.trustScoreRepo.Create(updatedScore); err != nil {
-			fmt.Printf("⚠️  Warning: failed to store trust score breakdown: %v\n", err)
+			logging.FromContext(ctx).Warn("failed to store trust score breakdown", "agent_id", agentID, "error", err)
 		}
 		// Update agent's trust_score field to keep it in sync
 		if err := s.agentRepo.UpdateTrustScore(agentID, updatedScore.Score); err != nil {
-			fmt.Printf("⚠️  Warning: failed to update agent trust score: %v\n", err)
+			logging.FromContext(ctx).Warn("failed to update agent trust score", "agent_id", agentID, "error", err)
 		} else {
-			fmt.Printf("✅ Trust score recalculated after violation: %.2f%% for agent %s\n", updatedScore.Score*100, agent.Name)
+			metrics.UpdateTrustScore(agentID.String(), agent.Name, updatedScore.Score)
+			logging.FromContext(ctx).Info("trust score recalculated after violation", "agent_id", agentID, "trust_score", updatedScore.Score)
 		}
 	}
 
@@ -2,7 +2,10 @@ package application
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
+	"math"
+	"strings"
 	"testing"
 	"time"
 
@@ -11,6 +14,7 @@ import (
 	"github.com/opena2a/identity/backend/internal/domain"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 )
 
 // ===========================
@@ -132,6 +136,11 @@ func (m *AgentServiceMockSecurityPolicyRepository) Delete(id uuid.UUID) error {
 	return args.Error(0)
 }
 
+func (m *AgentServiceMockSecurityPolicyRepository) ApplyChanges(created, updated []*domain.SecurityPolicy, deleted []uuid.UUID) error {
+	args := m.Called(created, updated, deleted)
+	return args.Error(0)
+}
+
 // ===========================
 // Test Utilities
 // ===========================
@@ -228,13 +237,57 @@ func TestAgentService_DeleteAgent_Success(t *testing.T) {
 	service := &AgentService{agentRepo: mockAgentRepo}
 
 	agentID := uuid.New()
-	mockAgentRepo.On("Delete", agentID).Return(nil)
+	mockAgentRepo.On("SoftDelete", agentID).Return(nil)
 
 	ctx := context.Background()
 	err := service.DeleteAgent(ctx, agentID)
 
 	assert.NoError(t, err)
 	mockAgentRepo.AssertExpectations(t)
+	mockAgentRepo.AssertNotCalled(t, "Delete", agentID)
+}
+
+func TestAgentService_HardDeleteAgent_Success(t *testing.T) {
+	mockAgentRepo := new(MockAgentRepository)
+	service := &AgentService{agentRepo: mockAgentRepo}
+
+	agentID := uuid.New()
+	mockAgentRepo.On("Delete", agentID).Return(nil)
+
+	err := service.HardDeleteAgent(context.Background(), agentID)
+
+	assert.NoError(t, err)
+	mockAgentRepo.AssertExpectations(t)
+	mockAgentRepo.AssertNotCalled(t, "SoftDelete", agentID)
+}
+
+func TestAgentService_VerifyAgent_CapturesBaseline(t *testing.T) {
+	mockAgentRepo := new(MockAgentRepository)
+	mockTrustCalc := new(AgentServiceMockTrustScoreCalculator)
+	mockBaselineRepo := new(MockAgentBaselineRepository)
+	service := &AgentService{
+		agentRepo:     mockAgentRepo,
+		trustCalc:     mockTrustCalc,
+		policyService: &SecurityPolicyService{baselineRepo: mockBaselineRepo},
+	}
+
+	agent := createTestAgentForService()
+	agent.Status = domain.AgentStatusPending
+
+	mockAgentRepo.On("GetByID", agent.ID).Return(agent, nil)
+	mockAgentRepo.On("UpdateStatus", agent, mock.Anything).Return(nil)
+	mockTrustCalc.On("Calculate", agent).Return(nil, assert.AnError)
+	mockBaselineRepo.On("Upsert", mock.MatchedBy(func(b *domain.AgentBaseline) bool {
+		return b.AgentID == agent.ID &&
+			assert.ObjectsAreEqual(agent.TalksTo, b.TalksTo) &&
+			assert.ObjectsAreEqual(agent.Capabilities, b.Capabilities) &&
+			b.KeyAlgorithm == agent.KeyAlgorithm
+	})).Return(nil)
+
+	err := service.VerifyAgent(context.Background(), agent.ID)
+
+	assert.NoError(t, err)
+	mockBaselineRepo.AssertExpectations(t)
 }
 
 // ===========================
@@ -326,6 +379,61 @@ func TestAgentService_UpdateTrustScore_InvalidScore(t *testing.T) {
 	}
 }
 
+func TestAgentService_UpdateTrustScore_PolicyCriticalDropThreshold(t *testing.T) {
+	tests := []struct {
+		name         string
+		policies     []*domain.SecurityPolicy
+		wantSeverity domain.AlertSeverity
+	}{
+		{
+			name:         "default thresholds treat a 15% drop as a warning",
+			policies:     []*domain.SecurityPolicy{},
+			wantSeverity: domain.AlertSeverityWarning,
+		},
+		{
+			name: "org critical threshold of 12% escalates a 15% drop",
+			policies: []*domain.SecurityPolicy{{
+				Name:       "Strict Trust Drops",
+				PolicyType: domain.PolicyTypeTrustScoreDrop,
+				Rules:      map[string]interface{}{"critical_drop_threshold": 0.12},
+				AppliesTo:  "all",
+				IsEnabled:  true,
+			}},
+			wantSeverity: domain.AlertSeverityCritical,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockAgentRepo := new(MockAgentRepository)
+			mockAlertRepo := new(MockAlertRepository)
+			mockPolicyRepo := new(AgentServiceMockSecurityPolicyRepository)
+			service := &AgentService{
+				agentRepo:     mockAgentRepo,
+				alertRepo:     mockAlertRepo,
+				policyService: &SecurityPolicyService{policyRepo: mockPolicyRepo},
+			}
+
+			agent := createTestAgentForService()
+			agent.TrustScore = 0.80
+			newScore := 0.68 // 15% drop, still above the low score threshold
+
+			mockAgentRepo.On("GetByID", agent.ID).Return(agent, nil)
+			mockAgentRepo.On("UpdateTrustScore", agent.ID, newScore).Return(nil)
+			mockPolicyRepo.On("GetByType", agent.OrganizationID, domain.PolicyTypeTrustScoreDrop).Return(tt.policies, nil)
+			mockAlertRepo.On("GetUnacknowledged", agent.OrganizationID).Return([]*domain.Alert{}, nil)
+			mockAlertRepo.On("Create", mock.MatchedBy(func(alert *domain.Alert) bool {
+				return alert.AlertType == domain.AlertTrustScoreDrop && alert.Severity == tt.wantSeverity
+			})).Return(nil)
+
+			err := service.UpdateTrustScore(context.Background(), agent.ID, newScore)
+
+			assert.NoError(t, err)
+			mockAlertRepo.AssertExpectations(t)
+		})
+	}
+}
+
 // ===========================
 // VerifyAction Tests (EchoLeak Prevention - CRITICAL)
 // ===========================
@@ -398,6 +506,86 @@ func TestAgentService_VerifyAction_AgentNotVerified(t *testing.T) {
 	mockAgentRepo.AssertExpectations(t)
 }
 
+func TestAgentService_ValidateVerifyAction(t *testing.T) {
+	orgID := uuid.New()
+	mockCapabilityRepo := new(MockCapabilityRepository)
+	mockCapabilityRepo.On("GetCatalog", orgID).Return([]*domain.CapabilityCatalogEntry{
+		{CapabilityType: domain.CapabilityFileRead},
+		{CapabilityType: "read_*"},
+		{CapabilityType: "db:query:analytics.*"},
+	}, nil)
+	service := &AgentService{capabilityRepo: mockCapabilityRepo}
+
+	tests := []struct {
+		name          string
+		actionType    string
+		resource      string
+		wantFields    []string
+		wantInCatalog bool
+	}{
+		{name: "catalog type", actionType: domain.CapabilityFileRead, resource: "/data/report.csv", wantInCatalog: true},
+		{name: "wildcard catalog type", actionType: "read_email", resource: "inbox", wantInCatalog: true},
+		{name: "resource-scoped catalog type", actionType: domain.CapabilityDBQuery, resource: "analytics.events", wantInCatalog: true},
+		// Unknown and legacy names are not rejected; VerifyAction records them as violations
+		{name: "unknown action type", actionType: "file:raed", resource: "/data/report.csv"},
+		{name: "legacy action type", actionType: "database_query", resource: "SELECT 1"},
+		{name: "missing action type", actionType: "", resource: "/data/report.csv", wantFields: []string{"action_type"}},
+		{name: "oversized resource", actionType: domain.CapabilityFileRead, resource: strings.Repeat("a", domain.MaxVerifyActionResourceLength+1), wantFields: []string{"resource"}},
+		{name: "missing action type and oversized resource", actionType: "", resource: strings.Repeat("a", domain.MaxVerifyActionResourceLength+1), wantFields: []string{"action_type", "resource"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fieldErrors, inCatalog, err := service.ValidateVerifyAction(context.Background(), orgID, tt.actionType, tt.resource)
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantInCatalog, inCatalog)
+
+			var fields []string
+			for _, fieldError := range fieldErrors {
+				fields = append(fields, fieldError.Field)
+				assert.NotEmpty(t, fieldError.Message)
+			}
+			assert.Equal(t, tt.wantFields, fields)
+		})
+	}
+}
+
+func TestAgentService_ValidateVerifyAction_DefaultCatalog(t *testing.T) {
+	orgID := uuid.New()
+	mockCapabilityRepo := new(MockCapabilityRepository)
+	mockCapabilityRepo.On("GetCatalog", orgID).Return(nil, nil)
+	service := &AgentService{capabilityRepo: mockCapabilityRepo}
+
+	fieldErrors, inCatalog, err := service.ValidateVerifyAction(context.Background(), orgID, domain.CapabilityAPICall, "https://api.example.com")
+	require.NoError(t, err)
+	assert.Empty(t, fieldErrors)
+	assert.True(t, inCatalog)
+
+	fieldErrors, inCatalog, err = service.ValidateVerifyAction(context.Background(), orgID, "delete_database", "users")
+	require.NoError(t, err)
+	assert.Empty(t, fieldErrors)
+	assert.False(t, inCatalog)
+}
+
+func TestAgentService_ValidateVerifyAction_CachesCatalog(t *testing.T) {
+	orgID := uuid.New()
+	mockCapabilityRepo := new(MockCapabilityRepository)
+	mockCapabilityRepo.On("GetCatalog", orgID).Return([]*domain.CapabilityCatalogEntry{
+		{CapabilityType: domain.CapabilityFileRead},
+	}, nil).Once()
+	service := &AgentService{
+		capabilityRepo: mockCapabilityRepo,
+		lookupCache:    NewAgentLookupCache(newFakeLookupStore(), time.Minute),
+	}
+
+	for i := 0; i < 3; i++ {
+		_, inCatalog, err := service.ValidateVerifyAction(context.Background(), orgID, domain.CapabilityFileRead, "/data/report.csv")
+		require.NoError(t, err)
+		assert.True(t, inCatalog)
+	}
+	mockCapabilityRepo.AssertExpectations(t)
+}
+
 func TestAgentService_VerifyAction_AgentCompromised(t *testing.T) {
 	mockAgentRepo := new(MockAgentRepository)
 	service := &AgentService{agentRepo: mockAgentRepo}
@@ -418,6 +606,115 @@ func TestAgentService_VerifyAction_AgentCompromised(t *testing.T) {
 	mockAgentRepo.AssertExpectations(t)
 }
 
+func TestAgentService_VerifyAction_KeyExpired(t *testing.T) {
+	mockAgentRepo := new(MockAgentRepository)
+	mockAlertRepo := new(MockAlertRepository)
+	service := &AgentService{agentRepo: mockAgentRepo, alertRepo: mockAlertRepo}
+
+	agent := createTestAgentForService()
+	expiredAt := time.Now().Add(-1 * time.Hour)
+	agent.KeyExpiresAt = &expiredAt
+
+	mockAgentRepo.On("GetByID", agent.ID).Return(agent, nil)
+	mockAlertRepo.On("GetUnacknowledgedByResourceID", agent.ID).Return([]*domain.Alert{}, nil)
+	mockAlertRepo.On("Create", mock.MatchedBy(func(alert *domain.Alert) bool {
+		return alert.AlertType == domain.AlertKeyExpired &&
+			alert.Severity == domain.AlertSeverityWarning &&
+			alert.ResourceID == agent.ID
+	})).Return(nil)
+
+	ctx := context.Background()
+	allowed, reason, auditID, err := service.VerifyAction(ctx, agent.ID, "file:read", "/test.txt", nil)
+
+	assert.NoError(t, err)
+	assert.False(t, allowed)
+	assert.Equal(t, "Agent key expired - rotate credentials", reason)
+	assert.NotEqual(t, uuid.Nil, auditID)
+	mockAgentRepo.AssertExpectations(t)
+	mockAlertRepo.AssertExpectations(t)
+}
+
+func TestAgentService_VerifyAction_KeyExpired_ExistingAlert(t *testing.T) {
+	mockAgentRepo := new(MockAgentRepository)
+	mockAlertRepo := new(MockAlertRepository)
+	service := &AgentService{agentRepo: mockAgentRepo, alertRepo: mockAlertRepo}
+
+	agent := createTestAgentForService()
+	expiredAt := time.Now().Add(-24 * time.Hour)
+	agent.KeyExpiresAt = &expiredAt
+
+	mockAgentRepo.On("GetByID", agent.ID).Return(agent, nil)
+	mockAlertRepo.On("GetUnacknowledgedByResourceID", agent.ID).Return([]*domain.Alert{
+		{ID: uuid.New(), AlertType: domain.AlertKeyExpired, ResourceID: agent.ID},
+	}, nil)
+
+	ctx := context.Background()
+	allowed, _, _, err := service.VerifyAction(ctx, agent.ID, "file:read", "/test.txt", nil)
+
+	assert.NoError(t, err)
+	assert.False(t, allowed)
+	mockAlertRepo.AssertNotCalled(t, "Create", mock.Anything)
+}
+
+func TestAgentService_VerifyAction_KeyNotExpired(t *testing.T) {
+	tests := []struct {
+		name      string
+		expiresIn *time.Duration
+		rotated   bool
+	}{
+		{"near expiry", durationPtr(time.Minute), false},
+		{"valid key", durationPtr(365 * 24 * time.Hour), false},
+		{"no expiry set", nil, false},
+		{"rotated key in grace period", durationPtr(365 * 24 * time.Hour), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockAgentRepo := new(MockAgentRepository)
+			mockCapabilityRepo := new(MockCapabilityRepository)
+			mockPolicyRepo := new(AgentServiceMockSecurityPolicyRepository)
+			mockAlertRepo := new(MockAlertRepository)
+
+			service := &AgentService{
+				agentRepo:      mockAgentRepo,
+				capabilityRepo: mockCapabilityRepo,
+				policyService:  &SecurityPolicyService{policyRepo: mockPolicyRepo, alertRepo: mockAlertRepo},
+				alertRepo:      mockAlertRepo,
+			}
+
+			agent := createTestAgentForService()
+			if tt.expiresIn != nil {
+				expiresAt := time.Now().Add(*tt.expiresIn)
+				agent.KeyExpiresAt = &expiresAt
+			}
+			if tt.rotated {
+				previousKey := "previous-public-key"
+				graceUntil := time.Now().Add(24 * time.Hour)
+				agent.PreviousPublicKey = &previousKey
+				agent.KeyRotationGraceUntil = &graceUntil
+			}
+
+			mockAgentRepo.On("GetByID", agent.ID).Return(agent, nil)
+			mockCapabilityRepo.On("GetActiveCapabilitiesByAgentID", agent.ID).Return([]*domain.AgentCapability{
+				{ID: uuid.New(), AgentID: agent.ID, CapabilityType: "file:read"},
+			}, nil)
+			mockPolicyRepo.On("GetActiveByOrganization", agent.OrganizationID).Return([]*domain.SecurityPolicy{}, nil).Maybe()
+			mockPolicyRepo.On("GetByType", agent.OrganizationID, mock.Anything).Return([]*domain.SecurityPolicy{}, nil).Maybe()
+
+			ctx := context.Background()
+			allowed, reason, _, err := service.VerifyAction(ctx, agent.ID, "file:read", "/test.txt", nil)
+
+			assert.NoError(t, err)
+			assert.True(t, allowed, reason)
+			mockAlertRepo.AssertNotCalled(t, "Create", mock.Anything)
+		})
+	}
+}
+
+func durationPtr(d time.Duration) *time.Duration {
+	return &d
+}
+
 func TestAgentService_VerifyAction_NoCapabilities(t *testing.T) {
 	mockAgentRepo := new(MockAgentRepository)
 	mockCapabilityRepo := new(MockCapabilityRepository)
@@ -507,6 +804,19 @@ func TestAgentService_matchesCapability_Patterns(t *testing.T) {
 		{"wildcard match", "file:read", "/test.txt", "file:*", true},
 		{"no match", "file:write", "/test.txt", "file:read", false},
 		{"wrong prefix", "db:query", "/database", "file:*", false},
+		{"resource scoped allow", "read_file", "/data/x.csv", "read_file:/data/*", true},
+		{"resource scoped deny", "read_file", "/secret", "read_file:/data/*", false},
+		{"resource scoped deny passwd", "read_file", "/etc/passwd", "read_file:/data/*", false},
+		{"resource scoped single segment", "read_file", "/data/sub/x.csv", "read_file:/data/*", false},
+		{"resource scoped recursive", "read_file", "/data/sub/x.csv", "read_file:/data/**", true},
+		{"resource scoped traversal", "read_file", "/data/../etc/passwd", "read_file:/data/**", false},
+		{"resource scoped wrong action", "write_file", "/data/x.csv", "read_file:/data/*", false},
+		{"resource scoped empty resource", "read_file", "", "read_file:/data/*", false},
+		{"resource scoped action wildcard", "read_file", "/data/x.csv", "read_*:/data/*", true},
+		{"resource scoped colon action", "file:read", "/data/x.csv", "file:read:/data/*", true},
+		{"resource scoped whole capability as action", "read_file:/data/*", "/etc/passwd", "read_file:/data/*", false},
+		{"resource scoped prefix as action", "read_file:/data/x", "/etc/passwd", "read_file:/data/*", false},
+		{"resource scoped wildcard action prefix", "read_*:/data/x", "/etc/passwd", "read_*:/data/*", false},
 	}
 
 	for _, tt := range tests {
@@ -517,6 +827,245 @@ func TestAgentService_matchesCapability_Patterns(t *testing.T) {
 	}
 }
 
+// newScopedGrantTestService wires an AgentService whose capability violations hit the
+// default block + alert policy
+func newScopedGrantTestService(agent *domain.Agent, capabilities []*domain.AgentCapability) (*AgentService, *MockCapabilityRepository) {
+	mockAgentRepo := new(MockAgentRepository)
+	mockCapabilityRepo := new(MockCapabilityRepository)
+	mockPolicyRepo := new(AgentServiceMockSecurityPolicyRepository)
+	mockAlertRepo := new(MockAlertRepository)
+
+	mockAgentRepo.On("GetByID", agent.ID).Return(agent, nil)
+	mockAgentRepo.On("UpdateTrustScore", agent.ID, mock.Anything).Return(nil).Maybe()
+	mockCapabilityRepo.On("GetActiveCapabilitiesByAgentID", agent.ID).Return(capabilities, nil)
+	mockCapabilityRepo.On("CreateViolation", mock.Anything).Return(nil).Maybe()
+	mockPolicyRepo.On("GetByType", agent.OrganizationID, mock.Anything).Return([]*domain.SecurityPolicy{}, nil).Maybe()
+	mockPolicyRepo.On("GetActiveByOrganization", agent.OrganizationID).Return([]*domain.SecurityPolicy{}, nil).Maybe()
+	mockAlertRepo.On("Create", mock.Anything).Return(nil).Maybe()
+
+	service := &AgentService{
+		agentRepo:      mockAgentRepo,
+		capabilityRepo: mockCapabilityRepo,
+		policyService:  &SecurityPolicyService{policyRepo: mockPolicyRepo, alertRepo: mockAlertRepo},
+		alertRepo:      mockAlertRepo,
+	}
+	return service, mockCapabilityRepo
+}
+
+func TestAgentService_VerifyAction_ScopedGrant(t *testing.T) {
+	agent := createTestAgentForService()
+	capabilities := []*domain.AgentCapability{
+		{
+			ID:              uuid.New(),
+			AgentID:         agent.ID,
+			CapabilityType:  "write_file",
+			CapabilityScope: map[string]interface{}{domain.CapabilityScopeGrantedScope: "/tmp/*"},
+		},
+	}
+	service, mockCapabilityRepo := newScopedGrantTestService(agent, capabilities)
+	ctx := context.Background()
+
+	allowed, _, _, err := service.VerifyAction(ctx, agent.ID, "write_file", "/tmp/report.txt", nil)
+	assert.NoError(t, err)
+	assert.True(t, allowed)
+	mockCapabilityRepo.AssertNotCalled(t, "CreateViolation", mock.Anything)
+
+	allowed, reason, _, err := service.VerifyAction(ctx, agent.ID, "write_file", "/etc/passwd", nil)
+	assert.NoError(t, err)
+	assert.False(t, allowed)
+	assert.Contains(t, reason, "Capability violation blocked")
+	mockCapabilityRepo.AssertCalled(t, "CreateViolation", mock.Anything)
+}
+
+func TestAgentService_VerifyAction_ExpiredGrantIgnored(t *testing.T) {
+	agent := createTestAgentForService()
+	expiredAt := time.Now().Add(-time.Minute)
+	capabilities := []*domain.AgentCapability{
+		{
+			ID:             uuid.New(),
+			AgentID:        agent.ID,
+			CapabilityType: "write_file",
+			ExpiresAt:      &expiredAt,
+		},
+	}
+	service, _ := newScopedGrantTestService(agent, capabilities)
+
+	allowed, reason, _, err := service.VerifyAction(context.Background(), agent.ID, "write_file", "/tmp/report.txt", nil)
+	assert.NoError(t, err)
+	assert.False(t, allowed)
+	assert.Contains(t, reason, "no granted capabilities")
+}
+
+func TestAgentService_VerifyAction_TimeBoxedCapabilityExpires(t *testing.T) {
+	agent := createTestAgentForService()
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	expiresAt := now.Add(time.Hour)
+	capabilities := []*domain.AgentCapability{
+		{ID: uuid.New(), AgentID: agent.ID, CapabilityType: "file:read"},
+		{ID: uuid.New(), AgentID: agent.ID, CapabilityType: "db:write", ExpiresAt: &expiresAt},
+	}
+	service, mockCapabilityRepo := newScopedGrantTestService(agent, capabilities)
+	service.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	allowed, _, _, err := service.VerifyAction(ctx, agent.ID, "db:write", "orders", nil)
+	assert.NoError(t, err)
+	assert.True(t, allowed)
+
+	now = expiresAt
+
+	// The moment the grant expires the same action is a capability violation
+	allowed, reason, _, err := service.VerifyAction(ctx, agent.ID, "db:write", "orders", nil)
+	assert.NoError(t, err)
+	assert.False(t, allowed)
+	assert.Contains(t, reason, "Capability violation blocked")
+	assert.Contains(t, reason, "allowed: [file:read]")
+	mockCapabilityRepo.AssertCalled(t, "CreateViolation", mock.MatchedBy(func(v *domain.CapabilityViolation) bool {
+		return v.AttemptedCapability == "db:write"
+	}))
+}
+
+func TestAgentService_ViolationPenaltiesFromOrganization(t *testing.T) {
+	custom := domain.ViolationPenalties{
+		Alert:   domain.SeverityPenalties{Low: 1, Medium: 12, High: 20, Critical: 30},
+		Blocked: domain.SeverityPenalties{Low: 2, Medium: 22, High: 24, Critical: 25},
+	}
+
+	tests := []struct {
+		name          string
+		penalties     *domain.ViolationPenalties
+		blockedImpact int // Blocked VerifyAction violation; the test agent's severity is critical
+		alertImpact   int // SDK-reported "warning" violation, recorded as medium
+	}{
+		{name: "default penalties", penalties: nil, blockedImpact: -15, alertImpact: -7},
+		{name: "organization penalties", penalties: &custom, blockedImpact: -25, alertImpact: -12},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			agent := createTestAgentForService()
+			service, mockCapabilityRepo := newScopedGrantTestService(agent, []*domain.AgentCapability{
+				{ID: uuid.New(), AgentID: agent.ID, CapabilityType: "file:read"},
+			})
+			mockOrgRepo := new(MockOrganizationRepository)
+			mockOrgRepo.On("GetByID", agent.OrganizationID).Return(&domain.Organization{
+				ID:                 agent.OrganizationID,
+				ViolationPenalties: tt.penalties,
+			}, nil)
+			service.orgRepo = mockOrgRepo
+			mockTrustCalc := new(AgentServiceMockTrustScoreCalculator)
+			mockTrustCalc.On("Calculate", agent).Return(nil, errors.New("skip recalculation"))
+			service.trustCalc = mockTrustCalc
+
+			allowed, _, _, err := service.VerifyAction(context.Background(), agent.ID, "db:write", "orders", nil)
+			require.NoError(t, err)
+			assert.False(t, allowed)
+			mockCapabilityRepo.AssertCalled(t, "CreateViolation", mock.MatchedBy(func(v *domain.CapabilityViolation) bool {
+				return v.IsBlocked && v.Severity == domain.ViolationSeverityCritical && v.TrustScoreImpact == tt.blockedImpact
+			}))
+			service.agentRepo.(*MockAgentRepository).AssertCalled(t, "UpdateTrustScore", agent.ID,
+				mock.MatchedBy(func(score float64) bool {
+					return math.Abs(score-(agent.TrustScore+float64(tt.blockedImpact)/100)) < 1e-9
+				}))
+
+			err = service.CreateCapabilityViolation(context.Background(), agent.ID, "file:delete", "/etc", "warning", nil)
+			require.NoError(t, err)
+			mockCapabilityRepo.AssertCalled(t, "CreateViolation", mock.MatchedBy(func(v *domain.CapabilityViolation) bool {
+				return !v.IsBlocked && v.Severity == domain.ViolationSeverityMedium && v.TrustScoreImpact == tt.alertImpact
+			}))
+		})
+	}
+}
+
+func TestAgentService_VerifyAction_MCPAllowlist(t *testing.T) {
+	tests := []struct {
+		name        string
+		server      string
+		wantAllowed bool
+	}{
+		{"server in talks_to is allowed", "filesystem-mcp", true},
+		{"unknown server is blocked", "exfil-mcp", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			agent := createTestAgentForService()
+			agent.TalksTo = []string{"filesystem-mcp"}
+
+			mockAgentRepo := new(MockAgentRepository)
+			mockCapabilityRepo := new(MockCapabilityRepository)
+			mockPolicyRepo := new(AgentServiceMockSecurityPolicyRepository)
+			mockAlertRepo := new(MockAlertRepository)
+
+			mockAgentRepo.On("GetByID", agent.ID).Return(agent, nil)
+			mockCapabilityRepo.On("GetActiveCapabilitiesByAgentID", agent.ID).Return([]*domain.AgentCapability{
+				{ID: uuid.New(), AgentID: agent.ID, CapabilityType: "mcp_tool:*"},
+			}, nil)
+			mockPolicyRepo.On("GetByType", agent.OrganizationID, domain.PolicyTypeMCPAllowlist).
+				Return([]*domain.SecurityPolicy{{
+					Name:              "Registered MCP Servers Only",
+					PolicyType:        domain.PolicyTypeMCPAllowlist,
+					EnforcementAction: domain.EnforcementBlockAndAlert,
+					AppliesTo:         "all",
+					IsEnabled:         true,
+				}}, nil).Maybe()
+			mockPolicyRepo.On("GetByType", agent.OrganizationID, mock.Anything).Return([]*domain.SecurityPolicy{}, nil).Maybe()
+			mockAlertRepo.On("GetUnacknowledged", agent.OrganizationID).Return([]*domain.Alert{}, nil).Maybe()
+			mockAlertRepo.On("Create", mock.Anything).Return(nil).Maybe()
+
+			service := &AgentService{
+				agentRepo:      mockAgentRepo,
+				capabilityRepo: mockCapabilityRepo,
+				policyService:  &SecurityPolicyService{policyRepo: mockPolicyRepo, alertRepo: mockAlertRepo},
+				alertRepo:      mockAlertRepo,
+			}
+
+			metadata := map[string]interface{}{"protocol": "mcp", "mcp_server": tt.server}
+			allowed, reason, _, err := service.VerifyAction(context.Background(), agent.ID, "mcp_tool:search", "query", metadata)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantAllowed, allowed)
+			if tt.wantAllowed {
+				mockAlertRepo.AssertNotCalled(t, "Create", mock.Anything)
+			} else {
+				assert.Contains(t, reason, "MCP allowlist policy 'Registered MCP Servers Only'")
+				assert.Contains(t, reason, "exfil-mcp")
+				mockAlertRepo.AssertCalled(t, "Create", mock.MatchedBy(func(alert *domain.Alert) bool {
+					return alert.Severity == domain.AlertSeverityHigh
+				}))
+			}
+		})
+	}
+}
+
+func TestAgentService_matchesGrant(t *testing.T) {
+	service := &AgentService{}
+	now := time.Now()
+	future := now.Add(time.Hour)
+	past := now.Add(-time.Hour)
+	scoped := map[string]interface{}{domain.CapabilityScopeGrantedScope: "/tmp/*"}
+
+	tests := []struct {
+		name       string
+		capability *domain.AgentCapability
+		resource   string
+		expected   bool
+	}{
+		{"unscoped", &domain.AgentCapability{CapabilityType: "write_file"}, "/etc/hosts", true},
+		{"in scope", &domain.AgentCapability{CapabilityType: "write_file", CapabilityScope: scoped}, "/tmp/a.txt", true},
+		{"out of scope", &domain.AgentCapability{CapabilityType: "write_file", CapabilityScope: scoped}, "/var/a.txt", false},
+		{"scope traversal", &domain.AgentCapability{CapabilityType: "write_file", CapabilityScope: scoped}, "/tmp/../etc/passwd", false},
+		{"not yet expired", &domain.AgentCapability{CapabilityType: "write_file", ExpiresAt: &future}, "/tmp/a.txt", true},
+		{"expired", &domain.AgentCapability{CapabilityType: "write_file", ExpiresAt: &past}, "/tmp/a.txt", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, service.matchesGrant("write_file", tt.resource, tt.capability, now))
+		})
+	}
+}
+
 // ===========================
 // shouldAutoVerifyAgent Tests
 // ===========================
@@ -555,6 +1104,25 @@ func TestAgentService_shouldAutoVerifyAgent_Conditions(t *testing.T) {
 			},
 			expected: false,
 		},
+		{
+			name: "missing description - should auto-verify",
+			agent: &domain.Agent{
+				Name:        "test",
+				DisplayName: "Test",
+				TrustScore:  0.85,
+				PublicKey:   stringPtr("key"),
+			},
+			expected: true,
+		},
+		{
+			name: "missing display name - should NOT auto-verify",
+			agent: &domain.Agent{
+				Name:       "test",
+				TrustScore: 0.85,
+				PublicKey:  stringPtr("key"),
+			},
+			expected: false,
+		},
 		{
 			name: "missing keys - should NOT auto-verify",
 			agent: &domain.Agent{
@@ -571,9 +1139,1052 @@ func TestAgentService_shouldAutoVerifyAgent_Conditions(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result := service.shouldAutoVerifyAgent(tt.agent)
+			result := service.shouldAutoVerifyAgent(tt.agent, 0.3)
 			assert.Equal(t, tt.expected, result)
 		})
 	}
 }
 
+func TestAgentService_shouldAutoVerifyAgent_DefaultThresholdVerifiesLowTrust(t *testing.T) {
+	// New agents were verified on creation before the threshold was configurable
+	service := &AgentService{}
+	publicKey := "key"
+	agent := &domain.Agent{Name: "test", DisplayName: "Test", TrustScore: 0.1, PublicKey: &publicKey}
+
+	assert.True(t, service.shouldAutoVerifyAgent(agent, domain.DefaultAutoVerifyMinTrust))
+}
+
+
+// ===========================
+// CreateAgent Auto-Verification Settings Tests
+// ===========================
+
+func newAutoVerifyTestService(t *testing.T, org *domain.Organization, trustScore float64) (*AgentService, *MockAgentRepository) {
+	t.Helper()
+
+	keyVault, err := crypto.NewKeyVault("MDEyMzQ1Njc4OTAxMjM0NTY3ODkwMTIzNDU2Nzg5MDE=")
+	if err != nil {
+		t.Fatalf("failed to create key vault: %v", err)
+	}
+
+	mockAgentRepo := new(MockAgentRepository)
+	mockTrustCalc := new(AgentServiceMockTrustScoreCalculator)
+	mockTrustScoreRepo := new(AgentServiceMockTrustScoreRepository)
+	mockOrgRepo := new(MockOrganizationRepository)
+
+	mockAgentRepo.On("Create", mock.AnythingOfType("*domain.Agent")).Return(nil)
+	mockAgentRepo.On("Update", mock.AnythingOfType("*domain.Agent")).Return(nil)
+	mockAgentRepo.On("UpdateStatus", mock.AnythingOfType("*domain.Agent"), mock.Anything).Return(nil).Maybe()
+	mockTrustCalc.On("Calculate", mock.AnythingOfType("*domain.Agent")).Return(&domain.TrustScore{Score: trustScore}, nil)
+	mockTrustScoreRepo.On("Create", mock.AnythingOfType("*domain.TrustScore")).Return(nil)
+	mockOrgRepo.On("GetByID", org.ID).Return(org, nil)
+
+	service := &AgentService{
+		agentRepo:      mockAgentRepo,
+		trustCalc:      mockTrustCalc,
+		trustScoreRepo: mockTrustScoreRepo,
+		keyVault:       keyVault,
+		orgRepo:        mockOrgRepo,
+	}
+	return service, mockAgentRepo
+}
+
+func TestAgentService_CreateAgent_AutoVerifyThreshold(t *testing.T) {
+	tests := []struct {
+		name           string
+		enabled        bool
+		minTrust       float64
+		expectedStatus domain.AgentStatus
+	}{
+		{name: "0.5 trust under 0.3 threshold - auto-verified", enabled: true, minTrust: 0.3, expectedStatus: domain.AgentStatusVerified},
+		{name: "0.5 trust under 0.7 threshold - stays pending", enabled: true, minTrust: 0.7, expectedStatus: domain.AgentStatusPending},
+		{name: "auto-verification disabled - stays pending", enabled: false, minTrust: 0.3, expectedStatus: domain.AgentStatusPending},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			org := &domain.Organization{
+				ID:                 uuid.New(),
+				AutoVerifyEnabled:  tt.enabled,
+				AutoVerifyMinTrust: tt.minTrust,
+			}
+			service, mockAgentRepo := newAutoVerifyTestService(t, org, 0.5)
+
+			agent, err := service.CreateAgent(context.Background(), &CreateAgentRequest{
+				Name:        "auto-verify-agent",
+				DisplayName: "Auto Verify Agent",
+				Description: "Agent used to test auto-verification thresholds",
+				AgentType:   domain.AgentTypeAI,
+			}, org.ID, uuid.New())
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedStatus, agent.Status)
+			if tt.expectedStatus == domain.AgentStatusVerified {
+				assert.NotNil(t, agent.VerifiedAt)
+			} else {
+				assert.Nil(t, agent.VerifiedAt)
+			}
+			mockAgentRepo.AssertExpectations(t)
+		})
+	}
+}
+
+// insertStatusAgentRepo records the status agents had when they were inserted and the outbox
+// messages queued when they were promoted
+type insertStatusAgentRepo struct {
+	*MockAgentRepository
+	insertedStatus domain.AgentStatus
+	queued         []*domain.OutboxMessage
+	failPromotion  bool
+}
+
+func (r *insertStatusAgentRepo) Create(agent *domain.Agent) error {
+	r.insertedStatus = agent.Status
+	return nil
+}
+
+func (r *insertStatusAgentRepo) UpdateStatus(agent *domain.Agent, messages ...*domain.OutboxMessage) error {
+	if r.failPromotion {
+		return errors.New("connection reset")
+	}
+	r.queued = append(r.queued, messages...)
+	return nil
+}
+
+func TestAgentService_CreateAgent_InsertsPendingBeforeAutoVerify(t *testing.T) {
+	org := &domain.Organization{ID: uuid.New(), AutoVerifyEnabled: true, AutoVerifyMinTrust: 0.3}
+	req := &CreateAgentRequest{
+		Name:        "sdk-agent",
+		DisplayName: "SDK Agent",
+		Description: "Agent registered with an SDK-held key",
+		AgentType:   domain.AgentTypeAI,
+		PublicKey:   "c2RrLXB1YmxpYy1rZXk=",
+	}
+
+	t.Run("qualifying agent is verified after the insert", func(t *testing.T) {
+		service, mockAgentRepo := newAutoVerifyTestService(t, org, 0.5)
+		repo := &insertStatusAgentRepo{MockAgentRepository: mockAgentRepo}
+		service.agentRepo = repo
+
+		agent, err := service.CreateAgent(context.Background(), req, org.ID, uuid.New())
+		require.NoError(t, err)
+		assert.Equal(t, domain.AgentStatusPending, repo.insertedStatus)
+		assert.Equal(t, domain.AgentStatusVerified, agent.Status)
+
+		// Auto-verification announces agent.verified like a manual verification
+		require.Len(t, repo.queued, 1)
+		var event domain.OutboxWebhookEvent
+		require.NoError(t, json.Unmarshal(repo.queued[0].Payload, &event))
+		assert.Equal(t, domain.WebhookEventAgentVerified, event.Event)
+		var data domain.AgentStatusWebhookData
+		require.NoError(t, json.Unmarshal(event.Data, &data))
+		assert.Equal(t, agent.ID, data.AgentID)
+		assert.Equal(t, domain.AgentStatusPending, data.PreviousStatus)
+		assert.Equal(t, domain.AgentStatusVerified, data.Status)
+	})
+
+	t.Run("failed promotion leaves the agent pending", func(t *testing.T) {
+		service, mockAgentRepo := newAutoVerifyTestService(t, org, 0.5)
+		repo := &insertStatusAgentRepo{MockAgentRepository: mockAgentRepo, failPromotion: true}
+		service.agentRepo = repo
+
+		agent, err := service.CreateAgent(context.Background(), req, org.ID, uuid.New())
+		require.NoError(t, err)
+		assert.Equal(t, domain.AgentStatusPending, repo.insertedStatus)
+		assert.Equal(t, domain.AgentStatusPending, agent.Status)
+		assert.Nil(t, agent.VerifiedAt)
+	})
+}
+
+func TestAgentService_CreateAgent_RejectsCapabilitiesOutsideCatalog(t *testing.T) {
+	org := &domain.Organization{ID: uuid.New(), AutoVerifyEnabled: true, AutoVerifyMinTrust: 0.3}
+	service, mockAgentRepo := newAutoVerifyTestService(t, org, 0.5)
+	mockCapabilityRepo := new(MockCapabilityRepository)
+	mockCapabilityRepo.On("GetCatalog", org.ID).Return([]*domain.CapabilityCatalogEntry{
+		{CapabilityType: domain.CapabilityAPICall},
+	}, nil)
+	service.capabilityRepo = mockCapabilityRepo
+
+	_, err := service.CreateAgent(context.Background(), &CreateAgentRequest{
+		Name:         "catalog-agent",
+		DisplayName:  "Catalog Agent",
+		AgentType:    domain.AgentTypeAI,
+		Capabilities: []string{domain.CapabilityAPICall, domain.CapabilitySystemAdmin},
+	}, org.ID, uuid.New())
+
+	assert.ErrorIs(t, err, ErrCapabilityNotInCatalog)
+	assert.ErrorContains(t, err, domain.CapabilitySystemAdmin)
+	assert.NotContains(t, err.Error(), domain.CapabilityAPICall)
+	mockAgentRepo.AssertNotCalled(t, "Create", mock.Anything)
+	mockCapabilityRepo.AssertNotCalled(t, "CreateCapability", mock.Anything)
+}
+
+func TestAgentService_CreateAgent_CapabilityApproval(t *testing.T) {
+	declared := []string{domain.CapabilityAPICall, domain.CapabilityFileRead}
+
+	tests := []struct {
+		name            string
+		requireApproval bool
+	}{
+		{name: "approval not required - capabilities auto-granted", requireApproval: false},
+		{name: "approval required - capabilities requested", requireApproval: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			org := &domain.Organization{
+				ID:                        uuid.New(),
+				AutoVerifyEnabled:         true,
+				AutoVerifyMinTrust:        0.3,
+				RequireCapabilityApproval: tt.requireApproval,
+			}
+			service, _ := newAutoVerifyTestService(t, org, 0.5)
+			userID := uuid.New()
+
+			mockCapabilityRepo := new(MockCapabilityRepository)
+			mockCapabilityRepo.On("GetCatalog", org.ID).Return([]*domain.CapabilityCatalogEntry{
+				{CapabilityType: domain.CapabilityAPICall},
+				{CapabilityType: domain.CapabilityFileRead},
+			}, nil)
+			mockCapabilityRepo.On("CreateCapability", mock.AnythingOfType("*domain.AgentCapability")).Return(nil)
+			mockRequestRepo := new(MockCapabilityRequestRepository)
+			mockRequestRepo.On("Create", mock.AnythingOfType("*domain.CapabilityRequest")).Return(nil)
+			service.capabilityRepo = mockCapabilityRepo
+			service.capabilityRequestService = NewCapabilityRequestService(mockRequestRepo, mockCapabilityRepo, nil, nil, nil, nil, 0)
+
+			agent, err := service.CreateAgent(context.Background(), &CreateAgentRequest{
+				Name:         "approval-agent",
+				DisplayName:  "Approval Agent",
+				Description:  "Agent used to test capability approval",
+				AgentType:    domain.AgentTypeAI,
+				Capabilities: declared,
+			}, org.ID, userID)
+			require.NoError(t, err)
+
+			if !tt.requireApproval {
+				mockCapabilityRepo.AssertNumberOfCalls(t, "CreateCapability", len(declared))
+				mockRequestRepo.AssertNotCalled(t, "Create", mock.Anything)
+				return
+			}
+
+			mockCapabilityRepo.AssertNotCalled(t, "CreateCapability", mock.Anything)
+			mockRequestRepo.AssertNumberOfCalls(t, "Create", len(declared))
+			for i, call := range mockRequestRepo.Calls {
+				request := call.Arguments.Get(0).(*domain.CapabilityRequest)
+				assert.Equal(t, agent.ID, request.AgentID)
+				assert.Equal(t, declared[i], request.CapabilityType)
+				assert.Equal(t, userID, request.RequestedBy)
+				assert.True(t, request.ExpiresAt.After(time.Now()))
+			}
+		})
+	}
+}
+
+func TestAgentService_UpdateAgent_CapabilityApproval(t *testing.T) {
+	tests := []struct {
+		name            string
+		requireApproval bool
+	}{
+		{name: "auto-grant", requireApproval: false},
+		{name: "require approval", requireApproval: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			org := &domain.Organization{ID: uuid.New(), RequireCapabilityApproval: tt.requireApproval}
+			service, mockAgentRepo := newAutoVerifyTestService(t, org, 0.5)
+			agent := &domain.Agent{ID: uuid.New(), OrganizationID: org.ID, Name: "update-agent", CreatedBy: uuid.New()}
+			mockAgentRepo.On("GetByID", agent.ID).Return(agent, nil)
+			userID := uuid.New()
+
+			mockCapabilityRepo := new(MockCapabilityRepository)
+			mockCapabilityRepo.On("GetCatalog", org.ID).Return([]*domain.CapabilityCatalogEntry{
+				{CapabilityType: domain.CapabilityAPICall},
+				{CapabilityType: domain.CapabilityFileRead},
+				{CapabilityType: domain.CapabilityFileWrite},
+			}, nil)
+			mockCapabilityRepo.On("GetCapabilitiesByAgentID", agent.ID).Return([]*domain.AgentCapability{
+				{ID: uuid.New(), AgentID: agent.ID, CapabilityType: domain.CapabilityAPICall},
+			}, nil)
+			mockCapabilityRepo.On("CreateCapability", mock.AnythingOfType("*domain.AgentCapability")).Return(nil)
+			mockRequestRepo := new(MockCapabilityRequestRepository)
+			// file:write is already awaiting approval
+			mockRequestRepo.On("List", mock.Anything).Return([]*domain.CapabilityRequestWithDetails{
+				{CapabilityRequest: domain.CapabilityRequest{AgentID: agent.ID, CapabilityType: domain.CapabilityFileWrite, Status: domain.CapabilityRequestStatusPending}},
+			}, nil)
+			mockRequestRepo.On("Create", mock.AnythingOfType("*domain.CapabilityRequest")).Return(nil)
+			service.capabilityRepo = mockCapabilityRepo
+			service.capabilityRequestService = NewCapabilityRequestService(mockRequestRepo, mockCapabilityRepo, nil, nil, nil, nil, 0)
+
+			_, err := service.UpdateAgent(context.Background(), agent.ID, &CreateAgentRequest{
+				Capabilities: []string{domain.CapabilityAPICall, domain.CapabilityFileRead, domain.CapabilityFileWrite},
+			}, userID)
+			require.NoError(t, err)
+
+			if !tt.requireApproval {
+				mockCapabilityRepo.AssertNumberOfCalls(t, "CreateCapability", 2)
+				mockRequestRepo.AssertNotCalled(t, "Create", mock.Anything)
+				return
+			}
+
+			// Only the capability without a grant or pending request is requested; nothing is granted
+			mockCapabilityRepo.AssertNotCalled(t, "CreateCapability", mock.Anything)
+			mockRequestRepo.AssertNumberOfCalls(t, "Create", 1)
+			request := mockRequestRepo.Calls[len(mockRequestRepo.Calls)-1].Arguments.Get(0).(*domain.CapabilityRequest)
+			assert.Equal(t, domain.CapabilityFileRead, request.CapabilityType)
+			assert.Equal(t, userID, request.RequestedBy)
+		})
+	}
+}
+
+// quotaTestAgentRepo keeps a live agent count so creations and deletions move it
+type quotaTestAgentRepo struct {
+	*MockAgentRepository
+	active int
+}
+
+func (r *quotaTestAgentRepo) Create(agent *domain.Agent) error {
+	r.active++
+	return nil
+}
+
+func (r *quotaTestAgentRepo) CreateBatch(agents []*domain.Agent, atomic bool) ([]error, error) {
+	r.active += len(agents)
+	return make([]error, len(agents)), nil
+}
+
+func (r *quotaTestAgentRepo) SoftDelete(id uuid.UUID) error {
+	r.active--
+	return nil
+}
+
+func (r *quotaTestAgentRepo) CountByOrganization(orgID uuid.UUID) (int, error) {
+	return r.active, nil
+}
+
+func TestAgentService_CreateAgent_EnforcesMaxAgents(t *testing.T) {
+	org := &domain.Organization{ID: uuid.New(), MaxAgents: 2, AutoVerifyEnabled: true, AutoVerifyMinTrust: 0.3}
+	service, mockAgentRepo := newAutoVerifyTestService(t, org, 0.5)
+	repo := &quotaTestAgentRepo{MockAgentRepository: mockAgentRepo}
+	service.agentRepo = repo
+
+	ctx := context.Background()
+	create := func(name string) (*domain.Agent, error) {
+		return service.CreateAgent(ctx, &CreateAgentRequest{Name: name, DisplayName: name, AgentType: domain.AgentTypeAI}, org.ID, uuid.New())
+	}
+
+	first, err := create("quota-agent-1")
+	require.NoError(t, err)
+	_, err = create("quota-agent-2")
+	require.NoError(t, err)
+
+	// The (max+1)th agent is rejected with the limit it hit
+	_, err = create("quota-agent-3")
+	require.ErrorIs(t, err, domain.ErrOrganizationQuotaExceeded)
+	var quotaErr *domain.QuotaExceededError
+	require.ErrorAs(t, err, &quotaErr)
+	assert.Equal(t, "agents", quotaErr.Resource)
+	assert.Equal(t, 2, quotaErr.Limit)
+	assert.Equal(t, 2, quotaErr.Current)
+	assert.Equal(t, 2, repo.active)
+
+	// Deleting an agent frees its slot
+	require.NoError(t, service.DeleteAgent(ctx, first.ID))
+	_, err = create("quota-agent-3")
+	require.NoError(t, err)
+	assert.Equal(t, 2, repo.active)
+}
+
+func TestAgentService_CreateAgentsBulk_EnforcesMaxAgents(t *testing.T) {
+	org := &domain.Organization{ID: uuid.New(), MaxAgents: 3, AutoVerifyEnabled: true, AutoVerifyMinTrust: 0.3}
+	service, mockAgentRepo := newAutoVerifyTestService(t, org, 0.5)
+	repo := &quotaTestAgentRepo{MockAgentRepository: mockAgentRepo, active: 2}
+	service.agentRepo = repo
+
+	// Two valid agents do not fit in the one remaining slot, so none are created
+	results, err := service.CreateAgentsBulk(context.Background(), bulkTestRequests(), false, org.ID, uuid.New())
+	assert.ErrorIs(t, err, domain.ErrOrganizationQuotaExceeded)
+	assert.Nil(t, results)
+	assert.Equal(t, 2, repo.active)
+}
+
+// ===========================
+// CreateAgentsBulk Tests
+// ===========================
+
+func bulkTestRequests() []*CreateAgentRequest {
+	return []*CreateAgentRequest{
+		{Name: "bulk-agent-1", DisplayName: "Bulk Agent 1", Description: "First bulk agent", AgentType: domain.AgentTypeAI},
+		{Name: "", DisplayName: "Missing Name", AgentType: domain.AgentTypeAI},
+		{Name: "bulk-agent-3", DisplayName: "Bulk Agent 3", Description: "Third bulk agent", AgentType: "robot"},
+		{Name: "bulk-agent-4", DisplayName: "Bulk Agent 4", Description: "Fourth bulk agent", AgentType: domain.AgentTypeMCP},
+	}
+}
+
+func TestAgentService_CreateAgentsBulk_MixedPayload(t *testing.T) {
+	org := &domain.Organization{ID: uuid.New(), AutoVerifyEnabled: true, AutoVerifyMinTrust: 0.3}
+	service, mockAgentRepo := newAutoVerifyTestService(t, org, 0.5)
+
+	mockAgentRepo.On("CreateBatch", mock.MatchedBy(func(agents []*domain.Agent) bool {
+		return len(agents) == 2 && agents[0].Name == "bulk-agent-1" && agents[1].Name == "bulk-agent-4"
+	}), false).Return([]error{nil, nil}, nil)
+
+	results, err := service.CreateAgentsBulk(context.Background(), bulkTestRequests(), false, org.ID, uuid.New())
+
+	assert.NoError(t, err)
+	assert.Len(t, results, 4)
+
+	assert.True(t, results[0].Success)
+	assert.Equal(t, domain.AgentStatusVerified, results[0].Agent.Status)
+	assert.False(t, results[1].Success)
+	assert.Equal(t, "name and display_name are required", results[1].Error)
+	assert.False(t, results[2].Success)
+	assert.Equal(t, "invalid agent_type", results[2].Error)
+	assert.True(t, results[3].Success)
+	assert.Equal(t, 3, results[3].Index)
+
+	mockAgentRepo.AssertNotCalled(t, "Create", mock.Anything)
+}
+
+func TestAgentService_CreateAgentsBulk_InsertFailureIsPerItem(t *testing.T) {
+	org := &domain.Organization{ID: uuid.New(), AutoVerifyEnabled: true, AutoVerifyMinTrust: 0.3}
+	service, mockAgentRepo := newAutoVerifyTestService(t, org, 0.5)
+
+	reqs := []*CreateAgentRequest{bulkTestRequests()[0], bulkTestRequests()[3]}
+	mockAgentRepo.On("CreateBatch", mock.Anything, false).Return([]error{errors.New("duplicate key"), nil}, nil)
+
+	results, err := service.CreateAgentsBulk(context.Background(), reqs, false, org.ID, uuid.New())
+
+	assert.NoError(t, err)
+	assert.False(t, results[0].Success)
+	assert.Contains(t, results[0].Error, "duplicate key")
+	assert.Nil(t, results[0].Agent)
+	assert.True(t, results[1].Success)
+}
+
+func TestAgentService_CreateAgentsBulk_AtomicRejectsInvalidItems(t *testing.T) {
+	org := &domain.Organization{ID: uuid.New(), AutoVerifyEnabled: true, AutoVerifyMinTrust: 0.3}
+	service, mockAgentRepo := newAutoVerifyTestService(t, org, 0.5)
+
+	results, err := service.CreateAgentsBulk(context.Background(), bulkTestRequests(), true, org.ID, uuid.New())
+
+	assert.ErrorIs(t, err, ErrBulkAgentsAborted)
+	assert.Len(t, results, 4)
+	assert.Equal(t, "name and display_name are required", results[1].Error)
+	for _, result := range results {
+		assert.False(t, result.Success)
+	}
+	mockAgentRepo.AssertNotCalled(t, "CreateBatch", mock.Anything, mock.Anything)
+}
+
+func TestAgentService_CreateAgentsBulk_AtomicInsertFailure(t *testing.T) {
+	org := &domain.Organization{ID: uuid.New(), AutoVerifyEnabled: true, AutoVerifyMinTrust: 0.3}
+	service, mockAgentRepo := newAutoVerifyTestService(t, org, 0.5)
+
+	reqs := []*CreateAgentRequest{bulkTestRequests()[0], bulkTestRequests()[3]}
+	mockAgentRepo.On("CreateBatch", mock.Anything, true).Return(nil, errors.New("duplicate key"))
+
+	_, err := service.CreateAgentsBulk(context.Background(), reqs, true, org.ID, uuid.New())
+
+	assert.ErrorIs(t, err, ErrBulkAgentsAborted)
+	mockAgentRepo.AssertNotCalled(t, "Update", mock.Anything)
+}
+
+func TestAgentService_CreateAgentsBulk_Limits(t *testing.T) {
+	service := &AgentService{}
+
+	_, err := service.CreateAgentsBulk(context.Background(), nil, false, uuid.New(), uuid.New())
+	assert.ErrorIs(t, err, ErrBulkAgentsEmpty)
+
+	reqs := make([]*CreateAgentRequest, MaxBulkAgents+1)
+	_, err = service.CreateAgentsBulk(context.Background(), reqs, false, uuid.New(), uuid.New())
+	assert.ErrorIs(t, err, ErrBulkAgentsLimitExceeded)
+}
+
+// pagedTestAgents returns n agents ordered newest first, as the repository returns them
+func pagedTestAgents(n int) []*domain.Agent {
+	base := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	agents := make([]*domain.Agent, n)
+	for i := range agents {
+		agents[i] = &domain.Agent{ID: uuid.New(), CreatedAt: base.Add(-time.Duration(i) * time.Minute)}
+	}
+	return agents
+}
+
+func TestAgentService_ListAgentsPage_Boundaries(t *testing.T) {
+	orgID := uuid.New()
+	agents := pagedTestAgents(5)
+
+	tests := []struct {
+		name       string
+		limit      int
+		offset     int
+		rows       []*domain.Agent // rows returned by the repository for limit+1
+		wantLen    int
+		wantCursor bool
+	}{
+		{name: "more pages", limit: 2, offset: 0, rows: agents[0:3], wantLen: 2, wantCursor: true},
+		{name: "exact fit", limit: 5, offset: 0, rows: agents, wantLen: 5, wantCursor: false},
+		{name: "last partial page", limit: 2, offset: 4, rows: agents[4:], wantLen: 1, wantCursor: false},
+		{name: "past the end", limit: 2, offset: 10, rows: nil, wantLen: 0, wantCursor: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockAgentRepo := new(MockAgentRepository)
+			mockAgentRepo.On("GetByOrganizationPaginated", orgID, tt.limit+1, tt.offset, (*domain.AgentCursor)(nil)).
+				Return(tt.rows, len(agents), nil)
+			service := &AgentService{agentRepo: mockAgentRepo}
+
+			page, err := service.ListAgentsPage(context.Background(), orgID, ListAgentsParams{Limit: tt.limit, Offset: tt.offset})
+
+			assert.NoError(t, err)
+			assert.NotNil(t, page.Agents)
+			assert.Len(t, page.Agents, tt.wantLen)
+			assert.Equal(t, len(agents), page.Total)
+			assert.Equal(t, tt.wantCursor, page.NextCursor != "")
+		})
+	}
+}
+
+func TestAgentService_ListAgentsPage_CursorRoundTrip(t *testing.T) {
+	orgID := uuid.New()
+	agents := pagedTestAgents(4)
+
+	mockAgentRepo := new(MockAgentRepository)
+	mockAgentRepo.On("GetByOrganizationPaginated", orgID, 3, 0, (*domain.AgentCursor)(nil)).Return(agents[0:3], 4, nil)
+	mockAgentRepo.On("GetByOrganizationPaginated", orgID, 3, 0, &domain.AgentCursor{CreatedAt: agents[1].CreatedAt, ID: agents[1].ID}).
+		Return(agents[2:], 4, nil)
+	service := &AgentService{agentRepo: mockAgentRepo}
+
+	first, err := service.ListAgentsPage(context.Background(), orgID, ListAgentsParams{Limit: 2})
+	assert.NoError(t, err)
+	assert.Equal(t, []*domain.Agent{agents[0], agents[1]}, first.Agents)
+	assert.NotEmpty(t, first.NextCursor)
+
+	// Offset is ignored once a cursor is supplied
+	second, err := service.ListAgentsPage(context.Background(), orgID, ListAgentsParams{Limit: 2, Offset: 7, Cursor: first.NextCursor})
+	assert.NoError(t, err)
+	assert.Equal(t, []*domain.Agent{agents[2], agents[3]}, second.Agents)
+	assert.Empty(t, second.NextCursor)
+	mockAgentRepo.AssertExpectations(t)
+}
+
+func TestAgentService_ListAgentsPage_LimitClamping(t *testing.T) {
+	orgID := uuid.New()
+
+	mockAgentRepo := new(MockAgentRepository)
+	mockAgentRepo.On("GetByOrganizationPaginated", orgID, DefaultAgentPageSize+1, 0, (*domain.AgentCursor)(nil)).Return(nil, 0, nil)
+	mockAgentRepo.On("GetByOrganizationPaginated", orgID, MaxAgentPageSize+1, 0, (*domain.AgentCursor)(nil)).Return(nil, 0, nil)
+	service := &AgentService{agentRepo: mockAgentRepo}
+
+	_, err := service.ListAgentsPage(context.Background(), orgID, ListAgentsParams{Limit: 0, Offset: -3})
+	assert.NoError(t, err)
+	_, err = service.ListAgentsPage(context.Background(), orgID, ListAgentsParams{Limit: MaxAgentPageSize * 10})
+	assert.NoError(t, err)
+	mockAgentRepo.AssertExpectations(t)
+}
+
+func TestAgentService_ListAgentsPage_InvalidCursor(t *testing.T) {
+	mockAgentRepo := new(MockAgentRepository)
+	service := &AgentService{agentRepo: mockAgentRepo}
+
+	for _, cursor := range []string{"not base64!", "bm8tc2VwYXJhdG9y", encodeAgentCursor(domain.AgentCursor{})[:4]} {
+		_, err := service.ListAgentsPage(context.Background(), uuid.New(), ListAgentsParams{Cursor: cursor})
+		assert.ErrorIs(t, err, ErrInvalidAgentCursor, cursor)
+	}
+	mockAgentRepo.AssertNotCalled(t, "GetByOrganizationPaginated", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestAgentService_ListAgentsPage_FilterUsesSearch(t *testing.T) {
+	orgID := uuid.New()
+	minTrust := 0.9
+	filter := domain.AgentSearchFilter{Status: domain.AgentStatusVerified, MinTrust: &minTrust}
+
+	mockAgentRepo := new(MockAgentRepository)
+	mockAgentRepo.On("Search", orgID, filter, DefaultAgentPageSize+1, 0, (*domain.AgentCursor)(nil)).Return([]*domain.Agent{}, 0, nil)
+	service := &AgentService{agentRepo: mockAgentRepo}
+
+	page, err := service.ListAgentsPage(context.Background(), orgID, ListAgentsParams{Filter: filter})
+
+	assert.NoError(t, err)
+	assert.NotNil(t, page.Agents)
+	assert.Empty(t, page.Agents)
+	assert.Empty(t, page.NextCursor)
+	mockAgentRepo.AssertNotCalled(t, "GetByOrganizationPaginated", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	mockAgentRepo.AssertExpectations(t)
+}
+
+// ===========================
+// Key Expiration Period Tests
+// ===========================
+
+func TestAgentService_RotateCredentials_UsesOrgKeyRotationDays(t *testing.T) {
+	tests := []struct {
+		name         string
+		org          *domain.Organization
+		orgErr       error
+		expectedDays int
+	}{
+		{name: "90-day org", org: &domain.Organization{KeyRotationDays: 90}, expectedDays: 90},
+		{name: "unset org falls back to a year", org: &domain.Organization{}, expectedDays: 365},
+		{name: "out-of-range setting falls back to a year", org: &domain.Organization{KeyRotationDays: 3}, expectedDays: 365},
+		{name: "org lookup failure falls back to a year", orgErr: errors.New("db down"), expectedDays: 365},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			keyVault, err := crypto.NewKeyVault("MDEyMzQ1Njc4OTAxMjM0NTY3ODkwMTIzNDU2Nzg5MDE=")
+			if err != nil {
+				t.Fatalf("failed to create key vault: %v", err)
+			}
+
+			orgID := uuid.New()
+			agent := &domain.Agent{ID: uuid.New(), OrganizationID: orgID}
+			mockAgentRepo := new(MockAgentRepository)
+			mockAgentRepo.On("GetByID", agent.ID).Return(agent, nil)
+			mockAgentRepo.On("Update", agent).Return(nil)
+			mockOrgRepo := new(MockOrganizationRepository)
+			if tt.orgErr != nil {
+				mockOrgRepo.On("GetByID", orgID).Return(nil, tt.orgErr)
+			} else {
+				tt.org.ID = orgID
+				mockOrgRepo.On("GetByID", orgID).Return(tt.org, nil)
+			}
+			service := &AgentService{agentRepo: mockAgentRepo, keyVault: keyVault, orgRepo: mockOrgRepo}
+
+			_, _, err = service.RotateCredentials(context.Background(), agent.ID)
+
+			assert.NoError(t, err)
+			if assert.NotNil(t, agent.KeyCreatedAt) && assert.NotNil(t, agent.KeyExpiresAt) {
+				assert.Equal(t, agent.KeyCreatedAt.AddDate(0, 0, tt.expectedDays), *agent.KeyExpiresAt)
+			}
+		})
+	}
+}
+
+func TestAgentService_UpdateAgentPublicKey_UsesOrgKeyRotationDays(t *testing.T) {
+	orgID := uuid.New()
+	agent := &domain.Agent{ID: uuid.New(), OrganizationID: orgID}
+	mockAgentRepo := new(MockAgentRepository)
+	mockAgentRepo.On("GetByID", agent.ID).Return(agent, nil)
+	mockAgentRepo.On("Update", agent).Return(nil)
+	mockOrgRepo := new(MockOrganizationRepository)
+	mockOrgRepo.On("GetByID", orgID).Return(&domain.Organization{ID: orgID, KeyRotationDays: 90}, nil)
+	service := &AgentService{agentRepo: mockAgentRepo, orgRepo: mockOrgRepo}
+
+	err := service.UpdateAgentPublicKey(context.Background(), agent.ID, "bmV3LXB1YmxpYy1rZXk=")
+
+	assert.NoError(t, err)
+	assert.Equal(t, agent.KeyCreatedAt.AddDate(0, 0, 90), *agent.KeyExpiresAt)
+}
+
+func TestValidateKeyRotationDays(t *testing.T) {
+	for _, days := range []int{7, 90, 365, 3650} {
+		assert.NoError(t, domain.ValidateKeyRotationDays(days), days)
+	}
+	for _, days := range []int{0, 6, 3651, -1} {
+		assert.Error(t, domain.ValidateKeyRotationDays(days), days)
+	}
+}
+
+// ===========================
+// Key Expiry Scan Tests
+// ===========================
+
+func TestAgentService_ScanExpiringKeys(t *testing.T) {
+	// Fake clock: every scan runs at a fixed instant
+	now := time.Date(2025, 6, 1, 9, 0, 0, 0, time.UTC)
+	leadTime := 14 * 24 * time.Hour
+	expiresAt := now.Add(5 * 24 * time.Hour)
+	windowStart := expiresAt.Add(-leadTime)
+
+	tests := []struct {
+		name           string
+		unacknowledged []*domain.Alert
+		recent         []*domain.Alert
+		expectAlert    bool
+	}{
+		{name: "no previous alert", expectAlert: true},
+		{
+			name:           "unresolved alert already exists",
+			unacknowledged: []*domain.Alert{{AlertType: domain.AlertKeyExpiring, CreatedAt: now.Add(-24 * time.Hour)}},
+			expectAlert:    false,
+		},
+		{
+			name:        "acknowledged alert already raised in this window",
+			recent:      []*domain.Alert{{AlertType: domain.AlertKeyExpiring, IsAcknowledged: true, CreatedAt: windowStart.Add(time.Hour)}},
+			expectAlert: false,
+		},
+		{
+			name:        "acknowledged alert from a previous window",
+			recent:      []*domain.Alert{{AlertType: domain.AlertKeyExpiring, IsAcknowledged: true, CreatedAt: windowStart.Add(-time.Hour)}},
+			expectAlert: true,
+		},
+		{
+			name:           "unrelated unresolved alert",
+			unacknowledged: []*domain.Alert{{AlertType: domain.AlertTrustScoreDrop, CreatedAt: now}},
+			expectAlert:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			agent := &domain.Agent{ID: uuid.New(), OrganizationID: uuid.New(), Name: "expiring-agent", KeyExpiresAt: &expiresAt}
+
+			mockAgentRepo := new(MockAgentRepository)
+			mockAgentRepo.On("GetByKeyExpiringBetween", now, now.Add(leadTime)).Return([]*domain.Agent{agent}, nil)
+			mockAlertRepo := new(MockAlertRepository)
+			mockAlertRepo.On("GetUnacknowledgedByResourceID", agent.ID).Return(tt.unacknowledged, nil)
+			mockAlertRepo.On("GetByResourceID", agent.ID, 20, 0).Return(tt.recent, nil).Maybe()
+			mockAlertRepo.On("Create", mock.AnythingOfType("*domain.Alert")).Return(nil).Maybe()
+			service := &AgentService{agentRepo: mockAgentRepo, alertRepo: mockAlertRepo}
+
+			created, err := service.ScanExpiringKeys(context.Background(), now, leadTime)
+
+			assert.NoError(t, err)
+			if !tt.expectAlert {
+				assert.Equal(t, 0, created)
+				mockAlertRepo.AssertNotCalled(t, "Create", mock.Anything)
+				return
+			}
+
+			assert.Equal(t, 1, created)
+			mockAlertRepo.AssertCalled(t, "Create", mock.MatchedBy(func(alert *domain.Alert) bool {
+				return alert.AlertType == domain.AlertKeyExpiring &&
+					alert.Severity == domain.AlertSeverityWarning &&
+					alert.ResourceID == agent.ID &&
+					alert.OrganizationID == agent.OrganizationID &&
+					alert.CreatedAt.Equal(now) &&
+					strings.Contains(alert.Description, "in 5 day(s)")
+			}))
+		})
+	}
+}
+
+func TestAgentService_ScanExpiringKeys_NoRealertOnNextDay(t *testing.T) {
+	day1 := time.Date(2025, 6, 1, 9, 0, 0, 0, time.UTC)
+	day2 := day1.Add(24 * time.Hour)
+	leadTime := 14 * 24 * time.Hour
+	expiresAt := day1.Add(10 * 24 * time.Hour)
+	agent := &domain.Agent{ID: uuid.New(), OrganizationID: uuid.New(), DisplayName: "Expiring Agent", KeyExpiresAt: &expiresAt}
+
+	var createdAlerts []*domain.Alert
+	mockAgentRepo := new(MockAgentRepository)
+	mockAgentRepo.On("GetByKeyExpiringBetween", mock.Anything, mock.Anything).Return([]*domain.Agent{agent}, nil)
+	mockAlertRepo := new(MockAlertRepository)
+	mockAlertRepo.On("GetUnacknowledgedByResourceID", agent.ID).Return([]*domain.Alert{}, nil).Once()
+	mockAlertRepo.On("GetByResourceID", agent.ID, 20, 0).Return([]*domain.Alert{}, nil).Once()
+	mockAlertRepo.On("Create", mock.AnythingOfType("*domain.Alert")).Run(func(args mock.Arguments) {
+		createdAlerts = append(createdAlerts, args.Get(0).(*domain.Alert))
+	}).Return(nil)
+	service := &AgentService{agentRepo: mockAgentRepo, alertRepo: mockAlertRepo}
+
+	created, err := service.ScanExpiringKeys(context.Background(), day1, leadTime)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, created)
+
+	// The next day's scan sees the unresolved alert from day 1
+	mockAlertRepo.On("GetUnacknowledgedByResourceID", agent.ID).Return(createdAlerts, nil)
+	created, err = service.ScanExpiringKeys(context.Background(), day2, leadTime)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, created)
+	assert.Len(t, createdAlerts, 1)
+}
+
+func TestKeyExpiryScanSettingsFromEnv(t *testing.T) {
+	interval, leadTime := KeyExpiryScanSettingsFromEnv()
+	assert.Equal(t, DefaultKeyExpiryScanInterval, interval)
+	assert.Equal(t, 14*24*time.Hour, leadTime)
+
+	t.Setenv("KEY_EXPIRY_SCAN_INTERVAL", "15m")
+	t.Setenv("KEY_EXPIRY_ALERT_LEAD_DAYS", "30")
+	interval, leadTime = KeyExpiryScanSettingsFromEnv()
+	assert.Equal(t, 15*time.Minute, interval)
+	assert.Equal(t, 30*24*time.Hour, leadTime)
+
+	t.Setenv("KEY_EXPIRY_SCAN_INTERVAL", "soon")
+	t.Setenv("KEY_EXPIRY_ALERT_LEAD_DAYS", "-3")
+	interval, leadTime = KeyExpiryScanSettingsFromEnv()
+	assert.Equal(t, DefaultKeyExpiryScanInterval, interval)
+	assert.Equal(t, 14*24*time.Hour, leadTime)
+}
+
+const uploadedClaudeDesktopConfig = `{
+  "mcpServers": {
+    "github": {
+      "command": "npx",
+      "args": ["-y", "@modelcontextprotocol/server-github"],
+      "env": {"GITHUB_PERSONAL_ACCESS_TOKEN": "ghp_example"}
+    },
+    "filesystem": {
+      "command": "npx",
+      "args": ["-y", "@modelcontextprotocol/server-filesystem", "/Users/dev/projects"]
+    },
+    "postgres": {
+      "command": "uvx",
+      "args": ["mcp-server-postgres", "postgresql://localhost/app"]
+    }
+  },
+  "globalShortcut": "Ctrl+Space"
+}`
+
+func TestParseClaudeDesktopConfigBytes_MultipleServers(t *testing.T) {
+	servers, err := ParseClaudeDesktopConfigBytes([]byte(uploadedClaudeDesktopConfig))
+	require.NoError(t, err)
+	require.Len(t, servers, 3)
+
+	assert.Equal(t, "filesystem", servers[0].Name)
+	assert.Equal(t, []string{"-y", "@modelcontextprotocol/server-filesystem", "/Users/dev/projects"}, servers[0].Args)
+	assert.Equal(t, "github", servers[1].Name)
+	assert.Equal(t, "npx", servers[1].Command)
+	assert.Equal(t, "ghp_example", servers[1].Env["GITHUB_PERSONAL_ACCESS_TOKEN"])
+	assert.Equal(t, "postgres", servers[2].Name)
+	assert.Equal(t, "uvx", servers[2].Command)
+	for _, server := range servers {
+		assert.Equal(t, "claude_desktop_config", server.Source)
+		assert.Equal(t, 100.0, server.Confidence)
+	}
+}
+
+func TestParseClaudeDesktopConfigBytes_NoServersAndInvalidJSON(t *testing.T) {
+	servers, err := ParseClaudeDesktopConfigBytes([]byte(`{"globalShortcut": "Ctrl+Space"}`))
+	require.NoError(t, err)
+	assert.Empty(t, servers)
+
+	_, err = ParseClaudeDesktopConfigBytes([]byte(`{"mcpServers": `))
+	assert.Error(t, err)
+}
+
+func TestAgentService_DetectMCPServersFromUpload(t *testing.T) {
+	service := &AgentService{}
+
+	result, err := service.DetectMCPServersFromUpload(context.Background(), uuid.New(), []byte(uploadedClaudeDesktopConfig),
+		&DetectMCPServersRequest{DryRun: true}, nil, uuid.New(), uuid.New())
+	require.NoError(t, err)
+	assert.True(t, result.DryRun)
+	require.Len(t, result.DetectedServers, 3)
+	assert.Equal(t, "upload", result.DetectedServers[0].Metadata["config_source"])
+
+	_, err = service.DetectMCPServersFromUpload(context.Background(), uuid.New(), []byte("not json"),
+		&DetectMCPServersRequest{DryRun: true}, nil, uuid.New(), uuid.New())
+	assert.ErrorIs(t, err, ErrInvalidMCPConfig)
+}
+
+func TestAgentService_UpdateAgentLabels(t *testing.T) {
+	mockAgentRepo := new(MockAgentRepository)
+	service := &AgentService{agentRepo: mockAgentRepo}
+	agent := createTestAgentForService()
+	labels := map[string]string{"env": "prod", "team.owner": "payments"}
+
+	mockAgentRepo.On("UpdateLabels", agent.ID, labels).Return(nil)
+	mockAgentRepo.On("GetByID", agent.ID).Return(agent, nil)
+
+	updated, err := service.UpdateAgentLabels(context.Background(), agent.ID, labels)
+	assert.NoError(t, err)
+	assert.Equal(t, agent.ID, updated.ID)
+
+	// Malformed labels are rejected before reaching the repository
+	for _, invalid := range []map[string]string{
+		{"Env": "prod"},
+		{"env": "prod east"},
+		{"": "prod"},
+		{"-env": "prod"},
+	} {
+		_, err := service.UpdateAgentLabels(context.Background(), agent.ID, invalid)
+		assert.ErrorIs(t, err, domain.ErrInvalidAgentLabels, "labels %v", invalid)
+	}
+	mockAgentRepo.AssertNumberOfCalls(t, "UpdateLabels", 1)
+}
+
+func TestParseAgentLabelSelectors(t *testing.T) {
+	selectors, err := domain.ParseAgentLabelSelectors([]string{"env:prod", "team:payments, tier:"})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"env": "prod", "team": "payments", "tier": ""}, selectors)
+
+	selectors, err = domain.ParseAgentLabelSelectors(nil)
+	assert.NoError(t, err)
+	assert.Nil(t, selectors)
+
+	_, err = domain.ParseAgentLabelSelectors([]string{"env"})
+	assert.ErrorIs(t, err, domain.ErrInvalidAgentLabels)
+
+	_, err = domain.ParseAgentLabelSelectors([]string{"env:prod,env:staging"})
+	assert.ErrorIs(t, err, domain.ErrInvalidAgentLabels)
+}
+
+func TestAgentService_CompromiseAgent_DeniesActionsAndQueuesAlertAndWebhook(t *testing.T) {
+	decisions := NewVerificationDecisionCache(newFakeLookupStore(), time.Hour)
+	agent := createTestAgentForService()
+	service, mockAgentRepo, mockCapabilityRepo := newDecisionCachedVerifyActionService(decisions, agent)
+	userID := uuid.New()
+
+	grant := &domain.AgentCapability{ID: uuid.New(), AgentID: agent.ID, CapabilityType: "*", GrantedAt: time.Now()}
+	var queued []*domain.OutboxMessage
+	mockAgentRepo.On("GetByID", agent.ID).Return(agent, nil)
+	mockAgentRepo.On("MarkCompromisedBy", agent.ID, userID, "leaked key", mock.Anything, mock.Anything).Return(nil).Run(func(args mock.Arguments) {
+		agent.IsCompromised = true
+		agent.Status = domain.AgentStatusSuspended
+		queued = args.Get(4).([]*domain.OutboxMessage)
+	})
+	mockCapabilityRepo.On("GetActiveCapabilitiesByAgentID", agent.ID).Return([]*domain.AgentCapability{grant}, nil)
+
+	ctx := context.Background()
+	allowed, _, _, err := service.VerifyAction(ctx, agent.ID, "file:read", "/test.txt", nil)
+	require.NoError(t, err)
+	require.True(t, allowed)
+
+	updated, err := service.CompromiseAgent(ctx, agent.ID, userID, "leaked key")
+	require.NoError(t, err)
+	assert.True(t, updated.IsCompromised)
+
+	// Every action is denied, including the one whose allow was cached
+	for _, action := range []string{"file:read", "file:write", "api:call"} {
+		allowed, _, _, err := service.VerifyAction(ctx, agent.ID, action, "/test.txt", nil)
+		require.NoError(t, err)
+		assert.False(t, allowed, action)
+	}
+
+	// A critical alert and an agent.compromised webhook event are queued with the flag
+	require.Len(t, queued, 2)
+	assert.Equal(t, domain.OutboxTopicAlert, queued[0].Topic)
+	var alert domain.Alert
+	require.NoError(t, json.Unmarshal(queued[0].Payload, &alert))
+	assert.Equal(t, domain.AlertSeverityCritical, alert.Severity)
+	assert.Equal(t, agent.ID, alert.ResourceID)
+	assert.Contains(t, alert.Description, "leaked key")
+
+	assert.Equal(t, domain.OutboxTopicWebhook, queued[1].Topic)
+	var event domain.OutboxWebhookEvent
+	require.NoError(t, json.Unmarshal(queued[1].Payload, &event))
+	assert.Equal(t, domain.WebhookEventAgentCompromised, event.Event)
+	var data agentCompromiseWebhookData
+	require.NoError(t, json.Unmarshal(event.Data, &data))
+	assert.Equal(t, userID, data.ChangedBy)
+	assert.True(t, data.Compromised)
+	assert.Equal(t, domain.AgentStatusVerified, data.PreviousStatus)
+	assert.Equal(t, domain.AgentStatusSuspended, data.Status)
+
+	// Flagging it again is rejected rather than raising a second alert
+	_, err = service.CompromiseAgent(ctx, agent.ID, userID, "again")
+	assert.ErrorIs(t, err, ErrAgentAlreadyCompromised)
+	mockAgentRepo.AssertNumberOfCalls(t, "MarkCompromisedBy", 1)
+}
+
+func TestAgentService_UncompromiseAgent(t *testing.T) {
+	mockAgentRepo := new(MockAgentRepository)
+	service := &AgentService{agentRepo: mockAgentRepo}
+	agent := createTestAgentForService()
+	agent.Status = domain.AgentStatusSuspended
+	agent.IsCompromised = true
+	userID := uuid.New()
+
+	var queued []*domain.OutboxMessage
+	mockAgentRepo.On("GetByID", agent.ID).Return(agent, nil)
+	mockAgentRepo.On("ClearCompromised", agent.ID, mock.Anything).Return(nil).Run(func(args mock.Arguments) {
+		agent.IsCompromised = false
+		queued = args.Get(1).([]*domain.OutboxMessage)
+	})
+
+	updated, err := service.UncompromiseAgent(context.Background(), agent.ID, userID)
+	require.NoError(t, err)
+	assert.False(t, updated.IsCompromised)
+	// Clearing the flag does not reactivate the agent
+	assert.Equal(t, domain.AgentStatusSuspended, updated.Status)
+
+	require.Len(t, queued, 1)
+	var event domain.OutboxWebhookEvent
+	require.NoError(t, json.Unmarshal(queued[0].Payload, &event))
+	assert.Equal(t, domain.WebhookEventAgentUncompromised, event.Event)
+
+	_, err = service.UncompromiseAgent(context.Background(), agent.ID, userID)
+	assert.ErrorIs(t, err, ErrAgentNotCompromised)
+}
+
+func TestAgentService_StatusTransitions_QueueWebhookEvents(t *testing.T) {
+	tests := []struct {
+		name       string
+		fromStatus domain.AgentStatus
+		transition func(s *AgentService, id uuid.UUID) error
+		wantStatus domain.AgentStatus
+		wantEvent  domain.WebhookEvent
+	}{
+		{
+			name:       "verify pending agent",
+			fromStatus: domain.AgentStatusPending,
+			transition: func(s *AgentService, id uuid.UUID) error { return s.VerifyAgent(context.Background(), id) },
+			wantStatus: domain.AgentStatusVerified,
+			wantEvent:  domain.WebhookEventAgentVerified,
+		},
+		{
+			name:       "suspend verified agent",
+			fromStatus: domain.AgentStatusVerified,
+			transition: func(s *AgentService, id uuid.UUID) error { return s.SuspendAgent(context.Background(), id) },
+			wantStatus: domain.AgentStatusSuspended,
+			wantEvent:  domain.WebhookEventAgentSuspended,
+		},
+		{
+			name:       "reactivate suspended agent",
+			fromStatus: domain.AgentStatusSuspended,
+			transition: func(s *AgentService, id uuid.UUID) error { return s.ReactivateAgent(context.Background(), id) },
+			wantStatus: domain.AgentStatusVerified,
+			wantEvent:  domain.WebhookEventAgentReactivated,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockAgentRepo := new(MockAgentRepository)
+			mockTrustCalc := new(AgentServiceMockTrustScoreCalculator)
+			mockTrustScoreRepo := new(AgentServiceMockTrustScoreRepository)
+			service := &AgentService{agentRepo: mockAgentRepo, trustCalc: mockTrustCalc, trustScoreRepo: mockTrustScoreRepo}
+
+			agent := createTestAgentForService()
+			agent.Status = tt.fromStatus
+			agent.TrustScore = 0.6
+			trustScore := &domain.TrustScore{AgentID: agent.ID, Score: 0.8}
+
+			var queued []*domain.OutboxMessage
+			mockAgentRepo.On("GetByID", agent.ID).Return(agent, nil)
+			mockAgentRepo.On("UpdateStatus", agent, mock.Anything).Return(nil).Run(func(args mock.Arguments) {
+				queued = args.Get(1).([]*domain.OutboxMessage)
+			})
+			mockTrustCalc.On("Calculate", agent).Return(trustScore, nil)
+			mockTrustScoreRepo.On("Create", trustScore).Return(nil)
+
+			require.NoError(t, tt.transition(service, agent.ID))
+
+			require.Len(t, queued, 1)
+			assert.Equal(t, domain.OutboxTopicWebhook, queued[0].Topic)
+			var event domain.OutboxWebhookEvent
+			require.NoError(t, json.Unmarshal(queued[0].Payload, &event))
+			assert.Equal(t, tt.wantEvent, event.Event)
+			assert.Equal(t, string(domain.WebhookResourceAgent), event.ResourceType)
+
+			var data domain.AgentStatusWebhookData
+			require.NoError(t, json.Unmarshal(event.Data, &data))
+			assert.Equal(t, agent.ID, data.AgentID)
+			assert.Equal(t, tt.fromStatus, data.PreviousStatus)
+			assert.Equal(t, tt.wantStatus, data.Status)
+			assert.Equal(t, 0.6, data.PreviousTrustScore)
+			assert.Equal(t, 0.8, data.TrustScore)
+			mockTrustScoreRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestAgentService_SuspendAgent_AlreadySuspendedQueuesNoWebhook(t *testing.T) {
+	mockAgentRepo := new(MockAgentRepository)
+	mockTrustCalc := new(AgentServiceMockTrustScoreCalculator)
+	service := &AgentService{agentRepo: mockAgentRepo, trustCalc: mockTrustCalc}
+
+	agent := createTestAgentForService()
+	agent.Status = domain.AgentStatusSuspended
+
+	mockAgentRepo.On("GetByID", agent.ID).Return(agent, nil)
+	mockAgentRepo.On("UpdateStatus", agent, []*domain.OutboxMessage(nil)).Return(nil)
+	mockTrustCalc.On("Calculate", agent).Return(nil, assert.AnError)
+
+	require.NoError(t, service.SuspendAgent(context.Background(), agent.ID))
+	mockAgentRepo.AssertExpectations(t)
+}
@@ -4,12 +4,26 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"os"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/opena2a/identity/backend/internal/domain"
 )
 
+// DefaultAlertDedupWindow is how long after its last occurrence an open alert absorbs identical
+// alerts; override with ALERT_DEDUP_WINDOW (Go duration, "0" disables deduplication)
+const DefaultAlertDedupWindow = 5 * time.Minute
+
+// AlertDedupWindowFromEnv reads ALERT_DEDUP_WINDOW, falling back to the default
+func AlertDedupWindowFromEnv() time.Duration {
+	if value, err := time.ParseDuration(os.Getenv("ALERT_DEDUP_WINDOW")); err == nil && value >= 0 {
+		return value
+	}
+	return DefaultAlertDedupWindow
+}
+
 // AlertService handles alert management
 type AlertService struct {
 	alertRepo domain.AlertRepository
@@ -30,7 +44,8 @@ func NewAlertService(
 	}
 }
 
-// CreateAlert creates a new alert
+// CreateAlert creates a new alert. The repository collapses it into an identical open alert
+// within the dedup window.
 func (s *AlertService) CreateAlert(ctx context.Context, alert *domain.Alert) error {
 	return s.alertRepo.Create(alert)
 }
@@ -145,14 +160,14 @@ func (s *AlertService) GetAlerts(
 	return alerts, total, nil
 }
 
-// AcknowledgeAlert acknowledges an alert
+// AcknowledgeAlert acknowledges an alert, recording the user and time
 func (s *AlertService) AcknowledgeAlert(
 	ctx context.Context,
 	alertID uuid.UUID,
 	orgID uuid.UUID,
 	userID uuid.UUID,
 ) error {
-	return s.alertRepo.Acknowledge(alertID, userID)
+	return s.alertRepo.Acknowledge(alertID, userID, time.Now().UTC())
 }
 
 // BulkAcknowledgeAlerts acknowledges multiple alerts in one request
@@ -164,7 +179,8 @@ func (s *AlertService) BulkAcknowledgeAlerts(
 	return s.alertRepo.BulkAcknowledge(orgID, userID)
 }
 
-// ResolveAlert marks an alert as resolved
+// ResolveAlert marks an alert as resolved, recording the user, time and an optional resolution note.
+// An alert that was never acknowledged is acknowledged by the resolving user as well.
 func (s *AlertService) ResolveAlert(
 	ctx context.Context,
 	alertID uuid.UUID,
@@ -172,9 +188,11 @@ func (s *AlertService) ResolveAlert(
 	userID uuid.UUID,
 	resolution string,
 ) error {
-	// For now, just acknowledge it
-	// TODO: Add a resolved status to the domain model
-	return s.alertRepo.Acknowledge(alertID, userID)
+	var note *string
+	if resolution = strings.TrimSpace(resolution); resolution != "" {
+		note = &resolution
+	}
+	return s.alertRepo.Resolve(alertID, userID, note, time.Now().UTC())
 }
 
 // ApproveDriftRequest contains the request data for approving drift
@@ -592,7 +610,7 @@ func (s *AlertService) ApproveDrift(ctx context.Context, req *ApproveDriftReques
 	}
 
 	// 7. Acknowledge the alert
-	if err := s.alertRepo.Acknowledge(req.AlertID, req.UserID); err != nil {
+	if err := s.alertRepo.Acknowledge(req.AlertID, req.UserID, time.Now().UTC()); err != nil {
 		return fmt.Errorf("failed to acknowledge alert: %w", err)
 	}
 
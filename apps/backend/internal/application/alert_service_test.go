@@ -0,0 +1,85 @@
+package application
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// inMemoryAlertRepository keeps the alerts created through it; other methods panic through the
+// nil embedded interface
+type inMemoryAlertRepository struct {
+	domain.AlertRepository
+	alerts []*domain.Alert
+}
+
+func (r *inMemoryAlertRepository) Create(alert *domain.Alert) error {
+	if alert.ID == uuid.Nil {
+		alert.ID = uuid.New()
+	}
+	if alert.OccurrenceCount < 1 {
+		alert.OccurrenceCount = 1
+	}
+	if alert.CreatedAt.IsZero() {
+		alert.CreatedAt = time.Now()
+	}
+	stored := *alert
+	r.alerts = append(r.alerts, &stored)
+	return nil
+}
+
+// recentTime matches a timestamp taken during the test
+func recentTime(since time.Time) interface{} {
+	return mock.MatchedBy(func(at time.Time) bool {
+		return !at.Before(since.Add(-time.Second)) && !at.After(time.Now())
+	})
+}
+
+func TestAlertService_AcknowledgeAlert_RecordsUserAndTime(t *testing.T) {
+	alertID, orgID, userID := uuid.New(), uuid.New(), uuid.New()
+	startedAt := time.Now()
+
+	mockAlertRepo := new(MockAlertRepository)
+	mockAlertRepo.On("Acknowledge", alertID, userID, recentTime(startedAt)).Return(nil)
+
+	service := NewAlertService(mockAlertRepo, nil, nil)
+	err := service.AcknowledgeAlert(context.Background(), alertID, orgID, userID)
+
+	assert.NoError(t, err)
+	mockAlertRepo.AssertExpectations(t)
+}
+
+func TestAlertService_ResolveAlert_RecordsUserTimeAndNote(t *testing.T) {
+	alertID, orgID, userID := uuid.New(), uuid.New(), uuid.New()
+	startedAt := time.Now()
+
+	mockAlertRepo := new(MockAlertRepository)
+	mockAlertRepo.On("Resolve", alertID, userID,
+		mock.MatchedBy(func(note *string) bool { return note != nil && *note == "Rotated the leaked key" }),
+		recentTime(startedAt),
+	).Return(nil)
+
+	service := NewAlertService(mockAlertRepo, nil, nil)
+	err := service.ResolveAlert(context.Background(), alertID, orgID, userID, "  Rotated the leaked key ")
+
+	assert.NoError(t, err)
+	mockAlertRepo.AssertExpectations(t)
+}
+
+func TestAlertService_ResolveAlert_WithoutNote(t *testing.T) {
+	alertID, orgID, userID := uuid.New(), uuid.New(), uuid.New()
+
+	mockAlertRepo := new(MockAlertRepository)
+	mockAlertRepo.On("Resolve", alertID, userID, (*string)(nil), mock.AnythingOfType("time.Time")).Return(nil)
+
+	service := NewAlertService(mockAlertRepo, nil, nil)
+	err := service.ResolveAlert(context.Background(), alertID, orgID, userID, "   ")
+
+	assert.NoError(t, err)
+	mockAlertRepo.AssertExpectations(t)
+}
@@ -5,32 +5,56 @@ import (
 	"crypto/rand"
 	"crypto/sha256"
 	"encoding/base64"
+	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/opena2a/identity/backend/internal/domain"
+	"github.com/opena2a/identity/backend/internal/infrastructure/logging"
 )
 
 // APIKeyService handles API key operations
 type APIKeyService struct {
 	apiKeyRepo domain.APIKeyRepository
 	agentRepo  domain.AgentRepository
+	auditRepo  domain.AuditLogRepository
 }
 
 // NewAPIKeyService creates a new API key service
 func NewAPIKeyService(
 	apiKeyRepo domain.APIKeyRepository,
 	agentRepo domain.AgentRepository,
+	auditRepo domain.AuditLogRepository,
 ) *APIKeyService {
 	return &APIKeyService{
 		apiKeyRepo: apiKeyRepo,
 		agentRepo:  agentRepo,
+		auditRepo:  auditRepo,
 	}
 }
 
-// GenerateAPIKey generates a new API key for an agent
-func (s *APIKeyService) GenerateAPIKey(ctx context.Context, agentID, orgID, userID uuid.UUID, name string, expiresInDays int) (string, *domain.APIKey, error) {
+// APIKeyExpirySweepInterval is how often expired API keys are disabled
+const APIKeyExpirySweepInterval = 15 * time.Minute
+
+// ErrAPIKeyExpiryInPast is returned when an API key is requested with an expiry that has already passed
+var ErrAPIKeyExpiryInPast = errors.New("API key expiry must be in the future")
+
+// ErrInvalidAPIKeyScope is returned when an API key is requested with an unknown scope
+var ErrInvalidAPIKeyScope = errors.New("invalid API key scope")
+
+// GenerateAPIKey generates a new API key for an agent. A nil expiresAt creates a key that never
+// expires. scopes restricts what the key can call; a key without scopes is read-only.
+func (s *APIKeyService) GenerateAPIKey(ctx context.Context, agentID, orgID, userID uuid.UUID, name string, expiresAt *time.Time, scopes []string) (string, *domain.APIKey, error) {
+	scopes, err := normalizeAPIKeyScopes(scopes)
+	if err != nil {
+		return "", nil, err
+	}
+	if expiresAt != nil && !expiresAt.After(time.Now()) {
+		return "", nil, ErrAPIKeyExpiryInPast
+	}
+
 	// Verify agent exists and belongs to organization
 	agent, err := s.agentRepo.GetByID(agentID)
 	if err != nil {
@@ -60,13 +84,6 @@ func (s *APIKeyService) GenerateAPIKey(ctx context.Context, agentID, orgID, user
 	// Extract prefix (first 12 chars after aim_live_)
 	prefix := fullKey[:16] // "aim_live_" + first 8 chars
 
-	// Calculate expiry
-	var expiresAt *time.Time
-	if expiresInDays > 0 {
-		expiry := time.Now().AddDate(0, 0, expiresInDays)
-		expiresAt = &expiry
-	}
-
 	// Create API key record
 	apiKey := &domain.APIKey{
 		OrganizationID: orgID,
@@ -75,6 +92,7 @@ func (s *APIKeyService) GenerateAPIKey(ctx context.Context, agentID, orgID, user
 		KeyHash:        keyHash,
 		Prefix:         prefix,
 		ExpiresAt:      expiresAt,
+		Scopes:         scopes,
 		IsActive:       true,
 		CreatedBy:      userID,
 	}
@@ -86,9 +104,44 @@ func (s *APIKeyService) GenerateAPIKey(ctx context.Context, agentID, orgID, user
 	return fullKey, apiKey, nil
 }
 
-// ListAPIKeys lists all API keys for an organization
+// normalizeAPIKeyScopes validates scope names and removes duplicates
+func normalizeAPIKeyScopes(scopes []string) ([]string, error) {
+	normalized := []string{}
+	seen := make(map[string]bool, len(scopes))
+	for _, scope := range scopes {
+		scope = strings.TrimSpace(scope)
+		if !domain.IsValidAPIKeyScope(scope) {
+			return nil, fmt.Errorf("%w: %q", ErrInvalidAPIKeyScope, scope)
+		}
+		if seen[scope] {
+			continue
+		}
+		seen[scope] = true
+		normalized = append(normalized, scope)
+	}
+	return normalized, nil
+}
+
+// ListAPIKeys lists all API keys for an organization, with the remaining validity of keys that expire
 func (s *APIKeyService) ListAPIKeys(ctx context.Context, orgID uuid.UUID) ([]*domain.APIKey, error) {
-	return s.apiKeyRepo.GetByOrganization(orgID)
+	keys, err := s.apiKeyRepo.GetByOrganization(orgID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	for _, key := range keys {
+		if key.ExpiresAt == nil {
+			continue
+		}
+		remaining := int64(key.ExpiresAt.Sub(now).Seconds())
+		if remaining < 0 {
+			remaining = 0
+		}
+		key.ExpiresIn = &remaining
+	}
+
+	return keys, nil
 }
 
 // RevokeAPIKey revokes an API key (sets is_active=false)
@@ -142,8 +195,8 @@ func (s *APIKeyService) ValidateAPIKey(ctx context.Context, fullKey string) (*do
 		return nil, fmt.Errorf("invalid API key")
 	}
 
-	// Check if expired
-	if apiKey.ExpiresAt != nil && time.Now().After(*apiKey.ExpiresAt) {
+	// Expired keys are invalid even before the expiry sweep disables them
+	if apiKey.ExpiresAt != nil && !apiKey.ExpiresAt.After(time.Now()) {
 		return nil, fmt.Errorf("API key expired")
 	}
 
@@ -152,3 +205,60 @@ func (s *APIKeyService) ValidateAPIKey(ctx context.Context, fullKey string) (*do
 
 	return apiKey, nil
 }
+
+// StartExpirySweeper runs DisableExpiredKeys every interval until ctx is cancelled
+func (s *APIKeyService) StartExpirySweeper(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := s.DisableExpiredKeys(ctx, time.Now()); err != nil {
+				logging.FromContext(ctx).Warn("API key expiry sweep failed", "error", err)
+			}
+		}
+	}
+}
+
+// DisableExpiredKeys sets is_active=false on every active key whose expiry has passed and
+// records an audit entry for each. Returns the number of keys disabled.
+func (s *APIKeyService) DisableExpiredKeys(ctx context.Context, now time.Time) (int, error) {
+	keys, err := s.apiKeyRepo.GetExpiredActive(now)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list expired API keys: %w", err)
+	}
+
+	disabled := 0
+	for _, key := range keys {
+		if err := s.apiKeyRepo.Revoke(key.ID); err != nil {
+			logging.FromContext(ctx).Warn("failed to disable expired API key", "api_key_id", key.ID, "error", err)
+			continue
+		}
+		disabled++
+
+		if s.auditRepo == nil {
+			continue
+		}
+		auditLog := &domain.AuditLog{
+			OrganizationID: key.OrganizationID,
+			UserID:         key.CreatedBy,
+			Action:         domain.AuditActionExpire,
+			ResourceType:   "api_key",
+			ResourceID:     key.ID,
+			Metadata: map[string]interface{}{
+				"keyName":    key.Name,
+				"agentId":    key.AgentID.String(),
+				"expiresAt":  key.ExpiresAt,
+				"disabledBy": "system",
+			},
+		}
+		if err := s.auditRepo.Create(auditLog); err != nil {
+			logging.FromContext(ctx).Warn("failed to audit expired API key", "api_key_id", key.ID, "error", err)
+		}
+	}
+
+	return disabled, nil
+}
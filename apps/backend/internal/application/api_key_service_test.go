@@ -0,0 +1,124 @@
+package application
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAPIKeyService_GenerateAPIKey_Scopes(t *testing.T) {
+	orgID := uuid.New()
+	agentID := uuid.New()
+
+	mockAgentRepo := new(MockAgentRepository)
+	mockAgentRepo.On("GetByID", agentID).Return(&domain.Agent{ID: agentID, OrganizationID: orgID}, nil)
+	mockAPIKeyRepo := new(MockAPIKeyRepository)
+	mockAPIKeyRepo.On("Create", mock.AnythingOfType("*domain.APIKey")).Return(nil)
+	service := NewAPIKeyService(mockAPIKeyRepo, mockAgentRepo, nil)
+
+	_, apiKey, err := service.GenerateAPIKey(context.Background(), agentID, orgID, uuid.New(), "monitoring", nil,
+		[]string{"agents:read", " agents:read", "verifications:read"})
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{"agents:read", "verifications:read"}, apiKey.Scopes)
+	mockAPIKeyRepo.AssertExpectations(t)
+}
+
+func TestAPIKeyService_GenerateAPIKey_RejectsUnknownScope(t *testing.T) {
+	mockAPIKeyRepo := new(MockAPIKeyRepository)
+	service := NewAPIKeyService(mockAPIKeyRepo, new(MockAgentRepository), nil)
+
+	_, _, err := service.GenerateAPIKey(context.Background(), uuid.New(), uuid.New(), uuid.New(), "monitoring", nil,
+		[]string{"agents:read", "agents:delete"})
+
+	assert.ErrorIs(t, err, ErrInvalidAPIKeyScope)
+	mockAPIKeyRepo.AssertNotCalled(t, "Create", mock.Anything)
+}
+
+func TestAPIKeyScopesAllow(t *testing.T) {
+	assert.True(t, domain.APIKeyScopesAllow(nil, domain.APIKeyScopeAgentsRead))
+	assert.False(t, domain.APIKeyScopesAllow(nil, domain.APIKeyScopeAgentsWrite))
+	assert.True(t, domain.APIKeyScopesAllow([]string{domain.APIKeyScopeAgentsRead}, domain.APIKeyScopeAgentsRead))
+	assert.False(t, domain.APIKeyScopesAllow([]string{domain.APIKeyScopeAgentsRead}, domain.APIKeyScopeAgentsWrite))
+	assert.True(t, domain.APIKeyScopesAllow([]string{domain.APIKeyScopeAgentsWrite}, domain.APIKeyScopeAgentsRead))
+	assert.False(t, domain.APIKeyScopesAllow([]string{domain.APIKeyScopeAgentsWrite}, domain.APIKeyScopeVerificationsRead))
+}
+
+func TestAPIKeyService_GenerateAPIKey_RejectsPastExpiry(t *testing.T) {
+	mockAPIKeyRepo := new(MockAPIKeyRepository)
+	service := NewAPIKeyService(mockAPIKeyRepo, new(MockAgentRepository), nil)
+	expiresAt := time.Now().Add(-time.Minute)
+
+	_, _, err := service.GenerateAPIKey(context.Background(), uuid.New(), uuid.New(), uuid.New(), "monitoring", &expiresAt, nil)
+
+	assert.ErrorIs(t, err, ErrAPIKeyExpiryInPast)
+	mockAPIKeyRepo.AssertNotCalled(t, "Create", mock.Anything)
+}
+
+func TestAPIKeyService_ValidateAPIKey_RejectsExpiredKey(t *testing.T) {
+	expiresAt := time.Now().Add(-time.Hour)
+	mockAPIKeyRepo := new(MockAPIKeyRepository)
+	mockAPIKeyRepo.On("GetByHash", mock.Anything).Return(&domain.APIKey{ID: uuid.New(), IsActive: true, ExpiresAt: &expiresAt}, nil)
+	service := NewAPIKeyService(mockAPIKeyRepo, new(MockAgentRepository), nil)
+
+	_, err := service.ValidateAPIKey(context.Background(), "aim_live_expired")
+
+	assert.Error(t, err)
+	mockAPIKeyRepo.AssertNotCalled(t, "UpdateLastUsed", mock.Anything)
+}
+
+func TestAPIKeyService_DisableExpiredKeys(t *testing.T) {
+	now := time.Date(2025, 4, 1, 12, 0, 0, 0, time.UTC)
+	expiresAt := now.Add(-time.Hour)
+	expired := &domain.APIKey{ID: uuid.New(), OrganizationID: uuid.New(), AgentID: uuid.New(), CreatedBy: uuid.New(), Name: "ci", ExpiresAt: &expiresAt, IsActive: true}
+
+	mockAPIKeyRepo := new(MockAPIKeyRepository)
+	mockAPIKeyRepo.On("GetExpiredActive", now).Return([]*domain.APIKey{expired}, nil)
+	mockAPIKeyRepo.On("Revoke", expired.ID).Return(nil)
+	mockAuditRepo := new(AgentServiceMockAuditLogRepository)
+	mockAuditRepo.On("Create", mock.MatchedBy(func(log *domain.AuditLog) bool {
+		return log.Action == domain.AuditActionExpire &&
+			log.ResourceType == "api_key" &&
+			log.ResourceID == expired.ID &&
+			log.OrganizationID == expired.OrganizationID &&
+			log.UserID == expired.CreatedBy
+	})).Return(nil)
+	service := NewAPIKeyService(mockAPIKeyRepo, new(MockAgentRepository), mockAuditRepo)
+
+	disabled, err := service.DisableExpiredKeys(context.Background(), now)
+
+	require.NoError(t, err)
+	assert.Equal(t, 1, disabled)
+	mockAPIKeyRepo.AssertExpectations(t)
+	mockAuditRepo.AssertExpectations(t)
+}
+
+func TestAPIKeyService_ListAPIKeys_RemainingValidity(t *testing.T) {
+	orgID := uuid.New()
+	inTwoDays := time.Now().Add(48 * time.Hour)
+	lastWeek := time.Now().AddDate(0, 0, -7)
+	keys := []*domain.APIKey{
+		{ID: uuid.New(), ExpiresAt: &inTwoDays},
+		{ID: uuid.New(), ExpiresAt: &lastWeek},
+		{ID: uuid.New()},
+	}
+	mockAPIKeyRepo := new(MockAPIKeyRepository)
+	mockAPIKeyRepo.On("GetByOrganization", orgID).Return(keys, nil)
+	service := NewAPIKeyService(mockAPIKeyRepo, new(MockAgentRepository), nil)
+
+	listed, err := service.ListAPIKeys(context.Background(), orgID)
+
+	require.NoError(t, err)
+	require.Len(t, listed, 3)
+	require.NotNil(t, listed[0].ExpiresIn)
+	assert.InDelta(t, 48*3600, *listed[0].ExpiresIn, 5)
+	require.NotNil(t, listed[1].ExpiresIn)
+	assert.Zero(t, *listed[1].ExpiresIn)
+	assert.Nil(t, listed[2].ExpiresIn)
+}
@@ -2,6 +2,7 @@ package application
 
 import (
 	"context"
+	"io"
 	"time"
 
 	"github.com/google/uuid"
@@ -20,7 +21,11 @@ func NewAuditService(auditRepo domain.AuditLogRepository) *AuditService {
 	}
 }
 
-// Log creates an audit log entry
+// auditChainPageSize is how many entries VerifyChain reads at a time
+const auditChainPageSize = 1000
+
+// Log creates an audit log entry. The repository appends it to the organization's hash chain,
+// setting its chain sequence, previous hash and entry hash.
 func (s *AuditService) Log(ctx context.Context, log *domain.AuditLog) error {
 	return s.auditRepo.Create(log)
 }
@@ -49,6 +54,79 @@ func (s *AuditService) LogAction(
 	return s.auditRepo.Create(log)
 }
 
+// VerifyChain walks the organization's audit log hash chain and reports the first entry that was
+// altered, or whose link to the entry before it is broken by an inserted or deleted entry
+func (s *AuditService) VerifyChain(ctx context.Context, orgID uuid.UUID) (*domain.AuditChainVerification, error) {
+	result := &domain.AuditChainVerification{
+		OrganizationID: orgID,
+		Valid:          true,
+		VerifiedAt:     time.Now(),
+	}
+
+	// Read the head first; entries logged while verifying come after it and are left unchecked
+	head, err := s.auditRepo.GetChainHead(orgID)
+	if err != nil {
+		return nil, err
+	}
+	if head != nil {
+		result.HeadSequence = head.ChainSequence
+		result.HeadHash = head.EntryHash
+	}
+
+	var prev *domain.AuditLog
+	var after int64
+	for {
+		entries, err := s.auditRepo.GetChain(orgID, after, auditChainPageSize)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, entry := range entries {
+			if head != nil && entry.ChainSequence > head.ChainSequence {
+				return verifyAuditChainHead(result, prev, head), nil
+			}
+			if err := entry.VerifyLink(prev); err != nil {
+				id := entry.ID
+				result.Valid = false
+				result.BrokenEntryID = &id
+				result.BrokenSequence = entry.ChainSequence
+				result.Reason = err.Error()
+				return result, nil
+			}
+
+			if prev == nil {
+				result.FirstSequence = entry.ChainSequence
+			}
+			result.EntriesChecked++
+			result.LastSequence = entry.ChainSequence
+			prev = entry
+		}
+
+		if len(entries) < auditChainPageSize {
+			return verifyAuditChainHead(result, prev, head), nil
+		}
+		after = prev.ChainSequence
+	}
+}
+
+// verifyAuditChainHead marks result broken unless the chain, whose last checked entry is last,
+// ends at head. Organizations without a head have not logged anything since heads were stored.
+func verifyAuditChainHead(result *domain.AuditChainVerification, last *domain.AuditLog, head *domain.AuditChainHead) *domain.AuditChainVerification {
+	if head == nil {
+		return result
+	}
+	if err := head.VerifyHead(last); err != nil {
+		result.Valid = false
+		result.BrokenSequence = head.ChainSequence
+		if last != nil && last.ChainSequence == head.ChainSequence {
+			id := last.ID
+			result.BrokenEntryID = &id
+		}
+		result.Reason = err.Error()
+	}
+	return result
+}
+
 // GetLogs retrieves audit logs for an organization
 func (s *AuditService) GetLogs(ctx context.Context, orgID uuid.UUID, limit, offset int) ([]*domain.AuditLog, error) {
 	return s.auditRepo.GetByOrganization(orgID, limit, offset)
@@ -64,12 +142,13 @@ func (s *AuditService) GetResourceLogs(ctx context.Context, resourceType string,
 	return s.auditRepo.GetByResource(resourceType, resourceID)
 }
 
-// SearchLogs searches audit logs
-func (s *AuditService) SearchLogs(ctx context.Context, query string, limit, offset int) ([]*domain.AuditLog, error) {
-	return s.auditRepo.Search(query, limit, offset)
+// SearchLogs returns a page of the organization's audit logs matching filter and the total match count
+func (s *AuditService) SearchLogs(ctx context.Context, orgID uuid.UUID, filter domain.AuditLogFilter, limit, offset int) ([]*domain.AuditLog, int, error) {
+	return s.auditRepo.Search(orgID, filter, limit, offset)
 }
 
-// GetAuditLogs retrieves audit logs with filtering
+// GetAuditLogs retrieves audit logs with filtering; nil and empty filters match everything.
+// The returned total counts every matching log, not just the page.
 func (s *AuditService) GetAuditLogs(
 	ctx context.Context,
 	orgID uuid.UUID,
@@ -82,12 +161,70 @@ func (s *AuditService) GetAuditLogs(
 	limit int,
 	offset int,
 ) ([]*domain.AuditLog, int, error) {
-	// For now, just return organization logs
-	// TODO: Implement full filtering in repository layer
-	logs, err := s.auditRepo.GetByOrganization(orgID, limit, offset)
+	return s.SearchLogs(ctx, orgID, domain.AuditLogFilter{
+		Action:       domain.AuditAction(action),
+		ResourceType: entityType,
+		ResourceID:   entityID,
+		UserID:       userID,
+		StartDate:    startDate,
+		EndDate:      endDate,
+	}, limit, offset)
+}
+
+// ExportAgentLogs streams the audit trail of one of the organization's agents to w as JSON or CSV,
+// in the same formats as the compliance audit log export. Nil dates leave that end of the range open.
+// Returns the number of exported logs.
+func (s *AuditService) ExportAgentLogs(
+	ctx context.Context,
+	w io.Writer,
+	orgID uuid.UUID,
+	agentID uuid.UUID,
+	startDate *time.Time,
+	endDate *time.Time,
+	format string,
+) (int, error) {
+	exporter, err := newAuditLogExporter(w, format)
 	if err != nil {
-		return nil, 0, err
+		return 0, err
+	}
+	if err := exporter.begin(); err != nil {
+		return 0, err
+	}
+
+	filter := domain.AuditLogFilter{
+		ResourceType: "agent",
+		ResourceID:   &agentID,
+		StartDate:    startDate,
+		EndDate:      endDate,
+	}
+
+	// Page with a keyset cursor: entries logged while the export runs are newer than the cursor,
+	// so they cannot shift later pages the way they would shift an offset
+	exported := 0
+	var after *domain.AuditLogCursor
+	for {
+		if err := ctx.Err(); err != nil {
+			return exported, err
+		}
+
+		logs, err := s.auditRepo.SearchAfter(orgID, filter, after, auditExportPageSize)
+		if err != nil {
+			return exported, err
+		}
+
+		for _, log := range logs {
+			if err := exporter.write(log); err != nil {
+				return exported, err
+			}
+			exported++
+		}
+
+		if len(logs) < auditExportPageSize {
+			break
+		}
+		last := logs[len(logs)-1]
+		after = &domain.AuditLogCursor{Timestamp: last.Timestamp, ID: last.ID}
 	}
 
-	return logs, len(logs), nil
+	return exported, exporter.end()
 }
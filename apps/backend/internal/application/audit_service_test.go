@@ -0,0 +1,330 @@
+package application
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// chainAuditLogRepository chains entries like the Postgres repository and hands back copies
+// whose metadata went through a JSON round trip, as it does through JSONB
+type chainAuditLogRepository struct {
+	domain.AuditLogRepository
+	entries []*domain.AuditLog
+	head    *domain.AuditChainHead
+}
+
+func (r *chainAuditLogRepository) Create(log *domain.AuditLog) error {
+	log.ID = uuid.New()
+	log.Timestamp = time.Now().UTC().Truncate(domain.AuditChainTimestampPrecision)
+	log.ChainSequence = 1
+	log.PrevHash = ""
+	if r.head != nil {
+		log.ChainSequence = r.head.ChainSequence + 1
+		log.PrevHash = r.head.EntryHash
+	}
+
+	var err error
+	if log.EntryHash, err = log.ComputeHash(); err != nil {
+		return err
+	}
+
+	stored := *log
+	raw, err := json.Marshal(log.Metadata)
+	if err != nil {
+		return err
+	}
+	stored.Metadata = nil
+	if err := json.Unmarshal(raw, &stored.Metadata); err != nil {
+		return err
+	}
+	r.entries = append(r.entries, &stored)
+	r.head = &domain.AuditChainHead{OrganizationID: log.OrganizationID, ChainSequence: log.ChainSequence, EntryHash: log.EntryHash}
+	return nil
+}
+
+func (r *chainAuditLogRepository) GetChainHead(orgID uuid.UUID) (*domain.AuditChainHead, error) {
+	if r.head == nil || r.head.OrganizationID != orgID {
+		return nil, nil
+	}
+	return r.head, nil
+}
+
+func (r *chainAuditLogRepository) GetChain(orgID uuid.UUID, afterSequence int64, limit int) ([]*domain.AuditLog, error) {
+	var page []*domain.AuditLog
+	for _, entry := range r.entries {
+		if entry.OrganizationID == orgID && entry.ChainSequence > afterSequence && len(page) < limit {
+			page = append(page, entry)
+		}
+	}
+	return page, nil
+}
+
+func newChainedAuditService(t *testing.T, orgID uuid.UUID, count int) (*AuditService, *chainAuditLogRepository) {
+	repo := &chainAuditLogRepository{}
+	service := NewAuditService(repo)
+	for i := 0; i < count; i++ {
+		require.NoError(t, service.Log(context.Background(), &domain.AuditLog{
+			OrganizationID: orgID,
+			UserID:         uuid.New(),
+			Action:         domain.AuditActionUpdate,
+			ResourceType:   "agent",
+			ResourceID:     uuid.New(),
+			IPAddress:      "10.0.0.1",
+			Metadata:       map[string]interface{}{"attempt": i, "changes": map[string]interface{}{"status": "verified"}},
+		}))
+	}
+	return service, repo
+}
+
+func TestAuditService_VerifyChain_Intact(t *testing.T) {
+	orgID := uuid.New()
+	// More entries than one page, so verification has to follow the chain across pages
+	service, _ := newChainedAuditService(t, orgID, auditChainPageSize+5)
+
+	result, err := service.VerifyChain(context.Background(), orgID)
+	require.NoError(t, err)
+	assert.True(t, result.Valid, result.Reason)
+	assert.Equal(t, auditChainPageSize+5, result.EntriesChecked)
+	assert.Equal(t, int64(1), result.FirstSequence)
+	assert.Equal(t, int64(auditChainPageSize+5), result.LastSequence)
+	assert.Equal(t, int64(auditChainPageSize+5), result.HeadSequence)
+	assert.NotEmpty(t, result.HeadHash)
+	assert.Nil(t, result.BrokenEntryID)
+}
+
+func TestAuditService_VerifyChain_DetectsTruncatedTail(t *testing.T) {
+	tests := []struct {
+		name string
+		keep int
+	}{
+		{"newest entries deleted", 3},
+		{"every entry deleted", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			orgID := uuid.New()
+			service, repo := newChainedAuditService(t, orgID, 5)
+			// Every remaining link is intact; only the head shows entries are missing
+			repo.entries = repo.entries[:tt.keep]
+
+			result, err := service.VerifyChain(context.Background(), orgID)
+			require.NoError(t, err)
+			assert.False(t, result.Valid)
+			assert.Equal(t, int64(5), result.BrokenSequence)
+			assert.Contains(t, result.Reason, "deleted from its end")
+		})
+	}
+}
+
+func TestAuditService_VerifyChain_IgnoresEntriesPastHead(t *testing.T) {
+	orgID := uuid.New()
+	service, repo := newChainedAuditService(t, orgID, 5)
+	// An entry logged after verification read the head
+	head := *repo.head
+	require.NoError(t, service.Log(context.Background(), &domain.AuditLog{OrganizationID: orgID, Action: domain.AuditActionView, ResourceType: "agent"}))
+	repo.head = &head
+
+	result, err := service.VerifyChain(context.Background(), orgID)
+	require.NoError(t, err)
+	assert.True(t, result.Valid, result.Reason)
+	assert.Equal(t, 5, result.EntriesChecked)
+}
+
+func TestAuditService_VerifyChain_DetectsTampering(t *testing.T) {
+	tests := []struct {
+		name         string
+		tamper       func(entries []*domain.AuditLog) []*domain.AuditLog
+		wantSequence int64
+	}{
+		{
+			name: "modified metadata",
+			tamper: func(entries []*domain.AuditLog) []*domain.AuditLog {
+				entries[2].Metadata["changes"] = map[string]interface{}{"status": "revoked"}
+				return entries
+			},
+			wantSequence: 3,
+		},
+		{
+			name: "modified action with rehashed entry",
+			tamper: func(entries []*domain.AuditLog) []*domain.AuditLog {
+				// Recomputing the altered entry's own hash still breaks the next entry's link
+				entries[2].Action = domain.AuditActionView
+				entries[2].EntryHash, _ = entries[2].ComputeHash()
+				return entries
+			},
+			wantSequence: 4,
+		},
+		{
+			name: "deleted entry",
+			tamper: func(entries []*domain.AuditLog) []*domain.AuditLog {
+				return append(entries[:2], entries[3:]...)
+			},
+			wantSequence: 4,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			orgID := uuid.New()
+			service, repo := newChainedAuditService(t, orgID, 5)
+			repo.entries = tt.tamper(repo.entries)
+
+			result, err := service.VerifyChain(context.Background(), orgID)
+			require.NoError(t, err)
+			assert.False(t, result.Valid)
+			assert.Equal(t, tt.wantSequence, result.BrokenSequence)
+			require.NotNil(t, result.BrokenEntryID)
+			assert.NotEmpty(t, result.Reason)
+		})
+	}
+}
+
+func TestAuditService_VerifyChain_AcceptsPurgedPrefix(t *testing.T) {
+	orgID := uuid.New()
+	service, repo := newChainedAuditService(t, orgID, 5)
+	// Retention deleted the two oldest entries
+	repo.entries = repo.entries[2:]
+
+	result, err := service.VerifyChain(context.Background(), orgID)
+	require.NoError(t, err)
+	assert.True(t, result.Valid, result.Reason)
+	assert.Equal(t, 3, result.EntriesChecked)
+	assert.Equal(t, int64(3), result.FirstSequence)
+}
+
+// filteringAuditLogRepository applies search filters to an in-memory set of logs, newest first.
+// onSearch, if set, runs after every search, e.g. to log new entries mid-export.
+type filteringAuditLogRepository struct {
+	domain.AuditLogRepository
+	logs     []*domain.AuditLog
+	onSearch func()
+}
+
+func (r *filteringAuditLogRepository) SearchAfter(orgID uuid.UUID, filter domain.AuditLogFilter, after *domain.AuditLogCursor, limit int) ([]*domain.AuditLog, error) {
+	defer func() {
+		if r.onSearch != nil {
+			r.onSearch()
+		}
+	}()
+
+	var page []*domain.AuditLog
+	for _, log := range r.logs {
+		switch {
+		case log.OrganizationID != orgID,
+			filter.ResourceType != "" && log.ResourceType != filter.ResourceType,
+			filter.ResourceID != nil && log.ResourceID != *filter.ResourceID,
+			filter.StartDate != nil && log.Timestamp.Before(*filter.StartDate),
+			filter.EndDate != nil && !log.Timestamp.Before(*filter.EndDate),
+			after != nil && !log.Timestamp.Before(after.Timestamp) &&
+				!(log.Timestamp.Equal(after.Timestamp) && log.ID.String() < after.ID.String()):
+			continue
+		}
+		if len(page) == limit {
+			break
+		}
+		page = append(page, log)
+	}
+	return page, nil
+}
+
+func TestAuditService_ExportAgentLogs_OnlyTargetAgent(t *testing.T) {
+	orgID := uuid.New()
+	agentID, otherAgentID := uuid.New(), uuid.New()
+	now := time.Now().UTC().Truncate(time.Second)
+
+	repo := &filteringAuditLogRepository{logs: []*domain.AuditLog{
+		{ID: uuid.New(), OrganizationID: orgID, Action: domain.AuditActionUpdate, ResourceType: "agent", ResourceID: agentID, Timestamp: now.Add(-1 * time.Hour)},
+		{ID: uuid.New(), OrganizationID: orgID, Action: domain.AuditActionUpdate, ResourceType: "agent", ResourceID: otherAgentID, Timestamp: now.Add(-2 * time.Hour)},
+		{ID: uuid.New(), OrganizationID: orgID, Action: domain.AuditActionCreate, ResourceType: "api_key", ResourceID: agentID, Timestamp: now.Add(-3 * time.Hour)},
+		{ID: uuid.New(), OrganizationID: uuid.New(), Action: domain.AuditActionView, ResourceType: "agent", ResourceID: agentID, Timestamp: now.Add(-4 * time.Hour)},
+		{ID: uuid.New(), OrganizationID: orgID, Action: domain.AuditActionCreate, ResourceType: "agent", ResourceID: agentID, Timestamp: now.Add(-5 * time.Hour)},
+	}}
+	service := NewAuditService(repo)
+
+	var buf bytes.Buffer
+	count, err := service.ExportAgentLogs(context.Background(), &buf, orgID, agentID, nil, nil, "csv")
+	require.NoError(t, err)
+	assert.Equal(t, 2, count)
+
+	records, err := csv.NewReader(&buf).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, records, 3)
+	assert.Equal(t, auditExportCSVHeader, records[0])
+	assert.Equal(t, repo.logs[0].ID.String(), records[1][0])
+	assert.Equal(t, repo.logs[4].ID.String(), records[2][0])
+	for _, row := range records[1:] {
+		assert.Equal(t, "agent", row[4])
+		assert.Equal(t, agentID.String(), row[5])
+	}
+}
+
+func TestAuditService_ExportAgentLogs_NewEntriesDoNotShiftPages(t *testing.T) {
+	orgID, agentID := uuid.New(), uuid.New()
+	now := time.Now().UTC().Truncate(time.Second)
+
+	newLog := func(timestamp time.Time) *domain.AuditLog {
+		return &domain.AuditLog{
+			ID: uuid.New(), OrganizationID: orgID, Action: domain.AuditActionView, ResourceType: "agent",
+			ResourceID: agentID, Timestamp: timestamp,
+		}
+	}
+	repo := &filteringAuditLogRepository{}
+	for i := 0; i < auditExportPageSize+1; i++ {
+		repo.logs = append(repo.logs, newLog(now.Add(-time.Duration(i)*time.Minute)))
+	}
+	// The agent keeps working while its trail is exported
+	repo.onSearch = func() {
+		repo.logs = append([]*domain.AuditLog{newLog(time.Now().UTC().Add(time.Hour))}, repo.logs...)
+	}
+	service := NewAuditService(repo)
+
+	var buf bytes.Buffer
+	count, err := service.ExportAgentLogs(context.Background(), &buf, orgID, agentID, nil, nil, "json")
+	require.NoError(t, err)
+	assert.Equal(t, auditExportPageSize+1, count)
+
+	var exported []*domain.AuditLog
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &exported))
+	seen := make(map[uuid.UUID]bool, len(exported))
+	for _, log := range exported {
+		assert.False(t, seen[log.ID], "log %s exported twice", log.ID)
+		seen[log.ID] = true
+	}
+}
+
+func TestAuditService_ExportAgentLogs_DateRange(t *testing.T) {
+	orgID, agentID := uuid.New(), uuid.New()
+	now := time.Now().UTC().Truncate(time.Second)
+
+	repo := &filteringAuditLogRepository{}
+	for i := 0; i < 5; i++ {
+		repo.logs = append(repo.logs, &domain.AuditLog{
+			ID: uuid.New(), OrganizationID: orgID, Action: domain.AuditActionView, ResourceType: "agent",
+			ResourceID: agentID, Timestamp: now.Add(-time.Duration(i) * 24 * time.Hour),
+		})
+	}
+	service := NewAuditService(repo)
+
+	start, end := now.Add(-3*24*time.Hour), now.Add(-24*time.Hour)
+	var buf bytes.Buffer
+	count, err := service.ExportAgentLogs(context.Background(), &buf, orgID, agentID, &start, &end, "json")
+	require.NoError(t, err)
+	assert.Equal(t, 2, count)
+
+	var exported []*domain.AuditLog
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &exported))
+	require.Len(t, exported, 2)
+	// The end of the range is exclusive
+	assert.Equal(t, repo.logs[2].ID, exported[0].ID)
+	assert.Equal(t, repo.logs[3].ID, exported[1].ID)
+}
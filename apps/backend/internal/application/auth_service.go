@@ -140,27 +140,51 @@ func (s *AuthService) DeactivateUser(
 	orgID uuid.UUID,
 	adminID uuid.UUID,
 ) error {
-	user, err := s.userRepo.GetByID(userID)
+	user, err := s.deactivationTarget(userID, orgID, adminID)
 	if err != nil {
 		return err
 	}
 
+	// Update status to deactivated (soft delete) and set deleted_at timestamp
+	now := time.Now()
+	user.Status = domain.UserStatusDeactivated
+	user.DeletedAt = &now
+	user.UpdatedAt = now
+	return s.userRepo.Update(user)
+}
+
+// DeactivateUserWithCascade deactivates a user account and, in the same transaction, suspends the
+// agents the user created and disables their API keys. It returns the affected resources.
+func (s *AuthService) DeactivateUserWithCascade(
+	ctx context.Context,
+	userID uuid.UUID,
+	orgID uuid.UUID,
+	adminID uuid.UUID,
+) (*domain.UserDeactivationCascade, error) {
+	if _, err := s.deactivationTarget(userID, orgID, adminID); err != nil {
+		return nil, err
+	}
+	return s.userRepo.DeactivateWithCascade(userID, time.Now())
+}
+
+// deactivationTarget loads the user an admin wants to deactivate and checks they may do so
+func (s *AuthService) deactivationTarget(userID, orgID, adminID uuid.UUID) (*domain.User, error) {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return nil, err
+	}
+
 	// Verify user belongs to organization
 	if user.OrganizationID != orgID {
-		return fmt.Errorf("user not found in organization")
+		return nil, fmt.Errorf("user not found in organization")
 	}
 
 	// Prevent self-deactivation
 	if userID == adminID {
-		return fmt.Errorf("cannot deactivate your own account")
+		return nil, fmt.Errorf("cannot deactivate your own account")
 	}
 
-	// Update status to deactivated (soft delete) and set deleted_at timestamp
-	now := time.Now()
-	user.Status = domain.UserStatusDeactivated
-	user.DeletedAt = &now
-	user.UpdatedAt = now
-	return s.userRepo.Update(user)
+	return user, nil
 }
 
 // ChangePassword changes a user's password
@@ -185,6 +209,11 @@ func (s *AuthService) ChangePassword(
 		return fmt.Errorf("current password is incorrect")
 	}
 
+	// Validate against the organization's password policy
+	passwordHasher, err = passwordHasherForOrganization(s.orgRepo, user.OrganizationID)
+	if err != nil {
+		return err
+	}
 	if err := passwordHasher.ValidatePassword(newPassword); err != nil {
 		return err
 	}
@@ -207,6 +236,15 @@ func (s *AuthService) ChangePassword(
 	return nil
 }
 
+// passwordHasherForOrganization returns a password hasher enforcing the organization's password policy
+func passwordHasherForOrganization(orgRepo domain.OrganizationRepository, orgID uuid.UUID) (*auth.PasswordHasher, error) {
+	org, err := orgRepo.GetByID(orgID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load password policy: %w", err)
+	}
+	return auth.NewPasswordHasherWithPolicy(org.EffectivePasswordPolicy()), nil
+}
+
 // ValidateAPIKeyResponse contains API key validation result
 type ValidateAPIKeyResponse struct {
 	User         *domain.User
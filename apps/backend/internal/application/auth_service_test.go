@@ -74,6 +74,11 @@ func (m *MockUserRepository) Update(user *domain.User) error {
 	return args.Error(0)
 }
 
+func (m *MockUserRepository) UpdateWithinQuota(user *domain.User) error {
+	args := m.Called(user)
+	return args.Error(0)
+}
+
 func (m *MockUserRepository) UpdateRole(id uuid.UUID, role domain.UserRole) error {
 	args := m.Called(id, role)
 	return args.Error(0)
@@ -89,6 +94,14 @@ func (m *MockUserRepository) CountActiveUsers(orgID uuid.UUID, withinMinutes int
 	return args.Int(0), args.Error(1)
 }
 
+func (m *MockUserRepository) DeactivateWithCascade(userID uuid.UUID, at time.Time) (*domain.UserDeactivationCascade, error) {
+	args := m.Called(userID, at)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.UserDeactivationCascade), args.Error(1)
+}
+
 // MockOrganizationRepository for testing
 type MockOrganizationRepository struct {
 	mock.Mock
@@ -115,6 +128,14 @@ func (m *MockOrganizationRepository) GetByDomain(domainName string) (*domain.Org
 	return args.Get(0).(*domain.Organization), args.Error(1)
 }
 
+func (m *MockOrganizationRepository) ListActive() ([]*domain.Organization, error) {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.Organization), args.Error(1)
+}
+
 func (m *MockOrganizationRepository) Update(org *domain.Organization) error {
 	args := m.Called(org)
 	return args.Error(0)
@@ -172,6 +193,14 @@ func (m *MockAPIKeyRepository) UpdateLastUsed(id uuid.UUID) error {
 	return args.Error(0)
 }
 
+func (m *MockAPIKeyRepository) GetExpiredActive(now time.Time) ([]*domain.APIKey, error) {
+	args := m.Called(now)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.APIKey), args.Error(1)
+}
+
 func (m *MockAPIKeyRepository) Revoke(id uuid.UUID) error {
 	args := m.Called(id)
 	return args.Error(0)
@@ -769,6 +798,50 @@ func TestAuthService_DeactivateUser_Success(t *testing.T) {
 	assert.NoError(t, err)
 
 	mockUserRepo.AssertExpectations(t)
+	mockUserRepo.AssertNotCalled(t, "DeactivateWithCascade", mock.Anything, mock.Anything)
+}
+
+func TestAuthService_DeactivateUserWithCascade_Success(t *testing.T) {
+	// Arrange
+	mockUserRepo := new(MockUserRepository)
+	service := NewAuthService(mockUserRepo, new(MockOrganizationRepository), new(MockAPIKeyRepository), nil, new(MockEmailService))
+
+	user := createTestUser("test@example.com")
+	adminID := uuid.New()
+	affected := &domain.UserDeactivationCascade{
+		SuspendedAgentIDs: []uuid.UUID{uuid.New(), uuid.New()},
+		DisabledAPIKeyIDs: []uuid.UUID{uuid.New()},
+	}
+
+	mockUserRepo.On("GetByID", user.ID).Return(user, nil)
+	mockUserRepo.On("DeactivateWithCascade", user.ID, mock.AnythingOfType("time.Time")).Return(affected, nil)
+
+	// Act
+	result, err := service.DeactivateUserWithCascade(context.Background(), user.ID, user.OrganizationID, adminID)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, affected, result)
+
+	mockUserRepo.AssertExpectations(t)
+	mockUserRepo.AssertNotCalled(t, "Update", mock.Anything)
+}
+
+func TestAuthService_DeactivateUserWithCascade_WrongOrganization(t *testing.T) {
+	// Arrange
+	mockUserRepo := new(MockUserRepository)
+	service := NewAuthService(mockUserRepo, new(MockOrganizationRepository), new(MockAPIKeyRepository), nil, new(MockEmailService))
+
+	user := createTestUser("test@example.com")
+
+	mockUserRepo.On("GetByID", user.ID).Return(user, nil)
+
+	// Act
+	_, err := service.DeactivateUserWithCascade(context.Background(), user.ID, uuid.New(), uuid.New())
+
+	// Assert
+	assert.ErrorContains(t, err, "user not found in organization")
+	mockUserRepo.AssertNotCalled(t, "DeactivateWithCascade", mock.Anything, mock.Anything)
 }
 
 func TestAuthService_DeactivateUser_UserNotFound(t *testing.T) {
@@ -888,6 +961,7 @@ func TestAuthService_ChangePassword_Success(t *testing.T) {
 	user.ForcePasswordChange = true
 
 	mockUserRepo.On("GetByID", user.ID).Return(user, nil)
+	mockOrgRepo.On("GetByID", user.OrganizationID).Return(&domain.Organization{ID: user.OrganizationID}, nil)
 	mockUserRepo.On("Update", mock.AnythingOfType("*domain.User")).Return(nil)
 
 	// Act
@@ -984,6 +1058,7 @@ func TestAuthService_ChangePassword_WeakNewPassword(t *testing.T) {
 	user := createTestUser("test@example.com")
 
 	mockUserRepo.On("GetByID", user.ID).Return(user, nil)
+	mockOrgRepo.On("GetByID", user.OrganizationID).Return(&domain.Organization{ID: user.OrganizationID}, nil)
 
 	// Act
 	ctx := context.Background()
@@ -1008,6 +1083,7 @@ func TestAuthService_ChangePassword_UpdateFails(t *testing.T) {
 	user := createTestUser("test@example.com")
 
 	mockUserRepo.On("GetByID", user.ID).Return(user, nil)
+	mockOrgRepo.On("GetByID", user.OrganizationID).Return(&domain.Organization{ID: user.OrganizationID}, nil)
 	mockUserRepo.On("Update", mock.AnythingOfType("*domain.User")).Return(errors.New("database error"))
 
 	// Act
@@ -1021,6 +1097,32 @@ func TestAuthService_ChangePassword_UpdateFails(t *testing.T) {
 	mockUserRepo.AssertExpectations(t)
 }
 
+func TestAuthService_ChangePassword_UsesOrganizationPasswordPolicy(t *testing.T) {
+	// Arrange
+	mockUserRepo := new(MockUserRepository)
+	mockOrgRepo := new(MockOrganizationRepository)
+	mockAPIKeyRepo := new(MockAPIKeyRepository)
+	mockEmailService := new(MockEmailService)
+
+	service := NewAuthService(mockUserRepo, mockOrgRepo, mockAPIKeyRepo, nil, mockEmailService)
+
+	user := createTestUser("test@example.com")
+	policy := domain.DefaultPasswordPolicy()
+	policy.MinLength = 20
+
+	mockUserRepo.On("GetByID", user.ID).Return(user, nil)
+	mockOrgRepo.On("GetByID", user.OrganizationID).Return(&domain.Organization{ID: user.OrganizationID, PasswordPolicy: &policy}, nil)
+
+	// Act
+	ctx := context.Background()
+	err := service.ChangePassword(ctx, user.ID, "SecurePass123!", "NewSecurePass123!")
+
+	// Assert
+	assert.ErrorIs(t, err, auth.ErrPasswordTooShort)
+	mockUserRepo.AssertNotCalled(t, "Update", mock.Anything)
+	mockOrgRepo.AssertExpectations(t)
+}
+
 // ====================
 // ValidateAPIKey Tests
 // ====================
@@ -0,0 +1,68 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrBackgroundTasksTimeout is returned by Shutdown when tasks are still running after the timeout
+var ErrBackgroundTasksTimeout = errors.New("timed out waiting for background tasks")
+
+// BackgroundTasks tracks goroutines that must finish before the server exits.
+// Shutdown cancels the context handed to every task, so long-running workers stop
+// polling, and then waits for in-flight tasks to return.
+type BackgroundTasks struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	mu       sync.Mutex
+	stopping bool
+	wg       sync.WaitGroup
+}
+
+// NewBackgroundTasks creates a new background task group
+func NewBackgroundTasks() *BackgroundTasks {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &BackgroundTasks{ctx: ctx, cancel: cancel}
+}
+
+// Go runs task in a new goroutine that Shutdown waits for. The task's context is cancelled
+// when shutdown starts; one-off work that should still complete can detach from it with
+// context.WithoutCancel. Go reports false, and does not run task, once shutdown has started.
+func (b *BackgroundTasks) Go(task func(ctx context.Context)) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.stopping {
+		return false
+	}
+
+	b.wg.Add(1)
+	go func() {
+		defer b.wg.Done()
+		task(b.ctx)
+	}()
+	return true
+}
+
+// Shutdown cancels the tasks' context and waits up to timeout for every task to return
+func (b *BackgroundTasks) Shutdown(timeout time.Duration) error {
+	b.mu.Lock()
+	b.stopping = true
+	b.mu.Unlock()
+	b.cancel()
+
+	done := make(chan struct{})
+	go func() {
+		b.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-time.After(timeout):
+		return ErrBackgroundTasksTimeout
+	}
+}
@@ -0,0 +1,74 @@
+package application
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBackgroundTasks_ShutdownWaitsForInFlightTask(t *testing.T) {
+	tasks := NewBackgroundTasks()
+	started := make(chan struct{})
+	var completed atomic.Bool
+
+	require.True(t, tasks.Go(func(ctx context.Context) {
+		close(started)
+		// Still running when shutdown begins, and not abandoned because of it
+		<-ctx.Done()
+		time.Sleep(50 * time.Millisecond)
+		completed.Store(true)
+	}))
+	<-started
+
+	require.NoError(t, tasks.Shutdown(time.Second))
+	assert.True(t, completed.Load(), "shutdown returned before the task finished")
+}
+
+func TestBackgroundTasks_ShutdownStopsWorkers(t *testing.T) {
+	tasks := NewBackgroundTasks()
+	var ticks atomic.Int32
+
+	tasks.Go(func(ctx context.Context) {
+		ticker := time.NewTicker(time.Millisecond)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				ticks.Add(1)
+			}
+		}
+	})
+
+	require.NoError(t, tasks.Shutdown(time.Second))
+	stopped := ticks.Load()
+	time.Sleep(10 * time.Millisecond)
+	assert.Equal(t, stopped, ticks.Load(), "worker kept running after shutdown")
+}
+
+func TestBackgroundTasks_ShutdownTimesOut(t *testing.T) {
+	tasks := NewBackgroundTasks()
+	release := make(chan struct{})
+	defer close(release)
+
+	tasks.Go(func(ctx context.Context) {
+		<-release
+	})
+
+	assert.ErrorIs(t, tasks.Shutdown(20*time.Millisecond), ErrBackgroundTasksTimeout)
+}
+
+func TestBackgroundTasks_RejectsTasksAfterShutdown(t *testing.T) {
+	tasks := NewBackgroundTasks()
+	require.NoError(t, tasks.Shutdown(time.Second))
+
+	var ran atomic.Bool
+	assert.False(t, tasks.Go(func(ctx context.Context) { ran.Store(true) }))
+	time.Sleep(10 * time.Millisecond)
+	assert.False(t, ran.Load())
+}
@@ -2,29 +2,77 @@ package application
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"os"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/opena2a/identity/backend/internal/domain"
 )
 
+// Errors returned when a capability request cannot be approved or rejected
+var (
+	ErrCapabilityRequestExpired    = errors.New("capability request has expired")
+	ErrCapabilityRequestNotPending = errors.New("capability request is not pending")
+	ErrInvalidCapabilityGrant      = errors.New("invalid capability grant")
+)
+
 type CapabilityRequestService struct {
 	requestRepo    domain.CapabilityRequestRepository
 	capabilityRepo domain.CapabilityRepository
 	agentRepo      domain.AgentRepository
+	userRepo       domain.UserRepository
+	orgRepo        domain.OrganizationRepository
+	emailService   domain.EmailService
+	requestTTL     time.Duration
 }
 
 func NewCapabilityRequestService(
 	requestRepo domain.CapabilityRequestRepository,
 	capabilityRepo domain.CapabilityRepository,
 	agentRepo domain.AgentRepository,
+	userRepo domain.UserRepository,
+	orgRepo domain.OrganizationRepository,
+	emailService domain.EmailService,
+	requestTTL time.Duration,
 ) *CapabilityRequestService {
 	return &CapabilityRequestService{
 		requestRepo:    requestRepo,
 		capabilityRepo: capabilityRepo,
 		agentRepo:      agentRepo,
+		userRepo:       userRepo,
+		orgRepo:        orgRepo,
+		emailService:   emailService,
+		requestTTL:     requestTTL,
+	}
+}
+
+// Capability request expiry defaults; override with CAPABILITY_REQUEST_SWEEP_INTERVAL (Go duration)
+// and CAPABILITY_REQUEST_TTL_DAYS
+const (
+	DefaultCapabilityRequestSweepInterval = time.Hour
+	DefaultCapabilityRequestTTLDays       = 7
+)
+
+// CapabilityRequestAdminReminderAfter is how long a request may stay pending before admins are reminded
+const CapabilityRequestAdminReminderAfter = 48 * time.Hour
+
+// CapabilityRequestExpirySettingsFromEnv returns the expiry sweep interval and request lifetime
+func CapabilityRequestExpirySettingsFromEnv() (interval, ttl time.Duration) {
+	interval = DefaultCapabilityRequestSweepInterval
+	if value, err := time.ParseDuration(os.Getenv("CAPABILITY_REQUEST_SWEEP_INTERVAL")); err == nil && value > 0 {
+		interval = value
+	}
+
+	ttlDays := DefaultCapabilityRequestTTLDays
+	if value, err := strconv.Atoi(os.Getenv("CAPABILITY_REQUEST_TTL_DAYS")); err == nil && value > 0 {
+		ttlDays = value
 	}
+
+	return interval, time.Duration(ttlDays) * 24 * time.Hour
 }
 
 // CreateRequest creates a new capability request
@@ -35,6 +83,11 @@ func (s *CapabilityRequestService) CreateRequest(ctx context.Context, input *dom
 		return nil, fmt.Errorf("agent not found: %w", err)
 	}
 
+	// A request could never be approved for a type the organization does not allow
+	if err := ensureInCapabilityCatalog(s.capabilityRepo, agent.OrganizationID, []string{input.CapabilityType}); err != nil {
+		return nil, err
+	}
+
 	// Check if capability already granted
 	capabilities, err := s.capabilityRepo.GetCapabilitiesByAgentID(input.AgentID)
 	if err != nil {
@@ -67,6 +120,7 @@ func (s *CapabilityRequestService) CreateRequest(ctx context.Context, input *dom
 		CapabilityType: input.CapabilityType,
 		Reason:         input.Reason,
 		RequestedBy:    input.RequestedBy,
+		ExpiresAt:      time.Now().Add(s.ttl()),
 	}
 
 	if err := s.requestRepo.Create(request); err != nil {
@@ -79,6 +133,67 @@ func (s *CapabilityRequestService) CreateRequest(ctx context.Context, input *dom
 	return request, nil
 }
 
+// declaredCapabilityReason is the reason recorded on requests filed for capabilities declared at registration
+const declaredCapabilityReason = "Declared at agent registration; organization requires capability approval"
+
+// addedCapabilityReason is the reason recorded on requests filed for capabilities added by an agent update
+const addedCapabilityReason = "Added by agent update; organization requires capability approval"
+
+// RequestDeclaredCapabilities files a pending request for each capability a newly registered agent
+// declared, for organizations that require approval instead of auto-granting them. The agent was
+// just created and its capabilities already checked against the catalog, so no duplicate or
+// catalog checks are repeated. It returns how many requests were created.
+func (s *CapabilityRequestService) RequestDeclaredCapabilities(ctx context.Context, agent *domain.Agent, capabilityTypes []string, requestedBy uuid.UUID) (int, error) {
+	return s.fileCapabilityRequests(agent, capabilityTypes, requestedBy, declaredCapabilityReason, nil)
+}
+
+// RequestAddedCapabilities files a pending request for each capability an agent update adds, for
+// organizations that require approval. Capabilities that already have a pending request are
+// skipped. The caller has checked the types against the catalog and the agent's current grants.
+func (s *CapabilityRequestService) RequestAddedCapabilities(ctx context.Context, agent *domain.Agent, capabilityTypes []string, requestedBy uuid.UUID) (int, error) {
+	existing, err := s.requestRepo.List(domain.CapabilityRequestFilter{AgentID: &agent.ID})
+	if err != nil {
+		return 0, fmt.Errorf("failed to check existing requests: %w", err)
+	}
+
+	pending := make(map[string]bool, len(existing))
+	for _, req := range existing {
+		if req.Status == domain.CapabilityRequestStatusPending {
+			pending[req.CapabilityType] = true
+		}
+	}
+
+	return s.fileCapabilityRequests(agent, capabilityTypes, requestedBy, addedCapabilityReason, pending)
+}
+
+// fileCapabilityRequests creates one pending request per distinct capability type not in skip
+func (s *CapabilityRequestService) fileCapabilityRequests(agent *domain.Agent, capabilityTypes []string, requestedBy uuid.UUID, reason string, skip map[string]bool) (int, error) {
+	seen := make(map[string]bool, len(capabilityTypes))
+	var errs []error
+	created := 0
+	for _, capabilityType := range capabilityTypes {
+		if seen[capabilityType] || skip[capabilityType] {
+			continue
+		}
+		seen[capabilityType] = true
+
+		request := &domain.CapabilityRequest{
+			AgentID:        agent.ID,
+			CapabilityType: capabilityType,
+			Reason:         reason,
+			RequestedBy:    requestedBy,
+			ExpiresAt:      time.Now().Add(s.ttl()),
+		}
+		if err := s.requestRepo.Create(request); err != nil {
+			errs = append(errs, fmt.Errorf("capability '%s': %w", capabilityType, err))
+			continue
+		}
+		created++
+	}
+
+	return created, errors.Join(errs...)
+}
+
 // ListRequests lists capability requests with optional filtering
 func (s *CapabilityRequestService) ListRequests(ctx context.Context, filter domain.CapabilityRequestFilter) ([]*domain.CapabilityRequestWithDetails, error) {
 	requests, err := s.requestRepo.List(filter)
@@ -99,8 +214,14 @@ func (s *CapabilityRequestService) GetRequest(ctx context.Context, id uuid.UUID)
 	return request, nil
 }
 
-// ApproveRequest approves a capability request and grants the capability
-func (s *CapabilityRequestService) ApproveRequest(ctx context.Context, id uuid.UUID, reviewerID uuid.UUID) error {
+// ApproveRequest approves a capability request and grants the capability. The grant can be
+// narrowed to a resource scope and given an expiry through opts.
+func (s *CapabilityRequestService) ApproveRequest(ctx context.Context, id uuid.UUID, reviewerID uuid.UUID, opts domain.CapabilityGrantOptions) error {
+	grantedScope := strings.TrimSpace(opts.GrantedScope)
+	if opts.ExpiresAt != nil && !opts.ExpiresAt.After(time.Now()) {
+		return fmt.Errorf("%w: expires_at must be in the future", ErrInvalidCapabilityGrant)
+	}
+
 	// Get the request details
 	request, err := s.requestRepo.GetByID(id)
 	if err != nil {
@@ -109,7 +230,11 @@ func (s *CapabilityRequestService) ApproveRequest(ctx context.Context, id uuid.U
 
 	// Verify status is pending
 	if request.Status != domain.CapabilityRequestStatusPending {
-		return fmt.Errorf("capability request is not pending (current status: %s)", request.Status)
+		return fmt.Errorf("%w (current status: %s)", ErrCapabilityRequestNotPending, request.Status)
+	}
+
+	if !request.ExpiresAt.IsZero() && time.Now().After(request.ExpiresAt) {
+		return fmt.Errorf("%w at %s", ErrCapabilityRequestExpired, request.ExpiresAt.Format(time.RFC3339))
 	}
 
 	// Update request status to approved
@@ -123,6 +248,12 @@ func (s *CapabilityRequestService) ApproveRequest(ctx context.Context, id uuid.U
 		CapabilityType: request.CapabilityType,
 		GrantedBy:      &reviewerID,
 		GrantedAt:      time.Now(),
+		ExpiresAt:      opts.ExpiresAt,
+	}
+	if grantedScope != "" {
+		capability.CapabilityScope = map[string]interface{}{
+			domain.CapabilityScopeGrantedScope: grantedScope,
+		}
 	}
 
 	if err := s.capabilityRepo.CreateCapability(capability); err != nil {
@@ -147,7 +278,7 @@ func (s *CapabilityRequestService) RejectRequest(ctx context.Context, id uuid.UU
 
 	// Verify status is pending
 	if request.Status != domain.CapabilityRequestStatusPending {
-		return fmt.Errorf("capability request is not pending (current status: %s)", request.Status)
+		return fmt.Errorf("%w (current status: %s)", ErrCapabilityRequestNotPending, request.Status)
 	}
 
 	// Update request status to rejected
@@ -160,3 +291,187 @@ func (s *CapabilityRequestService) RejectRequest(ctx context.Context, id uuid.UU
 
 	return nil
 }
+
+// ttl returns the configured request lifetime, falling back to the default
+func (s *CapabilityRequestService) ttl() time.Duration {
+	if s.requestTTL > 0 {
+		return s.requestTTL
+	}
+	return domain.DefaultCapabilityRequestTTL
+}
+
+// StartExpirySweeper runs SweepExpiredRequests and SendPendingReminders every interval until ctx is cancelled
+func (s *CapabilityRequestService) StartExpirySweeper(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			now := time.Now()
+			if _, err := s.SweepExpiredRequests(ctx, now); err != nil {
+				fmt.Printf("⚠️  Warning: capability request expiry sweep: %v\n", err)
+			}
+			if _, err := s.SendPendingReminders(ctx, now); err != nil {
+				fmt.Printf("⚠️  Warning: capability request reminders: %v\n", err)
+			}
+		}
+	}
+}
+
+// SweepExpiredRequests rejects every pending request whose expiry has passed and
+// emails the requester. Returns the number of requests expired.
+func (s *CapabilityRequestService) SweepExpiredRequests(ctx context.Context, now time.Time) (int, error) {
+	status := domain.CapabilityRequestStatusPending
+	requests, err := s.requestRepo.List(domain.CapabilityRequestFilter{
+		Status:        &status,
+		ExpiresBefore: &now,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to list expired capability requests: %w", err)
+	}
+
+	orgs := make(map[uuid.UUID]*domain.Organization)
+	expired := 0
+	for _, request := range requests {
+		if err := s.requestRepo.Expire(request.ID, now); err != nil {
+			fmt.Printf("⚠️  Warning: failed to expire capability request %s: %v\n", request.ID, err)
+			continue
+		}
+		expired++
+
+		fmt.Printf("⌛ Capability request expired: agent=%s, capability=%s\n", request.AgentName, request.CapabilityType)
+
+		if s.emailService == nil || request.RequestedByEmail == "" {
+			continue
+		}
+		templateData := capabilityRequestEmailData(request, request.RequestedByEmail, now)
+		s.emailOrganization(orgs, request.OrganizationID).BrandEmail(&templateData)
+		if err := s.emailService.SendTemplatedEmail(domain.TemplateCapabilityRequestExpired, request.RequestedByEmail, templateData); err != nil {
+			fmt.Printf("⚠️  Failed to send capability request expiry email to %s: %v\n", request.RequestedByEmail, err)
+		}
+	}
+
+	return expired, nil
+}
+
+// SendPendingReminders emails the organization's admins about every request that has been
+// pending for longer than CapabilityRequestAdminReminderAfter. Each request is reminded about
+// once. Returns the number of requests reminded about.
+func (s *CapabilityRequestService) SendPendingReminders(ctx context.Context, now time.Time) (int, error) {
+	if s.emailService == nil || s.userRepo == nil {
+		return 0, nil
+	}
+
+	status := domain.CapabilityRequestStatusPending
+	cutoff := now.Add(-CapabilityRequestAdminReminderAfter)
+	requests, err := s.requestRepo.List(domain.CapabilityRequestFilter{
+		Status:           &status,
+		RequestedBefore:  &cutoff,
+		NotAdminReminded: true,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to list pending capability requests: %w", err)
+	}
+
+	adminsByOrg := make(map[uuid.UUID][]*domain.User)
+	orgs := make(map[uuid.UUID]*domain.Organization)
+	reminded := 0
+	for _, request := range requests {
+		admins, ok := adminsByOrg[request.OrganizationID]
+		if !ok {
+			admins, err = s.getActiveAdmins(request.OrganizationID)
+			if err != nil {
+				fmt.Printf("⚠️  Warning: failed to load admins for organization %s: %v\n", request.OrganizationID, err)
+				continue
+			}
+			adminsByOrg[request.OrganizationID] = admins
+		}
+		if len(admins) == 0 {
+			continue
+		}
+
+		for _, admin := range admins {
+			templateData := capabilityRequestEmailData(request, admin.Name, now)
+			s.emailOrganization(orgs, request.OrganizationID).BrandEmail(&templateData)
+			if err := s.emailService.SendTemplatedEmail(domain.TemplateCapabilityRequestPending, admin.Email, templateData); err != nil {
+				fmt.Printf("⚠️  Failed to send capability request reminder to %s: %v\n", admin.Email, err)
+			}
+		}
+
+		if err := s.requestRepo.MarkAdminReminded(request.ID, now); err != nil {
+			fmt.Printf("⚠️  Warning: failed to record reminder for capability request %s: %v\n", request.ID, err)
+			continue
+		}
+		reminded++
+	}
+
+	return reminded, nil
+}
+
+// getActiveAdmins returns the active admin users of an organization
+func (s *CapabilityRequestService) getActiveAdmins(orgID uuid.UUID) ([]*domain.User, error) {
+	users, err := s.userRepo.GetByOrganization(orgID)
+	if err != nil {
+		return nil, err
+	}
+
+	var admins []*domain.User
+	for _, user := range users {
+		if user.Role == domain.RoleAdmin && user.Status == domain.UserStatusActive {
+			admins = append(admins, user)
+		}
+	}
+	return admins, nil
+}
+
+// emailOrganization returns the organization used to brand emails, or nil if it is unknown.
+// Organizations are looked up once per orgs cache.
+func (s *CapabilityRequestService) emailOrganization(orgs map[uuid.UUID]*domain.Organization, orgID uuid.UUID) *domain.Organization {
+	if s.orgRepo == nil {
+		return nil
+	}
+	org, ok := orgs[orgID]
+	if !ok {
+		org, _ = s.orgRepo.GetByID(orgID)
+		orgs[orgID] = org
+	}
+	return org
+}
+
+// capabilityRequestEmailData builds the template data shared by capability request emails
+func capabilityRequestEmailData(request *domain.CapabilityRequestWithDetails, userName string, now time.Time) domain.EmailTemplateData {
+	frontendURL := os.Getenv("FRONTEND_URL")
+	if frontendURL == "" {
+		frontendURL = "http://localhost:3000"
+	}
+
+	supportEmail := os.Getenv("SUPPORT_EMAIL")
+	if supportEmail == "" {
+		supportEmail = "info@opena2a.org"
+	}
+
+	agentName := request.AgentDisplayName
+	if agentName == "" {
+		agentName = request.AgentName
+	}
+
+	return domain.EmailTemplateData{
+		UserName:     userName,
+		DashboardURL: frontendURL,
+		SupportEmail: supportEmail,
+		Timestamp:    now,
+		AgentID:      request.AgentID.String(),
+		AgentName:    agentName,
+		ExpiresAt:    request.ExpiresAt,
+		CustomData: map[string]interface{}{
+			"RequestID":        request.ID.String(),
+			"CapabilityType":   request.CapabilityType,
+			"Reason":           request.Reason,
+			"RequestedByEmail": request.RequestedByEmail,
+			"PendingHours":     int(CapabilityRequestAdminReminderAfter.Hours()),
+		},
+	}
+}
@@ -0,0 +1,222 @@
+package application
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// MockCapabilityRequestRepository for testing
+type MockCapabilityRequestRepository struct {
+	mock.Mock
+}
+
+func (m *MockCapabilityRequestRepository) Create(req *domain.CapabilityRequest) error {
+	args := m.Called(req)
+	return args.Error(0)
+}
+
+func (m *MockCapabilityRequestRepository) GetByID(id uuid.UUID) (*domain.CapabilityRequestWithDetails, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.CapabilityRequestWithDetails), args.Error(1)
+}
+
+func (m *MockCapabilityRequestRepository) List(filter domain.CapabilityRequestFilter) ([]*domain.CapabilityRequestWithDetails, error) {
+	args := m.Called(filter)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.CapabilityRequestWithDetails), args.Error(1)
+}
+
+func (m *MockCapabilityRequestRepository) UpdateStatus(id uuid.UUID, status domain.CapabilityRequestStatus, reviewedBy uuid.UUID) error {
+	args := m.Called(id, status, reviewedBy)
+	return args.Error(0)
+}
+
+func (m *MockCapabilityRequestRepository) Expire(id uuid.UUID, at time.Time) error {
+	args := m.Called(id, at)
+	return args.Error(0)
+}
+
+func (m *MockCapabilityRequestRepository) MarkAdminReminded(id uuid.UUID, at time.Time) error {
+	args := m.Called(id, at)
+	return args.Error(0)
+}
+
+func (m *MockCapabilityRequestRepository) Delete(id uuid.UUID) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func newPendingCapabilityRequest(orgID uuid.UUID, requestedAt time.Time) *domain.CapabilityRequestWithDetails {
+	return &domain.CapabilityRequestWithDetails{
+		CapabilityRequest: domain.CapabilityRequest{
+			ID:             uuid.New(),
+			AgentID:        uuid.New(),
+			CapabilityType: "file:write",
+			Reason:         "Needs to write reports",
+			Status:         domain.CapabilityRequestStatusPending,
+			RequestedBy:    uuid.New(),
+			RequestedAt:    requestedAt,
+			ExpiresAt:      requestedAt.Add(domain.DefaultCapabilityRequestTTL),
+		},
+		OrganizationID:   orgID,
+		AgentName:        "report-agent",
+		RequestedByEmail: "requester@example.com",
+	}
+}
+
+func TestCapabilityRequestService_SweepExpiredRequests(t *testing.T) {
+	// Fake clock: the sweep runs at a fixed instant
+	now := time.Date(2025, 6, 1, 9, 0, 0, 0, time.UTC)
+	request := newPendingCapabilityRequest(uuid.New(), now.Add(-8*24*time.Hour))
+
+	status := domain.CapabilityRequestStatusPending
+	mockRequestRepo := new(MockCapabilityRequestRepository)
+	mockRequestRepo.On("List", domain.CapabilityRequestFilter{Status: &status, ExpiresBefore: &now}).
+		Return([]*domain.CapabilityRequestWithDetails{request}, nil)
+	mockRequestRepo.On("Expire", request.ID, now).Return(nil)
+
+	mockEmail := new(MockEmailService)
+	mockEmail.On("SendTemplatedEmail", domain.TemplateCapabilityRequestExpired, "requester@example.com", mock.MatchedBy(func(data domain.EmailTemplateData) bool {
+		return data.AgentName == "report-agent" && data.CustomData["CapabilityType"] == "file:write"
+	})).Return(nil)
+
+	service := &CapabilityRequestService{requestRepo: mockRequestRepo, emailService: mockEmail}
+
+	expired, err := service.SweepExpiredRequests(context.Background(), now)
+	require.NoError(t, err)
+	assert.Equal(t, 1, expired)
+	mockRequestRepo.AssertExpectations(t)
+	mockEmail.AssertExpectations(t)
+}
+
+func TestCapabilityRequestService_SweepExpiredRequests_NoEmailService(t *testing.T) {
+	now := time.Date(2025, 6, 1, 9, 0, 0, 0, time.UTC)
+	request := newPendingCapabilityRequest(uuid.New(), now.Add(-8*24*time.Hour))
+
+	mockRequestRepo := new(MockCapabilityRequestRepository)
+	mockRequestRepo.On("List", mock.Anything).Return([]*domain.CapabilityRequestWithDetails{request}, nil)
+	mockRequestRepo.On("Expire", request.ID, now).Return(nil)
+
+	service := &CapabilityRequestService{requestRepo: mockRequestRepo}
+
+	expired, err := service.SweepExpiredRequests(context.Background(), now)
+	require.NoError(t, err)
+	assert.Equal(t, 1, expired)
+	mockRequestRepo.AssertExpectations(t)
+}
+
+func TestCapabilityRequestService_SendPendingReminders(t *testing.T) {
+	// Fake clock: the reminder pass runs at a fixed instant
+	now := time.Date(2025, 6, 1, 9, 0, 0, 0, time.UTC)
+	cutoff := now.Add(-CapabilityRequestAdminReminderAfter)
+	orgID := uuid.New()
+	request := newPendingCapabilityRequest(orgID, now.Add(-50*time.Hour))
+
+	status := domain.CapabilityRequestStatusPending
+	mockRequestRepo := new(MockCapabilityRequestRepository)
+	mockRequestRepo.On("List", domain.CapabilityRequestFilter{Status: &status, RequestedBefore: &cutoff, NotAdminReminded: true}).
+		Return([]*domain.CapabilityRequestWithDetails{request}, nil)
+	mockRequestRepo.On("MarkAdminReminded", request.ID, now).Return(nil)
+
+	admin := &domain.User{ID: uuid.New(), OrganizationID: orgID, Email: "admin@example.com", Name: "Admin", Role: domain.RoleAdmin, Status: domain.UserStatusActive}
+	suspendedAdmin := &domain.User{ID: uuid.New(), OrganizationID: orgID, Email: "gone@example.com", Role: domain.RoleAdmin, Status: domain.UserStatusSuspended}
+	member := &domain.User{ID: uuid.New(), OrganizationID: orgID, Email: "member@example.com", Role: domain.RoleMember, Status: domain.UserStatusActive}
+	mockUserRepo := new(MockUserRepository)
+	mockUserRepo.On("GetByOrganization", orgID).Return([]*domain.User{admin, suspendedAdmin, member}, nil)
+
+	mockOrgRepo := new(MockOrganizationRepository)
+	mockOrgRepo.On("GetByID", orgID).Return(&domain.Organization{
+		ID: orgID, Name: "Acme", LogoURL: "https://cdn.example.com/acme.png", EmailLocale: "es",
+	}, nil)
+
+	mockEmail := new(MockEmailService)
+	mockEmail.On("SendTemplatedEmail", domain.TemplateCapabilityRequestPending, "admin@example.com", mock.MatchedBy(func(data domain.EmailTemplateData) bool {
+		return data.OrganizationName == "Acme" && data.OrganizationLogoURL == "https://cdn.example.com/acme.png" && data.Locale == "es"
+	})).Return(nil)
+
+	service := &CapabilityRequestService{requestRepo: mockRequestRepo, userRepo: mockUserRepo, orgRepo: mockOrgRepo, emailService: mockEmail}
+
+	reminded, err := service.SendPendingReminders(context.Background(), now)
+	require.NoError(t, err)
+	assert.Equal(t, 1, reminded)
+	mockRequestRepo.AssertExpectations(t)
+	mockEmail.AssertExpectations(t)
+	mockEmail.AssertNumberOfCalls(t, "SendTemplatedEmail", 1)
+}
+
+func TestCapabilityRequestService_SendPendingReminders_NoAdmins(t *testing.T) {
+	now := time.Date(2025, 6, 1, 9, 0, 0, 0, time.UTC)
+	orgID := uuid.New()
+	request := newPendingCapabilityRequest(orgID, now.Add(-72*time.Hour))
+
+	mockRequestRepo := new(MockCapabilityRequestRepository)
+	mockRequestRepo.On("List", mock.Anything).Return([]*domain.CapabilityRequestWithDetails{request}, nil)
+	mockUserRepo := new(MockUserRepository)
+	mockUserRepo.On("GetByOrganization", orgID).Return([]*domain.User{}, nil)
+	mockEmail := new(MockEmailService)
+
+	service := &CapabilityRequestService{requestRepo: mockRequestRepo, userRepo: mockUserRepo, emailService: mockEmail}
+
+	reminded, err := service.SendPendingReminders(context.Background(), now)
+	require.NoError(t, err)
+	assert.Equal(t, 0, reminded)
+	mockRequestRepo.AssertNotCalled(t, "MarkAdminReminded", mock.Anything, mock.Anything)
+	mockEmail.AssertNotCalled(t, "SendTemplatedEmail", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestCapabilityRequestService_ApproveRequest_Expired(t *testing.T) {
+	request := newPendingCapabilityRequest(uuid.New(), time.Now().Add(-8*24*time.Hour))
+
+	mockRequestRepo := new(MockCapabilityRequestRepository)
+	mockRequestRepo.On("GetByID", request.ID).Return(request, nil)
+
+	service := &CapabilityRequestService{requestRepo: mockRequestRepo}
+
+	err := service.ApproveRequest(context.Background(), request.ID, uuid.New(), domain.CapabilityGrantOptions{})
+	assert.ErrorIs(t, err, ErrCapabilityRequestExpired)
+	mockRequestRepo.AssertNotCalled(t, "UpdateStatus", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestCapabilityRequestService_ApproveRequest_ScopedGrant(t *testing.T) {
+	request := newPendingCapabilityRequest(uuid.New(), time.Now())
+	reviewerID := uuid.New()
+	expiresAt := time.Now().Add(24 * time.Hour)
+
+	mockRequestRepo := new(MockCapabilityRequestRepository)
+	mockRequestRepo.On("GetByID", request.ID).Return(request, nil)
+	mockRequestRepo.On("UpdateStatus", request.ID, domain.CapabilityRequestStatusApproved, reviewerID).Return(nil)
+	mockCapabilityRepo := new(MockCapabilityRepository)
+	mockCapabilityRepo.On("CreateCapability", mock.MatchedBy(func(c *domain.AgentCapability) bool {
+		return c.GrantedScope() == "/tmp/*" && c.ExpiresAt != nil && c.ExpiresAt.Equal(expiresAt)
+	})).Return(nil)
+
+	service := &CapabilityRequestService{requestRepo: mockRequestRepo, capabilityRepo: mockCapabilityRepo}
+
+	err := service.ApproveRequest(context.Background(), request.ID, reviewerID, domain.CapabilityGrantOptions{
+		GrantedScope: " /tmp/* ",
+		ExpiresAt:    &expiresAt,
+	})
+	require.NoError(t, err)
+	mockCapabilityRepo.AssertExpectations(t)
+}
+
+func TestCapabilityRequestService_ApproveRequest_PastExpiry(t *testing.T) {
+	past := time.Now().Add(-time.Hour)
+	service := &CapabilityRequestService{}
+
+	err := service.ApproveRequest(context.Background(), uuid.New(), uuid.New(), domain.CapabilityGrantOptions{ExpiresAt: &past})
+	assert.ErrorIs(t, err, ErrInvalidCapabilityGrant)
+	assert.Contains(t, err.Error(), "expires_at")
+}
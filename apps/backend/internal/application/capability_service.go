@@ -4,13 +4,20 @@ import (
 	"context"
 	"crypto/ed25519"
 	"encoding/base64"
+	"errors"
 	"fmt"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/opena2a/identity/backend/internal/domain"
+	"github.com/opena2a/identity/backend/internal/infrastructure/metrics"
 )
 
+// ErrCapabilityNotInCatalog is returned when a capability type is not in the organization's capability catalog
+var ErrCapabilityNotInCatalog = errors.New("capability type is not allowed by the organization's capability catalog")
+
 // VerificationResult represents the result of an action verification
 type VerificationResult struct {
 	IsValid      bool    `json:"isValid"`
@@ -22,11 +29,14 @@ type VerificationResult struct {
 
 // CapabilityService handles capability verification and management
 type CapabilityService struct {
-	capabilityRepo domain.CapabilityRepository
-	agentRepo      domain.AgentRepository
-	auditRepo      domain.AuditLogRepository
-	trustCalc      domain.TrustScoreCalculator
-	trustScoreRepo domain.TrustScoreRepository
+	capabilityRepo   domain.CapabilityRepository
+	agentRepo        domain.AgentRepository
+	auditRepo        domain.AuditLogRepository
+	trustCalc        domain.TrustScoreCalculator
+	trustScoreRepo   domain.TrustScoreRepository
+	cacheInvalidator AgentCacheInvalidator         // Optional: drops cached authorization data on revoke
+	orgRepo          domain.OrganizationRepository // Optional: per-organization violation penalties
+	now              func() time.Time              // Clock for capability expiry; nil means time.Now
 }
 
 // NewCapabilityService creates a new capability service
@@ -36,14 +46,27 @@ func NewCapabilityService(
 	auditRepo domain.AuditLogRepository,
 	trustCalc domain.TrustScoreCalculator,
 	trustScoreRepo domain.TrustScoreRepository,
+	cacheInvalidator AgentCacheInvalidator,
+	orgRepo domain.OrganizationRepository,
 ) *CapabilityService {
 	return &CapabilityService{
-		capabilityRepo: capabilityRepo,
-		agentRepo:      agentRepo,
-		auditRepo:      auditRepo,
-		trustCalc:      trustCalc,
-		trustScoreRepo: trustScoreRepo,
+		capabilityRepo:   capabilityRepo,
+		agentRepo:        agentRepo,
+		auditRepo:        auditRepo,
+		trustCalc:        trustCalc,
+		trustScoreRepo:   trustScoreRepo,
+		cacheInvalidator: cacheInvalidator,
+		orgRepo:          orgRepo,
+		now:              time.Now,
+	}
+}
+
+// clock returns the current time from the injected clock, falling back to time.Now
+func (s *CapabilityService) clock() time.Time {
+	if s.now != nil {
+		return s.now()
 	}
+	return time.Now()
 }
 
 // VerifyAction verifies if an agent is authorized to perform a specific action
@@ -90,27 +113,30 @@ func (s *CapabilityService) VerifyAction(
 	inScope := s.hasCapability(capabilities, requestedCapability)
 
 	if !inScope {
-		// 4. Record violation
+		// 4. Record violation, penalized per the organization's violation penalties.
+		// Alert-only violations used to cost a flat 10 points; they now use the
+		// severity-based alert penalties (5/7/10/15 by default).
+		severity := s.calculateSeverity(agent)
 		violation := &domain.CapabilityViolation{
-			AgentID:             agentID,
-			AttemptedCapability: requestedCapability,
+			AgentID:                agentID,
+			AttemptedCapability:    requestedCapability,
 			RegisteredCapabilities: s.capabilitiesToMap(capabilities),
-			Severity:            s.calculateSeverity(agent),
-			TrustScoreImpact:    -10,
-			IsBlocked:           false,
-			SourceIP:            sourceIP,
-			RequestMetadata:     metadata,
+			Severity:               severity,
+			TrustScoreImpact:       violationPenalties(s.orgRepo, agent.OrganizationID).TrustScoreImpact(severity, false),
+			IsBlocked:              false,
+			SourceIP:               sourceIP,
+			RequestMetadata:        metadata,
 		}
 
 		if err := s.capabilityRepo.CreateViolation(violation); err != nil {
 			return nil, err
 		}
+		metrics.RecordCapabilityViolation(violation.Severity)
 
 		// 5. Decrease trust score
 		// IMPORTANT: trust_score is stored as 0.0-1.0 (representing 0-100%), not 0-100
-		// Subtract 10% as a decimal (0.10), not as integer 10
-		trustScoreDecrease := 0.10 // 10% penalty
-		newTrustScore := agent.TrustScore - trustScoreDecrease
+		// Subtract the impact as a decimal (-10 subtracts 0.10), not as integer 10
+		newTrustScore := agent.TrustScore + float64(violation.TrustScoreImpact)/100.0
 		if newTrustScore < 0 {
 			newTrustScore = 0
 		}
@@ -120,6 +146,7 @@ func (s *CapabilityService) VerifyAction(
 		if err := s.agentRepo.UpdateTrustScore(agentID, newTrustScore); err != nil {
 			return nil, err
 		}
+		metrics.UpdateTrustScore(agentID.String(), agent.Name, newTrustScore)
 
 		// Check if agent should be marked as compromised
 		// IMPORTANT: trust_score is 0.0-1.0 scale, so 30% = 0.30
@@ -191,13 +218,22 @@ func (s *CapabilityService) GrantCapability(
 	capabilityType string,
 	scope map[string]interface{},
 	grantedBy *uuid.UUID,
+	duration time.Duration, // 0 grants the capability until it is revoked
 ) (*domain.AgentCapability, error) {
+	if duration < 0 {
+		return nil, fmt.Errorf("duration must be positive")
+	}
+
 	// Verify agent exists
 	agent, err := s.agentRepo.GetByID(agentID)
 	if err != nil {
 		return nil, fmt.Errorf("agent not found: %w", err)
 	}
 
+	if err := ensureInCapabilityCatalog(s.capabilityRepo, agent.OrganizationID, []string{capabilityType}); err != nil {
+		return nil, err
+	}
+
 	// Create capability
 	capability := &domain.AgentCapability{
 		AgentID:         agentID,
@@ -206,6 +242,10 @@ func (s *CapabilityService) GrantCapability(
 		GrantedBy:       grantedBy,
 		GrantedAt:       time.Now(),
 	}
+	if duration > 0 {
+		expiresAt := capability.GrantedAt.Add(duration)
+		capability.ExpiresAt = &expiresAt
+	}
 
 	if err := s.capabilityRepo.CreateCapability(capability); err != nil {
 		return nil, err
@@ -213,6 +253,9 @@ func (s *CapabilityService) GrantCapability(
 
 	// Log to audit trail
 	description := fmt.Sprintf("Capability '%s' granted to agent %s", capabilityType, agent.DisplayName)
+	if capability.ExpiresAt != nil {
+		description += fmt.Sprintf(" until %s", capability.ExpiresAt.Format(time.RFC3339))
+	}
 	grantedByID := uuid.Nil
 	if grantedBy != nil {
 		grantedByID = *grantedBy
@@ -270,6 +313,9 @@ func (s *CapabilityService) RevokeCapability(
 	if err := s.capabilityRepo.RevokeCapability(capabilityID, time.Now()); err != nil {
 		return err
 	}
+	if s.cacheInvalidator != nil {
+		s.cacheInvalidator.InvalidateAgent(ctx, capability.AgentID)
+	}
 
 	// Log to audit trail
 	description := fmt.Sprintf("Capability '%s' revoked from agent %s", capability.CapabilityType, agent.DisplayName)
@@ -379,6 +425,15 @@ func (s *CapabilityService) GetAgentCapabilities(
 	return s.capabilityRepo.GetCapabilitiesByAgentID(agentID)
 }
 
+// GetActiveCapabilitiesForAgents retrieves the active capabilities of several
+// agents in one query, keyed by agent ID
+func (s *CapabilityService) GetActiveCapabilitiesForAgents(
+	ctx context.Context,
+	agentIDs []uuid.UUID,
+) (map[uuid.UUID][]*domain.AgentCapability, error) {
+	return s.capabilityRepo.GetCapabilitiesByAgentIDs(agentIDs)
+}
+
 // CapabilityDefinition represents a capability type available in the system
 type CapabilityDefinition struct {
 	Type        string `json:"type"`
@@ -388,85 +443,68 @@ type CapabilityDefinition struct {
 	RiskLevel   string `json:"riskLevel"`
 }
 
-// ListCapabilities lists all available capability types in the system
+// ListCapabilities lists the capability types the organization allows its agents to be granted
 func (s *CapabilityService) ListCapabilities(ctx context.Context, orgID uuid.UUID) ([]CapabilityDefinition, error) {
-	// Return the standard set of capabilities available in AIM
-	capabilities := []CapabilityDefinition{
-		{
-			Type:        domain.CapabilityFileRead,
-			Name:        "File Read",
-			Description: "Read files from the file system",
-			Category:    "file_system",
-			RiskLevel:   "low",
-		},
-		{
-			Type:        domain.CapabilityFileWrite,
-			Name:        "File Write",
-			Description: "Write files to the file system",
-			Category:    "file_system",
-			RiskLevel:   "medium",
-		},
-		{
-			Type:        domain.CapabilityFileDelete,
-			Name:        "File Delete",
-			Description: "Delete files from the file system",
-			Category:    "file_system",
-			RiskLevel:   "high",
-		},
-		{
-			Type:        domain.CapabilityNetworkAccess,
-			Name:        "Network Access",
-			Description: "Make network requests and access external services",
-			Category:    "network",
-			RiskLevel:   "medium",
-		},
-		{
-			Type:        domain.CapabilityDBQuery,
-			Name:        "Database Query",
-			Description: "Query databases (read operations)",
-			Category:    "database",
-			RiskLevel:   "low",
-		},
-		{
-			Type:        domain.CapabilityDBWrite,
-			Name:        "Database Write",
-			Description: "Modify databases (write operations)",
-			Category:    "database",
-			RiskLevel:   "high",
-		},
-		{
-			Type:        domain.CapabilityAPICall,
-			Name:        "API Call",
-			Description: "Call external APIs",
-			Category:    "network",
-			RiskLevel:   "medium",
-		},
-		{
-			Type:        domain.CapabilityDataExport,
-			Name:        "Data Export",
-			Description: "Export data from the system",
-			Category:    "data",
-			RiskLevel:   "high",
-		},
-		{
-			Type:        domain.CapabilitySystemAdmin,
-			Name:        "System Administration",
-			Description: "Execute system commands and administrative actions",
-			Category:    "system",
-			RiskLevel:   "critical",
-		},
-		{
-			Type:        domain.CapabilityMCPToolUse,
-			Name:        "MCP Tool Use",
-			Description: "Use Model Context Protocol tools",
-			Category:    "mcp",
-			RiskLevel:   "medium",
-		},
+	catalog, err := capabilityCatalog(s.capabilityRepo, orgID)
+	if err != nil {
+		return nil, err
+	}
+
+	capabilities := make([]CapabilityDefinition, 0, len(catalog))
+	for _, entry := range catalog {
+		capabilities = append(capabilities, CapabilityDefinition{
+			Type:        entry.CapabilityType,
+			Name:        entry.Name,
+			Description: entry.Description,
+			Category:    entry.Category,
+			RiskLevel:   entry.RiskLevel,
+		})
 	}
 
 	return capabilities, nil
 }
 
+// capabilityCatalog returns the organization's capability catalog, or the default catalog if the
+// organization has none (it was created before catalogs existed or outside the repository)
+func capabilityCatalog(repo domain.CapabilityRepository, orgID uuid.UUID) ([]*domain.CapabilityCatalogEntry, error) {
+	catalog, err := repo.GetCatalog(orgID)
+	if err != nil {
+		return nil, err
+	}
+	if len(catalog) == 0 {
+		return domain.DefaultCapabilityCatalog(), nil
+	}
+	return catalog, nil
+}
+
+// ensureInCapabilityCatalog fails with ErrCapabilityNotInCatalog, naming every offending type, if
+// the organization's catalog does not allow one of capabilityTypes
+func ensureInCapabilityCatalog(repo domain.CapabilityRepository, orgID uuid.UUID, capabilityTypes []string) error {
+	if len(capabilityTypes) == 0 {
+		return nil
+	}
+
+	catalog, err := capabilityCatalog(repo, orgID)
+	if err != nil {
+		return fmt.Errorf("failed to load capability catalog: %w", err)
+	}
+	allowed := make(map[string]bool, len(catalog))
+	for _, entry := range catalog {
+		allowed[entry.CapabilityType] = true
+	}
+
+	var rejected []string
+	for _, capabilityType := range capabilityTypes {
+		if !allowed[capabilityType] {
+			rejected = append(rejected, capabilityType)
+		}
+	}
+	if len(rejected) > 0 {
+		return fmt.Errorf("%w: %s", ErrCapabilityNotInCatalog, strings.Join(rejected, ", "))
+	}
+	return nil
+}
+
 // GetViolationsByAgent retrieves violations for a specific agent
 func (s *CapabilityService) GetViolationsByAgent(
 	ctx context.Context,
@@ -494,6 +532,127 @@ func (s *CapabilityService) GetRecentViolations(
 	return s.capabilityRepo.GetRecentViolations(orgID, minutes)
 }
 
+// Violation statistics window, in days, and how many attempted capabilities are ranked
+const (
+	DefaultViolationStatsDays  = 30
+	MaxViolationStatsDays      = 90
+	violationStatsTopAttempted = 10
+)
+
+var (
+	ErrInvalidViolationStatsDays = fmt.Errorf("days must be between 1 and %d", MaxViolationStatsDays)
+	ErrViolationStatsAgentAccess = errors.New("agent not found")
+)
+
+// violationSeverities are the severities every stats bucket reports, even when zero
+var violationSeverities = []string{
+	domain.ViolationSeverityLow,
+	domain.ViolationSeverityMedium,
+	domain.ViolationSeverityHigh,
+	domain.ViolationSeverityCritical,
+}
+
+// GetViolationStats summarizes an agent's capability violations over the days days ending at now:
+// daily counts by severity and the unauthorized capabilities it attempted most. The agent must
+// belong to orgID.
+func (s *CapabilityService) GetViolationStats(
+	ctx context.Context,
+	orgID, agentID uuid.UUID,
+	days int,
+	now time.Time,
+) (*domain.ViolationStats, error) {
+	if days < 1 || days > MaxViolationStatsDays {
+		return nil, ErrInvalidViolationStatsDays
+	}
+
+	agent, err := s.agentRepo.GetByID(agentID)
+	if err != nil || agent == nil || agent.OrganizationID != orgID {
+		return nil, ErrViolationStatsAgentAccess
+	}
+
+	// Days are UTC calendar days; the window starts at the beginning of the oldest one
+	today := now.UTC().Truncate(24 * time.Hour)
+	since := today.AddDate(0, 0, -(days - 1))
+
+	violations, err := s.capabilityRepo.GetViolationsByAgentSince(agentID, since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get violations: %w", err)
+	}
+
+	stats := &domain.ViolationStats{
+		AgentID:    agentID,
+		Since:      since,
+		Until:      now.UTC(),
+		BySeverity: newSeverityCounts(),
+		Timeline:   make([]domain.ViolationStatsBucket, days),
+	}
+	for i := range stats.Timeline {
+		stats.Timeline[i] = domain.ViolationStatsBucket{
+			Date:       since.AddDate(0, 0, i),
+			BySeverity: newSeverityCounts(),
+		}
+	}
+
+	attempted := make(map[string]*domain.AttemptedActionSummary)
+	for _, violation := range violations {
+		createdAt := violation.CreatedAt.UTC()
+		if createdAt.Before(since) || createdAt.After(stats.Until) {
+			continue
+		}
+
+		stats.TotalViolations++
+		stats.BySeverity[violation.Severity]++
+		if violation.IsBlocked {
+			stats.Blocked++
+		}
+
+		bucket := &stats.Timeline[int(createdAt.Sub(since)/(24*time.Hour))]
+		bucket.Total++
+		bucket.BySeverity[violation.Severity]++
+
+		summary, ok := attempted[violation.AttemptedCapability]
+		if !ok {
+			summary = &domain.AttemptedActionSummary{Capability: violation.AttemptedCapability}
+			attempted[violation.AttemptedCapability] = summary
+		}
+		summary.Count++
+		if violation.IsBlocked {
+			summary.Blocked++
+		}
+		if createdAt.After(summary.LastAttemptAt) {
+			summary.LastAttemptAt = createdAt
+		}
+	}
+
+	stats.TopAttempted = make([]domain.AttemptedActionSummary, 0, len(attempted))
+	for _, summary := range attempted {
+		stats.TopAttempted = append(stats.TopAttempted, *summary)
+	}
+	sort.Slice(stats.TopAttempted, func(i, j int) bool {
+		a, b := stats.TopAttempted[i], stats.TopAttempted[j]
+		if a.Count != b.Count {
+			return a.Count > b.Count
+		}
+		if !a.LastAttemptAt.Equal(b.LastAttemptAt) {
+			return a.LastAttemptAt.After(b.LastAttemptAt)
+		}
+		return a.Capability < b.Capability
+	})
+	if len(stats.TopAttempted) > violationStatsTopAttempted {
+		stats.TopAttempted = stats.TopAttempted[:violationStatsTopAttempted]
+	}
+
+	return stats, nil
+}
+
+func newSeverityCounts() map[string]int {
+	counts := make(map[string]int, len(violationSeverities))
+	for _, severity := range violationSeverities {
+		counts[severity] = 0
+	}
+	return counts
+}
+
 // Helper: Verify cryptographic signature
 func (s *CapabilityService) verifySignature(publicKeyStr string, algorithm string, signature []byte, payload []byte) bool {
 	// Decode public key from base64
@@ -517,7 +676,12 @@ func (s *CapabilityService) verifySignature(publicKeyStr string, algorithm strin
 
 // Helper: Check if agent has a specific capability
 func (s *CapabilityService) hasCapability(capabilities []*domain.AgentCapability, requestedCapability string) bool {
+	now := s.clock()
 	for _, cap := range capabilities {
+		// An expired time-boxed capability is no longer granted
+		if cap.IsExpired(now) {
+			continue
+		}
 		if cap.CapabilityType == requestedCapability {
 			return true
 		}
@@ -0,0 +1,271 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCapabilityService_GrantCapability_Duration(t *testing.T) {
+	agent := createTestAgentForService()
+
+	mockAgentRepo := new(MockAgentRepository)
+	mockAgentRepo.On("GetByID", agent.ID).Return(agent, nil)
+	mockCapabilityRepo := new(MockCapabilityRepository)
+	mockCapabilityRepo.On("GetCatalog", agent.OrganizationID).Return(nil, nil) // Default catalog
+	mockCapabilityRepo.On("CreateCapability", mock.Anything).Return(nil)
+	mockAuditRepo := new(AgentServiceMockAuditLogRepository)
+	mockAuditRepo.On("Create", mock.Anything).Return(nil)
+	mockTrustCalc := new(AgentServiceMockTrustScoreCalculator)
+	mockTrustCalc.On("Calculate", agent).Return(nil, errors.New("skip recalculation"))
+
+	service := &CapabilityService{
+		capabilityRepo: mockCapabilityRepo,
+		agentRepo:      mockAgentRepo,
+		auditRepo:      mockAuditRepo,
+		trustCalc:      mockTrustCalc,
+	}
+
+	capability, err := service.GrantCapability(context.Background(), agent.ID, "db:write", nil, nil, 2*time.Hour)
+	require.NoError(t, err)
+	require.NotNil(t, capability.ExpiresAt)
+	assert.Equal(t, capability.GrantedAt.Add(2*time.Hour), *capability.ExpiresAt)
+
+	permanent, err := service.GrantCapability(context.Background(), agent.ID, "db:query", nil, nil, 0)
+	require.NoError(t, err)
+	assert.Nil(t, permanent.ExpiresAt)
+
+	_, err = service.GrantCapability(context.Background(), agent.ID, "db:query", nil, nil, -time.Hour)
+	assert.Error(t, err)
+}
+
+func TestCapabilityService_GrantCapability_RejectsOutOfCatalog(t *testing.T) {
+	agent := createTestAgentForService()
+
+	mockAgentRepo := new(MockAgentRepository)
+	mockAgentRepo.On("GetByID", agent.ID).Return(agent, nil)
+	mockCapabilityRepo := new(MockCapabilityRepository)
+	// This organization only allows read-only capabilities
+	mockCapabilityRepo.On("GetCatalog", agent.OrganizationID).Return([]*domain.CapabilityCatalogEntry{
+		{CapabilityType: domain.CapabilityFileRead, Name: "File Read"},
+		{CapabilityType: domain.CapabilityDBQuery, Name: "Database Query"},
+	}, nil)
+
+	service := &CapabilityService{
+		capabilityRepo: mockCapabilityRepo,
+		agentRepo:      mockAgentRepo,
+	}
+
+	_, err := service.GrantCapability(context.Background(), agent.ID, domain.CapabilityDBWrite, nil, nil, 0)
+	assert.ErrorIs(t, err, ErrCapabilityNotInCatalog)
+	assert.ErrorContains(t, err, domain.CapabilityDBWrite)
+	// db:write is in the default catalog, but not in this organization's
+	mockCapabilityRepo.AssertNotCalled(t, "CreateCapability", mock.Anything)
+}
+
+func TestCapabilityService_ListCapabilities_ReturnsOrganizationCatalog(t *testing.T) {
+	orgID, newOrgID := uuid.New(), uuid.New()
+	mockCapabilityRepo := new(MockCapabilityRepository)
+	mockCapabilityRepo.On("GetCatalog", orgID).Return([]*domain.CapabilityCatalogEntry{
+		{CapabilityType: domain.CapabilityFileRead, Name: "File Read", Category: "file_system", RiskLevel: "low"},
+	}, nil)
+	mockCapabilityRepo.On("GetCatalog", newOrgID).Return(nil, nil)
+
+	service := &CapabilityService{capabilityRepo: mockCapabilityRepo}
+
+	capabilities, err := service.ListCapabilities(context.Background(), orgID)
+	require.NoError(t, err)
+	assert.Equal(t, []CapabilityDefinition{
+		{Type: domain.CapabilityFileRead, Name: "File Read", Category: "file_system", RiskLevel: "low"},
+	}, capabilities)
+
+	// An organization without a catalog gets the default one
+	capabilities, err = service.ListCapabilities(context.Background(), newOrgID)
+	require.NoError(t, err)
+	assert.Len(t, capabilities, len(domain.DefaultCapabilityCatalog()))
+}
+
+func TestCapabilityService_VerifyAction_TimeBoxedCapabilityExpires(t *testing.T) {
+	agent := createTestAgentForService()
+	agent.TrustScore = 0.9
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	expiresAt := now.Add(time.Hour)
+	capabilities := []*domain.AgentCapability{
+		{ID: uuid.New(), AgentID: agent.ID, CapabilityType: "db:write", ExpiresAt: &expiresAt},
+	}
+
+	mockAgentRepo := new(MockAgentRepository)
+	mockAgentRepo.On("GetByID", agent.ID).Return(agent, nil)
+	mockAgentRepo.On("Update", agent).Return(nil)
+	mockAgentRepo.On("UpdateTrustScore", agent.ID, mock.Anything).Return(nil)
+	mockCapabilityRepo := new(MockCapabilityRepository)
+	mockCapabilityRepo.On("GetActiveCapabilitiesByAgentID", agent.ID).Return(capabilities, nil)
+	mockCapabilityRepo.On("CreateViolation", mock.MatchedBy(func(v *domain.CapabilityViolation) bool {
+		return v.AttemptedCapability == "db:write"
+	})).Return(nil)
+	mockAuditRepo := new(AgentServiceMockAuditLogRepository)
+	mockAuditRepo.On("Create", mock.Anything).Return(nil)
+
+	service := &CapabilityService{
+		capabilityRepo: mockCapabilityRepo,
+		agentRepo:      mockAgentRepo,
+		auditRepo:      mockAuditRepo,
+		now:            func() time.Time { return now },
+	}
+
+	result, err := service.VerifyAction(context.Background(), agent.ID, "db:write", nil, nil, nil, nil)
+	require.NoError(t, err)
+	assert.True(t, result.IsAuthorized)
+	mockCapabilityRepo.AssertNotCalled(t, "CreateViolation", mock.Anything)
+
+	now = expiresAt
+
+	result, err = service.VerifyAction(context.Background(), agent.ID, "db:write", nil, nil, nil, nil)
+	require.NoError(t, err)
+	assert.False(t, result.IsAuthorized)
+	assert.False(t, result.InScope)
+	mockCapabilityRepo.AssertCalled(t, "CreateViolation", mock.Anything)
+	mockAgentRepo.AssertCalled(t, "UpdateTrustScore", agent.ID, mock.Anything)
+}
+
+func TestCapabilityService_VerifyAction_ViolationPenaltyFromOrganization(t *testing.T) {
+	tests := []struct {
+		name      string
+		penalties *domain.ViolationPenalties
+		wantScore float64 // First violation of an agent at 0.9 is low severity
+	}{
+		{name: "default penalties", penalties: nil, wantScore: 0.85},
+		{name: "organization penalties", penalties: &domain.ViolationPenalties{
+			Alert: domain.SeverityPenalties{Low: 20, Medium: 20, High: 20, Critical: 20},
+		}, wantScore: 0.7},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			agent := createTestAgentForService()
+			agent.TrustScore = 0.9
+
+			mockAgentRepo := new(MockAgentRepository)
+			mockAgentRepo.On("GetByID", agent.ID).Return(agent, nil)
+			mockAgentRepo.On("Update", agent).Return(nil)
+			mockAgentRepo.On("UpdateTrustScore", agent.ID, mock.Anything).Return(nil)
+			mockCapabilityRepo := new(MockCapabilityRepository)
+			mockCapabilityRepo.On("GetActiveCapabilitiesByAgentID", agent.ID).Return([]*domain.AgentCapability{}, nil)
+			mockCapabilityRepo.On("CreateViolation", mock.Anything).Return(nil)
+			mockAuditRepo := new(AgentServiceMockAuditLogRepository)
+			mockAuditRepo.On("Create", mock.Anything).Return(nil)
+			mockOrgRepo := new(MockOrganizationRepository)
+			mockOrgRepo.On("GetByID", agent.OrganizationID).Return(&domain.Organization{
+				ID:                 agent.OrganizationID,
+				ViolationPenalties: tt.penalties,
+			}, nil)
+
+			service := &CapabilityService{
+				capabilityRepo: mockCapabilityRepo,
+				agentRepo:      mockAgentRepo,
+				auditRepo:      mockAuditRepo,
+				orgRepo:        mockOrgRepo,
+			}
+
+			result, err := service.VerifyAction(context.Background(), agent.ID, "db:write", nil, nil, nil, nil)
+			require.NoError(t, err)
+			assert.False(t, result.InScope)
+			mockAgentRepo.AssertCalled(t, "UpdateTrustScore", agent.ID, mock.MatchedBy(func(score float64) bool {
+				return math.Abs(score-tt.wantScore) < 1e-9
+			}))
+		})
+	}
+}
+
+func TestCapabilityService_GetViolationStats(t *testing.T) {
+	agent := createTestAgentForService()
+	now := time.Date(2025, 3, 10, 15, 30, 0, 0, time.UTC)
+	since := time.Date(2025, 3, 4, 0, 0, 0, 0, time.UTC) // 7 days ending today
+
+	violation := func(capability, severity string, blocked bool, at time.Time) *domain.CapabilityViolation {
+		return &domain.CapabilityViolation{
+			ID:                  uuid.New(),
+			AgentID:             agent.ID,
+			AttemptedCapability: capability,
+			Severity:            severity,
+			IsBlocked:           blocked,
+			CreatedAt:           at,
+		}
+	}
+	violations := []*domain.CapabilityViolation{
+		violation(domain.CapabilityDBWrite, domain.ViolationSeverityHigh, true, since.Add(2*time.Hour)),
+		violation(domain.CapabilityFileDelete, domain.ViolationSeverityMedium, false, since.Add(26*time.Hour)),
+		violation(domain.CapabilityDBWrite, domain.ViolationSeverityHigh, true, since.Add(27*time.Hour)),
+		violation(domain.CapabilitySystemAdmin, domain.ViolationSeverityCritical, true, now.Add(-4*time.Hour)),
+		violation(domain.CapabilityDBWrite, domain.ViolationSeverityCritical, true, now.Add(-time.Hour)),
+		violation(domain.CapabilityFileDelete, domain.ViolationSeverityLow, false, now.Add(-30*time.Minute)),
+	}
+
+	mockAgentRepo := new(MockAgentRepository)
+	mockAgentRepo.On("GetByID", agent.ID).Return(agent, nil)
+	mockCapabilityRepo := new(MockCapabilityRepository)
+	mockCapabilityRepo.On("GetViolationsByAgentSince", agent.ID, since).Return(violations, nil)
+	service := &CapabilityService{capabilityRepo: mockCapabilityRepo, agentRepo: mockAgentRepo}
+
+	stats, err := service.GetViolationStats(context.Background(), agent.OrganizationID, agent.ID, 7, now)
+	require.NoError(t, err)
+
+	assert.Equal(t, since, stats.Since)
+	assert.Equal(t, 6, stats.TotalViolations)
+	assert.Equal(t, 4, stats.Blocked)
+	assert.Equal(t, map[string]int{
+		domain.ViolationSeverityLow:      1,
+		domain.ViolationSeverityMedium:   1,
+		domain.ViolationSeverityHigh:     2,
+		domain.ViolationSeverityCritical: 2,
+	}, stats.BySeverity)
+
+	// One bucket per day, empty days included
+	require.Len(t, stats.Timeline, 7)
+	assert.Equal(t, since, stats.Timeline[0].Date)
+	assert.Equal(t, 1, stats.Timeline[0].Total)
+	assert.Equal(t, 1, stats.Timeline[0].BySeverity[domain.ViolationSeverityHigh])
+	assert.Equal(t, 2, stats.Timeline[1].Total)
+	assert.Equal(t, 1, stats.Timeline[1].BySeverity[domain.ViolationSeverityMedium])
+	assert.Equal(t, 1, stats.Timeline[1].BySeverity[domain.ViolationSeverityHigh])
+	for _, bucket := range stats.Timeline[2:6] {
+		assert.Zero(t, bucket.Total)
+		assert.Zero(t, bucket.BySeverity[domain.ViolationSeverityCritical])
+	}
+	assert.Equal(t, time.Date(2025, 3, 10, 0, 0, 0, 0, time.UTC), stats.Timeline[6].Date)
+	assert.Equal(t, 3, stats.Timeline[6].Total)
+	assert.Equal(t, 2, stats.Timeline[6].BySeverity[domain.ViolationSeverityCritical])
+
+	require.Len(t, stats.TopAttempted, 3)
+	assert.Equal(t, domain.AttemptedActionSummary{
+		Capability: domain.CapabilityDBWrite, Count: 3, Blocked: 3, LastAttemptAt: now.Add(-time.Hour),
+	}, stats.TopAttempted[0])
+	assert.Equal(t, domain.CapabilityFileDelete, stats.TopAttempted[1].Capability)
+	assert.Equal(t, 2, stats.TopAttempted[1].Count)
+	assert.Zero(t, stats.TopAttempted[1].Blocked)
+	assert.Equal(t, domain.CapabilitySystemAdmin, stats.TopAttempted[2].Capability)
+}
+
+func TestCapabilityService_GetViolationStats_RejectsOtherOrganizationsAgent(t *testing.T) {
+	agent := createTestAgentForService()
+
+	mockAgentRepo := new(MockAgentRepository)
+	mockAgentRepo.On("GetByID", agent.ID).Return(agent, nil)
+	mockCapabilityRepo := new(MockCapabilityRepository)
+	service := &CapabilityService{capabilityRepo: mockCapabilityRepo, agentRepo: mockAgentRepo}
+
+	_, err := service.GetViolationStats(context.Background(), uuid.New(), agent.ID, 30, time.Now())
+	assert.ErrorIs(t, err, ErrViolationStatsAgentAccess)
+
+	_, err = service.GetViolationStats(context.Background(), agent.OrganizationID, agent.ID, MaxViolationStatsDays+1, time.Now())
+	assert.ErrorIs(t, err, ErrInvalidViolationStatsDays)
+	mockCapabilityRepo.AssertNotCalled(t, "GetViolationsByAgentSince", mock.Anything, mock.Anything)
+}
@@ -0,0 +1,164 @@
+package application
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/domain"
+	"github.com/opena2a/identity/backend/internal/infrastructure/logging"
+)
+
+// ComplianceFrameworks are the frameworks evaluated by scheduled compliance checks
+var ComplianceFrameworks = []string{"soc2", "iso27001", "hipaa", "gdpr"}
+
+// IsComplianceFramework reports whether framework is one of ComplianceFrameworks
+func IsComplianceFramework(framework string) bool {
+	for _, f := range ComplianceFrameworks {
+		if f == framework {
+			return true
+		}
+	}
+	return false
+}
+
+// Scheduled compliance checks run once per DefaultComplianceCheckInterval for each
+// organization and framework; override with COMPLIANCE_CHECK_INTERVAL (Go duration).
+// The scheduler polls every ComplianceCheckPollInterval, so missed runs (e.g. across
+// restarts) are caught up within the hour.
+const (
+	DefaultComplianceCheckInterval = 7 * 24 * time.Hour
+	ComplianceCheckPollInterval    = time.Hour
+)
+
+// ComplianceCheckIntervalFromEnv returns how often scheduled compliance checks run
+func ComplianceCheckIntervalFromEnv() time.Duration {
+	if value, err := time.ParseDuration(os.Getenv("COMPLIANCE_CHECK_INTERVAL")); err == nil && value > 0 {
+		return value
+	}
+	return DefaultComplianceCheckInterval
+}
+
+// ComplianceScorePoint is one entry of a compliance score timeline
+type ComplianceScorePoint struct {
+	RunAt          time.Time `json:"run_at"`
+	ComplianceRate float64   `json:"compliance_rate"`
+	Passed         int       `json:"passed"`
+	Failed         int       `json:"failed"`
+	Total          int       `json:"total"`
+}
+
+// StartComplianceCheckScheduler runs RunScheduledComplianceChecks every ComplianceCheckPollInterval
+// until ctx is cancelled
+func (s *ComplianceService) StartComplianceCheckScheduler(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(ComplianceCheckPollInterval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := s.RunScheduledComplianceChecks(ctx, time.Now(), interval); err != nil {
+			logging.FromContext(ctx).Warn("scheduled compliance checks failed", "error", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunScheduledComplianceChecks runs RunComplianceCheck for every framework of every active
+// organization whose latest snapshot is older than interval, and stores the results as
+// snapshots. Returns the number of snapshots stored.
+func (s *ComplianceService) RunScheduledComplianceChecks(ctx context.Context, now time.Time, interval time.Duration) (int, error) {
+	if s.snapshotRepo == nil || s.orgRepo == nil {
+		return 0, nil
+	}
+
+	orgs, err := s.orgRepo.ListActive()
+	if err != nil {
+		return 0, fmt.Errorf("failed to list organizations: %w", err)
+	}
+
+	stored := 0
+	for _, org := range orgs {
+		for _, framework := range ComplianceFrameworks {
+			latest, err := s.snapshotRepo.GetLatest(org.ID, framework)
+			if err != nil {
+				logging.FromContext(ctx).Warn("failed to get latest compliance snapshot", "organization_id", org.ID, "framework", framework, "error", err)
+				continue
+			}
+			if latest != nil && now.Sub(latest.RunAt) < interval {
+				continue
+			}
+
+			if _, err := s.SnapshotComplianceCheck(ctx, org.ID, framework, now); err != nil {
+				logging.FromContext(ctx).Warn("scheduled compliance check failed", "organization_id", org.ID, "framework", framework, "error", err)
+				continue
+			}
+			stored++
+		}
+	}
+
+	return stored, nil
+}
+
+// SnapshotComplianceCheck runs the framework's compliance checks and stores the result
+func (s *ComplianceService) SnapshotComplianceCheck(ctx context.Context, orgID uuid.UUID, framework string, runAt time.Time) (*domain.ComplianceCheckSnapshot, error) {
+	if s.snapshotRepo == nil {
+		return nil, fmt.Errorf("compliance history storage is not configured")
+	}
+
+	checkResult, err := s.RunComplianceCheck(ctx, orgID, framework)
+	if err != nil {
+		return nil, err
+	}
+	result, ok := checkResult.(*ComplianceCheckResult)
+	if !ok {
+		return nil, fmt.Errorf("unexpected compliance check result %T", checkResult)
+	}
+
+	snapshot := &domain.ComplianceCheckSnapshot{
+		ID:             uuid.New(),
+		OrganizationID: orgID,
+		Framework:      framework,
+		Passed:         result.Passed,
+		Failed:         result.Failed,
+		Total:          result.Total,
+		ComplianceRate: result.ComplianceRate,
+		Checks:         result.Checks,
+		RunAt:          runAt,
+	}
+	if err := s.snapshotRepo.Create(snapshot); err != nil {
+		return nil, fmt.Errorf("failed to store compliance snapshot: %w", err)
+	}
+
+	return snapshot, nil
+}
+
+// GetComplianceHistory returns the framework's compliance score timeline since the given time, oldest first
+func (s *ComplianceService) GetComplianceHistory(ctx context.Context, orgID uuid.UUID, framework string, since time.Time) ([]ComplianceScorePoint, error) {
+	timeline := []ComplianceScorePoint{}
+	if s.snapshotRepo == nil {
+		return timeline, nil
+	}
+
+	snapshots, err := s.snapshotRepo.GetTimeline(orgID, framework, since)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, snapshot := range snapshots {
+		timeline = append(timeline, ComplianceScorePoint{
+			RunAt:          snapshot.RunAt,
+			ComplianceRate: snapshot.ComplianceRate,
+			Passed:         snapshot.Passed,
+			Failed:         snapshot.Failed,
+			Total:          snapshot.Total,
+		})
+	}
+
+	return timeline, nil
+}
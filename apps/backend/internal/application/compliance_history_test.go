@@ -0,0 +1,110 @@
+package application
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// inMemoryComplianceCheckSnapshotRepository stores snapshots in insertion order
+type inMemoryComplianceCheckSnapshotRepository struct {
+	snapshots []*domain.ComplianceCheckSnapshot
+}
+
+func (r *inMemoryComplianceCheckSnapshotRepository) Create(snapshot *domain.ComplianceCheckSnapshot) error {
+	r.snapshots = append(r.snapshots, snapshot)
+	return nil
+}
+
+func (r *inMemoryComplianceCheckSnapshotRepository) GetLatest(orgID uuid.UUID, framework string) (*domain.ComplianceCheckSnapshot, error) {
+	var latest *domain.ComplianceCheckSnapshot
+	for _, snapshot := range r.snapshots {
+		if snapshot.OrganizationID == orgID && snapshot.Framework == framework &&
+			(latest == nil || snapshot.RunAt.After(latest.RunAt)) {
+			latest = snapshot
+		}
+	}
+	return latest, nil
+}
+
+func (r *inMemoryComplianceCheckSnapshotRepository) GetTimeline(orgID uuid.UUID, framework string, since time.Time) ([]*domain.ComplianceCheckSnapshot, error) {
+	var timeline []*domain.ComplianceCheckSnapshot
+	for _, snapshot := range r.snapshots {
+		if snapshot.OrganizationID == orgID && snapshot.Framework == framework && !snapshot.RunAt.Before(since) {
+			timeline = append(timeline, snapshot)
+		}
+	}
+	return timeline, nil
+}
+
+func TestComplianceService_RunScheduledComplianceChecks(t *testing.T) {
+	orgID := uuid.New()
+	now := time.Date(2025, 3, 10, 9, 0, 0, 0, time.UTC)
+
+	mockOrgRepo := new(MockOrganizationRepository)
+	mockOrgRepo.On("ListActive").Return([]*domain.Organization{{ID: orgID, Name: "Acme"}}, nil)
+	mockAgentRepo := new(MockAgentRepository)
+	mockAgentRepo.On("GetByOrganization", orgID).Return([]*domain.Agent{
+		{ID: uuid.New(), OrganizationID: orgID, Status: domain.AgentStatusVerified, TrustScore: 0.9},
+	}, nil)
+
+	// soc2 ran two days ago, so only the other frameworks are due
+	snapshotRepo := &inMemoryComplianceCheckSnapshotRepository{snapshots: []*domain.ComplianceCheckSnapshot{
+		{ID: uuid.New(), OrganizationID: orgID, Framework: "soc2", RunAt: now.Add(-48 * time.Hour)},
+	}}
+	service := &ComplianceService{agentRepo: mockAgentRepo, orgRepo: mockOrgRepo, snapshotRepo: snapshotRepo}
+
+	stored, err := service.RunScheduledComplianceChecks(context.Background(), now, DefaultComplianceCheckInterval)
+	require.NoError(t, err)
+	assert.Equal(t, len(ComplianceFrameworks)-1, stored)
+
+	frameworks := map[string]int{}
+	for _, snapshot := range snapshotRepo.snapshots[1:] {
+		frameworks[snapshot.Framework]++
+		assert.Equal(t, now, snapshot.RunAt)
+		assert.Positive(t, snapshot.Total)
+		assert.Equal(t, snapshot.Total, snapshot.Passed+snapshot.Failed)
+		assert.Len(t, snapshot.Checks, snapshot.Total)
+	}
+	assert.Equal(t, map[string]int{"iso27001": 1, "hipaa": 1, "gdpr": 1}, frameworks)
+
+	// Nothing is due until the interval has elapsed
+	stored, err = service.RunScheduledComplianceChecks(context.Background(), now.Add(time.Hour), DefaultComplianceCheckInterval)
+	require.NoError(t, err)
+	assert.Zero(t, stored)
+}
+
+func TestComplianceService_GetComplianceHistory(t *testing.T) {
+	orgID := uuid.New()
+	now := time.Date(2025, 3, 10, 9, 0, 0, 0, time.UTC)
+	snapshotRepo := &inMemoryComplianceCheckSnapshotRepository{snapshots: []*domain.ComplianceCheckSnapshot{
+		{OrganizationID: orgID, Framework: "soc2", ComplianceRate: 50, Passed: 5, Failed: 5, Total: 10, RunAt: now.AddDate(0, 0, -120)},
+		{OrganizationID: orgID, Framework: "soc2", ComplianceRate: 70, Passed: 7, Failed: 3, Total: 10, RunAt: now.AddDate(0, 0, -14)},
+		{OrganizationID: orgID, Framework: "gdpr", ComplianceRate: 40, Passed: 4, Failed: 6, Total: 10, RunAt: now.AddDate(0, 0, -7)},
+		{OrganizationID: orgID, Framework: "soc2", ComplianceRate: 90, Passed: 9, Failed: 1, Total: 10, RunAt: now.AddDate(0, 0, -7)},
+		{OrganizationID: uuid.New(), Framework: "soc2", ComplianceRate: 10, Passed: 1, Failed: 9, Total: 10, RunAt: now.AddDate(0, 0, -7)},
+	}}
+	service := &ComplianceService{snapshotRepo: snapshotRepo}
+
+	history, err := service.GetComplianceHistory(context.Background(), orgID, "soc2", now.AddDate(0, 0, -90))
+
+	require.NoError(t, err)
+	assert.Equal(t, []ComplianceScorePoint{
+		{RunAt: now.AddDate(0, 0, -14), ComplianceRate: 70, Passed: 7, Failed: 3, Total: 10},
+		{RunAt: now.AddDate(0, 0, -7), ComplianceRate: 90, Passed: 9, Failed: 1, Total: 10},
+	}, history)
+}
+
+func TestComplianceService_GetComplianceHistory_NoStore(t *testing.T) {
+	service := &ComplianceService{}
+
+	history, err := service.GetComplianceHistory(context.Background(), uuid.New(), "soc2", time.Time{})
+
+	require.NoError(t, err)
+	assert.Empty(t, history)
+}
@@ -0,0 +1,172 @@
+package application
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/go-pdf/fpdf"
+)
+
+// Column layout of the agent table in PDF compliance reports (A4 portrait, 15mm margins)
+var complianceReportPDFColumns = []struct {
+	title string
+	width float64
+	align string
+}{
+	{"Agent", 52, "L"},
+	{"Type", 28, "L"},
+	{"Status", 26, "L"},
+	{"Trust Score", 24, "R"},
+	{"Certificate", 24, "C"},
+	{"Last Verified", 26, "C"},
+}
+
+// WriteComplianceReportPDF renders a compliance report (summary, agent table and recommendations)
+// as a PDF. generatedAt is printed in the header and used as the document's creation date, so the
+// same report and timestamp always produce byte-identical output.
+func WriteComplianceReportPDF(w io.Writer, report *ComplianceReport, generatedAt time.Time) error {
+	pdf := fpdf.New("P", "mm", "A4", "")
+	pdf.SetMargins(15, 15, 15)
+	pdf.SetAutoPageBreak(true, 15)
+	pdf.SetCompression(false)
+	pdf.SetCatalogSort(true)
+	pdf.SetCreationDate(generatedAt)
+	pdf.SetModificationDate(generatedAt)
+	pdf.SetTitle("Compliance Report", true)
+	pdf.SetAuthor("Agent Identity Management", true)
+	pdf.AliasNbPages("")
+	tr := pdf.UnicodeTranslatorFromDescriptor("") // Core fonts are cp1252
+
+	orgLabel := report.OrganizationName
+	if orgLabel == "" {
+		orgLabel = report.OrganizationID
+	}
+
+	pdf.SetFooterFunc(func() {
+		pdf.SetY(-12)
+		pdf.SetFont("Helvetica", "I", 8)
+		pdf.SetTextColor(120, 120, 120)
+		pdf.CellFormat(0, 5, tr(fmt.Sprintf("%s - Compliance Report", orgLabel)), "", 0, "L", false, 0, "")
+		pdf.SetX(15)
+		pdf.CellFormat(0, 5, fmt.Sprintf("Page %d of {nb}", pdf.PageNo()), "", 0, "R", false, 0, "")
+	})
+	pdf.AddPage()
+
+	// Header
+	pdf.SetFont("Helvetica", "B", 18)
+	pdf.SetTextColor(0, 0, 0)
+	pdf.CellFormat(0, 10, "Compliance Report", "", 1, "L", false, 0, "")
+	pdf.SetFont("Helvetica", "", 11)
+	pdf.CellFormat(0, 6, tr(orgLabel), "", 1, "L", false, 0, "")
+	pdf.SetFont("Helvetica", "", 9)
+	pdf.SetTextColor(90, 90, 90)
+	if report.Period != "" {
+		pdf.CellFormat(0, 5, tr("Period: "+report.Period), "", 1, "L", false, 0, "")
+	}
+	pdf.CellFormat(0, 5, "Generated: "+generatedAt.UTC().Format("2006-01-02 15:04 UTC"), "", 1, "L", false, 0, "")
+	pdf.Ln(4)
+
+	// Summary
+	writeComplianceReportPDFHeading(pdf, "Summary")
+	summary := report.Summary
+	summaryRows := [][2]string{
+		{"Total agents", fmt.Sprintf("%d", summary.TotalAgents)},
+		{"Verified agents", fmt.Sprintf("%d", summary.VerifiedAgents)},
+		{"Pending agents", fmt.Sprintf("%d", summary.PendingAgents)},
+		{"Average trust score", fmt.Sprintf("%.1f%%", summary.AverageTrustScore*100)},
+		{"Active API keys", fmt.Sprintf("%d", summary.ActiveAPIKeys)},
+		{"Audit log entries", fmt.Sprintf("%d", summary.TotalAuditLogs)},
+		{"Unacknowledged alerts", fmt.Sprintf("%d", summary.UnacknowledgedAlerts)},
+	}
+	pdf.SetFont("Helvetica", "", 10)
+	for _, row := range summaryRows {
+		pdf.CellFormat(60, 6, row[0], "", 0, "L", false, 0, "")
+		pdf.CellFormat(0, 6, row[1], "", 1, "L", false, 0, "")
+	}
+	pdf.Ln(4)
+
+	// Agent table
+	writeComplianceReportPDFHeading(pdf, "Agents")
+	writeComplianceReportPDFTableHeader(pdf)
+	pdf.SetFont("Helvetica", "", 9)
+	if len(report.Agents) == 0 {
+		pdf.CellFormat(0, 7, "No agents registered.", "1", 1, "L", false, 0, "")
+	}
+	_, pageHeight := pdf.GetPageSize()
+	_, _, _, bottomMargin := pdf.GetMargins()
+	for i, agent := range report.Agents {
+		// Repeat the header when the table continues on a new page
+		if pdf.GetY()+7 > pageHeight-bottomMargin {
+			pdf.AddPage()
+			writeComplianceReportPDFTableHeader(pdf)
+			pdf.SetFont("Helvetica", "", 9)
+		}
+
+		certificate := "No"
+		if agent.HasCertificate {
+			certificate = "Yes"
+		}
+		lastVerified := agent.LastVerified
+		if lastVerified == "" {
+			lastVerified = "-"
+		}
+		cells := []string{
+			truncatePDFText(pdf, tr, agent.Name, complianceReportPDFColumns[0].width-2),
+			agent.Type,
+			agent.Status,
+			fmt.Sprintf("%.1f%%", agent.TrustScore*100),
+			certificate,
+			lastVerified,
+		}
+
+		fill := i%2 == 1
+		pdf.SetFillColor(245, 245, 245)
+		for j, column := range complianceReportPDFColumns {
+			pdf.CellFormat(column.width, 7, cells[j], "1", 0, column.align, fill, 0, "")
+		}
+		pdf.Ln(-1)
+	}
+	pdf.Ln(4)
+
+	// Recommendations
+	writeComplianceReportPDFHeading(pdf, "Recommendations")
+	pdf.SetFont("Helvetica", "", 10)
+	for i, recommendation := range report.Recommendations {
+		pdf.MultiCell(0, 5.5, tr(fmt.Sprintf("%d. %s", i+1, recommendation)), "", "L", false)
+		pdf.Ln(1)
+	}
+
+	if err := pdf.Error(); err != nil {
+		return fmt.Errorf("failed to render compliance report PDF: %w", err)
+	}
+	return pdf.Output(w)
+}
+
+func writeComplianceReportPDFHeading(pdf *fpdf.Fpdf, title string) {
+	pdf.SetFont("Helvetica", "B", 13)
+	pdf.SetTextColor(0, 0, 0)
+	pdf.CellFormat(0, 8, title, "B", 1, "L", false, 0, "")
+	pdf.Ln(2)
+}
+
+func writeComplianceReportPDFTableHeader(pdf *fpdf.Fpdf) {
+	pdf.SetFont("Helvetica", "B", 9)
+	pdf.SetFillColor(220, 225, 235)
+	for _, column := range complianceReportPDFColumns {
+		pdf.CellFormat(column.width, 7, column.title, "1", 0, column.align, true, 0, "")
+	}
+	pdf.Ln(-1)
+}
+
+// truncatePDFText translates text for the current core font, shortening it with an ellipsis so it fits in width
+func truncatePDFText(pdf *fpdf.Fpdf, tr func(string) string, text string, width float64) string {
+	if pdf.GetStringWidth(tr(text)) <= width {
+		return tr(text)
+	}
+	runes := []rune(text)
+	for len(runes) > 0 && pdf.GetStringWidth(tr(string(runes)+"...")) > width {
+		runes = runes[:len(runes)-1]
+	}
+	return tr(string(runes) + "...")
+}
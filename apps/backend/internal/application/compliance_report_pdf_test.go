@@ -0,0 +1,67 @@
+package application
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestComplianceReport(generatedAt time.Time) *ComplianceReport {
+	return &ComplianceReport{
+		OrganizationID:   "6f1c2b9e-8d0a-4c53-9a57-2f1e4b7c9d10",
+		OrganizationName: "Acme Robotics",
+		GeneratedAt:      generatedAt,
+		Period:           "2025-01-01 to 2025-01-31",
+		Summary: ComplianceSummary{
+			TotalAgents:       2,
+			VerifiedAgents:    1,
+			PendingAgents:     1,
+			AverageTrustScore: 0.72,
+			ActiveAPIKeys:     3,
+			TotalAuditLogs:    128,
+		},
+		Agents: []AgentCompliance{
+			{ID: "a1", Name: "billing-assistant", Type: "ai_agent", Status: "verified", TrustScore: 0.91, HasCertificate: true, LastVerified: "2025-01-30"},
+			{ID: "a2", Name: "a-very-long-agent-name-that-does-not-fit-in-its-column", Type: "mcp_server", Status: "pending", TrustScore: 0.53},
+		},
+		Recommendations: []string{"Verify 1 pending agent to improve compliance posture"},
+	}
+}
+
+func TestWriteComplianceReportPDF(t *testing.T) {
+	generatedAt := time.Date(2025, 2, 1, 9, 30, 0, 0, time.UTC)
+	report := newTestComplianceReport(generatedAt)
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteComplianceReportPDF(&buf, report, generatedAt))
+
+	output := buf.Bytes()
+	require.NotEmpty(t, output)
+	assert.True(t, bytes.HasPrefix(output, []byte("%PDF-")))
+	assert.Contains(t, string(output), "Acme Robotics")
+	assert.Contains(t, string(output), "billing-assistant")
+}
+
+func TestWriteComplianceReportPDF_Deterministic(t *testing.T) {
+	generatedAt := time.Date(2025, 2, 1, 9, 30, 0, 0, time.UTC)
+
+	var first, second bytes.Buffer
+	require.NoError(t, WriteComplianceReportPDF(&first, newTestComplianceReport(generatedAt), generatedAt))
+	require.NoError(t, WriteComplianceReportPDF(&second, newTestComplianceReport(generatedAt), generatedAt))
+
+	assert.Equal(t, first.Bytes(), second.Bytes())
+}
+
+func TestWriteComplianceReportPDF_FallsBackToOrganizationID(t *testing.T) {
+	generatedAt := time.Date(2025, 2, 1, 9, 30, 0, 0, time.UTC)
+	report := newTestComplianceReport(generatedAt)
+	report.OrganizationName = ""
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteComplianceReportPDF(&buf, report, generatedAt))
+
+	assert.Contains(t, buf.String(), report.OrganizationID)
+}
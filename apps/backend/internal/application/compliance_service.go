@@ -2,7 +2,11 @@ package application
 
 import (
 	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"time"
 
 	"github.com/google/uuid"
@@ -12,6 +16,7 @@ import (
 // ComplianceReport represents a compliance report
 type ComplianceReport struct {
 	OrganizationID string                 `json:"organization_id"`
+	OrganizationName string               `json:"organization_name,omitempty"`
 	GeneratedAt    time.Time              `json:"generated_at"`
 	Period         string                 `json:"period"`
 	Summary        ComplianceSummary      `json:"summary"`
@@ -49,9 +54,12 @@ type AuditActivitySummary struct {
 
 // ComplianceService handles compliance reporting
 type ComplianceService struct {
-	auditRepo domain.AuditLogRepository
-	agentRepo domain.AgentRepository
-	userRepo  domain.UserRepository
+	auditRepo     domain.AuditLogRepository
+	agentRepo     domain.AgentRepository
+	userRepo      domain.UserRepository
+	orgRepo       domain.OrganizationRepository
+	violationRepo domain.ComplianceViolationRepository
+	snapshotRepo  domain.ComplianceCheckSnapshotRepository
 }
 
 // NewComplianceService creates a new compliance service
@@ -59,11 +67,17 @@ func NewComplianceService(
 	auditRepo domain.AuditLogRepository,
 	agentRepo domain.AgentRepository,
 	userRepo domain.UserRepository,
+	orgRepo domain.OrganizationRepository,
+	violationRepo domain.ComplianceViolationRepository,
+	snapshotRepo domain.ComplianceCheckSnapshotRepository,
 ) *ComplianceService {
 	return &ComplianceService{
-		auditRepo: auditRepo,
-		agentRepo: agentRepo,
-		userRepo:  userRepo,
+		auditRepo:     auditRepo,
+		agentRepo:     agentRepo,
+		userRepo:      userRepo,
+		orgRepo:       orgRepo,
+		violationRepo: violationRepo,
+		snapshotRepo:  snapshotRepo,
 	}
 }
 
@@ -81,6 +95,13 @@ func (s *ComplianceService) GenerateComplianceReport(
 		Period:         fmt.Sprintf("%s to %s", startDate.Format("2006-01-02"), endDate.Format("2006-01-02")),
 	}
 
+	// Organization name is only used for display (e.g. PDF exports), so a lookup failure is not fatal
+	if s.orgRepo != nil {
+		if org, err := s.orgRepo.GetByID(orgID); err == nil && org != nil {
+			report.OrganizationName = org.Name
+		}
+	}
+
 	// Get agents
 	agents, err := s.agentRepo.GetByOrganization(orgID)
 	if err != nil {
@@ -206,53 +227,162 @@ func (s *ComplianceService) generateRecommendations(summary ComplianceSummary, a
 	return recommendations
 }
 
-// ExportAuditLog exports audit logs for compliance
+// auditExportPageSize is how many audit logs are read from the database per page while exporting
+const auditExportPageSize = 1000
+
+// auditExportCSVHeader is the column order of CSV audit log exports
+var auditExportCSVHeader = []string{
+	"id", "timestamp", "user_id", "action", "resource_type", "resource_id", "ip_address", "user_agent", "metadata",
+}
+
+// ExportAuditLog streams audit logs between startDate and endDate to w as JSON or CSV.
+// Logs are read page by page so large exports never sit in memory as a whole; pages are keyed
+// on the last exported log, so logs written during the export neither repeat nor skip rows.
+// JSON is an array of domain.AuditLog; CSV is RFC 4180 with metadata serialized as JSON.
+// Zero dates leave that end of the range open. Returns the number of exported logs.
 func (s *ComplianceService) ExportAuditLog(
 	ctx context.Context,
+	w io.Writer,
 	orgID uuid.UUID,
 	startDate time.Time,
 	endDate time.Time,
 	format string,
-) (string, error) {
-	// Get audit logs for the time period
-	logs, err := s.auditRepo.GetByOrganization(orgID, 10000, 0)
+) (int, error) {
+	exporter, err := newAuditLogExporter(w, format)
 	if err != nil {
-		return "", err
+		return 0, err
+	}
+	if err := exporter.begin(); err != nil {
+		return 0, err
 	}
 
-	// Filter by date range
-	var filteredLogs []*domain.AuditLog
-	for _, log := range logs {
-		if log.Timestamp.After(startDate) && log.Timestamp.Before(endDate) {
-			filteredLogs = append(filteredLogs, log)
+	var filter domain.AuditLogFilter
+	if !startDate.IsZero() {
+		filter.StartDate = &startDate
+	}
+	if !endDate.IsZero() {
+		filter.EndDate = &endDate
+	}
+
+	exported := 0
+	var after *domain.AuditLogCursor
+	for {
+		if err := ctx.Err(); err != nil {
+			return exported, err
 		}
+
+		logs, err := s.auditRepo.SearchAfter(orgID, filter, after, auditExportPageSize)
+		if err != nil {
+			return exported, err
+		}
+
+		for _, log := range logs {
+			if err := exporter.write(log); err != nil {
+				return exported, err
+			}
+			exported++
+		}
+
+		if len(logs) < auditExportPageSize {
+			break
+		}
+		last := logs[len(logs)-1]
+		after = &domain.AuditLogCursor{Timestamp: last.Timestamp, ID: last.ID}
 	}
 
-	// Export based on format
+	return exported, exporter.end()
+}
+
+// auditLogExporter writes audit logs in a specific export format
+type auditLogExporter interface {
+	begin() error
+	write(log *domain.AuditLog) error
+	end() error
+}
+
+// newAuditLogExporter returns the exporter for format, "json" or "csv"
+func newAuditLogExporter(w io.Writer, format string) (auditLogExporter, error) {
 	switch format {
 	case "json":
-		// Simple JSON export - in production would use json.Marshal
-		return fmt.Sprintf(`{"organization_id": "%s", "total_logs": %d, "start_date": "%s", "end_date": "%s"}`,
-			orgID.String(), len(filteredLogs), startDate.Format(time.RFC3339), endDate.Format(time.RFC3339)), nil
+		return &jsonAuditLogExporter{w: w}, nil
 	case "csv":
-		// Simple CSV export
-		csv := "timestamp,user_id,action,resource_type,resource_id,ip_address\n"
-		for _, log := range filteredLogs {
-			csv += fmt.Sprintf("%s,%s,%s,%s,%s,%s\n",
-				log.Timestamp.Format(time.RFC3339),
-				log.UserID.String(),
-				log.Action,
-				log.ResourceType,
-				log.ResourceID.String(),
-				log.IPAddress,
-			)
-		}
-		return csv, nil
+		return &csvAuditLogExporter{w: csv.NewWriter(w)}, nil
 	default:
-		return "", fmt.Errorf("unsupported export format: %s", format)
+		return nil, fmt.Errorf("unsupported export format: %s", format)
 	}
 }
 
+// jsonAuditLogExporter writes a JSON array of audit logs, one element at a time
+type jsonAuditLogExporter struct {
+	w       io.Writer
+	written bool
+}
+
+func (e *jsonAuditLogExporter) begin() error {
+	_, err := io.WriteString(e.w, "[")
+	return err
+}
+
+func (e *jsonAuditLogExporter) write(log *domain.AuditLog) error {
+	data, err := json.Marshal(log)
+	if err != nil {
+		return fmt.Errorf("failed to marshal audit log %s: %w", log.ID, err)
+	}
+
+	if e.written {
+		if _, err := io.WriteString(e.w, ","); err != nil {
+			return err
+		}
+	}
+	e.written = true
+
+	_, err = e.w.Write(data)
+	return err
+}
+
+func (e *jsonAuditLogExporter) end() error {
+	_, err := io.WriteString(e.w, "]\n")
+	return err
+}
+
+// csvAuditLogExporter writes RFC 4180 CSV (fields with commas, quotes or newlines are quoted)
+type csvAuditLogExporter struct {
+	w *csv.Writer
+}
+
+func (e *csvAuditLogExporter) begin() error {
+	return e.w.Write(auditExportCSVHeader)
+}
+
+func (e *csvAuditLogExporter) write(log *domain.AuditLog) error {
+	metadata := ""
+	if len(log.Metadata) > 0 {
+		data, err := json.Marshal(log.Metadata)
+		if err != nil {
+			return fmt.Errorf("failed to marshal metadata for audit log %s: %w", log.ID, err)
+		}
+		metadata = string(data)
+	}
+
+	// csv.Writer buffers internally and writes through to w as its buffer fills
+	return e.w.Write([]string{
+		log.ID.String(),
+		log.Timestamp.Format(time.RFC3339),
+		log.UserID.String(),
+		string(log.Action),
+		log.ResourceType,
+		log.ResourceID.String(),
+		log.IPAddress,
+		log.UserAgent,
+		metadata,
+	})
+}
+
+func (e *csvAuditLogExporter) end() error {
+	e.w.Flush()
+	return e.w.Error()
+}
+
 // GetComplianceStatus returns current compliance status
 func (s *ComplianceService) GetComplianceStatus(ctx context.Context, orgID uuid.UUID) (interface{}, error) {
 	// Get agents
@@ -1194,106 +1324,182 @@ func (s *ComplianceService) evaluateCheck(checkName string, agents []*domain.Age
 	}
 }
 
-// ComplianceViolation represents a compliance violation
-type ComplianceViolation struct {
-	ID               uuid.UUID `json:"id"`
-	OrganizationID   uuid.UUID `json:"organization_id"`
-	Framework        string    `json:"framework"`
-	Severity         string    `json:"severity"`
-	Title            string    `json:"title"`
-	Description      string    `json:"description"`
-	ResourceType     string    `json:"resource_type"`
-	ResourceID       uuid.UUID `json:"resource_id"`
-	IsRemediated     bool      `json:"is_remediated"`
-	RemediatedBy     *uuid.UUID `json:"remediated_by"`
-	RemediatedAt     *time.Time `json:"remediated_at"`
-	RemediationNotes string    `json:"remediation_notes"`
-	DetectedAt       time.Time `json:"detected_at"`
-}
+// Checks that raise compliance violations; stored as ComplianceViolation.CheckKey
+const (
+	complianceCheckUnverifiedAgent = "unverified_agent"
+	complianceCheckLowTrustScore   = "low_trust_score"
+)
+
+// complianceMinTrustScore is the agent trust score below which low_trust_score fires
+const complianceMinTrustScore = 0.5
+
+// ErrComplianceViolationNotFound is returned when a violation does not exist in the organization
+var ErrComplianceViolationNotFound = errors.New("compliance violation not found")
 
-// GetComplianceViolations retrieves compliance violations
+// GetComplianceViolations returns the organization's stored violations together with any newly
+// detected ones. New violations are persisted, so remediation state sticks across calls while the
+// condition keeps firing. A remediated violation whose condition clears and later fires again is
+// reopened.
 func (s *ComplianceService) GetComplianceViolations(
 	ctx context.Context,
 	orgID uuid.UUID,
 	frameworkFilter string,
 	severityFilter string,
-) ([]*ComplianceViolation, error) {
-	// For MVP, generate sample violations based on current state
-	agents, err := s.agentRepo.GetByOrganization(orgID)
+) ([]*domain.ComplianceViolation, error) {
+	now := time.Now()
+	detected, err := s.detectComplianceViolations(orgID, now)
 	if err != nil {
 		return nil, err
 	}
 
-	var violations []*ComplianceViolation
+	allViolations := detected
+	if s.violationRepo != nil {
+		stored, err := s.violationRepo.GetByOrganization(orgID)
+		if err != nil {
+			return nil, err
+		}
 
-	// Check for unverified agents (compliance violation)
+		firing := make(map[string]bool, len(detected))
+		for _, violation := range detected {
+			firing[complianceViolationKey(violation)] = true
+		}
+
+		known := make(map[string]bool, len(stored))
+		for _, violation := range stored {
+			key := complianceViolationKey(violation)
+			known[key] = true
+			if err := s.updateViolationRecurrence(violation, firing[key], now); err != nil {
+				return nil, err
+			}
+		}
+
+		var newViolations []*domain.ComplianceViolation
+		for _, violation := range detected {
+			if known[complianceViolationKey(violation)] {
+				continue
+			}
+			if err := s.violationRepo.Create(violation); err != nil {
+				return nil, fmt.Errorf("failed to store compliance violation: %w", err)
+			}
+			newViolations = append(newViolations, violation)
+		}
+		allViolations = append(newViolations, stored...)
+	}
+
+	var violations []*domain.ComplianceViolation
+	for _, violation := range allViolations {
+		// Apply filters
+		if frameworkFilter != "" && violation.Framework != frameworkFilter {
+			continue
+		}
+		if severityFilter != "" && violation.Severity != severityFilter {
+			continue
+		}
+		violations = append(violations, violation)
+	}
+
+	return violations, nil
+}
+
+// updateViolationRecurrence marks a stored violation cleared once its condition stops firing, and
+// reopens it when the condition fires again after clearing
+func (s *ComplianceService) updateViolationRecurrence(violation *domain.ComplianceViolation, firing bool, now time.Time) error {
+	switch {
+	case !firing && violation.ClearedAt == nil:
+		if err := s.violationRepo.MarkCleared(violation.ID, now); err != nil {
+			return fmt.Errorf("failed to mark compliance violation cleared: %w", err)
+		}
+		violation.ClearedAt = &now
+	case firing && violation.ClearedAt != nil:
+		if err := s.violationRepo.Reopen(violation.ID, now); err != nil {
+			return fmt.Errorf("failed to reopen compliance violation: %w", err)
+		}
+		violation.IsRemediated = false
+		violation.RemediatedBy = nil
+		violation.RemediatedAt = nil
+		violation.RemediationNotes = ""
+		violation.ClearedAt = nil
+		violation.DetectedAt = now
+	}
+	return nil
+}
+
+// detectComplianceViolations evaluates the organization's agents against the violation checks
+func (s *ComplianceService) detectComplianceViolations(orgID uuid.UUID, now time.Time) ([]*domain.ComplianceViolation, error) {
+	agents, err := s.agentRepo.GetByOrganization(orgID)
+	if err != nil {
+		return nil, err
+	}
+
+	var violations []*domain.ComplianceViolation
 	for _, agent := range agents {
+		// Check for unverified agents (compliance violation)
 		if agent.Status != domain.AgentStatusVerified {
-			violation := &ComplianceViolation{
+			violations = append(violations, &domain.ComplianceViolation{
 				ID:             uuid.New(),
 				OrganizationID: orgID,
+				CheckKey:       complianceCheckUnverifiedAgent,
 				Framework:      "soc2",
 				Severity:       "high",
 				Title:          fmt.Sprintf("Unverified Agent: %s", agent.Name),
 				Description:    "Agent has not been verified, which violates SOC2 trust services criteria",
 				ResourceType:   "agent",
 				ResourceID:     agent.ID,
-				IsRemediated:   false,
-				DetectedAt:     time.Now(),
-			}
-
-			// Apply filters
-			if frameworkFilter != "" && violation.Framework != frameworkFilter {
-				continue
-			}
-			if severityFilter != "" && violation.Severity != severityFilter {
-				continue
-			}
-
-			violations = append(violations, violation)
+				DetectedAt:     now,
+			})
 		}
 
-		// Check for low trust scores
-		if agent.TrustScore < 50 {
-			violation := &ComplianceViolation{
+		// Check for low trust scores (0-1 scale)
+		if agent.TrustScore < complianceMinTrustScore {
+			violations = append(violations, &domain.ComplianceViolation{
 				ID:             uuid.New(),
 				OrganizationID: orgID,
+				CheckKey:       complianceCheckLowTrustScore,
 				Framework:      "iso27001",
 				Severity:       "critical",
 				Title:          fmt.Sprintf("Low Trust Score: %s", agent.Name),
 				Description:    fmt.Sprintf("Agent trust score (%.2f) is below acceptable threshold", agent.TrustScore),
 				ResourceType:   "agent",
 				ResourceID:     agent.ID,
-				IsRemediated:   false,
-				DetectedAt:     time.Now(),
-			}
-
-			// Apply filters
-			if frameworkFilter != "" && violation.Framework != frameworkFilter {
-				continue
-			}
-			if severityFilter != "" && violation.Severity != severityFilter {
-				continue
-			}
-
-			violations = append(violations, violation)
+				DetectedAt:     now,
+			})
 		}
 	}
 
 	return violations, nil
 }
 
+// complianceViolationKey identifies a violation by the check that raised it and the affected resource
+func complianceViolationKey(violation *domain.ComplianceViolation) string {
+	return violation.CheckKey + "|" + violation.ResourceType + "|" + violation.ResourceID.String()
+}
+
 // RemediateViolation marks a compliance violation as remediated
 func (s *ComplianceService) RemediateViolation(
 	ctx context.Context,
+	orgID uuid.UUID,
 	violationID uuid.UUID,
 	remediatedBy uuid.UUID,
 	notes string,
 	remediationDate time.Time,
 ) error {
-	// For MVP, this would just log the remediation
-	// In production, would update the violation in the database
-	return nil
+	if s.violationRepo == nil {
+		return fmt.Errorf("compliance violation storage is not configured")
+	}
+
+	violation, err := s.violationRepo.GetByID(violationID)
+	if err != nil {
+		return err
+	}
+	if violation == nil || violation.OrganizationID != orgID {
+		return ErrComplianceViolationNotFound
+	}
+
+	if remediationDate.IsZero() {
+		remediationDate = time.Now()
+	}
+
+	return s.violationRepo.Remediate(violationID, remediatedBy, notes, remediationDate)
 }
 
 // ComplianceReportSummary represents a summary of compliance reports
@@ -1444,11 +1650,18 @@ func (s *ComplianceService) GetDataRetentionPolicies(
 	ctx context.Context,
 	orgID uuid.UUID,
 ) (map[string]interface{}, error) {
+	retention := domain.DefaultDataRetentionPolicy()
+	if s.orgRepo != nil {
+		if org, err := s.orgRepo.GetByID(orgID); err == nil {
+			retention = org.EffectiveRetentionPolicy()
+		}
+	}
+
 	policy := DataRetentionPolicy{
-		AuditLogRetentionDays:          365, // 1 year
-		VerificationEventRetentionDays: 90,  // 3 months
-		AlertRetentionDays:             180, // 6 months
-		InactiveAgentRetentionDays:     730, // 2 years
+		AuditLogRetentionDays:          retention.AuditLogDays,
+		VerificationEventRetentionDays: retention.VerificationEventDays,
+		AlertRetentionDays:             retention.ResolvedAlertDays,
+		InactiveAgentRetentionDays:     730, // 2 years (reviewed, not purged)
 		LastUpdated:                    time.Now().AddDate(0, -1, 0).Format("2006-01-02"),
 		EnforcementStatus:              "active",
 	}
@@ -0,0 +1,356 @@
+package application
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func createTestAuditLogsForExport(orgID uuid.UUID, now time.Time) []*domain.AuditLog {
+	return []*domain.AuditLog{
+		{
+			ID:             uuid.New(),
+			OrganizationID: orgID,
+			UserID:         uuid.New(),
+			Action:         domain.AuditActionUpdate,
+			ResourceType:   "agent",
+			ResourceID:     uuid.New(),
+			IPAddress:      "10.0.0.1",
+			UserAgent:      "Mozilla/5.0 (X11, Linux)",
+			Metadata: map[string]interface{}{
+				"reason": `renamed "billing, prod" agent`,
+				"note":   "line one\nline two",
+			},
+			Timestamp: now.Add(-1 * time.Hour),
+		},
+		{
+			ID:             uuid.New(),
+			OrganizationID: orgID,
+			UserID:         uuid.New(),
+			Action:         domain.AuditActionCreate,
+			ResourceType:   "api_key",
+			ResourceID:     uuid.New(),
+			IPAddress:      "10.0.0.2",
+			Timestamp:      now.Add(-2 * time.Hour),
+		},
+		{
+			ID:             uuid.New(),
+			OrganizationID: orgID,
+			UserID:         uuid.New(),
+			Action:         domain.AuditActionDelete,
+			ResourceType:   "agent",
+			ResourceID:     uuid.New(),
+			Timestamp:      now.Add(-72 * time.Hour),
+		},
+	}
+}
+
+func TestComplianceService_ExportAuditLog_CSVEscapesMetadata(t *testing.T) {
+	orgID := uuid.New()
+	now := time.Now().UTC().Truncate(time.Second)
+	logs := createTestAuditLogsForExport(orgID, now)
+
+	start := now.Add(-24 * time.Hour)
+	filter := domain.AuditLogFilter{StartDate: &start, EndDate: &now}
+	mockAuditRepo := new(AgentServiceMockAuditLogRepository)
+	mockAuditRepo.On("SearchAfter", orgID, filter, (*domain.AuditLogCursor)(nil), auditExportPageSize).Return(logs[:2], nil)
+	service := &ComplianceService{auditRepo: mockAuditRepo}
+
+	var buf bytes.Buffer
+	count, err := service.ExportAuditLog(context.Background(), &buf, orgID, start, now, "csv")
+
+	require.NoError(t, err)
+	assert.Equal(t, 2, count)
+
+	records, err := csv.NewReader(&buf).ReadAll()
+	require.NoError(t, err, "export must be valid RFC 4180 CSV")
+	require.Len(t, records, 3)
+	assert.Equal(t, auditExportCSVHeader, records[0])
+
+	row := records[1]
+	assert.Len(t, row, len(auditExportCSVHeader))
+	assert.Equal(t, logs[0].ID.String(), row[0])
+	assert.Equal(t, "Mozilla/5.0 (X11, Linux)", row[7])
+
+	var metadata map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(row[8]), &metadata))
+	assert.Equal(t, `renamed "billing, prod" agent`, metadata["reason"])
+	assert.Equal(t, "line one\nline two", metadata["note"])
+
+	assert.Equal(t, "", records[2][8], "logs without metadata export an empty field")
+}
+
+func TestComplianceService_ExportAuditLog_JSONIncludesMetadata(t *testing.T) {
+	orgID := uuid.New()
+	now := time.Now().UTC().Truncate(time.Second)
+	logs := createTestAuditLogsForExport(orgID, now)
+
+	start := now.Add(-24 * time.Hour)
+	filter := domain.AuditLogFilter{StartDate: &start, EndDate: &now}
+	mockAuditRepo := new(AgentServiceMockAuditLogRepository)
+	mockAuditRepo.On("SearchAfter", orgID, filter, (*domain.AuditLogCursor)(nil), auditExportPageSize).Return(logs[:2], nil)
+	service := &ComplianceService{auditRepo: mockAuditRepo}
+
+	var buf bytes.Buffer
+	count, err := service.ExportAuditLog(context.Background(), &buf, orgID, start, now, "json")
+
+	require.NoError(t, err)
+	assert.Equal(t, 2, count)
+
+	var exported []*domain.AuditLog
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &exported))
+	require.Len(t, exported, 2)
+	assert.Equal(t, logs[0].ID, exported[0].ID)
+	assert.Equal(t, `renamed "billing, prod" agent`, exported[0].Metadata["reason"])
+	assert.Equal(t, logs[1].ID, exported[1].ID)
+}
+
+func TestComplianceService_ExportAuditLog_EmptyJSON(t *testing.T) {
+	orgID := uuid.New()
+
+	mockAuditRepo := new(AgentServiceMockAuditLogRepository)
+	mockAuditRepo.On("SearchAfter", orgID, domain.AuditLogFilter{}, (*domain.AuditLogCursor)(nil), auditExportPageSize).Return([]*domain.AuditLog{}, nil)
+	service := &ComplianceService{auditRepo: mockAuditRepo}
+
+	var buf bytes.Buffer
+	count, err := service.ExportAuditLog(context.Background(), &buf, orgID, time.Time{}, time.Time{}, "json")
+
+	require.NoError(t, err)
+	assert.Equal(t, 0, count)
+	assert.JSONEq(t, "[]", buf.String())
+}
+
+func TestComplianceService_ExportAuditLog_PagesThroughLogs(t *testing.T) {
+	orgID := uuid.New()
+	now := time.Now().UTC()
+
+	firstPage := make([]*domain.AuditLog, auditExportPageSize)
+	for i := range firstPage {
+		firstPage[i] = &domain.AuditLog{ID: uuid.New(), Action: domain.AuditActionView, Timestamp: now.Add(-time.Duration(i+1) * time.Second)}
+	}
+	secondPage := []*domain.AuditLog{
+		{ID: uuid.New(), Action: domain.AuditActionView, Timestamp: now.Add(-2 * time.Hour)},
+	}
+
+	// The second page is keyed on the last log of the first, not on an offset
+	last := firstPage[len(firstPage)-1]
+	cursor := &domain.AuditLogCursor{Timestamp: last.Timestamp, ID: last.ID}
+	mockAuditRepo := new(AgentServiceMockAuditLogRepository)
+	mockAuditRepo.On("SearchAfter", orgID, domain.AuditLogFilter{}, (*domain.AuditLogCursor)(nil), auditExportPageSize).Return(firstPage, nil)
+	mockAuditRepo.On("SearchAfter", orgID, domain.AuditLogFilter{}, cursor, auditExportPageSize).Return(secondPage, nil)
+	service := &ComplianceService{auditRepo: mockAuditRepo}
+
+	var buf bytes.Buffer
+	count, err := service.ExportAuditLog(context.Background(), &buf, orgID, time.Time{}, time.Time{}, "csv")
+
+	require.NoError(t, err)
+	assert.Equal(t, auditExportPageSize+1, count)
+	mockAuditRepo.AssertExpectations(t)
+}
+
+func TestComplianceService_ExportAuditLog_UnsupportedFormat(t *testing.T) {
+	service := &ComplianceService{}
+
+	_, err := service.ExportAuditLog(context.Background(), &bytes.Buffer{}, uuid.New(), time.Time{}, time.Time{}, "xml")
+
+	assert.Error(t, err)
+}
+
+// inMemoryComplianceViolationRepository keeps violations across calls so remediation state can be asserted
+type inMemoryComplianceViolationRepository struct {
+	violations []*domain.ComplianceViolation
+}
+
+func (r *inMemoryComplianceViolationRepository) Create(violation *domain.ComplianceViolation) error {
+	stored := *violation
+	r.violations = append(r.violations, &stored)
+	return nil
+}
+
+func (r *inMemoryComplianceViolationRepository) GetByID(id uuid.UUID) (*domain.ComplianceViolation, error) {
+	for _, violation := range r.violations {
+		if violation.ID == id {
+			stored := *violation
+			return &stored, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *inMemoryComplianceViolationRepository) GetByOrganization(orgID uuid.UUID) ([]*domain.ComplianceViolation, error) {
+	var violations []*domain.ComplianceViolation
+	for _, violation := range r.violations {
+		if violation.OrganizationID == orgID {
+			stored := *violation
+			violations = append(violations, &stored)
+		}
+	}
+	return violations, nil
+}
+
+func (r *inMemoryComplianceViolationRepository) Remediate(id uuid.UUID, remediatedBy uuid.UUID, notes string, remediatedAt time.Time) error {
+	for _, violation := range r.violations {
+		if violation.ID == id {
+			violation.IsRemediated = true
+			violation.RemediatedBy = &remediatedBy
+			violation.RemediatedAt = &remediatedAt
+			violation.RemediationNotes = notes
+			return nil
+		}
+	}
+	return errors.New("compliance violation not found")
+}
+
+func (r *inMemoryComplianceViolationRepository) MarkCleared(id uuid.UUID, at time.Time) error {
+	for _, violation := range r.violations {
+		if violation.ID == id {
+			violation.ClearedAt = &at
+		}
+	}
+	return nil
+}
+
+func (r *inMemoryComplianceViolationRepository) Reopen(id uuid.UUID, detectedAt time.Time) error {
+	for _, violation := range r.violations {
+		if violation.ID == id {
+			violation.IsRemediated = false
+			violation.RemediatedBy = nil
+			violation.RemediatedAt = nil
+			violation.RemediationNotes = ""
+			violation.ClearedAt = nil
+			violation.DetectedAt = detectedAt
+		}
+	}
+	return nil
+}
+
+func TestComplianceService_RemediatedViolationStaysRemediated(t *testing.T) {
+	orgID := uuid.New()
+	userID := uuid.New()
+	agent := &domain.Agent{ID: uuid.New(), OrganizationID: orgID, Name: "billing-agent", Status: domain.AgentStatusPending, TrustScore: 0.8}
+
+	mockAgentRepo := new(MockAgentRepository)
+	mockAgentRepo.On("GetByOrganization", orgID).Return([]*domain.Agent{agent}, nil)
+	violationRepo := &inMemoryComplianceViolationRepository{}
+	service := &ComplianceService{agentRepo: mockAgentRepo, violationRepo: violationRepo}
+
+	violations, err := service.GetComplianceViolations(context.Background(), orgID, "", "")
+	require.NoError(t, err)
+	require.Len(t, violations, 1)
+	violation := violations[0]
+	assert.Equal(t, complianceCheckUnverifiedAgent, violation.CheckKey)
+	assert.False(t, violation.IsRemediated)
+
+	remediatedAt := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	require.NoError(t, service.RemediateViolation(context.Background(), orgID, violation.ID, userID, "Agent verified manually", remediatedAt))
+
+	// The agent is still unverified, so the check fires again; the stored violation must be reused
+	for i := 0; i < 2; i++ {
+		violations, err = service.GetComplianceViolations(context.Background(), orgID, "", "")
+		require.NoError(t, err)
+		require.Len(t, violations, 1)
+		assert.Equal(t, violation.ID, violations[0].ID)
+		assert.True(t, violations[0].IsRemediated)
+		require.NotNil(t, violations[0].RemediatedBy)
+		assert.Equal(t, userID, *violations[0].RemediatedBy)
+		require.NotNil(t, violations[0].RemediatedAt)
+		assert.True(t, remediatedAt.Equal(*violations[0].RemediatedAt))
+		assert.Equal(t, "Agent verified manually", violations[0].RemediationNotes)
+	}
+	assert.Len(t, violationRepo.violations, 1)
+}
+
+func TestComplianceService_GetComplianceViolations_Filters(t *testing.T) {
+	orgID := uuid.New()
+	agent := &domain.Agent{ID: uuid.New(), OrganizationID: orgID, Name: "risky-agent", Status: domain.AgentStatusPending, TrustScore: 0.2}
+
+	mockAgentRepo := new(MockAgentRepository)
+	mockAgentRepo.On("GetByOrganization", orgID).Return([]*domain.Agent{agent}, nil)
+	service := &ComplianceService{agentRepo: mockAgentRepo, violationRepo: &inMemoryComplianceViolationRepository{}}
+
+	violations, err := service.GetComplianceViolations(context.Background(), orgID, "iso27001", "")
+	require.NoError(t, err)
+	require.Len(t, violations, 1)
+	assert.Equal(t, complianceCheckLowTrustScore, violations[0].CheckKey)
+
+	violations, err = service.GetComplianceViolations(context.Background(), orgID, "", "")
+	require.NoError(t, err)
+	assert.Len(t, violations, 2)
+}
+
+func TestComplianceService_LowTrustScoreUsesUnitScale(t *testing.T) {
+	orgID := uuid.New()
+	agents := []*domain.Agent{
+		{ID: uuid.New(), OrganizationID: orgID, Name: "trusted-agent", Status: domain.AgentStatusVerified, TrustScore: 0.75},
+		{ID: uuid.New(), OrganizationID: orgID, Name: "low-trust-agent", Status: domain.AgentStatusVerified, TrustScore: 0.45},
+	}
+
+	mockAgentRepo := new(MockAgentRepository)
+	mockAgentRepo.On("GetByOrganization", orgID).Return(agents, nil)
+	service := &ComplianceService{agentRepo: mockAgentRepo}
+
+	violations, err := service.GetComplianceViolations(context.Background(), orgID, "", "")
+	require.NoError(t, err)
+	require.Len(t, violations, 1)
+	assert.Equal(t, complianceCheckLowTrustScore, violations[0].CheckKey)
+	assert.Equal(t, agents[1].ID, violations[0].ResourceID)
+}
+
+func TestComplianceService_RemediatedViolationReopensWhenConditionRecurs(t *testing.T) {
+	orgID := uuid.New()
+	agent := &domain.Agent{ID: uuid.New(), OrganizationID: orgID, Name: "billing-agent", Status: domain.AgentStatusPending, TrustScore: 0.8}
+
+	mockAgentRepo := new(MockAgentRepository)
+	mockAgentRepo.On("GetByOrganization", orgID).Return([]*domain.Agent{agent}, nil)
+	violationRepo := &inMemoryComplianceViolationRepository{}
+	service := &ComplianceService{agentRepo: mockAgentRepo, violationRepo: violationRepo}
+
+	violations, err := service.GetComplianceViolations(context.Background(), orgID, "", "")
+	require.NoError(t, err)
+	require.Len(t, violations, 1)
+	violationID := violations[0].ID
+	require.NoError(t, service.RemediateViolation(context.Background(), orgID, violationID, uuid.New(), "Agent verified", time.Time{}))
+
+	// The agent is verified: the condition clears and the violation stays remediated
+	agent.Status = domain.AgentStatusVerified
+	violations, err = service.GetComplianceViolations(context.Background(), orgID, "", "")
+	require.NoError(t, err)
+	require.Len(t, violations, 1)
+	assert.True(t, violations[0].IsRemediated)
+	assert.NotNil(t, violations[0].ClearedAt)
+
+	// The agent is suspended later, so the same violation is reopened
+	agent.Status = domain.AgentStatusSuspended
+	violations, err = service.GetComplianceViolations(context.Background(), orgID, "", "")
+	require.NoError(t, err)
+	require.Len(t, violations, 1)
+	assert.Equal(t, violationID, violations[0].ID)
+	assert.False(t, violations[0].IsRemediated)
+	assert.Nil(t, violations[0].RemediatedBy)
+	assert.Nil(t, violations[0].ClearedAt)
+	assert.Len(t, violationRepo.violations, 1)
+}
+
+func TestComplianceService_RemediateViolation_NotFound(t *testing.T) {
+	orgID := uuid.New()
+	otherOrgViolation := &domain.ComplianceViolation{ID: uuid.New(), OrganizationID: uuid.New()}
+	service := &ComplianceService{violationRepo: &inMemoryComplianceViolationRepository{
+		violations: []*domain.ComplianceViolation{otherOrgViolation},
+	}}
+
+	err := service.RemediateViolation(context.Background(), orgID, uuid.New(), uuid.New(), "", time.Time{})
+	assert.ErrorIs(t, err, ErrComplianceViolationNotFound)
+
+	err = service.RemediateViolation(context.Background(), orgID, otherOrgViolation.ID, uuid.New(), "", time.Time{})
+	assert.ErrorIs(t, err, ErrComplianceViolationNotFound)
+	assert.False(t, otherOrgViolation.IsRemediated)
+}
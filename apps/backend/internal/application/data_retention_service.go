@@ -0,0 +1,227 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/domain"
+	"github.com/opena2a/identity/backend/internal/infrastructure/logging"
+)
+
+// Data retention is enforced by cmd/retention. Setting DATA_RETENTION_INTERVAL (Go duration)
+// additionally runs it inside the server on that interval. Rows are deleted in batches of
+// DefaultDataRetentionBatchSize so no purge holds locks for long.
+const (
+	DefaultDataRetentionBatchSize = 1000
+)
+
+// DataRetentionIntervalFromEnv returns how often the server enforces data retention, or 0 if the
+// in-server scheduler is disabled
+func DataRetentionIntervalFromEnv() time.Duration {
+	if value, err := time.ParseDuration(os.Getenv("DATA_RETENTION_INTERVAL")); err == nil && value > 0 {
+		return value
+	}
+	return 0
+}
+
+// DataRetentionSummary reports what one retention run deleted for an organization
+type DataRetentionSummary struct {
+	OrganizationID           uuid.UUID                  `json:"organization_id"`
+	Policy                   domain.DataRetentionPolicy `json:"policy"`
+	AuditLogsPurged          int64                      `json:"audit_logs_purged"`
+	VerificationEventsPurged int64                      `json:"verification_events_purged"`
+	ResolvedAlertsPurged     int64                      `json:"resolved_alerts_purged"`
+}
+
+// Total returns the number of rows deleted
+func (s *DataRetentionSummary) Total() int64 {
+	return s.AuditLogsPurged + s.VerificationEventsPurged + s.ResolvedAlertsPurged
+}
+
+// DataRetentionService deletes audit logs, verification events and resolved alerts that are older
+// than the organization's retention policy
+type DataRetentionService struct {
+	retentionRepo domain.DataRetentionRepository
+	orgRepo       domain.OrganizationRepository
+	userRepo      domain.UserRepository
+	auditRepo     domain.AuditLogRepository
+	batchSize     int
+}
+
+// NewDataRetentionService creates a new data retention service. batchSize <= 0 uses
+// DefaultDataRetentionBatchSize. userRepo and auditRepo are used to record a summary audit entry
+// per organization; both may be nil.
+func NewDataRetentionService(
+	retentionRepo domain.DataRetentionRepository,
+	orgRepo domain.OrganizationRepository,
+	userRepo domain.UserRepository,
+	auditRepo domain.AuditLogRepository,
+	batchSize int,
+) *DataRetentionService {
+	if batchSize <= 0 {
+		batchSize = DefaultDataRetentionBatchSize
+	}
+	return &DataRetentionService{
+		retentionRepo: retentionRepo,
+		orgRepo:       orgRepo,
+		userRepo:      userRepo,
+		auditRepo:     auditRepo,
+		batchSize:     batchSize,
+	}
+}
+
+// StartRetentionScheduler runs EnforceRetention every interval until ctx is cancelled
+func (s *DataRetentionService) StartRetentionScheduler(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := s.EnforceRetention(ctx, time.Now()); err != nil {
+			logging.FromContext(ctx).Warn("data retention enforcement failed", "error", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// EnforceRetention purges expired data of every active organization. An organization that fails
+// does not stop the others; all failures are returned together.
+func (s *DataRetentionService) EnforceRetention(ctx context.Context, now time.Time) ([]*DataRetentionSummary, error) {
+	orgs, err := s.orgRepo.ListActive()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list organizations: %w", err)
+	}
+
+	var summaries []*DataRetentionSummary
+	var errs []error
+	for _, org := range orgs {
+		summary, err := s.EnforceOrganizationRetention(ctx, org, now)
+		if summary != nil {
+			summaries = append(summaries, summary)
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("organization %s: %w", org.ID, err))
+		}
+	}
+	return summaries, errors.Join(errs...)
+}
+
+// EnforceOrganizationRetention purges the organization's data that is older than its retention
+// policy and records a summary audit entry if anything was deleted. An organization whose policy
+// is invalid is skipped without purging anything. On error the summary still reports what was
+// deleted before the failure.
+func (s *DataRetentionService) EnforceOrganizationRetention(ctx context.Context, org *domain.Organization, now time.Time) (*DataRetentionSummary, error) {
+	policy := org.EffectiveRetentionPolicy()
+	if err := policy.Validate(); err != nil {
+		return nil, fmt.Errorf("skipping invalid retention policy: %w", err)
+	}
+	summary := &DataRetentionSummary{OrganizationID: org.ID, Policy: policy}
+
+	purges := []struct {
+		purge  func(uuid.UUID, time.Time, int) (int64, error)
+		days   int
+		purged *int64
+	}{
+		{s.retentionRepo.PurgeAuditLogs, policy.AuditLogDays, &summary.AuditLogsPurged},
+		{s.retentionRepo.PurgeVerificationEvents, policy.VerificationEventDays, &summary.VerificationEventsPurged},
+		{s.retentionRepo.PurgeResolvedAlerts, policy.ResolvedAlertDays, &summary.ResolvedAlertsPurged},
+	}
+
+	var purgeErr error
+	for _, p := range purges {
+		purged, err := s.purgeInBatches(ctx, p.purge, org.ID, now.AddDate(0, 0, -p.days))
+		*p.purged = purged
+		if err != nil {
+			purgeErr = err
+			break
+		}
+	}
+
+	if summary.Total() > 0 {
+		s.recordSummary(ctx, summary, now)
+	}
+	return summary, purgeErr
+}
+
+// purgeInBatches calls purge until a batch comes back short, so each DELETE stays small
+func (s *DataRetentionService) purgeInBatches(
+	ctx context.Context,
+	purge func(uuid.UUID, time.Time, int) (int64, error),
+	orgID uuid.UUID,
+	before time.Time,
+) (int64, error) {
+	var total int64
+	for {
+		if err := ctx.Err(); err != nil {
+			return total, err
+		}
+		deleted, err := purge(orgID, before, s.batchSize)
+		if err != nil {
+			return total, err
+		}
+		total += deleted
+		if deleted < int64(s.batchSize) {
+			return total, nil
+		}
+	}
+}
+
+// recordSummary writes an audit entry describing the purge. Audit entries need a user, so the
+// entry is attributed to the organization's longest-standing active admin.
+func (s *DataRetentionService) recordSummary(ctx context.Context, summary *DataRetentionSummary, now time.Time) {
+	logger := logging.FromContext(ctx)
+	logger.Info("data retention enforced",
+		"organization_id", summary.OrganizationID,
+		"audit_logs_purged", summary.AuditLogsPurged,
+		"verification_events_purged", summary.VerificationEventsPurged,
+		"resolved_alerts_purged", summary.ResolvedAlertsPurged)
+
+	if s.auditRepo == nil || s.userRepo == nil {
+		return
+	}
+	users, err := s.userRepo.GetByOrganization(summary.OrganizationID)
+	if err != nil {
+		logger.Warn("failed to load users for data retention audit", "organization_id", summary.OrganizationID, "error", err)
+		return
+	}
+	var admin *domain.User
+	for _, user := range users {
+		if user.Role == domain.RoleAdmin && user.Status == domain.UserStatusActive &&
+			(admin == nil || user.CreatedAt.Before(admin.CreatedAt)) {
+			admin = user
+		}
+	}
+	if admin == nil {
+		logger.Warn("no active admin to attribute data retention audit to", "organization_id", summary.OrganizationID)
+		return
+	}
+
+	auditLog := &domain.AuditLog{
+		OrganizationID: summary.OrganizationID,
+		UserID:         admin.ID,
+		Action:         domain.AuditActionDelete,
+		ResourceType:   "data_retention",
+		ResourceID:     summary.OrganizationID,
+		Metadata: map[string]interface{}{
+			"auditLogsPurged":          summary.AuditLogsPurged,
+			"verificationEventsPurged": summary.VerificationEventsPurged,
+			"resolvedAlertsPurged":     summary.ResolvedAlertsPurged,
+			"auditLogDays":             summary.Policy.AuditLogDays,
+			"verificationEventDays":    summary.Policy.VerificationEventDays,
+			"resolvedAlertDays":        summary.Policy.ResolvedAlertDays,
+			"deletedBy":                "system",
+		},
+		Timestamp: now,
+	}
+	if err := s.auditRepo.Create(auditLog); err != nil {
+		logger.Warn("failed to audit data retention", "organization_id", summary.OrganizationID, "error", err)
+	}
+}
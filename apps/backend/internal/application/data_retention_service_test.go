@@ -0,0 +1,248 @@
+package application
+
+import (
+	"context"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// retentionRow is a seeded row: its organization and the timestamp retention is measured from
+type retentionRow struct {
+	orgID uuid.UUID
+	at    time.Time
+}
+
+// inMemoryRetentionRepository deletes seeded rows the way the Postgres repository does: the
+// oldest rows of one organization before the cutoff, at most limit per call
+type inMemoryRetentionRepository struct {
+	tables map[string][]retentionRow
+	calls  map[string]int
+}
+
+func newInMemoryRetentionRepository() *inMemoryRetentionRepository {
+	return &inMemoryRetentionRepository{tables: map[string][]retentionRow{}, calls: map[string]int{}}
+}
+
+func (r *inMemoryRetentionRepository) seed(table string, orgID uuid.UUID, at ...time.Time) {
+	for _, t := range at {
+		r.tables[table] = append(r.tables[table], retentionRow{orgID: orgID, at: t})
+	}
+}
+
+func (r *inMemoryRetentionRepository) remaining(table string, orgID uuid.UUID) []time.Time {
+	var times []time.Time
+	for _, row := range r.tables[table] {
+		if row.orgID == orgID {
+			times = append(times, row.at)
+		}
+	}
+	return times
+}
+
+func (r *inMemoryRetentionRepository) purge(table string, orgID uuid.UUID, before time.Time, limit int) (int64, error) {
+	r.calls[table]++
+	rows := r.tables[table]
+	sort.Slice(rows, func(i, j int) bool { return rows[i].at.Before(rows[j].at) })
+
+	kept := rows[:0]
+	var deleted int64
+	for _, row := range rows {
+		if row.orgID == orgID && row.at.Before(before) && deleted < int64(limit) {
+			deleted++
+			continue
+		}
+		kept = append(kept, row)
+	}
+	r.tables[table] = kept
+	return deleted, nil
+}
+
+func (r *inMemoryRetentionRepository) PurgeAuditLogs(orgID uuid.UUID, before time.Time, limit int) (int64, error) {
+	return r.purge("audit_logs", orgID, before, limit)
+}
+
+func (r *inMemoryRetentionRepository) PurgeVerificationEvents(orgID uuid.UUID, before time.Time, limit int) (int64, error) {
+	return r.purge("verification_events", orgID, before, limit)
+}
+
+func (r *inMemoryRetentionRepository) PurgeResolvedAlerts(orgID uuid.UUID, before time.Time, limit int) (int64, error) {
+	return r.purge("alerts", orgID, before, limit)
+}
+
+type retentionOrgRepository struct {
+	domain.OrganizationRepository
+	orgs []*domain.Organization
+}
+
+func (r *retentionOrgRepository) ListActive() ([]*domain.Organization, error) {
+	return r.orgs, nil
+}
+
+type retentionUserRepository struct {
+	domain.UserRepository
+	users []*domain.User
+}
+
+func (r *retentionUserRepository) GetByOrganization(orgID uuid.UUID) ([]*domain.User, error) {
+	var users []*domain.User
+	for _, user := range r.users {
+		if user.OrganizationID == orgID {
+			users = append(users, user)
+		}
+	}
+	return users, nil
+}
+
+type retentionAuditRepository struct {
+	domain.AuditLogRepository
+	logs []*domain.AuditLog
+}
+
+func (r *retentionAuditRepository) Create(log *domain.AuditLog) error {
+	r.logs = append(r.logs, log)
+	return nil
+}
+
+func daysAgo(now time.Time, days int) time.Time {
+	return now.AddDate(0, 0, -days)
+}
+
+func TestDataRetentionService_PurgesOnlyExpiredRows(t *testing.T) {
+	now := time.Date(2025, 6, 1, 3, 0, 0, 0, time.UTC)
+	org := &domain.Organization{ID: uuid.New()}
+	otherOrg := &domain.Organization{ID: uuid.New()}
+
+	repo := newInMemoryRetentionRepository()
+	// Default policy: audit logs 365 days, verification events 90 days, resolved alerts 180 days
+	repo.seed("audit_logs", org.ID, daysAgo(now, 400), daysAgo(now, 366), daysAgo(now, 364), daysAgo(now, 1))
+	repo.seed("verification_events", org.ID, daysAgo(now, 120), daysAgo(now, 91), daysAgo(now, 89), now)
+	repo.seed("alerts", org.ID, daysAgo(now, 200), daysAgo(now, 179))
+	// Another organization's old rows are purged by its own run, never by this one
+	repo.seed("audit_logs", otherOrg.ID, daysAgo(now, 500))
+
+	service := NewDataRetentionService(repo, nil, nil, nil, 0)
+	summary, err := service.EnforceOrganizationRetention(context.Background(), org, now)
+	require.NoError(t, err)
+
+	assert.Equal(t, int64(2), summary.AuditLogsPurged)
+	assert.Equal(t, int64(2), summary.VerificationEventsPurged)
+	assert.Equal(t, int64(1), summary.ResolvedAlertsPurged)
+	assert.Equal(t, []time.Time{daysAgo(now, 364), daysAgo(now, 1)}, repo.remaining("audit_logs", org.ID))
+	assert.Equal(t, []time.Time{daysAgo(now, 89), now}, repo.remaining("verification_events", org.ID))
+	assert.Equal(t, []time.Time{daysAgo(now, 179)}, repo.remaining("alerts", org.ID))
+	assert.Len(t, repo.remaining("audit_logs", otherOrg.ID), 1)
+}
+
+func TestDataRetentionService_UsesOrganizationPolicy(t *testing.T) {
+	now := time.Date(2025, 6, 1, 3, 0, 0, 0, time.UTC)
+	org := &domain.Organization{
+		ID: uuid.New(),
+		RetentionPolicy: &domain.DataRetentionPolicy{
+			AuditLogDays:          30,
+			VerificationEventDays: 30,
+			ResolvedAlertDays:     30,
+		},
+	}
+
+	repo := newInMemoryRetentionRepository()
+	repo.seed("audit_logs", org.ID, daysAgo(now, 45), daysAgo(now, 10))
+
+	service := NewDataRetentionService(repo, nil, nil, nil, 0)
+	summary, err := service.EnforceOrganizationRetention(context.Background(), org, now)
+	require.NoError(t, err)
+
+	assert.Equal(t, int64(1), summary.AuditLogsPurged)
+	assert.Equal(t, []time.Time{daysAgo(now, 10)}, repo.remaining("audit_logs", org.ID))
+}
+
+func TestDataRetentionService_SkipsInvalidPolicy(t *testing.T) {
+	now := time.Date(2025, 6, 1, 3, 0, 0, 0, time.UTC)
+	org := &domain.Organization{
+		ID: uuid.New(),
+		RetentionPolicy: &domain.DataRetentionPolicy{
+			AuditLogDays:          365,
+			VerificationEventDays: 0, // e.g. a policy stored before validation existed
+			ResolvedAlertDays:     180,
+		},
+	}
+
+	repo := newInMemoryRetentionRepository()
+	repo.seed("audit_logs", org.ID, daysAgo(now, 400))
+	repo.seed("verification_events", org.ID, daysAgo(now, 1), now)
+
+	service := NewDataRetentionService(repo, nil, nil, nil, 0)
+	summary, err := service.EnforceOrganizationRetention(context.Background(), org, now)
+	assert.ErrorContains(t, err, "verificationEventDays")
+	assert.Nil(t, summary)
+
+	// Nothing is purged, not even the periods that are valid
+	assert.Len(t, repo.remaining("audit_logs", org.ID), 1)
+	assert.Len(t, repo.remaining("verification_events", org.ID), 2)
+	assert.Empty(t, repo.calls)
+}
+
+func TestDataRetentionService_PurgesInBatches(t *testing.T) {
+	now := time.Date(2025, 6, 1, 3, 0, 0, 0, time.UTC)
+	org := &domain.Organization{ID: uuid.New()}
+
+	repo := newInMemoryRetentionRepository()
+	for i := 0; i < 5; i++ {
+		repo.seed("verification_events", org.ID, daysAgo(now, 100+i))
+	}
+
+	service := NewDataRetentionService(repo, nil, nil, nil, 2)
+	summary, err := service.EnforceOrganizationRetention(context.Background(), org, now)
+	require.NoError(t, err)
+
+	assert.Equal(t, int64(5), summary.VerificationEventsPurged)
+	assert.Empty(t, repo.remaining("verification_events", org.ID))
+	assert.Equal(t, 3, repo.calls["verification_events"]) // 2 + 2 + 1
+}
+
+func TestDataRetentionService_EnforceRetention_RecordsSummaryAudit(t *testing.T) {
+	now := time.Date(2025, 6, 1, 3, 0, 0, 0, time.UTC)
+	org := &domain.Organization{ID: uuid.New()}
+	idleOrg := &domain.Organization{ID: uuid.New()}
+	founder := &domain.User{ID: uuid.New(), OrganizationID: org.ID, Role: domain.RoleAdmin, Status: domain.UserStatusActive, CreatedAt: daysAgo(now, 700)}
+	laterAdmin := &domain.User{ID: uuid.New(), OrganizationID: org.ID, Role: domain.RoleAdmin, Status: domain.UserStatusActive, CreatedAt: daysAgo(now, 10)}
+
+	repo := newInMemoryRetentionRepository()
+	repo.seed("audit_logs", org.ID, daysAgo(now, 400))
+	repo.seed("audit_logs", idleOrg.ID, daysAgo(now, 5))
+	auditRepo := &retentionAuditRepository{}
+
+	service := NewDataRetentionService(
+		repo,
+		&retentionOrgRepository{orgs: []*domain.Organization{org, idleOrg}},
+		&retentionUserRepository{users: []*domain.User{laterAdmin, founder}},
+		auditRepo,
+		0,
+	)
+	summaries, err := service.EnforceRetention(context.Background(), now)
+	require.NoError(t, err)
+	require.Len(t, summaries, 2)
+
+	// Only the organization that lost data gets an audit entry
+	require.Len(t, auditRepo.logs, 1)
+	entry := auditRepo.logs[0]
+	assert.Equal(t, org.ID, entry.OrganizationID)
+	assert.Equal(t, founder.ID, entry.UserID)
+	assert.Equal(t, domain.AuditActionDelete, entry.Action)
+	assert.Equal(t, "data_retention", entry.ResourceType)
+	assert.Equal(t, int64(1), entry.Metadata["auditLogsPurged"])
+	assert.Equal(t, now, entry.Timestamp)
+}
+
+func TestDataRetentionPolicy_Validate(t *testing.T) {
+	assert.NoError(t, domain.DefaultDataRetentionPolicy().Validate())
+
+	policy := domain.DefaultDataRetentionPolicy()
+	policy.ResolvedAlertDays = 7
+	assert.ErrorContains(t, policy.Validate(), "resolvedAlertDays")
+}
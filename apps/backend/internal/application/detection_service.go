@@ -11,14 +11,16 @@ import (
 	"github.com/google/uuid"
 	"github.com/lib/pq"
 	"github.com/opena2a/identity/backend/internal/domain"
+	"github.com/opena2a/identity/backend/internal/infrastructure/logging"
 )
 
 // DetectionService handles MCP detection business logic
 type DetectionService struct {
-	db                    *sql.DB
-	trustCalculator       domain.TrustScoreCalculator // ✅ NEW: For proper trust score calculation
-	agentRepo             domain.AgentRepository      // ✅ NEW: For fetching agent data
-	deduplicationWindow   time.Duration
+	db                  *sql.DB
+	trustCalculator     domain.TrustScoreCalculator // ✅ NEW: For proper trust score calculation
+	agentRepo           domain.AgentRepository      // ✅ NEW: For fetching agent data
+	deduplicationWindow time.Duration
+	now                 func() time.Time
 }
 
 // NewDetectionService creates a new detection service
@@ -36,21 +38,25 @@ func NewDetectionService(
 	}
 
 	return &DetectionService{
-		db:                    db,
-		trustCalculator:       trustCalculator,
-		agentRepo:             agentRepo,
-		deduplicationWindow:   deduplicationWindow,
+		db:                  db,
+		trustCalculator:     trustCalculator,
+		agentRepo:           agentRepo,
+		deduplicationWindow: deduplicationWindow,
+		now:                 time.Now,
 	}
 }
 
 // ReportDetections processes detection events from SDK or Direct API
 //
 // Server-Side Intelligent Deduplication Architecture:
-// 1. Store EVERY detection in immutable audit table (detections)
-// 2. Determine if detection is "significant" based on time window
-// 3. Only update aggregated state (agent_mcp_detections) if significant
-// 4. Only trigger trust score updates/webhooks/alerts if significant
-// 5. Maintain full audit trail for compliance and analytics
+//  1. Upsert every detection into aggregated state (agent_mcp_detections), one row per
+//     agent+mcp+method: confidence is merged (highest reported wins), report_count and
+//     last_reported_at are updated instead of inserting duplicates
+//  2. A detection is "significant" when it is the first one or the previous significant one
+//     (last_seen_at) is older than the deduplication window
+//  3. Only significant detections are written to the audit table (detections), so repeated
+//     reports don't bloat it while the first sighting per window stays auditable
+//  4. Only significant detections update talks_to and the SDK heartbeat
 func (s *DetectionService) ReportDetections(
 	ctx context.Context,
 	agentID uuid.UUID,
@@ -60,7 +66,7 @@ func (s *DetectionService) ReportDetections(
 	// 1. Validate agent belongs to organization
 	var exists bool
 	err := s.db.QueryRowContext(ctx,
-		`SELECT EXISTS(SELECT 1 FROM agents WHERE id = $1 AND organization_id = $2)`,
+		`SELECT EXISTS(SELECT 1 FROM agents WHERE id = $1 AND organization_id = $2 AND deleted_at IS NULL)`,
 		agentID, orgID,
 	).Scan(&exists)
 
@@ -73,6 +79,10 @@ func (s *DetectionService) ReportDetections(
 	totalProcessed := 0
 	significantCount := 0
 
+	// Postgres stores microseconds; truncate so the significance check below compares equal
+	now := s.now().UTC().Truncate(time.Microsecond)
+	significantBefore := now.Add(-s.deduplicationWindow)
+
 	// 2. Process each detection
 	for _, detection := range req.Detections {
 		// Validate detection
@@ -86,132 +96,103 @@ func (s *DetectionService) ReportDetections(
 
 		detailsJSON, _ := json.Marshal(detection.Details)
 
-		// 3. ALWAYS store in audit table (immutable, full trail)
-		// This ensures compliance, analytics, and forensic capabilities
-		var detectionID uuid.UUID
+		// 3. Upsert aggregated state; last_seen_at only moves when the detection is significant,
+		// which the RETURNING clause reports
+		var isSignificant bool
 		err := s.db.QueryRowContext(ctx, `
-			INSERT INTO detections (
+			INSERT INTO agent_mcp_detections (
 				agent_id, mcp_server_name, detection_method,
 				confidence_score, details, sdk_version,
-				is_significant, detected_at
-			) VALUES ($1, $2, $3, $4, $5, $6, FALSE, NOW())
-			RETURNING id
+				first_detected_at, last_seen_at, last_reported_at, report_count
+			) VALUES ($1, $2, $3, $4, $5, NULLIF($6, ''), $7, $7, $7, 1)
+			ON CONFLICT (agent_id, mcp_server_name, detection_method)
+			DO UPDATE SET
+				confidence_score = GREATEST(agent_mcp_detections.confidence_score, EXCLUDED.confidence_score),
+				details = EXCLUDED.details,
+				sdk_version = COALESCE(EXCLUDED.sdk_version, agent_mcp_detections.sdk_version),
+				report_count = agent_mcp_detections.report_count + 1,
+				last_reported_at = EXCLUDED.last_reported_at,
+				last_seen_at = CASE
+					WHEN agent_mcp_detections.last_seen_at <= $8 THEN EXCLUDED.last_seen_at
+					ELSE agent_mcp_detections.last_seen_at
+				END,
+				updated_at = NOW()
+			RETURNING last_seen_at = $7
 		`, agentID, detection.MCPServer, detection.DetectionMethod,
-			detection.Confidence, detailsJSON, detection.SDKVersion).Scan(&detectionID)
+			detection.Confidence, detailsJSON, detection.SDKVersion,
+			now, significantBefore).Scan(&isSignificant)
 
 		if err != nil {
-			fmt.Printf("Warning: failed to store audit detection for %s: %v\n", detection.MCPServer, err)
+			logging.FromContext(ctx).Warn("failed to update aggregated detection state", "agent_id", agentID, "mcp_server", detection.MCPServer, "error", err)
 			continue
 		}
 
 		totalProcessed++
 
-		// 4. Check if this detection is "significant" (server-side deduplication)
-		// Query last significant detection for this agent+mcp+method combination
-		var lastSignificantAt sql.NullTime
-		err = s.db.QueryRowContext(ctx, `
-			SELECT detected_at
-			FROM detections
-			WHERE agent_id = $1
-			  AND mcp_server_name = $2
-			  AND detection_method = $3
-			  AND is_significant = TRUE
-			ORDER BY detected_at DESC
-			LIMIT 1
-		`, agentID, detection.MCPServer, detection.DetectionMethod).Scan(&lastSignificantAt)
-
-		// Determine if this detection is significant
-		isSignificant := false
-		if err == sql.ErrNoRows {
-			// First detection ever - always significant
-			isSignificant = true
-		} else if err == nil && lastSignificantAt.Valid {
-			// Check if enough time has passed since last significant detection
-			timeSinceLastSignificant := time.Since(lastSignificantAt.Time)
-			if timeSinceLastSignificant >= s.deduplicationWindow {
-				isSignificant = true
-			}
-		} else if err != nil {
-			// Query error - be conservative, treat as significant
-			fmt.Printf("Warning: failed to check last significant detection: %v\n", err)
-			isSignificant = true
+		// 4. Repeated reports inside the window are merged into the aggregated row only
+		if !isSignificant {
+			continue
 		}
+		significantCount++
 
-		// 5. If significant, mark in audit table and update aggregated state
-		if isSignificant {
-			// Mark as significant in audit table
-			s.db.ExecContext(ctx, `
-				UPDATE detections SET is_significant = TRUE WHERE id = $1
-			`, detectionID)
-
-			significantCount++
-
-			// Update aggregated state table (agent_mcp_detections)
-			_, err := s.db.ExecContext(ctx, `
-				INSERT INTO agent_mcp_detections (
-					agent_id, mcp_server_name, detection_method,
-					confidence_score, details, sdk_version,
-					first_detected_at, last_seen_at
-				) VALUES ($1, $2, $3, $4, $5, $6, NOW(), NOW())
-				ON CONFLICT (agent_id, mcp_server_name, detection_method)
-				DO UPDATE SET
-					last_seen_at = NOW(),
-					confidence_score = EXCLUDED.confidence_score,
-					details = EXCLUDED.details,
-					sdk_version = COALESCE(EXCLUDED.sdk_version, agent_mcp_detections.sdk_version)
-			`, agentID, detection.MCPServer, detection.DetectionMethod,
-				detection.Confidence, detailsJSON, detection.SDKVersion)
-
-			if err != nil {
-				fmt.Printf("Warning: failed to update aggregated state for %s: %v\n", detection.MCPServer, err)
-				continue
-			}
+		// 5. Record the significant detection in the audit table
+		_, err = s.db.ExecContext(ctx, `
+			INSERT INTO detections (
+				agent_id, mcp_server_name, detection_method,
+				confidence_score, details, sdk_version,
+				is_significant, detected_at
+			) VALUES ($1, $2, $3, $4, $5, NULLIF($6, ''), TRUE, $7)
+		`, agentID, detection.MCPServer, detection.DetectionMethod,
+			detection.Confidence, detailsJSON, detection.SDKVersion, now)
 
-			// 6. Check if MCP is already in agent's talks_to
-			var talksToJSON []byte
-			err = s.db.QueryRowContext(ctx,
-				`SELECT talks_to FROM agents WHERE id = $1`, agentID,
-			).Scan(&talksToJSON)
+		if err != nil {
+			logging.FromContext(ctx).Warn("failed to store audit detection", "agent_id", agentID, "mcp_server", detection.MCPServer, "error", err)
+		}
 
-			if err != nil {
-				fmt.Printf("Warning: failed to get agent talks_to: %v\n", err)
-				continue
-			}
+		// 6. Check if MCP is already in agent's talks_to
+		var talksToJSON []byte
+		err = s.db.QueryRowContext(ctx,
+			`SELECT talks_to FROM agents WHERE id = $1`, agentID,
+		).Scan(&talksToJSON)
 
-			var talksTo []string
-			if len(talksToJSON) > 0 {
-				json.Unmarshal(talksToJSON, &talksTo)
-			}
+		if err != nil {
+			logging.FromContext(ctx).Warn("failed to get agent talks_to", "agent_id", agentID, "error", err)
+			continue
+		}
+
+		var talksTo []string
+		if len(talksToJSON) > 0 {
+			json.Unmarshal(talksToJSON, &talksTo)
+		}
 
-			// 7. Add to talks_to if not present
-			found := false
-			for _, mcp := range talksTo {
-				if mcp == detection.MCPServer {
-					found = true
-					existingMCPs = append(existingMCPs, detection.MCPServer)
-					break
-				}
+		// 7. Add to talks_to if not present
+		found := false
+		for _, mcp := range talksTo {
+			if mcp == detection.MCPServer {
+				found = true
+				existingMCPs = append(existingMCPs, detection.MCPServer)
+				break
 			}
+		}
 
-			if !found {
-				talksTo = append(talksTo, detection.MCPServer)
-				updatedJSON, _ := json.Marshal(talksTo)
+		if !found {
+			talksTo = append(talksTo, detection.MCPServer)
+			updatedJSON, _ := json.Marshal(talksTo)
 
-				_, err = s.db.ExecContext(ctx,
-					`UPDATE agents SET talks_to = $1, updated_at = NOW() WHERE id = $2`,
-					updatedJSON, agentID)
+			_, err = s.db.ExecContext(ctx,
+				`UPDATE agents SET talks_to = $1, updated_at = NOW() WHERE id = $2`,
+				updatedJSON, agentID)
 
-				if err == nil {
-					newMCPs = append(newMCPs, detection.MCPServer)
-				} else {
-					fmt.Printf("Warning: failed to update talks_to for %s: %v\n", detection.MCPServer, err)
-				}
+			if err == nil {
+				newMCPs = append(newMCPs, detection.MCPServer)
+			} else {
+				logging.FromContext(ctx).Warn("failed to update agent talks_to", "agent_id", agentID, "mcp_server", detection.MCPServer, "error", err)
 			}
+		}
 
-			// 8. Update SDK installation heartbeat if SDK detection
-			if detection.SDKVersion != "" {
-				s.updateSDKHeartbeat(ctx, agentID, detection.SDKVersion)
-			}
+		// 8. Update SDK installation heartbeat if SDK detection
+		if detection.SDKVersion != "" {
+			s.updateSDKHeartbeat(ctx, agentID, detection.SDKVersion)
 		}
 	}
 
@@ -224,7 +205,7 @@ func (s *DetectionService) ReportDetections(
 		DetectionsProcessed: totalProcessed,
 		NewMCPs:             newMCPs,
 		ExistingMCPs:        existingMCPs,
-		Message:             fmt.Sprintf("Processed %d detections (%d significant, %d filtered)", totalProcessed, significantCount, totalProcessed-significantCount),
+		Message:             fmt.Sprintf("Processed %d detections (%d significant, %d merged)", totalProcessed, significantCount, totalProcessed-significantCount),
 	}, nil
 }
 
@@ -269,7 +250,7 @@ func (s *DetectionService) GetDetectionStatus(
 	// 1. Validate agent belongs to organization
 	var exists bool
 	err := s.db.QueryRowContext(ctx,
-		`SELECT EXISTS(SELECT 1 FROM agents WHERE id = $1 AND organization_id = $2)`,
+		`SELECT EXISTS(SELECT 1 FROM agents WHERE id = $1 AND organization_id = $2 AND deleted_at IS NULL)`,
 		agentID, orgID,
 	).Scan(&exists)
 
@@ -325,7 +306,7 @@ func (s *DetectionService) GetDetectionStatus(
 			COALESCE(ARRAY_AGG(DISTINCT d.detection_method::text) FILTER (WHERE d.detection_method IS NOT NULL), ARRAY['manual']::text[]) as methods,
 			COALESCE(AVG(d.confidence_score), 0) as avg_confidence,
 			MIN(d.first_detected_at) as first_detected,
-			MAX(d.last_seen_at) as last_seen,
+			MAX(d.last_reported_at) as last_seen,
 			CASE WHEN COUNT(d.mcp_server_name) = 0 THEN true ELSE false END as is_manual
 		FROM connected_mcps t
 		LEFT JOIN agent_mcp_detections d
@@ -391,7 +372,7 @@ func (s *DetectionService) ReportCapabilities(
 	// 1. Validate agent belongs to organization
 	var exists bool
 	err := s.db.QueryRowContext(ctx,
-		`SELECT EXISTS(SELECT 1 FROM agents WHERE id = $1 AND organization_id = $2)`,
+		`SELECT EXISTS(SELECT 1 FROM agents WHERE id = $1 AND organization_id = $2 AND deleted_at IS NULL)`,
 		agentID, orgID,
 	).Scan(&exists)
 
@@ -495,7 +476,7 @@ func (s *DetectionService) GetLatestCapabilityReport(
 	var count int
 	err := s.db.QueryRowContext(ctx, `
 		SELECT COUNT(*) FROM agents
-		WHERE id = $1 AND organization_id = $2
+		WHERE id = $1 AND organization_id = $2 AND deleted_at IS NULL
 	`, agentID, orgID).Scan(&count)
 
 	if err != nil {
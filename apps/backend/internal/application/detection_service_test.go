@@ -0,0 +1,112 @@
+package application
+
+import (
+	"context"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// detectionUpsertQuery matches the aggregated-state upsert, including its confidence merge
+var detectionUpsertQuery = regexp.QuoteMeta("INSERT INTO agent_mcp_detections") +
+	`[\s\S]*ON CONFLICT \(agent_id, mcp_server_name, detection_method\)` +
+	`[\s\S]*GREATEST\(agent_mcp_detections\.confidence_score, EXCLUDED\.confidence_score\)` +
+	`[\s\S]*report_count = agent_mcp_detections\.report_count \+ 1` +
+	`[\s\S]*last_reported_at = EXCLUDED\.last_reported_at`
+
+func newTestDetectionService(t *testing.T, now time.Time) (*DetectionService, sqlmock.Sqlmock) {
+	t.Helper()
+	db, sqlMock, err := sqlmock.New()
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	service := NewDetectionService(db, nil, nil)
+	service.deduplicationWindow = 24 * time.Hour
+	service.now = func() time.Time { return now }
+	return service, sqlMock
+}
+
+func detectionReport(confidence float64) *domain.DetectionReportRequest {
+	return &domain.DetectionReportRequest{Detections: []domain.DetectionEvent{{
+		MCPServer:       "filesystem",
+		DetectionMethod: domain.DetectionMethodSDKRuntime,
+		Confidence:      confidence,
+	}}}
+}
+
+func TestDetectionService_ReportDetections_FirstReportIsSignificant(t *testing.T) {
+	now := time.Date(2025, 3, 4, 12, 0, 0, 0, time.UTC)
+	service, sqlMock := newTestDetectionService(t, now)
+	agentID, orgID := uuid.New(), uuid.New()
+
+	sqlMock.ExpectQuery(regexp.QuoteMeta("SELECT EXISTS")).WithArgs(agentID, orgID).
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+	sqlMock.ExpectQuery(detectionUpsertQuery).
+		WithArgs(agentID, "filesystem", domain.DetectionMethodSDKRuntime, 60.0, sqlmock.AnyArg(), "", now, now.Add(-24*time.Hour)).
+		WillReturnRows(sqlmock.NewRows([]string{"significant"}).AddRow(true))
+	sqlMock.ExpectExec(regexp.QuoteMeta("INSERT INTO detections")).
+		WithArgs(agentID, "filesystem", domain.DetectionMethodSDKRuntime, 60.0, sqlmock.AnyArg(), "", now).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	sqlMock.ExpectQuery(regexp.QuoteMeta("SELECT talks_to FROM agents")).WithArgs(agentID).
+		WillReturnRows(sqlmock.NewRows([]string{"talks_to"}).AddRow([]byte(`[]`)))
+	sqlMock.ExpectExec(regexp.QuoteMeta("UPDATE agents SET talks_to")).
+		WithArgs([]byte(`["filesystem"]`), agentID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	resp, err := service.ReportDetections(context.Background(), agentID, orgID, detectionReport(60))
+
+	require.NoError(t, err)
+	assert.Equal(t, 1, resp.DetectionsProcessed)
+	assert.Equal(t, []string{"filesystem"}, resp.NewMCPs)
+	assert.NoError(t, sqlMock.ExpectationsWereMet())
+}
+
+func TestDetectionService_ReportDetections_RepeatedReportsMergeIntoOneRow(t *testing.T) {
+	now := time.Date(2025, 3, 4, 12, 0, 0, 0, time.UTC)
+	service, sqlMock := newTestDetectionService(t, now)
+	agentID, orgID := uuid.New(), uuid.New()
+
+	// Each repeat inside the window upserts the same row with the reported confidence, which the
+	// upsert merges with GREATEST; nothing is added to the audit table
+	for _, confidence := range []float64{70, 90} {
+		sqlMock.ExpectQuery(regexp.QuoteMeta("SELECT EXISTS")).WithArgs(agentID, orgID).
+			WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+		sqlMock.ExpectQuery(detectionUpsertQuery).
+			WithArgs(agentID, "filesystem", domain.DetectionMethodSDKRuntime, confidence, sqlmock.AnyArg(), "", now, now.Add(-24*time.Hour)).
+			WillReturnRows(sqlmock.NewRows([]string{"significant"}).AddRow(false))
+	}
+
+	for _, confidence := range []float64{70, 90} {
+		resp, err := service.ReportDetections(context.Background(), agentID, orgID, detectionReport(confidence))
+		require.NoError(t, err)
+		assert.Equal(t, 1, resp.DetectionsProcessed)
+		assert.Empty(t, resp.NewMCPs)
+		assert.Contains(t, resp.Message, "1 merged")
+	}
+	assert.NoError(t, sqlMock.ExpectationsWereMet())
+}
+
+func TestDetectionService_ReportDetections_SkipsInvalidDetections(t *testing.T) {
+	service, sqlMock := newTestDetectionService(t, time.Now())
+	agentID, orgID := uuid.New(), uuid.New()
+
+	sqlMock.ExpectQuery(regexp.QuoteMeta("SELECT EXISTS")).WithArgs(agentID, orgID).
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+
+	resp, err := service.ReportDetections(context.Background(), agentID, orgID, &domain.DetectionReportRequest{
+		Detections: []domain.DetectionEvent{
+			{MCPServer: "", Confidence: 50},
+			{MCPServer: "filesystem", Confidence: 101},
+		},
+	})
+
+	require.NoError(t, err)
+	assert.Zero(t, resp.DetectionsProcessed)
+	assert.NoError(t, sqlMock.ExpectationsWereMet())
+}
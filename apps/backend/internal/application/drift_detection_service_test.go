@@ -3,6 +3,7 @@ package application
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/opena2a/identity/backend/internal/domain"
@@ -20,6 +21,14 @@ func (m *MockAgentRepository) Create(agent *domain.Agent) error {
 	return args.Error(0)
 }
 
+func (m *MockAgentRepository) CreateBatch(agents []*domain.Agent, atomic bool) ([]error, error) {
+	args := m.Called(agents, atomic)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]error), args.Error(1)
+}
+
 func (m *MockAgentRepository) GetByID(id uuid.UUID) (*domain.Agent, error) {
 	args := m.Called(id)
 	if args.Get(0) == nil {
@@ -44,6 +53,43 @@ func (m *MockAgentRepository) GetByOrganization(orgID uuid.UUID) ([]*domain.Agen
 	return args.Get(0).([]*domain.Agent), args.Error(1)
 }
 
+func (m *MockAgentRepository) GetByOrganizationPaginated(orgID uuid.UUID, limit, offset int, after *domain.AgentCursor) ([]*domain.Agent, int, error) {
+	args := m.Called(orgID, limit, offset, after)
+	if args.Get(0) == nil {
+		return nil, args.Int(1), args.Error(2)
+	}
+	return args.Get(0).([]*domain.Agent), args.Int(1), args.Error(2)
+}
+
+func (m *MockAgentRepository) Search(orgID uuid.UUID, filter domain.AgentSearchFilter, limit, offset int, after *domain.AgentCursor) ([]*domain.Agent, int, error) {
+	args := m.Called(orgID, filter, limit, offset, after)
+	if args.Get(0) == nil {
+		return nil, args.Int(1), args.Error(2)
+	}
+	return args.Get(0).([]*domain.Agent), args.Int(1), args.Error(2)
+}
+
+func (m *MockAgentRepository) GetByIDIncludingDeleted(id uuid.UUID) (*domain.Agent, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Agent), args.Error(1)
+}
+
+func (m *MockAgentRepository) SoftDelete(id uuid.UUID) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func (m *MockAgentRepository) GetByKeyExpiringBetween(from, to time.Time) ([]*domain.Agent, error) {
+	args := m.Called(from, to)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.Agent), args.Error(1)
+}
+
 func (m *MockAgentRepository) List(limit, offset int) ([]*domain.Agent, error) {
 	args := m.Called(limit, offset)
 	if args.Get(0) == nil {
@@ -72,11 +118,41 @@ func (m *MockAgentRepository) UpdateTrustScore(agentID uuid.UUID, score float64)
 	return args.Error(0)
 }
 
+func (m *MockAgentRepository) UpdateLabels(id uuid.UUID, labels map[string]string) error {
+	args := m.Called(id, labels)
+	return args.Error(0)
+}
+
+func (m *MockAgentRepository) UpdateOAuthClientID(id uuid.UUID, clientID string) error {
+	args := m.Called(id, clientID)
+	return args.Error(0)
+}
+
+func (m *MockAgentRepository) CountByOrganization(orgID uuid.UUID) (int, error) {
+	args := m.Called(orgID)
+	return args.Int(0), args.Error(1)
+}
+
 func (m *MockAgentRepository) MarkAsCompromised(agentID uuid.UUID) error {
 	args := m.Called(agentID)
 	return args.Error(0)
 }
 
+func (m *MockAgentRepository) MarkCompromisedBy(id, userID uuid.UUID, reason string, at time.Time, messages ...*domain.OutboxMessage) error {
+	args := m.Called(id, userID, reason, at, messages)
+	return args.Error(0)
+}
+
+func (m *MockAgentRepository) ClearCompromised(id uuid.UUID, messages ...*domain.OutboxMessage) error {
+	args := m.Called(id, messages)
+	return args.Error(0)
+}
+
+func (m *MockAgentRepository) UpdateStatus(agent *domain.Agent, messages ...*domain.OutboxMessage) error {
+	args := m.Called(agent, messages)
+	return args.Error(0)
+}
+
 func (m *MockAgentRepository) UpdateLastActive(ctx context.Context, agentID uuid.UUID) error {
 	args := m.Called(ctx, agentID)
 	return args.Error(0)
@@ -116,8 +192,13 @@ func (m *MockAlertRepository) GetUnacknowledged(orgID uuid.UUID) ([]*domain.Aler
 	return args.Get(0).([]*domain.Alert), args.Error(1)
 }
 
-func (m *MockAlertRepository) Acknowledge(id, userID uuid.UUID) error {
-	args := m.Called(id, userID)
+func (m *MockAlertRepository) Acknowledge(id, userID uuid.UUID, at time.Time) error {
+	args := m.Called(id, userID, at)
+	return args.Error(0)
+}
+
+func (m *MockAlertRepository) Resolve(id, userID uuid.UUID, note *string, at time.Time) error {
+	args := m.Called(id, userID, note, at)
 	return args.Error(0)
 }
 
@@ -0,0 +1,291 @@
+package application
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/domain"
+	"github.com/opena2a/identity/backend/internal/infrastructure/logging"
+)
+
+// Login lockout defaults; override with LOGIN_LOCKOUT_THRESHOLD and LOGIN_LOCKOUT_DURATION (Go duration)
+const (
+	DefaultLoginLockoutThreshold = 5
+	DefaultLoginLockoutDuration  = 15 * time.Minute
+)
+
+const (
+	// MaxLoginLockoutDuration caps the backoff: every further threshold of failures doubles the lockout
+	MaxLoginLockoutDuration = 24 * time.Hour
+	// loginFailureWindow is how long failed attempts are remembered after the first one
+	loginFailureWindow = 24 * time.Hour
+	// loginLockoutIPMultiplier lets a client IP fail this many times the per-account threshold before
+	// it is locked, so one client cannot spray passwords across many accounts
+	loginLockoutIPMultiplier = 4
+
+	loginFailuresPrefix = "login_failures:"
+	loginLockPrefix     = "login_lock:"
+)
+
+// LoginLockoutSettingsFromEnv returns the failure threshold and the initial lockout duration
+func LoginLockoutSettingsFromEnv() (threshold int, duration time.Duration) {
+	threshold = DefaultLoginLockoutThreshold
+	if value, err := strconv.Atoi(os.Getenv("LOGIN_LOCKOUT_THRESHOLD")); err == nil && value > 0 {
+		threshold = value
+	}
+
+	duration = DefaultLoginLockoutDuration
+	if value, err := time.ParseDuration(os.Getenv("LOGIN_LOCKOUT_DURATION")); err == nil && value > 0 {
+		duration = value
+	}
+
+	return threshold, duration
+}
+
+// LoginAttemptStore keeps failed login counters and lockouts with expiry.
+// *cache.RedisCache implements it so lockouts apply across server instances.
+type LoginAttemptStore interface {
+	IncrementWithExpiry(ctx context.Context, key string, ttl time.Duration) (int64, error)
+	Set(ctx context.Context, key string, value interface{}, ttl time.Duration) error
+	GetTTL(ctx context.Context, key string) (time.Duration, error)
+	Delete(ctx context.Context, key string) error
+}
+
+// LoginLockout protects password logins against brute force. Failed attempts are counted per
+// email and per client IP; reaching the threshold locks that email (or IP) out, and each further
+// threshold of failures doubles the lockout up to MaxLoginLockoutDuration. A successful login
+// clears the counters.
+type LoginLockout struct {
+	store     LoginAttemptStore
+	threshold int
+	duration  time.Duration
+	userRepo  domain.UserRepository
+	alertRepo domain.AlertRepository
+
+	mu       sync.Mutex
+	failures map[string]loginFailureCounter // in-memory fallback
+	locks    map[string]time.Time           // in-memory fallback: key -> locked until
+}
+
+type loginFailureCounter struct {
+	count     int64
+	expiresAt time.Time
+}
+
+// NewLoginLockout creates a login lockout guard. store may be nil, in which case (and whenever
+// the store errors) attempts are tracked in memory. userRepo and alertRepo are used to raise a
+// security alert in the account's organization when an account is locked; both may be nil.
+func NewLoginLockout(store LoginAttemptStore, threshold int, duration time.Duration, userRepo domain.UserRepository, alertRepo domain.AlertRepository) *LoginLockout {
+	if threshold <= 0 {
+		threshold = DefaultLoginLockoutThreshold
+	}
+	if duration <= 0 {
+		duration = DefaultLoginLockoutDuration
+	}
+	return &LoginLockout{
+		store:     store,
+		threshold: threshold,
+		duration:  duration,
+		userRepo:  userRepo,
+		alertRepo: alertRepo,
+		failures:  make(map[string]loginFailureCounter),
+		locks:     make(map[string]time.Time),
+	}
+}
+
+// RetryAfter returns how long logins for email from ipAddress stay locked, or 0 if they are allowed
+func (l *LoginLockout) RetryAfter(ctx context.Context, email, ipAddress string, now time.Time) time.Duration {
+	if l == nil {
+		return 0
+	}
+	var retryAfter time.Duration
+	for _, subject := range loginLockoutSubjects(email, ipAddress) {
+		if remaining := l.lockedFor(ctx, subject, now); remaining > retryAfter {
+			retryAfter = remaining
+		}
+	}
+	return retryAfter
+}
+
+// RecordFailure counts a failed login. If it locks the email or the IP, the lockout duration is
+// returned (0 otherwise) and a security alert is raised for a locked account.
+func (l *LoginLockout) RecordFailure(ctx context.Context, email, ipAddress string, now time.Time) time.Duration {
+	if l == nil {
+		return 0
+	}
+	var retryAfter time.Duration
+	for _, subject := range loginLockoutSubjects(email, ipAddress) {
+		threshold := int64(l.threshold)
+		if strings.HasPrefix(subject, "ip:") {
+			threshold *= loginLockoutIPMultiplier
+		}
+
+		count := l.incrementFailures(ctx, subject, now)
+		if count < threshold || count%threshold != 0 {
+			continue
+		}
+
+		lockout := l.lockoutDuration(int(count / threshold))
+		l.lock(ctx, subject, lockout, now)
+		if lockout > retryAfter {
+			retryAfter = lockout
+		}
+		l.raiseLockoutAlert(ctx, subject, count, lockout, ipAddress, now)
+	}
+	return retryAfter
+}
+
+// RecordSuccess clears the failed login counter of email. The per-IP counter is left alone, so
+// an attacker spraying many accounts cannot reset it by logging in to one they control.
+func (l *LoginLockout) RecordSuccess(ctx context.Context, email string) {
+	if l == nil {
+		return
+	}
+	for _, subject := range loginLockoutSubjects(email, "") {
+		l.resetFailures(ctx, subject)
+	}
+}
+
+// lockoutDuration doubles the base duration for every lockout after the first, up to the maximum
+func (l *LoginLockout) lockoutDuration(lockouts int) time.Duration {
+	duration := l.duration
+	for i := 1; i < lockouts && duration < MaxLoginLockoutDuration; i++ {
+		duration *= 2
+	}
+	if duration > MaxLoginLockoutDuration {
+		duration = MaxLoginLockoutDuration
+	}
+	return duration
+}
+
+// raiseLockoutAlert logs the lockout and, for a known account, creates a security alert in its organization
+func (l *LoginLockout) raiseLockoutAlert(ctx context.Context, subject string, failures int64, lockout time.Duration, ipAddress string, now time.Time) {
+	logger := logging.FromContext(ctx)
+	logger.Warn("security alert: login locked after repeated failures",
+		"subject", subject, "failures", failures, "lockout", lockout, "ip_address", ipAddress)
+
+	email, isAccount := strings.CutPrefix(subject, "email:")
+	if !isAccount || l.userRepo == nil || l.alertRepo == nil {
+		return
+	}
+	user, err := l.userRepo.GetByEmail(email)
+	if err != nil || user == nil {
+		return // Unknown accounts are locked too, but there is no organization to alert
+	}
+
+	alert := &domain.Alert{
+		ID:             uuid.New(),
+		OrganizationID: user.OrganizationID,
+		AlertType:      domain.AlertSecurityBreach,
+		Severity:       domain.AlertSeverityHigh,
+		Title:          fmt.Sprintf("Account locked: %s", user.Email),
+		Description: fmt.Sprintf(
+			"Login for %s was locked for %s after %d failed attempts. Last attempt from %s.",
+			user.Email, lockout, failures, ipAddress,
+		),
+		ResourceType:   "user",
+		ResourceID:     user.ID,
+		IsAcknowledged: false,
+		CreatedAt:      now,
+	}
+	if err := l.alertRepo.Create(alert); err != nil {
+		logger.Warn("failed to create login lockout alert", "user_id", user.ID, "error", err)
+	}
+}
+
+func loginLockoutSubjects(email, ipAddress string) []string {
+	var subjects []string
+	if email = strings.ToLower(strings.TrimSpace(email)); email != "" {
+		subjects = append(subjects, "email:"+email)
+	}
+	if ipAddress != "" {
+		subjects = append(subjects, "ip:"+ipAddress)
+	}
+	return subjects
+}
+
+func (l *LoginLockout) incrementFailures(ctx context.Context, subject string, now time.Time) int64 {
+	if l.store != nil {
+		count, err := l.store.IncrementWithExpiry(ctx, loginFailuresPrefix+subject, loginFailureWindow)
+		if err == nil {
+			return count
+		}
+		logging.FromContext(ctx).Warn("login attempt store unavailable, using in-memory tracking", "error", err)
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	counter, ok := l.failures[subject]
+	if !ok || !now.Before(counter.expiresAt) {
+		// Drop expired counters while we hold the lock so the map doesn't grow unbounded
+		for key, c := range l.failures {
+			if !now.Before(c.expiresAt) {
+				delete(l.failures, key)
+			}
+		}
+		counter = loginFailureCounter{expiresAt: now.Add(loginFailureWindow)}
+	}
+	counter.count++
+	l.failures[subject] = counter
+	return counter.count
+}
+
+func (l *LoginLockout) lock(ctx context.Context, subject string, duration time.Duration, now time.Time) {
+	if l.store != nil {
+		err := l.store.Set(ctx, loginLockPrefix+subject, now.Unix(), duration)
+		if err == nil {
+			return
+		}
+		logging.FromContext(ctx).Warn("login attempt store unavailable, using in-memory tracking", "error", err)
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for key, until := range l.locks {
+		if !now.Before(until) {
+			delete(l.locks, key)
+		}
+	}
+	l.locks[subject] = now.Add(duration)
+}
+
+func (l *LoginLockout) lockedFor(ctx context.Context, subject string, now time.Time) time.Duration {
+	if l.store != nil {
+		ttl, err := l.store.GetTTL(ctx, loginLockPrefix+subject)
+		if err == nil {
+			if ttl < 0 {
+				ttl = 0 // Missing key
+			}
+			return ttl
+		}
+		logging.FromContext(ctx).Warn("login attempt store unavailable, using in-memory tracking", "error", err)
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if until, ok := l.locks[subject]; ok && now.Before(until) {
+		return until.Sub(now)
+	}
+	return 0
+}
+
+func (l *LoginLockout) resetFailures(ctx context.Context, subject string) {
+	if l.store != nil {
+		if err := l.store.Delete(ctx, loginFailuresPrefix+subject); err != nil {
+			logging.FromContext(ctx).Warn("failed to reset login failures", "subject", subject, "error", err)
+		}
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	delete(l.failures, subject)
+}
@@ -0,0 +1,142 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/opena2a/identity/backend/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// unavailableLoginAttemptStore simulates Redis being down so the in-memory fallback is used
+type unavailableLoginAttemptStore struct{}
+
+func (unavailableLoginAttemptStore) IncrementWithExpiry(ctx context.Context, key string, ttl time.Duration) (int64, error) {
+	return 0, errors.New("connection refused")
+}
+
+func (unavailableLoginAttemptStore) Set(ctx context.Context, key string, value interface{}, ttl time.Duration) error {
+	return errors.New("connection refused")
+}
+
+func (unavailableLoginAttemptStore) GetTTL(ctx context.Context, key string) (time.Duration, error) {
+	return 0, errors.New("connection refused")
+}
+
+func (unavailableLoginAttemptStore) Delete(ctx context.Context, key string) error {
+	return errors.New("connection refused")
+}
+
+func TestLoginLockout_LocksAfterThreshold(t *testing.T) {
+	lockout := NewLoginLockout(nil, 3, 15*time.Minute, nil, nil)
+	ctx := context.Background()
+	now := time.Date(2025, 4, 1, 9, 0, 0, 0, time.UTC)
+
+	assert.Zero(t, lockout.RecordFailure(ctx, "dev@example.com", "198.51.100.1", now))
+	assert.Zero(t, lockout.RecordFailure(ctx, "dev@example.com", "198.51.100.1", now))
+	assert.Zero(t, lockout.RetryAfter(ctx, "dev@example.com", "198.51.100.1", now))
+
+	assert.Equal(t, 15*time.Minute, lockout.RecordFailure(ctx, "dev@example.com", "198.51.100.1", now))
+	assert.Equal(t, 15*time.Minute, lockout.RetryAfter(ctx, "DEV@example.com ", "203.0.113.9", now), "email lockout applies from any IP")
+
+	// Other accounts from the same client are not affected yet
+	assert.Zero(t, lockout.RetryAfter(ctx, "other@example.com", "198.51.100.1", now))
+}
+
+func TestLoginLockout_UnlocksAfterWindowAndBacksOff(t *testing.T) {
+	lockout := NewLoginLockout(nil, 3, 15*time.Minute, nil, nil)
+	ctx := context.Background()
+	now := time.Date(2025, 4, 1, 9, 0, 0, 0, time.UTC)
+
+	for i := 0; i < 3; i++ {
+		lockout.RecordFailure(ctx, "dev@example.com", "198.51.100.1", now)
+	}
+	assert.Equal(t, 5*time.Minute, lockout.RetryAfter(ctx, "dev@example.com", "198.51.100.1", now.Add(10*time.Minute)))
+
+	now = now.Add(15 * time.Minute)
+	assert.Zero(t, lockout.RetryAfter(ctx, "dev@example.com", "198.51.100.1", now))
+
+	// Failures keep counting, so the next lockout is twice as long
+	assert.Zero(t, lockout.RecordFailure(ctx, "dev@example.com", "198.51.100.1", now))
+	assert.Zero(t, lockout.RecordFailure(ctx, "dev@example.com", "198.51.100.1", now))
+	assert.Equal(t, 30*time.Minute, lockout.RecordFailure(ctx, "dev@example.com", "198.51.100.1", now))
+}
+
+func TestLoginLockout_SuccessResetsCounter(t *testing.T) {
+	lockout := NewLoginLockout(nil, 3, 15*time.Minute, nil, nil)
+	ctx := context.Background()
+	now := time.Date(2025, 4, 1, 9, 0, 0, 0, time.UTC)
+
+	lockout.RecordFailure(ctx, "dev@example.com", "198.51.100.1", now)
+	lockout.RecordFailure(ctx, "dev@example.com", "198.51.100.1", now)
+	lockout.RecordSuccess(ctx, "dev@example.com")
+
+	assert.Zero(t, lockout.RecordFailure(ctx, "dev@example.com", "198.51.100.1", now))
+	assert.Zero(t, lockout.RecordFailure(ctx, "dev@example.com", "198.51.100.1", now))
+	assert.Zero(t, lockout.RetryAfter(ctx, "dev@example.com", "198.51.100.1", now))
+}
+
+func TestLoginLockout_LocksIPSprayingAcrossAccounts(t *testing.T) {
+	lockout := NewLoginLockout(unavailableLoginAttemptStore{}, 2, time.Minute, nil, nil)
+	ctx := context.Background()
+	now := time.Date(2025, 4, 1, 9, 0, 0, 0, time.UTC)
+
+	for i := 0; i < 2*loginLockoutIPMultiplier; i++ {
+		lockout.RecordFailure(ctx, fmt.Sprintf("user%d@example.com", i), "198.51.100.1", now)
+	}
+
+	assert.Equal(t, time.Minute, lockout.RetryAfter(ctx, "new@example.com", "198.51.100.1", now))
+	assert.Zero(t, lockout.RetryAfter(ctx, "new@example.com", "203.0.113.9", now))
+}
+
+func TestLoginLockout_SuccessKeepsIPCounter(t *testing.T) {
+	lockout := NewLoginLockout(nil, 2, time.Minute, nil, nil)
+	ctx := context.Background()
+	now := time.Date(2025, 4, 1, 9, 0, 0, 0, time.UTC)
+
+	// Logging in to an attacker-controlled account between sprays does not reset the IP
+	for i := 0; i < 2*loginLockoutIPMultiplier; i++ {
+		lockout.RecordFailure(ctx, fmt.Sprintf("user%d@example.com", i), "198.51.100.1", now)
+		if i == loginLockoutIPMultiplier {
+			lockout.RecordSuccess(ctx, "attacker@example.com")
+		}
+	}
+
+	assert.Equal(t, time.Minute, lockout.RetryAfter(ctx, "new@example.com", "198.51.100.1", now))
+}
+
+func TestLoginLockout_RaisesAlertOnAccountLockout(t *testing.T) {
+	mockUserRepo := new(MockUserRepository)
+	mockAlertRepo := new(MockAlertRepository)
+	lockout := NewLoginLockout(nil, 2, 15*time.Minute, mockUserRepo, mockAlertRepo)
+	ctx := context.Background()
+	now := time.Date(2025, 4, 1, 9, 0, 0, 0, time.UTC)
+
+	user := createTestUser("admin@example.com")
+	mockUserRepo.On("GetByEmail", "admin@example.com").Return(user, nil)
+	mockAlertRepo.On("Create", mock.MatchedBy(func(alert *domain.Alert) bool {
+		return alert.AlertType == domain.AlertSecurityBreach &&
+			alert.OrganizationID == user.OrganizationID &&
+			alert.ResourceType == "user" &&
+			alert.ResourceID == user.ID
+	})).Return(nil).Once()
+
+	lockout.RecordFailure(ctx, "admin@example.com", "198.51.100.1", now)
+	mockAlertRepo.AssertNotCalled(t, "Create", mock.Anything)
+
+	lockout.RecordFailure(ctx, "admin@example.com", "198.51.100.1", now)
+	mockAlertRepo.AssertExpectations(t)
+}
+
+func TestLoginLockout_NilIsDisabled(t *testing.T) {
+	var lockout *LoginLockout
+	ctx := context.Background()
+
+	assert.Zero(t, lockout.RecordFailure(ctx, "dev@example.com", "198.51.100.1", time.Now()))
+	assert.Zero(t, lockout.RetryAfter(ctx, "dev@example.com", "198.51.100.1", time.Now()))
+	lockout.RecordSuccess(ctx, "dev@example.com")
+}
@@ -3,7 +3,9 @@ package application
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -20,6 +22,8 @@ type MCPAttestationService struct {
 	userRepo        *repository.UserRepository
 	connectionRepo  *repository.AgentMCPConnectionRepository
 	cryptoService   *infracrypto.ED25519Service
+	mcpService      *MCPService
+	activity        agentActivityRecorder
 }
 
 func NewMCPAttestationService(
@@ -28,6 +32,7 @@ func NewMCPAttestationService(
 	mcpRepo *repository.MCPServerRepository,
 	userRepo *repository.UserRepository,
 	connectionRepo *repository.AgentMCPConnectionRepository,
+	mcpService *MCPService,
 ) *MCPAttestationService {
 	return &MCPAttestationService{
 		attestationRepo: attestationRepo,
@@ -36,6 +41,8 @@ func NewMCPAttestationService(
 		userRepo:        userRepo,
 		connectionRepo:  connectionRepo,
 		cryptoService:   infracrypto.NewED25519Service(),
+		mcpService:      mcpService,
+		activity:        agentRepo,
 	}
 }
 
@@ -81,51 +88,21 @@ func (s *MCPAttestationService) VerifyAndRecordAttestation(
 
 	fmt.Printf("✅ Agent status check passed: %s\n", agent.Status)
 
-	if agent.PublicKey == nil || *agent.PublicKey == "" {
-		return nil, fmt.Errorf("agent has no public key registered")
-	}
-
-	// 3. Verify signature using agent's public key
-	attestationJSON, err := req.Attestation.ToCanonicalJSON()
-	if err != nil {
-		return nil, fmt.Errorf("failed to serialize attestation: %w", err)
-	}
-
-	// Debug: Print what we're verifying
-	fmt.Printf("🔍 Backend attestation verification:\n")
-	fmt.Printf("   Canonical JSON (first 200): %s\n", string(attestationJSON)[:min(200, len(attestationJSON))])
-	fmt.Printf("   Signature (first 40): %s\n", req.Signature[:min(40, len(req.Signature))])
-	fmt.Printf("   Agent public key (first 20): %s\n", (*agent.PublicKey)[:min(20, len(*agent.PublicKey))])
-
-	valid, err := s.cryptoService.Verify(*agent.PublicKey, attestationJSON, req.Signature)
+	// 3. Verify MCP server exists
+	server, err := s.mcpRepo.GetByID(mcpServerID)
 	if err != nil {
-		fmt.Printf("❌ Crypto verification error: %v\n", err)
-		return nil, fmt.Errorf("signature verification failed: %w", err)
+		return nil, fmt.Errorf("mcp server not found: %w", err)
 	}
 
-	if !valid {
-		fmt.Printf("❌ Signature verification returned FALSE\n")
-		return nil, fmt.Errorf("invalid attestation signature")
+	// 4. Verify signature, MCP binding and timestamp
+	if err := s.validateAttestation(agent, server, req, time.Now()); err != nil {
+		fmt.Printf("❌ Attestation rejected for agent %s: %v\n", agent.ID, err)
+		return nil, err
 	}
 
 	fmt.Printf("✅ Attestation signature verification PASSED\n")
 
-	// 4. Check attestation is recent (< 5 minutes old)
-	attestationTime, err := time.Parse(time.RFC3339, req.Attestation.Timestamp)
-	if err != nil {
-		return nil, fmt.Errorf("invalid timestamp format: %w", err)
-	}
-
-	if time.Since(attestationTime) > 5*time.Minute {
-		return nil, fmt.Errorf("attestation expired (older than 5 minutes)")
-	}
-
-	// 5. Verify MCP server exists
-	if _, err := s.mcpRepo.GetByID(mcpServerID); err != nil {
-		return nil, fmt.Errorf("mcp server not found: %w", err)
-	}
-
-	// 6. Store attestation
+	// 5. Store attestation, rejecting replays of one that was already recorded
 	now := time.Now().UTC()
 	attestation := &domain.MCPAttestation{
 		ID:                uuid.New(),
@@ -141,16 +118,20 @@ func (s *MCPAttestationService) VerifyAndRecordAttestation(
 	}
 
 	if err := s.attestationRepo.CreateAttestation(attestation); err != nil {
+		if errors.Is(err, domain.ErrAttestationSignatureExists) {
+			return nil, ErrAttestationReplayed
+		}
 		return nil, fmt.Errorf("failed to store attestation: %w", err)
 	}
+	recordAgentActivity(ctx, s.activity, agentID)
 
-	// 7. Update MCP confidence score
+	// 6. Update MCP confidence score
 	confidenceScore, attestationCount, err := s.updateMCPConfidenceScore(ctx, mcpServerID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to update confidence score: %w", err)
 	}
 
-	// 8. Update or create agent-MCP connection
+	// 7. Update or create agent-MCP connection
 	if err := s.updateAgentMCPConnection(ctx, agentID, mcpServerID, now); err != nil {
 		return nil, fmt.Errorf("failed to update agent-MCP connection: %w", err)
 	}
@@ -164,6 +145,69 @@ func (s *MCPAttestationService) VerifyAndRecordAttestation(
 	}, nil
 }
 
+// MaxAttestationAge is how old an attestation timestamp may be when it is submitted
+const MaxAttestationAge = 5 * time.Minute
+
+// MaxAttestationClockSkew is how far in the future an attestation timestamp may be
+const MaxAttestationClockSkew = time.Minute
+
+var (
+	ErrAttestationNoPublicKey      = errors.New("agent has no public key registered")
+	ErrAttestationInvalidSignature = errors.New("invalid attestation signature")
+	ErrAttestationExpired          = errors.New("attestation expired (older than 5 minutes)")
+	ErrAttestationFromFuture       = errors.New("attestation timestamp is in the future")
+	ErrAttestationMCPMismatch      = errors.New("attestation was signed for a different MCP server")
+	ErrAttestationReplayed         = errors.New("attestation has already been submitted")
+	ErrAttestationInvalidTimestamp = errors.New("invalid timestamp format")
+	ErrAttestationNotFound         = errors.New("attestation not found")
+)
+
+// validateAttestation checks the agent's Ed25519 signature over the canonical attestation payload,
+// that the payload names this MCP server, and that its timestamp is within the accepted skew
+func (s *MCPAttestationService) validateAttestation(
+	agent *domain.Agent,
+	server *domain.MCPServer,
+	req *AttestMCPRequest,
+	now time.Time,
+) error {
+	if agent.PublicKey == nil || *agent.PublicKey == "" {
+		return ErrAttestationNoPublicKey
+	}
+
+	attestationJSON, err := req.Attestation.ToCanonicalJSON()
+	if err != nil {
+		return fmt.Errorf("failed to serialize attestation: %w", err)
+	}
+
+	valid, err := s.cryptoService.Verify(*agent.PublicKey, attestationJSON, req.Signature)
+	if err != nil || !valid {
+		return ErrAttestationInvalidSignature
+	}
+
+	// The signed payload must name this server, so it cannot be replayed against another MCP.
+	// Older SDKs do not sign mcp_id; for those the signed URL must match instead.
+	if req.Attestation.MCPID != "" {
+		if req.Attestation.MCPID != server.ID.String() {
+			return ErrAttestationMCPMismatch
+		}
+	} else if strings.TrimSuffix(req.Attestation.MCPURL, "/") != strings.TrimSuffix(server.URL, "/") {
+		return ErrAttestationMCPMismatch
+	}
+
+	attestationTime, err := time.Parse(time.RFC3339, req.Attestation.Timestamp)
+	if err != nil {
+		return ErrAttestationInvalidTimestamp
+	}
+	if now.Sub(attestationTime) > MaxAttestationAge {
+		return ErrAttestationExpired
+	}
+	if attestationTime.Sub(now) > MaxAttestationClockSkew {
+		return ErrAttestationFromFuture
+	}
+
+	return nil
+}
+
 // updateMCPConfidenceScore calculates and updates the confidence score for an MCP server
 func (s *MCPAttestationService) updateMCPConfidenceScore(
 	ctx context.Context,
@@ -175,6 +219,13 @@ func (s *MCPAttestationService) updateMCPConfidenceScore(
 		return 0, 0, err
 	}
 
+	// Attesting agents also determine the MCP's trust score
+	if s.mcpService != nil {
+		if _, err := s.mcpService.RecalculateTrust(ctx, mcpServerID); err != nil {
+			fmt.Printf("⚠️  Warning: failed to recalculate trust for MCP %s: %v\n", mcpServerID, err)
+		}
+	}
+
 	if len(attestations) == 0 {
 		// No attestations - confidence is 0
 		return 0, 0, nil
@@ -480,6 +531,41 @@ func (s *MCPAttestationService) GetMCPServersForAgent(
 	return mcpServers, nil
 }
 
+// RevokeAttestation invalidates an attestation of one of the organization's MCP servers and
+// recalculates the server's confidence and trust scores without it
+func (s *MCPAttestationService) RevokeAttestation(
+	ctx context.Context,
+	organizationID uuid.UUID,
+	mcpServerID uuid.UUID,
+	attestationID uuid.UUID,
+) (*AttestMCPResponse, error) {
+	mcpServer, err := s.mcpRepo.GetByID(mcpServerID)
+	if err != nil || mcpServer.OrganizationID != organizationID {
+		return nil, ErrAttestationNotFound
+	}
+	attestation, err := s.attestationRepo.GetAttestationByID(attestationID)
+	if err != nil || attestation.MCPServerID != mcpServerID {
+		return nil, ErrAttestationNotFound
+	}
+
+	if err := s.attestationRepo.InvalidateAttestation(attestationID); err != nil {
+		return nil, err
+	}
+
+	confidenceScore, attestationCount, err := s.updateMCPConfidenceScore(ctx, mcpServerID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update confidence score: %w", err)
+	}
+
+	return &AttestMCPResponse{
+		Success:            true,
+		AttestationID:      attestationID.String(),
+		MCPConfidenceScore: confidenceScore,
+		AttestationCount:   attestationCount,
+		Message:            "Attestation revoked",
+	}, nil
+}
+
 // InvalidateExpiredAttestations is a background job to invalidate expired attestations
 func (s *MCPAttestationService) InvalidateExpiredAttestations(ctx context.Context) error {
 	return s.attestationRepo.InvalidateExpiredAttestations()
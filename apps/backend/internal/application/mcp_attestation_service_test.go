@@ -0,0 +1,119 @@
+package application
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/domain"
+	infracrypto "github.com/opena2a/identity/backend/internal/infrastructure/crypto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newAttestationFixture(t *testing.T, now time.Time) (*MCPAttestationService, *domain.Agent, *domain.MCPServer, ed25519.PrivateKey, domain.AttestationPayload) {
+	t.Helper()
+
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	pubB64 := base64.StdEncoding.EncodeToString(pub)
+
+	agent := &domain.Agent{
+		ID:        uuid.New(),
+		Status:    domain.AgentStatusVerified,
+		PublicKey: &pubB64,
+	}
+	server := &domain.MCPServer{
+		ID:  uuid.New(),
+		URL: "https://mcp.example.com",
+	}
+	payload := domain.AttestationPayload{
+		AgentID:              agent.ID.String(),
+		CapabilitiesFound:    []string{"read_file", "list_directory"},
+		ConnectionSuccessful: true,
+		HealthCheckPassed:    true,
+		MCPID:                server.ID.String(),
+		MCPName:              "filesystem",
+		MCPURL:               server.URL,
+		SDKVersion:           "1.0.0",
+		Timestamp:            now.Format(time.RFC3339),
+	}
+
+	service := &MCPAttestationService{cryptoService: infracrypto.NewED25519Service()}
+	return service, agent, server, priv, payload
+}
+
+func signAttestation(t *testing.T, key ed25519.PrivateKey, payload domain.AttestationPayload) *AttestMCPRequest {
+	t.Helper()
+
+	msg, err := payload.ToCanonicalJSON()
+	require.NoError(t, err)
+	return &AttestMCPRequest{
+		Attestation: payload,
+		Signature:   base64.StdEncoding.EncodeToString(ed25519.Sign(key, msg)),
+	}
+}
+
+func TestMCPAttestationService_ValidateAttestation_ValidSignature(t *testing.T) {
+	now := time.Now()
+	service, agent, server, key, payload := newAttestationFixture(t, now)
+
+	err := service.validateAttestation(agent, server, signAttestation(t, key, payload), now)
+	assert.NoError(t, err)
+}
+
+func TestMCPAttestationService_ValidateAttestation_WrongKey(t *testing.T) {
+	now := time.Now()
+	service, agent, server, _, payload := newAttestationFixture(t, now)
+
+	_, otherKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	err = service.validateAttestation(agent, server, signAttestation(t, otherKey, payload), now)
+	assert.ErrorIs(t, err, ErrAttestationInvalidSignature)
+}
+
+func TestMCPAttestationService_ValidateAttestation_TamperedPayload(t *testing.T) {
+	now := time.Now()
+	service, agent, server, key, payload := newAttestationFixture(t, now)
+
+	req := signAttestation(t, key, payload)
+	req.Attestation.CapabilitiesFound = append(req.Attestation.CapabilitiesFound, "write_file")
+
+	err := service.validateAttestation(agent, server, req, now)
+	assert.ErrorIs(t, err, ErrAttestationInvalidSignature)
+}
+
+func TestMCPAttestationService_ValidateAttestation_StaleTimestamp(t *testing.T) {
+	now := time.Now()
+	service, agent, server, key, payload := newAttestationFixture(t, now.Add(-10*time.Minute))
+
+	err := service.validateAttestation(agent, server, signAttestation(t, key, payload), now)
+	assert.ErrorIs(t, err, ErrAttestationExpired)
+}
+
+func TestMCPAttestationService_ValidateAttestation_FutureTimestamp(t *testing.T) {
+	now := time.Now()
+	service, agent, server, key, payload := newAttestationFixture(t, now.Add(5*time.Minute))
+
+	err := service.validateAttestation(agent, server, signAttestation(t, key, payload), now)
+	assert.ErrorIs(t, err, ErrAttestationFromFuture)
+}
+
+func TestMCPAttestationService_ValidateAttestation_DifferentMCP(t *testing.T) {
+	now := time.Now()
+	service, agent, server, key, payload := newAttestationFixture(t, now)
+
+	otherServer := &domain.MCPServer{ID: uuid.New(), URL: server.URL}
+	err := service.validateAttestation(agent, otherServer, signAttestation(t, key, payload), now)
+	assert.ErrorIs(t, err, ErrAttestationMCPMismatch)
+
+	// Without a signed mcp_id the signed URL must match the server
+	payload.MCPID = ""
+	payload.MCPURL = "https://elsewhere.example.com"
+	err = service.validateAttestation(agent, server, signAttestation(t, key, payload), now)
+	assert.ErrorIs(t, err, ErrAttestationMCPMismatch)
+}
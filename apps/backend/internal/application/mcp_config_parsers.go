@@ -0,0 +1,236 @@
+package application
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// MCP config formats understood by auto-detection; the value is also used as DetectedMCPServer.Source
+const (
+	MCPConfigSourceClaudeDesktop = "claude_desktop_config"
+	MCPConfigSourceCursor        = "cursor"
+	MCPConfigSourceVSCode        = "vscode"
+)
+
+// MCPConfigParser extracts the MCP servers declared in one editor's config format
+type MCPConfigParser func(data []byte) ([]DetectedMCPServer, error)
+
+var (
+	mcpConfigParsersMu sync.RWMutex
+	mcpConfigParsers   = map[string]MCPConfigParser{
+		MCPConfigSourceClaudeDesktop: ParseClaudeDesktopConfigBytes,
+		MCPConfigSourceCursor:        ParseCursorConfigBytes,
+		MCPConfigSourceVSCode:        ParseVSCodeConfigBytes,
+	}
+)
+
+// RegisterMCPConfigParser adds (or replaces) the parser used for a config source
+func RegisterMCPConfigParser(source string, parser MCPConfigParser) {
+	mcpConfigParsersMu.Lock()
+	defer mcpConfigParsersMu.Unlock()
+	mcpConfigParsers[source] = parser
+}
+
+// ParseMCPConfigBytes parses an MCP config using the parser registered for source.
+// An empty source is inferred from the shape of the config.
+func ParseMCPConfigBytes(source string, data []byte) ([]DetectedMCPServer, error) {
+	if source == "" {
+		source = detectMCPConfigSource(data)
+	}
+
+	mcpConfigParsersMu.RLock()
+	parser, ok := mcpConfigParsers[source]
+	mcpConfigParsersMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unsupported config source %q", source)
+	}
+
+	return parser(data)
+}
+
+// mcpServerConfigEntry is a single server entry; all supported editors share these fields
+type mcpServerConfigEntry struct {
+	Command string            `json:"command"`
+	Args    []string          `json:"args"`
+	Env     map[string]string `json:"env"`
+	URL     string            `json:"url"` // Remote servers (Cursor, VS Code)
+}
+
+// ParseClaudeDesktopConfigBytes extracts the MCP servers declared in the contents of a
+// Claude Desktop config (claude_desktop_config.json), sorted by name
+func ParseClaudeDesktopConfigBytes(data []byte) ([]DetectedMCPServer, error) {
+	var config struct {
+		MCPServers map[string]mcpServerConfigEntry `json:"mcpServers"`
+	}
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("failed to parse config JSON: %w", err)
+	}
+
+	return detectedMCPServers(config.MCPServers, MCPConfigSourceClaudeDesktop), nil
+}
+
+// ParseCursorConfigBytes extracts the MCP servers declared in a Cursor config (.cursor/mcp.json).
+// Cursor uses the Claude Desktop layout but also allows remote servers declared by url.
+func ParseCursorConfigBytes(data []byte) ([]DetectedMCPServer, error) {
+	var config struct {
+		MCPServers map[string]mcpServerConfigEntry `json:"mcpServers"`
+	}
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("failed to parse config JSON: %w", err)
+	}
+
+	return detectedMCPServers(config.MCPServers, MCPConfigSourceCursor), nil
+}
+
+// ParseVSCodeConfigBytes extracts the MCP servers declared in VS Code config: either a workspace
+// .vscode/mcp.json ({"servers": {...}}) or user settings.json ({"mcp": {"servers": {...}}}).
+// VS Code files are JSONC, so comments and trailing commas are allowed.
+func ParseVSCodeConfigBytes(data []byte) ([]DetectedMCPServer, error) {
+	var config struct {
+		Servers map[string]mcpServerConfigEntry `json:"servers"`
+		MCP     struct {
+			Servers map[string]mcpServerConfigEntry `json:"servers"`
+		} `json:"mcp"`
+	}
+	if err := json.Unmarshal(stripJSONC(data), &config); err != nil {
+		return nil, fmt.Errorf("failed to parse config JSON: %w", err)
+	}
+
+	servers := config.Servers
+	if len(servers) == 0 {
+		servers = config.MCP.Servers
+	}
+	return detectedMCPServers(servers, MCPConfigSourceVSCode), nil
+}
+
+// detectedMCPServers normalizes config entries to DetectedMCPServer, sorted by name
+func detectedMCPServers(entries map[string]mcpServerConfigEntry, source string) []DetectedMCPServer {
+	detectedServers := []DetectedMCPServer{}
+	for name, entry := range entries {
+		detectedServers = append(detectedServers, DetectedMCPServer{
+			Name:       name,
+			Command:    entry.Command,
+			Args:       entry.Args,
+			Env:        entry.Env,
+			URL:        entry.URL,
+			Confidence: 100.0, // High confidence for config file detection
+			Source:     source,
+		})
+	}
+
+	sort.Slice(detectedServers, func(i, j int) bool {
+		return detectedServers[i].Name < detectedServers[j].Name
+	})
+
+	return detectedServers
+}
+
+// detectMCPConfigSource guesses the format of a config from its top-level keys. Cursor and
+// Claude Desktop share a layout, so mcpServers configs are treated as Claude Desktop.
+func detectMCPConfigSource(data []byte) string {
+	var keys map[string]json.RawMessage
+	if err := json.Unmarshal(stripJSONC(data), &keys); err != nil {
+		return MCPConfigSourceClaudeDesktop
+	}
+	if _, ok := keys["mcpServers"]; ok {
+		return MCPConfigSourceClaudeDesktop
+	}
+	if _, ok := keys["servers"]; ok {
+		return MCPConfigSourceVSCode
+	}
+	if _, ok := keys["mcp"]; ok {
+		return MCPConfigSourceVSCode
+	}
+	return MCPConfigSourceClaudeDesktop
+}
+
+// mcpConfigSourceFromPath infers the config format from its file path
+func mcpConfigSourceFromPath(configPath string) string {
+	normalized := filepath.ToSlash(configPath)
+	switch {
+	case strings.Contains(normalized, ".cursor/"):
+		return MCPConfigSourceCursor
+	case strings.Contains(normalized, ".vscode/"),
+		strings.Contains(normalized, "/Code/User/"),
+		strings.Contains(normalized, "/Code - Insiders/User/"):
+		return MCPConfigSourceVSCode
+	default:
+		return ""
+	}
+}
+
+// stripJSONC removes // and /* */ comments and trailing commas outside of strings
+func stripJSONC(data []byte) []byte {
+	return stripTrailingCommas(stripJSONComments(data))
+}
+
+// stripJSONComments removes // and /* */ comments outside of strings
+func stripJSONComments(data []byte) []byte {
+	var out bytes.Buffer
+	inString := false
+	for i := 0; i < len(data); i++ {
+		c := data[i]
+		switch {
+		case inString:
+			out.WriteByte(c)
+			if c == '\\' && i+1 < len(data) {
+				i++
+				out.WriteByte(data[i])
+			} else if c == '"' {
+				inString = false
+			}
+		case c == '"':
+			inString = true
+			out.WriteByte(c)
+		case c == '/' && i+1 < len(data) && data[i+1] == '/':
+			for i+1 < len(data) && data[i+1] != '\n' {
+				i++
+			}
+		case c == '/' && i+1 < len(data) && data[i+1] == '*':
+			i += 2
+			for i+1 < len(data) && !(data[i] == '*' && data[i+1] == '/') {
+				i++
+			}
+			i++
+		default:
+			out.WriteByte(c)
+		}
+	}
+	return out.Bytes()
+}
+
+// stripTrailingCommas drops commas that directly precede a closing } or ] outside of strings
+func stripTrailingCommas(data []byte) []byte {
+	var out bytes.Buffer
+	inString := false
+	for i := 0; i < len(data); i++ {
+		c := data[i]
+		switch {
+		case inString:
+			out.WriteByte(c)
+			if c == '\\' && i+1 < len(data) {
+				i++
+				out.WriteByte(data[i])
+			} else if c == '"' {
+				inString = false
+			}
+		case c == '"':
+			inString = true
+			out.WriteByte(c)
+		case c == ',':
+			next := bytes.TrimLeft(data[i+1:], " \t\r\n")
+			if len(next) > 0 && (next[0] == '}' || next[0] == ']') {
+				continue
+			}
+			out.WriteByte(c)
+		default:
+			out.WriteByte(c)
+		}
+	}
+	return out.Bytes()
+}
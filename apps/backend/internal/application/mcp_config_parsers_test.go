@@ -0,0 +1,129 @@
+package application
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func readMCPConfigFixture(t *testing.T, name string) []byte {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("testdata", "mcp_configs", name))
+	require.NoError(t, err)
+	return data
+}
+
+func TestParseMCPConfigBytes_ClaudeDesktop(t *testing.T) {
+	servers, err := ParseMCPConfigBytes(MCPConfigSourceClaudeDesktop, readMCPConfigFixture(t, "claude_desktop_config.json"))
+	require.NoError(t, err)
+	require.Len(t, servers, 2)
+
+	assert.Equal(t, "filesystem", servers[0].Name)
+	assert.Equal(t, "github", servers[1].Name)
+	assert.Equal(t, "npx", servers[1].Command)
+	assert.Equal(t, "ghp_example", servers[1].Env["GITHUB_PERSONAL_ACCESS_TOKEN"])
+	for _, server := range servers {
+		assert.Equal(t, MCPConfigSourceClaudeDesktop, server.Source)
+	}
+}
+
+func TestParseMCPConfigBytes_Cursor(t *testing.T) {
+	servers, err := ParseMCPConfigBytes(MCPConfigSourceCursor, readMCPConfigFixture(t, "cursor_mcp.json"))
+	require.NoError(t, err)
+	require.Len(t, servers, 2)
+
+	assert.Equal(t, "linear", servers[0].Name)
+	assert.Equal(t, "https://mcp.linear.app/sse", servers[0].URL)
+	assert.Empty(t, servers[0].Command)
+	assert.Equal(t, "postgres", servers[1].Name)
+	assert.Equal(t, "uvx", servers[1].Command)
+	assert.Equal(t, []string{"mcp-server-postgres", "postgresql://localhost/app"}, servers[1].Args)
+	for _, server := range servers {
+		assert.Equal(t, MCPConfigSourceCursor, server.Source)
+	}
+}
+
+func TestParseMCPConfigBytes_VSCodeWorkspace(t *testing.T) {
+	servers, err := ParseMCPConfigBytes(MCPConfigSourceVSCode, readMCPConfigFixture(t, "vscode_mcp.json"))
+	require.NoError(t, err)
+	require.Len(t, servers, 2)
+
+	assert.Equal(t, "docs", servers[0].Name)
+	assert.Equal(t, "https://docs.example.com/mcp", servers[0].URL)
+	assert.Equal(t, "github", servers[1].Name)
+	assert.Equal(t, "docker", servers[1].Command)
+	assert.Equal(t, "${input:github-token}", servers[1].Env["GITHUB_PERSONAL_ACCESS_TOKEN"])
+	for _, server := range servers {
+		assert.Equal(t, MCPConfigSourceVSCode, server.Source)
+	}
+}
+
+func TestParseMCPConfigBytes_VSCodeUserSettings(t *testing.T) {
+	servers, err := ParseMCPConfigBytes(MCPConfigSourceVSCode, readMCPConfigFixture(t, "vscode_settings.json"))
+	require.NoError(t, err)
+	require.Len(t, servers, 1)
+
+	assert.Equal(t, "fetch", servers[0].Name)
+	assert.Equal(t, "uvx", servers[0].Command)
+	assert.Equal(t, MCPConfigSourceVSCode, servers[0].Source)
+}
+
+func TestParseMCPConfigBytes_InfersSourceFromContent(t *testing.T) {
+	servers, err := ParseMCPConfigBytes("", readMCPConfigFixture(t, "vscode_mcp.json"))
+	require.NoError(t, err)
+	require.NotEmpty(t, servers)
+	assert.Equal(t, MCPConfigSourceVSCode, servers[0].Source)
+
+	servers, err = ParseMCPConfigBytes("", readMCPConfigFixture(t, "claude_desktop_config.json"))
+	require.NoError(t, err)
+	require.NotEmpty(t, servers)
+	assert.Equal(t, MCPConfigSourceClaudeDesktop, servers[0].Source)
+
+	_, err = ParseMCPConfigBytes("zed", readMCPConfigFixture(t, "claude_desktop_config.json"))
+	assert.Error(t, err)
+}
+
+func TestMCPConfigSourceFromPath(t *testing.T) {
+	assert.Equal(t, MCPConfigSourceCursor, mcpConfigSourceFromPath("/home/dev/project/.cursor/mcp.json"))
+	assert.Equal(t, MCPConfigSourceVSCode, mcpConfigSourceFromPath("/home/dev/project/.vscode/mcp.json"))
+	assert.Equal(t, MCPConfigSourceVSCode, mcpConfigSourceFromPath("/Users/dev/Library/Application Support/Code/User/settings.json"))
+	assert.Empty(t, mcpConfigSourceFromPath("/Users/dev/Library/Application Support/Claude/claude_desktop_config.json"))
+}
+
+func TestAgentService_DetectMCPServersFromConfig_CursorPath(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), ".cursor")
+	require.NoError(t, os.MkdirAll(dir, 0o755))
+	configPath := filepath.Join(dir, "mcp.json")
+	require.NoError(t, os.WriteFile(configPath, readMCPConfigFixture(t, "cursor_mcp.json"), 0o600))
+
+	service := &AgentService{}
+	result, err := service.DetectMCPServersFromConfig(context.Background(), uuid.New(),
+		&DetectMCPServersRequest{ConfigPath: configPath, DryRun: true}, nil, uuid.New(), uuid.New())
+	require.NoError(t, err)
+	require.Len(t, result.DetectedServers, 2)
+	for _, server := range result.DetectedServers {
+		assert.Equal(t, MCPConfigSourceCursor, server.Source)
+		assert.Equal(t, configPath, server.Metadata["config_path"])
+	}
+}
+
+func TestRegisterMCPConfigParser(t *testing.T) {
+	RegisterMCPConfigParser("test_editor", func(data []byte) ([]DetectedMCPServer, error) {
+		return []DetectedMCPServer{{Name: string(data), Source: "test_editor"}}, nil
+	})
+	defer func() {
+		mcpConfigParsersMu.Lock()
+		delete(mcpConfigParsers, "test_editor")
+		mcpConfigParsersMu.Unlock()
+	}()
+
+	servers, err := ParseMCPConfigBytes("test_editor", []byte("custom"))
+	require.NoError(t, err)
+	require.Len(t, servers, 1)
+	assert.Equal(t, "custom", servers[0].Name)
+}
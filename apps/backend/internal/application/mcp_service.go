@@ -4,9 +4,13 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"math"
 	"net/http"
+	"net/url"
+	"os"
 	"strings"
 	"time"
 
@@ -14,7 +18,9 @@ import (
 	"github.com/opena2a/identity/backend/internal/crypto"
 	"github.com/opena2a/identity/backend/internal/domain"
 	infracrypto "github.com/opena2a/identity/backend/internal/infrastructure/crypto"
+	"github.com/opena2a/identity/backend/internal/infrastructure/logging"
 	"github.com/opena2a/identity/backend/internal/infrastructure/repository"
+	"github.com/opena2a/identity/backend/internal/infrastructure/utils"
 )
 
 type MCPService struct {
@@ -27,7 +33,9 @@ type MCPService struct {
 	capabilityRepo        *repository.MCPServerCapabilityRepository // ✅ For creating SDK capabilities
 	connectionRepo        *repository.AgentMCPConnectionRepository  // ✅ For tracking agent-MCP connections
 	httpClient            *http.Client           // ✅ For real MCP server communication
+	healthClient          *http.Client           // Refuses internal addresses, since server URLs are user-supplied
 	agentRepo             *repository.AgentRepository // ✅ For querying connected agents
+	attestationRepo       *repository.MCPAttestationRepository // ✅ For deriving trust from attestations
 	// In-memory challenge storage (in production, use Redis)
 	challenges map[string]ChallengeData
 }
@@ -40,7 +48,7 @@ type ChallengeData struct {
 	ExpiresAt time.Time
 }
 
-func NewMCPService(mcpRepo *repository.MCPServerRepository, verificationEventRepo domain.VerificationEventRepository, userRepo *repository.UserRepository, keyVault *crypto.KeyVault, capabilityService *MCPCapabilityService, capabilityRepo *repository.MCPServerCapabilityRepository, connectionRepo *repository.AgentMCPConnectionRepository, agentRepo *repository.AgentRepository) *MCPService {
+func NewMCPService(mcpRepo *repository.MCPServerRepository, verificationEventRepo domain.VerificationEventRepository, userRepo *repository.UserRepository, keyVault *crypto.KeyVault, capabilityService *MCPCapabilityService, capabilityRepo *repository.MCPServerCapabilityRepository, connectionRepo *repository.AgentMCPConnectionRepository, agentRepo *repository.AgentRepository, attestationRepo *repository.MCPAttestationRepository) *MCPService {
 	return &MCPService{
 		mcpRepo:               mcpRepo,
 		verificationEventRepo: verificationEventRepo,
@@ -53,8 +61,10 @@ func NewMCPService(mcpRepo *repository.MCPServerRepository, verificationEventRep
 		httpClient: &http.Client{
 			Timeout: 30 * time.Second, // 30 second timeout for MCP server communication
 		},
-		challenges: make(map[string]ChallengeData),
-		agentRepo:  agentRepo,
+		healthClient:    utils.NewPublicHTTPClient(DefaultMCPHealthCheckTimeout),
+		challenges:      make(map[string]ChallengeData),
+		agentRepo:       agentRepo,
+		attestationRepo: attestationRepo,
 	}
 }
 
@@ -452,7 +462,98 @@ func (s *MCPService) AddPublicKey(ctx context.Context, serverID uuid.UUID, req *
 
 // GetVerificationStatus retrieves the verification status of an MCP server
 func (s *MCPService) GetVerificationStatus(ctx context.Context, id uuid.UUID) (*domain.MCPServerVerificationStatus, error) {
-	return s.mcpRepo.GetVerificationStatus(id)
+	status, err := s.mcpRepo.GetVerificationStatus(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if s.attestationRepo != nil {
+		attestations, err := s.attestationRepo.GetAttestationsByMCP(id)
+		if err != nil {
+			return nil, err
+		}
+		status.TrustFactors = CalculateMCPTrust(attestations, time.Now())
+	}
+
+	return status, nil
+}
+
+// MCPTrustAttestationHalfLife is the attestation age at which its weight is halved
+const MCPTrustAttestationHalfLife = 7 * 24 * time.Hour
+
+// MCPTrustFullCoverageAttesters is the number of fresh attesting agents needed for full coverage
+const MCPTrustFullCoverageAttesters = 3.0
+
+// RecalculateTrust derives an MCP server's trust score from the agents attesting to it
+// and stores it on the server
+func (s *MCPService) RecalculateTrust(ctx context.Context, id uuid.UUID) (*domain.MCPTrustFactors, error) {
+	if s.attestationRepo == nil {
+		return nil, fmt.Errorf("attestation repository not configured")
+	}
+
+	attestations, err := s.attestationRepo.GetAttestationsByMCP(id)
+	if err != nil {
+		return nil, err
+	}
+
+	factors := CalculateMCPTrust(attestations, time.Now())
+	if err := s.mcpRepo.UpdateTrustScore(id, factors.Score); err != nil {
+		return nil, err
+	}
+
+	return factors, nil
+}
+
+// CalculateMCPTrust computes an age-weighted average of attesting-agent trust, scaled by how many
+// agents recently attested, as a 0-100 score. Only each agent's latest valid attestation counts; revoked attestations
+// are excluded and reported.
+func CalculateMCPTrust(attestations []*domain.MCPAttestation, now time.Time) *domain.MCPTrustFactors {
+	factors := &domain.MCPTrustFactors{CalculatedAt: now}
+
+	latest := make(map[uuid.UUID]*domain.MCPAttestation)
+	for _, att := range attestations {
+		if !att.IsValid {
+			factors.RevokedAttestations++
+			continue
+		}
+		if att.AgentID == nil || now.After(att.ExpiresAt) {
+			continue
+		}
+		if prev, ok := latest[*att.AgentID]; !ok || attestedAt(att).After(attestedAt(prev)) {
+			latest[*att.AgentID] = att
+		}
+	}
+
+	var weightedTrust float64
+	for _, att := range latest {
+		age := now.Sub(attestedAt(att))
+		if age < 0 {
+			age = 0
+		}
+		weight := math.Pow(0.5, age.Hours()/MCPTrustAttestationHalfLife.Hours())
+		factors.EffectiveAttesters += weight
+		weightedTrust += weight * att.AgentTrustScore
+	}
+
+	factors.AttestingAgents = len(latest)
+	if factors.EffectiveAttesters == 0 {
+		return factors
+	}
+
+	factors.WeightedAgentTrust = weightedTrust / factors.EffectiveAttesters
+	factors.Coverage = math.Min(1.0, factors.EffectiveAttesters/MCPTrustFullCoverageAttesters)
+	// Agent trust is 0-1 while MCP server trust scores are stored 0-100
+	factors.Score = math.Round(factors.WeightedAgentTrust*factors.Coverage*100*100) / 100
+
+	return factors
+}
+
+// attestedAt returns when an attestation was verified, falling back to its creation time
+func attestedAt(att *domain.MCPAttestation) time.Time {
+	if att.VerifiedAt != nil {
+		return *att.VerifiedAt
+	}
+	return att.CreatedAt
 }
 
 // GenerateVerificationChallenge generates a challenge for server verification
@@ -672,3 +773,126 @@ func (s *MCPService) GetConnectedAgentsCount(ctx context.Context, mcpServerID uu
 	}
 	return len(agents), nil
 }
+
+const (
+	// DefaultMCPHealthCheckInterval is how often the poller probes every MCP server
+	DefaultMCPHealthCheckInterval = 5 * time.Minute
+	// DefaultMCPHealthCheckTimeout bounds a single health probe
+	DefaultMCPHealthCheckTimeout = 5 * time.Second
+)
+
+// MCPHealthCheckIntervalFromEnv reads MCP_HEALTH_CHECK_INTERVAL, falling back to the default
+func MCPHealthCheckIntervalFromEnv() time.Duration {
+	if value, err := time.ParseDuration(os.Getenv("MCP_HEALTH_CHECK_INTERVAL")); err == nil && value > 0 {
+		return value
+	}
+	return DefaultMCPHealthCheckInterval
+}
+
+// CheckHealth probes an MCP server's URL and records the outcome on the server
+func (s *MCPService) CheckHealth(ctx context.Context, id uuid.UUID) (*domain.MCPHealthCheckResult, error) {
+	server, err := s.mcpRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	return s.checkServerHealth(ctx, server)
+}
+
+// checkServerHealth probes a server that has already been loaded and records the outcome
+func (s *MCPService) checkServerHealth(ctx context.Context, server *domain.MCPServer) (*domain.MCPHealthCheckResult, error) {
+	result := probeMCPServerHealth(ctx, s.healthClient, server.URL, DefaultMCPHealthCheckTimeout)
+	result.ServerID = server.ID
+
+	if err := s.mcpRepo.UpdateHealth(server.ID, result.Status, result.CheckedAt); err != nil {
+		return nil, fmt.Errorf("failed to record health check: %w", err)
+	}
+
+	server.HealthStatus = result.Status
+	server.LastHealthCheck = &result.CheckedAt
+	return result, nil
+}
+
+// CheckAllHealth probes every MCP server in an active organization and returns how many were checked
+func (s *MCPService) CheckAllHealth(ctx context.Context) (int, error) {
+	servers, err := s.mcpRepo.GetHealthCheckTargets()
+	if err != nil {
+		return 0, err
+	}
+
+	checked := 0
+	for _, server := range servers {
+		if ctx.Err() != nil {
+			return checked, ctx.Err()
+		}
+		if _, err := s.checkServerHealth(ctx, server); err != nil {
+			logging.FromContext(ctx).Warn("MCP server health check failed", "mcp_server_id", server.ID, "error", err)
+			continue
+		}
+		checked++
+	}
+
+	return checked, nil
+}
+
+// StartHealthCheckPoller runs CheckAllHealth every interval until ctx is cancelled
+func (s *MCPService) StartHealthCheckPoller(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := s.CheckAllHealth(ctx); err != nil && ctx.Err() == nil {
+				logging.FromContext(ctx).Error("MCP health check poll failed", "error", err)
+			}
+		}
+	}
+}
+
+// probeMCPServerHealth issues a lightweight GET to the server URL. Any response below 500
+// means the server is up; 5xx is unhealthy and connection errors or timeouts are unreachable.
+// Non-HTTP URLs such as mcp:// local placeholders and URLs the client refuses as internal are not
+// checkable. Dial errors are not reported, so probes cannot be used to map the network.
+func probeMCPServerHealth(ctx context.Context, client *http.Client, serverURL string, timeout time.Duration) *domain.MCPHealthCheckResult {
+	result := &domain.MCPHealthCheckResult{CheckedAt: time.Now().UTC()}
+
+	parsed, err := url.Parse(serverURL)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		result.Status = domain.MCPHealthStatusNotCheckable
+		return result
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, serverURL, nil)
+	if err != nil {
+		result.Status = domain.MCPHealthStatusNotCheckable
+		return result
+	}
+	req.Header.Set("User-Agent", "AIM-HealthCheck/1.0")
+
+	start := time.Now()
+	resp, err := client.Do(req)
+	result.LatencyMs = time.Since(start).Milliseconds()
+	if errors.Is(err, utils.ErrNonPublicAddress) {
+		result.Status = domain.MCPHealthStatusNotCheckable
+		return result
+	}
+	if err != nil {
+		result.Status = domain.MCPHealthStatusUnreachable
+		return result
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+
+	result.StatusCode = resp.StatusCode
+	if resp.StatusCode >= http.StatusInternalServerError {
+		result.Status = domain.MCPHealthStatusUnhealthy
+	} else {
+		result.Status = domain.MCPHealthStatusHealthy
+	}
+	return result
+}
@@ -0,0 +1,156 @@
+package application
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/domain"
+	"github.com/opena2a/identity/backend/internal/infrastructure/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProbeMCPServerHealth(t *testing.T) {
+	okServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer okServer.Close()
+
+	errorServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer errorServer.Close()
+
+	slowServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(2 * time.Second):
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer slowServer.Close()
+
+	tests := []struct {
+		name       string
+		url        string
+		wantStatus domain.MCPHealthStatus
+		wantCode   int
+	}{
+		{"200 is healthy", okServer.URL, domain.MCPHealthStatusHealthy, http.StatusOK},
+		{"500 is unhealthy", errorServer.URL, domain.MCPHealthStatusUnhealthy, http.StatusInternalServerError},
+		{"timeout is unreachable", slowServer.URL, domain.MCPHealthStatusUnreachable, 0},
+		{"mcp placeholder is not checkable", "mcp://local-filesystem", domain.MCPHealthStatusNotCheckable, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := probeMCPServerHealth(context.Background(), http.DefaultClient, tt.url, 100*time.Millisecond)
+
+			assert.Equal(t, tt.wantStatus, result.Status)
+			assert.Equal(t, tt.wantCode, result.StatusCode)
+			assert.False(t, result.CheckedAt.IsZero())
+		})
+	}
+}
+
+func TestProbeMCPServerHealth_RefusesInternalAddress(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("health probe reached a loopback server")
+	}))
+	defer server.Close()
+
+	client := utils.NewPublicHTTPClient(time.Second)
+	result := probeMCPServerHealth(context.Background(), client, server.URL, time.Second)
+
+	assert.Equal(t, domain.MCPHealthStatusNotCheckable, result.Status)
+	assert.Zero(t, result.StatusCode)
+}
+
+func newTestAttestation(trust float64, attestedAt time.Time) *domain.MCPAttestation {
+	agentID := uuid.New()
+	return &domain.MCPAttestation{
+		ID:              uuid.New(),
+		AgentID:         &agentID,
+		VerifiedAt:      &attestedAt,
+		ExpiresAt:       attestedAt.Add(30 * 24 * time.Hour),
+		IsValid:         true,
+		CreatedAt:       attestedAt,
+		AgentTrustScore: trust,
+	}
+}
+
+func TestCalculateMCPTrust_HighTrustAttestersRaiseScore(t *testing.T) {
+	now := time.Now()
+	attestations := []*domain.MCPAttestation{newTestAttestation(0.40, now)}
+
+	before := CalculateMCPTrust(attestations, now)
+
+	attestations = append(attestations,
+		newTestAttestation(0.95, now),
+		newTestAttestation(0.90, now.Add(-time.Hour)),
+	)
+	after := CalculateMCPTrust(attestations, now)
+
+	assert.Equal(t, 1, before.AttestingAgents)
+	assert.Equal(t, 3, after.AttestingAgents)
+	assert.Greater(t, after.WeightedAgentTrust, before.WeightedAgentTrust)
+	assert.Greater(t, after.Score, before.Score)
+}
+
+func TestCalculateMCPTrust_RevokedAttestationsLowerScore(t *testing.T) {
+	now := time.Now()
+	attestations := []*domain.MCPAttestation{
+		newTestAttestation(0.90, now),
+		newTestAttestation(0.90, now),
+		newTestAttestation(0.90, now),
+	}
+
+	before := CalculateMCPTrust(attestations, now)
+
+	attestations[1].IsValid = false
+	after := CalculateMCPTrust(attestations, now)
+
+	assert.Equal(t, 1, after.RevokedAttestations)
+	assert.Equal(t, 2, after.AttestingAgents)
+	assert.Less(t, after.Score, before.Score)
+}
+
+func TestCalculateMCPTrust_DecaysWithAttestationAge(t *testing.T) {
+	now := time.Now()
+	fresh := CalculateMCPTrust([]*domain.MCPAttestation{newTestAttestation(0.80, now)}, now)
+	stale := CalculateMCPTrust([]*domain.MCPAttestation{newTestAttestation(0.80, now.Add(-MCPTrustAttestationHalfLife))}, now)
+
+	assert.InDelta(t, 0.5, stale.EffectiveAttesters, 0.001)
+	assert.Less(t, stale.Score, fresh.Score)
+
+	// Older attestations carry less weight in the average
+	mixed := CalculateMCPTrust([]*domain.MCPAttestation{
+		newTestAttestation(0.90, now),
+		newTestAttestation(0.30, now.Add(-3*MCPTrustAttestationHalfLife)),
+	}, now)
+	assert.Greater(t, mixed.WeightedAgentTrust, 0.60)
+}
+
+func TestCalculateMCPTrust_ScoreIsOnHundredPointScale(t *testing.T) {
+	now := time.Now()
+	factors := CalculateMCPTrust([]*domain.MCPAttestation{
+		newTestAttestation(0.90, now),
+		newTestAttestation(0.90, now),
+		newTestAttestation(0.90, now),
+	}, now)
+
+	// Agent trust is 0-1; the MCP server's trust_score column is 0-100
+	assert.InDelta(t, 0.90, factors.WeightedAgentTrust, 0.001)
+	assert.Equal(t, 1.0, factors.Coverage)
+	assert.Equal(t, 90.0, factors.Score)
+}
+
+func TestCalculateMCPTrust_NoAttestations(t *testing.T) {
+	factors := CalculateMCPTrust(nil, time.Now())
+
+	assert.Equal(t, 0, factors.AttestingAgents)
+	assert.Equal(t, 0.0, factors.Score)
+}
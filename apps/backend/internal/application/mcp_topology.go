@@ -0,0 +1,138 @@
+package application
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/domain"
+)
+
+// DeclaredConnectionConfidence is the confidence of an edge the agent declared in talks_to or
+// whose connection was registered by a user or attested; detections only score the rest
+const DeclaredConnectionConfidence = 100.0
+
+// GetTopology returns the organization's agent↔MCP graph. It loads agents, MCP servers,
+// connections and detections with one query each, however large the organization.
+func (s *MCPService) GetTopology(ctx context.Context, orgID uuid.UUID) (*domain.AgentMCPTopology, error) {
+	agents, err := s.agentRepo.GetByOrganization(orgID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get agents: %w", err)
+	}
+
+	servers, err := s.mcpRepo.GetByOrganization(orgID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get MCP servers: %w", err)
+	}
+
+	connections, err := s.connectionRepo.ListByOrganization(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+
+	detections, err := s.connectionRepo.ListDetectionsByOrganization(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+
+	return BuildAgentMCPTopology(agents, servers, connections, detections), nil
+}
+
+// BuildAgentMCPTopology assembles the agent↔MCP graph. Each agent/server pair gets at most one
+// edge, merged from the agent's talks_to (matched by server ID or name), its recorded connection
+// and its most confident detection. Detections only annotate edges; on their own they are not
+// evidence of a connection, and their score is the edge's confidence only for auto-detected
+// connections. References to agents or servers outside the given sets are dropped.
+func BuildAgentMCPTopology(
+	agents []*domain.Agent,
+	servers []*domain.MCPServer,
+	connections []*domain.AgentMCPConnection,
+	detections []*domain.AgentMCPDetection,
+) *domain.AgentMCPTopology {
+	topology := &domain.AgentMCPTopology{
+		Nodes: make([]domain.TopologyNode, 0, len(agents)+len(servers)),
+		Edges: []domain.TopologyEdge{},
+	}
+
+	agentIDs := make(map[uuid.UUID]bool, len(agents))
+	for _, agent := range agents {
+		agentIDs[agent.ID] = true
+		topology.Nodes = append(topology.Nodes, domain.TopologyNode{
+			ID:         agent.ID,
+			Type:       domain.TopologyNodeAgent,
+			Name:       agent.Name,
+			Status:     string(agent.Status),
+			TrustScore: agent.TrustScore,
+		})
+	}
+
+	serversByKey := make(map[string]*domain.MCPServer, 2*len(servers))
+	serverNames := make(map[uuid.UUID]string, len(servers))
+	for _, server := range servers {
+		serversByKey[server.ID.String()] = server
+		serversByKey[server.Name] = server
+		serverNames[server.ID] = server.Name
+		topology.Nodes = append(topology.Nodes, domain.TopologyNode{
+			ID:         server.ID,
+			Type:       domain.TopologyNodeMCPServer,
+			Name:       server.Name,
+			Status:     string(server.Status),
+			TrustScore: server.TrustScore,
+		})
+	}
+
+	type edgeKey struct{ agentID, serverID uuid.UUID }
+	edgeIndex := make(map[edgeKey]int)
+	edgeFor := func(agentID, serverID uuid.UUID) *domain.TopologyEdge {
+		key := edgeKey{agentID, serverID}
+		if i, ok := edgeIndex[key]; ok {
+			return &topology.Edges[i]
+		}
+		edgeIndex[key] = len(topology.Edges)
+		topology.Edges = append(topology.Edges, domain.TopologyEdge{AgentID: agentID, MCPServerID: serverID})
+		return &topology.Edges[len(topology.Edges)-1]
+	}
+
+	for _, agent := range agents {
+		for _, identifier := range agent.TalksTo {
+			if server, ok := serversByKey[identifier]; ok {
+				edgeFor(agent.ID, server.ID).Declared = true
+			}
+		}
+	}
+
+	for _, connection := range connections {
+		if !agentIDs[connection.AgentID] || serverNames[connection.MCPServerID] == "" {
+			continue
+		}
+		edge := edgeFor(connection.AgentID, connection.MCPServerID)
+		edge.ConnectionType = connection.ConnectionType
+		edge.AttestationCount = connection.AttestationCount
+		edge.LastAttestedAt = connection.LastAttestedAt
+	}
+
+	for _, detection := range detections {
+		server, ok := serversByKey[detection.MCPServerName]
+		if !ok {
+			continue
+		}
+		i, ok := edgeIndex[edgeKey{detection.AgentID, server.ID}]
+		if !ok {
+			continue
+		}
+		edge := &topology.Edges[i]
+		if edge.DetectedMethod == "" || detection.ConfidenceScore > edge.Confidence {
+			edge.DetectedMethod = detection.DetectionMethod
+			edge.Confidence = detection.ConfidenceScore
+		}
+	}
+
+	for i := range topology.Edges {
+		edge := &topology.Edges[i]
+		if edge.Declared || (edge.ConnectionType != "" && edge.ConnectionType != domain.ConnectionTypeAutoDetected) {
+			edge.Confidence = DeclaredConnectionConfidence
+		}
+	}
+
+	return topology
+}
@@ -0,0 +1,79 @@
+package application
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func findTopologyEdge(t *testing.T, topology *domain.AgentMCPTopology, agentID, serverID uuid.UUID) domain.TopologyEdge {
+	t.Helper()
+	for _, edge := range topology.Edges {
+		if edge.AgentID == agentID && edge.MCPServerID == serverID {
+			return edge
+		}
+	}
+	require.Failf(t, "edge not found", "no edge from agent %s to MCP server %s", agentID, serverID)
+	return domain.TopologyEdge{}
+}
+
+func TestBuildAgentMCPTopology_MergesDeclaredAndRecordedConnections(t *testing.T) {
+	filesystem := &domain.MCPServer{ID: uuid.New(), Name: "filesystem", Status: domain.MCPServerStatusVerified}
+	github := &domain.MCPServer{ID: uuid.New(), Name: "github"}
+	slack := &domain.MCPServer{ID: uuid.New(), Name: "slack"}
+
+	// Declares filesystem by name and github by ID; slack was only detected at runtime
+	agent := &domain.Agent{
+		ID:      uuid.New(),
+		Name:    "support-bot",
+		TalksTo: []string{"filesystem", github.ID.String(), "unregistered-mcp"},
+	}
+	connections := []*domain.AgentMCPConnection{
+		{AgentID: agent.ID, MCPServerID: github.ID, ConnectionType: domain.ConnectionTypeAttested, AttestationCount: 4},
+		{AgentID: agent.ID, MCPServerID: slack.ID, ConnectionType: domain.ConnectionTypeAutoDetected},
+		{AgentID: uuid.New(), MCPServerID: slack.ID, ConnectionType: domain.ConnectionTypeAutoDetected}, // Agent from another org
+	}
+	detections := []*domain.AgentMCPDetection{
+		{AgentID: agent.ID, MCPServerName: "slack", DetectionMethod: domain.DetectionMethodSDKRuntime, ConfidenceScore: 85},
+		{AgentID: agent.ID, MCPServerName: "github", DetectionMethod: domain.DetectionMethodSDKImport, ConfidenceScore: 60},
+	}
+
+	topology := BuildAgentMCPTopology([]*domain.Agent{agent}, []*domain.MCPServer{filesystem, github, slack}, connections, detections)
+
+	assert.Len(t, topology.Nodes, 4)
+	require.Len(t, topology.Edges, 3)
+
+	declaredOnly := findTopologyEdge(t, topology, agent.ID, filesystem.ID)
+	assert.True(t, declaredOnly.Declared)
+	assert.Empty(t, declaredOnly.ConnectionType)
+	assert.Equal(t, DeclaredConnectionConfidence, declaredOnly.Confidence)
+
+	declaredAndAttested := findTopologyEdge(t, topology, agent.ID, github.ID)
+	assert.True(t, declaredAndAttested.Declared)
+	assert.Equal(t, domain.ConnectionTypeAttested, declaredAndAttested.ConnectionType)
+	assert.Equal(t, 4, declaredAndAttested.AttestationCount)
+	assert.Equal(t, domain.DetectionMethodSDKImport, declaredAndAttested.DetectedMethod)
+	assert.Equal(t, DeclaredConnectionConfidence, declaredAndAttested.Confidence)
+
+	recordedOnly := findTopologyEdge(t, topology, agent.ID, slack.ID)
+	assert.False(t, recordedOnly.Declared)
+	assert.Equal(t, domain.ConnectionTypeAutoDetected, recordedOnly.ConnectionType)
+	assert.Equal(t, domain.DetectionMethodSDKRuntime, recordedOnly.DetectedMethod)
+	assert.Equal(t, 85.0, recordedOnly.Confidence)
+}
+
+func TestBuildAgentMCPTopology_DetectionsAloneAddNoEdges(t *testing.T) {
+	server := &domain.MCPServer{ID: uuid.New(), Name: "filesystem"}
+	agent := &domain.Agent{ID: uuid.New(), Name: "support-bot"}
+	detections := []*domain.AgentMCPDetection{
+		{AgentID: agent.ID, MCPServerName: "filesystem", DetectionMethod: domain.DetectionMethodClaudeConfig, ConfidenceScore: 90},
+	}
+
+	topology := BuildAgentMCPTopology([]*domain.Agent{agent}, []*domain.MCPServer{server}, nil, detections)
+
+	assert.Len(t, topology.Nodes, 2)
+	assert.Empty(t, topology.Edges)
+}
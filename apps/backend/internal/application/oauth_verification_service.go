@@ -0,0 +1,160 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/domain"
+)
+
+// ErrOAuthIntrospectionNotConfigured is returned when no token introspection endpoint is configured
+var ErrOAuthIntrospectionNotConfigured = errors.New("OAuth token introspection is not configured")
+
+// ErrOAuthIntrospectionFailed is returned when the introspection endpoint could not answer; the
+// attempt is still recorded as a failed verification event
+var ErrOAuthIntrospectionFailed = errors.New("OAuth token introspection failed")
+
+// TokenIntrospector checks whether an OAuth access token is active
+type TokenIntrospector interface {
+	Introspect(ctx context.Context, token string) (*domain.TokenIntrospection, error)
+}
+
+// OAuthVerificationService verifies agents presenting OAuth bearer tokens by introspecting the
+// token and recording the outcome as an OAuth-protocol verification event
+type OAuthVerificationService struct {
+	introspector TokenIntrospector // Optional: OAuth verification is off without it
+	eventService *VerificationEventService
+}
+
+// NewOAuthVerificationService creates a new OAuth verification service
+func NewOAuthVerificationService(introspector TokenIntrospector, eventService *VerificationEventService) *OAuthVerificationService {
+	return &OAuthVerificationService{
+		introspector: introspector,
+		eventService: eventService,
+	}
+}
+
+// OAuthVerificationResult is the outcome of verifying an agent's OAuth token
+type OAuthVerificationResult struct {
+	Verified            bool       `json:"verified"`
+	Reason              string     `json:"reason,omitempty"`
+	Scope               string     `json:"scope,omitempty"`
+	ClientID            string     `json:"clientId,omitempty"`
+	Subject             string     `json:"subject,omitempty"`
+	ExpiresAt           *time.Time `json:"expiresAt,omitempty"`
+	VerificationEventID uuid.UUID  `json:"verificationEventId"`
+}
+
+// VerifyAgentToken introspects the bearer token agent presented and records the result. Only
+// active tokens issued to the agent's configured OAuth client verify it. The token itself is never
+// stored; the event keeps the introspected client, subject and scope.
+func (s *OAuthVerificationService) VerifyAgentToken(
+	ctx context.Context,
+	agent *domain.Agent,
+	token string,
+	initiatorIP string,
+) (*OAuthVerificationResult, error) {
+	if s.introspector == nil {
+		return nil, ErrOAuthIntrospectionNotConfigured
+	}
+
+	startedAt := time.Now()
+	introspection, introspectErr := s.introspector.Introspect(ctx, token)
+	completedAt := time.Now()
+
+	result := &OAuthVerificationResult{}
+	metadata := map[string]interface{}{}
+	status := domain.VerificationEventStatusFailed
+	var eventResult *domain.VerificationResult
+
+	if introspectErr != nil {
+		result.Reason = "Token introspection failed"
+		metadata["introspection_error"] = introspectErr.Error()
+	} else {
+		result.Scope = introspection.Scope
+		result.ClientID = introspection.ClientID
+		result.Subject = introspection.Subject
+		if introspection.ExpiresAt > 0 {
+			expiresAt := time.Unix(introspection.ExpiresAt, 0)
+			result.ExpiresAt = &expiresAt
+		}
+		metadata["active"] = introspection.Active
+		metadata["scope"] = introspection.Scope
+		metadata["client_id"] = introspection.ClientID
+		metadata["subject"] = introspection.Subject
+		metadata["issuer"] = introspection.Issuer
+
+		switch {
+		case !introspection.Active:
+			result.Reason = "Token is not active"
+			eventResult = verificationResultPtr(domain.VerificationResultDenied)
+		case result.ExpiresAt != nil && !completedAt.Before(*result.ExpiresAt):
+			// Introspection endpoints should report expired tokens inactive, but do not rely on it
+			result.Reason = "Token has expired"
+			eventResult = verificationResultPtr(domain.VerificationResultExpired)
+		case agent.OAuthClientID == "":
+			result.Reason = "Agent has no OAuth client configured"
+			eventResult = verificationResultPtr(domain.VerificationResultDenied)
+		case !introspection.IssuedTo(agent.OAuthClientID):
+			// A valid token of another client must not vouch for this agent
+			result.Reason = "Token was not issued to this agent's OAuth client"
+			eventResult = verificationResultPtr(domain.VerificationResultDenied)
+		default:
+			result.Verified = true
+			status = domain.VerificationEventStatusSuccess
+			eventResult = verificationResultPtr(domain.VerificationResultVerified)
+		}
+	}
+
+	var errorReason *string
+	if !result.Verified {
+		errorReason = &result.Reason
+	}
+	var initiatorIPPtr *string
+	if initiatorIP != "" {
+		initiatorIPPtr = &initiatorIP
+	}
+
+	event, err := s.eventService.CreateVerificationEvent(ctx, &CreateVerificationEventRequest{
+		OrganizationID:   agent.OrganizationID,
+		AgentID:          agent.ID,
+		Protocol:         domain.VerificationProtocolOAuth,
+		VerificationType: domain.VerificationTypeIdentity,
+		Status:           status,
+		Result:           eventResult,
+		Confidence:       oauthVerificationConfidence(result.Verified),
+		DurationMs:       int(completedAt.Sub(startedAt).Milliseconds()),
+		ErrorReason:      errorReason,
+		InitiatorType:    domain.InitiatorTypeAgent,
+		InitiatorID:      &agent.ID,
+		InitiatorName:    &agent.DisplayName,
+		InitiatorIP:      initiatorIPPtr,
+		StartedAt:        startedAt,
+		CompletedAt:      &completedAt,
+		Metadata:         metadata,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to record OAuth verification event: %w", err)
+	}
+	result.VerificationEventID = event.ID
+
+	if introspectErr != nil {
+		return result, fmt.Errorf("%w: %v", ErrOAuthIntrospectionFailed, introspectErr)
+	}
+	return result, nil
+}
+
+// oauthVerificationConfidence is full confidence in an active token's identity and none otherwise
+func oauthVerificationConfidence(verified bool) float64 {
+	if verified {
+		return 1.0
+	}
+	return 0.0
+}
+
+func verificationResultPtr(result domain.VerificationResult) *domain.VerificationResult {
+	return &result
+}
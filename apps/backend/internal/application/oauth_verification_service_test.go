@@ -0,0 +1,145 @@
+package application
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/domain"
+	"github.com/opena2a/identity/backend/internal/infrastructure/oauth"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// newFakeIntrospectionServer answers RFC 7662 requests for "active-token" with an active token
+// and for any other token with {"active": false}, rejecting requests without client credentials
+func newFakeIntrospectionServer(t *testing.T) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		clientID, clientSecret, ok := r.BasicAuth()
+		if !ok || clientID != "aim" || clientSecret != "introspection-secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if r.Method != http.MethodPost || r.FormValue("token_type_hint") != "access_token" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if r.FormValue("token") != "active-token" {
+			json.NewEncoder(w).Encode(map[string]interface{}{"active": false})
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"active":    true,
+			"scope":     "agents:verify",
+			"client_id": "billing-agent",
+			"sub":       "billing-agent",
+			"iss":       "https://idp.example.com",
+			"exp":       time.Now().Add(time.Hour).Unix(),
+		})
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// newOAuthVerificationTestService returns a service introspecting against endpoint and a pointer
+// to the verification event it records
+func newOAuthVerificationTestService(agent *domain.Agent, endpoint string) (*OAuthVerificationService, **domain.VerificationEvent) {
+	var recorded *domain.VerificationEvent
+	mockEventRepo := new(MockVerificationEventRepository)
+	mockEventRepo.On("Create", mock.AnythingOfType("*domain.VerificationEvent")).
+		Run(func(args mock.Arguments) {
+			recorded = args.Get(0).(*domain.VerificationEvent)
+			recorded.ID = uuid.New()
+		}).
+		Return(nil)
+	mockAgentRepo := new(MockAgentRepository)
+	mockAgentRepo.On("GetByID", agent.ID).Return(agent, nil)
+	mockAgentRepo.On("UpdateLastActive", mock.Anything, agent.ID).Return(nil).Maybe()
+
+	introspector := oauth.NewTokenIntrospector(endpoint, "aim", "introspection-secret", 5*time.Second)
+	eventService := NewVerificationEventService(mockEventRepo, mockAgentRepo, nil)
+	return NewOAuthVerificationService(introspector, eventService), &recorded
+}
+
+func TestOAuthVerificationService_VerifyAgentToken(t *testing.T) {
+	server := newFakeIntrospectionServer(t)
+
+	tests := []struct {
+		name          string
+		token         string
+		agentClientID string
+		wantVerified  bool
+		wantReason    string
+		wantStatus    domain.VerificationEventStatus
+		wantResult    domain.VerificationResult
+	}{
+		{"active token", "active-token", "billing-agent", true, "", domain.VerificationEventStatusSuccess, domain.VerificationResultVerified},
+		{"inactive token", "revoked-token", "billing-agent", false, "Token is not active", domain.VerificationEventStatusFailed, domain.VerificationResultDenied},
+		{"token of another client", "active-token", "reporting-agent", false, "Token was not issued to this agent's OAuth client", domain.VerificationEventStatusFailed, domain.VerificationResultDenied},
+		{"agent without OAuth client", "active-token", "", false, "Agent has no OAuth client configured", domain.VerificationEventStatusFailed, domain.VerificationResultDenied},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			agent := &domain.Agent{ID: uuid.New(), OrganizationID: uuid.New(), DisplayName: "Billing Agent", OAuthClientID: tt.agentClientID}
+			service, recorded := newOAuthVerificationTestService(agent, server.URL)
+
+			result, err := service.VerifyAgentToken(context.Background(), agent, tt.token, "203.0.113.7")
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantVerified, result.Verified)
+
+			event := *recorded
+			require.NotNil(t, event)
+			assert.Equal(t, event.ID, result.VerificationEventID)
+			assert.Equal(t, domain.VerificationProtocolOAuth, event.Protocol)
+			assert.Equal(t, agent.OrganizationID, event.OrganizationID)
+			assert.Equal(t, tt.wantStatus, event.Status)
+			require.NotNil(t, event.Result)
+			assert.Equal(t, tt.wantResult, *event.Result)
+			assert.Equal(t, "203.0.113.7", *event.InitiatorIP)
+			assert.Equal(t, !tt.wantVerified, event.ErrorReason != nil)
+			assert.NotContains(t, event.Metadata, "token")
+
+			assert.Equal(t, tt.wantReason, result.Reason)
+			if tt.wantVerified {
+				assert.Equal(t, "billing-agent", result.Subject)
+				assert.Equal(t, "agents:verify", result.Scope)
+				assert.Equal(t, "billing-agent", event.Metadata["client_id"])
+			}
+		})
+	}
+}
+
+func TestOAuthVerificationService_VerifyAgentToken_IntrospectionFailure(t *testing.T) {
+	// The endpoint rejects AIM's client credentials
+	server := newFakeIntrospectionServer(t)
+	agent := &domain.Agent{ID: uuid.New(), OrganizationID: uuid.New(), DisplayName: "Billing Agent", OAuthClientID: "billing-agent"}
+	service, recorded := newOAuthVerificationTestService(agent, server.URL)
+	service.introspector = oauth.NewTokenIntrospector(server.URL, "aim", "wrong-secret", 5*time.Second)
+
+	result, err := service.VerifyAgentToken(context.Background(), agent, "active-token", "")
+	assert.ErrorIs(t, err, ErrOAuthIntrospectionFailed)
+	require.NotNil(t, result)
+	assert.False(t, result.Verified)
+
+	// The failed attempt is still recorded
+	event := *recorded
+	require.NotNil(t, event)
+	assert.Equal(t, domain.VerificationEventStatusFailed, event.Status)
+	assert.Nil(t, event.Result)
+	assert.Contains(t, event.Metadata["introspection_error"], "status 401")
+}
+
+func TestOAuthVerificationService_VerifyAgentToken_NotConfigured(t *testing.T) {
+	service := NewOAuthVerificationService(nil, nil)
+
+	_, err := service.VerifyAgentToken(context.Background(), &domain.Agent{ID: uuid.New()}, "active-token", "")
+	assert.ErrorIs(t, err, ErrOAuthIntrospectionNotConfigured)
+}
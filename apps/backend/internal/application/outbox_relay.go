@@ -0,0 +1,192 @@
+package application
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/opena2a/identity/backend/internal/domain"
+	"github.com/opena2a/identity/backend/internal/infrastructure/logging"
+)
+
+// OutboxRelayPollInterval is how often the relay looks for outbox messages that are due
+const OutboxRelayPollInterval = time.Second
+
+const (
+	// outboxBatchSize bounds how many messages one poll claims
+	outboxBatchSize = 100
+	// outboxClaimLease is how long a claimed message stays hidden from other relays. It must
+	// outlast a dispatch, which for webhooks may POST to several endpoints in turn.
+	outboxClaimLease = 5 * time.Minute
+	// outboxMaxAttempts is how often a message is dispatched before the relay gives up on it
+	outboxMaxAttempts = 10
+)
+
+// outboxRetrySchedule is the backoff applied after each failed dispatch.
+// Attempts beyond the schedule reuse the last delay.
+var outboxRetrySchedule = []time.Duration{
+	1 * time.Second,
+	5 * time.Second,
+	30 * time.Second,
+	2 * time.Minute,
+	10 * time.Minute,
+	1 * time.Hour,
+}
+
+// errUnknownOutboxTopic marks a message no relay can dispatch; it is failed without retrying
+var errUnknownOutboxTopic = errors.New("unknown outbox topic")
+
+// OutboxRelay dispatches outbox messages written alongside business changes and marks them
+// delivered. Delivery is at-least-once: a message whose side effect succeeded but whose
+// MarkDelivered did not is dispatched again.
+type OutboxRelay struct {
+	outboxRepo     domain.OutboxRepository
+	alertRepo      domain.AlertRepository
+	webhookService *WebhookService
+}
+
+// NewOutboxRelay creates a new outbox relay
+func NewOutboxRelay(
+	outboxRepo domain.OutboxRepository,
+	alertRepo domain.AlertRepository,
+	webhookService *WebhookService,
+) *OutboxRelay {
+	return &OutboxRelay{
+		outboxRepo:     outboxRepo,
+		alertRepo:      alertRepo,
+		webhookService: webhookService,
+	}
+}
+
+// StartRelay dispatches due outbox messages until the context is cancelled
+func (r *OutboxRelay) StartRelay(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := r.ProcessDue(ctx, time.Now().UTC()); err != nil {
+				logging.FromContext(ctx).Warn("outbox relay failed", "error", err)
+			}
+		}
+	}
+}
+
+// ProcessDue claims the messages due at now, dispatches each one and records the outcome.
+// It returns how many messages were claimed.
+func (r *OutboxRelay) ProcessDue(ctx context.Context, now time.Time) (int, error) {
+	messages, err := r.outboxRepo.ClaimDue(now, outboxClaimLease, outboxBatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	logger := logging.FromContext(ctx)
+	for _, message := range messages {
+		dispatchErr := r.dispatch(ctx, message)
+
+		var markErr error
+		switch {
+		case dispatchErr == nil:
+			markErr = r.outboxRepo.MarkDelivered(message.ID, time.Now().UTC())
+		case errors.Is(dispatchErr, errUnknownOutboxTopic) || message.Attempts >= outboxMaxAttempts:
+			logger.Error("giving up on outbox message",
+				"message_id", message.ID, "topic", message.Topic, "attempts", message.Attempts, "error", dispatchErr)
+			markErr = r.outboxRepo.MarkFailed(message.ID, dispatchErr.Error())
+		default:
+			logger.Warn("outbox message dispatch failed",
+				"message_id", message.ID, "topic", message.Topic, "attempts", message.Attempts, "error", dispatchErr)
+			markErr = r.outboxRepo.MarkRetry(message.ID, dispatchErr.Error(), now.Add(outboxRetryDelay(message.Attempts)))
+		}
+		if markErr != nil {
+			// The claim lease expires and the message is dispatched again
+			logger.Warn("failed to record outbox message outcome", "message_id", message.ID, "error", markErr)
+		}
+	}
+
+	return len(messages), nil
+}
+
+// dispatch performs the side effect the message describes
+func (r *OutboxRelay) dispatch(ctx context.Context, message *domain.OutboxMessage) error {
+	switch message.Topic {
+	case domain.OutboxTopicAlert:
+		var alert domain.Alert
+		if err := json.Unmarshal(message.Payload, &alert); err != nil {
+			return fmt.Errorf("invalid alert payload: %w", err)
+		}
+		// A redelivered message finds the alert its first delivery created
+		if existing, err := r.alertRepo.GetByID(alert.ID); err == nil && existing != nil {
+			return nil
+		}
+		return r.alertRepo.Create(&alert)
+
+	case domain.OutboxTopicWebhook:
+		if r.webhookService == nil {
+			return errors.New("webhook delivery is not configured")
+		}
+		var event domain.OutboxWebhookEvent
+		if err := json.Unmarshal(message.Payload, &event); err != nil {
+			return fmt.Errorf("invalid webhook payload: %w", err)
+		}
+		// Queue the deliveries for the webhook retry worker rather than POSTing here, so one slow
+		// receiver cannot hold up the rest of the outbox
+		return r.webhookService.QueueEvent(ctx, message.ID, message.OrganizationID, event.Event, event.ResourceType, event.Data)
+
+	default:
+		return fmt.Errorf("%w: %s", errUnknownOutboxTopic, message.Topic)
+	}
+}
+
+// outboxRetryDelay returns the backoff to wait after the given (1-based) failed attempt
+func outboxRetryDelay(attempt int) time.Duration {
+	if attempt < 1 {
+		attempt = 1
+	}
+	if attempt > len(outboxRetrySchedule) {
+		attempt = len(outboxRetrySchedule)
+	}
+	return outboxRetrySchedule[attempt-1]
+}
+
+// NewOutboxAlertRepository wraps repo so alerts are created through the outbox: Create writes an
+// alert message and the relay creates the alert, retrying until it is stored. The relay itself must
+// be given the unwrapped repository.
+func NewOutboxAlertRepository(repo domain.AlertRepository, outboxRepo domain.OutboxRepository) domain.AlertRepository {
+	return &outboxAlertRepository{AlertRepository: repo, outboxRepo: outboxRepo}
+}
+
+// outboxAlertRepository queues alert creation in the outbox and reads from the wrapped repository
+type outboxAlertRepository struct {
+	domain.AlertRepository
+	outboxRepo domain.OutboxRepository
+}
+
+// Create queues alert and assigns its ID. The repository's deduplication applies when the relay
+// creates it, so alert does not reflect an open alert it is collapsed into.
+func (r *outboxAlertRepository) Create(alert *domain.Alert) error {
+	message, err := domain.NewAlertOutboxMessage(alert)
+	if err != nil {
+		return fmt.Errorf("failed to encode alert: %w", err)
+	}
+	return r.outboxRepo.Enqueue(message)
+}
+
+// securityAlertOutboxMessages returns the outbox messages that raise alert and announce it to the
+// organization's security_breach webhooks
+func securityAlertOutboxMessages(alert *domain.Alert) ([]*domain.OutboxMessage, error) {
+	alertMessage, err := domain.NewAlertOutboxMessage(alert)
+	if err != nil {
+		return nil, err
+	}
+	webhookMessage, err := domain.NewWebhookOutboxMessage(
+		alert.OrganizationID, domain.WebhookEventSecurityBreach, domain.WebhookResourceAlert, alert)
+	if err != nil {
+		return nil, err
+	}
+	return []*domain.OutboxMessage{alertMessage, webhookMessage}, nil
+}
@@ -0,0 +1,261 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// inMemoryOutboxRepository claims and marks messages the way the Postgres repository does
+type inMemoryOutboxRepository struct {
+	messages []*domain.OutboxMessage
+}
+
+func (r *inMemoryOutboxRepository) Enqueue(messages ...*domain.OutboxMessage) error {
+	r.messages = append(r.messages, messages...)
+	return nil
+}
+
+func (r *inMemoryOutboxRepository) ClaimDue(now time.Time, lease time.Duration, limit int) ([]*domain.OutboxMessage, error) {
+	var claimed []*domain.OutboxMessage
+	for _, message := range r.messages {
+		if len(claimed) == limit {
+			break
+		}
+		if message.Status != domain.OutboxStatusPending || message.AvailableAt.After(now) {
+			continue
+		}
+		message.Attempts++
+		message.AvailableAt = now.Add(lease)
+		copied := *message
+		claimed = append(claimed, &copied)
+	}
+	return claimed, nil
+}
+
+func (r *inMemoryOutboxRepository) get(id uuid.UUID) *domain.OutboxMessage {
+	for _, message := range r.messages {
+		if message.ID == id {
+			return message
+		}
+	}
+	return nil
+}
+
+func (r *inMemoryOutboxRepository) MarkDelivered(id uuid.UUID, at time.Time) error {
+	message := r.get(id)
+	message.Status = domain.OutboxStatusDelivered
+	message.DeliveredAt = &at
+	return nil
+}
+
+func (r *inMemoryOutboxRepository) MarkRetry(id uuid.UUID, lastError string, retryAt time.Time) error {
+	message := r.get(id)
+	message.LastError = lastError
+	message.AvailableAt = retryAt
+	return nil
+}
+
+func (r *inMemoryOutboxRepository) MarkFailed(id uuid.UUID, lastError string) error {
+	message := r.get(id)
+	message.Status = domain.OutboxStatusFailed
+	message.LastError = lastError
+	return nil
+}
+
+// relayAlertRepository stores the alerts the relay creates and can fail the next creates
+type relayAlertRepository struct {
+	domain.AlertRepository
+	alerts   map[uuid.UUID]*domain.Alert
+	failures int
+}
+
+func (r *relayAlertRepository) Create(alert *domain.Alert) error {
+	if r.failures > 0 {
+		r.failures--
+		return errors.New("connection reset")
+	}
+	r.alerts[alert.ID] = alert
+	return nil
+}
+
+func (r *relayAlertRepository) GetByID(id uuid.UUID) (*domain.Alert, error) {
+	alert, ok := r.alerts[id]
+	if !ok {
+		return nil, errors.New("alert not found")
+	}
+	return alert, nil
+}
+
+func newOutboxAlertMessage(t *testing.T, now time.Time) (*domain.OutboxMessage, *domain.Alert) {
+	alert := &domain.Alert{
+		OrganizationID: uuid.New(),
+		AlertType:      domain.AlertSecurityBreach,
+		Severity:       domain.AlertSeverityHigh,
+		Title:          "Refresh token reuse detected",
+		ResourceType:   "user",
+		ResourceID:     uuid.New(),
+		CreatedAt:      now,
+	}
+	message, err := domain.NewAlertOutboxMessage(alert)
+	require.NoError(t, err)
+	message.AvailableAt = now
+	return message, alert
+}
+
+func TestOutboxRelay_DeliversAlertAndMarksDone(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	message, alert := newOutboxAlertMessage(t, now)
+	outbox := &inMemoryOutboxRepository{messages: []*domain.OutboxMessage{message}}
+	alertRepo := &relayAlertRepository{alerts: map[uuid.UUID]*domain.Alert{}}
+	relay := NewOutboxRelay(outbox, alertRepo, nil)
+
+	processed, err := relay.ProcessDue(context.Background(), now)
+	require.NoError(t, err)
+	assert.Equal(t, 1, processed)
+
+	require.Contains(t, alertRepo.alerts, alert.ID)
+	assert.Equal(t, alert.Title, alertRepo.alerts[alert.ID].Title)
+	assert.Equal(t, domain.OutboxStatusDelivered, message.Status)
+	assert.NotNil(t, message.DeliveredAt)
+
+	// Nothing is left to dispatch
+	processed, err = relay.ProcessDue(context.Background(), now.Add(time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, 0, processed)
+}
+
+func TestOutboxRelay_RetriesFailedDispatch(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	message, alert := newOutboxAlertMessage(t, now)
+	outbox := &inMemoryOutboxRepository{messages: []*domain.OutboxMessage{message}}
+	alertRepo := &relayAlertRepository{alerts: map[uuid.UUID]*domain.Alert{}, failures: 1}
+	relay := NewOutboxRelay(outbox, alertRepo, nil)
+
+	_, err := relay.ProcessDue(context.Background(), now)
+	require.NoError(t, err)
+	assert.Equal(t, domain.OutboxStatusPending, message.Status)
+	assert.Equal(t, "connection reset", message.LastError)
+	assert.Equal(t, now.Add(outboxRetryDelay(1)), message.AvailableAt)
+
+	// Not due again until the backoff elapsed
+	processed, err := relay.ProcessDue(context.Background(), now)
+	require.NoError(t, err)
+	assert.Equal(t, 0, processed)
+
+	_, err = relay.ProcessDue(context.Background(), message.AvailableAt)
+	require.NoError(t, err)
+	assert.Equal(t, domain.OutboxStatusDelivered, message.Status)
+	assert.Equal(t, 2, message.Attempts)
+	assert.Contains(t, alertRepo.alerts, alert.ID)
+}
+
+func TestOutboxRelay_RedeliveryDoesNotDuplicateAlert(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	message, alert := newOutboxAlertMessage(t, now)
+	// The first delivery created the alert but the relay died before marking the message
+	alertRepo := &relayAlertRepository{alerts: map[uuid.UUID]*domain.Alert{alert.ID: alert}, failures: 1}
+	outbox := &inMemoryOutboxRepository{messages: []*domain.OutboxMessage{message}}
+	relay := NewOutboxRelay(outbox, alertRepo, nil)
+
+	_, err := relay.ProcessDue(context.Background(), now)
+	require.NoError(t, err)
+	assert.Equal(t, domain.OutboxStatusDelivered, message.Status)
+	assert.Len(t, alertRepo.alerts, 1)
+}
+
+func TestOutboxRelay_GivesUpAfterMaxAttempts(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	message, _ := newOutboxAlertMessage(t, now)
+	message.Attempts = outboxMaxAttempts - 1
+	unknown := &domain.OutboxMessage{
+		ID:          uuid.New(),
+		Topic:       "carrier_pigeon",
+		Payload:     []byte(`{}`),
+		Status:      domain.OutboxStatusPending,
+		AvailableAt: now,
+	}
+	outbox := &inMemoryOutboxRepository{messages: []*domain.OutboxMessage{message, unknown}}
+	alertRepo := &relayAlertRepository{alerts: map[uuid.UUID]*domain.Alert{}, failures: 1}
+	relay := NewOutboxRelay(outbox, alertRepo, nil)
+
+	_, err := relay.ProcessDue(context.Background(), now)
+	require.NoError(t, err)
+	assert.Equal(t, domain.OutboxStatusFailed, message.Status)
+	assert.Equal(t, domain.OutboxStatusFailed, unknown.Status)
+	assert.Contains(t, unknown.LastError, "unknown outbox topic")
+}
+
+func TestOutboxAlertRepository_CreateQueuesAlertForRelay(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	outbox := &inMemoryOutboxRepository{}
+	stored := &relayAlertRepository{alerts: map[uuid.UUID]*domain.Alert{}}
+	alertRepo := NewOutboxAlertRepository(stored, outbox)
+
+	alert := &domain.Alert{
+		OrganizationID: uuid.New(),
+		AlertType:      domain.AlertKeyExpired,
+		Severity:       domain.AlertSeverityWarning,
+		Title:          "Agent Key Expired: billing-agent",
+		ResourceType:   "agent",
+		ResourceID:     uuid.New(),
+		CreatedAt:      now,
+	}
+	require.NoError(t, alertRepo.Create(alert))
+
+	// Nothing is stored until the relay dispatches the queued message
+	require.NotEqual(t, uuid.Nil, alert.ID)
+	assert.Empty(t, stored.alerts)
+	require.Len(t, outbox.messages, 1)
+	assert.Equal(t, domain.OutboxTopicAlert, outbox.messages[0].Topic)
+
+	relay := NewOutboxRelay(outbox, stored, nil)
+	_, err := relay.ProcessDue(context.Background(), time.Now().UTC())
+	require.NoError(t, err)
+	require.Contains(t, stored.alerts, alert.ID)
+	assert.Equal(t, alert.Title, stored.alerts[alert.ID].Title)
+}
+
+func TestOutboxRelay_QueuesWebhookDeliveriesWithoutSending(t *testing.T) {
+	receiver, received := newRecordingWebhookServer()
+	defer receiver.Close()
+
+	orgID := uuid.New()
+	webhook := &domain.Webhook{
+		ID: uuid.New(), OrganizationID: orgID, URL: receiver.URL, IsActive: true,
+		EncryptedSecret: encryptWebhookSecret(t, "secret"),
+		Events:          []domain.WebhookEvent{domain.WebhookEventSecurityBreach},
+	}
+	var recorded []*domain.WebhookDelivery
+	webhookRepo := new(MockWebhookRepository)
+	webhookRepo.On("GetByOrganization", orgID).Return([]*domain.Webhook{webhook}, nil)
+	webhookRepo.On("RecordDelivery", mock.AnythingOfType("*domain.WebhookDelivery")).
+		Run(func(args mock.Arguments) { recorded = append(recorded, args.Get(0).(*domain.WebhookDelivery)) }).
+		Return(nil)
+
+	message, err := domain.NewWebhookOutboxMessage(orgID, domain.WebhookEventSecurityBreach, domain.WebhookResourceAlert, map[string]string{"title": "breach"})
+	require.NoError(t, err)
+	outbox := &inMemoryOutboxRepository{messages: []*domain.OutboxMessage{message}}
+	relay := NewOutboxRelay(outbox, &relayAlertRepository{alerts: map[uuid.UUID]*domain.Alert{}}, newTestWebhookService(webhookRepo, DefaultWebhookMaxAttempts))
+
+	_, err = relay.ProcessDue(context.Background(), time.Now().UTC())
+	require.NoError(t, err)
+	assert.Equal(t, domain.OutboxStatusDelivered, message.Status)
+	assert.Empty(t, *received, "the retry worker sends queued deliveries, not the relay")
+
+	require.Len(t, recorded, 1)
+	assert.Equal(t, domain.WebhookDeliveryPending, recorded[0].Status)
+	require.NotNil(t, recorded[0].NextAttemptAt)
+
+	// Relaying the message again records the same delivery, which the repository ignores
+	require.NoError(t, relay.dispatch(context.Background(), message))
+	require.Len(t, recorded, 2)
+	assert.Equal(t, recorded[0].ID, recorded[1].ID)
+}
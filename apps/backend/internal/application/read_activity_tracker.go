@@ -0,0 +1,66 @@
+package application
+
+import (
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// readEvent is a single read action observed for an agent
+type readEvent struct {
+	at       time.Time
+	resource string
+}
+
+// ReadActivityTracker counts per-agent reads of distinct resources in a sliding window.
+// Bulk reads of many distinct resources in a short time are the EchoLeak exfiltration signature.
+type ReadActivityTracker struct {
+	mu        sync.Mutex
+	reads     map[uuid.UUID][]readEvent
+	lastSweep time.Time
+}
+
+// NewReadActivityTracker creates an empty in-memory read tracker
+func NewReadActivityTracker() *ReadActivityTracker {
+	return &ReadActivityTracker{reads: make(map[uuid.UUID][]readEvent)}
+}
+
+// Record adds a read at the given time and returns the number of distinct resources
+// the agent has read within the window ending at that time
+func (t *ReadActivityTracker) Record(agentID uuid.UUID, resource string, at time.Time, window time.Duration) int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	cutoff := at.Add(-window)
+	events := pruneReadEvents(t.reads[agentID], cutoff)
+	events = append(events, readEvent{at: at, resource: resource})
+	t.reads[agentID] = events
+
+	// Drop agents that have gone quiet so the map does not grow without bound
+	if at.Sub(t.lastSweep) >= window {
+		for id, agentEvents := range t.reads {
+			if remaining := pruneReadEvents(agentEvents, cutoff); len(remaining) == 0 {
+				delete(t.reads, id)
+			} else {
+				t.reads[id] = remaining
+			}
+		}
+		t.lastSweep = at
+	}
+
+	distinct := make(map[string]struct{}, len(events))
+	for _, e := range events {
+		distinct[e.resource] = struct{}{}
+	}
+	return len(distinct)
+}
+
+// pruneReadEvents drops events at or before the cutoff; events are kept in time order
+func pruneReadEvents(events []readEvent, cutoff time.Time) []readEvent {
+	i := 0
+	for i < len(events) && !events[i].at.After(cutoff) {
+		i++
+	}
+	return events[i:]
+}
@@ -0,0 +1,35 @@
+package application
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadActivityTracker_SlidingWindow(t *testing.T) {
+	tracker := NewReadActivityTracker()
+	agentID := uuid.New()
+	start := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	// 50 reads of distinct resources spread over 10 seconds
+	var count int
+	for i := 0; i < 50; i++ {
+		at := start.Add(time.Duration(i) * 200 * time.Millisecond)
+		count = tracker.Record(agentID, fmt.Sprintf("/data/file-%d", i), at, 10*time.Second)
+	}
+	assert.Equal(t, 50, count)
+
+	// Repeated reads of the same resource are counted once
+	other := uuid.New()
+	for i := 0; i < 5; i++ {
+		count = tracker.Record(other, "/data/same", start.Add(time.Duration(i)*time.Second), 10*time.Second)
+	}
+	assert.Equal(t, 1, count)
+
+	// Reads older than the window fall out
+	count = tracker.Record(agentID, "/data/late", start.Add(30*time.Second), 10*time.Second)
+	assert.Equal(t, 1, count)
+}
@@ -0,0 +1,136 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/domain"
+	"github.com/opena2a/identity/backend/internal/infrastructure/logging"
+)
+
+var (
+	// ErrRefreshTokenReused is returned when an already-rotated refresh token is presented again.
+	// The token's whole family has been revoked and the user must log in again.
+	ErrRefreshTokenReused = errors.New("refresh token has already been used")
+	// ErrRefreshTokenRevoked is returned for a token whose family was revoked earlier
+	ErrRefreshTokenRevoked = errors.New("refresh token has been revoked")
+)
+
+// RefreshTokenRotation describes one refresh: the presented token and the token issued in its place
+type RefreshTokenRotation struct {
+	UserID         uuid.UUID
+	OrganizationID uuid.UUID
+	TokenID        string
+	ExpiresAt      time.Time
+	NewTokenID     string
+	NewExpiresAt   time.Time
+	IPAddress      string
+}
+
+// RefreshTokenService tracks refresh token lineage and detects reuse of rotated tokens
+type RefreshTokenService struct {
+	refreshTokenRepo domain.RefreshTokenRepository
+}
+
+// NewRefreshTokenService creates a new refresh token service
+func NewRefreshTokenService(refreshTokenRepo domain.RefreshTokenRepository) *RefreshTokenService {
+	return &RefreshTokenService{
+		refreshTokenRepo: refreshTokenRepo,
+	}
+}
+
+// Rotate records that rotation.TokenID was exchanged for rotation.NewTokenID. A token may only be
+// rotated once: presenting it again means it was copied, so its family is revoked, a security alert
+// is raised and ErrRefreshTokenReused is returned.
+//
+// Tokens issued before lineage tracking (or never refreshed yet) are not stored; the first rotation
+// starts a new family with the presented token as its root.
+func (s *RefreshTokenService) Rotate(ctx context.Context, rotation RefreshTokenRotation, now time.Time) error {
+	current, err := s.refreshTokenRepo.GetByTokenID(rotation.TokenID)
+	if err != nil {
+		return err
+	}
+	if current == nil {
+		root := &domain.RefreshToken{
+			TokenID:        rotation.TokenID,
+			FamilyID:       uuid.New(),
+			UserID:         rotation.UserID,
+			OrganizationID: rotation.OrganizationID,
+			IssuedAt:       now,
+			ExpiresAt:      rotation.ExpiresAt,
+		}
+		if err := s.refreshTokenRepo.Create(root); err != nil {
+			return err
+		}
+		// Re-read in case a concurrent request created the root first
+		if current, err = s.refreshTokenRepo.GetByTokenID(rotation.TokenID); err != nil {
+			return err
+		}
+		if current == nil {
+			return fmt.Errorf("refresh token %s not found after creation", rotation.TokenID)
+		}
+	}
+
+	if current.RevokedAt != nil {
+		return ErrRefreshTokenRevoked
+	}
+
+	rotated, err := s.refreshTokenRepo.MarkRotated(current.TokenID, now)
+	if err != nil {
+		return err
+	}
+	if !rotated {
+		s.revokeReusedFamily(ctx, current, rotation.IPAddress, now)
+		return ErrRefreshTokenReused
+	}
+
+	parentTokenID := current.TokenID
+	return s.refreshTokenRepo.Create(&domain.RefreshToken{
+		TokenID:        rotation.NewTokenID,
+		FamilyID:       current.FamilyID,
+		ParentTokenID:  &parentTokenID,
+		UserID:         current.UserID,
+		OrganizationID: current.OrganizationID,
+		IssuedAt:       now,
+		ExpiresAt:      rotation.NewExpiresAt,
+	})
+}
+
+// revokeReusedFamily revokes every token descended from the same login and, in the same transaction,
+// queues a security alert and a security_breach webhook event in the outbox; failures are logged,
+// the caller rejects the refresh either way
+func (s *RefreshTokenService) revokeReusedFamily(ctx context.Context, token *domain.RefreshToken, ipAddress string, now time.Time) {
+	logger := logging.FromContext(ctx)
+
+	alert := &domain.Alert{
+		ID:             uuid.New(),
+		OrganizationID: token.OrganizationID,
+		AlertType:      domain.AlertSecurityBreach,
+		Severity:       domain.AlertSeverityHigh,
+		Title:          "Refresh token reuse detected",
+		Description: fmt.Sprintf(
+			"An already-rotated refresh token was presented again from %s, which indicates the token was stolen. "+
+				"Every refresh token of the session was revoked and the user must log in again. Token family: %s",
+			ipAddress, token.FamilyID,
+		),
+		ResourceType:   "user",
+		ResourceID:     token.UserID,
+		IsAcknowledged: false,
+		CreatedAt:      now,
+	}
+	messages, err := securityAlertOutboxMessages(alert)
+	if err != nil {
+		logger.Warn("failed to build refresh token reuse alert", "user_id", token.UserID, "error", err)
+	}
+
+	revoked, err := s.refreshTokenRepo.RevokeFamily(token.FamilyID, "refresh token reuse detected", now, messages...)
+	if err != nil {
+		logger.Error("failed to revoke refresh token family", "family_id", token.FamilyID, "error", err)
+	}
+	logger.Warn("security alert: refresh token reuse detected",
+		"user_id", token.UserID, "family_id", token.FamilyID, "token_id", token.TokenID,
+		"revoked_tokens", revoked, "ip_address", ipAddress)
+}
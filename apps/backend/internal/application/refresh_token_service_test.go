@@ -0,0 +1,140 @@
+package application
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// inMemoryRefreshTokenRepository is a stateful fake so lineage survives across rotations
+type inMemoryRefreshTokenRepository struct {
+	tokens map[string]*domain.RefreshToken
+	outbox []*domain.OutboxMessage
+}
+
+func newInMemoryRefreshTokenRepository() *inMemoryRefreshTokenRepository {
+	return &inMemoryRefreshTokenRepository{tokens: map[string]*domain.RefreshToken{}}
+}
+
+func (r *inMemoryRefreshTokenRepository) Create(token *domain.RefreshToken) error {
+	if _, exists := r.tokens[token.TokenID]; !exists {
+		stored := *token
+		r.tokens[token.TokenID] = &stored
+	}
+	return nil
+}
+
+func (r *inMemoryRefreshTokenRepository) GetByTokenID(tokenID string) (*domain.RefreshToken, error) {
+	token, ok := r.tokens[tokenID]
+	if !ok {
+		return nil, nil
+	}
+	copied := *token
+	return &copied, nil
+}
+
+func (r *inMemoryRefreshTokenRepository) MarkRotated(tokenID string, at time.Time) (bool, error) {
+	token, ok := r.tokens[tokenID]
+	if !ok || token.RotatedAt != nil || token.RevokedAt != nil {
+		return false, nil
+	}
+	token.RotatedAt = &at
+	return true, nil
+}
+
+func (r *inMemoryRefreshTokenRepository) RevokeFamily(familyID uuid.UUID, reason string, at time.Time, messages ...*domain.OutboxMessage) (int64, error) {
+	var revoked int64
+	for _, token := range r.tokens {
+		if token.FamilyID == familyID && token.RevokedAt == nil {
+			token.RevokedAt = &at
+			token.RevokeReason = &reason
+			revoked++
+		}
+	}
+	r.outbox = append(r.outbox, messages...)
+	return revoked, nil
+}
+
+func newTestRotation(userID, orgID uuid.UUID, from, to string, now time.Time) RefreshTokenRotation {
+	return RefreshTokenRotation{
+		UserID:         userID,
+		OrganizationID: orgID,
+		TokenID:        from,
+		ExpiresAt:      now.Add(7 * 24 * time.Hour),
+		NewTokenID:     to,
+		NewExpiresAt:   now.Add(7 * 24 * time.Hour),
+		IPAddress:      "203.0.113.7",
+	}
+}
+
+func TestRefreshTokenService_Rotate_TracksLineage(t *testing.T) {
+	repo := newInMemoryRefreshTokenRepository()
+	service := NewRefreshTokenService(repo)
+	userID, orgID := uuid.New(), uuid.New()
+	now := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	require.NoError(t, service.Rotate(context.Background(), newTestRotation(userID, orgID, "jti-a", "jti-b", now), now))
+	require.NoError(t, service.Rotate(context.Background(), newTestRotation(userID, orgID, "jti-b", "jti-c", now), now.Add(time.Hour)))
+
+	root, b, c := repo.tokens["jti-a"], repo.tokens["jti-b"], repo.tokens["jti-c"]
+	require.NotNil(t, root)
+	require.NotNil(t, b)
+	require.NotNil(t, c)
+	assert.Nil(t, root.ParentTokenID)
+	assert.Equal(t, "jti-a", *b.ParentTokenID)
+	assert.Equal(t, "jti-b", *c.ParentTokenID)
+	assert.Equal(t, root.FamilyID, b.FamilyID)
+	assert.Equal(t, root.FamilyID, c.FamilyID)
+	assert.NotNil(t, root.RotatedAt)
+	assert.NotNil(t, b.RotatedAt)
+	assert.Nil(t, c.RotatedAt)
+	assert.Nil(t, c.RevokedAt)
+}
+
+func TestRefreshTokenService_Rotate_ReuseRevokesFamily(t *testing.T) {
+	repo := newInMemoryRefreshTokenRepository()
+	service := NewRefreshTokenService(repo)
+	userID, orgID := uuid.New(), uuid.New()
+	now := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	// A separate login is its own family and must survive
+	require.NoError(t, service.Rotate(context.Background(), newTestRotation(userID, orgID, "other-a", "other-b", now), now))
+
+	require.NoError(t, service.Rotate(context.Background(), newTestRotation(userID, orgID, "jti-a", "jti-b", now), now))
+	require.NoError(t, service.Rotate(context.Background(), newTestRotation(userID, orgID, "jti-b", "jti-c", now), now))
+
+	// An attacker replays the stolen, already-rotated token
+	err := service.Rotate(context.Background(), newTestRotation(userID, orgID, "jti-a", "jti-x", now), now.Add(time.Hour))
+	assert.ErrorIs(t, err, ErrRefreshTokenReused)
+
+	// The alert and webhook event are queued together with the revocation
+	require.Len(t, repo.outbox, 2)
+	assert.Equal(t, domain.OutboxTopicAlert, repo.outbox[0].Topic)
+	assert.Equal(t, orgID, repo.outbox[0].OrganizationID)
+	var alert domain.Alert
+	require.NoError(t, json.Unmarshal(repo.outbox[0].Payload, &alert))
+	assert.Equal(t, domain.AlertSecurityBreach, alert.AlertType)
+	assert.Equal(t, "user", alert.ResourceType)
+	assert.Equal(t, userID, alert.ResourceID)
+	assert.Equal(t, domain.OutboxTopicWebhook, repo.outbox[1].Topic)
+	var event domain.OutboxWebhookEvent
+	require.NoError(t, json.Unmarshal(repo.outbox[1].Payload, &event))
+	assert.Equal(t, domain.WebhookEventSecurityBreach, event.Event)
+
+	for _, tokenID := range []string{"jti-a", "jti-b", "jti-c"} {
+		assert.NotNil(t, repo.tokens[tokenID].RevokedAt, tokenID)
+	}
+	assert.NotContains(t, repo.tokens, "jti-x")
+	assert.Nil(t, repo.tokens["other-b"].RevokedAt)
+
+	// The legitimate client's latest token no longer works either: the user has to log in again
+	err = service.Rotate(context.Background(), newTestRotation(userID, orgID, "jti-c", "jti-d", now), now.Add(2*time.Hour))
+	assert.ErrorIs(t, err, ErrRefreshTokenRevoked)
+	assert.NotContains(t, repo.tokens, "jti-d")
+}
@@ -2,6 +2,10 @@ package application
 
 import (
 	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"os"
@@ -18,8 +22,19 @@ var (
 	ErrRegistrationNotPending    = errors.New("registration request is not pending")
 	ErrUserAlreadyExists         = errors.New("user with this email already exists")
 	ErrRegistrationRequestExists = errors.New("registration request with this email already exists")
+
+	// ErrPasswordResetTokenInvalid is returned for a reset token that was never issued
+	ErrPasswordResetTokenInvalid = errors.New("invalid or expired reset token")
+	// ErrPasswordResetTokenExpired is returned for a reset token past its expiry
+	ErrPasswordResetTokenExpired = errors.New("reset token has expired")
+	// ErrPasswordResetTokenUsed is returned for a reset token that was already redeemed, or
+	// superseded by a newer reset request
+	ErrPasswordResetTokenUsed = errors.New("reset token has already been used")
 )
 
+// passwordResetTokenTTL is how long a password reset link stays valid
+const passwordResetTokenTTL = 24 * time.Hour
+
 // RegistrationRepository defines the interface for registration data persistence
 type RegistrationRepository interface {
 	// Registration requests
@@ -38,6 +53,9 @@ type RegistrationService struct {
 	orgRepo          domain.OrganizationRepository
 	auditService     *AuditService
 	emailService     domain.EmailService
+
+	passwordResetRepo domain.PasswordResetTokenRepository
+	now               func() time.Time
 }
 
 func NewRegistrationService(
@@ -46,20 +64,24 @@ func NewRegistrationService(
 	orgRepo domain.OrganizationRepository,
 	auditService *AuditService,
 	emailService domain.EmailService,
+	passwordResetRepo domain.PasswordResetTokenRepository,
 ) *RegistrationService {
 	return &RegistrationService{
-		registrationRepo: registrationRepo,
-		userRepo:         userRepo,
-		orgRepo:          orgRepo,
-		auditService:     auditService,
-		emailService:     emailService,
+		registrationRepo:  registrationRepo,
+		userRepo:          userRepo,
+		orgRepo:           orgRepo,
+		auditService:      auditService,
+		emailService:      emailService,
+		passwordResetRepo: passwordResetRepo,
+		now:               time.Now,
 	}
 }
 
-// CreateManualRegistrationRequest creates a registration request for email/password user registration
+// CreateManualRegistrationRequest creates a registration request for email/password user registration.
+// locale is the registrant's locale tag or Accept-Language list, used for the confirmation email.
 func (s *RegistrationService) CreateManualRegistrationRequest(
 	ctx context.Context,
-	email, firstName, lastName, password string,
+	email, firstName, lastName, password, locale string,
 ) (*domain.UserRegistrationRequest, error) {
 	// Check if user already exists
 	existingUser, err := s.userRepo.GetByEmail(email)
@@ -73,8 +95,17 @@ func (s *RegistrationService) CreateManualRegistrationRequest(
 		return nil, ErrRegistrationRequestExists
 	}
 
-	// Hash and validate password
-	passwordHasher := auth.NewPasswordHasher()
+	// Hash and validate password against the policy of the organization the email domain belongs to
+	emailDomain := extractEmailDomain(email)
+	policy := domain.DefaultPasswordPolicy()
+	var emailOrg *domain.Organization
+	if s.orgRepo != nil {
+		if org, err := s.orgRepo.GetByDomain(emailDomain); err == nil && org != nil {
+			policy = org.EffectivePasswordPolicy()
+			emailOrg = org
+		}
+	}
+	passwordHasher := auth.NewPasswordHasherWithPolicy(policy)
 	if err := passwordHasher.ValidatePassword(password); err != nil {
 		return nil, fmt.Errorf("password validation failed: %w", err)
 	}
@@ -85,7 +116,6 @@ func (s *RegistrationService) CreateManualRegistrationRequest(
 	}
 
 	// Check if this is the first user from this email domain (auto-approve if yes)
-	emailDomain := extractEmailDomain(email)
 	shouldAutoApprove := s.shouldAutoApproveFirstUser(ctx, emailDomain)
 
 	// Create new manual registration request
@@ -95,6 +125,7 @@ func (s *RegistrationService) CreateManualRegistrationRequest(
 		lastName,
 		hashedPassword,
 	)
+	req.Locale = truncateRegistrationLocale(locale)
 
 	// Auto-approve if first user from this domain
 	if shouldAutoApprove {
@@ -117,6 +148,9 @@ func (s *RegistrationService) CreateManualRegistrationRequest(
 		if err != nil {
 			return nil, fmt.Errorf("failed to find or create organization: %w", err)
 		}
+		if emailOrg == nil {
+			emailOrg = s.emailOrganization(targetOrgID)
+		}
 
 		// Create user account
 		fullName := firstName
@@ -184,11 +218,13 @@ func (s *RegistrationService) CreateManualRegistrationRequest(
 			DashboardURL: frontendURL,
 			SupportEmail: supportEmail,
 			Timestamp:    time.Now(),
+			Locale:       locale,
 			CustomData: map[string]interface{}{
 				"FirstName": firstName,
 				"LastName":  lastName,
 			},
 		}
+		emailOrg.BrandEmail(&templateData)
 
 		if err := s.emailService.SendTemplatedEmail(domain.TemplateWelcome, email, templateData); err != nil {
 			// Log error but don't fail the request (email is non-critical)
@@ -301,6 +337,15 @@ func (s *RegistrationService) ApproveRegistrationRequest(
 		return nil, fmt.Errorf("failed to find or create organization: %w", err)
 	}
 
+	// Check the organization's max_users before approving, so a rejected approval stays pending
+	existingUsers, err := s.userRepo.GetByOrganization(targetOrgID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check existing users: %w", err)
+	}
+	if err := s.checkUserQuota(targetOrgID, existingUsers); err != nil {
+		return nil, err
+	}
+
 	// Approve request
 	req.Approve(reviewerID)
 	if err := s.registrationRepo.UpdateRegistrationRequest(ctx, req); err != nil {
@@ -333,11 +378,6 @@ func (s *RegistrationService) ApproveRegistrationRequest(
 	}
 
 	// Check if this is the first user in the organization (make them admin)
-	existingUsers, err := s.userRepo.GetByOrganization(targetOrgID)
-	if err != nil {
-		return nil, fmt.Errorf("failed to check existing users: %w", err)
-	}
-	
 	userRole := domain.RoleViewer // Default to viewer
 	if len(existingUsers) == 0 {
 		userRole = domain.RoleAdmin // First user becomes admin
@@ -425,11 +465,13 @@ func (s *RegistrationService) ApproveRegistrationRequest(
 			DashboardURL: frontendURL,
 			SupportEmail: supportEmail,
 			Timestamp:    now,
+			Locale:       req.Locale,
 			CustomData: map[string]interface{}{
 				"LoginURL": loginURL,
 				"Role":     string(user.Role),
 			},
 		}
+		s.emailOrganization(targetOrgID).BrandEmail(&templateData)
 
 		if err := s.emailService.SendTemplatedEmail(domain.TemplateUserApproved, user.Email, templateData); err != nil {
 			// Log error but don't fail the request (email is non-critical)
@@ -470,10 +512,11 @@ func (s *RegistrationService) RejectRegistrationRequest(
 	return nil
 }
 
-// RequestPasswordReset generates a password reset token for a user and sends a reset email
+// RequestPasswordReset generates a password reset token for a user and sends a reset email.
+// locale is the requester's locale tag or Accept-Language list.
 func (s *RegistrationService) RequestPasswordReset(
 	ctx context.Context,
-	email string,
+	email, locale string,
 ) error {
 	// Normalize email
 	email = strings.ToLower(strings.TrimSpace(email))
@@ -491,18 +534,23 @@ func (s *RegistrationService) RequestPasswordReset(
 		return nil
 	}
 
-	// Generate password reset token (UUID format)
-	resetToken := uuid.New().String()
-
-	// Set expiration to 24 hours from now
-	expiresAt := time.Now().Add(24 * time.Hour)
+	// Generate a password reset token; only its hash is stored
+	resetToken, err := generatePasswordResetToken()
+	if err != nil {
+		return err
+	}
 
-	// Update user with reset token and expiration
-	user.PasswordResetToken = &resetToken
-	user.PasswordResetExpiresAt = &expiresAt
+	now := s.now()
+	expiresAt := now.Add(passwordResetTokenTTL)
 
-	if err := s.userRepo.Update(user); err != nil {
-		return fmt.Errorf("failed to update user with reset token: %w", err)
+	// Storing the new token invalidates any token requested earlier
+	if err := s.passwordResetRepo.Create(&domain.PasswordResetToken{
+		UserID:    user.ID,
+		TokenHash: hashPasswordResetToken(resetToken),
+		ExpiresAt: expiresAt,
+		CreatedAt: now,
+	}); err != nil {
+		return fmt.Errorf("failed to store reset token: %w", err)
 	}
 
 	// Send password reset email using template
@@ -524,13 +572,15 @@ func (s *RegistrationService) RequestPasswordReset(
 			UserEmail:    user.Email,
 			DashboardURL: frontendURL,
 			SupportEmail: supportEmail,
-			Timestamp:    time.Now(),
+			Timestamp:    now,
 			ExpiresAt:    expiresAt,
+			Locale:       locale,
 			CustomData: map[string]interface{}{
 				"ResetLink": resetLink,
 				"ExpiresIn": "24 hours",
 			},
 		}
+		s.emailOrganization(user.OrganizationID).BrandEmail(&templateData)
 
 		if err := s.emailService.SendTemplatedEmail(domain.TemplatePasswordReset, user.Email, templateData); err != nil {
 			// Log error but don't fail the request (email is non-critical)
@@ -559,30 +609,57 @@ func (s *RegistrationService) ResetPassword(
 		return fmt.Errorf("passwords do not match")
 	}
 
-	// Find user by reset token (automatically validates expiration)
-	user, err := s.userRepo.GetByPasswordResetToken(resetToken)
+	// Find the token by its hash and check it is still redeemable
+	token, err := s.passwordResetRepo.GetByTokenHash(hashPasswordResetToken(strings.TrimSpace(resetToken)))
 	if err != nil {
-		return fmt.Errorf("invalid or expired reset token")
+		return fmt.Errorf("failed to look up reset token: %w", err)
+	}
+	if token == nil {
+		return ErrPasswordResetTokenInvalid
+	}
+	if token.UsedAt != nil {
+		return ErrPasswordResetTokenUsed
+	}
+	now := s.now()
+	if !now.Before(token.ExpiresAt) {
+		return ErrPasswordResetTokenExpired
+	}
+
+	user, err := s.userRepo.GetByID(token.UserID)
+	if err != nil || user == nil || user.DeletedAt != nil || user.Status == domain.UserStatusDeactivated {
+		return ErrPasswordResetTokenInvalid
 	}
 
-	// Validate password strength
-	passwordHasher := auth.NewPasswordHasher()
+	// Validate password strength against the organization's password policy
+	passwordHasher, err := passwordHasherForOrganization(s.orgRepo, user.OrganizationID)
+	if err != nil {
+		return err
+	}
 	if err := passwordHasher.ValidatePassword(newPassword); err != nil {
 		return err
 	}
 
+	// Consume the token before changing the password, so it cannot be redeemed twice
+	consumed, err := s.passwordResetRepo.MarkUsed(token.ID, now)
+	if err != nil {
+		return fmt.Errorf("failed to consume reset token: %w", err)
+	}
+	if !consumed {
+		return ErrPasswordResetTokenUsed
+	}
+
 	// Hash new password
 	hashedPassword, err := passwordHasher.HashPassword(newPassword)
 	if err != nil {
 		return fmt.Errorf("failed to hash password: %w", err)
 	}
 
-	// Update user password and clear reset token
+	// Update user password and clear any legacy plaintext reset token
 	user.PasswordHash = &hashedPassword
 	user.PasswordResetToken = nil
 	user.PasswordResetExpiresAt = nil
 	user.ForcePasswordChange = false // Clear force password change if set
-	user.UpdatedAt = time.Now()
+	user.UpdatedAt = now
 
 	if err := s.userRepo.Update(user); err != nil {
 		return fmt.Errorf("failed to update password: %w", err)
@@ -606,6 +683,21 @@ func (s *RegistrationService) ResetPassword(
 	return nil
 }
 
+// generatePasswordResetToken returns a random URL-safe reset token
+func generatePasswordResetToken() (string, error) {
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return "", fmt.Errorf("failed to generate reset token: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(raw), nil
+}
+
+// hashPasswordResetToken returns the hex SHA-256 of a reset token, as stored in password_reset_tokens
+func hashPasswordResetToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
 // extractEmailDomain extracts the domain from an email address
 func extractEmailDomain(email string) string {
 	parts := strings.Split(email, "@")
@@ -615,6 +707,47 @@ func extractEmailDomain(email string) string {
 	return parts[1]
 }
 
+// checkUserQuota returns a *domain.QuotaExceededError if orgID has no seat left under its
+// max_users. Deactivated users do not take up a seat. This only rejects early; the repository
+// re-checks the quota under a lock on the organization when inserting.
+func (s *RegistrationService) checkUserQuota(orgID uuid.UUID, existingUsers []*domain.User) error {
+	org, err := s.orgRepo.GetByID(orgID)
+	if err != nil {
+		return fmt.Errorf("failed to load organization limits: %w", err)
+	}
+
+	seats := 0
+	for _, user := range existingUsers {
+		if user.Status != domain.UserStatusDeactivated {
+			seats++
+		}
+	}
+	return org.CheckUserQuota(seats, 1)
+}
+
+// maxRegistrationLocaleLength is the length of the user_registration_requests.locale column
+const maxRegistrationLocaleLength = 255
+
+// truncateRegistrationLocale cuts a locale (or Accept-Language list) to fit the registration request
+func truncateRegistrationLocale(locale string) string {
+	if len(locale) <= maxRegistrationLocaleLength {
+		return locale
+	}
+	return locale[:maxRegistrationLocaleLength]
+}
+
+// emailOrganization returns the organization used to brand emails, or nil if it is unknown
+func (s *RegistrationService) emailOrganization(orgID uuid.UUID) *domain.Organization {
+	if s.orgRepo == nil {
+		return nil
+	}
+	org, err := s.orgRepo.GetByID(orgID)
+	if err != nil {
+		return nil
+	}
+	return org
+}
+
 // findOrCreateOrganization finds an existing organization by domain or creates a new one
 func (s *RegistrationService) findOrCreateOrganization(ctx context.Context, domainName string) (uuid.UUID, error) {
 	// Try to find existing organization by domain
@@ -0,0 +1,191 @@
+package application
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// inMemoryPasswordResetTokenRepository is a stateful fake that, like the Postgres repository,
+// supersedes a user's outstanding tokens when a new one is created
+type inMemoryPasswordResetTokenRepository struct {
+	tokens map[string]*domain.PasswordResetToken // by hash
+}
+
+func newInMemoryPasswordResetTokenRepository() *inMemoryPasswordResetTokenRepository {
+	return &inMemoryPasswordResetTokenRepository{tokens: map[string]*domain.PasswordResetToken{}}
+}
+
+func (r *inMemoryPasswordResetTokenRepository) Create(token *domain.PasswordResetToken) error {
+	for _, existing := range r.tokens {
+		if existing.UserID == token.UserID && existing.UsedAt == nil {
+			at := token.CreatedAt
+			existing.UsedAt = &at
+		}
+	}
+	stored := *token
+	stored.ID = uuid.New()
+	r.tokens[token.TokenHash] = &stored
+	return nil
+}
+
+func (r *inMemoryPasswordResetTokenRepository) GetByTokenHash(tokenHash string) (*domain.PasswordResetToken, error) {
+	token, ok := r.tokens[tokenHash]
+	if !ok {
+		return nil, nil
+	}
+	copied := *token
+	return &copied, nil
+}
+
+func (r *inMemoryPasswordResetTokenRepository) MarkUsed(id uuid.UUID, at time.Time) (bool, error) {
+	for _, token := range r.tokens {
+		if token.ID == id {
+			if token.UsedAt != nil {
+				return false, nil
+			}
+			token.UsedAt = &at
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+type passwordResetFixture struct {
+	service    *RegistrationService
+	resetRepo  *inMemoryPasswordResetTokenRepository
+	userRepo   *MockUserRepository
+	user       *domain.User
+	now        time.Time
+	sentTokens []string
+	sentEmails []domain.EmailTemplateData
+}
+
+func newPasswordResetFixture(t *testing.T) *passwordResetFixture {
+	t.Helper()
+	f := &passwordResetFixture{
+		resetRepo: newInMemoryPasswordResetTokenRepository(),
+		userRepo:  new(MockUserRepository),
+		user:      createTestUser("ada@example.com"),
+		now:       time.Date(2025, 3, 4, 12, 0, 0, 0, time.UTC),
+	}
+
+	orgRepo := new(MockOrganizationRepository)
+	orgRepo.On("GetByID", f.user.OrganizationID).Return(&domain.Organization{
+		ID: f.user.OrganizationID, Name: "Acme", LogoURL: "https://cdn.example.com/acme.png", EmailLocale: "es",
+	}, nil)
+
+	f.userRepo.On("GetByEmail", f.user.Email).Return(f.user, nil)
+	f.userRepo.On("GetByID", f.user.ID).Return(f.user, nil)
+	f.userRepo.On("Update", f.user).Return(nil)
+
+	emailService := new(MockEmailService)
+	emailService.On("SendTemplatedEmail", domain.TemplatePasswordReset, f.user.Email, mock.Anything).
+		Run(func(args mock.Arguments) {
+			data := args.Get(2).(domain.EmailTemplateData)
+			f.sentEmails = append(f.sentEmails, data)
+			link := data.CustomData["ResetLink"].(string)
+			f.sentTokens = append(f.sentTokens, link[strings.Index(link, "token=")+len("token="):])
+		}).
+		Return(nil)
+
+	f.service = NewRegistrationService(nil, f.userRepo, orgRepo, NewAuditService(&chainAuditLogRepository{}), emailService, f.resetRepo)
+	f.service.now = func() time.Time { return f.now }
+	return f
+}
+
+// requestToken requests a reset and returns the token sent by email
+func (f *passwordResetFixture) requestToken(t *testing.T) string {
+	t.Helper()
+	require.NoError(t, f.service.RequestPasswordReset(context.Background(), f.user.Email, ""))
+	require.NotEmpty(t, f.sentTokens)
+	return f.sentTokens[len(f.sentTokens)-1]
+}
+
+func (f *passwordResetFixture) reset(token string) error {
+	return f.service.ResetPassword(context.Background(), token, "N3w-Secure-Passw0rd!", "N3w-Secure-Passw0rd!")
+}
+
+func TestRegistrationService_PasswordReset_StoresOnlyHashedToken(t *testing.T) {
+	f := newPasswordResetFixture(t)
+	token := f.requestToken(t)
+
+	require.Len(t, f.resetRepo.tokens, 1)
+	for hash, stored := range f.resetRepo.tokens {
+		assert.NotEqual(t, token, hash)
+		assert.Equal(t, hashPasswordResetToken(token), stored.TokenHash)
+		assert.Equal(t, f.user.ID, stored.UserID)
+		assert.Equal(t, f.now.Add(passwordResetTokenTTL), stored.ExpiresAt)
+		assert.Nil(t, stored.UsedAt)
+	}
+
+	require.NoError(t, f.reset(token))
+	assert.NotNil(t, f.user.PasswordHash)
+	f.userRepo.AssertCalled(t, "Update", f.user)
+}
+
+func TestRegistrationService_PasswordReset_EmailIsBrandedAndLocalized(t *testing.T) {
+	f := newPasswordResetFixture(t)
+
+	require.NoError(t, f.service.RequestPasswordReset(context.Background(), f.user.Email, "fr-CA,fr;q=0.9"))
+	require.NoError(t, f.service.RequestPasswordReset(context.Background(), f.user.Email, ""))
+
+	require.Len(t, f.sentEmails, 2)
+	for _, data := range f.sentEmails {
+		assert.Equal(t, "Acme", data.OrganizationName)
+		assert.Equal(t, "https://cdn.example.com/acme.png", data.OrganizationLogoURL)
+	}
+	assert.Equal(t, "fr-CA,fr;q=0.9", f.sentEmails[0].Locale) // The requester's language wins
+	assert.Equal(t, "es", f.sentEmails[1].Locale)             // Otherwise the organization's
+}
+
+func TestRegistrationService_PasswordReset_RejectsExpiredToken(t *testing.T) {
+	f := newPasswordResetFixture(t)
+	token := f.requestToken(t)
+
+	f.now = f.now.Add(passwordResetTokenTTL)
+	assert.ErrorIs(t, f.reset(token), ErrPasswordResetTokenExpired)
+	f.userRepo.AssertNotCalled(t, "Update", mock.Anything)
+}
+
+func TestRegistrationService_PasswordReset_RejectsReusedToken(t *testing.T) {
+	f := newPasswordResetFixture(t)
+	token := f.requestToken(t)
+
+	require.NoError(t, f.reset(token))
+	assert.ErrorIs(t, f.reset(token), ErrPasswordResetTokenUsed)
+	f.userRepo.AssertNumberOfCalls(t, "Update", 1)
+}
+
+func TestRegistrationService_PasswordReset_NewRequestSupersedesOutstandingToken(t *testing.T) {
+	f := newPasswordResetFixture(t)
+	first := f.requestToken(t)
+	f.now = f.now.Add(time.Minute)
+	second := f.requestToken(t)
+	require.NotEqual(t, first, second)
+
+	assert.ErrorIs(t, f.reset(first), ErrPasswordResetTokenUsed)
+	assert.NoError(t, f.reset(second))
+}
+
+func TestRegistrationService_PasswordReset_RejectsUnknownToken(t *testing.T) {
+	f := newPasswordResetFixture(t)
+	f.requestToken(t)
+
+	assert.ErrorIs(t, f.reset("not-a-real-token"), ErrPasswordResetTokenInvalid)
+}
+
+func TestRegistrationService_PasswordReset_WeakPasswordDoesNotConsumeToken(t *testing.T) {
+	f := newPasswordResetFixture(t)
+	token := f.requestToken(t)
+
+	require.Error(t, f.service.ResetPassword(context.Background(), token, "short", "short"))
+	assert.NoError(t, f.reset(token))
+}
@@ -0,0 +1,292 @@
+package application
+
+import (
+	"context"
+	"crypto/ed25519"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/domain"
+	"github.com/opena2a/identity/backend/internal/infrastructure/logging"
+)
+
+const (
+	// sdkRecoveryFailureThreshold is how many failed recoveries of one token lock its recovery;
+	// a client IP may fail sdkRecoveryIPMultiplier times as often across tokens
+	sdkRecoveryFailureThreshold = 5
+	sdkRecoveryIPMultiplier     = 4
+	// sdkRecoveryLockoutDuration is how long recovery stays locked once the threshold is reached
+	sdkRecoveryLockoutDuration = time.Hour
+	// sdkRecoveryFailureWindow is how long failed recoveries are remembered after the first one
+	sdkRecoveryFailureWindow = 24 * time.Hour
+
+	sdkRecoveryFailuresPrefix = "sdk_recovery_failures:"
+	sdkRecoveryLockPrefix     = "sdk_recovery_lock:"
+
+	// sdkRecoveryMessagePrefix separates recovery signatures from signed API requests
+	sdkRecoveryMessagePrefix = "aim-sdk-token-recovery"
+)
+
+var (
+	ErrSDKRecoveryProofMissing     = errors.New("token recovery must be signed with the private key of one of your agents")
+	ErrSDKRecoveryAgentInvalid     = errors.New("agent cannot recover this token")
+	ErrSDKRecoverySignatureInvalid = errors.New("invalid recovery signature")
+)
+
+// SDKTokenRecoveryProof proves that whoever recovers a revoked SDK token also holds the private
+// key of an agent registered by the token's user
+type SDKTokenRecoveryProof struct {
+	AgentID   uuid.UUID
+	Timestamp string // RFC 3339, within the verification clock skew
+	Signature string // Base64 Ed25519 signature of SDKTokenRecoveryMessage
+}
+
+// SDKTokenRecoveryMessage returns the message an agent signs to recover the SDK token tokenID
+func SDKTokenRecoveryMessage(tokenID string, agentID uuid.UUID, timestamp string) string {
+	return strings.Join([]string{sdkRecoveryMessagePrefix, tokenID, agentID.String(), timestamp}, "\n")
+}
+
+// SDKTokenRecoveryGuard authorizes the recovery of revoked SDK tokens. A recovery must carry a
+// fresh, single-use proof-of-possession signed by an active agent of the token's user. Failed
+// recoveries are counted per token and per client IP; reaching the threshold locks recovery for
+// sdkRecoveryLockoutDuration and raises a security alert in the token's organization.
+type SDKTokenRecoveryGuard struct {
+	agentRepo   domain.AgentRepository
+	alertRepo   domain.AlertRepository
+	replayGuard *VerificationReplayGuard
+	store       LoginAttemptStore
+
+	mu       sync.Mutex
+	failures map[string]loginFailureCounter // in-memory fallback
+	locks    map[string]time.Time           // in-memory fallback: key -> locked until
+}
+
+// NewSDKTokenRecoveryGuard creates a recovery guard. store may be nil, in which case (and
+// whenever the store errors) failed attempts are tracked in memory. alertRepo may be nil.
+func NewSDKTokenRecoveryGuard(agentRepo domain.AgentRepository, alertRepo domain.AlertRepository, replayGuard *VerificationReplayGuard, store LoginAttemptStore) *SDKTokenRecoveryGuard {
+	return &SDKTokenRecoveryGuard{
+		agentRepo:   agentRepo,
+		alertRepo:   alertRepo,
+		replayGuard: replayGuard,
+		store:       store,
+		failures:    make(map[string]loginFailureCounter),
+		locks:       make(map[string]time.Time),
+	}
+}
+
+// RetryAfter returns how long recovery of tokenID from ipAddress stays locked, or 0 if it is allowed
+func (g *SDKTokenRecoveryGuard) RetryAfter(ctx context.Context, tokenID, ipAddress string, now time.Time) time.Duration {
+	var retryAfter time.Duration
+	for _, subject := range sdkRecoverySubjects(tokenID, ipAddress) {
+		if remaining := g.lockedFor(ctx, subject, now); remaining > retryAfter {
+			retryAfter = remaining
+		}
+	}
+	return retryAfter
+}
+
+// Verify checks the proof-of-possession for recovering token and returns the signing agent
+func (g *SDKTokenRecoveryGuard) Verify(ctx context.Context, token *domain.SDKToken, proof SDKTokenRecoveryProof, now time.Time) (*domain.Agent, error) {
+	if proof.AgentID == uuid.Nil || proof.Timestamp == "" || proof.Signature == "" {
+		return nil, ErrSDKRecoveryProofMissing
+	}
+	if err := g.replayGuard.CheckTimestamp(proof.Timestamp, now); err != nil {
+		return nil, err
+	}
+
+	agent, err := g.agentRepo.GetByID(proof.AgentID)
+	if err != nil || agent == nil {
+		return nil, ErrSDKRecoveryAgentInvalid
+	}
+	// The agent must belong to the token's user, and be trusted with its own key
+	if agent.OrganizationID != token.OrganizationID || agent.CreatedBy != token.UserID {
+		return nil, ErrSDKRecoveryAgentInvalid
+	}
+	if agent.Status == domain.AgentStatusSuspended || agent.Status == domain.AgentStatusRevoked || agent.IsCompromised {
+		return nil, ErrSDKRecoveryAgentInvalid
+	}
+	if agent.KeyExpiresAt != nil && now.After(*agent.KeyExpiresAt) {
+		return nil, ErrSDKRecoveryAgentInvalid
+	}
+	// Only a registered key counts; the previous key's rotation grace period does not extend to recovery
+	if agent.PublicKey == nil || *agent.PublicKey == "" {
+		return nil, ErrSDKRecoveryAgentInvalid
+	}
+
+	publicKey, err := base64.StdEncoding.DecodeString(*agent.PublicKey)
+	if err != nil || len(publicKey) != ed25519.PublicKeySize {
+		return nil, ErrSDKRecoveryAgentInvalid
+	}
+	signature, err := base64.StdEncoding.DecodeString(proof.Signature)
+	if err != nil {
+		return nil, ErrSDKRecoverySignatureInvalid
+	}
+	message := SDKTokenRecoveryMessage(token.TokenID, agent.ID, proof.Timestamp)
+	if !ed25519.Verify(ed25519.PublicKey(publicKey), []byte(message), signature) {
+		return nil, ErrSDKRecoverySignatureInvalid
+	}
+
+	if err := g.replayGuard.MarkSignatureUsed(ctx, proof.Signature, now); err != nil {
+		return nil, err
+	}
+	return agent, nil
+}
+
+// RecordFailure counts a failed recovery of tokenID. token is nil when no such token exists. If
+// the failure locks the token or the IP, the lockout duration is returned (0 otherwise), and a
+// security alert is raised for a locked token.
+func (g *SDKTokenRecoveryGuard) RecordFailure(ctx context.Context, token *domain.SDKToken, tokenID, ipAddress string, reason error, now time.Time) time.Duration {
+	var retryAfter time.Duration
+	for _, subject := range sdkRecoverySubjects(tokenID, ipAddress) {
+		threshold := int64(sdkRecoveryFailureThreshold)
+		if strings.HasPrefix(subject, "ip:") {
+			threshold *= sdkRecoveryIPMultiplier
+		}
+
+		count := g.incrementFailures(ctx, subject, now)
+		if count < threshold {
+			continue
+		}
+
+		g.lock(ctx, subject, now)
+		retryAfter = sdkRecoveryLockoutDuration
+		if count == threshold {
+			g.raiseFailureAlert(ctx, subject, token, count, ipAddress, reason, now)
+		}
+	}
+	return retryAfter
+}
+
+// RecordSuccess clears the failed recovery counters of tokenID and ipAddress
+func (g *SDKTokenRecoveryGuard) RecordSuccess(ctx context.Context, tokenID, ipAddress string) {
+	for _, subject := range sdkRecoverySubjects(tokenID, ipAddress) {
+		g.resetFailures(ctx, subject)
+	}
+}
+
+// raiseFailureAlert logs the lockout and, for a known token, creates a security alert in its organization
+func (g *SDKTokenRecoveryGuard) raiseFailureAlert(ctx context.Context, subject string, token *domain.SDKToken, failures int64, ipAddress string, reason error, now time.Time) {
+	logger := logging.FromContext(ctx)
+	logger.Warn("security alert: SDK token recovery locked after repeated failures",
+		"subject", subject, "failures", failures, "ip_address", ipAddress, "last_error", reason)
+
+	if !strings.HasPrefix(subject, "token:") || token == nil || g.alertRepo == nil {
+		return
+	}
+
+	alert := &domain.Alert{
+		ID:             uuid.New(),
+		OrganizationID: token.OrganizationID,
+		AlertType:      domain.AlertSecurityBreach,
+		Severity:       domain.AlertSeverityHigh,
+		Title:          "Repeated failed SDK token recovery",
+		Description: fmt.Sprintf(
+			"Recovery of revoked SDK token %s was locked for %s after %d failed attempts (last error: %v). Last attempt from %s. "+
+				"Someone may hold a leaked copy of this token.",
+			token.TokenID, sdkRecoveryLockoutDuration, failures, reason, ipAddress,
+		),
+		ResourceType:   "sdk_token",
+		ResourceID:     token.ID,
+		IsAcknowledged: false,
+		CreatedAt:      now,
+	}
+	if err := g.alertRepo.Create(alert); err != nil {
+		logger.Warn("failed to create SDK token recovery alert", "token_id", token.TokenID, "error", err)
+	}
+}
+
+func sdkRecoverySubjects(tokenID, ipAddress string) []string {
+	var subjects []string
+	if tokenID != "" {
+		subjects = append(subjects, "token:"+tokenID)
+	}
+	if ipAddress != "" {
+		subjects = append(subjects, "ip:"+ipAddress)
+	}
+	return subjects
+}
+
+func (g *SDKTokenRecoveryGuard) incrementFailures(ctx context.Context, subject string, now time.Time) int64 {
+	if g.store != nil {
+		count, err := g.store.IncrementWithExpiry(ctx, sdkRecoveryFailuresPrefix+subject, sdkRecoveryFailureWindow)
+		if err == nil {
+			return count
+		}
+		logging.FromContext(ctx).Warn("recovery attempt store unavailable, using in-memory tracking", "error", err)
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	counter, ok := g.failures[subject]
+	if !ok || !now.Before(counter.expiresAt) {
+		// Drop expired counters while we hold the lock so the map doesn't grow unbounded
+		for key, c := range g.failures {
+			if !now.Before(c.expiresAt) {
+				delete(g.failures, key)
+			}
+		}
+		counter = loginFailureCounter{expiresAt: now.Add(sdkRecoveryFailureWindow)}
+	}
+	counter.count++
+	g.failures[subject] = counter
+	return counter.count
+}
+
+func (g *SDKTokenRecoveryGuard) lock(ctx context.Context, subject string, now time.Time) {
+	if g.store != nil {
+		err := g.store.Set(ctx, sdkRecoveryLockPrefix+subject, now.Unix(), sdkRecoveryLockoutDuration)
+		if err == nil {
+			return
+		}
+		logging.FromContext(ctx).Warn("recovery attempt store unavailable, using in-memory tracking", "error", err)
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	for key, until := range g.locks {
+		if !now.Before(until) {
+			delete(g.locks, key)
+		}
+	}
+	g.locks[subject] = now.Add(sdkRecoveryLockoutDuration)
+}
+
+func (g *SDKTokenRecoveryGuard) lockedFor(ctx context.Context, subject string, now time.Time) time.Duration {
+	if g.store != nil {
+		ttl, err := g.store.GetTTL(ctx, sdkRecoveryLockPrefix+subject)
+		if err == nil {
+			if ttl < 0 {
+				ttl = 0 // Missing key
+			}
+			return ttl
+		}
+		logging.FromContext(ctx).Warn("recovery attempt store unavailable, using in-memory tracking", "error", err)
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if until, ok := g.locks[subject]; ok && now.Before(until) {
+		return until.Sub(now)
+	}
+	return 0
+}
+
+func (g *SDKTokenRecoveryGuard) resetFailures(ctx context.Context, subject string) {
+	if g.store != nil {
+		if err := g.store.Delete(ctx, sdkRecoveryFailuresPrefix+subject); err != nil {
+			logging.FromContext(ctx).Warn("failed to reset recovery failures", "subject", subject, "error", err)
+		}
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	delete(g.failures, subject)
+}
@@ -0,0 +1,200 @@
+package application
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type sdkTokenRecoveryFixture struct {
+	guard      *SDKTokenRecoveryGuard
+	alertRepo  *inMemoryAlertRepository
+	token      *domain.SDKToken
+	agent      *domain.Agent
+	privateKey ed25519.PrivateKey
+	now        time.Time
+}
+
+func newSDKTokenRecoveryFixture(t *testing.T) *sdkTokenRecoveryFixture {
+	t.Helper()
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	encodedKey := base64.StdEncoding.EncodeToString(publicKey)
+
+	revokedAt := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	f := &sdkTokenRecoveryFixture{
+		alertRepo: &inMemoryAlertRepository{},
+		token: &domain.SDKToken{
+			ID:             uuid.New(),
+			UserID:         uuid.New(),
+			OrganizationID: uuid.New(),
+			TokenID:        "jti-revoked",
+			RevokedAt:      &revokedAt,
+		},
+		privateKey: privateKey,
+		now:        time.Date(2025, 3, 4, 12, 0, 0, 0, time.UTC),
+	}
+	f.agent = &domain.Agent{
+		ID:             uuid.New(),
+		OrganizationID: f.token.OrganizationID,
+		CreatedBy:      f.token.UserID,
+		Status:         domain.AgentStatusVerified,
+		PublicKey:      &encodedKey,
+	}
+
+	agentRepo := new(MockAgentRepository)
+	agentRepo.On("GetByID", f.agent.ID).Return(f.agent, nil)
+	agentRepo.On("GetByID", uuid.Nil).Return(nil, errors.New("agent not found"))
+
+	f.guard = NewSDKTokenRecoveryGuard(agentRepo, f.alertRepo, NewVerificationReplayGuard(nil, DefaultVerificationClockSkew), nil)
+	return f
+}
+
+// proof signs the recovery of the fixture's token with key at the fixture's current time
+func (f *sdkTokenRecoveryFixture) proof(key ed25519.PrivateKey) SDKTokenRecoveryProof {
+	timestamp := f.now.Format(time.RFC3339)
+	message := SDKTokenRecoveryMessage(f.token.TokenID, f.agent.ID, timestamp)
+	return SDKTokenRecoveryProof{
+		AgentID:   f.agent.ID,
+		Timestamp: timestamp,
+		Signature: base64.StdEncoding.EncodeToString(ed25519.Sign(key, []byte(message))),
+	}
+}
+
+func TestSDKTokenRecoveryGuard_AcceptsProofFromUsersAgent(t *testing.T) {
+	f := newSDKTokenRecoveryFixture(t)
+
+	agent, err := f.guard.Verify(context.Background(), f.token, f.proof(f.privateKey), f.now)
+
+	require.NoError(t, err)
+	assert.Equal(t, f.agent.ID, agent.ID)
+}
+
+func TestSDKTokenRecoveryGuard_RejectsUnsignedRecovery(t *testing.T) {
+	f := newSDKTokenRecoveryFixture(t)
+
+	_, err := f.guard.Verify(context.Background(), f.token, SDKTokenRecoveryProof{}, f.now)
+	assert.ErrorIs(t, err, ErrSDKRecoveryProofMissing)
+
+	proof := f.proof(f.privateKey)
+	proof.Signature = ""
+	_, err = f.guard.Verify(context.Background(), f.token, proof, f.now)
+	assert.ErrorIs(t, err, ErrSDKRecoveryProofMissing)
+}
+
+func TestSDKTokenRecoveryGuard_RejectsInvalidProof(t *testing.T) {
+	_, otherKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name    string
+		prepare func(f *sdkTokenRecoveryFixture) SDKTokenRecoveryProof
+		wantErr error
+	}{
+		{
+			name:    "signed with another key",
+			prepare: func(f *sdkTokenRecoveryFixture) SDKTokenRecoveryProof { return f.proof(otherKey) },
+			wantErr: ErrSDKRecoverySignatureInvalid,
+		},
+		{
+			name: "signed for another token",
+			prepare: func(f *sdkTokenRecoveryFixture) SDKTokenRecoveryProof {
+				proof := f.proof(f.privateKey)
+				f.token.TokenID = "jti-other"
+				return proof
+			},
+			wantErr: ErrSDKRecoverySignatureInvalid,
+		},
+		{
+			name: "agent of another user",
+			prepare: func(f *sdkTokenRecoveryFixture) SDKTokenRecoveryProof {
+				f.agent.CreatedBy = uuid.New()
+				return f.proof(f.privateKey)
+			},
+			wantErr: ErrSDKRecoveryAgentInvalid,
+		},
+		{
+			name: "compromised agent",
+			prepare: func(f *sdkTokenRecoveryFixture) SDKTokenRecoveryProof {
+				f.agent.IsCompromised = true
+				return f.proof(f.privateKey)
+			},
+			wantErr: ErrSDKRecoveryAgentInvalid,
+		},
+		{
+			name: "stale timestamp",
+			prepare: func(f *sdkTokenRecoveryFixture) SDKTokenRecoveryProof {
+				proof := f.proof(f.privateKey)
+				f.now = f.now.Add(time.Hour)
+				return proof
+			},
+			wantErr: ErrVerificationTimestampSkewed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newSDKTokenRecoveryFixture(t)
+			proof := tt.prepare(f)
+
+			_, err := f.guard.Verify(context.Background(), f.token, proof, f.now)
+			assert.ErrorIs(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestSDKTokenRecoveryGuard_RejectsReplayedProof(t *testing.T) {
+	f := newSDKTokenRecoveryFixture(t)
+	proof := f.proof(f.privateKey)
+
+	_, err := f.guard.Verify(context.Background(), f.token, proof, f.now)
+	require.NoError(t, err)
+
+	_, err = f.guard.Verify(context.Background(), f.token, proof, f.now.Add(time.Second))
+	assert.ErrorIs(t, err, ErrVerificationReplayed)
+}
+
+func TestSDKTokenRecoveryGuard_LocksAndAlertsAfterRepeatedFailures(t *testing.T) {
+	f := newSDKTokenRecoveryFixture(t)
+	ctx := context.Background()
+
+	for i := 1; i < sdkRecoveryFailureThreshold; i++ {
+		assert.Zero(t, f.guard.RecordFailure(ctx, f.token, f.token.TokenID, "198.51.100.1", ErrSDKRecoverySignatureInvalid, f.now))
+	}
+	assert.Zero(t, f.guard.RetryAfter(ctx, f.token.TokenID, "198.51.100.1", f.now))
+	assert.Empty(t, f.alertRepo.alerts)
+
+	retryAfter := f.guard.RecordFailure(ctx, f.token, f.token.TokenID, "198.51.100.1", ErrSDKRecoverySignatureInvalid, f.now)
+	assert.Equal(t, sdkRecoveryLockoutDuration, retryAfter)
+	assert.Equal(t, sdkRecoveryLockoutDuration, f.guard.RetryAfter(ctx, f.token.TokenID, "203.0.113.9", f.now))
+
+	require.Len(t, f.alertRepo.alerts, 1)
+	alert := f.alertRepo.alerts[0]
+	assert.Equal(t, domain.AlertSecurityBreach, alert.AlertType)
+	assert.Equal(t, f.token.OrganizationID, alert.OrganizationID)
+	assert.Equal(t, "sdk_token", alert.ResourceType)
+	assert.Equal(t, f.token.ID, alert.ResourceID)
+
+	assert.Zero(t, f.guard.RetryAfter(ctx, f.token.TokenID, "198.51.100.1", f.now.Add(sdkRecoveryLockoutDuration)))
+}
+
+func TestSDKTokenRecoveryGuard_SuccessResetsFailures(t *testing.T) {
+	f := newSDKTokenRecoveryFixture(t)
+	ctx := context.Background()
+
+	for i := 1; i < sdkRecoveryFailureThreshold; i++ {
+		f.guard.RecordFailure(ctx, f.token, f.token.TokenID, "198.51.100.1", ErrSDKRecoverySignatureInvalid, f.now)
+	}
+	f.guard.RecordSuccess(ctx, f.token.TokenID, "198.51.100.1")
+
+	assert.Zero(t, f.guard.RecordFailure(ctx, f.token, f.token.TokenID, "198.51.100.1", ErrSDKRecoverySignatureInvalid, f.now))
+}
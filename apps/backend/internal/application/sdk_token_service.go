@@ -63,8 +63,8 @@ func (s *SDKTokenService) GetActiveTokenCount(ctx context.Context, userID uuid.U
 }
 
 // RecordTokenUsage updates token usage statistics
-func (s *SDKTokenService) RecordTokenUsage(ctx context.Context, tokenID string, ipAddress string) error {
-	return s.sdkTokenRepo.RecordUsage(tokenID, ipAddress)
+func (s *SDKTokenService) RecordTokenUsage(ctx context.Context, usage domain.SDKTokenUsage) error {
+	return s.sdkTokenRepo.RecordUsage(usage)
 }
 
 // ValidateToken checks if a token is active (not revoked, not expired)
@@ -3,18 +3,26 @@ package application
 import (
 	"context"
 	"fmt"
+	"math"
+	"sort"
 	"strings"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/opena2a/identity/backend/internal/domain"
+	"github.com/opena2a/identity/backend/internal/infrastructure/logging"
+	"github.com/opena2a/identity/backend/internal/infrastructure/metrics"
 )
 
 // SecurityPolicyService handles security policy evaluation and management
 type SecurityPolicyService struct {
-	policyRepo   domain.SecurityPolicyRepository
-	alertRepo    domain.AlertRepository
-	auditLogRepo domain.AuditLogRepository
+	policyRepo            domain.SecurityPolicyRepository
+	alertRepo             domain.AlertRepository
+	auditLogRepo          domain.AuditLogRepository
+	verificationEventRepo domain.VerificationEventRepository
+	baselineRepo          domain.AgentBaselineRepository
+	simulationRepo        domain.PolicySimulationRepository
+	readTracker           *ReadActivityTracker
 }
 
 // NewSecurityPolicyService creates a new security policy service
@@ -22,16 +30,40 @@ func NewSecurityPolicyService(
 	policyRepo domain.SecurityPolicyRepository,
 	alertRepo domain.AlertRepository,
 	auditLogRepo domain.AuditLogRepository,
+	verificationEventRepo domain.VerificationEventRepository,
+	baselineRepo domain.AgentBaselineRepository,
+	simulationRepo domain.PolicySimulationRepository,
 ) *SecurityPolicyService {
 	return &SecurityPolicyService{
-		policyRepo:   policyRepo,
-		alertRepo:    alertRepo,
-		auditLogRepo: auditLogRepo,
+		policyRepo:            policyRepo,
+		alertRepo:             alertRepo,
+		auditLogRepo:          auditLogRepo,
+		verificationEventRepo: verificationEventRepo,
+		baselineRepo:          baselineRepo,
+		simulationRepo:        simulationRepo,
+		readTracker:           NewReadActivityTracker(),
 	}
 }
 
+// getPoliciesByType fetches an organization's enabled policies of one type, ordered for
+// evaluation: highest priority first, ties keeping the repository's order (newest first).
+// Evaluators walk this list and the first policy that matches the agent decides enforcement.
+func (s *SecurityPolicyService) getPoliciesByType(orgID uuid.UUID, policyType domain.PolicyType) ([]*domain.SecurityPolicy, error) {
+	policies, err := s.policyRepo.GetByType(orgID, policyType)
+	if err != nil {
+		return nil, err
+	}
+
+	sorted := append([]*domain.SecurityPolicy(nil), policies...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Priority > sorted[j].Priority
+	})
+	return sorted, nil
+}
+
 // EvaluateCapabilityViolation evaluates security policies for capability violations
-// Returns enforcement decision and whether to create an alert
+// Returns enforcement decision and whether to create an alert. When several policies
+// apply to the agent, the highest-priority one wins and policyName reports which it was.
 func (s *SecurityPolicyService) EvaluateCapabilityViolation(
 	ctx context.Context,
 	agent *domain.Agent,
@@ -40,7 +72,7 @@ func (s *SecurityPolicyService) EvaluateCapabilityViolation(
 	auditID uuid.UUID,
 ) (shouldBlock bool, shouldAlert bool, policyName string, err error) {
 	// 1. Get active capability_violation policies for this organization
-	policies, err := s.policyRepo.GetByType(agent.OrganizationID, domain.PolicyTypeCapabilityViolation)
+	policies, err := s.getPoliciesByType(agent.OrganizationID, domain.PolicyTypeCapabilityViolation)
 	if err != nil {
 		return false, false, "", fmt.Errorf("failed to fetch policies: %w", err)
 	}
@@ -51,8 +83,12 @@ func (s *SecurityPolicyService) EvaluateCapabilityViolation(
 		return true, true, "default_policy", nil
 	}
 
-	// 3. Evaluate policies by priority (highest first)
+	// 3. Evaluate policies by priority (highest first); the first match wins
 	for _, policy := range policies {
+		if !policy.IsEnabled {
+			continue
+		}
+
 		// Check if policy applies to this agent
 		if !s.policyAppliesToAgent(policy, agent) {
 			continue
@@ -69,6 +105,10 @@ func (s *SecurityPolicyService) EvaluateCapabilityViolation(
 			return false, true, policy.Name, nil
 		case domain.EnforcementAllow:
 			return false, false, policy.Name, nil
+		case domain.EnforcementSimulate:
+			// Simulation never blocks; falling through would hit the block + alert default
+			s.recordSimulatedBlock(ctx, policy, agent, actionType, resource, auditID)
+			return false, false, policy.Name, nil
 		default:
 			// Unknown enforcement action - use safe default
 			return true, true, policy.Name, nil
@@ -80,12 +120,18 @@ func (s *SecurityPolicyService) EvaluateCapabilityViolation(
 	return true, true, "default_policy", nil
 }
 
+// policyAgentTypeAliases maps the short agent_type scope names to agent types
+var policyAgentTypeAliases = map[string]domain.AgentType{
+	"ai":  domain.AgentTypeAI,
+	"mcp": domain.AgentTypeMCP,
+}
+
 // policyAppliesToAgent checks if a policy applies to a specific agent
 func (s *SecurityPolicyService) policyAppliesToAgent(policy *domain.SecurityPolicy, agent *domain.Agent) bool {
 	appliesTo := policy.AppliesTo
 
 	// Apply to all agents
-	if appliesTo == "all" {
+	if appliesTo == "all" || appliesTo == "all_agents" {
 		return true
 	}
 
@@ -95,12 +141,26 @@ func (s *SecurityPolicyService) policyAppliesToAgent(policy *domain.SecurityPoli
 		return targetID == agent.ID.String()
 	}
 
-	// Apply to specific agent type
+	// Apply to specific agent type, by full name ("mcp_server") or short alias ("mcp")
 	if strings.HasPrefix(appliesTo, "agent_type:") {
 		targetType := strings.TrimPrefix(appliesTo, "agent_type:")
+		if alias, ok := policyAgentTypeAliases[targetType]; ok {
+			targetType = string(alias)
+		}
 		return targetType == string(agent.AgentType)
 	}
 
+	// Apply to agents carrying a label, e.g. "label:env=prod" ("label:env:prod" is also accepted)
+	if strings.HasPrefix(appliesTo, "label:") {
+		selector := strings.TrimPrefix(appliesTo, "label:")
+		key, value, found := strings.Cut(selector, "=")
+		if !found {
+			key, value, _ = strings.Cut(selector, ":")
+		}
+		labelValue, ok := agent.Labels[key]
+		return ok && labelValue == value
+	}
+
 	// Apply to agents with trust score below threshold
 	if strings.HasPrefix(appliesTo, "trust_score_below:") {
 		var threshold float64
@@ -112,6 +172,67 @@ func (s *SecurityPolicyService) policyAppliesToAgent(policy *domain.SecurityPoli
 	return true
 }
 
+// recordSimulatedBlock records that a simulate-mode policy would have blocked the action.
+// Nothing is blocked: capability violations matching a simulated policy are allowed, other checks
+// carry on evaluating lower-priority policies. Failures are logged, never returned, so simulation
+// cannot affect traffic.
+func (s *SecurityPolicyService) recordSimulatedBlock(
+	ctx context.Context,
+	policy *domain.SecurityPolicy,
+	agent *domain.Agent,
+	actionType string,
+	resource string,
+	auditID uuid.UUID,
+) {
+	logger := logging.FromContext(ctx)
+	logger.Info("simulated security policy would block action",
+		"policy", policy.Name, "policy_id", policy.ID, "agent_id", agent.ID, "action_type", actionType)
+
+	recorded := true
+	if s.simulationRepo != nil {
+		var err error
+		recorded, err = s.simulationRepo.Record(&domain.PolicySimulation{
+			ID:             uuid.New(),
+			OrganizationID: agent.OrganizationID,
+			PolicyID:       policy.ID,
+			AgentID:        agent.ID,
+			ActionType:     actionType,
+			Resource:       resource,
+			AuditID:        auditID,
+			CreatedAt:      time.Now(),
+		})
+		if err != nil {
+			logger.Warn("failed to record policy simulation", "policy_id", policy.ID, "error", err)
+			return
+		}
+	}
+
+	if recorded {
+		metrics.RecordPolicySimulation(string(policy.PolicyType))
+	}
+}
+
+// GetSimulationReport summarizes the actions policy would have blocked in simulate mode since the given time
+func (s *SecurityPolicyService) GetSimulationReport(ctx context.Context, policy *domain.SecurityPolicy, since time.Time) (*domain.PolicySimulationReport, error) {
+	report := &domain.PolicySimulationReport{
+		PolicyID:          policy.ID,
+		PolicyName:        policy.Name,
+		PolicyType:        policy.PolicyType,
+		EnforcementAction: policy.EnforcementAction,
+		Since:             since,
+		ByAgent:           []domain.PolicySimulationCount{},
+		ByActionType:      []domain.PolicySimulationCount{},
+	}
+	if s.simulationRepo == nil {
+		return report, nil
+	}
+
+	if err := s.simulationRepo.GetReport(report); err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
 // CreateDefaultPolicies creates default security policies for a new organization
 func (s *SecurityPolicyService) CreateDefaultPolicies(ctx context.Context, orgID, userID uuid.UUID) error {
 	// Default Policy 1: Block and Alert on Capability Violations (HIGH priority)
@@ -230,6 +351,101 @@ func (s *SecurityPolicyService) DisablePolicy(ctx context.Context, id uuid.UUID)
 	return s.policyRepo.Update(policy)
 }
 
+// ExportPolicies returns the organization's security policies as a portable document
+func (s *SecurityPolicyService) ExportPolicies(ctx context.Context, orgID uuid.UUID) (*domain.SecurityPolicyExport, error) {
+	policies, err := s.policyRepo.GetByOrganization(orgID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch policies: %w", err)
+	}
+
+	export := &domain.SecurityPolicyExport{
+		Version:    domain.SecurityPolicyExportVersion,
+		ExportedAt: time.Now().UTC(),
+		Policies:   make([]domain.PortableSecurityPolicy, 0, len(policies)),
+	}
+	for _, policy := range policies {
+		portable, err := domain.NewPortableSecurityPolicy(policy)
+		if err != nil {
+			return nil, err
+		}
+		export.Policies = append(export.Policies, portable)
+	}
+	return export, nil
+}
+
+// ImportPolicies upserts the document's policies into the organization, matching existing
+// policies by name. In replace mode, policies not named in the document are deleted.
+// The whole document is validated before anything is written.
+func (s *SecurityPolicyService) ImportPolicies(
+	ctx context.Context,
+	orgID uuid.UUID,
+	userID uuid.UUID,
+	document *domain.SecurityPolicyExport,
+	mode domain.PolicyImportMode,
+) (*domain.SecurityPolicyImportResult, error) {
+	if !mode.IsValid() {
+		return nil, fmt.Errorf("%w: mode must be %q or %q", domain.ErrInvalidPolicyImport, domain.PolicyImportMerge, domain.PolicyImportReplace)
+	}
+	if err := document.Validate(); err != nil {
+		return nil, err
+	}
+
+	existing, err := s.policyRepo.GetByOrganization(orgID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch policies: %w", err)
+	}
+	existingByName := make(map[string]*domain.SecurityPolicy, len(existing))
+	for _, policy := range existing {
+		existingByName[policy.Name] = policy
+	}
+
+	result := &domain.SecurityPolicyImportResult{
+		Mode:    mode,
+		Created: []string{},
+		Updated: []string{},
+		Deleted: []string{},
+	}
+	var created, updated []*domain.SecurityPolicy
+	var deleted []uuid.UUID
+	imported := make(map[string]bool, len(document.Policies))
+	for _, portable := range document.Policies {
+		imported[portable.Name] = true
+
+		if policy, ok := existingByName[portable.Name]; ok {
+			portable.ApplyTo(policy)
+			policy.UpdatedAt = time.Now()
+			updated = append(updated, policy)
+			result.Updated = append(result.Updated, portable.Name)
+			continue
+		}
+
+		policy := &domain.SecurityPolicy{
+			OrganizationID: orgID,
+			CreatedBy:      userID,
+		}
+		portable.ApplyTo(policy)
+		created = append(created, policy)
+		result.Created = append(result.Created, portable.Name)
+	}
+
+	if mode == domain.PolicyImportReplace {
+		for _, policy := range existing {
+			if imported[policy.Name] {
+				continue
+			}
+			deleted = append(deleted, policy.ID)
+			result.Deleted = append(result.Deleted, policy.Name)
+		}
+	}
+
+	// All or nothing: a failure part-way must not leave the organization with half an import
+	if err := s.policyRepo.ApplyChanges(created, updated, deleted); err != nil {
+		return nil, fmt.Errorf("failed to import policies: %w", err)
+	}
+
+	return result, nil
+}
+
 // EvaluateTrustScoreLow evaluates security policies for low trust score agents
 // Returns enforcement decision and whether to create an alert
 func (s *SecurityPolicyService) EvaluateTrustScoreLow(
@@ -240,7 +456,7 @@ func (s *SecurityPolicyService) EvaluateTrustScoreLow(
 	auditID uuid.UUID,
 ) (shouldBlock bool, shouldAlert bool, policyName string, err error) {
 	// Get active trust_score_low policies for this organization
-	policies, err := s.policyRepo.GetByType(agent.OrganizationID, domain.PolicyTypeTrustScoreLow)
+	policies, err := s.getPoliciesByType(agent.OrganizationID, domain.PolicyTypeTrustScoreLow)
 	if err != nil {
 		return false, false, "", fmt.Errorf("failed to fetch trust score policies: %w", err)
 	}
@@ -279,6 +495,8 @@ func (s *SecurityPolicyService) EvaluateTrustScoreLow(
 				return false, true, policy.Name, nil
 			case domain.EnforcementAllow:
 				return false, false, policy.Name, nil
+			case domain.EnforcementSimulate:
+				s.recordSimulatedBlock(ctx, policy, agent, actionType, resource, auditID)
 			}
 		}
 	}
@@ -287,6 +505,70 @@ func (s *SecurityPolicyService) EvaluateTrustScoreLow(
 	return false, false, "", nil
 }
 
+// TrustScoreDropThresholds controls when a trust score drop raises an alert
+type TrustScoreDropThresholds struct {
+	SignificantDrop float64 // Relative drop that triggers a warning
+	CriticalDrop    float64 // Relative drop that triggers a critical alert
+	LowScore        float64 // Any drop ending below this score is critical
+}
+
+// DefaultTrustScoreDropThresholds are used when no trust_score_drop policy applies
+var DefaultTrustScoreDropThresholds = TrustScoreDropThresholds{
+	SignificantDrop: 0.1, // 10% drop triggers warning
+	CriticalDrop:    0.2, // 20% drop triggers critical
+	LowScore:        0.5, // 50% trust score threshold
+}
+
+// DropSeverity classifies a change from previous to current: critical when the score fell by at least
+// CriticalDrop of its previous value or ended below LowScore, warning when it fell by at least
+// SignificantDrop. ok is false when the change is not a significant drop.
+func (t TrustScoreDropThresholds) DropSeverity(previous, current float64) (severity domain.AlertSeverity, ok bool) {
+	drop := previous - current
+	if previous <= 0 || drop <= 0 {
+		return "", false // No meaningful comparison, or the score did not fall
+	}
+
+	dropPercentage := drop / previous
+	switch {
+	case dropPercentage >= t.CriticalDrop || current < t.LowScore:
+		return domain.AlertSeverityCritical, true
+	case dropPercentage >= t.SignificantDrop:
+		return domain.AlertSeverityWarning, true
+	}
+	return "", false
+}
+
+// GetTrustScoreDropThresholds returns the drop thresholds from the highest priority trust_score_drop
+// policy that applies to the agent. Rules that are missing or out of range keep their defaults.
+func (s *SecurityPolicyService) GetTrustScoreDropThresholds(ctx context.Context, agent *domain.Agent) TrustScoreDropThresholds {
+	thresholds := DefaultTrustScoreDropThresholds
+
+	policies, err := s.getPoliciesByType(agent.OrganizationID, domain.PolicyTypeTrustScoreDrop)
+	if err != nil {
+		fmt.Printf("⚠️  Warning: failed to fetch trust score drop policies for org %s: %v\n", agent.OrganizationID, err)
+		return thresholds
+	}
+
+	for _, policy := range policies {
+		if !policy.IsEnabled || !s.policyAppliesToAgent(policy, agent) {
+			continue
+		}
+
+		if v, ok := policy.Rules["significant_drop_threshold"].(float64); ok && v > 0 && v <= 1 {
+			thresholds.SignificantDrop = v
+		}
+		if v, ok := policy.Rules["critical_drop_threshold"].(float64); ok && v > 0 && v <= 1 {
+			thresholds.CriticalDrop = v
+		}
+		if v, ok := policy.Rules["low_score_threshold"].(float64); ok && v >= 0 && v <= 1 {
+			thresholds.LowScore = v
+		}
+		break
+	}
+
+	return thresholds
+}
+
 // EvaluateUnusualActivity evaluates security policies for unusual activity patterns
 // Returns enforcement decision and whether to create an alert
 func (s *SecurityPolicyService) EvaluateUnusualActivity(
@@ -297,7 +579,7 @@ func (s *SecurityPolicyService) EvaluateUnusualActivity(
 	auditID uuid.UUID,
 ) (shouldBlock bool, shouldAlert bool, policyName string, err error) {
 	// Get active unusual_activity policies for this organization
-	policies, err := s.policyRepo.GetByType(agent.OrganizationID, domain.PolicyTypeUnusualActivity)
+	policies, err := s.getPoliciesByType(agent.OrganizationID, domain.PolicyTypeUnusualActivity)
 	if err != nil {
 		return false, false, "", fmt.Errorf("failed to fetch unusual activity policies: %w", err)
 	}
@@ -318,6 +600,28 @@ func (s *SecurityPolicyService) EvaluateUnusualActivity(
 			continue
 		}
 
+		// Check the verification rate against the policy's limit and the agent's own baseline
+		if rateLimit, ok := policy.Rules["rate_limit_threshold"].(float64); ok && s.verificationEventRepo != nil {
+			spike, err := s.detectVerificationRateSpike(agent, policy, rateLimit, time.Now())
+			if err != nil {
+				fmt.Printf("⚠️  Failed to count verifications for agent %s: %v\n", agent.Name, err)
+			} else if spike.Unusual {
+				fmt.Printf("✅ Unusual Activity Policy '%s' triggered: Verification rate spike detected (count: %d, limit: %.0f, baseline threshold: %.1f)\n",
+					policy.Name, spike.CurrentCount, rateLimit, spike.Threshold)
+
+				switch policy.EnforcementAction {
+				case domain.EnforcementBlockAndAlert:
+					return true, true, policy.Name, nil
+				case domain.EnforcementAlertOnly:
+					return false, true, policy.Name, nil
+				case domain.EnforcementAllow:
+					return false, false, policy.Name, nil
+				case domain.EnforcementSimulate:
+					s.recordSimulatedBlock(ctx, policy, agent, actionType, resource, auditID)
+				}
+			}
+		}
+
 		// Check for API rate spikes
 		if apiRateThreshold, ok := policy.Rules["api_rate_threshold"].(float64); ok {
 			timeWindowMinutes, _ := policy.Rules["time_window_minutes"].(float64)
@@ -347,6 +651,8 @@ func (s *SecurityPolicyService) EvaluateUnusualActivity(
 					return false, true, policy.Name, nil
 				case domain.EnforcementAllow:
 					return false, false, policy.Name, nil
+				case domain.EnforcementSimulate:
+					s.recordSimulatedBlock(ctx, policy, agent, actionType, resource, auditID)
 				}
 			}
 		}
@@ -376,6 +682,8 @@ func (s *SecurityPolicyService) EvaluateUnusualActivity(
 					return false, true, policy.Name, nil
 				case domain.EnforcementAllow:
 					return false, false, policy.Name, nil
+				case domain.EnforcementSimulate:
+					s.recordSimulatedBlock(ctx, policy, agent, actionType, resource, auditID)
 				}
 			}
 		}
@@ -412,6 +720,8 @@ func (s *SecurityPolicyService) EvaluateUnusualActivity(
 					return false, true, policy.Name, nil
 				case domain.EnforcementAllow:
 					return false, false, policy.Name, nil
+				case domain.EnforcementSimulate:
+					s.recordSimulatedBlock(ctx, policy, agent, actionType, resource, auditID)
 				}
 			}
 		}
@@ -420,26 +730,130 @@ func (s *SecurityPolicyService) EvaluateUnusualActivity(
 	return false, false, "", nil
 }
 
+// Defaults for verification rate spike detection in unusual_activity policies
+const (
+	DefaultRateSpikeWindowMinutes    = 60 // Size of each sliding window
+	DefaultRateSpikeBaselineWindow   = 24 // Number of past windows that form the baseline
+	DefaultRateSpikeMinEvents        = 10 // Current windows below this count never count as a baseline spike
+	DefaultRateSpikeStdDevMultiplier = 3  // N in mean + N*stddev
+)
+
+// RateSpikeResult describes the current verification rate compared to the agent's baseline
+type RateSpikeResult struct {
+	CurrentCount int
+	Mean         float64
+	StdDev       float64
+	Threshold    float64 // mean + N*stddev
+	Unusual      bool
+}
+
+// detectVerificationRateSpike counts the agent's verifications per window and compares the latest
+// window against rateLimit and, when detect_anomalies is set, the baseline windows before it.
+// Window sizes come from the policy rules.
+func (s *SecurityPolicyService) detectVerificationRateSpike(
+	agent *domain.Agent,
+	policy *domain.SecurityPolicy,
+	rateLimit float64,
+	now time.Time,
+) (RateSpikeResult, error) {
+	windowMinutes, _ := policy.Rules["time_window_minutes"].(float64)
+	if windowMinutes <= 0 {
+		windowMinutes = DefaultRateSpikeWindowMinutes
+	}
+	baselineWindows, _ := policy.Rules["baseline_windows"].(float64)
+	if baselineWindows < 1 {
+		baselineWindows = DefaultRateSpikeBaselineWindow
+	}
+	minEvents, _ := policy.Rules["min_spike_events"].(float64)
+	if minEvents <= 0 {
+		minEvents = DefaultRateSpikeMinEvents
+	}
+	stddevMultiplier, _ := policy.Rules["stddev_multiplier"].(float64)
+	if stddevMultiplier <= 0 {
+		stddevMultiplier = DefaultRateSpikeStdDevMultiplier
+	}
+	detectAnomalies, _ := policy.Rules["detect_anomalies"].(bool)
+
+	window := time.Duration(windowMinutes) * time.Minute
+	counts, err := s.verificationEventRepo.CountAgentEventsByWindow(agent.ID, now, window, int(baselineWindows)+1)
+	if err != nil {
+		return RateSpikeResult{}, err
+	}
+
+	result := DetectRateSpike(counts, stddevMultiplier, int(minEvents))
+	if !detectAnomalies {
+		result.Unusual = false
+	}
+	if float64(result.CurrentCount) > rateLimit {
+		result.Unusual = true
+	}
+	return result, nil
+}
+
+// DetectRateSpike compares per-window event counts, where counts[0] is the current window and the
+// rest form the baseline. The current rate is unusual when it exceeds mean + stddevMultiplier*stddev
+// of the baseline and reaches minEvents.
+func DetectRateSpike(counts []int, stddevMultiplier float64, minEvents int) RateSpikeResult {
+	if len(counts) == 0 {
+		return RateSpikeResult{}
+	}
+	current := counts[0]
+	baseline := counts[1:]
+
+	var mean, stdDev float64
+	if len(baseline) > 0 {
+		var sum float64
+		for _, c := range baseline {
+			sum += float64(c)
+		}
+		mean = sum / float64(len(baseline))
+
+		var variance float64
+		for _, c := range baseline {
+			variance += (float64(c) - mean) * (float64(c) - mean)
+		}
+		stdDev = math.Sqrt(variance / float64(len(baseline)))
+	}
+
+	threshold := mean + stddevMultiplier*stdDev
+
+	return RateSpikeResult{
+		CurrentCount: current,
+		Mean:         mean,
+		StdDev:       stdDev,
+		Threshold:    threshold,
+		Unusual:      current >= minEvents && float64(current) > threshold,
+	}
+}
+
+// DefaultBulkReadWindowSeconds is the sliding window for bulk read detection when a policy sets no window
+const DefaultBulkReadWindowSeconds = 60
+
 // EvaluateDataExfiltration evaluates security policies for data exfiltration attempts
-// Returns enforcement decision and whether to create an alert
+// Returns enforcement decision, whether to create an alert and a description of what was detected
 func (s *SecurityPolicyService) EvaluateDataExfiltration(
 	ctx context.Context,
 	agent *domain.Agent,
 	actionType string,
 	resource string,
 	auditID uuid.UUID,
-) (shouldBlock bool, shouldAlert bool, policyName string, err error) {
+) (shouldBlock bool, shouldAlert bool, policyName string, details string, err error) {
 	// Get active data_exfiltration policies for this organization
-	policies, err := s.policyRepo.GetByType(agent.OrganizationID, domain.PolicyTypeDataExfiltration)
+	policies, err := s.getPoliciesByType(agent.OrganizationID, domain.PolicyTypeDataExfiltration)
 	if err != nil {
-		return false, false, "", fmt.Errorf("failed to fetch data exfiltration policies: %w", err)
+		return false, false, "", "", fmt.Errorf("failed to fetch data exfiltration policies: %w", err)
 	}
 
 	// If no policies configured, don't enforce
 	if len(policies) == 0 {
-		return false, false, "", nil
+		return false, false, "", "", nil
 	}
 
+	// Each read is recorded once, in the window of the first bulk read policy that applies
+	isRead := strings.HasPrefix(strings.ToLower(actionType), "read_")
+	readCount := 0
+	var readWindow time.Duration
+
 	// Evaluate policies by priority (highest first)
 	for _, policy := range policies {
 		if !policy.IsEnabled {
@@ -451,6 +865,35 @@ func (s *SecurityPolicyService) EvaluateDataExfiltration(
 			continue
 		}
 
+		// Check for bulk reads of distinct resources (EchoLeak signature)
+		if bulkReadLimit, ok := policy.Rules["bulk_read_limit"].(float64); ok && isRead && s.readTracker != nil {
+			if readWindow == 0 {
+				windowSeconds, _ := policy.Rules["bulk_read_window_seconds"].(float64)
+				if windowSeconds <= 0 {
+					windowSeconds = DefaultBulkReadWindowSeconds
+				}
+				readWindow = time.Duration(windowSeconds * float64(time.Second))
+				readCount = s.readTracker.Record(agent.ID, resource, time.Now(), readWindow)
+			}
+
+			if float64(readCount) > bulkReadLimit {
+				details = fmt.Sprintf("Bulk read pattern detected: %d distinct resources read within %s (limit: %.0f)",
+					readCount, readWindow, bulkReadLimit)
+				fmt.Printf("✅ Data Exfiltration Policy '%s' triggered for agent %s: %s\n", policy.Name, agent.Name, details)
+
+				switch policy.EnforcementAction {
+				case domain.EnforcementBlockAndAlert:
+					return true, true, policy.Name, details, nil
+				case domain.EnforcementAlertOnly:
+					return false, true, policy.Name, details, nil
+				case domain.EnforcementAllow:
+					return false, false, policy.Name, details, nil
+				case domain.EnforcementSimulate:
+					s.recordSimulatedBlock(ctx, policy, agent, actionType, resource, auditID)
+				}
+			}
+		}
+
 		// Check for data exfiltration patterns in action
 		patterns, ok := policy.Rules["patterns"].([]interface{})
 		if ok {
@@ -468,40 +911,55 @@ func (s *SecurityPolicyService) EvaluateDataExfiltration(
 
 					switch policy.EnforcementAction {
 					case domain.EnforcementBlockAndAlert:
-						return true, true, policy.Name, nil
+						return true, true, policy.Name, "", nil
 					case domain.EnforcementAlertOnly:
-						return false, true, policy.Name, nil
+						return false, true, policy.Name, "", nil
 					case domain.EnforcementAllow:
-						return false, false, policy.Name, nil
+						return false, false, policy.Name, "", nil
+					case domain.EnforcementSimulate:
+						s.recordSimulatedBlock(ctx, policy, agent, actionType, resource, auditID)
 					}
 				}
 			}
 		}
 	}
 
-	return false, false, "", nil
+	return false, false, "", "", nil
+}
+
+// CaptureAgentBaseline snapshots the agent's capabilities, talks_to and key algorithm
+// as the reference for config drift policies. Called whenever an agent is verified.
+func (s *SecurityPolicyService) CaptureAgentBaseline(ctx context.Context, agent *domain.Agent) error {
+	if s.baselineRepo == nil {
+		return nil
+	}
+	return s.baselineRepo.Upsert(domain.NewAgentBaseline(agent, time.Now()))
 }
 
 // EvaluateConfigDrift evaluates security policies for configuration drift
-// Returns enforcement decision and whether to create an alert
+// Returns enforcement decision, whether to create an alert and a description of the drift
 func (s *SecurityPolicyService) EvaluateConfigDrift(
 	ctx context.Context,
 	agent *domain.Agent,
 	actionType string,
 	resource string,
 	auditID uuid.UUID,
-) (shouldBlock bool, shouldAlert bool, policyName string, err error) {
+) (shouldBlock bool, shouldAlert bool, policyName string, details string, err error) {
 	// Get active config_drift policies for this organization
-	policies, err := s.policyRepo.GetByType(agent.OrganizationID, domain.PolicyTypeConfigDrift)
+	policies, err := s.getPoliciesByType(agent.OrganizationID, domain.PolicyTypeConfigDrift)
 	if err != nil {
-		return false, false, "", fmt.Errorf("failed to fetch config drift policies: %w", err)
+		return false, false, "", "", fmt.Errorf("failed to fetch config drift policies: %w", err)
 	}
 
 	// If no policies configured, don't enforce
 	if len(policies) == 0 {
-		return false, false, "", nil
+		return false, false, "", "", nil
 	}
 
+	// Baseline is loaded once, on first use
+	var baselineDrift *domain.AgentConfigDrift
+	baselineLoaded := false
+
 	// Evaluate policies by priority (highest first)
 	for _, policy := range policies {
 		if !policy.IsEnabled {
@@ -513,6 +971,37 @@ func (s *SecurityPolicyService) EvaluateConfigDrift(
 			continue
 		}
 
+		// Check for drift from the baseline captured at verification time
+		if checkBaselineDrift, ok := policy.Rules["check_baseline_drift"].(bool); ok && checkBaselineDrift && s.baselineRepo != nil {
+			if !baselineLoaded {
+				baselineLoaded = true
+				baseline, err := s.baselineRepo.GetByAgentID(agent.ID)
+				if err != nil {
+					fmt.Printf("⚠️  Failed to get baseline for agent %s: %v\n", agent.Name, err)
+				} else if baseline != nil {
+					baselineDrift = baseline.Diff(agent)
+				}
+			}
+
+			// drift_threshold is the number of changes tolerated before the policy triggers
+			driftThreshold, _ := policy.Rules["drift_threshold"].(float64)
+			if baselineDrift != nil && float64(baselineDrift.Count()) > driftThreshold {
+				details = fmt.Sprintf("Configuration drifted from baseline (%s)", baselineDrift.String())
+				fmt.Printf("✅ Config Drift Policy '%s' triggered: %s\n", policy.Name, details)
+
+				switch policy.EnforcementAction {
+				case domain.EnforcementBlockAndAlert:
+					return true, true, policy.Name, details, nil
+				case domain.EnforcementAlertOnly:
+					return false, true, policy.Name, details, nil
+				case domain.EnforcementAllow:
+					return false, false, policy.Name, details, nil
+				case domain.EnforcementSimulate:
+					s.recordSimulatedBlock(ctx, policy, agent, actionType, resource, auditID)
+				}
+			}
+		}
+
 		// Check for capability changes (compare current vs. baseline)
 		if checkCapabilityChanges, ok := policy.Rules["check_capability_changes"].(bool); ok && checkCapabilityChanges {
 			// Baseline capabilities are stored in policy rules
@@ -554,11 +1043,13 @@ func (s *SecurityPolicyService) EvaluateConfigDrift(
 
 					switch policy.EnforcementAction {
 					case domain.EnforcementBlockAndAlert:
-						return true, true, policy.Name, nil
+						return true, true, policy.Name, "", nil
 					case domain.EnforcementAlertOnly:
-						return false, true, policy.Name, nil
+						return false, true, policy.Name, "", nil
 					case domain.EnforcementAllow:
-						return false, false, policy.Name, nil
+						return false, false, policy.Name, "", nil
+					case domain.EnforcementSimulate:
+						s.recordSimulatedBlock(ctx, policy, agent, actionType, resource, auditID)
 					}
 				}
 			}
@@ -588,11 +1079,13 @@ func (s *SecurityPolicyService) EvaluateConfigDrift(
 
 								switch policy.EnforcementAction {
 								case domain.EnforcementBlockAndAlert:
-									return true, true, policy.Name, nil
+									return true, true, policy.Name, "", nil
 								case domain.EnforcementAlertOnly:
-									return false, true, policy.Name, nil
+									return false, true, policy.Name, "", nil
 								case domain.EnforcementAllow:
-									return false, false, policy.Name, nil
+									return false, false, policy.Name, "", nil
+								case domain.EnforcementSimulate:
+									s.recordSimulatedBlock(ctx, policy, agent, actionType, resource, auditID)
 								}
 							}
 						}
@@ -641,17 +1134,93 @@ func (s *SecurityPolicyService) EvaluateConfigDrift(
 
 				switch policy.EnforcementAction {
 				case domain.EnforcementBlockAndAlert:
-					return true, true, policy.Name, nil
+					return true, true, policy.Name, "", nil
 				case domain.EnforcementAlertOnly:
-					return false, true, policy.Name, nil
+					return false, true, policy.Name, "", nil
 				case domain.EnforcementAllow:
-					return false, false, policy.Name, nil
+					return false, false, policy.Name, "", nil
+				case domain.EnforcementSimulate:
+					s.recordSimulatedBlock(ctx, policy, agent, actionType, resource, auditID)
 				}
 			}
 		}
 	}
 
-	return false, false, "", nil
+	return false, false, "", "", nil
+}
+
+// mcpServerMetadataKeys are the verification metadata keys that name the MCP server an action targets
+var mcpServerMetadataKeys = []string{"mcp_server", "mcp_server_id", "mcp_server_name"}
+
+// MCPServerFromMetadata returns the MCP server named in verification metadata. Metadata
+// declaring a protocol other than "mcp" names no server.
+func MCPServerFromMetadata(metadata map[string]interface{}) (string, bool) {
+	if protocol, ok := metadata["protocol"].(string); ok && protocol != "" && !strings.EqualFold(protocol, "mcp") {
+		return "", false
+	}
+	for _, key := range mcpServerMetadataKeys {
+		if server, ok := metadata[key].(string); ok && strings.TrimSpace(server) != "" {
+			return strings.TrimSpace(server), true
+		}
+	}
+	return "", false
+}
+
+// EvaluateMCPAllowlist evaluates security policies for MCP actions against servers the agent has
+// not declared in talks_to. Servers are matched by name or ID, as listed in talks_to.
+// Returns enforcement decision, whether to create an alert and a description of the violation
+func (s *SecurityPolicyService) EvaluateMCPAllowlist(
+	ctx context.Context,
+	agent *domain.Agent,
+	actionType string,
+	metadata map[string]interface{},
+	auditID uuid.UUID,
+) (shouldBlock bool, shouldAlert bool, policyName string, details string, err error) {
+	server, ok := MCPServerFromMetadata(metadata)
+	if !ok {
+		return false, false, "", "", nil
+	}
+
+	for _, allowed := range agent.TalksTo {
+		if strings.EqualFold(allowed, server) {
+			return false, false, "", "", nil
+		}
+	}
+
+	// Get active mcp_allowlist policies for this organization
+	policies, err := s.getPoliciesByType(agent.OrganizationID, domain.PolicyTypeMCPAllowlist)
+	if err != nil {
+		return false, false, "", "", fmt.Errorf("failed to fetch MCP allowlist policies: %w", err)
+	}
+
+	// Evaluate policies by priority (highest first)
+	for _, policy := range policies {
+		if !policy.IsEnabled {
+			continue
+		}
+
+		// Check if policy applies to this agent
+		if !s.policyAppliesToAgent(policy, agent) {
+			continue
+		}
+
+		details = fmt.Sprintf("MCP action '%s' targets server '%s', which is not in the agent's talks_to list", actionType, server)
+		fmt.Printf("✅ MCP Allowlist Policy '%s' triggered: %s\n", policy.Name, details)
+
+		switch policy.EnforcementAction {
+		case domain.EnforcementBlockAndAlert:
+			return true, true, policy.Name, details, nil
+		case domain.EnforcementAlertOnly:
+			return false, true, policy.Name, details, nil
+		case domain.EnforcementAllow:
+			return false, false, policy.Name, details, nil
+		case domain.EnforcementSimulate:
+			s.recordSimulatedBlock(ctx, policy, agent, actionType, server, auditID)
+		}
+	}
+
+	// If no policies configured, don't enforce
+	return false, false, "", "", nil
 }
 
 // EvaluateUnauthorizedAccess evaluates security policies for unauthorized access attempts
@@ -664,7 +1233,7 @@ func (s *SecurityPolicyService) EvaluateUnauthorizedAccess(
 	auditID uuid.UUID,
 ) (shouldBlock bool, shouldAlert bool, policyName string, err error) {
 	// Get active unauthorized_access policies for this organization
-	policies, err := s.policyRepo.GetByType(agent.OrganizationID, domain.PolicyTypeUnauthorizedAccess)
+	policies, err := s.getPoliciesByType(agent.OrganizationID, domain.PolicyTypeUnauthorizedAccess)
 	if err != nil {
 		return false, false, "", fmt.Errorf("failed to fetch unauthorized access policies: %w", err)
 	}
@@ -736,6 +1305,8 @@ func (s *SecurityPolicyService) EvaluateUnauthorizedAccess(
 						return false, true, policy.Name, nil
 					case domain.EnforcementAllow:
 						return false, false, policy.Name, nil
+					case domain.EnforcementSimulate:
+						s.recordSimulatedBlock(ctx, policy, agent, actionType, resource, auditID)
 					}
 				}
 			}
@@ -772,6 +1343,8 @@ func (s *SecurityPolicyService) EvaluateUnauthorizedAccess(
 						return false, true, policy.Name, nil
 					case domain.EnforcementAllow:
 						return false, false, policy.Name, nil
+					case domain.EnforcementSimulate:
+						s.recordSimulatedBlock(ctx, policy, agent, actionType, resource, auditID)
 					}
 				}
 			}
@@ -793,6 +1366,8 @@ func (s *SecurityPolicyService) EvaluateUnauthorizedAccess(
 						return false, true, policy.Name, nil
 					case domain.EnforcementAllow:
 						return false, false, policy.Name, nil
+					case domain.EnforcementSimulate:
+						s.recordSimulatedBlock(ctx, policy, agent, actionType, resource, auditID)
 					}
 				}
 			}
@@ -818,6 +1393,8 @@ func (s *SecurityPolicyService) EvaluateUnauthorizedAccess(
 								return false, true, policy.Name, nil
 							case domain.EnforcementAllow:
 								return false, false, policy.Name, nil
+							case domain.EnforcementSimulate:
+								s.recordSimulatedBlock(ctx, policy, agent, actionType, resource, auditID)
 							}
 						}
 					}
@@ -843,6 +1420,8 @@ func (s *SecurityPolicyService) EvaluateUnauthorizedAccess(
 								return false, true, policy.Name, nil
 							case domain.EnforcementAllow:
 								return false, false, policy.Name, nil
+							case domain.EnforcementSimulate:
+								s.recordSimulatedBlock(ctx, policy, agent, actionType, resource, auditID)
 							}
 						}
 					}
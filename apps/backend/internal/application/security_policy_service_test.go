@@ -0,0 +1,734 @@
+package application
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// quietThenBurstCounts returns 2 events per hour for the previous 24 hours followed by
+// burst events in the current hour
+func quietThenBurstCounts(burst int) []int {
+	counts := make([]int, 25)
+	counts[0] = burst
+	for i := 1; i < len(counts); i++ {
+		counts[i] = 2
+	}
+	return counts
+}
+
+func TestDetectRateSpike(t *testing.T) {
+	quiet := DetectRateSpike(quietThenBurstCounts(2), 3, 10)
+	assert.Equal(t, 2, quiet.CurrentCount)
+	assert.Equal(t, 2.0, quiet.Mean)
+	assert.False(t, quiet.Unusual)
+
+	burst := DetectRateSpike(quietThenBurstCounts(40), 3, 10)
+	assert.Equal(t, 40, burst.CurrentCount)
+	assert.True(t, burst.Unusual)
+
+	// A burst below the minimum event floor is not flagged even with an empty baseline
+	small := DetectRateSpike(make([]int, 25), 3, 10)
+	assert.False(t, small.Unusual)
+}
+
+func TestSecurityPolicyService_EvaluateUnusualActivity_VerificationRateSpike(t *testing.T) {
+	tests := []struct {
+		name        string
+		rules       map[string]interface{}
+		burst       int
+		action      domain.EnforcementAction
+		wantBlocked bool
+		wantAlert   bool
+	}{
+		{"quiet history is allowed", map[string]interface{}{"rate_limit_threshold": 100.0, "detect_anomalies": true}, 2, domain.EnforcementBlockAndAlert, false, false},
+		{"burst is blocked", map[string]interface{}{"rate_limit_threshold": 100.0, "detect_anomalies": true}, 40, domain.EnforcementBlockAndAlert, true, true},
+		{"burst alerts only", map[string]interface{}{"rate_limit_threshold": 100.0, "detect_anomalies": true}, 40, domain.EnforcementAlertOnly, false, true},
+		{"burst without anomaly detection is allowed", map[string]interface{}{"rate_limit_threshold": 100.0}, 40, domain.EnforcementBlockAndAlert, false, false},
+		{"count at the limit is allowed", map[string]interface{}{"rate_limit_threshold": 100.0}, 100, domain.EnforcementBlockAndAlert, false, false},
+		{"count over the limit is blocked", map[string]interface{}{"rate_limit_threshold": 100.0}, 101, domain.EnforcementBlockAndAlert, true, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockPolicyRepo := new(AgentServiceMockSecurityPolicyRepository)
+			mockEventRepo := new(MockVerificationEventRepository)
+			service := NewSecurityPolicyService(mockPolicyRepo, nil, nil, mockEventRepo, nil, nil)
+
+			agent := &domain.Agent{ID: uuid.New(), OrganizationID: uuid.New(), Name: "burst-agent"}
+			policy := &domain.SecurityPolicy{
+				Name:              "Verification Rate Spikes",
+				PolicyType:        domain.PolicyTypeUnusualActivity,
+				EnforcementAction: tt.action,
+				Rules:             tt.rules,
+				AppliesTo:         "all",
+				IsEnabled:         true,
+			}
+
+			mockPolicyRepo.On("GetByType", agent.OrganizationID, domain.PolicyTypeUnusualActivity).
+				Return([]*domain.SecurityPolicy{policy}, nil)
+			mockEventRepo.On("CountAgentEventsByWindow", agent.ID, mock.AnythingOfType("time.Time"), time.Hour, 25).
+				Return(quietThenBurstCounts(tt.burst), nil)
+
+			blocked, alert, policyName, err := service.EvaluateUnusualActivity(
+				context.Background(), agent, "verify", "agent", uuid.New(),
+			)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantBlocked, blocked)
+			assert.Equal(t, tt.wantAlert, alert)
+			if tt.wantAlert {
+				assert.Equal(t, policy.Name, policyName)
+			}
+			mockEventRepo.AssertExpectations(t)
+		})
+	}
+}
+
+// MockAgentBaselineRepository for testing
+type MockAgentBaselineRepository struct {
+	mock.Mock
+}
+
+func (m *MockAgentBaselineRepository) Upsert(baseline *domain.AgentBaseline) error {
+	args := m.Called(baseline)
+	return args.Error(0)
+}
+
+func (m *MockAgentBaselineRepository) GetByAgentID(agentID uuid.UUID) (*domain.AgentBaseline, error) {
+	args := m.Called(agentID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.AgentBaseline), args.Error(1)
+}
+
+func TestSecurityPolicyService_EvaluateConfigDrift_UnexpectedMCPConnection(t *testing.T) {
+	tests := []struct {
+		name        string
+		talksTo     []string
+		threshold   float64
+		wantBlocked bool
+	}{
+		{"matches baseline", []string{"mcp-server-1"}, 0, false},
+		{"unexpected MCP connection", []string{"mcp-server-1", "exfil-mcp"}, 0, true},
+		{"drift within threshold", []string{"mcp-server-1", "exfil-mcp"}, 1, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockPolicyRepo := new(AgentServiceMockSecurityPolicyRepository)
+			mockBaselineRepo := new(MockAgentBaselineRepository)
+			service := NewSecurityPolicyService(mockPolicyRepo, nil, nil, nil, mockBaselineRepo, nil)
+
+			agent := createTestAgentForService()
+			baseline := domain.NewAgentBaseline(agent, time.Now().Add(-time.Hour))
+			agent.TalksTo = tt.talksTo
+
+			mockPolicyRepo.On("GetByType", agent.OrganizationID, domain.PolicyTypeConfigDrift).
+				Return([]*domain.SecurityPolicy{{
+					Name:              "Baseline Drift",
+					PolicyType:        domain.PolicyTypeConfigDrift,
+					EnforcementAction: domain.EnforcementBlockAndAlert,
+					Rules:             map[string]interface{}{"check_baseline_drift": true, "drift_threshold": tt.threshold},
+					AppliesTo:         "all",
+					IsEnabled:         true,
+				}}, nil)
+			mockBaselineRepo.On("GetByAgentID", agent.ID).Return(baseline, nil)
+
+			blocked, alert, _, details, err := service.EvaluateConfigDrift(
+				context.Background(), agent, "read_file", "/data", uuid.New(),
+			)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantBlocked, blocked)
+			assert.Equal(t, tt.wantBlocked, alert)
+			if tt.wantBlocked {
+				assert.Contains(t, details, "MCP connections added: exfil-mcp")
+			}
+		})
+	}
+}
+
+func TestSecurityPolicyService_EvaluateDataExfiltration_BulkReads(t *testing.T) {
+	mockPolicyRepo := new(AgentServiceMockSecurityPolicyRepository)
+	service := NewSecurityPolicyService(mockPolicyRepo, nil, nil, nil, nil, nil)
+
+	agent := createTestAgentForService()
+	mockPolicyRepo.On("GetByType", agent.OrganizationID, domain.PolicyTypeDataExfiltration).
+		Return([]*domain.SecurityPolicy{{
+			Name:              "Block Bulk Reads",
+			PolicyType:        domain.PolicyTypeDataExfiltration,
+			EnforcementAction: domain.EnforcementBlockAndAlert,
+			Rules:             map[string]interface{}{"bulk_read_limit": 40.0, "bulk_read_window_seconds": 10.0},
+			AppliesTo:         "all",
+			IsEnabled:         true,
+		}}, nil)
+
+	// 50 reads of distinct resources within the 10 second window
+	var tripped int
+	var details string
+	for i := 1; i <= 50; i++ {
+		blocked, alert, _, d, err := service.EvaluateDataExfiltration(
+			context.Background(), agent, "read_file", fmt.Sprintf("/data/customer-%d.csv", i), uuid.New(),
+		)
+		assert.NoError(t, err)
+		assert.Equal(t, blocked, alert)
+		if blocked && tripped == 0 {
+			tripped = i
+			details = d
+		}
+	}
+
+	assert.Equal(t, 41, tripped)
+	assert.Contains(t, details, "41 distinct resources read within 10s")
+
+	// Non-read actions are not counted
+	blocked, _, _, _, err := service.EvaluateDataExfiltration(context.Background(), agent, "write_file", "/data/out.csv", uuid.New())
+	assert.NoError(t, err)
+	assert.False(t, blocked)
+}
+
+func TestMCPServerFromMetadata(t *testing.T) {
+	server, ok := MCPServerFromMetadata(map[string]interface{}{"protocol": "mcp", "mcp_server": "filesystem-mcp"})
+	assert.True(t, ok)
+	assert.Equal(t, "filesystem-mcp", server)
+
+	server, ok = MCPServerFromMetadata(map[string]interface{}{"mcp_server_id": "7d1f0e4a-0000-4000-8000-000000000000"})
+	assert.True(t, ok)
+	assert.Equal(t, "7d1f0e4a-0000-4000-8000-000000000000", server)
+
+	_, ok = MCPServerFromMetadata(map[string]interface{}{"protocol": "a2a", "mcp_server": "filesystem-mcp"})
+	assert.False(t, ok)
+
+	_, ok = MCPServerFromMetadata(nil)
+	assert.False(t, ok)
+}
+
+func TestSecurityPolicyService_PolicyAppliesToAgent(t *testing.T) {
+	service := NewSecurityPolicyService(nil, nil, nil, nil, nil, nil)
+	prodAgent := createTestAgentForService()
+	prodAgent.Labels = map[string]string{"env": "prod"}
+	mcpAgent := createTestAgentForService()
+	mcpAgent.AgentType = domain.AgentTypeMCP
+
+	tests := []struct {
+		appliesTo string
+		agent     *domain.Agent
+		want      bool
+	}{
+		{"all_agents", prodAgent, true},
+		{"label:env=prod", prodAgent, true},
+		{"label:env:prod", prodAgent, true},
+		{"label:env=staging", prodAgent, false},
+		{"label:env=prod", mcpAgent, false},
+		{"label:team=payments", prodAgent, false},
+		{"agent_type:mcp", mcpAgent, true},
+		{"agent_type:mcp_server", mcpAgent, true},
+		{"agent_type:mcp", prodAgent, false},
+		{"agent_type:ai", prodAgent, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.appliesTo, func(t *testing.T) {
+			assert.Equal(t, tt.want, service.policyAppliesToAgent(&domain.SecurityPolicy{AppliesTo: tt.appliesTo}, tt.agent))
+		})
+	}
+}
+
+func TestSecurityPolicyService_EvaluateTrustScoreLow_LabelScopedPolicy(t *testing.T) {
+	mockPolicyRepo := new(AgentServiceMockSecurityPolicyRepository)
+	service := NewSecurityPolicyService(mockPolicyRepo, nil, nil, nil, nil, nil)
+	policy := &domain.SecurityPolicy{
+		Name:              "Prod Trust Floor",
+		PolicyType:        domain.PolicyTypeTrustScoreLow,
+		EnforcementAction: domain.EnforcementBlockAndAlert,
+		Rules:             map[string]interface{}{"trust_threshold": 0.9},
+		AppliesTo:         "label:env=prod",
+		IsEnabled:         true,
+	}
+
+	prodAgent := createTestAgentForService()
+	prodAgent.Labels = map[string]string{"env": "prod"}
+	devAgent := createTestAgentForService()
+	devAgent.OrganizationID = prodAgent.OrganizationID
+	devAgent.Labels = map[string]string{"env": "dev"}
+
+	mockPolicyRepo.On("GetByType", prodAgent.OrganizationID, domain.PolicyTypeTrustScoreLow).
+		Return([]*domain.SecurityPolicy{policy}, nil)
+
+	// Both agents are below the 0.9 floor, but only the prod-labeled one is in scope
+	blocked, alert, policyName, err := service.EvaluateTrustScoreLow(context.Background(), prodAgent, "read_file", "/data", uuid.New())
+	assert.NoError(t, err)
+	assert.True(t, blocked)
+	assert.True(t, alert)
+	assert.Equal(t, policy.Name, policyName)
+
+	blocked, alert, _, err = service.EvaluateTrustScoreLow(context.Background(), devAgent, "read_file", "/data", uuid.New())
+	assert.NoError(t, err)
+	assert.False(t, blocked)
+	assert.False(t, alert)
+}
+
+func TestSecurityPolicyService_EvaluateCapabilityViolation_HighestPriorityWins(t *testing.T) {
+	agent := createTestAgentForService()
+	agent.Labels = map[string]string{"env": "prod"}
+
+	strict := &domain.SecurityPolicy{
+		Name:              "Block Violations",
+		PolicyType:        domain.PolicyTypeCapabilityViolation,
+		EnforcementAction: domain.EnforcementBlockAndAlert,
+		AppliesTo:         "all",
+		IsEnabled:         true,
+		Priority:          10,
+	}
+	lenient := &domain.SecurityPolicy{
+		Name:              "Alert On Violations",
+		PolicyType:        domain.PolicyTypeCapabilityViolation,
+		EnforcementAction: domain.EnforcementAlertOnly,
+		AppliesTo:         "all",
+		IsEnabled:         true,
+		Priority:          100,
+	}
+	outOfScope := &domain.SecurityPolicy{
+		Name:              "Allow Staging",
+		PolicyType:        domain.PolicyTypeCapabilityViolation,
+		EnforcementAction: domain.EnforcementAllow,
+		AppliesTo:         "label:env=staging",
+		IsEnabled:         true,
+		Priority:          1000,
+	}
+
+	tests := []struct {
+		name        string
+		policies    []*domain.SecurityPolicy
+		wantBlock   bool
+		wantAlert   bool
+		wantDecider string
+	}{
+		{"higher priority alert_only overrides block", []*domain.SecurityPolicy{strict, lenient}, false, true, lenient.Name},
+		{"order returned by the repository does not matter", []*domain.SecurityPolicy{lenient, strict}, false, true, lenient.Name},
+		{"highest priority policy out of scope is skipped", []*domain.SecurityPolicy{strict, outOfScope}, true, true, strict.Name},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockPolicyRepo := new(AgentServiceMockSecurityPolicyRepository)
+			mockPolicyRepo.On("GetByType", agent.OrganizationID, domain.PolicyTypeCapabilityViolation).Return(tt.policies, nil)
+			service := NewSecurityPolicyService(mockPolicyRepo, nil, nil, nil, nil, nil)
+
+			blocked, alert, policyName, err := service.EvaluateCapabilityViolation(context.Background(), agent, "delete_file", "/etc/passwd", uuid.New())
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantBlock, blocked)
+			assert.Equal(t, tt.wantAlert, alert)
+			assert.Equal(t, tt.wantDecider, policyName)
+		})
+	}
+}
+
+// MockPolicySimulationRepository is a mock implementation of domain.PolicySimulationRepository
+type MockPolicySimulationRepository struct {
+	mock.Mock
+}
+
+func (m *MockPolicySimulationRepository) Record(simulation *domain.PolicySimulation) (bool, error) {
+	args := m.Called(simulation)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockPolicySimulationRepository) GetReport(report *domain.PolicySimulationReport) error {
+	args := m.Called(report)
+	return args.Error(0)
+}
+
+func TestSecurityPolicyService_SimulatePolicy_RecordsButNeverBlocks(t *testing.T) {
+	agent := createTestAgentForService()
+	auditID := uuid.New()
+	simulated := &domain.SecurityPolicy{
+		ID:                uuid.New(),
+		Name:              "Trial Trust Floor",
+		PolicyType:        domain.PolicyTypeTrustScoreLow,
+		EnforcementAction: domain.EnforcementSimulate,
+		Rules:             map[string]interface{}{"trust_threshold": 0.9},
+		AppliesTo:         "all",
+		IsEnabled:         true,
+	}
+
+	mockPolicyRepo := new(AgentServiceMockSecurityPolicyRepository)
+	mockPolicyRepo.On("GetByType", agent.OrganizationID, domain.PolicyTypeTrustScoreLow).
+		Return([]*domain.SecurityPolicy{simulated}, nil)
+	mockSimulationRepo := new(MockPolicySimulationRepository)
+	mockSimulationRepo.On("Record", mock.MatchedBy(func(s *domain.PolicySimulation) bool {
+		return s.PolicyID == simulated.ID && s.AgentID == agent.ID && s.AuditID == auditID &&
+			s.ActionType == "read_file" && s.Resource == "/data"
+	})).Return(true, nil).Once()
+	service := NewSecurityPolicyService(mockPolicyRepo, nil, nil, nil, nil, mockSimulationRepo)
+
+	// The agent's 0.85 trust score is below the 0.9 threshold, so block_and_alert would block
+	blocked, alert, policyName, err := service.EvaluateTrustScoreLow(context.Background(), agent, "read_file", "/data", auditID)
+	assert.NoError(t, err)
+	assert.False(t, blocked)
+	assert.False(t, alert)
+	assert.Empty(t, policyName)
+	mockSimulationRepo.AssertExpectations(t)
+}
+
+func TestSecurityPolicyService_SimulatePolicy_CapabilityViolationAllowed(t *testing.T) {
+	agent := createTestAgentForService()
+	simulated := &domain.SecurityPolicy{
+		ID:                uuid.New(),
+		Name:              "Trial Block Violations",
+		PolicyType:        domain.PolicyTypeCapabilityViolation,
+		EnforcementAction: domain.EnforcementSimulate,
+		AppliesTo:         "all",
+		IsEnabled:         true,
+		Priority:          100,
+	}
+
+	mockPolicyRepo := new(AgentServiceMockSecurityPolicyRepository)
+	mockPolicyRepo.On("GetByType", agent.OrganizationID, domain.PolicyTypeCapabilityViolation).
+		Return([]*domain.SecurityPolicy{simulated}, nil)
+	mockSimulationRepo := new(MockPolicySimulationRepository)
+	mockSimulationRepo.On("Record", mock.MatchedBy(func(s *domain.PolicySimulation) bool {
+		return s.PolicyID == simulated.ID
+	})).Return(true, nil).Once()
+	service := NewSecurityPolicyService(mockPolicyRepo, nil, nil, nil, nil, mockSimulationRepo)
+
+	// A simulate-only policy set records the would-be block instead of falling back to block + alert
+	blocked, alert, policyName, err := service.EvaluateCapabilityViolation(context.Background(), agent, "delete_file", "/etc/passwd", uuid.New())
+	assert.NoError(t, err)
+	assert.False(t, blocked)
+	assert.False(t, alert)
+	assert.Equal(t, simulated.Name, policyName)
+	mockSimulationRepo.AssertExpectations(t)
+}
+
+func TestSecurityPolicyService_SimulatePolicy_RecordFailureStillAllows(t *testing.T) {
+	agent := createTestAgentForService()
+	simulated := &domain.SecurityPolicy{
+		ID:                uuid.New(),
+		Name:              "Trial Trust Floor",
+		PolicyType:        domain.PolicyTypeTrustScoreLow,
+		EnforcementAction: domain.EnforcementSimulate,
+		Rules:             map[string]interface{}{"trust_threshold": 0.9},
+		AppliesTo:         "all",
+		IsEnabled:         true,
+	}
+
+	mockPolicyRepo := new(AgentServiceMockSecurityPolicyRepository)
+	mockPolicyRepo.On("GetByType", agent.OrganizationID, domain.PolicyTypeTrustScoreLow).
+		Return([]*domain.SecurityPolicy{simulated}, nil)
+	mockSimulationRepo := new(MockPolicySimulationRepository)
+	mockSimulationRepo.On("Record", mock.Anything).Return(false, fmt.Errorf("connection reset"))
+	service := NewSecurityPolicyService(mockPolicyRepo, nil, nil, nil, nil, mockSimulationRepo)
+
+	blocked, alert, _, err := service.EvaluateTrustScoreLow(context.Background(), agent, "read_file", "/data", uuid.New())
+	assert.NoError(t, err)
+	assert.False(t, blocked)
+	assert.False(t, alert)
+	mockSimulationRepo.AssertCalled(t, "Record", mock.Anything)
+}
+
+// memorySecurityPolicyRepository keeps security policies in memory, ordered like the real repository
+type memorySecurityPolicyRepository struct {
+	policies   []*domain.SecurityPolicy
+	applyError error // ApplyChanges fails with it before changing anything
+}
+
+func (r *memorySecurityPolicyRepository) Create(policy *domain.SecurityPolicy) error {
+	if policy.ID == uuid.Nil {
+		policy.ID = uuid.New()
+	}
+	stored := *policy
+	r.policies = append(r.policies, &stored)
+	return nil
+}
+
+func (r *memorySecurityPolicyRepository) GetByID(id uuid.UUID) (*domain.SecurityPolicy, error) {
+	for _, policy := range r.policies {
+		if policy.ID == id {
+			stored := *policy
+			return &stored, nil
+		}
+	}
+	return nil, fmt.Errorf("policy not found")
+}
+
+func (r *memorySecurityPolicyRepository) GetByOrganization(orgID uuid.UUID) ([]*domain.SecurityPolicy, error) {
+	var policies []*domain.SecurityPolicy
+	for _, policy := range r.policies {
+		if policy.OrganizationID == orgID {
+			stored := *policy
+			policies = append(policies, &stored)
+		}
+	}
+	sort.SliceStable(policies, func(i, j int) bool { return policies[i].Priority > policies[j].Priority })
+	return policies, nil
+}
+
+func (r *memorySecurityPolicyRepository) GetActiveByOrganization(orgID uuid.UUID) ([]*domain.SecurityPolicy, error) {
+	return nil, fmt.Errorf("not implemented")
+}
+
+func (r *memorySecurityPolicyRepository) GetByType(orgID uuid.UUID, policyType domain.PolicyType) ([]*domain.SecurityPolicy, error) {
+	return nil, fmt.Errorf("not implemented")
+}
+
+func (r *memorySecurityPolicyRepository) Update(policy *domain.SecurityPolicy) error {
+	for i, stored := range r.policies {
+		if stored.ID == policy.ID {
+			updated := *policy
+			r.policies[i] = &updated
+			return nil
+		}
+	}
+	return fmt.Errorf("policy not found")
+}
+
+func (r *memorySecurityPolicyRepository) Delete(id uuid.UUID) error {
+	for i, policy := range r.policies {
+		if policy.ID == id {
+			r.policies = append(r.policies[:i], r.policies[i+1:]...)
+			return nil
+		}
+	}
+	return fmt.Errorf("policy not found")
+}
+
+func (r *memorySecurityPolicyRepository) ApplyChanges(created, updated []*domain.SecurityPolicy, deleted []uuid.UUID) error {
+	if r.applyError != nil {
+		return r.applyError
+	}
+	for _, policy := range updated {
+		if err := r.Update(policy); err != nil {
+			return err
+		}
+	}
+	for _, policy := range created {
+		if err := r.Create(policy); err != nil {
+			return err
+		}
+	}
+	for _, id := range deleted {
+		if err := r.Delete(id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *memorySecurityPolicyRepository) names(orgID uuid.UUID) []string {
+	policies, _ := r.GetByOrganization(orgID)
+	names := make([]string, 0, len(policies))
+	for _, policy := range policies {
+		names = append(names, policy.Name)
+	}
+	return names
+}
+
+func TestSecurityPolicyService_ExportImport_RoundTrip(t *testing.T) {
+	repo := &memorySecurityPolicyRepository{}
+	service := NewSecurityPolicyService(repo, nil, nil, nil, nil, nil)
+	stagingOrg, prodOrg, userID := uuid.New(), uuid.New(), uuid.New()
+
+	require.NoError(t, service.CreateDefaultPolicies(context.Background(), stagingOrg, userID))
+	require.NoError(t, service.CreatePolicy(context.Background(), &domain.SecurityPolicy{
+		OrganizationID:    stagingOrg,
+		Name:              "Trial Resource Lockdown",
+		PolicyType:        domain.PolicyTypeUnauthorizedAccess,
+		EnforcementAction: domain.EnforcementSimulate,
+		SeverityThreshold: domain.AlertSeverityHigh,
+		Rules: map[string]interface{}{
+			"check_resource_access": true,
+			"restricted_resources":  []interface{}{"/etc", "/var/secrets"},
+		},
+		AppliesTo: "label:env=prod",
+		IsEnabled: true,
+		Priority:  500,
+	}))
+
+	exported, err := service.ExportPolicies(context.Background(), stagingOrg)
+	require.NoError(t, err)
+	require.Len(t, exported.Policies, len(repo.names(stagingOrg)))
+
+	// Ship the document as JSON, the way it moves between environments
+	body, err := json.Marshal(exported)
+	require.NoError(t, err)
+	var document domain.SecurityPolicyExport
+	require.NoError(t, json.Unmarshal(body, &document))
+
+	result, err := service.ImportPolicies(context.Background(), prodOrg, userID, &document, domain.PolicyImportMerge)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, repo.names(stagingOrg), result.Created)
+	assert.Empty(t, result.Updated)
+	assert.Empty(t, result.Deleted)
+
+	reexported, err := service.ExportPolicies(context.Background(), prodOrg)
+	require.NoError(t, err)
+	assert.Equal(t, exported.Policies, reexported.Policies)
+
+	// Importing the same document again changes nothing but reports every policy as updated
+	result, err = service.ImportPolicies(context.Background(), prodOrg, userID, &document, domain.PolicyImportReplace)
+	require.NoError(t, err)
+	assert.Empty(t, result.Created)
+	assert.Len(t, result.Updated, len(document.Policies))
+	assert.Empty(t, result.Deleted)
+	assert.Equal(t, repo.names(stagingOrg), repo.names(prodOrg))
+}
+
+func TestSecurityPolicyService_ImportPolicies_Modes(t *testing.T) {
+	document := &domain.SecurityPolicyExport{
+		Version: domain.SecurityPolicyExportVersion,
+		Policies: []domain.PortableSecurityPolicy{
+			{
+				Name:              "Block Capability Violations",
+				PolicyType:        domain.PolicyTypeCapabilityViolation,
+				EnforcementAction: domain.EnforcementAlertOnly,
+				SeverityThreshold: domain.AlertSeverityHigh,
+				Rules:             json.RawMessage(`{"attack_patterns":["echoleak"]}`),
+				AppliesTo:         "all",
+				IsEnabled:         true,
+				Priority:          1000,
+			},
+			{
+				Name:              "Low Trust Floor",
+				PolicyType:        domain.PolicyTypeTrustScoreLow,
+				EnforcementAction: domain.EnforcementBlockAndAlert,
+				SeverityThreshold: domain.AlertSeverityWarning,
+				AppliesTo:         "agent_type:mcp",
+				IsEnabled:         true,
+				Priority:          100,
+			},
+		},
+	}
+
+	tests := []struct {
+		mode        domain.PolicyImportMode
+		wantDeleted []string
+		wantNames   []string
+	}{
+		{domain.PolicyImportMerge, []string{}, []string{"Block Capability Violations", "Low Trust Floor", "Legacy Policy"}},
+		{domain.PolicyImportReplace, []string{"Legacy Policy"}, []string{"Block Capability Violations", "Low Trust Floor"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.mode), func(t *testing.T) {
+			repo := &memorySecurityPolicyRepository{}
+			service := NewSecurityPolicyService(repo, nil, nil, nil, nil, nil)
+			orgID := uuid.New()
+			existing := &domain.SecurityPolicy{
+				OrganizationID:    orgID,
+				Name:              "Block Capability Violations",
+				PolicyType:        domain.PolicyTypeCapabilityViolation,
+				EnforcementAction: domain.EnforcementBlockAndAlert,
+				AppliesTo:         "all",
+				Priority:          1000,
+			}
+			require.NoError(t, repo.Create(existing))
+			require.NoError(t, repo.Create(&domain.SecurityPolicy{OrganizationID: orgID, Name: "Legacy Policy", Priority: 1}))
+
+			result, err := service.ImportPolicies(context.Background(), orgID, uuid.New(), document, tt.mode)
+			require.NoError(t, err)
+			assert.Equal(t, []string{"Low Trust Floor"}, result.Created)
+			assert.Equal(t, []string{"Block Capability Violations"}, result.Updated)
+			assert.Equal(t, tt.wantDeleted, result.Deleted)
+			assert.Equal(t, tt.wantNames, repo.names(orgID))
+
+			// The matched policy keeps its identity and takes the imported settings
+			updated, err := repo.GetByID(existing.ID)
+			require.NoError(t, err)
+			assert.Equal(t, domain.EnforcementAlertOnly, updated.EnforcementAction)
+			assert.Equal(t, []interface{}{"echoleak"}, updated.Rules["attack_patterns"])
+		})
+	}
+}
+
+func TestSecurityPolicyService_ImportPolicies_FailureChangesNothing(t *testing.T) {
+	repo := &memorySecurityPolicyRepository{}
+	service := NewSecurityPolicyService(repo, nil, nil, nil, nil, nil)
+	orgID := uuid.New()
+	require.NoError(t, repo.Create(&domain.SecurityPolicy{OrganizationID: orgID, Name: "Legacy Policy"}))
+	repo.applyError = errors.New("connection reset")
+
+	document := &domain.SecurityPolicyExport{
+		Version: domain.SecurityPolicyExportVersion,
+		Policies: []domain.PortableSecurityPolicy{{
+			Name:              "Low Trust Floor",
+			PolicyType:        domain.PolicyTypeTrustScoreLow,
+			EnforcementAction: domain.EnforcementBlockAndAlert,
+			SeverityThreshold: domain.AlertSeverityWarning,
+			AppliesTo:         "all",
+		}},
+	}
+
+	result, err := service.ImportPolicies(context.Background(), orgID, uuid.New(), document, domain.PolicyImportReplace)
+	assert.ErrorContains(t, err, "connection reset")
+	assert.Nil(t, result)
+	assert.Equal(t, []string{"Legacy Policy"}, repo.names(orgID))
+}
+
+func TestSecurityPolicyService_ImportPolicies_RejectsInvalidDocument(t *testing.T) {
+	valid := domain.PortableSecurityPolicy{
+		Name:              "Low Trust Floor",
+		PolicyType:        domain.PolicyTypeTrustScoreLow,
+		EnforcementAction: domain.EnforcementBlockAndAlert,
+		SeverityThreshold: domain.AlertSeverityWarning,
+		Rules:             json.RawMessage(`{"trust_threshold":0.5}`),
+		AppliesTo:         "all",
+	}
+	with := func(change func(*domain.PortableSecurityPolicy)) domain.PortableSecurityPolicy {
+		policy := valid
+		change(&policy)
+		return policy
+	}
+
+	tests := []struct {
+		name     string
+		version  int
+		mode     domain.PolicyImportMode
+		policies []domain.PortableSecurityPolicy
+	}{
+		{"unknown mode", 1, "overwrite", []domain.PortableSecurityPolicy{valid}},
+		{"unsupported version", 2, domain.PolicyImportMerge, []domain.PortableSecurityPolicy{valid}},
+		{"rules not an object", 1, domain.PolicyImportMerge, []domain.PortableSecurityPolicy{
+			with(func(p *domain.PortableSecurityPolicy) { p.Rules = json.RawMessage(`["trust_threshold"]`) }),
+		}},
+		{"unknown policy type", 1, domain.PolicyImportMerge, []domain.PortableSecurityPolicy{
+			with(func(p *domain.PortableSecurityPolicy) { p.PolicyType = "geo_fence" }),
+		}},
+		{"unknown enforcement action", 1, domain.PolicyImportMerge, []domain.PortableSecurityPolicy{
+			with(func(p *domain.PortableSecurityPolicy) { p.EnforcementAction = "quarantine" }),
+		}},
+		{"missing name", 1, domain.PolicyImportMerge, []domain.PortableSecurityPolicy{
+			with(func(p *domain.PortableSecurityPolicy) { p.Name = " " }),
+		}},
+		{"duplicate name", 1, domain.PolicyImportMerge, []domain.PortableSecurityPolicy{valid, valid}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &memorySecurityPolicyRepository{}
+			service := NewSecurityPolicyService(repo, nil, nil, nil, nil, nil)
+			orgID := uuid.New()
+			require.NoError(t, repo.Create(&domain.SecurityPolicy{OrganizationID: orgID, Name: "Existing"}))
+
+			// Valid policies listed before the bad one must not be written either
+			policies := append([]domain.PortableSecurityPolicy{with(func(p *domain.PortableSecurityPolicy) { p.Name = "First" })}, tt.policies...)
+			document := &domain.SecurityPolicyExport{Version: tt.version, Policies: policies}
+
+			_, err := service.ImportPolicies(context.Background(), orgID, uuid.New(), document, tt.mode)
+			assert.ErrorIs(t, err, domain.ErrInvalidPolicyImport)
+			assert.Equal(t, []string{"Existing"}, repo.names(orgID))
+		})
+	}
+}
@@ -3,6 +3,7 @@ package application
 import (
 	"context"
 	"fmt"
+	"log/slog"
 	"math"
 	"time"
 
@@ -20,6 +21,10 @@ type TrustCalculator struct {
 	agentRepo              domain.AgentRepository
 	alertRepo              domain.AlertRepository
 	verificationEventRepo  domain.VerificationEventRepository
+	orgRepo                domain.OrganizationRepository // For per-organization trust weights and decay half-life
+	mcpServerRepo          domain.MCPServerRepository    // For the MCP servers factor
+	policyService          *SecurityPolicyService        // For per-organization trust score drop thresholds
+	outboxRepo             domain.OutboxRepository       // Queues trust_score_drop webhook events
 }
 
 // NewTrustCalculator creates a new trust calculator
@@ -41,7 +46,11 @@ func NewTrustCalculator(
 	}
 }
 
-// NewTrustCalculatorWithVerification creates a new trust calculator with verification event repo
+// NewTrustCalculatorWithVerification creates a new trust calculator with verification event repo,
+// the organization repo that supplies each organization's trust weights and decay half-life, the
+// MCP server repo the MCP servers factor is scored from, and the outbox that trust_score_drop
+// webhook events are queued in when a recalculation lowers a score. A nil policyService uses
+// DefaultTrustScoreDropThresholds.
 func NewTrustCalculatorWithVerification(
 	trustScoreRepo domain.TrustScoreRepository,
 	apiKeyRepo domain.APIKeyRepository,
@@ -50,6 +59,10 @@ func NewTrustCalculatorWithVerification(
 	agentRepo domain.AgentRepository,
 	alertRepo domain.AlertRepository,
 	verificationEventRepo domain.VerificationEventRepository,
+	orgRepo domain.OrganizationRepository,
+	mcpServerRepo domain.MCPServerRepository,
+	policyService *SecurityPolicyService,
+	outboxRepo domain.OutboxRepository,
 ) *TrustCalculator {
 	return &TrustCalculator{
 		trustScoreRepo:         trustScoreRepo,
@@ -59,47 +72,23 @@ func NewTrustCalculatorWithVerification(
 		agentRepo:              agentRepo,
 		alertRepo:              alertRepo,
 		verificationEventRepo:  verificationEventRepo,
+		orgRepo:                orgRepo,
+		mcpServerRepo:          mcpServerRepo,
+		policyService:          policyService,
+		outboxRepo:             outboxRepo,
 	}
 }
 
 // Calculate calculates trust score for an agent
 // Implements the 8-factor algorithm with weighted average
 func (c *TrustCalculator) Calculate(agent *domain.Agent) (*domain.TrustScore, error) {
-	factors, err := c.CalculateFactors(agent)
-	if err != nil {
-		return nil, err
-	}
+	// The organization supplies both the decay half-life and the weights; load it once
+	org := c.organization(agent.OrganizationID)
+	factors := c.calculateFactors(agent, org)
 
-	// 8-factor weighted average (totaling 100%)
-	// Formula from documentation:
-	// Trust Score =
-	//     (0.25 × Verification Status) +
-	//     (0.15 × Uptime & Availability) +
-	//     (0.15 × Action Success Rate) +
-	//     (0.15 × Security Alerts) +
-	//     (0.10 × Compliance Score) +
-	//     (0.10 × Age & History) +
-	//     (0.05 × Drift Detection) +
-	//     (0.05 × User Feedback)
-	weights := map[string]float64{
-		"verification":    0.25, // Factor 1
-		"uptime":          0.15, // Factor 2
-		"success_rate":    0.15, // Factor 3
-		"security_alerts": 0.15, // Factor 4
-		"compliance":      0.10, // Factor 5
-		"age":             0.10, // Factor 6
-		"drift_detection": 0.05, // Factor 7
-		"user_feedback":   0.05, // Factor 8
-	}
-
-	score := factors.VerificationStatus*weights["verification"] +
-		factors.Uptime*weights["uptime"] +
-		factors.SuccessRate*weights["success_rate"] +
-		factors.SecurityAlerts*weights["security_alerts"] +
-		factors.Compliance*weights["compliance"] +
-		factors.Age*weights["age"] +
-		factors.DriftDetection*weights["drift_detection"] +
-		factors.UserFeedback*weights["user_feedback"]
+	// Weighted average (totaling 100%) with the organization's category weights
+	breakdown := BuildTrustScoreBreakdownWithWeights(factors, trustWeights(org))
+	score := breakdown.Total
 
 	// Ensure score is within bounds [0, 1]
 	score = math.Max(0.0, math.Min(1.0, score))
@@ -112,14 +101,89 @@ func (c *TrustCalculator) Calculate(agent *domain.Agent) (*domain.TrustScore, er
 		AgentID:        agent.ID,
 		Score:          score,
 		Factors:        *factors,
+		Breakdown:      breakdown,
 		Confidence:     confidence,
 		LastCalculated: time.Now(),
 		CreatedAt:      time.Now(),
 	}, nil
 }
 
+// trustScoreFactors are the trust score factors in display order. Organizations weight categories
+// (see domain.TrustWeights); a category's weight is split between its factors by their share.
+// The default weights reproduce the documented formula:
+// Trust Score =
+//     (0.25 × Verification Status) +
+//     (0.15 × Uptime & Availability) +
+//     (0.15 × Action Success Rate) +
+//     (0.15 × Security Alerts) +
+//     (0.10 × Compliance Score) +
+//     (0.10 × Age & History) +
+//     (0.05 × Drift Detection) +
+//     (0.05 × User Feedback)
+var trustScoreFactors = []struct {
+	factor   string
+	category string
+	share    float64 // Relative to the other factors of the category
+	value    func(f *domain.TrustScoreFactors) float64
+}{
+	{"verificationStatus", domain.TrustCategoryVerification, 0.25, func(f *domain.TrustScoreFactors) float64 { return f.VerificationStatus }}, // Factor 1
+	{"uptime", domain.TrustCategoryVerification, 0.15, func(f *domain.TrustScoreFactors) float64 { return f.Uptime }},                         // Factor 2
+	{"successRate", domain.TrustCategoryVerification, 0.15, func(f *domain.TrustScoreFactors) float64 { return f.SuccessRate }},               // Factor 3
+	{"securityAlerts", domain.TrustCategoryAlerts, 1, func(f *domain.TrustScoreFactors) float64 { return f.SecurityAlerts }},                  // Factor 4
+	{"compliance", domain.TrustCategoryVerification, 0.10, func(f *domain.TrustScoreFactors) float64 { return f.Compliance }},                 // Factor 5
+	{"age", domain.TrustCategoryVerification, 0.10, func(f *domain.TrustScoreFactors) float64 { return f.Age }},                               // Factor 6
+	{"driftDetection", domain.TrustCategoryVerification, 0.05, func(f *domain.TrustScoreFactors) float64 { return f.DriftDetection }},         // Factor 7
+	{"userFeedback", domain.TrustCategoryVerification, 0.05, func(f *domain.TrustScoreFactors) float64 { return f.UserFeedback }},             // Factor 8
+	{"capabilityViolations", domain.TrustCategoryViolations, 1, func(f *domain.TrustScoreFactors) float64 { return f.CapabilityViolations }},
+	{"keyAge", domain.TrustCategoryKeyAge, 1, func(f *domain.TrustScoreFactors) float64 { return f.KeyAge }},
+	{"mcpServers", domain.TrustCategoryMCP, 1, func(f *domain.TrustScoreFactors) float64 { return f.MCPServers }},
+}
+
+// trustCategoryShares is the total share of the factors in each category
+var trustCategoryShares = func() map[string]float64 {
+	shares := make(map[string]float64)
+	for _, f := range trustScoreFactors {
+		shares[f.category] += f.share
+	}
+	return shares
+}()
+
+// BuildTrustScoreBreakdown computes each factor's weighted contribution to the trust score
+// using the default weights
+func BuildTrustScoreBreakdown(factors *domain.TrustScoreFactors) *domain.TrustScoreBreakdown {
+	return BuildTrustScoreBreakdownWithWeights(factors, domain.DefaultTrustWeights())
+}
+
+// BuildTrustScoreBreakdownWithWeights computes each factor's weighted contribution to the trust score
+func BuildTrustScoreBreakdownWithWeights(factors *domain.TrustScoreFactors, weights domain.TrustWeights) *domain.TrustScoreBreakdown {
+	breakdown := &domain.TrustScoreBreakdown{
+		Factors: make([]domain.TrustScoreFactorContribution, 0, len(trustScoreFactors)),
+	}
+
+	for _, f := range trustScoreFactors {
+		raw := f.value(factors)
+		weight := weights.Weight(f.category) * f.share / trustCategoryShares[f.category]
+		contribution := raw * weight
+		breakdown.Factors = append(breakdown.Factors, domain.TrustScoreFactorContribution{
+			Factor:       f.factor,
+			Category:     f.category,
+			RawValue:     raw,
+			Weight:       weight,
+			Contribution: contribution,
+		})
+		breakdown.Total += contribution
+	}
+
+	return breakdown
+}
+
 // CalculateFactors calculates individual trust factors
 func (c *TrustCalculator) CalculateFactors(agent *domain.Agent) (*domain.TrustScoreFactors, error) {
+	return c.calculateFactors(agent, c.organization(agent.OrganizationID)), nil
+}
+
+// calculateFactors calculates individual trust factors with the settings of org, which may be nil
+func (c *TrustCalculator) calculateFactors(agent *domain.Agent, org *domain.Organization) *domain.TrustScoreFactors {
 	factors := &domain.TrustScoreFactors{}
 
 	// Factor 1: Verification Status (25% weight)
@@ -135,8 +199,9 @@ func (c *TrustCalculator) CalculateFactors(agent *domain.Agent) (*domain.TrustSc
 	factors.SuccessRate = c.calculateSuccessRate(agent)
 
 	// Factor 4: Security Alerts (15% weight)
-	// Active security alerts by severity
-	factors.SecurityAlerts = c.calculateSecurityAlerts(agent)
+	// Active security alerts by severity, or recent capability violations without any
+	factors.CapabilityViolations = c.calculateCapabilityViolations(agent)
+	factors.SecurityAlerts = c.calculateSecurityAlerts(agent, factors.CapabilityViolations)
 
 	// Factor 5: Compliance Score (10% weight)
 	// SOC 2, HIPAA, GDPR adherence
@@ -154,7 +219,81 @@ func (c *TrustCalculator) CalculateFactors(agent *domain.Agent) (*domain.TrustSc
 	// Explicit user ratings
 	factors.UserFeedback = c.calculateUserFeedback(agent)
 
-	return factors, nil
+	// Category factors, only weighted by organizations that opt into their category
+	// (capability violations are scored with the security alerts above)
+	factors.KeyAge = calculateKeyAge(agent, time.Now())
+	factors.MCPServers = c.calculateMCPServers(agent)
+
+	// Activity-derived factors fade while the agent is inactive. Age is not one of them: an idle
+	// agent does not get any younger.
+	decay := inactivityDecay(agent, trustDecayHalfLife(org), time.Now())
+	factors.Uptime *= decay
+	factors.SuccessRate *= decay
+
+	return factors
+}
+
+// organization loads the organization whose settings a calculation uses. It returns nil, and the
+// defaults apply, without an organization repo or when the organization cannot be loaded.
+func (c *TrustCalculator) organization(orgID uuid.UUID) *domain.Organization {
+	if c.orgRepo == nil {
+		return nil
+	}
+	org, err := c.orgRepo.GetByID(orgID)
+	if err != nil || org == nil {
+		slog.Warn("failed to load trust settings, using defaults", "org_id", orgID, "error", err)
+		return nil
+	}
+	return org
+}
+
+// trustDecayHalfLife returns the inactivity half-life configured for org, 0 if decay is disabled.
+// Without an organization or a valid setting the default half-life applies.
+func trustDecayHalfLife(org *domain.Organization) time.Duration {
+	days := domain.DefaultTrustDecayHalfLifeDays
+	if org != nil {
+		if err := domain.ValidateTrustDecayHalfLifeDays(org.TrustDecayHalfLifeDays); err != nil {
+			slog.Warn("invalid trust_decay_half_life_days, using default", "org_id", org.ID,
+				"trust_decay_half_life_days", org.TrustDecayHalfLifeDays, "days", days)
+		} else {
+			days = org.TrustDecayHalfLifeDays
+		}
+	}
+	return time.Duration(days) * 24 * time.Hour
+}
+
+// TrustWeights returns the category weights configured for orgID. Without an organization repo,
+// configured weights or valid ones the default weights apply.
+func (c *TrustCalculator) TrustWeights(orgID uuid.UUID) domain.TrustWeights {
+	return trustWeights(c.organization(orgID))
+}
+
+// trustWeights returns the category weights configured for org, which may be nil
+func trustWeights(org *domain.Organization) domain.TrustWeights {
+	weights := org.EffectiveTrustWeights()
+	if err := weights.Validate(); err != nil {
+		slog.Warn("invalid trust weights, using defaults", "org_id", org.ID, "error", err)
+		return domain.DefaultTrustWeights()
+	}
+	return weights
+}
+
+// inactivityDecay returns the multiplier (0-1] for an agent's activity-derived factors: 1 for an
+// agent active at now, halving with every halfLife since it was last active (or created, if it
+// never was). A halfLife of 0 disables decay, as does an agent without timestamps.
+func inactivityDecay(agent *domain.Agent, halfLife time.Duration, now time.Time) float64 {
+	if halfLife <= 0 {
+		return 1.0
+	}
+	lastActive := agent.CreatedAt
+	if agent.LastActive != nil {
+		lastActive = *agent.LastActive
+	}
+	idle := now.Sub(lastActive)
+	if lastActive.IsZero() || idle <= 0 {
+		return 1.0
+	}
+	return math.Pow(0.5, idle.Hours()/halfLife.Hours())
 }
 
 // Factor 1: Verification Status (25% weight)
@@ -265,8 +404,9 @@ func (c *TrustCalculator) calculateSuccessRate(agent *domain.Agent) float64 {
 }
 
 // Factor 4: Security Alerts (15% weight)
-// Measures active security alerts by severity
-func (c *TrustCalculator) calculateSecurityAlerts(agent *domain.Agent) float64 {
+// Measures active security alerts by severity. Without any, the agent's capability violations
+// score stands in as additional security signal.
+func (c *TrustCalculator) calculateSecurityAlerts(agent *domain.Agent, violationsScore float64) float64 {
 	// Query alerts table for agent-specific unacknowledged alerts
 	if c.alertRepo != nil {
 		alerts, err := c.alertRepo.GetUnacknowledgedByResourceID(agent.ID)
@@ -298,7 +438,12 @@ func (c *TrustCalculator) calculateSecurityAlerts(agent *domain.Agent) float64 {
 		}
 	}
 
-	// Also check capability violations as additional security signal
+	return violationsScore
+}
+
+// calculateCapabilityViolations measures the agent's capability violations in the last 30 days
+// by severity (violations category)
+func (c *TrustCalculator) calculateCapabilityViolations(agent *domain.Agent) float64 {
 	violations, _, err := c.capabilityRepo.GetViolationsByAgentID(agent.ID, 100, 0)
 	if err != nil || len(violations) == 0 {
 		return 1.0 // No violations = perfect security score
@@ -385,6 +530,56 @@ func (c *TrustCalculator) calculateUserFeedback(agent *domain.Agent) float64 {
 	return 0.75
 }
 
+// calculateKeyAge measures how much of the agent's signing key lifetime is left at now (key_age
+// category): 1.0 for a freshly rotated key, falling linearly to 0 at expiry. A key without
+// recorded creation or expiry scores 0.5.
+func calculateKeyAge(agent *domain.Agent, now time.Time) float64 {
+	if agent.KeyCreatedAt == nil || agent.KeyExpiresAt == nil {
+		return 0.5
+	}
+	lifetime := agent.KeyExpiresAt.Sub(*agent.KeyCreatedAt)
+	if lifetime <= 0 {
+		return 0.0
+	}
+	remaining := agent.KeyExpiresAt.Sub(now)
+	return math.Max(0.0, math.Min(1.0, remaining.Hours()/lifetime.Hours()))
+}
+
+// calculateMCPServers measures the verification status of the MCP servers the agent talks to
+// (mcp category): verified servers count fully, pending ones half, and suspended, revoked or
+// unregistered ones not at all. An agent that talks to no MCP servers scores 1.0.
+func (c *TrustCalculator) calculateMCPServers(agent *domain.Agent) float64 {
+	if len(agent.TalksTo) == 0 || c.mcpServerRepo == nil {
+		return 1.0
+	}
+
+	servers, err := c.mcpServerRepo.GetByOrganization(agent.OrganizationID)
+	if err != nil {
+		return 1.0
+	}
+	// Agents refer to MCP servers by ID or name
+	serversByKey := make(map[string]*domain.MCPServer, 2*len(servers))
+	for _, server := range servers {
+		serversByKey[server.ID.String()] = server
+		serversByKey[server.Name] = server
+	}
+
+	total := 0.0
+	for _, identifier := range agent.TalksTo {
+		server, ok := serversByKey[identifier]
+		if !ok {
+			continue
+		}
+		switch server.Status {
+		case domain.MCPServerStatusVerified:
+			total += 1.0
+		case domain.MCPServerStatusPending:
+			total += 0.5
+		}
+	}
+	return total / float64(len(agent.TalksTo))
+}
+
 // calculateConfidence determines confidence level based on available data
 func (c *TrustCalculator) calculateConfidence(agent *domain.Agent, factors *domain.TrustScoreFactors) float64 {
 	// Count available data points (each real data source adds confidence)
@@ -446,6 +641,12 @@ func (c *TrustCalculator) CalculateTrustScore(ctx context.Context, agentID uuid.
 		return nil, err
 	}
 
+	return c.recalculate(ctx, agent)
+}
+
+// recalculate calculates the agent's trust score and stores it. A significant drop from the
+// agent's previous score queues a trust_score_drop webhook event.
+func (c *TrustCalculator) recalculate(ctx context.Context, agent *domain.Agent) (*domain.TrustScore, error) {
 	// Calculate trust score
 	score, err := c.Calculate(agent)
 	if err != nil {
@@ -459,13 +660,52 @@ func (c *TrustCalculator) CalculateTrustScore(ctx context.Context, agentID uuid.
 
 	// Update the agent's trust_score field to keep it in sync
 	// This ensures agents.trust_score matches the calculated score from trust_scores table
-	if err := c.agentRepo.UpdateTrustScore(agentID, score.Score); err != nil {
+	if err := c.agentRepo.UpdateTrustScore(agent.ID, score.Score); err != nil {
 		return nil, fmt.Errorf("failed to update agent trust score: %w", err)
 	}
 
+	c.queueTrustScoreDrop(ctx, agent, score)
+
 	return score, nil
 }
 
+// queueTrustScoreDrop queues a trust_score_drop webhook event if score is a significant drop from
+// the agent's previous score. The score is already stored, so a failure is only logged.
+func (c *TrustCalculator) queueTrustScoreDrop(ctx context.Context, agent *domain.Agent, score *domain.TrustScore) {
+	if c.outboxRepo == nil {
+		return
+	}
+
+	thresholds := DefaultTrustScoreDropThresholds
+	if c.policyService != nil {
+		thresholds = c.policyService.GetTrustScoreDropThresholds(ctx, agent)
+	}
+	severity, ok := thresholds.DropSeverity(agent.TrustScore, score.Score)
+	if !ok {
+		return
+	}
+
+	agentName := agent.DisplayName
+	if agentName == "" {
+		agentName = agent.Name
+	}
+	message, err := domain.NewWebhookOutboxMessage(agent.OrganizationID, domain.WebhookEventTrustScoreDrop, domain.WebhookResourceAgent,
+		domain.TrustScoreDropWebhookData{
+			AgentID:            agent.ID,
+			AgentName:          agentName,
+			PreviousTrustScore: agent.TrustScore,
+			TrustScore:         score.Score,
+			Severity:           severity,
+			ChangedAt:          score.LastCalculated,
+		})
+	if err == nil {
+		err = c.outboxRepo.Enqueue(message)
+	}
+	if err != nil {
+		slog.Warn("failed to queue trust score drop webhook event", "agent_id", agent.ID, "error", err)
+	}
+}
+
 // GetLatestTrustScore retrieves the latest trust score for an agent
 func (c *TrustCalculator) GetLatestTrustScore(ctx context.Context, agentID uuid.UUID) (*domain.TrustScore, error) {
 	return c.trustScoreRepo.GetLatest(agentID)
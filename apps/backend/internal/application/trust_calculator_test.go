@@ -15,6 +15,7 @@ import (
 	"github.com/opena2a/identity/backend/internal/domain"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 )
 
 // MockCapabilityRepository for testing
@@ -51,6 +52,14 @@ func (m *MockCapabilityRepository) GetActiveCapabilitiesByAgentID(agentID uuid.U
 	return args.Get(0).([]*domain.AgentCapability), args.Error(1)
 }
 
+func (m *MockCapabilityRepository) GetCapabilitiesByAgentIDs(agentIDs []uuid.UUID) (map[uuid.UUID][]*domain.AgentCapability, error) {
+	args := m.Called(agentIDs)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[uuid.UUID][]*domain.AgentCapability), args.Error(1)
+}
+
 func (m *MockCapabilityRepository) RevokeCapability(id uuid.UUID, revokedAt time.Time) error {
 	args := m.Called(id, revokedAt)
 	return args.Error(0)
@@ -90,6 +99,14 @@ func (m *MockCapabilityRepository) GetRecentViolations(orgID uuid.UUID, minutes
 	return args.Get(0).([]*domain.CapabilityViolation), args.Error(1)
 }
 
+func (m *MockCapabilityRepository) GetViolationsByAgentSince(agentID uuid.UUID, since time.Time) ([]*domain.CapabilityViolation, error) {
+	args := m.Called(agentID, since)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.CapabilityViolation), args.Error(1)
+}
+
 func (m *MockCapabilityRepository) GetViolationsByOrganization(orgID uuid.UUID, limit, offset int) ([]*domain.CapabilityViolation, int, error) {
 	args := m.Called(orgID, limit, offset)
 	if args.Get(0) == nil {
@@ -98,6 +115,14 @@ func (m *MockCapabilityRepository) GetViolationsByOrganization(orgID uuid.UUID,
 	return args.Get(0).([]*domain.CapabilityViolation), args.Int(1), args.Error(2)
 }
 
+func (m *MockCapabilityRepository) GetCatalog(orgID uuid.UUID) ([]*domain.CapabilityCatalogEntry, error) {
+	args := m.Called(orgID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.CapabilityCatalogEntry), args.Error(1)
+}
+
 // Note: AgentServiceMockTrustScoreRepository and MockAPIKeyRepository
 // are defined in other test files (agent_service_test.go, auth_service_test.go)
 
@@ -159,8 +184,16 @@ func (m *AgentServiceMockAuditLogRepository) GetByResource(resourceType string,
 	return args.Get(0).([]*domain.AuditLog), args.Error(1)
 }
 
-func (m *AgentServiceMockAuditLogRepository) Search(query string, limit, offset int) ([]*domain.AuditLog, error) {
-	args := m.Called(query, limit, offset)
+func (m *AgentServiceMockAuditLogRepository) Search(orgID uuid.UUID, filter domain.AuditLogFilter, limit, offset int) ([]*domain.AuditLog, int, error) {
+	args := m.Called(orgID, filter, limit, offset)
+	if args.Get(0) == nil {
+		return nil, args.Int(1), args.Error(2)
+	}
+	return args.Get(0).([]*domain.AuditLog), args.Int(1), args.Error(2)
+}
+
+func (m *AgentServiceMockAuditLogRepository) SearchAfter(orgID uuid.UUID, filter domain.AuditLogFilter, after *domain.AuditLogCursor, limit int) ([]*domain.AuditLog, error) {
+	args := m.Called(orgID, filter, after, limit)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
@@ -188,6 +221,22 @@ func (m *AgentServiceMockAuditLogRepository) GetAgentActionsByIPAddress(agentID
 	return args.Get(0).([]*domain.AuditLog), args.Error(1)
 }
 
+func (m *AgentServiceMockAuditLogRepository) GetChain(orgID uuid.UUID, afterSequence int64, limit int) ([]*domain.AuditLog, error) {
+	args := m.Called(orgID, afterSequence, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.AuditLog), args.Error(1)
+}
+
+func (m *AgentServiceMockAuditLogRepository) GetChainHead(orgID uuid.UUID) (*domain.AuditChainHead, error) {
+	args := m.Called(orgID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.AuditChainHead), args.Error(1)
+}
+
 // TrustCalcMockAgentRepository mocks the AgentRepository for trust calculator tests
 type TrustCalcMockAgentRepository struct {
 	mock.Mock
@@ -198,6 +247,14 @@ func (m *TrustCalcMockAgentRepository) Create(agent *domain.Agent) error {
 	return args.Error(0)
 }
 
+func (m *TrustCalcMockAgentRepository) CreateBatch(agents []*domain.Agent, atomic bool) ([]error, error) {
+	args := m.Called(agents, atomic)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]error), args.Error(1)
+}
+
 func (m *TrustCalcMockAgentRepository) GetByID(id uuid.UUID) (*domain.Agent, error) {
 	args := m.Called(id)
 	if args.Get(0) == nil {
@@ -222,6 +279,43 @@ func (m *TrustCalcMockAgentRepository) GetByOrganization(orgID uuid.UUID) ([]*do
 	return args.Get(0).([]*domain.Agent), args.Error(1)
 }
 
+func (m *TrustCalcMockAgentRepository) GetByOrganizationPaginated(orgID uuid.UUID, limit, offset int, after *domain.AgentCursor) ([]*domain.Agent, int, error) {
+	args := m.Called(orgID, limit, offset, after)
+	if args.Get(0) == nil {
+		return nil, args.Int(1), args.Error(2)
+	}
+	return args.Get(0).([]*domain.Agent), args.Int(1), args.Error(2)
+}
+
+func (m *TrustCalcMockAgentRepository) Search(orgID uuid.UUID, filter domain.AgentSearchFilter, limit, offset int, after *domain.AgentCursor) ([]*domain.Agent, int, error) {
+	args := m.Called(orgID, filter, limit, offset, after)
+	if args.Get(0) == nil {
+		return nil, args.Int(1), args.Error(2)
+	}
+	return args.Get(0).([]*domain.Agent), args.Int(1), args.Error(2)
+}
+
+func (m *TrustCalcMockAgentRepository) GetByIDIncludingDeleted(id uuid.UUID) (*domain.Agent, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Agent), args.Error(1)
+}
+
+func (m *TrustCalcMockAgentRepository) SoftDelete(id uuid.UUID) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func (m *TrustCalcMockAgentRepository) GetByKeyExpiringBetween(from, to time.Time) ([]*domain.Agent, error) {
+	args := m.Called(from, to)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.Agent), args.Error(1)
+}
+
 func (m *TrustCalcMockAgentRepository) List(limit, offset int) ([]*domain.Agent, error) {
 	args := m.Called(limit, offset)
 	if args.Get(0) == nil {
@@ -245,11 +339,41 @@ func (m *TrustCalcMockAgentRepository) UpdateTrustScore(agentID uuid.UUID, score
 	return args.Error(0)
 }
 
+func (m *TrustCalcMockAgentRepository) UpdateLabels(id uuid.UUID, labels map[string]string) error {
+	args := m.Called(id, labels)
+	return args.Error(0)
+}
+
+func (m *TrustCalcMockAgentRepository) UpdateOAuthClientID(id uuid.UUID, clientID string) error {
+	args := m.Called(id, clientID)
+	return args.Error(0)
+}
+
+func (m *TrustCalcMockAgentRepository) CountByOrganization(orgID uuid.UUID) (int, error) {
+	args := m.Called(orgID)
+	return args.Int(0), args.Error(1)
+}
+
 func (m *TrustCalcMockAgentRepository) MarkAsCompromised(agentID uuid.UUID) error {
 	args := m.Called(agentID)
 	return args.Error(0)
 }
 
+func (m *TrustCalcMockAgentRepository) MarkCompromisedBy(id, userID uuid.UUID, reason string, at time.Time, messages ...*domain.OutboxMessage) error {
+	args := m.Called(id, userID, reason, at, messages)
+	return args.Error(0)
+}
+
+func (m *TrustCalcMockAgentRepository) ClearCompromised(id uuid.UUID, messages ...*domain.OutboxMessage) error {
+	args := m.Called(id, messages)
+	return args.Error(0)
+}
+
+func (m *TrustCalcMockAgentRepository) UpdateStatus(agent *domain.Agent, messages ...*domain.OutboxMessage) error {
+	args := m.Called(agent, messages)
+	return args.Error(0)
+}
+
 func (m *TrustCalcMockAgentRepository) UpdateLastActive(ctx context.Context, agentID uuid.UUID) error {
 	args := m.Called(ctx, agentID)
 	return args.Error(0)
@@ -310,8 +434,13 @@ func (m *TrustCalcMockAlertRepository) GetUnacknowledgedByResourceID(resourceID
 	return args.Get(0).([]*domain.Alert), args.Error(1)
 }
 
-func (m *TrustCalcMockAlertRepository) Acknowledge(id, userID uuid.UUID) error {
-	args := m.Called(id, userID)
+func (m *TrustCalcMockAlertRepository) Acknowledge(id, userID uuid.UUID, at time.Time) error {
+	args := m.Called(id, userID, at)
+	return args.Error(0)
+}
+
+func (m *TrustCalcMockAlertRepository) Resolve(id, userID uuid.UUID, note *string, at time.Time) error {
+	args := m.Called(id, userID, note, at)
 	return args.Error(0)
 }
 
@@ -558,3 +687,308 @@ func TestTrustCalculator_VerificationStatus_Unknown(t *testing.T) {
 
 	assert.Equal(t, 0.3, score, "Unknown status should default to 0.3")
 }
+
+// ============================================================================
+// TEST: Trust Score Breakdown
+// ============================================================================
+
+func TestTrustCalculator_Calculate_BreakdownSumsToScore(t *testing.T) {
+	mockTrustRepo := new(AgentServiceMockTrustScoreRepository)
+	mockAPIKeyRepo := new(MockAPIKeyRepository)
+	mockAuditRepo := new(AgentServiceMockAuditLogRepository)
+	mockCapabilityRepo := new(MockCapabilityRepository)
+	mockAgentRepo := new(TrustCalcMockAgentRepository)
+	mockAlertRepo := new(TrustCalcMockAlertRepository)
+
+	calculator := NewTrustCalculator(mockTrustRepo, mockAPIKeyRepo, mockAuditRepo, mockCapabilityRepo, mockAgentRepo, mockAlertRepo)
+
+	agent := &domain.Agent{
+		ID:        uuid.New(),
+		Status:    domain.AgentStatusVerified,
+		UpdatedAt: time.Now(),
+		CreatedAt: time.Now().Add(-45 * 24 * time.Hour),
+	}
+
+	mockCapabilityRepo.On("GetActiveCapabilitiesByAgentID", agent.ID).Return([]*domain.AgentCapability{}, nil).Maybe()
+	mockCapabilityRepo.On("GetViolationsByAgentID", agent.ID, 100, 0).Return([]*domain.CapabilityViolation{}, 0, nil).Maybe()
+	mockAlertRepo.On("GetUnacknowledgedByResourceID", agent.ID).Return([]*domain.Alert{
+		{ID: uuid.New(), Severity: domain.AlertSeverityHigh},
+	}, nil).Maybe()
+	mockAlertRepo.On("GetByResourceID", agent.ID, 100, 0).Return([]*domain.Alert{}, nil).Maybe()
+
+	score, err := calculator.Calculate(agent)
+
+	assert.NoError(t, err)
+	assert.NotNil(t, score.Breakdown)
+	assert.Len(t, score.Breakdown.Factors, 11)
+
+	sumContributions := 0.0
+	sumWeights := 0.0
+	for _, f := range score.Breakdown.Factors {
+		assert.InDelta(t, f.RawValue*f.Weight, f.Contribution, 1e-9, "factor %s contribution", f.Factor)
+		sumContributions += f.Contribution
+		sumWeights += f.Weight
+	}
+
+	assert.InDelta(t, 1.0, sumWeights, 1e-9, "weights must sum to 1.0")
+	assert.InDelta(t, score.Score, sumContributions, 1e-6, "contributions must sum to the final score")
+	assert.InDelta(t, score.Breakdown.Total, sumContributions, 1e-9)
+}
+
+func TestBuildTrustScoreBreakdown_FactorValues(t *testing.T) {
+	factors := &domain.TrustScoreFactors{
+		VerificationStatus: 1.0,
+		Uptime:             0.8,
+		SuccessRate:        0.5,
+		SecurityAlerts:     0.2,
+		Compliance:         1.0,
+		Age:                0.4,
+		DriftDetection:     1.0,
+		UserFeedback:       0.0,
+	}
+
+	breakdown := BuildTrustScoreBreakdown(factors)
+
+	assert.Equal(t, "verificationStatus", breakdown.Factors[0].Factor)
+	assert.Equal(t, domain.TrustCategoryVerification, breakdown.Factors[0].Category)
+	assert.InDelta(t, 0.25, breakdown.Factors[0].Weight, 1e-9)
+	assert.InDelta(t, 0.25, breakdown.Factors[0].Contribution, 1e-9)
+	assert.Equal(t, "uptime", breakdown.Factors[1].Factor)
+	assert.InDelta(t, 0.12, breakdown.Factors[1].Contribution, 1e-9)
+	assert.InDelta(t, 0.25+0.12+0.075+0.03+0.10+0.04+0.05+0.0, breakdown.Total, 1e-9)
+}
+
+// ============================================================================
+// TEST: Inactivity decay
+// ============================================================================
+
+func newDecayTestCalculator(orgID uuid.UUID, halfLifeDays int) *TrustCalculator {
+	mockCapabilityRepo := new(MockCapabilityRepository)
+	mockAlertRepo := new(TrustCalcMockAlertRepository)
+	mockOrgRepo := new(MockOrganizationRepository)
+
+	mockCapabilityRepo.On("GetViolationsByAgentID", mock.Anything, 100, 0).Return([]*domain.CapabilityViolation{}, 0, nil).Maybe()
+	mockAlertRepo.On("GetUnacknowledgedByResourceID", mock.Anything).Return([]*domain.Alert{}, nil).Maybe()
+	mockOrgRepo.On("GetByID", orgID).Return(&domain.Organization{ID: orgID, TrustDecayHalfLifeDays: halfLifeDays}, nil)
+
+	return NewTrustCalculatorWithVerification(nil, nil, nil, mockCapabilityRepo, nil, mockAlertRepo, nil, mockOrgRepo, nil, nil, nil)
+}
+
+func newDecayTestAgent(orgID uuid.UUID, lastActive time.Time) *domain.Agent {
+	return &domain.Agent{
+		ID:             uuid.New(),
+		OrganizationID: orgID,
+		Status:         domain.AgentStatusVerified,
+		CreatedAt:      time.Now().Add(-365 * 24 * time.Hour),
+		LastActive:     &lastActive,
+	}
+}
+
+func TestTrustCalculator_CalculateFactors_FreshlyActiveAgentNotPenalized(t *testing.T) {
+	orgID := uuid.New()
+	calculator := newDecayTestCalculator(orgID, 30)
+
+	factors, err := calculator.CalculateFactors(newDecayTestAgent(orgID, time.Now()))
+	require.NoError(t, err)
+
+	// Same values as the undecayed verified-agent baselines
+	assert.InDelta(t, 0.98, factors.Uptime, 1e-6)
+	assert.InDelta(t, 0.95, factors.SuccessRate, 1e-6)
+	assert.InDelta(t, 1.0, factors.Age, 1e-6)
+}
+
+func TestTrustCalculator_CalculateFactors_HalfLifeHalvesActivityFactors(t *testing.T) {
+	orgID := uuid.New()
+	calculator := newDecayTestCalculator(orgID, 30)
+
+	fresh, err := calculator.CalculateFactors(newDecayTestAgent(orgID, time.Now()))
+	require.NoError(t, err)
+	stale, err := calculator.CalculateFactors(newDecayTestAgent(orgID, time.Now().Add(-30*24*time.Hour)))
+	require.NoError(t, err)
+
+	assert.InDelta(t, fresh.Uptime/2, stale.Uptime, 0.01)
+	assert.InDelta(t, fresh.SuccessRate/2, stale.SuccessRate, 0.01)
+
+	// Age, identity and security factors do not depend on activity
+	assert.Equal(t, fresh.Age, stale.Age)
+	assert.Equal(t, fresh.VerificationStatus, stale.VerificationStatus)
+	assert.Equal(t, fresh.SecurityAlerts, stale.SecurityAlerts)
+
+	// The activity-derived contribution to the score is halved as well
+	activityContribution := func(f *domain.TrustScoreFactors) float64 {
+		total := 0.0
+		for _, c := range BuildTrustScoreBreakdown(f).Factors {
+			switch c.Factor {
+			case "uptime", "successRate":
+				total += c.Contribution
+			}
+		}
+		return total
+	}
+	assert.InDelta(t, activityContribution(fresh)/2, activityContribution(stale), 0.01)
+}
+
+func TestTrustCalculator_CalculateFactors_DecayDisabled(t *testing.T) {
+	orgID := uuid.New()
+	calculator := newDecayTestCalculator(orgID, 0)
+
+	factors, err := calculator.CalculateFactors(newDecayTestAgent(orgID, time.Now().Add(-365*24*time.Hour)))
+	require.NoError(t, err)
+	assert.InDelta(t, 0.98, factors.Uptime, 1e-6)
+}
+
+func TestInactivityDecay_FallsBackToCreatedAt(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	halfLife := 90 * 24 * time.Hour
+
+	neverActive := &domain.Agent{CreatedAt: now.Add(-2 * halfLife)}
+	assert.InDelta(t, 0.25, inactivityDecay(neverActive, halfLife, now), 1e-9)
+
+	justCreated := &domain.Agent{CreatedAt: now}
+	assert.Equal(t, 1.0, inactivityDecay(justCreated, halfLife, now))
+}
+
+// ============================================================================
+// TEST: Per-organization weights
+// ============================================================================
+
+// trustCalcMCPServerRepository serves a fixed list of MCP servers
+type trustCalcMCPServerRepository struct {
+	domain.MCPServerRepository
+	servers []*domain.MCPServer
+}
+
+func (r *trustCalcMCPServerRepository) GetByOrganization(orgID uuid.UUID) ([]*domain.MCPServer, error) {
+	return r.servers, nil
+}
+
+func newWeightedTestCalculator(orgID uuid.UUID, weights *domain.TrustWeights) (*TrustCalculator, *MockOrganizationRepository) {
+	mockCapabilityRepo := new(MockCapabilityRepository)
+	mockAlertRepo := new(TrustCalcMockAlertRepository)
+	mockOrgRepo := new(MockOrganizationRepository)
+
+	mockCapabilityRepo.On("GetActiveCapabilitiesByAgentID", mock.Anything).Return([]*domain.AgentCapability{}, nil).Maybe()
+	mockCapabilityRepo.On("GetViolationsByAgentID", mock.Anything, 100, 0).Return([]*domain.CapabilityViolation{}, 0, nil).Maybe()
+	mockAlertRepo.On("GetUnacknowledgedByResourceID", mock.Anything).Return([]*domain.Alert{}, nil).Maybe()
+	mockAlertRepo.On("GetByResourceID", mock.Anything, 100, 0).Return([]*domain.Alert{}, nil).Maybe()
+	// Decay is disabled so only the weights differ between calculations
+	mockOrgRepo.On("GetByID", orgID).Return(&domain.Organization{ID: orgID, TrustWeights: weights}, nil)
+
+	return NewTrustCalculatorWithVerification(nil, nil, nil, mockCapabilityRepo, nil, mockAlertRepo, nil, mockOrgRepo, nil, nil, nil), mockOrgRepo
+}
+
+func TestTrustCalculator_Calculate_UsesOrganizationWeights(t *testing.T) {
+	orgID := uuid.New()
+	// No violations, and a key without recorded creation or expiry
+	agent := &domain.Agent{
+		ID:             uuid.New(),
+		OrganizationID: orgID,
+		Status:         domain.AgentStatusPending,
+		CreatedAt:      time.Now().Add(-10 * 24 * time.Hour),
+		UpdatedAt:      time.Now(),
+	}
+
+	// Everything on capability violations, then everything on key age
+	violationsOnly := &domain.TrustWeights{Violations: 1.0}
+	keyAgeOnly := &domain.TrustWeights{KeyAge: 1.0}
+
+	calculator, _ := newWeightedTestCalculator(orgID, violationsOnly)
+	byViolations, err := calculator.Calculate(agent)
+	require.NoError(t, err)
+	calculator, _ = newWeightedTestCalculator(orgID, keyAgeOnly)
+	byKeyAge, err := calculator.Calculate(agent)
+	require.NoError(t, err)
+
+	assert.Equal(t, 1.0, byViolations.Score)
+	assert.Equal(t, 0.5, byKeyAge.Score)
+	for _, f := range byKeyAge.Breakdown.Factors {
+		if f.Factor == "keyAge" {
+			assert.Equal(t, 1.0, f.Weight)
+		} else {
+			assert.Zero(t, f.Weight, "factor %s", f.Factor)
+		}
+	}
+
+	// The same weights always produce the same score
+	calculator, _ = newWeightedTestCalculator(orgID, violationsOnly)
+	again, err := calculator.Calculate(agent)
+	require.NoError(t, err)
+	assert.Equal(t, byViolations.Score, again.Score)
+}
+
+func TestTrustCalculator_Calculate_ShiftingWeightChangesScoreByContributionDelta(t *testing.T) {
+	orgID := uuid.New()
+	agent := &domain.Agent{
+		ID:             uuid.New(),
+		OrganizationID: orgID,
+		Status:         domain.AgentStatusVerified,
+		CreatedAt:      time.Now().Add(-3 * 24 * time.Hour),
+		UpdatedAt:      time.Now(),
+	}
+
+	defaults := domain.DefaultTrustWeights()
+	shifted := defaults
+	// Move 0.05 of weight from the verification category to capability violations (1.0 without any)
+	shifted.Verification -= 0.05
+	shifted.Violations += 0.05
+
+	calculator, _ := newWeightedTestCalculator(orgID, nil)
+	base, err := calculator.Calculate(agent)
+	require.NoError(t, err)
+	calculator, _ = newWeightedTestCalculator(orgID, &shifted)
+	moved, err := calculator.Calculate(agent)
+	require.NoError(t, err)
+
+	verificationContribution := 0.0
+	for _, f := range base.Breakdown.Factors {
+		if f.Category == domain.TrustCategoryVerification {
+			verificationContribution += f.Contribution
+		}
+	}
+	// The verification factors keep their proportions, so they lose 0.05/0.85 of their contribution
+	expectedDelta := 0.05*moved.Factors.CapabilityViolations - verificationContribution*0.05/defaults.Verification
+	assert.InDelta(t, base.Score+expectedDelta, moved.Score, 1e-9)
+}
+
+func TestTrustCalculator_Calculate_LoadsOrganizationOnce(t *testing.T) {
+	orgID := uuid.New()
+	calculator, mockOrgRepo := newWeightedTestCalculator(orgID, nil)
+
+	_, err := calculator.Calculate(newDecayTestAgent(orgID, time.Now()))
+	require.NoError(t, err)
+
+	// Weights and the decay half-life come from the same organization lookup
+	mockOrgRepo.AssertNumberOfCalls(t, "GetByID", 1)
+}
+
+func TestTrustCalculator_TrustWeights_InvalidWeightsFallBackToDefaults(t *testing.T) {
+	orgID := uuid.New()
+	calculator, _ := newWeightedTestCalculator(orgID, &domain.TrustWeights{Verification: 0.5})
+
+	assert.Equal(t, domain.DefaultTrustWeights(), calculator.TrustWeights(orgID))
+}
+
+func TestCalculateKeyAge(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	created := now.Add(-30 * 24 * time.Hour)
+	expires := now.Add(60 * 24 * time.Hour)
+	expired := now.Add(-time.Hour)
+
+	assert.InDelta(t, 2.0/3.0, calculateKeyAge(&domain.Agent{KeyCreatedAt: &created, KeyExpiresAt: &expires}, now), 1e-9)
+	assert.Equal(t, 1.0, calculateKeyAge(&domain.Agent{KeyCreatedAt: &now, KeyExpiresAt: &expires}, now))
+	assert.Equal(t, 0.0, calculateKeyAge(&domain.Agent{KeyCreatedAt: &created, KeyExpiresAt: &expired}, now))
+	assert.Equal(t, 0.5, calculateKeyAge(&domain.Agent{}, now))
+}
+
+func TestTrustCalculator_CalculateMCPServers(t *testing.T) {
+	orgID := uuid.New()
+	verified := &domain.MCPServer{ID: uuid.New(), OrganizationID: orgID, Name: "files", Status: domain.MCPServerStatusVerified}
+	pending := &domain.MCPServer{ID: uuid.New(), OrganizationID: orgID, Name: "search", Status: domain.MCPServerStatusPending}
+	calculator := &TrustCalculator{mcpServerRepo: &trustCalcMCPServerRepository{servers: []*domain.MCPServer{verified, pending}}}
+
+	// Servers are matched by ID or name; an unregistered server counts as untrusted
+	agent := &domain.Agent{OrganizationID: orgID, TalksTo: []string{verified.ID.String(), "search", "unknown"}}
+	assert.InDelta(t, 1.5/3, calculator.calculateMCPServers(agent), 1e-9)
+
+	assert.Equal(t, 1.0, calculator.calculateMCPServers(&domain.Agent{OrganizationID: orgID}))
+}
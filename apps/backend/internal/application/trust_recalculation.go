@@ -0,0 +1,110 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/domain"
+)
+
+// DefaultTrustRecalculationBatchSize is how many agents are loaded per page while recalculating
+const DefaultTrustRecalculationBatchSize = 100
+
+// TrustRecalculationSummary reports the outcome of recalculating every agent in an organization
+type TrustRecalculationSummary struct {
+	OrganizationID uuid.UUID   `json:"organization_id"`
+	TotalAgents    int         `json:"total_agents"`
+	Recalculated   int         `json:"recalculated"`
+	Changed        int         `json:"changed"`
+	Failed         int         `json:"failed"`
+	FailedAgentIDs []uuid.UUID `json:"failed_agent_ids,omitempty"`
+	DurationMs     int64       `json:"duration_ms"`
+}
+
+// RecalculateOrganization recomputes the trust score of every agent in the organization, batchSize
+// agents at a time, so scores catch up after the trust weights change. Each score is stored like a
+// single recalculation; the database records the change in trust_score_history. An agent that fails
+// is counted and skipped. Progress is logged after every batch.
+func (c *TrustCalculator) RecalculateOrganization(ctx context.Context, orgID uuid.UUID, batchSize int) (*TrustRecalculationSummary, error) {
+	if batchSize <= 0 {
+		batchSize = DefaultTrustRecalculationBatchSize
+	}
+
+	started := time.Now()
+	summary := &TrustRecalculationSummary{OrganizationID: orgID}
+	defer func() { summary.DurationMs = time.Since(started).Milliseconds() }()
+
+	// Keyset paging keeps batches stable while scores are being written
+	var after *domain.AgentCursor
+	for {
+		if err := ctx.Err(); err != nil {
+			return summary, err
+		}
+
+		agents, total, err := c.agentRepo.GetByOrganizationPaginated(orgID, batchSize, 0, after)
+		if err != nil {
+			return summary, fmt.Errorf("failed to list agents: %w", err)
+		}
+		if after == nil {
+			summary.TotalAgents = total
+		}
+
+		for _, agent := range agents {
+			previous := agent.TrustScore
+			score, err := c.recalculate(ctx, agent)
+			if err != nil {
+				slog.Warn("trust score recalculation failed", "org_id", orgID, "agent_id", agent.ID, "error", err)
+				summary.Failed++
+				summary.FailedAgentIDs = append(summary.FailedAgentIDs, agent.ID)
+				continue
+			}
+
+			summary.Recalculated++
+			if score.Score != previous {
+				summary.Changed++
+			}
+		}
+
+		slog.Info("trust score recalculation progress",
+			"org_id", orgID,
+			"processed", summary.Recalculated+summary.Failed,
+			"total", summary.TotalAgents,
+			"failed", summary.Failed,
+		)
+
+		if len(agents) < batchSize {
+			return summary, nil
+		}
+		last := agents[len(agents)-1]
+		after = &domain.AgentCursor{CreatedAt: last.CreatedAt, ID: last.ID}
+	}
+}
+
+// RecalculateAll recalculates the trust scores of every active organization's agents
+func (c *TrustCalculator) RecalculateAll(ctx context.Context, batchSize int) ([]*TrustRecalculationSummary, error) {
+	if c.orgRepo == nil {
+		return nil, errors.New("trust calculator has no organization repository")
+	}
+
+	orgs, err := c.orgRepo.ListActive()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list organizations: %w", err)
+	}
+
+	var summaries []*TrustRecalculationSummary
+	var errs []error
+	for _, org := range orgs {
+		summary, err := c.RecalculateOrganization(ctx, org.ID, batchSize)
+		if summary != nil {
+			summaries = append(summaries, summary)
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("organization %s: %w", org.ID, err))
+		}
+	}
+	return summaries, errors.Join(errs...)
+}
@@ -0,0 +1,139 @@
+package application
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// newRecalculationTestCalculator returns a calculator whose factor lookups find no capabilities or alerts
+func newRecalculationTestCalculator() (*TrustCalculator, *AgentServiceMockTrustScoreRepository, *TrustCalcMockAgentRepository) {
+	mockTrustRepo := new(AgentServiceMockTrustScoreRepository)
+	mockAgentRepo := new(TrustCalcMockAgentRepository)
+	mockCapabilityRepo := new(MockCapabilityRepository)
+	mockAlertRepo := new(TrustCalcMockAlertRepository)
+
+	mockCapabilityRepo.On("GetActiveCapabilitiesByAgentID", mock.Anything).Return([]*domain.AgentCapability{}, nil).Maybe()
+	mockCapabilityRepo.On("GetViolationsByAgentID", mock.Anything, 100, 0).Return([]*domain.CapabilityViolation{}, 0, nil).Maybe()
+	mockAlertRepo.On("GetUnacknowledgedByResourceID", mock.Anything).Return([]*domain.Alert{}, nil).Maybe()
+	mockAlertRepo.On("GetByResourceID", mock.Anything, 100, 0).Return([]*domain.Alert{}, nil).Maybe()
+
+	calculator := NewTrustCalculator(mockTrustRepo, new(MockAPIKeyRepository), new(AgentServiceMockAuditLogRepository),
+		mockCapabilityRepo, mockAgentRepo, mockAlertRepo)
+	return calculator, mockTrustRepo, mockAgentRepo
+}
+
+func createTestAgentsForRecalculation(orgID uuid.UUID, count int) []*domain.Agent {
+	now := time.Now()
+	agents := make([]*domain.Agent, count)
+	for i := range agents {
+		agents[i] = &domain.Agent{
+			ID:             uuid.New(),
+			OrganizationID: orgID,
+			Status:         domain.AgentStatusVerified,
+			TrustScore:     0.99, // Stale score from before the weights changed
+			CreatedAt:      now.Add(-time.Duration(i+1) * time.Hour),
+			UpdatedAt:      now,
+		}
+	}
+	return agents
+}
+
+func TestTrustCalculator_RecalculateOrganization_UpdatesEveryAgentInBatches(t *testing.T) {
+	orgID := uuid.New()
+	agents := createTestAgentsForRecalculation(orgID, 3)
+	calculator, mockTrustRepo, mockAgentRepo := newRecalculationTestCalculator()
+
+	// Two agents per batch; the second batch continues after the last agent of the first
+	mockAgentRepo.On("GetByOrganizationPaginated", orgID, 2, 0, (*domain.AgentCursor)(nil)).Return(agents[:2], 3, nil).Once()
+	mockAgentRepo.On("GetByOrganizationPaginated", orgID, 2, 0, &domain.AgentCursor{CreatedAt: agents[1].CreatedAt, ID: agents[1].ID}).
+		Return(agents[2:], 3, nil).Once()
+	for _, agent := range agents {
+		id := agent.ID
+		mockTrustRepo.On("Create", mock.MatchedBy(func(score *domain.TrustScore) bool { return score.AgentID == id })).Return(nil).Once()
+		mockAgentRepo.On("UpdateTrustScore", id, mock.AnythingOfType("float64")).Return(nil).Once()
+	}
+
+	summary, err := calculator.RecalculateOrganization(context.Background(), orgID, 2)
+
+	require.NoError(t, err)
+	assert.Equal(t, orgID, summary.OrganizationID)
+	assert.Equal(t, 3, summary.TotalAgents)
+	assert.Equal(t, 3, summary.Recalculated)
+	assert.Equal(t, 3, summary.Changed)
+	assert.Zero(t, summary.Failed)
+	mockAgentRepo.AssertExpectations(t)
+	mockTrustRepo.AssertExpectations(t)
+}
+
+func TestTrustCalculator_RecalculateOrganization_SkipsFailedAgents(t *testing.T) {
+	orgID := uuid.New()
+	agents := createTestAgentsForRecalculation(orgID, 3)
+	calculator, mockTrustRepo, mockAgentRepo := newRecalculationTestCalculator()
+
+	mockAgentRepo.On("GetByOrganizationPaginated", orgID, DefaultTrustRecalculationBatchSize, 0, (*domain.AgentCursor)(nil)).Return(agents, 3, nil).Once()
+	mockTrustRepo.On("Create", mock.Anything).Return(nil)
+	mockAgentRepo.On("UpdateTrustScore", agents[0].ID, mock.AnythingOfType("float64")).Return(nil).Once()
+	mockAgentRepo.On("UpdateTrustScore", agents[1].ID, mock.AnythingOfType("float64")).Return(errors.New("connection reset")).Once()
+	mockAgentRepo.On("UpdateTrustScore", agents[2].ID, mock.AnythingOfType("float64")).Return(nil).Once()
+
+	summary, err := calculator.RecalculateOrganization(context.Background(), orgID, 0)
+
+	require.NoError(t, err)
+	assert.Equal(t, 2, summary.Recalculated)
+	assert.Equal(t, 1, summary.Failed)
+	assert.Equal(t, []uuid.UUID{agents[1].ID}, summary.FailedAgentIDs)
+	mockAgentRepo.AssertExpectations(t)
+}
+
+func TestTrustCalculator_RecalculateOrganization_QueuesTrustScoreDropWebhook(t *testing.T) {
+	orgID := uuid.New()
+	agents := createTestAgentsForRecalculation(orgID, 2)
+	agents[0].Status = domain.AgentStatusSuspended // Loses the verification factor
+	agents[1].TrustScore = 0                       // No previous score to drop from
+	calculator, mockTrustRepo, mockAgentRepo := newRecalculationTestCalculator()
+	outbox := &inMemoryOutboxRepository{}
+	calculator.outboxRepo = outbox
+
+	mockAgentRepo.On("GetByOrganizationPaginated", orgID, DefaultTrustRecalculationBatchSize, 0, (*domain.AgentCursor)(nil)).Return(agents, 2, nil).Once()
+	mockTrustRepo.On("Create", mock.Anything).Return(nil)
+	mockAgentRepo.On("UpdateTrustScore", mock.Anything, mock.AnythingOfType("float64")).Return(nil)
+
+	_, err := calculator.RecalculateOrganization(context.Background(), orgID, 0)
+	require.NoError(t, err)
+
+	require.Len(t, outbox.messages, 1)
+	message := outbox.messages[0]
+	assert.Equal(t, domain.OutboxTopicWebhook, message.Topic)
+	assert.Equal(t, orgID, message.OrganizationID)
+
+	var event domain.OutboxWebhookEvent
+	require.NoError(t, json.Unmarshal(message.Payload, &event))
+	assert.Equal(t, domain.WebhookEventTrustScoreDrop, event.Event)
+	assert.Equal(t, domain.WebhookResourceAgent, event.ResourceType)
+
+	var data domain.TrustScoreDropWebhookData
+	require.NoError(t, json.Unmarshal(event.Data, &data))
+	assert.Equal(t, agents[0].ID, data.AgentID)
+	assert.Equal(t, 0.99, data.PreviousTrustScore)
+	assert.Less(t, data.TrustScore, data.PreviousTrustScore)
+	assert.Equal(t, domain.AlertSeverityCritical, data.Severity)
+}
+
+func TestTrustCalculator_RecalculateOrganization_ListFailure(t *testing.T) {
+	orgID := uuid.New()
+	calculator, _, mockAgentRepo := newRecalculationTestCalculator()
+	mockAgentRepo.On("GetByOrganizationPaginated", orgID, 10, 0, (*domain.AgentCursor)(nil)).Return(nil, 0, errors.New("database unavailable"))
+
+	_, err := calculator.RecalculateOrganization(context.Background(), orgID, 10)
+
+	assert.ErrorContains(t, err, "failed to list agents")
+}
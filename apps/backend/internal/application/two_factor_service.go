@@ -0,0 +1,221 @@
+package application
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/crypto"
+	"github.com/opena2a/identity/backend/internal/domain"
+	"github.com/opena2a/identity/backend/internal/infrastructure/auth"
+	"github.com/opena2a/identity/backend/internal/infrastructure/logging"
+)
+
+var (
+	// ErrTwoFactorRequired is returned when a user with two-factor enabled logs in without a code
+	ErrTwoFactorRequired = errors.New("two-factor authentication code required")
+	// ErrInvalidTwoFactorCode is returned for a wrong, expired or already used code
+	ErrInvalidTwoFactorCode = errors.New("invalid two-factor authentication code")
+	// ErrTwoFactorAlreadyEnabled is returned when enrolling a user who already has two-factor enabled
+	ErrTwoFactorAlreadyEnabled = errors.New("two-factor authentication is already enabled")
+	// ErrTwoFactorNotEnrolled is returned when verifying without a pending enrollment
+	ErrTwoFactorNotEnrolled = errors.New("two-factor authentication enrollment not started")
+)
+
+const (
+	// TwoFactorIssuer is the account issuer shown by authenticator apps
+	TwoFactorIssuer = "Agent Identity Management"
+	// twoFactorBackupCodeCount is how many single-use backup codes are issued when 2FA is enabled
+	twoFactorBackupCodeCount = 10
+)
+
+// TwoFactorEnrollment is returned when a user starts enrolling: the secret to add to an
+// authenticator app, either typed in or scanned from the otpauth:// URI as a QR code
+type TwoFactorEnrollment struct {
+	Secret          string `json:"secret"`
+	ProvisioningURI string `json:"provisioningUri"`
+}
+
+// TwoFactorService handles TOTP two-factor enrollment and login verification
+type TwoFactorService struct {
+	twoFactorRepo domain.TwoFactorRepository
+	keyVault      *crypto.KeyVault
+}
+
+// NewTwoFactorService creates a new two-factor service. TOTP secrets are encrypted with keyVault.
+func NewTwoFactorService(twoFactorRepo domain.TwoFactorRepository, keyVault *crypto.KeyVault) *TwoFactorService {
+	return &TwoFactorService{
+		twoFactorRepo: twoFactorRepo,
+		keyVault:      keyVault,
+	}
+}
+
+// Enroll generates a new TOTP secret for the user; email labels the account in the authenticator app.
+// Two-factor stays disabled until Verify confirms a code from the app, and enrolling again before
+// that replaces the pending secret.
+func (s *TwoFactorService) Enroll(ctx context.Context, userID uuid.UUID, email string, now time.Time) (*TwoFactorEnrollment, error) {
+	existing, err := s.twoFactorRepo.GetByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil && existing.Enabled {
+		return nil, ErrTwoFactorAlreadyEnabled
+	}
+
+	secret, err := auth.GenerateTOTPSecret()
+	if err != nil {
+		return nil, err
+	}
+	encryptedSecret, err := s.keyVault.EncryptPrivateKey(secret)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encrypt TOTP secret: %w", err)
+	}
+
+	createdAt := now
+	if existing != nil {
+		createdAt = existing.CreatedAt
+	}
+	if err := s.twoFactorRepo.Upsert(&domain.UserTwoFactor{
+		UserID:          userID,
+		EncryptedSecret: encryptedSecret,
+		CreatedAt:       createdAt,
+		UpdatedAt:       now,
+	}); err != nil {
+		return nil, err
+	}
+
+	return &TwoFactorEnrollment{
+		Secret:          secret,
+		ProvisioningURI: auth.TOTPProvisioningURI(TwoFactorIssuer, email, secret),
+	}, nil
+}
+
+// Verify confirms a pending enrollment with a code from the authenticator app and enables
+// two-factor for the user. The returned backup codes are shown once and only stored hashed.
+func (s *TwoFactorService) Verify(ctx context.Context, userID uuid.UUID, code string, now time.Time) ([]string, error) {
+	twoFactor, err := s.twoFactorRepo.GetByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+	if twoFactor == nil {
+		return nil, ErrTwoFactorNotEnrolled
+	}
+	if twoFactor.Enabled {
+		return nil, ErrTwoFactorAlreadyEnabled
+	}
+
+	step, ok, err := s.validateCode(twoFactor, code, now)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, ErrInvalidTwoFactorCode
+	}
+
+	backupCodes, backupCodeHashes, err := generateBackupCodes(twoFactorBackupCodeCount)
+	if err != nil {
+		return nil, err
+	}
+
+	twoFactor.Enabled = true
+	twoFactor.EnabledAt = &now
+	twoFactor.BackupCodeHashes = backupCodeHashes
+	twoFactor.LastUsedStep = step
+	twoFactor.UpdatedAt = now
+	if err := s.twoFactorRepo.Upsert(twoFactor); err != nil {
+		return nil, err
+	}
+
+	logging.FromContext(ctx).Info("two-factor authentication enabled", "user_id", userID)
+	return backupCodes, nil
+}
+
+// CheckLogin enforces two-factor for a user whose password was already verified. It returns nil if
+// the user has no two-factor enabled or code is valid, ErrTwoFactorRequired if code is empty and
+// ErrInvalidTwoFactorCode otherwise. code may be a TOTP code or an unused backup code; TOTP codes
+// are accepted once and backup codes are consumed.
+func (s *TwoFactorService) CheckLogin(ctx context.Context, userID uuid.UUID, code string, now time.Time) error {
+	if s == nil {
+		return nil
+	}
+	twoFactor, err := s.twoFactorRepo.GetByUserID(userID)
+	if err != nil {
+		return err
+	}
+	if twoFactor == nil || !twoFactor.Enabled {
+		return nil
+	}
+
+	code = strings.TrimSpace(code)
+	if code == "" {
+		return ErrTwoFactorRequired
+	}
+
+	step, ok, err := s.validateCode(twoFactor, code, now)
+	if err != nil {
+		return err
+	}
+	if ok {
+		if step <= twoFactor.LastUsedStep {
+			return ErrInvalidTwoFactorCode // Replay of an already used code
+		}
+		twoFactor.LastUsedStep = step
+		twoFactor.UpdatedAt = now
+		return s.twoFactorRepo.Upsert(twoFactor)
+	}
+
+	hash := hashBackupCode(code)
+	for i, candidate := range twoFactor.BackupCodeHashes {
+		if subtle.ConstantTimeCompare([]byte(candidate), []byte(hash)) != 1 {
+			continue
+		}
+		remaining := append([]string{}, twoFactor.BackupCodeHashes[:i]...)
+		twoFactor.BackupCodeHashes = append(remaining, twoFactor.BackupCodeHashes[i+1:]...)
+		twoFactor.UpdatedAt = now
+		if err := s.twoFactorRepo.Upsert(twoFactor); err != nil {
+			return err
+		}
+		logging.FromContext(ctx).Info("two-factor backup code used",
+			"user_id", userID, "remaining_backup_codes", len(twoFactor.BackupCodeHashes))
+		return nil
+	}
+
+	return ErrInvalidTwoFactorCode
+}
+
+func (s *TwoFactorService) validateCode(twoFactor *domain.UserTwoFactor, code string, now time.Time) (int64, bool, error) {
+	secret, err := s.keyVault.DecryptPrivateKey(twoFactor.EncryptedSecret)
+	if err != nil {
+		return 0, false, fmt.Errorf("failed to decrypt TOTP secret: %w", err)
+	}
+	return auth.ValidateTOTPCode(secret, code, now)
+}
+
+// generateBackupCodes returns count random codes formatted as xxxxx-xxxxx, and their hashes
+func generateBackupCodes(count int) (codes []string, hashes []string, err error) {
+	for i := 0; i < count; i++ {
+		raw := make([]byte, 5)
+		if _, err := rand.Read(raw); err != nil {
+			return nil, nil, fmt.Errorf("failed to generate backup code: %w", err)
+		}
+		encoded := hex.EncodeToString(raw)
+		code := encoded[:5] + "-" + encoded[5:]
+		codes = append(codes, code)
+		hashes = append(hashes, hashBackupCode(code))
+	}
+	return codes, hashes, nil
+}
+
+// hashBackupCode hashes a backup code, ignoring case and the separator
+func hashBackupCode(code string) string {
+	normalized := strings.ToLower(strings.ReplaceAll(strings.TrimSpace(code), "-", ""))
+	sum := sha256.Sum256([]byte(normalized))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,152 @@
+package application
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/crypto"
+	"github.com/opena2a/identity/backend/internal/domain"
+	"github.com/opena2a/identity/backend/internal/infrastructure/auth"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// inMemoryTwoFactorRepository is a stateful fake so enrollment survives across calls
+type inMemoryTwoFactorRepository struct {
+	enrollments map[uuid.UUID]*domain.UserTwoFactor
+}
+
+func newInMemoryTwoFactorRepository() *inMemoryTwoFactorRepository {
+	return &inMemoryTwoFactorRepository{enrollments: map[uuid.UUID]*domain.UserTwoFactor{}}
+}
+
+func (r *inMemoryTwoFactorRepository) Upsert(twoFactor *domain.UserTwoFactor) error {
+	stored := *twoFactor
+	stored.BackupCodeHashes = append([]string{}, twoFactor.BackupCodeHashes...)
+	r.enrollments[twoFactor.UserID] = &stored
+	return nil
+}
+
+func (r *inMemoryTwoFactorRepository) GetByUserID(userID uuid.UUID) (*domain.UserTwoFactor, error) {
+	twoFactor, ok := r.enrollments[userID]
+	if !ok {
+		return nil, nil
+	}
+	copied := *twoFactor
+	copied.BackupCodeHashes = append([]string{}, twoFactor.BackupCodeHashes...)
+	return &copied, nil
+}
+
+func newTestTwoFactorService(t *testing.T) (*TwoFactorService, *inMemoryTwoFactorRepository) {
+	keyVault, err := crypto.NewKeyVault("MDEyMzQ1Njc4OTAxMjM0NTY3ODkwMTIzNDU2Nzg5MDE=")
+	require.NoError(t, err)
+	repo := newInMemoryTwoFactorRepository()
+	return NewTwoFactorService(repo, keyVault), repo
+}
+
+// enrollAndEnable enrolls the user, confirms the enrollment and returns the secret and backup codes
+func enrollAndEnable(t *testing.T, service *TwoFactorService, userID uuid.UUID, now time.Time) (string, []string) {
+	enrollment, err := service.Enroll(context.Background(), userID, "user@example.com", now)
+	require.NoError(t, err)
+	code, err := auth.GenerateTOTPCode(enrollment.Secret, now)
+	require.NoError(t, err)
+	backupCodes, err := service.Verify(context.Background(), userID, code, now)
+	require.NoError(t, err)
+	return enrollment.Secret, backupCodes
+}
+
+func TestTwoFactorService_Enroll(t *testing.T) {
+	service, repo := newTestTwoFactorService(t)
+	userID := uuid.New()
+	now := time.Now()
+
+	enrollment, err := service.Enroll(context.Background(), userID, "user@example.com", now)
+	require.NoError(t, err)
+	assert.NotEmpty(t, enrollment.Secret)
+	assert.Contains(t, enrollment.ProvisioningURI, "secret="+enrollment.Secret)
+
+	stored := repo.enrollments[userID]
+	require.NotNil(t, stored)
+	assert.False(t, stored.Enabled, "2FA is not enabled until a code is verified")
+	assert.NotContains(t, stored.EncryptedSecret, enrollment.Secret, "secret must be stored encrypted")
+
+	// A pending enrollment does not affect login yet
+	assert.NoError(t, service.CheckLogin(context.Background(), userID, "", now))
+
+	// A wrong code does not enable 2FA
+	_, err = service.Verify(context.Background(), userID, "000000", now)
+	assert.ErrorIs(t, err, ErrInvalidTwoFactorCode)
+
+	code, err := auth.GenerateTOTPCode(enrollment.Secret, now)
+	require.NoError(t, err)
+	backupCodes, err := service.Verify(context.Background(), userID, code, now)
+	require.NoError(t, err)
+	assert.Len(t, backupCodes, twoFactorBackupCodeCount)
+	assert.True(t, repo.enrollments[userID].Enabled)
+
+	_, err = service.Enroll(context.Background(), userID, "user@example.com", now)
+	assert.ErrorIs(t, err, ErrTwoFactorAlreadyEnabled)
+}
+
+func TestTwoFactorService_VerifyWithoutEnrollment(t *testing.T) {
+	service, _ := newTestTwoFactorService(t)
+
+	_, err := service.Verify(context.Background(), uuid.New(), "123456", time.Now())
+	assert.ErrorIs(t, err, ErrTwoFactorNotEnrolled)
+}
+
+func TestTwoFactorService_CheckLogin_ValidCode(t *testing.T) {
+	service, _ := newTestTwoFactorService(t)
+	userID := uuid.New()
+	enrolledAt := time.Now()
+	secret, _ := enrollAndEnable(t, service, userID, enrolledAt)
+
+	assert.ErrorIs(t, service.CheckLogin(context.Background(), userID, "", enrolledAt), ErrTwoFactorRequired)
+
+	loginAt := enrolledAt.Add(5 * time.Minute)
+	code, err := auth.GenerateTOTPCode(secret, loginAt)
+	require.NoError(t, err)
+	assert.NoError(t, service.CheckLogin(context.Background(), userID, code, loginAt))
+
+	// The same code cannot be replayed
+	assert.ErrorIs(t, service.CheckLogin(context.Background(), userID, code, loginAt), ErrInvalidTwoFactorCode)
+}
+
+func TestTwoFactorService_CheckLogin_InvalidCode(t *testing.T) {
+	service, _ := newTestTwoFactorService(t)
+	userID := uuid.New()
+	now := time.Now()
+	secret, _ := enrollAndEnable(t, service, userID, now)
+
+	loginAt := now.Add(5 * time.Minute)
+	assert.ErrorIs(t, service.CheckLogin(context.Background(), userID, "000000", loginAt), ErrInvalidTwoFactorCode)
+
+	// A code from long ago is rejected too
+	staleCode, err := auth.GenerateTOTPCode(secret, now.Add(-10*time.Minute))
+	require.NoError(t, err)
+	assert.ErrorIs(t, service.CheckLogin(context.Background(), userID, staleCode, loginAt), ErrInvalidTwoFactorCode)
+}
+
+func TestTwoFactorService_CheckLogin_BackupCode(t *testing.T) {
+	service, repo := newTestTwoFactorService(t)
+	userID := uuid.New()
+	now := time.Now()
+	_, backupCodes := enrollAndEnable(t, service, userID, now)
+
+	assert.NoError(t, service.CheckLogin(context.Background(), userID, backupCodes[0], now))
+	assert.Len(t, repo.enrollments[userID].BackupCodeHashes, twoFactorBackupCodeCount-1)
+
+	// Backup codes are single use
+	assert.ErrorIs(t, service.CheckLogin(context.Background(), userID, backupCodes[0], now), ErrInvalidTwoFactorCode)
+}
+
+func TestTwoFactorService_CheckLogin_NotEnrolled(t *testing.T) {
+	service, _ := newTestTwoFactorService(t)
+
+	assert.NoError(t, service.CheckLogin(context.Background(), uuid.New(), "", time.Now()))
+
+	var disabled *TwoFactorService
+	assert.NoError(t, disabled.CheckLogin(context.Background(), uuid.New(), "", time.Now()))
+}
@@ -0,0 +1,106 @@
+package application
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/infrastructure/logging"
+)
+
+// Key prefixes for cached verification decisions and the agent revocation list
+const (
+	verificationDecisionPrefix = "verify_decision:"
+	verificationRevokedPrefix  = "verify_decision:revoked:"
+)
+
+// VerificationDecisionCacheTTLFromEnv reads VERIFICATION_DECISION_CACHE_TTL (Go duration).
+// Decision caching is off unless it is set to a positive duration.
+func VerificationDecisionCacheTTLFromEnv() time.Duration {
+	if value, err := time.ParseDuration(os.Getenv("VERIFICATION_DECISION_CACHE_TTL")); err == nil && value > 0 {
+		return value
+	}
+	return 0
+}
+
+// VerificationDecisionStore stores JSON-encoded values with a TTL.
+// *cache.RedisCache implements it so decisions and revocations are shared across server instances.
+type VerificationDecisionStore interface {
+	Get(ctx context.Context, key string, dest interface{}) error
+	Set(ctx context.Context, key string, value interface{}, ttl time.Duration) error
+	Exists(ctx context.Context, key string) (bool, error)
+}
+
+// VerificationDecisionCache remembers that VerifyAction allowed an (agent, action, resource) for
+// a short TTL, so repeat calls skip capability and policy evaluation. Only full allows are cached;
+// denials and alert-only allows are always re-evaluated.
+//
+// InvalidateAgent puts the agent on a revocation list for one TTL: while it is listed its cached
+// decisions are ignored and no new ones are stored, and once the entry expires every decision
+// cached before it has expired too. Suspension, compromise and capability revocation all
+// invalidate the agent, so they take effect on the very next verification.
+//
+// A nil *VerificationDecisionCache is valid and caches nothing.
+type VerificationDecisionCache struct {
+	store VerificationDecisionStore
+	ttl   time.Duration
+}
+
+// NewVerificationDecisionCache creates a decision cache. It returns nil, which disables decision
+// caching, when store is nil or ttl <= 0.
+func NewVerificationDecisionCache(store VerificationDecisionStore, ttl time.Duration) *VerificationDecisionCache {
+	if store == nil || ttl <= 0 {
+		return nil
+	}
+	return &VerificationDecisionCache{store: store, ttl: ttl}
+}
+
+// Allowed returns the reason of a cached allow for the action, if there is one and the agent is
+// not on the revocation list
+func (c *VerificationDecisionCache) Allowed(ctx context.Context, agentID uuid.UUID, actionType, resource string) (string, bool) {
+	if c == nil || c.revoked(ctx, agentID) {
+		return "", false
+	}
+
+	var reason string
+	if err := c.store.Get(ctx, verificationDecisionKey(agentID, actionType, resource), &reason); err != nil {
+		return "", false
+	}
+	return reason, true
+}
+
+// RememberAllow caches an allow decision, unless the agent is on the revocation list
+func (c *VerificationDecisionCache) RememberAllow(ctx context.Context, agentID uuid.UUID, actionType, resource, reason string) {
+	if c == nil || c.revoked(ctx, agentID) {
+		return
+	}
+	if err := c.store.Set(ctx, verificationDecisionKey(agentID, actionType, resource), reason, c.ttl); err != nil {
+		logging.FromContext(ctx).Debug("failed to cache verification decision", "agent_id", agentID, "error", err)
+	}
+}
+
+// InvalidateAgent puts the agent on the revocation list so its cached decisions are bypassed
+func (c *VerificationDecisionCache) InvalidateAgent(ctx context.Context, agentID uuid.UUID) {
+	if c == nil {
+		return
+	}
+	if err := c.store.Set(ctx, verificationRevokedPrefix+agentID.String(), true, c.ttl); err != nil {
+		logging.FromContext(ctx).Warn("failed to revoke cached verification decisions", "agent_id", agentID, "error", err)
+	}
+}
+
+// revoked reports whether the agent is on the revocation list. A store error counts as revoked,
+// so decisions are only served while revocations can be seen.
+func (c *VerificationDecisionCache) revoked(ctx context.Context, agentID uuid.UUID) bool {
+	listed, err := c.store.Exists(ctx, verificationRevokedPrefix+agentID.String())
+	return err != nil || listed
+}
+
+// verificationDecisionKey hashes the action and resource, which can be arbitrarily long
+func verificationDecisionKey(agentID uuid.UUID, actionType, resource string) string {
+	sum := sha256.Sum256([]byte(actionType + "\x00" + resource))
+	return verificationDecisionPrefix + agentID.String() + ":" + hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,136 @@
+package application
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// newDecisionCachedVerifyActionService builds an AgentService that caches allow decisions and
+// whose agent repository invalidates them, as initServices wires it
+func newDecisionCachedVerifyActionService(decisions *VerificationDecisionCache, agent *domain.Agent) (*AgentService, *MockAgentRepository, *MockCapabilityRepository) {
+	mockAgentRepo := new(MockAgentRepository)
+	mockCapabilityRepo := new(MockCapabilityRepository)
+	mockPolicyRepo := new(AgentServiceMockSecurityPolicyRepository)
+	mockAlertRepo := new(MockAlertRepository)
+	mockPolicyRepo.On("GetActiveByOrganization", agent.OrganizationID).Return([]*domain.SecurityPolicy{}, nil).Maybe()
+	mockPolicyRepo.On("GetByType", agent.OrganizationID, mock.Anything).Return([]*domain.SecurityPolicy{}, nil).Maybe()
+
+	service := &AgentService{
+		agentRepo:        NewInvalidatingAgentRepository(mockAgentRepo, decisions),
+		capabilityRepo:   mockCapabilityRepo,
+		policyService:    &SecurityPolicyService{policyRepo: mockPolicyRepo, alertRepo: mockAlertRepo},
+		alertRepo:        mockAlertRepo,
+		decisionCache:    decisions,
+		cacheInvalidator: decisions,
+	}
+	return service, mockAgentRepo, mockCapabilityRepo
+}
+
+func TestVerificationDecisionCache_DisabledByDefault(t *testing.T) {
+	t.Setenv("VERIFICATION_DECISION_CACHE_TTL", "")
+	assert.Zero(t, VerificationDecisionCacheTTLFromEnv())
+	assert.Nil(t, NewVerificationDecisionCache(newFakeLookupStore(), VerificationDecisionCacheTTLFromEnv()))
+	assert.Nil(t, NewVerificationDecisionCache(nil, time.Minute))
+
+	t.Setenv("VERIFICATION_DECISION_CACHE_TTL", "5s")
+	assert.Equal(t, 5*time.Second, VerificationDecisionCacheTTLFromEnv())
+
+	var decisions *VerificationDecisionCache
+	decisions.RememberAllow(context.Background(), uuid.New(), "file:read", "/test.txt", "allowed")
+	_, ok := decisions.Allowed(context.Background(), uuid.New(), "file:read", "/test.txt")
+	assert.False(t, ok)
+}
+
+func TestAgentService_VerifyAction_CachedAllowHonoredWithinTTL(t *testing.T) {
+	decisions := NewVerificationDecisionCache(newFakeLookupStore(), time.Minute)
+	agent := createTestAgentForService()
+	service, mockAgentRepo, mockCapabilityRepo := newDecisionCachedVerifyActionService(decisions, agent)
+
+	grant := &domain.AgentCapability{ID: uuid.New(), AgentID: agent.ID, CapabilityType: "file:read", GrantedAt: time.Now()}
+	mockAgentRepo.On("GetByID", agent.ID).Return(agent, nil).Once()
+	mockCapabilityRepo.On("GetActiveCapabilitiesByAgentID", agent.ID).Return([]*domain.AgentCapability{grant}, nil).Once()
+
+	ctx := context.Background()
+	allowed, reason, _, err := service.VerifyAction(ctx, agent.ID, "file:read", "/test.txt", nil)
+	require.NoError(t, err)
+	require.True(t, allowed)
+
+	// Served from the cache: the repositories are not consulted again
+	for i := 0; i < 3; i++ {
+		cachedAllowed, cachedReason, auditID, err := service.VerifyAction(ctx, agent.ID, "file:read", "/test.txt", nil)
+		require.NoError(t, err)
+		assert.True(t, cachedAllowed)
+		assert.Equal(t, reason, cachedReason)
+		assert.NotEqual(t, uuid.Nil, auditID)
+	}
+	mockAgentRepo.AssertExpectations(t)
+	mockCapabilityRepo.AssertExpectations(t)
+
+	// A different resource is a different decision
+	mockAgentRepo.On("GetByID", agent.ID).Return(agent, nil).Once()
+	mockCapabilityRepo.On("GetActiveCapabilitiesByAgentID", agent.ID).Return([]*domain.AgentCapability{grant}, nil).Once()
+	allowed, _, _, err = service.VerifyAction(ctx, agent.ID, "file:read", "/other.txt", nil)
+	require.NoError(t, err)
+	assert.True(t, allowed)
+	mockAgentRepo.AssertExpectations(t)
+}
+
+func TestAgentService_VerifyAction_CompromiseBypassesCachedAllow(t *testing.T) {
+	decisions := NewVerificationDecisionCache(newFakeLookupStore(), time.Hour)
+	agent := createTestAgentForService()
+	service, mockAgentRepo, mockCapabilityRepo := newDecisionCachedVerifyActionService(decisions, agent)
+
+	grant := &domain.AgentCapability{ID: uuid.New(), AgentID: agent.ID, CapabilityType: "file:read", GrantedAt: time.Now()}
+	mockAgentRepo.On("GetByID", agent.ID).Return(agent, nil)
+	mockAgentRepo.On("MarkAsCompromised", agent.ID).Return(nil).Run(func(mock.Arguments) {
+		agent.IsCompromised = true
+	})
+	mockCapabilityRepo.On("GetActiveCapabilitiesByAgentID", agent.ID).Return([]*domain.AgentCapability{grant}, nil)
+
+	ctx := context.Background()
+	allowed, _, _, err := service.VerifyAction(ctx, agent.ID, "file:read", "/test.txt", nil)
+	require.NoError(t, err)
+	require.True(t, allowed)
+
+	// The compromise flag puts the agent on the revocation list, so the hour-long allow is bypassed
+	require.NoError(t, service.agentRepo.MarkAsCompromised(agent.ID))
+
+	allowed, reason, _, err := service.VerifyAction(ctx, agent.ID, "file:read", "/test.txt", nil)
+	require.NoError(t, err)
+	assert.False(t, allowed)
+	assert.Contains(t, reason, "compromised")
+	mockAgentRepo.AssertNumberOfCalls(t, "GetByID", 2)
+}
+
+func TestAgentService_SuspendAgent_BypassesCachedAllow(t *testing.T) {
+	decisions := NewVerificationDecisionCache(newFakeLookupStore(), time.Hour)
+	agent := createTestAgentForService()
+	service, mockAgentRepo, mockCapabilityRepo := newDecisionCachedVerifyActionService(decisions, agent)
+
+	grant := &domain.AgentCapability{ID: uuid.New(), AgentID: agent.ID, CapabilityType: "file:read", GrantedAt: time.Now()}
+	mockAgentRepo.On("GetByID", agent.ID).Return(agent, nil)
+	mockAgentRepo.On("UpdateStatus", agent, mock.Anything).Return(nil)
+	mockCapabilityRepo.On("GetActiveCapabilitiesByAgentID", agent.ID).Return([]*domain.AgentCapability{grant}, nil)
+	mockTrustCalc := new(AgentServiceMockTrustScoreCalculator)
+	mockTrustCalc.On("Calculate", agent).Return(nil, assert.AnError)
+	service.trustCalc = mockTrustCalc
+
+	ctx := context.Background()
+	allowed, _, _, err := service.VerifyAction(ctx, agent.ID, "file:read", "/test.txt", nil)
+	require.NoError(t, err)
+	require.True(t, allowed)
+
+	require.NoError(t, service.SuspendAgent(ctx, agent.ID))
+
+	allowed, reason, _, err := service.VerifyAction(ctx, agent.ID, "file:read", "/test.txt", nil)
+	require.NoError(t, err)
+	assert.False(t, allowed)
+	assert.Contains(t, reason, "not verified")
+}
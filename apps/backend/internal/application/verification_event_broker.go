@@ -0,0 +1,67 @@
+package application
+
+import (
+	"sync"
+
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/domain"
+)
+
+// verificationEventSubscriberBuffer is how many events a slow subscriber may fall behind by
+// before further events are dropped for it
+const verificationEventSubscriberBuffer = 64
+
+// VerificationEventBroker is an in-process pub/sub for newly created verification events,
+// scoped by organization. Publishing never blocks: a subscriber whose buffer is full misses
+// the event rather than stalling verifications. Events created on other server instances are
+// not seen.
+type VerificationEventBroker struct {
+	mu          sync.RWMutex
+	subscribers map[uuid.UUID]map[chan *domain.VerificationEvent]struct{}
+}
+
+// NewVerificationEventBroker creates an empty broker
+func NewVerificationEventBroker() *VerificationEventBroker {
+	return &VerificationEventBroker{
+		subscribers: make(map[uuid.UUID]map[chan *domain.VerificationEvent]struct{}),
+	}
+}
+
+// Subscribe returns a channel receiving the organization's new events and a function that
+// unsubscribes and closes the channel. The function must be called once the caller is done.
+func (b *VerificationEventBroker) Subscribe(orgID uuid.UUID) (<-chan *domain.VerificationEvent, func()) {
+	ch := make(chan *domain.VerificationEvent, verificationEventSubscriberBuffer)
+
+	b.mu.Lock()
+	if b.subscribers[orgID] == nil {
+		b.subscribers[orgID] = make(map[chan *domain.VerificationEvent]struct{})
+	}
+	b.subscribers[orgID][ch] = struct{}{}
+	b.mu.Unlock()
+
+	var once sync.Once
+	return ch, func() {
+		once.Do(func() {
+			b.mu.Lock()
+			defer b.mu.Unlock()
+			delete(b.subscribers[orgID], ch)
+			if len(b.subscribers[orgID]) == 0 {
+				delete(b.subscribers, orgID)
+			}
+			close(ch)
+		})
+	}
+}
+
+// Publish delivers the event to every subscriber of its organization
+func (b *VerificationEventBroker) Publish(event *domain.VerificationEvent) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	for ch := range b.subscribers[event.OrganizationID] {
+		select {
+		case ch <- event:
+		default: // Subscriber is too slow; drop rather than block
+		}
+	}
+}
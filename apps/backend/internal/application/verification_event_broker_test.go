@@ -0,0 +1,82 @@
+package application
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVerificationEventService_CreateVerificationEvent_PublishesToSubscribers(t *testing.T) {
+	orgID, otherOrgID, agentID := uuid.New(), uuid.New(), uuid.New()
+
+	mockEventRepo := new(MockVerificationEventRepository)
+	mockEventRepo.On("Create", mock.AnythingOfType("*domain.VerificationEvent")).Return(nil)
+	mockAgentRepo := new(MockAgentRepository)
+	mockAgentRepo.On("GetByID", agentID).Return(&domain.Agent{ID: agentID, DisplayName: "Agent", TrustScore: 80}, nil)
+	mockAgentRepo.On("UpdateLastActive", mock.Anything, agentID).Return(nil)
+
+	service := NewVerificationEventService(mockEventRepo, mockAgentRepo, nil)
+	events, unsubscribe := service.SubscribeToEvents(orgID)
+	defer unsubscribe()
+	otherEvents, unsubscribeOther := service.SubscribeToEvents(otherOrgID)
+	defer unsubscribeOther()
+
+	created, err := service.CreateVerificationEvent(context.Background(), &CreateVerificationEventRequest{
+		OrganizationID:   orgID,
+		AgentID:          agentID,
+		Protocol:         domain.VerificationProtocolA2A,
+		VerificationType: domain.VerificationTypeIdentity,
+		Status:           domain.VerificationEventStatusSuccess,
+		InitiatorType:    domain.InitiatorTypeAgent,
+	})
+	require.NoError(t, err)
+
+	select {
+	case received := <-events:
+		assert.Same(t, created, received)
+	case <-time.After(time.Second):
+		t.Fatal("subscriber did not receive the created event")
+	}
+
+	// Other organizations never see the event
+	select {
+	case received := <-otherEvents:
+		t.Fatalf("event leaked to another organization: %v", received)
+	default:
+	}
+}
+
+func TestVerificationEventBroker_Unsubscribe(t *testing.T) {
+	broker := NewVerificationEventBroker()
+	orgID := uuid.New()
+
+	events, unsubscribe := broker.Subscribe(orgID)
+	unsubscribe()
+	unsubscribe() // Safe to call twice
+
+	_, open := <-events
+	assert.False(t, open)
+	assert.Empty(t, broker.subscribers)
+
+	// Publishing without subscribers is a no-op
+	broker.Publish(&domain.VerificationEvent{OrganizationID: orgID})
+}
+
+func TestVerificationEventBroker_SlowSubscriberDoesNotBlock(t *testing.T) {
+	broker := NewVerificationEventBroker()
+	orgID := uuid.New()
+	events, unsubscribe := broker.Subscribe(orgID)
+	defer unsubscribe()
+
+	for i := 0; i < verificationEventSubscriberBuffer+10; i++ {
+		broker.Publish(&domain.VerificationEvent{OrganizationID: orgID})
+	}
+
+	assert.Len(t, events, verificationEventSubscriberBuffer)
+}
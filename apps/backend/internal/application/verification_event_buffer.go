@@ -0,0 +1,183 @@
+package application
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/domain"
+)
+
+// Verification events are written synchronously unless VERIFICATION_EVENT_BUFFER_SIZE is set, in
+// which case up to that many events are buffered in memory and inserted in batches of
+// DefaultVerificationEventBatchSize, at least every DefaultVerificationEventFlushInterval.
+const (
+	DefaultVerificationEventBatchSize     = 100
+	DefaultVerificationEventFlushInterval = 200 * time.Millisecond
+)
+
+// VerificationEventBufferSizeFromEnv returns how many verification events may be buffered before
+// inserts fall back to being synchronous, or 0 if buffering is disabled
+func VerificationEventBufferSizeFromEnv() int {
+	if value, err := strconv.Atoi(os.Getenv("VERIFICATION_EVENT_BUFFER_SIZE")); err == nil && value > 0 {
+		return value
+	}
+	return 0
+}
+
+// BufferedVerificationEventRepository wraps a VerificationEventRepository so Create queues the
+// event and returns; Run inserts queued events in batches. Events are written in the order their
+// Create calls returned, so the events of one agent keep their order. When the buffer is full,
+// Create writes the queued events and its own synchronously instead of dropping anything.
+//
+// Events get their ID and CreatedAt when queued. GetByID, UpdateResult and Delete flush first so
+// they see events created before them; listings and statistics may lag by one flush interval.
+type BufferedVerificationEventRepository struct {
+	domain.VerificationEventRepository
+	bufferSize    int
+	batchSize     int
+	flushInterval time.Duration
+
+	mu      sync.Mutex
+	pending []*domain.VerificationEvent
+
+	flushMu sync.Mutex // Held while writing, so batches land in the order they were taken
+	wake    chan struct{}
+}
+
+// NewBufferedVerificationEventRepository creates a buffered repository in front of repo.
+// batchSize and flushInterval <= 0 use the defaults; batchSize never exceeds bufferSize.
+func NewBufferedVerificationEventRepository(
+	repo domain.VerificationEventRepository,
+	bufferSize, batchSize int,
+	flushInterval time.Duration,
+) *BufferedVerificationEventRepository {
+	if bufferSize <= 0 {
+		bufferSize = DefaultVerificationEventBatchSize
+	}
+	if batchSize <= 0 {
+		batchSize = DefaultVerificationEventBatchSize
+	}
+	if batchSize > bufferSize {
+		batchSize = bufferSize
+	}
+	if flushInterval <= 0 {
+		flushInterval = DefaultVerificationEventFlushInterval
+	}
+	return &BufferedVerificationEventRepository{
+		VerificationEventRepository: repo,
+		bufferSize:                  bufferSize,
+		batchSize:                   batchSize,
+		flushInterval:               flushInterval,
+		wake:                        make(chan struct{}, 1),
+	}
+}
+
+// Create queues event for the next flush, or writes it synchronously when the buffer is full
+func (r *BufferedVerificationEventRepository) Create(event *domain.VerificationEvent) error {
+	if event.ID == uuid.Nil {
+		event.ID = uuid.New()
+	}
+	if event.CreatedAt.IsZero() {
+		event.CreatedAt = time.Now()
+	}
+
+	r.mu.Lock()
+	if len(r.pending) >= r.bufferSize {
+		r.mu.Unlock()
+		return r.flush(event)
+	}
+	r.pending = append(r.pending, event)
+	batchReady := len(r.pending) >= r.batchSize
+	r.mu.Unlock()
+
+	if batchReady {
+		select {
+		case r.wake <- struct{}{}:
+		default:
+		}
+	}
+	return nil
+}
+
+// Run flushes the buffer every flush interval, or as soon as a batch is ready, until ctx is
+// cancelled; it then flushes whatever is still queued before returning
+func (r *BufferedVerificationEventRepository) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.flushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			r.Flush()
+			return
+		case <-ticker.C:
+		case <-r.wake:
+		}
+		r.Flush()
+	}
+}
+
+// Flush writes every queued event
+func (r *BufferedVerificationEventRepository) Flush() {
+	_ = r.flush(nil)
+}
+
+// GetByID flushes queued events and then retrieves the event
+func (r *BufferedVerificationEventRepository) GetByID(id uuid.UUID) (*domain.VerificationEvent, error) {
+	r.Flush()
+	return r.VerificationEventRepository.GetByID(id)
+}
+
+// UpdateResult flushes queued events and then updates the event's result
+func (r *BufferedVerificationEventRepository) UpdateResult(id uuid.UUID, result domain.VerificationResult, reason *string, metadata map[string]interface{}) error {
+	r.Flush()
+	return r.VerificationEventRepository.UpdateResult(id, result, reason, metadata)
+}
+
+// Delete flushes queued events and then deletes the event
+func (r *BufferedVerificationEventRepository) Delete(id uuid.UUID) error {
+	r.Flush()
+	return r.VerificationEventRepository.Delete(id)
+}
+
+// flush writes the queued events followed by own, if any, and returns the error for own. A batch
+// that fails is retried one event at a time so one bad event does not lose the rest.
+func (r *BufferedVerificationEventRepository) flush(own *domain.VerificationEvent) error {
+	r.flushMu.Lock()
+	defer r.flushMu.Unlock()
+
+	r.mu.Lock()
+	events := r.pending
+	r.pending = nil
+	r.mu.Unlock()
+	if own != nil {
+		events = append(events, own)
+	}
+
+	var ownErr error
+	for start := 0; start < len(events); start += r.batchSize {
+		batch := events[start:min(start+r.batchSize, len(events))]
+		if err := r.VerificationEventRepository.CreateBatch(batch); err == nil {
+			continue
+		}
+
+		for _, event := range batch {
+			err := r.VerificationEventRepository.CreateBatch([]*domain.VerificationEvent{event})
+			if err == nil {
+				continue
+			}
+			if event == own {
+				ownErr = err
+				continue
+			}
+			slog.Error("failed to write buffered verification event",
+				"event_id", event.ID, "agent_id", event.AgentID, "error", err)
+		}
+	}
+	return ownErr
+}
@@ -0,0 +1,144 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingVerificationEventRepository keeps every inserted event in insertion order
+type recordingVerificationEventRepository struct {
+	domain.VerificationEventRepository
+
+	mu      sync.Mutex
+	events  []*domain.VerificationEvent
+	batches []int
+	fail    func(event *domain.VerificationEvent) bool
+}
+
+func (r *recordingVerificationEventRepository) CreateBatch(events []*domain.VerificationEvent) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, event := range events {
+		if r.fail != nil && r.fail(event) {
+			return errors.New("insert failed")
+		}
+	}
+	r.events = append(r.events, events...)
+	r.batches = append(r.batches, len(events))
+	return nil
+}
+
+func (r *recordingVerificationEventRepository) inserted() ([]*domain.VerificationEvent, []int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]*domain.VerificationEvent(nil), r.events...), append([]int(nil), r.batches...)
+}
+
+func newBufferTestEvent(agentID uuid.UUID) *domain.VerificationEvent {
+	return &domain.VerificationEvent{AgentID: &agentID, Status: domain.VerificationEventStatusSuccess}
+}
+
+func TestBufferedVerificationEventRepository_BatchesAndPersistsAllEventsInOrder(t *testing.T) {
+	inner := &recordingVerificationEventRepository{}
+	repo := NewBufferedVerificationEventRepository(inner, 100, 10, time.Hour)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		repo.Run(ctx)
+		close(done)
+	}()
+
+	agentA, agentB := uuid.New(), uuid.New()
+	var created []*domain.VerificationEvent
+	for i := 0; i < 25; i++ {
+		agentID := agentA
+		if i%2 == 1 {
+			agentID = agentB
+		}
+		event := newBufferTestEvent(agentID)
+		require.NoError(t, repo.Create(event))
+		assert.NotEqual(t, uuid.Nil, event.ID, "queued events get their ID up front")
+		created = append(created, event)
+	}
+
+	// Full batches are written without waiting for the (hour-long) flush interval
+	require.Eventually(t, func() bool {
+		events, _ := inner.inserted()
+		return len(events) >= 20
+	}, time.Second, 5*time.Millisecond)
+
+	cancel()
+	<-done
+
+	events, batches := inner.inserted()
+	require.Len(t, events, len(created))
+	for i := range created {
+		assert.Equal(t, created[i].ID, events[i].ID, "event %d written out of order", i)
+	}
+	for _, size := range batches {
+		assert.LessOrEqual(t, size, 10)
+	}
+	assert.Less(t, len(batches), len(created), "events were not batched")
+}
+
+func TestBufferedVerificationEventRepository_FlushesOnShutdown(t *testing.T) {
+	inner := &recordingVerificationEventRepository{}
+	repo := NewBufferedVerificationEventRepository(inner, 100, 50, time.Hour)
+	tasks := NewBackgroundTasks()
+	require.True(t, tasks.Go(repo.Run))
+
+	for i := 0; i < 3; i++ {
+		require.NoError(t, repo.Create(newBufferTestEvent(uuid.New())))
+	}
+	events, _ := inner.inserted()
+	assert.Empty(t, events, "events below the batch size wait for the flush interval")
+
+	require.NoError(t, tasks.Shutdown(time.Second))
+	events, batches := inner.inserted()
+	assert.Len(t, events, 3)
+	assert.Equal(t, []int{3}, batches)
+}
+
+func TestBufferedVerificationEventRepository_WritesSynchronouslyWhenFull(t *testing.T) {
+	inner := &recordingVerificationEventRepository{}
+	// No Run loop: only the synchronous fallback writes anything
+	repo := NewBufferedVerificationEventRepository(inner, 2, 2, time.Hour)
+	agentID := uuid.New()
+
+	first, second, third := newBufferTestEvent(agentID), newBufferTestEvent(agentID), newBufferTestEvent(agentID)
+	require.NoError(t, repo.Create(first))
+	require.NoError(t, repo.Create(second))
+	events, _ := inner.inserted()
+	assert.Empty(t, events)
+
+	require.NoError(t, repo.Create(third))
+	events, _ = inner.inserted()
+	require.Len(t, events, 3, "a full buffer is written together with the new event")
+	assert.Equal(t, []uuid.UUID{first.ID, second.ID, third.ID}, []uuid.UUID{events[0].ID, events[1].ID, events[2].ID})
+}
+
+func TestBufferedVerificationEventRepository_FailedEventDoesNotLoseBatch(t *testing.T) {
+	bad := newBufferTestEvent(uuid.New())
+	inner := &recordingVerificationEventRepository{fail: func(event *domain.VerificationEvent) bool { return event == bad }}
+	repo := NewBufferedVerificationEventRepository(inner, 1, 1, time.Hour)
+
+	good := newBufferTestEvent(uuid.New())
+	require.NoError(t, repo.Create(bad))
+	// The buffer is full, so this write is synchronous; only its own outcome is reported
+	require.NoError(t, repo.Create(good))
+
+	events, _ := inner.inserted()
+	require.Len(t, events, 1)
+	assert.Equal(t, good.ID, events[0].ID)
+
+	require.NoError(t, repo.Create(newBufferTestEvent(uuid.New())))
+	assert.Error(t, repo.Create(bad), "a synchronous write reports its own failure")
+}
@@ -21,6 +21,11 @@ func (m *MockVerificationEventRepository) Create(event *domain.VerificationEvent
 	return args.Error(0)
 }
 
+func (m *MockVerificationEventRepository) CreateBatch(events []*domain.VerificationEvent) error {
+	args := m.Called(events)
+	return args.Error(0)
+}
+
 func (m *MockVerificationEventRepository) GetByID(id uuid.UUID) (*domain.VerificationEvent, error) {
 	args := m.Called(id)
 	if args.Get(0) == nil {
@@ -87,6 +92,30 @@ func (m *MockVerificationEventRepository) GetAgentStatistics(agentID uuid.UUID,
 	return args.Get(0).(*domain.AgentVerificationStatistics), args.Error(1)
 }
 
+func (m *MockVerificationEventRepository) GetStatisticsByProtocol(orgID uuid.UUID, startTime, endTime time.Time) (map[domain.VerificationProtocol]*domain.ProtocolVerificationStatistics, error) {
+	args := m.Called(orgID, startTime, endTime)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[domain.VerificationProtocol]*domain.ProtocolVerificationStatistics), args.Error(1)
+}
+
+func (m *MockVerificationEventRepository) GetLatencyStatistics(orgID uuid.UUID, startTime, endTime time.Time) (*domain.VerificationLatencyStatistics, error) {
+	args := m.Called(orgID, startTime, endTime)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.VerificationLatencyStatistics), args.Error(1)
+}
+
+func (m *MockVerificationEventRepository) CountAgentEventsByWindow(agentID uuid.UUID, now time.Time, window time.Duration, windows int) ([]int, error) {
+	args := m.Called(agentID, now, window, windows)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]int), args.Error(1)
+}
+
 func (m *MockVerificationEventRepository) GetPendingVerifications(orgID uuid.UUID) ([]*domain.VerificationEvent, error) {
 	args := m.Called(orgID)
 	if args.Get(0) == nil {
@@ -14,6 +14,7 @@ type VerificationEventService struct {
 	eventRepo      domain.VerificationEventRepository
 	agentRepo      domain.AgentRepository
 	driftDetection *DriftDetectionService
+	broker         *VerificationEventBroker
 }
 
 // NewVerificationEventService creates a new verification event service
@@ -26,6 +27,7 @@ func NewVerificationEventService(
 		eventRepo:      eventRepo,
 		agentRepo:      agentRepo,
 		driftDetection: driftDetection,
+		broker:         NewVerificationEventBroker(),
 	}
 }
 
@@ -72,6 +74,8 @@ func (s *VerificationEventService) LogVerificationEvent(
 	if err := s.eventRepo.Create(event); err != nil {
 		return nil, fmt.Errorf("failed to create verification event: %w", err)
 	}
+	s.broker.Publish(event)
+	s.recordActivity(ctx, event)
 
 	return event, nil
 }
@@ -160,10 +164,27 @@ func (s *VerificationEventService) CreateVerificationEvent(
 	if err := s.eventRepo.Create(event); err != nil {
 		return nil, fmt.Errorf("failed to create verification event: %w", err)
 	}
+	s.broker.Publish(event)
+	s.recordActivity(ctx, event)
 
 	return event, nil
 }
 
+// recordActivity bumps last_active for events the agent itself initiated; events raised by users
+// or the system about an agent say nothing about whether the agent is running
+func (s *VerificationEventService) recordActivity(ctx context.Context, event *domain.VerificationEvent) {
+	if event.AgentID == nil || event.InitiatorType != domain.InitiatorTypeAgent {
+		return
+	}
+	recordAgentActivity(ctx, s.agentRepo, *event.AgentID)
+}
+
+// SubscribeToEvents streams the organization's verification events as they are created on this
+// server instance. The returned function unsubscribes and must be called when done.
+func (s *VerificationEventService) SubscribeToEvents(orgID uuid.UUID) (<-chan *domain.VerificationEvent, func()) {
+	return s.broker.Subscribe(orgID)
+}
+
 // GetVerificationEvent retrieves a verification event by ID
 func (s *VerificationEventService) GetVerificationEvent(ctx context.Context, id uuid.UUID) (*domain.VerificationEvent, error) {
 	return s.eventRepo.GetByID(id)
@@ -210,6 +231,32 @@ func (s *VerificationEventService) GetStatistics(
 	return s.eventRepo.GetStatistics(orgID, startTime, endTime)
 }
 
+// GetStatisticsByProtocol calculates per-protocol verification statistics for a time range
+func (s *VerificationEventService) GetStatisticsByProtocol(
+	ctx context.Context,
+	orgID uuid.UUID,
+	startTime, endTime time.Time,
+) (map[domain.VerificationProtocol]*domain.ProtocolVerificationStatistics, error) {
+	return s.eventRepo.GetStatisticsByProtocol(orgID, startTime, endTime)
+}
+
+// MaxLatencyStatisticsWindow bounds the time range latency percentiles are computed over, so one
+// request cannot make Postgres sort an organization's whole event history
+const MaxLatencyStatisticsWindow = 90 * 24 * time.Hour
+
+// GetLatencyStatistics calculates p50/p95/p99 verification latency for a time range. A range longer
+// than MaxLatencyStatisticsWindow is cut to its most recent MaxLatencyStatisticsWindow.
+func (s *VerificationEventService) GetLatencyStatistics(
+	ctx context.Context,
+	orgID uuid.UUID,
+	startTime, endTime time.Time,
+) (*domain.VerificationLatencyStatistics, error) {
+	if earliest := endTime.Add(-MaxLatencyStatisticsWindow); startTime.Before(earliest) {
+		startTime = earliest
+	}
+	return s.eventRepo.GetLatencyStatistics(orgID, startTime, endTime)
+}
+
 // GetLast24HoursStatistics calculates statistics for the last 24 hours
 func (s *VerificationEventService) GetLast24HoursStatistics(ctx context.Context, orgID uuid.UUID) (*domain.VerificationStatistics, error) {
 	endTime := time.Now()
@@ -0,0 +1,142 @@
+package application
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/opena2a/identity/backend/internal/infrastructure/logging"
+)
+
+// DefaultVerificationClockSkew is how far a signed verification timestamp may drift from
+// server time in either direction; override with VERIFICATION_MAX_CLOCK_SKEW (Go duration)
+const DefaultVerificationClockSkew = 5 * time.Minute
+
+// verificationSignaturePrefix namespaces accepted signatures in the replay store
+const verificationSignaturePrefix = "verification_signature:"
+
+var (
+	ErrVerificationTimestampInvalid = errors.New("invalid timestamp format")
+	ErrVerificationTimestampSkewed  = errors.New("verification timestamp is outside the allowed clock skew")
+	ErrVerificationReplayed         = errors.New("verification signature has already been used")
+)
+
+// VerificationClockSkewFromEnv reads VERIFICATION_MAX_CLOCK_SKEW, falling back to the default
+func VerificationClockSkewFromEnv() time.Duration {
+	if value, err := time.ParseDuration(os.Getenv("VERIFICATION_MAX_CLOCK_SKEW")); err == nil && value > 0 {
+		return value
+	}
+	return DefaultVerificationClockSkew
+}
+
+// SignatureReplayStore remembers accepted signatures for a limited time.
+// *cache.RedisCache implements it so replays are caught across server instances.
+type SignatureReplayStore interface {
+	SetWithNX(ctx context.Context, key string, value interface{}, ttl time.Duration) (bool, error)
+}
+
+// VerificationReplayGuard rejects signed verification requests whose timestamp is too far
+// from server time, and exact replays of a signature that was already accepted. A signature
+// only has to be remembered while its timestamp is still inside the skew window.
+type VerificationReplayGuard struct {
+	store   SignatureReplayStore
+	maxSkew time.Duration
+
+	mu   sync.Mutex
+	seen map[string]time.Time // in-memory fallback: signature hash -> expiry
+}
+
+// NewVerificationReplayGuard creates a replay guard. store may be nil, in which case
+// (and whenever the store errors) signatures are tracked in memory.
+func NewVerificationReplayGuard(store SignatureReplayStore, maxSkew time.Duration) *VerificationReplayGuard {
+	if maxSkew <= 0 {
+		maxSkew = DefaultVerificationClockSkew
+	}
+	return &VerificationReplayGuard{
+		store:   store,
+		maxSkew: maxSkew,
+		seen:    make(map[string]time.Time),
+	}
+}
+
+// MaxClockSkew returns the accepted timestamp skew
+func (g *VerificationReplayGuard) MaxClockSkew() time.Duration {
+	return g.maxSkew
+}
+
+// CheckTimestamp verifies that a signed timestamp is within the allowed skew of now.
+// SDKs send RFC 3339 timestamps; the Python SDK's isoformat() output is accepted too.
+func (g *VerificationReplayGuard) CheckTimestamp(timestamp string, now time.Time) error {
+	signedAt, err := parseVerificationTimestamp(timestamp)
+	if err != nil {
+		return ErrVerificationTimestampInvalid
+	}
+
+	skew := now.Sub(signedAt)
+	if skew < 0 {
+		skew = -skew
+	}
+	if skew > g.maxSkew {
+		return ErrVerificationTimestampSkewed
+	}
+	return nil
+}
+
+// MarkSignatureUsed records a verified signature, returning ErrVerificationReplayed if it
+// was already accepted within the replay window
+func (g *VerificationReplayGuard) MarkSignatureUsed(ctx context.Context, signature string, now time.Time) error {
+	key := signatureReplayKey(signature)
+	// A timestamp stays acceptable from maxSkew before it until maxSkew after it
+	ttl := 2 * g.maxSkew
+
+	if g.store != nil {
+		claimed, err := g.store.SetWithNX(ctx, verificationSignaturePrefix+key, now.Unix(), ttl)
+		if err == nil {
+			if !claimed {
+				return ErrVerificationReplayed
+			}
+			return nil
+		}
+		logging.FromContext(ctx).Warn("signature replay store unavailable, using in-memory tracking", "error", err)
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if expiresAt, ok := g.seen[key]; ok && now.Before(expiresAt) {
+		return ErrVerificationReplayed
+	}
+	// Drop expired signatures while we hold the lock so the map doesn't grow unbounded
+	for k, expiresAt := range g.seen {
+		if !now.Before(expiresAt) {
+			delete(g.seen, k)
+		}
+	}
+	g.seen[key] = now.Add(ttl)
+	return nil
+}
+
+// signatureReplayKey hashes the decoded signature bytes. The base64 decoder that verifies
+// signatures ignores line breaks and unused trailing bits, so several strings carry the same
+// signature; keying on the raw string would let each of them through once.
+func signatureReplayKey(signature string) string {
+	raw := []byte(signature)
+	if decoded, err := base64.StdEncoding.DecodeString(signature); err == nil {
+		raw = decoded
+	}
+	sum := sha256.Sum256(raw)
+	return hex.EncodeToString(sum[:])
+}
+
+// parseVerificationTimestamp accepts RFC 3339 timestamps and zone-less ISO 8601 timestamps (assumed UTC)
+func parseVerificationTimestamp(timestamp string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339Nano, timestamp); err == nil {
+		return t, nil
+	}
+	return time.Parse("2006-01-02T15:04:05.999999999", timestamp)
+}
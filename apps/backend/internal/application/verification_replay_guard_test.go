@@ -0,0 +1,130 @@
+package application
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVerificationReplayGuard_RejectsStaleTimestamp(t *testing.T) {
+	now := time.Date(2025, 6, 1, 9, 0, 0, 0, time.UTC)
+	guard := NewVerificationReplayGuard(nil, 5*time.Minute)
+
+	assert.NoError(t, guard.CheckTimestamp(now.Add(-4*time.Minute).Format(time.RFC3339Nano), now))
+	assert.NoError(t, guard.CheckTimestamp(now.Add(4*time.Minute).Format(time.RFC3339), now))
+	assert.ErrorIs(t, guard.CheckTimestamp(now.Add(-6*time.Minute).Format(time.RFC3339), now), ErrVerificationTimestampSkewed)
+	assert.ErrorIs(t, guard.CheckTimestamp(now.Add(6*time.Minute).Format(time.RFC3339), now), ErrVerificationTimestampSkewed)
+	assert.ErrorIs(t, guard.CheckTimestamp("", now), ErrVerificationTimestampInvalid)
+	assert.ErrorIs(t, guard.CheckTimestamp("yesterday", now), ErrVerificationTimestampInvalid)
+}
+
+func TestVerificationReplayGuard_AcceptsPythonSDKTimestamps(t *testing.T) {
+	now := time.Date(2025, 6, 1, 9, 0, 0, 0, time.UTC)
+	guard := NewVerificationReplayGuard(nil, 5*time.Minute)
+
+	// datetime.utcnow().isoformat() + 'Z'
+	assert.NoError(t, guard.CheckTimestamp("2025-06-01T08:58:30.123456Z", now))
+	// datetime.utcnow().isoformat() without a zone suffix is treated as UTC
+	assert.NoError(t, guard.CheckTimestamp("2025-06-01T08:58:30.123456", now))
+}
+
+func TestVerificationReplayGuard_SkewIsConfigurable(t *testing.T) {
+	now := time.Date(2025, 6, 1, 9, 0, 0, 0, time.UTC)
+	stamp := now.Add(-2 * time.Minute).Format(time.RFC3339)
+
+	assert.ErrorIs(t, NewVerificationReplayGuard(nil, time.Minute).CheckTimestamp(stamp, now), ErrVerificationTimestampSkewed)
+	assert.NoError(t, NewVerificationReplayGuard(nil, 10*time.Minute).CheckTimestamp(stamp, now))
+
+	t.Setenv("VERIFICATION_MAX_CLOCK_SKEW", "30s")
+	assert.Equal(t, 30*time.Second, VerificationClockSkewFromEnv())
+	t.Setenv("VERIFICATION_MAX_CLOCK_SKEW", "bogus")
+	assert.Equal(t, DefaultVerificationClockSkew, VerificationClockSkewFromEnv())
+}
+
+func TestVerificationReplayGuard_RejectsDuplicateSignature(t *testing.T) {
+	now := time.Date(2025, 6, 1, 9, 0, 0, 0, time.UTC)
+	guard := NewVerificationReplayGuard(nil, 5*time.Minute)
+	ctx := context.Background()
+
+	require.NoError(t, guard.MarkSignatureUsed(ctx, "sig-a", now))
+	assert.ErrorIs(t, guard.MarkSignatureUsed(ctx, "sig-a", now.Add(time.Minute)), ErrVerificationReplayed)
+	assert.NoError(t, guard.MarkSignatureUsed(ctx, "sig-b", now.Add(time.Minute)))
+
+	// Once the signed timestamp can no longer pass the skew check, the signature is forgotten
+	assert.NoError(t, guard.MarkSignatureUsed(ctx, "sig-a", now.Add(10*time.Minute)))
+}
+
+func TestVerificationReplayGuard_RejectsReencodedSignature(t *testing.T) {
+	now := time.Date(2025, 6, 1, 9, 0, 0, 0, time.UTC)
+	ctx := context.Background()
+
+	signature := make([]byte, 64) // Ed25519 signature size
+	for i := range signature {
+		signature[i] = byte(i)
+	}
+	encoded := base64.StdEncoding.EncodeToString(signature)
+
+	// The final data character of "…x==" carries 4 unused bits the decoder ignores
+	const alphabet = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789+/"
+	last := len(encoded) - 3
+	flipped := encoded[:last] + string(alphabet[strings.IndexByte(alphabet, encoded[last])^1]) + encoded[last+1:]
+
+	for _, variant := range []string{flipped, encoded[:40] + "\n" + encoded[40:]} {
+		decoded, err := base64.StdEncoding.DecodeString(variant)
+		require.NoError(t, err)
+		require.Equal(t, signature, decoded)
+
+		guard := NewVerificationReplayGuard(nil, 5*time.Minute)
+		require.NoError(t, guard.MarkSignatureUsed(ctx, encoded, now))
+		assert.ErrorIs(t, guard.MarkSignatureUsed(ctx, variant, now), ErrVerificationReplayed)
+	}
+}
+
+// fakeReplayStore mimics Redis SETNX
+type fakeReplayStore struct {
+	keys map[string]time.Duration
+	err  error
+}
+
+func (s *fakeReplayStore) SetWithNX(ctx context.Context, key string, value interface{}, ttl time.Duration) (bool, error) {
+	if s.err != nil {
+		return false, s.err
+	}
+	if _, ok := s.keys[key]; ok {
+		return false, nil
+	}
+	s.keys[key] = ttl
+	return true, nil
+}
+
+func TestVerificationReplayGuard_UsesSharedStore(t *testing.T) {
+	now := time.Date(2025, 6, 1, 9, 0, 0, 0, time.UTC)
+	store := &fakeReplayStore{keys: make(map[string]time.Duration)}
+	ctx := context.Background()
+
+	// Two guards sharing a store behave like two server instances sharing Redis
+	first := NewVerificationReplayGuard(store, 5*time.Minute)
+	second := NewVerificationReplayGuard(store, 5*time.Minute)
+
+	require.NoError(t, first.MarkSignatureUsed(ctx, "sig-a", now))
+	assert.ErrorIs(t, second.MarkSignatureUsed(ctx, "sig-a", now), ErrVerificationReplayed)
+	for key, ttl := range store.keys {
+		assert.Contains(t, key, verificationSignaturePrefix)
+		assert.Equal(t, 10*time.Minute, ttl)
+	}
+}
+
+func TestVerificationReplayGuard_FallsBackToMemoryWhenStoreFails(t *testing.T) {
+	now := time.Date(2025, 6, 1, 9, 0, 0, 0, time.UTC)
+	guard := NewVerificationReplayGuard(&fakeReplayStore{err: errors.New("connection refused")}, 5*time.Minute)
+	ctx := context.Background()
+
+	require.NoError(t, guard.MarkSignatureUsed(ctx, "sig-a", now))
+	assert.ErrorIs(t, guard.MarkSignatureUsed(ctx, "sig-a", now), ErrVerificationReplayed)
+}
@@ -8,54 +8,158 @@ import (
 	"crypto/sha256"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"os"
+	"slices"
+	"strconv"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/crypto"
 	"github.com/opena2a/identity/backend/internal/domain"
-	"github.com/opena2a/identity/backend/internal/infrastructure/repository"
+	"github.com/opena2a/identity/backend/internal/infrastructure/utils"
 )
 
+// DefaultWebhookMaxAttempts is the default number of delivery attempts before a delivery is marked failed
+const DefaultWebhookMaxAttempts = 5
+
+// WebhookRetryPollInterval is how often the retry worker looks for deliveries that are due
+const WebhookRetryPollInterval = time.Second
+
+// webhookClaimLease is how long a claimed delivery stays hidden from other retry workers.
+// It must outlast a delivery attempt, which is bounded by the HTTP client timeout.
+const webhookClaimLease = 2 * time.Minute
+
+// webhookRetrySchedule is the exponential backoff applied after each failed attempt.
+// Attempts beyond the schedule reuse the last delay.
+var webhookRetrySchedule = []time.Duration{
+	1 * time.Second,
+	5 * time.Second,
+	30 * time.Second,
+	2 * time.Minute,
+	10 * time.Minute,
+}
+
 type WebhookService struct {
-	webhookRepo *repository.WebhookRepository
+	webhookRepo   domain.WebhookRepository
+	keyVault      *crypto.KeyVault // Encrypts webhook secrets at rest
+	httpClient    *http.Client
+	validateURL   func(ctx context.Context, rawURL string) error
+	retrySchedule []time.Duration
+	maxAttempts   int
 }
 
-func NewWebhookService(webhookRepo *repository.WebhookRepository) *WebhookService {
+func NewWebhookService(webhookRepo domain.WebhookRepository, keyVault *crypto.KeyVault) *WebhookService {
+	// Max attempts is configurable via WEBHOOK_MAX_DELIVERY_ATTEMPTS
+	maxAttempts := DefaultWebhookMaxAttempts
+	if value, err := strconv.Atoi(os.Getenv("WEBHOOK_MAX_DELIVERY_ATTEMPTS")); err == nil && value > 0 {
+		maxAttempts = value
+	}
+
+	// Webhook URLs are user-supplied: never deliver to internal addresses
 	return &WebhookService{
-		webhookRepo: webhookRepo,
+		webhookRepo:   webhookRepo,
+		keyVault:      keyVault,
+		httpClient:    utils.NewPublicHTTPClient(10 * time.Second),
+		validateURL:   utils.ValidatePublicURL,
+		retrySchedule: webhookRetrySchedule,
+		maxAttempts:   maxAttempts,
 	}
 }
 
 // CreateWebhookRequest represents the request to create a webhook
 type CreateWebhookRequest struct {
-	Name     string                 `json:"name" validate:"required"`
-	URL      string                 `json:"url" validate:"required,url"`
-	Events   []domain.WebhookEvent  `json:"events" validate:"required"`
-	IsActive *bool                  `json:"is_active,omitempty"` // Pointer to distinguish between false and not provided
+	Name         string                `json:"name" validate:"required"`
+	URL          string                `json:"url" validate:"required,url"`
+	EventTypes   []domain.WebhookEvent `json:"event_types"`             // Events to deliver; takes precedence over events
+	Events       []domain.WebhookEvent `json:"events"`                  // Deprecated: use event_types
+	ResourceType *string               `json:"resource_type,omitempty"` // Only deliver events about this resource type
+	IsActive     *bool                 `json:"is_active,omitempty"`     // Pointer to distinguish between false and not provided
+}
+
+var (
+	ErrWebhookNoEvents            = errors.New("at least one event type is required")
+	ErrInvalidWebhookEvent        = errors.New("unknown webhook event type")
+	ErrInvalidWebhookResourceType = errors.New("unknown webhook resource type")
+	ErrInvalidWebhookURL          = errors.New("webhook URL must be a public http or https URL")
+	ErrWebhookSecretNotEncrypted  = errors.New("webhook has no encrypted secret; rotate its secret")
+)
+
+// subscription returns the validated event types and resource filter from the request
+func (req *CreateWebhookRequest) subscription() ([]domain.WebhookEvent, *string, error) {
+	events := req.EventTypes
+	if len(events) == 0 {
+		events = req.Events
+	}
+	if len(events) == 0 {
+		return nil, nil, ErrWebhookNoEvents
+	}
+	for _, event := range events {
+		if !event.IsValid() {
+			return nil, nil, fmt.Errorf("%w: %q", ErrInvalidWebhookEvent, event)
+		}
+	}
+
+	var resourceType *string
+	if req.ResourceType != nil && *req.ResourceType != "" {
+		if !slices.Contains(domain.KnownWebhookResourceTypes, *req.ResourceType) {
+			return nil, nil, fmt.Errorf("%w: %q", ErrInvalidWebhookResourceType, *req.ResourceType)
+		}
+		resourceType = req.ResourceType
+	}
+
+	return events, resourceType, nil
+}
+
+// checkURL rejects webhook URLs that are not http(s) or point at loopback, private or link-local
+// addresses. Deliveries are checked again at dial time since DNS can change after saving.
+func (s *WebhookService) checkURL(ctx context.Context, rawURL string) error {
+	if s.validateURL == nil {
+		return nil
+	}
+	if err := s.validateURL(ctx, rawURL); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidWebhookURL, err)
+	}
+	return nil
 }
 
 // CreateWebhook creates a new webhook subscription
 func (s *WebhookService) CreateWebhook(ctx context.Context, req *CreateWebhookRequest, orgID, userID uuid.UUID) (*domain.Webhook, error) {
-	// Generate secret for webhook signature
+	events, resourceType, err := req.subscription()
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkURL(ctx, req.URL); err != nil {
+		return nil, err
+	}
+
+	// Generate secret for webhook signature - returned once, stored encrypted
 	secret, err := generateSecret()
 	if err != nil {
 		return nil, err
 	}
+	encryptedSecret, err := s.keyVault.EncryptPrivateKey(secret)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encrypt webhook secret: %w", err)
+	}
 
 	webhook := &domain.Webhook{
-		ID:             uuid.New(),
-		OrganizationID: orgID,
-		Name:           req.Name,
-		URL:            req.URL,
-		Events:         req.Events,
-		Secret:         secret,
-		IsActive:       true,
-		FailureCount:   0,
-		CreatedBy:      userID,
-		CreatedAt:      time.Now().UTC(),
-		UpdatedAt:      time.Now().UTC(),
+		ID:              uuid.New(),
+		OrganizationID:  orgID,
+		Name:            req.Name,
+		URL:             req.URL,
+		Events:          events,
+		ResourceType:    resourceType,
+		Secret:          secret,
+		EncryptedSecret: encryptedSecret,
+		IsActive:        true,
+		FailureCount:    0,
+		CreatedBy:       userID,
+		CreatedAt:       time.Now().UTC(),
+		UpdatedAt:       time.Now().UTC(),
 	}
 
 	if err := s.webhookRepo.Create(webhook); err != nil {
@@ -65,6 +169,57 @@ func (s *WebhookService) CreateWebhook(ctx context.Context, req *CreateWebhookRe
 	return webhook, nil
 }
 
+// RotateWebhookSecret generates a new signing secret for a webhook.
+// The returned webhook carries the plaintext secret; it cannot be retrieved again.
+func (s *WebhookService) RotateWebhookSecret(ctx context.Context, id uuid.UUID) (*domain.Webhook, error) {
+	webhook, err := s.webhookRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	secret, err := generateSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	encryptedSecret, err := s.keyVault.EncryptPrivateKey(secret)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encrypt webhook secret: %w", err)
+	}
+	if err := s.webhookRepo.UpdateSecret(id, encryptedSecret); err != nil {
+		return nil, fmt.Errorf("failed to rotate webhook secret: %w", err)
+	}
+
+	webhook.Secret = secret
+	webhook.EncryptedSecret = encryptedSecret
+	webhook.UpdatedAt = time.Now().UTC()
+
+	return webhook, nil
+}
+
+// EncryptPlaintextSecrets encrypts the secrets of webhooks created before secrets were stored
+// encrypted and clears the plaintext. It returns how many secrets were encrypted.
+func (s *WebhookService) EncryptPlaintextSecrets(ctx context.Context) (int, error) {
+	secrets, err := s.webhookRepo.GetPlaintextSecrets()
+	if err != nil {
+		return 0, fmt.Errorf("failed to load plaintext webhook secrets: %w", err)
+	}
+
+	encrypted := 0
+	for id, secret := range secrets {
+		encryptedSecret, err := s.keyVault.EncryptPrivateKey(secret)
+		if err != nil {
+			return encrypted, fmt.Errorf("failed to encrypt secret of webhook %s: %w", id, err)
+		}
+		if err := s.webhookRepo.UpdateSecret(id, encryptedSecret); err != nil {
+			return encrypted, fmt.Errorf("failed to store encrypted secret of webhook %s: %w", id, err)
+		}
+		encrypted++
+	}
+
+	return encrypted, nil
+}
+
 // ListWebhooks lists all webhooks for an organization
 func (s *WebhookService) ListWebhooks(ctx context.Context, orgID uuid.UUID) ([]*domain.Webhook, error) {
 	return s.webhookRepo.GetByOrganization(orgID)
@@ -82,6 +237,14 @@ func (s *WebhookService) DeleteWebhook(ctx context.Context, id uuid.UUID) error
 
 // UpdateWebhook updates an existing webhook
 func (s *WebhookService) UpdateWebhook(ctx context.Context, id uuid.UUID, req *CreateWebhookRequest) (*domain.Webhook, error) {
+	events, resourceType, err := req.subscription()
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkURL(ctx, req.URL); err != nil {
+		return nil, err
+	}
+
 	// Get existing webhook
 	webhook, err := s.webhookRepo.GetByID(id)
 	if err != nil {
@@ -91,7 +254,8 @@ func (s *WebhookService) UpdateWebhook(ctx context.Context, id uuid.UUID, req *C
 	// Update fields
 	webhook.Name = req.Name
 	webhook.URL = req.URL
-	webhook.Events = req.Events
+	webhook.Events = events
+	webhook.ResourceType = resourceType
 
 	// Update IsActive if provided
 	if req.IsActive != nil {
@@ -108,38 +272,48 @@ func (s *WebhookService) UpdateWebhook(ctx context.Context, id uuid.UUID, req *C
 	return webhook, nil
 }
 
-// WebhookTestResult contains the result of a webhook test
+// WebhookTestResult contains the result of a webhook test. The receiver's response body is
+// deliberately not reported back.
 type WebhookTestResult struct {
 	Success      bool
+	Event        domain.WebhookEvent
 	StatusCode   int
+	Latency      time.Duration
 	ErrorMessage string
 }
 
-// TestWebhook sends a test payload to a webhook
+// TestWebhook sends a sample security_breach event to a webhook, signed exactly like a production
+// delivery, so users can check their receiver end to end. The payload is marked "test": true.
 func (s *WebhookService) TestWebhook(ctx context.Context, id uuid.UUID) (*WebhookTestResult, error) {
 	webhook, err := s.webhookRepo.GetByID(id)
 	if err != nil {
 		return nil, err
 	}
 
-	// Create test payload
+	event := domain.WebhookEventSecurityBreach
 	payload := map[string]interface{}{
-		"event":      "webhook.test",
-		"webhook_id": webhook.ID.String(),
-		"timestamp":  time.Now().UTC(),
-		"data": map[string]string{
-			"message": "This is a test webhook delivery",
-		},
+		"event":         event,
+		"resource_type": domain.WebhookResourceAgent,
+		"webhook_id":    webhook.ID.String(),
+		"timestamp":     time.Now().UTC(),
+		"test":          true,
+		"data":          sampleSecurityBreachEvent(webhook.OrganizationID),
 	}
 
-	// Send webhook and capture result
-	statusCode, deliveryErr := s.sendWebhookWithResult(webhook, "webhook.test", payload)
+	// Single attempt without retries so the caller sees the receiver's response directly
+	delivery, err := s.newDelivery(webhook, event, payload)
+	if err != nil {
+		return nil, err
+	}
+	startedAt := time.Now()
+	deliveryErr := s.attemptDelivery(webhook, delivery, false)
 
 	result := &WebhookTestResult{
-		Success:    statusCode >= 200 && statusCode < 300,
-		StatusCode: statusCode,
+		Success:    deliveryErr == nil,
+		Event:      event,
+		StatusCode: delivery.StatusCode,
+		Latency:    time.Since(startedAt),
 	}
-
 	if deliveryErr != nil {
 		result.ErrorMessage = deliveryErr.Error()
 	}
@@ -147,15 +321,228 @@ func (s *WebhookService) TestWebhook(ctx context.Context, id uuid.UUID) (*Webhoo
 	return result, nil
 }
 
-// sendWebhookWithResult sends a webhook payload and returns status code and error
-func (s *WebhookService) sendWebhookWithResult(webhook *domain.Webhook, event string, payload interface{}) (int, error) {
+// sampleSecurityBreachEvent returns representative event data for webhook tests; the IDs are random
+func sampleSecurityBreachEvent(orgID uuid.UUID) map[string]interface{} {
+	return map[string]interface{}{
+		"alert_id":        uuid.New().String(),
+		"organization_id": orgID.String(),
+		"agent_id":        uuid.New().String(),
+		"agent_name":      "sample-agent",
+		"severity":        domain.AlertSeverityHigh,
+		"title":           "Sample security breach",
+		"description":     "This is a sample event sent by the webhook test endpoint. No action is required.",
+	}
+}
+
+// TriggerEvent delivers an event about a resource of resourceType to every active webhook in the
+// organization subscribed to that event type and, if the webhook filters on one, that resource type.
+// Failed deliveries are retried in the background by the retry worker.
+func (s *WebhookService) TriggerEvent(ctx context.Context, orgID uuid.UUID, event domain.WebhookEvent, resourceType string, data interface{}) error {
+	webhooks, err := s.webhookRepo.GetByOrganization(orgID)
+	if err != nil {
+		return fmt.Errorf("failed to load webhooks: %w", err)
+	}
+
+	for _, webhook := range webhooks {
+		if !webhook.IsActive || !webhookSubscribedTo(webhook, event, resourceType) {
+			continue
+		}
+
+		payload := map[string]interface{}{
+			"event":         event,
+			"resource_type": resourceType,
+			"webhook_id":    webhook.ID.String(),
+			"timestamp":     time.Now().UTC(),
+			"data":          data,
+		}
+
+		if _, err := s.DeliverEvent(webhook, event, payload); err != nil {
+			fmt.Printf("⚠️  Warning: webhook %s delivery for %s failed: %v\n", webhook.ID, event, err)
+		}
+	}
+
+	return nil
+}
+
+// QueueEvent records a pending delivery of an event for every webhook TriggerEvent would deliver to,
+// due immediately, and leaves sending it to the retry worker. Delivery IDs are derived from key, so
+// queueing the same event again with the same key records no second delivery.
+func (s *WebhookService) QueueEvent(ctx context.Context, key, orgID uuid.UUID, event domain.WebhookEvent, resourceType string, data interface{}) error {
+	webhooks, err := s.webhookRepo.GetByOrganization(orgID)
+	if err != nil {
+		return fmt.Errorf("failed to load webhooks: %w", err)
+	}
+
+	now := time.Now().UTC()
+	for _, webhook := range webhooks {
+		if !webhook.IsActive || !webhookSubscribedTo(webhook, event, resourceType) {
+			continue
+		}
+
+		payload, err := json.Marshal(map[string]interface{}{
+			"event":         event,
+			"resource_type": resourceType,
+			"webhook_id":    webhook.ID.String(),
+			"timestamp":     now,
+			"data":          data,
+		})
+		if err != nil {
+			return err
+		}
+
+		delivery := &domain.WebhookDelivery{
+			ID:            uuid.NewSHA1(key, webhook.ID[:]),
+			WebhookID:     webhook.ID,
+			Event:         event,
+			Payload:       string(payload),
+			Status:        domain.WebhookDeliveryPending,
+			NextAttemptAt: &now,
+			CreatedAt:     now,
+		}
+		if err := s.webhookRepo.RecordDelivery(delivery); err != nil {
+			return fmt.Errorf("failed to queue webhook delivery: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// DeliverEvent records a delivery for the webhook and makes the first attempt.
+// If the attempt fails the delivery is queued for retry with exponential backoff.
+func (s *WebhookService) DeliverEvent(webhook *domain.Webhook, event domain.WebhookEvent, payload interface{}) (*domain.WebhookDelivery, error) {
+	delivery, err := s.newDelivery(webhook, event, payload)
+	if err != nil {
+		return nil, err
+	}
+
+	return delivery, s.attemptDelivery(webhook, delivery, true)
+}
+
+// ProcessDueDeliveries claims every delivery whose backoff has elapsed, retries it and returns how many
+// were attempted. Claiming keeps concurrent workers from sending the same delivery twice.
+func (s *WebhookService) ProcessDueDeliveries(ctx context.Context) (int, error) {
+	deliveries, err := s.webhookRepo.ClaimDueDeliveries(time.Now().UTC(), webhookClaimLease, 100)
+	if err != nil {
+		return 0, fmt.Errorf("failed to claim due webhook deliveries: %w", err)
+	}
+
+	for _, delivery := range deliveries {
+		webhook, err := s.webhookRepo.GetByID(delivery.WebhookID)
+		if err != nil || !webhook.IsActive {
+			// Webhook was deleted or disabled - stop retrying
+			delivery.Status = domain.WebhookDeliveryFailed
+			delivery.ErrorMessage = "webhook no longer active"
+			delivery.NextAttemptAt = nil
+			if err := s.webhookRepo.UpdateDelivery(delivery); err != nil {
+				fmt.Printf("⚠️  Warning: failed to update webhook delivery %s: %v\n", delivery.ID, err)
+			}
+			continue
+		}
+
+		if err := s.attemptDelivery(webhook, delivery, true); err != nil {
+			fmt.Printf("⚠️  Webhook delivery %s attempt %d failed: %v\n", delivery.ID, delivery.AttemptCount, err)
+		}
+	}
+
+	return len(deliveries), nil
+}
+
+// StartRetryWorker polls for due deliveries until the context is cancelled
+func (s *WebhookService) StartRetryWorker(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := s.ProcessDueDeliveries(ctx); err != nil {
+				fmt.Printf("⚠️  Warning: webhook retry worker: %v\n", err)
+			}
+		}
+	}
+}
+
+// GetDeliveries returns the delivery history for a webhook, newest first
+func (s *WebhookService) GetDeliveries(ctx context.Context, webhookID uuid.UUID, limit, offset int) ([]*domain.WebhookDelivery, error) {
+	return s.webhookRepo.GetDeliveries(webhookID, limit, offset)
+}
+
+// newDelivery marshals the payload and persists a pending delivery record
+func (s *WebhookService) newDelivery(webhook *domain.Webhook, event domain.WebhookEvent, payload interface{}) (*domain.WebhookDelivery, error) {
 	jsonData, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	delivery := &domain.WebhookDelivery{
+		ID:        uuid.New(),
+		WebhookID: webhook.ID,
+		Event:     event,
+		Payload:   string(jsonData),
+		Status:    domain.WebhookDeliveryPending,
+		CreatedAt: time.Now().UTC(),
+	}
+
+	if err := s.webhookRepo.RecordDelivery(delivery); err != nil {
+		return nil, fmt.Errorf("failed to record webhook delivery: %w", err)
+	}
+
+	return delivery, nil
+}
+
+// attemptDelivery POSTs the delivery payload and updates its retry state.
+// On non-2xx or transport errors the delivery is rescheduled until maxAttempts is reached.
+func (s *WebhookService) attemptDelivery(webhook *domain.Webhook, delivery *domain.WebhookDelivery, retry bool) error {
+	now := time.Now().UTC()
+	delivery.AttemptCount++
+	delivery.LastAttemptAt = &now
+
+	statusCode, sendErr := s.post(webhook, delivery)
+	delivery.StatusCode = statusCode
+	delivery.Success = sendErr == nil
+
+	switch {
+	case delivery.Success:
+		delivery.Status = domain.WebhookDeliverySucceeded
+		delivery.ErrorMessage = ""
+		delivery.NextAttemptAt = nil
+	case retry && delivery.AttemptCount < s.maxAttempts:
+		nextAttempt := now.Add(s.retryDelay(delivery.AttemptCount))
+		delivery.Status = domain.WebhookDeliveryRetrying
+		delivery.ErrorMessage = sendErr.Error()
+		delivery.NextAttemptAt = &nextAttempt
+	default:
+		delivery.Status = domain.WebhookDeliveryFailed
+		delivery.ErrorMessage = sendErr.Error()
+		delivery.NextAttemptAt = nil
+	}
+
+	if err := s.webhookRepo.UpdateDelivery(delivery); err != nil {
+		fmt.Printf("⚠️  Warning: failed to update webhook delivery %s: %v\n", delivery.ID, err)
+	}
+
+	return sendErr
+}
+
+// webhookResponseDrainLimit bounds how much of a receiver's response is read before the
+// connection is released
+const webhookResponseDrainLimit = 64 << 10
+
+// post sends the signed payload and returns the response status code. The response body is
+// discarded: echoing it back would let members read internal endpoints through AIM.
+func (s *WebhookService) post(webhook *domain.Webhook, delivery *domain.WebhookDelivery) (int, error) {
+	jsonData := []byte(delivery.Payload)
+
+	signingKey, err := s.signingKey(webhook)
 	if err != nil {
 		return 0, err
 	}
 
-	// Create signature
-	signature := createSignature(jsonData, webhook.Secret)
+	// Sign timestamp + body so a captured delivery cannot be replayed later
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	signature := createSignature(signingKey, timestamp, jsonData)
 
 	// Send HTTP request
 	req, err := http.NewRequest("POST", webhook.URL, bytes.NewBuffer(jsonData))
@@ -164,45 +551,48 @@ func (s *WebhookService) sendWebhookWithResult(webhook *domain.Webhook, event st
 	}
 
 	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("X-Webhook-Signature", signature)
-	req.Header.Set("X-Webhook-Event", event)
+	req.Header.Set("X-AIM-Signature", "sha256="+signature)
+	req.Header.Set("X-AIM-Timestamp", timestamp)
+	req.Header.Set("X-Webhook-Event", string(delivery.Event))
+	req.Header.Set("X-Webhook-Delivery", delivery.ID.String())
 
-	client := &http.Client{Timeout: 10 * time.Second}
-	resp, err := client.Do(req)
+	resp, err := s.httpClient.Do(req)
 	if err != nil {
 		return 0, err
 	}
 	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, webhookResponseDrainLimit))
 
-	// Read response
-	body, _ := io.ReadAll(resp.Body)
-
-	// Record delivery
-	delivery := &domain.WebhookDelivery{
-		ID:           uuid.New(),
-		WebhookID:    webhook.ID,
-		Event:        domain.WebhookEvent(event),
-		Payload:      string(jsonData),
-		StatusCode:   resp.StatusCode,
-		ResponseBody: string(body),
-		Success:      resp.StatusCode >= 200 && resp.StatusCode < 300,
-		AttemptCount: 1,
-		CreatedAt:    time.Now().UTC(),
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("webhook delivery failed with status %d", resp.StatusCode)
 	}
 
-	s.webhookRepo.RecordDelivery(delivery)
+	return resp.StatusCode, nil
+}
 
-	if !delivery.Success {
-		return resp.StatusCode, fmt.Errorf("webhook delivery failed with status %d", resp.StatusCode)
+// signingKey returns the HMAC key for a webhook's deliveries: its decrypted secret. A webhook
+// without an encrypted secret is not delivered until its secret is rotated.
+func (s *WebhookService) signingKey(webhook *domain.Webhook) (string, error) {
+	if webhook.EncryptedSecret == "" {
+		return "", fmt.Errorf("webhook %s: %w", webhook.ID, ErrWebhookSecretNotEncrypted)
 	}
 
-	return resp.StatusCode, nil
+	secret, err := s.keyVault.DecryptPrivateKey(webhook.EncryptedSecret)
+	if err != nil {
+		return "", fmt.Errorf("failed to decrypt webhook secret: %w", err)
+	}
+	return secret, nil
 }
 
-// sendWebhook sends a webhook payload
-func (s *WebhookService) sendWebhook(webhook *domain.Webhook, event string, payload interface{}) error {
-	_, err := s.sendWebhookWithResult(webhook, event, payload)
-	return err
+// retryDelay returns the backoff to wait after the given (1-based) failed attempt
+func (s *WebhookService) retryDelay(attempt int) time.Duration {
+	if len(s.retrySchedule) == 0 {
+		return 0
+	}
+	if attempt > len(s.retrySchedule) {
+		attempt = len(s.retrySchedule)
+	}
+	return s.retrySchedule[attempt-1]
 }
 
 // Helper functions
@@ -216,8 +606,20 @@ func generateSecret() (string, error) {
 	return hex.EncodeToString(b), nil
 }
 
-func createSignature(payload []byte, secret string) string {
-	mac := hmac.New(sha256.New, []byte(secret))
+// webhookSubscribedTo reports whether the webhook wants an event of this type about this kind of resource
+func webhookSubscribedTo(webhook *domain.Webhook, event domain.WebhookEvent, resourceType string) bool {
+	if webhook.ResourceType != nil && *webhook.ResourceType != resourceType {
+		return false
+	}
+	return slices.Contains(webhook.Events, event)
+}
+
+// createSignature computes the X-AIM-Signature value: hex HMAC-SHA256 over "<timestamp>.<body>",
+// keyed with the webhook secret
+func createSignature(key, timestamp string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(key))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
 	mac.Write(payload)
 	return hex.EncodeToString(mac.Sum(nil))
 }
@@ -0,0 +1,572 @@
+package application
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/crypto"
+	"github.com/opena2a/identity/backend/internal/domain"
+	"github.com/opena2a/identity/backend/internal/infrastructure/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// MockWebhookRepository is a mock implementation of domain.WebhookRepository
+type MockWebhookRepository struct {
+	mock.Mock
+}
+
+func (m *MockWebhookRepository) Create(webhook *domain.Webhook) error {
+	args := m.Called(webhook)
+	return args.Error(0)
+}
+
+func (m *MockWebhookRepository) GetByID(id uuid.UUID) (*domain.Webhook, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Webhook), args.Error(1)
+}
+
+func (m *MockWebhookRepository) GetByOrganization(orgID uuid.UUID) ([]*domain.Webhook, error) {
+	args := m.Called(orgID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.Webhook), args.Error(1)
+}
+
+func (m *MockWebhookRepository) Update(webhook *domain.Webhook) error {
+	args := m.Called(webhook)
+	return args.Error(0)
+}
+
+func (m *MockWebhookRepository) Delete(id uuid.UUID) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func (m *MockWebhookRepository) UpdateSecret(id uuid.UUID, encryptedSecret string) error {
+	args := m.Called(id, encryptedSecret)
+	return args.Error(0)
+}
+
+func (m *MockWebhookRepository) GetPlaintextSecrets() (map[uuid.UUID]string, error) {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[uuid.UUID]string), args.Error(1)
+}
+
+func (m *MockWebhookRepository) RecordDelivery(delivery *domain.WebhookDelivery) error {
+	args := m.Called(delivery)
+	return args.Error(0)
+}
+
+func (m *MockWebhookRepository) UpdateDelivery(delivery *domain.WebhookDelivery) error {
+	args := m.Called(delivery)
+	return args.Error(0)
+}
+
+func (m *MockWebhookRepository) GetDeliveries(webhookID uuid.UUID, limit, offset int) ([]*domain.WebhookDelivery, error) {
+	args := m.Called(webhookID, limit, offset)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.WebhookDelivery), args.Error(1)
+}
+
+func (m *MockWebhookRepository) ClaimDueDeliveries(now time.Time, lease time.Duration, limit int) ([]*domain.WebhookDelivery, error) {
+	args := m.Called(now, lease, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.WebhookDelivery), args.Error(1)
+}
+
+// newFlakyWebhookServer returns a server that responds 503 for the first failures requests, then 200
+func newFlakyWebhookServer(failures int32) (*httptest.Server, *int32) {
+	var hits int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&hits, 1) <= failures {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"received":true}`))
+	}))
+	return server, &hits
+}
+
+// testWebhookKeyVault encrypts webhook secrets in tests
+var testWebhookKeyVault, _ = crypto.NewKeyVault("MDEyMzQ1Njc4OTAxMjM0NTY3ODkwMTIzNDU2Nzg5MDE=")
+
+// encryptWebhookSecret returns secret encrypted the way webhooks store it
+func encryptWebhookSecret(t *testing.T, secret string) string {
+	encrypted, err := testWebhookKeyVault.EncryptPrivateKey(secret)
+	require.NoError(t, err)
+	return encrypted
+}
+
+func newTestWebhookService(repo domain.WebhookRepository, maxAttempts int) *WebhookService {
+	return &WebhookService{
+		webhookRepo:   repo,
+		keyVault:      testWebhookKeyVault,
+		httpClient:    &http.Client{Timeout: time.Second},
+		retrySchedule: []time.Duration{0},
+		maxAttempts:   maxAttempts,
+	}
+}
+
+func TestWebhookService_DeliverEvent_RetriesUntilSuccess(t *testing.T) {
+	server, hits := newFlakyWebhookServer(2)
+	defer server.Close()
+
+	webhook := &domain.Webhook{ID: uuid.New(), URL: server.URL, EncryptedSecret: encryptWebhookSecret(t, "secret"), IsActive: true}
+	mockRepo := new(MockWebhookRepository)
+	mockRepo.On("RecordDelivery", mock.AnythingOfType("*domain.WebhookDelivery")).Return(nil)
+	mockRepo.On("UpdateDelivery", mock.AnythingOfType("*domain.WebhookDelivery")).Return(nil)
+	mockRepo.On("GetByID", webhook.ID).Return(webhook, nil)
+
+	service := newTestWebhookService(mockRepo, DefaultWebhookMaxAttempts)
+
+	// First attempt fails and is queued for retry
+	delivery, err := service.DeliverEvent(webhook, domain.WebhookEventAlertCreated, map[string]string{"alert": "test"})
+	assert.Error(t, err)
+	assert.Equal(t, domain.WebhookDeliveryRetrying, delivery.Status)
+	assert.Equal(t, 1, delivery.AttemptCount)
+	assert.NotNil(t, delivery.NextAttemptAt)
+
+	mockRepo.On("ClaimDueDeliveries", mock.AnythingOfType("time.Time"), webhookClaimLease, 100).Return([]*domain.WebhookDelivery{delivery}, nil)
+
+	// Second attempt fails again
+	processed, err := service.ProcessDueDeliveries(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 1, processed)
+	assert.Equal(t, domain.WebhookDeliveryRetrying, delivery.Status)
+	assert.Equal(t, 2, delivery.AttemptCount)
+	assert.Equal(t, http.StatusServiceUnavailable, delivery.StatusCode)
+
+	// Third attempt succeeds
+	_, err = service.ProcessDueDeliveries(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, domain.WebhookDeliverySucceeded, delivery.Status)
+	assert.Equal(t, 3, delivery.AttemptCount)
+	assert.Equal(t, http.StatusOK, delivery.StatusCode)
+	assert.True(t, delivery.Success)
+	assert.Nil(t, delivery.NextAttemptAt)
+	assert.Empty(t, delivery.ErrorMessage)
+	assert.Equal(t, int32(3), atomic.LoadInt32(hits))
+}
+
+func TestWebhookService_DeliverEvent_FailsAfterMaxAttempts(t *testing.T) {
+	server, hits := newFlakyWebhookServer(10)
+	defer server.Close()
+
+	webhook := &domain.Webhook{ID: uuid.New(), URL: server.URL, EncryptedSecret: encryptWebhookSecret(t, "secret"), IsActive: true}
+	mockRepo := new(MockWebhookRepository)
+	mockRepo.On("RecordDelivery", mock.AnythingOfType("*domain.WebhookDelivery")).Return(nil)
+	mockRepo.On("UpdateDelivery", mock.AnythingOfType("*domain.WebhookDelivery")).Return(nil)
+	mockRepo.On("GetByID", webhook.ID).Return(webhook, nil)
+
+	service := newTestWebhookService(mockRepo, 2)
+
+	delivery, _ := service.DeliverEvent(webhook, domain.WebhookEventAlertCreated, map[string]string{"alert": "test"})
+	mockRepo.On("ClaimDueDeliveries", mock.AnythingOfType("time.Time"), webhookClaimLease, 100).Return([]*domain.WebhookDelivery{delivery}, nil)
+
+	_, err := service.ProcessDueDeliveries(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, domain.WebhookDeliveryFailed, delivery.Status)
+	assert.Equal(t, 2, delivery.AttemptCount)
+	assert.Nil(t, delivery.NextAttemptAt)
+	assert.Equal(t, int32(2), atomic.LoadInt32(hits))
+}
+
+func TestWebhookService_retryDelay_Backoff(t *testing.T) {
+	service := &WebhookService{retrySchedule: webhookRetrySchedule}
+
+	assert.Equal(t, 1*time.Second, service.retryDelay(1))
+	assert.Equal(t, 5*time.Second, service.retryDelay(2))
+	assert.Equal(t, 30*time.Second, service.retryDelay(3))
+	assert.Equal(t, 2*time.Minute, service.retryDelay(4))
+	assert.Equal(t, 10*time.Minute, service.retryDelay(5))
+	assert.Equal(t, 10*time.Minute, service.retryDelay(8))
+}
+
+// verifyAIMSignature is the receiver-side verification recipe for AIM webhooks:
+//  1. Reject the request if X-AIM-Timestamp is too far from the current time (replay protection).
+//  2. Compute expected = hex(HMAC-SHA256(secret, X-AIM-Timestamp + "." + raw request body)), using
+//     the secret shown on creation/rotation as the key.
+//  3. Compare "sha256=" + expected with X-AIM-Signature using a constant-time comparison.
+func verifyAIMSignature(secret, timestamp, signatureHeader string, body []byte, now time.Time, tolerance time.Duration) bool {
+	ts, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return false
+	}
+	if delta := now.Sub(time.Unix(ts, 0)); delta > tolerance || delta < -tolerance {
+		return false
+	}
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(body)
+	expected := "sha256=" + hex.EncodeToString(mac.Sum(nil))
+
+	return hmac.Equal([]byte(expected), []byte(signatureHeader))
+}
+
+func TestCreateSignature_KnownVector(t *testing.T) {
+	secret := "whsec_test_secret"
+	body := []byte(`{"event":"agent.created","data":{"agent_id":"123"}}`)
+
+	signature := createSignature(secret, "1700000000", body)
+	assert.Equal(t, "fbc35afd580b519570dd443abe6baca6ae47117d4feea18f2aec43ad150dd305", signature)
+
+	now := time.Unix(1700000060, 0)
+	assert.True(t, verifyAIMSignature(secret, "1700000000", "sha256="+signature, body, now, 5*time.Minute))
+	assert.False(t, verifyAIMSignature("wrong-secret", "1700000000", "sha256="+signature, body, now, 5*time.Minute))
+	assert.False(t, verifyAIMSignature(secret, "1700000001", "sha256="+signature, body, now, 5*time.Minute))
+	assert.False(t, verifyAIMSignature(secret, "1700000000", "sha256="+signature, body, time.Unix(1700001000, 0), 5*time.Minute))
+}
+
+func TestWebhookService_DeliverEvent_SendsAIMSignatureHeaders(t *testing.T) {
+	secret := "whsec_delivery_secret"
+
+	var signatureHeader, timestampHeader string
+	var receivedBody []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		signatureHeader = r.Header.Get("X-AIM-Signature")
+		timestampHeader = r.Header.Get("X-AIM-Timestamp")
+		receivedBody, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	webhook := &domain.Webhook{ID: uuid.New(), URL: server.URL, EncryptedSecret: encryptWebhookSecret(t, secret), IsActive: true}
+	mockRepo := new(MockWebhookRepository)
+	mockRepo.On("RecordDelivery", mock.AnythingOfType("*domain.WebhookDelivery")).Return(nil)
+	mockRepo.On("UpdateDelivery", mock.AnythingOfType("*domain.WebhookDelivery")).Return(nil)
+
+	service := newTestWebhookService(mockRepo, DefaultWebhookMaxAttempts)
+	_, err := service.DeliverEvent(webhook, domain.WebhookEventAgentCreated, map[string]string{"agent_id": "123"})
+
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(signatureHeader, "sha256="))
+	assert.NotEmpty(t, timestampHeader)
+	assert.True(t, verifyAIMSignature(secret, timestampHeader, signatureHeader, receivedBody, time.Now(), 5*time.Minute))
+}
+
+func TestWebhookService_DeliverEvent_RequiresEncryptedSecret(t *testing.T) {
+	// A webhook without an encrypted secret must be rotated; it is never signed with anything else
+	var received int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&received, 1)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	webhook := &domain.Webhook{ID: uuid.New(), URL: server.URL, IsActive: true}
+	mockRepo := new(MockWebhookRepository)
+	mockRepo.On("RecordDelivery", mock.AnythingOfType("*domain.WebhookDelivery")).Return(nil)
+	mockRepo.On("UpdateDelivery", mock.AnythingOfType("*domain.WebhookDelivery")).Return(nil)
+
+	service := newTestWebhookService(mockRepo, DefaultWebhookMaxAttempts)
+	_, err := service.DeliverEvent(webhook, domain.WebhookEventAgentCreated, map[string]string{"agent_id": "123"})
+
+	assert.ErrorIs(t, err, ErrWebhookSecretNotEncrypted)
+	assert.Zero(t, atomic.LoadInt32(&received))
+}
+
+func TestWebhookService_EncryptPlaintextSecrets(t *testing.T) {
+	webhookID := uuid.New()
+	var encryptedSecret string
+	mockRepo := new(MockWebhookRepository)
+	mockRepo.On("GetPlaintextSecrets").Return(map[uuid.UUID]string{webhookID: "legacy-secret"}, nil)
+	mockRepo.On("UpdateSecret", webhookID, mock.AnythingOfType("string")).
+		Run(func(args mock.Arguments) { encryptedSecret = args.String(1) }).
+		Return(nil)
+
+	service := newTestWebhookService(mockRepo, DefaultWebhookMaxAttempts)
+	encrypted, err := service.EncryptPlaintextSecrets(context.Background())
+
+	require.NoError(t, err)
+	assert.Equal(t, 1, encrypted)
+
+	// Receivers holding the original secret keep verifying deliveries
+	signingKey, err := service.signingKey(&domain.Webhook{ID: webhookID, EncryptedSecret: encryptedSecret})
+	require.NoError(t, err)
+	assert.Equal(t, "legacy-secret", signingKey)
+}
+
+func TestWebhookService_CreateWebhook_StoresSecretEncrypted(t *testing.T) {
+	var stored *domain.Webhook
+	mockRepo := new(MockWebhookRepository)
+	mockRepo.On("Create", mock.AnythingOfType("*domain.Webhook")).
+		Run(func(args mock.Arguments) { stored = args.Get(0).(*domain.Webhook) }).
+		Return(nil)
+
+	service := newTestWebhookService(mockRepo, DefaultWebhookMaxAttempts)
+	webhook, err := service.CreateWebhook(context.Background(), &CreateWebhookRequest{
+		Name: "pagerduty", URL: "https://events.pagerduty.com/hook",
+		EventTypes: []domain.WebhookEvent{"security_breach"},
+	}, uuid.New(), uuid.New())
+
+	require.NoError(t, err)
+	require.NotNil(t, stored)
+	assert.NotEmpty(t, webhook.Secret)
+	assert.NotContains(t, stored.EncryptedSecret, webhook.Secret)
+
+	decrypted, err := testWebhookKeyVault.DecryptPrivateKey(stored.EncryptedSecret)
+	require.NoError(t, err)
+	assert.Equal(t, webhook.Secret, decrypted)
+}
+
+func TestWebhookService_RotateWebhookSecret(t *testing.T) {
+	oldSecret, err := testWebhookKeyVault.EncryptPrivateKey("old-secret")
+	require.NoError(t, err)
+	webhook := &domain.Webhook{ID: uuid.New(), EncryptedSecret: oldSecret}
+	mockRepo := new(MockWebhookRepository)
+	mockRepo.On("GetByID", webhook.ID).Return(webhook, nil)
+	mockRepo.On("UpdateSecret", webhook.ID, mock.AnythingOfType("string")).Return(nil)
+
+	service := newTestWebhookService(mockRepo, DefaultWebhookMaxAttempts)
+	rotated, err := service.RotateWebhookSecret(context.Background(), webhook.ID)
+
+	require.NoError(t, err)
+	assert.NotEmpty(t, rotated.Secret)
+	assert.NotEqual(t, oldSecret, rotated.EncryptedSecret)
+	mockRepo.AssertCalled(t, "UpdateSecret", webhook.ID, rotated.EncryptedSecret)
+
+	// The rotated webhook signs with the new secret
+	signingKey, err := service.signingKey(rotated)
+	require.NoError(t, err)
+	assert.Equal(t, rotated.Secret, signingKey)
+}
+
+// newRecordingWebhookServer returns a server that records the event named in each delivery
+func newRecordingWebhookServer() (*httptest.Server, *[]string) {
+	var received []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = append(received, r.Header.Get("X-Webhook-Event"))
+		w.WriteHeader(http.StatusOK)
+	}))
+	return server, &received
+}
+
+func TestWebhookService_TriggerEvent_OnlyDeliversSubscribedEvents(t *testing.T) {
+	pagerDuty, pagerDutyReceived := newRecordingWebhookServer()
+	defer pagerDuty.Close()
+	audit, auditReceived := newRecordingWebhookServer()
+	defer audit.Close()
+
+	orgID := uuid.New()
+	webhooks := []*domain.Webhook{
+		{
+			ID: uuid.New(), OrganizationID: orgID, URL: pagerDuty.URL, IsActive: true,
+			EncryptedSecret: encryptWebhookSecret(t, "secret"),
+			Events:          []domain.WebhookEvent{domain.WebhookEventSecurityBreach},
+		},
+		{
+			ID: uuid.New(), OrganizationID: orgID, URL: audit.URL, IsActive: true,
+			EncryptedSecret: encryptWebhookSecret(t, "secret"),
+			Events:          []domain.WebhookEvent{domain.WebhookEventAgentCreated, domain.WebhookEventSecurityBreach},
+		},
+	}
+	mockRepo := new(MockWebhookRepository)
+	mockRepo.On("GetByOrganization", orgID).Return(webhooks, nil)
+	mockRepo.On("RecordDelivery", mock.AnythingOfType("*domain.WebhookDelivery")).Return(nil)
+	mockRepo.On("UpdateDelivery", mock.AnythingOfType("*domain.WebhookDelivery")).Return(nil)
+
+	service := newTestWebhookService(mockRepo, DefaultWebhookMaxAttempts)
+
+	assert.NoError(t, service.TriggerEvent(context.Background(), orgID, domain.WebhookEventAgentCreated, domain.WebhookResourceAgent, nil))
+	assert.Empty(t, *pagerDutyReceived, "security_breach-only webhook must not receive agent.created")
+	assert.Equal(t, []string{"agent.created"}, *auditReceived)
+
+	assert.NoError(t, service.TriggerEvent(context.Background(), orgID, domain.WebhookEventSecurityBreach, domain.WebhookResourceAgent, nil))
+	assert.Equal(t, []string{"security_breach"}, *pagerDutyReceived)
+	assert.Equal(t, []string{"agent.created", "security_breach"}, *auditReceived)
+}
+
+func TestWebhookService_TriggerEvent_FiltersByResourceType(t *testing.T) {
+	server, received := newRecordingWebhookServer()
+	defer server.Close()
+
+	orgID := uuid.New()
+	mcpOnly := domain.WebhookResourceMCPServer
+	webhook := &domain.Webhook{
+		ID: uuid.New(), OrganizationID: orgID, URL: server.URL, IsActive: true,
+		EncryptedSecret: encryptWebhookSecret(t, "secret"),
+		Events:          []domain.WebhookEvent{domain.WebhookEventSecurityBreach},
+		ResourceType:    &mcpOnly,
+	}
+	mockRepo := new(MockWebhookRepository)
+	mockRepo.On("GetByOrganization", orgID).Return([]*domain.Webhook{webhook}, nil)
+	mockRepo.On("RecordDelivery", mock.AnythingOfType("*domain.WebhookDelivery")).Return(nil)
+	mockRepo.On("UpdateDelivery", mock.AnythingOfType("*domain.WebhookDelivery")).Return(nil)
+
+	service := newTestWebhookService(mockRepo, DefaultWebhookMaxAttempts)
+
+	assert.NoError(t, service.TriggerEvent(context.Background(), orgID, domain.WebhookEventSecurityBreach, domain.WebhookResourceAgent, nil))
+	assert.Empty(t, *received)
+
+	assert.NoError(t, service.TriggerEvent(context.Background(), orgID, domain.WebhookEventSecurityBreach, domain.WebhookResourceMCPServer, nil))
+	assert.Equal(t, []string{"security_breach"}, *received)
+}
+
+func TestWebhookService_CreateWebhook_ValidatesSubscription(t *testing.T) {
+	mockRepo := new(MockWebhookRepository)
+	mockRepo.On("Create", mock.AnythingOfType("*domain.Webhook")).Return(nil)
+	service := newTestWebhookService(mockRepo, DefaultWebhookMaxAttempts)
+	orgID, userID := uuid.New(), uuid.New()
+
+	_, err := service.CreateWebhook(context.Background(), &CreateWebhookRequest{
+		Name: "pagerduty", URL: "https://events.pagerduty.com/hook",
+		EventTypes: []domain.WebhookEvent{"security_breach", "agent.exploded"},
+	}, orgID, userID)
+	assert.ErrorIs(t, err, ErrInvalidWebhookEvent)
+
+	_, err = service.CreateWebhook(context.Background(), &CreateWebhookRequest{
+		Name: "pagerduty", URL: "https://events.pagerduty.com/hook",
+	}, orgID, userID)
+	assert.ErrorIs(t, err, ErrWebhookNoEvents)
+
+	badResource := "database"
+	_, err = service.CreateWebhook(context.Background(), &CreateWebhookRequest{
+		Name: "pagerduty", URL: "https://events.pagerduty.com/hook",
+		EventTypes:   []domain.WebhookEvent{"security_breach"},
+		ResourceType: &badResource,
+	}, orgID, userID)
+	assert.ErrorIs(t, err, ErrInvalidWebhookResourceType)
+	mockRepo.AssertNotCalled(t, "Create", mock.Anything)
+
+	webhook, err := service.CreateWebhook(context.Background(), &CreateWebhookRequest{
+		Name: "pagerduty", URL: "https://events.pagerduty.com/hook",
+		EventTypes: []domain.WebhookEvent{"trust_score_drop", "security_breach"},
+	}, orgID, userID)
+	assert.NoError(t, err)
+	assert.Equal(t, []domain.WebhookEvent{domain.WebhookEventTrustScoreDrop, domain.WebhookEventSecurityBreach}, webhook.Events)
+	assert.Nil(t, webhook.ResourceType)
+
+	// Legacy clients still send "events"
+	webhook, err = service.CreateWebhook(context.Background(), &CreateWebhookRequest{
+		Name: "legacy", URL: "https://example.com/hook",
+		Events: []domain.WebhookEvent{"agent.created"},
+	}, orgID, userID)
+	assert.NoError(t, err)
+	assert.Equal(t, []domain.WebhookEvent{domain.WebhookEventAgentCreated}, webhook.Events)
+}
+
+func TestWebhookService_TestWebhook_SendsSignedSampleEvent(t *testing.T) {
+	secret := "whsec_test_secret"
+
+	var signatureHeader, timestampHeader, eventHeader string
+	var receivedBody []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		signatureHeader = r.Header.Get("X-AIM-Signature")
+		timestampHeader = r.Header.Get("X-AIM-Timestamp")
+		eventHeader = r.Header.Get("X-Webhook-Event")
+		receivedBody, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusAccepted)
+		w.Write([]byte(`{"queued":true}`))
+	}))
+	defer server.Close()
+
+	webhook := &domain.Webhook{ID: uuid.New(), OrganizationID: uuid.New(), URL: server.URL, EncryptedSecret: encryptWebhookSecret(t, secret), IsActive: true}
+	mockRepo := new(MockWebhookRepository)
+	mockRepo.On("GetByID", webhook.ID).Return(webhook, nil)
+	mockRepo.On("RecordDelivery", mock.AnythingOfType("*domain.WebhookDelivery")).Return(nil)
+	mockRepo.On("UpdateDelivery", mock.AnythingOfType("*domain.WebhookDelivery")).Return(nil)
+
+	service := newTestWebhookService(mockRepo, DefaultWebhookMaxAttempts)
+	result, err := service.TestWebhook(context.Background(), webhook.ID)
+
+	assert.NoError(t, err)
+	assert.True(t, result.Success)
+	assert.Equal(t, http.StatusAccepted, result.StatusCode)
+	assert.Equal(t, domain.WebhookEventSecurityBreach, result.Event)
+	assert.Positive(t, result.Latency)
+
+	// The sample is signed exactly like a production delivery
+	assert.Equal(t, string(domain.WebhookEventSecurityBreach), eventHeader)
+	assert.True(t, verifyAIMSignature(secret, timestampHeader, signatureHeader, receivedBody, time.Now(), 5*time.Minute))
+
+	var payload map[string]interface{}
+	assert.NoError(t, json.Unmarshal(receivedBody, &payload))
+	assert.Equal(t, "security_breach", payload["event"])
+	assert.Equal(t, true, payload["test"])
+	assert.Equal(t, webhook.OrganizationID.String(), payload["data"].(map[string]interface{})["organization_id"])
+}
+
+func TestWebhookService_TestWebhook_ReportsReceiverFailure(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte("internal receiver details"))
+	}))
+	defer server.Close()
+
+	webhook := &domain.Webhook{ID: uuid.New(), URL: server.URL, EncryptedSecret: encryptWebhookSecret(t, "secret"), IsActive: true}
+	mockRepo := new(MockWebhookRepository)
+	mockRepo.On("GetByID", webhook.ID).Return(webhook, nil)
+	mockRepo.On("RecordDelivery", mock.AnythingOfType("*domain.WebhookDelivery")).Return(nil)
+	mockRepo.On("UpdateDelivery", mock.AnythingOfType("*domain.WebhookDelivery")).Return(nil)
+
+	service := newTestWebhookService(mockRepo, DefaultWebhookMaxAttempts)
+	result, err := service.TestWebhook(context.Background(), webhook.ID)
+
+	assert.NoError(t, err)
+	assert.False(t, result.Success)
+	assert.Equal(t, http.StatusInternalServerError, result.StatusCode)
+	assert.Contains(t, result.ErrorMessage, "500")
+	// The receiver's response body is never reported back
+	assert.NotContains(t, result.ErrorMessage, "internal receiver details")
+}
+
+func TestWebhookService_RefusesInternalURLs(t *testing.T) {
+	mockRepo := new(MockWebhookRepository)
+	service := NewWebhookService(mockRepo, testWebhookKeyVault)
+	orgID, userID := uuid.New(), uuid.New()
+
+	for _, rawURL := range []string{"http://127.0.0.1:8080/admin", "http://169.254.169.254/latest/meta-data/", "file:///etc/passwd"} {
+		_, err := service.CreateWebhook(context.Background(), &CreateWebhookRequest{
+			Name: "internal", URL: rawURL, EventTypes: []domain.WebhookEvent{"security_breach"},
+		}, orgID, userID)
+		assert.ErrorIs(t, err, ErrInvalidWebhookURL, rawURL)
+
+		_, err = service.UpdateWebhook(context.Background(), uuid.New(), &CreateWebhookRequest{
+			Name: "internal", URL: rawURL, EventTypes: []domain.WebhookEvent{"security_breach"},
+		})
+		assert.ErrorIs(t, err, ErrInvalidWebhookURL, rawURL)
+	}
+	mockRepo.AssertNotCalled(t, "Create", mock.Anything)
+
+	// Deliveries to a URL that was saved earlier are refused at dial time
+	var hits int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+	}))
+	defer server.Close()
+
+	webhook := &domain.Webhook{ID: uuid.New(), URL: server.URL, EncryptedSecret: encryptWebhookSecret(t, "secret"), IsActive: true}
+	mockRepo.On("RecordDelivery", mock.AnythingOfType("*domain.WebhookDelivery")).Return(nil)
+	mockRepo.On("UpdateDelivery", mock.AnythingOfType("*domain.WebhookDelivery")).Return(nil)
+
+	_, err := service.DeliverEvent(webhook, domain.WebhookEventSecurityBreach, map[string]string{"alert": "test"})
+	assert.ErrorIs(t, err, utils.ErrNonPublicAddress)
+	assert.Zero(t, atomic.LoadInt32(&hits))
+}
@@ -2,6 +2,7 @@ package config
 
 import (
 	"fmt"
+	"net/url"
 	"os"
 	"strconv"
 	"time"
@@ -10,10 +11,12 @@ import (
 // Config holds all configuration for the application
 type Config struct {
 	Server   ServerConfig
+	HTTP     HTTPConfig
 	Database DatabaseConfig
 	Redis    RedisConfig
-	JWT      JWTConfig
-	OAuth    OAuthConfig
+	JWT       JWTConfig
+	OAuth     OAuthConfig
+	RateLimit RateLimitConfig
 }
 
 // ServerConfig holds server configuration
@@ -24,6 +27,18 @@ type ServerConfig struct {
 	FrontendURL string
 }
 
+// HTTPConfig holds the HTTP server's timeouts and request body limits. The defaults apply to
+// every route; SDK downloads and exports get longer write timeouts for their large responses,
+// and bulk endpoints their own body limit, without loosening verification routes.
+type HTTPConfig struct {
+	ReadTimeout        time.Duration
+	WriteTimeout       time.Duration
+	BodyLimit          int // bytes
+	SDKWriteTimeout    time.Duration
+	ExportWriteTimeout time.Duration
+	BulkBodyLimit      int // bytes
+}
+
 // DatabaseConfig holds database configuration
 type DatabaseConfig struct {
 	Host            string
@@ -34,6 +49,10 @@ type DatabaseConfig struct {
 	SSLMode         string
 	MaxConnections  int
 	ConnMaxLifetime time.Duration
+
+	// Startup connection retries while Postgres comes up; the interval doubles after each failure
+	ConnectAttempts      int
+	ConnectRetryInterval time.Duration
 }
 
 // RedisConfig holds Redis configuration
@@ -44,18 +63,32 @@ type RedisConfig struct {
 	DB       int
 }
 
+// RateLimitConfig holds per-agent and per-organization rate limits.
+// Each limit is a token bucket holding up to N requests that refills over Window.
+type RateLimitConfig struct {
+	AgentRequests int
+	OrgRequests   int
+	Window        time.Duration
+}
+
 // JWTConfig holds JWT configuration
 type JWTConfig struct {
 	Secret          string
 	AccessTokenTTL  time.Duration
 	RefreshTokenTTL time.Duration
+	Issuer          string
+	Audience        string
+	SigningKeys     string // Comma-separated kid=path entries of RS256 private keys
+	SigningKeyID    string
+	RejectHS256     bool   // Stop accepting tokens signed with Secret once every client holds RS256 tokens
 }
 
 // OAuthConfig holds OAuth provider configurations
 type OAuthConfig struct {
-	Google    OAuthProvider
-	Microsoft OAuthProvider
-	Okta      OktaProvider
+	Google        OAuthProvider
+	Microsoft     OAuthProvider
+	Okta          OktaProvider
+	Introspection OAuthIntrospectionConfig
 }
 
 // OAuthProvider holds OAuth provider configuration
@@ -73,6 +106,15 @@ type OktaProvider struct {
 	RedirectURL  string
 }
 
+// OAuthIntrospectionConfig holds the RFC 7662 token introspection endpoint that OAuth
+// verifications check agent access tokens against; OAuth verification is off when URL is empty
+type OAuthIntrospectionConfig struct {
+	URL          string
+	ClientID     string
+	ClientSecret string
+	Timeout      time.Duration
+}
+
 // Load loads configuration from environment variables
 func Load() (*Config, error) {
 	config := &Config{
@@ -82,6 +124,14 @@ func Load() (*Config, error) {
 			LogLevel:    getEnv("LOG_LEVEL", "info"),
 			FrontendURL: getEnv("FRONTEND_URL", "http://localhost:3000"),
 		},
+		HTTP: HTTPConfig{
+			ReadTimeout:        getEnvAsDuration("HTTP_READ_TIMEOUT", 30*time.Second),
+			WriteTimeout:       getEnvAsDuration("HTTP_WRITE_TIMEOUT", 30*time.Second),
+			BodyLimit:          getEnvAsInt("HTTP_BODY_LIMIT", 4*1024*1024),
+			SDKWriteTimeout:    getEnvAsDuration("HTTP_SDK_WRITE_TIMEOUT", 5*time.Minute),
+			ExportWriteTimeout: getEnvAsDuration("HTTP_EXPORT_WRITE_TIMEOUT", 5*time.Minute),
+			BulkBodyLimit:      getEnvAsInt("HTTP_BULK_BODY_LIMIT", 16*1024*1024),
+		},
 	Database: DatabaseConfig{
 		Host:            getEnvRequired("POSTGRES_HOST"),
 		Port:            getEnvAsInt("POSTGRES_PORT", 5432),
@@ -91,6 +141,9 @@ func Load() (*Config, error) {
 		SSLMode:         getEnv("POSTGRES_SSL_MODE", "disable"),
 		MaxConnections:  getEnvAsInt("POSTGRES_MAX_CONNECTIONS", 25),
 		ConnMaxLifetime: getEnvAsDuration("POSTGRES_CONN_MAX_LIFETIME", 5*time.Minute),
+
+		ConnectAttempts:      getEnvAsInt("POSTGRES_CONNECT_ATTEMPTS", 10),
+		ConnectRetryInterval: getEnvAsDuration("POSTGRES_CONNECT_RETRY_INTERVAL", time.Second),
 	},
 		Redis: RedisConfig{
 			Host:     getEnv("REDIS_HOST", "localhost"),
@@ -102,7 +155,17 @@ func Load() (*Config, error) {
 		Secret:          getEnvRequired("JWT_SECRET"),
 		AccessTokenTTL:  getEnvAsDuration("JWT_ACCESS_TTL", 24*time.Hour),
 		RefreshTokenTTL: getEnvAsDuration("JWT_REFRESH_TTL", 7*24*time.Hour),
+		Issuer:          getEnv("JWT_ISSUER", "agent-identity-management"),
+		Audience:        getEnv("JWT_AUDIENCE", ""),
+		SigningKeys:     getEnv("JWT_SIGNING_KEYS", ""),
+		SigningKeyID:    getEnv("JWT_SIGNING_KEY_ID", ""),
+		RejectHS256:     getEnvAsBool("JWT_REJECT_HS256", false),
 	},
+		RateLimit: RateLimitConfig{
+			AgentRequests: getEnvAsInt("RATE_LIMIT_AGENT_REQUESTS", 60),
+			OrgRequests:   getEnvAsInt("RATE_LIMIT_ORG_REQUESTS", 1000),
+			Window:        getEnvAsDuration("RATE_LIMIT_WINDOW", time.Minute),
+		},
 		OAuth: OAuthConfig{
 			Google: OAuthProvider{
 				ClientID:     getEnv("GOOGLE_CLIENT_ID", ""),
@@ -120,6 +183,12 @@ func Load() (*Config, error) {
 				Domain:       getEnv("OKTA_DOMAIN", ""),
 				RedirectURL:  getEnv("OKTA_REDIRECT_URL", "http://localhost:8080/api/v1/auth/callback/okta"),
 			},
+			Introspection: OAuthIntrospectionConfig{
+				URL:          getEnv("OAUTH_INTROSPECTION_URL", ""),
+				ClientID:     getEnv("OAUTH_INTROSPECTION_CLIENT_ID", ""),
+				ClientSecret: getEnv("OAUTH_INTROSPECTION_CLIENT_SECRET", ""),
+				Timeout:      getEnvAsDuration("OAUTH_INTROSPECTION_TIMEOUT", 5*time.Second),
+			},
 		},
 	}
 
@@ -141,6 +210,27 @@ func (c *Config) Validate() error {
 		return fmt.Errorf("JWT_SECRET must be at least 32 characters")
 	}
 
+	if c.JWT.AccessTokenTTL <= 0 || c.JWT.RefreshTokenTTL <= 0 {
+		return fmt.Errorf("JWT_ACCESS_TTL and JWT_REFRESH_TTL must be positive durations")
+	}
+
+	if c.HTTP.ReadTimeout <= 0 || c.HTTP.WriteTimeout <= 0 || c.HTTP.SDKWriteTimeout <= 0 || c.HTTP.ExportWriteTimeout <= 0 {
+		return fmt.Errorf("HTTP_READ_TIMEOUT, HTTP_WRITE_TIMEOUT, HTTP_SDK_WRITE_TIMEOUT and HTTP_EXPORT_WRITE_TIMEOUT must be positive durations")
+	}
+
+	if c.HTTP.BodyLimit <= 0 || c.HTTP.BulkBodyLimit <= 0 {
+		return fmt.Errorf("HTTP_BODY_LIMIT and HTTP_BULK_BODY_LIMIT must be positive byte counts")
+	}
+
+	if introspection := c.OAuth.Introspection; introspection.URL != "" {
+		if u, err := url.Parse(introspection.URL); err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+			return fmt.Errorf("OAUTH_INTROSPECTION_URL must be an absolute http(s) URL")
+		}
+		if introspection.Timeout <= 0 {
+			return fmt.Errorf("OAUTH_INTROSPECTION_TIMEOUT must be a positive duration")
+		}
+	}
+
 	// OAuth providers are now optional since we support email/password authentication
 	// Validation removed - OAuth configuration is checked at runtime when needed
 
@@ -168,6 +258,18 @@ func getEnvAsInt(key string, defaultValue int) int {
 	return value
 }
 
+func getEnvAsBool(key string, defaultValue bool) bool {
+	valueStr := os.Getenv(key)
+	if valueStr == "" {
+		return defaultValue
+	}
+	value, err := strconv.ParseBool(valueStr)
+	if err != nil {
+		return defaultValue
+	}
+	return value
+}
+
 func getEnvAsDuration(key string, defaultValue time.Duration) time.Duration {
 	valueStr := os.Getenv(key)
 	if valueStr == "" {
@@ -46,6 +46,12 @@ type Agent struct {
 	LastCapabilityCheckAt    *time.Time  `json:"lastCapabilityCheckAt"`
 	CapabilityViolationCount int         `json:"capabilityViolationCount"`
 	IsCompromised            bool        `json:"isCompromised"`
+	// Set with IsCompromised when a user flags the agent (POST /agents/:id/compromise)
+	CompromisedAt            *time.Time  `json:"compromisedAt,omitempty"`
+	CompromisedBy            *uuid.UUID  `json:"compromisedBy,omitempty"`
+	CompromiseReason         string      `json:"compromiseReason,omitempty"`
+	// OAuth client the agent authenticates as; tokens issued to other clients do not verify it
+	OAuthClientID            string      `json:"oauthClientId,omitempty"`
 	// Capability-based access control (simple MVP)
 	TalksTo                  []string    `json:"talksTo"` // List of MCP server names/IDs this agent can communicate with
 	Capabilities             []string    `json:"capabilities"` // Agent capabilities (e.g., ["file:read", "api:call"])
@@ -60,20 +66,66 @@ type Agent struct {
 	CreatedBy                uuid.UUID   `json:"createdBy"`
 	// Tags applied to this agent (populated by join)
 	Tags                     []Tag       `json:"tags"`
+	// Key-value labels (e.g. env=prod) for filtering and policy targeting
+	Labels                   map[string]string `json:"labels"`
 	// Track when agent last performed an action (updated on every verify-action call)
 	LastActive               *time.Time  `json:"lastActive"`
+	// Set when the agent is soft-deleted
+	DeletedAt                *time.Time  `json:"deletedAt,omitempty"`
+}
+
+// AgentCursor identifies the last agent of a page when paging agents ordered
+// by created_at DESC, id DESC
+type AgentCursor struct {
+	CreatedAt time.Time
+	ID        uuid.UUID
+}
+
+// AgentSearchFilter narrows an agent listing. Zero-valued fields are ignored
+// and the remaining filters are combined with AND.
+type AgentSearchFilter struct {
+	Status    AgentStatus
+	AgentType AgentType
+	MinTrust  *float64
+	Tag       string            // Tag key, or "key:value" to match a specific value
+	Query     string            // Case-insensitive substring of name or display_name
+	Labels    map[string]string // Label selectors; every one must match
+	// IncludeDeleted also returns soft-deleted agents
+	IncludeDeleted bool
+}
+
+// IsEmpty reports whether no filter is set
+func (f AgentSearchFilter) IsEmpty() bool {
+	return f.Status == "" && f.AgentType == "" && f.MinTrust == nil && f.Tag == "" && f.Query == "" && len(f.Labels) == 0 && !f.IncludeDeleted
 }
 
 // AgentRepository defines the interface for agent persistence
 type AgentRepository interface {
 	Create(agent *Agent) error
+	CreateBatch(agents []*Agent, atomic bool) ([]error, error)
 	GetByID(id uuid.UUID) (*Agent, error)
+	GetByIDIncludingDeleted(id uuid.UUID) (*Agent, error)
 	GetByName(orgID uuid.UUID, name string) (*Agent, error)
 	GetByOrganization(orgID uuid.UUID) ([]*Agent, error)
+	GetByOrganizationPaginated(orgID uuid.UUID, limit, offset int, after *AgentCursor) ([]*Agent, int, error)
+	CountByOrganization(orgID uuid.UUID) (int, error) // Excludes soft-deleted agents
+	Search(orgID uuid.UUID, filter AgentSearchFilter, limit, offset int, after *AgentCursor) ([]*Agent, int, error)
 	Update(agent *Agent) error
+	SoftDelete(id uuid.UUID) error
 	Delete(id uuid.UUID) error
 	List(limit, offset int) ([]*Agent, error)
 	UpdateTrustScore(id uuid.UUID, newScore float64) error
+	UpdateLabels(id uuid.UUID, labels map[string]string) error
+	UpdateOAuthClientID(id uuid.UUID, clientID string) error // "" clears it
 	MarkAsCompromised(id uuid.UUID) error
+	// MarkCompromisedBy flags the agent as compromised by userID and suspends it; messages are
+	// queued in the same transaction
+	MarkCompromisedBy(id, userID uuid.UUID, reason string, at time.Time, messages ...*OutboxMessage) error
+	// ClearCompromised clears the compromise flag, leaving the agent suspended until reactivated
+	ClearCompromised(id uuid.UUID, messages ...*OutboxMessage) error
+	// UpdateStatus persists the agent's status, verified_at and trust score; messages are queued
+	// in the same transaction
+	UpdateStatus(agent *Agent, messages ...*OutboxMessage) error
 	UpdateLastActive(ctx context.Context, agentID uuid.UUID) error
+	GetByKeyExpiringBetween(from, to time.Time) ([]*Agent, error)
 }
@@ -0,0 +1,118 @@
+package domain
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// AgentBaseline is a snapshot of an agent's configuration taken when it was verified
+type AgentBaseline struct {
+	AgentID        uuid.UUID `json:"agentId"`
+	OrganizationID uuid.UUID `json:"organizationId"`
+	Capabilities   []string  `json:"capabilities"`
+	TalksTo        []string  `json:"talksTo"`
+	KeyAlgorithm   string    `json:"keyAlgorithm"`
+	CapturedAt     time.Time `json:"capturedAt"`
+}
+
+// NewAgentBaseline captures the current configuration of an agent
+func NewAgentBaseline(agent *Agent, capturedAt time.Time) *AgentBaseline {
+	return &AgentBaseline{
+		AgentID:        agent.ID,
+		OrganizationID: agent.OrganizationID,
+		Capabilities:   append([]string{}, agent.Capabilities...),
+		TalksTo:        append([]string{}, agent.TalksTo...),
+		KeyAlgorithm:   agent.KeyAlgorithm,
+		CapturedAt:     capturedAt,
+	}
+}
+
+// AgentConfigDrift describes how an agent's configuration differs from its baseline
+type AgentConfigDrift struct {
+	AddedCapabilities   []string `json:"addedCapabilities,omitempty"`
+	RemovedCapabilities []string `json:"removedCapabilities,omitempty"`
+	AddedTalksTo        []string `json:"addedTalksTo,omitempty"`
+	RemovedTalksTo      []string `json:"removedTalksTo,omitempty"`
+	BaselineKeyAlgo     string   `json:"baselineKeyAlgorithm,omitempty"`
+	CurrentKeyAlgo      string   `json:"currentKeyAlgorithm,omitempty"`
+}
+
+// Diff compares an agent's current configuration against the baseline
+func (b *AgentBaseline) Diff(agent *Agent) *AgentConfigDrift {
+	drift := &AgentConfigDrift{}
+	drift.AddedCapabilities, drift.RemovedCapabilities = diffStringSets(b.Capabilities, agent.Capabilities)
+	drift.AddedTalksTo, drift.RemovedTalksTo = diffStringSets(b.TalksTo, agent.TalksTo)
+	if b.KeyAlgorithm != agent.KeyAlgorithm {
+		drift.BaselineKeyAlgo = b.KeyAlgorithm
+		drift.CurrentKeyAlgo = agent.KeyAlgorithm
+	}
+	return drift
+}
+
+// Count returns the number of individual changes
+func (d *AgentConfigDrift) Count() int {
+	count := len(d.AddedCapabilities) + len(d.RemovedCapabilities) + len(d.AddedTalksTo) + len(d.RemovedTalksTo)
+	if d.BaselineKeyAlgo != d.CurrentKeyAlgo {
+		count++
+	}
+	return count
+}
+
+// String renders the drift for alert descriptions
+func (d *AgentConfigDrift) String() string {
+	var parts []string
+	if len(d.AddedCapabilities) > 0 {
+		parts = append(parts, fmt.Sprintf("capabilities added: %s", strings.Join(d.AddedCapabilities, ", ")))
+	}
+	if len(d.RemovedCapabilities) > 0 {
+		parts = append(parts, fmt.Sprintf("capabilities removed: %s", strings.Join(d.RemovedCapabilities, ", ")))
+	}
+	if len(d.AddedTalksTo) > 0 {
+		parts = append(parts, fmt.Sprintf("MCP connections added: %s", strings.Join(d.AddedTalksTo, ", ")))
+	}
+	if len(d.RemovedTalksTo) > 0 {
+		parts = append(parts, fmt.Sprintf("MCP connections removed: %s", strings.Join(d.RemovedTalksTo, ", ")))
+	}
+	if d.BaselineKeyAlgo != d.CurrentKeyAlgo {
+		parts = append(parts, fmt.Sprintf("key algorithm changed: %s -> %s", d.BaselineKeyAlgo, d.CurrentKeyAlgo))
+	}
+	return strings.Join(parts, "; ")
+}
+
+// diffStringSets returns the sorted values present only in current (added) and only in baseline (removed)
+func diffStringSets(baseline, current []string) (added, removed []string) {
+	baselineSet := make(map[string]bool, len(baseline))
+	for _, v := range baseline {
+		baselineSet[v] = true
+	}
+	currentSet := make(map[string]bool, len(current))
+	for _, v := range current {
+		currentSet[v] = true
+	}
+
+	for v := range currentSet {
+		if !baselineSet[v] {
+			added = append(added, v)
+		}
+	}
+	for v := range baselineSet {
+		if !currentSet[v] {
+			removed = append(removed, v)
+		}
+	}
+	sort.Strings(added)
+	sort.Strings(removed)
+	return added, removed
+}
+
+// AgentBaselineRepository defines the interface for agent baseline persistence
+type AgentBaselineRepository interface {
+	// Upsert stores the baseline, replacing any previous snapshot for the agent
+	Upsert(baseline *AgentBaseline) error
+	// GetByAgentID returns the agent's baseline, or nil if none has been captured
+	GetByAgentID(agentID uuid.UUID) (*AgentBaseline, error)
+}
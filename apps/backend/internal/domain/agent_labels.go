@@ -0,0 +1,80 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Agent label limits
+const (
+	MaxAgentLabels           = 64
+	MaxAgentLabelKeyLength   = 63
+	MaxAgentLabelValueLength = 63
+)
+
+// ErrInvalidAgentLabels is returned when agent labels or label selectors are malformed
+var ErrInvalidAgentLabels = errors.New("invalid agent labels")
+
+var (
+	// Keys are lowercase alphanumerics separated by '.', '_', '-' or '/' (e.g. "env", "team.owner")
+	agentLabelKeyPattern = regexp.MustCompile(`^[a-z0-9]([a-z0-9._/-]*[a-z0-9])?$`)
+	// Values are alphanumerics separated by '.', '_' or '-', or empty
+	agentLabelValuePattern = regexp.MustCompile(`^([A-Za-z0-9]([A-Za-z0-9._-]*[A-Za-z0-9])?)?$`)
+)
+
+// ValidateAgentLabels checks label count, key and value format
+func ValidateAgentLabels(labels map[string]string) error {
+	if len(labels) > MaxAgentLabels {
+		return fmt.Errorf("%w: at most %d labels are allowed", ErrInvalidAgentLabels, MaxAgentLabels)
+	}
+	for key, value := range labels {
+		if err := validateAgentLabel(key, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func validateAgentLabel(key, value string) error {
+	if len(key) == 0 || len(key) > MaxAgentLabelKeyLength || !agentLabelKeyPattern.MatchString(key) {
+		return fmt.Errorf("%w: key %q must be 1-%d lowercase alphanumerics, '.', '_', '-' or '/', starting and ending with an alphanumeric",
+			ErrInvalidAgentLabels, key, MaxAgentLabelKeyLength)
+	}
+	if len(value) > MaxAgentLabelValueLength || !agentLabelValuePattern.MatchString(value) {
+		return fmt.Errorf("%w: value %q for key %q must be at most %d alphanumerics, '.', '_' or '-', starting and ending with an alphanumeric",
+			ErrInvalidAgentLabels, value, key, MaxAgentLabelValueLength)
+	}
+	return nil
+}
+
+// ParseAgentLabelSelectors parses "key:value" selectors, each entry optionally holding several
+// comma-separated selectors. An agent matches when it carries every selected label.
+func ParseAgentLabelSelectors(entries []string) (map[string]string, error) {
+	selectors := make(map[string]string)
+	for _, entry := range entries {
+		for _, selector := range strings.Split(entry, ",") {
+			selector = strings.TrimSpace(selector)
+			if selector == "" {
+				continue
+			}
+			key, value, ok := strings.Cut(selector, ":")
+			if !ok {
+				return nil, fmt.Errorf("%w: selector %q must be key:value", ErrInvalidAgentLabels, selector)
+			}
+			key, value = strings.TrimSpace(key), strings.TrimSpace(value)
+			if err := validateAgentLabel(key, value); err != nil {
+				return nil, err
+			}
+			if existing, ok := selectors[key]; ok && existing != value {
+				return nil, fmt.Errorf("%w: selector key %q is given conflicting values", ErrInvalidAgentLabels, key)
+			}
+			selectors[key] = value
+		}
+	}
+	if len(selectors) == 0 {
+		return nil, nil
+	}
+	return selectors, nil
+}
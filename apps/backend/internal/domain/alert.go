@@ -18,6 +18,8 @@ const (
 	AlertSecurityBreach         AlertType = "security_breach"
 	AlertUnusualActivity        AlertType = "unusual_activity"
 	AlertTypeConfigurationDrift AlertType = "configuration_drift"
+	AlertKeyExpired             AlertType = "key_expired"  // Agent signing key passed its expiration date
+	AlertKeyExpiring            AlertType = "key_expiring" // Agent signing key expires soon
 )
 
 // AlertSeverity represents alert severity level
@@ -37,22 +39,30 @@ const (
 
 // Alert represents a security or operational alert
 type Alert struct {
-	ID             uuid.UUID     `json:"id"`
-	OrganizationID uuid.UUID     `json:"organizationId"`
-	AlertType      AlertType     `json:"alertType"`
-	Severity       AlertSeverity `json:"severity"`
-	Title          string        `json:"title"`
-	Description    string        `json:"description"`
-	ResourceType   string        `json:"resourceType"`
-	ResourceID     uuid.UUID     `json:"resourceId"`
-	IsAcknowledged bool          `json:"isAcknowledged"`
-	AcknowledgedBy *uuid.UUID    `json:"acknowledgedBy"`
-	AcknowledgedAt *time.Time    `json:"acknowledgedAt"`
-	CreatedAt      time.Time     `json:"createdAt"`
+	ID              uuid.UUID     `json:"id"`
+	OrganizationID  uuid.UUID     `json:"organizationId"`
+	AlertType       AlertType     `json:"alertType"`
+	Severity        AlertSeverity `json:"severity"`
+	Title           string        `json:"title"`
+	Description     string        `json:"description"`
+	ResourceType    string        `json:"resourceType"`
+	ResourceID      uuid.UUID     `json:"resourceId"`
+	IsAcknowledged  bool          `json:"isAcknowledged"`
+	AcknowledgedBy  *uuid.UUID    `json:"acknowledgedBy"`
+	AcknowledgedAt  *time.Time    `json:"acknowledgedAt"`
+	ResolvedBy      *uuid.UUID    `json:"resolvedBy"`
+	ResolvedAt      *time.Time    `json:"resolvedAt"`
+	ResolutionNote  *string       `json:"resolutionNote,omitempty"`
+	OccurrenceCount int           `json:"occurrenceCount"` // Identical alerts collapsed into this one (1 = no duplicates)
+	LastOccurredAt  *time.Time    `json:"lastOccurredAt,omitempty"`
+	CreatedAt       time.Time     `json:"createdAt"`
 }
 
 // AlertRepository defines the interface for alert persistence
 type AlertRepository interface {
+	// Create stores alert. An alert identical to an open one (same organization, type, resource
+	// and title) that last occurred within the dedup window is collapsed into it by incrementing
+	// its occurrence count; alert then reflects the existing row.
 	Create(alert *Alert) error
 	GetByID(id uuid.UUID) (*Alert, error)
 	GetByOrganization(orgID uuid.UUID, limit, offset int) ([]*Alert, error)
@@ -62,7 +72,9 @@ type AlertRepository interface {
 	GetUnacknowledged(orgID uuid.UUID) ([]*Alert, error)
 	GetByResourceID(resourceID uuid.UUID, limit, offset int) ([]*Alert, error)
 	GetUnacknowledgedByResourceID(resourceID uuid.UUID) ([]*Alert, error)
-	Acknowledge(id, userID uuid.UUID) error
+	Acknowledge(id, userID uuid.UUID, at time.Time) error
+	// Resolve marks the alert resolved by userID; an unacknowledged alert is acknowledged by them too
+	Resolve(id, userID uuid.UUID, note *string, at time.Time) error
 	BulkAcknowledge(orgID uuid.UUID, userID uuid.UUID) (int, error)
 	Delete(id uuid.UUID) error
 }
@@ -1,6 +1,7 @@
 package domain
 
 import (
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -17,9 +18,61 @@ type APIKey struct {
 	Prefix         string     `json:"prefix"`  // First 8 chars for identification
 	LastUsedAt     *time.Time `json:"lastUsedAt"`
 	ExpiresAt      *time.Time `json:"expiresAt"`
+	Scopes         []string   `json:"scopes"` // Empty means read-only (e.g. keys created before scopes existed)
 	IsActive       bool       `json:"isActive"`
 	CreatedAt      time.Time  `json:"createdAt"`
 	CreatedBy      uuid.UUID  `json:"createdBy"`
+	ExpiresIn      *int64     `json:"expiresInSeconds,omitempty"` // Remaining validity, set when listing keys; 0 once expired
+}
+
+// API key scopes have the form "<resource>:<action>". A write scope also grants read access
+// to the same resource.
+const (
+	APIKeyScopeAgentsRead         = "agents:read"
+	APIKeyScopeAgentsWrite        = "agents:write"
+	APIKeyScopeMCPServersRead     = "mcp_servers:read"
+	APIKeyScopeMCPServersWrite    = "mcp_servers:write"
+	APIKeyScopeVerificationsRead  = "verifications:read"
+	APIKeyScopeVerificationsWrite = "verifications:write"
+)
+
+// KnownAPIKeyScopes lists every scope that can be granted to an API key
+var KnownAPIKeyScopes = []string{
+	APIKeyScopeAgentsRead,
+	APIKeyScopeAgentsWrite,
+	APIKeyScopeMCPServersRead,
+	APIKeyScopeMCPServersWrite,
+	APIKeyScopeVerificationsRead,
+	APIKeyScopeVerificationsWrite,
+}
+
+// IsValidAPIKeyScope reports whether scope is one of KnownAPIKeyScopes
+func IsValidAPIKeyScope(scope string) bool {
+	for _, known := range KnownAPIKeyScopes {
+		if known == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// APIKeyScopesAllow reports whether a key with the given scopes may use required.
+// Keys without scopes are read-only: write access must be granted explicitly.
+func APIKeyScopesAllow(scopes []string, required string) bool {
+	resource, action, _ := strings.Cut(required, ":")
+	if len(scopes) == 0 {
+		return action == "read"
+	}
+
+	for _, scope := range scopes {
+		if scope == required {
+			return true
+		}
+		if action == "read" && scope == resource+":write" {
+			return true
+		}
+	}
+	return false
 }
 
 // APIKeyRepository defines the interface for API key persistence
@@ -32,4 +85,6 @@ type APIKeyRepository interface {
 	Revoke(id uuid.UUID) error
 	Delete(id uuid.UUID) error
 	UpdateLastUsed(id uuid.UUID) error
+	// GetExpiredActive returns active keys, across all organizations, whose expiry is at or before now
+	GetExpiredActive(now time.Time) ([]*APIKey, error)
 }
@@ -0,0 +1,127 @@
+package domain
+
+import (
+	"fmt"
+	"sort"
+)
+
+// APIUsageGroup is the number and total duration of recorded API calls to one endpoint that
+// returned a status in one class
+type APIUsageGroup struct {
+	Method          string
+	Endpoint        string
+	StatusClass     int // status code / 100, e.g. 4 for a 404
+	Calls           int64
+	TotalDurationMs int64
+}
+
+// APIStatusClassUsage summarizes the calls to an endpoint that returned a status in one class
+type APIStatusClassUsage struct {
+	StatusClass   string  `json:"statusClass"` // "2xx", "4xx", ...
+	Calls         int64   `json:"calls"`
+	AvgDurationMs float64 `json:"avgDurationMs"`
+}
+
+// APIEndpointUsage summarizes the calls to one endpoint
+type APIEndpointUsage struct {
+	Method        string                `json:"method"`
+	Endpoint      string                `json:"endpoint"`
+	Calls         int64                 `json:"calls"`
+	Errors        int64                 `json:"errors"`    // 4xx and 5xx responses
+	ErrorRate     float64               `json:"errorRate"` // 0-1
+	AvgDurationMs float64               `json:"avgDurationMs"`
+	StatusClasses []APIStatusClassUsage `json:"statusClasses"`
+}
+
+// APIUsageStatistics is the per-endpoint breakdown of an organization's API calls
+type APIUsageStatistics struct {
+	TotalCalls    int64              `json:"totalCalls"`
+	ErrorRate     float64            `json:"errorRate"` // 0-1
+	AvgDurationMs float64            `json:"avgDurationMs"`
+	Endpoints     []APIEndpointUsage `json:"endpoints"`
+}
+
+// CalculateAPIUsageStatistics merges groups by method and endpoint. Endpoints are
+// ordered by call count, busiest first, and each lists its status classes in ascending order.
+func CalculateAPIUsageStatistics(groups []APIUsageGroup) *APIUsageStatistics {
+	type classTotals struct{ calls, durationMs int64 }
+	type endpointKey struct{ method, endpoint string }
+
+	byEndpoint := make(map[endpointKey]map[int]*classTotals)
+	for _, group := range groups {
+		key := endpointKey{method: group.Method, endpoint: group.Endpoint}
+		classes, ok := byEndpoint[key]
+		if !ok {
+			classes = make(map[int]*classTotals)
+			byEndpoint[key] = classes
+		}
+		totals, ok := classes[group.StatusClass]
+		if !ok {
+			totals = &classTotals{}
+			classes[group.StatusClass] = totals
+		}
+		totals.calls += group.Calls
+		totals.durationMs += group.TotalDurationMs
+	}
+
+	stats := &APIUsageStatistics{Endpoints: make([]APIEndpointUsage, 0, len(byEndpoint))}
+	var totalErrors, totalDurationMs int64
+	for key, classes := range byEndpoint {
+		usage := APIEndpointUsage{Method: key.method, Endpoint: key.endpoint}
+		var durationMs int64
+
+		statusClasses := make([]int, 0, len(classes))
+		for class := range classes {
+			statusClasses = append(statusClasses, class)
+		}
+		sort.Ints(statusClasses)
+		for _, class := range statusClasses {
+			totals := classes[class]
+			usage.Calls += totals.calls
+			durationMs += totals.durationMs
+			if class >= 4 {
+				usage.Errors += totals.calls
+			}
+			usage.StatusClasses = append(usage.StatusClasses, APIStatusClassUsage{
+				StatusClass:   fmt.Sprintf("%dxx", class),
+				Calls:         totals.calls,
+				AvgDurationMs: averageAPICallDuration(totals.durationMs, totals.calls),
+			})
+		}
+		usage.ErrorRate = apiCallRate(usage.Errors, usage.Calls)
+		usage.AvgDurationMs = averageAPICallDuration(durationMs, usage.Calls)
+		stats.Endpoints = append(stats.Endpoints, usage)
+
+		stats.TotalCalls += usage.Calls
+		totalErrors += usage.Errors
+		totalDurationMs += durationMs
+	}
+	stats.ErrorRate = apiCallRate(totalErrors, stats.TotalCalls)
+	stats.AvgDurationMs = averageAPICallDuration(totalDurationMs, stats.TotalCalls)
+
+	sort.Slice(stats.Endpoints, func(i, j int) bool {
+		a, b := stats.Endpoints[i], stats.Endpoints[j]
+		if a.Calls != b.Calls {
+			return a.Calls > b.Calls
+		}
+		if a.Endpoint != b.Endpoint {
+			return a.Endpoint < b.Endpoint
+		}
+		return a.Method < b.Method
+	})
+	return stats
+}
+
+func averageAPICallDuration(totalMs, calls int64) float64 {
+	if calls == 0 {
+		return 0
+	}
+	return float64(totalMs) / float64(calls)
+}
+
+func apiCallRate(count, total int64) float64 {
+	if total == 0 {
+		return 0
+	}
+	return float64(count) / float64(total)
+}
@@ -0,0 +1,141 @@
+package domain
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Audit logs are hash chained per organization: every entry stores the hash of the entry before
+// it (prev_hash) and a hash over its own fields plus prev_hash (entry_hash). Altering, inserting
+// or deleting a stored entry breaks a link that VerifyLink detects. The first entry of a chain
+// has chain sequence 1 and an empty prev_hash. Deleting the newest entries breaks no link, so
+// the chain's head (AuditChainHead) is stored separately and the chain must end at it.
+
+// AuditChainTimestampPrecision is the precision audit timestamps are stored, and hashed, at
+const AuditChainTimestampPrecision = time.Microsecond
+
+// ComputeHash returns the hex SHA-256 of the entry's fields and its PrevHash. Metadata is
+// hashed in its canonical JSON form, so the hash survives a round trip through JSONB.
+func (l *AuditLog) ComputeHash() (string, error) {
+	metadata, err := canonicalAuditMetadata(l.Metadata)
+	if err != nil {
+		return "", err
+	}
+
+	payload, err := json.Marshal(struct {
+		ChainSequence  int64           `json:"chainSequence"`
+		PrevHash       string          `json:"prevHash"`
+		ID             uuid.UUID       `json:"id"`
+		OrganizationID uuid.UUID       `json:"organizationId"`
+		UserID         uuid.UUID       `json:"userId"`
+		Action         AuditAction     `json:"action"`
+		ResourceType   string          `json:"resourceType"`
+		ResourceID     uuid.UUID       `json:"resourceId"`
+		IPAddress      string          `json:"ipAddress"`
+		UserAgent      string          `json:"userAgent"`
+		Metadata       json.RawMessage `json:"metadata"`
+		Timestamp      string          `json:"timestamp"`
+	}{
+		ChainSequence:  l.ChainSequence,
+		PrevHash:       l.PrevHash,
+		ID:             l.ID,
+		OrganizationID: l.OrganizationID,
+		UserID:         l.UserID,
+		Action:         l.Action,
+		ResourceType:   l.ResourceType,
+		ResourceID:     l.ResourceID,
+		IPAddress:      l.IPAddress,
+		UserAgent:      l.UserAgent,
+		Metadata:       metadata,
+		Timestamp:      l.Timestamp.UTC().Truncate(AuditChainTimestampPrecision).Format(time.RFC3339Nano),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	sum := sha256.Sum256(payload)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// canonicalAuditMetadata encodes metadata the way it reads back from storage: decoded into
+// generic JSON values (numbers as float64) and re-encoded with sorted keys
+func canonicalAuditMetadata(metadata map[string]interface{}) (json.RawMessage, error) {
+	raw, err := json.Marshal(metadata)
+	if err != nil {
+		return nil, err
+	}
+
+	var generic interface{}
+	if err := json.Unmarshal(raw, &generic); err != nil {
+		return nil, err
+	}
+	return json.Marshal(generic)
+}
+
+// VerifyLink checks that the entry's hash matches its fields and that it follows prev, the
+// entry before it in the chain. prev is nil for the oldest entry still stored; when that is
+// not the chain's first entry, older entries were purged by retention and its prev_hash is
+// taken on trust.
+func (l *AuditLog) VerifyLink(prev *AuditLog) error {
+	hash, err := l.ComputeHash()
+	if err != nil {
+		return fmt.Errorf("failed to hash entry: %w", err)
+	}
+	if hash != l.EntryHash {
+		return fmt.Errorf("entry hash does not match its contents")
+	}
+
+	if prev == nil {
+		if l.ChainSequence == 1 && l.PrevHash != "" {
+			return fmt.Errorf("first entry of the chain has a previous hash")
+		}
+		return nil
+	}
+	if l.ChainSequence != prev.ChainSequence+1 {
+		return fmt.Errorf("chain sequence jumps from %d to %d", prev.ChainSequence, l.ChainSequence)
+	}
+	if l.PrevHash != prev.EntryHash {
+		return fmt.Errorf("previous hash does not match entry %d", prev.ChainSequence)
+	}
+	return nil
+}
+
+// AuditChainHead is the last entry of an organization's audit log chain, written with every entry
+type AuditChainHead struct {
+	OrganizationID uuid.UUID `json:"organizationId"`
+	ChainSequence  int64     `json:"chainSequence"`
+	EntryHash      string    `json:"entryHash"`
+	UpdatedAt      time.Time `json:"updatedAt"`
+}
+
+// VerifyHead checks that a chain whose last stored entry is last ends at the head. last is nil
+// when no entry is stored.
+func (h *AuditChainHead) VerifyHead(last *AuditLog) error {
+	if last == nil || last.ChainSequence < h.ChainSequence {
+		return fmt.Errorf("chain ends before its head entry %d: entries were deleted from its end", h.ChainSequence)
+	}
+	if last.ChainSequence != h.ChainSequence || last.EntryHash != h.EntryHash {
+		return fmt.Errorf("entry %d does not match the chain head", last.ChainSequence)
+	}
+	return nil
+}
+
+// AuditChainVerification is the outcome of verifying an organization's audit log hash chain
+type AuditChainVerification struct {
+	OrganizationID uuid.UUID  `json:"organizationId"`
+	Valid          bool       `json:"valid"`
+	EntriesChecked int        `json:"entriesChecked"`
+	FirstSequence  int64      `json:"firstSequence,omitempty"`  // Oldest stored entry; above 1 after retention purges
+	LastSequence   int64      `json:"lastSequence,omitempty"`   // Last entry checked
+	HeadSequence   int64      `json:"headSequence,omitempty"`   // Chain head the chain must end at
+	HeadHash       string     `json:"headHash,omitempty"`       // Entry hash of the head; record it to compare later verifications against
+	BrokenEntryID  *uuid.UUID `json:"brokenEntryId,omitempty"`  // First entry whose link failed
+	BrokenSequence int64      `json:"brokenSequence,omitempty"` // Chain sequence of that entry
+	Reason         string     `json:"reason,omitempty"`
+	VerifiedAt     time.Time  `json:"verifiedAt"`
+}
@@ -24,6 +24,7 @@ const (
 
 	// API Key actions
 	AuditActionRevoke AuditAction = "revoke"
+	AuditActionExpire AuditAction = "expire" // Disabled automatically after its expiry
 
 	// Trust score actions
 	AuditActionCalculate AuditAction = "calculate"
@@ -64,6 +65,29 @@ type AuditLog struct {
 	UserAgent      string                 `json:"userAgent"`
 	Metadata       map[string]interface{} `json:"metadata"`
 	Timestamp      time.Time              `json:"timestamp"`
+
+	// Hash chain, set when the entry is stored (see audit_chain.go)
+	ChainSequence int64  `json:"chainSequence,omitempty"`
+	PrevHash      string `json:"prevHash,omitempty"`
+	EntryHash     string `json:"entryHash,omitempty"`
+}
+
+// AuditLogFilter narrows an organization's audit log search; zero fields do not filter
+type AuditLogFilter struct {
+	Action       AuditAction
+	ResourceType string
+	ResourceID   *uuid.UUID
+	UserID       *uuid.UUID
+	StartDate    *time.Time // Inclusive
+	EndDate      *time.Time // Exclusive
+	Query        string     // Case-insensitive substring of action or resource type
+}
+
+// AuditLogCursor identifies the last audit log of a page when paging logs ordered
+// by timestamp DESC, id DESC
+type AuditLogCursor struct {
+	Timestamp time.Time
+	ID        uuid.UUID
 }
 
 // AuditLogRepository defines the interface for audit log persistence
@@ -72,10 +96,21 @@ type AuditLogRepository interface {
 	GetByOrganization(orgID uuid.UUID, limit, offset int) ([]*AuditLog, error)
 	GetByUser(userID uuid.UUID, limit, offset int) ([]*AuditLog, error)
 	GetByResource(resourceType string, resourceID uuid.UUID) ([]*AuditLog, error)
-	Search(query string, limit, offset int) ([]*AuditLog, error)
+	// Search returns a page of the organization's audit logs matching filter, newest first, and the total match count
+	Search(orgID uuid.UUID, filter AuditLogFilter, limit, offset int) ([]*AuditLog, int, error)
+	// SearchAfter returns up to limit of the organization's audit logs matching filter, newest first,
+	// starting right after the cursor (keyset pagination). A nil cursor starts at the newest log.
+	// Logs written while paging never shift later pages.
+	SearchAfter(orgID uuid.UUID, filter AuditLogFilter, after *AuditLogCursor, limit int) ([]*AuditLog, error)
 
 	// Security policy query methods
 	CountActionsByAgentInTimeWindow(agentID uuid.UUID, action AuditAction, windowMinutes int) (int, error)
 	GetRecentActionsByAgent(agentID uuid.UUID, limit int) ([]*AuditLog, error)
 	GetAgentActionsByIPAddress(agentID uuid.UUID, ipAddress string, limit int) ([]*AuditLog, error)
+
+	// GetChain returns up to limit of the organization's chained entries with a chain sequence
+	// above afterSequence, in chain order
+	GetChain(orgID uuid.UUID, afterSequence int64, limit int) ([]*AuditLog, error)
+	// GetChainHead returns the last entry of the organization's chain, nil if it has none
+	GetChainHead(orgID uuid.UUID) (*AuditChainHead, error)
 }
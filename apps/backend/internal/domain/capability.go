@@ -15,10 +15,31 @@ type AgentCapability struct {
 	GrantedBy       *uuid.UUID             `json:"grantedBy,omitempty"`
 	GrantedAt       time.Time              `json:"grantedAt"`
 	RevokedAt       *time.Time             `json:"revokedAt,omitempty"`
+	ExpiresAt       *time.Time             `json:"expiresAt,omitempty"` // Grant stops applying after this time
 	CreatedAt       time.Time              `json:"createdAt"`
 	UpdatedAt       time.Time              `json:"updatedAt"`
 }
 
+// CapabilityScopeGrantedScope is the capability_scope key holding the resource glob a grant is limited to
+const CapabilityScopeGrantedScope = "granted_scope"
+
+// GrantedScope returns the resource glob this grant is limited to, or "" if it is unscoped
+func (c *AgentCapability) GrantedScope() string {
+	scope, _ := c.CapabilityScope[CapabilityScopeGrantedScope].(string)
+	return scope
+}
+
+// IsExpired reports whether the grant has a per-capability expiry that has passed
+func (c *AgentCapability) IsExpired(now time.Time) bool {
+	return c.ExpiresAt != nil && !now.Before(*c.ExpiresAt)
+}
+
+// CapabilityGrantOptions narrows a capability grant to a resource scope and/or a lifetime
+type CapabilityGrantOptions struct {
+	GrantedScope string     `json:"granted_scope,omitempty"` // Resource glob, e.g. "/tmp/*"
+	ExpiresAt    *time.Time `json:"expires_at,omitempty"`
+}
+
 // CapabilityViolation represents an attempt to perform an action outside capability scope
 type CapabilityViolation struct {
 	ID                     uuid.UUID              `json:"id"`
@@ -34,6 +55,33 @@ type CapabilityViolation struct {
 	CreatedAt              time.Time              `json:"created_at"`
 }
 
+// ViolationStats summarizes an agent's capability violations over a period
+type ViolationStats struct {
+	AgentID         uuid.UUID                `json:"agent_id"`
+	Since           time.Time                `json:"since"`
+	Until           time.Time                `json:"until"`
+	TotalViolations int                      `json:"total_violations"`
+	Blocked         int                      `json:"blocked"`
+	BySeverity      map[string]int           `json:"by_severity"`
+	Timeline        []ViolationStatsBucket   `json:"timeline"`      // One bucket per day, oldest first
+	TopAttempted    []AttemptedActionSummary `json:"top_attempted"` // Most attempted unauthorized capabilities, most frequent first
+}
+
+// ViolationStatsBucket counts the violations of one day by severity
+type ViolationStatsBucket struct {
+	Date       time.Time      `json:"date"` // Start of the day, UTC
+	Total      int            `json:"total"`
+	BySeverity map[string]int `json:"by_severity"`
+}
+
+// AttemptedActionSummary counts attempts at one unauthorized capability
+type AttemptedActionSummary struct {
+	Capability    string    `json:"capability"`
+	Count         int       `json:"count"`
+	Blocked       int       `json:"blocked"`
+	LastAttemptAt time.Time `json:"last_attempt_at"`
+}
+
 // CapabilityRepository defines the interface for capability data access
 type CapabilityRepository interface {
 	// Capability CRUD
@@ -41,6 +89,7 @@ type CapabilityRepository interface {
 	GetCapabilityByID(id uuid.UUID) (*AgentCapability, error)
 	GetCapabilitiesByAgentID(agentID uuid.UUID) ([]*AgentCapability, error)
 	GetActiveCapabilitiesByAgentID(agentID uuid.UUID) ([]*AgentCapability, error)
+	GetCapabilitiesByAgentIDs(agentIDs []uuid.UUID) (map[uuid.UUID][]*AgentCapability, error)
 	RevokeCapability(id uuid.UUID, revokedAt time.Time) error
 	DeleteCapability(id uuid.UUID) error
 
@@ -48,8 +97,37 @@ type CapabilityRepository interface {
 	CreateViolation(violation *CapabilityViolation) error
 	GetViolationByID(id uuid.UUID) (*CapabilityViolation, error)
 	GetViolationsByAgentID(agentID uuid.UUID, limit, offset int) ([]*CapabilityViolation, int, error)
+	GetViolationsByAgentSince(agentID uuid.UUID, since time.Time) ([]*CapabilityViolation, error)
 	GetRecentViolations(orgID uuid.UUID, minutes int) ([]*CapabilityViolation, error)
 	GetViolationsByOrganization(orgID uuid.UUID, limit, offset int) ([]*CapabilityViolation, int, error)
+
+	// Capability catalog: the types an organization allows, empty if it has none
+	GetCatalog(orgID uuid.UUID) ([]*CapabilityCatalogEntry, error)
+}
+
+// CapabilityCatalogEntry is a capability type an organization allows its agents to be granted
+type CapabilityCatalogEntry struct {
+	CapabilityType string `json:"type"`
+	Name           string `json:"name"`
+	Description    string `json:"description"`
+	Category       string `json:"category"`
+	RiskLevel      string `json:"riskLevel"`
+}
+
+// DefaultCapabilityCatalog returns the catalog every organization starts with
+func DefaultCapabilityCatalog() []*CapabilityCatalogEntry {
+	return []*CapabilityCatalogEntry{
+		{CapabilityType: CapabilityFileRead, Name: "File Read", Description: "Read files from the file system", Category: "file_system", RiskLevel: "low"},
+		{CapabilityType: CapabilityFileWrite, Name: "File Write", Description: "Write files to the file system", Category: "file_system", RiskLevel: "medium"},
+		{CapabilityType: CapabilityFileDelete, Name: "File Delete", Description: "Delete files from the file system", Category: "file_system", RiskLevel: "high"},
+		{CapabilityType: CapabilityNetworkAccess, Name: "Network Access", Description: "Make network requests and access external services", Category: "network", RiskLevel: "medium"},
+		{CapabilityType: CapabilityDBQuery, Name: "Database Query", Description: "Query databases (read operations)", Category: "database", RiskLevel: "low"},
+		{CapabilityType: CapabilityDBWrite, Name: "Database Write", Description: "Modify databases (write operations)", Category: "database", RiskLevel: "high"},
+		{CapabilityType: CapabilityAPICall, Name: "API Call", Description: "Call external APIs", Category: "network", RiskLevel: "medium"},
+		{CapabilityType: CapabilityDataExport, Name: "Data Export", Description: "Export data from the system", Category: "data", RiskLevel: "high"},
+		{CapabilityType: CapabilitySystemAdmin, Name: "System Administration", Description: "Execute system commands and administrative actions", Category: "system", RiskLevel: "critical"},
+		{CapabilityType: CapabilityMCPToolUse, Name: "MCP Tool Use", Description: "Use Model Context Protocol tools", Category: "mcp", RiskLevel: "medium"},
+	}
 }
 
 // Standard capability types
@@ -74,3 +152,12 @@ const (
 	ViolationSeverityHigh     = "high"
 	ViolationSeverityCritical = "critical"
 )
+
+// MaxVerifyActionResourceLength caps the resource an agent may name in a verify-action request
+const MaxVerifyActionResourceLength = 2048
+
+// FieldError describes why a request field was rejected
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
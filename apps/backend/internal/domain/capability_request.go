@@ -15,28 +15,34 @@ const (
 	CapabilityRequestStatusRejected CapabilityRequestStatus = "rejected"
 )
 
+// DefaultCapabilityRequestTTL is how long a capability request may stay pending before it is rejected
+const DefaultCapabilityRequestTTL = 7 * 24 * time.Hour
+
 // CapabilityRequest represents a request for additional agent capabilities after registration
 type CapabilityRequest struct {
-	ID             uuid.UUID               `json:"id" db:"id"`
-	AgentID        uuid.UUID               `json:"agentId" db:"agent_id"`
-	CapabilityType string                  `json:"capabilityType" db:"capability_type"`
-	Reason         string                  `json:"reason" db:"reason"`
-	Status         CapabilityRequestStatus `json:"status" db:"status"`
-	RequestedBy    uuid.UUID               `json:"requestedBy" db:"requested_by"`
-	ReviewedBy     *uuid.UUID              `json:"reviewedBy,omitempty" db:"reviewed_by"`
-	RequestedAt    time.Time               `json:"requestedAt" db:"requested_at"`
-	ReviewedAt     *time.Time              `json:"reviewedAt,omitempty" db:"reviewed_at"`
-	CreatedAt      time.Time               `json:"createdAt" db:"created_at"`
-	UpdatedAt      time.Time               `json:"updatedAt" db:"updated_at"`
+	ID              uuid.UUID               `json:"id" db:"id"`
+	AgentID         uuid.UUID               `json:"agentId" db:"agent_id"`
+	CapabilityType  string                  `json:"capabilityType" db:"capability_type"`
+	Reason          string                  `json:"reason" db:"reason"`
+	Status          CapabilityRequestStatus `json:"status" db:"status"`
+	RequestedBy     uuid.UUID               `json:"requestedBy" db:"requested_by"`
+	ReviewedBy      *uuid.UUID              `json:"reviewedBy,omitempty" db:"reviewed_by"`
+	RequestedAt     time.Time               `json:"requestedAt" db:"requested_at"`
+	ReviewedAt      *time.Time              `json:"reviewedAt,omitempty" db:"reviewed_at"`
+	ExpiresAt       time.Time               `json:"expiresAt" db:"expires_at"`
+	AdminRemindedAt *time.Time              `json:"adminRemindedAt,omitempty" db:"admin_reminded_at"`
+	CreatedAt       time.Time               `json:"createdAt" db:"created_at"`
+	UpdatedAt       time.Time               `json:"updatedAt" db:"updated_at"`
 }
 
 // CapabilityRequestWithDetails includes agent and user details for API responses
 type CapabilityRequestWithDetails struct {
 	CapabilityRequest
-	AgentName        string  `json:"agentName" db:"agent_name"`
-	AgentDisplayName string  `json:"agentDisplayName" db:"agent_display_name"`
-	RequestedByEmail string  `json:"requestedByEmail" db:"requested_by_email"`
-	ReviewedByEmail  *string `json:"reviewedByEmail,omitempty" db:"reviewed_by_email"`
+	OrganizationID   uuid.UUID `json:"organizationId" db:"organization_id"`
+	AgentName        string    `json:"agentName" db:"agent_name"`
+	AgentDisplayName string    `json:"agentDisplayName" db:"agent_display_name"`
+	RequestedByEmail string    `json:"requestedByEmail" db:"requested_by_email"`
+	ReviewedByEmail  *string   `json:"reviewedByEmail,omitempty" db:"reviewed_by_email"`
 }
 
 // CreateCapabilityRequestInput represents input for creating a new capability request
@@ -53,14 +59,19 @@ type CapabilityRequestRepository interface {
 	GetByID(id uuid.UUID) (*CapabilityRequestWithDetails, error)
 	List(filter CapabilityRequestFilter) ([]*CapabilityRequestWithDetails, error)
 	UpdateStatus(id uuid.UUID, status CapabilityRequestStatus, reviewedBy uuid.UUID) error
+	Expire(id uuid.UUID, at time.Time) error
+	MarkAdminReminded(id uuid.UUID, at time.Time) error
 	Delete(id uuid.UUID) error
 }
 
 // CapabilityRequestFilter defines filtering options for capability request queries
 type CapabilityRequestFilter struct {
-	Status         *CapabilityRequestStatus
-	AgentID        *uuid.UUID
-	OrganizationID *uuid.UUID // Filter by organization for multi-tenancy
-	Limit          int
-	Offset         int
+	Status           *CapabilityRequestStatus
+	AgentID          *uuid.UUID
+	OrganizationID   *uuid.UUID // Filter by organization for multi-tenancy
+	ExpiresBefore    *time.Time // Only requests that expire at or before this time
+	RequestedBefore  *time.Time // Only requests submitted at or before this time
+	NotAdminReminded bool       // Only requests admins have not been reminded about
+	Limit            int
+	Offset           int
 }
@@ -0,0 +1,30 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ComplianceCheckSnapshot is the stored result of a compliance check run for one framework.
+// Snapshots taken over time make up an organization's compliance score history.
+type ComplianceCheckSnapshot struct {
+	ID             uuid.UUID                `json:"id"`
+	OrganizationID uuid.UUID                `json:"organization_id"`
+	Framework      string                   `json:"framework"`
+	Passed         int                      `json:"passed"`
+	Failed         int                      `json:"failed"`
+	Total          int                      `json:"total"`
+	ComplianceRate float64                  `json:"compliance_rate"`
+	Checks         []map[string]interface{} `json:"checks"`
+	RunAt          time.Time                `json:"run_at"`
+}
+
+// ComplianceCheckSnapshotRepository defines the interface for compliance check snapshot persistence
+type ComplianceCheckSnapshotRepository interface {
+	Create(snapshot *ComplianceCheckSnapshot) error
+	// GetLatest returns the most recent snapshot for the framework, or nil if none exists
+	GetLatest(orgID uuid.UUID, framework string) (*ComplianceCheckSnapshot, error)
+	// GetTimeline returns the framework's snapshots taken at or after since, oldest first
+	GetTimeline(orgID uuid.UUID, framework string, since time.Time) ([]*ComplianceCheckSnapshot, error)
+}
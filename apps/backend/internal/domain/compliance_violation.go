@@ -0,0 +1,44 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ComplianceViolation is a compliance finding about a resource. Violations are persisted so that
+// remediation state survives re-detection.
+type ComplianceViolation struct {
+	ID               uuid.UUID  `json:"id"`
+	OrganizationID   uuid.UUID  `json:"organization_id"`
+	CheckKey         string     `json:"check_key"` // Detection rule that raised the violation, e.g. "unverified_agent"
+	Framework        string     `json:"framework"`
+	Severity         string     `json:"severity"`
+	Title            string     `json:"title"`
+	Description      string     `json:"description"`
+	ResourceType     string     `json:"resource_type"`
+	ResourceID       uuid.UUID  `json:"resource_id"`
+	IsRemediated     bool       `json:"is_remediated"`
+	RemediatedBy     *uuid.UUID `json:"remediated_by"`
+	RemediatedAt     *time.Time `json:"remediated_at"`
+	RemediationNotes string     `json:"remediation_notes"`
+	DetectedAt       time.Time  `json:"detected_at"`
+	ClearedAt        *time.Time `json:"cleared_at"` // When the condition was last found no longer holding
+}
+
+// ComplianceViolationRepository defines the interface for compliance violation persistence
+type ComplianceViolationRepository interface {
+	// Create stores a newly detected violation. A violation for the same check and resource is only
+	// stored once; violation.ID and DetectedAt are set to the stored row.
+	Create(violation *ComplianceViolation) error
+	// GetByID returns the violation, or nil if it does not exist
+	GetByID(id uuid.UUID) (*ComplianceViolation, error)
+	// GetByOrganization returns all stored violations for an organization, newest first
+	GetByOrganization(orgID uuid.UUID) ([]*ComplianceViolation, error)
+	// Remediate records the remediation of a violation
+	Remediate(id uuid.UUID, remediatedBy uuid.UUID, notes string, remediatedAt time.Time) error
+	// MarkCleared records that the violation's condition no longer holds
+	MarkCleared(id uuid.UUID, at time.Time) error
+	// Reopen clears the remediation and cleared state of a violation whose condition fires again
+	Reopen(id uuid.UUID, detectedAt time.Time) error
+}
@@ -0,0 +1,62 @@
+package domain
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Default retention periods in days, used when an organization has not configured its own
+const (
+	DefaultAuditLogRetentionDays          = 365
+	DefaultVerificationEventRetentionDays = 90
+	DefaultResolvedAlertRetentionDays     = 180
+
+	// MinRetentionDays keeps organizations from purging data that is still needed for
+	// investigations and monthly compliance reports
+	MinRetentionDays = 30
+)
+
+// DataRetentionPolicy describes how long an organization keeps audit and monitoring data
+type DataRetentionPolicy struct {
+	AuditLogDays          int `json:"auditLogDays"`
+	VerificationEventDays int `json:"verificationEventDays"`
+	ResolvedAlertDays     int `json:"resolvedAlertDays"` // Open alerts are never purged
+}
+
+// DefaultDataRetentionPolicy returns the policy used when an organization has not configured its own
+func DefaultDataRetentionPolicy() DataRetentionPolicy {
+	return DataRetentionPolicy{
+		AuditLogDays:          DefaultAuditLogRetentionDays,
+		VerificationEventDays: DefaultVerificationEventRetentionDays,
+		ResolvedAlertDays:     DefaultResolvedAlertRetentionDays,
+	}
+}
+
+// Validate checks that the policy itself is within the allowed bounds
+func (p DataRetentionPolicy) Validate() error {
+	periods := []struct {
+		name string
+		days int
+	}{
+		{"auditLogDays", p.AuditLogDays},
+		{"verificationEventDays", p.VerificationEventDays},
+		{"resolvedAlertDays", p.ResolvedAlertDays},
+	}
+	for _, period := range periods {
+		if period.days < MinRetentionDays {
+			return fmt.Errorf("%s must be at least %d", period.name, MinRetentionDays)
+		}
+	}
+	return nil
+}
+
+// DataRetentionRepository deletes data that is past its retention period. Every method deletes
+// at most limit rows of one organization that are older than before, and returns how many it
+// deleted, so callers can purge in short transactions.
+type DataRetentionRepository interface {
+	PurgeAuditLogs(orgID uuid.UUID, before time.Time, limit int) (int64, error)
+	PurgeVerificationEvents(orgID uuid.UUID, before time.Time, limit int) (int64, error)
+	PurgeResolvedAlerts(orgID uuid.UUID, before time.Time, limit int) (int64, error)
+}
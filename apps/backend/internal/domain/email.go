@@ -46,6 +46,10 @@ const (
 	TemplateAPIKeyCreated  EmailTemplate = "api_key_created"
 	TemplateAPIKeyExpiring EmailTemplate = "api_key_expiring"
 	TemplateAPIKeyRevoked  EmailTemplate = "api_key_revoked"
+
+	// Capability request templates
+	TemplateCapabilityRequestExpired EmailTemplate = "capability_request_expired"
+	TemplateCapabilityRequestPending EmailTemplate = "capability_request_pending"
 )
 
 // EmailTemplateData contains data for rendering email templates
@@ -57,6 +61,11 @@ type EmailTemplateData struct {
 	SupportEmail string
 	Timestamp   time.Time
 
+	// Localization and branding
+	Locale              string // Locale tag (e.g. "es" or "es-MX") or Accept-Language list; empty uses the default
+	OrganizationName    string // Replaces the product name in the email header when set
+	OrganizationLogoURL string // Shown in the email header when set
+
 	// Agent-specific fields
 	AgentID        string
 	AgentName      string
@@ -89,7 +98,7 @@ type EmailTemplateData struct {
 
 // EmailConfig holds email service configuration
 type EmailConfig struct {
-	// Provider: "azure" or "smtp"
+	// Provider: "console", "azure", "smtp", "sendgrid" or "aws_ses"
 	Provider string
 
 	// Common configuration
@@ -102,6 +111,15 @@ type EmailConfig struct {
 	// SMTP configuration
 	SMTP SMTPConfig
 
+	// SendGrid configuration
+	SendGrid SendGridConfig
+
+	// Amazon SES configuration
+	SES SESConfig
+
+	// Resend configuration
+	Resend ResendConfig
+
 	// Template directory (optional)
 	TemplateDir string
 
@@ -133,6 +151,27 @@ type SMTPConfig struct {
 	IdleTimeout    time.Duration
 }
 
+// SendGridConfig holds SendGrid configuration
+type SendGridConfig struct {
+	// API key with the mail.send scope
+	APIKey string
+}
+
+// SESConfig holds Amazon SES configuration
+type SESConfig struct {
+	Region          string
+	AccessKeyID     string
+	SecretAccessKey string
+
+	// Session token for temporary credentials (optional)
+	SessionToken string
+}
+
+// ResendConfig holds Resend configuration
+type ResendConfig struct {
+	APIKey string
+}
+
 // EmailMetrics tracks email sending metrics
 type EmailMetrics struct {
 	TotalSent       int64
@@ -2,6 +2,7 @@ package domain
 
 import (
 	"encoding/json"
+	"errors"
 	"time"
 
 	"github.com/google/uuid"
@@ -40,10 +41,11 @@ type AttestationPayload struct {
 	ConnectionLatencyMs  float64  `json:"connection_latency_ms"`   // 3. connection_latency_ms
 	ConnectionSuccessful bool     `json:"connection_successful"`   // 4. connection_successful
 	HealthCheckPassed    bool     `json:"health_check_passed"`     // 5. health_check_passed
-	MCPName              string   `json:"mcp_name"`                // 6. mcp_name
-	MCPURL               string   `json:"mcp_url"`                 // 7. mcp_url
-	SDKVersion           string   `json:"sdk_version"`             // 8. sdk_version
-	Timestamp            string   `json:"timestamp"`               // 9. timestamp
+	MCPID                string   `json:"mcp_id,omitempty"`        // 6. mcp_id (omitted by older SDKs)
+	MCPName              string   `json:"mcp_name"`                // 7. mcp_name
+	MCPURL               string   `json:"mcp_url"`                 // 8. mcp_url
+	SDKVersion           string   `json:"sdk_version"`             // 9. sdk_version
+	Timestamp            string   `json:"timestamp"`               // 10. timestamp
 }
 
 // ToCanonicalJSON converts attestation payload to canonical JSON for signature verification
@@ -109,6 +111,9 @@ const (
 	VerificationMethodManual           VerificationMethod = "manual"
 )
 
+// ErrAttestationSignatureExists is returned when recording an agent attestation whose signature was already recorded
+var ErrAttestationSignatureExists = errors.New("attestation signature already recorded")
+
 // MCPAttestationRepository defines the interface for attestation persistence
 type MCPAttestationRepository interface {
 	// Attestation operations
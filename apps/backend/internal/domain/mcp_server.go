@@ -16,6 +16,17 @@ const (
 	MCPServerStatusRevoked   MCPServerStatus = "revoked"
 )
 
+// MCPHealthStatus represents the outcome of the last health check of an MCP server
+type MCPHealthStatus string
+
+const (
+	MCPHealthStatusUnknown      MCPHealthStatus = "unknown"       // Never checked
+	MCPHealthStatusHealthy      MCPHealthStatus = "healthy"       // Responded without a server error
+	MCPHealthStatusUnhealthy    MCPHealthStatus = "unhealthy"     // Responded with a 5xx status
+	MCPHealthStatusUnreachable  MCPHealthStatus = "unreachable"   // Connection failed or timed out
+	MCPHealthStatusNotCheckable MCPHealthStatus = "not_checkable" // Local placeholder URL (e.g. mcp://) or internal address
+)
+
 // MCPServer represents a Model Context Protocol server
 type MCPServer struct {
 	ID                   uuid.UUID       `json:"id"`
@@ -43,6 +54,9 @@ type MCPServer struct {
 	AttestationCount     int        `json:"attestationCount"`   // Number of verified agent attestations
 	ConfidenceScore      float64    `json:"confidenceScore"`    // Calculated from attestations (0-100)
 	LastAttestedAt       *time.Time `json:"lastAttestedAt"`     // Most recent attestation timestamp
+	// Health check state (updated by the health poller)
+	LastHealthCheck *time.Time      `json:"lastHealthCheck"`
+	HealthStatus    MCPHealthStatus `json:"healthStatus"`
 	// Populated via JOIN queries
 	AttestedBy           []string `json:"attestedBy,omitempty"`           // Agent names that have attested
 	ConnectedAgentsCount int      `json:"connectedAgentsCount,omitempty"` // Number of connected agents
@@ -61,12 +75,33 @@ type MCPServerRepository interface {
 	GetVerificationStatus(id uuid.UUID) (*MCPServerVerificationStatus, error)
 }
 
+// MCPHealthCheckResult is the outcome of probing an MCP server URL
+type MCPHealthCheckResult struct {
+	ServerID   uuid.UUID       `json:"serverId"`
+	Status     MCPHealthStatus `json:"status"`
+	StatusCode int             `json:"statusCode,omitempty"`
+	LatencyMs  int64           `json:"latencyMs"`
+	CheckedAt  time.Time       `json:"checkedAt"`
+}
+
 // MCPServerVerificationStatus represents the verification status details
 type MCPServerVerificationStatus struct {
-	ServerID       uuid.UUID       `json:"serverId"`
-	IsVerified     bool            `json:"isVerified"`
-	LastVerifiedAt *time.Time      `json:"lastVerifiedAt"`
-	TrustScore     float64         `json:"trustScore"`
-	PublicKeyCount int             `json:"publicKeyCount"`
-	Status         MCPServerStatus `json:"status"`
+	ServerID       uuid.UUID        `json:"serverId"`
+	IsVerified     bool             `json:"isVerified"`
+	LastVerifiedAt *time.Time       `json:"lastVerifiedAt"`
+	TrustScore     float64          `json:"trustScore"`
+	PublicKeyCount int              `json:"publicKeyCount"`
+	Status         MCPServerStatus  `json:"status"`
+	TrustFactors   *MCPTrustFactors `json:"trustFactors,omitempty"`
+}
+
+// MCPTrustFactors describes how an MCP server's trust score was derived from agent attestations
+type MCPTrustFactors struct {
+	AttestingAgents     int       `json:"attestingAgents"`     // Distinct agents with a valid attestation
+	RevokedAttestations int       `json:"revokedAttestations"` // Attestations that were invalidated
+	WeightedAgentTrust  float64   `json:"weightedAgentTrust"`  // Age-weighted average trust of attesting agents (0-1)
+	EffectiveAttesters  float64   `json:"effectiveAttesters"`  // Sum of attestation age weights
+	Coverage            float64   `json:"coverage"`            // 0-1, reaches 1 at full attester coverage
+	Score               float64   `json:"score"`               // WeightedAgentTrust * Coverage, scaled to 0-100
+	CalculatedAt        time.Time `json:"calculatedAt"`
 }
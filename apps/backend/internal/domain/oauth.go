@@ -1,6 +1,7 @@
 package domain
 
 import (
+	"errors"
 	"time"
 
 	"github.com/google/uuid"
@@ -43,6 +44,7 @@ type UserRegistrationRequest struct {
 	ProfilePictureURL    *string                   `json:"profilePictureUrl,omitempty" db:"profile_picture_url"`
 	OAuthEmailVerified   bool                      `json:"oauthEmailVerified" db:"oauth_email_verified"`
 	Metadata             map[string]interface{}    `json:"metadata,omitempty" db:"metadata"`
+	Locale               string                    `json:"locale,omitempty" db:"locale"` // Registrant's locale tag or Accept-Language list, for emails
 	CreatedAt            time.Time                 `json:"createdAt" db:"created_at"`
 	UpdatedAt            time.Time                 `json:"updatedAt" db:"updated_at"`
 }
@@ -76,6 +78,32 @@ type OAuthProfile struct {
 	RawProfile     map[string]interface{}
 }
 
+// TokenIntrospection is an authorization server's answer about an OAuth access token (RFC 7662).
+// Only Active is always present; the other fields are optional.
+type TokenIntrospection struct {
+	Active    bool   `json:"active"`
+	Scope     string `json:"scope,omitempty"`
+	ClientID  string `json:"client_id,omitempty"`
+	Username  string `json:"username,omitempty"`
+	TokenType string `json:"token_type,omitempty"`
+	ExpiresAt int64  `json:"exp,omitempty"` // Unix seconds
+	IssuedAt  int64  `json:"iat,omitempty"` // Unix seconds
+	Subject   string `json:"sub,omitempty"`
+	Issuer    string `json:"iss,omitempty"`
+}
+
+// MaxOAuthClientIDLength is the longest OAuth client ID an agent can be bound to
+const MaxOAuthClientIDLength = 255
+
+// ErrInvalidOAuthClientID is returned when an agent's OAuth client ID is malformed
+var ErrInvalidOAuthClientID = errors.New("invalid OAuth client ID")
+
+// IssuedTo reports whether the token was issued to clientID, either as the client it was issued
+// to or as its subject (client credentials tokens often carry the client ID only in sub)
+func (t *TokenIntrospection) IssuedTo(clientID string) bool {
+	return clientID != "" && (t.ClientID == clientID || t.Subject == clientID)
+}
+
 // NewUserRegistrationRequestOAuth creates a new OAuth registration request
 func NewUserRegistrationRequestOAuth(
 	email, firstName, lastName string,
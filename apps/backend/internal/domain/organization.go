@@ -1,23 +1,184 @@
 package domain
 
 import (
+	"errors"
+	"fmt"
+	"math"
+	"net/url"
+	"regexp"
 	"time"
 
 	"github.com/google/uuid"
 )
 
+// DefaultAutoVerifyMinTrust is the minimum trust score for agent auto-verification
+// used when an organization has not configured its own threshold. It is 0 so that new
+// agents are verified on creation, as they were before the threshold was configurable.
+const DefaultAutoVerifyMinTrust = 0.0
+
+// ValidateAutoVerifyMinTrust checks that an auto-verification trust threshold is between 0 and 1
+func ValidateAutoVerifyMinTrust(minTrust float64) error {
+	if math.IsNaN(minTrust) || minTrust < 0 || minTrust > 1 {
+		return fmt.Errorf("auto_verify_min_trust must be between 0 and 1")
+	}
+	return nil
+}
+
+// Agent key expiration period bounds, in days
+const (
+	DefaultKeyRotationDays = 365
+	MinKeyRotationDays     = 7
+	MaxKeyRotationDays     = 3650
+)
+
+// ValidateKeyRotationDays checks that a key expiration period is within the allowed range
+func ValidateKeyRotationDays(days int) error {
+	if days < MinKeyRotationDays || days > MaxKeyRotationDays {
+		return fmt.Errorf("key_rotation_days must be between %d and %d", MinKeyRotationDays, MaxKeyRotationDays)
+	}
+	return nil
+}
+
+// Trust score inactivity decay half-life bounds, in days. 0 turns decay off, which is the default
+// so existing scores do not change until an organization opts in.
+const (
+	DefaultTrustDecayHalfLifeDays = 0
+	MinTrustDecayHalfLifeDays     = 7
+	MaxTrustDecayHalfLifeDays     = 3650
+)
+
+// ValidateTrustDecayHalfLifeDays checks that a trust decay half-life is 0 or within the allowed range
+func ValidateTrustDecayHalfLifeDays(days int) error {
+	if days != 0 && (days < MinTrustDecayHalfLifeDays || days > MaxTrustDecayHalfLifeDays) {
+		return fmt.Errorf("trust_decay_half_life_days must be 0 or between %d and %d",
+			MinTrustDecayHalfLifeDays, MaxTrustDecayHalfLifeDays)
+	}
+	return nil
+}
+
+// emailLocalePattern matches a locale tag such as "es", "es-MX" or "pt_BR"
+var emailLocalePattern = regexp.MustCompile(`^[A-Za-z]{2,3}([-_][A-Za-z0-9]{2,8})*$`)
+
+// ValidateEmailBranding checks that a logo URL is empty or an absolute HTTPS URL, and that an email
+// locale is empty or a locale tag
+func ValidateEmailBranding(logoURL, locale string) error {
+	if logoURL != "" {
+		parsed, err := url.Parse(logoURL)
+		if err != nil || parsed.Scheme != "https" || parsed.Host == "" {
+			return fmt.Errorf("logo_url must be an absolute https URL")
+		}
+	}
+	if len(locale) > 35 || (locale != "" && !emailLocalePattern.MatchString(locale)) {
+		return fmt.Errorf("email_locale must be a locale tag such as \"en\" or \"es-MX\"")
+	}
+	return nil
+}
+
 // Organization represents a tenant organization
 type Organization struct {
-	ID        uuid.UUID              `json:"id"`
-	Name      string                 `json:"name"`
-	Domain    string                 `json:"domain"`
-	PlanType  string                 `json:"-"` // internal use only, not exposed via API
-	MaxAgents int                    `json:"maxAgents"`
-	MaxUsers  int                    `json:"maxUsers"`
-	IsActive  bool                   `json:"isActive"`
-	Settings  map[string]interface{} `json:"settings"` // Additional org settings
-	CreatedAt time.Time              `json:"createdAt"`
-	UpdatedAt time.Time              `json:"updatedAt"`
+	ID                        uuid.UUID              `json:"id"`
+	Name                      string                 `json:"name"`
+	Domain                    string                 `json:"domain"`
+	PlanType                  string                 `json:"-"` // internal use only, not exposed via API
+	MaxAgents                 int                    `json:"maxAgents"`
+	MaxUsers                  int                    `json:"maxUsers"`
+	IsActive                  bool                   `json:"isActive"`
+	AutoVerifyEnabled         bool                   `json:"autoVerifyEnabled"`         // Auto-verify new agents that meet the criteria
+	AutoVerifyMinTrust        float64                `json:"autoVerifyMinTrust"`        // Minimum trust score (0-1) for auto-verification
+	KeyRotationDays           int                    `json:"keyRotationDays"`           // Days until a newly issued agent key expires
+	TrustDecayHalfLifeDays    int                    `json:"trustDecayHalfLifeDays"`    // Days of inactivity that halve activity-derived trust, 0 disables
+	RequireCapabilityApproval bool                   `json:"requireCapabilityApproval"` // Declared capabilities of new agents need admin approval instead of being auto-granted
+	PasswordPolicy            *PasswordPolicy        `json:"passwordPolicy"`            // nil uses DefaultPasswordPolicy
+	RetentionPolicy           *DataRetentionPolicy   `json:"retentionPolicy"`           // nil uses DefaultDataRetentionPolicy
+	TrustWeights              *TrustWeights          `json:"trustWeights"`              // nil uses DefaultTrustWeights
+	ViolationPenalties        *ViolationPenalties    `json:"violationPenalties"`        // nil uses DefaultViolationPenalties
+	LogoURL                   string                 `json:"logoUrl"`                   // Logo shown in emails, empty shows none
+	EmailLocale               string                 `json:"emailLocale"`               // Locale of emails without a request locale, empty uses the default
+	Settings                  map[string]interface{} `json:"settings"`                  // Additional org settings
+	CreatedAt                 time.Time              `json:"createdAt"`
+	UpdatedAt                 time.Time              `json:"updatedAt"`
+}
+
+// EffectivePasswordPolicy returns the organization's password policy, or the default if none is configured
+func (o *Organization) EffectivePasswordPolicy() PasswordPolicy {
+	if o == nil || o.PasswordPolicy == nil {
+		return DefaultPasswordPolicy()
+	}
+	return *o.PasswordPolicy
+}
+
+// EffectiveRetentionPolicy returns the organization's data retention policy, or the default if none is configured
+func (o *Organization) EffectiveRetentionPolicy() DataRetentionPolicy {
+	if o == nil || o.RetentionPolicy == nil {
+		return DefaultDataRetentionPolicy()
+	}
+	return *o.RetentionPolicy
+}
+
+// EffectiveTrustWeights returns the organization's trust score factor weights, or the defaults if none are configured
+func (o *Organization) EffectiveTrustWeights() TrustWeights {
+	if o == nil || o.TrustWeights == nil {
+		return DefaultTrustWeights()
+	}
+	return *o.TrustWeights
+}
+
+// EffectiveViolationPenalties returns the organization's violation trust score penalties, or the defaults if none are configured
+func (o *Organization) EffectiveViolationPenalties() ViolationPenalties {
+	if o == nil || o.ViolationPenalties == nil {
+		return DefaultViolationPenalties()
+	}
+	return *o.ViolationPenalties
+}
+
+// BrandEmail sets the organization's name and logo on data, and its email locale unless data
+// already has the recipient's locale. A nil organization leaves data unbranded.
+func (o *Organization) BrandEmail(data *EmailTemplateData) {
+	if o == nil {
+		return
+	}
+	data.OrganizationName = o.Name
+	data.OrganizationLogoURL = o.LogoURL
+	if data.Locale == "" {
+		data.Locale = o.EmailLocale
+	}
+}
+
+// ErrOrganizationQuotaExceeded is matched by every *QuotaExceededError
+var ErrOrganizationQuotaExceeded = errors.New("organization quota exceeded")
+
+// QuotaExceededError reports an organization limit (max_agents or max_users) that a request would exceed
+type QuotaExceededError struct {
+	Resource string // "agents" or "users"
+	Limit    int
+	Current  int
+}
+
+func (e *QuotaExceededError) Error() string {
+	return fmt.Sprintf("organization %s limit reached (%d of %d in use)", e.Resource, e.Current, e.Limit)
+}
+
+func (e *QuotaExceededError) Unwrap() error {
+	return ErrOrganizationQuotaExceeded
+}
+
+// CheckAgentQuota returns a *QuotaExceededError if adding n agents to the current count would
+// exceed MaxAgents. A MaxAgents of 0 or less means unlimited.
+func (o *Organization) CheckAgentQuota(current, n int) error {
+	return checkQuota("agents", o.MaxAgents, current, n)
+}
+
+// CheckUserQuota returns a *QuotaExceededError if adding n users to the current count would
+// exceed MaxUsers. A MaxUsers of 0 or less means unlimited.
+func (o *Organization) CheckUserQuota(current, n int) error {
+	return checkQuota("users", o.MaxUsers, current, n)
+}
+
+func checkQuota(resource string, limit, current, n int) error {
+	if limit <= 0 || current+n <= limit {
+		return nil
+	}
+	return &QuotaExceededError{Resource: resource, Limit: limit, Current: current}
 }
 
 // OrganizationRepository defines the interface for organization persistence
@@ -25,6 +186,7 @@ type OrganizationRepository interface {
 	Create(org *Organization) error
 	GetByID(id uuid.UUID) (*Organization, error)
 	GetByDomain(domain string) (*Organization, error)
+	ListActive() ([]*Organization, error)
 	Update(org *Organization) error
 	Delete(id uuid.UUID) error
 }
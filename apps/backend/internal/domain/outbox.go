@@ -0,0 +1,109 @@
+package domain
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// OutboxTopic identifies the side effect an outbox message asks the relay to perform
+type OutboxTopic string
+
+const (
+	OutboxTopicAlert   OutboxTopic = "alert"   // Payload is an Alert to create
+	OutboxTopicWebhook OutboxTopic = "webhook" // Payload is an OutboxWebhookEvent to deliver
+)
+
+// OutboxStatus represents the state of an outbox message
+type OutboxStatus string
+
+const (
+	OutboxStatusPending   OutboxStatus = "pending"
+	OutboxStatusDelivered OutboxStatus = "delivered"
+	OutboxStatusFailed    OutboxStatus = "failed" // Attempts exhausted
+)
+
+// OutboxMessage is a side effect written in the same transaction as the change that caused it,
+// so it survives a crash between the commit and the side effect. The relay dispatches it at least
+// once, which means handlers must tolerate seeing the same message twice.
+type OutboxMessage struct {
+	ID             uuid.UUID       `json:"id"`
+	OrganizationID uuid.UUID       `json:"organizationId"`
+	Topic          OutboxTopic     `json:"topic"`
+	Payload        json.RawMessage `json:"payload"`
+	Status         OutboxStatus    `json:"status"`
+	Attempts       int             `json:"attempts"`
+	LastError      string          `json:"lastError,omitempty"`
+	AvailableAt    time.Time       `json:"availableAt"`
+	CreatedAt      time.Time       `json:"createdAt"`
+	DeliveredAt    *time.Time      `json:"deliveredAt,omitempty"`
+}
+
+// OutboxWebhookEvent is the payload of an OutboxTopicWebhook message
+type OutboxWebhookEvent struct {
+	Event        WebhookEvent    `json:"event"`
+	ResourceType string          `json:"resourceType"`
+	Data         json.RawMessage `json:"data"`
+}
+
+// NewAlertOutboxMessage returns a message that creates alert. The alert ID is assigned here so
+// a redelivered message can be recognised.
+func NewAlertOutboxMessage(alert *Alert) (*OutboxMessage, error) {
+	if alert.ID == uuid.Nil {
+		alert.ID = uuid.New()
+	}
+	return newOutboxMessage(alert.OrganizationID, OutboxTopicAlert, alert)
+}
+
+// NewWebhookOutboxMessage returns a message that delivers event to the organization's webhooks
+func NewWebhookOutboxMessage(orgID uuid.UUID, event WebhookEvent, resourceType string, data interface{}) (*OutboxMessage, error) {
+	encoded, err := json.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+	return newOutboxMessage(orgID, OutboxTopicWebhook, OutboxWebhookEvent{
+		Event:        event,
+		ResourceType: resourceType,
+		Data:         encoded,
+	})
+}
+
+func newOutboxMessage(orgID uuid.UUID, topic OutboxTopic, payload interface{}) (*OutboxMessage, error) {
+	encoded, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now().UTC()
+	return &OutboxMessage{
+		ID:             uuid.New(),
+		OrganizationID: orgID,
+		Topic:          topic,
+		Payload:        encoded,
+		Status:         OutboxStatusPending,
+		AvailableAt:    now,
+		CreatedAt:      now,
+	}, nil
+}
+
+// OutboxRepository defines the interface for outbox persistence. Messages are written by the
+// repository methods that make the business change, inside their transaction.
+type OutboxRepository interface {
+	// Enqueue writes messages whose side effect is the only change, so there is no business
+	// transaction to join
+	Enqueue(messages ...*OutboxMessage) error
+
+	// ClaimDue returns up to limit pending messages that are available at now, oldest first, and
+	// hides them from other relays for lease by counting an attempt and pushing back AvailableAt.
+	// A relay that dies mid-dispatch therefore leaves its messages to be claimed again.
+	ClaimDue(now time.Time, lease time.Duration, limit int) ([]*OutboxMessage, error)
+
+	// MarkDelivered records that the message's side effect was performed
+	MarkDelivered(id uuid.UUID, at time.Time) error
+
+	// MarkRetry records a failed attempt and makes the message available again at retryAt
+	MarkRetry(id uuid.UUID, lastError string, retryAt time.Time) error
+
+	// MarkFailed records a failed attempt and gives up on the message
+	MarkFailed(id uuid.UUID, lastError string) error
+}
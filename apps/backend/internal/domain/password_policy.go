@@ -0,0 +1,40 @@
+package domain
+
+import "fmt"
+
+// Password minimum length bounds. Organizations may require longer passwords than the default,
+// never shorter ones.
+const (
+	DefaultPasswordMinLength = 8
+	MaxPasswordMinLength     = 128
+)
+
+// PasswordPolicy describes the password requirements of an organization
+type PasswordPolicy struct {
+	MinLength               int  `json:"minLength"`
+	RequireUppercase        bool `json:"requireUppercase"`
+	RequireLowercase        bool `json:"requireLowercase"`
+	RequireNumber           bool `json:"requireNumber"`
+	RequireSymbol           bool `json:"requireSymbol"`
+	DisallowCommonPasswords bool `json:"disallowCommonPasswords"` // Reject passwords from the embedded common/breached list
+}
+
+// DefaultPasswordPolicy returns the policy used when an organization has not configured its own
+func DefaultPasswordPolicy() PasswordPolicy {
+	return PasswordPolicy{
+		MinLength:               DefaultPasswordMinLength,
+		RequireUppercase:        true,
+		RequireLowercase:        true,
+		RequireNumber:           true,
+		RequireSymbol:           true,
+		DisallowCommonPasswords: true,
+	}
+}
+
+// Validate checks that the policy itself is within the allowed bounds
+func (p PasswordPolicy) Validate() error {
+	if p.MinLength < DefaultPasswordMinLength || p.MinLength > MaxPasswordMinLength {
+		return fmt.Errorf("minLength must be between %d and %d", DefaultPasswordMinLength, MaxPasswordMinLength)
+	}
+	return nil
+}
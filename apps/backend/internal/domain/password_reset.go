@@ -0,0 +1,32 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// PasswordResetToken is a single-use password reset token. Only the SHA-256 hash of the token is
+// stored; the token itself is only ever sent in the reset email.
+type PasswordResetToken struct {
+	ID        uuid.UUID  `json:"id"`
+	UserID    uuid.UUID  `json:"userId"`
+	TokenHash string     `json:"-"` // Never expose in JSON
+	ExpiresAt time.Time  `json:"expiresAt"`
+	UsedAt    *time.Time `json:"usedAt,omitempty"` // Redeemed or superseded
+	CreatedAt time.Time  `json:"createdAt"`
+}
+
+// PasswordResetTokenRepository defines the interface for password reset token persistence
+type PasswordResetTokenRepository interface {
+	// Create stores a new token and marks the user's outstanding tokens as used in the same
+	// transaction, so only the most recently requested token can be redeemed
+	Create(token *PasswordResetToken) error
+
+	// GetByTokenHash returns the token, or nil if no token has this hash
+	GetByTokenHash(tokenHash string) (*PasswordResetToken, error)
+
+	// MarkUsed sets used_at if the token has not been used yet and reports whether it did, so
+	// concurrent redemptions of the same token cannot both succeed
+	MarkUsed(id uuid.UUID, at time.Time) (bool, error)
+}
@@ -0,0 +1,48 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// PolicySimulation records an action that a policy in simulate mode would have blocked.
+// The action itself was allowed; at most one record is kept per policy and audit ID.
+type PolicySimulation struct {
+	ID             uuid.UUID `json:"id"`
+	OrganizationID uuid.UUID `json:"organizationId"`
+	PolicyID       uuid.UUID `json:"policyId"`
+	AgentID        uuid.UUID `json:"agentId"`
+	ActionType     string    `json:"actionType"`
+	Resource       string    `json:"resource"`
+	AuditID        uuid.UUID `json:"auditId"`
+	CreatedAt      time.Time `json:"createdAt"`
+}
+
+// PolicySimulationCount is a would-block count for one agent or action type
+type PolicySimulationCount struct {
+	Key   string `json:"key"`
+	Count int    `json:"count"`
+}
+
+// PolicySimulationReport summarizes what a simulated policy would have blocked since a point in time
+type PolicySimulationReport struct {
+	PolicyID          uuid.UUID               `json:"policyId"`
+	PolicyName        string                  `json:"policyName"`
+	PolicyType        PolicyType              `json:"policyType"`
+	EnforcementAction EnforcementAction       `json:"enforcementAction"`
+	Since             time.Time               `json:"since"`
+	WouldBlockCount   int                     `json:"wouldBlockCount"`
+	AffectedAgents    int                     `json:"affectedAgents"`
+	LastSimulatedAt   *time.Time              `json:"lastSimulatedAt,omitempty"`
+	ByAgent           []PolicySimulationCount `json:"byAgent"`
+	ByActionType      []PolicySimulationCount `json:"byActionType"`
+}
+
+// PolicySimulationRepository defines the interface for policy simulation persistence
+type PolicySimulationRepository interface {
+	// Record stores the simulation, reporting false if one already exists for its policy and audit ID
+	Record(simulation *PolicySimulation) (bool, error)
+	// GetReport fills the would-block counts of report for simulations recorded since report.Since
+	GetReport(report *PolicySimulationReport) error
+}
@@ -0,0 +1,41 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// RefreshToken records one refresh token (by its JWT ID) in a rotation family. Every token issued
+// by rotating another one joins the same family, so replaying an already-rotated token can revoke
+// every descendant at once.
+type RefreshToken struct {
+	TokenID        string     `json:"tokenId"` // JTI claim
+	FamilyID       uuid.UUID  `json:"familyId"`
+	ParentTokenID  *string    `json:"parentTokenId,omitempty"`
+	UserID         uuid.UUID  `json:"userId"`
+	OrganizationID uuid.UUID  `json:"organizationId"`
+	IssuedAt       time.Time  `json:"issuedAt"`
+	ExpiresAt      time.Time  `json:"expiresAt"`
+	RotatedAt      *time.Time `json:"rotatedAt,omitempty"`
+	RevokedAt      *time.Time `json:"revokedAt,omitempty"`
+	RevokeReason   *string    `json:"revokeReason,omitempty"`
+}
+
+// RefreshTokenRepository defines the interface for refresh token lineage persistence
+type RefreshTokenRepository interface {
+	// Create stores a token; an existing row with the same token ID is left untouched
+	Create(token *RefreshToken) error
+
+	// GetByTokenID returns the token, or nil if it is not tracked
+	GetByTokenID(tokenID string) (*RefreshToken, error)
+
+	// MarkRotated sets rotated_at if the token has not been rotated or revoked yet and reports
+	// whether it did, so concurrent rotations of the same token cannot both succeed
+	MarkRotated(tokenID string, at time.Time) (bool, error)
+
+	// RevokeFamily revokes every unrevoked token in the family and returns how many were revoked.
+	// messages are written to the outbox in the same transaction, so they exist if and only if the
+	// revocation committed.
+	RevokeFamily(familyID uuid.UUID, reason string, at time.Time, messages ...*OutboxMessage) (int64, error)
+}
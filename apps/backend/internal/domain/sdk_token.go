@@ -19,6 +19,7 @@ type SDKToken struct {
 	UserAgent        *string                `json:"userAgent,omitempty"`
 	LastUsedAt       *time.Time             `json:"lastUsedAt,omitempty"`
 	LastIPAddress    *string                `json:"lastIpAddress,omitempty"`
+	LastUserAgent    *string                `json:"lastUserAgent,omitempty"`
 	UsageCount       int                    `json:"usageCount"`
 	CreatedAt        time.Time              `json:"createdAt"`
 	ExpiresAt        time.Time              `json:"expiresAt"`
@@ -45,14 +46,26 @@ func (t *SDKToken) Revoke(reason string) {
 	t.RevokeReason = &reason
 }
 
-// RecordUsage updates the last used timestamp and IP address
-func (t *SDKToken) RecordUsage(ipAddress string) {
+// RecordUsage updates the last used timestamp, IP address and user agent
+func (t *SDKToken) RecordUsage(ipAddress, userAgent string) {
 	now := time.Now()
 	t.LastUsedAt = &now
 	t.LastIPAddress = &ipAddress
+	t.LastUserAgent = &userAgent
 	t.UsageCount++
 }
 
+// SDKTokenUsage describes requests made with an SDK token since its usage was last recorded
+type SDKTokenUsage struct {
+	TokenID        string     // JTI of the SDK token
+	OrganizationID uuid.UUID  // Organization of the authenticated caller
+	UserID         *uuid.UUID // Authenticated user, if the request was made as a user
+	IPAddress      string
+	UserAgent      string
+	UsedAt         time.Time
+	Requests       int
+}
+
 // SDKTokenRepository defines the interface for SDK token persistence
 type SDKTokenRepository interface {
 	// Create stores a new SDK token
@@ -85,8 +98,8 @@ type SDKTokenRepository interface {
 	// RevokeAllForUser revokes all tokens for a user
 	RevokeAllForUser(userID uuid.UUID, reason string) error
 
-	// RecordUsage updates token usage statistics
-	RecordUsage(tokenID string, ipAddress string) error
+	// RecordUsage updates usage statistics of an active token owned by the caller
+	RecordUsage(usage SDKTokenUsage) error
 
 	// DeleteExpired removes expired tokens (cleanup job)
 	DeleteExpired() error
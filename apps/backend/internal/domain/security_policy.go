@@ -16,8 +16,32 @@ const (
 	PolicyTypeUnauthorizedAccess  PolicyType = "unauthorized_access"
 	PolicyTypeDataExfiltration    PolicyType = "data_exfiltration"
 	PolicyTypeConfigDrift         PolicyType = "config_drift"
+	PolicyTypeTrustScoreDrop      PolicyType = "trust_score_drop"
+	PolicyTypeMCPAllowlist        PolicyType = "mcp_allowlist" // MCP actions only against servers in the agent's talks_to
 )
 
+// KnownPolicyTypes lists every policy type the policy service evaluates
+var KnownPolicyTypes = []PolicyType{
+	PolicyTypeCapabilityViolation,
+	PolicyTypeTrustScoreLow,
+	PolicyTypeUnusualActivity,
+	PolicyTypeUnauthorizedAccess,
+	PolicyTypeDataExfiltration,
+	PolicyTypeConfigDrift,
+	PolicyTypeTrustScoreDrop,
+	PolicyTypeMCPAllowlist,
+}
+
+// IsValid reports whether the policy type is one the policy service evaluates
+func (t PolicyType) IsValid() bool {
+	for _, known := range KnownPolicyTypes {
+		if t == known {
+			return true
+		}
+	}
+	return false
+}
+
 // EnforcementAction defines what action to take when policy is triggered
 type EnforcementAction string
 
@@ -25,8 +49,27 @@ const (
 	EnforcementAlertOnly     EnforcementAction = "alert_only"      // Generate alert, allow action
 	EnforcementBlockAndAlert EnforcementAction = "block_and_alert" // Generate alert, deny action
 	EnforcementAllow         EnforcementAction = "allow"           // Permit action, no alert
+	EnforcementSimulate      EnforcementAction = "simulate"        // Record what block_and_alert would do, allow action
 )
 
+// KnownEnforcementActions lists every enforcement action a policy can take
+var KnownEnforcementActions = []EnforcementAction{
+	EnforcementAlertOnly,
+	EnforcementBlockAndAlert,
+	EnforcementAllow,
+	EnforcementSimulate,
+}
+
+// IsValid reports whether the enforcement action is one policies can take
+func (a EnforcementAction) IsValid() bool {
+	for _, known := range KnownEnforcementActions {
+		if a == known {
+			return true
+		}
+	}
+	return false
+}
+
 // SecurityPolicy represents a configurable security policy
 type SecurityPolicy struct {
 	ID                uuid.UUID         `json:"id"`
@@ -43,11 +86,11 @@ type SecurityPolicy struct {
 	Rules map[string]interface{} `json:"rules"`
 
 	// Scope
-	AppliesTo string `json:"appliesTo"` // "all", "agent_id:xxx", "agent_type:ai", etc.
+	AppliesTo string `json:"appliesTo"` // "all", "agent_id:xxx", "agent_type:mcp", "label:env=prod", etc.
 
 	// Status
 	IsEnabled bool `json:"isEnabled"`
-	Priority  int  `json:"priority"` // Higher priority policies evaluated first
+	Priority  int  `json:"priority"` // Higher priority policies evaluated first; the first matching policy decides
 
 	// Timestamps
 	CreatedAt time.Time `json:"createdAt"`
@@ -75,4 +118,7 @@ type SecurityPolicyRepository interface {
 	GetByType(orgID uuid.UUID, policyType PolicyType) ([]*SecurityPolicy, error)
 	Update(policy *SecurityPolicy) error
 	Delete(id uuid.UUID) error
+	// ApplyChanges creates, updates and deletes policies in one transaction, so on error no
+	// policy has changed
+	ApplyChanges(created, updated []*SecurityPolicy, deleted []uuid.UUID) error
 }
@@ -0,0 +1,152 @@
+package domain
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// SecurityPolicyExportVersion is the format version of exported policy documents
+const SecurityPolicyExportVersion = 1
+
+// ErrInvalidPolicyImport is returned when a policy import document or mode is malformed
+var ErrInvalidPolicyImport = errors.New("invalid security policy import")
+
+// PolicyImportMode controls what an import does with policies missing from the document
+type PolicyImportMode string
+
+const (
+	PolicyImportMerge   PolicyImportMode = "merge"   // Upsert the imported policies, keep the others
+	PolicyImportReplace PolicyImportMode = "replace" // Upsert the imported policies, delete the others
+)
+
+// IsValid reports whether the import mode is known
+func (m PolicyImportMode) IsValid() bool {
+	return m == PolicyImportMerge || m == PolicyImportReplace
+}
+
+// PortableSecurityPolicy is a security policy without organization-specific identifiers.
+// Policies are matched by name when a document is imported.
+type PortableSecurityPolicy struct {
+	Name              string            `json:"name"`
+	Description       string            `json:"description"`
+	PolicyType        PolicyType        `json:"policyType"`
+	EnforcementAction EnforcementAction `json:"enforcementAction"`
+	SeverityThreshold AlertSeverity     `json:"severityThreshold"`
+	Rules             json.RawMessage   `json:"rules"`
+	AppliesTo         string            `json:"appliesTo"`
+	IsEnabled         bool              `json:"isEnabled"`
+	Priority          int               `json:"priority"`
+}
+
+// SecurityPolicyExport is a portable document holding an organization's security policies
+type SecurityPolicyExport struct {
+	Version    int                      `json:"version"`
+	ExportedAt time.Time                `json:"exportedAt"`
+	Policies   []PortableSecurityPolicy `json:"policies"`
+}
+
+// SecurityPolicyImportResult lists the names of the policies an import created, updated and deleted
+type SecurityPolicyImportResult struct {
+	Mode    PolicyImportMode `json:"mode"`
+	Created []string         `json:"created"`
+	Updated []string         `json:"updated"`
+	Deleted []string         `json:"deleted"`
+}
+
+// NewPortableSecurityPolicy strips the organization-specific fields from policy
+func NewPortableSecurityPolicy(policy *SecurityPolicy) (PortableSecurityPolicy, error) {
+	rules := policy.Rules
+	if rules == nil {
+		rules = map[string]interface{}{}
+	}
+	rulesJSON, err := json.Marshal(rules)
+	if err != nil {
+		return PortableSecurityPolicy{}, fmt.Errorf("failed to marshal rules of policy %q: %w", policy.Name, err)
+	}
+
+	return PortableSecurityPolicy{
+		Name:              policy.Name,
+		Description:       policy.Description,
+		PolicyType:        policy.PolicyType,
+		EnforcementAction: policy.EnforcementAction,
+		SeverityThreshold: policy.SeverityThreshold,
+		Rules:             rulesJSON,
+		AppliesTo:         policy.AppliesTo,
+		IsEnabled:         policy.IsEnabled,
+		Priority:          policy.Priority,
+	}, nil
+}
+
+// ApplyTo copies the portable fields onto policy. The policy must have passed Validate.
+func (p PortableSecurityPolicy) ApplyTo(policy *SecurityPolicy) {
+	rules := map[string]interface{}{}
+	if len(p.Rules) > 0 {
+		_ = json.Unmarshal(p.Rules, &rules)
+	}
+
+	policy.Name = p.Name
+	policy.Description = p.Description
+	policy.PolicyType = p.PolicyType
+	policy.EnforcementAction = p.EnforcementAction
+	policy.SeverityThreshold = p.SeverityThreshold
+	policy.Rules = rules
+	policy.AppliesTo = p.AppliesTo
+	policy.IsEnabled = p.IsEnabled
+	policy.Priority = p.Priority
+}
+
+// Validate checks the document version and every policy: a unique non-empty name, known type,
+// enforcement action and severity, a scope, and rules that are a JSON object (or omitted)
+func (e *SecurityPolicyExport) Validate() error {
+	if e.Version != SecurityPolicyExportVersion {
+		return fmt.Errorf("%w: unsupported version %d (expected %d)", ErrInvalidPolicyImport, e.Version, SecurityPolicyExportVersion)
+	}
+
+	names := make(map[string]bool, len(e.Policies))
+	for i, policy := range e.Policies {
+		if strings.TrimSpace(policy.Name) == "" {
+			return fmt.Errorf("%w: policy %d has no name", ErrInvalidPolicyImport, i)
+		}
+		if names[policy.Name] {
+			return fmt.Errorf("%w: policy name %q appears more than once", ErrInvalidPolicyImport, policy.Name)
+		}
+		names[policy.Name] = true
+
+		if !policy.PolicyType.IsValid() {
+			return fmt.Errorf("%w: policy %q has unknown type %q", ErrInvalidPolicyImport, policy.Name, policy.PolicyType)
+		}
+		if !policy.EnforcementAction.IsValid() {
+			return fmt.Errorf("%w: policy %q has unknown enforcement action %q", ErrInvalidPolicyImport, policy.Name, policy.EnforcementAction)
+		}
+		switch policy.SeverityThreshold {
+		case AlertSeverityInfo, AlertSeverityWarning, AlertSeverityHigh, AlertSeverityCritical:
+		default:
+			return fmt.Errorf("%w: policy %q has unknown severity threshold %q", ErrInvalidPolicyImport, policy.Name, policy.SeverityThreshold)
+		}
+		if strings.TrimSpace(policy.AppliesTo) == "" {
+			return fmt.Errorf("%w: policy %q has no appliesTo scope", ErrInvalidPolicyImport, policy.Name)
+		}
+		if err := validatePolicyRules(policy.Rules); err != nil {
+			return fmt.Errorf("%w: policy %q: %v", ErrInvalidPolicyImport, policy.Name, err)
+		}
+	}
+	return nil
+}
+
+// validatePolicyRules accepts an omitted or null rules value, or a JSON object
+func validatePolicyRules(rules json.RawMessage) error {
+	trimmed := bytes.TrimSpace(rules)
+	if len(trimmed) == 0 || bytes.Equal(trimmed, []byte("null")) {
+		return nil
+	}
+
+	var object map[string]interface{}
+	if err := json.Unmarshal(trimmed, &object); err != nil {
+		return errors.New("rules must be a JSON object")
+	}
+	return nil
+}
@@ -0,0 +1,43 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// TopologyNodeType identifies what a topology node represents
+type TopologyNodeType string
+
+const (
+	TopologyNodeAgent     TopologyNodeType = "agent"
+	TopologyNodeMCPServer TopologyNodeType = "mcp_server"
+)
+
+// TopologyNode is an agent or MCP server in an organization's agent↔MCP graph
+type TopologyNode struct {
+	ID         uuid.UUID        `json:"id"`
+	Type       TopologyNodeType `json:"type"`
+	Name       string           `json:"name"`
+	Status     string           `json:"status"`
+	TrustScore float64          `json:"trustScore"`
+}
+
+// TopologyEdge connects an agent to an MCP server. An edge exists when the agent declares the
+// server in talks_to, has a recorded connection to it, or both.
+type TopologyEdge struct {
+	AgentID          uuid.UUID       `json:"agentId"`
+	MCPServerID      uuid.UUID       `json:"mcpServerId"`
+	Declared         bool            `json:"declared"`                 // Listed in the agent's talks_to
+	ConnectionType   ConnectionType  `json:"connectionType,omitempty"` // Set when a connection is recorded
+	DetectedMethod   DetectionMethod `json:"detectedMethod,omitempty"` // Method of the most confident detection
+	Confidence       float64         `json:"confidence"`               // 0-100
+	AttestationCount int             `json:"attestationCount"`
+	LastAttestedAt   *time.Time      `json:"lastAttestedAt,omitempty"`
+}
+
+// AgentMCPTopology is the graph of agents, MCP servers and the connections between them
+type AgentMCPTopology struct {
+	Nodes []TopologyNode `json:"nodes"`
+	Edges []TopologyEdge `json:"edges"`
+}
@@ -32,14 +32,42 @@ type TrustScoreFactors struct {
 
 	// Factor 8: User Feedback (5% weight) - Explicit user ratings
 	UserFeedback float64 `json:"userFeedback"` // 0-1
+
+	// The factors below only count for organizations that weight their category (see TrustWeights)
+
+	// Capability violations in the last 30 days by severity
+	CapabilityViolations float64 `json:"capabilityViolations"` // 0-1
+
+	// Remaining lifetime of the agent's signing key
+	KeyAge float64 `json:"keyAge"` // 0-1
+
+	// Verification status of the MCP servers the agent talks to
+	MCPServers float64 `json:"mcpServers"` // 0-1
+}
+
+// TrustScoreFactorContribution explains how a single factor contributed to the trust score
+type TrustScoreFactorContribution struct {
+	Factor       string  `json:"factor"`       // e.g. "verificationStatus"
+	Category     string  `json:"category"`     // Trust weight category the factor belongs to, e.g. "verification"
+	RawValue     float64 `json:"rawValue"`     // 0-1
+	Weight       float64 `json:"weight"`       // 0-1, the factor's share of its category weight; all weights sum to 1
+	Contribution float64 `json:"contribution"` // RawValue × Weight
+}
+
+// TrustScoreBreakdown lists every factor's raw value, weight and weighted contribution
+// Contributions sum to the final score (before clamping to [0, 1])
+type TrustScoreBreakdown struct {
+	Factors []TrustScoreFactorContribution `json:"factors"`
+	Total   float64                        `json:"total"`
 }
 
 // TrustScore represents a calculated trust score for an agent
 type TrustScore struct {
-	ID             uuid.UUID         `json:"id"`
-	AgentID        uuid.UUID         `json:"agentId"`
-	Score          float64           `json:"score"` // 0-1
-	Factors        TrustScoreFactors `json:"factors"`
+	ID             uuid.UUID            `json:"id"`
+	AgentID        uuid.UUID            `json:"agentId"`
+	Score          float64              `json:"score"` // 0-1
+	Factors        TrustScoreFactors    `json:"factors"`
+	Breakdown      *TrustScoreBreakdown `json:"breakdown,omitempty"` // Only populated on fresh calculation
 	Confidence     float64           `json:"confidence"` // 0-1
 	LastCalculated time.Time         `json:"lastCalculated"`
 	CreatedAt      time.Time         `json:"createdAt"`
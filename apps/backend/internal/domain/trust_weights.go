@@ -0,0 +1,83 @@
+package domain
+
+import (
+	"fmt"
+	"math"
+)
+
+// trustWeightsSumTolerance absorbs floating point error when checking that the weights add up to 1.0
+const trustWeightsSumTolerance = 1e-6
+
+// Trust weight categories. Every trust score factor belongs to one of them; a category's weight
+// is shared by its factors (see TrustScoreFactorContribution.Category).
+const (
+	TrustCategoryVerification = "verification" // Verification status, uptime, success rate, compliance, age, drift and feedback
+	TrustCategoryViolations   = "violations"   // Capability violations in the last 30 days
+	TrustCategoryKeyAge       = "key_age"      // How much of the signing key's lifetime is left
+	TrustCategoryMCP          = "mcp"          // Verification status of the MCP servers the agent talks to
+	TrustCategoryAlerts       = "alerts"       // Active security alerts
+)
+
+// TrustWeights are an organization's trust score weights per category. They must add up to 1.0;
+// a category weighted 0 does not affect the score.
+type TrustWeights struct {
+	Verification float64 `json:"verification"`
+	Violations   float64 `json:"violations"`
+	KeyAge       float64 `json:"key_age"`
+	MCP          float64 `json:"mcp"`
+	Alerts       float64 `json:"alerts"`
+}
+
+// DefaultTrustWeights returns the weights used when an organization has not configured its own.
+// They reproduce the documented 8-factor formula: violations, key age and MCP servers only
+// count for organizations that weight them.
+func DefaultTrustWeights() TrustWeights {
+	return TrustWeights{
+		Verification: 0.85,
+		Alerts:       0.15,
+	}
+}
+
+// Weight returns the weight of the named category, 0 for an unknown category
+func (w TrustWeights) Weight(category string) float64 {
+	switch category {
+	case TrustCategoryVerification:
+		return w.Verification
+	case TrustCategoryViolations:
+		return w.Violations
+	case TrustCategoryKeyAge:
+		return w.KeyAge
+	case TrustCategoryMCP:
+		return w.MCP
+	case TrustCategoryAlerts:
+		return w.Alerts
+	default:
+		return 0
+	}
+}
+
+// Validate checks that every weight is between 0 and 1 and that they add up to 1.0
+func (w TrustWeights) Validate() error {
+	weights := []struct {
+		name   string
+		weight float64
+	}{
+		{TrustCategoryVerification, w.Verification},
+		{TrustCategoryViolations, w.Violations},
+		{TrustCategoryKeyAge, w.KeyAge},
+		{TrustCategoryMCP, w.MCP},
+		{TrustCategoryAlerts, w.Alerts},
+	}
+
+	sum := 0.0
+	for _, category := range weights {
+		if math.IsNaN(category.weight) || category.weight < 0 || category.weight > 1 {
+			return fmt.Errorf("%s weight must be between 0 and 1", category.name)
+		}
+		sum += category.weight
+	}
+	if math.Abs(sum-1) > trustWeightsSumTolerance {
+		return fmt.Errorf("weights must add up to 1.0, got %.4f", sum)
+	}
+	return nil
+}
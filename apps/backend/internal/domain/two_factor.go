@@ -0,0 +1,30 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// UserTwoFactor holds a user's TOTP two-factor enrollment. The secret is stored encrypted and
+// backup codes are stored as hashes; enrollment only takes effect once Enabled is set by verifying
+// a first code.
+type UserTwoFactor struct {
+	UserID           uuid.UUID  `json:"userId"`
+	EncryptedSecret  string     `json:"-"`
+	Enabled          bool       `json:"enabled"`
+	EnabledAt        *time.Time `json:"enabledAt,omitempty"`
+	BackupCodeHashes []string   `json:"-"`
+	LastUsedStep     int64      `json:"-"` // TOTP time step of the last accepted code, to reject replays
+	CreatedAt        time.Time  `json:"createdAt"`
+	UpdatedAt        time.Time  `json:"updatedAt"`
+}
+
+// TwoFactorRepository defines the interface for two-factor enrollment persistence
+type TwoFactorRepository interface {
+	// Upsert creates or replaces the user's enrollment
+	Upsert(twoFactor *UserTwoFactor) error
+
+	// GetByUserID returns the enrollment, or nil if the user never enrolled
+	GetByUserID(userID uuid.UUID) (*UserTwoFactor, error)
+}
@@ -49,6 +49,12 @@ type User struct {
 	UpdatedAt              time.Time  `json:"updatedAt"`
 }
 
+// UserDeactivationCascade lists the resources suspended together with a deactivated user
+type UserDeactivationCascade struct {
+	SuspendedAgentIDs []uuid.UUID `json:"suspendedAgentIds"`
+	DisabledAPIKeyIDs []uuid.UUID `json:"disabledApiKeyIds"`
+}
+
 // UserRepository defines the interface for user persistence
 type UserRepository interface {
 	Create(user *User) error
@@ -58,7 +64,15 @@ type UserRepository interface {
 	GetByOrganization(orgID uuid.UUID) ([]*User, error)
 	GetByOrganizationAndStatus(orgID uuid.UUID, status UserStatus) ([]*User, error)
 	Update(user *User) error
+	// UpdateWithinQuota saves user like Update after checking, under a lock on its organization,
+	// that the organization has a seat for it under max_users; it returns a *QuotaExceededError
+	// otherwise
+	UpdateWithinQuota(user *User) error
 	UpdateRole(id uuid.UUID, role UserRole) error
 	Delete(id uuid.UUID) error
 	CountActiveUsers(orgID uuid.UUID, withinMinutes int) (int, error)
+
+	// DeactivateWithCascade deactivates the user, suspends the active agents they created and
+	// disables the API keys they created or that belong to those agents, in one transaction
+	DeactivateWithCascade(userID uuid.UUID, at time.Time) (*UserDeactivationCascade, error)
 }
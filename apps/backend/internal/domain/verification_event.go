@@ -138,6 +138,7 @@ type VerificationStatusCounts struct {
 // VerificationEventRepository defines the interface for verification event storage
 type VerificationEventRepository interface {
 	Create(event *VerificationEvent) error
+	CreateBatch(events []*VerificationEvent) error // Inserts in order, keeping each event's ID and CreatedAt
 	GetByID(id uuid.UUID) (*VerificationEvent, error)
 	GetByOrganization(orgID uuid.UUID, limit, offset int) ([]*VerificationEvent, int, error)
 	GetByAgent(agentID uuid.UUID, limit, offset int) ([]*VerificationEvent, int, error)
@@ -147,6 +148,9 @@ type VerificationEventRepository interface {
 	SearchAdminVerifications(orgID uuid.UUID, params VerificationQueryParams) ([]*VerificationEvent, int, *VerificationStatusCounts, error)
 	GetStatistics(orgID uuid.UUID, startTime, endTime time.Time) (*VerificationStatistics, error)
 	GetAgentStatistics(agentID uuid.UUID, startTime, endTime time.Time) (*AgentVerificationStatistics, error)
+	GetStatisticsByProtocol(orgID uuid.UUID, startTime, endTime time.Time) (map[VerificationProtocol]*ProtocolVerificationStatistics, error)
+	GetLatencyStatistics(orgID uuid.UUID, startTime, endTime time.Time) (*VerificationLatencyStatistics, error)
+	CountAgentEventsByWindow(agentID uuid.UUID, now time.Time, window time.Duration, windows int) ([]int, error)
 	UpdateResult(id uuid.UUID, result VerificationResult, reason *string, metadata map[string]interface{}) error
 	Delete(id uuid.UUID) error
 }
@@ -180,3 +184,22 @@ type AgentVerificationStatistics struct {
 	AvgConfidence      float64   `json:"avgConfidence"`
 	LastVerification   time.Time `json:"lastVerification"`
 }
+
+// ProtocolVerificationStatistics represents verification metrics for a single protocol
+type ProtocolVerificationStatistics struct {
+	Protocol      VerificationProtocol `json:"protocol"`
+	Total         int                  `json:"total"`
+	SuccessCount  int                  `json:"successCount"`
+	FailureCount  int                  `json:"failureCount"` // failed and timed out events
+	P50DurationMs float64              `json:"p50DurationMs"`
+	P95DurationMs float64              `json:"p95DurationMs"`
+}
+
+// VerificationLatencyStatistics represents verification duration percentiles for a time range.
+// Percentiles are interpolated between the nearest durations.
+type VerificationLatencyStatistics struct {
+	Count         int     `json:"count"` // events with a recorded duration
+	P50DurationMs float64 `json:"p50DurationMs"`
+	P95DurationMs float64 `json:"p95DurationMs"`
+	P99DurationMs float64 `json:"p99DurationMs"`
+}
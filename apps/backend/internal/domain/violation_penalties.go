@@ -0,0 +1,75 @@
+package domain
+
+import "fmt"
+
+// MaxViolationPenalty caps the trust score penalty, in percentage points, of a single violation
+const MaxViolationPenalty = 100
+
+// SeverityPenalties are the trust score penalties, in percentage points, of a violation of each severity
+type SeverityPenalties struct {
+	Low      int `json:"low"`
+	Medium   int `json:"medium"`
+	High     int `json:"high"`
+	Critical int `json:"critical"`
+}
+
+// ViolationPenalties are an organization's trust score penalties for capability violations.
+// Blocked violations and alert-only violations (allowed, but recorded) are tuned separately.
+type ViolationPenalties struct {
+	Alert   SeverityPenalties `json:"alert"`
+	Blocked SeverityPenalties `json:"blocked"`
+}
+
+// DefaultViolationPenalties returns the penalties used when an organization has not configured its own
+func DefaultViolationPenalties() ViolationPenalties {
+	return ViolationPenalties{
+		Alert:   SeverityPenalties{Low: 5, Medium: 7, High: 10, Critical: 15},
+		Blocked: SeverityPenalties{Low: 10, Medium: 10, High: 10, Critical: 15},
+	}
+}
+
+// TrustScoreImpact returns the trust score impact (a negative number of percentage points) of a
+// violation with the given severity. Unknown severities are penalized as low.
+func (p ViolationPenalties) TrustScoreImpact(severity string, blocked bool) int {
+	penalties := p.Alert
+	if blocked {
+		penalties = p.Blocked
+	}
+
+	switch severity {
+	case ViolationSeverityCritical:
+		return -penalties.Critical
+	case ViolationSeverityHigh:
+		return -penalties.High
+	case ViolationSeverityMedium:
+		return -penalties.Medium
+	default:
+		return -penalties.Low
+	}
+}
+
+// Validate checks that every penalty is between 0 and MaxViolationPenalty
+func (p ViolationPenalties) Validate() error {
+	for _, mode := range []struct {
+		name      string
+		penalties SeverityPenalties
+	}{
+		{"alert", p.Alert},
+		{"blocked", p.Blocked},
+	} {
+		for _, penalty := range []struct {
+			severity string
+			value    int
+		}{
+			{ViolationSeverityLow, mode.penalties.Low},
+			{ViolationSeverityMedium, mode.penalties.Medium},
+			{ViolationSeverityHigh, mode.penalties.High},
+			{ViolationSeverityCritical, mode.penalties.Critical},
+		} {
+			if penalty.value < 0 || penalty.value > MaxViolationPenalty {
+				return fmt.Errorf("%s.%s penalty must be between 0 and %d", mode.name, penalty.severity, MaxViolationPenalty)
+			}
+		}
+	}
+	return nil
+}
@@ -10,41 +10,147 @@ import (
 type WebhookEvent string
 
 const (
-	WebhookEventAgentCreated      WebhookEvent = "agent.created"
-	WebhookEventAgentVerified     WebhookEvent = "agent.verified"
-	WebhookEventAgentSuspended    WebhookEvent = "agent.suspended"
-	WebhookEventTrustScoreChanged WebhookEvent = "trust_score.changed"
-	WebhookEventAlertCreated      WebhookEvent = "alert.created"
+	WebhookEventAgentCreated        WebhookEvent = "agent.created"
+	WebhookEventAgentUpdated        WebhookEvent = "agent.updated"
+	WebhookEventAgentDeleted        WebhookEvent = "agent.deleted"
+	WebhookEventAgentVerified       WebhookEvent = "agent.verified"
+	WebhookEventAgentSuspended      WebhookEvent = "agent.suspended"
+	WebhookEventAgentReactivated    WebhookEvent = "agent.reactivated"
+	WebhookEventAgentCompromised    WebhookEvent = "agent.compromised"
+	WebhookEventAgentUncompromised  WebhookEvent = "agent.uncompromised"
+	WebhookEventTrustScoreChanged   WebhookEvent = "trust_score.changed"
+	WebhookEventTrustScoreCritical  WebhookEvent = "trust_score.critical"
+	WebhookEventTrustScoreDrop      WebhookEvent = "trust_score_drop"
+	WebhookEventAlertCreated        WebhookEvent = "alert.created"
+	WebhookEventAlertAcknowledged   WebhookEvent = "alert.acknowledged"
+	WebhookEventAlertResolved       WebhookEvent = "alert.resolved"
+	WebhookEventAPIKeyCreated       WebhookEvent = "api_key.created"
+	WebhookEventAPIKeyRevoked       WebhookEvent = "api_key.revoked"
+	WebhookEventAPIKeyExpired       WebhookEvent = "api_key.expired"
+	WebhookEventVerificationFailed  WebhookEvent = "verification.failed"
 	WebhookEventComplianceViolation WebhookEvent = "compliance.violation"
+	WebhookEventSecurityBreach      WebhookEvent = "security_breach"
 )
 
+// KnownWebhookEvents lists every event a webhook may subscribe to
+var KnownWebhookEvents = []WebhookEvent{
+	WebhookEventAgentCreated,
+	WebhookEventAgentUpdated,
+	WebhookEventAgentDeleted,
+	WebhookEventAgentVerified,
+	WebhookEventAgentSuspended,
+	WebhookEventAgentReactivated,
+	WebhookEventAgentCompromised,
+	WebhookEventAgentUncompromised,
+	WebhookEventTrustScoreChanged,
+	WebhookEventTrustScoreCritical,
+	WebhookEventTrustScoreDrop,
+	WebhookEventAlertCreated,
+	WebhookEventAlertAcknowledged,
+	WebhookEventAlertResolved,
+	WebhookEventAPIKeyCreated,
+	WebhookEventAPIKeyRevoked,
+	WebhookEventAPIKeyExpired,
+	WebhookEventVerificationFailed,
+	WebhookEventComplianceViolation,
+	WebhookEventSecurityBreach,
+}
+
+// IsValid reports whether the event is one webhooks can subscribe to
+func (e WebhookEvent) IsValid() bool {
+	for _, known := range KnownWebhookEvents {
+		if e == known {
+			return true
+		}
+	}
+	return false
+}
+
+// Resource types an event can concern; a webhook with a resource type set only
+// receives events about that kind of resource
+const (
+	WebhookResourceAgent        = "agent"
+	WebhookResourceMCPServer    = "mcp_server"
+	WebhookResourceAPIKey       = "api_key"
+	WebhookResourceAlert        = "alert"
+	WebhookResourceVerification = "verification"
+	WebhookResourceOrganization = "organization"
+)
+
+// KnownWebhookResourceTypes lists the resource types a webhook may filter on
+var KnownWebhookResourceTypes = []string{
+	WebhookResourceAgent,
+	WebhookResourceMCPServer,
+	WebhookResourceAPIKey,
+	WebhookResourceAlert,
+	WebhookResourceVerification,
+	WebhookResourceOrganization,
+}
+
+// AgentStatusWebhookData is the payload of agent.verified, agent.suspended and agent.reactivated events
+type AgentStatusWebhookData struct {
+	AgentID            uuid.UUID   `json:"agentId"`
+	AgentName          string      `json:"agentName"`
+	PreviousStatus     AgentStatus `json:"previousStatus"`
+	Status             AgentStatus `json:"status"`
+	PreviousTrustScore float64     `json:"previousTrustScore"`
+	TrustScore         float64     `json:"trustScore"`
+	ChangedAt          time.Time   `json:"changedAt"`
+}
+
+// TrustScoreDropWebhookData is the payload of trust_score_drop events
+type TrustScoreDropWebhookData struct {
+	AgentID            uuid.UUID     `json:"agentId"`
+	AgentName          string        `json:"agentName"`
+	PreviousTrustScore float64       `json:"previousTrustScore"`
+	TrustScore         float64       `json:"trustScore"`
+	Severity           AlertSeverity `json:"severity"` // warning or critical, as for the trust score drop alert
+	ChangedAt          time.Time     `json:"changedAt"`
+}
+
 // Webhook represents a webhook subscription
 type Webhook struct {
-	ID             uuid.UUID      `json:"id"`
-	OrganizationID uuid.UUID      `json:"organizationId"`
-	Name           string         `json:"name"`
-	URL            string         `json:"url"`
-	Events         []WebhookEvent `json:"events"`
-	Secret         string         `json:"secret"` // For webhook signature verification
-	IsActive       bool           `json:"isActive"`
-	LastTriggered  *time.Time     `json:"lastTriggered"`
-	FailureCount   int            `json:"failureCount"`
-	CreatedAt      time.Time      `json:"createdAt"`
-	UpdatedAt      time.Time      `json:"updatedAt"`
-	CreatedBy      uuid.UUID      `json:"createdBy"`
-}
-
-// WebhookDelivery represents a webhook delivery attempt
+	ID              uuid.UUID      `json:"id"`
+	OrganizationID  uuid.UUID      `json:"organizationId"`
+	Name            string         `json:"name"`
+	URL             string         `json:"url"`
+	Events          []WebhookEvent `json:"events"`
+	ResourceType    *string        `json:"resourceType,omitempty"` // Only deliver events about this resource type (nil = all)
+	Secret          string         `json:"secret,omitempty"`       // Plaintext secret - only populated on creation and rotation
+	EncryptedSecret string         `json:"-"`                      // Secret encrypted with the key vault; decrypted to sign deliveries
+	IsActive        bool           `json:"isActive"`
+	LastTriggered   *time.Time     `json:"lastTriggered"`
+	FailureCount    int            `json:"failureCount"`
+	CreatedAt       time.Time      `json:"createdAt"`
+	UpdatedAt       time.Time      `json:"updatedAt"`
+	CreatedBy       uuid.UUID      `json:"createdBy"`
+}
+
+// WebhookDeliveryStatus represents the state of a webhook delivery
+type WebhookDeliveryStatus string
+
+const (
+	WebhookDeliveryPending   WebhookDeliveryStatus = "pending"
+	WebhookDeliveryRetrying  WebhookDeliveryStatus = "retrying"
+	WebhookDeliverySucceeded WebhookDeliveryStatus = "succeeded"
+	WebhookDeliveryFailed    WebhookDeliveryStatus = "failed" // Retries exhausted
+)
+
+// WebhookDelivery represents a webhook delivery and its retry state
 type WebhookDelivery struct {
-	ID           uuid.UUID    `json:"id"`
-	WebhookID    uuid.UUID    `json:"webhookId"`
-	Event        WebhookEvent `json:"event"`
-	Payload      string       `json:"payload"`
-	StatusCode   int          `json:"statusCode"`
-	ResponseBody string       `json:"responseBody"`
-	Success      bool         `json:"success"`
-	AttemptCount int          `json:"attemptCount"`
-	CreatedAt    time.Time    `json:"createdAt"`
+	ID            uuid.UUID             `json:"id"`
+	WebhookID     uuid.UUID             `json:"webhookId"`
+	Event         WebhookEvent          `json:"event"`
+	Payload       string                `json:"payload"`
+	StatusCode    int                   `json:"statusCode"`
+	Success       bool                  `json:"success"`
+	Status        WebhookDeliveryStatus `json:"status"`
+	AttemptCount  int                   `json:"attemptCount"`
+	ErrorMessage  string                `json:"errorMessage,omitempty"`
+	NextAttemptAt *time.Time            `json:"nextAttemptAt,omitempty"`
+	LastAttemptAt *time.Time            `json:"lastAttemptAt,omitempty"`
+	CreatedAt     time.Time             `json:"createdAt"`
+	UpdatedAt     time.Time             `json:"updatedAt"`
 }
 
 // WebhookRepository defines the interface for webhook persistence
@@ -54,6 +160,13 @@ type WebhookRepository interface {
 	GetByOrganization(orgID uuid.UUID) ([]*Webhook, error)
 	Update(webhook *Webhook) error
 	Delete(id uuid.UUID) error
+	UpdateSecret(id uuid.UUID, encryptedSecret string) error
+	// GetPlaintextSecrets returns the secrets of webhooks created before secrets were encrypted, by webhook ID
+	GetPlaintextSecrets() (map[uuid.UUID]string, error)
 	RecordDelivery(delivery *WebhookDelivery) error
+	UpdateDelivery(delivery *WebhookDelivery) error
 	GetDeliveries(webhookID uuid.UUID, limit, offset int) ([]*WebhookDelivery, error)
+	// ClaimDueDeliveries returns up to limit pending or retrying deliveries due at now, oldest first,
+	// and hides them from other workers for lease by pushing back NextAttemptAt
+	ClaimDueDeliveries(now time.Time, lease time.Duration, limit int) ([]*WebhookDelivery, error)
 }
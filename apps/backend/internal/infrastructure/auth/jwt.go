@@ -1,8 +1,13 @@
 package auth
 
 import (
+	"crypto/rsa"
+	"encoding/base64"
 	"fmt"
+	"math/big"
 	"os"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
@@ -18,14 +23,59 @@ type JWTClaims struct {
 	jwt.RegisteredClaims
 }
 
+// DefaultJWTIssuer is the iss claim of tokens when no issuer is configured
+const DefaultJWTIssuer = "agent-identity-management"
+
+// sdkIssuerSuffix marks SDK refresh tokens, which are issued by "<issuer>-sdk"
+const sdkIssuerSuffix = "-sdk"
+
+// JWTConfig configures a JWTService
+type JWTConfig struct {
+	Secret          string
+	AccessTokenTTL  time.Duration
+	RefreshTokenTTL time.Duration
+	// Issuer is set as the iss claim and required on verification (default DefaultJWTIssuer)
+	Issuer string
+	// Audience, when set, is added as the aud claim and required on verification
+	Audience string
+	// SigningKeys are the active RS256 keys. Tokens are signed with SigningKeyID (default: the
+	// first key) and verified with whichever active key their kid header names, so a key is
+	// rotated by adding a new one and retired by removing it. Without signing keys tokens are
+	// signed with Secret (HS256); tokens without a kid are verified with Secret unless RejectHS256.
+	SigningKeys  []JWTSigningKey
+	SigningKeyID string
+	// RejectHS256 stops verifying tokens with Secret, retiring it once the HS256 tokens issued
+	// before SigningKeys were configured have expired. It requires SigningKeys.
+	RejectHS256 bool
+}
+
+// JWTSigningKey is an RS256 key identified by the kid header of the tokens it signs
+type JWTSigningKey struct {
+	ID         string
+	PrivateKey *rsa.PrivateKey
+}
+
+// minSigningKeyBits is the smallest RSA modulus accepted for signing keys
+const minSigningKeyBits = 2048
+
 // JWTService handles JWT operations
 type JWTService struct {
-	secret         []byte
-	accessExpiry   time.Duration
-	refreshExpiry  time.Duration
+	secret        []byte
+	accessExpiry  time.Duration
+	refreshExpiry time.Duration
+	issuer        string
+	audience      string
+	keys          map[string]*rsa.PrivateKey
+	keyIDs        []string // Active key IDs in configuration order
+	signingKeyID  string   // Empty when tokens are signed with the HS256 secret
+	rejectHS256   bool
+	now           func() time.Time
 }
 
-// NewJWTService creates a new JWT service
+// NewJWTService creates a JWT service configured from JWT_SECRET, JWT_ACCESS_TTL,
+// JWT_REFRESH_TTL, JWT_ISSUER, JWT_AUDIENCE, JWT_SIGNING_KEYS, JWT_SIGNING_KEY_ID and
+// JWT_REJECT_HS256.
+// It panics on an invalid configuration.
 func NewJWTService() *JWTService {
 	secret := os.Getenv("JWT_SECRET")
 	if secret == "" {
@@ -33,14 +83,119 @@ func NewJWTService() *JWTService {
 	}
 
 	// Get expiry durations from env or use defaults
-	accessExpiry, _ := time.ParseDuration(getEnv("JWT_ACCESS_TTL", "24h"))
-	refreshExpiry, _ := time.ParseDuration(getEnv("JWT_REFRESH_TTL", "168h"))
+	accessExpiry, err := time.ParseDuration(getEnv("JWT_ACCESS_TTL", "24h"))
+	if err != nil {
+		panic(fmt.Sprintf("invalid JWT_ACCESS_TTL: %v", err))
+	}
+	refreshExpiry, err := time.ParseDuration(getEnv("JWT_REFRESH_TTL", "168h"))
+	if err != nil {
+		panic(fmt.Sprintf("invalid JWT_REFRESH_TTL: %v", err))
+	}
+	signingKeys, err := LoadJWTSigningKeys(os.Getenv("JWT_SIGNING_KEYS"))
+	if err != nil {
+		panic(fmt.Sprintf("invalid JWT_SIGNING_KEYS: %v", err))
+	}
+	rejectHS256, _ := strconv.ParseBool(os.Getenv("JWT_REJECT_HS256"))
+
+	service, err := NewJWTServiceWithConfig(JWTConfig{
+		Secret:          secret,
+		AccessTokenTTL:  accessExpiry,
+		RefreshTokenTTL: refreshExpiry,
+		Issuer:          os.Getenv("JWT_ISSUER"),
+		Audience:        os.Getenv("JWT_AUDIENCE"),
+		SigningKeys:     signingKeys,
+		SigningKeyID:    os.Getenv("JWT_SIGNING_KEY_ID"),
+		RejectHS256:     rejectHS256,
+	})
+	if err != nil {
+		panic(err.Error())
+	}
+	return service
+}
+
+// NewJWTServiceWithConfig creates a JWT service from cfg
+func NewJWTServiceWithConfig(cfg JWTConfig) (*JWTService, error) {
+	if cfg.Secret == "" {
+		return nil, fmt.Errorf("JWT secret is required")
+	}
+	if cfg.AccessTokenTTL <= 0 {
+		return nil, fmt.Errorf("JWT access token TTL must be positive, got %s", cfg.AccessTokenTTL)
+	}
+	if cfg.RefreshTokenTTL <= 0 {
+		return nil, fmt.Errorf("JWT refresh token TTL must be positive, got %s", cfg.RefreshTokenTTL)
+	}
+	if cfg.Issuer == "" {
+		cfg.Issuer = DefaultJWTIssuer
+	}
+
+	keys := make(map[string]*rsa.PrivateKey, len(cfg.SigningKeys))
+	keyIDs := make([]string, 0, len(cfg.SigningKeys))
+	for _, key := range cfg.SigningKeys {
+		if key.ID == "" {
+			return nil, fmt.Errorf("JWT signing key ID is required")
+		}
+		if key.PrivateKey == nil {
+			return nil, fmt.Errorf("JWT signing key %q has no private key", key.ID)
+		}
+		if key.PrivateKey.N.BitLen() < minSigningKeyBits {
+			return nil, fmt.Errorf("JWT signing key %q must be at least %d bits", key.ID, minSigningKeyBits)
+		}
+		if _, exists := keys[key.ID]; exists {
+			return nil, fmt.Errorf("duplicate JWT signing key ID %q", key.ID)
+		}
+		keys[key.ID] = key.PrivateKey
+		keyIDs = append(keyIDs, key.ID)
+	}
+
+	signingKeyID := cfg.SigningKeyID
+	if signingKeyID == "" && len(keyIDs) > 0 {
+		signingKeyID = keyIDs[0]
+	}
+	if _, ok := keys[signingKeyID]; signingKeyID != "" && !ok {
+		return nil, fmt.Errorf("JWT signing key ID %q is not an active signing key", signingKeyID)
+	}
+	if cfg.RejectHS256 && len(keyIDs) == 0 {
+		return nil, fmt.Errorf("rejecting HS256 tokens requires RS256 signing keys")
+	}
 
 	return &JWTService{
-		secret:        []byte(secret),
-		accessExpiry:  accessExpiry,
-		refreshExpiry: refreshExpiry,
+		secret:        []byte(cfg.Secret),
+		accessExpiry:  cfg.AccessTokenTTL,
+		refreshExpiry: cfg.RefreshTokenTTL,
+		issuer:        cfg.Issuer,
+		audience:      cfg.Audience,
+		keys:          keys,
+		keyIDs:        keyIDs,
+		signingKeyID:  signingKeyID,
+		rejectHS256:   cfg.RejectHS256,
+		now:           time.Now,
+	}, nil
+}
+
+// LoadJWTSigningKeys reads signing keys from a comma-separated list of kid=path entries,
+// each path naming a PEM-encoded RSA private key. An empty spec yields no keys.
+func LoadJWTSigningKeys(spec string) ([]JWTSigningKey, error) {
+	var keys []JWTSigningKey
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		id, path, ok := strings.Cut(entry, "=")
+		if !ok || strings.TrimSpace(id) == "" || strings.TrimSpace(path) == "" {
+			return nil, fmt.Errorf("expected kid=path, got %q", entry)
+		}
+		pemBytes, err := os.ReadFile(strings.TrimSpace(path))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read signing key %q: %w", id, err)
+		}
+		privateKey, err := jwt.ParseRSAPrivateKeyFromPEM(pemBytes)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse signing key %q: %w", id, err)
+		}
+		keys = append(keys, JWTSigningKey{ID: strings.TrimSpace(id), PrivateKey: privateKey})
 	}
+	return keys, nil
 }
 
 // getEnv is a helper function to get env var with fallback
@@ -51,30 +206,78 @@ func getEnv(key, fallback string) string {
 	return fallback
 }
 
+// registeredClaims builds the standard claims of a token for userID that is valid for expiry
+func (s *JWTService) registeredClaims(issuer, userID string, expiry time.Duration) jwt.RegisteredClaims {
+	now := s.now()
+	claims := jwt.RegisteredClaims{
+		ExpiresAt: jwt.NewNumericDate(now.Add(expiry)),
+		IssuedAt:  jwt.NewNumericDate(now),
+		NotBefore: jwt.NewNumericDate(now),
+		Issuer:    issuer,
+		Subject:   userID,
+		ID:        uuid.New().String(),
+	}
+	if s.audience != "" {
+		claims.Audience = jwt.ClaimStrings{s.audience}
+	}
+	return claims
+}
+
+// sign signs claims with the current RS256 signing key, or the HS256 secret when none is configured
+func (s *JWTService) sign(claims JWTClaims) (string, error) {
+	if s.signingKeyID == "" {
+		return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(s.secret)
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = s.signingKeyID
+	return token.SignedString(s.keys[s.signingKeyID])
+}
+
+// verificationKey returns the key a token is verified with: the active RS256 key named by its
+// kid header, or the HS256 secret for tokens without one. Tokens naming a retired key are rejected,
+// as are tokens without a kid once HS256 is rejected.
+func (s *JWTService) verificationKey(token *jwt.Token) (interface{}, error) {
+	kid, hasKID := token.Header["kid"].(string)
+	if !hasKID {
+		if s.rejectHS256 {
+			return nil, fmt.Errorf("tokens without a kid are no longer accepted")
+		}
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+		}
+		return s.secret, nil
+	}
+
+	if _, ok := token.Method.(*jwt.SigningMethodRSA); !ok {
+		return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+	}
+	key, ok := s.keys[kid]
+	if !ok {
+		return nil, fmt.Errorf("unknown or retired signing key %q", kid)
+	}
+	return &key.PublicKey, nil
+}
+
+// sdkIssuer is the issuer of SDK refresh tokens
+func (s *JWTService) sdkIssuer() string {
+	return s.issuer + sdkIssuerSuffix
+}
+
 // GenerateSDKRefreshToken generates a refresh token for SDK usage (90 days)
 // This token is embedded in downloaded SDKs for auto-authentication
 // Security: Reduced from 1 year to 90 days to minimize exposure window
 func (s *JWTService) GenerateSDKRefreshToken(userID, orgID, email, role string) (string, error) {
-	now := time.Now()
 	sdkExpiry := 90 * 24 * time.Hour // 90 days (reduced from 365 for security)
 
 	claims := JWTClaims{
-		UserID:         userID,
-		OrganizationID: orgID,
-		Email:          email,
-		Role:           role,
-		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(now.Add(sdkExpiry)),
-			IssuedAt:  jwt.NewNumericDate(now),
-			NotBefore: jwt.NewNumericDate(now),
-			Issuer:    "agent-identity-management-sdk",
-			Subject:   userID,
-			ID:        uuid.New().String(),
-		},
+		UserID:           userID,
+		OrganizationID:   orgID,
+		Email:            email,
+		Role:             role,
+		RegisteredClaims: s.registeredClaims(s.sdkIssuer(), userID, sdkExpiry),
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString(s.secret)
+	return s.sign(claims)
 }
 
 // GenerateTokenPair generates access and refresh tokens
@@ -96,60 +299,53 @@ func (s *JWTService) GenerateTokenPair(userID, orgID, email, role string) (acces
 
 // GenerateAccessToken generates an access token
 func (s *JWTService) GenerateAccessToken(userID, orgID, email, role string) (string, error) {
-	now := time.Now()
 	claims := JWTClaims{
-		UserID:         userID,
-		OrganizationID: orgID,
-		Email:          email,
-		Role:           role,
-		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(now.Add(s.accessExpiry)),
-			IssuedAt:  jwt.NewNumericDate(now),
-			NotBefore: jwt.NewNumericDate(now),
-			Issuer:    "agent-identity-management",
-			Subject:   userID,
-			ID:        uuid.New().String(),
-		},
+		UserID:           userID,
+		OrganizationID:   orgID,
+		Email:            email,
+		Role:             role,
+		RegisteredClaims: s.registeredClaims(s.issuer, userID, s.accessExpiry),
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString(s.secret)
+	return s.sign(claims)
 }
 
 // GenerateRefreshToken generates a refresh token
 func (s *JWTService) GenerateRefreshToken(userID, orgID string) (string, error) {
-	now := time.Now()
 	claims := JWTClaims{
-		UserID:         userID,
-		OrganizationID: orgID,
-		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(now.Add(s.refreshExpiry)),
-			IssuedAt:  jwt.NewNumericDate(now),
-			NotBefore: jwt.NewNumericDate(now),
-			Issuer:    "agent-identity-management",
-			Subject:   userID,
-			ID:        uuid.New().String(),
-		},
+		UserID:           userID,
+		OrganizationID:   orgID,
+		RegisteredClaims: s.registeredClaims(s.issuer, userID, s.refreshExpiry),
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString(s.secret)
+	return s.sign(claims)
 }
 
-// ValidateToken validates and parses a JWT token
+// ValidateToken validates and parses a JWT token. The token must be issued by the
+// configured issuer (or its SDK issuer) and, when an audience is configured, for that audience.
 func (s *JWTService) ValidateToken(tokenString string) (*JWTClaims, error) {
-	token, err := jwt.ParseWithClaims(tokenString, &JWTClaims{}, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
-		}
-		return s.secret, nil
-	})
+	validMethods := []string{jwt.SigningMethodHS256.Alg(), jwt.SigningMethodRS256.Alg()}
+	if s.rejectHS256 {
+		validMethods = []string{jwt.SigningMethodRS256.Alg()}
+	}
+	options := []jwt.ParserOption{
+		jwt.WithTimeFunc(s.now),
+		jwt.WithValidMethods(validMethods),
+	}
+	if s.audience != "" {
+		options = append(options, jwt.WithAudience(s.audience))
+	}
+
+	token, err := jwt.ParseWithClaims(tokenString, &JWTClaims{}, s.verificationKey, options...)
 
 	if err != nil {
 		return nil, err
 	}
 
 	if claims, ok := token.Claims.(*JWTClaims); ok && token.Valid {
+		if claims.Issuer != s.issuer && claims.Issuer != s.sdkIssuer() {
+			return nil, fmt.Errorf("%w: unexpected issuer %q", jwt.ErrTokenInvalidIssuer, claims.Issuer)
+		}
 		return claims, nil
 	}
 
@@ -179,7 +375,7 @@ func (s *JWTService) RefreshTokenPair(refreshToken string) (string, string, erro
 	}
 
 	// Check if this is an SDK token (different issuer)
-	isSDKToken := claims.Issuer == "agent-identity-management-sdk"
+	isSDKToken := claims.Issuer == s.sdkIssuer()
 
 	var newAccessToken, newRefreshToken string
 
@@ -205,9 +401,7 @@ func (s *JWTService) RefreshTokenPair(refreshToken string) (string, string, erro
 // GetTokenID extracts the JTI (token ID) from a JWT without full validation
 // Useful for token revocation checks before full validation
 func (s *JWTService) GetTokenID(tokenString string) (string, error) {
-	token, err := jwt.ParseWithClaims(tokenString, &JWTClaims{}, func(token *jwt.Token) (interface{}, error) {
-		return s.secret, nil
-	})
+	token, err := jwt.ParseWithClaims(tokenString, &JWTClaims{}, s.verificationKey)
 
 	if err != nil {
 		return "", err
@@ -219,3 +413,36 @@ func (s *JWTService) GetTokenID(tokenString string) (string, error) {
 
 	return "", fmt.Errorf("failed to extract token ID")
 }
+
+// JWK is an RSA public key in JSON Web Key format (RFC 7517)
+type JWK struct {
+	Kty string `json:"kty"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+}
+
+// JWKS is a JSON Web Key Set
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// JWKS returns the public keys of all active signing keys, so relying parties can verify
+// tokens signed by any of them. It is empty when tokens are signed with the HS256 secret.
+func (s *JWTService) JWKS() JWKS {
+	set := JWKS{Keys: make([]JWK, 0, len(s.keyIDs))}
+	for _, id := range s.keyIDs {
+		publicKey := s.keys[id].PublicKey
+		set.Keys = append(set.Keys, JWK{
+			Kty: "RSA",
+			Use: "sig",
+			Alg: jwt.SigningMethodRS256.Alg(),
+			Kid: id,
+			N:   base64.RawURLEncoding.EncodeToString(publicKey.N.Bytes()),
+			E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(publicKey.E)).Bytes()),
+		})
+	}
+	return set
+}
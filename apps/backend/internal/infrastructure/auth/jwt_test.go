@@ -0,0 +1,290 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testJWTSecret = "test-secret-that-is-at-least-32-characters"
+
+func newTestJWTService(t *testing.T, cfg JWTConfig) *JWTService {
+	t.Helper()
+	cfg.Secret = testJWTSecret
+	if cfg.AccessTokenTTL == 0 {
+		cfg.AccessTokenTTL = time.Hour
+	}
+	if cfg.RefreshTokenTTL == 0 {
+		cfg.RefreshTokenTTL = 24 * time.Hour
+	}
+	service, err := NewJWTServiceWithConfig(cfg)
+	require.NoError(t, err)
+	return service
+}
+
+func TestJWTService_AccessTokenExpiresAfterConfiguredTTL(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	service := newTestJWTService(t, JWTConfig{AccessTokenTTL: 15 * time.Minute})
+	service.now = func() time.Time { return now }
+
+	token, err := service.GenerateAccessToken("user-1", "org-1", "user@example.com", "admin")
+	require.NoError(t, err)
+
+	claims, err := service.ValidateToken(token)
+	require.NoError(t, err)
+	assert.True(t, claims.ExpiresAt.Time.Equal(now.Add(15*time.Minute)))
+	assert.Equal(t, DefaultJWTIssuer, claims.Issuer)
+	assert.Empty(t, claims.Audience)
+
+	now = now.Add(14 * time.Minute)
+	_, err = service.ValidateToken(token)
+	assert.NoError(t, err)
+
+	now = now.Add(2 * time.Minute)
+	_, err = service.ValidateToken(token)
+	assert.ErrorIs(t, err, jwt.ErrTokenExpired)
+}
+
+func TestJWTService_RefreshTokenUsesRefreshTTL(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	service := newTestJWTService(t, JWTConfig{AccessTokenTTL: time.Minute, RefreshTokenTTL: 2 * time.Hour})
+	service.now = func() time.Time { return now }
+
+	token, err := service.GenerateRefreshToken("user-1", "org-1")
+	require.NoError(t, err)
+
+	claims, err := service.ValidateToken(token)
+	require.NoError(t, err)
+	assert.True(t, claims.ExpiresAt.Time.Equal(now.Add(2*time.Hour)))
+}
+
+func TestJWTService_ValidatesIssuerAndAudience(t *testing.T) {
+	service := newTestJWTService(t, JWTConfig{Issuer: "https://sso.example.com", Audience: "aim-api"})
+
+	token, err := service.GenerateAccessToken("user-1", "org-1", "user@example.com", "admin")
+	require.NoError(t, err)
+	claims, err := service.ValidateToken(token)
+	require.NoError(t, err)
+	assert.Equal(t, "https://sso.example.com", claims.Issuer)
+	assert.Equal(t, jwt.ClaimStrings{"aim-api"}, claims.Audience)
+
+	// Same secret, different issuer
+	otherIssuer := newTestJWTService(t, JWTConfig{Issuer: "https://other.example.com", Audience: "aim-api"})
+	token, err = otherIssuer.GenerateAccessToken("user-1", "org-1", "user@example.com", "admin")
+	require.NoError(t, err)
+	_, err = service.ValidateToken(token)
+	assert.ErrorIs(t, err, jwt.ErrTokenInvalidIssuer)
+
+	// Same secret and issuer, different audience
+	otherAudience := newTestJWTService(t, JWTConfig{Issuer: "https://sso.example.com", Audience: "another-api"})
+	token, err = otherAudience.GenerateAccessToken("user-1", "org-1", "user@example.com", "admin")
+	require.NoError(t, err)
+	_, err = service.ValidateToken(token)
+	assert.ErrorIs(t, err, jwt.ErrTokenInvalidAudience)
+
+	// No audience at all
+	noAudience := newTestJWTService(t, JWTConfig{Issuer: "https://sso.example.com"})
+	token, err = noAudience.GenerateAccessToken("user-1", "org-1", "user@example.com", "admin")
+	require.NoError(t, err)
+	_, err = service.ValidateToken(token)
+	assert.ErrorIs(t, err, jwt.ErrTokenRequiredClaimMissing)
+}
+
+func TestJWTService_SDKRefreshTokenRotatesWithCustomIssuer(t *testing.T) {
+	service := newTestJWTService(t, JWTConfig{Issuer: "https://sso.example.com"})
+
+	sdkToken, err := service.GenerateSDKRefreshToken("user-1", "org-1", "user@example.com", "admin")
+	require.NoError(t, err)
+
+	_, refreshToken, err := service.RefreshTokenPair(sdkToken)
+	require.NoError(t, err)
+	claims, err := service.ValidateToken(refreshToken)
+	require.NoError(t, err)
+	assert.Equal(t, "https://sso.example.com-sdk", claims.Issuer)
+}
+
+func TestNewJWTServiceWithConfig_RejectsInvalidTTL(t *testing.T) {
+	_, err := NewJWTServiceWithConfig(JWTConfig{Secret: testJWTSecret, AccessTokenTTL: 0, RefreshTokenTTL: time.Hour})
+	assert.Error(t, err)
+	_, err = NewJWTServiceWithConfig(JWTConfig{Secret: testJWTSecret, AccessTokenTTL: time.Hour, RefreshTokenTTL: -time.Hour})
+	assert.Error(t, err)
+}
+
+var (
+	testRSAKeysOnce sync.Once
+	testRSAKeys     [2]*rsa.PrivateKey
+)
+
+// testSigningKeys returns two RSA keys, generated once per test run
+func testSigningKeys(t *testing.T) (*rsa.PrivateKey, *rsa.PrivateKey) {
+	t.Helper()
+	testRSAKeysOnce.Do(func() {
+		for i := range testRSAKeys {
+			key, err := rsa.GenerateKey(rand.Reader, minSigningKeyBits)
+			if err != nil {
+				panic(err)
+			}
+			testRSAKeys[i] = key
+		}
+	})
+	return testRSAKeys[0], testRSAKeys[1]
+}
+
+func TestJWTService_RotatedKeys(t *testing.T) {
+	oldKey, newKey := testSigningKeys(t)
+
+	before := newTestJWTService(t, JWTConfig{
+		SigningKeys: []JWTSigningKey{{ID: "2025-01", PrivateKey: oldKey}},
+	})
+	oldToken, err := before.GenerateAccessToken("user-1", "org-1", "user@example.com", "admin")
+	require.NoError(t, err)
+
+	// Rotation: the new key signs, the old one stays active for verification
+	rotated := newTestJWTService(t, JWTConfig{
+		SigningKeys:  []JWTSigningKey{{ID: "2025-01", PrivateKey: oldKey}, {ID: "2025-06", PrivateKey: newKey}},
+		SigningKeyID: "2025-06",
+	})
+	newToken, err := rotated.GenerateAccessToken("user-1", "org-1", "user@example.com", "admin")
+	require.NoError(t, err)
+
+	parsed, _, err := jwt.NewParser().ParseUnverified(newToken, &JWTClaims{})
+	require.NoError(t, err)
+	assert.Equal(t, "RS256", parsed.Method.Alg())
+	assert.Equal(t, "2025-06", parsed.Header["kid"])
+
+	claims, err := rotated.ValidateToken(oldToken)
+	require.NoError(t, err, "token signed by an older active key must still verify")
+	assert.Equal(t, "user-1", claims.UserID)
+	_, err = rotated.ValidateToken(newToken)
+	require.NoError(t, err)
+
+	// Retirement: the old key is removed from the active set
+	retired := newTestJWTService(t, JWTConfig{
+		SigningKeys: []JWTSigningKey{{ID: "2025-06", PrivateKey: newKey}},
+	})
+	_, err = retired.ValidateToken(oldToken)
+	assert.ErrorIs(t, err, jwt.ErrTokenUnverifiable)
+	_, err = retired.ValidateToken(newToken)
+	assert.NoError(t, err)
+}
+
+func TestJWTService_RejectsKIDSignedWithWrongKey(t *testing.T) {
+	oldKey, newKey := testSigningKeys(t)
+	service := newTestJWTService(t, JWTConfig{
+		SigningKeys: []JWTSigningKey{{ID: "current", PrivateKey: oldKey}},
+	})
+
+	// Claims the active kid but is signed by a key the service does not know
+	claims := JWTClaims{UserID: "user-1", RegisteredClaims: service.registeredClaims(service.issuer, "user-1", time.Hour)}
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = "current"
+	forged, err := token.SignedString(newKey)
+	require.NoError(t, err)
+
+	_, err = service.ValidateToken(forged)
+	assert.ErrorIs(t, err, jwt.ErrTokenSignatureInvalid)
+}
+
+func TestJWTService_HS256TokensStillVerifyAfterEnablingSigningKeys(t *testing.T) {
+	key, _ := testSigningKeys(t)
+	legacy := newTestJWTService(t, JWTConfig{})
+	token, err := legacy.GenerateAccessToken("user-1", "org-1", "user@example.com", "admin")
+	require.NoError(t, err)
+
+	service := newTestJWTService(t, JWTConfig{SigningKeys: []JWTSigningKey{{ID: "k1", PrivateKey: key}}})
+	_, err = service.ValidateToken(token)
+	assert.NoError(t, err)
+}
+
+func TestJWTService_RejectHS256(t *testing.T) {
+	key, _ := testSigningKeys(t)
+	legacy := newTestJWTService(t, JWTConfig{})
+	hs256Token, err := legacy.GenerateAccessToken("user-1", "org-1", "user@example.com", "admin")
+	require.NoError(t, err)
+
+	service := newTestJWTService(t, JWTConfig{SigningKeys: []JWTSigningKey{{ID: "k1", PrivateKey: key}}, RejectHS256: true})
+	_, err = service.ValidateToken(hs256Token)
+	assert.Error(t, err)
+
+	rs256Token, err := service.GenerateAccessToken("user-1", "org-1", "user@example.com", "admin")
+	require.NoError(t, err)
+	_, err = service.ValidateToken(rs256Token)
+	assert.NoError(t, err)
+}
+
+func TestJWTService_JWKS(t *testing.T) {
+	oldKey, newKey := testSigningKeys(t)
+	service := newTestJWTService(t, JWTConfig{
+		SigningKeys:  []JWTSigningKey{{ID: "old", PrivateKey: oldKey}, {ID: "new", PrivateKey: newKey}},
+		SigningKeyID: "new",
+	})
+
+	set := service.JWKS()
+	require.Len(t, set.Keys, 2)
+	assert.Equal(t, "old", set.Keys[0].Kid)
+	assert.Equal(t, "new", set.Keys[1].Kid)
+	for i, key := range []*rsa.PrivateKey{oldKey, newKey} {
+		jwk := set.Keys[i]
+		assert.Equal(t, "RSA", jwk.Kty)
+		assert.Equal(t, "sig", jwk.Use)
+		assert.Equal(t, "RS256", jwk.Alg)
+		n, err := base64.RawURLEncoding.DecodeString(jwk.N)
+		require.NoError(t, err)
+		assert.Equal(t, 0, new(big.Int).SetBytes(n).Cmp(key.N))
+		assert.Equal(t, "AQAB", jwk.E) // 65537
+	}
+
+	assert.Empty(t, newTestJWTService(t, JWTConfig{}).JWKS().Keys)
+}
+
+func TestNewJWTServiceWithConfig_RejectsInvalidSigningKeys(t *testing.T) {
+	key, _ := testSigningKeys(t)
+	weak, err := rsa.GenerateKey(rand.Reader, 1024)
+	require.NoError(t, err)
+
+	for name, cfg := range map[string]JWTConfig{
+		"unknown signing key ID":    {SigningKeys: []JWTSigningKey{{ID: "a", PrivateKey: key}}, SigningKeyID: "b"},
+		"duplicate key ID":          {SigningKeys: []JWTSigningKey{{ID: "a", PrivateKey: key}, {ID: "a", PrivateKey: key}}},
+		"missing key ID":            {SigningKeys: []JWTSigningKey{{PrivateKey: key}}},
+		"weak key":                  {SigningKeys: []JWTSigningKey{{ID: "a", PrivateKey: weak}}},
+		"reject HS256 without keys": {RejectHS256: true},
+	} {
+		cfg.Secret, cfg.AccessTokenTTL, cfg.RefreshTokenTTL = testJWTSecret, time.Hour, time.Hour
+		_, err := NewJWTServiceWithConfig(cfg)
+		assert.Error(t, err, name)
+	}
+}
+
+func TestLoadJWTSigningKeys(t *testing.T) {
+	key, _ := testSigningKeys(t)
+	path := filepath.Join(t.TempDir(), "signing.pem")
+	pemBytes := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+	require.NoError(t, os.WriteFile(path, pemBytes, 0o600))
+
+	keys, err := LoadJWTSigningKeys(" k1=" + path + " ,")
+	require.NoError(t, err)
+	require.Len(t, keys, 1)
+	assert.Equal(t, "k1", keys[0].ID)
+	assert.True(t, keys[0].PrivateKey.Equal(key))
+
+	keys, err = LoadJWTSigningKeys("")
+	require.NoError(t, err)
+	assert.Empty(t, keys)
+
+	_, err = LoadJWTSigningKeys(path)
+	assert.Error(t, err)
+	_, err = LoadJWTSigningKeys("k1=" + filepath.Join(t.TempDir(), "missing.pem"))
+	assert.Error(t, err)
+}
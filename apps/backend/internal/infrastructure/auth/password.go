@@ -1,16 +1,19 @@
 package auth
 
 import (
+	_ "embed"
 	"errors"
 	"fmt"
 	"regexp"
+	"strings"
 
+	"github.com/opena2a/identity/backend/internal/domain"
 	"golang.org/x/crypto/bcrypt"
 )
 
 const (
-	// MinPasswordLength is the minimum required password length
-	MinPasswordLength = 8
+	// MinPasswordLength is the minimum required password length of the default policy
+	MinPasswordLength = domain.DefaultPasswordMinLength
 
 	// BcryptCost is the cost factor for bcrypt hashing
 	BcryptCost = 12
@@ -18,10 +21,13 @@ const (
 
 var (
 	// ErrPasswordTooShort indicates password doesn't meet minimum length
-	ErrPasswordTooShort = errors.New("password must be at least 8 characters long")
+	ErrPasswordTooShort = errors.New("password is too short")
 
 	// ErrPasswordTooWeak indicates password doesn't meet complexity requirements
-	ErrPasswordTooWeak = errors.New("password must contain uppercase, lowercase, number, and special character")
+	ErrPasswordTooWeak = errors.New("password does not meet complexity requirements")
+
+	// ErrPasswordTooCommon indicates password is on the common/breached password list
+	ErrPasswordTooCommon = errors.New("password is too common, please choose a less predictable password")
 
 	// ErrPasswordMismatch indicates passwords don't match
 	ErrPasswordMismatch = errors.New("passwords do not match")
@@ -30,12 +36,39 @@ var (
 	ErrInvalidPassword = errors.New("invalid password")
 )
 
+var (
+	upperPattern   = regexp.MustCompile(`[A-Z]`)
+	lowerPattern   = regexp.MustCompile(`[a-z]`)
+	digitPattern   = regexp.MustCompile(`[0-9]`)
+	specialPattern = regexp.MustCompile(`[!@#$%^&*()_+\-=\[\]{};':"\\|,.<>\/?]`)
+
+	//go:embed common_passwords.txt
+	commonPasswordList string
+	commonPasswords    = parseCommonPasswords(commonPasswordList)
+)
+
+// passwordPolicyError carries a message describing the violated rule while matching its sentinel error
+type passwordPolicyError struct {
+	err     error
+	message string
+}
+
+func (e *passwordPolicyError) Error() string { return e.message }
+func (e *passwordPolicyError) Unwrap() error { return e.err }
+
 // PasswordHasher provides password hashing and verification
-type PasswordHasher struct{}
+type PasswordHasher struct {
+	policy domain.PasswordPolicy
+}
 
-// NewPasswordHasher creates a new password hasher
+// NewPasswordHasher creates a new password hasher that enforces the default password policy
 func NewPasswordHasher() *PasswordHasher {
-	return &PasswordHasher{}
+	return NewPasswordHasherWithPolicy(domain.DefaultPasswordPolicy())
+}
+
+// NewPasswordHasherWithPolicy creates a password hasher that enforces an organization's password policy
+func NewPasswordHasherWithPolicy(policy domain.PasswordPolicy) *PasswordHasher {
+	return &PasswordHasher{policy: policy}
 }
 
 // HashPassword hashes a password using bcrypt
@@ -65,29 +98,69 @@ func (h *PasswordHasher) VerifyPassword(password, hash string) error {
 	return nil
 }
 
-// ValidatePassword checks if password meets security requirements
+// ValidatePassword checks if password meets the hasher's password policy
 func (h *PasswordHasher) ValidatePassword(password string) error {
+	policy := h.policy
+
 	// Check minimum length
-	if len(password) < MinPasswordLength {
-		return ErrPasswordTooShort
+	minLength := policy.MinLength
+	if minLength < MinPasswordLength {
+		minLength = MinPasswordLength
+	}
+	if len(password) < minLength {
+		return &passwordPolicyError{
+			err:     ErrPasswordTooShort,
+			message: fmt.Sprintf("password must be at least %d characters long", minLength),
+		}
 	}
 
-	// Check for uppercase letter
-	hasUpper := regexp.MustCompile(`[A-Z]`).MatchString(password)
-	// Check for lowercase letter
-	hasLower := regexp.MustCompile(`[a-z]`).MatchString(password)
-	// Check for digit
-	hasDigit := regexp.MustCompile(`[0-9]`).MatchString(password)
-	// Check for special character
-	hasSpecial := regexp.MustCompile(`[!@#$%^&*()_+\-=\[\]{};':"\\|,.<>\/?]`).MatchString(password)
-
-	if !hasUpper || !hasLower || !hasDigit || !hasSpecial {
-		return ErrPasswordTooWeak
+	// Check required character classes
+	var missing []string
+	if policy.RequireUppercase && !upperPattern.MatchString(password) {
+		missing = append(missing, "an uppercase letter")
+	}
+	if policy.RequireLowercase && !lowerPattern.MatchString(password) {
+		missing = append(missing, "a lowercase letter")
+	}
+	if policy.RequireNumber && !digitPattern.MatchString(password) {
+		missing = append(missing, "a number")
+	}
+	if policy.RequireSymbol && !specialPattern.MatchString(password) {
+		missing = append(missing, "a special character")
+	}
+	if len(missing) > 0 {
+		return &passwordPolicyError{
+			err:     ErrPasswordTooWeak,
+			message: "password must contain " + strings.Join(missing, ", "),
+		}
+	}
+
+	// Check against common/breached passwords
+	if policy.DisallowCommonPasswords && IsCommonPassword(password) {
+		return ErrPasswordTooCommon
 	}
 
 	return nil
 }
 
+// IsCommonPassword reports whether password is on the embedded common/breached password list
+func IsCommonPassword(password string) bool {
+	_, found := commonPasswords[strings.ToLower(password)]
+	return found
+}
+
+func parseCommonPasswords(list string) map[string]struct{} {
+	passwords := make(map[string]struct{})
+	for _, line := range strings.Split(list, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		passwords[strings.ToLower(line)] = struct{}{}
+	}
+	return passwords
+}
+
 // ComparePasswords checks if two passwords match (used for confirmation)
 func (h *PasswordHasher) ComparePasswords(password, confirmation string) error {
 	if password != confirmation {
@@ -0,0 +1,92 @@
+package auth
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/opena2a/identity/backend/internal/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+// permissivePolicy only enforces the minimum length so each rule can be toggled on its own
+func permissivePolicy() domain.PasswordPolicy {
+	return domain.PasswordPolicy{MinLength: domain.DefaultPasswordMinLength}
+}
+
+func TestValidatePassword_Rules(t *testing.T) {
+	tests := []struct {
+		name     string
+		enable   func(*domain.PasswordPolicy)
+		password string
+		wantErr  error
+	}{
+		{"min length off", nil, "abcdefghij", nil},
+		{"min length on", func(p *domain.PasswordPolicy) { p.MinLength = 12 }, "abcdefghij", ErrPasswordTooShort},
+		{"min length satisfied", func(p *domain.PasswordPolicy) { p.MinLength = 12 }, "abcdefghijkl", nil},
+		{"uppercase off", nil, "lowercase-only", nil},
+		{"uppercase on", func(p *domain.PasswordPolicy) { p.RequireUppercase = true }, "lowercase-only", ErrPasswordTooWeak},
+		{"uppercase satisfied", func(p *domain.PasswordPolicy) { p.RequireUppercase = true }, "Lowercase-only", nil},
+		{"lowercase off", nil, "UPPERCASE-ONLY", nil},
+		{"lowercase on", func(p *domain.PasswordPolicy) { p.RequireLowercase = true }, "UPPERCASE-ONLY", ErrPasswordTooWeak},
+		{"number off", nil, "no-digits-here", nil},
+		{"number on", func(p *domain.PasswordPolicy) { p.RequireNumber = true }, "no-digits-here", ErrPasswordTooWeak},
+		{"number satisfied", func(p *domain.PasswordPolicy) { p.RequireNumber = true }, "no-digits-here-9", nil},
+		{"symbol off", nil, "nosymbolshere1", nil},
+		{"symbol on", func(p *domain.PasswordPolicy) { p.RequireSymbol = true }, "nosymbolshere1", ErrPasswordTooWeak},
+		{"symbol satisfied", func(p *domain.PasswordPolicy) { p.RequireSymbol = true }, "nosymbolshere1!", nil},
+		{"common passwords off", nil, "password123", nil},
+		{"common passwords on", func(p *domain.PasswordPolicy) { p.DisallowCommonPasswords = true }, "password123", ErrPasswordTooCommon},
+		{"common passwords case-insensitive", func(p *domain.PasswordPolicy) { p.DisallowCommonPasswords = true }, "P@ssw0rd123!", ErrPasswordTooCommon},
+		{"uncommon password", func(p *domain.PasswordPolicy) { p.DisallowCommonPasswords = true }, "violet-harbor-tram", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			policy := permissivePolicy()
+			if tt.enable != nil {
+				tt.enable(&policy)
+			}
+
+			err := NewPasswordHasherWithPolicy(policy).ValidatePassword(tt.password)
+			if tt.wantErr == nil {
+				assert.NoError(t, err)
+			} else {
+				assert.True(t, errors.Is(err, tt.wantErr), "expected %v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestValidatePassword_DefaultPolicy(t *testing.T) {
+	hasher := NewPasswordHasher()
+
+	assert.NoError(t, hasher.ValidatePassword("SecurePass123!"))
+	assert.ErrorIs(t, hasher.ValidatePassword("Sh0rt!"), ErrPasswordTooShort)
+	assert.ErrorIs(t, hasher.ValidatePassword("NoSymbols123"), ErrPasswordTooWeak)
+	assert.ErrorIs(t, hasher.ValidatePassword("Password123!"), ErrPasswordTooCommon)
+}
+
+func TestValidatePassword_MessageNamesMissingRules(t *testing.T) {
+	policy := permissivePolicy()
+	policy.MinLength = 14
+	hasher := NewPasswordHasherWithPolicy(policy)
+	assert.EqualError(t, hasher.ValidatePassword("short"), "password must be at least 14 characters long")
+
+	policy.RequireNumber = true
+	policy.RequireSymbol = true
+	hasher = NewPasswordHasherWithPolicy(policy)
+	assert.EqualError(t, hasher.ValidatePassword("long-enough-password"), "password must contain a number")
+}
+
+func TestHashPassword_EnforcesPolicy(t *testing.T) {
+	policy := permissivePolicy()
+	policy.MinLength = 16
+
+	_, err := NewPasswordHasherWithPolicy(policy).HashPassword("SecurePass123!")
+	assert.ErrorIs(t, err, ErrPasswordTooShort)
+
+	// A relaxed policy accepts what the default policy would reject
+	hash, err := NewPasswordHasherWithPolicy(permissivePolicy()).HashPassword("plain-words-only")
+	assert.NoError(t, err)
+	assert.NoError(t, NewPasswordHasher().VerifyPassword("plain-words-only", hash))
+}
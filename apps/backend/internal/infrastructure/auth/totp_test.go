@@ -0,0 +1,69 @@
+package auth
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// rfc6238Secret is the SHA-1 test key from RFC 6238 Appendix B ("12345678901234567890") in base32
+const rfc6238Secret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func TestGenerateTOTPCode_RFC6238Vectors(t *testing.T) {
+	// The RFC lists 8-digit codes; a 6-digit code is the same value truncated to its last 6 digits
+	vectors := map[int64]string{
+		59:         "287082",
+		1111111109: "081804",
+		1111111111: "050471",
+		1234567890: "005924",
+		2000000000: "279037",
+	}
+	for unix, want := range vectors {
+		code, err := GenerateTOTPCode(rfc6238Secret, time.Unix(unix, 0))
+		require.NoError(t, err)
+		assert.Equal(t, want, code, "t=%d", unix)
+	}
+}
+
+func TestValidateTOTPCode(t *testing.T) {
+	now := time.Unix(1234567890, 0)
+	code, err := GenerateTOTPCode(rfc6238Secret, now)
+	require.NoError(t, err)
+
+	step, ok, err := ValidateTOTPCode(rfc6238Secret, code, now)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, TOTPStep(now), step)
+
+	// One period of clock drift either way is tolerated
+	_, ok, _ = ValidateTOTPCode(rfc6238Secret, code, now.Add(TOTPPeriod))
+	assert.True(t, ok)
+	_, ok, _ = ValidateTOTPCode(rfc6238Secret, code, now.Add(-TOTPPeriod))
+	assert.True(t, ok)
+
+	// Older codes and wrong codes are rejected
+	_, ok, _ = ValidateTOTPCode(rfc6238Secret, code, now.Add(3*TOTPPeriod))
+	assert.False(t, ok)
+	_, ok, _ = ValidateTOTPCode(rfc6238Secret, "000000", now)
+	assert.False(t, ok)
+	_, ok, _ = ValidateTOTPCode(rfc6238Secret, "12345", now)
+	assert.False(t, ok)
+
+	_, _, err = ValidateTOTPCode("not base32!", code, now)
+	assert.Error(t, err)
+}
+
+func TestTOTPProvisioningURI(t *testing.T) {
+	secret, err := GenerateTOTPSecret()
+	require.NoError(t, err)
+	assert.Len(t, secret, 32)
+
+	uri := TOTPProvisioningURI("Agent Identity Management", "user@example.com", secret)
+	assert.True(t, strings.HasPrefix(uri, "otpauth://totp/Agent%20Identity%20Management:user@example.com?"))
+	assert.Contains(t, uri, "secret="+secret)
+	assert.Contains(t, uri, "digits=6")
+	assert.Contains(t, uri, "period=30")
+}
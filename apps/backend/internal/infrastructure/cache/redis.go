@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"strconv"
 	"time"
 
 	"github.com/redis/go-redis/v9"
@@ -111,6 +112,20 @@ func (c *RedisCache) IncrementBy(ctx context.Context, key string, value int64) (
 	return c.client.IncrBy(ctx, key, value).Result()
 }
 
+// IncrementWithExpiry increments a counter, starting its TTL when the counter is created
+func (c *RedisCache) IncrementWithExpiry(ctx context.Context, key string, ttl time.Duration) (int64, error) {
+	count, err := c.Increment(ctx, key)
+	if err != nil {
+		return 0, err
+	}
+
+	if count == 1 {
+		c.client.Expire(ctx, key, ttl)
+	}
+
+	return count, nil
+}
+
 // SetWithNX sets a value only if it doesn't exist (for distributed locks)
 func (c *RedisCache) SetWithNX(ctx context.Context, key string, value interface{}, ttl time.Duration) (bool, error) {
 	data, err := json.Marshal(value)
@@ -160,6 +175,9 @@ const (
 	// Session cache
 	SessionPrefix = "session:"
 	SessionTTL    = 24 * time.Hour
+
+	// Idempotency keys
+	IdempotencyPrefix = "idempotency:"
 )
 
 // Helper functions for common cache operations
@@ -219,3 +237,76 @@ func (c *RedisCache) RateLimit(ctx context.Context, key string, limit int64, win
 
 	return count <= limit, nil
 }
+
+// tokenBucketScript atomically refills and takes one token from a bucket stored
+// as a hash {tokens, ts}. ARGV: capacity, window (ms), now (ms).
+// Returns {allowed (0/1), tokens left}. Tokens are returned as a string because
+// Redis truncates Lua numbers to integers.
+var tokenBucketScript = redis.NewScript(`
+local capacity = tonumber(ARGV[1])
+local window = tonumber(ARGV[2])
+local now = tonumber(ARGV[3])
+local rate = capacity / window
+
+local bucket = redis.call('HMGET', KEYS[1], 'tokens', 'ts')
+local tokens = tonumber(bucket[1]) or capacity
+local ts = tonumber(bucket[2]) or now
+tokens = math.min(capacity, tokens + math.max(0, now - ts) * rate)
+
+local allowed = 0
+if tokens >= 1 then
+	tokens = tokens - 1
+	allowed = 1
+end
+
+redis.call('HSET', KEYS[1], 'tokens', tostring(tokens), 'ts', now)
+redis.call('PEXPIRE', KEYS[1], window)
+return {allowed, tostring(tokens)}
+`)
+
+// TakeToken takes one token from the token bucket identified by key. The bucket
+// holds up to capacity tokens and refills completely over window. It returns
+// whether a token was taken and how many tokens are left in the bucket.
+func (c *RedisCache) TakeToken(ctx context.Context, key string, capacity int, window time.Duration, now time.Time) (bool, float64, error) {
+	result, err := tokenBucketScript.Run(ctx, c.client, []string{RateLimitPrefix + "bucket:" + key},
+		capacity, window.Milliseconds(), now.UnixMilli()).Slice()
+	if err != nil {
+		return false, 0, err
+	}
+	if len(result) != 2 {
+		return false, 0, fmt.Errorf("unexpected token bucket reply: %v", result)
+	}
+	allowed, _ := result[0].(int64)
+	tokensText, _ := result[1].(string)
+	tokens, err := strconv.ParseFloat(tokensText, 64)
+	if err != nil {
+		return false, 0, fmt.Errorf("invalid token bucket level %q: %w", tokensText, err)
+	}
+	return allowed == 1, tokens, nil
+}
+
+// ClaimIdempotencyKey stores record under key unless the key is already taken,
+// in which case it returns the record stored by the earlier request.
+func (c *RedisCache) ClaimIdempotencyKey(ctx context.Context, key string, record []byte, ttl time.Duration) ([]byte, bool, error) {
+	claimed, err := c.client.SetNX(ctx, IdempotencyPrefix+key, record, ttl).Result()
+	if err != nil || claimed {
+		return nil, claimed, err
+	}
+
+	existing, err := c.client.Get(ctx, IdempotencyPrefix+key).Bytes()
+	if err == redis.Nil {
+		// Expired between SETNX and GET - report it as taken so the caller retries
+		return nil, false, nil
+	}
+	return existing, false, err
+}
+
+// SaveIdempotencyRecord overwrites the record stored under key
+func (c *RedisCache) SaveIdempotencyRecord(ctx context.Context, key string, record []byte, ttl time.Duration) error {
+	return c.client.Set(ctx, IdempotencyPrefix+key, record, ttl).Err()
+}
+
+// ReleaseIdempotencyKey frees key so the request can be retried
+func (c *RedisCache) ReleaseIdempotencyKey(ctx context.Context, key string) error {
+	return c.client.Del(ctx, IdempotencyPrefix+key).Err()
+}
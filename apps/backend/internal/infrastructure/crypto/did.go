@@ -0,0 +1,258 @@
+package crypto
+
+import (
+	"context"
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/opena2a/identity/backend/internal/infrastructure/utils"
+)
+
+// Maximum size of a did:web DID document
+const maxDIDDocumentSize = 64 << 10
+
+// ed25519PubMulticodec is the multicodec prefix (0xed as a varint) of an Ed25519 public key
+var ed25519PubMulticodec = []byte{0xed, 0x01}
+
+var (
+	// ErrInvalidDID is returned for a malformed DID or one whose method is not supported
+	ErrInvalidDID = errors.New("invalid DID")
+	// ErrDIDKeyNotFound is returned when a DID document holds no usable Ed25519 key
+	ErrDIDKeyNotFound = errors.New("no Ed25519 verification key found in DID document")
+)
+
+// DIDVerifier resolves did:key and did:web DIDs to Ed25519 public keys and verifies signatures
+// made with them
+type DIDVerifier struct {
+	httpClient *http.Client
+}
+
+// NewDIDVerifier creates a DID verifier; httpClient fetches did:web documents and defaults, when
+// nil, to a client with a 10 second timeout that only connects to public addresses and does not
+// follow redirects
+func NewDIDVerifier(httpClient *http.Client) *DIDVerifier {
+	if httpClient == nil {
+		httpClient = utils.NewPublicHTTPClient(10 * time.Second)
+	}
+	return &DIDVerifier{httpClient: httpClient}
+}
+
+// Verify resolves did and checks that signature is its key's signature of message. It returns
+// the resolved public key, so callers can check it belongs to the expected identity.
+func (v *DIDVerifier) Verify(ctx context.Context, did string, message, signature []byte) (ed25519.PublicKey, error) {
+	publicKey, err := v.ResolvePublicKey(ctx, did)
+	if err != nil {
+		return nil, err
+	}
+	if !ed25519.Verify(publicKey, message, signature) {
+		return nil, fmt.Errorf("signature verification failed")
+	}
+	return publicKey, nil
+}
+
+// ResolvePublicKey returns the Ed25519 public key of did. A did:web DID may name one of its
+// document's verification methods with a fragment (e.g. "did:web:example.com#key-1"); without
+// one, the first Ed25519 verification method is used.
+func (v *DIDVerifier) ResolvePublicKey(ctx context.Context, did string) (ed25519.PublicKey, error) {
+	did, fragment, _ := strings.Cut(did, "#")
+
+	switch {
+	case strings.HasPrefix(did, "did:key:"):
+		return parseDIDKey(strings.TrimPrefix(did, "did:key:"))
+	case strings.HasPrefix(did, "did:web:"):
+		documentURL, err := didWebDocumentURL(did)
+		if err != nil {
+			return nil, err
+		}
+		document, err := v.fetchDIDDocument(ctx, documentURL)
+		if err != nil {
+			return nil, err
+		}
+		if document.ID != did {
+			return nil, fmt.Errorf("DID document id %q does not match %s", document.ID, did)
+		}
+		return document.publicKey(fragment)
+	default:
+		return nil, fmt.Errorf("%w: %q is not a did:key or did:web DID", ErrInvalidDID, did)
+	}
+}
+
+// parseDIDKey decodes the method-specific id of a did:key: a base58btc multibase ("z") value
+// holding a multicodec-prefixed Ed25519 public key
+func parseDIDKey(id string) (ed25519.PublicKey, error) {
+	publicKey, err := decodeEd25519Multibase(id)
+	if err != nil {
+		return nil, fmt.Errorf("%w: did:key: %v", ErrInvalidDID, err)
+	}
+	return publicKey, nil
+}
+
+func decodeEd25519Multibase(value string) (ed25519.PublicKey, error) {
+	if !strings.HasPrefix(value, "z") {
+		return nil, fmt.Errorf("only base58btc (z) multibase keys are supported")
+	}
+	decoded, err := decodeBase58(value[1:])
+	if err != nil {
+		return nil, err
+	}
+	if len(decoded) != len(ed25519PubMulticodec)+ed25519.PublicKeySize ||
+		decoded[0] != ed25519PubMulticodec[0] || decoded[1] != ed25519PubMulticodec[1] {
+		return nil, fmt.Errorf("not a multicodec Ed25519 public key")
+	}
+	return ed25519.PublicKey(decoded[len(ed25519PubMulticodec):]), nil
+}
+
+// didWebDocumentURL maps a did:web DID to the HTTPS URL of its DID document:
+// "did:web:example.com" to https://example.com/.well-known/did.json and
+// "did:web:example.com:agents:a1" to https://example.com/agents/a1/did.json
+func didWebDocumentURL(did string) (string, error) {
+	segments := strings.Split(strings.TrimPrefix(did, "did:web:"), ":")
+	for i, segment := range segments {
+		decoded, err := url.PathUnescape(segment)
+		if err != nil || decoded == "" || strings.Contains(decoded, "/") {
+			return "", fmt.Errorf("%w: malformed did:web segment %q", ErrInvalidDID, segment)
+		}
+		segments[i] = decoded
+	}
+
+	path := "/.well-known"
+	if len(segments) > 1 {
+		path = "/" + strings.Join(segments[1:], "/")
+	}
+	return (&url.URL{Scheme: "https", Host: segments[0], Path: path + "/did.json"}).String(), nil
+}
+
+// didDocument is the part of a DID document needed to find a verification key
+type didDocument struct {
+	ID                 string                  `json:"id"`
+	VerificationMethod []didVerificationMethod `json:"verificationMethod"`
+}
+
+type didVerificationMethod struct {
+	ID                 string `json:"id"`
+	Type               string `json:"type"`
+	PublicKeyMultibase string `json:"publicKeyMultibase"`
+	PublicKeyBase58    string `json:"publicKeyBase58"`
+	PublicKeyJWK       *struct {
+		Kty string `json:"kty"`
+		Crv string `json:"crv"`
+		X   string `json:"x"`
+	} `json:"publicKeyJwk"`
+}
+
+func (v *DIDVerifier) fetchDIDDocument(ctx context.Context, documentURL string) (*didDocument, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, documentURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create DID document request: %w", err)
+	}
+	req.Header.Set("Accept", "application/did+json, application/json")
+
+	resp, err := v.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch DID document: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch DID document: %s returned status %d", documentURL, resp.StatusCode)
+	}
+
+	var document didDocument
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxDIDDocumentSize)).Decode(&document); err != nil {
+		return nil, fmt.Errorf("failed to decode DID document: %w", err)
+	}
+	return &document, nil
+}
+
+// publicKey returns the key of the verification method with the given fragment, or of the first
+// method holding an Ed25519 key when fragment is empty
+func (d *didDocument) publicKey(fragment string) (ed25519.PublicKey, error) {
+	for _, method := range d.VerificationMethod {
+		if fragment != "" {
+			if method.ID != "#"+fragment && method.ID != d.ID+"#"+fragment {
+				continue
+			}
+			publicKey, err := method.ed25519PublicKey()
+			if err != nil {
+				return nil, fmt.Errorf("verification method #%s: %w", fragment, err)
+			}
+			return publicKey, nil
+		}
+		if publicKey, err := method.ed25519PublicKey(); err == nil {
+			return publicKey, nil
+		}
+	}
+	if fragment != "" {
+		return nil, fmt.Errorf("%w: no verification method #%s", ErrDIDKeyNotFound, fragment)
+	}
+	return nil, ErrDIDKeyNotFound
+}
+
+// ed25519PublicKey decodes the method's key from whichever representation it uses
+func (m *didVerificationMethod) ed25519PublicKey() (ed25519.PublicKey, error) {
+	switch {
+	case m.PublicKeyMultibase != "":
+		return decodeEd25519Multibase(m.PublicKeyMultibase)
+	case m.PublicKeyJWK != nil:
+		if m.PublicKeyJWK.Kty != "OKP" || m.PublicKeyJWK.Crv != "Ed25519" {
+			return nil, fmt.Errorf("JWK is %s/%s, not OKP/Ed25519", m.PublicKeyJWK.Kty, m.PublicKeyJWK.Crv)
+		}
+		decoded, err := base64.RawURLEncoding.DecodeString(m.PublicKeyJWK.X)
+		if err != nil {
+			return nil, fmt.Errorf("invalid JWK x: %w", err)
+		}
+		return checkEd25519PublicKeySize(decoded)
+	case m.PublicKeyBase58 != "":
+		decoded, err := decodeBase58(m.PublicKeyBase58)
+		if err != nil {
+			return nil, err
+		}
+		return checkEd25519PublicKeySize(decoded)
+	default:
+		return nil, fmt.Errorf("verification method %s has no public key", m.ID)
+	}
+}
+
+func checkEd25519PublicKeySize(key []byte) (ed25519.PublicKey, error) {
+	if len(key) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("invalid public key size: expected %d, got %d", ed25519.PublicKeySize, len(key))
+	}
+	return ed25519.PublicKey(key), nil
+}
+
+const base58Alphabet = "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz"
+
+// decodeBase58 decodes a Bitcoin-alphabet base58 string
+func decodeBase58(value string) ([]byte, error) {
+	// Each leading '1' encodes a leading zero byte
+	zeros := 0
+	for zeros < len(value) && value[zeros] == '1' {
+		zeros++
+	}
+
+	// Big-endian base-256 accumulator, multiplied by 58 and added to digit by digit
+	var decoded []byte
+	for i := zeros; i < len(value); i++ {
+		carry := strings.IndexByte(base58Alphabet, value[i])
+		if carry < 0 {
+			return nil, fmt.Errorf("invalid base58 character %q", value[i])
+		}
+		for j := len(decoded) - 1; j >= 0; j-- {
+			carry += int(decoded[j]) * 58
+			decoded[j] = byte(carry)
+			carry >>= 8
+		}
+		for ; carry > 0; carry >>= 8 {
+			decoded = append([]byte{byte(carry)}, decoded...)
+		}
+	}
+	return append(make([]byte, zeros), decoded...), nil
+}
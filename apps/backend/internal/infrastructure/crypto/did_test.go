@@ -0,0 +1,204 @@
+package crypto
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/opena2a/identity/backend/internal/infrastructure/utils"
+)
+
+// The did:key fixture: an Ed25519 key pair derived from a fixed seed, and its DID
+var (
+	didKeyFixturePrivateKey = ed25519.NewKeyFromSeed(bytes.Repeat([]byte{7}, ed25519.SeedSize))
+	didKeyFixture           = "did:key:" + encodeEd25519Multibase(didKeyFixturePrivateKey.Public().(ed25519.PublicKey))
+)
+
+// encodeEd25519Multibase is the inverse of decodeEd25519Multibase
+func encodeEd25519Multibase(publicKey ed25519.PublicKey) string {
+	input := append(append([]byte{}, ed25519PubMulticodec...), publicKey...)
+	var digits []byte // base58 digits, least significant first
+	for _, b := range input {
+		carry := int(b)
+		for i := range digits {
+			carry += int(digits[i]) << 8
+			digits[i] = byte(carry % 58)
+			carry /= 58
+		}
+		for ; carry > 0; carry /= 58 {
+			digits = append(digits, byte(carry%58))
+		}
+	}
+	var encoded strings.Builder
+	encoded.WriteByte('z')
+	for _, b := range input {
+		if b != 0 {
+			break
+		}
+		encoded.WriteByte('1')
+	}
+	for i := len(digits) - 1; i >= 0; i-- {
+		encoded.WriteByte(base58Alphabet[digits[i]])
+	}
+	return encoded.String()
+}
+
+func TestDIDVerifier_DIDKey(t *testing.T) {
+	verifier := NewDIDVerifier(nil)
+	challenge := []byte(`{"action_type": "file:read", "timestamp": "2026-01-01T00:00:00Z"}`)
+	signature := ed25519.Sign(didKeyFixturePrivateKey, challenge)
+
+	t.Run("verifies a valid signature", func(t *testing.T) {
+		publicKey, err := verifier.Verify(context.Background(), didKeyFixture, challenge, signature)
+		if err != nil {
+			t.Fatalf("Verify() error = %v", err)
+		}
+		if !publicKey.Equal(didKeyFixturePrivateKey.Public()) {
+			t.Error("Verify() returned a different public key than the DID encodes")
+		}
+	})
+
+	t.Run("rejects a signature over a different challenge", func(t *testing.T) {
+		if _, err := verifier.Verify(context.Background(), didKeyFixture, []byte("tampered"), signature); err == nil {
+			t.Error("Verify() accepted a signature over a different challenge")
+		}
+	})
+
+	t.Run("rejects a signature by another key", func(t *testing.T) {
+		_, otherKey, _ := ed25519.GenerateKey(nil)
+		if _, err := verifier.Verify(context.Background(), didKeyFixture, challenge, ed25519.Sign(otherKey, challenge)); err == nil {
+			t.Error("Verify() accepted a signature by another key")
+		}
+	})
+}
+
+func TestDIDVerifier_ResolvePublicKey_DIDKeySpecVector(t *testing.T) {
+	// Test vector from the did:key specification
+	publicKey, err := NewDIDVerifier(nil).ResolvePublicKey(context.Background(), "did:key:z6MkiTBz1ymuepAQ4HEHYSF1H8quG5GLVVQR3djdX3mDooWp")
+	if err != nil {
+		t.Fatalf("ResolvePublicKey() error = %v", err)
+	}
+	expected, _ := decodeBase58("4zvwRjXUKGfvwnParsHAS3HuSVzV5cA4McphgmoCtajS")
+	if !bytes.Equal(publicKey, expected) {
+		t.Errorf("ResolvePublicKey() = %x, want %x", []byte(publicKey), expected)
+	}
+}
+
+func TestDIDVerifier_ResolvePublicKey_InvalidDIDs(t *testing.T) {
+	verifier := NewDIDVerifier(nil)
+	for _, did := range []string{
+		"did:example:123",
+		"did:key:",
+		"did:key:6MkiTBz1ymuepAQ4HEHYSF1H8quG5GLVVQR3djdX3mDooWp", // not multibase
+		"did:key:z0OIl", // not base58
+		"did:key:zDnaerDaTF5BXEavCrfRZEk316dpbLsfPDZ3WJ5hRTPFU2169", // P-256 key
+		"did:web:",
+	} {
+		if _, err := verifier.ResolvePublicKey(context.Background(), did); !errors.Is(err, ErrInvalidDID) {
+			t.Errorf("ResolvePublicKey(%q) error = %v, want ErrInvalidDID", did, err)
+		}
+	}
+}
+
+func TestDIDVerifier_DIDWeb(t *testing.T) {
+	publicKey := didKeyFixturePrivateKey.Public().(ed25519.PublicKey)
+	var document map[string]interface{}
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/agents/a1/did.json" {
+			http.NotFound(w, r)
+			return
+		}
+		json.NewEncoder(w).Encode(document)
+	}))
+	defer server.Close()
+
+	did := "did:web:" + strings.Replace(strings.TrimPrefix(server.URL, "https://"), ":", "%3A", 1) + ":agents:a1"
+	verifier := NewDIDVerifier(server.Client())
+	challenge := []byte("challenge")
+	signature := ed25519.Sign(didKeyFixturePrivateKey, challenge)
+
+	t.Run("verifies with the first Ed25519 key", func(t *testing.T) {
+		document = map[string]interface{}{
+			"id": did,
+			"verificationMethod": []map[string]interface{}{
+				{"id": did + "#p256", "type": "JsonWebKey2020", "publicKeyJwk": map[string]string{"kty": "EC", "crv": "P-256", "x": "AA"}},
+				{"id": did + "#key-1", "type": "Ed25519VerificationKey2020", "publicKeyMultibase": strings.TrimPrefix(didKeyFixture, "did:key:")},
+			},
+		}
+		if _, err := verifier.Verify(context.Background(), did, challenge, signature); err != nil {
+			t.Errorf("Verify() error = %v", err)
+		}
+	})
+
+	t.Run("selects a verification method by fragment", func(t *testing.T) {
+		_, otherKey, _ := ed25519.GenerateKey(nil)
+		document = map[string]interface{}{
+			"id": did,
+			"verificationMethod": []map[string]interface{}{
+				{"id": "#other", "type": "JsonWebKey2020", "publicKeyJwk": map[string]string{"kty": "OKP", "crv": "Ed25519", "x": base64.RawURLEncoding.EncodeToString(otherKey.Public().(ed25519.PublicKey))}},
+				{"id": "#jwk", "type": "JsonWebKey2020", "publicKeyJwk": map[string]string{"kty": "OKP", "crv": "Ed25519", "x": base64.RawURLEncoding.EncodeToString(publicKey)}},
+			},
+		}
+		if _, err := verifier.Verify(context.Background(), did+"#jwk", challenge, signature); err != nil {
+			t.Errorf("Verify(#jwk) error = %v", err)
+		}
+		if _, err := verifier.Verify(context.Background(), did+"#other", challenge, signature); err == nil {
+			t.Error("Verify(#other) accepted a signature by a different key")
+		}
+		if _, err := verifier.Verify(context.Background(), did+"#missing", challenge, signature); !errors.Is(err, ErrDIDKeyNotFound) {
+			t.Errorf("Verify(#missing) error = %v, want ErrDIDKeyNotFound", err)
+		}
+	})
+
+	t.Run("rejects a document for another DID", func(t *testing.T) {
+		document = map[string]interface{}{
+			"id": "did:web:attacker.example",
+			"verificationMethod": []map[string]interface{}{
+				{"id": "#key-1", "type": "Ed25519VerificationKey2020", "publicKeyMultibase": strings.TrimPrefix(didKeyFixture, "did:key:")},
+			},
+		}
+		if _, err := verifier.Verify(context.Background(), did, challenge, signature); err == nil {
+			t.Error("Verify() accepted a DID document with a different id")
+		}
+	})
+
+	t.Run("fails when the document is missing", func(t *testing.T) {
+		missing := "did:web:" + strings.Replace(strings.TrimPrefix(server.URL, "https://"), ":", "%3A", 1)
+		if _, err := verifier.ResolvePublicKey(context.Background(), missing); err == nil {
+			t.Error("ResolvePublicKey() succeeded without a DID document")
+		}
+	})
+}
+
+func TestDIDVerifier_DIDWebRefusesInternalHosts(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("the default verifier fetched a DID document from a loopback address")
+	}))
+	defer server.Close()
+
+	did := "did:web:" + strings.Replace(strings.TrimPrefix(server.URL, "https://"), ":", "%3A", 1)
+	if _, err := NewDIDVerifier(nil).ResolvePublicKey(context.Background(), did); !errors.Is(err, utils.ErrNonPublicAddress) {
+		t.Errorf("ResolvePublicKey(%q) error = %v, want ErrNonPublicAddress", did, err)
+	}
+}
+
+func TestDIDWebDocumentURL(t *testing.T) {
+	tests := map[string]string{
+		"did:web:example.com":                "https://example.com/.well-known/did.json",
+		"did:web:example.com%3A8443":         "https://example.com:8443/.well-known/did.json",
+		"did:web:example.com:agents:billing": "https://example.com/agents/billing/did.json",
+	}
+	for did, want := range tests {
+		got, err := didWebDocumentURL(did)
+		if err != nil || got != want {
+			t.Errorf("didWebDocumentURL(%q) = %q, %v; want %q", did, got, err, want)
+		}
+	}
+}
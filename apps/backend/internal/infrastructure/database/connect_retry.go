@@ -0,0 +1,47 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+// maxConnectRetryInterval caps the exponential backoff between connection attempts
+const maxConnectRetryInterval = 30 * time.Second
+
+// Pinger is the part of *sql.DB that PingWithRetry needs
+type Pinger interface {
+	PingContext(ctx context.Context) error
+}
+
+// PingWithRetry pings db until it answers, up to attempts times. It waits interval after the
+// first failure and doubles the wait after every further one, capped at 30s, so a server
+// started alongside Postgres waits for it instead of exiting. Every failed attempt is logged.
+func PingWithRetry(ctx context.Context, db Pinger, attempts int, interval time.Duration) error {
+	if attempts < 1 {
+		attempts = 1
+	}
+
+	wait := interval
+	var err error
+	for attempt := 1; attempt <= attempts; attempt++ {
+		if err = db.PingContext(ctx); err == nil {
+			return nil
+		}
+		if attempt == attempts {
+			break
+		}
+
+		slog.Warn("database not ready, retrying",
+			"attempt", attempt, "attempts", attempts, "retry_in", wait, "error", err)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(wait):
+		}
+		wait = min(wait*2, maxConnectRetryInterval)
+	}
+
+	return fmt.Errorf("database not reachable after %d attempts: %w", attempts, err)
+}
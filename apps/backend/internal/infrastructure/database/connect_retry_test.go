@@ -0,0 +1,65 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// flakyPinger fails the first `failures` pings, as Postgres does while it is still starting
+type flakyPinger struct {
+	failures int
+	pings    int
+}
+
+func (p *flakyPinger) PingContext(ctx context.Context) error {
+	p.pings++
+	if p.pings <= p.failures {
+		return errors.New("connection refused")
+	}
+	return nil
+}
+
+func TestPingWithRetry_SucceedsOnceDatabaseIsUp(t *testing.T) {
+	pinger := &flakyPinger{failures: 3}
+
+	err := PingWithRetry(context.Background(), pinger, 5, time.Millisecond)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 4, pinger.pings)
+}
+
+func TestPingWithRetry_GivesUpAfterAttempts(t *testing.T) {
+	pinger := &flakyPinger{failures: 10}
+
+	err := PingWithRetry(context.Background(), pinger, 3, time.Millisecond)
+
+	assert.ErrorContains(t, err, "after 3 attempts")
+	assert.ErrorContains(t, err, "connection refused")
+	assert.Equal(t, 3, pinger.pings)
+}
+
+func TestPingWithRetry_BacksOffExponentially(t *testing.T) {
+	pinger := &flakyPinger{failures: 3}
+
+	start := time.Now()
+	err := PingWithRetry(context.Background(), pinger, 4, 10*time.Millisecond)
+
+	// Waits of 10ms, 20ms and 40ms between the four attempts
+	assert.NoError(t, err)
+	assert.GreaterOrEqual(t, time.Since(start), 70*time.Millisecond)
+}
+
+func TestPingWithRetry_StopsWhenContextIsDone(t *testing.T) {
+	pinger := &flakyPinger{failures: 10}
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	err := PingWithRetry(ctx, pinger, 10, time.Hour)
+
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Equal(t, 1, pinger.pings)
+}
@@ -0,0 +1,90 @@
+package database
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ErrMigrationChecksumMismatch is returned when an applied migration file was edited after it ran
+var ErrMigrationChecksumMismatch = errors.New("applied migration files were modified")
+
+// MigrationChecksum returns the hex-encoded SHA-256 of a migration file's content
+func MigrationChecksum(content []byte) string {
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
+
+// EnsureMigrationChecksumColumn adds the checksum column to schema_migrations. Rows applied before
+// checksums were tracked keep a NULL checksum until VerifyMigrationChecksums backfills them.
+func EnsureMigrationChecksumColumn(ctx context.Context, db *sql.DB) error {
+	_, err := db.ExecContext(ctx, `ALTER TABLE schema_migrations ADD COLUMN IF NOT EXISTS checksum VARCHAR(64)`)
+	return err
+}
+
+// CompareMigrationChecksums compares the recorded checksum of every applied migration with the
+// checksum of its file on disk, without changing anything. current maps a version, as recorded in
+// schema_migrations, to the checksum of its file. It returns the versions whose file changed and
+// the versions applied before checksums were tracked. Applied migrations whose file no longer
+// exists are not checked.
+func CompareMigrationChecksums(ctx context.Context, db *sql.DB, current map[string]string) (modified, unrecorded []string, err error) {
+	rows, err := db.QueryContext(ctx, `SELECT version, checksum FROM schema_migrations`)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read migration checksums: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var version string
+		var recorded sql.NullString
+		if err := rows.Scan(&version, &recorded); err != nil {
+			return nil, nil, err
+		}
+
+		checksum, onDisk := current[version]
+		switch {
+		case !onDisk:
+			continue
+		case !recorded.Valid:
+			unrecorded = append(unrecorded, version)
+		case recorded.String != checksum:
+			modified = append(modified, version)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	sort.Strings(modified)
+	return modified, unrecorded, nil
+}
+
+// VerifyMigrationChecksums fails with ErrMigrationChecksumMismatch, naming every changed file, if
+// an applied migration was edited (see CompareMigrationChecksums). Applied migrations without a
+// recorded checksum are backfilled with the current one.
+func VerifyMigrationChecksums(ctx context.Context, db *sql.DB, current map[string]string) error {
+	modified, unrecorded, err := CompareMigrationChecksums(ctx, db, current)
+	if err != nil {
+		return err
+	}
+	if len(modified) > 0 {
+		return fmt.Errorf("%w: %s (restore the original files and add a new migration instead)",
+			ErrMigrationChecksumMismatch, strings.Join(modified, ", "))
+	}
+
+	for _, version := range unrecorded {
+		if _, err := db.ExecContext(ctx,
+			`UPDATE schema_migrations SET checksum = $1 WHERE version = $2 AND checksum IS NULL`,
+			current[version], version,
+		); err != nil {
+			return fmt.Errorf("failed to record checksum for %s: %w", version, err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,56 @@
+package database
+
+import (
+	"context"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVerifyMigrationChecksums_DetectsModifiedFile(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	original := MigrationChecksum([]byte("CREATE TABLE widgets (id INT);"))
+	edited := MigrationChecksum([]byte("CREATE TABLE widgets (id BIGINT);"))
+
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT version, checksum FROM schema_migrations")).
+		WillReturnRows(sqlmock.NewRows([]string{"version", "checksum"}).
+			AddRow("001_create_widgets.sql", original).
+			AddRow("002_add_color.sql", nil))
+
+	err = VerifyMigrationChecksums(context.Background(), db, map[string]string{
+		"001_create_widgets.sql": edited,
+		"002_add_color.sql":      MigrationChecksum([]byte("ALTER TABLE widgets ADD COLUMN color TEXT;")),
+	})
+	assert.ErrorIs(t, err, ErrMigrationChecksumMismatch)
+	assert.ErrorContains(t, err, "001_create_widgets.sql")
+	// Nothing is backfilled when the check fails
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestVerifyMigrationChecksums_BackfillsMissingChecksums(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	checksum := MigrationChecksum([]byte("CREATE TABLE widgets (id INT);"))
+
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT version, checksum FROM schema_migrations")).
+		WillReturnRows(sqlmock.NewRows([]string{"version", "checksum"}).
+			AddRow("001_create_widgets.sql", nil).
+			AddRow("000_removed_long_ago.sql", "deadbeef"))
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE schema_migrations SET checksum = $1")).
+		WithArgs(checksum, "001_create_widgets.sql").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	err = VerifyMigrationChecksums(context.Background(), db, map[string]string{
+		"001_create_widgets.sql": checksum,
+	})
+	require.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
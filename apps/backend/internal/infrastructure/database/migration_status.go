@@ -0,0 +1,66 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// MigrationState reports whether the schema matches the migration files shipped with the build
+type MigrationState string
+
+const (
+	MigrationsUpToDate MigrationState = "up_to_date"
+	MigrationsPending  MigrationState = "pending"
+)
+
+// MigrationStatus compares the applied migrations with the migration files on disk
+type MigrationStatus struct {
+	State           MigrationState `json:"state"`
+	LatestApplied   string         `json:"latestApplied"`
+	LatestAvailable string         `json:"latestAvailable"`
+	Pending         []string       `json:"pending,omitempty"`
+}
+
+// CheckMigrationStatus reports which of files, the forward migration filenames, are not yet
+// recorded in schema_migrations. The server records a migration under its filename and
+// cmd/migrate under the filename without its .sql/.up.sql extension; either counts as applied.
+func CheckMigrationStatus(ctx context.Context, db *sql.DB, files []string) (*MigrationStatus, error) {
+	rows, err := db.QueryContext(ctx, `SELECT version FROM schema_migrations`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read applied migrations: %w", err)
+	}
+	defer rows.Close()
+
+	applied := make(map[string]bool)
+	for rows.Next() {
+		var version string
+		if err := rows.Scan(&version); err != nil {
+			return nil, err
+		}
+		applied[version] = true
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	sorted := append([]string(nil), files...)
+	sort.Strings(sorted)
+
+	status := &MigrationStatus{State: MigrationsUpToDate}
+	for _, file := range sorted {
+		status.LatestAvailable = file
+		if applied[file] || applied[strings.TrimSuffix(strings.TrimSuffix(file, ".sql"), ".up")] {
+			status.LatestApplied = file
+			continue
+		}
+		status.Pending = append(status.Pending, file)
+	}
+	if len(status.Pending) > 0 {
+		status.State = MigrationsPending
+	}
+
+	return status, nil
+}
@@ -0,0 +1,63 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testMigrationFiles = []string{"002_add_tags.sql", "001_initial_schema.sql", "003_add_webhooks.up.sql"}
+
+func TestCheckMigrationStatus_UpToDate(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	// The server records filenames, cmd/migrate records names without the extension
+	mock.ExpectQuery("SELECT version FROM schema_migrations").
+		WillReturnRows(sqlmock.NewRows([]string{"version"}).
+			AddRow("001_initial_schema.sql").
+			AddRow("002_add_tags").
+			AddRow("003_add_webhooks"))
+
+	status, err := CheckMigrationStatus(context.Background(), db, testMigrationFiles)
+	require.NoError(t, err)
+	assert.Equal(t, MigrationsUpToDate, status.State)
+	assert.Equal(t, "003_add_webhooks.up.sql", status.LatestApplied)
+	assert.Equal(t, "003_add_webhooks.up.sql", status.LatestAvailable)
+	assert.Empty(t, status.Pending)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCheckMigrationStatus_Pending(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	mock.ExpectQuery("SELECT version FROM schema_migrations").
+		WillReturnRows(sqlmock.NewRows([]string{"version"}).AddRow("001_initial_schema.sql"))
+
+	status, err := CheckMigrationStatus(context.Background(), db, testMigrationFiles)
+	require.NoError(t, err)
+	assert.Equal(t, MigrationsPending, status.State)
+	assert.Equal(t, "001_initial_schema.sql", status.LatestApplied)
+	assert.Equal(t, "003_add_webhooks.up.sql", status.LatestAvailable)
+	assert.Equal(t, []string{"002_add_tags.sql", "003_add_webhooks.up.sql"}, status.Pending)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCheckMigrationStatus_QueryError(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	mock.ExpectQuery("SELECT version FROM schema_migrations").
+		WillReturnError(errors.New(`relation "schema_migrations" does not exist`))
+
+	_, err = CheckMigrationStatus(context.Background(), db, testMigrationFiles)
+	assert.ErrorContains(t, err, "failed to read applied migrations")
+}
@@ -1,25 +1,71 @@
 package email
 
 import (
+	"bytes"
 	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
 	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/opena2a/identity/backend/internal/domain"
 )
 
-// AWSSESProvider implements email sending via AWS SES
+// sesSigningService is the service name SES v2 requests are signed for
+const sesSigningService = "ses"
+
+// AWSSESProvider implements email sending via the AWS SES v2 API
 type AWSSESProvider struct {
 	region          string
 	accessKeyID     string
 	secretAccessKey string
+	sessionToken    string
 	from            string
+	endpoint        string
+	httpClient      *http.Client
+	now             func() time.Time
+}
+
+// sesSendEmailRequest is the request payload for POST /v2/email/outbound-emails
+type sesSendEmailRequest struct {
+	FromEmailAddress string         `json:"FromEmailAddress"`
+	Destination      sesDestination `json:"Destination"`
+	ReplyToAddresses []string       `json:"ReplyToAddresses,omitempty"`
+	Content          sesContent     `json:"Content"`
+}
+
+// sesDestination holds the recipients of an SES message
+type sesDestination struct {
+	ToAddresses  []string `json:"ToAddresses,omitempty"`
+	CcAddresses  []string `json:"CcAddresses,omitempty"`
+	BccAddresses []string `json:"BccAddresses,omitempty"`
+}
+
+// sesContent carries the complete MIME message, so headers and alternatives match SMTP delivery
+type sesContent struct {
+	Raw struct {
+		Data string `json:"Data"`
+	} `json:"Raw"`
 }
 
 // NewAWSSESProvider creates a new AWS SES email provider
-func NewAWSSESProvider(config EmailConfig) (*AWSSESProvider, error) {
+func NewAWSSESProvider(config domain.EmailConfig) (*AWSSESProvider, error) {
 	provider := &AWSSESProvider{
-		region:          config.AWSRegion,
-		accessKeyID:     config.AWSAccessKeyID,
-		secretAccessKey: config.AWSSecretAccessKey,
-		from:            fmt.Sprintf("%s <%s>", config.FromName, config.FromAddress),
+		region:          config.SES.Region,
+		accessKeyID:     config.SES.AccessKeyID,
+		secretAccessKey: config.SES.SecretAccessKey,
+		sessionToken:    config.SES.SessionToken,
+		from:            formatAddress(config.FromName, config.FromAddress),
+		endpoint:        fmt.Sprintf("https://email.%s.amazonaws.com", config.SES.Region),
+		httpClient:      &http.Client{Timeout: 30 * time.Second},
+		now:             time.Now,
 	}
 
 	if err := provider.ValidateConfig(); err != nil {
@@ -51,16 +97,175 @@ func (p *AWSSESProvider) GetProviderName() string {
 	return "AWS SES"
 }
 
-// SendEmail sends an email via AWS SES
-func (p *AWSSESProvider) SendEmail(ctx context.Context, params EmailParams) error {
-	// TODO: Implement AWS SES integration
-	// See: https://docs.aws.amazon.com/sdk-for-go/v1/developer-guide/ses-example-send-email.html
-	//
-	// Implementation steps:
-	// 1. Import AWS SDK: github.com/aws/aws-sdk-go/service/ses
-	// 2. Create SES client with credentials
-	// 3. Build SendEmailInput with params
-	// 4. Call client.SendEmail()
-
-	return fmt.Errorf("AWS SES provider not yet implemented - contributions welcome!")
+// ValidateConnection checks that the credentials are accepted and sending is enabled for the account
+func (p *AWSSESProvider) ValidateConnection(ctx context.Context) error {
+	resp, err := p.do(ctx, http.MethodGet, "/v2/email/account", nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return sesError(resp)
+	}
+
+	var account struct {
+		SendingEnabled bool `json:"SendingEnabled"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&account); err != nil {
+		return fmt.Errorf("failed to decode SES account: %w", err)
+	}
+
+	if !account.SendingEnabled {
+		return fmt.Errorf("sending is disabled for the SES account in %s", p.region)
+	}
+	return nil
+}
+
+// Send sends an email via AWS SES
+func (p *AWSSESProvider) Send(ctx context.Context, params EmailParams) error {
+	if params.From == "" {
+		params.From = p.from
+	}
+	if len(params.To)+len(params.CC)+len(params.BCC) == 0 {
+		return fmt.Errorf("at least one recipient is required")
+	}
+
+	request := sesSendEmailRequest{
+		FromEmailAddress: params.From,
+		Destination: sesDestination{
+			ToAddresses:  params.To,
+			CcAddresses:  params.CC,
+			BccAddresses: params.BCC,
+		},
+	}
+	if params.ReplyTo != "" {
+		request.ReplyToAddresses = []string{params.ReplyTo}
+	}
+	request.Content.Raw.Data = base64.StdEncoding.EncodeToString([]byte(buildMIMEMessage(params)))
+
+	body, err := json.Marshal(request)
+	if err != nil {
+		return fmt.Errorf("failed to marshal SES request: %w", err)
+	}
+
+	resp, err := p.do(ctx, http.MethodPost, "/v2/email/outbound-emails", body)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return sesError(resp)
+	}
+
+	return nil
+}
+
+// do sends a SigV4-signed request to the SES API
+func (p *AWSSESProvider) do(ctx context.Context, method, path string, body []byte) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, p.endpoint+path, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create SES request: %w", err)
+	}
+
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	p.sign(req, body, p.now())
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reach SES: %w", err)
+	}
+
+	return resp, nil
+}
+
+// sign adds an AWS Signature Version 4 Authorization header to the request
+func (p *AWSSESProvider) sign(req *http.Request, body []byte, now time.Time) {
+	signRequestV4(req, body, now, p.region, sesSigningService, p.accessKeyID, p.secretAccessKey, p.sessionToken)
+}
+
+// signRequestV4 signs the request with AWS Signature Version 4, covering the host and x-amz-*
+// headers. See https://docs.aws.amazon.com/IAM/latest/UserGuide/reference_sigv-create-signed-request.html
+func signRequestV4(req *http.Request, body []byte, now time.Time, region, service, accessKeyID, secretAccessKey, sessionToken string) {
+	now = now.UTC()
+	amzDate := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+
+	req.Header.Set("X-Amz-Date", amzDate)
+	if sessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", sessionToken)
+	}
+
+	// Canonical headers, sorted by lowercase name
+	headers := map[string]string{"host": req.URL.Host}
+	for name, values := range req.Header {
+		lower := strings.ToLower(name)
+		if strings.HasPrefix(lower, "x-amz-") {
+			headers[lower] = strings.TrimSpace(strings.Join(values, ","))
+		}
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	path := req.URL.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		path,
+		req.URL.Query().Encode(),
+		canonicalHeaders.String(),
+		signedHeaders,
+		sha256Hex(body),
+	}, "\n")
+
+	scope := strings.Join([]string{date, region, service, "aws4_request"}, "/")
+	stringToSign := strings.Join([]string{
+		"AWS4-HMAC-SHA256",
+		amzDate,
+		scope,
+		sha256Hex([]byte(canonicalRequest)),
+	}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+secretAccessKey), date)
+	key = hmacSHA256(key, region)
+	key = hmacSHA256(key, service)
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf(
+		"AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		accessKeyID, scope, signedHeaders, signature,
+	))
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+// sesError builds an error from a failed SES response
+func sesError(resp *http.Response) error {
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	return fmt.Errorf("SES API error (status %d): %s", resp.StatusCode, string(body))
 }
@@ -0,0 +1,114 @@
+package email
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/opena2a/identity/backend/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestSESProvider(t *testing.T, handler http.HandlerFunc) *AWSSESProvider {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	provider, err := NewAWSSESProvider(domain.EmailConfig{
+		FromAddress: "noreply@example.com",
+		FromName:    "AIM",
+		SES: domain.SESConfig{
+			Region:          "eu-west-1",
+			AccessKeyID:     "AKIDEXAMPLE",
+			SecretAccessKey: "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY",
+			SessionToken:    "session-token",
+		},
+	})
+	require.NoError(t, err)
+	provider.endpoint = server.URL
+	provider.now = func() time.Time { return time.Date(2025, 3, 4, 15, 30, 0, 0, time.UTC) }
+	return provider
+}
+
+// Test vector "get-vanilla" from the AWS Signature Version 4 test suite
+func TestSignRequestV4_MatchesAWSTestSuite(t *testing.T) {
+	req, err := http.NewRequest(http.MethodGet, "https://example.amazonaws.com/", nil)
+	require.NoError(t, err)
+
+	signRequestV4(req, nil, time.Date(2015, 8, 30, 12, 36, 0, 0, time.UTC),
+		"us-east-1", "service", "AKIDEXAMPLE", "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY", "")
+
+	assert.Equal(t, "20150830T123600Z", req.Header.Get("X-Amz-Date"))
+	assert.Equal(t,
+		"AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/service/aws4_request, "+
+			"SignedHeaders=host;x-amz-date, "+
+			"Signature=5fa00fa31553b73ebf1942676e86291e8372ff2a2260956d9b8aae1d763fbf31",
+		req.Header.Get("Authorization"))
+}
+
+func TestAWSSESProvider_Send(t *testing.T) {
+	var got sesSendEmailRequest
+	provider := newTestSESProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "/v2/email/outbound-emails", r.URL.Path)
+		assert.Equal(t, "session-token", r.Header.Get("X-Amz-Security-Token"))
+		assert.True(t, strings.HasPrefix(r.Header.Get("Authorization"),
+			"AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20250304/eu-west-1/ses/aws4_request, SignedHeaders=host;x-amz-date;x-amz-security-token, "))
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&got))
+		w.Write([]byte(`{"MessageId":"0100018c"}`))
+	})
+
+	err := provider.Send(context.Background(), EmailParams{
+		To:       []string{"ada@example.com"},
+		CC:       []string{"ops@example.com"},
+		Subject:  "Welcome",
+		TextBody: "Hello Ada",
+		HTMLBody: "<p>Hello Ada</p>",
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, `"AIM" <noreply@example.com>`, got.FromEmailAddress)
+	assert.Equal(t, []string{"ada@example.com"}, got.Destination.ToAddresses)
+	assert.Equal(t, []string{"ops@example.com"}, got.Destination.CcAddresses)
+
+	raw, err := base64.StdEncoding.DecodeString(got.Content.Raw.Data)
+	require.NoError(t, err)
+	assert.Contains(t, string(raw), "Subject: Welcome\r\n")
+	assert.Contains(t, string(raw), "Cc: ops@example.com\r\n")
+	assert.Contains(t, string(raw), "Content-Type: multipart/alternative")
+	assert.Contains(t, string(raw), "<p>Hello Ada</p>")
+}
+
+func TestAWSSESProvider_SendReportsAPIErrors(t *testing.T) {
+	provider := newTestSESProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"message":"Email address is not verified."}`))
+	})
+
+	err := provider.Send(context.Background(), EmailParams{To: []string{"ada@example.com"}, Subject: "Hi", TextBody: "Hi"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "status 400")
+	assert.Contains(t, err.Error(), "not verified")
+}
+
+func TestAWSSESProvider_ValidateConnectionChecksSendingEnabled(t *testing.T) {
+	sendingEnabled := true
+	provider := newTestSESProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodGet, r.Method)
+		assert.Equal(t, "/v2/email/account", r.URL.Path)
+		json.NewEncoder(w).Encode(map[string]bool{"SendingEnabled": sendingEnabled})
+	})
+
+	assert.NoError(t, provider.ValidateConnection(context.Background()))
+
+	sendingEnabled = false
+	err := provider.ValidateConnection(context.Background())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "sending is disabled")
+}
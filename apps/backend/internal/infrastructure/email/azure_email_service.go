@@ -152,15 +152,29 @@ func parseAzureConnectionString(connStr string) (endpoint, accessKey string, err
 
 // SendEmail sends a plain text or HTML email
 func (s *AzureEmailService) SendEmail(to, subject, body string, isHTML bool) error {
+	content := azureEmailContent{
+		Subject: subject,
+	}
+
+	// Set body content based on type
+	if isHTML {
+		content.HTML = body
+	} else {
+		content.PlainText = body
+	}
+
+	return s.send(to, content)
+}
+
+// send sends content to a single recipient
+func (s *AzureEmailService) send(to string, content azureEmailContent) error {
 	ctx := context.Background()
 	startTime := time.Now()
 
 	// Build email request
 	request := azureEmailRequest{
 		SenderAddress: s.fromAddress,
-		Content: azureEmailContent{
-			Subject: subject,
-		},
+		Content:       content,
 		Recipients: azureEmailRecipients{
 			To: []azureEmailAddress{
 				{
@@ -170,13 +184,6 @@ func (s *AzureEmailService) SendEmail(to, subject, body string, isHTML bool) err
 		},
 	}
 
-	// Set body content based on type
-	if isHTML {
-		request.Content.HTML = body
-	} else {
-		request.Content.PlainText = body
-	}
-
 	// Send via Azure Communication Services API
 	if err := s.sendAzureEmail(ctx, request); err != nil {
 		s.recordFailure("send_error")
@@ -192,14 +199,14 @@ func (s *AzureEmailService) SendEmail(to, subject, body string, isHTML bool) err
 // SendTemplatedEmail sends an email using a predefined template
 func (s *AzureEmailService) SendTemplatedEmail(template domain.EmailTemplate, to string, data interface{}) error {
 	// Render the template
-	subject, body, err := s.templateRenderer.Render(template, data)
+	rendered, err := s.templateRenderer.Render(template, data)
 	if err != nil {
 		s.recordFailure("template_render_error")
 		return fmt.Errorf("failed to render template %s: %w", template, err)
 	}
 
-	// Send the email (always HTML for templates)
-	if err := s.SendEmail(to, subject, body, true); err != nil {
+	// Send the email as HTML with a plain-text alternative
+	if err := s.send(to, azureEmailContent{Subject: rendered.Subject, HTML: rendered.HTML, PlainText: rendered.Text}); err != nil {
 		s.recordFailure("send_error")
 		return err
 	}
@@ -3,6 +3,8 @@ package email
 import (
 	"context"
 	"fmt"
+
+	"github.com/opena2a/identity/backend/internal/domain"
 )
 
 // AzureEmailProvider implements email sending via Azure Communication Services
@@ -12,10 +14,10 @@ type AzureEmailProvider struct {
 }
 
 // NewAzureEmailProvider creates a new Azure email provider
-func NewAzureEmailProvider(config EmailConfig) (*AzureEmailProvider, error) {
+func NewAzureEmailProvider(config domain.EmailConfig) (*AzureEmailProvider, error) {
 	provider := &AzureEmailProvider{
-		connectionString: config.AzureConnectionString,
-		from:             fmt.Sprintf("%s <%s>", config.FromName, config.FromAddress),
+		connectionString: config.Azure.ConnectionString,
+		from:             formatAddress(config.FromName, config.FromAddress),
 	}
 
 	if err := provider.ValidateConfig(); err != nil {
@@ -41,8 +43,13 @@ func (p *AzureEmailProvider) GetProviderName() string {
 	return "Azure Communication Services"
 }
 
-// SendEmail sends an email via Azure Communication Services
-func (p *AzureEmailProvider) SendEmail(ctx context.Context, params EmailParams) error {
+// ValidateConnection checks the Azure configuration
+func (p *AzureEmailProvider) ValidateConnection(ctx context.Context) error {
+	return fmt.Errorf("Azure Communication Services provider not yet implemented - use NewAzureEmailService")
+}
+
+// Send sends an email via Azure Communication Services
+func (p *AzureEmailProvider) Send(ctx context.Context, params EmailParams) error {
 	// TODO: Implement Azure Communication Services integration
 	// See: https://learn.microsoft.com/en-us/azure/communication-services/quickstarts/email/send-email
 	//
@@ -53,8 +53,8 @@ func (s *ConsoleEmailService) SendEmail(to, subject, body string, isHTML bool) e
 
 // SendTemplatedEmail sends an email using a predefined template
 func (s *ConsoleEmailService) SendTemplatedEmail(template domain.EmailTemplate, to string, data interface{}) error {
-	// Render template (returns subject, body, err)
-	subject, body, err := s.templateRenderer.Render(template, data)
+	// Render template
+	rendered, err := s.templateRenderer.Render(template, data)
 	if err != nil {
 		return fmt.Errorf("failed to render template: %w", err)
 	}
@@ -66,11 +66,14 @@ func (s *ConsoleEmailService) SendTemplatedEmail(template domain.EmailTemplate,
 	fmt.Printf("Template: %s\n", template)
 	fmt.Printf("From: %s <%s>\n", s.fromName, s.fromAddress)
 	fmt.Printf("To: %s\n", to)
-	fmt.Printf("Subject: %s\n", subject)
+	fmt.Printf("Subject: %s\n", rendered.Subject)
 	fmt.Println(strings.Repeat("-", 80))
 
-	fmt.Println("Body:")
-	fmt.Println(body)
+	fmt.Println("Text Body:")
+	fmt.Println(rendered.Text)
+	fmt.Println(strings.Repeat("-", 80))
+	fmt.Println("HTML Body:")
+	fmt.Println(rendered.HTML)
 	fmt.Println(strings.Repeat("=", 80) + "\n")
 
 	return nil
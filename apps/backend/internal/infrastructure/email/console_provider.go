@@ -4,6 +4,8 @@ import (
 	"context"
 	"fmt"
 	"strings"
+
+	"github.com/opena2a/identity/backend/internal/domain"
 )
 
 // ConsoleProvider implements email sending by printing to console (for development)
@@ -12,9 +14,9 @@ type ConsoleProvider struct {
 }
 
 // NewConsoleProvider creates a new console email provider
-func NewConsoleProvider(config EmailConfig) (*ConsoleProvider, error) {
+func NewConsoleProvider(config domain.EmailConfig) (*ConsoleProvider, error) {
 	return &ConsoleProvider{
-		from: fmt.Sprintf("%s <%s>", config.FromName, config.FromAddress),
+		from: formatAddress(config.FromName, config.FromAddress),
 	}, nil
 }
 
@@ -28,8 +30,13 @@ func (p *ConsoleProvider) GetProviderName() string {
 	return "Console"
 }
 
-// SendEmail "sends" an email by printing it to console
-func (p *ConsoleProvider) SendEmail(ctx context.Context, params EmailParams) error {
+// ValidateConnection always succeeds, there is nothing to connect to
+func (p *ConsoleProvider) ValidateConnection(ctx context.Context) error {
+	return nil
+}
+
+// Send "sends" an email by printing it to console
+func (p *ConsoleProvider) Send(ctx context.Context, params EmailParams) error {
 	fmt.Println("\n" + strings.Repeat("=", 80))
 	fmt.Println("📧 EMAIL (Console Provider - Development Only)")
 	fmt.Println(strings.Repeat("=", 80))
@@ -26,13 +26,20 @@ func NewEmailServiceWithConfig(config domain.EmailConfig) (domain.EmailService,
 		return NewConsoleEmailService(config)
 	case "azure":
 		return NewAzureEmailService(config)
-	case "smtp":
-		return NewSMTPEmailService(config)
+	case string(ProviderSMTP), string(ProviderSendGrid), string(ProviderAWSSES):
+		provider, err := NewEmailProvider(config)
+		if err != nil {
+			return nil, err
+		}
+		return NewProviderEmailService(provider, config)
 	default:
-		return nil, fmt.Errorf("unsupported email provider: %s (use 'console', 'azure' or 'smtp')", config.Provider)
+		return nil, fmt.Errorf("unsupported email provider: %s (%s)", config.Provider, supportedProvidersHint)
 	}
 }
 
+// supportedProvidersHint lists the EMAIL_PROVIDER values NewEmailServiceWithConfig accepts
+const supportedProvidersHint = "use 'console', 'azure', 'smtp', 'sendgrid' or 'aws_ses'"
+
 // LoadEmailConfigFromEnv loads email configuration from environment variables
 func LoadEmailConfigFromEnv() (domain.EmailConfig, error) {
 	provider := getEnv("EMAIL_PROVIDER", "azure")
@@ -84,8 +91,34 @@ func LoadEmailConfigFromEnv() (domain.EmailConfig, error) {
 			return config, fmt.Errorf("SMTP_PORT is required for SMTP provider")
 		}
 
+	case "sendgrid":
+		config.SendGrid = domain.SendGridConfig{
+			APIKey: getEnv("SENDGRID_API_KEY", ""),
+		}
+
+		if config.SendGrid.APIKey == "" {
+			return config, fmt.Errorf("SENDGRID_API_KEY is required for SendGrid provider")
+		}
+
+	case "aws_ses":
+		// Fall back to the standard AWS variables so SES can share the deployment's credentials
+		config.SES = domain.SESConfig{
+			Region:          getEnv("AWS_SES_REGION", os.Getenv("AWS_REGION")),
+			AccessKeyID:     getEnv("AWS_SES_ACCESS_KEY_ID", os.Getenv("AWS_ACCESS_KEY_ID")),
+			SecretAccessKey: getEnv("AWS_SES_SECRET_ACCESS_KEY", os.Getenv("AWS_SECRET_ACCESS_KEY")),
+			SessionToken:    getEnv("AWS_SES_SESSION_TOKEN", os.Getenv("AWS_SESSION_TOKEN")),
+		}
+
+		if config.SES.Region == "" {
+			return config, fmt.Errorf("AWS_SES_REGION is required for AWS SES provider")
+		}
+
+		if config.SES.AccessKeyID == "" || config.SES.SecretAccessKey == "" {
+			return config, fmt.Errorf("AWS_SES_ACCESS_KEY_ID and AWS_SES_SECRET_ACCESS_KEY are required for AWS SES provider")
+		}
+
 	default:
-		return config, fmt.Errorf("unsupported EMAIL_PROVIDER: %s (use 'console', 'azure' or 'smtp')", provider)
+		return config, fmt.Errorf("unsupported EMAIL_PROVIDER: %s (%s)", provider, supportedProvidersHint)
 	}
 
 	return config, nil
@@ -153,6 +186,20 @@ func ValidateEmailConfig(config domain.EmailConfig) error {
 			return fmt.Errorf("smtp port is required")
 		}
 
+	case "sendgrid":
+		if config.SendGrid.APIKey == "" {
+			return fmt.Errorf("sendgrid api key is required")
+		}
+
+	case "aws_ses":
+		if config.SES.Region == "" {
+			return fmt.Errorf("aws ses region is required")
+		}
+
+		if config.SES.AccessKeyID == "" || config.SES.SecretAccessKey == "" {
+			return fmt.Errorf("aws ses access key id and secret access key are required")
+		}
+
 	default:
 		return fmt.Errorf("unsupported provider: %s", config.Provider)
 	}
@@ -3,17 +3,23 @@ package email
 import (
 	"context"
 	"fmt"
+	"net/mail"
+
+	"github.com/opena2a/identity/backend/internal/domain"
 )
 
 // EmailProvider defines the interface that all email providers must implement
 // This allows AIM to support multiple email services (SMTP, Azure, AWS SES, SendGrid, Resend)
 type EmailProvider interface {
-	// SendEmail sends an email with the given parameters
-	SendEmail(ctx context.Context, params EmailParams) error
+	// Send delivers an email with the given parameters
+	Send(ctx context.Context, params EmailParams) error
 
 	// ValidateConfig validates the provider's configuration
 	ValidateConfig() error
 
+	// ValidateConnection checks that the provider is reachable and accepts the credentials
+	ValidateConnection(ctx context.Context) error
+
 	// GetProviderName returns the name of the email provider
 	GetProviderName() string
 }
@@ -51,39 +57,9 @@ const (
 	ProviderConsole  ProviderType = "console"   // Console output (development only)
 )
 
-// EmailConfig holds configuration for email providers
-type EmailConfig struct {
-	Provider ProviderType `json:"provider"`
-
-	// SMTP Configuration
-	SMTPHost     string `json:"smtp_host,omitempty"`
-	SMTPPort     int    `json:"smtp_port,omitempty"`
-	SMTPUser     string `json:"smtp_user,omitempty"`
-	SMTPPassword string `json:"smtp_password,omitempty"`
-	SMTPTLS      bool   `json:"smtp_tls,omitempty"`
-
-	// Azure Communication Services
-	AzureConnectionString string `json:"azure_connection_string,omitempty"`
-
-	// AWS SES
-	AWSRegion          string `json:"aws_region,omitempty"`
-	AWSAccessKeyID     string `json:"aws_access_key_id,omitempty"`
-	AWSSecretAccessKey string `json:"aws_secret_access_key,omitempty"`
-
-	// SendGrid
-	SendGridAPIKey string `json:"sendgrid_api_key,omitempty"`
-
-	// Resend
-	ResendAPIKey string `json:"resend_api_key,omitempty"`
-
-	// Common Settings
-	FromAddress string `json:"from_address"`
-	FromName    string `json:"from_name"`
-}
-
 // NewEmailProvider creates a new email provider based on the configuration
-func NewEmailProvider(config EmailConfig) (EmailProvider, error) {
-	switch config.Provider {
+func NewEmailProvider(config domain.EmailConfig) (EmailProvider, error) {
+	switch ProviderType(config.Provider) {
 	case ProviderSMTP:
 		return NewSMTPProvider(config)
 	case ProviderAzure:
@@ -100,3 +76,14 @@ func NewEmailProvider(config EmailConfig) (EmailProvider, error) {
 		return nil, fmt.Errorf("unsupported email provider: %s", config.Provider)
 	}
 }
+
+// formatAddress returns the From header value for a sender
+func formatAddress(name, address string) string {
+	if address == "" {
+		return ""
+	}
+	if name == "" {
+		return address
+	}
+	return (&mail.Address{Name: name, Address: address}).String()
+}
@@ -0,0 +1,203 @@
+package email
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/opena2a/identity/backend/internal/domain"
+)
+
+// providerSendTimeout bounds a single delivery or connection check
+const providerSendTimeout = 30 * time.Second
+
+// ProviderEmailService implements domain.EmailService on top of an EmailProvider.
+// It renders templates and tracks metrics; the provider only delivers messages.
+type ProviderEmailService struct {
+	provider         EmailProvider
+	from             string
+	templateRenderer *TemplateRenderer
+	metrics          *emailMetrics
+}
+
+// NewProviderEmailService creates an email service that delivers through the given provider
+func NewProviderEmailService(provider EmailProvider, config domain.EmailConfig) (*ProviderEmailService, error) {
+	if provider == nil {
+		return nil, fmt.Errorf("email provider is required")
+	}
+
+	if config.FromAddress == "" {
+		return nil, fmt.Errorf("from email address is required")
+	}
+
+	templateRenderer, err := NewTemplateRenderer(config.TemplateDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize template renderer: %w", err)
+	}
+
+	return &ProviderEmailService{
+		provider:         provider,
+		from:             formatAddress(config.FromName, config.FromAddress),
+		templateRenderer: templateRenderer,
+		metrics: &emailMetrics{
+			failuresByType: make(map[string]int64),
+			sentByTemplate: make(map[domain.EmailTemplate]int64),
+		},
+	}, nil
+}
+
+// SendEmail sends a plain text or HTML email. HTML emails get a plain-text alternative derived from the HTML.
+func (s *ProviderEmailService) SendEmail(to, subject, body string, isHTML bool) error {
+	params := EmailParams{
+		To:       []string{to},
+		From:     s.from,
+		Subject:  subject,
+		TextBody: body,
+	}
+	if isHTML {
+		params.HTMLBody = body
+		params.TextBody = htmlToText(body)
+	}
+
+	return s.send(params)
+}
+
+// SendTemplatedEmail sends an email using a predefined template
+func (s *ProviderEmailService) SendTemplatedEmail(template domain.EmailTemplate, to string, data interface{}) error {
+	// Render the template
+	rendered, err := s.templateRenderer.Render(template, data)
+	if err != nil {
+		s.recordFailure("template_render_error")
+		return fmt.Errorf("failed to render template %s: %w", template, err)
+	}
+
+	// Send the email as HTML with a plain-text alternative
+	if err := s.send(EmailParams{
+		To:       []string{to},
+		From:     s.from,
+		Subject:  rendered.Subject,
+		TextBody: rendered.Text,
+		HTMLBody: rendered.HTML,
+	}); err != nil {
+		return err
+	}
+
+	// Track template usage
+	s.metrics.mu.Lock()
+	s.metrics.sentByTemplate[template]++
+	s.metrics.mu.Unlock()
+
+	return nil
+}
+
+// SendBulkEmail sends the same email to multiple recipients
+func (s *ProviderEmailService) SendBulkEmail(recipients []string, subject, body string, isHTML bool) error {
+	var wg sync.WaitGroup
+	errChan := make(chan error, len(recipients))
+
+	for _, recipient := range recipients {
+		wg.Add(1)
+		go func(to string) {
+			defer wg.Done()
+			if err := s.SendEmail(to, subject, body, isHTML); err != nil {
+				errChan <- fmt.Errorf("failed to send to %s: %w", to, err)
+			}
+			// Small delay to avoid rate limiting
+			time.Sleep(100 * time.Millisecond)
+		}(recipient)
+	}
+
+	wg.Wait()
+	close(errChan)
+
+	// Collect errors
+	var errors []error
+	for err := range errChan {
+		errors = append(errors, err)
+	}
+
+	if len(errors) > 0 {
+		return fmt.Errorf("failed to send %d/%d emails: %v", len(errors), len(recipients), errors[0])
+	}
+
+	return nil
+}
+
+// ValidateConnection checks that the provider is reachable and accepts the credentials
+func (s *ProviderEmailService) ValidateConnection() error {
+	ctx, cancel := context.WithTimeout(context.Background(), providerSendTimeout)
+	defer cancel()
+
+	if err := s.provider.ValidateConnection(ctx); err != nil {
+		return fmt.Errorf("%s: %w", s.provider.GetProviderName(), err)
+	}
+
+	return nil
+}
+
+// send delivers a message through the provider and records the outcome
+func (s *ProviderEmailService) send(params EmailParams) error {
+	ctx, cancel := context.WithTimeout(context.Background(), providerSendTimeout)
+	defer cancel()
+
+	if err := s.provider.Send(ctx, params); err != nil {
+		s.recordFailure("send_error")
+		return fmt.Errorf("failed to send email via %s: %w", s.provider.GetProviderName(), err)
+	}
+
+	s.recordSuccess()
+
+	return nil
+}
+
+// GetMetrics returns current email sending metrics
+func (s *ProviderEmailService) GetMetrics() domain.EmailMetrics {
+	s.metrics.mu.RLock()
+	defer s.metrics.mu.RUnlock()
+
+	failuresByType := make(map[string]int64)
+	for k, v := range s.metrics.failuresByType {
+		failuresByType[k] = v
+	}
+
+	sentByTemplate := make(map[domain.EmailTemplate]int64)
+	for k, v := range s.metrics.sentByTemplate {
+		sentByTemplate[k] = v
+	}
+
+	var successRate float64
+	total := s.metrics.totalSent + s.metrics.totalFailed
+	if total > 0 {
+		successRate = float64(s.metrics.totalSent) / float64(total) * 100
+	}
+
+	return domain.EmailMetrics{
+		TotalSent:      s.metrics.totalSent,
+		TotalFailed:    s.metrics.totalFailed,
+		LastSentAt:     s.metrics.lastSentAt,
+		LastFailedAt:   s.metrics.lastFailedAt,
+		SuccessRate:    successRate,
+		FailuresByType: failuresByType,
+		SentByTemplate: sentByTemplate,
+	}
+}
+
+// recordSuccess updates metrics for successful email send
+func (s *ProviderEmailService) recordSuccess() {
+	s.metrics.mu.Lock()
+	defer s.metrics.mu.Unlock()
+
+	s.metrics.totalSent++
+	s.metrics.lastSentAt = time.Now()
+}
+
+// recordFailure updates metrics for failed email send
+func (s *ProviderEmailService) recordFailure(errorType string) {
+	s.metrics.mu.Lock()
+	defer s.metrics.mu.Unlock()
+
+	s.metrics.totalFailed++
+	s.metrics.lastFailedAt = time.Now()
+	s.metrics.failuresByType[errorType]++
+}
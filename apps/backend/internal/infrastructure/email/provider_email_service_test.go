@@ -0,0 +1,267 @@
+package email
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/opena2a/identity/backend/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeProvider records sent messages instead of delivering them
+type fakeProvider struct {
+	mu         sync.Mutex
+	sent       []EmailParams
+	sendErr    error
+	connectErr error
+}
+
+func (p *fakeProvider) Send(ctx context.Context, params EmailParams) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.sendErr != nil {
+		return p.sendErr
+	}
+	p.sent = append(p.sent, params)
+	return nil
+}
+
+func (p *fakeProvider) ValidateConfig() error                        { return nil }
+func (p *fakeProvider) ValidateConnection(ctx context.Context) error { return p.connectErr }
+func (p *fakeProvider) GetProviderName() string                      { return "Fake" }
+
+func newTestProviderService(t *testing.T, provider EmailProvider) *ProviderEmailService {
+	t.Helper()
+	service, err := NewProviderEmailService(provider, domain.EmailConfig{
+		FromAddress: "noreply@example.com",
+		FromName:    "AIM",
+	})
+	require.NoError(t, err)
+	return service
+}
+
+func TestProviderEmailService_SendTemplatedEmailSendsBothParts(t *testing.T) {
+	provider := &fakeProvider{}
+	service := newTestProviderService(t, provider)
+
+	require.NoError(t, service.SendTemplatedEmail(domain.TemplateWelcome, "ada@example.com", brandedTemplateData("en")))
+
+	require.Len(t, provider.sent, 1)
+	sent := provider.sent[0]
+	assert.Equal(t, []string{"ada@example.com"}, sent.To)
+	assert.Equal(t, `"AIM" <noreply@example.com>`, sent.From)
+	assert.Equal(t, "Registration Received - Pending Approval", sent.Subject)
+	assert.Contains(t, sent.HTMLBody, "<html")
+	assert.NotEmpty(t, sent.TextBody)
+	assert.NotContains(t, sent.TextBody, "<")
+
+	metrics := service.GetMetrics()
+	assert.Equal(t, int64(1), metrics.TotalSent)
+	assert.Equal(t, int64(1), metrics.SentByTemplate[domain.TemplateWelcome])
+}
+
+func TestProviderEmailService_SendEmailDerivesTextFromHTML(t *testing.T) {
+	provider := &fakeProvider{}
+	service := newTestProviderService(t, provider)
+
+	require.NoError(t, service.SendEmail("ada@example.com", "Hello", "<p>Hello <b>Ada</b></p>", true))
+	require.NoError(t, service.SendEmail("ada@example.com", "Plain", "Just text", false))
+
+	require.Len(t, provider.sent, 2)
+	assert.Equal(t, "<p>Hello <b>Ada</b></p>", provider.sent[0].HTMLBody)
+	assert.Equal(t, "Hello Ada", strings.TrimSpace(provider.sent[0].TextBody))
+	assert.Empty(t, provider.sent[1].HTMLBody)
+	assert.Equal(t, "Just text", provider.sent[1].TextBody)
+}
+
+func TestProviderEmailService_RecordsProviderFailures(t *testing.T) {
+	provider := &fakeProvider{sendErr: errors.New("mailbox unavailable")}
+	service := newTestProviderService(t, provider)
+
+	err := service.SendEmail("ada@example.com", "Hello", "body", false)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "Fake")
+	assert.ErrorIs(t, err, provider.sendErr)
+
+	err = service.SendBulkEmail([]string{"a@example.com", "b@example.com"}, "Hello", "body", false)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "2/2")
+
+	metrics := service.GetMetrics()
+	assert.Equal(t, int64(0), metrics.TotalSent)
+	assert.Equal(t, int64(3), metrics.TotalFailed)
+	assert.Equal(t, int64(3), metrics.FailuresByType["send_error"])
+}
+
+func TestProviderEmailService_ValidateConnectionDelegatesToProvider(t *testing.T) {
+	provider := &fakeProvider{}
+	service := newTestProviderService(t, provider)
+	assert.NoError(t, service.ValidateConnection())
+
+	provider.connectErr = errors.New("535 authentication failed")
+	err := service.ValidateConnection()
+	require.Error(t, err)
+	assert.ErrorIs(t, err, provider.connectErr)
+}
+
+func TestNewEmailServiceWithConfig_SelectsProvider(t *testing.T) {
+	base := domain.EmailConfig{FromAddress: "noreply@example.com", FromName: "AIM"}
+
+	tests := []struct {
+		name     string
+		config   func(domain.EmailConfig) domain.EmailConfig
+		provider string
+		wantErr  string
+	}{
+		{
+			name: "smtp",
+			config: func(c domain.EmailConfig) domain.EmailConfig {
+				c.Provider = "smtp"
+				c.SMTP = domain.SMTPConfig{Host: "smtp.example.com", Port: 587, TLSEnabled: true}
+				return c
+			},
+			provider: "SMTP",
+		},
+		{
+			name: "smtp without host",
+			config: func(c domain.EmailConfig) domain.EmailConfig {
+				c.Provider = "smtp"
+				c.SMTP = domain.SMTPConfig{Port: 587}
+				return c
+			},
+			wantErr: "SMTP host is required",
+		},
+		{
+			name: "smtp with invalid port",
+			config: func(c domain.EmailConfig) domain.EmailConfig {
+				c.Provider = "smtp"
+				c.SMTP = domain.SMTPConfig{Host: "smtp.example.com", Port: 70000}
+				return c
+			},
+			wantErr: "out of range",
+		},
+		{
+			name: "sendgrid",
+			config: func(c domain.EmailConfig) domain.EmailConfig {
+				c.Provider = "sendgrid"
+				c.SendGrid = domain.SendGridConfig{APIKey: "SG.key"}
+				return c
+			},
+			provider: "SendGrid",
+		},
+		{
+			name: "sendgrid without api key",
+			config: func(c domain.EmailConfig) domain.EmailConfig {
+				c.Provider = "sendgrid"
+				return c
+			},
+			wantErr: "SendGrid API key is required",
+		},
+		{
+			name: "aws ses",
+			config: func(c domain.EmailConfig) domain.EmailConfig {
+				c.Provider = "aws_ses"
+				c.SES = domain.SESConfig{Region: "eu-west-1", AccessKeyID: "AKID", SecretAccessKey: "secret"}
+				return c
+			},
+			provider: "AWS SES",
+		},
+		{
+			name: "aws ses without region",
+			config: func(c domain.EmailConfig) domain.EmailConfig {
+				c.Provider = "aws_ses"
+				c.SES = domain.SESConfig{AccessKeyID: "AKID", SecretAccessKey: "secret"}
+				return c
+			},
+			wantErr: "AWS region is required",
+		},
+		{
+			name: "aws ses without secret",
+			config: func(c domain.EmailConfig) domain.EmailConfig {
+				c.Provider = "aws_ses"
+				c.SES = domain.SESConfig{Region: "eu-west-1", AccessKeyID: "AKID"}
+				return c
+			},
+			wantErr: "AWS secret access key is required",
+		},
+		{
+			name: "unknown provider",
+			config: func(c domain.EmailConfig) domain.EmailConfig {
+				c.Provider = "carrier_pigeon"
+				return c
+			},
+			wantErr: "unsupported email provider",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, err := NewEmailServiceWithConfig(tt.config(base))
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+
+			require.NoError(t, err)
+			providerService, ok := service.(*ProviderEmailService)
+			require.True(t, ok, "expected a provider-backed email service")
+			assert.Equal(t, tt.provider, providerService.provider.GetProviderName())
+		})
+	}
+}
+
+func TestLoadEmailConfigFromEnv_ProviderSettings(t *testing.T) {
+	t.Run("sendgrid requires an api key", func(t *testing.T) {
+		t.Setenv("EMAIL_PROVIDER", "sendgrid")
+		t.Setenv("EMAIL_FROM_ADDRESS", "noreply@example.com")
+		t.Setenv("SENDGRID_API_KEY", "")
+
+		_, err := LoadEmailConfigFromEnv()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "SENDGRID_API_KEY")
+
+		t.Setenv("SENDGRID_API_KEY", "SG.key")
+		config, err := LoadEmailConfigFromEnv()
+		require.NoError(t, err)
+		assert.Equal(t, "SG.key", config.SendGrid.APIKey)
+		assert.NoError(t, ValidateEmailConfig(config))
+	})
+
+	t.Run("aws ses falls back to the standard aws variables", func(t *testing.T) {
+		t.Setenv("EMAIL_PROVIDER", "aws_ses")
+		t.Setenv("EMAIL_FROM_ADDRESS", "noreply@example.com")
+		t.Setenv("AWS_SES_REGION", "")
+		t.Setenv("AWS_SES_ACCESS_KEY_ID", "")
+		t.Setenv("AWS_SES_SECRET_ACCESS_KEY", "")
+		t.Setenv("AWS_SES_SESSION_TOKEN", "")
+		t.Setenv("AWS_REGION", "")
+		t.Setenv("AWS_ACCESS_KEY_ID", "AKID")
+		t.Setenv("AWS_SECRET_ACCESS_KEY", "secret")
+		t.Setenv("AWS_SESSION_TOKEN", "")
+
+		_, err := LoadEmailConfigFromEnv()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "AWS_SES_REGION")
+
+		t.Setenv("AWS_REGION", "us-east-1")
+		t.Setenv("AWS_SES_REGION", "eu-west-1")
+		config, err := LoadEmailConfigFromEnv()
+		require.NoError(t, err)
+		assert.Equal(t, domain.SESConfig{Region: "eu-west-1", AccessKeyID: "AKID", SecretAccessKey: "secret"}, config.SES)
+		assert.NoError(t, ValidateEmailConfig(config))
+	})
+
+	t.Run("unknown provider", func(t *testing.T) {
+		t.Setenv("EMAIL_PROVIDER", "carrier_pigeon")
+		t.Setenv("EMAIL_FROM_ADDRESS", "noreply@example.com")
+
+		_, err := LoadEmailConfigFromEnv()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "aws_ses")
+	})
+}
@@ -3,6 +3,8 @@ package email
 import (
 	"context"
 	"fmt"
+
+	"github.com/opena2a/identity/backend/internal/domain"
 )
 
 // ResendProvider implements email sending via Resend
@@ -12,10 +14,10 @@ type ResendProvider struct {
 }
 
 // NewResendProvider creates a new Resend email provider
-func NewResendProvider(config EmailConfig) (*ResendProvider, error) {
+func NewResendProvider(config domain.EmailConfig) (*ResendProvider, error) {
 	provider := &ResendProvider{
-		apiKey: config.ResendAPIKey,
-		from:   fmt.Sprintf("%s <%s>", config.FromName, config.FromAddress),
+		apiKey: config.Resend.APIKey,
+		from:   formatAddress(config.FromName, config.FromAddress),
 	}
 
 	if err := provider.ValidateConfig(); err != nil {
@@ -41,8 +43,13 @@ func (p *ResendProvider) GetProviderName() string {
 	return "Resend"
 }
 
-// SendEmail sends an email via Resend
-func (p *ResendProvider) SendEmail(ctx context.Context, params EmailParams) error {
+// ValidateConnection checks the Resend API key
+func (p *ResendProvider) ValidateConnection(ctx context.Context) error {
+	return fmt.Errorf("Resend provider not yet implemented - contributions welcome!")
+}
+
+// Send sends an email via Resend
+func (p *ResendProvider) Send(ctx context.Context, params EmailParams) error {
 	// TODO: Implement Resend integration
 	// See: https://resend.com/docs/send-with-go
 	//
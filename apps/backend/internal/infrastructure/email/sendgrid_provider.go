@@ -1,21 +1,77 @@
 package email
 
 import (
+	"bytes"
 	"context"
+	"encoding/base64"
+	"encoding/json"
 	"fmt"
+	"io"
+	"net/http"
+	"net/mail"
+	"time"
+
+	"github.com/opena2a/identity/backend/internal/domain"
 )
 
+// sendGridAPIURL is the SendGrid v3 API base URL
+const sendGridAPIURL = "https://api.sendgrid.com"
+
+// sendGridMailSendScope is the API key scope required to send mail
+const sendGridMailSendScope = "mail.send"
+
 // SendGridProvider implements email sending via SendGrid
 type SendGridProvider struct {
-	apiKey string
-	from   string
+	apiKey     string
+	from       string
+	baseURL    string
+	httpClient *http.Client
+}
+
+// sendGridAddress is an email address in SendGrid's v3 API
+type sendGridAddress struct {
+	Email string `json:"email"`
+	Name  string `json:"name,omitempty"`
+}
+
+// sendGridPersonalization holds the recipients of a SendGrid message
+type sendGridPersonalization struct {
+	To  []sendGridAddress `json:"to"`
+	CC  []sendGridAddress `json:"cc,omitempty"`
+	BCC []sendGridAddress `json:"bcc,omitempty"`
+}
+
+// sendGridContent is one body part of a SendGrid message
+type sendGridContent struct {
+	Type  string `json:"type"`
+	Value string `json:"value"`
+}
+
+// sendGridAttachment is a base64-encoded SendGrid attachment
+type sendGridAttachment struct {
+	Content  string `json:"content"`
+	Filename string `json:"filename"`
+	Type     string `json:"type,omitempty"`
+}
+
+// sendGridMessage is the request payload for POST /v3/mail/send
+type sendGridMessage struct {
+	Personalizations []sendGridPersonalization `json:"personalizations"`
+	From             sendGridAddress           `json:"from"`
+	ReplyTo          *sendGridAddress          `json:"reply_to,omitempty"`
+	Subject          string                    `json:"subject"`
+	Content          []sendGridContent         `json:"content"`
+	Attachments      []sendGridAttachment      `json:"attachments,omitempty"`
+	Headers          map[string]string         `json:"headers,omitempty"`
 }
 
 // NewSendGridProvider creates a new SendGrid email provider
-func NewSendGridProvider(config EmailConfig) (*SendGridProvider, error) {
+func NewSendGridProvider(config domain.EmailConfig) (*SendGridProvider, error) {
 	provider := &SendGridProvider{
-		apiKey: config.SendGridAPIKey,
-		from:   fmt.Sprintf("%s <%s>", config.FromName, config.FromAddress),
+		apiKey:     config.SendGrid.APIKey,
+		from:       formatAddress(config.FromName, config.FromAddress),
+		baseURL:    sendGridAPIURL,
+		httpClient: &http.Client{Timeout: 30 * time.Second},
 	}
 
 	if err := provider.ValidateConfig(); err != nil {
@@ -41,16 +97,169 @@ func (p *SendGridProvider) GetProviderName() string {
 	return "SendGrid"
 }
 
-// SendEmail sends an email via SendGrid
-func (p *SendGridProvider) SendEmail(ctx context.Context, params EmailParams) error {
-	// TODO: Implement SendGrid integration
-	// See: https://github.com/sendgrid/sendgrid-go
-	//
-	// Implementation steps:
-	// 1. Import SendGrid SDK: github.com/sendgrid/sendgrid-go
-	// 2. Create message with mail.NewV3Mail()
-	// 3. Set personalization (to, cc, bcc)
-	// 4. Send via sendgrid.NewSendClient(apiKey).Send()
+// ValidateConnection checks that the API key is accepted and has the mail.send scope
+func (p *SendGridProvider) ValidateConnection(ctx context.Context) error {
+	resp, err := p.do(ctx, http.MethodGet, "/v3/scopes", nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return sendGridError(resp)
+	}
+
+	var result struct {
+		Scopes []string `json:"scopes"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return fmt.Errorf("failed to decode SendGrid scopes: %w", err)
+	}
+
+	for _, scope := range result.Scopes {
+		if scope == sendGridMailSendScope {
+			return nil
+		}
+	}
+	return fmt.Errorf("SendGrid API key is missing the %s scope", sendGridMailSendScope)
+}
+
+// Send sends an email via SendGrid
+func (p *SendGridProvider) Send(ctx context.Context, params EmailParams) error {
+	if params.From == "" {
+		params.From = p.from
+	}
+
+	message, err := buildSendGridMessage(params)
+	if err != nil {
+		return err
+	}
+
+	body, err := json.Marshal(message)
+	if err != nil {
+		return fmt.Errorf("failed to marshal SendGrid request: %w", err)
+	}
+
+	resp, err := p.do(ctx, http.MethodPost, "/v3/mail/send", body)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	// SendGrid answers 202 Accepted once the message is queued
+	if resp.StatusCode != http.StatusAccepted && resp.StatusCode != http.StatusOK {
+		return sendGridError(resp)
+	}
+
+	return nil
+}
+
+// do sends an authenticated request to the SendGrid API
+func (p *SendGridProvider) do(ctx context.Context, method, path string, body []byte) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, p.baseURL+path, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create SendGrid request: %w", err)
+	}
+
+	req.Header.Set("Authorization", "Bearer "+p.apiKey)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reach SendGrid: %w", err)
+	}
+
+	return resp, nil
+}
+
+// buildSendGridMessage converts email parameters into a SendGrid v3 payload
+func buildSendGridMessage(params EmailParams) (*sendGridMessage, error) {
+	from, err := sendGridAddressOf(params.From)
+	if err != nil {
+		return nil, fmt.Errorf("invalid From address: %w", err)
+	}
+
+	to, err := sendGridAddressList(params.To)
+	if err != nil {
+		return nil, err
+	}
+	if len(to) == 0 {
+		return nil, fmt.Errorf("at least one recipient is required")
+	}
+
+	cc, err := sendGridAddressList(params.CC)
+	if err != nil {
+		return nil, err
+	}
+
+	bcc, err := sendGridAddressList(params.BCC)
+	if err != nil {
+		return nil, err
+	}
+
+	message := &sendGridMessage{
+		Personalizations: []sendGridPersonalization{{To: to, CC: cc, BCC: bcc}},
+		From:             from,
+		Subject:          params.Subject,
+		Headers:          params.Headers,
+	}
+
+	if params.ReplyTo != "" {
+		replyTo, err := sendGridAddressOf(params.ReplyTo)
+		if err != nil {
+			return nil, fmt.Errorf("invalid Reply-To address: %w", err)
+		}
+		message.ReplyTo = &replyTo
+	}
+
+	// SendGrid requires text/plain before text/html
+	if params.TextBody != "" {
+		message.Content = append(message.Content, sendGridContent{Type: "text/plain", Value: params.TextBody})
+	}
+	if params.HTMLBody != "" {
+		message.Content = append(message.Content, sendGridContent{Type: "text/html", Value: params.HTMLBody})
+	}
+	if len(message.Content) == 0 {
+		return nil, fmt.Errorf("email body is required")
+	}
+
+	for _, attachment := range params.Attachments {
+		message.Attachments = append(message.Attachments, sendGridAttachment{
+			Content:  base64.StdEncoding.EncodeToString(attachment.Data),
+			Filename: attachment.Filename,
+			Type:     attachment.ContentType,
+		})
+	}
+
+	return message, nil
+}
+
+// sendGridAddressOf parses an RFC 5322 address such as "AIM <noreply@example.com>"
+func sendGridAddressOf(address string) (sendGridAddress, error) {
+	parsed, err := mail.ParseAddress(address)
+	if err != nil {
+		return sendGridAddress{}, err
+	}
+	return sendGridAddress{Email: parsed.Address, Name: parsed.Name}, nil
+}
+
+// sendGridAddressList parses a list of recipient addresses
+func sendGridAddressList(addresses []string) ([]sendGridAddress, error) {
+	var result []sendGridAddress
+	for _, address := range addresses {
+		parsed, err := sendGridAddressOf(address)
+		if err != nil {
+			return nil, fmt.Errorf("invalid recipient %q: %w", address, err)
+		}
+		result = append(result, parsed)
+	}
+	return result, nil
+}
 
-	return fmt.Errorf("SendGrid provider not yet implemented - contributions welcome!")
+// sendGridError builds an error from a failed SendGrid response
+func sendGridError(resp *http.Response) error {
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	return fmt.Errorf("SendGrid API error (status %d): %s", resp.StatusCode, string(body))
 }
@@ -0,0 +1,90 @@
+package email
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/opena2a/identity/backend/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestSendGridProvider(t *testing.T, handler http.HandlerFunc) *SendGridProvider {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	provider, err := NewSendGridProvider(domain.EmailConfig{
+		FromAddress: "noreply@example.com",
+		FromName:    "AIM",
+		SendGrid:    domain.SendGridConfig{APIKey: "SG.test"},
+	})
+	require.NoError(t, err)
+	provider.baseURL = server.URL
+	return provider
+}
+
+func TestSendGridProvider_Send(t *testing.T) {
+	var got sendGridMessage
+	provider := newTestSendGridProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "/v3/mail/send", r.URL.Path)
+		assert.Equal(t, "Bearer SG.test", r.Header.Get("Authorization"))
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&got))
+		w.WriteHeader(http.StatusAccepted)
+	})
+
+	err := provider.Send(context.Background(), EmailParams{
+		To:       []string{"Ada Lovelace <ada@example.com>"},
+		BCC:      []string{"audit@example.com"},
+		ReplyTo:  "support@example.com",
+		Subject:  "Welcome",
+		TextBody: "Hello Ada",
+		HTMLBody: "<p>Hello Ada</p>",
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, sendGridAddress{Email: "noreply@example.com", Name: "AIM"}, got.From)
+	require.Len(t, got.Personalizations, 1)
+	assert.Equal(t, []sendGridAddress{{Email: "ada@example.com", Name: "Ada Lovelace"}}, got.Personalizations[0].To)
+	assert.Equal(t, []sendGridAddress{{Email: "audit@example.com"}}, got.Personalizations[0].BCC)
+	assert.Equal(t, &sendGridAddress{Email: "support@example.com"}, got.ReplyTo)
+	assert.Equal(t, "Welcome", got.Subject)
+	assert.Equal(t, []sendGridContent{
+		{Type: "text/plain", Value: "Hello Ada"},
+		{Type: "text/html", Value: "<p>Hello Ada</p>"},
+	}, got.Content)
+}
+
+func TestSendGridProvider_SendReportsAPIErrors(t *testing.T) {
+	provider := newTestSendGridProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+		w.Write([]byte(`{"errors":[{"message":"The provided authorization grant is invalid"}]}`))
+	})
+
+	err := provider.Send(context.Background(), EmailParams{To: []string{"ada@example.com"}, Subject: "Hi", TextBody: "Hi"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "status 401")
+	assert.Contains(t, err.Error(), "authorization grant is invalid")
+
+	err = provider.Send(context.Background(), EmailParams{Subject: "Hi", TextBody: "Hi"})
+	assert.EqualError(t, err, "at least one recipient is required")
+}
+
+func TestSendGridProvider_ValidateConnectionRequiresMailSendScope(t *testing.T) {
+	scopes := []string{"mail.send", "user.profile.read"}
+	provider := newTestSendGridProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v3/scopes", r.URL.Path)
+		json.NewEncoder(w).Encode(map[string][]string{"scopes": scopes})
+	})
+
+	assert.NoError(t, provider.ValidateConnection(context.Background()))
+
+	scopes = []string{"user.profile.read"}
+	err := provider.ValidateConnection(context.Background())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "mail.send")
+}
@@ -4,10 +4,20 @@ import (
 	"context"
 	"crypto/tls"
 	"fmt"
+	"mime"
+	"net"
+	"net/mail"
 	"net/smtp"
+	"strconv"
 	"strings"
+	"time"
+
+	"github.com/opena2a/identity/backend/internal/domain"
 )
 
+// smtpImplicitTLSPort is the submission port that expects TLS from the first byte (RFC 8314)
+const smtpImplicitTLSPort = 465
+
 // SMTPProvider implements email sending via SMTP
 type SMTPProvider struct {
 	host     string
@@ -19,14 +29,14 @@ type SMTPProvider struct {
 }
 
 // NewSMTPProvider creates a new SMTP email provider
-func NewSMTPProvider(config EmailConfig) (*SMTPProvider, error) {
+func NewSMTPProvider(config domain.EmailConfig) (*SMTPProvider, error) {
 	provider := &SMTPProvider{
-		host:     config.SMTPHost,
-		port:     config.SMTPPort,
-		user:     config.SMTPUser,
-		password: config.SMTPPassword,
-		useTLS:   config.SMTPTLS,
-		from:     fmt.Sprintf("%s <%s>", config.FromName, config.FromAddress),
+		host:     config.SMTP.Host,
+		port:     config.SMTP.Port,
+		user:     config.SMTP.Username,
+		password: config.SMTP.Password,
+		useTLS:   config.SMTP.TLSEnabled,
+		from:     formatAddress(config.FromName, config.FromAddress),
 	}
 
 	if err := provider.ValidateConfig(); err != nil {
@@ -44,6 +54,9 @@ func (p *SMTPProvider) ValidateConfig() error {
 	if p.port == 0 {
 		return fmt.Errorf("SMTP port is required")
 	}
+	if p.port < 0 || p.port > 65535 {
+		return fmt.Errorf("SMTP port %d is out of range", p.port)
+	}
 	if p.from == "" {
 		return fmt.Errorf("From address is required")
 	}
@@ -55,61 +68,41 @@ func (p *SMTPProvider) GetProviderName() string {
 	return "SMTP"
 }
 
-// SendEmail sends an email via SMTP
-func (p *SMTPProvider) SendEmail(ctx context.Context, params EmailParams) error {
-	// Build email message
-	message := p.buildMessage(params)
-
-	// Connect to SMTP server
-	addr := fmt.Sprintf("%s:%d", p.host, p.port)
-
-	// Determine authentication method
-	var auth smtp.Auth
-	if p.user != "" && p.password != "" {
-		auth = smtp.PlainAuth("", p.user, p.password, p.host)
-	}
-
-	// Recipients
-	to := append(params.To, params.CC...)
-	to = append(to, params.BCC...)
-
-	// Send email
-	if p.useTLS {
-		return p.sendWithTLS(addr, auth, params.From, to, message)
+// ValidateConnection connects to the SMTP server and authenticates without sending anything
+func (p *SMTPProvider) ValidateConnection(ctx context.Context) error {
+	client, err := p.connect(ctx)
+	if err != nil {
+		return err
 	}
-	return smtp.SendMail(addr, auth, params.From, to, []byte(message))
+	return client.Quit()
 }
 
-// sendWithTLS sends email with TLS encryption
-func (p *SMTPProvider) sendWithTLS(addr string, auth smtp.Auth, from string, to []string, message string) error {
-	// Create TLS config
-	tlsConfig := &tls.Config{
-		ServerName: p.host,
+// Send sends an email via SMTP
+func (p *SMTPProvider) Send(ctx context.Context, params EmailParams) error {
+	if params.From == "" {
+		params.From = p.from
 	}
 
-	// Connect to server
-	conn, err := tls.Dial("tcp", addr, tlsConfig)
+	// The envelope sender is the bare address of the From header
+	sender, err := mail.ParseAddress(params.From)
 	if err != nil {
-		return fmt.Errorf("failed to connect to SMTP server: %w", err)
+		return fmt.Errorf("invalid From address: %w", err)
 	}
-	defer conn.Close()
 
-	// Create SMTP client
-	client, err := smtp.NewClient(conn, p.host)
-	if err != nil {
-		return fmt.Errorf("failed to create SMTP client: %w", err)
+	// Recipients
+	to := append(append(append([]string{}, params.To...), params.CC...), params.BCC...)
+	if len(to) == 0 {
+		return fmt.Errorf("at least one recipient is required")
 	}
-	defer client.Quit()
 
-	// Authenticate
-	if auth != nil {
-		if err := client.Auth(auth); err != nil {
-			return fmt.Errorf("SMTP authentication failed: %w", err)
-		}
+	client, err := p.connect(ctx)
+	if err != nil {
+		return err
 	}
+	defer client.Close()
 
 	// Set sender
-	if err := client.Mail(from); err != nil {
+	if err := client.Mail(sender.Address); err != nil {
 		return fmt.Errorf("failed to set sender: %w", err)
 	}
 
@@ -126,16 +119,68 @@ func (p *SMTPProvider) sendWithTLS(addr string, auth smtp.Auth, from string, to
 		return fmt.Errorf("failed to get data writer: %w", err)
 	}
 
-	_, err = writer.Write([]byte(message))
-	if err != nil {
+	if _, err := writer.Write([]byte(buildMIMEMessage(params))); err != nil {
 		return fmt.Errorf("failed to write message: %w", err)
 	}
 
-	return writer.Close()
+	if err := writer.Close(); err != nil {
+		return fmt.Errorf("failed to send message: %w", err)
+	}
+
+	return client.Quit()
 }
 
-// buildMessage constructs the email message in MIME format
-func (p *SMTPProvider) buildMessage(params EmailParams) string {
+// connect opens an authenticated SMTP session. With TLS enabled, port 465 uses implicit TLS and
+// every other port upgrades the plaintext connection with STARTTLS.
+func (p *SMTPProvider) connect(ctx context.Context) (*smtp.Client, error) {
+	addr := net.JoinHostPort(p.host, strconv.Itoa(p.port))
+	tlsConfig := &tls.Config{ServerName: p.host}
+
+	var conn net.Conn
+	var err error
+	if p.useTLS && p.port == smtpImplicitTLSPort {
+		dialer := &tls.Dialer{Config: tlsConfig}
+		conn, err = dialer.DialContext(ctx, "tcp", addr)
+	} else {
+		var dialer net.Dialer
+		conn, err = dialer.DialContext(ctx, "tcp", addr)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to SMTP server: %w", err)
+	}
+
+	// Bound the whole SMTP conversation by the context deadline
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+
+	client, err := smtp.NewClient(conn, p.host)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to create SMTP client: %w", err)
+	}
+
+	if p.useTLS && p.port != smtpImplicitTLSPort {
+		if err := client.StartTLS(tlsConfig); err != nil {
+			client.Close()
+			return nil, fmt.Errorf("failed to start TLS: %w", err)
+		}
+	}
+
+	// Authenticate
+	if p.user != "" && p.password != "" {
+		if err := client.Auth(smtp.PlainAuth("", p.user, p.password, p.host)); err != nil {
+			client.Close()
+			return nil, fmt.Errorf("SMTP authentication failed: %w", err)
+		}
+	}
+
+	return client, nil
+}
+
+// buildMIMEMessage constructs the email message in MIME format. A message with an HTML body is
+// sent as multipart/alternative with the plain-text body as the fallback part.
+func buildMIMEMessage(params EmailParams) string {
 	var builder strings.Builder
 
 	// Headers
@@ -150,7 +195,8 @@ func (p *SMTPProvider) buildMessage(params EmailParams) string {
 		builder.WriteString(fmt.Sprintf("Reply-To: %s\r\n", params.ReplyTo))
 	}
 
-	builder.WriteString(fmt.Sprintf("Subject: %s\r\n", params.Subject))
+	builder.WriteString(fmt.Sprintf("Subject: %s\r\n", mime.QEncoding.Encode("UTF-8", params.Subject)))
+	builder.WriteString(fmt.Sprintf("Date: %s\r\n", time.Now().Format(time.RFC1123Z)))
 	builder.WriteString("MIME-Version: 1.0\r\n")
 
 	// Custom headers
@@ -160,24 +206,24 @@ func (p *SMTPProvider) buildMessage(params EmailParams) string {
 
 	// Multipart message (text + HTML)
 	if params.HTMLBody != "" {
-		builder.WriteString("Content-Type: multipart/alternative; boundary=\"boundary-aim\"\r\n\r\n")
-
-		// Plain text part
-		builder.WriteString("--boundary-aim\r\n")
-		builder.WriteString("Content-Type: text/plain; charset=UTF-8\r\n\r\n")
-		builder.WriteString(params.TextBody)
-		builder.WriteString("\r\n\r\n")
-
-		// HTML part
-		builder.WriteString("--boundary-aim\r\n")
-		builder.WriteString("Content-Type: text/html; charset=UTF-8\r\n\r\n")
-		builder.WriteString(params.HTMLBody)
-		builder.WriteString("\r\n\r\n")
+		boundary := fmt.Sprintf("aim-%d", time.Now().UnixNano())
+		builder.WriteString(fmt.Sprintf("Content-Type: multipart/alternative; boundary=\"%s\"\r\n\r\n", boundary))
+
+		// Parts, least preferred first
+		for _, part := range []struct{ contentType, body string }{
+			{"text/plain", params.TextBody},
+			{"text/html", params.HTMLBody},
+		} {
+			builder.WriteString(fmt.Sprintf("--%s\r\n", boundary))
+			builder.WriteString(fmt.Sprintf("Content-Type: %s; charset=\"UTF-8\"\r\n\r\n", part.contentType))
+			builder.WriteString(part.body)
+			builder.WriteString("\r\n")
+		}
 
-		builder.WriteString("--boundary-aim--\r\n")
+		builder.WriteString(fmt.Sprintf("--%s--\r\n", boundary))
 	} else {
 		// Plain text only
-		builder.WriteString("Content-Type: text/plain; charset=UTF-8\r\n\r\n")
+		builder.WriteString("Content-Type: text/plain; charset=\"UTF-8\"\r\n\r\n")
 		builder.WriteString(params.TextBody)
 		builder.WriteString("\r\n")
 	}
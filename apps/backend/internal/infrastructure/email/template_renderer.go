@@ -4,31 +4,72 @@ import (
 	"bytes"
 	"embed"
 	"fmt"
+	"html"
 	"html/template"
+	"io/fs"
+	"regexp"
+	"strings"
 	"sync"
+	texttemplate "text/template"
 
 	"github.com/opena2a/identity/backend/internal/domain"
 )
 
-//go:embed templates/*.html
+//go:embed templates
 var embeddedTemplates embed.FS
 
+// DefaultEmailLocale is the locale of the templates at the root of the templates directory.
+// Other locales live in subdirectories named after the locale (e.g. templates/es) and fall
+// back to the default locale for any template they do not translate.
+const DefaultEmailLocale = "en"
+
+// templateNames lists every template the renderer loads
+var templateNames = []domain.EmailTemplate{
+	domain.TemplateWelcome,
+	domain.TemplateUserApproved,
+	domain.TemplateUserRejected,
+	domain.TemplatePasswordReset,
+	domain.TemplateAgentRegistered,
+	domain.TemplateAgentVerified,
+	domain.TemplateVerificationReminder,
+	domain.TemplateVerificationFailed,
+	domain.TemplateAlertCritical,
+	domain.TemplateAlertWarning,
+	domain.TemplateAlertInfo,
+	domain.TemplateMCPServerRegistered,
+	domain.TemplateMCPServerExpiring,
+	domain.TemplateAPIKeyCreated,
+	domain.TemplateAPIKeyExpiring,
+	domain.TemplateAPIKeyRevoked,
+	domain.TemplateCapabilityRequestExpired,
+	domain.TemplateCapabilityRequestPending,
+}
+
 // TemplateRenderer renders email templates
 type TemplateRenderer struct {
-	templates map[domain.EmailTemplate]*emailTemplate
+	templates map[string]map[domain.EmailTemplate]*emailTemplate // By locale
 	mu        sync.RWMutex
 }
 
-// emailTemplate holds both subject and body templates
+// emailTemplate holds the subject, HTML body and plain-text body templates
 type emailTemplate struct {
-	subject *template.Template
+	subject *texttemplate.Template
 	body    *template.Template
+	entry   string                 // Template executed for the body: "layout" or the template name
+	text    *texttemplate.Template // nil derives the plain-text body from the HTML body
+}
+
+// RenderedEmail is a rendered email template
+type RenderedEmail struct {
+	Subject string
+	HTML    string
+	Text    string
 }
 
 // NewTemplateRenderer creates a new template renderer
 func NewTemplateRenderer(customTemplateDir string) (*TemplateRenderer, error) {
 	renderer := &TemplateRenderer{
-		templates: make(map[domain.EmailTemplate]*emailTemplate),
+		templates: make(map[string]map[domain.EmailTemplate]*emailTemplate),
 	}
 
 	// Load embedded templates by default
@@ -47,64 +88,116 @@ func NewTemplateRenderer(customTemplateDir string) (*TemplateRenderer, error) {
 	return renderer, nil
 }
 
-// loadEmbeddedTemplates loads templates from embedded filesystem
+// loadEmbeddedTemplates loads the default locale and every locale subdirectory from the
+// embedded filesystem
 func (r *TemplateRenderer) loadEmbeddedTemplates() error {
-	templateNames := []domain.EmailTemplate{
-		domain.TemplateWelcome,
-		domain.TemplateUserApproved,
-		domain.TemplateUserRejected,
-		domain.TemplatePasswordReset,
-		domain.TemplateAgentRegistered,
-		domain.TemplateAgentVerified,
-		domain.TemplateVerificationReminder,
-		domain.TemplateVerificationFailed,
-		domain.TemplateAlertCritical,
-		domain.TemplateAlertWarning,
-		domain.TemplateAlertInfo,
-		domain.TemplateMCPServerRegistered,
-		domain.TemplateMCPServerExpiring,
-		domain.TemplateAPIKeyCreated,
-		domain.TemplateAPIKeyExpiring,
-		domain.TemplateAPIKeyRevoked,
+	root, err := fs.Sub(embeddedTemplates, "templates")
+	if err != nil {
+		return err
+	}
+	if err := r.loadLocale(root, root, DefaultEmailLocale); err != nil {
+		return err
+	}
+
+	entries, err := fs.ReadDir(root, ".")
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		dir, err := fs.Sub(root, entry.Name())
+		if err != nil {
+			return err
+		}
+		if err := r.loadLocale(dir, root, normalizeLocale(entry.Name())); err != nil {
+			return fmt.Errorf("locale %s: %w", entry.Name(), err)
+		}
 	}
+	return nil
+}
 
+// loadLocale loads the templates of one locale from dir. Files missing from dir are taken from
+// root (the default locale); a translated locale only gets the templates it has a body for.
+func (r *TemplateRenderer) loadLocale(dir, root fs.FS, locale string) error {
+	isDefault := locale == DefaultEmailLocale
+	funcs := template.FuncMap{"locale": func() string { return locale }}
+
+	layout, ok := readTemplateFile(dir, "layout.html")
+	if !ok {
+		layout, _ = readTemplateFile(root, "layout.html")
+	}
+
+	templates := make(map[domain.EmailTemplate]*emailTemplate, len(templateNames))
 	for _, name := range templateNames {
-		bodyPath := fmt.Sprintf("templates/%s.html", name)
-		subjectPath := fmt.Sprintf("templates/%s.subject.txt", name)
+		file := string(name)
 
 		// Load body template
-		bodyContent, err := embeddedTemplates.ReadFile(bodyPath)
-		if err != nil {
+		bodyContent, ok := readTemplateFile(dir, file+".html")
+		if !ok {
+			if !isDefault {
+				continue
+			}
 			// Create placeholder template if not found
 			bodyContent = []byte(r.getDefaultTemplate(name))
 		}
 
-		bodyTmpl, err := template.New(string(name)).Parse(string(bodyContent))
-		if err != nil {
+		bodyTmpl := template.New(string(name)).Funcs(funcs)
+		if _, err := bodyTmpl.New("layout.html").Parse(string(layout)); err != nil {
+			return fmt.Errorf("failed to parse layout: %w", err)
+		}
+		if _, err := bodyTmpl.Parse(string(bodyContent)); err != nil {
 			return fmt.Errorf("failed to parse body template %s: %w", name, err)
 		}
+		entry := string(name)
+		if bodyTmpl.Lookup("content") != nil {
+			entry = "layout"
+		}
 
 		// Load subject template
-		subjectContent, err := embeddedTemplates.ReadFile(subjectPath)
-		if err != nil {
+		subjectContent, ok := readTemplateFile(dir, file+".subject.txt")
+		if !ok && !isDefault {
+			subjectContent, ok = readTemplateFile(root, file+".subject.txt")
+		}
+		if !ok {
 			// Use default subject if not found
 			subjectContent = []byte(r.getDefaultSubject(name))
 		}
-
-		subjectTmpl, err := template.New(string(name) + "_subject").Parse(string(subjectContent))
+		subjectTmpl, err := texttemplate.New(string(name) + "_subject").Parse(string(subjectContent))
 		if err != nil {
 			return fmt.Errorf("failed to parse subject template %s: %w", name, err)
 		}
 
-		r.templates[name] = &emailTemplate{
+		// Load plain-text template, if there is one
+		var textTmpl *texttemplate.Template
+		if textContent, ok := readTemplateFile(dir, file+".txt"); ok {
+			textTmpl, err = texttemplate.New(string(name) + "_text").Parse(string(textContent))
+			if err != nil {
+				return fmt.Errorf("failed to parse text template %s: %w", name, err)
+			}
+		}
+
+		templates[name] = &emailTemplate{
 			subject: subjectTmpl,
 			body:    bodyTmpl,
+			entry:   entry,
+			text:    textTmpl,
 		}
 	}
 
+	r.mu.Lock()
+	r.templates[locale] = templates
+	r.mu.Unlock()
 	return nil
 }
 
+// readTemplateFile reads a template file, reporting whether it exists
+func readTemplateFile(dir fs.FS, name string) ([]byte, bool) {
+	content, err := fs.ReadFile(dir, name)
+	return content, err == nil
+}
+
 // loadCustomTemplates loads templates from filesystem directory
 func (r *TemplateRenderer) loadCustomTemplates(dir string) error {
 	// This would load templates from a custom directory
@@ -112,31 +205,117 @@ func (r *TemplateRenderer) loadCustomTemplates(dir string) error {
 	return nil
 }
 
-// Render renders a template with the given data
-func (r *TemplateRenderer) Render(templateName domain.EmailTemplate, data interface{}) (subject, body string, err error) {
+// Render renders a template with the given data. The locale is taken from the Locale field of
+// domain.EmailTemplateData (or the "Locale" key of a map) and falls back to DefaultEmailLocale.
+func (r *TemplateRenderer) Render(templateName domain.EmailTemplate, data interface{}) (RenderedEmail, error) {
 	r.mu.RLock()
-	tmpl, ok := r.templates[templateName]
+	tmpl, ok := r.lookup(templateName, templateLocale(data))
 	r.mu.RUnlock()
 
 	if !ok {
-		return "", "", fmt.Errorf("template not found: %s", templateName)
+		return RenderedEmail{}, fmt.Errorf("template not found: %s", templateName)
 	}
 
-	// Render subject
+	// Render subject, on a single line so it cannot inject headers
 	var subjectBuf bytes.Buffer
 	if err := tmpl.subject.Execute(&subjectBuf, data); err != nil {
-		return "", "", fmt.Errorf("failed to render subject: %w", err)
+		return RenderedEmail{}, fmt.Errorf("failed to render subject: %w", err)
 	}
-	subject = subjectBuf.String()
+	subject := strings.Join(strings.Fields(subjectBuf.String()), " ")
 
 	// Render body
 	var bodyBuf bytes.Buffer
-	if err := tmpl.body.Execute(&bodyBuf, data); err != nil {
-		return "", "", fmt.Errorf("failed to render body: %w", err)
+	if err := tmpl.body.ExecuteTemplate(&bodyBuf, tmpl.entry, data); err != nil {
+		return RenderedEmail{}, fmt.Errorf("failed to render body: %w", err)
+	}
+	body := bodyBuf.String()
+
+	// Render plain-text fallback
+	text := htmlToText(body)
+	if tmpl.text != nil {
+		var textBuf bytes.Buffer
+		if err := tmpl.text.Execute(&textBuf, data); err != nil {
+			return RenderedEmail{}, fmt.Errorf("failed to render text body: %w", err)
+		}
+		text = strings.TrimSpace(textBuf.String()) + "\n"
 	}
-	body = bodyBuf.String()
 
-	return subject, body, nil
+	return RenderedEmail{Subject: subject, HTML: body, Text: text}, nil
+}
+
+// lookup finds a template in the first requested locale that has it, trying each tag and then
+// its base language ("es-MX", then "es"), and falls back to the default locale
+func (r *TemplateRenderer) lookup(name domain.EmailTemplate, requested string) (*emailTemplate, bool) {
+	for _, tag := range strings.Split(requested, ",") {
+		// Accept-Language style lists are tried in the order given; quality values are ignored
+		tag, _, _ = strings.Cut(tag, ";")
+		tag = normalizeLocale(tag)
+		if tag == "" {
+			continue
+		}
+		base, _, _ := strings.Cut(tag, "-")
+		for _, locale := range []string{tag, base} {
+			if tmpl, ok := r.templates[locale][name]; ok {
+				return tmpl, true
+			}
+		}
+	}
+	tmpl, ok := r.templates[DefaultEmailLocale][name]
+	return tmpl, ok
+}
+
+// templateLocale extracts the requested locale from template data
+func templateLocale(data interface{}) string {
+	switch d := data.(type) {
+	case domain.EmailTemplateData:
+		return d.Locale
+	case *domain.EmailTemplateData:
+		if d != nil {
+			return d.Locale
+		}
+	case map[string]interface{}:
+		locale, _ := d["Locale"].(string)
+		return locale
+	}
+	return ""
+}
+
+// normalizeLocale lowercases a locale tag and uses "-" as its separator
+func normalizeLocale(locale string) string {
+	return strings.ReplaceAll(strings.ToLower(strings.TrimSpace(locale)), "_", "-")
+}
+
+var (
+	htmlHeadPattern  = regexp.MustCompile(`(?is)<head.*?</head>`)
+	htmlLinkPattern  = regexp.MustCompile(`(?is)<a\s[^>]*href="([^"]*)"[^>]*>(.*?)</a>`)
+	htmlBreakPattern = regexp.MustCompile(`(?i)<br\s*/?>|</(p|div|h[1-6]|li|tr)>`)
+	htmlItemPattern  = regexp.MustCompile(`(?i)<li[^>]*>`)
+	htmlTagPattern   = regexp.MustCompile(`(?s)<[^>]*>`)
+)
+
+// htmlToText derives a plain-text body from an HTML body, for templates without a .txt file
+func htmlToText(body string) string {
+	body = htmlHeadPattern.ReplaceAllString(body, "")
+	body = htmlLinkPattern.ReplaceAllString(body, "$2 ($1)")
+	body = htmlItemPattern.ReplaceAllString(body, "- ")
+	body = htmlBreakPattern.ReplaceAllString(body, "\n")
+	body = html.UnescapeString(htmlTagPattern.ReplaceAllString(body, ""))
+
+	var lines []string
+	blank := true
+	for _, line := range strings.Split(body, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			if !blank {
+				lines = append(lines, "")
+			}
+			blank = true
+			continue
+		}
+		lines = append(lines, line)
+		blank = false
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n")) + "\n"
 }
 
 // getDefaultTemplate returns a simple default template if file doesn't exist
@@ -178,22 +357,24 @@ func (r *TemplateRenderer) getDefaultTemplate(name domain.EmailTemplate) string
 // getDefaultSubject returns a simple default subject if file doesn't exist
 func (r *TemplateRenderer) getDefaultSubject(name domain.EmailTemplate) string {
 	subjects := map[domain.EmailTemplate]string{
-		domain.TemplateWelcome:              "Welcome to Agent Identity Management",
-		domain.TemplateUserApproved:         "Your account has been approved",
-		domain.TemplateUserRejected:         "Account registration update",
-		domain.TemplatePasswordReset:        "Reset your password",
-		domain.TemplateAgentRegistered:      "Agent registered successfully",
-		domain.TemplateAgentVerified:        "Agent verified successfully",
-		domain.TemplateVerificationReminder: "Agent verification required",
-		domain.TemplateVerificationFailed:   "Agent verification failed",
-		domain.TemplateAlertCritical:        "🚨 Critical Alert",
-		domain.TemplateAlertWarning:         "⚠️ Warning Alert",
-		domain.TemplateAlertInfo:            "ℹ️ Information Alert",
-		domain.TemplateMCPServerRegistered:  "MCP Server registered successfully",
-		domain.TemplateMCPServerExpiring:    "MCP Server certificate expiring soon",
-		domain.TemplateAPIKeyCreated:        "New API key created",
-		domain.TemplateAPIKeyExpiring:       "API key expiring soon",
-		domain.TemplateAPIKeyRevoked:        "API key revoked",
+		domain.TemplateWelcome:                  "Welcome to Agent Identity Management",
+		domain.TemplateUserApproved:             "Your account has been approved",
+		domain.TemplateUserRejected:             "Account registration update",
+		domain.TemplatePasswordReset:            "Reset your password",
+		domain.TemplateAgentRegistered:          "Agent registered successfully",
+		domain.TemplateAgentVerified:            "Agent verified successfully",
+		domain.TemplateVerificationReminder:     "Agent verification required",
+		domain.TemplateVerificationFailed:       "Agent verification failed",
+		domain.TemplateAlertCritical:            "🚨 Critical Alert",
+		domain.TemplateAlertWarning:             "⚠️ Warning Alert",
+		domain.TemplateAlertInfo:                "ℹ️ Information Alert",
+		domain.TemplateMCPServerRegistered:      "MCP Server registered successfully",
+		domain.TemplateMCPServerExpiring:        "MCP Server certificate expiring soon",
+		domain.TemplateAPIKeyCreated:            "New API key created",
+		domain.TemplateAPIKeyExpiring:           "API key expiring soon",
+		domain.TemplateAPIKeyRevoked:            "API key revoked",
+		domain.TemplateCapabilityRequestExpired: "Capability request expired",
+		domain.TemplateCapabilityRequestPending: "Capability request awaiting review",
 	}
 
 	if subject, ok := subjects[name]; ok {
@@ -0,0 +1,145 @@
+package email
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/opena2a/identity/backend/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestRenderer(t *testing.T) *TemplateRenderer {
+	t.Helper()
+	renderer, err := NewTemplateRenderer("")
+	require.NoError(t, err)
+	return renderer
+}
+
+func brandedTemplateData(locale string) domain.EmailTemplateData {
+	return domain.EmailTemplateData{
+		UserName:            "Ada Lovelace",
+		UserEmail:           "ada@example.com",
+		DashboardURL:        "https://aim.example.com",
+		SupportEmail:        "support@example.com",
+		Timestamp:           time.Date(2025, 3, 4, 15, 30, 0, 0, time.UTC),
+		ExpiresAt:           time.Date(2025, 3, 5, 15, 30, 0, 0, time.UTC),
+		Locale:              locale,
+		OrganizationName:    "Acme Robotics",
+		OrganizationLogoURL: "https://cdn.example.com/acme.png",
+		AgentName:           "billing-agent",
+		CustomData: map[string]interface{}{
+			"LoginURL":  "https://aim.example.com/auth/login",
+			"Role":      "manager",
+			"ResetLink": "https://aim.example.com/auth/reset-password?token=abc123",
+		},
+	}
+}
+
+func TestTemplateRenderer_RendersLocalizedBrandedTemplates(t *testing.T) {
+	renderer := newTestRenderer(t)
+
+	// Each template with a value only it renders, and a phrase per locale
+	cases := []struct {
+		template domain.EmailTemplate
+		values   []string
+		phrases  map[string]string
+	}{
+		{domain.TemplateWelcome, nil, map[string]string{
+			"en": "Thank you for registering", "es": "Gracias por registrarte"}},
+		{domain.TemplateUserApproved, []string{"ada@example.com", "manager", "https://aim.example.com/auth/login"}, map[string]string{
+			"en": "has been approved", "es": "ha sido aprobada"}},
+		{domain.TemplatePasswordReset, []string{"https://aim.example.com/auth/reset-password?token=abc123"}, map[string]string{
+			"en": "reset your", "es": "restablecer tu contraseña"}},
+	}
+
+	for _, tc := range cases {
+		for _, locale := range []string{"en", "es"} {
+			t.Run(string(tc.template)+"/"+locale, func(t *testing.T) {
+				rendered, err := renderer.Render(tc.template, brandedTemplateData(locale))
+				require.NoError(t, err)
+
+				assert.NotEmpty(t, rendered.Subject)
+				assert.NotContains(t, rendered.Subject, "\n")
+				assert.Contains(t, rendered.HTML, `<html lang="`+locale+`">`)
+				assert.Contains(t, rendered.HTML, `<img src="https://cdn.example.com/acme.png" alt="Acme Robotics">`)
+
+				for _, body := range []string{rendered.HTML, rendered.Text} {
+					assert.Contains(t, body, "Ada Lovelace")
+					assert.Contains(t, body, "Acme Robotics")
+					assert.Contains(t, body, tc.phrases[locale])
+					for _, value := range tc.values {
+						assert.Contains(t, strings.ReplaceAll(body, "&amp;", "&"), value)
+					}
+					assert.NotContains(t, body, "<no value>")
+					assert.NotContains(t, body, "{{")
+				}
+				assert.NotContains(t, rendered.Text, "<")
+			})
+		}
+	}
+}
+
+func TestTemplateRenderer_LocalizedSubjects(t *testing.T) {
+	renderer := newTestRenderer(t)
+
+	english, err := renderer.Render(domain.TemplateUserApproved, brandedTemplateData("en"))
+	require.NoError(t, err)
+	spanish, err := renderer.Render(domain.TemplateUserApproved, brandedTemplateData("es"))
+	require.NoError(t, err)
+	assert.Equal(t, "Your AIM Account Has Been Approved!", english.Subject)
+	assert.Equal(t, "¡Tu cuenta de AIM ha sido aprobada!", spanish.Subject)
+}
+
+func TestTemplateRenderer_LocaleFallback(t *testing.T) {
+	renderer := newTestRenderer(t)
+
+	for locale, phrase := range map[string]string{
+		"":                        "Thank you for registering",
+		"fr":                      "Thank you for registering", // No French templates
+		"es-MX":                   "Gracias por registrarte",   // Region falls back to the language
+		"es_ES":                   "Gracias por registrarte",
+		"fr-CA,es;q=0.8,en;q=0.5": "Gracias por registrarte", // Accept-Language list
+	} {
+		rendered, err := renderer.Render(domain.TemplateWelcome, brandedTemplateData(locale))
+		require.NoError(t, err, locale)
+		assert.Contains(t, rendered.HTML, phrase, locale)
+	}
+
+	// Templates without a translation use the default locale
+	rendered, err := renderer.Render(domain.TemplateCapabilityRequestPending, domain.EmailTemplateData{
+		Locale: "es", AgentName: "billing-agent", CustomData: map[string]interface{}{},
+	})
+	require.NoError(t, err)
+	assert.Contains(t, rendered.Subject, "billing-agent")
+	assert.NotEmpty(t, rendered.Text)
+}
+
+func TestTemplateRenderer_WithoutBrandingUsesProductName(t *testing.T) {
+	renderer := newTestRenderer(t)
+
+	rendered, err := renderer.Render(domain.TemplateWelcome, domain.EmailTemplateData{UserName: "Ada"})
+	require.NoError(t, err)
+	assert.Contains(t, rendered.HTML, "<h1>Agent Identity Management</h1>")
+	assert.NotContains(t, rendered.HTML, "<img")
+}
+
+func TestTemplateRenderer_EscapesHTMLButNotText(t *testing.T) {
+	renderer := newTestRenderer(t)
+	data := brandedTemplateData("en")
+	data.UserName = `<script>alert("x")</script>`
+
+	rendered, err := renderer.Render(domain.TemplateWelcome, data)
+	require.NoError(t, err)
+	assert.NotContains(t, rendered.HTML, "<script>")
+	assert.Contains(t, rendered.Text, `<script>alert("x")</script>`)
+}
+
+func TestHTMLToText(t *testing.T) {
+	text := htmlToText(`<html><head><style>p { color: red; }</style></head><body>
+<h2>Title</h2><p>Hello &amp; welcome</p><ul><li>One</li><li>Two</li></ul>
+<a href="https://example.com/x" class="cta-button">Open</a></body></html>`)
+
+	assert.Equal(t, "Title\nHello & welcome\n- One\n- Two\n\nOpen (https://example.com/x)\n", text)
+}
@@ -0,0 +1,74 @@
+package logging
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+)
+
+type contextKey string
+
+const (
+	// RequestIDKey is the context key holding the request ID for the current request
+	RequestIDKey contextKey = "request_id"
+	// LoggerKey is the context key holding the request-scoped logger
+	LoggerKey contextKey = "logger"
+)
+
+// New creates a JSON logger writing to w. LOG_LEVEL (debug, info, warn, error)
+// sets the minimum level and defaults to info.
+func New(w io.Writer) *slog.Logger {
+	return slog.New(slog.NewJSONHandler(w, &slog.HandlerOptions{
+		Level: levelFromEnv(os.Getenv("LOG_LEVEL")),
+	}))
+}
+
+// Init installs the JSON logger as the process-wide default so that slog
+// calls and the standard log package both emit structured output.
+func Init() *slog.Logger {
+	logger := New(os.Stdout)
+	slog.SetDefault(logger)
+	return logger
+}
+
+// FromContext returns the request-scoped logger stored in ctx, falling back to
+// the default logger when none is present (background jobs, tests).
+func FromContext(ctx context.Context) *slog.Logger {
+	if ctx != nil {
+		if logger, ok := ctx.Value(LoggerKey).(*slog.Logger); ok && logger != nil {
+			return logger
+		}
+	}
+	return slog.Default()
+}
+
+// RequestIDFromContext returns the request ID stored in ctx, or an empty string
+func RequestIDFromContext(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	requestID, _ := ctx.Value(RequestIDKey).(string)
+	return requestID
+}
+
+// WithRequestID returns a copy of ctx carrying the request ID and a logger
+// annotated with it.
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	ctx = context.WithValue(ctx, RequestIDKey, requestID)
+	return context.WithValue(ctx, LoggerKey, slog.Default().With("request_id", requestID))
+}
+
+func levelFromEnv(value string) slog.Level {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "debug":
+		return slog.LevelDebug
+	case "warn", "warning":
+		return slog.LevelWarn
+	case "error":
+		return slog.LevelError
+	default:
+		return slog.LevelInfo
+	}
+}
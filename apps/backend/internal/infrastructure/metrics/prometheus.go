@@ -29,6 +29,9 @@ func init() {
 		mcpAttestationsTotal,
 		verificationEventsTotal,
 		verificationDuration,
+		verificationsTotal,
+		capabilityViolationsTotal,
+		policySimulationsTotal,
 		complianceChecksTotal,
 		complianceViolationsTotal,
 		databaseConnectionsActive,
@@ -88,7 +91,7 @@ var (
 		prometheus.HistogramOpts{
 			Name:    "aim_trust_score_distribution",
 			Help:    "Distribution of trust scores across all agents",
-			Buckets: []float64{0, 10, 20, 30, 40, 50, 60, 70, 80, 90, 100},
+			Buckets: []float64{0.1, 0.2, 0.3, 0.4, 0.5, 0.6, 0.7, 0.8, 0.9, 1.0},
 		},
 	)
 
@@ -142,6 +145,30 @@ var (
 		[]string{"event_type"},
 	)
 
+	verificationsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "aim_verifications_total",
+			Help: "Total number of agent action verifications by outcome",
+		},
+		[]string{"result", "protocol"},
+	)
+
+	capabilityViolationsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "aim_capability_violations_total",
+			Help: "Total number of capability violations",
+		},
+		[]string{"severity"},
+	)
+
+	policySimulationsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "aim_policy_simulations_total",
+			Help: "Total number of actions simulate-mode security policies would have blocked",
+		},
+		[]string{"policy_type"},
+	)
+
 	// Compliance metrics
 	complianceChecksTotal = prometheus.NewCounterVec(
 		prometheus.CounterOpts{
@@ -308,6 +335,21 @@ func ObserveVerificationDuration(eventType string, duration float64) {
 	verificationDuration.WithLabelValues(eventType).Observe(duration)
 }
 
+// RecordVerification records the outcome (approved, denied, pending) of an agent action verification
+func RecordVerification(result, protocol string) {
+	verificationsTotal.WithLabelValues(result, protocol).Inc()
+}
+
+// RecordCapabilityViolation records a capability violation
+func RecordCapabilityViolation(severity string) {
+	capabilityViolationsTotal.WithLabelValues(severity).Inc()
+}
+
+// RecordPolicySimulation records an action a simulate-mode policy would have blocked
+func RecordPolicySimulation(policyType string) {
+	policySimulationsTotal.WithLabelValues(policyType).Inc()
+}
+
 // RecordComplianceCheck records a compliance check
 func RecordComplianceCheck(checkType, status string) {
 	complianceChecksTotal.WithLabelValues(checkType, status).Inc()
@@ -0,0 +1,35 @@
+package metrics
+
+import (
+	"io"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPrometheusHandler_ExportsVerificationMetrics(t *testing.T) {
+	RecordVerification("denied", "A2A")
+	RecordVerification("approved", "MCP")
+	RecordCapabilityViolation("high")
+	UpdateTrustScore("agent-1", "report-agent", 0.72)
+
+	app := fiber.New()
+	app.Get("/metrics", PrometheusHandler())
+
+	resp, err := app.Test(httptest.NewRequest("GET", "/metrics", nil))
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	output := string(body)
+
+	assert.Contains(t, output, `aim_verifications_total{protocol="A2A",result="denied"} 1`)
+	assert.Contains(t, output, `aim_verifications_total{protocol="MCP",result="approved"} 1`)
+	assert.Contains(t, output, `aim_capability_violations_total{severity="high"} 1`)
+	assert.Contains(t, output, `aim_trust_score{agent_id="agent-1",agent_name="report-agent"} 0.72`)
+	assert.Contains(t, output, `aim_trust_score_distribution_bucket{le="0.8"} 1`)
+}
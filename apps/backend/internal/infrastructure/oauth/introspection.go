@@ -0,0 +1,75 @@
+package oauth
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/opena2a/identity/backend/internal/domain"
+)
+
+// Maximum size of an introspection response
+const maxIntrospectionResponseSize = 1 << 20
+
+// TokenIntrospector checks OAuth access tokens against an RFC 7662 introspection endpoint
+type TokenIntrospector struct {
+	endpoint     string
+	clientID     string
+	clientSecret string
+	httpClient   *http.Client
+}
+
+// NewTokenIntrospector creates a token introspector. When clientID is set, requests authenticate
+// to the endpoint with HTTP Basic client credentials.
+func NewTokenIntrospector(endpoint, clientID, clientSecret string, timeout time.Duration) *TokenIntrospector {
+	return &TokenIntrospector{
+		endpoint:     endpoint,
+		clientID:     clientID,
+		clientSecret: clientSecret,
+		httpClient:   &http.Client{Timeout: timeout},
+	}
+}
+
+// Introspect asks the authorization server whether token is an active access token. An inactive
+// token is not an error; errors mean the server could not be asked or gave no usable answer.
+func (i *TokenIntrospector) Introspect(ctx context.Context, token string) (*domain.TokenIntrospection, error) {
+	data := url.Values{}
+	data.Set("token", token)
+	data.Set("token_type_hint", "access_token")
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, i.endpoint, strings.NewReader(data.Encode()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if i.clientID != "" {
+		// RFC 6749 section 2.3.1: client credentials are form-encoded before Basic encoding
+		req.SetBasicAuth(url.QueryEscape(i.clientID), url.QueryEscape(i.clientSecret))
+	}
+
+	resp, err := i.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to introspect token: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxIntrospectionResponseSize))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read introspection response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("introspection endpoint returned status %d: %s", resp.StatusCode, string(body))
+	}
+
+	var introspection domain.TokenIntrospection
+	if err := json.Unmarshal(body, &introspection); err != nil {
+		return nil, fmt.Errorf("failed to decode introspection response: %w", err)
+	}
+	return &introspection, nil
+}
@@ -0,0 +1,95 @@
+package repository
+
+import (
+	"database/sql"
+	"encoding/json"
+
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/domain"
+)
+
+// AgentBaselineRepository implements domain.AgentBaselineRepository
+type AgentBaselineRepository struct {
+	db *sql.DB
+}
+
+// NewAgentBaselineRepository creates a new agent baseline repository
+func NewAgentBaselineRepository(db *sql.DB) *AgentBaselineRepository {
+	return &AgentBaselineRepository{db: db}
+}
+
+// Upsert stores the baseline, replacing any previous snapshot for the agent
+func (r *AgentBaselineRepository) Upsert(baseline *domain.AgentBaseline) error {
+	query := `
+		INSERT INTO agent_baselines (agent_id, organization_id, capabilities, talks_to, key_algorithm, captured_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (agent_id) DO UPDATE SET
+			capabilities = EXCLUDED.capabilities,
+			talks_to = EXCLUDED.talks_to,
+			key_algorithm = EXCLUDED.key_algorithm,
+			captured_at = EXCLUDED.captured_at
+	`
+
+	capabilitiesJSON, err := json.Marshal(nonNilStrings(baseline.Capabilities))
+	if err != nil {
+		return err
+	}
+	talksToJSON, err := json.Marshal(nonNilStrings(baseline.TalksTo))
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.Exec(query,
+		baseline.AgentID,
+		baseline.OrganizationID,
+		capabilitiesJSON,
+		talksToJSON,
+		baseline.KeyAlgorithm,
+		baseline.CapturedAt,
+	)
+	return err
+}
+
+// GetByAgentID returns the agent's baseline, or nil if none has been captured
+func (r *AgentBaselineRepository) GetByAgentID(agentID uuid.UUID) (*domain.AgentBaseline, error) {
+	query := `
+		SELECT agent_id, organization_id, capabilities, talks_to, key_algorithm, captured_at
+		FROM agent_baselines
+		WHERE agent_id = $1
+	`
+
+	baseline := &domain.AgentBaseline{}
+	var capabilitiesJSON, talksToJSON []byte
+
+	err := r.db.QueryRow(query, agentID).Scan(
+		&baseline.AgentID,
+		&baseline.OrganizationID,
+		&capabilitiesJSON,
+		&talksToJSON,
+		&baseline.KeyAlgorithm,
+		&baseline.CapturedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(capabilitiesJSON, &baseline.Capabilities); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(talksToJSON, &baseline.TalksTo); err != nil {
+		return nil, err
+	}
+
+	return baseline, nil
+}
+
+// nonNilStrings ensures nil slices are stored as empty JSON arrays
+func nonNilStrings(values []string) []string {
+	if values == nil {
+		return []string{}
+	}
+	return values
+}
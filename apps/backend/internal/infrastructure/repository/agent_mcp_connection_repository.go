@@ -124,6 +124,78 @@ func (r *AgentMCPConnectionRepository) ListByAgent(ctx context.Context, agentID
 	return connections, nil
 }
 
+// ListByOrganization lists all active connections of the organization's agents in one query
+func (r *AgentMCPConnectionRepository) ListByOrganization(ctx context.Context, orgID uuid.UUID) ([]*domain.AgentMCPConnection, error) {
+	query := `
+		SELECT c.id, c.agent_id, c.mcp_server_id, c.detection_id, c.connection_type,
+		       c.first_connected_at, c.last_attested_at, c.attestation_count, c.is_active,
+		       c.created_at, c.updated_at
+		FROM agent_mcp_connections c
+		JOIN agents a ON a.id = c.agent_id
+		WHERE a.organization_id = $1 AND a.deleted_at IS NULL AND c.is_active = true
+		ORDER BY c.first_connected_at
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, orgID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list connections for organization: %w", err)
+	}
+	defer rows.Close()
+
+	var connections []*domain.AgentMCPConnection
+	for rows.Next() {
+		connection := &domain.AgentMCPConnection{}
+		var connectionType string
+		if err := rows.Scan(
+			&connection.ID, &connection.AgentID, &connection.MCPServerID, &connection.DetectionID, &connectionType,
+			&connection.FirstConnectedAt, &connection.LastAttestedAt, &connection.AttestationCount, &connection.IsActive,
+			&connection.CreatedAt, &connection.UpdatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan connection: %w", err)
+		}
+		connection.ConnectionType = domain.ConnectionType(connectionType)
+		connections = append(connections, connection)
+	}
+
+	return connections, rows.Err()
+}
+
+// ListDetectionsByOrganization returns, for each agent and detected MCP server name in the
+// organization, the detection with the highest confidence
+func (r *AgentMCPConnectionRepository) ListDetectionsByOrganization(ctx context.Context, orgID uuid.UUID) ([]*domain.AgentMCPDetection, error) {
+	query := `
+		SELECT DISTINCT ON (d.agent_id, d.mcp_server_name)
+		       d.id, d.agent_id, d.mcp_server_name, d.detection_method, d.confidence_score,
+		       d.first_detected_at, d.last_seen_at
+		FROM agent_mcp_detections d
+		JOIN agents a ON a.id = d.agent_id
+		WHERE a.organization_id = $1 AND a.deleted_at IS NULL
+		ORDER BY d.agent_id, d.mcp_server_name, d.confidence_score DESC, d.last_seen_at DESC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, orgID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list detections for organization: %w", err)
+	}
+	defer rows.Close()
+
+	var detections []*domain.AgentMCPDetection
+	for rows.Next() {
+		detection := &domain.AgentMCPDetection{}
+		var method string
+		if err := rows.Scan(
+			&detection.ID, &detection.AgentID, &detection.MCPServerName, &method, &detection.ConfidenceScore,
+			&detection.FirstDetectedAt, &detection.LastSeenAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan detection: %w", err)
+		}
+		detection.DetectionMethod = domain.DetectionMethod(method)
+		detections = append(detections, detection)
+	}
+
+	return detections, rows.Err()
+}
+
 // UpdateAttestation updates the attestation count and timestamp
 func (r *AgentMCPConnectionRepository) UpdateAttestation(ctx context.Context, agentID, mcpServerID uuid.UUID) error {
 	query := `
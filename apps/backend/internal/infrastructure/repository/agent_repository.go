@@ -5,6 +5,7 @@ import (
 	"database/sql"
 	"encoding/json"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -21,8 +22,104 @@ func NewAgentRepository(db *sql.DB) *AgentRepository {
 	return &AgentRepository{db: db}
 }
 
-// Create creates a new agent
+// sqlExecer is satisfied by both *sql.DB and *sql.Tx
+type sqlExecer interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+}
+
+// Create creates a new agent. It returns a *domain.QuotaExceededError if the organization has no
+// room left under max_agents, checked under a lock on the organization row.
 func (r *AgentRepository) Create(agent *domain.Agent) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := checkAgentQuotaLocked(tx, agent.OrganizationID, 1); err != nil {
+		return err
+	}
+	if err := insertAgent(tx, agent); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// CreateBatch inserts agents in a single transaction and returns one error slot per agent.
+// Each insert runs under a savepoint so a failing row does not abort the rest of the batch.
+// When atomic is true, any failure rolls back the whole batch and the first error is returned.
+// The agents must belong to one organization; a batch that does not fit in its max_agents is
+// rejected as a whole with a *domain.QuotaExceededError.
+func (r *AgentRepository) CreateBatch(agents []*domain.Agent, atomic bool) ([]error, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if len(agents) > 0 {
+		if err := checkAgentQuotaLocked(tx, agents[0].OrganizationID, len(agents)); err != nil {
+			return nil, err
+		}
+	}
+
+	itemErrors := make([]error, len(agents))
+	for i, agent := range agents {
+		if _, err := tx.Exec("SAVEPOINT agent_batch_item"); err != nil {
+			return nil, fmt.Errorf("failed to create savepoint: %w", err)
+		}
+
+		if err := insertAgent(tx, agent); err != nil {
+			if atomic {
+				return nil, fmt.Errorf("failed to create agent %q: %w", agent.Name, err)
+			}
+			itemErrors[i] = err
+			if _, err := tx.Exec("ROLLBACK TO SAVEPOINT agent_batch_item"); err != nil {
+				return nil, fmt.Errorf("failed to rollback savepoint: %w", err)
+			}
+			continue
+		}
+
+		if _, err := tx.Exec("RELEASE SAVEPOINT agent_batch_item"); err != nil {
+			return nil, fmt.Errorf("failed to release savepoint: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return itemErrors, nil
+}
+
+// checkAgentQuotaLocked locks the organization and returns a *domain.QuotaExceededError if n more
+// agents would exceed its max_agents. Soft-deleted agents do not count against the limit.
+func checkAgentQuotaLocked(tx *sql.Tx, orgID uuid.UUID, n int) error {
+	org, err := lockOrganizationLimits(tx, orgID)
+	if err != nil {
+		return err
+	}
+	if org.MaxAgents <= 0 {
+		return nil // Unlimited, no need to count
+	}
+
+	var count int
+	err = tx.QueryRow(
+		`SELECT COUNT(*) FROM agents WHERE organization_id = $1 AND deleted_at IS NULL`,
+		orgID,
+	).Scan(&count)
+	if err != nil {
+		return fmt.Errorf("failed to count organization agents: %w", err)
+	}
+	return org.CheckAgentQuota(count, n)
+}
+
+// insertAgent applies creation defaults and inserts a single agent row
+func insertAgent(db sqlExecer, agent *domain.Agent) error {
 	query := `
 		INSERT INTO agents (id, organization_id, name, display_name, description, agent_type, status, version,
 		                    public_key, encrypted_private_key, key_algorithm, certificate_url, repository_url, documentation_url,
@@ -57,7 +154,7 @@ func (r *AgentRepository) Create(agent *domain.Agent) error {
 		return fmt.Errorf("failed to marshal capabilities: %w", err)
 	}
 
-	_, err = r.db.Exec(query,
+	_, err = db.Exec(query,
 		agent.ID,
 		agent.OrganizationID,
 		agent.Name,
@@ -83,15 +180,29 @@ func (r *AgentRepository) Create(agent *domain.Agent) error {
 	return err
 }
 
-// GetByID retrieves an agent by ID
+// GetByID retrieves an agent by ID, ignoring soft-deleted agents
 func (r *AgentRepository) GetByID(id uuid.UUID) (*domain.Agent, error) {
+	return r.getByID(id, false)
+}
+
+// GetByIDIncludingDeleted retrieves an agent by ID even if it was soft-deleted
+func (r *AgentRepository) GetByIDIncludingDeleted(id uuid.UUID) (*domain.Agent, error) {
+	return r.getByID(id, true)
+}
+
+func (r *AgentRepository) getByID(id uuid.UUID, includeDeleted bool) (*domain.Agent, error) {
 	query := `
 		SELECT id, organization_id, name, display_name, description, agent_type, status, version,
 		       public_key, encrypted_private_key, key_algorithm, certificate_url, repository_url, documentation_url,
-		       trust_score, verified_at, talks_to, capabilities, created_at, updated_at, created_by, last_active
+		       trust_score, verified_at, talks_to, capabilities, labels, created_at, updated_at, created_by, last_active,
+		       deleted_at, COALESCE(is_compromised, FALSE), compromised_at, compromised_by, compromise_reason,
+		       COALESCE(oauth_client_id, '')
 		FROM agents
 		WHERE id = $1
 	`
+	if !includeDeleted {
+		query += " AND deleted_at IS NULL"
+	}
 
 	agent := &domain.Agent{}
 	var publicKey sql.NullString
@@ -102,7 +213,10 @@ func (r *AgentRepository) GetByID(id uuid.UUID) (*domain.Agent, error) {
 	var documentationURL sql.NullString
 	var talksToJSON []byte
 	var capabilitiesJSON []byte
+	var labelsJSON []byte
 	var lastActive sql.NullTime
+	var compromisedBy uuid.NullUUID
+	var compromiseReason sql.NullString
 
 	err := r.db.QueryRow(query, id).Scan(
 		&agent.ID,
@@ -123,10 +237,17 @@ func (r *AgentRepository) GetByID(id uuid.UUID) (*domain.Agent, error) {
 		&agent.VerifiedAt,
 		&talksToJSON,
 		&capabilitiesJSON,
+		&labelsJSON,
 		&agent.CreatedAt,
 		&agent.UpdatedAt,
 		&agent.CreatedBy,
 		&lastActive,
+		&agent.DeletedAt,
+		&agent.IsCompromised,
+		&agent.CompromisedAt,
+		&compromisedBy,
+		&compromiseReason,
+		&agent.OAuthClientID,
 	)
 
 	if err == sql.ErrNoRows {
@@ -158,6 +279,12 @@ func (r *AgentRepository) GetByID(id uuid.UUID) (*domain.Agent, error) {
 	if lastActive.Valid {
 		agent.LastActive = &lastActive.Time
 	}
+	if compromisedBy.Valid {
+		agent.CompromisedBy = &compromisedBy.UUID
+	}
+	if compromiseReason.Valid {
+		agent.CompromiseReason = compromiseReason.String
+	}
 
 	// Unmarshal talks_to from JSONB
 	if len(talksToJSON) > 0 {
@@ -173,6 +300,10 @@ func (r *AgentRepository) GetByID(id uuid.UUID) (*domain.Agent, error) {
 		}
 	}
 
+	if err := unmarshalAgentLabels(labelsJSON, agent); err != nil {
+		return nil, err
+	}
+
 	return agent, nil
 }
 
@@ -181,9 +312,9 @@ func (r *AgentRepository) GetByOrganization(orgID uuid.UUID) ([]*domain.Agent, e
 	query := `
 		SELECT id, organization_id, name, display_name, description, agent_type, status, version, public_key,
 		       certificate_url, repository_url, documentation_url, trust_score, verified_at,
-		       talks_to, created_at, updated_at, created_by
+		       talks_to, labels, created_at, updated_at, created_by, deleted_at
 		FROM agents
-		WHERE organization_id = $1
+		WHERE organization_id = $1 AND deleted_at IS NULL
 		ORDER BY created_at DESC
 	`
 
@@ -193,6 +324,126 @@ func (r *AgentRepository) GetByOrganization(orgID uuid.UUID) ([]*domain.Agent, e
 	}
 	defer rows.Close()
 
+	return scanAgentRows(rows)
+}
+
+// GetByOrganizationPaginated retrieves one page of agents in an organization
+// ordered by created_at DESC, id DESC. When after is set the page starts right
+// after that cursor (keyset pagination) and offset is ignored. The returned
+// total is the number of agents in the organization.
+func (r *AgentRepository) GetByOrganizationPaginated(orgID uuid.UUID, limit, offset int, after *domain.AgentCursor) ([]*domain.Agent, int, error) {
+	return r.Search(orgID, domain.AgentSearchFilter{}, limit, offset, after)
+}
+
+// CountByOrganization counts the organization's agents, excluding soft-deleted ones
+func (r *AgentRepository) CountByOrganization(orgID uuid.UUID) (int, error) {
+	var count int
+	err := r.db.QueryRow(
+		`SELECT COUNT(*) FROM agents WHERE organization_id = $1 AND deleted_at IS NULL`,
+		orgID,
+	).Scan(&count)
+	return count, err
+}
+
+// Search retrieves one page of agents in an organization matching filter,
+// paged like GetByOrganizationPaginated. The returned total is the number of
+// matching agents.
+func (r *AgentRepository) Search(orgID uuid.UUID, filter domain.AgentSearchFilter, limit, offset int, after *domain.AgentCursor) ([]*domain.Agent, int, error) {
+	where, args := buildAgentSearchWhere(orgID, filter)
+
+	var total int
+	if err := r.db.QueryRow(`SELECT COUNT(*) FROM agents WHERE `+where, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	if after != nil {
+		where += fmt.Sprintf(" AND (created_at, id) < ($%d, $%d)", len(args)+1, len(args)+2)
+		args = append(args, after.CreatedAt, after.ID)
+		offset = 0
+	}
+	query := fmt.Sprintf(`
+		SELECT id, organization_id, name, display_name, description, agent_type, status, version, public_key,
+		       certificate_url, repository_url, documentation_url, trust_score, verified_at,
+		       talks_to, labels, created_at, updated_at, created_by, deleted_at
+		FROM agents
+		WHERE %s
+		ORDER BY created_at DESC, id DESC
+		LIMIT $%d OFFSET $%d
+	`, where, len(args)+1, len(args)+2)
+	args = append(args, limit, offset)
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	agents, err := scanAgentRows(rows)
+	if err != nil {
+		return nil, 0, err
+	}
+	if agents == nil {
+		agents = []*domain.Agent{}
+	}
+	return agents, total, nil
+}
+
+// buildAgentSearchWhere builds the WHERE clause (without the keyword) and its
+// positional arguments for an agent search
+func buildAgentSearchWhere(orgID uuid.UUID, filter domain.AgentSearchFilter) (string, []interface{}) {
+	conditions := []string{"organization_id = $1"}
+	args := []interface{}{orgID}
+
+	if !filter.IncludeDeleted {
+		conditions = append(conditions, "deleted_at IS NULL")
+	}
+
+	addArg := func(value interface{}) string {
+		args = append(args, value)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	if filter.Status != "" {
+		conditions = append(conditions, "status = "+addArg(string(filter.Status)))
+	}
+	if filter.AgentType != "" {
+		conditions = append(conditions, "agent_type = "+addArg(string(filter.AgentType)))
+	}
+	if filter.MinTrust != nil {
+		conditions = append(conditions, "trust_score >= "+addArg(*filter.MinTrust))
+	}
+	if filter.Tag != "" {
+		var tagCondition string
+		if key, value, ok := strings.Cut(filter.Tag, ":"); ok {
+			tagCondition = "t.key = " + addArg(key) + " AND t.value = " + addArg(value)
+		} else {
+			tagCondition = "t.key = " + addArg(filter.Tag)
+		}
+		conditions = append(conditions, `EXISTS (
+			SELECT 1 FROM agent_tags at
+			INNER JOIN tags t ON t.id = at.tag_id
+			WHERE at.agent_id = agents.id AND `+tagCondition+`)`)
+	}
+	if len(filter.Labels) > 0 {
+		// Selectors are validated key:value strings, so marshalling cannot fail
+		selectorJSON, _ := json.Marshal(filter.Labels)
+		conditions = append(conditions, "labels @> "+addArg(string(selectorJSON))+"::jsonb")
+	}
+	if filter.Query != "" {
+		pattern := addArg("%" + escapeLikePattern(filter.Query) + "%")
+		conditions = append(conditions, "(name ILIKE "+pattern+" OR display_name ILIKE "+pattern+")")
+	}
+
+	return strings.Join(conditions, " AND "), args
+}
+
+// escapeLikePattern escapes LIKE wildcards so user input matches literally
+func escapeLikePattern(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s)
+}
+
+// scanAgentRows scans the agent list columns selected by GetByOrganization and Search
+func scanAgentRows(rows *sql.Rows) ([]*domain.Agent, error) {
 	var agents []*domain.Agent
 	for rows.Next() {
 		agent := &domain.Agent{}
@@ -201,6 +452,7 @@ func (r *AgentRepository) GetByOrganization(orgID uuid.UUID) ([]*domain.Agent, e
 		var repositoryURL sql.NullString
 		var documentationURL sql.NullString
 		var talksToJSON []byte
+		var labelsJSON []byte
 		err := rows.Scan(
 			&agent.ID,
 			&agent.OrganizationID,
@@ -217,9 +469,11 @@ func (r *AgentRepository) GetByOrganization(orgID uuid.UUID) ([]*domain.Agent, e
 			&agent.TrustScore,
 			&agent.VerifiedAt,
 			&talksToJSON,
+			&labelsJSON,
 			&agent.CreatedAt,
 			&agent.UpdatedAt,
 			&agent.CreatedBy,
+			&agent.DeletedAt,
 		)
 		if err != nil {
 			return nil, err
@@ -246,12 +500,76 @@ func (r *AgentRepository) GetByOrganization(orgID uuid.UUID) ([]*domain.Agent, e
 			}
 		}
 
+		if err := unmarshalAgentLabels(labelsJSON, agent); err != nil {
+			return nil, err
+		}
+
 		agents = append(agents, agent)
 	}
 
 	return agents, nil
 }
 
+// unmarshalAgentLabels decodes the labels JSONB column; agents without labels get an empty map
+func unmarshalAgentLabels(labelsJSON []byte, agent *domain.Agent) error {
+	agent.Labels = map[string]string{}
+	if len(labelsJSON) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(labelsJSON, &agent.Labels); err != nil {
+		return fmt.Errorf("failed to unmarshal labels: %w", err)
+	}
+	return nil
+}
+
+// UpdateLabels replaces an agent's labels
+func (r *AgentRepository) UpdateLabels(id uuid.UUID, labels map[string]string) error {
+	if labels == nil {
+		labels = map[string]string{}
+	}
+	labelsJSON, err := json.Marshal(labels)
+	if err != nil {
+		return fmt.Errorf("failed to marshal labels: %w", err)
+	}
+
+	result, err := r.db.Exec(
+		`UPDATE agents SET labels = $1, updated_at = $2 WHERE id = $3 AND deleted_at IS NULL`,
+		labelsJSON, time.Now(), id,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update agent labels: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return fmt.Errorf("agent not found")
+	}
+	return nil
+}
+
+// UpdateOAuthClientID sets the OAuth client an agent authenticates as; "" clears it
+func (r *AgentRepository) UpdateOAuthClientID(id uuid.UUID, clientID string) error {
+	result, err := r.db.Exec(
+		`UPDATE agents SET oauth_client_id = NULLIF($1, ''), updated_at = $2 WHERE id = $3 AND deleted_at IS NULL`,
+		clientID, time.Now(), id,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update agent OAuth client: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return fmt.Errorf("agent not found")
+	}
+	return nil
+}
+
 // Update updates an agent
 func (r *AgentRepository) Update(agent *domain.Agent) error {
 	query := `
@@ -300,7 +618,25 @@ func (r *AgentRepository) Update(agent *domain.Agent) error {
 	return err
 }
 
-// Delete deletes an agent
+// SoftDelete marks an agent as deleted, keeping the row for audit history
+func (r *AgentRepository) SoftDelete(id uuid.UUID) error {
+	query := `UPDATE agents SET deleted_at = $1, updated_at = $1 WHERE id = $2 AND deleted_at IS NULL`
+	result, err := r.db.Exec(query, time.Now(), id)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return fmt.Errorf("agent not found")
+	}
+	return nil
+}
+
+// Delete permanently removes an agent
 func (r *AgentRepository) Delete(id uuid.UUID) error {
 	query := `DELETE FROM agents WHERE id = $1`
 	_, err := r.db.Exec(query, id)
@@ -314,6 +650,7 @@ func (r *AgentRepository) List(limit, offset int) ([]*domain.Agent, error) {
 		       certificate_url, repository_url, documentation_url, trust_score, verified_at,
 		       talks_to, created_at, updated_at, created_by
 		FROM agents
+		WHERE deleted_at IS NULL
 		ORDER BY created_at DESC
 		LIMIT $1 OFFSET $2
 	`
@@ -405,6 +742,75 @@ func (r *AgentRepository) MarkAsCompromised(id uuid.UUID) error {
 	return err
 }
 
+// MarkCompromisedBy flags the agent as compromised by userID and suspends it, queueing messages
+// in the same transaction
+func (r *AgentRepository) MarkCompromisedBy(id, userID uuid.UUID, reason string, at time.Time, messages ...*domain.OutboxMessage) error {
+	query := `
+		UPDATE agents
+		SET is_compromised = TRUE, status = $1, compromised_at = $2, compromised_by = $3,
+		    compromise_reason = $4, updated_at = $2
+		WHERE id = $5 AND deleted_at IS NULL
+	`
+
+	return r.updateWithOutbox(messages, query, domain.AgentStatusSuspended, at, userID, reason, id)
+}
+
+// ClearCompromised clears the compromise flag and who set it, queueing messages in the same
+// transaction. The agent's status is left alone, so it stays suspended until reactivated.
+func (r *AgentRepository) ClearCompromised(id uuid.UUID, messages ...*domain.OutboxMessage) error {
+	query := `
+		UPDATE agents
+		SET is_compromised = FALSE, compromised_at = NULL, compromised_by = NULL,
+		    compromise_reason = NULL, updated_at = $1
+		WHERE id = $2 AND deleted_at IS NULL
+	`
+
+	return r.updateWithOutbox(messages, query, time.Now(), id)
+}
+
+// UpdateStatus persists the agent's status, verified_at and trust score, queueing messages in the
+// same transaction
+func (r *AgentRepository) UpdateStatus(agent *domain.Agent, messages ...*domain.OutboxMessage) error {
+	query := `
+		UPDATE agents
+		SET status = $1, verified_at = $2, trust_score = $3, updated_at = $4
+		WHERE id = $5 AND deleted_at IS NULL
+	`
+
+	agent.UpdatedAt = time.Now()
+	return r.updateWithOutbox(messages, query, agent.Status, agent.VerifiedAt, agent.TrustScore, agent.UpdatedAt, agent.ID)
+}
+
+// updateWithOutbox runs a single-agent update and writes messages in one transaction
+func (r *AgentRepository) updateWithOutbox(messages []*domain.OutboxMessage, query string, args ...interface{}) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(query, args...)
+	if err != nil {
+		return err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return fmt.Errorf("agent not found")
+	}
+
+	if err := enqueueOutbox(tx, messages); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
 // GetByMCPServer retrieves all agents that talk to a specific MCP server
 func (r *AgentRepository) GetByMCPServer(mcpServerID uuid.UUID, orgID uuid.UUID) ([]*domain.Agent, error) {
 	// Query agents where talks_to JSONB array contains the MCP server ID (as string)
@@ -414,6 +820,7 @@ func (r *AgentRepository) GetByMCPServer(mcpServerID uuid.UUID, orgID uuid.UUID)
 		       talks_to, created_at, updated_at, created_by
 		FROM agents
 		WHERE organization_id = $1
+		  AND deleted_at IS NULL
 		  AND talks_to @> $2::jsonb
 		ORDER BY created_at DESC
 	`
@@ -496,6 +903,7 @@ func (r *AgentRepository) GetByMCPServerName(mcpServerName string, orgID uuid.UU
 		       talks_to, created_at, updated_at, created_by
 		FROM agents
 		WHERE organization_id = $1
+		  AND deleted_at IS NULL
 		  AND talks_to @> $2::jsonb
 		ORDER BY created_at DESC
 	`
@@ -578,7 +986,7 @@ func (r *AgentRepository) GetByName(orgID uuid.UUID, name string) (*domain.Agent
 		       key_created_at, key_expires_at, key_rotation_grace_until, previous_public_key, rotation_count,
 		       talks_to, capabilities
 		FROM agents
-		WHERE organization_id = $1 AND name = $2
+		WHERE organization_id = $1 AND name = $2 AND deleted_at IS NULL
 		LIMIT 1
 	`
 
@@ -707,3 +1115,41 @@ func (r *AgentRepository) UpdateLastActive(ctx context.Context, agentID uuid.UUI
 
 	return nil
 }
+
+// GetByKeyExpiringBetween retrieves live agents across all organizations whose
+// current key expires in (from, to]. Only the fields needed for expiry
+// notifications are populated: identity, organization, names and key dates.
+func (r *AgentRepository) GetByKeyExpiringBetween(from, to time.Time) ([]*domain.Agent, error) {
+	query := `
+		SELECT id, organization_id, name, display_name, key_created_at, key_expires_at
+		FROM agents
+		WHERE deleted_at IS NULL
+		  AND key_expires_at > $1
+		  AND key_expires_at <= $2
+		ORDER BY key_expires_at ASC
+	`
+
+	rows, err := r.db.Query(query, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query agents with expiring keys: %w", err)
+	}
+	defer rows.Close()
+
+	var agents []*domain.Agent
+	for rows.Next() {
+		agent := &domain.Agent{}
+		if err := rows.Scan(
+			&agent.ID,
+			&agent.OrganizationID,
+			&agent.Name,
+			&agent.DisplayName,
+			&agent.KeyCreatedAt,
+			&agent.KeyExpiresAt,
+		); err != nil {
+			return nil, err
+		}
+		agents = append(agents, agent)
+	}
+
+	return agents, rows.Err()
+}
@@ -0,0 +1,399 @@
+package repository
+
+import (
+	"database/sql"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var agentListColumns = []string{
+	"id", "organization_id", "name", "display_name", "description", "agent_type", "status", "version", "public_key",
+	"certificate_url", "repository_url", "documentation_url", "trust_score", "verified_at",
+	"talks_to", "labels", "created_at", "updated_at", "created_by", "deleted_at",
+}
+
+func setupAgentTestDB(t *testing.T) (*sql.DB, sqlmock.Sqlmock) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db, mock
+}
+
+func floatPtr(v float64) *float64 {
+	return &v
+}
+
+func TestBuildAgentSearchWhere_StatusAndMinTrust(t *testing.T) {
+	orgID := uuid.New()
+
+	tests := []struct {
+		name      string
+		filter    domain.AgentSearchFilter
+		wantWhere string
+		wantArgs  []interface{}
+	}{
+		{
+			name:      "no filters",
+			filter:    domain.AgentSearchFilter{},
+			wantWhere: "organization_id = $1 AND deleted_at IS NULL",
+			wantArgs:  []interface{}{orgID},
+		},
+		{
+			name:      "include deleted",
+			filter:    domain.AgentSearchFilter{IncludeDeleted: true},
+			wantWhere: "organization_id = $1",
+			wantArgs:  []interface{}{orgID},
+		},
+		{
+			name:      "status only",
+			filter:    domain.AgentSearchFilter{Status: domain.AgentStatusVerified},
+			wantWhere: "organization_id = $1 AND deleted_at IS NULL AND status = $2",
+			wantArgs:  []interface{}{orgID, "verified"},
+		},
+		{
+			name:      "min_trust only",
+			filter:    domain.AgentSearchFilter{MinTrust: floatPtr(0.7)},
+			wantWhere: "organization_id = $1 AND deleted_at IS NULL AND trust_score >= $2",
+			wantArgs:  []interface{}{orgID, 0.7},
+		},
+		{
+			name:      "status and min_trust",
+			filter:    domain.AgentSearchFilter{Status: domain.AgentStatusPending, MinTrust: floatPtr(0)},
+			wantWhere: "organization_id = $1 AND deleted_at IS NULL AND status = $2 AND trust_score >= $3",
+			wantArgs:  []interface{}{orgID, "pending", 0.0},
+		},
+		{
+			name:      "status, agent_type and min_trust",
+			filter:    domain.AgentSearchFilter{Status: domain.AgentStatusVerified, AgentType: domain.AgentTypeMCP, MinTrust: floatPtr(0.5)},
+			wantWhere: "organization_id = $1 AND deleted_at IS NULL AND status = $2 AND agent_type = $3 AND trust_score >= $4",
+			wantArgs:  []interface{}{orgID, "verified", "mcp_server", 0.5},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			where, args := buildAgentSearchWhere(orgID, tt.filter)
+			assert.Equal(t, tt.wantWhere, where)
+			assert.Equal(t, tt.wantArgs, args)
+		})
+	}
+}
+
+func TestBuildAgentSearchWhere_TagAndQuery(t *testing.T) {
+	orgID := uuid.New()
+
+	where, args := buildAgentSearchWhere(orgID, domain.AgentSearchFilter{Tag: "env:prod", Query: "50%_off"})
+
+	assert.Contains(t, where, "t.key = $2 AND t.value = $3")
+	assert.Contains(t, where, "(name ILIKE $4 OR display_name ILIKE $4)")
+	assert.Equal(t, []interface{}{orgID, "env", "prod", `%50\%\_off%`}, args)
+
+	where, args = buildAgentSearchWhere(orgID, domain.AgentSearchFilter{Tag: "env"})
+	assert.Contains(t, where, "WHERE at.agent_id = agents.id AND t.key = $2)")
+	assert.Equal(t, []interface{}{orgID, "env"}, args)
+}
+
+func TestAgentRepository_Search_StatusAndMinTrust(t *testing.T) {
+	db, mock := setupAgentTestDB(t)
+	repo := NewAgentRepository(db)
+	orgID := uuid.New()
+	agentID := uuid.New()
+	now := time.Now()
+
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT COUNT(*) FROM agents WHERE organization_id = $1 AND deleted_at IS NULL AND status = $2 AND trust_score >= $3")).
+		WithArgs(orgID, "verified", 0.8).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+	mock.ExpectQuery(regexp.QuoteMeta("WHERE organization_id = $1 AND deleted_at IS NULL AND status = $2 AND trust_score >= $3")).
+		WithArgs(orgID, "verified", 0.8, 10, 0).
+		WillReturnRows(sqlmock.NewRows(agentListColumns).AddRow(
+			agentID, orgID, "billing-bot", "Billing Bot", "", "ai_agent", "verified", "1.0.0", nil,
+			nil, nil, nil, 0.92, now,
+			[]byte(`[]`), []byte(`{}`), now, now, uuid.New(), nil,
+		))
+
+	agents, total, err := repo.Search(orgID, domain.AgentSearchFilter{
+		Status:   domain.AgentStatusVerified,
+		MinTrust: floatPtr(0.8),
+	}, 10, 0, nil)
+
+	require.NoError(t, err)
+	assert.Equal(t, 1, total)
+	require.Len(t, agents, 1)
+	assert.Equal(t, agentID, agents[0].ID)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestBuildAgentSearchWhere_Labels(t *testing.T) {
+	orgID := uuid.New()
+
+	where, args := buildAgentSearchWhere(orgID, domain.AgentSearchFilter{
+		Labels: map[string]string{"env": "prod", "team": "payments"},
+	})
+
+	assert.Equal(t, "organization_id = $1 AND deleted_at IS NULL AND labels @> $2::jsonb", where)
+	assert.Equal(t, []interface{}{orgID, `{"env":"prod","team":"payments"}`}, args)
+}
+
+func TestAgentRepository_Search_LabelSelectors(t *testing.T) {
+	db, mock := setupAgentTestDB(t)
+	repo := NewAgentRepository(db)
+	orgID := uuid.New()
+	now := time.Now()
+
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT COUNT(*) FROM agents WHERE organization_id = $1 AND deleted_at IS NULL AND labels @> $2::jsonb")).
+		WithArgs(orgID, `{"env":"prod"}`).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+	mock.ExpectQuery(regexp.QuoteMeta("labels @> $2::jsonb")).
+		WithArgs(orgID, `{"env":"prod"}`, 10, 0).
+		WillReturnRows(sqlmock.NewRows(agentListColumns).AddRow(
+			uuid.New(), orgID, "billing-bot", "Billing Bot", "", "ai_agent", "verified", "1.0.0", nil,
+			nil, nil, nil, 0.92, now,
+			[]byte(`[]`), []byte(`{"env":"prod","team":"payments"}`), now, now, uuid.New(), nil,
+		))
+
+	agents, total, err := repo.Search(orgID, domain.AgentSearchFilter{
+		Labels: map[string]string{"env": "prod"},
+	}, 10, 0, nil)
+
+	require.NoError(t, err)
+	assert.Equal(t, 1, total)
+	require.Len(t, agents, 1)
+	assert.Equal(t, map[string]string{"env": "prod", "team": "payments"}, agents[0].Labels)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAgentRepository_Search_NoMatchReturnsEmptySlice(t *testing.T) {
+	db, mock := setupAgentTestDB(t)
+	repo := NewAgentRepository(db)
+	orgID := uuid.New()
+
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT COUNT(*) FROM agents WHERE")).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+	mock.ExpectQuery(regexp.QuoteMeta("ORDER BY created_at DESC, id DESC")).
+		WillReturnRows(sqlmock.NewRows(agentListColumns))
+
+	agents, total, err := repo.Search(orgID, domain.AgentSearchFilter{
+		Status:   domain.AgentStatusSuspended,
+		MinTrust: floatPtr(0.99),
+		Query:    "does-not-exist",
+	}, 10, 0, nil)
+
+	require.NoError(t, err)
+	assert.Equal(t, 0, total)
+	assert.NotNil(t, agents)
+	assert.Empty(t, agents)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAgentRepository_GetByOrganization_ExcludesDeleted(t *testing.T) {
+	db, mock := setupAgentTestDB(t)
+	repo := NewAgentRepository(db)
+	orgID := uuid.New()
+
+	mock.ExpectQuery(regexp.QuoteMeta("WHERE organization_id = $1 AND deleted_at IS NULL")).
+		WithArgs(orgID).
+		WillReturnRows(sqlmock.NewRows(agentListColumns))
+
+	agents, err := repo.GetByOrganization(orgID)
+
+	require.NoError(t, err)
+	assert.Empty(t, agents)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAgentRepository_CountByOrganization_ExcludesDeleted(t *testing.T) {
+	db, mock := setupAgentTestDB(t)
+	repo := NewAgentRepository(db)
+	orgID := uuid.New()
+
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT COUNT(*) FROM agents WHERE organization_id = $1 AND deleted_at IS NULL")).
+		WithArgs(orgID).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(4))
+
+	count, err := repo.CountByOrganization(orgID)
+
+	require.NoError(t, err)
+	assert.Equal(t, 4, count)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAgentRepository_GetByID_ExcludesDeleted(t *testing.T) {
+	db, mock := setupAgentTestDB(t)
+	repo := NewAgentRepository(db)
+	agentID := uuid.New()
+
+	mock.ExpectQuery(regexp.QuoteMeta("WHERE id = $1 AND deleted_at IS NULL")).
+		WithArgs(agentID).
+		WillReturnError(sql.ErrNoRows)
+
+	agent, err := repo.GetByID(agentID)
+
+	assert.Nil(t, agent)
+	assert.EqualError(t, err, "agent not found")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAgentRepository_GetByIDIncludingDeleted(t *testing.T) {
+	db, mock := setupAgentTestDB(t)
+	repo := NewAgentRepository(db)
+	agentID := uuid.New()
+	orgID := uuid.New()
+	now := time.Now()
+	deletedAt := now.Add(-time.Hour)
+
+	mock.ExpectQuery(`WHERE id = \$1\s*$`).
+		WithArgs(agentID).
+		WillReturnRows(sqlmock.NewRows([]string{
+			"id", "organization_id", "name", "display_name", "description", "agent_type", "status", "version",
+			"public_key", "encrypted_private_key", "key_algorithm", "certificate_url", "repository_url", "documentation_url",
+			"trust_score", "verified_at", "talks_to", "capabilities", "labels", "created_at", "updated_at", "created_by", "last_active",
+			"deleted_at", "is_compromised", "compromised_at", "compromised_by", "compromise_reason",
+			"oauth_client_id",
+		}).AddRow(
+			agentID, orgID, "old-bot", "Old Bot", "", "ai_agent", "verified", "1.0.0",
+			nil, nil, nil, nil, nil, nil,
+			0.5, nil, []byte(`[]`), []byte(`[]`), []byte(`{"env":"prod"}`), now, now, uuid.New(), nil,
+			deletedAt, false, nil, nil, nil,
+			"",
+		))
+
+	agent, err := repo.GetByIDIncludingDeleted(agentID)
+
+	require.NoError(t, err)
+	require.NotNil(t, agent.DeletedAt)
+	assert.True(t, deletedAt.Equal(*agent.DeletedAt))
+	assert.Equal(t, map[string]string{"env": "prod"}, agent.Labels)
+	assert.False(t, agent.IsCompromised)
+	assert.Nil(t, agent.CompromisedBy)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAgentRepository_SoftDelete(t *testing.T) {
+	db, mock := setupAgentTestDB(t)
+	repo := NewAgentRepository(db)
+	agentID := uuid.New()
+
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE agents SET deleted_at = $1, updated_at = $1 WHERE id = $2 AND deleted_at IS NULL")).
+		WithArgs(sqlmock.AnyArg(), agentID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE agents SET deleted_at")).
+		WithArgs(sqlmock.AnyArg(), agentID).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	assert.NoError(t, repo.SoftDelete(agentID))
+	// Deleting an already soft-deleted agent reports it as missing
+	assert.EqualError(t, repo.SoftDelete(agentID), "agent not found")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAgentRepository_UpdateLabels(t *testing.T) {
+	db, mock := setupAgentTestDB(t)
+	repo := NewAgentRepository(db)
+	agentID := uuid.New()
+
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE agents SET labels = $1, updated_at = $2 WHERE id = $3 AND deleted_at IS NULL")).
+		WithArgs([]byte(`{"env":"prod"}`), sqlmock.AnyArg(), agentID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	// Clearing labels stores an empty object rather than NULL
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE agents SET labels = $1")).
+		WithArgs([]byte(`{}`), sqlmock.AnyArg(), agentID).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	assert.NoError(t, repo.UpdateLabels(agentID, map[string]string{"env": "prod"}))
+	assert.EqualError(t, repo.UpdateLabels(agentID, nil), "agent not found")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAgentRepository_MarkCompromisedBy(t *testing.T) {
+	db, mock := setupAgentTestDB(t)
+	repo := NewAgentRepository(db)
+	agentID := uuid.New()
+	userID := uuid.New()
+	at := time.Now()
+	message, err := domain.NewWebhookOutboxMessage(uuid.New(), domain.WebhookEventAgentCompromised, domain.WebhookResourceAgent, map[string]string{})
+	require.NoError(t, err)
+
+	// The flag, the suspension and the outbox message commit together
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("SET is_compromised = TRUE, status = $1, compromised_at = $2, compromised_by = $3")).
+		WithArgs(domain.AgentStatusSuspended, at, userID, "leaked key", agentID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO outbox")).
+		WithArgs(message.ID, message.OrganizationID, message.Topic, sqlmock.AnyArg(), message.Status, 0, sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	require.NoError(t, repo.MarkCompromisedBy(agentID, userID, "leaked key", at, message))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAgentRepository_ClearCompromised_UnknownAgent(t *testing.T) {
+	db, mock := setupAgentTestDB(t)
+	repo := NewAgentRepository(db)
+	agentID := uuid.New()
+	message, err := domain.NewWebhookOutboxMessage(uuid.New(), domain.WebhookEventAgentUncompromised, domain.WebhookResourceAgent, map[string]string{})
+	require.NoError(t, err)
+
+	// Nothing is queued for an agent that does not exist
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("SET is_compromised = FALSE")).
+		WithArgs(sqlmock.AnyArg(), agentID).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectRollback()
+
+	assert.EqualError(t, repo.ClearCompromised(agentID, message), "agent not found")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAgentRepository_UpdateStatus(t *testing.T) {
+	db, mock := setupAgentTestDB(t)
+	repo := NewAgentRepository(db)
+	verifiedAt := time.Now()
+	agent := &domain.Agent{ID: uuid.New(), Status: domain.AgentStatusVerified, VerifiedAt: &verifiedAt, TrustScore: 0.8}
+	message, err := domain.NewWebhookOutboxMessage(uuid.New(), domain.WebhookEventAgentVerified, domain.WebhookResourceAgent, map[string]string{})
+	require.NoError(t, err)
+
+	// The status change and its webhook event commit together
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("SET status = $1, verified_at = $2, trust_score = $3, updated_at = $4")).
+		WithArgs(domain.AgentStatusVerified, &verifiedAt, 0.8, sqlmock.AnyArg(), agent.ID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO outbox")).
+		WithArgs(message.ID, message.OrganizationID, message.Topic, sqlmock.AnyArg(), message.Status, 0, sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	require.NoError(t, repo.UpdateStatus(agent, message))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAgentRepository_Create_RejectsOverQuotaUnderLock(t *testing.T) {
+	db, mock := setupAgentTestDB(t)
+	repo := NewAgentRepository(db)
+	orgID := uuid.New()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT max_agents, max_users FROM organizations WHERE id = $1 FOR UPDATE")).
+		WithArgs(orgID).
+		WillReturnRows(sqlmock.NewRows([]string{"max_agents", "max_users"}).AddRow(2, 0))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT COUNT(*) FROM agents WHERE organization_id = $1 AND deleted_at IS NULL")).
+		WithArgs(orgID).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(2))
+	mock.ExpectRollback()
+
+	err := repo.Create(&domain.Agent{ID: uuid.New(), OrganizationID: orgID, Name: "over-quota"})
+
+	var quotaErr *domain.QuotaExceededError
+	require.ErrorAs(t, err, &quotaErr)
+	assert.Equal(t, 2, quotaErr.Limit)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
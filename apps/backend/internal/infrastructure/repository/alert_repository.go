@@ -10,17 +10,34 @@ import (
 )
 
 type AlertRepository struct {
-	db *sql.DB
+	db          *sql.DB
+	dedupWindow time.Duration // 0 disables deduplication
 }
 
-func NewAlertRepository(db *sql.DB) *AlertRepository {
-	return &AlertRepository{db: db}
+// NewAlertRepository creates an alert repository that collapses identical open alerts occurring
+// within dedupWindow of each other
+func NewAlertRepository(db *sql.DB, dedupWindow time.Duration) *AlertRepository {
+	return &AlertRepository{db: db, dedupWindow: dedupWindow}
 }
 
+// Create stores alert, or collapses it into an identical open alert (same organization, type,
+// resource and title) that last occurred within the dedup window. Deduplicating here covers
+// every alert source, not only those going through AlertService.
 func (r *AlertRepository) Create(alert *domain.Alert) error {
+	if r.dedupWindow > 0 && alert.ResourceID != uuid.Nil {
+		now := time.Now().UTC()
+		merged, err := r.incrementDuplicate(alert, now.Add(-r.dedupWindow), now)
+		if err != nil {
+			return err
+		}
+		if merged {
+			return nil
+		}
+	}
+
 	query := `
-		INSERT INTO alerts (id, organization_id, alert_type, severity, title, description, resource_type, resource_id, is_acknowledged, created_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		INSERT INTO alerts (id, organization_id, alert_type, severity, title, description, resource_type, resource_id, is_acknowledged, occurrence_count, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
 	`
 
 	if alert.ID == uuid.Nil {
@@ -29,6 +46,9 @@ func (r *AlertRepository) Create(alert *domain.Alert) error {
 	if alert.CreatedAt.IsZero() {
 		alert.CreatedAt = time.Now()
 	}
+	if alert.OccurrenceCount < 1 {
+		alert.OccurrenceCount = 1
+	}
 
 	_, err := r.db.Exec(query,
 		alert.ID,
@@ -40,14 +60,47 @@ func (r *AlertRepository) Create(alert *domain.Alert) error {
 		alert.ResourceType,
 		alert.ResourceID,
 		alert.IsAcknowledged,
+		alert.OccurrenceCount,
 		alert.CreatedAt,
 	)
 	return err
 }
 
+// incrementDuplicate looks for an unacknowledged alert with the same organization, type, resource
+// and title that last occurred at or after since. If there is one, its occurrence count is
+// incremented, alert is updated from it and true is returned.
+func (r *AlertRepository) incrementDuplicate(alert *domain.Alert, since, at time.Time) (bool, error) {
+	query := `
+		UPDATE alerts
+		SET occurrence_count = occurrence_count + 1, last_occurred_at = $6
+		WHERE id = (
+			SELECT id FROM alerts
+			WHERE organization_id = $1 AND alert_type = $2 AND resource_id = $3 AND title = $4
+			  AND is_acknowledged = false
+			  AND COALESCE(last_occurred_at, created_at) >= $5
+			ORDER BY created_at DESC
+			LIMIT 1
+			FOR UPDATE
+		)
+		RETURNING id, occurrence_count, created_at
+	`
+
+	err := r.db.QueryRow(query, alert.OrganizationID, alert.AlertType, alert.ResourceID, alert.Title, since, at).
+		Scan(&alert.ID, &alert.OccurrenceCount, &alert.CreatedAt)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to increment duplicate alert: %w", err)
+	}
+	alert.LastOccurredAt = &at
+	return true, nil
+}
+
 func (r *AlertRepository) GetByID(id uuid.UUID) (*domain.Alert, error) {
 	query := `
-		SELECT id, organization_id, alert_type, severity, title, description, resource_type, resource_id, is_acknowledged, acknowledged_by, acknowledged_at, created_at
+		SELECT id, organization_id, alert_type, severity, title, description, resource_type, resource_id, is_acknowledged, acknowledged_by, acknowledged_at,
+		       resolved_by, resolved_at, resolution_note, occurrence_count, last_occurred_at, created_at
 		FROM alerts
 		WHERE id = $1
 	`
@@ -65,6 +118,11 @@ func (r *AlertRepository) GetByID(id uuid.UUID) (*domain.Alert, error) {
 		&alert.IsAcknowledged,
 		&alert.AcknowledgedBy,
 		&alert.AcknowledgedAt,
+		&alert.ResolvedBy,
+		&alert.ResolvedAt,
+		&alert.ResolutionNote,
+		&alert.OccurrenceCount,
+		&alert.LastOccurredAt,
 		&alert.CreatedAt,
 	)
 
@@ -76,7 +134,8 @@ func (r *AlertRepository) GetByID(id uuid.UUID) (*domain.Alert, error) {
 
 func (r *AlertRepository) GetByOrganization(orgID uuid.UUID, limit, offset int) ([]*domain.Alert, error) {
 	query := `
-		SELECT id, organization_id, alert_type, severity, title, description, resource_type, resource_id, is_acknowledged, acknowledged_by, acknowledged_at, created_at
+		SELECT id, organization_id, alert_type, severity, title, description, resource_type, resource_id, is_acknowledged, acknowledged_by, acknowledged_at,
+		       resolved_by, resolved_at, resolution_note, occurrence_count, last_occurred_at, created_at
 		FROM alerts
 		WHERE organization_id = $1
 		ORDER BY created_at DESC
@@ -99,7 +158,8 @@ func (r *AlertRepository) GetByOrganizationFiltered(orgID uuid.UUID, status stri
 
 	if status == "acknowledged" {
 		query = `
-			SELECT id, organization_id, alert_type, severity, title, description, resource_type, resource_id, is_acknowledged, acknowledged_by, acknowledged_at, created_at
+			SELECT id, organization_id, alert_type, severity, title, description, resource_type, resource_id, is_acknowledged, acknowledged_by, acknowledged_at,
+			       resolved_by, resolved_at, resolution_note, occurrence_count, last_occurred_at, created_at
 			FROM alerts
 			WHERE organization_id = $1 AND is_acknowledged = true
 			ORDER BY created_at DESC
@@ -108,7 +168,8 @@ func (r *AlertRepository) GetByOrganizationFiltered(orgID uuid.UUID, status stri
 		args = []interface{}{orgID, limit, offset}
 	} else if status == "unacknowledged" {
 		query = `
-			SELECT id, organization_id, alert_type, severity, title, description, resource_type, resource_id, is_acknowledged, acknowledged_by, acknowledged_at, created_at
+			SELECT id, organization_id, alert_type, severity, title, description, resource_type, resource_id, is_acknowledged, acknowledged_by, acknowledged_at,
+			       resolved_by, resolved_at, resolution_note, occurrence_count, last_occurred_at, created_at
 			FROM alerts
 			WHERE organization_id = $1 AND is_acknowledged = false
 			ORDER BY created_at DESC
@@ -118,7 +179,8 @@ func (r *AlertRepository) GetByOrganizationFiltered(orgID uuid.UUID, status stri
 	} else {
 		// Return all alerts (no status filter)
 		query = `
-			SELECT id, organization_id, alert_type, severity, title, description, resource_type, resource_id, is_acknowledged, acknowledged_by, acknowledged_at, created_at
+			SELECT id, organization_id, alert_type, severity, title, description, resource_type, resource_id, is_acknowledged, acknowledged_by, acknowledged_at,
+			       resolved_by, resolved_at, resolution_note, occurrence_count, last_occurred_at, created_at
 			FROM alerts
 			WHERE organization_id = $1
 			ORDER BY created_at DESC
@@ -138,7 +200,8 @@ func (r *AlertRepository) GetByOrganizationFiltered(orgID uuid.UUID, status stri
 
 func (r *AlertRepository) GetUnacknowledged(orgID uuid.UUID) ([]*domain.Alert, error) {
 	query := `
-		SELECT id, organization_id, alert_type, severity, title, description, resource_type, resource_id, is_acknowledged, acknowledged_by, acknowledged_at, created_at
+		SELECT id, organization_id, alert_type, severity, title, description, resource_type, resource_id, is_acknowledged, acknowledged_by, acknowledged_at,
+		       resolved_by, resolved_at, resolution_note, occurrence_count, last_occurred_at, created_at
 		FROM alerts
 		WHERE organization_id = $1 AND is_acknowledged = false
 		ORDER BY created_at DESC
@@ -153,15 +216,31 @@ func (r *AlertRepository) GetUnacknowledged(orgID uuid.UUID) ([]*domain.Alert, e
 	return r.scanAlerts(rows)
 }
 
-func (r *AlertRepository) Acknowledge(id, userID uuid.UUID) error {
+func (r *AlertRepository) Acknowledge(id, userID uuid.UUID, at time.Time) error {
 	query := `
 		UPDATE alerts
 		SET is_acknowledged = true, acknowledged_by = $1, acknowledged_at = $2
 		WHERE id = $3
 	`
 
-	now := time.Now()
-	_, err := r.db.Exec(query, userID, now, id)
+	_, err := r.db.Exec(query, userID, at, id)
+	return err
+}
+
+// Resolve marks the alert resolved by userID; an unacknowledged alert is acknowledged by them too
+func (r *AlertRepository) Resolve(id, userID uuid.UUID, note *string, at time.Time) error {
+	query := `
+		UPDATE alerts
+		SET is_acknowledged = true,
+		    acknowledged_by = COALESCE(acknowledged_by, $1),
+		    acknowledged_at = COALESCE(acknowledged_at, $2),
+		    resolved_by = $1,
+		    resolved_at = $2,
+		    resolution_note = $3
+		WHERE id = $4
+	`
+
+	_, err := r.db.Exec(query, userID, at, note, id)
 	return err
 }
 
@@ -223,7 +302,8 @@ func (r *AlertRepository) CountByOrganizationFiltered(orgID uuid.UUID, status st
 
 func (r *AlertRepository) GetByResourceID(resourceID uuid.UUID, limit, offset int) ([]*domain.Alert, error) {
 	query := `
-		SELECT id, organization_id, alert_type, severity, title, description, resource_type, resource_id, is_acknowledged, acknowledged_by, acknowledged_at, created_at
+		SELECT id, organization_id, alert_type, severity, title, description, resource_type, resource_id, is_acknowledged, acknowledged_by, acknowledged_at,
+		       resolved_by, resolved_at, resolution_note, occurrence_count, last_occurred_at, created_at
 		FROM alerts
 		WHERE resource_id = $1
 		ORDER BY created_at DESC
@@ -241,7 +321,8 @@ func (r *AlertRepository) GetByResourceID(resourceID uuid.UUID, limit, offset in
 
 func (r *AlertRepository) GetUnacknowledgedByResourceID(resourceID uuid.UUID) ([]*domain.Alert, error) {
 	query := `
-		SELECT id, organization_id, alert_type, severity, title, description, resource_type, resource_id, is_acknowledged, acknowledged_by, acknowledged_at, created_at
+		SELECT id, organization_id, alert_type, severity, title, description, resource_type, resource_id, is_acknowledged, acknowledged_by, acknowledged_at,
+		       resolved_by, resolved_at, resolution_note, occurrence_count, last_occurred_at, created_at
 		FROM alerts
 		WHERE resource_id = $1 AND is_acknowledged = false
 		ORDER BY created_at DESC
@@ -273,6 +354,11 @@ func (r *AlertRepository) scanAlerts(rows *sql.Rows) ([]*domain.Alert, error) {
 			&alert.IsAcknowledged,
 			&alert.AcknowledgedBy,
 			&alert.AcknowledgedAt,
+			&alert.ResolvedBy,
+			&alert.ResolvedAt,
+			&alert.ResolutionNote,
+			&alert.OccurrenceCount,
+			&alert.LastOccurredAt,
 			&alert.CreatedAt,
 		)
 		if err != nil {
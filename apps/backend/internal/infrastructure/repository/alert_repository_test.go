@@ -0,0 +1,135 @@
+package repository
+
+import (
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var alertColumns = []string{
+	"id", "organization_id", "alert_type", "severity", "title", "description", "resource_type", "resource_id",
+	"is_acknowledged", "acknowledged_by", "acknowledged_at", "resolved_by", "resolved_at", "resolution_note",
+	"occurrence_count", "last_occurred_at", "created_at",
+}
+
+func TestAlertRepository_Acknowledge(t *testing.T) {
+	db, mock := setupAgentTestDB(t)
+	repo := NewAlertRepository(db, 0)
+	alertID, userID := uuid.New(), uuid.New()
+	at := time.Date(2025, 3, 3, 12, 0, 0, 0, time.UTC)
+
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE alerts")).
+		WithArgs(userID, at, alertID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	require.NoError(t, repo.Acknowledge(alertID, userID, at))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAlertRepository_Resolve(t *testing.T) {
+	db, mock := setupAgentTestDB(t)
+	repo := NewAlertRepository(db, 0)
+	alertID, userID := uuid.New(), uuid.New()
+	at := time.Date(2025, 3, 3, 12, 0, 0, 0, time.UTC)
+	note := "False positive"
+
+	mock.ExpectExec(regexp.QuoteMeta("resolved_by = $1")).
+		WithArgs(userID, at, &note, alertID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	require.NoError(t, repo.Resolve(alertID, userID, &note, at))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAlertRepository_GetByID_ScansResolution(t *testing.T) {
+	db, mock := setupAgentTestDB(t)
+	repo := NewAlertRepository(db, 0)
+	alertID, orgID, resourceID, userID := uuid.New(), uuid.New(), uuid.New(), uuid.New()
+	acknowledgedAt := time.Date(2025, 3, 3, 12, 0, 0, 0, time.UTC)
+	resolvedAt := acknowledgedAt.Add(time.Hour)
+	createdAt := acknowledgedAt.Add(-time.Hour)
+
+	mock.ExpectQuery(regexp.QuoteMeta("FROM alerts")).
+		WithArgs(alertID).
+		WillReturnRows(sqlmock.NewRows(alertColumns).AddRow(
+			alertID, orgID, "security_breach", "high", "Title", "Description", "agent", resourceID,
+			true, userID, acknowledgedAt, userID, resolvedAt, "Rotated credentials", 1, nil, createdAt,
+		))
+
+	alert, err := repo.GetByID(alertID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.AlertSecurityBreach, alert.AlertType)
+	assert.True(t, alert.IsAcknowledged)
+	require.NotNil(t, alert.AcknowledgedBy)
+	assert.Equal(t, userID, *alert.AcknowledgedBy)
+	assert.Equal(t, acknowledgedAt, *alert.AcknowledgedAt)
+	require.NotNil(t, alert.ResolvedBy)
+	assert.Equal(t, userID, *alert.ResolvedBy)
+	assert.Equal(t, resolvedAt, *alert.ResolvedAt)
+	require.NotNil(t, alert.ResolutionNote)
+	assert.Equal(t, "Rotated credentials", *alert.ResolutionNote)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func dedupTestAlert() *domain.Alert {
+	return &domain.Alert{
+		OrganizationID: uuid.New(),
+		AlertType:      domain.AlertSecurityBreach,
+		Severity:       domain.AlertSeverityHigh,
+		Title:          "Capability Violation: agent attempted delete_file",
+		ResourceType:   "agent",
+		ResourceID:     uuid.New(),
+	}
+}
+
+func TestAlertRepository_Create_CollapsesDuplicate(t *testing.T) {
+	db, mock := setupAgentTestDB(t)
+	repo := NewAlertRepository(db, 5*time.Minute)
+	alert := dedupTestAlert()
+	existingID := uuid.New()
+	createdAt := time.Date(2025, 3, 3, 12, 0, 0, 0, time.UTC)
+
+	mock.ExpectQuery(regexp.QuoteMeta("SET occurrence_count = occurrence_count + 1")).
+		WithArgs(alert.OrganizationID, alert.AlertType, alert.ResourceID, alert.Title, sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "occurrence_count", "created_at"}).AddRow(existingID, 4, createdAt))
+
+	require.NoError(t, repo.Create(alert))
+	assert.Equal(t, existingID, alert.ID)
+	assert.Equal(t, 4, alert.OccurrenceCount)
+	assert.NotNil(t, alert.LastOccurredAt)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAlertRepository_Create_InsertsWithoutDuplicate(t *testing.T) {
+	db, mock := setupAgentTestDB(t)
+	repo := NewAlertRepository(db, 5*time.Minute)
+	alert := dedupTestAlert()
+
+	mock.ExpectQuery(regexp.QuoteMeta("SET occurrence_count = occurrence_count + 1")).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "occurrence_count", "created_at"}))
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO alerts")).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	require.NoError(t, repo.Create(alert))
+	assert.NotEqual(t, uuid.Nil, alert.ID)
+	assert.Equal(t, 1, alert.OccurrenceCount)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAlertRepository_Create_DedupDisabled(t *testing.T) {
+	db, mock := setupAgentTestDB(t)
+	repo := NewAlertRepository(db, 0)
+
+	// No duplicate lookup: the alert is inserted directly
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO alerts")).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	require.NoError(t, repo.Create(dedupTestAlert()))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
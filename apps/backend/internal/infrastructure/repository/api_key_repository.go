@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 	"github.com/opena2a/identity/backend/internal/domain"
 )
 
@@ -19,8 +20,8 @@ func NewAPIKeyRepository(db *sql.DB) *APIKeyRepository {
 
 func (r *APIKeyRepository) Create(key *domain.APIKey) error {
 	query := `
-		INSERT INTO api_keys (id, organization_id, agent_id, name, key_hash, prefix, expires_at, is_active, created_at, created_by)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		INSERT INTO api_keys (id, organization_id, agent_id, name, key_hash, prefix, expires_at, scopes, is_active, created_at, created_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
 	`
 
 	if key.ID == uuid.Nil {
@@ -38,6 +39,7 @@ func (r *APIKeyRepository) Create(key *domain.APIKey) error {
 		key.KeyHash,
 		key.Prefix,
 		key.ExpiresAt,
+		pq.Array(nonNilStrings(key.Scopes)),
 		key.IsActive,
 		key.CreatedAt,
 		key.CreatedBy,
@@ -47,7 +49,7 @@ func (r *APIKeyRepository) Create(key *domain.APIKey) error {
 
 func (r *APIKeyRepository) GetByID(id uuid.UUID) (*domain.APIKey, error) {
 	query := `
-		SELECT id, organization_id, agent_id, name, key_hash, prefix, last_used_at, expires_at, is_active, created_at, created_by
+		SELECT id, organization_id, agent_id, name, key_hash, prefix, last_used_at, expires_at, scopes, is_active, created_at, created_by
 		FROM api_keys
 		WHERE id = $1
 	`
@@ -62,6 +64,7 @@ func (r *APIKeyRepository) GetByID(id uuid.UUID) (*domain.APIKey, error) {
 		&key.Prefix,
 		&key.LastUsedAt,
 		&key.ExpiresAt,
+		pq.Array(&key.Scopes),
 		&key.IsActive,
 		&key.CreatedAt,
 		&key.CreatedBy,
@@ -75,7 +78,7 @@ func (r *APIKeyRepository) GetByID(id uuid.UUID) (*domain.APIKey, error) {
 
 func (r *APIKeyRepository) GetByHash(hash string) (*domain.APIKey, error) {
 	query := `
-		SELECT id, organization_id, agent_id, name, key_hash, prefix, last_used_at, expires_at, is_active, created_at, created_by
+		SELECT id, organization_id, agent_id, name, key_hash, prefix, last_used_at, expires_at, scopes, is_active, created_at, created_by
 		FROM api_keys
 		WHERE key_hash = $1 AND is_active = true
 	`
@@ -90,6 +93,7 @@ func (r *APIKeyRepository) GetByHash(hash string) (*domain.APIKey, error) {
 		&key.Prefix,
 		&key.LastUsedAt,
 		&key.ExpiresAt,
+		pq.Array(&key.Scopes),
 		&key.IsActive,
 		&key.CreatedAt,
 		&key.CreatedBy,
@@ -103,7 +107,7 @@ func (r *APIKeyRepository) GetByHash(hash string) (*domain.APIKey, error) {
 
 func (r *APIKeyRepository) GetByAgent(agentID uuid.UUID) ([]*domain.APIKey, error) {
 	query := `
-		SELECT id, organization_id, agent_id, name, key_hash, prefix, last_used_at, expires_at, is_active, created_at, created_by
+		SELECT id, organization_id, agent_id, name, key_hash, prefix, last_used_at, expires_at, scopes, is_active, created_at, created_by
 		FROM api_keys
 		WHERE agent_id = $1
 		ORDER BY created_at DESC
@@ -127,6 +131,7 @@ func (r *APIKeyRepository) GetByAgent(agentID uuid.UUID) ([]*domain.APIKey, erro
 			&key.Prefix,
 			&key.LastUsedAt,
 			&key.ExpiresAt,
+			pq.Array(&key.Scopes),
 			&key.IsActive,
 			&key.CreatedAt,
 			&key.CreatedBy,
@@ -143,7 +148,7 @@ func (r *APIKeyRepository) GetByOrganization(orgID uuid.UUID) ([]*domain.APIKey,
 	query := `
 		SELECT
 			k.id, k.organization_id, k.agent_id, k.name, k.key_hash, k.prefix,
-			k.last_used_at, k.expires_at, k.is_active, k.created_at, k.created_by,
+			k.last_used_at, k.expires_at, k.scopes, k.is_active, k.created_at, k.created_by,
 			a.name as agent_name
 		FROM api_keys k
 		LEFT JOIN agents a ON k.agent_id = a.id
@@ -170,6 +175,7 @@ func (r *APIKeyRepository) GetByOrganization(orgID uuid.UUID) ([]*domain.APIKey,
 			&key.Prefix,
 			&key.LastUsedAt,
 			&key.ExpiresAt,
+			pq.Array(&key.Scopes),
 			&key.IsActive,
 			&key.CreatedAt,
 			&key.CreatedBy,
@@ -203,3 +209,43 @@ func (r *APIKeyRepository) UpdateLastUsed(id uuid.UUID) error {
 	_, err := r.db.Exec(query, id)
 	return err
 }
+
+// GetExpiredActive returns active keys, across all organizations, whose expiry is at or before now
+func (r *APIKeyRepository) GetExpiredActive(now time.Time) ([]*domain.APIKey, error) {
+	query := `
+		SELECT id, organization_id, agent_id, name, key_hash, prefix, last_used_at, expires_at, scopes, is_active, created_at, created_by
+		FROM api_keys
+		WHERE is_active = true AND expires_at IS NOT NULL AND expires_at <= $1
+		ORDER BY expires_at
+	`
+
+	rows, err := r.db.Query(query, now)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var keys []*domain.APIKey
+	for rows.Next() {
+		key := &domain.APIKey{}
+		err := rows.Scan(
+			&key.ID,
+			&key.OrganizationID,
+			&key.AgentID,
+			&key.Name,
+			&key.KeyHash,
+			&key.Prefix,
+			&key.LastUsedAt,
+			&key.ExpiresAt,
+			pq.Array(&key.Scopes),
+			&key.IsActive,
+			&key.CreatedAt,
+			&key.CreatedBy,
+		)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+	return keys, rows.Err()
+}
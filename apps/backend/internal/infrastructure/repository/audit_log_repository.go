@@ -3,6 +3,8 @@ package repository
 import (
 	"database/sql"
 	"encoding/json"
+	"fmt"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -17,10 +19,13 @@ func NewAuditLogRepository(db *sql.DB) *AuditLogRepository {
 	return &AuditLogRepository{db: db}
 }
 
+// Create appends the entry to its organization's hash chain. Writers for an organization are
+// serialized by a transaction-scoped advisory lock, so each entry links to the one before it.
 func (r *AuditLogRepository) Create(log *domain.AuditLog) error {
 	query := `
-		INSERT INTO audit_logs (id, organization_id, user_id, action, resource_type, resource_id, ip_address, user_agent, metadata, timestamp)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		INSERT INTO audit_logs (id, organization_id, user_id, action, resource_type, resource_id, ip_address, user_agent, metadata, timestamp,
+			chain_sequence, prev_hash, entry_hash)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
 	`
 
 	if log.ID == uuid.Nil {
@@ -29,13 +34,44 @@ func (r *AuditLogRepository) Create(log *domain.AuditLog) error {
 	if log.Timestamp.IsZero() {
 		log.Timestamp = time.Now()
 	}
+	// Store the timestamp exactly as it is hashed; the column keeps microseconds and no zone
+	log.Timestamp = log.Timestamp.UTC().Truncate(domain.AuditChainTimestampPrecision)
 
 	metadataJSON, err := json.Marshal(log.Metadata)
 	if err != nil {
 		return err
 	}
 
-	_, err = r.db.Exec(query,
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`SELECT pg_advisory_xact_lock(hashtext($1))`, "audit_logs:"+log.OrganizationID.String()); err != nil {
+		return fmt.Errorf("failed to lock audit chain: %w", err)
+	}
+
+	// Link to the stored head rather than the newest remaining entry: after entries are deleted
+	// from the end of the chain, new entries leave a gap instead of hiding the deletion
+	var prevSequence int64
+	var prevHash sql.NullString
+	err = tx.QueryRow(`
+		SELECT chain_sequence, entry_hash
+		FROM audit_chain_heads
+		WHERE organization_id = $1
+	`, log.OrganizationID).Scan(&prevSequence, &prevHash)
+	if err != nil && err != sql.ErrNoRows {
+		return fmt.Errorf("failed to read audit chain head: %w", err)
+	}
+
+	log.ChainSequence = prevSequence + 1
+	log.PrevHash = prevHash.String
+	if log.EntryHash, err = log.ComputeHash(); err != nil {
+		return fmt.Errorf("failed to hash audit log: %w", err)
+	}
+
+	if _, err := tx.Exec(query,
 		log.ID,
 		log.OrganizationID,
 		log.UserID,
@@ -46,8 +82,90 @@ func (r *AuditLogRepository) Create(log *domain.AuditLog) error {
 		log.UserAgent,
 		metadataJSON,
 		log.Timestamp,
-	)
-	return err
+		log.ChainSequence,
+		log.PrevHash,
+		log.EntryHash,
+	); err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(`
+		INSERT INTO audit_chain_heads (organization_id, chain_sequence, entry_hash, updated_at)
+		VALUES ($1, $2, $3, NOW())
+		ON CONFLICT (organization_id) DO UPDATE
+		SET chain_sequence = EXCLUDED.chain_sequence, entry_hash = EXCLUDED.entry_hash, updated_at = EXCLUDED.updated_at
+	`, log.OrganizationID, log.ChainSequence, log.EntryHash); err != nil {
+		return fmt.Errorf("failed to update audit chain head: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+// GetChainHead returns the last entry of an organization's chain, nil if it has none
+func (r *AuditLogRepository) GetChainHead(orgID uuid.UUID) (*domain.AuditChainHead, error) {
+	head := &domain.AuditChainHead{}
+	err := r.db.QueryRow(`
+		SELECT organization_id, chain_sequence, entry_hash, updated_at
+		FROM audit_chain_heads
+		WHERE organization_id = $1
+	`, orgID).Scan(&head.OrganizationID, &head.ChainSequence, &head.EntryHash, &head.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return head, nil
+}
+
+// GetChain returns up to limit chained entries of an organization after afterSequence, in chain order
+func (r *AuditLogRepository) GetChain(orgID uuid.UUID, afterSequence int64, limit int) ([]*domain.AuditLog, error) {
+	query := `
+		SELECT id, organization_id, user_id, action, resource_type, resource_id, COALESCE(ip_address, ''), COALESCE(user_agent, ''),
+			metadata, timestamp, chain_sequence, COALESCE(prev_hash, ''), entry_hash
+		FROM audit_logs
+		WHERE organization_id = $1 AND chain_sequence > $2
+		ORDER BY chain_sequence ASC
+		LIMIT $3
+	`
+
+	rows, err := r.db.Query(query, orgID, afterSequence, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var logs []*domain.AuditLog
+	for rows.Next() {
+		log := &domain.AuditLog{}
+		var metadataJSON []byte
+		if err := rows.Scan(
+			&log.ID,
+			&log.OrganizationID,
+			&log.UserID,
+			&log.Action,
+			&log.ResourceType,
+			&log.ResourceID,
+			&log.IPAddress,
+			&log.UserAgent,
+			&metadataJSON,
+			&log.Timestamp,
+			&log.ChainSequence,
+			&log.PrevHash,
+			&log.EntryHash,
+		); err != nil {
+			return nil, err
+		}
+
+		if len(metadataJSON) > 0 {
+			if err := json.Unmarshal(metadataJSON, &log.Metadata); err != nil {
+				return nil, err
+			}
+		}
+		logs = append(logs, log)
+	}
+
+	return logs, rows.Err()
 }
 
 func (r *AuditLogRepository) GetByOrganization(orgID uuid.UUID, limit, offset int) ([]*domain.AuditLog, error) {
@@ -103,19 +221,59 @@ func (r *AuditLogRepository) GetByResource(resourceType string, resourceID uuid.
 	return r.scanLogs(rows)
 }
 
-func (r *AuditLogRepository) Search(query string, limit, offset int) ([]*domain.AuditLog, error) {
-	// This would integrate with Elasticsearch for full-text search
-	// For now, implement basic SQL search
-	sqlQuery := `
+// Search returns a page of an organization's audit logs matching filter, newest first, with the total count
+func (r *AuditLogRepository) Search(orgID uuid.UUID, filter domain.AuditLogFilter, limit, offset int) ([]*domain.AuditLog, int, error) {
+	where, args := buildAuditLogSearchWhere(orgID, filter)
+
+	var total int
+	if err := r.db.QueryRow(`SELECT COUNT(*) FROM audit_logs WHERE `+where, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	query := fmt.Sprintf(`
 		SELECT id, organization_id, user_id, action, resource_type, resource_id, ip_address, user_agent, metadata, timestamp
 		FROM audit_logs
-		WHERE action LIKE $1 OR resource_type LIKE $1
-		ORDER BY timestamp DESC
-		LIMIT $2 OFFSET $3
-	`
+		WHERE %s
+		ORDER BY timestamp DESC, id DESC
+		LIMIT $%d OFFSET $%d
+	`, where, len(args)+1, len(args)+2)
+	args = append(args, limit, offset)
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
 
-	searchPattern := "%" + query + "%"
-	rows, err := r.db.Query(sqlQuery, searchPattern, limit, offset)
+	logs, err := r.scanLogs(rows)
+	if err != nil {
+		return nil, 0, err
+	}
+	if logs == nil {
+		logs = []*domain.AuditLog{}
+	}
+	return logs, total, nil
+}
+
+// SearchAfter returns up to limit of an organization's audit logs matching filter, newest first,
+// starting right after the cursor
+func (r *AuditLogRepository) SearchAfter(orgID uuid.UUID, filter domain.AuditLogFilter, after *domain.AuditLogCursor, limit int) ([]*domain.AuditLog, error) {
+	where, args := buildAuditLogSearchWhere(orgID, filter)
+	if after != nil {
+		where += fmt.Sprintf(" AND (timestamp, id) < ($%d, $%d)", len(args)+1, len(args)+2)
+		args = append(args, after.Timestamp.UTC(), after.ID)
+	}
+
+	query := fmt.Sprintf(`
+		SELECT id, organization_id, user_id, action, resource_type, resource_id, ip_address, user_agent, metadata, timestamp
+		FROM audit_logs
+		WHERE %s
+		ORDER BY timestamp DESC, id DESC
+		LIMIT $%d
+	`, where, len(args)+1)
+	args = append(args, limit)
+
+	rows, err := r.db.Query(query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -124,6 +282,43 @@ func (r *AuditLogRepository) Search(query string, limit, offset int) ([]*domain.
 	return r.scanLogs(rows)
 }
 
+// buildAuditLogSearchWhere returns the WHERE clause and arguments shared by the audit log count and page queries
+func buildAuditLogSearchWhere(orgID uuid.UUID, filter domain.AuditLogFilter) (string, []interface{}) {
+	conditions := []string{"organization_id = $1"}
+	args := []interface{}{orgID}
+
+	addArg := func(value interface{}) string {
+		args = append(args, value)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	if filter.Action != "" {
+		conditions = append(conditions, "action = "+addArg(string(filter.Action)))
+	}
+	if filter.ResourceType != "" {
+		conditions = append(conditions, "resource_type = "+addArg(filter.ResourceType))
+	}
+	if filter.ResourceID != nil {
+		conditions = append(conditions, "resource_id = "+addArg(*filter.ResourceID))
+	}
+	if filter.UserID != nil {
+		conditions = append(conditions, "user_id = "+addArg(*filter.UserID))
+	}
+	// timestamp has no time zone and holds UTC
+	if filter.StartDate != nil {
+		conditions = append(conditions, "timestamp >= "+addArg(filter.StartDate.UTC()))
+	}
+	if filter.EndDate != nil {
+		conditions = append(conditions, "timestamp < "+addArg(filter.EndDate.UTC()))
+	}
+	if filter.Query != "" {
+		pattern := addArg("%" + escapeLikePattern(filter.Query) + "%")
+		conditions = append(conditions, "(action ILIKE "+pattern+" OR resource_type ILIKE "+pattern+")")
+	}
+
+	return strings.Join(conditions, " AND "), args
+}
+
 func (r *AuditLogRepository) scanLogs(rows *sql.Rows) ([]*domain.AuditLog, error) {
 	var logs []*domain.AuditLog
 
@@ -0,0 +1,207 @@
+package repository
+
+import (
+	"database/sql"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestAuditLog(orgID uuid.UUID) *domain.AuditLog {
+	return &domain.AuditLog{
+		ID:             uuid.New(),
+		OrganizationID: orgID,
+		UserID:         uuid.New(),
+		Action:         domain.AuditActionCreate,
+		ResourceType:   "agent",
+		ResourceID:     uuid.New(),
+		IPAddress:      "10.0.0.1",
+		UserAgent:      "sdk/1.0",
+		Metadata:       map[string]interface{}{"name": "billing-agent"},
+		Timestamp:      time.Date(2025, 6, 1, 12, 0, 0, 123456789, time.UTC),
+	}
+}
+
+func TestAuditLogRepository_Create_LinksToChainHead(t *testing.T) {
+	db, mock := setupAgentTestDB(t)
+	repo := NewAuditLogRepository(db)
+	orgID := uuid.New()
+	log := newTestAuditLog(orgID)
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("SELECT pg_advisory_xact_lock(hashtext($1))")).
+		WithArgs("audit_logs:" + orgID.String()).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT chain_sequence, entry_hash")).
+		WithArgs(orgID).
+		WillReturnRows(sqlmock.NewRows([]string{"chain_sequence", "entry_hash"}).AddRow(41, "head-hash"))
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO audit_logs")).
+		WithArgs(log.ID, orgID, log.UserID, log.Action, log.ResourceType, log.ResourceID, log.IPAddress, log.UserAgent,
+			[]byte(`{"name":"billing-agent"}`), time.Date(2025, 6, 1, 12, 0, 0, 123456000, time.UTC),
+			int64(42), "head-hash", sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO audit_chain_heads")).
+		WithArgs(orgID, int64(42), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	require.NoError(t, repo.Create(log))
+	assert.Equal(t, int64(42), log.ChainSequence)
+	assert.Equal(t, "head-hash", log.PrevHash)
+	expected, err := log.ComputeHash()
+	require.NoError(t, err)
+	assert.Equal(t, expected, log.EntryHash)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAuditLogRepository_Create_StartsChain(t *testing.T) {
+	db, mock := setupAgentTestDB(t)
+	repo := NewAuditLogRepository(db)
+	log := newTestAuditLog(uuid.New())
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("pg_advisory_xact_lock")).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT chain_sequence, entry_hash")).WillReturnError(sql.ErrNoRows)
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO audit_logs")).
+		WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(),
+			sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), int64(1), "", sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO audit_chain_heads")).
+		WithArgs(log.OrganizationID, int64(1), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	require.NoError(t, repo.Create(log))
+	assert.Equal(t, int64(1), log.ChainSequence)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAuditLogRepository_GetChainHead(t *testing.T) {
+	db, mock := setupAgentTestDB(t)
+	repo := NewAuditLogRepository(db)
+	orgID := uuid.New()
+	updatedAt := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	mock.ExpectQuery(regexp.QuoteMeta("FROM audit_chain_heads")).
+		WithArgs(orgID).
+		WillReturnRows(sqlmock.NewRows([]string{"organization_id", "chain_sequence", "entry_hash", "updated_at"}).
+			AddRow(orgID, 42, "head-hash", updatedAt))
+	mock.ExpectQuery(regexp.QuoteMeta("FROM audit_chain_heads")).
+		WithArgs(orgID).
+		WillReturnError(sql.ErrNoRows)
+
+	head, err := repo.GetChainHead(orgID)
+	require.NoError(t, err)
+	assert.Equal(t, &domain.AuditChainHead{OrganizationID: orgID, ChainSequence: 42, EntryHash: "head-hash", UpdatedAt: updatedAt}, head)
+
+	head, err = repo.GetChainHead(orgID)
+	require.NoError(t, err)
+	assert.Nil(t, head)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAuditLogRepository_GetChain_ReadsEntriesThatVerify(t *testing.T) {
+	db, mock := setupAgentTestDB(t)
+	repo := NewAuditLogRepository(db)
+	orgID := uuid.New()
+
+	first := newTestAuditLog(orgID)
+	first.Timestamp = first.Timestamp.Truncate(domain.AuditChainTimestampPrecision)
+	first.ChainSequence = 1
+	first.EntryHash, _ = first.ComputeHash()
+
+	columns := []string{"id", "organization_id", "user_id", "action", "resource_type", "resource_id", "ip_address",
+		"user_agent", "metadata", "timestamp", "chain_sequence", "prev_hash", "entry_hash"}
+	mock.ExpectQuery(regexp.QuoteMeta("ORDER BY chain_sequence ASC")).
+		WithArgs(orgID, int64(0), 100).
+		WillReturnRows(sqlmock.NewRows(columns).AddRow(first.ID, orgID, first.UserID, string(first.Action),
+			first.ResourceType, first.ResourceID, first.IPAddress, first.UserAgent,
+			// JSONB hands metadata back reformatted
+			[]byte(`{"name": "billing-agent"}`), first.Timestamp, first.ChainSequence, "", first.EntryHash))
+
+	entries, err := repo.GetChain(orgID, 0, 100)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.NoError(t, entries[0].VerifyLink(nil))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+var auditLogColumns = []string{"id", "organization_id", "user_id", "action", "resource_type", "resource_id",
+	"ip_address", "user_agent", "metadata", "timestamp"}
+
+func TestAuditLogRepository_Search_CombinedFilters(t *testing.T) {
+	db, mock := setupAgentTestDB(t)
+	repo := NewAuditLogRepository(db)
+	orgID, userID, agentID := uuid.New(), uuid.New(), uuid.New()
+	start := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2025, 6, 8, 0, 0, 0, 0, time.UTC)
+	filter := domain.AuditLogFilter{
+		Action:       domain.AuditActionUpdate,
+		ResourceType: "agent",
+		ResourceID:   &agentID,
+		UserID:       &userID,
+		StartDate:    &start,
+		EndDate:      &end,
+	}
+
+	where := "WHERE organization_id = $1 AND action = $2 AND resource_type = $3 AND resource_id = $4 AND user_id = $5 AND timestamp >= $6 AND timestamp < $7"
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT COUNT(*) FROM audit_logs "+where)).
+		WithArgs(orgID, "update", "agent", agentID, userID, start, end).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(3))
+	mock.ExpectQuery(regexp.QuoteMeta(where)+`\s+ORDER BY timestamp DESC, id DESC\s+LIMIT \$8 OFFSET \$9`).
+		WithArgs(orgID, "update", "agent", agentID, userID, start, end, 2, 0).
+		WillReturnRows(sqlmock.NewRows(auditLogColumns).
+			AddRow(uuid.New(), orgID, userID, "update", "agent", agentID, "10.0.0.1", "sdk/1.0", []byte(`{"field":"status"}`), end.Add(-time.Hour)).
+			AddRow(uuid.New(), orgID, userID, "update", "agent", agentID, "10.0.0.1", "sdk/1.0", []byte(`{}`), start.Add(time.Hour)))
+
+	logs, total, err := repo.Search(orgID, filter, 2, 0)
+	require.NoError(t, err)
+	assert.Equal(t, 3, total)
+	require.Len(t, logs, 2)
+	assert.Equal(t, "status", logs[0].Metadata["field"])
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAuditLogRepository_SearchAfter_KeysOnCursor(t *testing.T) {
+	db, mock := setupAgentTestDB(t)
+	repo := NewAuditLogRepository(db)
+	orgID := uuid.New()
+	start := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	cursor := &domain.AuditLogCursor{Timestamp: start.Add(time.Hour), ID: uuid.New()}
+
+	mock.ExpectQuery(regexp.QuoteMeta("WHERE organization_id = $1 AND timestamp >= $2 AND (timestamp, id) < ($3, $4)")+`\s+ORDER BY timestamp DESC, id DESC\s+LIMIT \$5`).
+		WithArgs(orgID, start, cursor.Timestamp, cursor.ID, 100).
+		WillReturnRows(sqlmock.NewRows(auditLogColumns).
+			AddRow(uuid.New(), orgID, uuid.New(), "view", "agent", uuid.New(), "10.0.0.1", "sdk/1.0", []byte(`{}`), start.Add(time.Minute)))
+
+	logs, err := repo.SearchAfter(orgID, domain.AuditLogFilter{StartDate: &start}, cursor, 100)
+	require.NoError(t, err)
+	require.Len(t, logs, 1)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAuditLogRepository_Search_EmptyResult(t *testing.T) {
+	db, mock := setupAgentTestDB(t)
+	repo := NewAuditLogRepository(db)
+	orgID := uuid.New()
+
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT COUNT(*) FROM audit_logs WHERE organization_id = $1 AND (action ILIKE $2 OR resource_type ILIKE $2)")).
+		WithArgs(orgID, `%100\%%`).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+	mock.ExpectQuery(regexp.QuoteMeta("LIMIT $3 OFFSET $4")).
+		WithArgs(orgID, `%100\%%`, 50, 0).
+		WillReturnRows(sqlmock.NewRows(auditLogColumns))
+
+	logs, total, err := repo.Search(orgID, domain.AuditLogFilter{Query: "100%"}, 50, 0)
+	require.NoError(t, err)
+	assert.Equal(t, 0, total)
+	assert.NotNil(t, logs)
+	assert.Empty(t, logs)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
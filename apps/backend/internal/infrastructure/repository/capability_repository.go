@@ -3,10 +3,12 @@ package repository
 import (
 	"database/sql"
 	"encoding/json"
+	"fmt"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 	"github.com/opena2a/identity/backend/internal/domain"
 )
 
@@ -26,8 +28,8 @@ func (r *CapabilityRepositoryPostgres) CreateCapability(capability *domain.Agent
 
 	query := `
 		INSERT INTO agent_capabilities (
-			id, agent_id, capability_type, capability_scope, granted_by, granted_at, created_at, updated_at
-		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+			id, agent_id, capability_type, capability_scope, granted_by, granted_at, expires_at, created_at, updated_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
 	`
 
 	capability.ID = uuid.New()
@@ -41,6 +43,7 @@ func (r *CapabilityRepositoryPostgres) CreateCapability(capability *domain.Agent
 		scopeJSON,
 		capability.GrantedBy,
 		capability.GrantedAt,
+		capability.ExpiresAt,
 		capability.CreatedAt,
 		capability.UpdatedAt,
 	)
@@ -51,7 +54,7 @@ func (r *CapabilityRepositoryPostgres) CreateCapability(capability *domain.Agent
 // GetCapabilityByID retrieves a capability by ID
 func (r *CapabilityRepositoryPostgres) GetCapabilityByID(id uuid.UUID) (*domain.AgentCapability, error) {
 	query := `
-		SELECT id, agent_id, capability_type, capability_scope, granted_by, granted_at, revoked_at, created_at, updated_at
+		SELECT id, agent_id, capability_type, capability_scope, granted_by, granted_at, revoked_at, expires_at, created_at, updated_at
 		FROM agent_capabilities
 		WHERE id = $1
 	`
@@ -60,6 +63,7 @@ func (r *CapabilityRepositoryPostgres) GetCapabilityByID(id uuid.UUID) (*domain.
 	var scopeJSON []byte
 	var grantedBy uuid.NullUUID
 	var revokedAt sql.NullTime
+	var expiresAt sql.NullTime
 
 	err := r.db.QueryRow(query, id).Scan(
 		&capability.ID,
@@ -69,6 +73,7 @@ func (r *CapabilityRepositoryPostgres) GetCapabilityByID(id uuid.UUID) (*domain.
 		&grantedBy,
 		&capability.GrantedAt,
 		&revokedAt,
+		&expiresAt,
 		&capability.CreatedAt,
 		&capability.UpdatedAt,
 	)
@@ -83,6 +88,9 @@ func (r *CapabilityRepositoryPostgres) GetCapabilityByID(id uuid.UUID) (*domain.
 	if revokedAt.Valid {
 		capability.RevokedAt = &revokedAt.Time
 	}
+	if expiresAt.Valid {
+		capability.ExpiresAt = &expiresAt.Time
+	}
 	if len(scopeJSON) > 0 {
 		json.Unmarshal(scopeJSON, &capability.CapabilityScope)
 	}
@@ -93,7 +101,7 @@ func (r *CapabilityRepositoryPostgres) GetCapabilityByID(id uuid.UUID) (*domain.
 // GetCapabilitiesByAgentID retrieves all capabilities for an agent
 func (r *CapabilityRepositoryPostgres) GetCapabilitiesByAgentID(agentID uuid.UUID) ([]*domain.AgentCapability, error) {
 	query := `
-		SELECT id, agent_id, capability_type, capability_scope, granted_by, granted_at, revoked_at, created_at, updated_at
+		SELECT id, agent_id, capability_type, capability_scope, granted_by, granted_at, revoked_at, expires_at, created_at, updated_at
 		FROM agent_capabilities
 		WHERE agent_id = $1
 		ORDER BY created_at DESC
@@ -111,6 +119,7 @@ func (r *CapabilityRepositoryPostgres) GetCapabilitiesByAgentID(agentID uuid.UUI
 		var scopeJSON []byte
 		var grantedBy uuid.NullUUID
 		var revokedAt sql.NullTime
+		var expiresAt sql.NullTime
 
 		err := rows.Scan(
 			&capability.ID,
@@ -120,6 +129,7 @@ func (r *CapabilityRepositoryPostgres) GetCapabilitiesByAgentID(agentID uuid.UUI
 			&grantedBy,
 			&capability.GrantedAt,
 			&revokedAt,
+			&expiresAt,
 			&capability.CreatedAt,
 			&capability.UpdatedAt,
 		)
@@ -134,6 +144,9 @@ func (r *CapabilityRepositoryPostgres) GetCapabilitiesByAgentID(agentID uuid.UUI
 		if revokedAt.Valid {
 			capability.RevokedAt = &revokedAt.Time
 		}
+		if expiresAt.Valid {
+			capability.ExpiresAt = &expiresAt.Time
+		}
 		if len(scopeJSON) > 0 {
 			json.Unmarshal(scopeJSON, &capability.CapabilityScope)
 		}
@@ -144,12 +157,12 @@ func (r *CapabilityRepositoryPostgres) GetCapabilitiesByAgentID(agentID uuid.UUI
 	return capabilities, nil
 }
 
-// GetActiveCapabilitiesByAgentID retrieves only non-revoked capabilities
+// GetActiveCapabilitiesByAgentID retrieves only non-revoked, unexpired capabilities
 func (r *CapabilityRepositoryPostgres) GetActiveCapabilitiesByAgentID(agentID uuid.UUID) ([]*domain.AgentCapability, error) {
 	query := `
-		SELECT id, agent_id, capability_type, capability_scope, granted_by, granted_at, revoked_at, created_at, updated_at
+		SELECT id, agent_id, capability_type, capability_scope, granted_by, granted_at, revoked_at, expires_at, created_at, updated_at
 		FROM agent_capabilities
-		WHERE agent_id = $1 AND revoked_at IS NULL
+		WHERE agent_id = $1 AND revoked_at IS NULL AND (expires_at IS NULL OR expires_at > NOW())
 		ORDER BY created_at DESC
 	`
 
@@ -165,6 +178,7 @@ func (r *CapabilityRepositoryPostgres) GetActiveCapabilitiesByAgentID(agentID uu
 		var scopeJSON []byte
 		var grantedBy uuid.NullUUID
 		var revokedAt sql.NullTime
+		var expiresAt sql.NullTime
 
 		err := rows.Scan(
 			&capability.ID,
@@ -174,6 +188,7 @@ func (r *CapabilityRepositoryPostgres) GetActiveCapabilitiesByAgentID(agentID uu
 			&grantedBy,
 			&capability.GrantedAt,
 			&revokedAt,
+			&expiresAt,
 			&capability.CreatedAt,
 			&capability.UpdatedAt,
 		)
@@ -188,6 +203,9 @@ func (r *CapabilityRepositoryPostgres) GetActiveCapabilitiesByAgentID(agentID uu
 		if revokedAt.Valid {
 			capability.RevokedAt = &revokedAt.Time
 		}
+		if expiresAt.Valid {
+			capability.ExpiresAt = &expiresAt.Time
+		}
 		if len(scopeJSON) > 0 {
 			json.Unmarshal(scopeJSON, &capability.CapabilityScope)
 		}
@@ -198,6 +216,74 @@ func (r *CapabilityRepositoryPostgres) GetActiveCapabilitiesByAgentID(agentID uu
 	return capabilities, nil
 }
 
+// GetCapabilitiesByAgentIDs retrieves the non-revoked, unexpired capabilities of several
+// agents in a single query, keyed by agent ID
+func (r *CapabilityRepositoryPostgres) GetCapabilitiesByAgentIDs(agentIDs []uuid.UUID) (map[uuid.UUID][]*domain.AgentCapability, error) {
+	result := make(map[uuid.UUID][]*domain.AgentCapability, len(agentIDs))
+	if len(agentIDs) == 0 {
+		return result, nil
+	}
+
+	ids := make([]string, len(agentIDs))
+	for i, id := range agentIDs {
+		ids[i] = id.String()
+	}
+
+	query := `
+		SELECT id, agent_id, capability_type, capability_scope, granted_by, granted_at, revoked_at, expires_at, created_at, updated_at
+		FROM agent_capabilities
+		WHERE agent_id = ANY($1::uuid[]) AND revoked_at IS NULL AND (expires_at IS NULL OR expires_at > NOW())
+		ORDER BY created_at DESC
+	`
+
+	rows, err := r.db.Query(query, pq.Array(ids))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var capability domain.AgentCapability
+		var scopeJSON []byte
+		var grantedBy uuid.NullUUID
+		var revokedAt sql.NullTime
+		var expiresAt sql.NullTime
+
+		err := rows.Scan(
+			&capability.ID,
+			&capability.AgentID,
+			&capability.CapabilityType,
+			&scopeJSON,
+			&grantedBy,
+			&capability.GrantedAt,
+			&revokedAt,
+			&expiresAt,
+			&capability.CreatedAt,
+			&capability.UpdatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		if grantedBy.Valid {
+			capability.GrantedBy = &grantedBy.UUID
+		}
+		if revokedAt.Valid {
+			capability.RevokedAt = &revokedAt.Time
+		}
+		if expiresAt.Valid {
+			capability.ExpiresAt = &expiresAt.Time
+		}
+		if len(scopeJSON) > 0 {
+			json.Unmarshal(scopeJSON, &capability.CapabilityScope)
+		}
+
+		result[capability.AgentID] = append(result[capability.AgentID], &capability)
+	}
+
+	return result, rows.Err()
+}
+
 // RevokeCapability marks a capability as revoked
 func (r *CapabilityRepositoryPostgres) RevokeCapability(id uuid.UUID, revokedAt time.Time) error {
 	query := `
@@ -327,6 +413,28 @@ func (r *CapabilityRepositoryPostgres) GetViolationsByAgentID(agentID uuid.UUID,
 	return violations, total, nil
 }
 
+// GetViolationsByAgentSince retrieves all violations of an agent at or after since, oldest first
+func (r *CapabilityRepositoryPostgres) GetViolationsByAgentSince(agentID uuid.UUID, since time.Time) ([]*domain.CapabilityViolation, error) {
+	query := `
+		SELECT cv.id, cv.agent_id, a.display_name as agent_name, cv.attempted_capability,
+			cv.registered_capabilities, cv.severity, cv.trust_score_impact,
+			cv.is_blocked, cv.source_ip, cv.request_metadata, cv.created_at
+		FROM capability_violations cv
+		LEFT JOIN agents a ON cv.agent_id = a.id
+		WHERE cv.agent_id = $1
+		AND cv.created_at >= $2
+		ORDER BY cv.created_at ASC
+	`
+
+	rows, err := r.db.Query(query, agentID, since)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return r.scanViolations(rows), nil
+}
+
 // GetRecentViolations retrieves violations from the last N minutes
 func (r *CapabilityRepositoryPostgres) GetRecentViolations(orgID uuid.UUID, minutes int) ([]*domain.CapabilityViolation, error) {
 	query := `
@@ -425,3 +533,45 @@ func (r *CapabilityRepositoryPostgres) scanViolations(rows *sql.Rows) []*domain.
 
 	return violations
 }
+
+// GetCatalog returns the capability types the organization allows, empty if it has no catalog
+func (r *CapabilityRepositoryPostgres) GetCatalog(orgID uuid.UUID) ([]*domain.CapabilityCatalogEntry, error) {
+	query := `
+		SELECT capability_type, name, description, category, risk_level
+		FROM capability_catalog
+		WHERE organization_id = $1
+		ORDER BY category, capability_type
+	`
+
+	rows, err := r.db.Query(query, orgID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load capability catalog: %w", err)
+	}
+	defer rows.Close()
+
+	var catalog []*domain.CapabilityCatalogEntry
+	for rows.Next() {
+		entry := &domain.CapabilityCatalogEntry{}
+		if err := rows.Scan(&entry.CapabilityType, &entry.Name, &entry.Description, &entry.Category, &entry.RiskLevel); err != nil {
+			return nil, err
+		}
+		catalog = append(catalog, entry)
+	}
+	return catalog, rows.Err()
+}
+
+// insertCapabilityCatalog writes entries as the organization's catalog in tx
+func insertCapabilityCatalog(tx *sql.Tx, orgID uuid.UUID, entries []*domain.CapabilityCatalogEntry) error {
+	query := `
+		INSERT INTO capability_catalog (organization_id, capability_type, name, description, category, risk_level)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (organization_id, capability_type) DO NOTHING
+	`
+
+	for _, entry := range entries {
+		if _, err := tx.Exec(query, orgID, entry.CapabilityType, entry.Name, entry.Description, entry.Category, entry.RiskLevel); err != nil {
+			return fmt.Errorf("failed to seed capability catalog: %w", err)
+		}
+	}
+	return nil
+}
@@ -23,9 +23,9 @@ func (r *capabilityRequestRepository) Create(req *domain.CapabilityRequest) erro
 	query := `
 		INSERT INTO capability_requests (
 			id, agent_id, capability_type, reason, status,
-			requested_by, requested_at, created_at, updated_at
+			requested_by, requested_at, expires_at, created_at, updated_at
 		) VALUES (
-			$1, $2, $3, $4, $5, $6, $7, $8, $9
+			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10
 		)
 	`
 
@@ -35,6 +35,9 @@ func (r *capabilityRequestRepository) Create(req *domain.CapabilityRequest) erro
 	req.UpdatedAt = now
 	req.RequestedAt = now
 	req.Status = domain.CapabilityRequestStatusPending
+	if req.ExpiresAt.IsZero() {
+		req.ExpiresAt = now.Add(domain.DefaultCapabilityRequestTTL)
+	}
 
 	_, err := r.db.Exec(
 		query,
@@ -45,6 +48,7 @@ func (r *capabilityRequestRepository) Create(req *domain.CapabilityRequest) erro
 		req.Status,
 		req.RequestedBy,
 		req.RequestedAt,
+		req.ExpiresAt,
 		req.CreatedAt,
 		req.UpdatedAt,
 	)
@@ -64,8 +68,11 @@ func (r *capabilityRequestRepository) GetByID(id uuid.UUID) (*domain.CapabilityR
 			cr.reviewed_by,
 			cr.requested_at,
 			cr.reviewed_at,
+			cr.expires_at,
+			cr.admin_reminded_at,
 			cr.created_at,
 			cr.updated_at,
+			a.organization_id AS organization_id,
 			a.name AS agent_name,
 			a.display_name AS agent_display_name,
 			u1.email AS requested_by_email,
@@ -101,8 +108,11 @@ func (r *capabilityRequestRepository) List(filter domain.CapabilityRequestFilter
 			cr.reviewed_by,
 			cr.requested_at,
 			cr.reviewed_at,
+			cr.expires_at,
+			cr.admin_reminded_at,
 			cr.created_at,
 			cr.updated_at,
+			a.organization_id AS organization_id,
 			a.name AS agent_name,
 			a.display_name AS agent_display_name,
 			u1.email AS requested_by_email,
@@ -136,6 +146,22 @@ func (r *capabilityRequestRepository) List(filter domain.CapabilityRequestFilter
 		argPos++
 	}
 
+	if filter.ExpiresBefore != nil {
+		query += fmt.Sprintf(" AND cr.expires_at <= $%d", argPos)
+		args = append(args, *filter.ExpiresBefore)
+		argPos++
+	}
+
+	if filter.RequestedBefore != nil {
+		query += fmt.Sprintf(" AND cr.requested_at <= $%d", argPos)
+		args = append(args, *filter.RequestedBefore)
+		argPos++
+	}
+
+	if filter.NotAdminReminded {
+		query += " AND cr.admin_reminded_at IS NULL"
+	}
+
 	// Order by requested_at DESC (newest first)
 	query += " ORDER BY cr.requested_at DESC"
 
@@ -189,6 +215,55 @@ func (r *capabilityRequestRepository) UpdateStatus(id uuid.UUID, status domain.C
 	return nil
 }
 
+// Expire rejects a pending request that was not reviewed before it expired
+func (r *capabilityRequestRepository) Expire(id uuid.UUID, at time.Time) error {
+	query := `
+		UPDATE capability_requests
+		SET
+			status = $1,
+			reviewed_at = $2,
+			updated_at = $2
+		WHERE id = $3 AND status = $4
+	`
+
+	result, err := r.db.Exec(query, domain.CapabilityRequestStatusRejected, at, id, domain.CapabilityRequestStatusPending)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("pending capability request not found")
+	}
+
+	return nil
+}
+
+// MarkAdminReminded records that admins were reminded about a pending request
+func (r *capabilityRequestRepository) MarkAdminReminded(id uuid.UUID, at time.Time) error {
+	query := `UPDATE capability_requests SET admin_reminded_at = $1 WHERE id = $2`
+
+	result, err := r.db.Exec(query, at, id)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("capability request not found")
+	}
+
+	return nil
+}
+
 func (r *capabilityRequestRepository) Delete(id uuid.UUID) error {
 	query := `DELETE FROM capability_requests WHERE id = $1`
 
@@ -0,0 +1,123 @@
+package repository
+
+import (
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/domain"
+)
+
+// ComplianceCheckSnapshotRepository implements domain.ComplianceCheckSnapshotRepository
+type ComplianceCheckSnapshotRepository struct {
+	db *sql.DB
+}
+
+// NewComplianceCheckSnapshotRepository creates a new compliance check snapshot repository
+func NewComplianceCheckSnapshotRepository(db *sql.DB) *ComplianceCheckSnapshotRepository {
+	return &ComplianceCheckSnapshotRepository{db: db}
+}
+
+// Create stores a compliance check snapshot
+func (r *ComplianceCheckSnapshotRepository) Create(snapshot *domain.ComplianceCheckSnapshot) error {
+	query := `
+		INSERT INTO compliance_check_snapshots (
+			id, organization_id, framework, passed, failed, total, compliance_rate, checks, run_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+	`
+
+	checks := snapshot.Checks
+	if checks == nil {
+		checks = []map[string]interface{}{}
+	}
+	checksJSON, err := json.Marshal(checks)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.Exec(query,
+		snapshot.ID,
+		snapshot.OrganizationID,
+		snapshot.Framework,
+		snapshot.Passed,
+		snapshot.Failed,
+		snapshot.Total,
+		snapshot.ComplianceRate,
+		checksJSON,
+		snapshot.RunAt,
+	)
+	return err
+}
+
+// GetLatest returns the most recent snapshot for the framework, or nil if none exists
+func (r *ComplianceCheckSnapshotRepository) GetLatest(orgID uuid.UUID, framework string) (*domain.ComplianceCheckSnapshot, error) {
+	query := `
+		SELECT id, organization_id, framework, passed, failed, total, compliance_rate, checks, run_at
+		FROM compliance_check_snapshots
+		WHERE organization_id = $1 AND framework = $2
+		ORDER BY run_at DESC
+		LIMIT 1
+	`
+
+	snapshot, err := scanComplianceCheckSnapshot(r.db.QueryRow(query, orgID, framework))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return snapshot, nil
+}
+
+// GetTimeline returns the framework's snapshots taken at or after since, oldest first
+func (r *ComplianceCheckSnapshotRepository) GetTimeline(orgID uuid.UUID, framework string, since time.Time) ([]*domain.ComplianceCheckSnapshot, error) {
+	query := `
+		SELECT id, organization_id, framework, passed, failed, total, compliance_rate, checks, run_at
+		FROM compliance_check_snapshots
+		WHERE organization_id = $1 AND framework = $2 AND run_at >= $3
+		ORDER BY run_at ASC
+	`
+
+	rows, err := r.db.Query(query, orgID, framework, since)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	snapshots := []*domain.ComplianceCheckSnapshot{}
+	for rows.Next() {
+		snapshot, err := scanComplianceCheckSnapshot(rows)
+		if err != nil {
+			return nil, err
+		}
+		snapshots = append(snapshots, snapshot)
+	}
+	return snapshots, rows.Err()
+}
+
+// scanComplianceCheckSnapshot scans a snapshot row and decodes its checks
+func scanComplianceCheckSnapshot(row interface{ Scan(dest ...any) error }) (*domain.ComplianceCheckSnapshot, error) {
+	snapshot := &domain.ComplianceCheckSnapshot{}
+	var checksJSON []byte
+
+	err := row.Scan(
+		&snapshot.ID,
+		&snapshot.OrganizationID,
+		&snapshot.Framework,
+		&snapshot.Passed,
+		&snapshot.Failed,
+		&snapshot.Total,
+		&snapshot.ComplianceRate,
+		&checksJSON,
+		&snapshot.RunAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(checksJSON, &snapshot.Checks); err != nil {
+		return nil, err
+	}
+	return snapshot, nil
+}
@@ -0,0 +1,102 @@
+package repository
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var complianceCheckSnapshotColumns = []string{
+	"id", "organization_id", "framework", "passed", "failed", "total", "compliance_rate", "checks", "run_at",
+}
+
+// jsonArg matches a JSON-encoded query argument against the expected value
+type jsonArg struct {
+	expected interface{}
+}
+
+func (a jsonArg) Match(value driver.Value) bool {
+	data, ok := value.([]byte)
+	if !ok {
+		return false
+	}
+	expected, err := json.Marshal(a.expected)
+	if err != nil {
+		return false
+	}
+	return string(data) == string(expected)
+}
+
+func TestComplianceCheckSnapshotRepository_Create(t *testing.T) {
+	db, mock := setupAgentTestDB(t)
+	repo := NewComplianceCheckSnapshotRepository(db)
+	snapshot := &domain.ComplianceCheckSnapshot{
+		ID:             uuid.New(),
+		OrganizationID: uuid.New(),
+		Framework:      "soc2",
+		Passed:         8,
+		Failed:         2,
+		Total:          10,
+		ComplianceRate: 80,
+		RunAt:          time.Date(2025, 3, 3, 0, 0, 0, 0, time.UTC),
+	}
+
+	// Nil checks are stored as an empty JSON array
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO compliance_check_snapshots")).
+		WithArgs(snapshot.ID, snapshot.OrganizationID, "soc2", 8, 2, 10, 80.0, jsonArg{[]interface{}{}}, snapshot.RunAt).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	require.NoError(t, repo.Create(snapshot))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestComplianceCheckSnapshotRepository_GetLatest_None(t *testing.T) {
+	db, mock := setupAgentTestDB(t)
+	repo := NewComplianceCheckSnapshotRepository(db)
+	orgID := uuid.New()
+
+	mock.ExpectQuery(regexp.QuoteMeta("FROM compliance_check_snapshots")).
+		WithArgs(orgID, "gdpr").
+		WillReturnRows(sqlmock.NewRows(complianceCheckSnapshotColumns))
+
+	snapshot, err := repo.GetLatest(orgID, "gdpr")
+
+	require.NoError(t, err)
+	assert.Nil(t, snapshot)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestComplianceCheckSnapshotRepository_GetTimeline(t *testing.T) {
+	db, mock := setupAgentTestDB(t)
+	repo := NewComplianceCheckSnapshotRepository(db)
+	orgID := uuid.New()
+	since := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	first := since.AddDate(0, 0, 7)
+	second := since.AddDate(0, 0, 14)
+
+	mock.ExpectQuery(regexp.QuoteMeta("WHERE organization_id = $1 AND framework = $2 AND run_at >= $3")).
+		WithArgs(orgID, "soc2", since).
+		WillReturnRows(sqlmock.NewRows(complianceCheckSnapshotColumns).
+			AddRow(uuid.New(), orgID, "soc2", 6, 4, 10, 60.0, []byte(`[{"name":"inactive_agents","passed":false}]`), first).
+			AddRow(uuid.New(), orgID, "soc2", 9, 1, 10, 90.0, []byte(`[]`), second))
+
+	snapshots, err := repo.GetTimeline(orgID, "soc2", since)
+
+	require.NoError(t, err)
+	require.Len(t, snapshots, 2)
+	assert.Equal(t, first, snapshots[0].RunAt)
+	assert.Equal(t, 60.0, snapshots[0].ComplianceRate)
+	require.Len(t, snapshots[0].Checks, 1)
+	assert.Equal(t, "inactive_agents", snapshots[0].Checks[0]["name"])
+	assert.Equal(t, second, snapshots[1].RunAt)
+	assert.Equal(t, 90.0, snapshots[1].ComplianceRate)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
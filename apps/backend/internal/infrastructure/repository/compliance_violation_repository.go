@@ -0,0 +1,172 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/domain"
+)
+
+// ComplianceViolationRepository implements domain.ComplianceViolationRepository
+type ComplianceViolationRepository struct {
+	db *sql.DB
+}
+
+// NewComplianceViolationRepository creates a new compliance violation repository
+func NewComplianceViolationRepository(db *sql.DB) *ComplianceViolationRepository {
+	return &ComplianceViolationRepository{db: db}
+}
+
+const complianceViolationColumns = `
+	id, organization_id, check_key, framework, severity, title, description, resource_type, resource_id,
+	is_remediated, remediated_by, remediated_at, remediation_notes, detected_at, cleared_at
+`
+
+// Create stores a newly detected violation. If the check already raised a violation for the
+// resource, the stored row is kept and its ID and detection time are copied into violation.
+func (r *ComplianceViolationRepository) Create(violation *domain.ComplianceViolation) error {
+	query := `
+		INSERT INTO compliance_violations (
+			id, organization_id, check_key, framework, severity, title, description,
+			resource_type, resource_id, detected_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		ON CONFLICT (organization_id, check_key, resource_type, resource_id)
+		DO UPDATE SET detected_at = compliance_violations.detected_at
+		RETURNING id, detected_at
+	`
+
+	return r.db.QueryRow(query,
+		violation.ID,
+		violation.OrganizationID,
+		violation.CheckKey,
+		violation.Framework,
+		violation.Severity,
+		violation.Title,
+		violation.Description,
+		violation.ResourceType,
+		violation.ResourceID,
+		violation.DetectedAt,
+	).Scan(&violation.ID, &violation.DetectedAt)
+}
+
+// GetByID returns the violation, or nil if it does not exist
+func (r *ComplianceViolationRepository) GetByID(id uuid.UUID) (*domain.ComplianceViolation, error) {
+	query := `SELECT ` + complianceViolationColumns + ` FROM compliance_violations WHERE id = $1`
+
+	violation, err := scanComplianceViolation(r.db.QueryRow(query, id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return violation, nil
+}
+
+// GetByOrganization returns all stored violations for an organization, newest first
+func (r *ComplianceViolationRepository) GetByOrganization(orgID uuid.UUID) ([]*domain.ComplianceViolation, error) {
+	query := `
+		SELECT ` + complianceViolationColumns + `
+		FROM compliance_violations
+		WHERE organization_id = $1
+		ORDER BY detected_at DESC, id
+	`
+
+	rows, err := r.db.Query(query, orgID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	violations := []*domain.ComplianceViolation{}
+	for rows.Next() {
+		violation, err := scanComplianceViolation(rows)
+		if err != nil {
+			return nil, err
+		}
+		violations = append(violations, violation)
+	}
+	return violations, rows.Err()
+}
+
+// Remediate records the remediation of a violation
+func (r *ComplianceViolationRepository) Remediate(id uuid.UUID, remediatedBy uuid.UUID, notes string, remediatedAt time.Time) error {
+	query := `
+		UPDATE compliance_violations
+		SET is_remediated = TRUE, remediated_by = $2, remediated_at = $3, remediation_notes = $4
+		WHERE id = $1
+	`
+
+	result, err := r.db.Exec(query, id, remediatedBy, remediatedAt, notes)
+	if err != nil {
+		return err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return fmt.Errorf("compliance violation not found")
+	}
+	return nil
+}
+
+// MarkCleared records that the violation's condition no longer holds
+func (r *ComplianceViolationRepository) MarkCleared(id uuid.UUID, at time.Time) error {
+	_, err := r.db.Exec(`UPDATE compliance_violations SET cleared_at = $2 WHERE id = $1`, id, at)
+	return err
+}
+
+// Reopen clears the remediation and cleared state of a violation whose condition fires again
+func (r *ComplianceViolationRepository) Reopen(id uuid.UUID, detectedAt time.Time) error {
+	query := `
+		UPDATE compliance_violations
+		SET is_remediated = FALSE, remediated_by = NULL, remediated_at = NULL, remediation_notes = '',
+		    cleared_at = NULL, detected_at = $2
+		WHERE id = $1
+	`
+
+	_, err := r.db.Exec(query, id, detectedAt)
+	return err
+}
+
+// scanComplianceViolation scans a row selected with complianceViolationColumns
+func scanComplianceViolation(row interface{ Scan(dest ...any) error }) (*domain.ComplianceViolation, error) {
+	violation := &domain.ComplianceViolation{}
+	var remediatedBy uuid.NullUUID
+	var remediatedAt, clearedAt sql.NullTime
+
+	err := row.Scan(
+		&violation.ID,
+		&violation.OrganizationID,
+		&violation.CheckKey,
+		&violation.Framework,
+		&violation.Severity,
+		&violation.Title,
+		&violation.Description,
+		&violation.ResourceType,
+		&violation.ResourceID,
+		&violation.IsRemediated,
+		&remediatedBy,
+		&remediatedAt,
+		&violation.RemediationNotes,
+		&violation.DetectedAt,
+		&clearedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if remediatedBy.Valid {
+		violation.RemediatedBy = &remediatedBy.UUID
+	}
+	if remediatedAt.Valid {
+		violation.RemediatedAt = &remediatedAt.Time
+	}
+	if clearedAt.Valid {
+		violation.ClearedAt = &clearedAt.Time
+	}
+	return violation, nil
+}
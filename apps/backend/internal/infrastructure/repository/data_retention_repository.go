@@ -0,0 +1,69 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// DataRetentionRepository deletes audit and monitoring data past its retention period
+type DataRetentionRepository struct {
+	db *sql.DB
+}
+
+// NewDataRetentionRepository creates a new data retention repository
+func NewDataRetentionRepository(db *sql.DB) *DataRetentionRepository {
+	return &DataRetentionRepository{db: db}
+}
+
+// PurgeAuditLogs deletes up to limit audit logs of the organization recorded before the cutoff.
+// The head of the organization's hash chain is kept however old it is, so the chain still ends at it.
+func (r *DataRetentionRepository) PurgeAuditLogs(orgID uuid.UUID, before time.Time, limit int) (int64, error) {
+	return r.purge("audit_logs", `
+		DELETE FROM audit_logs
+		WHERE id IN (
+			SELECT id FROM audit_logs
+			WHERE organization_id = $1 AND timestamp < $2
+				AND chain_sequence IS DISTINCT FROM (SELECT chain_sequence FROM audit_chain_heads WHERE organization_id = $1)
+			ORDER BY timestamp
+			LIMIT $3
+		)
+	`, orgID, before, limit)
+}
+
+// PurgeVerificationEvents deletes up to limit verification events of the organization created before the cutoff
+func (r *DataRetentionRepository) PurgeVerificationEvents(orgID uuid.UUID, before time.Time, limit int) (int64, error) {
+	return r.purge("verification_events", `
+		DELETE FROM verification_events
+		WHERE id IN (
+			SELECT id FROM verification_events
+			WHERE organization_id = $1 AND created_at < $2
+			ORDER BY created_at
+			LIMIT $3
+		)
+	`, orgID, before, limit)
+}
+
+// PurgeResolvedAlerts deletes up to limit alerts of the organization resolved before the cutoff.
+// Open alerts are kept however old they are.
+func (r *DataRetentionRepository) PurgeResolvedAlerts(orgID uuid.UUID, before time.Time, limit int) (int64, error) {
+	return r.purge("alerts", `
+		DELETE FROM alerts
+		WHERE id IN (
+			SELECT id FROM alerts
+			WHERE organization_id = $1 AND resolved_at IS NOT NULL AND resolved_at < $2
+			ORDER BY resolved_at
+			LIMIT $3
+		)
+	`, orgID, before, limit)
+}
+
+func (r *DataRetentionRepository) purge(table, query string, orgID uuid.UUID, before time.Time, limit int) (int64, error) {
+	result, err := r.db.Exec(query, orgID, before, limit)
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge %s: %w", table, err)
+	}
+	return result.RowsAffected()
+}
@@ -0,0 +1,44 @@
+package repository
+
+import (
+	"errors"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDataRetentionRepository_PurgeResolvedAlerts(t *testing.T) {
+	db, mock := setupAgentTestDB(t)
+	repo := NewDataRetentionRepository(db)
+	orgID := uuid.New()
+	before := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	// Only resolved alerts are eligible, and one batch is bounded by LIMIT
+	mock.ExpectExec(regexp.QuoteMeta("resolved_at IS NOT NULL AND resolved_at < $2")).
+		WithArgs(orgID, before, 500).
+		WillReturnResult(sqlmock.NewResult(0, 42))
+
+	deleted, err := repo.PurgeResolvedAlerts(orgID, before, 500)
+	require.NoError(t, err)
+	assert.Equal(t, int64(42), deleted)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDataRetentionRepository_PurgeAuditLogs_Error(t *testing.T) {
+	db, mock := setupAgentTestDB(t)
+	repo := NewDataRetentionRepository(db)
+	orgID := uuid.New()
+	before := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	mock.ExpectExec(regexp.QuoteMeta("DELETE FROM audit_logs")).
+		WithArgs(orgID, before, 1000).
+		WillReturnError(errors.New("lock timeout"))
+
+	_, err := repo.PurgeAuditLogs(orgID, before, 1000)
+	assert.ErrorContains(t, err, "failed to purge audit_logs")
+}
@@ -20,12 +20,15 @@ func NewMCPAttestationRepository(db *sql.DB) *MCPAttestationRepository {
 
 // ==================== Attestation Operations ====================
 
+// CreateAttestation records an attestation. Agent attestation signatures are unique, so an agent
+// attestation whose signature was already recorded returns domain.ErrAttestationSignatureExists.
 func (r *MCPAttestationRepository) CreateAttestation(attestation *domain.MCPAttestation) error {
 	query := `
 		INSERT INTO mcp_attestations (
 			id, mcp_server_id, agent_id, attestation_data, signature,
 			signature_verified, verified_at, expires_at, is_valid, created_at
 		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		ON CONFLICT (signature) WHERE agent_id IS NOT NULL DO NOTHING
 		RETURNING id, created_at
 	`
 
@@ -49,6 +52,9 @@ func (r *MCPAttestationRepository) CreateAttestation(attestation *domain.MCPAtte
 		time.Now().UTC(),
 	).Scan(&attestation.ID, &attestation.CreatedAt)
 
+	if err == sql.ErrNoRows {
+		return domain.ErrAttestationSignatureExists
+	}
 	if err != nil {
 		return fmt.Errorf("failed to create attestation: %w", err)
 	}
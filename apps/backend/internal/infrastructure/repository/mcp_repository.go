@@ -68,7 +68,8 @@ func (r *MCPServerRepository) GetByID(id uuid.UUID) (*domain.MCPServer, error) {
 			id, organization_id, name, description, url, version,
 			public_key, status, is_verified, last_verified_at, verification_url,
 			capabilities, trust_score, registered_by_agent, created_by, created_at, updated_at,
-			verification_method, attestation_count, confidence_score, last_attested_at
+			verification_method, attestation_count, confidence_score, last_attested_at,
+			last_health_check, health_status
 		FROM mcp_servers
 		WHERE id = $1
 	`
@@ -98,6 +99,8 @@ func (r *MCPServerRepository) GetByID(id uuid.UUID) (*domain.MCPServer, error) {
 		&server.AttestationCount,
 		&server.ConfidenceScore,
 		&server.LastAttestedAt,
+		&server.LastHealthCheck,
+		&server.HealthStatus,
 	)
 
 	if err == sql.ErrNoRows {
@@ -124,6 +127,7 @@ func (r *MCPServerRepository) GetByOrganization(orgID uuid.UUID) ([]*domain.MCPS
 			m.public_key, m.status, m.is_verified, m.last_verified_at, m.verification_url,
 			m.capabilities, m.trust_score, m.registered_by_agent, m.created_by, m.created_at, m.updated_at,
 			m.verification_method, m.attestation_count, m.confidence_score, m.last_attested_at,
+			m.last_health_check, m.health_status,
 			COALESCE(COUNT(v.id), 0) AS verification_count
 		FROM mcp_servers m
 		LEFT JOIN verification_events v ON v.mcp_server_id = m.id
@@ -131,7 +135,8 @@ func (r *MCPServerRepository) GetByOrganization(orgID uuid.UUID) ([]*domain.MCPS
 		GROUP BY m.id, m.organization_id, m.name, m.description, m.url, m.version,
 			m.public_key, m.status, m.is_verified, m.last_verified_at, m.verification_url,
 			m.capabilities, m.trust_score, m.registered_by_agent, m.created_by, m.created_at, m.updated_at,
-			m.verification_method, m.attestation_count, m.confidence_score, m.last_attested_at
+			m.verification_method, m.attestation_count, m.confidence_score, m.last_attested_at,
+			m.last_health_check, m.health_status
 		ORDER BY m.created_at DESC
 	`
 
@@ -168,6 +173,8 @@ func (r *MCPServerRepository) GetByOrganization(orgID uuid.UUID) ([]*domain.MCPS
 			&server.AttestationCount,
 			&server.ConfidenceScore,
 			&server.LastAttestedAt,
+			&server.LastHealthCheck,
+			&server.HealthStatus,
 			&server.VerificationCount,
 		)
 		if err != nil {
@@ -292,6 +299,68 @@ func (r *MCPServerRepository) Update(server *domain.MCPServer) error {
 	return nil
 }
 
+// UpdateHealth records the outcome of a health check
+func (r *MCPServerRepository) UpdateHealth(id uuid.UUID, status domain.MCPHealthStatus, checkedAt time.Time) error {
+	query := `UPDATE mcp_servers SET health_status = $1, last_health_check = $2 WHERE id = $3`
+
+	result, err := r.db.Exec(query, status, checkedAt, id)
+	if err != nil {
+		return fmt.Errorf("failed to update mcp server health: %w", err)
+	}
+
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		return fmt.Errorf("mcp server not found")
+	}
+
+	return nil
+}
+
+// UpdateTrustScore sets the trust score derived from agent attestations
+func (r *MCPServerRepository) UpdateTrustScore(id uuid.UUID, trustScore float64) error {
+	query := `UPDATE mcp_servers SET trust_score = $1, updated_at = NOW() WHERE id = $2`
+
+	result, err := r.db.Exec(query, trustScore, id)
+	if err != nil {
+		return fmt.Errorf("failed to update mcp server trust score: %w", err)
+	}
+
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		return fmt.Errorf("mcp server not found")
+	}
+
+	return nil
+}
+
+// GetHealthCheckTargets returns the ID and URL of every MCP server in an active organization
+func (r *MCPServerRepository) GetHealthCheckTargets() ([]*domain.MCPServer, error) {
+	query := `
+		SELECT m.id, m.organization_id, m.url
+		FROM mcp_servers m
+		JOIN organizations o ON o.id = m.organization_id
+		WHERE o.is_active = true
+		ORDER BY m.last_health_check ASC NULLS FIRST
+	`
+
+	rows, err := r.db.Query(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list mcp servers for health check: %w", err)
+	}
+	defer rows.Close()
+
+	var servers []*domain.MCPServer
+	for rows.Next() {
+		server := &domain.MCPServer{}
+		if err := rows.Scan(&server.ID, &server.OrganizationID, &server.URL); err != nil {
+			return nil, fmt.Errorf("failed to scan mcp server: %w", err)
+		}
+		servers = append(servers, server)
+	}
+
+	return servers, rows.Err()
+}
+
 func (r *MCPServerRepository) Delete(id uuid.UUID) error {
 	query := `DELETE FROM mcp_servers WHERE id = $1`
 
@@ -27,9 +27,9 @@ func (r *OAuthRepositoryPostgres) CreateRegistrationRequest(ctx context.Context,
 		INSERT INTO user_registration_requests (
 			id, email, first_name, last_name,
 			organization_id, status, requested_at,
-			password_hash, created_at, updated_at
+			password_hash, locale, created_at, updated_at
 		) VALUES (
-			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10
+			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11
 		)
 	`
 
@@ -42,6 +42,7 @@ func (r *OAuthRepositoryPostgres) CreateRegistrationRequest(ctx context.Context,
 		req.Status,
 		req.RequestedAt,
 		req.PasswordHash,
+		req.Locale,
 		req.CreatedAt,
 		req.UpdatedAt,
 	)
@@ -57,7 +58,7 @@ func (r *OAuthRepositoryPostgres) GetRegistrationRequest(ctx context.Context, id
 	query := `
 		SELECT id, email, first_name, last_name,
 			   organization_id, status, requested_at, reviewed_at, reviewed_by,
-			   rejection_reason, password_hash, created_at, updated_at
+			   rejection_reason, password_hash, locale, created_at, updated_at
 		FROM user_registration_requests
 		WHERE id = $1
 	`
@@ -76,6 +77,7 @@ func (r *OAuthRepositoryPostgres) GetRegistrationRequest(ctx context.Context, id
 		&req.ReviewedBy,
 		&req.RejectionReason,
 		&req.PasswordHash,
+		&req.Locale,
 		&req.CreatedAt,
 		&req.UpdatedAt,
 	)
@@ -108,7 +110,7 @@ func (r *OAuthRepositoryPostgres) GetRegistrationRequestByEmail(
 	query := `
 		SELECT id, email, first_name, last_name,
 			   organization_id, status, requested_at, reviewed_at, reviewed_by,
-			   rejection_reason, password_hash, created_at, updated_at
+			   rejection_reason, password_hash, locale, created_at, updated_at
 		FROM user_registration_requests
 		WHERE email = $1 AND status = $2
 		ORDER BY created_at DESC
@@ -129,6 +131,7 @@ func (r *OAuthRepositoryPostgres) GetRegistrationRequestByEmail(
 		&req.ReviewedBy,
 		&req.RejectionReason,
 		&req.PasswordHash,
+		&req.Locale,
 		&req.CreatedAt,
 		&req.UpdatedAt,
 	)
@@ -151,7 +154,7 @@ func (r *OAuthRepositoryPostgres) GetRegistrationRequestByEmailAnyStatus(
 	query := `
 		SELECT id, email, first_name, last_name,
 			   organization_id, status, requested_at, reviewed_at, reviewed_by,
-			   rejection_reason, password_hash, created_at, updated_at
+			   rejection_reason, password_hash, locale, created_at, updated_at
 		FROM user_registration_requests
 		WHERE email = $1
 		ORDER BY created_at DESC
@@ -172,6 +175,7 @@ func (r *OAuthRepositoryPostgres) GetRegistrationRequestByEmailAnyStatus(
 		&req.ReviewedBy,
 		&req.RejectionReason,
 		&req.PasswordHash,
+		&req.Locale,
 		&req.CreatedAt,
 		&req.UpdatedAt,
 	)
@@ -206,7 +210,7 @@ func (r *OAuthRepositoryPostgres) ListPendingRegistrationRequests(
 	query := `
 		SELECT id, email, first_name, last_name,
 			   organization_id, status, requested_at, reviewed_at, reviewed_by,
-			   rejection_reason, password_hash, created_at, updated_at
+			   rejection_reason, password_hash, locale, created_at, updated_at
 		FROM user_registration_requests
 		WHERE status = $1 AND (organization_id = $2 OR organization_id IS NULL)
 		ORDER BY requested_at DESC
@@ -235,6 +239,7 @@ func (r *OAuthRepositoryPostgres) ListPendingRegistrationRequests(
 			&req.ReviewedBy,
 			&req.RejectionReason,
 			&req.PasswordHash,
+			&req.Locale,
 			&req.CreatedAt,
 			&req.UpdatedAt,
 		)
@@ -2,6 +2,7 @@ package repository
 
 import (
 	"database/sql"
+	"encoding/json"
 	"fmt"
 	"time"
 
@@ -19,11 +20,13 @@ func NewOrganizationRepository(db *sql.DB) *OrganizationRepository {
 	return &OrganizationRepository{db: db}
 }
 
-// Create creates a new organization
+// Create creates a new organization and seeds it with the default capability catalog
 func (r *OrganizationRepository) Create(org *domain.Organization) error {
 	query := `
 		INSERT INTO organizations (id, name, domain, plan_type, max_agents, max_users, is_active, created_at, updated_at)
 		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING auto_verify_enabled, auto_verify_min_trust, key_rotation_days, trust_decay_half_life_days,
+		          require_capability_approval
 	`
 
 	now := time.Now()
@@ -31,7 +34,14 @@ func (r *OrganizationRepository) Create(org *domain.Organization) error {
 	org.CreatedAt = now
 	org.UpdatedAt = now
 
-	_, err := r.db.Exec(query,
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// Auto-verification, key rotation, trust decay and capability approval settings use the database defaults for new organizations
+	if err := tx.QueryRow(query,
 		org.ID,
 		org.Name,
 		org.Domain,
@@ -41,20 +51,30 @@ func (r *OrganizationRepository) Create(org *domain.Organization) error {
 		org.IsActive,
 		org.CreatedAt,
 		org.UpdatedAt,
-	)
+	).Scan(&org.AutoVerifyEnabled, &org.AutoVerifyMinTrust, &org.KeyRotationDays, &org.TrustDecayHalfLifeDays, &org.RequireCapabilityApproval); err != nil {
+		return err
+	}
 
-	return err
+	if err := insertCapabilityCatalog(tx, org.ID, domain.DefaultCapabilityCatalog()); err != nil {
+		return err
+	}
+
+	return tx.Commit()
 }
 
 // GetByID retrieves an organization by ID
 func (r *OrganizationRepository) GetByID(id uuid.UUID) (*domain.Organization, error) {
 	query := `
-		SELECT id, name, domain, plan_type, max_agents, max_users, is_active, created_at, updated_at
+		SELECT id, name, domain, plan_type, max_agents, max_users, is_active,
+		       auto_verify_enabled, auto_verify_min_trust, key_rotation_days, trust_decay_half_life_days,
+		       require_capability_approval, password_policy, retention_policy, trust_weights, violation_penalties,
+		       logo_url, email_locale, created_at, updated_at
 		FROM organizations
 		WHERE id = $1
 	`
 
 	org := &domain.Organization{}
+	var passwordPolicy, retentionPolicy, trustWeights, violationPenalties []byte
 	err := r.db.QueryRow(query, id).Scan(
 		&org.ID,
 		&org.Name,
@@ -63,6 +83,17 @@ func (r *OrganizationRepository) GetByID(id uuid.UUID) (*domain.Organization, er
 		&org.MaxAgents,
 		&org.MaxUsers,
 		&org.IsActive,
+		&org.AutoVerifyEnabled,
+		&org.AutoVerifyMinTrust,
+		&org.KeyRotationDays,
+		&org.TrustDecayHalfLifeDays,
+		&org.RequireCapabilityApproval,
+		&passwordPolicy,
+		&retentionPolicy,
+		&trustWeights,
+		&violationPenalties,
+		&org.LogoURL,
+		&org.EmailLocale,
 		&org.CreatedAt,
 		&org.UpdatedAt,
 	)
@@ -73,6 +104,9 @@ func (r *OrganizationRepository) GetByID(id uuid.UUID) (*domain.Organization, er
 	if err != nil {
 		return nil, err
 	}
+	if err := decodeOrganizationPolicies(org, passwordPolicy, retentionPolicy, trustWeights, violationPenalties); err != nil {
+		return nil, err
+	}
 
 	return org, nil
 }
@@ -80,12 +114,16 @@ func (r *OrganizationRepository) GetByID(id uuid.UUID) (*domain.Organization, er
 // GetByDomain retrieves an organization by domain
 func (r *OrganizationRepository) GetByDomain(domainName string) (*domain.Organization, error) {
 	query := `
-		SELECT id, name, domain, plan_type, max_agents, max_users, is_active, created_at, updated_at
+		SELECT id, name, domain, plan_type, max_agents, max_users, is_active,
+		       auto_verify_enabled, auto_verify_min_trust, key_rotation_days, trust_decay_half_life_days,
+		       require_capability_approval, password_policy, retention_policy, trust_weights, violation_penalties,
+		       logo_url, email_locale, created_at, updated_at
 		FROM organizations
 		WHERE domain = $1
 	`
 
 	org := &domain.Organization{}
+	var passwordPolicy, retentionPolicy, trustWeights, violationPenalties []byte
 	err := r.db.QueryRow(query, domainName).Scan(
 		&org.ID,
 		&org.Name,
@@ -94,6 +132,17 @@ func (r *OrganizationRepository) GetByDomain(domainName string) (*domain.Organiz
 		&org.MaxAgents,
 		&org.MaxUsers,
 		&org.IsActive,
+		&org.AutoVerifyEnabled,
+		&org.AutoVerifyMinTrust,
+		&org.KeyRotationDays,
+		&org.TrustDecayHalfLifeDays,
+		&org.RequireCapabilityApproval,
+		&passwordPolicy,
+		&retentionPolicy,
+		&trustWeights,
+		&violationPenalties,
+		&org.LogoURL,
+		&org.EmailLocale,
 		&org.CreatedAt,
 		&org.UpdatedAt,
 	)
@@ -104,18 +153,110 @@ func (r *OrganizationRepository) GetByDomain(domainName string) (*domain.Organiz
 	if err != nil {
 		return nil, err
 	}
+	if err := decodeOrganizationPolicies(org, passwordPolicy, retentionPolicy, trustWeights, violationPenalties); err != nil {
+		return nil, err
+	}
 
 	return org, nil
 }
 
+// ListActive retrieves all active organizations
+func (r *OrganizationRepository) ListActive() ([]*domain.Organization, error) {
+	query := `
+		SELECT id, name, domain, plan_type, max_agents, max_users, is_active,
+		       auto_verify_enabled, auto_verify_min_trust, key_rotation_days, trust_decay_half_life_days,
+		       require_capability_approval, password_policy, retention_policy, trust_weights, violation_penalties,
+		       logo_url, email_locale, created_at, updated_at
+		FROM organizations
+		WHERE is_active = TRUE
+		ORDER BY created_at
+	`
+
+	rows, err := r.db.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	orgs := []*domain.Organization{}
+	for rows.Next() {
+		org := &domain.Organization{}
+		var passwordPolicy, retentionPolicy, trustWeights, violationPenalties []byte
+		if err := rows.Scan(
+			&org.ID,
+			&org.Name,
+			&org.Domain,
+			&org.PlanType,
+			&org.MaxAgents,
+			&org.MaxUsers,
+			&org.IsActive,
+			&org.AutoVerifyEnabled,
+			&org.AutoVerifyMinTrust,
+			&org.KeyRotationDays,
+			&org.TrustDecayHalfLifeDays,
+			&org.RequireCapabilityApproval,
+			&passwordPolicy,
+			&retentionPolicy,
+			&trustWeights,
+			&violationPenalties,
+			&org.LogoURL,
+			&org.EmailLocale,
+			&org.CreatedAt,
+			&org.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		if err := decodeOrganizationPolicies(org, passwordPolicy, retentionPolicy, trustWeights, violationPenalties); err != nil {
+			return nil, err
+		}
+		orgs = append(orgs, org)
+	}
+
+	return orgs, rows.Err()
+}
+
 // Update updates an organization
 func (r *OrganizationRepository) Update(org *domain.Organization) error {
 	query := `
 		UPDATE organizations
-		SET name = $1, plan_type = $2, max_agents = $3, max_users = $4, is_active = $5, updated_at = $6
-		WHERE id = $7
+		SET name = $1, plan_type = $2, max_agents = $3, max_users = $4, is_active = $5,
+		    auto_verify_enabled = $6, auto_verify_min_trust = $7, key_rotation_days = $8,
+		    trust_decay_half_life_days = $9, password_policy = $10, retention_policy = $11, trust_weights = $12,
+		    require_capability_approval = $13, violation_penalties = $14, logo_url = $15, email_locale = $16,
+		    updated_at = $17
+		WHERE id = $18
 	`
 
+	var passwordPolicy []byte
+	if org.PasswordPolicy != nil {
+		var err error
+		if passwordPolicy, err = json.Marshal(org.PasswordPolicy); err != nil {
+			return fmt.Errorf("failed to marshal password policy: %w", err)
+		}
+	}
+	var retentionPolicy []byte
+	if org.RetentionPolicy != nil {
+		var err error
+		if retentionPolicy, err = json.Marshal(org.RetentionPolicy); err != nil {
+			return fmt.Errorf("failed to marshal retention policy: %w", err)
+		}
+	}
+	var trustWeights []byte
+	if org.TrustWeights != nil {
+		var err error
+		if trustWeights, err = json.Marshal(org.TrustWeights); err != nil {
+			return fmt.Errorf("failed to marshal trust weights: %w", err)
+		}
+	}
+
+	var violationPenalties []byte
+	if org.ViolationPenalties != nil {
+		var err error
+		if violationPenalties, err = json.Marshal(org.ViolationPenalties); err != nil {
+			return fmt.Errorf("failed to marshal violation penalties: %w", err)
+		}
+	}
+
 	org.UpdatedAt = time.Now()
 
 	_, err := r.db.Exec(query,
@@ -124,6 +265,17 @@ func (r *OrganizationRepository) Update(org *domain.Organization) error {
 		org.MaxAgents,
 		org.MaxUsers,
 		org.IsActive,
+		org.AutoVerifyEnabled,
+		org.AutoVerifyMinTrust,
+		org.KeyRotationDays,
+		org.TrustDecayHalfLifeDays,
+		passwordPolicy,
+		retentionPolicy,
+		trustWeights,
+		org.RequireCapabilityApproval,
+		violationPenalties,
+		org.LogoURL,
+		org.EmailLocale,
 		org.UpdatedAt,
 		org.ID,
 	)
@@ -137,3 +289,54 @@ func (r *OrganizationRepository) Delete(id uuid.UUID) error {
 	_, err := r.db.Exec(query, id)
 	return err
 }
+
+// decodeOrganizationPolicies sets org.PasswordPolicy, org.RetentionPolicy, org.TrustWeights and
+// org.ViolationPenalties from the password_policy, retention_policy, trust_weights and
+// violation_penalties columns (NULL keeps the default)
+func decodeOrganizationPolicies(org *domain.Organization, passwordPolicy, retentionPolicy, trustWeights, violationPenalties []byte) error {
+	if len(passwordPolicy) > 0 {
+		policy := &domain.PasswordPolicy{}
+		if err := json.Unmarshal(passwordPolicy, policy); err != nil {
+			return fmt.Errorf("failed to unmarshal password policy: %w", err)
+		}
+		org.PasswordPolicy = policy
+	}
+	if len(retentionPolicy) > 0 {
+		// Periods missing from the stored policy keep their defaults rather than becoming 0 days
+		policy := domain.DefaultDataRetentionPolicy()
+		if err := json.Unmarshal(retentionPolicy, &policy); err != nil {
+			return fmt.Errorf("failed to unmarshal retention policy: %w", err)
+		}
+		org.RetentionPolicy = &policy
+	}
+	if len(trustWeights) > 0 {
+		weights := &domain.TrustWeights{}
+		if err := json.Unmarshal(trustWeights, weights); err != nil {
+			return fmt.Errorf("failed to unmarshal trust weights: %w", err)
+		}
+		org.TrustWeights = weights
+	}
+	if len(violationPenalties) > 0 {
+		penalties := &domain.ViolationPenalties{}
+		if err := json.Unmarshal(violationPenalties, penalties); err != nil {
+			return fmt.Errorf("failed to unmarshal violation penalties: %w", err)
+		}
+		org.ViolationPenalties = penalties
+	}
+	return nil
+}
+
+// lockOrganizationLimits locks the organization row until tx ends and returns the organization
+// with its max_agents and max_users set. Quota checks count and insert while holding the lock, so
+// concurrent creations in one organization cannot both take its last slot.
+func lockOrganizationLimits(tx *sql.Tx, orgID uuid.UUID) (*domain.Organization, error) {
+	org := &domain.Organization{ID: orgID}
+	err := tx.QueryRow(
+		`SELECT max_agents, max_users FROM organizations WHERE id = $1 FOR UPDATE`,
+		orgID,
+	).Scan(&org.MaxAgents, &org.MaxUsers)
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock organization limits: %w", err)
+	}
+	return org, nil
+}
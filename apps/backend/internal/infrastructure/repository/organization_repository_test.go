@@ -0,0 +1,21 @@
+package repository
+
+import (
+	"testing"
+
+	"github.com/opena2a/identity/backend/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecodeOrganizationPolicies_PartialRetentionPolicyKeepsDefaults(t *testing.T) {
+	org := &domain.Organization{}
+	require.NoError(t, decodeOrganizationPolicies(org, nil, []byte(`{"auditLogDays": 730}`), nil, nil))
+
+	require.NotNil(t, org.RetentionPolicy)
+	assert.Equal(t, domain.DataRetentionPolicy{
+		AuditLogDays:          730,
+		VerificationEventDays: domain.DefaultVerificationEventRetentionDays,
+		ResolvedAlertDays:     domain.DefaultResolvedAlertRetentionDays,
+	}, *org.RetentionPolicy)
+}
@@ -0,0 +1,146 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/domain"
+)
+
+// OutboxRepository implements domain.OutboxRepository
+type OutboxRepository struct {
+	db *sql.DB
+}
+
+// NewOutboxRepository creates a new outbox repository
+func NewOutboxRepository(db *sql.DB) *OutboxRepository {
+	return &OutboxRepository{db: db}
+}
+
+// enqueueOutbox writes messages in tx, the transaction of the change that caused them
+func enqueueOutbox(tx *sql.Tx, messages []*domain.OutboxMessage) error {
+	query := `
+		INSERT INTO outbox (id, organization_id, topic, payload, status, attempts, available_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`
+
+	for _, message := range messages {
+		if _, err := tx.Exec(query,
+			message.ID,
+			message.OrganizationID,
+			message.Topic,
+			[]byte(message.Payload),
+			message.Status,
+			message.Attempts,
+			message.AvailableAt,
+			message.CreatedAt,
+		); err != nil {
+			return fmt.Errorf("failed to write %s outbox message: %w", message.Topic, err)
+		}
+	}
+	return nil
+}
+
+// Enqueue writes messages in a transaction of their own
+func (r *OutboxRepository) Enqueue(messages ...*domain.OutboxMessage) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := enqueueOutbox(tx, messages); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// ClaimDue returns up to limit due pending messages and pushes them back by lease.
+// SKIP LOCKED lets several relays claim concurrently without handing out the same message.
+func (r *OutboxRepository) ClaimDue(now time.Time, lease time.Duration, limit int) ([]*domain.OutboxMessage, error) {
+	query := `
+		UPDATE outbox
+		SET attempts = attempts + 1, available_at = $3
+		WHERE id IN (
+			SELECT id FROM outbox
+			WHERE status = $1 AND available_at <= $2
+			ORDER BY available_at ASC
+			LIMIT $4
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING id, organization_id, topic, payload, status, attempts, COALESCE(last_error, ''),
+		          available_at, created_at, delivered_at
+	`
+
+	rows, err := r.db.Query(query, domain.OutboxStatusPending, now, now.Add(lease), limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim outbox messages: %w", err)
+	}
+	defer rows.Close()
+
+	var messages []*domain.OutboxMessage
+	for rows.Next() {
+		message := &domain.OutboxMessage{}
+		var payload []byte
+		if err := rows.Scan(
+			&message.ID,
+			&message.OrganizationID,
+			&message.Topic,
+			&payload,
+			&message.Status,
+			&message.Attempts,
+			&message.LastError,
+			&message.AvailableAt,
+			&message.CreatedAt,
+			&message.DeliveredAt,
+		); err != nil {
+			return nil, err
+		}
+		message.Payload = payload
+		messages = append(messages, message)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// RETURNING does not preserve the subquery's order
+	sort.Slice(messages, func(i, j int) bool { return messages[i].CreatedAt.Before(messages[j].CreatedAt) })
+	return messages, nil
+}
+
+// MarkDelivered records that the message's side effect was performed
+func (r *OutboxRepository) MarkDelivered(id uuid.UUID, at time.Time) error {
+	query := `UPDATE outbox SET status = $2, delivered_at = $3, last_error = NULL WHERE id = $1`
+
+	if _, err := r.db.Exec(query, id, domain.OutboxStatusDelivered, at); err != nil {
+		return fmt.Errorf("failed to mark outbox message delivered: %w", err)
+	}
+	return nil
+}
+
+// MarkRetry records a failed attempt and makes the message available again at retryAt
+func (r *OutboxRepository) MarkRetry(id uuid.UUID, lastError string, retryAt time.Time) error {
+	query := `UPDATE outbox SET last_error = $2, available_at = $3 WHERE id = $1`
+
+	if _, err := r.db.Exec(query, id, lastError, retryAt); err != nil {
+		return fmt.Errorf("failed to reschedule outbox message: %w", err)
+	}
+	return nil
+}
+
+// MarkFailed records a failed attempt and gives up on the message
+func (r *OutboxRepository) MarkFailed(id uuid.UUID, lastError string) error {
+	query := `UPDATE outbox SET status = $2, last_error = $3 WHERE id = $1`
+
+	if _, err := r.db.Exec(query, id, domain.OutboxStatusFailed, lastError); err != nil {
+		return fmt.Errorf("failed to mark outbox message failed: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,122 @@
+package repository
+
+import (
+	"errors"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestAlertOutboxMessage(t *testing.T) *domain.OutboxMessage {
+	message, err := domain.NewAlertOutboxMessage(&domain.Alert{
+		OrganizationID: uuid.New(),
+		AlertType:      domain.AlertSecurityBreach,
+		Title:          "Refresh token reuse detected",
+	})
+	require.NoError(t, err)
+	return message
+}
+
+func TestRefreshTokenRepository_RevokeFamily_WritesOutboxInSameTransaction(t *testing.T) {
+	db, mock := setupAgentTestDB(t)
+	repo := NewRefreshTokenRepository(db)
+	familyID := uuid.New()
+	at := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	message := newTestAlertOutboxMessage(t)
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE refresh_tokens")).
+		WithArgs(familyID, at, "reuse").
+		WillReturnResult(sqlmock.NewResult(0, 3))
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO outbox")).
+		WithArgs(message.ID, message.OrganizationID, domain.OutboxTopicAlert, []byte(message.Payload),
+			domain.OutboxStatusPending, 0, message.AvailableAt, message.CreatedAt).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	revoked, err := repo.RevokeFamily(familyID, "reuse", at, message)
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), revoked)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRefreshTokenRepository_RevokeFamily_OutboxFailureRollsBack(t *testing.T) {
+	db, mock := setupAgentTestDB(t)
+	repo := NewRefreshTokenRepository(db)
+	familyID := uuid.New()
+	at := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	// Without its outbox row the revocation must not commit either
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE refresh_tokens")).
+		WillReturnResult(sqlmock.NewResult(0, 3))
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO outbox")).
+		WillReturnError(errors.New("disk full"))
+	mock.ExpectRollback()
+
+	_, err := repo.RevokeFamily(familyID, "reuse", at, newTestAlertOutboxMessage(t))
+	assert.ErrorContains(t, err, "failed to write alert outbox message")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestOutboxRepository_ClaimDue(t *testing.T) {
+	db, mock := setupAgentTestDB(t)
+	repo := NewOutboxRepository(db)
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	first, second := uuid.New(), uuid.New()
+	orgID := uuid.New()
+
+	columns := []string{"id", "organization_id", "topic", "payload", "status", "attempts", "last_error",
+		"available_at", "created_at", "delivered_at"}
+	mock.ExpectQuery(regexp.QuoteMeta("FOR UPDATE SKIP LOCKED")).
+		WithArgs(domain.OutboxStatusPending, now, now.Add(time.Minute), 10).
+		WillReturnRows(sqlmock.NewRows(columns).
+			AddRow(second, orgID, "webhook", []byte(`{}`), "pending", 1, "", now.Add(time.Minute), now, nil).
+			AddRow(first, orgID, "alert", []byte(`{}`), "pending", 2, "timeout", now.Add(time.Minute), now.Add(-time.Hour), nil))
+
+	messages, err := repo.ClaimDue(now, time.Minute, 10)
+	require.NoError(t, err)
+	require.Len(t, messages, 2)
+	assert.Equal(t, first, messages[0].ID)
+	assert.Equal(t, domain.OutboxTopicAlert, messages[0].Topic)
+	assert.Equal(t, 2, messages[0].Attempts)
+	assert.Equal(t, "timeout", messages[0].LastError)
+	assert.Equal(t, second, messages[1].ID)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestOutboxRepository_MarkDelivered(t *testing.T) {
+	db, mock := setupAgentTestDB(t)
+	repo := NewOutboxRepository(db)
+	id := uuid.New()
+	at := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE outbox SET status = $2, delivered_at = $3")).
+		WithArgs(id, domain.OutboxStatusDelivered, at).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	require.NoError(t, repo.MarkDelivered(id, at))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestOutboxRepository_Enqueue(t *testing.T) {
+	db, mock := setupAgentTestDB(t)
+	repo := NewOutboxRepository(db)
+	message := newTestAlertOutboxMessage(t)
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO outbox")).
+		WithArgs(message.ID, message.OrganizationID, domain.OutboxTopicAlert, []byte(message.Payload),
+			domain.OutboxStatusPending, 0, message.AvailableAt, message.CreatedAt).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	require.NoError(t, repo.Enqueue(message))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,75 @@
+package repository
+
+import (
+	"errors"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPasswordResetTokenRepository_Create_SupersedesOutstandingTokens(t *testing.T) {
+	db, mock := setupAgentTestDB(t)
+	repo := NewPasswordResetTokenRepository(db)
+	now := time.Date(2025, 3, 4, 12, 0, 0, 0, time.UTC)
+	token := &domain.PasswordResetToken{
+		UserID:    uuid.New(),
+		TokenHash: "abc123",
+		ExpiresAt: now.Add(24 * time.Hour),
+		CreatedAt: now,
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE password_reset_tokens")).
+		WithArgs(token.UserID, now).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO password_reset_tokens")).
+		WithArgs(sqlmock.AnyArg(), token.UserID, "abc123", token.ExpiresAt, now).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	require.NoError(t, repo.Create(token))
+	assert.NotEqual(t, uuid.Nil, token.ID)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPasswordResetTokenRepository_Create_InsertFailureRollsBack(t *testing.T) {
+	db, mock := setupAgentTestDB(t)
+	repo := NewPasswordResetTokenRepository(db)
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE password_reset_tokens")).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO password_reset_tokens")).
+		WillReturnError(errors.New("duplicate key"))
+	mock.ExpectRollback()
+
+	err := repo.Create(&domain.PasswordResetToken{UserID: uuid.New(), TokenHash: "abc123", ExpiresAt: time.Now()})
+	require.Error(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPasswordResetTokenRepository_MarkUsed_OnlyOnce(t *testing.T) {
+	db, mock := setupAgentTestDB(t)
+	repo := NewPasswordResetTokenRepository(db)
+	id := uuid.New()
+	at := time.Date(2025, 3, 4, 12, 0, 0, 0, time.UTC)
+
+	query := regexp.QuoteMeta("UPDATE password_reset_tokens") + `[\s\S]*used_at IS NULL`
+	mock.ExpectExec(query).WithArgs(id, at).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(query).WithArgs(id, at).WillReturnResult(sqlmock.NewResult(0, 0))
+
+	used, err := repo.MarkUsed(id, at)
+	require.NoError(t, err)
+	assert.True(t, used)
+
+	used, err = repo.MarkUsed(id, at)
+	require.NoError(t, err)
+	assert.False(t, used)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
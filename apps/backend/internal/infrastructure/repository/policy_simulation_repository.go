@@ -0,0 +1,100 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/opena2a/identity/backend/internal/domain"
+)
+
+// PolicySimulationRepository implements domain.PolicySimulationRepository
+type PolicySimulationRepository struct {
+	db *sql.DB
+}
+
+// NewPolicySimulationRepository creates a new policy simulation repository
+func NewPolicySimulationRepository(db *sql.DB) *PolicySimulationRepository {
+	return &PolicySimulationRepository{db: db}
+}
+
+// Record stores a simulated block. A policy can match an action more than once while it is
+// evaluated, so a second record for the same policy and audit ID is ignored.
+func (r *PolicySimulationRepository) Record(simulation *domain.PolicySimulation) (bool, error) {
+	query := `
+		INSERT INTO policy_simulations (id, organization_id, policy_id, agent_id, action_type, resource, audit_id, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		ON CONFLICT (policy_id, audit_id) DO NOTHING
+	`
+
+	result, err := r.db.Exec(query,
+		simulation.ID,
+		simulation.OrganizationID,
+		simulation.PolicyID,
+		simulation.AgentID,
+		simulation.ActionType,
+		simulation.Resource,
+		simulation.AuditID,
+		simulation.CreatedAt,
+	)
+	if err != nil {
+		return false, fmt.Errorf("failed to record policy simulation: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return rows > 0, nil
+}
+
+// GetReport fills the would-block totals and the per-agent and per-action breakdowns of report
+func (r *PolicySimulationRepository) GetReport(report *domain.PolicySimulationReport) error {
+	var lastSimulatedAt sql.NullTime
+	err := r.db.QueryRow(`
+		SELECT COUNT(*), COUNT(DISTINCT agent_id), MAX(created_at)
+		FROM policy_simulations
+		WHERE policy_id = $1 AND created_at >= $2
+	`, report.PolicyID, report.Since).Scan(&report.WouldBlockCount, &report.AffectedAgents, &lastSimulatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to summarize policy simulations: %w", err)
+	}
+	if lastSimulatedAt.Valid {
+		report.LastSimulatedAt = &lastSimulatedAt.Time
+	}
+
+	if report.ByAgent, err = r.countBy("agent_id::text", report); err != nil {
+		return err
+	}
+	if report.ByActionType, err = r.countBy("action_type", report); err != nil {
+		return err
+	}
+	return nil
+}
+
+// countBy groups the report's simulations by column, most frequent first
+func (r *PolicySimulationRepository) countBy(column string, report *domain.PolicySimulationReport) ([]domain.PolicySimulationCount, error) {
+	query := fmt.Sprintf(`
+		SELECT %s, COUNT(*)
+		FROM policy_simulations
+		WHERE policy_id = $1 AND created_at >= $2
+		GROUP BY 1
+		ORDER BY 2 DESC, 1
+		LIMIT 50
+	`, column)
+
+	rows, err := r.db.Query(query, report.PolicyID, report.Since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to group policy simulations: %w", err)
+	}
+	defer rows.Close()
+
+	counts := []domain.PolicySimulationCount{}
+	for rows.Next() {
+		var count domain.PolicySimulationCount
+		if err := rows.Scan(&count.Key, &count.Count); err != nil {
+			return nil, err
+		}
+		counts = append(counts, count)
+	}
+	return counts, rows.Err()
+}
@@ -0,0 +1,74 @@
+package repository
+
+import (
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPolicySimulationRepository_Record_IgnoresDuplicateAuditID(t *testing.T) {
+	db, mock := setupAgentTestDB(t)
+	repo := NewPolicySimulationRepository(db)
+	simulation := &domain.PolicySimulation{
+		ID:             uuid.New(),
+		OrganizationID: uuid.New(),
+		PolicyID:       uuid.New(),
+		AgentID:        uuid.New(),
+		ActionType:     "delete_file",
+		Resource:       "/etc/passwd",
+		AuditID:        uuid.New(),
+		CreatedAt:      time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC),
+	}
+
+	insert := regexp.QuoteMeta("ON CONFLICT (policy_id, audit_id) DO NOTHING")
+	mock.ExpectExec(insert).
+		WithArgs(simulation.ID, simulation.OrganizationID, simulation.PolicyID, simulation.AgentID,
+			simulation.ActionType, simulation.Resource, simulation.AuditID, simulation.CreatedAt).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(insert).WillReturnResult(sqlmock.NewResult(0, 0))
+
+	recorded, err := repo.Record(simulation)
+	require.NoError(t, err)
+	assert.True(t, recorded)
+
+	recorded, err = repo.Record(simulation)
+	require.NoError(t, err)
+	assert.False(t, recorded)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPolicySimulationRepository_GetReport(t *testing.T) {
+	db, mock := setupAgentTestDB(t)
+	repo := NewPolicySimulationRepository(db)
+	since := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	last := since.Add(36 * time.Hour)
+	report := &domain.PolicySimulationReport{PolicyID: uuid.New(), Since: since}
+	agentA, agentB := uuid.New(), uuid.New()
+
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT COUNT(*), COUNT(DISTINCT agent_id), MAX(created_at)")).
+		WithArgs(report.PolicyID, since).
+		WillReturnRows(sqlmock.NewRows([]string{"count", "agents", "max"}).AddRow(5, 2, last))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT agent_id::text, COUNT(*)")).
+		WithArgs(report.PolicyID, since).
+		WillReturnRows(sqlmock.NewRows([]string{"key", "count"}).
+			AddRow(agentA.String(), 4).
+			AddRow(agentB.String(), 1))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT action_type, COUNT(*)")).
+		WithArgs(report.PolicyID, since).
+		WillReturnRows(sqlmock.NewRows([]string{"key", "count"}).AddRow("delete_file", 5))
+
+	require.NoError(t, repo.GetReport(report))
+	assert.Equal(t, 5, report.WouldBlockCount)
+	assert.Equal(t, 2, report.AffectedAgents)
+	require.NotNil(t, report.LastSimulatedAt)
+	assert.True(t, last.Equal(*report.LastSimulatedAt))
+	assert.Equal(t, []domain.PolicySimulationCount{{Key: agentA.String(), Count: 4}, {Key: agentB.String(), Count: 1}}, report.ByAgent)
+	assert.Equal(t, []domain.PolicySimulationCount{{Key: "delete_file", Count: 5}}, report.ByActionType)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
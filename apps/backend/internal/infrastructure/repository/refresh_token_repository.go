@@ -0,0 +1,128 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/domain"
+)
+
+// RefreshTokenRepository implements domain.RefreshTokenRepository
+type RefreshTokenRepository struct {
+	db *sql.DB
+}
+
+// NewRefreshTokenRepository creates a new refresh token repository
+func NewRefreshTokenRepository(db *sql.DB) *RefreshTokenRepository {
+	return &RefreshTokenRepository{db: db}
+}
+
+// Create stores a token; an existing row with the same token ID is left untouched
+func (r *RefreshTokenRepository) Create(token *domain.RefreshToken) error {
+	query := `
+		INSERT INTO refresh_tokens (
+			token_id, family_id, parent_token_id, user_id, organization_id, issued_at, expires_at, rotated_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		ON CONFLICT (token_id) DO NOTHING
+	`
+
+	if _, err := r.db.Exec(query,
+		token.TokenID,
+		token.FamilyID,
+		token.ParentTokenID,
+		token.UserID,
+		token.OrganizationID,
+		token.IssuedAt,
+		token.ExpiresAt,
+		token.RotatedAt,
+	); err != nil {
+		return fmt.Errorf("failed to create refresh token: %w", err)
+	}
+	return nil
+}
+
+// GetByTokenID returns the token, or nil if it is not tracked
+func (r *RefreshTokenRepository) GetByTokenID(tokenID string) (*domain.RefreshToken, error) {
+	query := `
+		SELECT token_id, family_id, parent_token_id, user_id, organization_id,
+		       issued_at, expires_at, rotated_at, revoked_at, revoke_reason
+		FROM refresh_tokens
+		WHERE token_id = $1
+	`
+
+	token := &domain.RefreshToken{}
+	err := r.db.QueryRow(query, tokenID).Scan(
+		&token.TokenID,
+		&token.FamilyID,
+		&token.ParentTokenID,
+		&token.UserID,
+		&token.OrganizationID,
+		&token.IssuedAt,
+		&token.ExpiresAt,
+		&token.RotatedAt,
+		&token.RevokedAt,
+		&token.RevokeReason,
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get refresh token: %w", err)
+	}
+	return token, nil
+}
+
+// MarkRotated sets rotated_at if the token has not been rotated or revoked yet and reports whether it did
+func (r *RefreshTokenRepository) MarkRotated(tokenID string, at time.Time) (bool, error) {
+	query := `
+		UPDATE refresh_tokens
+		SET rotated_at = $2
+		WHERE token_id = $1 AND rotated_at IS NULL AND revoked_at IS NULL
+	`
+
+	result, err := r.db.Exec(query, tokenID, at)
+	if err != nil {
+		return false, fmt.Errorf("failed to mark refresh token rotated: %w", err)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to mark refresh token rotated: %w", err)
+	}
+	return rows == 1, nil
+}
+
+// RevokeFamily revokes every unrevoked token in the family and returns how many were revoked.
+// messages are written to the outbox in the same transaction.
+func (r *RefreshTokenRepository) RevokeFamily(familyID uuid.UUID, reason string, at time.Time, messages ...*domain.OutboxMessage) (int64, error) {
+	query := `
+		UPDATE refresh_tokens
+		SET revoked_at = $2, revoke_reason = $3
+		WHERE family_id = $1 AND revoked_at IS NULL
+	`
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(query, familyID, at, reason)
+	if err != nil {
+		return 0, fmt.Errorf("failed to revoke refresh token family: %w", err)
+	}
+	revoked, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+
+	if err := enqueueOutbox(tx, messages); err != nil {
+		return 0, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return revoked, nil
+}
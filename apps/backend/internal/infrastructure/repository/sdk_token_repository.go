@@ -61,7 +61,7 @@ func (r *sdkTokenRepository) GetByID(id uuid.UUID) (*domain.SDKToken, error) {
 	query := `
 		SELECT id, user_id, organization_id, token_hash, token_id,
 		       device_name, device_fingerprint, ip_address, user_agent,
-		       last_used_at, last_ip_address, usage_count,
+		       last_used_at, last_ip_address, last_user_agent, usage_count,
 		       created_at, expires_at, revoked_at, revoke_reason, metadata
 		FROM sdk_tokens
 		WHERE id = $1
@@ -82,6 +82,7 @@ func (r *sdkTokenRepository) GetByID(id uuid.UUID) (*domain.SDKToken, error) {
 		&token.UserAgent,
 		&token.LastUsedAt,
 		&token.LastIPAddress,
+		&token.LastUserAgent,
 		&token.UsageCount,
 		&token.CreatedAt,
 		&token.ExpiresAt,
@@ -110,7 +111,7 @@ func (r *sdkTokenRepository) GetByTokenID(tokenID string) (*domain.SDKToken, err
 	query := `
 		SELECT id, user_id, organization_id, token_hash, token_id,
 		       device_name, device_fingerprint, ip_address, user_agent,
-		       last_used_at, last_ip_address, usage_count,
+		       last_used_at, last_ip_address, last_user_agent, usage_count,
 		       created_at, expires_at, revoked_at, revoke_reason, metadata
 		FROM sdk_tokens
 		WHERE token_id = $1
@@ -131,6 +132,7 @@ func (r *sdkTokenRepository) GetByTokenID(tokenID string) (*domain.SDKToken, err
 		&token.UserAgent,
 		&token.LastUsedAt,
 		&token.LastIPAddress,
+		&token.LastUserAgent,
 		&token.UsageCount,
 		&token.CreatedAt,
 		&token.ExpiresAt,
@@ -159,7 +161,7 @@ func (r *sdkTokenRepository) GetByTokenHash(tokenHash string) (*domain.SDKToken,
 	query := `
 		SELECT id, user_id, organization_id, token_hash, token_id,
 		       device_name, device_fingerprint, ip_address, user_agent,
-		       last_used_at, last_ip_address, usage_count,
+		       last_used_at, last_ip_address, last_user_agent, usage_count,
 		       created_at, expires_at, revoked_at, revoke_reason, metadata
 		FROM sdk_tokens
 		WHERE token_hash = $1
@@ -180,6 +182,7 @@ func (r *sdkTokenRepository) GetByTokenHash(tokenHash string) (*domain.SDKToken,
 		&token.UserAgent,
 		&token.LastUsedAt,
 		&token.LastIPAddress,
+		&token.LastUserAgent,
 		&token.UsageCount,
 		&token.CreatedAt,
 		&token.ExpiresAt,
@@ -208,7 +211,7 @@ func (r *sdkTokenRepository) GetByUserID(userID uuid.UUID, includeRevoked bool)
 	query := `
 		SELECT id, user_id, organization_id, token_hash, token_id,
 		       device_name, device_fingerprint, ip_address, user_agent,
-		       last_used_at, last_ip_address, usage_count,
+		       last_used_at, last_ip_address, last_user_agent, usage_count,
 		       created_at, expires_at, revoked_at, revoke_reason, metadata
 		FROM sdk_tokens
 		WHERE user_id = $1
@@ -243,6 +246,7 @@ func (r *sdkTokenRepository) GetByUserID(userID uuid.UUID, includeRevoked bool)
 			&token.UserAgent,
 			&token.LastUsedAt,
 			&token.LastIPAddress,
+			&token.LastUserAgent,
 			&token.UsageCount,
 			&token.CreatedAt,
 			&token.ExpiresAt,
@@ -270,7 +274,7 @@ func (r *sdkTokenRepository) GetByOrganizationID(organizationID uuid.UUID, inclu
 	query := `
 		SELECT id, user_id, organization_id, token_hash, token_id,
 		       device_name, device_fingerprint, ip_address, user_agent,
-		       last_used_at, last_ip_address, usage_count,
+		       last_used_at, last_ip_address, last_user_agent, usage_count,
 		       created_at, expires_at, revoked_at, revoke_reason, metadata
 		FROM sdk_tokens
 		WHERE organization_id = $1
@@ -305,6 +309,7 @@ func (r *sdkTokenRepository) GetByOrganizationID(organizationID uuid.UUID, inclu
 			&token.UserAgent,
 			&token.LastUsedAt,
 			&token.LastIPAddress,
+			&token.LastUserAgent,
 			&token.UsageCount,
 			&token.CreatedAt,
 			&token.ExpiresAt,
@@ -337,9 +342,9 @@ func (r *sdkTokenRepository) Update(token *domain.SDKToken) error {
 	query := `
 		UPDATE sdk_tokens
 		SET device_name = $1, device_fingerprint = $2,
-		    last_used_at = $3, last_ip_address = $4, usage_count = $5,
-		    revoked_at = $6, revoke_reason = $7, metadata = $8
-		WHERE id = $9
+		    last_used_at = $3, last_ip_address = $4, last_user_agent = $5, usage_count = $6,
+		    revoked_at = $7, revoke_reason = $8, metadata = $9
+		WHERE id = $10
 	`
 
 	result, err := r.db.Exec(
@@ -348,6 +353,7 @@ func (r *sdkTokenRepository) Update(token *domain.SDKToken) error {
 		token.DeviceFingerprint,
 		token.LastUsedAt,
 		token.LastIPAddress,
+		token.LastUserAgent,
 		token.UsageCount,
 		token.RevokedAt,
 		token.RevokeReason,
@@ -434,14 +440,29 @@ func (r *sdkTokenRepository) RevokeAllForUser(userID uuid.UUID, reason string) e
 	return nil
 }
 
-func (r *sdkTokenRepository) RecordUsage(tokenID string, ipAddress string) error {
+// RecordUsage adds usage.Requests to the token's usage count and stores when, from where and by
+// which client it was last used. Only an active token of usage.OrganizationID (and usage.UserID,
+// when set) is updated, so a caller cannot touch another tenant's or user's token.
+func (r *sdkTokenRepository) RecordUsage(usage domain.SDKTokenUsage) error {
 	query := `
 		UPDATE sdk_tokens
-		SET last_used_at = $1, last_ip_address = $2, usage_count = usage_count + 1
-		WHERE token_id = $3
+		SET last_used_at = $1, last_ip_address = $2, last_user_agent = $3, usage_count = usage_count + $4
+		WHERE token_id = $5
+		  AND organization_id = $6
+		  AND ($7::uuid IS NULL OR user_id = $7)
+		  AND revoked_at IS NULL
+		  AND expires_at > $1
 	`
 
-	_, err := r.db.Exec(query, time.Now(), ipAddress, tokenID)
+	_, err := r.db.Exec(query,
+		usage.UsedAt,
+		usage.IPAddress,
+		usage.UserAgent,
+		usage.Requests,
+		usage.TokenID,
+		usage.OrganizationID,
+		usage.UserID,
+	)
 	if err != nil {
 		return fmt.Errorf("failed to record SDK token usage: %w", err)
 	}
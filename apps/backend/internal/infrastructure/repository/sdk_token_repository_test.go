@@ -0,0 +1,54 @@
+package repository
+
+import (
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSDKTokenRepository_RecordUsage_ScopedToCaller(t *testing.T) {
+	db, mock := setupAgentTestDB(t)
+	repo := NewSDKTokenRepository(db)
+	userID := uuid.New()
+	usage := domain.SDKTokenUsage{
+		TokenID:        "jti-123",
+		OrganizationID: uuid.New(),
+		UserID:         &userID,
+		IPAddress:      "203.0.113.7",
+		UserAgent:      "aim-sdk-python/1.4.0",
+		UsedAt:         time.Date(2025, 3, 4, 12, 0, 0, 0, time.UTC),
+		Requests:       3,
+	}
+
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE sdk_tokens")+`[\s\S]*organization_id = \$6[\s\S]*revoked_at IS NULL`).
+		WithArgs(usage.UsedAt, "203.0.113.7", "aim-sdk-python/1.4.0", 3, "jti-123", usage.OrganizationID, userID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	require.NoError(t, repo.RecordUsage(usage))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSDKTokenRepository_RecordUsage_WithoutUser(t *testing.T) {
+	db, mock := setupAgentTestDB(t)
+	repo := NewSDKTokenRepository(db)
+	usage := domain.SDKTokenUsage{
+		TokenID:        "jti-123",
+		OrganizationID: uuid.New(),
+		IPAddress:      "203.0.113.7",
+		UsedAt:         time.Now(),
+		Requests:       1,
+	}
+
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE sdk_tokens")).
+		WithArgs(usage.UsedAt, "203.0.113.7", "", 1, "jti-123", usage.OrganizationID, nil).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	require.NoError(t, repo.RecordUsage(usage))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
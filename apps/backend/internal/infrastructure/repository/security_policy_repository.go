@@ -3,6 +3,7 @@ package repository
 import (
 	"database/sql"
 	"encoding/json"
+	"fmt"
 	"time"
 
 	"github.com/google/uuid"
@@ -21,6 +22,11 @@ func NewSecurityPolicyRepository(db *sql.DB) *SecurityPolicyRepository {
 
 // Create creates a new security policy
 func (r *SecurityPolicyRepository) Create(policy *domain.SecurityPolicy) error {
+	return insertSecurityPolicy(r.db, policy)
+}
+
+// insertSecurityPolicy applies creation defaults and inserts a policy row
+func insertSecurityPolicy(db sqlExecer, policy *domain.SecurityPolicy) error {
 	query := `
 		INSERT INTO security_policies (id, organization_id, name, description, policy_type, enforcement_action, severity_threshold, rules, applies_to, is_enabled, priority, created_at, updated_at, created_by)
 		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
@@ -42,7 +48,7 @@ func (r *SecurityPolicyRepository) Create(policy *domain.SecurityPolicy) error {
 		return err
 	}
 
-	_, err = r.db.Exec(query,
+	_, err = db.Exec(query,
 		policy.ID,
 		policy.OrganizationID,
 		policy.Name,
@@ -252,6 +258,11 @@ func (r *SecurityPolicyRepository) GetByType(orgID uuid.UUID, policyType domain.
 
 // Update updates a security policy
 func (r *SecurityPolicyRepository) Update(policy *domain.SecurityPolicy) error {
+	return updateSecurityPolicy(r.db, policy)
+}
+
+// updateSecurityPolicy writes a policy's settings
+func updateSecurityPolicy(db sqlExecer, policy *domain.SecurityPolicy) error {
 	query := `
 		UPDATE security_policies
 		SET name = $1, description = $2, policy_type = $3, enforcement_action = $4, severity_threshold = $5, rules = $6, applies_to = $7, is_enabled = $8, priority = $9, updated_at = $10
@@ -266,7 +277,7 @@ func (r *SecurityPolicyRepository) Update(policy *domain.SecurityPolicy) error {
 		return err
 	}
 
-	_, err = r.db.Exec(query,
+	_, err = db.Exec(query,
 		policy.Name,
 		policy.Description,
 		policy.PolicyType,
@@ -288,3 +299,33 @@ func (r *SecurityPolicyRepository) Delete(id uuid.UUID) error {
 	_, err := r.db.Exec(query, id)
 	return err
 }
+
+// ApplyChanges creates, updates and deletes policies in one transaction
+func (r *SecurityPolicyRepository) ApplyChanges(created, updated []*domain.SecurityPolicy, deleted []uuid.UUID) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	for _, policy := range updated {
+		if err := updateSecurityPolicy(tx, policy); err != nil {
+			return fmt.Errorf("failed to update policy %q: %w", policy.Name, err)
+		}
+	}
+	for _, policy := range created {
+		if err := insertSecurityPolicy(tx, policy); err != nil {
+			return fmt.Errorf("failed to create policy %q: %w", policy.Name, err)
+		}
+	}
+	for _, id := range deleted {
+		if _, err := tx.Exec(`DELETE FROM security_policies WHERE id = $1`, id); err != nil {
+			return fmt.Errorf("failed to delete policy %s: %w", id, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
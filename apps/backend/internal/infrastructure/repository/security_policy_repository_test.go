@@ -0,0 +1,52 @@
+package repository
+
+import (
+	"errors"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSecurityPolicyRepository_ApplyChanges_RollsBackOnFailure(t *testing.T) {
+	db, mock := setupAgentTestDB(t)
+	repo := NewSecurityPolicyRepository(db)
+	orgID := uuid.New()
+	updated := &domain.SecurityPolicy{ID: uuid.New(), OrganizationID: orgID, Name: "Block Capability Violations"}
+	created := &domain.SecurityPolicy{OrganizationID: orgID, Name: "Low Trust Floor"}
+	deleted := uuid.New()
+
+	// The update is undone when the insert after it fails, and the delete is never attempted
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE security_policies")).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO security_policies")).
+		WillReturnError(errors.New("duplicate key value violates unique constraint"))
+	mock.ExpectRollback()
+
+	err := repo.ApplyChanges([]*domain.SecurityPolicy{created}, []*domain.SecurityPolicy{updated}, []uuid.UUID{deleted})
+	assert.ErrorContains(t, err, `failed to create policy "Low Trust Floor"`)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSecurityPolicyRepository_ApplyChanges_Commits(t *testing.T) {
+	db, mock := setupAgentTestDB(t)
+	repo := NewSecurityPolicyRepository(db)
+	deleted := uuid.New()
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO security_policies")).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta("DELETE FROM security_policies WHERE id = $1")).
+		WithArgs(deleted).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	created := &domain.SecurityPolicy{OrganizationID: uuid.New(), Name: "Low Trust Floor"}
+	assert.NoError(t, repo.ApplyChanges([]*domain.SecurityPolicy{created}, nil, []uuid.UUID{deleted}))
+	assert.NotEqual(t, uuid.Nil, created.ID)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -501,7 +501,7 @@ func (r *SecurityRepository) GetSecurityMetrics(orgID uuid.UUID) (*domain.Securi
 	r.db.QueryRow(`
 		SELECT COALESCE(AVG(trust_score), 0)
 		FROM agents
-		WHERE organization_id = $1
+		WHERE organization_id = $1 AND deleted_at IS NULL
 	`, orgID).Scan(&metrics.AverageTrustScore)
 
 	// Calculate security score (simple formula)
@@ -22,9 +22,10 @@ func (r *TrustScoreRepository) Create(score *domain.TrustScore) error {
 			id, agent_id, score,
 			verification_status, uptime, success_rate, security_alerts,
 			compliance, age, drift_detection, user_feedback,
+			capability_violations, key_age, mcp_servers,
 			confidence, last_calculated, created_at
 		)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17)
 	`
 
 	if score.ID == uuid.Nil {
@@ -49,6 +50,9 @@ func (r *TrustScoreRepository) Create(score *domain.TrustScore) error {
 		score.Factors.Age,
 		score.Factors.DriftDetection,
 		score.Factors.UserFeedback,
+		score.Factors.CapabilityViolations,
+		score.Factors.KeyAge,
+		score.Factors.MCPServers,
 		score.Confidence,
 		score.LastCalculated,
 		score.CreatedAt,
@@ -67,6 +71,7 @@ func (r *TrustScoreRepository) GetLatest(agentID uuid.UUID) (*domain.TrustScore,
 			id, agent_id, score,
 			verification_status, uptime, success_rate, security_alerts,
 			compliance, age, drift_detection, user_feedback,
+			capability_violations, key_age, mcp_servers,
 			confidence, last_calculated, created_at
 		FROM trust_scores
 		WHERE agent_id = $1
@@ -87,6 +92,9 @@ func (r *TrustScoreRepository) GetLatest(agentID uuid.UUID) (*domain.TrustScore,
 		&score.Factors.Age,
 		&score.Factors.DriftDetection,
 		&score.Factors.UserFeedback,
+		&score.Factors.CapabilityViolations,
+		&score.Factors.KeyAge,
+		&score.Factors.MCPServers,
 		&score.Confidence,
 		&score.LastCalculated,
 		&score.CreatedAt,
@@ -105,6 +113,7 @@ func (r *TrustScoreRepository) GetHistory(agentID uuid.UUID, limit int) ([]*doma
 			id, agent_id, score,
 			verification_status, uptime, success_rate, security_alerts,
 			compliance, age, drift_detection, user_feedback,
+			capability_violations, key_age, mcp_servers,
 			confidence, last_calculated, created_at
 		FROM trust_scores
 		WHERE agent_id = $1
@@ -133,6 +142,9 @@ func (r *TrustScoreRepository) GetHistory(agentID uuid.UUID, limit int) ([]*doma
 			&score.Factors.Age,
 			&score.Factors.DriftDetection,
 			&score.Factors.UserFeedback,
+			&score.Factors.CapabilityViolations,
+			&score.Factors.KeyAge,
+			&score.Factors.MCPServers,
 			&score.Confidence,
 			&score.LastCalculated,
 			&score.CreatedAt,
@@ -0,0 +1,78 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/opena2a/identity/backend/internal/domain"
+)
+
+// TwoFactorRepository implements domain.TwoFactorRepository
+type TwoFactorRepository struct {
+	db *sql.DB
+}
+
+// NewTwoFactorRepository creates a new two-factor repository
+func NewTwoFactorRepository(db *sql.DB) *TwoFactorRepository {
+	return &TwoFactorRepository{db: db}
+}
+
+// Upsert creates or replaces the user's enrollment
+func (r *TwoFactorRepository) Upsert(twoFactor *domain.UserTwoFactor) error {
+	query := `
+		INSERT INTO user_two_factor (
+			user_id, encrypted_secret, enabled, enabled_at, backup_code_hashes, last_used_step, created_at, updated_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		ON CONFLICT (user_id) DO UPDATE SET
+			encrypted_secret = EXCLUDED.encrypted_secret,
+			enabled = EXCLUDED.enabled,
+			enabled_at = EXCLUDED.enabled_at,
+			backup_code_hashes = EXCLUDED.backup_code_hashes,
+			last_used_step = EXCLUDED.last_used_step,
+			updated_at = EXCLUDED.updated_at
+	`
+
+	if _, err := r.db.Exec(query,
+		twoFactor.UserID,
+		twoFactor.EncryptedSecret,
+		twoFactor.Enabled,
+		twoFactor.EnabledAt,
+		pq.Array(nonNilStrings(twoFactor.BackupCodeHashes)),
+		twoFactor.LastUsedStep,
+		twoFactor.CreatedAt,
+		twoFactor.UpdatedAt,
+	); err != nil {
+		return fmt.Errorf("failed to save two-factor enrollment: %w", err)
+	}
+	return nil
+}
+
+// GetByUserID returns the enrollment, or nil if the user never enrolled
+func (r *TwoFactorRepository) GetByUserID(userID uuid.UUID) (*domain.UserTwoFactor, error) {
+	query := `
+		SELECT user_id, encrypted_secret, enabled, enabled_at, backup_code_hashes, last_used_step, created_at, updated_at
+		FROM user_two_factor
+		WHERE user_id = $1
+	`
+
+	twoFactor := &domain.UserTwoFactor{}
+	err := r.db.QueryRow(query, userID).Scan(
+		&twoFactor.UserID,
+		&twoFactor.EncryptedSecret,
+		&twoFactor.Enabled,
+		&twoFactor.EnabledAt,
+		pq.Array(&twoFactor.BackupCodeHashes),
+		&twoFactor.LastUsedStep,
+		&twoFactor.CreatedAt,
+		&twoFactor.UpdatedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get two-factor enrollment: %w", err)
+	}
+	return twoFactor, nil
+}
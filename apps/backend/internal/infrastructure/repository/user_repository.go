@@ -19,7 +19,9 @@ func NewUserRepository(db *sql.DB) *UserRepository {
 	return &UserRepository{db: db}
 }
 
-// Create creates a new user
+// Create creates a new user. Unless the user is created deactivated, it returns a
+// *domain.QuotaExceededError if the organization has no seat left under max_users, checked under a
+// lock on the organization row.
 func (r *UserRepository) Create(user *domain.User) error {
 	query := `
 		INSERT INTO users (id, organization_id, email, name, avatar_url, role, provider, provider_id, password_hash, status, force_password_change, approved_by, approved_at, created_at, updated_at)
@@ -46,7 +48,19 @@ func (r *UserRepository) Create(user *domain.User) error {
 		user.Status = domain.UserStatusActive
 	}
 
-	_, err := r.db.Exec(query,
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if user.Status != domain.UserStatusDeactivated {
+		if err := checkUserSeatLocked(tx, user); err != nil {
+			return err
+		}
+	}
+
+	_, err = tx.Exec(query,
 		user.ID,
 		user.OrganizationID,
 		user.Email,
@@ -63,8 +77,37 @@ func (r *UserRepository) Create(user *domain.User) error {
 		user.CreatedAt,
 		user.UpdatedAt,
 	)
+	if err != nil {
+		return err
+	}
 
-	return err
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// checkUserSeatLocked locks user's organization and returns a *domain.QuotaExceededError if the
+// other users holding a seat leave none for user under max_users. Deactivated users do not take
+// up a seat.
+func checkUserSeatLocked(tx *sql.Tx, user *domain.User) error {
+	org, err := lockOrganizationLimits(tx, user.OrganizationID)
+	if err != nil {
+		return err
+	}
+	if org.MaxUsers <= 0 {
+		return nil // Unlimited, no need to count
+	}
+
+	var seats int
+	err = tx.QueryRow(
+		`SELECT COUNT(*) FROM users WHERE organization_id = $1 AND status <> $2 AND id <> $3`,
+		user.OrganizationID, domain.UserStatusDeactivated, user.ID,
+	).Scan(&seats)
+	if err != nil {
+		return fmt.Errorf("failed to count organization users: %w", err)
+	}
+	return org.CheckUserQuota(seats, 1)
 }
 
 // GetByID retrieves a user by ID
@@ -283,6 +326,34 @@ func (r *UserRepository) GetByOrganizationAndStatus(orgID uuid.UUID, status doma
 
 // Update updates a user
 func (r *UserRepository) Update(user *domain.User) error {
+	return updateUser(r.db, user)
+}
+
+// UpdateWithinQuota saves user like Update after checking, under a lock on its organization, that
+// the other users holding a seat leave one for it under max_users. It is used when a pending or
+// deactivated user is given a seat, and returns a *domain.QuotaExceededError if there is none.
+func (r *UserRepository) UpdateWithinQuota(user *domain.User) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := checkUserSeatLocked(tx, user); err != nil {
+		return err
+	}
+	if err := updateUser(tx, user); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// updateUser writes the user's mutable fields
+func updateUser(db sqlExecer, user *domain.User) error {
 	query := `
 		UPDATE users
 		SET name = $1, avatar_url = $2, role = $3, password_hash = $4,
@@ -295,7 +366,7 @@ func (r *UserRepository) Update(user *domain.User) error {
 
 	user.UpdatedAt = time.Now()
 
-	_, err := r.db.Exec(query,
+	_, err := db.Exec(query,
 		user.Name,
 		user.AvatarURL,
 		user.Role,
@@ -328,6 +399,112 @@ func (r *UserRepository) Delete(id uuid.UUID) error {
 	return err
 }
 
+// DeactivateWithCascade deactivates the user, suspends the active agents they created and disables
+// the API keys they created or that belong to those agents, in one transaction. An agent.suspended
+// webhook event is queued in the outbox for every suspended agent.
+func (r *UserRepository) DeactivateWithCascade(userID uuid.UUID, at time.Time) (*domain.UserDeactivationCascade, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(
+		`UPDATE users SET status = $2, deleted_at = $3, updated_at = $3 WHERE id = $1`,
+		userID, domain.UserStatusDeactivated, at,
+	); err != nil {
+		return nil, fmt.Errorf("failed to deactivate user: %w", err)
+	}
+
+	cascade := &domain.UserDeactivationCascade{}
+
+	cascade.DisabledAPIKeyIDs, err = collectIDs(tx.Query(`
+		UPDATE api_keys SET is_active = false
+		WHERE is_active = true
+		  AND (created_by = $1 OR agent_id IN (SELECT id FROM agents WHERE created_by = $1))
+		RETURNING id
+	`, userID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to disable API keys: %w", err)
+	}
+
+	suspended, err := suspendUserAgents(tx, userID, at)
+	if err != nil {
+		return nil, fmt.Errorf("failed to suspend agents: %w", err)
+	}
+
+	cascade.SuspendedAgentIDs = make([]uuid.UUID, 0, len(suspended))
+	messages := make([]*domain.OutboxMessage, 0, len(suspended))
+	for _, agent := range suspended {
+		cascade.SuspendedAgentIDs = append(cascade.SuspendedAgentIDs, agent.event.AgentID)
+		message, err := domain.NewWebhookOutboxMessage(agent.organizationID, domain.WebhookEventAgentSuspended, domain.WebhookResourceAgent, agent.event)
+		if err != nil {
+			return nil, fmt.Errorf("failed to build agent.suspended webhook event: %w", err)
+		}
+		messages = append(messages, message)
+	}
+	if err := enqueueOutbox(tx, messages); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return cascade, nil
+}
+
+// suspendedAgent is an agent suspended by a user deactivation and the agent.suspended event about it
+type suspendedAgent struct {
+	organizationID uuid.UUID
+	event          domain.AgentStatusWebhookData
+}
+
+// suspendUserAgents suspends the pending and verified agents userID created and returns them with
+// the status each had before
+func suspendUserAgents(tx *sql.Tx, userID uuid.UUID, at time.Time) ([]*suspendedAgent, error) {
+	rows, err := tx.Query(`
+		UPDATE agents a SET status = $2, updated_at = $3
+		FROM (SELECT id, status FROM agents WHERE created_by = $1 AND status IN ($4, $5) FOR UPDATE) previous
+		WHERE a.id = previous.id
+		RETURNING a.id, a.organization_id, a.name, previous.status, a.trust_score
+	`, userID, domain.AgentStatusSuspended, at, domain.AgentStatusPending, domain.AgentStatusVerified)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var agents []*suspendedAgent
+	for rows.Next() {
+		agent := &suspendedAgent{event: domain.AgentStatusWebhookData{Status: domain.AgentStatusSuspended, ChangedAt: at}}
+		event := &agent.event
+		if err := rows.Scan(&event.AgentID, &agent.organizationID, &event.AgentName, &event.PreviousStatus, &event.TrustScore); err != nil {
+			return nil, err
+		}
+		// Suspension by cascade does not recalculate the trust score
+		event.PreviousTrustScore = event.TrustScore
+		agents = append(agents, agent)
+	}
+	return agents, rows.Err()
+}
+
+// collectIDs reads a single UUID column and closes the rows
+func collectIDs(rows *sql.Rows, err error) ([]uuid.UUID, error) {
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	ids := []uuid.UUID{}
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
 // CountActiveUsers returns the count of users who logged in within the specified minutes
 func (r *UserRepository) CountActiveUsers(orgID uuid.UUID, withinMinutes int) (int, error) {
 	query := `
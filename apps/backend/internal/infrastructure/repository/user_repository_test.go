@@ -0,0 +1,87 @@
+package repository
+
+import (
+	"errors"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUserRepository_DeactivateWithCascade(t *testing.T) {
+	db, mock := setupAgentTestDB(t)
+	repo := NewUserRepository(db)
+	userID, agentID, keyID, orgID := uuid.New(), uuid.New(), uuid.New(), uuid.New()
+	at := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE users SET status = $2, deleted_at = $3")).
+		WithArgs(userID, domain.UserStatusDeactivated, at).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(regexp.QuoteMeta("UPDATE api_keys SET is_active = false")).
+		WithArgs(userID).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(keyID))
+	mock.ExpectQuery(regexp.QuoteMeta("UPDATE agents a SET status = $2")).
+		WithArgs(userID, domain.AgentStatusSuspended, at, domain.AgentStatusPending, domain.AgentStatusVerified).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "organization_id", "name", "status", "trust_score"}).
+			AddRow(agentID, orgID, "billing-agent", domain.AgentStatusVerified, 0.8))
+	// The suspension is announced in the same transaction
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO outbox")).
+		WithArgs(sqlmock.AnyArg(), orgID, domain.OutboxTopicWebhook, sqlmock.AnyArg(),
+			domain.OutboxStatusPending, 0, sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	affected, err := repo.DeactivateWithCascade(userID, at)
+	require.NoError(t, err)
+	assert.Equal(t, []uuid.UUID{agentID}, affected.SuspendedAgentIDs)
+	assert.Equal(t, []uuid.UUID{keyID}, affected.DisabledAPIKeyIDs)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUserRepository_DeactivateWithCascade_RollsBackOnFailure(t *testing.T) {
+	db, mock := setupAgentTestDB(t)
+	repo := NewUserRepository(db)
+	userID := uuid.New()
+	at := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	// The user stays active if their agents cannot be suspended
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE users")).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(regexp.QuoteMeta("UPDATE api_keys")).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	mock.ExpectQuery(regexp.QuoteMeta("UPDATE agents")).
+		WillReturnError(errors.New("deadlock detected"))
+	mock.ExpectRollback()
+
+	_, err := repo.DeactivateWithCascade(userID, at)
+	assert.ErrorContains(t, err, "failed to suspend agents")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUserRepository_UpdateWithinQuota_RejectsWhenNoSeatLeft(t *testing.T) {
+	db, mock := setupAgentTestDB(t)
+	repo := NewUserRepository(db)
+	user := &domain.User{ID: uuid.New(), OrganizationID: uuid.New(), Status: domain.UserStatusActive}
+
+	// Deactivated users and the user being reactivated do not count towards the seats in use
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT max_agents, max_users FROM organizations WHERE id = $1 FOR UPDATE")).
+		WithArgs(user.OrganizationID).
+		WillReturnRows(sqlmock.NewRows([]string{"max_agents", "max_users"}).AddRow(0, 3))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT COUNT(*) FROM users WHERE organization_id = $1 AND status <> $2 AND id <> $3")).
+		WithArgs(user.OrganizationID, domain.UserStatusDeactivated, user.ID).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(3))
+	mock.ExpectRollback()
+
+	err := repo.UpdateWithinQuota(user)
+
+	assert.True(t, errors.Is(err, domain.ErrOrganizationQuotaExceeded))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
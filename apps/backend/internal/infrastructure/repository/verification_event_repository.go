@@ -52,6 +52,61 @@ func (r *VerificationEventRepositorySimple) Create(event *domain.VerificationEve
 	).Scan(&event.ID, &event.CreatedAt)
 }
 
+// verificationEventBatchColumns is the number of columns CreateBatch inserts per event
+const verificationEventBatchColumns = 30
+
+// CreateBatch inserts events with a single statement, in order. Unlike Create it keeps the events'
+// own IDs and creation times (assigning them when unset), so callers can hand events out before
+// they are written.
+func (r *VerificationEventRepositorySimple) CreateBatch(events []*domain.VerificationEvent) error {
+	if len(events) == 0 {
+		return nil
+	}
+
+	placeholders := make([]string, 0, len(events))
+	args := make([]interface{}, 0, len(events)*verificationEventBatchColumns)
+	for _, event := range events {
+		if event.ID == uuid.Nil {
+			event.ID = uuid.New()
+		}
+		if event.CreatedAt.IsZero() {
+			event.CreatedAt = time.Now()
+		}
+
+		metadataJSON, err := json.Marshal(event.Metadata)
+		if err != nil {
+			return fmt.Errorf("failed to marshal metadata for verification event %s: %w", event.ID, err)
+		}
+
+		row := make([]string, verificationEventBatchColumns)
+		for i := range row {
+			row[i] = fmt.Sprintf("$%d", len(args)+i+1)
+		}
+		placeholders = append(placeholders, "("+strings.Join(row, ", ")+")")
+		args = append(args,
+			event.ID, event.OrganizationID, event.AgentID, event.AgentName, event.Protocol, event.VerificationType,
+			event.Status, event.Result, event.Signature, event.MessageHash, event.Nonce, event.PublicKey,
+			event.Confidence, event.TrustScore, event.DurationMs, event.ErrorCode, event.ErrorReason,
+			event.InitiatorType, event.InitiatorID, event.InitiatorName, event.InitiatorIP,
+			event.Action, event.ResourceType, event.ResourceID, event.Location,
+			event.StartedAt, event.CompletedAt, event.CreatedAt, event.Details, metadataJSON,
+		)
+	}
+
+	query := `
+		INSERT INTO verification_events (
+			id, organization_id, agent_id, agent_name, protocol, verification_type,
+			status, result, signature, message_hash, nonce, public_key,
+			confidence, trust_score, duration_ms, error_code, error_reason,
+			initiator_type, initiator_id, initiator_name, initiator_ip,
+			action, resource_type, resource_id, location,
+			started_at, completed_at, created_at, details, metadata
+		) VALUES ` + strings.Join(placeholders, ", ")
+
+	_, err := r.db.Exec(query, args...)
+	return err
+}
+
 // GetByID retrieves a verification event by ID
 func (r *VerificationEventRepositorySimple) GetByID(id uuid.UUID) (*domain.VerificationEvent, error) {
 	query := `
@@ -796,6 +851,98 @@ func (r *VerificationEventRepositorySimple) GetStatistics(orgID uuid.UUID, start
 	}, nil
 }
 
+// GetStatisticsByProtocol aggregates success/failure counts and duration percentiles per protocol for a time range.
+// Percentiles are nearest-rank over the events that recorded a duration.
+func (r *VerificationEventRepositorySimple) GetStatisticsByProtocol(orgID uuid.UUID, startTime, endTime time.Time) (map[domain.VerificationProtocol]*domain.ProtocolVerificationStatistics, error) {
+	query := `
+		SELECT protocol,
+		       COUNT(*),
+		       COUNT(*) FILTER (WHERE status = $4),
+		       COUNT(*) FILTER (WHERE status IN ($5, $6)),
+		       COALESCE(percentile_disc(0.50) WITHIN GROUP (ORDER BY duration_ms), 0),
+		       COALESCE(percentile_disc(0.95) WITHIN GROUP (ORDER BY duration_ms), 0)
+		FROM verification_events
+		WHERE organization_id = $1 AND created_at BETWEEN $2 AND $3
+		GROUP BY protocol`
+
+	rows, err := r.db.Query(query, orgID, startTime, endTime,
+		domain.VerificationEventStatusSuccess, domain.VerificationEventStatusFailed, domain.VerificationEventStatusTimeout)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	stats := make(map[domain.VerificationProtocol]*domain.ProtocolVerificationStatistics)
+	for rows.Next() {
+		s := &domain.ProtocolVerificationStatistics{}
+		if err := rows.Scan(&s.Protocol, &s.Total, &s.SuccessCount, &s.FailureCount, &s.P50DurationMs, &s.P95DurationMs); err != nil {
+			return nil, err
+		}
+		stats[s.Protocol] = s
+	}
+
+	return stats, rows.Err()
+}
+
+// GetLatencyStatistics computes duration percentiles of the events in a time range that recorded a
+// duration. Postgres computes them, so the durations never leave the database.
+func (r *VerificationEventRepositorySimple) GetLatencyStatistics(orgID uuid.UUID, startTime, endTime time.Time) (*domain.VerificationLatencyStatistics, error) {
+	query := `
+		SELECT COUNT(*),
+		       COALESCE(percentile_cont(0.50) WITHIN GROUP (ORDER BY duration_ms), 0),
+		       COALESCE(percentile_cont(0.95) WITHIN GROUP (ORDER BY duration_ms), 0),
+		       COALESCE(percentile_cont(0.99) WITHIN GROUP (ORDER BY duration_ms), 0)
+		FROM verification_events
+		WHERE organization_id = $1 AND created_at BETWEEN $2 AND $3
+		AND duration_ms IS NOT NULL`
+
+	latency := &domain.VerificationLatencyStatistics{}
+	err := r.db.QueryRow(query, orgID, startTime, endTime).Scan(
+		&latency.Count,
+		&latency.P50DurationMs,
+		&latency.P95DurationMs,
+		&latency.P99DurationMs,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return latency, nil
+}
+
+// CountAgentEventsByWindow counts an agent's verification events in consecutive windows ending at now.
+// counts[0] is the window (now-window, now]; counts[i] is the i-th window before it.
+func (r *VerificationEventRepositorySimple) CountAgentEventsByWindow(agentID uuid.UUID, now time.Time, window time.Duration, windows int) ([]int, error) {
+	counts := make([]int, windows)
+	if windows <= 0 || window <= 0 {
+		return counts, nil
+	}
+
+	query := `
+		SELECT FLOOR(EXTRACT(EPOCH FROM ($2 - created_at)) / $3)::int AS bucket, COUNT(*)
+		FROM verification_events
+		WHERE agent_id = $1 AND created_at > $4 AND created_at <= $2
+		GROUP BY bucket`
+
+	since := now.Add(-window * time.Duration(windows))
+	rows, err := r.db.Query(query, agentID, now, window.Seconds(), since)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var bucket, count int
+		if err := rows.Scan(&bucket, &count); err != nil {
+			return nil, err
+		}
+		if bucket >= 0 && bucket < windows {
+			counts[bucket] += count
+		}
+	}
+
+	return counts, rows.Err()
+}
+
 // UpdateResult updates the result of a verification event
 func (r *VerificationEventRepositorySimple) UpdateResult(id uuid.UUID, result domain.VerificationResult, reason *string, metadata map[string]interface{}) error {
 	// Merge new metadata with existing metadata
@@ -0,0 +1,156 @@
+package repository
+
+import (
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVerificationEventRepository_GetStatisticsByProtocol(t *testing.T) {
+	db, mock := setupAgentTestDB(t)
+	repo := NewVerificationEventRepository(db)
+	orgID := uuid.New()
+	to := time.Now()
+	from := to.Add(-24 * time.Hour)
+
+	// Postgres groups by protocol and picks the percentiles; one row comes back per protocol
+	rows := sqlmock.NewRows([]string{"protocol", "total", "success", "failure", "p50", "p95"}).
+		AddRow("MCP", 10, 8, 2, 50, 100).
+		AddRow("A2A", 3, 1, 1, 100, 300)
+
+	mock.ExpectQuery(`COUNT\(\*\) FILTER \(WHERE status = \$4\),\s+COUNT\(\*\) FILTER \(WHERE status IN \(\$5, \$6\)\),\s+COALESCE\(percentile_disc\(0\.50\)`).
+		WithArgs(orgID, from, to, domain.VerificationEventStatusSuccess, domain.VerificationEventStatusFailed, domain.VerificationEventStatusTimeout).
+		WillReturnRows(rows)
+
+	stats, err := repo.GetStatisticsByProtocol(orgID, from, to)
+
+	require.NoError(t, err)
+	require.Len(t, stats, 2)
+
+	mcp := stats[domain.VerificationProtocolMCP]
+	require.NotNil(t, mcp)
+	assert.Equal(t, domain.VerificationProtocolMCP, mcp.Protocol)
+	assert.Equal(t, 10, mcp.Total)
+	assert.Equal(t, 8, mcp.SuccessCount)
+	assert.Equal(t, 2, mcp.FailureCount)
+	assert.Equal(t, 50.0, mcp.P50DurationMs)
+	assert.Equal(t, 100.0, mcp.P95DurationMs)
+
+	a2a := stats[domain.VerificationProtocolA2A]
+	require.NotNil(t, a2a)
+	assert.Equal(t, 3, a2a.Total)
+	assert.Equal(t, 1, a2a.SuccessCount)
+	assert.Equal(t, 1, a2a.FailureCount)
+	assert.Equal(t, 100.0, a2a.P50DurationMs)
+	assert.Equal(t, 300.0, a2a.P95DurationMs)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestVerificationEventRepository_GetStatisticsByProtocol_NoEvents(t *testing.T) {
+	db, mock := setupAgentTestDB(t)
+	repo := NewVerificationEventRepository(db)
+
+	mock.ExpectQuery(regexp.QuoteMeta("GROUP BY protocol")).
+		WillReturnRows(sqlmock.NewRows([]string{"protocol", "total", "success", "failure", "p50", "p95"}))
+
+	stats, err := repo.GetStatisticsByProtocol(uuid.New(), time.Now().Add(-time.Hour), time.Now())
+
+	require.NoError(t, err)
+	assert.NotNil(t, stats)
+	assert.Empty(t, stats)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestVerificationEventRepository_GetLatencyStatistics(t *testing.T) {
+	db, mock := setupAgentTestDB(t)
+	repo := NewVerificationEventRepository(db)
+	orgID := uuid.New()
+	to := time.Now()
+	from := to.Add(-24 * time.Hour)
+
+	// Postgres interpolates the percentiles; only the aggregate row comes back
+	mock.ExpectQuery(`SELECT COUNT\(\*\),\s+COALESCE\(percentile_cont\(0\.50\) WITHIN GROUP \(ORDER BY duration_ms\), 0\)`).
+		WithArgs(orgID, from, to).
+		WillReturnRows(sqlmock.NewRows([]string{"count", "p50", "p95", "p99"}).AddRow(100, 50.5, 95.05, 99.01))
+
+	latency, err := repo.GetLatencyStatistics(orgID, from, to)
+
+	require.NoError(t, err)
+	assert.Equal(t, &domain.VerificationLatencyStatistics{
+		Count:         100,
+		P50DurationMs: 50.5,
+		P95DurationMs: 95.05,
+		P99DurationMs: 99.01,
+	}, latency)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestVerificationEventRepository_GetLatencyStatistics_NoEvents(t *testing.T) {
+	db, mock := setupAgentTestDB(t)
+	repo := NewVerificationEventRepository(db)
+
+	mock.ExpectQuery(regexp.QuoteMeta("percentile_cont")).
+		WillReturnRows(sqlmock.NewRows([]string{"count", "p50", "p95", "p99"}).AddRow(0, 0, 0, 0))
+
+	latency, err := repo.GetLatencyStatistics(uuid.New(), time.Now().Add(-time.Hour), time.Now())
+
+	require.NoError(t, err)
+	assert.Equal(t, &domain.VerificationLatencyStatistics{}, latency)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestVerificationEventRepository_CreateBatch_SingleInsertKeepsIDs(t *testing.T) {
+	db, mock := setupAgentTestDB(t)
+	repo := NewVerificationEventRepository(db)
+	orgID := uuid.New()
+	existingID := uuid.New()
+	createdAt := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	events := []*domain.VerificationEvent{
+		{ID: existingID, OrganizationID: orgID, Status: domain.VerificationEventStatusSuccess, CreatedAt: createdAt},
+		{OrganizationID: orgID, Status: domain.VerificationEventStatusFailed},
+	}
+
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO verification_events") + `.*\$30\), \(\$31, .*\$60\)$`).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+
+	require.NoError(t, repo.CreateBatch(events))
+	assert.Equal(t, existingID, events[0].ID)
+	assert.Equal(t, createdAt, events[0].CreatedAt)
+	assert.NotEqual(t, uuid.Nil, events[1].ID)
+	assert.False(t, events[1].CreatedAt.IsZero())
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestVerificationEventRepository_CreateBatch_Empty(t *testing.T) {
+	db, mock := setupAgentTestDB(t)
+	repo := NewVerificationEventRepository(db)
+
+	require.NoError(t, repo.CreateBatch(nil))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestVerificationEventRepository_CountAgentEventsByWindow(t *testing.T) {
+	db, mock := setupAgentTestDB(t)
+	repo := NewVerificationEventRepository(db)
+	agentID := uuid.New()
+	now := time.Now()
+
+	// Postgres buckets the events; only the non-empty windows come back
+	mock.ExpectQuery(`SELECT FLOOR\(EXTRACT\(EPOCH FROM \(\$2 - created_at\)\) / \$3\)::int AS bucket, COUNT\(\*\)`).
+		WithArgs(agentID, now, 3600.0, now.Add(-3*time.Hour)).
+		WillReturnRows(sqlmock.NewRows([]string{"bucket", "count"}).AddRow(0, 101).AddRow(2, 4))
+
+	counts, err := repo.CountAgentEventsByWindow(agentID, now, time.Hour, 3)
+
+	require.NoError(t, err)
+	assert.Equal(t, []int{101, 0, 4}, counts)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -21,8 +21,8 @@ func NewWebhookRepository(db *sql.DB) *WebhookRepository {
 func (r *WebhookRepository) Create(webhook *domain.Webhook) error {
 	query := `
 		INSERT INTO webhooks (
-			id, organization_id, name, url, events, secret, is_active, created_by, created_at, updated_at
-		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+			id, organization_id, name, url, events, resource_type, encrypted_secret, is_active, created_by, created_at, updated_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
 	`
 
 	events := make([]string, len(webhook.Events))
@@ -37,7 +37,8 @@ func (r *WebhookRepository) Create(webhook *domain.Webhook) error {
 		webhook.Name,
 		webhook.URL,
 		pq.Array(events),
-		webhook.Secret,
+		webhook.ResourceType,
+		webhook.EncryptedSecret,
 		webhook.IsActive,
 		webhook.CreatedBy,
 		time.Now().UTC(),
@@ -49,7 +50,7 @@ func (r *WebhookRepository) Create(webhook *domain.Webhook) error {
 
 func (r *WebhookRepository) GetByID(id uuid.UUID) (*domain.Webhook, error) {
 	query := `
-		SELECT id, organization_id, name, url, events, secret, is_active, last_triggered, failure_count, created_by, created_at, updated_at
+		SELECT id, organization_id, name, url, events, resource_type, COALESCE(encrypted_secret, ''), is_active, last_triggered, failure_count, created_by, created_at, updated_at
 		FROM webhooks
 		WHERE id = $1
 	`
@@ -63,7 +64,8 @@ func (r *WebhookRepository) GetByID(id uuid.UUID) (*domain.Webhook, error) {
 		&webhook.Name,
 		&webhook.URL,
 		pq.Array(&events),
-		&webhook.Secret,
+		&webhook.ResourceType,
+		&webhook.EncryptedSecret,
 		&webhook.IsActive,
 		&webhook.LastTriggered,
 		&webhook.FailureCount,
@@ -89,7 +91,7 @@ func (r *WebhookRepository) GetByID(id uuid.UUID) (*domain.Webhook, error) {
 
 func (r *WebhookRepository) GetByOrganization(orgID uuid.UUID) ([]*domain.Webhook, error) {
 	query := `
-		SELECT id, organization_id, name, url, events, secret, is_active, last_triggered, failure_count, created_by, created_at, updated_at
+		SELECT id, organization_id, name, url, events, resource_type, COALESCE(encrypted_secret, ''), is_active, last_triggered, failure_count, created_by, created_at, updated_at
 		FROM webhooks
 		WHERE organization_id = $1
 		ORDER BY created_at DESC
@@ -112,7 +114,8 @@ func (r *WebhookRepository) GetByOrganization(orgID uuid.UUID) ([]*domain.Webhoo
 			&webhook.Name,
 			&webhook.URL,
 			pq.Array(&events),
-			&webhook.Secret,
+			&webhook.ResourceType,
+			&webhook.EncryptedSecret,
 			&webhook.IsActive,
 			&webhook.LastTriggered,
 			&webhook.FailureCount,
@@ -138,8 +141,8 @@ func (r *WebhookRepository) GetByOrganization(orgID uuid.UUID) ([]*domain.Webhoo
 func (r *WebhookRepository) Update(webhook *domain.Webhook) error {
 	query := `
 		UPDATE webhooks
-		SET name = $1, url = $2, events = $3, is_active = $4, updated_at = $5
-		WHERE id = $6
+		SET name = $1, url = $2, events = $3, resource_type = $4, is_active = $5, updated_at = $6
+		WHERE id = $7
 	`
 
 	events := make([]string, len(webhook.Events))
@@ -152,6 +155,7 @@ func (r *WebhookRepository) Update(webhook *domain.Webhook) error {
 		webhook.Name,
 		webhook.URL,
 		pq.Array(events),
+		webhook.ResourceType,
 		webhook.IsActive,
 		time.Now().UTC(),
 		webhook.ID,
@@ -166,13 +170,69 @@ func (r *WebhookRepository) Delete(id uuid.UUID) error {
 	return err
 }
 
+// UpdateSecret replaces the stored encrypted secret and clears any legacy plaintext secret
+func (r *WebhookRepository) UpdateSecret(id uuid.UUID, encryptedSecret string) error {
+	query := `UPDATE webhooks SET encrypted_secret = $1, secret = NULL, updated_at = $2 WHERE id = $3`
+
+	result, err := r.db.Exec(query, encryptedSecret, time.Now().UTC(), id)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return fmt.Errorf("webhook not found")
+	}
+
+	return nil
+}
+
+// GetPlaintextSecrets returns the secrets of webhooks created before secrets were encrypted
+func (r *WebhookRepository) GetPlaintextSecrets() (map[uuid.UUID]string, error) {
+	query := `SELECT id, secret FROM webhooks WHERE secret IS NOT NULL AND encrypted_secret IS NULL`
+
+	rows, err := r.db.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	secrets := make(map[uuid.UUID]string)
+	for rows.Next() {
+		var id uuid.UUID
+		var secret string
+		if err := rows.Scan(&id, &secret); err != nil {
+			return nil, err
+		}
+		secrets[id] = secret
+	}
+
+	return secrets, rows.Err()
+}
+
+// RecordDelivery inserts a delivery. A delivery whose ID is already recorded is left unchanged,
+// so a queued delivery can be recorded again safely.
 func (r *WebhookRepository) RecordDelivery(delivery *domain.WebhookDelivery) error {
 	query := `
 		INSERT INTO webhook_deliveries (
-			id, webhook_id, event, payload, status_code, response_body, success, attempt_count, created_at
-		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+			id, webhook_id, event, payload, status_code, success, attempt_count,
+			status, error_message, next_attempt_at, last_attempt_at, created_at, updated_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+		ON CONFLICT (id) DO NOTHING
 	`
 
+	now := time.Now().UTC()
+	if delivery.CreatedAt.IsZero() {
+		delivery.CreatedAt = now
+	}
+	delivery.UpdatedAt = now
+	if delivery.Status == "" {
+		delivery.Status = domain.WebhookDeliveryPending
+	}
+
 	_, err := r.db.Exec(
 		query,
 		delivery.ID,
@@ -180,10 +240,40 @@ func (r *WebhookRepository) RecordDelivery(delivery *domain.WebhookDelivery) err
 		delivery.Event,
 		delivery.Payload,
 		delivery.StatusCode,
-		delivery.ResponseBody,
 		delivery.Success,
 		delivery.AttemptCount,
-		time.Now().UTC(),
+		delivery.Status,
+		delivery.ErrorMessage,
+		delivery.NextAttemptAt,
+		delivery.LastAttemptAt,
+		delivery.CreatedAt,
+		delivery.UpdatedAt,
+	)
+
+	return err
+}
+
+func (r *WebhookRepository) UpdateDelivery(delivery *domain.WebhookDelivery) error {
+	query := `
+		UPDATE webhook_deliveries
+		SET status_code = $1, success = $2, attempt_count = $3,
+		    status = $4, error_message = $5, next_attempt_at = $6, last_attempt_at = $7, updated_at = $8
+		WHERE id = $9
+	`
+
+	delivery.UpdatedAt = time.Now().UTC()
+
+	_, err := r.db.Exec(
+		query,
+		delivery.StatusCode,
+		delivery.Success,
+		delivery.AttemptCount,
+		delivery.Status,
+		delivery.ErrorMessage,
+		delivery.NextAttemptAt,
+		delivery.LastAttemptAt,
+		delivery.UpdatedAt,
+		delivery.ID,
 	)
 
 	return err
@@ -191,7 +281,7 @@ func (r *WebhookRepository) RecordDelivery(delivery *domain.WebhookDelivery) err
 
 func (r *WebhookRepository) GetDeliveries(webhookID uuid.UUID, limit, offset int) ([]*domain.WebhookDelivery, error) {
 	query := `
-		SELECT id, webhook_id, event, payload, status_code, response_body, success, attempt_count, created_at
+		SELECT ` + webhookDeliveryColumns + `
 		FROM webhook_deliveries
 		WHERE webhook_id = $1
 		ORDER BY created_at DESC
@@ -204,6 +294,38 @@ func (r *WebhookRepository) GetDeliveries(webhookID uuid.UUID, limit, offset int
 	}
 	defer rows.Close()
 
+	return scanWebhookDeliveries(rows)
+}
+
+// ClaimDueDeliveries returns up to limit due pending or retrying deliveries and pushes them back by lease.
+// SKIP LOCKED lets several retry workers claim concurrently without handing out the same delivery.
+func (r *WebhookRepository) ClaimDueDeliveries(now time.Time, lease time.Duration, limit int) ([]*domain.WebhookDelivery, error) {
+	query := `
+		UPDATE webhook_deliveries
+		SET next_attempt_at = $4, updated_at = $3
+		WHERE id IN (
+			SELECT id FROM webhook_deliveries
+			WHERE status IN ($1, $2) AND next_attempt_at <= $3
+			ORDER BY next_attempt_at ASC
+			LIMIT $5
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING ` + webhookDeliveryColumns + `
+	`
+
+	rows, err := r.db.Query(query, domain.WebhookDeliveryPending, domain.WebhookDeliveryRetrying, now, now.Add(lease), limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanWebhookDeliveries(rows)
+}
+
+const webhookDeliveryColumns = `id, webhook_id, event, payload, COALESCE(status_code, 0), success, attempt_count,
+		       status, COALESCE(error_message, ''), next_attempt_at, last_attempt_at, created_at, updated_at`
+
+func scanWebhookDeliveries(rows *sql.Rows) ([]*domain.WebhookDelivery, error) {
 	var deliveries []*domain.WebhookDelivery
 	for rows.Next() {
 		delivery := &domain.WebhookDelivery{}
@@ -213,10 +335,14 @@ func (r *WebhookRepository) GetDeliveries(webhookID uuid.UUID, limit, offset int
 			&delivery.Event,
 			&delivery.Payload,
 			&delivery.StatusCode,
-			&delivery.ResponseBody,
 			&delivery.Success,
 			&delivery.AttemptCount,
+			&delivery.Status,
+			&delivery.ErrorMessage,
+			&delivery.NextAttemptAt,
+			&delivery.LastAttemptAt,
 			&delivery.CreatedAt,
+			&delivery.UpdatedAt,
 		)
 		if err != nil {
 			return nil, err
@@ -0,0 +1,50 @@
+package repository
+
+import (
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWebhookRepository_ClaimDueDeliveries(t *testing.T) {
+	db, mock := setupAgentTestDB(t)
+	repo := NewWebhookRepository(db)
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	lease := now.Add(time.Minute)
+	deliveryID, webhookID := uuid.New(), uuid.New()
+
+	columns := []string{"id", "webhook_id", "event", "payload", "status_code", "success", "attempt_count",
+		"status", "error_message", "next_attempt_at", "last_attempt_at", "created_at", "updated_at"}
+	mock.ExpectQuery(regexp.QuoteMeta("FOR UPDATE SKIP LOCKED")).
+		WithArgs(domain.WebhookDeliveryPending, domain.WebhookDeliveryRetrying, now, lease, 100).
+		WillReturnRows(sqlmock.NewRows(columns).
+			AddRow(deliveryID, webhookID, "alert.created", `{}`, 503, false, 1, "retrying", "503", lease, now.Add(-time.Second), now.Add(-time.Minute), now))
+
+	deliveries, err := repo.ClaimDueDeliveries(now, time.Minute, 100)
+	require.NoError(t, err)
+	require.Len(t, deliveries, 1)
+	assert.Equal(t, deliveryID, deliveries[0].ID)
+	assert.Equal(t, domain.WebhookDeliveryRetrying, deliveries[0].Status)
+	assert.Equal(t, lease, *deliveries[0].NextAttemptAt)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestWebhookRepository_GetPlaintextSecrets(t *testing.T) {
+	db, mock := setupAgentTestDB(t)
+	repo := NewWebhookRepository(db)
+	webhookID := uuid.New()
+
+	mock.ExpectQuery(regexp.QuoteMeta("WHERE secret IS NOT NULL AND encrypted_secret IS NULL")).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "secret"}).AddRow(webhookID, "legacy-secret"))
+
+	secrets, err := repo.GetPlaintextSecrets()
+	require.NoError(t, err)
+	assert.Equal(t, map[uuid.UUID]string{webhookID: "legacy-secret"}, secrets)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,61 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestIsPublicIP(t *testing.T) {
+	tests := map[string]bool{
+		"8.8.8.8":         true,
+		"2606:4700::1111": true,
+		"127.0.0.1":       false,
+		"::1":             false,
+		"10.1.2.3":        false,
+		"172.16.0.1":      false,
+		"192.168.1.1":     false,
+		"169.254.169.254": false, // cloud metadata
+		"fe80::1":         false,
+		"fd00::1":         false,
+		"0.0.0.0":         false,
+	}
+	for ip, want := range tests {
+		if got := IsPublicIP(net.ParseIP(ip)); got != want {
+			t.Errorf("IsPublicIP(%s) = %v, want %v", ip, got, want)
+		}
+	}
+}
+
+func TestNewPublicHTTPClient_RefusesLoopback(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	_, err := NewPublicHTTPClient(5 * time.Second).Get(server.URL)
+	if !errors.Is(err, ErrNonPublicAddress) {
+		t.Errorf("Get(%s) error = %v, want ErrNonPublicAddress", server.URL, err)
+	}
+}
+
+func TestValidatePublicURL(t *testing.T) {
+	for _, rawURL := range []string{
+		"http://127.0.0.1:8080/hook",
+		"http://169.254.169.254/latest/meta-data/",
+		"https://[::1]/hook",
+		"http://localhost/hook",
+		"ftp://example.com/hook",
+		"not a url",
+	} {
+		if err := ValidatePublicURL(context.Background(), rawURL); err == nil {
+			t.Errorf("ValidatePublicURL(%q) accepted an internal or invalid URL", rawURL)
+		}
+	}
+
+	if err := ValidatePublicURL(context.Background(), "https://203.0.113.10/hook"); err != nil {
+		t.Errorf("ValidatePublicURL(public IP) error = %v", err)
+	}
+}
@@ -1,16 +1,20 @@
 package handlers
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
-	"log"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gofiber/fiber/v3"
 	"github.com/google/uuid"
 	"github.com/opena2a/identity/backend/internal/application"
 	"github.com/opena2a/identity/backend/internal/domain"
+	"github.com/opena2a/identity/backend/internal/infrastructure/logging"
 )
 
 type AdminHandler struct {
@@ -89,7 +93,7 @@ func (h *AdminHandler) ListUsers(c fiber.Ctx) error {
 	pendingRequests, _, err := h.registrationService.ListPendingRegistrationRequests(c.Context(), orgID, 100, 0)
 	if err != nil {
 		// ℹ️ If table doesn't exist or query fails, just show approved users
-		log.Printf("⚠️ Warning: Failed to fetch pending registration requests (table may not exist): %v", err)
+		logging.FromContext(c.Context()).Warn("failed to fetch pending registration requests (table may not exist)", "org_id", orgID, "error", err)
 		pendingRequests = []*domain.UserRegistrationRequest{} // Empty slice, no pending requests
 	}
 
@@ -250,7 +254,8 @@ func (h *AdminHandler) UpdateUserRole(c fiber.Ctx) error {
 	})
 }
 
-// DeactivateUser deactivates a user account
+// DeactivateUser deactivates a user account. With ?cascade=true the agents the user created are
+// suspended and their API keys disabled in the same transaction.
 func (h *AdminHandler) DeactivateUser(c fiber.Ctx) error {
 	orgID := c.Locals("organization_id").(uuid.UUID)
 	adminID := c.Locals("user_id").(uuid.UUID)
@@ -272,7 +277,7 @@ func (h *AdminHandler) DeactivateUser(c fiber.Ctx) error {
 	// Super admin is identified as the oldest admin user by created_at timestamp
 	isSuperAdmin, err := h.isSuperAdmin(c.Context(), targetUserID, orgID)
 	if err != nil {
-		log.Printf("⚠️ Error checking super admin status: %v", err)
+		logging.FromContext(c.Context()).Error("failed to check super admin status", "user_id", targetUserID, "error", err)
 	}
 
 	if isSuperAdmin {
@@ -281,7 +286,14 @@ func (h *AdminHandler) DeactivateUser(c fiber.Ctx) error {
 		})
 	}
 
-	if err := h.authService.DeactivateUser(c.Context(), targetUserID, orgID, adminID); err != nil {
+	cascade := c.Query("cascade") == "true"
+	var affected *domain.UserDeactivationCascade
+	if cascade {
+		affected, err = h.authService.DeactivateUserWithCascade(c.Context(), targetUserID, orgID, adminID)
+	} else {
+		err = h.authService.DeactivateUser(c.Context(), targetUserID, orgID, adminID)
+	}
+	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": err.Error(),
 		})
@@ -298,16 +310,64 @@ func (h *AdminHandler) DeactivateUser(c fiber.Ctx) error {
 		c.IP(),
 		c.Get("User-Agent"),
 		map[string]interface{}{
-			"action": "deactivate",
-			"type":   "soft_delete",
+			"action":  "deactivate",
+			"type":    "soft_delete",
+			"cascade": cascade,
 		},
 	)
 
+	if !cascade {
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{
+			"message": "User deactivated successfully",
+		})
+	}
+
+	h.logDeactivationCascade(c, orgID, adminID, targetUserID, affected)
+
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{
-		"message": "User deactivated successfully",
+		"message":           "User deactivated successfully",
+		"suspendedAgentIds": affected.SuspendedAgentIDs,
+		"disabledApiKeyIds": affected.DisabledAPIKeyIDs,
 	})
 }
 
+// logDeactivationCascade records an audit entry for every agent and API key suspended along with a user
+func (h *AdminHandler) logDeactivationCascade(c fiber.Ctx, orgID, adminID, userID uuid.UUID, affected *domain.UserDeactivationCascade) {
+	for _, agentID := range affected.SuspendedAgentIDs {
+		h.auditService.LogAction(
+			c.Context(),
+			orgID,
+			adminID,
+			domain.AuditActionUpdate,
+			"agent",
+			agentID,
+			c.IP(),
+			c.Get("User-Agent"),
+			map[string]interface{}{
+				"action":              "suspend",
+				"reason":              "creator_deactivated",
+				"deactivated_user_id": userID.String(),
+			},
+		)
+	}
+	for _, keyID := range affected.DisabledAPIKeyIDs {
+		h.auditService.LogAction(
+			c.Context(),
+			orgID,
+			adminID,
+			domain.AuditActionRevoke,
+			"api_key",
+			keyID,
+			c.IP(),
+			c.Get("User-Agent"),
+			map[string]interface{}{
+				"reason":              "creator_deactivated",
+				"deactivated_user_id": userID.String(),
+			},
+		)
+	}
+}
+
 // ActivateUser reactivates a deactivated user account
 func (h *AdminHandler) ActivateUser(c fiber.Ctx) error {
 	orgID := c.Locals("organization_id").(uuid.UUID)
@@ -335,6 +395,10 @@ func (h *AdminHandler) ActivateUser(c fiber.Ctx) error {
 
 	// Activate user using admin service
 	if err := h.adminService.ActivateUser(c.Context(), targetUserID, adminID); err != nil {
+		var quotaErr *domain.QuotaExceededError
+		if errors.As(err, &quotaErr) {
+			return quotaExceededResponse(c, quotaErr)
+		}
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": err.Error(),
 		})
@@ -396,7 +460,7 @@ func (h *AdminHandler) PermanentlyDeleteUser(c fiber.Ctx) error {
 	// Super admin is identified as the oldest admin user by created_at timestamp
 	isSuperAdmin, err := h.isSuperAdmin(c.Context(), targetUserID, orgID)
 	if err != nil {
-		log.Printf("⚠️ Error checking super admin status: %v", err)
+		logging.FromContext(c.Context()).Error("failed to check super admin status", "user_id", targetUserID, "error", err)
 	}
 
 	if isSuperAdmin {
@@ -439,6 +503,30 @@ func (h *AdminHandler) PermanentlyDeleteUser(c fiber.Ctx) error {
 	})
 }
 
+// VerifyAuditChain checks the organization's audit log hash chain for tampering
+func (h *AdminHandler) VerifyAuditChain(c fiber.Ctx) error {
+	orgID, ok := c.Locals("organization_id").(uuid.UUID)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Organization ID not found in context",
+		})
+	}
+
+	result, err := h.auditService.VerifyChain(c.Context(), orgID)
+	if err != nil {
+		logging.FromContext(c.Context()).Error("failed to verify audit chain", "org_id", orgID, "error", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to verify audit log chain",
+		})
+	}
+
+	if !result.Valid {
+		logging.FromContext(c.Context()).Warn("audit log chain verification failed",
+			"org_id", orgID, "sequence", result.BrokenSequence, "reason", result.Reason)
+	}
+	return c.JSON(result)
+}
+
 // GetAuditLogs returns audit logs with filtering
 func (h *AdminHandler) GetAuditLogs(c fiber.Ctx) error {
 	// 🔍 Safe type assertion with error checking
@@ -470,76 +558,33 @@ func (h *AdminHandler) GetAuditLogs(c fiber.Ctx) error {
 		})
 	}
 
-	// Parse filters
-	var filters struct {
-		Action     string `query:"action"`
-		EntityType string `query:"entity_type"`
-		EntityID   string `query:"entity_id"`
-		UserID     string `query:"user_id"`
-		StartDate  string `query:"start_date"`
-		EndDate    string `query:"end_date"`
-		Limit      int    `query:"limit"`
-		Offset     int    `query:"offset"`
-	}
-
-	if err := c.Bind().Query(&filters); err != nil {
+	filter, err := parseAuditLogFilter(c)
+	if err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid query parameters",
+			"error": err.Error(),
 		})
 	}
 
-	// Set defaults
-	if filters.Limit == 0 {
-		filters.Limit = 100
-	}
-
-	// Parse dates if provided
-	var startDate, endDate *time.Time
-	if filters.StartDate != "" {
-		parsed, err := time.Parse(time.RFC3339, filters.StartDate)
-		if err == nil {
-			startDate = &parsed
-		}
-	}
-	if filters.EndDate != "" {
-		parsed, err := time.Parse(time.RFC3339, filters.EndDate)
-		if err == nil {
-			endDate = &parsed
-		}
-	}
-
-	// Parse entity ID if provided
-	var entityID *uuid.UUID
-	if filters.EntityID != "" {
-		parsed, err := uuid.Parse(filters.EntityID)
-		if err == nil {
-			entityID = &parsed
+	limit := 100
+	if limitStr := c.Query("limit"); limitStr != "" {
+		if limit, err = strconv.Atoi(limitStr); err != nil || limit < 1 || limit > 1000 {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "limit must be between 1 and 1000",
+			})
 		}
 	}
-
-	// Parse user ID if provided
-	var filterUserID *uuid.UUID
-	if filters.UserID != "" {
-		parsed, err := uuid.Parse(filters.UserID)
-		if err == nil {
-			filterUserID = &parsed
+	offset := 0
+	if offsetStr := c.Query("offset"); offsetStr != "" {
+		if offset, err = strconv.Atoi(offsetStr); err != nil || offset < 0 {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "offset must be a non-negative integer",
+			})
 		}
 	}
 
-	// Get audit logs
-	logs, total, err := h.auditService.GetAuditLogs(
-		c.Context(),
-		orgID,
-		filters.Action,
-		filters.EntityType,
-		entityID,
-		filterUserID,
-		startDate,
-		endDate,
-		filters.Limit,
-		filters.Offset,
-	)
+	logs, total, err := h.auditService.SearchLogs(c.Context(), orgID, filter, limit, offset)
 	if err != nil {
+		logging.FromContext(c.Context()).Error("failed to search audit logs", "org_id", orgID, "error", err)
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": "Failed to fetch audit logs",
 		})
@@ -549,28 +594,15 @@ func (h *AdminHandler) GetAuditLogs(c fiber.Ctx) error {
 	metadata := map[string]interface{}{
 		"results_returned": len(logs),
 		"total_available":  total,
-		"page_number":      (filters.Offset / filters.Limit) + 1,
-		"page_size":        filters.Limit,
+		"page_number":      (offset / limit) + 1,
+		"page_size":        limit,
 	}
 
-	// Only include non-empty filters
-	if filters.Action != "" {
-		metadata["filter_action"] = filters.Action
-	}
-	if filters.EntityType != "" {
-		metadata["filter_resource_type"] = filters.EntityType
-	}
-	if filters.EntityID != "" {
-		metadata["filter_resource_id"] = filters.EntityID
-	}
-	if filters.UserID != "" {
-		metadata["filter_user_id"] = filters.UserID
-	}
-	if filters.StartDate != "" {
-		metadata["filter_start_date"] = filters.StartDate
-	}
-	if filters.EndDate != "" {
-		metadata["filter_end_date"] = filters.EndDate
+	// Only include the filters that were set
+	for _, param := range []string{"action", "resource_type", "entity_type", "resource_id", "entity_id", "user_id", "start_date", "end_date", "q"} {
+		if value := c.Query(param); value != "" {
+			metadata["filter_"+param] = value
+		}
 	}
 
 	h.auditService.LogAction(
@@ -588,11 +620,71 @@ func (h *AdminHandler) GetAuditLogs(c fiber.Ctx) error {
 	return c.JSON(fiber.Map{
 		"logs":   logs,
 		"total":  total,
-		"limit":  filters.Limit,
-		"offset": filters.Offset,
+		"limit":  limit,
+		"offset": offset,
 	})
 }
 
+// parseAuditLogFilter reads the audit log filters from the query string. resource_type and
+// resource_id are also accepted under their older names, entity_type and entity_id. Dates are
+// RFC 3339 timestamps or YYYY-MM-DD days; a day-only end_date includes that whole day.
+func parseAuditLogFilter(c fiber.Ctx) (domain.AuditLogFilter, error) {
+	filter := domain.AuditLogFilter{
+		Action:       domain.AuditAction(strings.TrimSpace(c.Query("action"))),
+		ResourceType: strings.TrimSpace(c.Query("resource_type", c.Query("entity_type"))),
+		Query:        strings.TrimSpace(c.Query("q")),
+	}
+
+	if resourceID := c.Query("resource_id", c.Query("entity_id")); resourceID != "" {
+		parsed, err := uuid.Parse(resourceID)
+		if err != nil {
+			return filter, fmt.Errorf("invalid resource_id: %s", resourceID)
+		}
+		filter.ResourceID = &parsed
+	}
+
+	if userID := c.Query("user_id"); userID != "" {
+		parsed, err := uuid.Parse(userID)
+		if err != nil {
+			return filter, fmt.Errorf("invalid user_id: %s", userID)
+		}
+		filter.UserID = &parsed
+	}
+
+	if startDate := c.Query("start_date"); startDate != "" {
+		parsed, _, err := parseAuditLogDate(startDate)
+		if err != nil {
+			return filter, fmt.Errorf("invalid start_date: %s", startDate)
+		}
+		filter.StartDate = &parsed
+	}
+
+	if endDate := c.Query("end_date"); endDate != "" {
+		parsed, dayOnly, err := parseAuditLogDate(endDate)
+		if err != nil {
+			return filter, fmt.Errorf("invalid end_date: %s", endDate)
+		}
+		if dayOnly {
+			parsed = parsed.AddDate(0, 0, 1)
+		}
+		filter.EndDate = &parsed
+	}
+
+	if filter.StartDate != nil && filter.EndDate != nil && !filter.StartDate.Before(*filter.EndDate) {
+		return filter, fmt.Errorf("start_date must be before end_date")
+	}
+	return filter, nil
+}
+
+// parseAuditLogDate parses an RFC 3339 timestamp or a YYYY-MM-DD day (UTC), reporting which it was
+func parseAuditLogDate(value string) (time.Time, bool, error) {
+	if parsed, err := time.Parse(time.RFC3339, value); err == nil {
+		return parsed, false, nil
+	}
+	parsed, err := time.Parse(time.DateOnly, value)
+	return parsed, true, err
+}
+
 // GetAlerts returns all alerts with optional filtering
 func (h *AdminHandler) GetAlerts(c fiber.Ctx) error {
 	// 🔍 Safe type assertion with error checking
@@ -1030,6 +1122,10 @@ func (h *AdminHandler) ApproveUser(c fiber.Ctx) error {
 	}
 
 	if err := h.adminService.ApproveUser(c.Context(), targetUserID, adminID); err != nil {
+		var quotaErr *domain.QuotaExceededError
+		if errors.As(err, &quotaErr) {
+			return quotaExceededResponse(c, quotaErr)
+		}
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": err.Error(),
 		})
@@ -1116,6 +1212,10 @@ func (h *AdminHandler) ApproveRegistrationRequest(c fiber.Ctx) error {
 	// Approve registration request
 	newUser, err := h.registrationService.ApproveRegistrationRequest(c.Context(), requestID, adminID, orgID)
 	if err != nil {
+		var quotaErr *domain.QuotaExceededError
+		if errors.As(err, &quotaErr) {
+			return quotaExceededResponse(c, quotaErr)
+		}
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": fmt.Sprintf("Failed to approve registration: %v", err),
 		})
@@ -1253,6 +1353,349 @@ func (h *AdminHandler) GetOrganizationSettings(c fiber.Ctx) error {
 		"maxAgents": org.MaxAgents,
 		"maxUsers":  org.MaxUsers,
 		"isActive":  org.IsActive,
+		"autoVerifyEnabled":         org.AutoVerifyEnabled,
+		"autoVerifyMinTrust":        org.AutoVerifyMinTrust,
+		"keyRotationDays":           org.KeyRotationDays,
+		"trustDecayHalfLifeDays":    org.TrustDecayHalfLifeDays,
+		"requireCapabilityApproval": org.RequireCapabilityApproval,
+		"logoUrl":                   org.LogoURL,
+		"emailLocale":               org.EmailLocale,
+		"trustWeights":              org.EffectiveTrustWeights(),
+		"violationPenalties":        org.EffectiveViolationPenalties(),
+		"passwordPolicy":            org.EffectivePasswordPolicy(),
+	})
+}
+
+// UpdatePasswordPolicy replaces the organization's password policy
+// PUT /api/v1/admin/organization/password-policy
+func (h *AdminHandler) UpdatePasswordPolicy(c fiber.Ctx) error {
+	orgID := c.Locals("organization_id").(uuid.UUID)
+	adminID := c.Locals("user_id").(uuid.UUID)
+
+	var policy domain.PasswordPolicy
+	if err := c.Bind().JSON(&policy); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	org, err := h.adminService.UpdatePasswordPolicy(c.Context(), orgID, policy)
+	if err != nil {
+		if errors.Is(err, application.ErrInvalidPasswordPolicy) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to update password policy",
+		})
+	}
+
+	h.auditService.LogAction(
+		c.Context(),
+		orgID,
+		adminID,
+		domain.AuditActionUpdate,
+		"password_policy",
+		orgID,
+		c.IP(),
+		c.Get("User-Agent"),
+		map[string]interface{}{
+			"minLength":               policy.MinLength,
+			"requireUppercase":        policy.RequireUppercase,
+			"requireLowercase":        policy.RequireLowercase,
+			"requireNumber":           policy.RequireNumber,
+			"requireSymbol":           policy.RequireSymbol,
+			"disallowCommonPasswords": policy.DisallowCommonPasswords,
+		},
+	)
+
+	return c.JSON(org.EffectivePasswordPolicy())
+}
+
+// UpdateTrustDecayHalfLife sets the organization's trust score inactivity half-life
+// PUT /api/v1/admin/organization/trust-decay
+func (h *AdminHandler) UpdateTrustDecayHalfLife(c fiber.Ctx) error {
+	orgID := c.Locals("organization_id").(uuid.UUID)
+	adminID := c.Locals("user_id").(uuid.UUID)
+
+	var req struct {
+		HalfLifeDays *int `json:"halfLifeDays"`
+	}
+	if err := c.Bind().JSON(&req); err != nil || req.HalfLifeDays == nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	org, err := h.adminService.UpdateTrustDecayHalfLife(c.Context(), orgID, *req.HalfLifeDays)
+	if err != nil {
+		if errors.Is(err, application.ErrInvalidTrustDecayHalfLife) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to update trust decay half-life",
+		})
+	}
+
+	h.auditService.LogAction(
+		c.Context(),
+		orgID,
+		adminID,
+		domain.AuditActionUpdate,
+		"trust_decay",
+		orgID,
+		c.IP(),
+		c.Get("User-Agent"),
+		map[string]interface{}{
+			"halfLifeDays": org.TrustDecayHalfLifeDays,
+		},
+	)
+
+	return c.JSON(fiber.Map{
+		"halfLifeDays": org.TrustDecayHalfLifeDays,
+	})
+}
+
+// UpdateAutoVerify sets whether new agents are verified automatically and the trust score they need
+// PUT /api/v1/admin/organization/auto-verify
+func (h *AdminHandler) UpdateAutoVerify(c fiber.Ctx) error {
+	orgID := c.Locals("organization_id").(uuid.UUID)
+	adminID := c.Locals("user_id").(uuid.UUID)
+
+	var req struct {
+		AutoVerifyEnabled  *bool    `json:"autoVerifyEnabled"`
+		AutoVerifyMinTrust *float64 `json:"autoVerifyMinTrust"`
+	}
+	if err := c.Bind().JSON(&req); err != nil || req.AutoVerifyEnabled == nil || req.AutoVerifyMinTrust == nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	org, err := h.adminService.UpdateAutoVerify(c.Context(), orgID, *req.AutoVerifyEnabled, *req.AutoVerifyMinTrust)
+	if err != nil {
+		if errors.Is(err, application.ErrInvalidAutoVerifyMinTrust) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to update auto-verification settings",
+		})
+	}
+
+	h.auditService.LogAction(
+		c.Context(),
+		orgID,
+		adminID,
+		domain.AuditActionUpdate,
+		"auto_verify",
+		orgID,
+		c.IP(),
+		c.Get("User-Agent"),
+		map[string]interface{}{
+			"autoVerifyEnabled":  org.AutoVerifyEnabled,
+			"autoVerifyMinTrust": org.AutoVerifyMinTrust,
+		},
+	)
+
+	return c.JSON(fiber.Map{
+		"autoVerifyEnabled":  org.AutoVerifyEnabled,
+		"autoVerifyMinTrust": org.AutoVerifyMinTrust,
+	})
+}
+
+// UpdateCapabilityApproval sets whether declared capabilities of new agents need admin approval
+// PUT /api/v1/admin/organization/capability-approval
+func (h *AdminHandler) UpdateCapabilityApproval(c fiber.Ctx) error {
+	orgID := c.Locals("organization_id").(uuid.UUID)
+	adminID := c.Locals("user_id").(uuid.UUID)
+
+	var req struct {
+		RequireCapabilityApproval *bool `json:"requireCapabilityApproval"`
+	}
+	if err := c.Bind().JSON(&req); err != nil || req.RequireCapabilityApproval == nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	org, err := h.adminService.UpdateCapabilityApproval(c.Context(), orgID, *req.RequireCapabilityApproval)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to update capability approval setting",
+		})
+	}
+
+	h.auditService.LogAction(
+		c.Context(),
+		orgID,
+		adminID,
+		domain.AuditActionUpdate,
+		"capability_approval",
+		orgID,
+		c.IP(),
+		c.Get("User-Agent"),
+		map[string]interface{}{
+			"requireCapabilityApproval": org.RequireCapabilityApproval,
+		},
+	)
+
+	return c.JSON(fiber.Map{
+		"requireCapabilityApproval": org.RequireCapabilityApproval,
+	})
+}
+
+// UpdateEmailBranding sets the logo and default locale of the organization's emails
+// PUT /api/v1/admin/organization/email-branding
+func (h *AdminHandler) UpdateEmailBranding(c fiber.Ctx) error {
+	orgID := c.Locals("organization_id").(uuid.UUID)
+	adminID := c.Locals("user_id").(uuid.UUID)
+
+	var req struct {
+		LogoURL     string `json:"logoUrl"`
+		EmailLocale string `json:"emailLocale"`
+	}
+	if err := c.Bind().JSON(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	org, err := h.adminService.UpdateEmailBranding(c.Context(), orgID, req.LogoURL, req.EmailLocale)
+	if err != nil {
+		if errors.Is(err, application.ErrInvalidEmailBranding) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to update email branding",
+		})
+	}
+
+	h.auditService.LogAction(
+		c.Context(),
+		orgID,
+		adminID,
+		domain.AuditActionUpdate,
+		"email_branding",
+		orgID,
+		c.IP(),
+		c.Get("User-Agent"),
+		map[string]interface{}{
+			"logoUrl":     org.LogoURL,
+			"emailLocale": org.EmailLocale,
+		},
+	)
+
+	return c.JSON(fiber.Map{
+		"logoUrl":     org.LogoURL,
+		"emailLocale": org.EmailLocale,
+	})
+}
+
+// UpdateTrustConfig replaces the organization's trust score category weights
+// PUT /api/v1/admin/trust-config
+func (h *AdminHandler) UpdateTrustConfig(c fiber.Ctx) error {
+	orgID := c.Locals("organization_id").(uuid.UUID)
+	adminID := c.Locals("user_id").(uuid.UUID)
+
+	// Reject unknown categories rather than silently weighting them 0
+	var weights domain.TrustWeights
+	decoder := json.NewDecoder(bytes.NewReader(c.Body()))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&weights); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": fmt.Sprintf("Invalid request body: %v", err),
+		})
+	}
+
+	org, err := h.adminService.UpdateTrustWeights(c.Context(), orgID, weights)
+	if err != nil {
+		if errors.Is(err, application.ErrInvalidTrustWeights) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to update trust weights",
+		})
+	}
+
+	h.auditService.LogAction(
+		c.Context(),
+		orgID,
+		adminID,
+		domain.AuditActionUpdate,
+		"trust_config",
+		orgID,
+		c.IP(),
+		c.Get("User-Agent"),
+		map[string]interface{}{
+			"verification": weights.Verification,
+			"violations":   weights.Violations,
+			"key_age":      weights.KeyAge,
+			"mcp":          weights.MCP,
+			"alerts":       weights.Alerts,
+		},
+	)
+
+	return c.JSON(fiber.Map{
+		"weights": org.EffectiveTrustWeights(),
+	})
+}
+
+// UpdateViolationPenalties replaces the organization's trust score penalties per violation severity.
+// Fields omitted from the body keep their default penalty.
+// PUT /api/v1/admin/trust-config/violation-penalties
+func (h *AdminHandler) UpdateViolationPenalties(c fiber.Ctx) error {
+	orgID := c.Locals("organization_id").(uuid.UUID)
+	adminID := c.Locals("user_id").(uuid.UUID)
+
+	// Decode over the defaults so an omitted severity is not silently penalized 0,
+	// and reject unknown modes and severities
+	penalties := domain.DefaultViolationPenalties()
+	decoder := json.NewDecoder(bytes.NewReader(c.Body()))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&penalties); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": fmt.Sprintf("Invalid request body: %v", err),
+		})
+	}
+
+	org, err := h.adminService.UpdateViolationPenalties(c.Context(), orgID, penalties)
+	if err != nil {
+		if errors.Is(err, application.ErrInvalidViolationPenalties) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to update violation penalties",
+		})
+	}
+
+	h.auditService.LogAction(
+		c.Context(),
+		orgID,
+		adminID,
+		domain.AuditActionUpdate,
+		"violation_penalties",
+		orgID,
+		c.IP(),
+		c.Get("User-Agent"),
+		map[string]interface{}{
+			"alert":   penalties.Alert,
+			"blocked": penalties.Blocked,
+		},
+	)
+
+	return c.JSON(fiber.Map{
+		"penalties": org.EffectiveViolationPenalties(),
 	})
 }
 
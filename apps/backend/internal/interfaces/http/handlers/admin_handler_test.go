@@ -0,0 +1,194 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/application"
+	"github.com/opena2a/identity/backend/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// searchAuditLogRepository records the last search and accepts the audit entry the handler writes
+type searchAuditLogRepository struct {
+	domain.AuditLogRepository
+	orgID         uuid.UUID
+	filter        domain.AuditLogFilter
+	limit, offset int
+	searched      bool
+}
+
+func (r *searchAuditLogRepository) Search(orgID uuid.UUID, filter domain.AuditLogFilter, limit, offset int) ([]*domain.AuditLog, int, error) {
+	r.orgID, r.filter, r.limit, r.offset, r.searched = orgID, filter, limit, offset, true
+	return []*domain.AuditLog{}, 0, nil
+}
+
+func (r *searchAuditLogRepository) Create(log *domain.AuditLog) error {
+	return nil
+}
+
+func newAuditLogTestApp(repo *searchAuditLogRepository, orgID uuid.UUID) *fiber.App {
+	handler := NewAdminHandler(nil, nil, nil, nil, application.NewAuditService(repo), nil, nil, nil)
+
+	app := fiber.New()
+	app.Get("/admin/audit-logs", handler.GetAuditLogs, func(c fiber.Ctx) error {
+		c.Locals("organization_id", orgID) // Stands in for the auth middleware
+		c.Locals("user_id", uuid.New())
+		return c.Next()
+	})
+	return app
+}
+
+func TestGetAuditLogs_PassesFiltersToSearch(t *testing.T) {
+	repo := &searchAuditLogRepository{}
+	orgID, userID, agentID := uuid.New(), uuid.New(), uuid.New()
+
+	url := "/admin/audit-logs?action=update&resource_type=agent&resource_id=" + agentID.String() +
+		"&user_id=" + userID.String() + "&start_date=2025-06-01T00:00:00Z&end_date=2025-06-07&limit=25&offset=50"
+	resp, err := newAuditLogTestApp(repo, orgID).Test(httptest.NewRequest("GET", url, nil))
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+	require.True(t, repo.searched)
+	assert.Equal(t, orgID, repo.orgID)
+	assert.Equal(t, domain.AuditActionUpdate, repo.filter.Action)
+	assert.Equal(t, "agent", repo.filter.ResourceType)
+	assert.Equal(t, &agentID, repo.filter.ResourceID)
+	assert.Equal(t, &userID, repo.filter.UserID)
+	assert.Equal(t, time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC), repo.filter.StartDate.UTC())
+	// A day-only end date includes the whole day
+	assert.Equal(t, time.Date(2025, 6, 8, 0, 0, 0, 0, time.UTC), repo.filter.EndDate.UTC())
+	assert.Equal(t, 25, repo.limit)
+	assert.Equal(t, 50, repo.offset)
+
+	var body struct {
+		Logs  []*domain.AuditLog `json:"logs"`
+		Total int                `json:"total"`
+	}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+	assert.Empty(t, body.Logs)
+	assert.Equal(t, 0, body.Total)
+}
+
+func TestGetAuditLogs_RejectsInvalidFilters(t *testing.T) {
+	for _, query := range []string{
+		"user_id=not-a-uuid",
+		"resource_id=42",
+		"start_date=yesterday",
+		"start_date=2025-06-07&end_date=2025-06-01",
+		"limit=0",
+	} {
+		t.Run(query, func(t *testing.T) {
+			repo := &searchAuditLogRepository{}
+			resp, err := newAuditLogTestApp(repo, uuid.New()).Test(httptest.NewRequest("GET", "/admin/audit-logs?"+query, nil))
+			require.NoError(t, err)
+			assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+			assert.False(t, repo.searched)
+		})
+	}
+}
+
+func TestUpdateTrustConfig_RejectsUnknownCategory(t *testing.T) {
+	handler := NewAdminHandler(nil, nil, nil, nil, nil, nil, nil, nil)
+
+	app := fiber.New()
+	app.Put("/admin/trust-config", handler.UpdateTrustConfig, func(c fiber.Ctx) error {
+		c.Locals("organization_id", uuid.New()) // Stands in for the auth middleware
+		c.Locals("user_id", uuid.New())
+		return c.Next()
+	})
+
+	// "uptime" is a trust score factor, not a weight category; it must not be silently weighted 0
+	req := httptest.NewRequest("PUT", "/admin/trust-config", strings.NewReader(`{"verification": 0.5, "uptime": 0.5}`))
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+
+	var body map[string]string
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+	assert.Contains(t, body["error"], "uptime")
+}
+
+// settingsOrganizationRepository serves one organization and keeps the last update
+type settingsOrganizationRepository struct {
+	domain.OrganizationRepository
+	org *domain.Organization
+}
+
+func (r *settingsOrganizationRepository) GetByID(id uuid.UUID) (*domain.Organization, error) {
+	return r.org, nil
+}
+
+func (r *settingsOrganizationRepository) Update(org *domain.Organization) error {
+	r.org = org
+	return nil
+}
+
+func TestUpdateViolationPenalties_KeepsDefaultsForOmittedFields(t *testing.T) {
+	orgID := uuid.New()
+	orgRepo := &settingsOrganizationRepository{org: &domain.Organization{ID: orgID}}
+	handler := NewAdminHandler(nil, application.NewAdminService(nil, orgRepo), nil, nil,
+		application.NewAuditService(&searchAuditLogRepository{}), nil, nil, nil)
+
+	app := fiber.New()
+	app.Put("/admin/trust-config/violation-penalties", handler.UpdateViolationPenalties, func(c fiber.Ctx) error {
+		c.Locals("organization_id", orgID) // Stands in for the auth middleware
+		c.Locals("user_id", uuid.New())
+		return c.Next()
+	})
+
+	req := httptest.NewRequest("PUT", "/admin/trust-config/violation-penalties", strings.NewReader(`{"blocked": {"critical": 40}}`))
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+	expected := domain.DefaultViolationPenalties()
+	expected.Blocked.Critical = 40
+	require.NotNil(t, orgRepo.org.ViolationPenalties)
+	assert.Equal(t, expected, *orgRepo.org.ViolationPenalties)
+}
+
+func TestUpdateAutoVerify(t *testing.T) {
+	orgID := uuid.New()
+	orgRepo := &settingsOrganizationRepository{org: &domain.Organization{ID: orgID, AutoVerifyEnabled: true}}
+	handler := NewAdminHandler(nil, application.NewAdminService(nil, orgRepo), nil, nil,
+		application.NewAuditService(&searchAuditLogRepository{}), nil, nil, nil)
+
+	app := fiber.New()
+	app.Put("/admin/organization/auto-verify", handler.UpdateAutoVerify, func(c fiber.Ctx) error {
+		c.Locals("organization_id", orgID) // Stands in for the auth middleware
+		c.Locals("user_id", uuid.New())
+		return c.Next()
+	})
+
+	tests := []struct {
+		name           string
+		body           string
+		expectedStatus int
+	}{
+		{"threshold out of range", `{"autoVerifyEnabled": true, "autoVerifyMinTrust": 1.5}`, fiber.StatusBadRequest},
+		{"missing threshold", `{"autoVerifyEnabled": true}`, fiber.StatusBadRequest},
+		{"valid", `{"autoVerifyEnabled": false, "autoVerifyMinTrust": 0.7}`, fiber.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("PUT", "/admin/organization/auto-verify", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			resp, err := app.Test(req)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedStatus, resp.StatusCode)
+		})
+	}
+
+	assert.False(t, orgRepo.org.AutoVerifyEnabled)
+	assert.Equal(t, 0.7, orgRepo.org.AutoVerifyMinTrust)
+}
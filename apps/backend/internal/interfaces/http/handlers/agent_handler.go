@@ -1,12 +1,18 @@
 package handlers
 
 import (
+	"errors"
 	"fmt"
+	"io"
+	"strconv"
+	"strings"
 
 	"github.com/gofiber/fiber/v3"
 	"github.com/google/uuid"
 	"github.com/opena2a/identity/backend/internal/application"
 	"github.com/opena2a/identity/backend/internal/domain"
+	"github.com/opena2a/identity/backend/internal/infrastructure/logging"
+	"github.com/opena2a/identity/backend/internal/infrastructure/metrics"
 	"github.com/opena2a/identity/backend/internal/infrastructure/utils"
 	"github.com/opena2a/identity/backend/internal/sdkgen"
 )
@@ -52,6 +58,11 @@ func (h *AgentHandler) enrichAgentResponse(c fiber.Ctx, agent *domain.Agent) fib
 		capabilities = []*domain.AgentCapability{}
 	}
 
+	return agentResponse(agent, capabilities)
+}
+
+// agentResponse builds the flat agent response from an agent and its active capabilities
+func agentResponse(agent *domain.Agent, capabilities []*domain.AgentCapability) fiber.Map {
 	// Extract capability types as simple string array (frontend compatible)
 	capabilityTypes := make([]string, 0, len(capabilities))
 	for _, cap := range capabilities {
@@ -74,6 +85,7 @@ func (h *AgentHandler) enrichAgentResponse(c fiber.Ctx, agent *domain.Agent) fib
 		"createdAt":                agent.CreatedAt,
 		"updatedAt":                agent.UpdatedAt,
 		"talksTo":                  agent.TalksTo,
+		"labels":                   agent.Labels,
 		"capabilities":             capabilityTypes,
 		"capabilityViolationCount": agent.CapabilityViolationCount,
 		"isCompromised":            agent.IsCompromised,
@@ -86,29 +98,129 @@ func (h *AgentHandler) enrichAgentResponse(c fiber.Ctx, agent *domain.Agent) fib
 		"keyCreatedAt":             agent.KeyCreatedAt,
 		"keyExpiresAt":             agent.KeyExpiresAt,
 		"rotationCount":            agent.RotationCount,
+		"deletedAt":                agent.DeletedAt,
 	}
 }
 
-// ListAgents returns all agents for the organization
+// ListAgents returns one page of agents for the organization
+// Query params: limit (default 100, max 500), offset, cursor (from next_cursor)
+// Filters (combined with AND): status, agent_type, min_trust, tag (key or key:value), q,
+// label (key:value selectors; repeat the param or comma-separate to require several)
+// include_deleted=true also returns soft-deleted agents (admin only)
 func (h *AgentHandler) ListAgents(c fiber.Ctx) error {
 	orgID := c.Locals("organization_id").(uuid.UUID)
 
-	agents, err := h.agentService.ListAgents(c.Context(), orgID)
+	limit, _ := strconv.Atoi(c.Query("limit", strconv.Itoa(application.DefaultAgentPageSize)))
+	offset, _ := strconv.Atoi(c.Query("offset", "0"))
+
+	filter, err := parseAgentSearchFilter(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	// Soft-deleted agents are only visible to admins
+	if c.Query("include_deleted") == "true" {
+		if role, _ := c.Locals("role").(string); role != string(domain.RoleAdmin) {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+				"error": "Admin access required to include deleted agents",
+			})
+		}
+		filter.IncludeDeleted = true
+	}
+
+	page, err := h.agentService.ListAgentsPage(c.Context(), orgID, application.ListAgentsParams{
+		Limit:  limit,
+		Offset: offset,
+		Cursor: c.Query("cursor"),
+		Filter: filter,
+	})
 	if err != nil {
+		if errors.Is(err, application.ErrInvalidAgentCursor) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Invalid cursor",
+			})
+		}
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": "Failed to fetch agents",
 		})
 	}
-	enriched := make([]fiber.Map, 0, len(agents))
-	for _, agent := range agents {
-		enriched = append(enriched, h.enrichAgentResponse(c, agent))
+
+	// Fetch capabilities for the whole page in a single query
+	agentIDs := make([]uuid.UUID, 0, len(page.Agents))
+	for _, agent := range page.Agents {
+		agentIDs = append(agentIDs, agent.ID)
+	}
+	capabilities, err := h.capabilityService.GetActiveCapabilitiesForAgents(c.Context(), agentIDs)
+	if err != nil {
+		// Log error but don't fail - return empty capabilities
+		capabilities = map[uuid.UUID][]*domain.AgentCapability{}
+	}
+
+	enriched := make([]fiber.Map, 0, len(page.Agents))
+	for _, agent := range page.Agents {
+		enriched = append(enriched, agentResponse(agent, capabilities[agent.ID]))
 	}
+
+	var nextCursor interface{}
+	if page.NextCursor != "" {
+		nextCursor = page.NextCursor
+	}
+
 	return c.JSON(fiber.Map{
-		"agents": enriched,
-		"total":  len(enriched),
+		"agents":      enriched,
+		"total":       page.Total,
+		"next_cursor": nextCursor,
 	})
 }
 
+// parseAgentSearchFilter reads the agent list filter query params
+func parseAgentSearchFilter(c fiber.Ctx) (domain.AgentSearchFilter, error) {
+	filter := domain.AgentSearchFilter{
+		Tag:   strings.TrimSpace(c.Query("tag")),
+		Query: strings.TrimSpace(c.Query("q")),
+	}
+
+	var labelSelectors []string
+	for _, value := range c.Request().URI().QueryArgs().PeekMulti("label") {
+		labelSelectors = append(labelSelectors, string(value))
+	}
+	labels, err := domain.ParseAgentLabelSelectors(labelSelectors)
+	if err != nil {
+		return filter, err
+	}
+	filter.Labels = labels
+
+	if status := c.Query("status"); status != "" {
+		switch domain.AgentStatus(status) {
+		case domain.AgentStatusPending, domain.AgentStatusVerified, domain.AgentStatusSuspended, domain.AgentStatusRevoked:
+			filter.Status = domain.AgentStatus(status)
+		default:
+			return filter, fmt.Errorf("invalid status: %s", status)
+		}
+	}
+
+	if agentType := c.Query("agent_type"); agentType != "" {
+		switch domain.AgentType(agentType) {
+		case domain.AgentTypeAI, domain.AgentTypeMCP:
+			filter.AgentType = domain.AgentType(agentType)
+		default:
+			return filter, fmt.Errorf("invalid agent_type: %s", agentType)
+		}
+	}
+
+	if minTrust := c.Query("min_trust"); minTrust != "" {
+		value, err := strconv.ParseFloat(minTrust, 64)
+		if err != nil {
+			return filter, fmt.Errorf("invalid min_trust: %s", minTrust)
+		}
+		filter.MinTrust = &value
+	}
+
+	return filter, nil
+}
+
 // CreateAgent creates a new agent
 func (h *AgentHandler) CreateAgent(c fiber.Ctx) error {
 	orgID := c.Locals("organization_id").(uuid.UUID)
@@ -125,7 +237,16 @@ func (h *AgentHandler) CreateAgent(c fiber.Ctx) error {
 	agent, err := h.agentService.CreateAgent(c.Context(), &req, orgID, userID)
 	if err != nil {
 		// Log the full error for debugging
-		fmt.Printf("ERROR creating agent: %v\n", err)
+		logging.FromContext(c.Context()).Error("failed to create agent", "org_id", orgID, "error", err)
+		var quotaErr *domain.QuotaExceededError
+		if errors.As(err, &quotaErr) {
+			return quotaExceededResponse(c, quotaErr)
+		}
+		if errors.Is(err, application.ErrCapabilityNotInCatalog) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": err.Error(),
 		})
@@ -150,6 +271,104 @@ func (h *AgentHandler) CreateAgent(c fiber.Ctx) error {
 	return c.Status(fiber.StatusCreated).JSON(agent)
 }
 
+// quotaExceededResponse rejects a request with 403 naming the organization limit it would exceed
+func quotaExceededResponse(c fiber.Ctx, quotaErr *domain.QuotaExceededError) error {
+	return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+		"error":    quotaErr.Error(),
+		"resource": quotaErr.Resource,
+		"limit":    quotaErr.Limit,
+		"current":  quotaErr.Current,
+	})
+}
+
+// CreateAgentsBulkRequest is the payload for bulk agent creation
+type CreateAgentsBulkRequest struct {
+	Agents []*application.CreateAgentRequest `json:"agents"`
+	Atomic bool                              `json:"atomic"`
+}
+
+// CreateAgentsBulk creates up to 500 agents in a single request.
+// Each item reports its own success or error; with atomic=true any failure rejects the whole batch.
+func (h *AgentHandler) CreateAgentsBulk(c fiber.Ctx) error {
+	orgID := c.Locals("organization_id").(uuid.UUID)
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	var req CreateAgentsBulkRequest
+	// Use flexible JSON unmarshaling to accept both camelCase and snake_case
+	if err := utils.UnmarshalFlexibleJSON(c.Body(), &req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	// atomic may also be passed as a query parameter
+	if c.Query("atomic") == "true" {
+		req.Atomic = true
+	}
+
+	results, err := h.agentService.CreateAgentsBulk(c.Context(), req.Agents, req.Atomic, orgID, userID)
+	if err != nil {
+		var quotaErr *domain.QuotaExceededError
+		if errors.As(err, &quotaErr) {
+			return quotaExceededResponse(c, quotaErr)
+		}
+		switch {
+		case errors.Is(err, application.ErrBulkAgentsEmpty), errors.Is(err, application.ErrBulkAgentsLimitExceeded):
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		case errors.Is(err, application.ErrBulkAgentsAborted):
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error":   err.Error(),
+				"results": results,
+			})
+		}
+		logging.FromContext(c.Context()).Error("failed to create agents in bulk", "org_id", orgID, "error", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	created := 0
+	agentIDs := make([]string, 0, len(results))
+	for _, result := range results {
+		if result.Success {
+			created++
+			agentIDs = append(agentIDs, result.Agent.ID.String())
+		}
+	}
+
+	// Log a single audit entry for the whole batch instead of one per agent
+	h.auditService.LogAction(
+		c.Context(),
+		orgID,
+		userID,
+		domain.AuditActionCreate,
+		"agent_bulk",
+		orgID,
+		c.IP(),
+		c.Get("User-Agent"),
+		map[string]interface{}{
+			"requested": len(results),
+			"created":   created,
+			"failed":    len(results) - created,
+			"atomic":    req.Atomic,
+			"agentIds":  agentIDs,
+		},
+	)
+
+	status := fiber.StatusCreated
+	if created < len(results) {
+		status = fiber.StatusMultiStatus
+	}
+
+	return c.Status(status).JSON(fiber.Map{
+		"results": results,
+		"created": created,
+		"failed":  len(results) - created,
+	})
+}
+
 // GetAgent returns a single agent
 func (h *AgentHandler) GetAgent(c fiber.Ctx) error {
 	orgID := c.Locals("organization_id").(uuid.UUID)
@@ -209,8 +428,13 @@ func (h *AgentHandler) UpdateAgent(c fiber.Ctx) error {
 		})
 	}
 
-	agent, err := h.agentService.UpdateAgent(c.Context(), agentID, &req)
+	agent, err := h.agentService.UpdateAgent(c.Context(), agentID, &req, userID)
 	if err != nil {
+		if errors.Is(err, application.ErrCapabilityNotInCatalog) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": err.Error(),
 		})
@@ -234,7 +458,7 @@ func (h *AgentHandler) UpdateAgent(c fiber.Ctx) error {
 	return c.JSON(h.enrichAgentResponse(c, agent))
 }
 
-// DeleteAgent deletes an agent
+// DeleteAgent soft-deletes an agent; force=true removes it permanently
 func (h *AgentHandler) DeleteAgent(c fiber.Ctx) error {
 	orgID := c.Locals("organization_id").(uuid.UUID)
 	userID := c.Locals("user_id").(uuid.UUID)
@@ -245,8 +469,16 @@ func (h *AgentHandler) DeleteAgent(c fiber.Ctx) error {
 		})
 	}
 
+	// force=true permanently removes the agent (including already soft-deleted ones)
+	force := c.Query("force") == "true"
+
 	// Verify agent belongs to organization first
-	existingAgent, err := h.agentService.GetAgent(c.Context(), agentID)
+	var existingAgent *domain.Agent
+	if force {
+		existingAgent, err = h.agentService.GetAgentIncludingDeleted(c.Context(), agentID)
+	} else {
+		existingAgent, err = h.agentService.GetAgent(c.Context(), agentID)
+	}
 	if err != nil {
 		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
 			"error": "Agent not found",
@@ -258,7 +490,12 @@ func (h *AgentHandler) DeleteAgent(c fiber.Ctx) error {
 		})
 	}
 
-	if err := h.agentService.DeleteAgent(c.Context(), agentID); err != nil {
+	if force {
+		err = h.agentService.HardDeleteAgent(c.Context(), agentID)
+	} else {
+		err = h.agentService.DeleteAgent(c.Context(), agentID)
+	}
+	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": err.Error(),
 		})
@@ -274,7 +511,9 @@ func (h *AgentHandler) DeleteAgent(c fiber.Ctx) error {
 		agentID,
 		c.IP(),
 		c.Get("User-Agent"),
-		nil,
+		map[string]interface{}{
+			"hard_delete": force,
+		},
 	)
 
 	return c.SendStatus(fiber.StatusNoContent)
@@ -346,6 +585,7 @@ func (h *AgentHandler) VerifyAgent(c fiber.Ctx) error {
 // @Param id path string true "Agent ID"
 // @Param request body VerifyActionRequest true "Action verification request"
 // @Success 200 {object} VerifyActionResponse
+// @Failure 400 {object} map[string]interface{} "Missing action type or oversized resource, with per-field errors"
 // @Failure 403 {object} ErrorResponse "Action denied"
 // @Router /agents/{id}/verify-action [post]
 func (h *AgentHandler) VerifyAction(c fiber.Ctx) error {
@@ -378,6 +618,20 @@ func (h *AgentHandler) VerifyAction(c fiber.Ctx) error {
 	}
 
 	orgID := agent.OrganizationID
+
+	fieldErrors, inCatalog, err := h.agentService.ValidateVerifyAction(c.Context(), orgID, req.ActionType, req.Resource)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Verification failed",
+		})
+	}
+	if len(fieldErrors) > 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":  "Invalid verification request",
+			"fields": fieldErrors,
+		})
+	}
+
 	startTime := c.Context().Time()
 
 	// Fetch agent and verify capabilities
@@ -409,6 +663,9 @@ func (h *AgentHandler) VerifyAction(c fiber.Ctx) error {
 	if req.Metadata != nil {
 		auditMetadata["request_metadata"] = req.Metadata
 	}
+	if !inCatalog {
+		auditMetadata["unknown_action_type"] = true
+	}
 
 	userID := uuid.Nil // System action - no specific user
 	if userIDLocal := c.Locals("user_id"); userIDLocal != nil {
@@ -430,6 +687,7 @@ func (h *AgentHandler) VerifyAction(c fiber.Ctx) error {
 	)
 
 	// 2. RECORD VERIFICATION EVENT (for monitoring dashboard)
+	// This also bumps the agent's last_active, whether the action is allowed or denied
 	verificationStatus := domain.VerificationEventStatusSuccess
 
 	if !decision {
@@ -457,6 +715,12 @@ func (h *AgentHandler) VerifyAction(c fiber.Ctx) error {
 		}
 	}
 
+	verificationResult := "approved"
+	if !decision {
+		verificationResult = "denied"
+	}
+	metrics.RecordVerification(verificationResult, string(protocol))
+
 	h.verificationEventService.LogVerificationEvent(
 		c.Context(),
 		orgID,
@@ -493,34 +757,24 @@ func (h *AgentHandler) VerifyAction(c fiber.Ctx) error {
 
 		// Create alert (non-blocking - don't fail the verification if alert creation fails)
 		if err := h.alertService.CreateAlert(c.Context(), alert); err != nil {
-			fmt.Printf("WARNING: Failed to create security alert for capability violation: %v\n", err)
+			logging.FromContext(c.Context()).Warn("failed to create security alert for capability violation", "agent_id", agentID, "error", err)
 		}
 	}
 
-	// 4. UPDATE AGENT LAST ACTIVE TIMESTAMP (for activity tracking)
-	// Update last_active regardless of whether action was allowed or denied
-	// This helps track when agents were last seen attempting actions
-	fmt.Printf("🔄 Updating last_active for agent %s...\n", agentID)
-	if err := h.agentService.UpdateLastActive(c.Context(), agentID); err != nil {
-		// Log but don't fail the request if timestamp update fails
-		fmt.Printf("❌ WARNING: Failed to update agent last_active: %v\n", err)
-	} else {
-		fmt.Printf("✅ Successfully updated last_active for agent %s\n", agentID)
+	response := fiber.Map{
+		"allowed":  decision,
+		"reason":   reason,
+		"audit_id": auditID,
+	}
+	// Unknown action types still go through authorization; tell the caller the name is not in the catalog
+	if !inCatalog {
+		response["warning"] = fmt.Sprintf("unknown action type %q: it matches no capability type in the organization's catalog", req.ActionType)
 	}
 
 	if !decision {
-		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
-			"allowed":  false,
-			"reason":   reason,
-			"audit_id": auditID,
-		})
+		return c.Status(fiber.StatusForbidden).JSON(response)
 	}
-
-	return c.JSON(fiber.Map{
-		"allowed":  true,
-		"reason":   reason,
-		"audit_id": auditID,
-	})
+	return c.JSON(response)
 }
 
 // LogActionResult logs the outcome of an action that was verified
@@ -581,6 +835,7 @@ func (h *AgentHandler) LogActionResult(c fiber.Ctx) error {
 // @Param lang query string false "SDK language (python, nodejs, go)" default(python)
 // @Success 200 {file} binary "SDK package as zip file"
 // @Failure 400 {object} ErrorResponse "Invalid agent ID or language"
+// @Failure 403 {object} ErrorResponse "Agent signature for a different agent"
 // @Failure 404 {object} ErrorResponse "Agent not found"
 // @Router /agents/{id}/sdk [get]
 func (h *AgentHandler) DownloadSDK(c fiber.Ctx) error {
@@ -639,16 +894,26 @@ func (h *AgentHandler) DownloadSDK(c fiber.Ctx) error {
 		filename = fmt.Sprintf("aim-sdk-%s-python.zip", agent.Name)
 
 	case "nodejs":
-		// TODO: Implement Node.js SDK generator
-		return c.Status(fiber.StatusNotImplemented).JSON(fiber.Map{
-			"error": "Node.js SDK not yet implemented",
+		sdkBytes, err = sdkgen.GenerateNodeSDK(sdkgen.NodeSDKConfig{
+			AgentID:    agentID.String(),
+			PublicKey:  publicKey,
+			PrivateKey: privateKey,
+			AIMURL:     getAIMBaseURL(c),
+			AgentName:  agent.Name,
+			Version:    "1.0.0",
 		})
+		filename = fmt.Sprintf("aim-sdk-%s-nodejs.zip", agent.Name)
 
 	case "go":
-		// TODO: Implement Go SDK generator
-		return c.Status(fiber.StatusNotImplemented).JSON(fiber.Map{
-			"error": "Go SDK not yet implemented",
+		sdkBytes, err = sdkgen.GenerateGoSDK(sdkgen.GoSDKConfig{
+			AgentID:    agentID.String(),
+			PublicKey:  publicKey,
+			PrivateKey: privateKey,
+			AIMURL:     getAIMBaseURL(c),
+			AgentName:  agent.Name,
+			Version:    "1.0.0",
 		})
+		filename = fmt.Sprintf("aim-sdk-%s-go.zip", agent.Name)
 	}
 
 	if err != nil {
@@ -662,8 +927,18 @@ func (h *AgentHandler) DownloadSDK(c fiber.Ctx) error {
 	c.Set("Content-Disposition", fmt.Sprintf("attachment; filename=%s", filename))
 	c.Set("Content-Length", fmt.Sprintf("%d", len(sdkBytes)))
 
-	// Log audit
-	userID := c.Locals("user_id").(uuid.UUID)
+	// Log audit. Agents signing with their Ed25519 credentials have no user; their downloads are
+	// attributed to the agent, recorded under its creator's account.
+	metadata := map[string]interface{}{
+		"language":  language,
+		"agentName": agent.Name,
+	}
+	userID, ok := c.Locals("user_id").(uuid.UUID)
+	if !ok {
+		userID = agent.CreatedBy
+		metadata["actorType"] = "agent"
+		metadata["actorAgentId"] = agentID.String()
+	}
 	h.auditService.LogAction(
 		c.Context(),
 		orgID,
@@ -673,10 +948,7 @@ func (h *AgentHandler) DownloadSDK(c fiber.Ctx) error {
 		agentID,
 		c.IP(),
 		c.Get("User-Agent"),
-		map[string]interface{}{
-			"language":  language,
-			"agentName": agent.Name,
-		},
+		metadata,
 	)
 
 	return c.Send(sdkBytes)
@@ -1001,9 +1273,9 @@ func (h *AgentHandler) GetAgentMCPServers(c fiber.Ctx) error {
 // Auto-Detection of MCP Servers
 // ========================================
 
-// DetectAndMapMCPServers auto-detects MCP servers from Claude Desktop config and maps them to agent
+// DetectAndMapMCPServers auto-detects MCP servers from an MCP config file on the server and maps them to agent
 // @Summary Auto-detect and map MCP servers
-// @Description Automatically detect MCP servers from Claude Desktop config file and map them to agent's talks_to list
+// @Description Automatically detect MCP servers from a Claude Desktop, Cursor or VS Code config file and map them to agent's talks_to list
 // @Tags agents
 // @Accept json
 // @Produce json
@@ -1091,6 +1363,156 @@ func (h *AgentHandler) DetectAndMapMCPServers(c fiber.Ctx) error {
 	return c.JSON(result)
 }
 
+// maxMCPConfigUploadSize caps uploaded MCP configs; real configs are a few KB
+const maxMCPConfigUploadSize = 1 << 20
+
+// DetectAndMapMCPServersFromUpload auto-detects MCP servers from an uploaded MCP config and maps them to agent
+// @Summary Auto-detect and map MCP servers from an uploaded config
+// @Description Detect MCP servers from a Claude Desktop, Cursor or VS Code config sent in the request body, either raw JSON or as the "config" file of a multipart form, and map them to agent's talks_to list. Use this from the web UI; the path-based /detect endpoint reads files on the server and is meant for the CLI.
+// @Tags agents
+// @Accept json
+// @Accept mpfd
+// @Produce json
+// @Param id path string true "Agent ID"
+// @Param config formData file false "claude_desktop_config.json, .cursor/mcp.json or VS Code mcp.json/settings.json (multipart uploads)"
+// @Param source query string false "Config format: claude_desktop_config, cursor or vscode; inferred when omitted (form field for multipart uploads)"
+// @Param auto_register query bool false "Register detected MCP servers that don't exist yet (form field for multipart uploads)"
+// @Param dry_run query bool false "Preview detected servers without applying (form field for multipart uploads)"
+// @Success 200 {object} application.DetectMCPServersResult
+// @Failure 400 {object} ErrorResponse "Invalid config"
+// @Failure 404 {object} ErrorResponse "Agent not found"
+// @Failure 413 {object} ErrorResponse "Config too large"
+// @Router /api/v1/agents/{id}/mcp-servers/detect-upload [post]
+func (h *AgentHandler) DetectAndMapMCPServersFromUpload(c fiber.Ctx) error {
+	orgID := c.Locals("organization_id").(uuid.UUID)
+	userID := c.Locals("user_id").(uuid.UUID)
+	agentID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid agent ID",
+		})
+	}
+
+	configData, status, err := readUploadedMCPConfig(c)
+	if err != nil {
+		return c.Status(status).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	req := application.DetectMCPServersRequest{
+		Source:       uploadValue(c, "source"),
+		AutoRegister: uploadOption(c, "auto_register"),
+		DryRun:       uploadOption(c, "dry_run"),
+	}
+
+	// Verify agent belongs to organization
+	agent, err := h.agentService.GetAgent(c.Context(), agentID)
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Agent not found",
+		})
+	}
+	if agent.OrganizationID != orgID {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error": "Access denied",
+		})
+	}
+
+	result, err := h.agentService.DetectMCPServersFromUpload(
+		c.Context(),
+		agentID,
+		configData,
+		&req,
+		h.mcpService,
+		orgID,
+		userID,
+	)
+	if err != nil {
+		status := fiber.StatusInternalServerError
+		if errors.Is(err, application.ErrInvalidMCPConfig) {
+			status = fiber.StatusBadRequest
+		}
+		return c.Status(status).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	// Log audit
+	if !req.DryRun {
+		h.auditService.LogAction(
+			c.Context(),
+			orgID,
+			userID,
+			domain.AuditActionUpdate,
+			"agent",
+			agentID,
+			c.IP(),
+			c.Get("User-Agent"),
+			map[string]interface{}{
+				"action":           "auto_detect_mcps",
+				"detected_count":   len(result.DetectedServers),
+				"registered_count": result.RegisteredCount,
+				"mapped_count":     result.MappedCount,
+				"config_source":    "upload",
+				"auto_register":    req.AutoRegister,
+			},
+		)
+	}
+
+	return c.JSON(result)
+}
+
+// readUploadedMCPConfig returns the uploaded config from the "config" file of a multipart
+// form, or the raw request body otherwise, along with the status to use if it can't be read
+func readUploadedMCPConfig(c fiber.Ctx) ([]byte, int, error) {
+	if !strings.HasPrefix(string(c.Request().Header.ContentType()), fiber.MIMEMultipartForm) {
+		body := c.Body()
+		if len(body) == 0 {
+			return nil, fiber.StatusBadRequest, errors.New("config JSON is required in the request body")
+		}
+		if len(body) > maxMCPConfigUploadSize {
+			return nil, fiber.StatusRequestEntityTooLarge, fmt.Errorf("config must be at most %d bytes", maxMCPConfigUploadSize)
+		}
+		return body, 0, nil
+	}
+
+	fileHeader, err := c.FormFile("config")
+	if err != nil {
+		return nil, fiber.StatusBadRequest, errors.New("multipart upload must include a \"config\" file")
+	}
+	if fileHeader.Size > maxMCPConfigUploadSize {
+		return nil, fiber.StatusRequestEntityTooLarge, fmt.Errorf("config must be at most %d bytes", maxMCPConfigUploadSize)
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		return nil, fiber.StatusBadRequest, fmt.Errorf("failed to read uploaded config: %w", err)
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(io.LimitReader(file, maxMCPConfigUploadSize))
+	if err != nil {
+		return nil, fiber.StatusBadRequest, fmt.Errorf("failed to read uploaded config: %w", err)
+	}
+	return data, 0, nil
+}
+
+// uploadValue reads an option from the multipart form or, for raw uploads, the query string
+func uploadValue(c fiber.Ctx, name string) string {
+	value := c.Query(name)
+	if strings.HasPrefix(string(c.Request().Header.ContentType()), fiber.MIMEMultipartForm) {
+		value = c.FormValue(name, value)
+	}
+	return value
+}
+
+// uploadOption reads a boolean option the same way as uploadValue
+func uploadOption(c fiber.Ctx, name string) bool {
+	enabled, _ := strconv.ParseBool(uploadValue(c, name))
+	return enabled
+}
+
 // GetAgentByIdentifier returns agent by ID or name (SDK API endpoint with API key auth)
 // @Summary Get agent by ID or name
 // @Description Get agent details by UUID or name. Works with API key authentication for SDK usage.
@@ -1214,6 +1636,160 @@ func (h *AgentHandler) GetAgentTrustScoreHistory(c fiber.Ctx) error {
 	return h.trustScoreHandler.GetTrustScoreHistory(c)
 }
 
+// UpdateAgentLabels replaces an agent's key-value labels
+// @Summary Update agent labels
+// @Description Replace the agent's labels, e.g. {"labels": {"env": "prod", "team": "payments"}}
+// @Tags agents
+// @Accept json
+// @Produce json
+// @Param id path string true "Agent ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /api/v1/agents/{id}/labels [put]
+func (h *AgentHandler) UpdateAgentLabels(c fiber.Ctx) error {
+	orgID := c.Locals("organization_id").(uuid.UUID)
+	userID := c.Locals("user_id").(uuid.UUID)
+	agentID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid agent ID",
+		})
+	}
+
+	var req struct {
+		Labels map[string]string `json:"labels"`
+	}
+	if err := c.Bind().JSON(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	// Verify agent belongs to organization
+	existingAgent, err := h.agentService.GetAgent(c.Context(), agentID)
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Agent not found",
+		})
+	}
+	if existingAgent.OrganizationID != orgID {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error": "Access denied",
+		})
+	}
+
+	agent, err := h.agentService.UpdateAgentLabels(c.Context(), agentID, req.Labels)
+	if err != nil {
+		if errors.Is(err, domain.ErrInvalidAgentLabels) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to update agent labels",
+		})
+	}
+
+	h.auditService.LogAction(
+		c.Context(),
+		orgID,
+		userID,
+		domain.AuditActionUpdate,
+		"agent_labels",
+		agentID,
+		c.IP(),
+		c.Get("User-Agent"),
+		map[string]interface{}{
+			"agentName":      agent.Name,
+			"previousLabels": existingAgent.Labels,
+			"labels":         agent.Labels,
+		},
+	)
+
+	return c.JSON(h.enrichAgentResponse(c, agent))
+}
+
+// UpdateAgentOAuthClient binds the agent to the OAuth client whose tokens verify it
+// @Summary Set agent OAuth client
+// @Description Set the OAuth client ID (the introspected client_id or sub) whose access tokens verify the agent
+// @Description at POST /verifications/oauth, e.g. {"client_id": "billing-agent"}. An empty client_id removes it.
+// @Tags agents
+// @Accept json
+// @Produce json
+// @Param id path string true "Agent ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /api/v1/agents/{id}/oauth-client [put]
+func (h *AgentHandler) UpdateAgentOAuthClient(c fiber.Ctx) error {
+	orgID := c.Locals("organization_id").(uuid.UUID)
+	userID := c.Locals("user_id").(uuid.UUID)
+	agentID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid agent ID",
+		})
+	}
+
+	var req struct {
+		ClientID string `json:"client_id"`
+	}
+	if err := c.Bind().JSON(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	// Verify agent belongs to organization
+	existingAgent, err := h.agentService.GetAgent(c.Context(), agentID)
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Agent not found",
+		})
+	}
+	if existingAgent.OrganizationID != orgID {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error": "Access denied",
+		})
+	}
+
+	agent, err := h.agentService.UpdateAgentOAuthClientID(c.Context(), agentID, req.ClientID)
+	if err != nil {
+		if errors.Is(err, domain.ErrInvalidOAuthClientID) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to update agent OAuth client",
+		})
+	}
+
+	h.auditService.LogAction(
+		c.Context(),
+		orgID,
+		userID,
+		domain.AuditActionUpdate,
+		"agent_oauth_client",
+		agentID,
+		c.IP(),
+		c.Get("User-Agent"),
+		map[string]interface{}{
+			"agentName":             agent.Name,
+			"previousOAuthClientId": existingAgent.OAuthClientID,
+			"oauthClientId":         agent.OAuthClientID,
+		},
+	)
+
+	return c.JSON(fiber.Map{
+		"agentId":       agent.ID,
+		"oauthClientId": agent.OAuthClientID,
+	})
+}
+
 // UpdateAgentTrustScore manually updates trust score (admin override)
 // @Summary Update agent trust score (admin only)
 // @Description Manually override the trust score for an agent
@@ -1329,7 +1905,7 @@ func (h *AgentHandler) RecalculateAgentTrustScore(c fiber.Ctx) error {
 
 // SuspendAgent suspends an agent by setting its status to suspended
 // @Summary Suspend agent
-// @Description Suspend an agent by setting its status to suspended. The agent will be unable to perform actions.
+// @Description Suspend an agent by setting its status to suspended. The agent will be unable to perform actions. An agent.suspended webhook event is emitted when the status changes.
 // @Tags agents
 // @Produce json
 // @Param id path string true "Agent ID"
@@ -1400,7 +1976,7 @@ func (h *AgentHandler) SuspendAgent(c fiber.Ctx) error {
 
 // ReactivateAgent reactivates a suspended agent by setting its status to verified
 // @Summary Reactivate agent
-// @Description Reactivate a suspended agent by setting its status to verified. The agent will be able to perform actions again.
+// @Description Reactivate a suspended agent by setting its status to verified. The agent will be able to perform actions again. An agent.reactivated webhook event is emitted when the status changes.
 // @Tags agents
 // @Produce json
 // @Param id path string true "Agent ID"
@@ -1470,6 +2046,167 @@ func (h *AgentHandler) ReactivateAgent(c fiber.Ctx) error {
 	})
 }
 
+// CompromiseAgentRequest is the optional body of POST /agents/:id/compromise
+type CompromiseAgentRequest struct {
+	Reason string `json:"reason"`
+}
+
+// CompromiseAgent flags an agent as compromised and suspends it
+// @Summary Mark agent as compromised
+// @Description Flag an agent as compromised, recording who flagged it. The agent is suspended and every verify-action call is denied until the flag is cleared. A critical alert and an agent.compromised webhook event are emitted, and cached verification decisions for the agent are dropped.
+// @Tags agents
+// @Accept json
+// @Produce json
+// @Param id path string true "Agent ID"
+// @Param request body CompromiseAgentRequest false "Why the agent is considered compromised"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} ErrorResponse "Invalid agent ID or request body"
+// @Failure 404 {object} ErrorResponse "Agent not found"
+// @Failure 403 {object} ErrorResponse "Access denied"
+// @Failure 409 {object} ErrorResponse "Agent is already marked as compromised"
+// @Router /agents/{id}/compromise [post]
+func (h *AgentHandler) CompromiseAgent(c fiber.Ctx) error {
+	orgID := c.Locals("organization_id").(uuid.UUID)
+	userID := c.Locals("user_id").(uuid.UUID)
+	agentID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid agent ID",
+		})
+	}
+
+	var req CompromiseAgentRequest
+	if len(c.Body()) > 0 {
+		if err := c.Bind().JSON(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Invalid request body",
+			})
+		}
+	}
+
+	// Verify agent belongs to organization first
+	agent, err := h.agentService.GetAgent(c.Context(), agentID)
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Agent not found",
+		})
+	}
+	if agent.OrganizationID != orgID {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error": "Access denied",
+		})
+	}
+
+	agent, err = h.agentService.CompromiseAgent(c.Context(), agentID, userID, req.Reason)
+	if err != nil {
+		if errors.Is(err, application.ErrAgentAlreadyCompromised) {
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	// Log audit
+	h.auditService.LogAction(
+		c.Context(),
+		orgID,
+		userID,
+		domain.AuditActionUpdate,
+		"agent",
+		agent.ID,
+		c.IP(),
+		c.Get("User-Agent"),
+		map[string]interface{}{
+			"action":    "compromise",
+			"agentName": agent.Name,
+			"status":    agent.Status,
+			"reason":    req.Reason,
+		},
+	)
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"message": "Agent marked as compromised and suspended",
+		"status":  agent.Status,
+		"agent":   agent,
+	})
+}
+
+// UncompromiseAgent clears an agent's compromise flag
+// @Summary Clear agent compromise flag
+// @Description Clear the compromise flag set by POST /agents/{id}/compromise and emit an agent.uncompromised webhook event. The agent stays suspended until it is reactivated.
+// @Tags agents
+// @Produce json
+// @Param id path string true "Agent ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} ErrorResponse "Invalid agent ID"
+// @Failure 404 {object} ErrorResponse "Agent not found"
+// @Failure 403 {object} ErrorResponse "Access denied"
+// @Failure 409 {object} ErrorResponse "Agent is not marked as compromised"
+// @Router /agents/{id}/uncompromise [post]
+func (h *AgentHandler) UncompromiseAgent(c fiber.Ctx) error {
+	orgID := c.Locals("organization_id").(uuid.UUID)
+	userID := c.Locals("user_id").(uuid.UUID)
+	agentID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid agent ID",
+		})
+	}
+
+	// Verify agent belongs to organization first
+	agent, err := h.agentService.GetAgent(c.Context(), agentID)
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Agent not found",
+		})
+	}
+	if agent.OrganizationID != orgID {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error": "Access denied",
+		})
+	}
+
+	agent, err = h.agentService.UncompromiseAgent(c.Context(), agentID, userID)
+	if err != nil {
+		if errors.Is(err, application.ErrAgentNotCompromised) {
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	// Log audit
+	h.auditService.LogAction(
+		c.Context(),
+		orgID,
+		userID,
+		domain.AuditActionUpdate,
+		"agent",
+		agent.ID,
+		c.IP(),
+		c.Get("User-Agent"),
+		map[string]interface{}{
+			"action":    "uncompromise",
+			"agentName": agent.Name,
+			"status":    agent.Status,
+		},
+	)
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"message": "Agent compromise flag cleared; reactivate the agent to restore it",
+		"status":  agent.Status,
+		"agent":   agent,
+	})
+}
+
 // RotateCredentials rotates an agent's cryptographic credentials by generating new Ed25519 keypair
 // @Summary Rotate agent credentials
 // @Description Generate new Ed25519 keypair for agent. Previous public key is stored for grace period.
@@ -0,0 +1,112 @@
+package handlers
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/application"
+	"github.com/opena2a/identity/backend/internal/crypto"
+	"github.com/opena2a/identity/backend/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// sdkDownloadAgentRepository serves a single agent
+type sdkDownloadAgentRepository struct {
+	domain.AgentRepository
+	agent *domain.Agent
+}
+
+func (r *sdkDownloadAgentRepository) GetByID(id uuid.UUID) (*domain.Agent, error) {
+	if id != r.agent.ID {
+		return nil, assert.AnError
+	}
+	return r.agent, nil
+}
+
+// recordingAuditLogRepository keeps the entries written to it
+type recordingAuditLogRepository struct {
+	domain.AuditLogRepository
+	logs []*domain.AuditLog
+}
+
+func (r *recordingAuditLogRepository) Create(log *domain.AuditLog) error {
+	r.logs = append(r.logs, log)
+	return nil
+}
+
+func newSDKDownloadTestAgent(t *testing.T) *domain.Agent {
+	t.Helper()
+	keyVault, err := crypto.NewKeyVault("MDEyMzQ1Njc4OTAxMjM0NTY3ODkwMTIzNDU2Nzg5MDE=")
+	require.NoError(t, err)
+	encryptedPrivateKey, err := keyVault.EncryptPrivateKey("cHJpdmF0ZS1rZXk=")
+	require.NoError(t, err)
+
+	publicKey := "cHVibGljLWtleQ=="
+	return &domain.Agent{
+		ID:                  uuid.New(),
+		OrganizationID:      uuid.New(),
+		Name:                "billing-agent",
+		CreatedBy:           uuid.New(),
+		PublicKey:           &publicKey,
+		EncryptedPrivateKey: &encryptedPrivateKey,
+	}
+}
+
+// newSDKDownloadTestApp serves DownloadSDK with locals set by authenticate, which stands in for
+// the auth middleware
+func newSDKDownloadTestApp(t *testing.T, agent *domain.Agent, auditRepo domain.AuditLogRepository, authenticate fiber.Handler) *fiber.App {
+	t.Helper()
+	keyVault, err := crypto.NewKeyVault("MDEyMzQ1Njc4OTAxMjM0NTY3ODkwMTIzNDU2Nzg5MDE=")
+	require.NoError(t, err)
+	agentService := application.NewAgentService(&sdkDownloadAgentRepository{agent: agent}, nil, nil, keyVault, nil, nil, nil, nil, nil, nil, nil, nil)
+	handler := NewAgentHandler(agentService, nil, application.NewAuditService(auditRepo), nil, nil, nil, nil, nil)
+
+	app := fiber.New()
+	app.Get("/agents/:id/sdk", handler.DownloadSDK, authenticate)
+	return app
+}
+
+func TestDownloadSDK_AgentAuthenticated(t *testing.T) {
+	agent := newSDKDownloadTestAgent(t)
+	auditRepo := &recordingAuditLogRepository{}
+	app := newSDKDownloadTestApp(t, agent, auditRepo, func(c fiber.Ctx) error {
+		// Ed25519AgentMiddleware sets no user_id
+		c.Locals("agent_id", agent.ID)
+		c.Locals("organization_id", agent.OrganizationID)
+		c.Locals("auth_method", "ed25519")
+		return c.Next()
+	})
+
+	resp, err := app.Test(httptest.NewRequest("GET", "/agents/"+agent.ID.String()+"/sdk?lang=go", nil))
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+	assert.Equal(t, "application/zip", resp.Header.Get("Content-Type"))
+
+	// The download is attributed to the agent
+	require.Len(t, auditRepo.logs, 1)
+	assert.Equal(t, agent.CreatedBy, auditRepo.logs[0].UserID)
+	assert.Equal(t, "agent", auditRepo.logs[0].Metadata["actorType"])
+	assert.Equal(t, agent.ID.String(), auditRepo.logs[0].Metadata["actorAgentId"])
+}
+
+func TestDownloadSDK_UserAuthenticated(t *testing.T) {
+	agent := newSDKDownloadTestAgent(t)
+	auditRepo := &recordingAuditLogRepository{}
+	userID := uuid.New()
+	app := newSDKDownloadTestApp(t, agent, auditRepo, func(c fiber.Ctx) error {
+		c.Locals("user_id", userID)
+		c.Locals("organization_id", agent.OrganizationID)
+		return c.Next()
+	})
+
+	resp, err := app.Test(httptest.NewRequest("GET", "/agents/"+agent.ID.String()+"/sdk?lang=python", nil))
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+	require.Len(t, auditRepo.logs, 1)
+	assert.Equal(t, userID, auditRepo.logs[0].UserID)
+	assert.NotContains(t, auditRepo.logs[0].Metadata, "actorType")
+}
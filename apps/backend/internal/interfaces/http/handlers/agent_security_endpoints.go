@@ -1,7 +1,10 @@
 package handlers
 
 import (
+	"bufio"
 	"fmt"
+	"log/slog"
+	"time"
 
 	"github.com/gofiber/fiber/v3"
 	"github.com/google/uuid"
@@ -176,3 +179,95 @@ func (h *AgentHandler) GetAgentAuditLogs(c fiber.Ctx) error {
 		"offset":     offset,
 	})
 }
+
+// ExportAgentAuditLogs streams an agent's full audit trail for investigations
+// @Summary Export agent audit logs
+// @Description Stream every audit log for a specific agent in CSV (RFC 4180) or JSON format, optionally within a date range
+// @Tags agents
+// @Produce text/csv,application/json
+// @Param id path string true "Agent ID"
+// @Param format query string false "Export format (csv or json)" default(csv)
+// @Param start_date query string false "Only include logs at or after this time (RFC3339 or YYYY-MM-DD)"
+// @Param end_date query string false "Only include logs before this time (RFC3339, or YYYY-MM-DD to include that day)"
+// @Success 200 {file} file
+// @Failure 400 {object} ErrorResponse "Invalid agent ID, format or date range"
+// @Failure 404 {object} ErrorResponse "Agent not found"
+// @Failure 403 {object} ErrorResponse "Access denied"
+// @Router /agents/{id}/audit-logs/export [get]
+func (h *AgentHandler) ExportAgentAuditLogs(c fiber.Ctx) error {
+	orgID := c.Locals("organization_id").(uuid.UUID)
+	userID := c.Locals("user_id").(uuid.UUID)
+	agentID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid agent ID",
+		})
+	}
+
+	format := c.Query("format", "csv")
+	if format != "csv" && format != "json" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid format. Supported formats: csv, json",
+		})
+	}
+
+	// Only the date range applies; the export is always scoped to this agent
+	filter, err := parseAuditLogFilter(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	// Verify agent belongs to organization
+	agent, err := h.agentService.GetAgent(c.Context(), agentID)
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Agent not found",
+		})
+	}
+	if agent.OrganizationID != orgID {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error": "Access denied",
+		})
+	}
+
+	h.auditService.LogAction(
+		c.Context(),
+		orgID,
+		userID,
+		domain.AuditActionView,
+		"agent_audit_log_export",
+		agentID,
+		c.IP(),
+		c.Get("User-Agent"),
+		map[string]interface{}{
+			"agentName": agent.Name,
+			"format":    format,
+			"startDate": c.Query("start_date"),
+			"endDate":   c.Query("end_date"),
+		},
+	)
+
+	filename := fmt.Sprintf("agent-%s-audit-log-%s.%s", agentID, time.Now().UTC().Format("20060102-150405"), format)
+	if format == "json" {
+		c.Set("Content-Type", "application/json")
+	} else {
+		c.Set("Content-Type", "text/csv")
+	}
+	c.Set("Content-Disposition", "attachment; filename="+filename)
+
+	// Stream the export. The fiber.Ctx is recycled once the handler returns, but the
+	// underlying request context lives until the body is written and is cancelled on shutdown.
+	auditService := h.auditService
+	startDate, endDate := filter.StartDate, filter.EndDate
+	requestCtx := c.Context()
+	c.Response().SetBodyStreamWriter(func(w *bufio.Writer) {
+		if _, err := auditService.ExportAgentLogs(requestCtx, w, orgID, agentID, startDate, endDate, format); err != nil {
+			slog.Warn("agent audit log export failed", "org_id", orgID, "agent_id", agentID, "error", err)
+		}
+		w.Flush()
+	})
+
+	return nil
+}
@@ -3,7 +3,6 @@ package handlers
 import (
 	"database/sql"
 	"fmt"
-	"log"
 	"sort"
 	"strconv"
 	"time"
@@ -12,6 +11,7 @@ import (
 	"github.com/google/uuid"
 	"github.com/opena2a/identity/backend/internal/application"
 	"github.com/opena2a/identity/backend/internal/domain"
+	"github.com/opena2a/identity/backend/internal/infrastructure/logging"
 )
 
 type AnalyticsHandler struct {
@@ -556,6 +556,17 @@ func (h *AnalyticsHandler) GetVerificationActivity(c fiber.Ctx) error {
 		})
 	}
 
+	// Verification latency over the same window, at most its last MaxLatencyStatisticsWindow,
+	// omitted if it cannot be computed
+	var latency *domain.VerificationLatencyStatistics
+	if h.verificationEventService != nil {
+		now := time.Now()
+		latency, err = h.verificationEventService.GetLatencyStatistics(c.Context(), orgID, now.AddDate(0, -months, 0), now)
+		if err != nil {
+			logging.FromContext(c.Context()).Warn("failed to compute verification latency", "org_id", orgID, "error", err)
+		}
+	}
+
 	// Calculate current verified and pending counts
 	verifiedCount := 0
 	pendingCount := 0
@@ -579,6 +590,7 @@ func (h *AnalyticsHandler) GetVerificationActivity(c fiber.Ctx) error {
 				COUNT(*) FILTER (WHERE status = 'pending') as pending
 			FROM agents
 			WHERE organization_id = $1
+				AND deleted_at IS NULL
 				AND created_at >= NOW() - INTERVAL '1 month' * $2
 			GROUP BY DATE_TRUNC('month', created_at)
 			ORDER BY month_start ASC
@@ -651,6 +663,7 @@ func (h *AnalyticsHandler) GetVerificationActivity(c fiber.Ctx) error {
 		return c.JSON(fiber.Map{
 			"period":   fmt.Sprintf("Last %d months", months),
 			"activity": activity,
+			"latency":  latency,
 			"currentStats": map[string]interface{}{
 				"totalVerified": verifiedCount,
 				"totalPending":  pendingCount,
@@ -741,6 +754,7 @@ func (h *AnalyticsHandler) GetVerificationActivity(c fiber.Ctx) error {
 	return c.JSON(fiber.Map{
 		"period":   fmt.Sprintf("Last %d months", months),
 		"activity": activity,
+		"latency":  latency,
 		"currentStats": map[string]interface{}{
 			"totalVerified": verifiedCount,
 			"totalPending":  pendingCount,
@@ -749,6 +763,158 @@ func (h *AnalyticsHandler) GetVerificationActivity(c fiber.Ctx) error {
 	})
 }
 
+// GetVerificationLatency retrieves verification latency percentiles
+// @Summary Get verification latency percentiles
+// @Description Get p50/p95/p99 verification duration over a time range, to catch performance regressions
+// @Tags analytics
+// @Produce json
+// @Param period query string false "Time period (24h, 7d, 30d, custom)" default(24h)
+// @Param start_time query string false "Start time for custom period (RFC3339)"
+// @Param end_time query string false "End time for custom period (RFC3339)"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /api/v1/analytics/latency [get]
+func (h *AnalyticsHandler) GetVerificationLatency(c fiber.Ctx) error {
+	orgID, ok := c.Locals("organization_id").(uuid.UUID)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Organization ID not found in context",
+		})
+	}
+
+	startTime, endTime, err := parseStatisticsPeriod(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	if endTime.Sub(startTime) > application.MaxLatencyStatisticsWindow {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": fmt.Sprintf("Time range must not exceed %d days", int(application.MaxLatencyStatisticsWindow.Hours()/24)),
+		})
+	}
+
+	latency, err := h.verificationEventService.GetLatencyStatistics(c.Context(), orgID, startTime, endTime)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to compute verification latency",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"latency":   latency,
+		"startTime": startTime,
+		"endTime":   endTime,
+	})
+}
+
+// maxAPIUsageWindow bounds the time range API usage is grouped over, so one request cannot make
+// Postgres scan an organization's whole call history
+const maxAPIUsageWindow = 90 * 24 * time.Hour
+
+// GetAPIUsage retrieves the per-endpoint breakdown of API calls
+// @Summary Get API usage by endpoint
+// @Description Get call counts, error rates and average latency of the organization's API calls, grouped by endpoint and status class. IDs in paths are replaced with :id so calls to the same route are counted together. The range may span at most 90 days.
+// @Tags analytics
+// @Produce json
+// @Param period query string false "Time period (24h, 7d, 30d, custom)" default(24h)
+// @Param start_time query string false "Start time for custom period (RFC3339)"
+// @Param end_time query string false "End time for custom period (RFC3339)"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /api/v1/analytics/api-usage [get]
+func (h *AnalyticsHandler) GetAPIUsage(c fiber.Ctx) error {
+	orgID, ok := c.Locals("organization_id").(uuid.UUID)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Organization ID not found in context",
+		})
+	}
+
+	startTime, endTime, err := parseStatisticsPeriod(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	if endTime.Sub(startTime) > maxAPIUsageWindow {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": fmt.Sprintf("Time range must not exceed %d days", int(maxAPIUsageWindow.Hours()/24)),
+		})
+	}
+
+	// Recorded by the AnalyticsTracking middleware. UUID path segments are replaced with :id
+	// before grouping, so each route comes back as one group per status class.
+	rows, err := h.db.Query(`
+		SELECT method,
+			regexp_replace(endpoint, '/[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}(?=/|$)', '/:id', 'g') AS route,
+			status_code / 100 AS status_class, COUNT(*), COALESCE(SUM(duration_ms), 0)
+		FROM api_calls
+		WHERE organization_id = $1
+			AND called_at >= $2
+			AND called_at <= $3
+		GROUP BY method, route, status_class
+	`, orgID, startTime, endTime)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to fetch API usage",
+		})
+	}
+	defer rows.Close()
+
+	var groups []domain.APIUsageGroup
+	for rows.Next() {
+		var group domain.APIUsageGroup
+		if err := rows.Scan(&group.Method, &group.Endpoint, &group.StatusClass, &group.Calls, &group.TotalDurationMs); err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Failed to fetch API usage",
+			})
+		}
+		groups = append(groups, group)
+	}
+	if err := rows.Err(); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to fetch API usage",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"usage":     domain.CalculateAPIUsageStatistics(groups),
+		"startTime": startTime,
+		"endTime":   endTime,
+	})
+}
+
+// GetTopology retrieves the agent↔MCP connection graph
+// @Summary Get agent-MCP topology
+// @Description Get agents and MCP servers as nodes, and their declared, recorded and detected connections as edges
+// @Tags analytics
+// @Produce json
+// @Success 200 {object} domain.AgentMCPTopology
+// @Failure 500 {object} map[string]interface{}
+// @Router /api/v1/analytics/topology [get]
+func (h *AnalyticsHandler) GetTopology(c fiber.Ctx) error {
+	orgID, ok := c.Locals("organization_id").(uuid.UUID)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Organization ID not found in context",
+		})
+	}
+
+	topology, err := h.mcpService.GetTopology(c.Context(), orgID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to build agent-MCP topology",
+		})
+	}
+
+	return c.JSON(topology)
+}
+
 // GetAgentActivity retrieves agent activity metrics
 // @Summary Get agent activity metrics
 // @Description Get activity metrics for all agents
@@ -776,7 +942,7 @@ func (h *AnalyticsHandler) GetAgentActivity(c fiber.Ctx) error {
 			COALESCE(SUM(aam.data_processed_bytes) / 1024.0 / 1024.0, 0) as data_processed_mb
 		FROM agents a
 		LEFT JOIN agent_activity_metrics aam ON a.id = aam.agent_id
-		WHERE a.organization_id = $1
+		WHERE a.organization_id = $1 AND a.deleted_at IS NULL
 		GROUP BY a.id, a.name, a.status, a.trust_score, a.created_at, a.last_active
 		ORDER BY last_active DESC
 		LIMIT $2 OFFSET $3
@@ -879,7 +1045,7 @@ func (h *AnalyticsHandler) GetAgentActivity(c fiber.Ctx) error {
 
 	// Get total count for pagination
 	var total int
-	countQuery := `SELECT COUNT(*) FROM agents WHERE organization_id = $1`
+	countQuery := `SELECT COUNT(*) FROM agents WHERE organization_id = $1 AND deleted_at IS NULL`
 	h.db.QueryRow(countQuery, orgID).Scan(&total)
 
 	// Calculate summary statistics for the activity timeline
@@ -945,7 +1111,7 @@ func (h *AnalyticsHandler) GetDashboardStats(c fiber.Ctx) error {
 	agents, err := h.agentService.ListAgents(c.Context(), orgID)
 	if err != nil {
 		// 🔍 LOG DETAILED ERROR for debugging
-		log.Printf("❌ Failed to fetch agents for org %s: %v", orgID.String(), err)
+		logging.FromContext(c.Context()).Error("failed to fetch agents", "org_id", orgID, "error", err)
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": fmt.Sprintf("Failed to fetch agents: %v", err),
 		})
@@ -954,7 +1120,7 @@ func (h *AnalyticsHandler) GetDashboardStats(c fiber.Ctx) error {
 	// Fetch MCP servers
 	mcpServers, err := h.mcpService.ListMCPServers(c.Context(), orgID)
 	if err != nil {
-		fmt.Printf("Error fetching MCP servers: %v", err)
+		logging.FromContext(c.Context()).Error("failed to fetch MCP servers", "org_id", orgID, "error", err)
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": "Failed to fetch MCP servers",
 		})
@@ -1125,7 +1291,7 @@ func (h *AnalyticsHandler) GetActivitySummary(c fiber.Ctx) error {
 	`
 	err = h.db.QueryRow(verificationQuery, orgID, startTime).Scan(&verificationCount)
 	if err != nil {
-		log.Printf("❌ Error fetching verification count: %v", err)
+		logging.FromContext(c.Context()).Error("failed to fetch verification count", "org_id", orgID, "error", err)
 		verificationCount = 0
 	}
 
@@ -1138,7 +1304,7 @@ func (h *AnalyticsHandler) GetActivitySummary(c fiber.Ctx) error {
 	`
 	err = h.db.QueryRow(attestationQuery, orgID, startTime).Scan(&attestationCount)
 	if err != nil {
-		log.Printf("❌ Error fetching attestation count: %v", err)
+		logging.FromContext(c.Context()).Error("failed to fetch attestation count", "org_id", orgID, "error", err)
 		attestationCount = 0
 	}
 
@@ -1161,14 +1327,14 @@ func (h *AnalyticsHandler) GetActivitySummary(c fiber.Ctx) error {
 
 	rows, err := h.db.Query(activityByDayQuery, orgID, startTime)
 	if err != nil {
-		log.Printf("❌ Error fetching activity by day: %v", err)
+		logging.FromContext(c.Context()).Error("failed to fetch activity by day", "org_id", orgID, "error", err)
 		activityByDay = []DailyActivity{}
 	} else {
 		defer rows.Close()
 		for rows.Next() {
 			var activity DailyActivity
 			if err := rows.Scan(&activity.Date, &activity.Count); err != nil {
-				log.Printf("❌ Error scanning activity row: %v", err)
+				logging.FromContext(c.Context()).Error("failed to scan activity row", "org_id", orgID, "error", err)
 				continue
 			}
 			activityByDay = append(activityByDay, activity)
@@ -1205,7 +1371,7 @@ func (h *AnalyticsHandler) GetActivitySummary(c fiber.Ctx) error {
 
 	activityRows, err := h.db.Query(recentActivityQuery, orgID, startTime)
 	if err != nil {
-		log.Printf("❌ Error fetching recent activity: %v", err)
+		logging.FromContext(c.Context()).Error("failed to fetch recent activity", "org_id", orgID, "error", err)
 		recentActivity = []RecentActivity{}
 	} else {
 		defer activityRows.Close()
@@ -1220,7 +1386,7 @@ func (h *AnalyticsHandler) GetActivitySummary(c fiber.Ctx) error {
 				&activity.CreatedAt,
 				&activity.DurationMs,
 			); err != nil {
-				log.Printf("❌ Error scanning recent activity row: %v", err)
+				logging.FromContext(c.Context()).Error("failed to scan recent activity row", "org_id", orgID, "error", err)
 				continue
 			}
 			recentActivity = append(recentActivity, activity)
@@ -1230,13 +1396,13 @@ func (h *AnalyticsHandler) GetActivitySummary(c fiber.Ctx) error {
 	// Get agent and MCP server counts
 	agents, err := h.agentService.ListAgents(c.Context(), orgID)
 	if err != nil {
-		log.Printf("❌ Error fetching agents: %v", err)
+		logging.FromContext(c.Context()).Error("failed to fetch agents", "org_id", orgID, "error", err)
 		agents = []*domain.Agent{}
 	}
 
 	mcpServers, err := h.mcpService.ListMCPServers(c.Context(), orgID)
 	if err != nil {
-		log.Printf("❌ Error fetching MCP servers: %v", err)
+		logging.FromContext(c.Context()).Error("failed to fetch MCP servers", "org_id", orgID, "error", err)
 		mcpServers = []*domain.MCPServer{}
 	}
 
@@ -0,0 +1,170 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gofiber/fiber/v3"
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/application"
+	"github.com/opena2a/identity/backend/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// latencyVerificationEventRepository serves fixed percentiles for every time range
+type latencyVerificationEventRepository struct {
+	domain.VerificationEventRepository
+	latency domain.VerificationLatencyStatistics
+	start   time.Time
+	end     time.Time
+}
+
+func (r *latencyVerificationEventRepository) GetLatencyStatistics(orgID uuid.UUID, startTime, endTime time.Time) (*domain.VerificationLatencyStatistics, error) {
+	r.start, r.end = startTime, endTime
+	latency := r.latency
+	return &latency, nil
+}
+
+func newLatencyTestApp(repo *latencyVerificationEventRepository) *fiber.App {
+	service := application.NewVerificationEventService(repo, stubAgentRepository{}, nil)
+	handler := NewAnalyticsHandler(nil, nil, nil, service, nil, nil, nil, nil)
+
+	app := fiber.New()
+	app.Get("/analytics/latency", handler.GetVerificationLatency, func(c fiber.Ctx) error {
+		c.Locals("organization_id", uuid.New()) // Stands in for the auth middleware
+		return c.Next()
+	})
+	return app
+}
+
+func TestGetVerificationLatency_ReturnsPercentiles(t *testing.T) {
+	repo := &latencyVerificationEventRepository{latency: domain.VerificationLatencyStatistics{
+		Count: 100, P50DurationMs: 10, P95DurationMs: 200, P99DurationMs: 213,
+	}}
+
+	resp, err := newLatencyTestApp(repo).Test(httptest.NewRequest("GET", "/analytics/latency?period=7d", nil))
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+	var body struct {
+		Latency domain.VerificationLatencyStatistics `json:"latency"`
+	}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+	assert.Equal(t, 100, body.Latency.Count)
+	assert.Equal(t, 10.0, body.Latency.P50DurationMs)
+	assert.Equal(t, 200.0, body.Latency.P95DurationMs)
+	assert.Equal(t, 213.0, body.Latency.P99DurationMs)
+	assert.InDelta(t, (7 * 24 * time.Hour).Seconds(), repo.end.Sub(repo.start).Seconds(), 1)
+}
+
+func TestGetVerificationLatency_RejectsRangeOverWindow(t *testing.T) {
+	repo := &latencyVerificationEventRepository{}
+	end := time.Now().UTC()
+	start := end.Add(-application.MaxLatencyStatisticsWindow - time.Hour)
+	url := "/analytics/latency?period=custom&start_time=" + start.Format(time.RFC3339) + "&end_time=" + end.Format(time.RFC3339)
+
+	resp, err := newLatencyTestApp(repo).Test(httptest.NewRequest("GET", url, nil))
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+	assert.True(t, repo.start.IsZero(), "no percentiles are computed for an oversized range")
+}
+
+func TestGetVerificationLatency_RejectsUnknownPeriod(t *testing.T) {
+	resp, err := newLatencyTestApp(&latencyVerificationEventRepository{}).
+		Test(httptest.NewRequest("GET", "/analytics/latency?period=fortnight", nil))
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+}
+
+func TestGetAPIUsage_GroupsByEndpointAndStatusClass(t *testing.T) {
+	db, sqlMock, err := sqlmock.New()
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	orgID := uuid.New()
+	handler := NewAnalyticsHandler(nil, nil, nil, nil, nil, nil, nil, db)
+	app := fiber.New()
+	app.Get("/analytics/api-usage", handler.GetAPIUsage, func(c fiber.Ctx) error {
+		c.Locals("organization_id", orgID) // Stands in for the auth middleware
+		return c.Next()
+	})
+
+	// Tracking rows as grouped by the query, which has already replaced IDs in paths with :id
+	sqlMock.ExpectQuery(regexp.QuoteMeta("regexp_replace(endpoint,")+`.+GROUP BY method, route, status_class`).
+		WithArgs(orgID, sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"method", "route", "status_class", "count", "sum"}).
+			AddRow("GET", "/api/v1/agents/:id", 2, 8, 100).
+			AddRow("GET", "/api/v1/agents/:id", 4, 2, 10).
+			AddRow("POST", "/api/v1/agents", 2, 3, 300).
+			AddRow("POST", "/api/v1/agents", 5, 1, 500))
+
+	resp, err := app.Test(httptest.NewRequest("GET", "/analytics/api-usage?period=7d", nil))
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+	var body struct {
+		Usage domain.APIUsageStatistics `json:"usage"`
+	}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+	assert.Equal(t, int64(14), body.Usage.TotalCalls)
+	assert.InDelta(t, 3.0/14, body.Usage.ErrorRate, 1e-9)
+	assert.InDelta(t, 910.0/14, body.Usage.AvgDurationMs, 1e-9)
+
+	assert.Equal(t, []domain.APIEndpointUsage{
+		{
+			Method: "GET", Endpoint: "/api/v1/agents/:id", Calls: 10, Errors: 2, ErrorRate: 0.2, AvgDurationMs: 11,
+			StatusClasses: []domain.APIStatusClassUsage{
+				{StatusClass: "2xx", Calls: 8, AvgDurationMs: 12.5},
+				{StatusClass: "4xx", Calls: 2, AvgDurationMs: 5},
+			},
+		},
+		{
+			Method: "POST", Endpoint: "/api/v1/agents", Calls: 4, Errors: 1, ErrorRate: 0.25, AvgDurationMs: 200,
+			StatusClasses: []domain.APIStatusClassUsage{
+				{StatusClass: "2xx", Calls: 3, AvgDurationMs: 100},
+				{StatusClass: "5xx", Calls: 1, AvgDurationMs: 500},
+			},
+		},
+	}, body.Usage.Endpoints)
+	assert.NoError(t, sqlMock.ExpectationsWereMet())
+}
+
+func TestGetAPIUsage_RejectsRangeOverWindow(t *testing.T) {
+	db, sqlMock, err := sqlmock.New()
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	handler := NewAnalyticsHandler(nil, nil, nil, nil, nil, nil, nil, db)
+	app := fiber.New()
+	app.Get("/analytics/api-usage", handler.GetAPIUsage, func(c fiber.Ctx) error {
+		c.Locals("organization_id", uuid.New())
+		return c.Next()
+	})
+
+	end := time.Now().UTC()
+	start := end.Add(-maxAPIUsageWindow - time.Hour)
+	url := "/analytics/api-usage?period=custom&start_time=" + start.Format(time.RFC3339) + "&end_time=" + end.Format(time.RFC3339)
+
+	resp, err := app.Test(httptest.NewRequest("GET", url, nil))
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+	assert.NoError(t, sqlMock.ExpectationsWereMet(), "no usage is queried for an oversized range")
+}
+
+func TestGetAPIUsage_RejectsUnknownPeriod(t *testing.T) {
+	handler := NewAnalyticsHandler(nil, nil, nil, nil, nil, nil, nil, nil)
+	app := fiber.New()
+	app.Get("/analytics/api-usage", handler.GetAPIUsage, func(c fiber.Ctx) error {
+		c.Locals("organization_id", uuid.New())
+		return c.Next()
+	})
+
+	resp, err := app.Test(httptest.NewRequest("GET", "/analytics/api-usage?period=fortnight", nil))
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+}
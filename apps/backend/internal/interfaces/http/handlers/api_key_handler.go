@@ -1,6 +1,9 @@
 package handlers
 
 import (
+	"errors"
+	"time"
+
 	"github.com/gofiber/fiber/v3"
 	"github.com/google/uuid"
 	"github.com/opena2a/identity/backend/internal/application"
@@ -82,9 +85,10 @@ func (h *APIKeyHandler) CreateAPIKey(c fiber.Ctx) error {
 	userID := c.Locals("user_id").(uuid.UUID)
 
 	var req struct {
-		AgentID   string  `json:"agent_id"`
-		Name      string  `json:"name"`
-		ExpiresAt *string `json:"expires_at"`
+		AgentID   string   `json:"agent_id"`
+		Name      string   `json:"name"`
+		ExpiresAt *string  `json:"expires_at"`
+		Scopes    []string `json:"scopes"` // e.g. ["agents:read"]; omit for a read-only key
 	}
 
 	if err := c.Bind().JSON(&req); err != nil {
@@ -106,11 +110,16 @@ func (h *APIKeyHandler) CreateAPIKey(c fiber.Ctx) error {
 		})
 	}
 
-	// Parse expiration days (default to 90 days if not provided)
-	expiresInDays := 90
+	// Parse expiration (default to 90 days if not provided)
+	expiresAt := time.Now().AddDate(0, 0, 90)
 	if req.ExpiresAt != nil {
-		// TODO: Parse expires_at timestamp and convert to days
-		// For now, using default
+		parsed, err := time.Parse(time.RFC3339, *req.ExpiresAt)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "expires_at must be an RFC3339 timestamp",
+			})
+		}
+		expiresAt = parsed
 	}
 
 	plainKey, apiKey, err := h.apiKeyService.GenerateAPIKey(
@@ -119,8 +128,20 @@ func (h *APIKeyHandler) CreateAPIKey(c fiber.Ctx) error {
 		orgID,
 		userID,
 		req.Name,
-		expiresInDays,
+		&expiresAt,
+		req.Scopes,
 	)
+	if errors.Is(err, application.ErrAPIKeyExpiryInPast) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	if errors.Is(err, application.ErrInvalidAPIKeyScope) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":       err.Error(),
+			"validScopes": domain.KnownAPIKeyScopes,
+		})
+	}
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": err.Error(),
@@ -140,6 +161,7 @@ func (h *APIKeyHandler) CreateAPIKey(c fiber.Ctx) error {
 		map[string]interface{}{
 			"keyName": req.Name,
 			"agentId": agentID.String(),
+			"scopes":  apiKey.Scopes,
 		},
 	)
 
@@ -149,6 +171,7 @@ func (h *APIKeyHandler) CreateAPIKey(c fiber.Ctx) error {
 		"name":      apiKey.Name,
 		"agentId":   apiKey.AgentID,
 		"expiresAt": apiKey.ExpiresAt,
+		"scopes":    apiKey.Scopes,
 		"createdAt": apiKey.CreatedAt,
 	})
 }
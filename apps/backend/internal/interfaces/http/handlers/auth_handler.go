@@ -1,6 +1,12 @@
 package handlers
 
 import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
 	"github.com/gofiber/fiber/v3"
 	"github.com/google/uuid"
 	"github.com/opena2a/identity/backend/internal/application"
@@ -12,17 +18,23 @@ type AuthHandler struct {
 	authService  *application.AuthService
 	jwtService   *auth.JWTService
 	orgRepo      domain.OrganizationRepository
+	loginLockout *application.LoginLockout
+	twoFactor    *application.TwoFactorService
 }
 
 func NewAuthHandler(
 	authService *application.AuthService,
 	jwtService *auth.JWTService,
 	orgRepo domain.OrganizationRepository,
+	loginLockout *application.LoginLockout,
+	twoFactor *application.TwoFactorService,
 ) *AuthHandler {
 	return &AuthHandler{
 		authService:  authService,
 		jwtService:   jwtService,
 		orgRepo:      orgRepo,
+		loginLockout: loginLockout,
+		twoFactor:    twoFactor,
 	}
 }
 
@@ -67,6 +79,7 @@ func (h *AuthHandler) LocalLogin(c fiber.Ctx) error {
 	type LoginRequest struct {
 		Email    string `json:"email"`
 		Password string `json:"password"`
+		TOTPCode string `json:"totpCode"` // Required once the user has two-factor enabled
 	}
 
 	var req LoginRequest
@@ -83,13 +96,26 @@ func (h *AuthHandler) LocalLogin(c fiber.Ctx) error {
 		})
 	}
 
+	// Reject logins while the account or client is locked out after repeated failures
+	email := strings.ToLower(strings.TrimSpace(req.Email))
+	if retryAfter := h.loginLockout.RetryAfter(c.Context(), email, c.IP(), time.Now()); retryAfter > 0 {
+		return loginLockedResponse(c, retryAfter)
+	}
+
 	// Authenticate user (this also updates last_login_at)
 	user, err := h.authService.LoginWithPassword(c.Context(), req.Email, req.Password)
 	if err != nil {
+		if retryAfter := h.loginLockout.RecordFailure(c.Context(), email, c.IP(), time.Now()); retryAfter > 0 {
+			return loginLockedResponse(c, retryAfter)
+		}
 		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
 			"error": "Invalid email or password",
 		})
 	}
+	if handled, err := checkTwoFactorLogin(c, h.twoFactor, h.loginLockout, user.ID, email, req.TOTPCode); handled {
+		return err
+	}
+	h.loginLockout.RecordSuccess(c.Context(), email)
 
 	// Generate JWT tokens
 	accessToken, refreshToken, err := h.jwtService.GenerateTokenPair(
@@ -214,6 +240,7 @@ func (h *AuthHandler) GetCurrentOrganization(c fiber.Ctx) error {
 		"id":         org.ID,
 		"name":       org.Name,
 		"max_agents": org.MaxAgents,
+		"max_users":  org.MaxUsers,
 		"isActive":  org.IsActive,
 		"createdAt": org.CreatedAt,
 		"updatedAt": org.UpdatedAt,
@@ -241,3 +268,21 @@ func (h *AuthHandler) Logout(c fiber.Ctx) error {
 		"message": "Logged out successfully",
 	})
 }
+
+// GetJWKS returns the public keys of the active JWT signing keys (RFC 7517), so relying
+// parties can verify access tokens without the signing secret
+func (h *AuthHandler) GetJWKS(c fiber.Ctx) error {
+	c.Set(fiber.HeaderCacheControl, "public, max-age=300")
+	return c.JSON(h.jwtService.JWKS())
+}
+
+// loginLockedResponse rejects a login with 429 and a Retry-After header while it is locked out
+func loginLockedResponse(c fiber.Ctx, retryAfter time.Duration) error {
+	seconds := int(math.Ceil(retryAfter.Seconds()))
+	c.Set(fiber.HeaderRetryAfter, strconv.Itoa(seconds))
+	return c.Status(fiber.StatusTooManyRequests).JSON(fiber.Map{
+		"success":    false,
+		"error":      fmt.Sprintf("Too many failed login attempts. Try again in %d minute(s).", int(math.Ceil(retryAfter.Minutes()))),
+		"retryAfter": seconds,
+	})
+}
@@ -3,6 +3,7 @@ package handlers
 import (
 	"crypto/sha256"
 	"encoding/hex"
+	"errors"
 	"time"
 
 	"github.com/gofiber/fiber/v3"
@@ -14,15 +15,17 @@ import (
 
 // AuthRefreshHandler handles token refresh operations
 type AuthRefreshHandler struct {
-	jwtService      *auth.JWTService
-	sdkTokenService *application.SDKTokenService
+	jwtService          *auth.JWTService
+	sdkTokenService     *application.SDKTokenService
+	refreshTokenService *application.RefreshTokenService
 }
 
 // NewAuthRefreshHandler creates a new auth refresh handler
-func NewAuthRefreshHandler(jwtService *auth.JWTService, sdkTokenService *application.SDKTokenService) *AuthRefreshHandler {
+func NewAuthRefreshHandler(jwtService *auth.JWTService, sdkTokenService *application.SDKTokenService, refreshTokenService *application.RefreshTokenService) *AuthRefreshHandler {
 	return &AuthRefreshHandler{
-		jwtService:      jwtService,
-		sdkTokenService: sdkTokenService,
+		jwtService:          jwtService,
+		sdkTokenService:     sdkTokenService,
+		refreshTokenService: refreshTokenService,
 	}
 }
 
@@ -78,10 +81,22 @@ func (h *AuthRefreshHandler) RefreshToken(c fiber.Ctx) error {
 		})
 	}
 
+	// Record the rotation in the token's lineage; replaying a rotated token revokes its whole family
+	if err := h.recordRotation(c, req.RefreshToken, newRefreshToken); err != nil {
+		if errors.Is(err, application.ErrRefreshTokenReused) || errors.Is(err, application.ErrRefreshTokenRevoked) {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+				"error": "Refresh token has been revoked, please log in again",
+				"code":  "refresh_token_revoked",
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to refresh token",
+		})
+	}
+
 	// If this is a tracked SDK token, track usage and create new token entry
-	// NOTE: We do NOT revoke old tokens on rotation - this allows multiple SDK instances
-	// to work independently (like GitHub, Google, etc. handle device sessions)
-	// Old tokens expire naturally after 90 days
+	// NOTE: The sdk_tokens row of the old token is not revoked - each SDK instance holds its own
+	// token family, and replaying a rotated token is already rejected by the lineage check above
 	if tokenID != "" {
 		hasher := sha256.New()
 		hasher.Write([]byte(req.RefreshToken))
@@ -90,20 +105,27 @@ func (h *AuthRefreshHandler) RefreshToken(c fiber.Ctx) error {
 		// Get old token info for creating new token entry
 		oldToken, _ := h.sdkTokenService.ValidateToken(c.Context(), oldTokenHash)
 
-		// Record usage on the old token (updates last_used_at, usage_count)
-		ipAddress := c.IP()
-		_ = h.sdkTokenService.RecordTokenUsage(c.Context(), tokenID, ipAddress)
+		// Record usage on the old token (updates last_used_at, last_ip_address, last_user_agent, usage_count)
+		if oldToken != nil {
+			_ = h.sdkTokenService.RecordTokenUsage(c.Context(), domain.SDKTokenUsage{
+				TokenID:        tokenID,
+				OrganizationID: oldToken.OrganizationID,
+				UserID:         &oldToken.UserID,
+				IPAddress:      c.IP(),
+				UserAgent:      c.Get("User-Agent"),
+				UsedAt:         time.Now(),
+				Requests:       1,
+			})
+		}
 
-		// IMPORTANT: We do NOT revoke the old token anymore!
-		// This was causing issues with multiple SDK instances:
+		// Multiple SDK instances work independently because each download starts its own family:
 		// - SDK A downloads → Token A
 		// - SDK B downloads → Token B
-		// - SDK A refreshes → Old behavior: Token A revoked, Token A' created
+		// - SDK A refreshes → Token A rotated, Token A' created
 		// - SDK B refreshes → Token B is still valid, Token B' created
 		// - Now both A' and B' work independently!
 		//
-		// Old tokens will expire naturally after 90 days.
-		// For security, we still track token lineage via metadata.
+		// Presenting Token A again after its rotation revokes A' as well (reuse detection).
 
 		// Save the new rotated SDK token to database
 		if oldToken != nil {
@@ -163,6 +185,40 @@ func (h *AuthRefreshHandler) RefreshToken(c fiber.Ctx) error {
 	})
 }
 
+// recordRotation tracks that oldRefreshToken was exchanged for newRefreshToken
+func (h *AuthRefreshHandler) recordRotation(c fiber.Ctx, oldRefreshToken, newRefreshToken string) error {
+	if h.refreshTokenService == nil {
+		return nil
+	}
+
+	oldClaims, err := h.jwtService.ValidateToken(oldRefreshToken)
+	if err != nil {
+		return err
+	}
+	newClaims, err := h.jwtService.ValidateToken(newRefreshToken)
+	if err != nil {
+		return err
+	}
+	userID, err := uuid.Parse(oldClaims.UserID)
+	if err != nil {
+		return err
+	}
+	orgID, err := uuid.Parse(oldClaims.OrganizationID)
+	if err != nil {
+		return err
+	}
+
+	return h.refreshTokenService.Rotate(c.Context(), application.RefreshTokenRotation{
+		UserID:         userID,
+		OrganizationID: orgID,
+		TokenID:        oldClaims.ID,
+		ExpiresAt:      oldClaims.ExpiresAt.Time,
+		NewTokenID:     newClaims.ID,
+		NewExpiresAt:   newClaims.ExpiresAt.Time,
+		IPAddress:      c.IP(),
+	}, time.Now())
+}
+
 // Request/Response types
 type RefreshTokenRequest struct {
 	RefreshToken string `json:"refresh_token" validate:"required"`
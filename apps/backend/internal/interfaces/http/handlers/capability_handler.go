@@ -3,7 +3,9 @@ package handlers
 import (
 	"context"
 	"encoding/base64"
+	"errors"
 	"strconv"
+	"time"
 
 	"github.com/gofiber/fiber/v3"
 	"github.com/google/uuid"
@@ -25,7 +27,7 @@ func NewCapabilityHandler(capabilityService *application.CapabilityService) *Cap
 
 // GrantCapability godoc
 // @Summary Grant a capability to an agent
-// @Description Add a new capability to an agent's registered capabilities
+// @Description Add a new capability to an agent's registered capabilities, optionally for a limited duration
 // @Tags capabilities
 // @Accept json
 // @Produce json
@@ -55,6 +57,16 @@ func (h *CapabilityHandler) GrantCapability(c fiber.Ctx) error {
 
 	println("DEBUG: GrantCapability - AgentID:", agentID.String(), "CapabilityType:", req.CapabilityType)
 
+	var duration time.Duration
+	if req.Duration != "" {
+		duration, err = time.ParseDuration(req.Duration)
+		if err != nil || duration <= 0 {
+			return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{
+				Error: "duration must be a positive duration such as \"72h\"",
+			})
+		}
+	}
+
 	// Get user ID from JWT claims
 	userID, err := h.getUserIDFromContext(c)
 	if err != nil {
@@ -79,9 +91,15 @@ func (h *CapabilityHandler) GrantCapability(c fiber.Ctx) error {
 		req.CapabilityType,
 		req.Scope,
 		userIDPtr,
+		duration,
 	)
 	if err != nil {
 		println("ERROR: GrantCapability service failed:", err.Error())
+		if errors.Is(err, application.ErrCapabilityNotInCatalog) {
+			return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{
+				Error: err.Error(),
+			})
+		}
 		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
 			Error: err.Error(),
 		})
@@ -288,6 +306,65 @@ func (h *CapabilityHandler) GetViolationsByAgent(c fiber.Ctx) error {
 	})
 }
 
+// GetViolationStats godoc
+// @Summary Get violation statistics for an agent
+// @Description Daily capability violation counts by severity and the most attempted unauthorized capabilities
+// @Tags capabilities
+// @Produce json
+// @Param id path string true "Agent ID"
+// @Param days query int false "Number of days, up to 90" default(30)
+// @Success 200 {object} domain.ViolationStats
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /agents/{id}/violations/stats [get]
+func (h *CapabilityHandler) GetViolationStats(c fiber.Ctx) error {
+	agentID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{
+			Error: "Invalid agent ID",
+		})
+	}
+
+	orgID, ok := c.Locals("organization_id").(uuid.UUID)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(ErrorResponse{
+			Error: "Organization not found in context",
+		})
+	}
+
+	days := application.DefaultViolationStatsDays
+	if daysStr := c.Query("days"); daysStr != "" {
+		parsedDays, err := strconv.Atoi(daysStr)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{
+				Error: "Invalid days",
+			})
+		}
+		days = parsedDays
+	}
+
+	stats, err := h.capabilityService.GetViolationStats(c.Context(), orgID, agentID, days, time.Now())
+	if err != nil {
+		switch {
+		case errors.Is(err, application.ErrInvalidViolationStatsDays):
+			return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{
+				Error: err.Error(),
+			})
+		case errors.Is(err, application.ErrViolationStatsAgentAccess):
+			return c.Status(fiber.StatusNotFound).JSON(ErrorResponse{
+				Error: "Agent not found",
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
+			Error: "Failed to get violation statistics",
+		})
+	}
+
+	return c.JSON(stats)
+}
+
 // GetViolationsByOrganization godoc
 // @Summary Get violations for an organization
 // @Description Retrieve all capability violations for an organization
@@ -345,7 +422,7 @@ func (h *CapabilityHandler) GetViolationsByOrganization(c fiber.Ctx) error {
 
 // ListCapabilities godoc
 // @Summary List all available capabilities
-// @Description Get all capability types available in the system
+// @Description Get the capability types allowed by the organization's capability catalog
 // @Tags capabilities
 // @Produce json
 // @Success 200 {array} application.CapabilityDefinition
@@ -453,6 +530,7 @@ func (h *CapabilityHandler) getUserIDFromContext(c fiber.Ctx) (uuid.UUID, error)
 type GrantCapabilityRequest struct {
 	CapabilityType string                 `json:"capabilityType" validate:"required"`
 	Scope          map[string]interface{} `json:"scope,omitempty"`
+	Duration       string                 `json:"duration,omitempty"` // Go duration (e.g. "72h"); empty grants until revoked
 }
 
 type VerifyActionRequest struct {
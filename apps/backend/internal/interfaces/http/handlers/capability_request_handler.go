@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"errors"
 	"strconv"
 
 	"github.com/gofiber/fiber/v3"
@@ -91,6 +92,11 @@ func (h *CapabilityRequestHandlers) CreateCapabilityRequest(c fiber.Ctx) error {
 	if err != nil {
 		// Check for specific error types
 		errMsg := err.Error()
+		if errors.Is(err, application.ErrCapabilityNotInCatalog) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": errMsg,
+			})
+		}
 		if errMsg == "agent not found" {
 			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
 				"error": "agent not found",
@@ -222,17 +228,20 @@ func (h *CapabilityRequestHandlers) GetCapabilityRequest(c fiber.Ctx) error {
 
 // ApproveCapabilityRequest godoc
 // @Summary Approve a capability request (Admin only)
-// @Description Approve a pending capability request and grant the capability
+// @Description Approve a pending capability request and grant the capability, optionally scoped and time-limited
 // @Tags capability-requests
 // @Accept json
 // @Produce json
 // @Security Bearer
 // @Param id path string true "Capability Request ID"
+// @Param body body domain.CapabilityGrantOptions false "Optional resource scope and expiry for the grant"
 // @Success 200 {object} map[string]interface{}
 // @Failure 400 {object} ErrorResponse
 // @Failure 401 {object} ErrorResponse
 // @Failure 403 {object} ErrorResponse
 // @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 410 {object} ErrorResponse
 // @Router /api/v1/admin/capability-requests/{id}/approve [post]
 func (h *CapabilityRequestHandlers) ApproveCapabilityRequest(c fiber.Ctx) error {
 	// Get user ID from context (admin user)
@@ -250,11 +259,34 @@ func (h *CapabilityRequestHandlers) ApproveCapabilityRequest(c fiber.Ctx) error
 		})
 	}
 
-	if err := h.service.ApproveRequest(c.Context(), id, userID); err != nil {
-		if err.Error() == "capability request not found" {
+	// Optional body narrows the grant: {"granted_scope": "/tmp/*", "expires_at": "2025-01-01T00:00:00Z"}
+	var opts domain.CapabilityGrantOptions
+	if len(c.Body()) > 0 {
+		if err := c.Bind().JSON(&opts); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "invalid request body",
+			})
+		}
+	}
+
+	if err := h.service.ApproveRequest(c.Context(), id, userID, opts); err != nil {
+		switch {
+		case err.Error() == "capability request not found":
 			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
 				"error": "capability request not found",
 			})
+		case errors.Is(err, application.ErrInvalidCapabilityGrant):
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		case errors.Is(err, application.ErrCapabilityRequestNotPending):
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		case errors.Is(err, application.ErrCapabilityRequestExpired):
+			return c.Status(fiber.StatusGone).JSON(fiber.Map{
+				"error": err.Error(),
+			})
 		}
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": err.Error(),
@@ -279,6 +311,7 @@ func (h *CapabilityRequestHandlers) ApproveCapabilityRequest(c fiber.Ctx) error
 // @Failure 401 {object} ErrorResponse
 // @Failure 403 {object} ErrorResponse
 // @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
 // @Router /api/v1/admin/capability-requests/{id}/reject [post]
 func (h *CapabilityRequestHandlers) RejectCapabilityRequest(c fiber.Ctx) error {
 	// Get user ID from context (admin user)
@@ -302,6 +335,11 @@ func (h *CapabilityRequestHandlers) RejectCapabilityRequest(c fiber.Ctx) error {
 				"error": "capability request not found",
 			})
 		}
+		if errors.Is(err, application.ErrCapabilityRequestNotPending) {
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": err.Error(),
 		})
@@ -1,6 +1,11 @@
 package handlers
 
 import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"log/slog"
+	"strconv"
 	"time"
 
 	"github.com/gofiber/fiber/v3"
@@ -210,12 +215,94 @@ func (h *ComplianceHandler) RunComplianceCheck(c fiber.Ctx) error {
 	return c.JSON(results)
 }
 
-// ExportComplianceReport exports compliance report in specified format
-// @Summary Export compliance report
-// @Description Export comprehensive compliance report in CSV or JSON format
+// ExportAuditLog streams the organization's audit logs for auditors
+// @Summary Export audit log
+// @Description Stream audit logs in CSV (RFC 4180) or JSON format, including metadata
 // @Tags compliance
 // @Produce text/csv,application/json
 // @Param format query string false "Export format (csv or json)" default(csv)
+// @Param start_date query string false "Only include logs at or after this time (RFC3339)"
+// @Param end_date query string false "Only include logs before this time (RFC3339)"
+// @Success 200 {file} file
+// @Failure 400 {object} map[string]interface{}
+// @Router /api/v1/compliance/audit-log/export [get]
+func (h *ComplianceHandler) ExportAuditLog(c fiber.Ctx) error {
+	orgID := c.Locals("organization_id").(uuid.UUID)
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	format := c.Query("format", "csv")
+	if format != "csv" && format != "json" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid format. Supported formats: csv, json",
+		})
+	}
+
+	// Parse date range (optional)
+	var startDate, endDate time.Time
+	if startDateStr := c.Query("start_date", c.Query("start")); startDateStr != "" {
+		parsed, err := time.Parse(time.RFC3339, startDateStr)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Invalid start_date. Use RFC3339 format",
+			})
+		}
+		startDate = parsed
+	}
+	if endDateStr := c.Query("end_date", c.Query("end")); endDateStr != "" {
+		parsed, err := time.Parse(time.RFC3339, endDateStr)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Invalid end_date. Use RFC3339 format",
+			})
+		}
+		endDate = parsed
+	}
+
+	// Log audit
+	h.auditService.LogAction(
+		c.Context(),
+		orgID,
+		userID,
+		domain.AuditActionView,
+		"audit_log_export",
+		orgID,
+		c.IP(),
+		c.Get("User-Agent"),
+		map[string]interface{}{
+			"format":    format,
+			"startDate": startDate,
+			"endDate":   endDate,
+		},
+	)
+
+	filename := fmt.Sprintf("audit-log-%s.%s", time.Now().UTC().Format("20060102-150405"), format)
+	if format == "json" {
+		c.Set("Content-Type", "application/json")
+	} else {
+		c.Set("Content-Type", "text/csv")
+	}
+	c.Set("Content-Disposition", "attachment; filename="+filename)
+
+	// Stream the export. The fiber.Ctx is recycled once the handler returns, but the
+	// underlying request context lives until the body is written and is cancelled on shutdown.
+	complianceService := h.complianceService
+	requestCtx := c.Context()
+	c.Response().SetBodyStreamWriter(func(w *bufio.Writer) {
+		if _, err := complianceService.ExportAuditLog(requestCtx, w, orgID, startDate, endDate, format); err != nil {
+			slog.Warn("audit log export failed", "org_id", orgID, "error", err)
+		}
+		w.Flush()
+	})
+
+	return nil
+}
+
+// ExportComplianceReport exports compliance report in specified format
+// @Summary Export compliance report
+// @Description Export comprehensive compliance report in CSV, JSON or PDF format
+// @Tags compliance
+// @Produce text/csv,application/json,application/pdf
+// @Param format query string false "Export format (csv, json or pdf)" default(csv)
 // @Param start_date query string false "Start date for report (RFC3339)"
 // @Param end_date query string false "End date for report (RFC3339)"
 // @Success 200 {file} file
@@ -226,9 +313,9 @@ func (h *ComplianceHandler) ExportComplianceReport(c fiber.Ctx) error {
 	userID := c.Locals("user_id").(uuid.UUID)
 
 	format := c.Query("format", "csv")
-	if format != "csv" && format != "json" {
+	if format != "csv" && format != "json" && format != "pdf" {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid format. Supported formats: csv, json",
+			"error": "Invalid format. Supported formats: csv, json, pdf",
 		})
 	}
 
@@ -280,6 +367,10 @@ func (h *ComplianceHandler) ExportComplianceReport(c fiber.Ctx) error {
 		},
 	)
 
+	if format == "pdf" {
+		return h.exportComplianceReportPDF(c, orgID, startDate, endDate)
+	}
+
 	if format == "json" {
 		c.Set("Content-Type", "application/json")
 		c.Set("Content-Disposition", "attachment; filename=compliance-report.json")
@@ -298,3 +389,79 @@ func (h *ComplianceHandler) ExportComplianceReport(c fiber.Ctx) error {
 	// Simple CSV export - just return status and metrics as JSON representation
 	return c.SendString("Compliance Report Export\nPlease use JSON format for full report details.")
 }
+
+// GetComplianceHistory returns the compliance score timeline recorded by scheduled compliance checks
+// @Summary Get compliance score history
+// @Description Compliance score timeline for a framework, built from scheduled compliance check runs
+// @Tags compliance
+// @Produce json
+// @Param framework query string true "Framework (soc2, iso27001, hipaa or gdpr)"
+// @Param days query int false "Number of days of history" default(90)
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Router /api/v1/compliance/history [get]
+func (h *ComplianceHandler) GetComplianceHistory(c fiber.Ctx) error {
+	orgID := c.Locals("organization_id").(uuid.UUID)
+
+	framework := c.Query("framework")
+	if !application.IsComplianceFramework(framework) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid framework. Supported frameworks: soc2, iso27001, hipaa, gdpr",
+		})
+	}
+
+	days, err := strconv.Atoi(c.Query("days", "90"))
+	if err != nil || days < 1 || days > 365 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "days must be between 1 and 365",
+		})
+	}
+
+	history, err := h.complianceService.GetComplianceHistory(c.Context(), orgID, framework, time.Now().AddDate(0, 0, -days))
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to fetch compliance history",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"framework": framework,
+		"days":      days,
+		"history":   history,
+	})
+}
+
+// exportComplianceReportPDF renders the full compliance report (summary, agents, recommendations) as a PDF download
+func (h *ComplianceHandler) exportComplianceReportPDF(c fiber.Ctx, orgID uuid.UUID, startDate, endDate time.Time) error {
+	// Default to last 30 days if not specified
+	if endDate.IsZero() {
+		endDate = time.Now()
+	}
+	if startDate.IsZero() {
+		startDate = endDate.AddDate(0, 0, -30)
+	}
+
+	result, err := h.complianceService.GenerateComplianceReport(c.Context(), orgID, "full", startDate, endDate)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to generate compliance report",
+		})
+	}
+	report, ok := result.(*application.ComplianceReport)
+	if !ok {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to generate compliance report",
+		})
+	}
+
+	var buf bytes.Buffer
+	if err := application.WriteComplianceReportPDF(&buf, report, report.GeneratedAt); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to render compliance report",
+		})
+	}
+
+	c.Set("Content-Type", "application/pdf")
+	c.Set("Content-Disposition", fmt.Sprintf("attachment; filename=compliance-report-%s.pdf", report.GeneratedAt.Format("2006-01-02")))
+	return c.Send(buf.Bytes())
+}
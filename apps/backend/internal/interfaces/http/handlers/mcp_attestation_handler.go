@@ -1,12 +1,14 @@
 package handlers
 
 import (
-	"fmt"
+	"errors"
+	"strings"
 
 	"github.com/gofiber/fiber/v3"
 	"github.com/google/uuid"
 	"github.com/opena2a/identity/backend/internal/application"
 	"github.com/opena2a/identity/backend/internal/domain"
+	"github.com/opena2a/identity/backend/internal/infrastructure/logging"
 )
 
 type MCPAttestationHandler struct {
@@ -60,14 +62,22 @@ func (h *MCPAttestationHandler) AttestMCP(c fiber.Ctx) error {
 	response, err := h.attestationService.VerifyAndRecordAttestation(c.Context(), mcpServerID, &req)
 	if err != nil {
 		// Log the actual error for debugging
-		fmt.Printf("❌ Attestation failed for MCP %s: %v\n", mcpServerID, err)
+		logging.FromContext(c.Context()).Warn("attestation failed", "mcp_server_id", mcpServerID, "error", err)
 
 		// Determine status code based on error
 		statusCode := fiber.StatusInternalServerError
-		if err.Error() == "only verified agents can attest MCPs" ||
-			err.Error() == "invalid attestation signature" ||
-			err.Error() == "attestation expired (older than 5 minutes)" {
+		switch {
+		case errors.Is(err, application.ErrAttestationInvalidSignature),
+			errors.Is(err, application.ErrAttestationExpired),
+			errors.Is(err, application.ErrAttestationFromFuture),
+			errors.Is(err, application.ErrAttestationMCPMismatch),
+			errors.Is(err, application.ErrAttestationNoPublicKey),
+			strings.HasPrefix(err.Error(), "only verified agents can attest MCPs"):
 			statusCode = fiber.StatusForbidden
+		case errors.Is(err, application.ErrAttestationReplayed):
+			statusCode = fiber.StatusConflict
+		case errors.Is(err, application.ErrAttestationInvalidTimestamp):
+			statusCode = fiber.StatusBadRequest
 		}
 
 		return c.Status(statusCode).JSON(fiber.Map{
@@ -286,7 +296,7 @@ func (h *MCPAttestationHandler) ManualAttestMCP(c fiber.Ctx) error {
 		req.Notes,
 	)
 	if err != nil {
-		fmt.Printf("❌ Manual attestation failed for MCP %s: %v\n", mcpServerID, err)
+		logging.FromContext(c.Context()).Warn("manual attestation failed", "mcp_server_id", mcpServerID, "error", err)
 
 		statusCode := fiber.StatusInternalServerError
 		if err.Error() == "mcp server not found" {
@@ -322,6 +332,77 @@ func (h *MCPAttestationHandler) ManualAttestMCP(c fiber.Ctx) error {
 	return c.Status(fiber.StatusOK).JSON(response)
 }
 
+// RevokeAttestation invalidates an attestation of an MCP server
+// @Summary Revoke MCP attestation
+// @Description Invalidate an attestation so it no longer counts toward the MCP server's confidence and trust scores
+// @Tags mcp-servers
+// @Produce json
+// @Param id path string true "MCP Server ID"
+// @Param attestationId path string true "Attestation ID"
+// @Success 200 {object} application.AttestMCPResponse
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /api/v1/mcp-servers/{id}/attestations/{attestationId} [delete]
+func (h *MCPAttestationHandler) RevokeAttestation(c fiber.Ctx) error {
+	userID, ok := c.Locals("user_id").(uuid.UUID)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "User not authenticated",
+		})
+	}
+	orgID, ok := c.Locals("organization_id").(uuid.UUID)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Organization not found",
+		})
+	}
+
+	mcpServerID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid MCP server ID",
+		})
+	}
+	attestationID, err := uuid.Parse(c.Params("attestationId"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid attestation ID",
+		})
+	}
+
+	response, err := h.attestationService.RevokeAttestation(c.Context(), orgID, mcpServerID, attestationID)
+	if errors.Is(err, application.ErrAttestationNotFound) {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Attestation not found",
+		})
+	}
+	if err != nil {
+		logging.FromContext(c.Context()).Error("failed to revoke attestation", "attestation_id", attestationID, "error", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to revoke attestation",
+		})
+	}
+
+	h.auditService.LogAction(
+		c.Context(),
+		orgID,
+		userID,
+		domain.AuditActionRevoke,
+		"mcp_server",
+		mcpServerID,
+		c.IP(),
+		c.Get("User-Agent"),
+		fiber.Map{
+			"attestation_id":    attestationID,
+			"confidence_score":  response.MCPConfidenceScore,
+			"attestation_count": response.AttestationCount,
+		},
+	)
+
+	return c.JSON(response)
+}
+
 // RecordMCPConnection handles agent recording MCP tool usage
 // @Summary Record MCP connection
 // @Description Record that an agent is using an MCP server tool (creates/updates agent-MCP connection)
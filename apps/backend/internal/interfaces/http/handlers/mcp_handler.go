@@ -1,13 +1,13 @@
 package handlers
 
 import (
-	"fmt"
 	"strconv"
 
 	"github.com/gofiber/fiber/v3"
 	"github.com/google/uuid"
 	"github.com/opena2a/identity/backend/internal/application"
 	"github.com/opena2a/identity/backend/internal/domain"
+	"github.com/opena2a/identity/backend/internal/infrastructure/logging"
 	"github.com/opena2a/identity/backend/internal/infrastructure/repository"
 )
 
@@ -86,7 +86,7 @@ func (h *MCPHandler) CreateMCPServer(c fiber.Ctx) error {
 	server, err := h.mcpService.CreateMCPServer(c.Context(), &req, orgID, userID, agentID)
 	if err != nil {
 		// Log the actual error for debugging
-		fmt.Printf("❌ Error creating MCP server: %v\n", err)
+		logging.FromContext(c.Context()).Error("failed to create MCP server", "org_id", orgID, "error", err)
 
 		// Return 409 Conflict for duplicate URL errors
 		if err.Error() == "mcp server with this URL already exists" {
@@ -491,6 +491,67 @@ func (h *MCPHandler) GetVerificationStatus(c fiber.Ctx) error {
 	return c.JSON(status)
 }
 
+// GetMCPServerHealth returns the last recorded health of an MCP server
+// @Summary Get MCP server health
+// @Description Get the last health check result of an MCP server. Managers and admins can pass refresh=true to probe the server now.
+// @Tags mcp-servers
+// @Produce json
+// @Param id path string true "MCP Server ID"
+// @Param refresh query bool false "Probe the server before responding"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /api/v1/mcp-servers/{id}/health [get]
+func (h *MCPHandler) GetMCPServerHealth(c fiber.Ctx) error {
+	orgID := c.Locals("organization_id").(uuid.UUID)
+	serverID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid MCP server ID",
+		})
+	}
+
+	// Probing makes the backend send a request, so it is limited to managers and admins
+	refresh := c.Query("refresh") == "true"
+	if refresh {
+		if role, _ := c.Locals("role").(string); role != string(domain.RoleAdmin) && role != string(domain.RoleManager) {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+				"error": "Manager or admin access required to refresh health",
+			})
+		}
+	}
+
+	// Verify server belongs to organization first
+	server, err := h.mcpService.GetMCPServer(c.Context(), serverID)
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "MCP server not found",
+		})
+	}
+	if server.OrganizationID != orgID {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error": "Access denied",
+		})
+	}
+
+	if refresh {
+		result, err := h.mcpService.CheckHealth(c.Context(), serverID)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Failed to check MCP server health",
+			})
+		}
+		return c.JSON(result)
+	}
+
+	return c.JSON(fiber.Map{
+		"serverId":        server.ID,
+		"status":          server.HealthStatus,
+		"lastHealthCheck": server.LastHealthCheck,
+	})
+}
+
 // GetMCPServerCapabilities retrieves all capabilities for an MCP server
 // @Summary Get MCP server capabilities
 // @Description Get all detected capabilities for an MCP server (tools, resources, prompts)
@@ -0,0 +1,31 @@
+package handlers
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMCPHandler_GetMCPServerHealth_RefreshRequiresManager(t *testing.T) {
+	for _, role := range []domain.UserRole{domain.RoleViewer, domain.RoleMember} {
+		t.Run(string(role), func(t *testing.T) {
+			// The role check runs before the service is used, so none is needed
+			handler := &MCPHandler{}
+			app := fiber.New()
+			app.Get("/mcp-servers/:id/health", handler.GetMCPServerHealth, func(c fiber.Ctx) error {
+				c.Locals("organization_id", uuid.New())
+				c.Locals("role", string(role))
+				return c.Next()
+			})
+
+			resp, err := app.Test(httptest.NewRequest("GET", "/mcp-servers/"+uuid.NewString()+"/health?refresh=true", nil))
+			require.NoError(t, err)
+			assert.Equal(t, fiber.StatusForbidden, resp.StatusCode)
+		})
+	}
+}
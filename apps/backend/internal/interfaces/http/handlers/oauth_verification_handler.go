@@ -0,0 +1,105 @@
+package handlers
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/application"
+	"github.com/opena2a/identity/backend/internal/infrastructure/logging"
+)
+
+// OAuthVerificationHandler handles verification of agents presenting OAuth bearer tokens
+type OAuthVerificationHandler struct {
+	agentService       *application.AgentService
+	oauthVerifyService *application.OAuthVerificationService
+}
+
+// NewOAuthVerificationHandler creates a new OAuth verification handler
+func NewOAuthVerificationHandler(
+	agentService *application.AgentService,
+	oauthVerifyService *application.OAuthVerificationService,
+) *OAuthVerificationHandler {
+	return &OAuthVerificationHandler{
+		agentService:       agentService,
+		oauthVerifyService: oauthVerifyService,
+	}
+}
+
+// OAuthVerificationRequest names the agent an OAuth access token is presented for
+type OAuthVerificationRequest struct {
+	AgentID     string `json:"agent_id" validate:"required"`
+	AccessToken string `json:"access_token" validate:"required"`
+}
+
+// VerifyOAuthToken handles POST /api/v1/verifications/oauth
+// @Summary Verify an agent's OAuth access token
+// @Description Introspect the OAuth access token an agent presents against the configured RFC 7662 introspection
+// @Description endpoint and record the result as an OAuth-protocol verification event. Inactive and expired tokens
+// @Description are answered with verified=false; the token itself is never stored.
+// @Tags verifications
+// @Accept json
+// @Produce json
+// @Param request body OAuthVerificationRequest true "Agent and access token"
+// @Success 200 {object} application.OAuthVerificationResult
+// @Failure 400 {object} ErrorResponse "Invalid request"
+// @Failure 404 {object} ErrorResponse "Agent not found"
+// @Failure 501 {object} ErrorResponse "Token introspection not configured"
+// @Failure 502 {object} ErrorResponse "Introspection endpoint unavailable"
+// @Router /api/v1/verifications/oauth [post]
+func (h *OAuthVerificationHandler) VerifyOAuthToken(c fiber.Ctx) error {
+	orgID, err := getOrganizationID(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Unauthorized",
+		})
+	}
+
+	var req OAuthVerificationRequest
+	if err := c.Bind().JSON(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+	if req.AgentID == "" || req.AccessToken == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "agent_id and access_token are required",
+		})
+	}
+
+	agentID, err := uuid.Parse(req.AgentID)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid agent_id format",
+		})
+	}
+
+	// Agents of other organizations are reported as missing
+	agent, err := h.agentService.GetAgent(c.Context(), agentID)
+	if err != nil || agent.OrganizationID != orgID {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Agent not found",
+		})
+	}
+
+	result, err := h.oauthVerifyService.VerifyAgentToken(c.Context(), agent, req.AccessToken, c.IP())
+	switch {
+	case errors.Is(err, application.ErrOAuthIntrospectionNotConfigured):
+		return c.Status(fiber.StatusNotImplemented).JSON(fiber.Map{
+			"error": "OAuth token introspection is not configured",
+		})
+	case errors.Is(err, application.ErrOAuthIntrospectionFailed):
+		logging.FromContext(c.Context()).Warn("OAuth token introspection failed", "agent_id", agentID, "error", err)
+		return c.Status(fiber.StatusBadGateway).JSON(fiber.Map{
+			"error":               "Token introspection failed",
+			"verificationEventId": result.VerificationEventID,
+		})
+	case err != nil:
+		logging.FromContext(c.Context()).Error("OAuth verification failed", "agent_id", agentID, "error", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to verify token",
+		})
+	}
+
+	return c.JSON(result)
+}
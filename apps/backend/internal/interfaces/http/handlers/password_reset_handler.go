@@ -11,6 +11,7 @@ import (
 	"github.com/gofiber/fiber/v3"
 	"github.com/google/uuid"
 	"github.com/opena2a/identity/backend/internal/domain"
+	"github.com/opena2a/identity/backend/internal/infrastructure/logging"
 	"golang.org/x/crypto/bcrypt"
 )
 
@@ -41,11 +42,13 @@ func (h *PasswordResetHandler) RequestPasswordReset(c fiber.Ctx) error {
 	// Check if user exists
 	var userID uuid.UUID
 	var userName string
+	var org domain.Organization // Brands the email
 	err := h.db.QueryRow(`
-		SELECT id, name
-		FROM users
-		WHERE email = $1 AND deleted_at IS NULL
-	`, req.Email).Scan(&userID, &userName)
+		SELECT u.id, u.name, o.name, o.logo_url, o.email_locale
+		FROM users u
+		JOIN organizations o ON o.id = u.organization_id
+		WHERE u.email = $1 AND u.deleted_at IS NULL
+	`, req.Email).Scan(&userID, &userName, &org.Name, &org.LogoURL, &org.EmailLocale)
 
 	if err == sql.ErrNoRows {
 		// For security, don't reveal if email exists
@@ -105,13 +108,18 @@ func (h *PasswordResetHandler) RequestPasswordReset(c fiber.Ctx) error {
 
 	// Send email using email service
 	if h.emailService != nil {
-		emailData := map[string]interface{}{
-			"UserName":    userName,
-			"UserEmail":   req.Email,
-			"ResetToken":  resetToken,
-			"ResetURL":    resetLink,
-			"DashboardURL": frontendURL,
+		emailData := domain.EmailTemplateData{
+			UserName:     userName,
+			UserEmail:    req.Email,
+			DashboardURL: frontendURL,
+			Timestamp:    time.Now(),
+			ExpiresAt:    expiresAt,
+			Locale:       c.Get(fiber.HeaderAcceptLanguage),
+			CustomData: map[string]interface{}{
+				"ResetLink": resetLink,
+			},
 		}
+		org.BrandEmail(&emailData)
 
 		if err := h.emailService.SendTemplatedEmail(
 			domain.TemplatePasswordReset,
@@ -119,19 +127,17 @@ func (h *PasswordResetHandler) RequestPasswordReset(c fiber.Ctx) error {
 			emailData,
 		); err != nil {
 			// Log error but don't fail - fallback to console
-			fmt.Printf("[WARN] Failed to send password reset email to %s: %v\n", req.Email, err)
-			fmt.Printf("Reset Link (console fallback): %s\n", resetLink)
+			logging.FromContext(c.Context()).Warn("failed to send password reset email, logging reset link instead",
+				"email", req.Email, "reset_link", resetLink, "error", err)
 		}
 	} else {
-		// Fallback: log to console
-		fmt.Printf("\n━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━\n")
-		fmt.Printf("📧 PASSWORD RESET EMAIL\n")
-		fmt.Printf("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━\n")
-		fmt.Printf("To: %s\n", req.Email)
-		fmt.Printf("User: %s\n", userName)
-		fmt.Printf("Reset Link: %s\n", resetLink)
-		fmt.Printf("Expires: %s (1 hour)\n", expiresAt.Format(time.RFC1123))
-		fmt.Printf("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━\n\n")
+		// Fallback: log the reset link so local deployments without SMTP can still reset
+		logging.FromContext(c.Context()).Info("password reset email (console fallback)",
+			"email", req.Email,
+			"user", userName,
+			"reset_link", resetLink,
+			"expires_at", expiresAt.Format(time.RFC3339),
+		)
 	}
 
 	return c.JSON(fiber.Map{
@@ -280,7 +286,7 @@ func (h *PasswordResetHandler) ResetPassword(c fiber.Ctx) error {
 		})
 	}
 
-	fmt.Printf("✅ Password reset successful for user: %s\n", req.Email)
+	logging.FromContext(c.Context()).Info("password reset successful", "email", req.Email)
 
 	return c.JSON(fiber.Map{
 		"success": true,
@@ -3,12 +3,14 @@ package handlers
 import (
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/gofiber/fiber/v3"
 	"github.com/google/uuid"
 	"github.com/opena2a/identity/backend/internal/application"
 	"github.com/opena2a/identity/backend/internal/domain"
 	"github.com/opena2a/identity/backend/internal/infrastructure/auth"
+	"github.com/opena2a/identity/backend/internal/infrastructure/logging"
 )
 
 // PublicRegistrationHandler handles public user registration and login (no auth required)
@@ -16,6 +18,8 @@ type PublicRegistrationHandler struct {
 	registrationService *application.RegistrationService
 	authService         *application.AuthService
 	jwtService          *auth.JWTService
+	loginLockout        *application.LoginLockout
+	twoFactor           *application.TwoFactorService
 }
 
 // NewPublicRegistrationHandler creates a new public registration handler
@@ -23,11 +27,15 @@ func NewPublicRegistrationHandler(
 	registrationService *application.RegistrationService,
 	authService *application.AuthService,
 	jwtService *auth.JWTService,
+	loginLockout *application.LoginLockout,
+	twoFactor *application.TwoFactorService,
 ) *PublicRegistrationHandler {
 	return &PublicRegistrationHandler{
 		registrationService: registrationService,
 		authService:         authService,
 		jwtService:          jwtService,
+		loginLockout:        loginLockout,
+		twoFactor:           twoFactor,
 	}
 }
 
@@ -89,10 +97,11 @@ func (h *PublicRegistrationHandler) RegisterUser(c fiber.Ctx) error {
 		firstName,
 		lastName,
 		req.Password,
+		c.Get(fiber.HeaderAcceptLanguage),
 	)
 	if err != nil {
 		// Log the actual error for debugging
-		fmt.Printf("ERROR in RegisterUser: %v\n", err)
+		logging.FromContext(c.Context()).Error("failed to register user", "error", err)
 		
 		// Handle specific error cases
 		switch err {
@@ -190,6 +199,7 @@ func (h *PublicRegistrationHandler) CheckRegistrationStatus(c fiber.Ctx) error {
 type LoginRequest struct {
 	Email    string `json:"email" validate:"required,email"`
 	Password string `json:"password" validate:"required"`
+	TOTPCode string `json:"totpCode,omitempty"` // TOTP or backup code, required once two-factor is enabled
 }
 
 // LoginResponse represents the login response
@@ -234,12 +244,13 @@ func (h *PublicRegistrationHandler) Login(c fiber.Ctx) error {
 	// Normalize email
 	email := strings.ToLower(strings.TrimSpace(req.Email))
 
+	// Reject logins while the account or client is locked out after repeated failures
+	if retryAfter := h.loginLockout.RetryAfter(c.Context(), email, c.IP(), time.Now()); retryAfter > 0 {
+		return loginLockedResponse(c, retryAfter)
+	}
+
 	// Check users table first - if user exists there, they are automatically approved
 	user, err := h.authService.GetUserByEmail(c.Context(), email)
-	fmt.Printf("🔍 DEBUG: GetUserByEmail result for %s: user=%v, err=%v\n", email, user, err)
-	if user != nil {
-		fmt.Printf("🔍 DEBUG: User loaded - ID: %s, Email: %s, Role: '%s' (type: %T)\n", user.ID, user.Email, user.Role, user.Role)
-	}
 	if err == nil && user != nil {
 		// Check if user account is deactivated
 		if user.Status == domain.UserStatusDeactivated || user.DeletedAt != nil {
@@ -253,12 +264,13 @@ func (h *PublicRegistrationHandler) Login(c fiber.Ctx) error {
 		if user.PasswordHash != nil && *user.PasswordHash != "" {
 			// Verify password from users table
 			passwordHasher := auth.NewPasswordHasher()
-			fmt.Printf("🔍 DEBUG: Verifying password for %s\n", user.Email)
-			fmt.Printf("🔍 DEBUG: Password hash from DB: %s\n", *user.PasswordHash)
-			fmt.Printf("🔍 DEBUG: Password length: %d chars\n", len(req.Password))
 			if err := passwordHasher.VerifyPassword(req.Password, *user.PasswordHash); err == nil {
+				logging.FromContext(c.Context()).Debug("password verification passed", "user_id", user.ID)
+				if handled, err := checkTwoFactorLogin(c, h.twoFactor, h.loginLockout, user.ID, email, req.TOTPCode); handled {
+					return err
+				}
+				h.loginLockout.RecordSuccess(c.Context(), email)
 				// Check if user must change password (e.g., default admin on first login)
-				fmt.Printf("✅ DEBUG: Password verification PASSED for %s\n", user.Email)
 				if user.ForcePasswordChange {
 					// Generate tokens even for forced password change
 					// so user can access the change password page
@@ -268,7 +280,7 @@ func (h *PublicRegistrationHandler) Login(c fiber.Ctx) error {
 				// User in users table = automatically approved, generate tokens
 				return h.generateApprovedLoginResponse(c, user)
 			} else {
-				fmt.Printf("❌ DEBUG: Password verification FAILED for %s: %v\n", user.Email, err)
+				logging.FromContext(c.Context()).Debug("password verification failed", "user_id", user.ID, "error", err)
 			}
 			// Password verification failed - continue to check registration requests
 		}
@@ -283,6 +295,7 @@ func (h *PublicRegistrationHandler) Login(c fiber.Ctx) error {
 			passwordHasher := auth.NewPasswordHasher()
 			if err := passwordHasher.VerifyPassword(req.Password, *regRequest.PasswordHash); err == nil {
 				// Password correct - check status
+				h.loginLockout.RecordSuccess(c.Context(), email)
 				if regRequest.Status == domain.RegistrationStatusApproved {
 					// Status = approved - this should not happen if approval process worked correctly
 					// Return error indicating system issue
@@ -326,6 +339,9 @@ func (h *PublicRegistrationHandler) Login(c fiber.Ctx) error {
 	}
 
 	// User not found in either table or password incorrect
+	if retryAfter := h.loginLockout.RecordFailure(c.Context(), email, c.IP(), time.Now()); retryAfter > 0 {
+		return loginLockedResponse(c, retryAfter)
+	}
 	return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
 		"success": false,
 		"error":   "Invalid email or password",
@@ -337,11 +353,10 @@ func (h *PublicRegistrationHandler) generateApprovedLoginResponse(c fiber.Ctx, u
 	// Update last login timestamp
 	if err := h.authService.UpdateLastLogin(c.Context(), user); err != nil {
 		// Log warning but continue - this is non-critical
-		fmt.Printf("Warning: failed to update last_login_at for user %s: %v\n", user.ID, err)
+		logging.FromContext(c.Context()).Warn("failed to update last_login_at", "user_id", user.ID, "error", err)
 	}
 
 	// Generate tokens
-	fmt.Printf("🔍 DEBUG: Generating JWT for user %s (email: %s, role: '%s', role type: %T)\n", user.ID, user.Email, user.Role, user.Role)
 	accessToken, refreshToken, err := h.jwtService.GenerateTokenPair(
 		user.ID.String(),
 		user.OrganizationID.String(),
@@ -529,7 +544,7 @@ func (h *PublicRegistrationHandler) RequestAccess(c fiber.Ctx) error {
 	)
 	if err != nil {
 		// Log the actual error for debugging
-		fmt.Printf("ERROR in RequestAccess: %v\n", err)
+		logging.FromContext(c.Context()).Error("failed to request access", "error", err)
 
 		// Handle specific error cases
 		switch err {
@@ -675,9 +690,9 @@ func (h *PublicRegistrationHandler) ForgotPassword(c fiber.Ctx) error {
 	}
 
 	// Request password reset (always succeeds for security - don't reveal if email exists)
-	if err := h.registrationService.RequestPasswordReset(c.Context(), req.Email); err != nil {
+	if err := h.registrationService.RequestPasswordReset(c.Context(), req.Email, c.Get(fiber.HeaderAcceptLanguage)); err != nil {
 		// Log error but don't reveal to user
-		fmt.Printf("ERROR in ForgotPassword: %v\n", err)
+		logging.FromContext(c.Context()).Error("failed to request password reset", "error", err)
 	}
 
 	// Always return success message for security (timing-attack prevention)
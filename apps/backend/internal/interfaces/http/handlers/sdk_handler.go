@@ -16,6 +16,7 @@ import (
 	"github.com/google/uuid"
 	"github.com/opena2a/identity/backend/internal/domain"
 	"github.com/opena2a/identity/backend/internal/infrastructure/auth"
+	"github.com/opena2a/identity/backend/internal/infrastructure/logging"
 )
 
 // SDKHandler handles SDK download operations
@@ -150,7 +151,7 @@ func (h *SDKHandler) DownloadSDK(c fiber.Ctx) error {
 	err = h.sdkTokenRepo.Create(sdkToken)
 	if err != nil {
 		// Log error but don't fail download (tracking is not critical for download)
-		fmt.Printf("Warning: Failed to track SDK token: %v\n", err)
+		logging.FromContext(c.Context()).Warn("failed to track SDK token", "error", err)
 	}
 
 	// Get AIM URL from environment or use request base URL
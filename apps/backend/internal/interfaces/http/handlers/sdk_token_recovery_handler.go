@@ -3,6 +3,9 @@ package handlers
 import (
 	"crypto/sha256"
 	"encoding/hex"
+	"fmt"
+	"math"
+	"strconv"
 	"time"
 
 	"github.com/gofiber/fiber/v3"
@@ -16,20 +19,29 @@ import (
 type SDKTokenRecoveryHandler struct {
 	sdkTokenService *application.SDKTokenService
 	jwtService      *auth.JWTService
+	recoveryGuard   *application.SDKTokenRecoveryGuard
 }
 
 func NewSDKTokenRecoveryHandler(
 	sdkTokenService *application.SDKTokenService,
 	jwtService *auth.JWTService,
+	recoveryGuard *application.SDKTokenRecoveryGuard,
 ) *SDKTokenRecoveryHandler {
 	return &SDKTokenRecoveryHandler{
 		sdkTokenService: sdkTokenService,
 		jwtService:      jwtService,
+		recoveryGuard:   recoveryGuard,
 	}
 }
 
+// RecoverTokenRequest carries the revoked refresh token and a proof-of-possession: the Ed25519
+// signature of application.SDKTokenRecoveryMessage(jti, agent_id, timestamp) by one of the
+// user's agents
 type RecoverTokenRequest struct {
 	OldRefreshToken string `json:"old_refresh_token" validate:"required"`
+	AgentID         string `json:"agent_id" validate:"required"`
+	Timestamp       string `json:"timestamp" validate:"required"`
+	Signature       string `json:"signature" validate:"required"`
 }
 
 type RecoverTokenResponse struct {
@@ -41,7 +53,8 @@ type RecoverTokenResponse struct {
 }
 
 // RecoverRevokedToken allows users to get a new SDK token when their old one was revoked
-// This prevents the need to re-download the entire SDK package
+// This prevents the need to re-download the entire SDK package. The request must be signed by
+// one of the user's agents; repeated failures lock recovery and raise a security alert.
 func (h *SDKTokenRecoveryHandler) RecoverRevokedToken(c fiber.Ctx) error {
 	var req RecoverTokenRequest
 	if err := c.Bind().JSON(&req); err != nil {
@@ -58,6 +71,12 @@ func (h *SDKTokenRecoveryHandler) RecoverRevokedToken(c fiber.Ctx) error {
 		})
 	}
 
+	now := time.Now()
+	ipAddress := c.IP()
+	if retryAfter := h.recoveryGuard.RetryAfter(c.Context(), tokenID, ipAddress, now); retryAfter > 0 {
+		return recoveryLockedResponse(c, retryAfter)
+	}
+
 	// Get hash of old token
 	hasher := sha256.New()
 	hasher.Write([]byte(req.OldRefreshToken))
@@ -65,7 +84,8 @@ func (h *SDKTokenRecoveryHandler) RecoverRevokedToken(c fiber.Ctx) error {
 
 	// Get old token info from database (even if revoked)
 	oldToken, err := h.sdkTokenService.GetByTokenHash(c.Context(), oldTokenHash)
-	if err != nil {
+	if err != nil || oldToken == nil {
+		h.recoveryGuard.RecordFailure(c.Context(), nil, tokenID, ipAddress, fmt.Errorf("token not found"), now)
 		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
 			"error": "Token not found - it may have been deleted",
 		})
@@ -78,6 +98,23 @@ func (h *SDKTokenRecoveryHandler) RecoverRevokedToken(c fiber.Ctx) error {
 		})
 	}
 
+	// Proof-of-possession: a leaked refresh token alone must not be enough to mint a new one
+	agentID, _ := uuid.Parse(req.AgentID)
+	agent, err := h.recoveryGuard.Verify(c.Context(), oldToken, application.SDKTokenRecoveryProof{
+		AgentID:   agentID,
+		Timestamp: req.Timestamp,
+		Signature: req.Signature,
+	}, now)
+	if err != nil {
+		if retryAfter := h.recoveryGuard.RecordFailure(c.Context(), oldToken, tokenID, ipAddress, err, now); retryAfter > 0 {
+			return recoveryLockedResponse(c, retryAfter)
+		}
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	h.recoveryGuard.RecordSuccess(c.Context(), tokenID, ipAddress)
+
 	// Generate new SDK token pair for the same user
 	newAccessToken, newRefreshToken, err := h.jwtService.GenerateTokenPair(
 		oldToken.UserID.String(),
@@ -105,7 +142,6 @@ func (h *SDKTokenRecoveryHandler) RecoverRevokedToken(c fiber.Ctx) error {
 	newTokenHash := hex.EncodeToString(newHasher.Sum(nil))
 
 	// Get client info
-	ipAddress := c.IP()
 	userAgent := c.Get("User-Agent")
 
 	// Create new SDK token entry
@@ -119,12 +155,13 @@ func (h *SDKTokenRecoveryHandler) RecoverRevokedToken(c fiber.Ctx) error {
 		DeviceFingerprint: oldToken.DeviceFingerprint,
 		IPAddress:         &ipAddress,
 		UserAgent:         &userAgent,
-		CreatedAt:         time.Now(),
-		ExpiresAt:         time.Now().Add(90 * 24 * time.Hour), // 90 days
+		CreatedAt:         now,
+		ExpiresAt:         now.Add(90 * 24 * time.Hour), // 90 days
 		Metadata: map[string]interface{}{
-			"source":          "token_recovery",
-			"recoveredFrom":  tokenID,
-			"recoveryReason": "token_revoked",
+			"source":           "token_recovery",
+			"recoveredFrom":    tokenID,
+			"recoveryReason":   "token_revoked",
+			"recoveredByAgent": agent.ID.String(),
 		},
 	}
 
@@ -143,3 +180,13 @@ func (h *SDKTokenRecoveryHandler) RecoverRevokedToken(c fiber.Ctx) error {
 		Message:      "Token recovered successfully - SDK credentials updated automatically",
 	})
 }
+
+// recoveryLockedResponse rejects a recovery with 429 and a Retry-After header while it is locked out
+func recoveryLockedResponse(c fiber.Ctx, retryAfter time.Duration) error {
+	seconds := int(math.Ceil(retryAfter.Seconds()))
+	c.Set(fiber.HeaderRetryAfter, strconv.Itoa(seconds))
+	return c.Status(fiber.StatusTooManyRequests).JSON(fiber.Map{
+		"error":      fmt.Sprintf("Too many failed recovery attempts. Try again in %d minute(s).", int(math.Ceil(retryAfter.Minutes()))),
+		"retryAfter": seconds,
+	})
+}
@@ -211,7 +211,7 @@ func (h *SecurityHandler) GetSecurityDashboard(c fiber.Ctx) error {
 
 // ListSecurityAlerts retrieves security alerts
 // @Summary List security alerts
-// @Description Get all security alerts for the organization
+// @Description Get all security alerts for the organization, including who acknowledged and resolved each one and when
 // @Tags security
 // @Produce json
 // @Param limit query int false "Limit" default(20)
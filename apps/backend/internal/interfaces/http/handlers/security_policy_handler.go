@@ -1,12 +1,15 @@
 package handlers
 
 import (
-	"fmt"
+	"errors"
+	"strconv"
+	"time"
 
 	"github.com/gofiber/fiber/v3"
 	"github.com/google/uuid"
 	"github.com/opena2a/identity/backend/internal/application"
 	"github.com/opena2a/identity/backend/internal/domain"
+	"github.com/opena2a/identity/backend/internal/infrastructure/logging"
 )
 
 type SecurityPolicyHandler struct {
@@ -36,18 +39,15 @@ func (h *SecurityPolicyHandler) ListPolicies(c fiber.Ctx) error {
 		})
 	}
 
-	// 🔍 DEBUG: Log the organization ID being queried
-	fmt.Printf("🔍 DEBUG ListPolicies: Querying policies for org_id=%s\n", orgID)
-
 	policies, err := h.policyService.ListPolicies(c.Context(), orgID)
 	if err != nil {
-		fmt.Printf("❌ DEBUG ListPolicies: Error fetching policies: %v\n", err)
+		logging.FromContext(c.Context()).Error("failed to list security policies", "org_id", orgID, "error", err)
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": "Failed to retrieve policies",
 		})
 	}
 
-	fmt.Printf("✅ DEBUG ListPolicies: Found %d policies for org_id=%s\n", len(policies), orgID)
+	logging.FromContext(c.Context()).Debug("listed security policies", "org_id", orgID, "count", len(policies))
 	return c.JSON(policies)
 }
 
@@ -217,3 +217,103 @@ func (h *SecurityPolicyHandler) TogglePolicy(c fiber.Ctx) error {
 	policy, _ := h.policyService.GetPolicy(c.Context(), policyID)
 	return c.JSON(policy)
 }
+
+// GetSimulationReport summarizes what a policy would have blocked while in simulate mode (admin only).
+// The optional days query parameter (1-365, default 7) sets the reporting window.
+func (h *SecurityPolicyHandler) GetSimulationReport(c fiber.Ctx) error {
+	orgID, ok := c.Locals("organization_id").(uuid.UUID)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Organization ID not found in context",
+		})
+	}
+
+	policyID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid policy ID",
+		})
+	}
+
+	days, err := strconv.Atoi(c.Query("days", "7"))
+	if err != nil || days < 1 || days > 365 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "days must be between 1 and 365",
+		})
+	}
+
+	policy, err := h.policyService.GetPolicy(c.Context(), policyID)
+	if err != nil || policy.OrganizationID != orgID {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Policy not found",
+		})
+	}
+
+	since := time.Now().AddDate(0, 0, -days)
+	report, err := h.policyService.GetSimulationReport(c.Context(), policy, since)
+	if err != nil {
+		logging.FromContext(c.Context()).Error("failed to build policy simulation report", "policy_id", policyID, "error", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to build simulation report",
+		})
+	}
+
+	return c.JSON(report)
+}
+
+// ExportPolicies returns the organization's security policies as a portable JSON document (admin only)
+func (h *SecurityPolicyHandler) ExportPolicies(c fiber.Ctx) error {
+	orgID, ok := c.Locals("organization_id").(uuid.UUID)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Organization ID not found in context",
+		})
+	}
+
+	export, err := h.policyService.ExportPolicies(c.Context(), orgID)
+	if err != nil {
+		logging.FromContext(c.Context()).Error("failed to export security policies", "org_id", orgID, "error", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to export policies",
+		})
+	}
+
+	return c.JSON(export)
+}
+
+// ImportPolicies upserts the policies of an exported document, matching by name (admin only).
+// The mode query parameter is "merge" (default), keeping policies missing from the document,
+// or "replace", deleting them.
+func (h *SecurityPolicyHandler) ImportPolicies(c fiber.Ctx) error {
+	orgID, ok := c.Locals("organization_id").(uuid.UUID)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Organization ID not found in context",
+		})
+	}
+	userID, _ := c.Locals("user_id").(uuid.UUID)
+
+	var document domain.SecurityPolicyExport
+	if err := c.Bind().JSON(&document); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	mode := domain.PolicyImportMode(c.Query("mode", string(domain.PolicyImportMerge)))
+	result, err := h.policyService.ImportPolicies(c.Context(), orgID, userID, &document, mode)
+	if errors.Is(err, domain.ErrInvalidPolicyImport) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	if err != nil {
+		logging.FromContext(c.Context()).Error("failed to import security policies", "org_id", orgID, "error", err)
+		// The import is applied in one transaction, so nothing was changed
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to import policies",
+		})
+	}
+
+	return c.JSON(result)
+}
@@ -81,6 +81,7 @@ func (h *TrustScoreHandler) CalculateTrustScore(c fiber.Ctx) error {
 		"agentId":      agentID,
 		"score":         score.Score,
 		"factors":       score.Factors,
+		"breakdown":     score.Breakdown,
 		"calculated_at": score.LastCalculated,
 	})
 }
@@ -158,46 +159,30 @@ func (h *TrustScoreHandler) GetTrustScoreBreakdown(c fiber.Ctx) error {
 		})
 	}
 
-	// Define weights (matches trust_calculator.go weights)
-	weights := map[string]float64{
-		"verificationStatus": 0.25,
-		"uptime":             0.15,
-		"successRate":        0.15,
-		"securityAlerts":     0.15,
-		"compliance":         0.10,
-		"age":                0.10,
-		"driftDetection":     0.05,
-		"userFeedback":       0.05,
+	// Stored scores don't carry a breakdown - rebuild it from the persisted factors
+	// and the organization's current weights
+	breakdown := score.Breakdown
+	if breakdown == nil {
+		breakdown = application.BuildTrustScoreBreakdownWithWeights(&score.Factors, h.trustCalculator.TrustWeights(orgID))
 	}
 
-	// Calculate contributions (factor value × weight)
-	contributions := map[string]float64{
-		"verificationStatus": score.Factors.VerificationStatus * weights["verificationStatus"],
-		"uptime":             score.Factors.Uptime * weights["uptime"],
-		"successRate":        score.Factors.SuccessRate * weights["successRate"],
-		"securityAlerts":     score.Factors.SecurityAlerts * weights["securityAlerts"],
-		"compliance":         score.Factors.Compliance * weights["compliance"],
-		"age":                score.Factors.Age * weights["age"],
-		"driftDetection":     score.Factors.DriftDetection * weights["driftDetection"],
-		"userFeedback":       score.Factors.UserFeedback * weights["userFeedback"],
+	factors := make(map[string]float64, len(breakdown.Factors))
+	weights := make(map[string]float64, len(breakdown.Factors))
+	contributions := make(map[string]float64, len(breakdown.Factors))
+	for _, f := range breakdown.Factors {
+		factors[f.Factor] = f.RawValue
+		weights[f.Factor] = f.Weight
+		contributions[f.Factor] = f.Contribution
 	}
 
 	return c.JSON(fiber.Map{
-		"agentId":   agentID,
-		"agentName": agent.Name,
-		"overall":   score.Score,
-		"factors": map[string]float64{
-			"verificationStatus": score.Factors.VerificationStatus,
-			"uptime":             score.Factors.Uptime,
-			"successRate":        score.Factors.SuccessRate,
-			"securityAlerts":     score.Factors.SecurityAlerts,
-			"compliance":         score.Factors.Compliance,
-			"age":                score.Factors.Age,
-			"driftDetection":     score.Factors.DriftDetection,
-			"userFeedback":       score.Factors.UserFeedback,
-		},
+		"agentId":       agentID,
+		"agentName":     agent.Name,
+		"overall":       score.Score,
+		"factors":       factors,
 		"weights":       weights,
 		"contributions": contributions,
+		"breakdown":     breakdown.Factors, // Per-factor raw value, weight and weighted contribution
 		"confidence":    score.Confidence,
 		"calculatedAt":  score.LastCalculated,
 	})
@@ -253,3 +238,56 @@ func (h *TrustScoreHandler) GetTrustScoreHistory(c fiber.Ctx) error {
 		"total":      len(history),
 	})
 }
+
+// RecalculateAllTrustScores recalculates the trust score of every agent in the organization
+// @Summary Recalculate all trust scores
+// @Description Recompute every agent's trust score in batches, e.g. after the trust weights change, and record the history
+// @Tags admin
+// @Produce json
+// @Param batch_size query int false "Agents loaded per batch (default: 100, max: 1000)"
+// @Success 200 {object} application.TrustRecalculationSummary
+// @Failure 400 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /admin/trust-score/recalculate-all [post]
+func (h *TrustScoreHandler) RecalculateAllTrustScores(c fiber.Ctx) error {
+	orgID := c.Locals("organization_id").(uuid.UUID)
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	batchSize := application.DefaultTrustRecalculationBatchSize
+	if batchSizeStr := c.Query("batch_size"); batchSizeStr != "" {
+		parsed, err := strconv.Atoi(batchSizeStr)
+		if err != nil || parsed < 1 || parsed > 1000 {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "batch_size must be between 1 and 1000",
+			})
+		}
+		batchSize = parsed
+	}
+
+	summary, err := h.trustCalculator.RecalculateOrganization(c.Context(), orgID, batchSize)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   "Failed to recalculate trust scores",
+			"summary": summary,
+		})
+	}
+
+	h.auditService.LogAction(
+		c.Context(),
+		orgID,
+		userID,
+		domain.AuditActionCalculate,
+		"trust_score",
+		orgID, // Use orgID for organization-wide operations
+		c.IP(),
+		c.Get("User-Agent"),
+		map[string]interface{}{
+			"totalAgents":  summary.TotalAgents,
+			"recalculated": summary.Recalculated,
+			"changed":      summary.Changed,
+			"failed":       summary.Failed,
+		},
+	)
+
+	return c.JSON(summary)
+}
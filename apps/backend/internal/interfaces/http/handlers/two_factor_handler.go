@@ -0,0 +1,138 @@
+package handlers
+
+import (
+	"errors"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/application"
+	"github.com/opena2a/identity/backend/internal/infrastructure/logging"
+)
+
+// TwoFactorHandler handles TOTP two-factor enrollment for the authenticated user
+type TwoFactorHandler struct {
+	twoFactorService *application.TwoFactorService
+}
+
+// NewTwoFactorHandler creates a new two-factor handler
+func NewTwoFactorHandler(twoFactorService *application.TwoFactorService) *TwoFactorHandler {
+	return &TwoFactorHandler{
+		twoFactorService: twoFactorService,
+	}
+}
+
+// Enroll starts two-factor enrollment and returns the TOTP secret and its otpauth:// URI
+// @Summary Start two-factor enrollment
+// @Tags auth
+// @Produce json
+// @Success 200 {object} application.TwoFactorEnrollment
+// @Failure 409 {object} map[string]string
+// @Router /api/v1/auth/2fa/enroll [post]
+func (h *TwoFactorHandler) Enroll(c fiber.Ctx) error {
+	userID, ok := c.Locals("user_id").(uuid.UUID)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Unauthorized - no user context",
+		})
+	}
+	email, _ := c.Locals("email").(string)
+
+	enrollment, err := h.twoFactorService.Enroll(c.Context(), userID, email, time.Now())
+	if errors.Is(err, application.ErrTwoFactorAlreadyEnabled) {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	if err != nil {
+		logging.FromContext(c.Context()).Error("failed to start two-factor enrollment", "user_id", userID, "error", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to start two-factor enrollment",
+		})
+	}
+
+	return c.JSON(enrollment)
+}
+
+// Verify confirms enrollment with a code from the authenticator app, enables two-factor and
+// returns the one-time backup codes
+// @Summary Confirm two-factor enrollment
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]string
+// @Router /api/v1/auth/2fa/verify [post]
+func (h *TwoFactorHandler) Verify(c fiber.Ctx) error {
+	userID, ok := c.Locals("user_id").(uuid.UUID)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Unauthorized - no user context",
+		})
+	}
+
+	var req struct {
+		Code string `json:"code"`
+	}
+	if err := c.Bind().JSON(&req); err != nil || req.Code == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "code is required",
+		})
+	}
+
+	backupCodes, err := h.twoFactorService.Verify(c.Context(), userID, req.Code, time.Now())
+	switch {
+	case errors.Is(err, application.ErrInvalidTwoFactorCode), errors.Is(err, application.ErrTwoFactorNotEnrolled):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	case errors.Is(err, application.ErrTwoFactorAlreadyEnabled):
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	case err != nil:
+		logging.FromContext(c.Context()).Error("failed to verify two-factor enrollment", "user_id", userID, "error", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to verify two-factor enrollment",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"enabled":     true,
+		"backupCodes": backupCodes,
+		"message":     "Two-factor authentication enabled. Store the backup codes somewhere safe; they are shown only once.",
+	})
+}
+
+// checkTwoFactorLogin enforces two-factor for a user whose password was just verified. handled is
+// true when the login must stop, in which case err is the response already written: 401 with
+// twoFactorRequired set when the code is missing or wrong, or 429 once wrong codes lock the login.
+func checkTwoFactorLogin(c fiber.Ctx, twoFactor *application.TwoFactorService, loginLockout *application.LoginLockout, userID uuid.UUID, email, code string) (handled bool, err error) {
+	err = twoFactor.CheckLogin(c.Context(), userID, code, time.Now())
+	switch {
+	case err == nil:
+		return false, nil
+	case errors.Is(err, application.ErrTwoFactorRequired):
+		return true, c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"success":           false,
+			"error":             "Two-factor authentication code required",
+			"twoFactorRequired": true,
+		})
+	case errors.Is(err, application.ErrInvalidTwoFactorCode):
+		// Wrong codes count as failed logins so the 6-digit code space cannot be brute forced
+		if retryAfter := loginLockout.RecordFailure(c.Context(), email, c.IP(), time.Now()); retryAfter > 0 {
+			return true, loginLockedResponse(c, retryAfter)
+		}
+		return true, c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"success":           false,
+			"error":             "Invalid two-factor authentication code",
+			"twoFactorRequired": true,
+		})
+	default:
+		logging.FromContext(c.Context()).Error("two-factor check failed", "user_id", userID, "error", err)
+		return true, c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"error":   "Login failed",
+		})
+	}
+}
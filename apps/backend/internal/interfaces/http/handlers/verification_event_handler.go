@@ -1,6 +1,10 @@
 package handlers
 
 import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"strconv"
 	"time"
 
@@ -36,7 +40,9 @@ func (h *VerificationEventHandler) RegisterRoutes(app *fiber.App, authMiddleware
 
 	api.Get("/", h.ListVerificationEvents)
 	api.Get("/recent", h.GetRecentEvents)
+	api.Get("/stream", h.StreamVerificationEvents)
 	api.Get("/statistics", h.GetStatistics)
+	api.Get("/stats/by-protocol", h.GetStatisticsByProtocol)
 	api.Get("/:id", h.GetVerificationEvent)
 	api.Post("/", h.CreateVerificationEvent)
 	api.Delete("/:id", h.DeleteVerificationEvent)
@@ -319,6 +325,77 @@ func (h *VerificationEventHandler) GetRecentEvents(c fiber.Ctx) error {
 	})
 }
 
+// verificationEventStreamHeartbeat is how often an idle event stream sends a comment line so
+// proxies keep the connection open and dead clients are noticed
+const verificationEventStreamHeartbeat = 15 * time.Second
+
+// StreamVerificationEvents pushes the organization's new verification events as Server-Sent Events
+// @Summary Stream verification events
+// @Description Server-Sent Events stream of verification events as they are created. Each event is sent as "event: verification" with the event JSON as data; comment heartbeats are sent every 15 seconds.
+// @Tags verification-events
+// @Produce text/event-stream
+// @Success 200 {string} string "text/event-stream"
+// @Failure 401 {object} map[string]interface{}
+// @Router /api/v1/verification-events/stream [get]
+func (h *VerificationEventHandler) StreamVerificationEvents(c fiber.Ctx) error {
+	orgID, err := getOrganizationID(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Unauthorized",
+		})
+	}
+
+	events, unsubscribe := h.service.SubscribeToEvents(orgID)
+
+	c.Set(fiber.HeaderContentType, "text/event-stream")
+	c.Set(fiber.HeaderCacheControl, "no-cache")
+	c.Set(fiber.HeaderConnection, "keep-alive")
+	c.Set("X-Accel-Buffering", "no") // Disable proxy buffering (nginx)
+
+	// The stream writer runs after this handler returns, so it must only use the request context
+	requestCtx := c.Context()
+	requestCtx.SetBodyStreamWriter(func(w *bufio.Writer) {
+		defer unsubscribe()
+		writeVerificationEventStream(w, events, requestCtx.Done(), verificationEventStreamHeartbeat)
+	})
+
+	return nil
+}
+
+// writeVerificationEventStream writes events to w until the subscription closes, done is closed
+// (server shutdown) or the client goes away
+func writeVerificationEventStream(w *bufio.Writer, events <-chan *domain.VerificationEvent, done <-chan struct{}, heartbeat time.Duration) {
+	ticker := time.NewTicker(heartbeat)
+	defer ticker.Stop()
+
+	// Flush right away so the client sees the response headers
+	fmt.Fprint(w, ": connected\n\n")
+	if err := w.Flush(); err != nil {
+		return
+	}
+
+	for {
+		select {
+		case <-done:
+			return
+		case event, ok := <-events:
+			if !ok {
+				return
+			}
+			data, err := json.Marshal(event)
+			if err != nil {
+				continue
+			}
+			fmt.Fprintf(w, "id: %s\nevent: verification\ndata: %s\n\n", event.ID, data)
+		case <-ticker.C:
+			fmt.Fprint(w, ": heartbeat\n\n")
+		}
+		if err := w.Flush(); err != nil {
+			return // Client disconnected
+		}
+	}
+}
+
 // GetStatistics retrieves aggregated verification statistics
 // @Summary Get verification statistics
 // @Description Get aggregated statistics for verification events in a time range
@@ -343,59 +420,102 @@ func (h *VerificationEventHandler) GetStatistics(c fiber.Ctx) error {
 	}
 
 	// Parse time range
+	startTime, endTime, err := parseStatisticsPeriod(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	// Get statistics
+	stats, err := h.service.GetStatistics(c.Context(), orgID, startTime, endTime)
+	if err != nil {
+		// Log the actual error for debugging
+		println("ERROR in GetStatistics:", err.Error())
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to retrieve statistics: " + err.Error(),
+		})
+	}
+
+	return c.JSON(stats)
+}
+
+// GetStatisticsByProtocol retrieves verification statistics grouped by protocol
+// @Summary Get verification statistics by protocol
+// @Description Get success/failure counts and p50/p95 durations per protocol in a time range
+// @Tags verification-events
+// @Accept json
+// @Produce json
+// @Param period query string false "Time period (24h, 7d, 30d, custom)" default(24h)
+// @Param start_time query string false "Start time for custom period (RFC3339)"
+// @Param end_time query string false "End time for custom period (RFC3339)"
+// @Success 200 {object} map[string]domain.ProtocolVerificationStatistics
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /api/v1/verification-events/stats/by-protocol [get]
+func (h *VerificationEventHandler) GetStatisticsByProtocol(c fiber.Ctx) error {
+	orgID, err := getOrganizationID(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Unauthorized",
+		})
+	}
+
+	startTime, endTime, err := parseStatisticsPeriod(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	stats, err := h.service.GetStatisticsByProtocol(c.Context(), orgID, startTime, endTime)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to retrieve protocol statistics",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"protocols": stats,
+		"startTime": startTime,
+		"endTime":   endTime,
+	})
+}
+
+// parseStatisticsPeriod resolves the period, start_time and end_time query parameters into a time range
+func parseStatisticsPeriod(c fiber.Ctx) (time.Time, time.Time, error) {
 	period := c.Query("period", "24h")
-	var startTime, endTime time.Time
+	endTime := time.Now()
 
 	switch period {
 	case "24h":
-		endTime = time.Now()
-		startTime = endTime.Add(-24 * time.Hour)
+		return endTime.Add(-24 * time.Hour), endTime, nil
 	case "7d":
-		endTime = time.Now()
-		startTime = endTime.Add(-7 * 24 * time.Hour)
+		return endTime.Add(-7 * 24 * time.Hour), endTime, nil
 	case "30d":
-		endTime = time.Now()
-		startTime = endTime.Add(-30 * 24 * time.Hour)
+		return endTime.Add(-30 * 24 * time.Hour), endTime, nil
 	case "custom":
 		startTimeStr := c.Query("start_time")
 		endTimeStr := c.Query("end_time")
 
 		if startTimeStr == "" || endTimeStr == "" {
-			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-				"error": "start_time and end_time required for custom period",
-			})
+			return time.Time{}, time.Time{}, errors.New("start_time and end_time required for custom period")
 		}
 
-		startTime, err = time.Parse(time.RFC3339, startTimeStr)
+		startTime, err := time.Parse(time.RFC3339, startTimeStr)
 		if err != nil {
-			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-				"error": "Invalid start_time format (use RFC3339)",
-			})
+			return time.Time{}, time.Time{}, errors.New("Invalid start_time format (use RFC3339)")
 		}
 
 		endTime, err = time.Parse(time.RFC3339, endTimeStr)
 		if err != nil {
-			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-				"error": "Invalid end_time format (use RFC3339)",
-			})
+			return time.Time{}, time.Time{}, errors.New("Invalid end_time format (use RFC3339)")
 		}
+		return startTime, endTime, nil
 	default:
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid period (use 24h, 7d, 30d, or custom)",
-		})
+		return time.Time{}, time.Time{}, errors.New("Invalid period (use 24h, 7d, 30d, or custom)")
 	}
-
-	// Get statistics
-	stats, err := h.service.GetStatistics(c.Context(), orgID, startTime, endTime)
-	if err != nil {
-		// Log the actual error for debugging
-		println("ERROR in GetStatistics:", err.Error())
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to retrieve statistics: " + err.Error(),
-		})
-	}
-
-	return c.JSON(stats)
 }
 
 // GetAgentVerificationEvents retrieves verification events for a specific agent
@@ -0,0 +1,118 @@
+package handlers
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/application"
+	"github.com/opena2a/identity/backend/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// stubVerificationEventRepository accepts every event; other methods are not used by these tests
+type stubVerificationEventRepository struct {
+	domain.VerificationEventRepository
+}
+
+func (stubVerificationEventRepository) Create(event *domain.VerificationEvent) error {
+	event.ID = uuid.New()
+	return nil
+}
+
+// stubAgentRepository returns the same agent for every ID
+type stubAgentRepository struct {
+	domain.AgentRepository
+}
+
+func (stubAgentRepository) GetByID(id uuid.UUID) (*domain.Agent, error) {
+	return &domain.Agent{ID: id, DisplayName: "Streaming Agent", TrustScore: 90}, nil
+}
+
+func (stubAgentRepository) UpdateLastActive(ctx context.Context, agentID uuid.UUID) error {
+	return nil
+}
+
+func TestStreamVerificationEvents_DeliversCreatedEvent(t *testing.T) {
+	orgID := uuid.New()
+	service := application.NewVerificationEventService(stubVerificationEventRepository{}, stubAgentRepository{}, nil)
+	handler := NewVerificationEventHandler(service)
+
+	app := fiber.New()
+	app.Get("/stream", handler.StreamVerificationEvents, func(c fiber.Ctx) error {
+		c.Locals("organization_id", orgID) // Stands in for the auth middleware
+		return c.Next()
+	})
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	go app.Listener(listener, fiber.ListenConfig{DisableStartupMessage: true})
+	defer app.Shutdown()
+
+	resp, err := http.Get("http://" + listener.Addr().String() + "/stream")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+
+	reader := bufio.NewReader(resp.Body)
+	line, err := reader.ReadString('\n')
+	require.NoError(t, err)
+	assert.Equal(t, ": connected\n", line) // Subscribed once the stream has started
+
+	agentID := uuid.New()
+	created, err := service.CreateVerificationEvent(context.Background(), &application.CreateVerificationEventRequest{
+		OrganizationID:   orgID,
+		AgentID:          agentID,
+		Protocol:         domain.VerificationProtocolA2A,
+		VerificationType: domain.VerificationTypeIdentity,
+		Status:           domain.VerificationEventStatusSuccess,
+		InitiatorType:    domain.InitiatorTypeAgent,
+	})
+	require.NoError(t, err)
+
+	received := make(chan string, 1)
+	go func() {
+		for {
+			line, err := reader.ReadString('\n')
+			if err != nil {
+				return
+			}
+			if data, ok := strings.CutPrefix(line, "data: "); ok {
+				received <- data
+				return
+			}
+		}
+	}()
+
+	select {
+	case data := <-received:
+		var event domain.VerificationEvent
+		require.NoError(t, json.Unmarshal([]byte(data), &event))
+		assert.Equal(t, created.ID, event.ID)
+		assert.Equal(t, orgID, event.OrganizationID)
+		require.NotNil(t, event.AgentID)
+		assert.Equal(t, agentID, *event.AgentID)
+	case <-time.After(5 * time.Second):
+		t.Fatal("created event was not streamed to the client")
+	}
+}
+
+func TestStreamVerificationEvents_RequiresOrganization(t *testing.T) {
+	service := application.NewVerificationEventService(stubVerificationEventRepository{}, stubAgentRepository{}, nil)
+	app := fiber.New()
+	app.Get("/stream", NewVerificationEventHandler(service).StreamVerificationEvents)
+
+	resp, err := app.Test(httptest.NewRequest("GET", "/stream", nil))
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusUnauthorized, resp.StatusCode)
+}
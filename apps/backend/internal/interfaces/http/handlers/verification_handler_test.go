@@ -0,0 +1,99 @@
+package handlers
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/application"
+	"github.com/opena2a/identity/backend/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// exhaustedAgentRateLimiter records the agents it is charged for and rejects them all
+type exhaustedAgentRateLimiter struct {
+	charged []uuid.UUID
+}
+
+func (l *exhaustedAgentRateLimiter) ChargeAgent(c fiber.Ctx, agentID uuid.UUID) (bool, error) {
+	l.charged = append(l.charged, agentID)
+	return false, c.SendStatus(fiber.StatusTooManyRequests)
+}
+
+// newVerificationTestApp serves CreateVerification for a verified agent signing with privateKey
+func newVerificationTestApp(t *testing.T, limiter AgentRateLimiter) (*fiber.App, *domain.Agent, ed25519.PrivateKey) {
+	t.Helper()
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	encodedPublicKey := base64.StdEncoding.EncodeToString(publicKey)
+	agent := &domain.Agent{
+		ID:             uuid.New(),
+		OrganizationID: uuid.New(),
+		Name:           "billing-agent",
+		Status:         domain.AgentStatusVerified,
+		PublicKey:      &encodedPublicKey,
+	}
+
+	agentService := application.NewAgentService(&sdkDownloadAgentRepository{agent: agent}, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil)
+	handler := NewVerificationHandler(agentService, nil, nil, nil, nil, nil, nil, nil, limiter)
+
+	app := fiber.New()
+	app.Post("/api/v1/sdk-api/verifications", handler.CreateVerification)
+	return app, agent, privateKey
+}
+
+// postVerification sends a verification request for agent signed with privateKey
+func postVerification(t *testing.T, app *fiber.App, agent *domain.Agent, privateKey ed25519.PrivateKey) int {
+	t.Helper()
+	req := VerificationRequest{
+		AgentID:    agent.ID.String(),
+		ActionType: "read_file",
+		Resource:   "/data/report.csv",
+		Timestamp:  time.Now().UTC().Format(time.RFC3339),
+		PublicKey:  *agent.PublicKey,
+	}
+	message, err := verificationSignatureMessage(req)
+	require.NoError(t, err)
+	req.Signature = base64.StdEncoding.EncodeToString(ed25519.Sign(privateKey, message))
+
+	body, err := json.Marshal(req)
+	require.NoError(t, err)
+	httpReq := httptest.NewRequest(fiber.MethodPost, "/api/v1/sdk-api/verifications", bytes.NewReader(body))
+	httpReq.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+
+	resp, err := app.Test(httpReq)
+	require.NoError(t, err)
+	return resp.StatusCode
+}
+
+func TestCreateVerification_ChargesSignedAgentRateLimit(t *testing.T) {
+	limiter := &exhaustedAgentRateLimiter{}
+	app, agent, privateKey := newVerificationTestApp(t, limiter)
+
+	status := postVerification(t, app, agent, privateKey)
+
+	assert.Equal(t, fiber.StatusTooManyRequests, status)
+	assert.Equal(t, []uuid.UUID{agent.ID}, limiter.charged)
+}
+
+func TestCreateVerification_UnsignedRequestDoesNotChargeAgent(t *testing.T) {
+	// Only the agent's own signature may spend its quota
+	limiter := &exhaustedAgentRateLimiter{}
+	app, agent, _ := newVerificationTestApp(t, limiter)
+	_, otherKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	status := postVerification(t, app, agent, otherKey)
+
+	assert.Equal(t, fiber.StatusUnauthorized, status)
+	assert.Empty(t, limiter.charged)
+}
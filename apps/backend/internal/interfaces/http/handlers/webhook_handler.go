@@ -1,7 +1,9 @@
 package handlers
 
 import (
+	"errors"
 	"fmt"
+	"strconv"
 
 	"github.com/gofiber/fiber/v3"
 	"github.com/google/uuid"
@@ -47,7 +49,7 @@ func (h *WebhookHandler) CreateWebhook(c fiber.Ctx) error {
 
 	webhook, err := h.webhookService.CreateWebhook(c.Context(), &req, orgID, userID)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+		return c.Status(webhookErrorStatus(err)).JSON(fiber.Map{
 			"error": err.Error(),
 		})
 	}
@@ -134,6 +136,120 @@ func (h *WebhookHandler) GetWebhook(c fiber.Ctx) error {
 	return c.JSON(webhook)
 }
 
+// GetWebhookDeliveries lists delivery attempts for a webhook
+// @Summary List webhook deliveries
+// @Description Get delivery history and retry status for a webhook
+// @Tags webhooks
+// @Produce json
+// @Param id path string true "Webhook ID"
+// @Param limit query int false "Max deliveries to return (default 50, max 100)"
+// @Param offset query int false "Offset for pagination"
+// @Success 200 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /api/v1/webhooks/{id}/deliveries [get]
+func (h *WebhookHandler) GetWebhookDeliveries(c fiber.Ctx) error {
+	orgID := c.Locals("organization_id").(uuid.UUID)
+	webhookID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid webhook ID",
+		})
+	}
+
+	webhook, err := h.webhookService.GetWebhook(c.Context(), webhookID)
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Webhook not found",
+		})
+	}
+
+	// Verify webhook belongs to organization
+	if webhook.OrganizationID != orgID {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error": "Access denied",
+		})
+	}
+
+	limit, _ := strconv.Atoi(c.Query("limit", "50"))
+	offset, _ := strconv.Atoi(c.Query("offset", "0"))
+	if limit <= 0 || limit > 100 {
+		limit = 50
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	deliveries, err := h.webhookService.GetDeliveries(c.Context(), webhookID, limit, offset)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to fetch webhook deliveries",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"deliveries": deliveries,
+		"limit":      limit,
+		"offset":     offset,
+	})
+}
+
+// RotateWebhookSecret generates a new signing secret for a webhook
+// @Summary Rotate webhook secret
+// @Description Replace the webhook signing secret. The new secret is returned once and cannot be retrieved again.
+// @Tags webhooks
+// @Produce json
+// @Param id path string true "Webhook ID"
+// @Success 200 {object} domain.Webhook
+// @Failure 404 {object} map[string]interface{}
+// @Router /api/v1/webhooks/{id}/rotate-secret [post]
+func (h *WebhookHandler) RotateWebhookSecret(c fiber.Ctx) error {
+	orgID := c.Locals("organization_id").(uuid.UUID)
+	userID := c.Locals("user_id").(uuid.UUID)
+	webhookID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid webhook ID",
+		})
+	}
+
+	// Verify webhook belongs to organization
+	webhook, err := h.webhookService.GetWebhook(c.Context(), webhookID)
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Webhook not found",
+		})
+	}
+	if webhook.OrganizationID != orgID {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error": "Access denied",
+		})
+	}
+
+	webhook, err = h.webhookService.RotateWebhookSecret(c.Context(), webhookID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	// Log audit
+	h.auditService.LogAction(
+		c.Context(),
+		orgID,
+		userID,
+		domain.AuditActionUpdate,
+		"webhook",
+		webhookID,
+		c.IP(),
+		c.Get("User-Agent"),
+		map[string]interface{}{
+			"action": "rotate_secret",
+		},
+	)
+
+	return c.JSON(webhook)
+}
+
 // DeleteWebhook deletes a webhook
 // @Summary Delete webhook
 // @Description Delete a webhook subscription
@@ -231,7 +347,7 @@ func (h *WebhookHandler) UpdateWebhook(c fiber.Ctx) error {
 	// Update webhook
 	webhook, err := h.webhookService.UpdateWebhook(c.Context(), webhookID, &req)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+		return c.Status(webhookErrorStatus(err)).JSON(fiber.Map{
 			"error": err.Error(),
 		})
 	}
@@ -255,9 +371,9 @@ func (h *WebhookHandler) UpdateWebhook(c fiber.Ctx) error {
 	return c.JSON(webhook)
 }
 
-// TestWebhook sends a test payload to a webhook
+// TestWebhook sends a signed sample event to a webhook
 // @Summary Test webhook
-// @Description Send a test payload to verify webhook functionality
+// @Description Send a signed sample security_breach event and report the receiver's status code and latency
 // @Tags webhooks
 // @Produce json
 // @Param id path string true "Webhook ID"
@@ -291,8 +407,7 @@ func (h *WebhookHandler) TestWebhook(c fiber.Ctx) error {
 	result, err := h.webhookService.TestWebhook(c.Context(), webhookID)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error":   "Failed to send test payload",
-			"details": err.Error(),
+			"error": "Failed to send test payload",
 		})
 	}
 
@@ -310,6 +425,7 @@ func (h *WebhookHandler) TestWebhook(c fiber.Ctx) error {
 			"action":      "test",
 			"status_code": result.StatusCode,
 			"success":     result.Success,
+			"latency_ms":  result.Latency.Milliseconds(),
 		},
 	)
 
@@ -322,7 +438,9 @@ func (h *WebhookHandler) TestWebhook(c fiber.Ctx) error {
 	return c.JSON(fiber.Map{
 		"success":       result.Success,
 		"message":       message,
+		"event":         result.Event,
 		"response_code": result.StatusCode,
+		"latency_ms":    result.Latency.Milliseconds(),
 		"webhook": fiber.Map{
 			"id":   webhook.ID,
 			"name": webhook.Name,
@@ -330,3 +448,16 @@ func (h *WebhookHandler) TestWebhook(c fiber.Ctx) error {
 		},
 	})
 }
+
+// webhookErrorStatus maps subscription and URL validation errors to 400 and anything else to 500
+func webhookErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, application.ErrWebhookNoEvents),
+		errors.Is(err, application.ErrInvalidWebhookEvent),
+		errors.Is(err, application.ErrInvalidWebhookResourceType),
+		errors.Is(err, application.ErrInvalidWebhookURL):
+		return fiber.StatusBadRequest
+	default:
+		return fiber.StatusInternalServerError
+	}
+}
@@ -0,0 +1,28 @@
+package middleware
+
+import (
+	"github.com/gofiber/fiber/v3"
+	"github.com/google/uuid"
+)
+
+// AgentSelfMiddleware limits agents authenticated with their Ed25519 credentials to their own
+// resources: the route's :id parameter must be the authenticated agent, otherwise the request is
+// rejected with 403. Users and API keys pass through to the handler's organization checks.
+// Must be used AFTER Ed25519AgentMiddleware.
+func AgentSelfMiddleware() fiber.Handler {
+	return func(c fiber.Ctx) error {
+		if c.Locals("auth_method") != "ed25519" {
+			return c.Next()
+		}
+
+		agentID, ok := c.Locals("agent_id").(uuid.UUID)
+		requestedID, err := uuid.Parse(c.Params("id"))
+		if !ok || err != nil || requestedID != agentID {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+				"error": "Agents may only access their own resources",
+			})
+		}
+
+		return c.Next()
+	}
+}
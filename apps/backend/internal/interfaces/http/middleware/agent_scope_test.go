@@ -0,0 +1,103 @@
+package middleware
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/application"
+	"github.com/opena2a/identity/backend/internal/domain"
+	"github.com/opena2a/identity/backend/internal/infrastructure/auth"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// agentScopeTestRepo serves agents by ID; Ed25519AgentMiddleware needs nothing else
+type agentScopeTestRepo struct {
+	domain.AgentRepository
+	agents map[uuid.UUID]*domain.Agent
+}
+
+func (r *agentScopeTestRepo) GetByID(id uuid.UUID) (*domain.Agent, error) {
+	if agent, ok := r.agents[id]; ok {
+		return agent, nil
+	}
+	return nil, errors.New("agent not found")
+}
+
+func newAgentScopeTestAgent(t *testing.T, orgID uuid.UUID) (*domain.Agent, ed25519.PrivateKey) {
+	t.Helper()
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	encoded := base64.StdEncoding.EncodeToString(publicKey)
+	return &domain.Agent{ID: uuid.New(), OrganizationID: orgID, Status: domain.AgentStatusVerified, PublicKey: &encoded}, privateKey
+}
+
+// newAgentScopeTestApp wires the SDK download route the same way setupRoutes does
+func newAgentScopeTestApp(t *testing.T, agents ...*domain.Agent) *fiber.App {
+	t.Helper()
+	t.Setenv("JWT_SECRET", "agent-scope-test-secret")
+	repo := &agentScopeTestRepo{agents: make(map[uuid.UUID]*domain.Agent)}
+	for _, agent := range agents {
+		repo.agents[agent.ID] = agent
+	}
+	agentService := application.NewAgentService(repo, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil)
+
+	app := fiber.New()
+	group := app.Group("/api/v1/agents")
+	group.Use(Ed25519AgentMiddleware(agentService))
+	group.Use(AuthMiddleware(auth.NewJWTService()))
+	group.Get("/:id/sdk", func(c fiber.Ctx) error { return c.SendStatus(fiber.StatusOK) }, AgentSelfMiddleware())
+	return app
+}
+
+// signedSDKDownload requests targetID's SDK signed as signer
+func signedSDKDownload(t *testing.T, app *fiber.App, signer *domain.Agent, key ed25519.PrivateKey, targetID uuid.UUID) int {
+	t.Helper()
+	path := "/api/v1/agents/" + targetID.String() + "/sdk"
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	signature := ed25519.Sign(key, []byte(strings.Join([]string{fiber.MethodGet, path, timestamp}, "\n")))
+
+	req := httptest.NewRequest(fiber.MethodGet, path+"?lang=go", nil)
+	req.Header.Set("X-Agent-ID", signer.ID.String())
+	req.Header.Set("X-Signature", base64.StdEncoding.EncodeToString(signature))
+	req.Header.Set("X-Timestamp", timestamp)
+	req.Header.Set("X-Public-Key", *signer.PublicKey)
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	return resp.StatusCode
+}
+
+func TestAgentSelfMiddleware_AgentDownloadsOwnSDK(t *testing.T) {
+	agent, key := newAgentScopeTestAgent(t, uuid.New())
+	app := newAgentScopeTestApp(t, agent)
+
+	assert.Equal(t, fiber.StatusOK, signedSDKDownload(t, app, agent, key, agent.ID))
+}
+
+func TestAgentSelfMiddleware_AgentCannotDownloadAnotherAgentsSDK(t *testing.T) {
+	orgID := uuid.New()
+	agent, key := newAgentScopeTestAgent(t, orgID)
+	sibling, _ := newAgentScopeTestAgent(t, orgID)
+	app := newAgentScopeTestApp(t, agent, sibling)
+
+	// Same organization, but the signature only authenticates the caller itself
+	assert.Equal(t, fiber.StatusForbidden, signedSDKDownload(t, app, agent, key, sibling.ID))
+}
+
+func TestAgentSelfMiddleware_UnsignedRequestStillNeedsJWT(t *testing.T) {
+	agent, _ := newAgentScopeTestAgent(t, uuid.New())
+	app := newAgentScopeTestApp(t, agent)
+
+	resp, err := app.Test(httptest.NewRequest(fiber.MethodGet, "/api/v1/agents/"+agent.ID.String()+"/sdk", nil))
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusUnauthorized, resp.StatusCode)
+}
@@ -9,8 +9,13 @@ import (
 
 	"github.com/gofiber/fiber/v3"
 	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/opena2a/identity/backend/internal/domain"
 )
 
+// APIKeyPrefix is the prefix of every API key issued by APIKeyService
+const APIKeyPrefix = "aim_"
+
 // APIKeyMiddleware validates API keys from Authorization header or X-API-Key header
 // Used for SDK authentication and direct API calls
 func APIKeyMiddleware(db *sql.DB) fiber.Handler {
@@ -63,16 +68,21 @@ func APIKeyMiddleware(db *sql.DB) fiber.Handler {
 		Name           string     `db:"name"`
 		IsActive       bool       `db:"is_active"`
 		ExpiresAt      *time.Time `db:"expires_at"`
+		Scopes         []string   `db:"scopes"`
+		Role           *string    `db:"role"`
 	}
 
 	query := `
-		SELECT ak.id, ak.organization_id, ak.agent_id, ak.created_by as user_id, ak.name, ak.is_active, ak.expires_at
+		SELECT ak.id, ak.organization_id, ak.agent_id, ak.created_by as user_id, ak.name, ak.is_active, ak.expires_at,
+		       ak.scopes, u.role
 		FROM api_keys ak
+		JOIN users u ON u.id = ak.created_by
 		WHERE ak.key_hash = $1
+		  AND u.status = $2 AND u.deleted_at IS NULL -- keys die with their creator's account
 		LIMIT 1
 	`
 
-	err := db.QueryRow(query, keyHash).Scan(
+	err := db.QueryRow(query, keyHash, domain.UserStatusActive).Scan(
 		&keyData.ID,
 		&keyData.OrganizationID,
 		&keyData.AgentID,
@@ -80,6 +90,8 @@ func APIKeyMiddleware(db *sql.DB) fiber.Handler {
 		&keyData.Name,
 		&keyData.IsActive,
 		&keyData.ExpiresAt,
+		pq.Array(&keyData.Scopes),
+		&keyData.Role,
 	)
 
 		if err != nil {
@@ -95,10 +107,10 @@ func APIKeyMiddleware(db *sql.DB) fiber.Handler {
 			})
 		}
 
-		// Check if key is expired
-		if keyData.ExpiresAt != nil && keyData.ExpiresAt.Before(time.Now()) {
+		// Expired keys are invalid even before the expiry sweep disables them
+		if apiKeyExpired(keyData.ExpiresAt, time.Now()) {
 			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-				"error": "API key has expired",
+				"error": "Invalid API key",
 			})
 		}
 
@@ -112,6 +124,11 @@ func APIKeyMiddleware(db *sql.DB) fiber.Handler {
 	c.Locals("agent_id", keyData.AgentID)
 	c.Locals("user_id", keyData.UserID) // ✅ Set user_id for capability requests
 	c.Locals("auth_method", "api_key")
+	c.Locals("authenticated_via", "api_key") // AuthMiddleware skips JWT validation
+	c.Locals("api_key_scopes", keyData.Scopes)
+	if keyData.Role != nil {
+		c.Locals("role", *keyData.Role) // API keys act with their creator's role
+	}
 
 	return c.Next()
 	}
@@ -137,8 +154,9 @@ func OptionalAPIKeyMiddleware(db *sql.DB) fiber.Handler {
 			apiKey = c.Get("X-API-Key")
 		}
 
-		// If no API key, continue without setting context
-		if apiKey == "" {
+		// If no API key, continue without setting context. Bearer tokens that are not
+		// API keys (e.g. JWTs) are left for AuthMiddleware.
+		if !strings.HasPrefix(apiKey, APIKeyPrefix) {
 			return c.Next()
 		}
 
@@ -155,16 +173,21 @@ func OptionalAPIKeyMiddleware(db *sql.DB) fiber.Handler {
 		Name           string     `db:"name"`
 		IsActive       bool       `db:"is_active"`
 		ExpiresAt      *time.Time `db:"expires_at"`
+		Scopes         []string   `db:"scopes"`
+		Role           *string    `db:"role"`
 	}
 
 	query := `
-		SELECT ak.id, ak.organization_id, ak.agent_id, ak.created_by as user_id, ak.name, ak.is_active, ak.expires_at
+		SELECT ak.id, ak.organization_id, ak.agent_id, ak.created_by as user_id, ak.name, ak.is_active, ak.expires_at,
+		       ak.scopes, u.role
 		FROM api_keys ak
+		JOIN users u ON u.id = ak.created_by
 		WHERE ak.key_hash = $1
+		  AND u.status = $2 AND u.deleted_at IS NULL -- keys die with their creator's account
 		LIMIT 1
 	`
 
-	err := db.QueryRow(query, keyHash).Scan(
+	err := db.QueryRow(query, keyHash, domain.UserStatusActive).Scan(
 		&keyData.ID,
 		&keyData.OrganizationID,
 		&keyData.AgentID,
@@ -172,6 +195,8 @@ func OptionalAPIKeyMiddleware(db *sql.DB) fiber.Handler {
 		&keyData.Name,
 		&keyData.IsActive,
 		&keyData.ExpiresAt,
+		pq.Array(&keyData.Scopes),
+		&keyData.Role,
 	)
 
 		// If key not found or invalid, continue without auth
@@ -180,7 +205,7 @@ func OptionalAPIKeyMiddleware(db *sql.DB) fiber.Handler {
 		}
 
 		// Check if key is active and not expired
-		if !keyData.IsActive || (keyData.ExpiresAt != nil && keyData.ExpiresAt.Before(time.Now())) {
+		if !keyData.IsActive || apiKeyExpired(keyData.ExpiresAt, time.Now()) {
 			return c.Next()
 		}
 
@@ -194,7 +219,17 @@ func OptionalAPIKeyMiddleware(db *sql.DB) fiber.Handler {
 	c.Locals("agent_id", keyData.AgentID)
 	c.Locals("user_id", keyData.UserID)
 	c.Locals("auth_method", "api_key")
+	c.Locals("authenticated_via", "api_key") // AuthMiddleware skips JWT validation
+	c.Locals("api_key_scopes", keyData.Scopes)
+	if keyData.Role != nil {
+		c.Locals("role", *keyData.Role) // API keys act with their creator's role
+	}
 
 	return c.Next()
 	}
 }
+
+// apiKeyExpired reports whether a key with the given expiry is no longer valid at now
+func apiKeyExpired(expiresAt *time.Time, now time.Time) bool {
+	return expiresAt != nil && !expiresAt.After(now)
+}
@@ -0,0 +1,54 @@
+package middleware
+
+import (
+	"strings"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/opena2a/identity/backend/internal/domain"
+)
+
+// apiKeyScopeResources maps the first path segment after /api/v1/ to the resource part of a scope
+var apiKeyScopeResources = map[string]string{
+	"agents":        "agents",
+	"mcp-servers":   "mcp_servers",
+	"verifications": "verifications",
+}
+
+// RequiredAPIKeyScope returns the scope an API key needs to call method on path, e.g.
+// "agents:read" for GET /api/v1/agents/:id. Returns "" for routes no scope grants access to.
+func RequiredAPIKeyScope(method, path string) string {
+	segments := strings.Split(strings.TrimPrefix(path, "/api/v1/"), "/")
+	resource, ok := apiKeyScopeResources[segments[0]]
+	if !ok {
+		return ""
+	}
+
+	switch method {
+	case fiber.MethodGet, fiber.MethodHead, fiber.MethodOptions:
+		return resource + ":read"
+	default:
+		return resource + ":write"
+	}
+}
+
+// APIKeyScopeMiddleware rejects API-key requests whose key lacks the scope required by the
+// route with 403. Keys without scopes may only read. Requests authenticated any other way pass
+// through. Must be used AFTER OptionalAPIKeyMiddleware.
+func APIKeyScopeMiddleware() fiber.Handler {
+	return func(c fiber.Ctx) error {
+		if c.Locals("auth_method") != "api_key" {
+			return c.Next()
+		}
+
+		scopes, _ := c.Locals("api_key_scopes").([]string)
+		required := RequiredAPIKeyScope(c.Method(), c.Path())
+		if required == "" || !domain.APIKeyScopesAllow(scopes, required) {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+				"error":         "API key does not have the required scope",
+				"requiredScope": required,
+			})
+		}
+
+		return c.Next()
+	}
+}
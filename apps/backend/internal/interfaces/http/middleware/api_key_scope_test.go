@@ -0,0 +1,91 @@
+package middleware
+
+import (
+	"net/http/httptest"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gofiber/fiber/v3"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newAPIKeyScopeTestApp wires the API key middlewares in front of agent routes the same way
+// setupRoutes does. Every request is authenticated as a key with the given scopes.
+func newAPIKeyScopeTestApp(t *testing.T, scopes string) *fiber.App {
+	t.Helper()
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	for i := 0; i < 2; i++ {
+		mock.ExpectQuery(regexp.QuoteMeta("FROM api_keys ak")).
+			WillReturnRows(sqlmock.NewRows(apiKeyLookupColumns).
+				AddRow(uuid.New(), uuid.New(), uuid.New(), uuid.New(), "monitoring", true, nil, scopes, "member"))
+		mock.ExpectExec(regexp.QuoteMeta("UPDATE api_keys SET last_used_at")).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+	}
+
+	app := fiber.New()
+	app.Use(OptionalAPIKeyMiddleware(db))
+	app.Use(APIKeyScopeMiddleware())
+	app.Get("/api/v1/agents", func(c fiber.Ctx) error { return c.SendStatus(fiber.StatusOK) })
+	// Fiber v3 runs route middleware before the handler passed first
+	app.Post("/api/v1/agents", func(c fiber.Ctx) error { return c.SendStatus(fiber.StatusCreated) }, MemberMiddleware())
+	return app
+}
+
+func sendWithAPIKey(t *testing.T, app *fiber.App, method, path string) int {
+	t.Helper()
+	req := httptest.NewRequest(method, path, nil)
+	req.Header.Set("X-API-Key", "aim_live_test-key")
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	return resp.StatusCode
+}
+
+func TestAPIKeyScopeMiddleware_ReadOnlyKey(t *testing.T) {
+	app := newAPIKeyScopeTestApp(t, "{agents:read}")
+
+	assert.Equal(t, fiber.StatusOK, sendWithAPIKey(t, app, fiber.MethodGet, "/api/v1/agents"))
+	assert.Equal(t, fiber.StatusForbidden, sendWithAPIKey(t, app, fiber.MethodPost, "/api/v1/agents"))
+}
+
+func TestAPIKeyScopeMiddleware_WriteScopeGrantsRead(t *testing.T) {
+	app := newAPIKeyScopeTestApp(t, "{agents:write}")
+
+	assert.Equal(t, fiber.StatusOK, sendWithAPIKey(t, app, fiber.MethodGet, "/api/v1/agents"))
+	assert.Equal(t, fiber.StatusCreated, sendWithAPIKey(t, app, fiber.MethodPost, "/api/v1/agents"))
+}
+
+func TestAPIKeyScopeMiddleware_UnscopedKeyIsReadOnly(t *testing.T) {
+	app := newAPIKeyScopeTestApp(t, "{}")
+
+	assert.Equal(t, fiber.StatusOK, sendWithAPIKey(t, app, fiber.MethodGet, "/api/v1/agents"))
+	assert.Equal(t, fiber.StatusForbidden, sendWithAPIKey(t, app, fiber.MethodPost, "/api/v1/agents"))
+}
+
+func TestAPIKeyScopeMiddleware_IgnoresOtherAuthMethods(t *testing.T) {
+	app := fiber.New()
+	app.Use(func(c fiber.Ctx) error {
+		c.Locals("auth_method", "jwt")
+		c.Locals("api_key_scopes", []string{"agents:read"})
+		return c.Next()
+	})
+	app.Use(APIKeyScopeMiddleware())
+	app.Post("/api/v1/agents", func(c fiber.Ctx) error { return c.SendStatus(fiber.StatusCreated) })
+
+	resp, err := app.Test(httptest.NewRequest(fiber.MethodPost, "/api/v1/agents", nil))
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusCreated, resp.StatusCode)
+}
+
+func TestRequiredAPIKeyScope(t *testing.T) {
+	assert.Equal(t, "agents:read", RequiredAPIKeyScope(fiber.MethodGet, "/api/v1/agents/123/key-vault"))
+	assert.Equal(t, "agents:write", RequiredAPIKeyScope(fiber.MethodDelete, "/api/v1/agents/123"))
+	assert.Equal(t, "mcp_servers:write", RequiredAPIKeyScope(fiber.MethodPut, "/api/v1/mcp-servers/abc"))
+	assert.Equal(t, "verifications:read", RequiredAPIKeyScope(fiber.MethodGet, "/api/v1/verifications/abc"))
+	assert.Empty(t, RequiredAPIKeyScope(fiber.MethodGet, "/api/v1/webhooks"))
+}
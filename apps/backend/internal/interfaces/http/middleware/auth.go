@@ -20,6 +20,10 @@ func AuthMiddleware(jwtService *auth.JWTService) fiber.Handler {
 			fmt.Printf("✅ JWT middleware: Skipping JWT - Ed25519 already authenticated\n")
 			return c.Next()
 		}
+		if authenticatedVia == "api_key" {
+			// Already authenticated by OptionalAPIKeyMiddleware - scopes are enforced by APIKeyScopeMiddleware
+			return c.Next()
+		}
 
 		// Try to get token from Authorization header first
 		authHeader := c.Get("Authorization")
@@ -8,6 +8,7 @@ import (
 )
 
 // CORSMiddleware configures CORS for the application
+// An origin may use a wildcard subdomain, e.g. https://*.example.com
 func CORSMiddleware(allowedOrigins []string) fiber.Handler {
 	return cors.New(cors.Config{
 		AllowOrigins:     strings.Join(allowedOrigins, ","),
@@ -17,3 +18,16 @@ func CORSMiddleware(allowedOrigins []string) fiber.Handler {
 		MaxAge:           3600,
 	})
 }
+
+// ParseAllowedOrigins splits a comma-separated origins list such as the ALLOWED_ORIGINS
+// environment variable, trimming whitespace and trailing slashes and skipping empty entries
+func ParseAllowedOrigins(value string) []string {
+	var origins []string
+	for _, origin := range strings.Split(value, ",") {
+		origin = strings.TrimSuffix(strings.TrimSpace(origin), "/")
+		if origin != "" {
+			origins = append(origins, origin)
+		}
+	}
+	return origins
+}
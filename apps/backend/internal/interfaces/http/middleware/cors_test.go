@@ -0,0 +1,58 @@
+package middleware
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newCORSTestApp(allowedOrigins []string) *fiber.App {
+	app := fiber.New()
+	app.Use(CORSMiddleware(allowedOrigins))
+	app.Get("/", func(c fiber.Ctx) error {
+		return c.SendStatus(fiber.StatusOK)
+	})
+	return app
+}
+
+// allowedOrigin returns the Access-Control-Allow-Origin the app sends back for origin
+func allowedOrigin(t *testing.T, app *fiber.App, origin string) string {
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set("Origin", origin)
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	return resp.Header.Get("Access-Control-Allow-Origin")
+}
+
+func TestParseAllowedOrigins(t *testing.T) {
+	assert.Equal(t,
+		[]string{"https://app.example.com", "https://admin.example.org", "https://*.example.net"},
+		ParseAllowedOrigins(" https://app.example.com, https://admin.example.org/ ,,https://*.example.net"))
+	assert.Empty(t, ParseAllowedOrigins(""))
+	assert.Empty(t, ParseAllowedOrigins(" , "))
+}
+
+func TestCORSMiddleware_MultipleOrigins(t *testing.T) {
+	app := newCORSTestApp(ParseAllowedOrigins("https://app.example.com,https://dashboard.example.org"))
+
+	assert.Equal(t, "https://app.example.com", allowedOrigin(t, app, "https://app.example.com"))
+	assert.Equal(t, "https://dashboard.example.org", allowedOrigin(t, app, "https://dashboard.example.org"))
+	assert.Empty(t, allowedOrigin(t, app, "https://evil.example.com"))
+}
+
+func TestCORSMiddleware_WildcardSubdomain(t *testing.T) {
+	app := newCORSTestApp(ParseAllowedOrigins("http://localhost:3000, https://*.example.com"))
+
+	assert.Equal(t, "https://eu.example.com", allowedOrigin(t, app, "https://eu.example.com"))
+	assert.Equal(t, "https://a.b.example.com", allowedOrigin(t, app, "https://a.b.example.com"))
+	assert.Equal(t, "http://localhost:3000", allowedOrigin(t, app, "http://localhost:3000"))
+
+	// The apex domain, other schemes and look-alike domains are not covered by the wildcard
+	assert.Empty(t, allowedOrigin(t, app, "https://example.com"))
+	assert.Empty(t, allowedOrigin(t, app, "http://eu.example.com"))
+	assert.Empty(t, allowedOrigin(t, app, "https://eu.notexample.com"))
+	assert.Empty(t, allowedOrigin(t, app, "https://example.com.evil.io"))
+}
@@ -0,0 +1,224 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/infrastructure/logging"
+)
+
+const (
+	// HeaderIdempotencyKey is the request header carrying the client's idempotency key
+	HeaderIdempotencyKey = "Idempotency-Key"
+	// HeaderIdempotentReplayed marks responses replayed from an earlier request
+	HeaderIdempotentReplayed = "Idempotent-Replayed"
+
+	// DefaultIdempotencyTTL is how long processed keys are remembered
+	DefaultIdempotencyTTL = 24 * time.Hour
+
+	maxIdempotencyKeyLength = 255
+)
+
+// IdempotencyStore remembers processed idempotency keys.
+// *cache.RedisCache implements it so keys are shared across server instances.
+type IdempotencyStore interface {
+	// ClaimIdempotencyKey stores record under key unless the key is already
+	// taken, in which case it returns the record stored by the earlier request
+	ClaimIdempotencyKey(ctx context.Context, key string, record []byte, ttl time.Duration) (existing []byte, claimed bool, err error)
+	SaveIdempotencyRecord(ctx context.Context, key string, record []byte, ttl time.Duration) error
+	ReleaseIdempotencyKey(ctx context.Context, key string) error
+}
+
+// MemoryIdempotencyStore is an in-process IdempotencyStore, used when Redis is unavailable
+type MemoryIdempotencyStore struct {
+	mu      sync.Mutex
+	records map[string]memoryIdempotencyRecord
+	now     func() time.Time
+}
+
+type memoryIdempotencyRecord struct {
+	record    []byte
+	expiresAt time.Time
+}
+
+// NewMemoryIdempotencyStore creates an empty in-memory idempotency store
+func NewMemoryIdempotencyStore() *MemoryIdempotencyStore {
+	return &MemoryIdempotencyStore{records: make(map[string]memoryIdempotencyRecord), now: time.Now}
+}
+
+// ClaimIdempotencyKey implements IdempotencyStore
+func (s *MemoryIdempotencyStore) ClaimIdempotencyKey(ctx context.Context, key string, record []byte, ttl time.Duration) ([]byte, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	if existing, ok := s.records[key]; ok && now.Before(existing.expiresAt) {
+		return existing.record, false, nil
+	}
+
+	// Drop expired keys while we hold the lock so the map doesn't grow unbounded
+	for k, r := range s.records {
+		if !now.Before(r.expiresAt) {
+			delete(s.records, k)
+		}
+	}
+	s.records[key] = memoryIdempotencyRecord{record: record, expiresAt: now.Add(ttl)}
+	return nil, true, nil
+}
+
+// SaveIdempotencyRecord implements IdempotencyStore
+func (s *MemoryIdempotencyStore) SaveIdempotencyRecord(ctx context.Context, key string, record []byte, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.records[key] = memoryIdempotencyRecord{record: record, expiresAt: s.now().Add(ttl)}
+	return nil
+}
+
+// ReleaseIdempotencyKey implements IdempotencyStore
+func (s *MemoryIdempotencyStore) ReleaseIdempotencyKey(ctx context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.records, key)
+	return nil
+}
+
+// idempotencyRecord is what gets stored per key: the request fingerprint and,
+// once the request completes, the response to replay
+type idempotencyRecord struct {
+	Fingerprint string `json:"fingerprint"`
+	Completed   bool   `json:"completed"`
+	Status      int    `json:"status,omitempty"`
+	ContentType string `json:"content_type,omitempty"`
+	Body        []byte `json:"body,omitempty"`
+}
+
+// IdempotencyMiddleware makes a POST endpoint safe to retry. When a request
+// carries an Idempotency-Key header, the first response for that key is stored
+// for ttl and returned for any repeat instead of processing the request again.
+// Reusing a key with a different body is rejected with 422, and a repeat that
+// arrives while the first request is still running gets 409. Responses with a
+// 5xx status are not stored, so the client can retry them.
+//
+// store may be nil, in which case (and whenever the store errors) keys are
+// tracked in memory.
+func IdempotencyMiddleware(store IdempotencyStore, ttl time.Duration) fiber.Handler {
+	fallback := NewMemoryIdempotencyStore()
+
+	return func(c fiber.Ctx) error {
+		idempotencyKey := c.Get(HeaderIdempotencyKey)
+		if idempotencyKey == "" {
+			return c.Next()
+		}
+		if len(idempotencyKey) > maxIdempotencyKeyLength {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": fmt.Sprintf("%s must be at most %d characters", HeaderIdempotencyKey, maxIdempotencyKeyLength),
+			})
+		}
+
+		ctx := c.Context()
+		logger := logging.FromContext(ctx)
+		key := idempotencyScope(c) + ":" + idempotencyKey
+		fingerprint := requestFingerprint(c)
+		pending, _ := json.Marshal(idempotencyRecord{Fingerprint: fingerprint})
+
+		active := store
+		if active == nil {
+			active = fallback
+		}
+		existing, claimed, err := active.ClaimIdempotencyKey(ctx, key, pending, ttl)
+		if err != nil {
+			logger.Warn("idempotency store unavailable, using in-memory keys", "error", err)
+			active = fallback
+			existing, claimed, _ = active.ClaimIdempotencyKey(ctx, key, pending, ttl)
+		}
+		if !claimed {
+			return replayIdempotentResponse(c, existing, fingerprint)
+		}
+
+		handlerErr := c.Next()
+
+		status := c.Response().StatusCode()
+		if handlerErr != nil || status >= fiber.StatusInternalServerError {
+			if err := active.ReleaseIdempotencyKey(ctx, key); err != nil {
+				logger.Warn("failed to release idempotency key", "error", err)
+			}
+			return handlerErr
+		}
+
+		completed, _ := json.Marshal(idempotencyRecord{
+			Fingerprint: fingerprint,
+			Completed:   true,
+			Status:      status,
+			ContentType: string(c.Response().Header.ContentType()),
+			Body:        bytes.Clone(c.Response().Body()),
+		})
+		if err := active.SaveIdempotencyRecord(ctx, key, completed, ttl); err != nil {
+			logger.Warn("failed to store idempotent response", "error", err)
+		}
+		return nil
+	}
+}
+
+// idempotencyScope namespaces keys by route and caller so two tenants that
+// happen to pick the same key don't see each other's responses. Signed SDK
+// requests are not authenticated before the handler runs; they are scoped by
+// the agent named in the body, whose signature the handler then checks.
+func idempotencyScope(c fiber.Ctx) string {
+	caller := "anonymous"
+	if agentID, ok := c.Locals("agent_id").(uuid.UUID); ok {
+		caller = "agent:" + agentID.String()
+	} else if orgID, ok := c.Locals("organization_id").(uuid.UUID); ok {
+		caller = "org:" + orgID.String()
+	} else if agentID := signedRequestAgentID(c); agentID != "" {
+		caller = "signed-agent:" + agentID
+	}
+	return c.Method() + ":" + c.Path() + ":" + caller
+}
+
+// signedRequestAgentID returns the agent_id of a JSON request body, or "" if there is none
+func signedRequestAgentID(c fiber.Ctx) string {
+	var body struct {
+		AgentID string `json:"agent_id"`
+	}
+	if json.Unmarshal(c.Body(), &body) != nil {
+		return ""
+	}
+	return body.AgentID
+}
+
+// requestFingerprint hashes the request body so a reused key with a different payload can be detected
+func requestFingerprint(c fiber.Ctx) string {
+	sum := sha256.Sum256(c.Body())
+	return hex.EncodeToString(sum[:])
+}
+
+// replayIdempotentResponse answers a repeat request from the record stored by the first one
+func replayIdempotentResponse(c fiber.Ctx, existing []byte, fingerprint string) error {
+	var previous idempotencyRecord
+	if existing != nil && json.Unmarshal(existing, &previous) == nil {
+		if previous.Fingerprint != fingerprint {
+			return c.Status(fiber.StatusUnprocessableEntity).JSON(fiber.Map{
+				"error": "Idempotency-Key was already used with a different request body",
+			})
+		}
+		if previous.Completed {
+			c.Set(HeaderIdempotentReplayed, "true")
+			if previous.ContentType != "" {
+				c.Set(fiber.HeaderContentType, previous.ContentType)
+			}
+			return c.Status(previous.Status).Send(previous.Body)
+		}
+	}
+
+	return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+		"error": "A request with this Idempotency-Key is still being processed",
+	})
+}
@@ -0,0 +1,217 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// verificationRecorder stands in for the verification handler: every call it
+// processes creates one verification event
+type verificationRecorder struct {
+	mu     sync.Mutex
+	events []string
+	status int
+}
+
+func (r *verificationRecorder) handle(c fiber.Ctx) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	id := uuid.New().String()
+	r.events = append(r.events, id)
+	status := r.status
+	if status == 0 {
+		status = fiber.StatusCreated
+	}
+	return c.Status(status).JSON(fiber.Map{"id": id, "status": "approved"})
+}
+
+func newIdempotencyTestApp(store IdempotencyStore, recorder *verificationRecorder) *fiber.App {
+	app := fiber.New()
+	app.Use(fakeAuth)
+	// Fiber v3 runs route middleware before the handler passed first
+	app.Post("/api/v1/verifications", recorder.handle, IdempotencyMiddleware(store, time.Hour))
+	return app
+}
+
+func postVerification(t *testing.T, app *fiber.App, key, body string) (int, string, string) {
+	t.Helper()
+	req := httptest.NewRequest("POST", "/api/v1/verifications", strings.NewReader(body))
+	req.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+	if key != "" {
+		req.Header.Set(HeaderIdempotencyKey, key)
+	}
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	respBody, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	return resp.StatusCode, string(respBody), resp.Header.Get(HeaderIdempotentReplayed)
+}
+
+func TestIdempotency_RepeatKeyCreatesOneVerificationEvent(t *testing.T) {
+	recorder := &verificationRecorder{}
+	app := newIdempotencyTestApp(nil, recorder)
+	body := `{"agent_id":"a1","action_type":"read_file"}`
+
+	status1, body1, replayed1 := postVerification(t, app, "retry-1", body)
+	status2, body2, replayed2 := postVerification(t, app, "retry-1", body)
+
+	assert.Equal(t, fiber.StatusCreated, status1)
+	assert.Equal(t, fiber.StatusCreated, status2)
+	assert.Equal(t, body1, body2, "repeat should return the original response")
+	assert.Empty(t, replayed1)
+	assert.Equal(t, "true", replayed2)
+	assert.Len(t, recorder.events, 1)
+}
+
+func TestIdempotency_DifferentKeysAndNoKeyAreProcessed(t *testing.T) {
+	recorder := &verificationRecorder{}
+	app := newIdempotencyTestApp(nil, recorder)
+	body := `{"agent_id":"a1","action_type":"read_file"}`
+
+	postVerification(t, app, "key-a", body)
+	postVerification(t, app, "key-b", body)
+	postVerification(t, app, "", body)
+	postVerification(t, app, "", body)
+
+	assert.Len(t, recorder.events, 4)
+}
+
+func TestIdempotency_KeyReusedWithDifferentBody(t *testing.T) {
+	recorder := &verificationRecorder{}
+	app := newIdempotencyTestApp(nil, recorder)
+
+	postVerification(t, app, "retry-1", `{"action_type":"read_file"}`)
+	status, _, _ := postVerification(t, app, "retry-1", `{"action_type":"delete_file"}`)
+
+	assert.Equal(t, fiber.StatusUnprocessableEntity, status)
+	assert.Len(t, recorder.events, 1)
+}
+
+func TestIdempotency_KeysAreScopedPerCaller(t *testing.T) {
+	recorder := &verificationRecorder{}
+	app := newIdempotencyTestApp(nil, recorder)
+	body := `{"action_type":"read_file"}`
+
+	for _, orgID := range []string{uuid.New().String(), uuid.New().String()} {
+		req := httptest.NewRequest("POST", "/api/v1/verifications", strings.NewReader(body))
+		req.Header.Set(HeaderIdempotencyKey, "shared-key")
+		req.Header.Set("X-Test-Org", orgID)
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		assert.Empty(t, resp.Header.Get(HeaderIdempotentReplayed))
+	}
+
+	assert.Len(t, recorder.events, 2)
+}
+
+func TestIdempotency_UnauthenticatedKeysAreScopedPerSigningAgent(t *testing.T) {
+	// The SDK verification route authenticates agents from the signed body, after this middleware
+	recorder := &verificationRecorder{}
+	app := newIdempotencyTestApp(nil, recorder)
+
+	for _, agentID := range []string{uuid.New().String(), uuid.New().String()} {
+		status, _, replayed := postVerification(t, app, "shared-key", `{"agent_id":"`+agentID+`","action_type":"read_file"}`)
+		assert.Equal(t, fiber.StatusCreated, status)
+		assert.Empty(t, replayed)
+	}
+
+	assert.Len(t, recorder.events, 2)
+}
+
+func TestIdempotency_ServerErrorsAreNotStored(t *testing.T) {
+	recorder := &verificationRecorder{status: fiber.StatusInternalServerError}
+	app := newIdempotencyTestApp(nil, recorder)
+	body := `{"action_type":"read_file"}`
+
+	postVerification(t, app, "retry-1", body)
+	recorder.status = fiber.StatusCreated
+	status, _, replayed := postVerification(t, app, "retry-1", body)
+
+	assert.Equal(t, fiber.StatusCreated, status)
+	assert.Empty(t, replayed)
+	assert.Len(t, recorder.events, 2)
+}
+
+func TestIdempotency_RequestStillInFlight(t *testing.T) {
+	store := NewMemoryIdempotencyStore()
+	recorder := &verificationRecorder{}
+	app := newIdempotencyTestApp(store, recorder)
+	body := `{"action_type":"read_file"}`
+
+	// Simulate a first request that claimed the key but hasn't finished
+	pending := `{"fingerprint":"` + fingerprintOf(body) + `"}`
+	_, claimed, err := store.ClaimIdempotencyKey(context.Background(), "POST:/api/v1/verifications:anonymous:retry-1", []byte(pending), time.Hour)
+	require.NoError(t, err)
+	require.True(t, claimed)
+
+	status, _, _ := postVerification(t, app, "retry-1", body)
+	assert.Equal(t, fiber.StatusConflict, status)
+	assert.Empty(t, recorder.events)
+}
+
+// failingIdempotencyStore simulates Redis being unreachable
+type failingIdempotencyStore struct{}
+
+func (failingIdempotencyStore) ClaimIdempotencyKey(ctx context.Context, key string, record []byte, ttl time.Duration) ([]byte, bool, error) {
+	return nil, false, errors.New("connection refused")
+}
+
+func (failingIdempotencyStore) SaveIdempotencyRecord(ctx context.Context, key string, record []byte, ttl time.Duration) error {
+	return errors.New("connection refused")
+}
+
+func (failingIdempotencyStore) ReleaseIdempotencyKey(ctx context.Context, key string) error {
+	return errors.New("connection refused")
+}
+
+func TestIdempotency_FallsBackToMemoryWhenStoreFails(t *testing.T) {
+	recorder := &verificationRecorder{}
+	app := newIdempotencyTestApp(failingIdempotencyStore{}, recorder)
+	body := `{"action_type":"read_file"}`
+
+	postVerification(t, app, "retry-1", body)
+	status, _, replayed := postVerification(t, app, "retry-1", body)
+
+	assert.Equal(t, fiber.StatusCreated, status)
+	assert.Equal(t, "true", replayed)
+	assert.Len(t, recorder.events, 1)
+}
+
+func TestMemoryIdempotencyStore_KeysExpire(t *testing.T) {
+	now := time.Date(2025, 6, 1, 9, 0, 0, 0, time.UTC)
+	store := NewMemoryIdempotencyStore()
+	store.now = func() time.Time { return now }
+
+	_, claimed, _ := store.ClaimIdempotencyKey(context.Background(), "k", []byte("first"), time.Hour)
+	require.True(t, claimed)
+
+	existing, claimed, _ := store.ClaimIdempotencyKey(context.Background(), "k", []byte("second"), time.Hour)
+	assert.False(t, claimed)
+	assert.Equal(t, "first", string(existing))
+
+	now = now.Add(time.Hour)
+	_, claimed, _ = store.ClaimIdempotencyKey(context.Background(), "k", []byte("third"), time.Hour)
+	assert.True(t, claimed)
+}
+
+func fingerprintOf(body string) string {
+	app := fiber.New()
+	var fingerprint string
+	app.Post("/", func(c fiber.Ctx) error {
+		fingerprint = requestFingerprint(c)
+		return nil
+	})
+	_, _ = app.Test(httptest.NewRequest("POST", "/", strings.NewReader(body)))
+	return fingerprint
+}
@@ -1,17 +1,85 @@
 package middleware
 
 import (
+	"context"
+	"log/slog"
 	"time"
 
 	"github.com/gofiber/fiber/v3"
-	"github.com/gofiber/fiber/v3/middleware/logger"
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/infrastructure/logging"
 )
 
-// LoggerMiddleware configures request logging
+// HeaderRequestID is the header used to propagate request IDs
+const HeaderRequestID = "X-Request-ID"
+
+// maxRequestIDLength caps client-supplied request IDs so they can't bloat logs
+const maxRequestIDLength = 128
+
+// RequestIDMiddleware assigns every request an ID, honoring a client-supplied
+// X-Request-ID when present. The ID is echoed in the response header, stored in
+// c.Locals("request_id") and attached to the request context together with a
+// request-scoped logger, so services can use logging.FromContext(c.Context()).
+func RequestIDMiddleware() fiber.Handler {
+	return func(c fiber.Ctx) error {
+		requestID := c.Get(HeaderRequestID)
+		if requestID == "" || len(requestID) > maxRequestIDLength {
+			requestID = uuid.New().String()
+		}
+
+		logger := slog.Default().With("request_id", requestID)
+
+		c.Locals("request_id", requestID)
+		c.Set(HeaderRequestID, requestID)
+
+		// Handlers pass c.Context() to services, so store the values on the
+		// fasthttp context; UserContext() gets them too for code that uses it.
+		c.Context().SetUserValue(logging.RequestIDKey, requestID)
+		c.Context().SetUserValue(logging.LoggerKey, logger)
+		userCtx := context.WithValue(c.UserContext(), logging.RequestIDKey, requestID)
+		c.SetUserContext(context.WithValue(userCtx, logging.LoggerKey, logger))
+
+		return c.Next()
+	}
+}
+
+// LoggerMiddleware emits one structured JSON log line per request
 func LoggerMiddleware() fiber.Handler {
-	return logger.New(logger.Config{
-		Format:     "[${time}] ${status} - ${latency} ${method} ${path}\n",
-		TimeFormat: time.RFC3339,
-		TimeZone:   "UTC",
-	})
+	return func(c fiber.Ctx) error {
+		start := time.Now()
+		err := c.Next()
+
+		status := c.Response().StatusCode()
+		if err != nil {
+			if fiberErr, ok := err.(*fiber.Error); ok {
+				status = fiberErr.Code
+			} else {
+				status = fiber.StatusInternalServerError
+			}
+		}
+
+		attrs := []any{
+			"method", c.Method(),
+			"path", c.Path(),
+			"status", status,
+			"duration_ms", float64(time.Since(start).Microseconds()) / 1000,
+		}
+		if orgID := c.Locals("organization_id"); orgID != nil {
+			attrs = append(attrs, "org_id", orgID)
+		}
+		if agentID := c.Locals("agent_id"); agentID != nil {
+			attrs = append(attrs, "agent_id", agentID)
+		}
+
+		level := slog.LevelInfo
+		switch {
+		case status >= fiber.StatusInternalServerError:
+			level = slog.LevelError
+		case status >= fiber.StatusBadRequest:
+			level = slog.LevelWarn
+		}
+
+		logging.FromContext(c.Context()).Log(c.Context(), level, "request completed", attrs...)
+		return err
+	}
 }
@@ -0,0 +1,89 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/infrastructure/logging"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequestIDMiddleware_InjectsRequestIDDownstream(t *testing.T) {
+	var localID, contextID, userContextID string
+	app := fiber.New()
+	app.Use(RequestIDMiddleware())
+	app.Get("/", func(c fiber.Ctx) error {
+		localID, _ = c.Locals("request_id").(string)
+		contextID = logging.RequestIDFromContext(c.Context())
+		userContextID = logging.RequestIDFromContext(c.UserContext())
+		return c.SendStatus(fiber.StatusOK)
+	})
+
+	resp, err := app.Test(httptest.NewRequest("GET", "/", nil))
+	require.NoError(t, err)
+
+	requestID := resp.Header.Get(HeaderRequestID)
+	_, err = uuid.Parse(requestID)
+	require.NoError(t, err, "generated request ID should be a UUID")
+	assert.Equal(t, requestID, localID)
+	assert.Equal(t, requestID, contextID)
+	assert.Equal(t, requestID, userContextID)
+}
+
+func TestRequestIDMiddleware_HonorsClientRequestID(t *testing.T) {
+	var contextID string
+	app := fiber.New()
+	app.Use(RequestIDMiddleware())
+	app.Get("/", func(c fiber.Ctx) error {
+		contextID = logging.RequestIDFromContext(c.Context())
+		return c.SendStatus(fiber.StatusOK)
+	})
+
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set(HeaderRequestID, "trace-123")
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+
+	assert.Equal(t, "trace-123", resp.Header.Get(HeaderRequestID))
+	assert.Equal(t, "trace-123", contextID)
+}
+
+func TestLoggerMiddleware_EmitsStructuredJSON(t *testing.T) {
+	var buf bytes.Buffer
+	previous := slog.Default()
+	slog.SetDefault(slog.New(slog.NewJSONHandler(&buf, nil)))
+	defer slog.SetDefault(previous)
+
+	orgID := uuid.New()
+	agentID := uuid.New()
+	app := fiber.New()
+	app.Use(RequestIDMiddleware())
+	app.Use(LoggerMiddleware())
+	app.Use(fakeAuth)
+	app.Get("/agents", func(c fiber.Ctx) error {
+		return c.SendStatus(fiber.StatusNotFound)
+	})
+
+	req := httptest.NewRequest("GET", "/agents", nil)
+	req.Header.Set("X-Test-Org", orgID.String())
+	req.Header.Set("X-Test-Agent", agentID.String())
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+
+	var entry map[string]any
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &entry))
+	assert.Equal(t, "WARN", entry["level"])
+	assert.Equal(t, "GET", entry["method"])
+	assert.Equal(t, "/agents", entry["path"])
+	assert.Equal(t, float64(fiber.StatusNotFound), entry["status"])
+	assert.Contains(t, entry, "duration_ms")
+	assert.Equal(t, orgID.String(), entry["org_id"])
+	assert.Equal(t, agentID.String(), entry["agent_id"])
+	assert.Equal(t, resp.Header.Get(HeaderRequestID), entry["request_id"])
+}
@@ -1,6 +1,11 @@
 package middleware
 
 import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"sync"
 	"time"
 
 	"github.com/gofiber/fiber/v3"
@@ -8,13 +13,77 @@ import (
 	"github.com/google/uuid"
 )
 
+// Headers reporting the caller's quota on every rate-limited response
+const (
+	headerRateLimitLimit     = "X-RateLimit-Limit"
+	headerRateLimitRemaining = "X-RateLimit-Remaining"
+	headerRateLimitReset     = "X-RateLimit-Reset"
+)
+
+// rateLimitQuotaKey is the request local holding the tightest quota reported so far
+const rateLimitQuotaKey = "rate_limit_quota"
+
+// rateLimitQuota is one limiter's view of the caller's quota. reset is in seconds.
+type rateLimitQuota struct {
+	limit     int
+	remaining int
+	reset     int
+}
+
+// reportRateLimit sets the X-RateLimit-* headers. Routes stack several limiters, so the quota
+// with the fewest remaining requests wins and clients back off on the limit they will hit first.
+func reportRateLimit(c fiber.Ctx, quota rateLimitQuota) {
+	if prev, ok := c.Locals(rateLimitQuotaKey).(rateLimitQuota); ok && prev.remaining <= quota.remaining {
+		quota = prev
+	}
+	c.Locals(rateLimitQuotaKey, quota)
+	c.Set(headerRateLimitLimit, strconv.Itoa(quota.limit))
+	c.Set(headerRateLimitRemaining, strconv.Itoa(quota.remaining))
+	c.Set(headerRateLimitReset, strconv.Itoa(quota.reset))
+}
+
+// rateLimitExceeded writes the 429 response shared by all limiters
+func rateLimitExceeded(c fiber.Ctx) error {
+	return c.Status(fiber.StatusTooManyRequests).JSON(fiber.Map{
+		"error": "Rate limit exceeded. Please try again later.",
+	})
+}
+
+// fixedWindowRateLimit wraps the fiber limiter so its quota is reported through reportRateLimit.
+// The limiter only sets the X-RateLimit-* headers on success, after the rest of the chain ran.
+func fixedWindowRateLimit(cfg limiter.Config) fiber.Handler {
+	cfg.LimitReached = func(c fiber.Ctx) error {
+		reset, _ := strconv.Atoi(c.GetRespHeader(fiber.HeaderRetryAfter))
+		reportRateLimit(c, rateLimitQuota{limit: cfg.Max, remaining: 0, reset: reset})
+		return rateLimitExceeded(c)
+	}
+	handler := limiter.New(cfg)
+
+	return func(c fiber.Ctx) error {
+		err := handler(c)
+
+		// Fold the headers the limiter just wrote back in with any inner limiter's quota
+		remaining, convErr := strconv.Atoi(c.GetRespHeader(headerRateLimitRemaining))
+		if convErr != nil {
+			return err
+		}
+		limit, _ := strconv.Atoi(c.GetRespHeader(headerRateLimitLimit))
+		reset, _ := strconv.Atoi(c.GetRespHeader(headerRateLimitReset))
+		reportRateLimit(c, rateLimitQuota{limit: limit, remaining: remaining, reset: reset})
+		return err
+	}
+}
+
 // RateLimitMiddleware implements rate limiting
 func RateLimitMiddleware() fiber.Handler {
-	return limiter.New(limiter.Config{
-		Max:        100,                  // 100 requests
-		Expiration: 1 * time.Minute,      // per minute
+	return fixedWindowRateLimit(limiter.Config{
+		Max:        100,             // 100 requests
+		Expiration: 1 * time.Minute, // per minute
 		KeyGenerator: func(c fiber.Ctx) string {
-			// Rate limit by user if authenticated, otherwise by IP
+			// Rate limit by agent (Ed25519) or user if authenticated, otherwise by IP
+			if agentID, ok := c.Locals("agent_id").(uuid.UUID); ok {
+				return "agent:" + agentID.String()
+			}
 			if userID := c.Locals("user_id"); userID != nil {
 				if id, ok := userID.(uuid.UUID); ok {
 					return id.String()
@@ -22,19 +91,14 @@ func RateLimitMiddleware() fiber.Handler {
 			}
 			return c.IP()
 		},
-		LimitReached: func(c fiber.Ctx) error {
-			return c.Status(fiber.StatusTooManyRequests).JSON(fiber.Map{
-				"error": "Rate limit exceeded. Please try again later.",
-			})
-		},
 	})
 }
 
 // StrictRateLimitMiddleware implements stricter rate limiting for sensitive endpoints
 func StrictRateLimitMiddleware() fiber.Handler {
-	return limiter.New(limiter.Config{
-		Max:        10,                   // 10 requests
-		Expiration: 1 * time.Minute,      // per minute
+	return fixedWindowRateLimit(limiter.Config{
+		Max:        10,              // 10 requests
+		Expiration: 1 * time.Minute, // per minute
 		KeyGenerator: func(c fiber.Ctx) string {
 			if userID := c.Locals("user_id"); userID != nil {
 				if id, ok := userID.(uuid.UUID); ok {
@@ -43,10 +107,185 @@ func StrictRateLimitMiddleware() fiber.Handler {
 			}
 			return c.IP()
 		},
-		LimitReached: func(c fiber.Ctx) error {
-			return c.Status(fiber.StatusTooManyRequests).JSON(fiber.Map{
-				"error": "Rate limit exceeded. Please try again later.",
-			})
-		},
 	})
 }
+
+// TokenBucketStore takes tokens from named token buckets. Each bucket holds up
+// to capacity tokens and refills completely over window. TakeToken returns
+// whether a token was taken and how many (possibly fractional) tokens are left.
+// *cache.RedisCache implements it for limits shared across server instances.
+type TokenBucketStore interface {
+	TakeToken(ctx context.Context, key string, capacity int, window time.Duration, now time.Time) (allowed bool, tokens float64, err error)
+}
+
+// memoryBucketSweepInterval is how often MemoryTokenBucketStore drops idle buckets
+const memoryBucketSweepInterval = time.Minute
+
+// MemoryTokenBucketStore is an in-process TokenBucketStore, used when Redis is unavailable.
+// A bucket left idle for its whole window has refilled completely, so it is dropped and
+// recreated full on the next request; the map only holds recently active keys.
+type MemoryTokenBucketStore struct {
+	mu        sync.Mutex
+	buckets   map[string]*tokenBucket
+	lastSweep time.Time
+}
+
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+	window time.Duration
+}
+
+// NewMemoryTokenBucketStore creates an empty in-memory token bucket store
+func NewMemoryTokenBucketStore() *MemoryTokenBucketStore {
+	return &MemoryTokenBucketStore{buckets: make(map[string]*tokenBucket)}
+}
+
+// TakeToken implements TokenBucketStore
+func (s *MemoryTokenBucketStore) TakeToken(ctx context.Context, key string, capacity int, window time.Duration, now time.Time) (bool, float64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.sweep(now)
+
+	rate := float64(capacity) / float64(window) // tokens per nanosecond
+	bucket, ok := s.buckets[key]
+	if !ok {
+		bucket = &tokenBucket{tokens: float64(capacity), last: now}
+		s.buckets[key] = bucket
+	}
+	bucket.window = window
+	if elapsed := now.Sub(bucket.last); elapsed > 0 {
+		bucket.tokens = math.Min(float64(capacity), bucket.tokens+float64(elapsed)*rate)
+		bucket.last = now
+	}
+
+	if bucket.tokens >= 1 {
+		bucket.tokens--
+		return true, bucket.tokens, nil
+	}
+	return false, bucket.tokens, nil
+}
+
+// sweep drops buckets idle for at least their window, at most once per memoryBucketSweepInterval.
+// The caller must hold s.mu.
+func (s *MemoryTokenBucketStore) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < memoryBucketSweepInterval {
+		return
+	}
+	s.lastSweep = now
+
+	for key, bucket := range s.buckets {
+		if now.Sub(bucket.last) >= bucket.window {
+			delete(s.buckets, key)
+		}
+	}
+}
+
+// RateLimiter enforces a token bucket per agent for Ed25519-authenticated
+// requests and per organization for JWT-authenticated requests, so one noisy
+// agent or tenant cannot exhaust the quota of the others
+type RateLimiter struct {
+	agentRequests int
+	orgRequests   int
+	window        time.Duration
+	store         TokenBucketStore
+	fallback      *MemoryTokenBucketStore
+	now           func() time.Time
+}
+
+// NewRateLimiter creates a rate limiter. store may be nil, in which case (and
+// whenever the store errors) limits are tracked in memory.
+func NewRateLimiter(agentRequests, orgRequests int, window time.Duration, store TokenBucketStore) *RateLimiter {
+	return &RateLimiter{
+		agentRequests: agentRequests,
+		orgRequests:   orgRequests,
+		window:        window,
+		store:         store,
+		fallback:      NewMemoryTokenBucketStore(),
+		now:           time.Now,
+	}
+}
+
+// take consumes a token from the bucket for key, falling back to memory when the store fails.
+// It returns whether the request is allowed and the tokens left in the bucket.
+func (rl *RateLimiter) take(ctx context.Context, key string, capacity int) (bool, float64) {
+	now := rl.now()
+	if rl.store != nil {
+		allowed, tokens, err := rl.store.TakeToken(ctx, key, capacity, rl.window, now)
+		if err == nil {
+			return allowed, tokens
+		}
+		fmt.Printf("⚠️  Warning: rate limit store unavailable, using in-memory limits: %v\n", err)
+	}
+	allowed, tokens, _ := rl.fallback.TakeToken(ctx, key, capacity, rl.window, now)
+	return allowed, tokens
+}
+
+// ceilSeconds rounds a duration up to whole seconds
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
+
+// scopedRateLimitChargedKey is the request local recording which bucket was charged
+const scopedRateLimitChargedKey = "rate_limit_charged_key"
+
+// ChargeAgent applies the per-agent limit to a request whose agent the handler authenticated
+// itself, e.g. from a signed request body, rather than through Ed25519AgentMiddleware. When the
+// agent is over its limit it answers 429 and returns false.
+func (rl *RateLimiter) ChargeAgent(c fiber.Ctx, agentID uuid.UUID) (bool, error) {
+	return rl.charge(c, "agent:"+agentID.String(), rl.agentRequests)
+}
+
+// charge takes a token from key's bucket and reports the quota. When the bucket is empty it
+// answers 429 and returns false.
+func (rl *RateLimiter) charge(c fiber.Ctx, key string, capacity int) (bool, error) {
+	// Groups sharing a path prefix run each other's middleware; charge a key once per request
+	if capacity <= 0 || c.Locals(scopedRateLimitChargedKey) == key {
+		return true, nil
+	}
+	c.Locals(scopedRateLimitChargedKey, key)
+
+	allowed, tokens := rl.take(c.Context(), key, capacity)
+
+	// Remaining counts whole tokens; the bucket is back to its full limit at reset
+	perToken := rl.window / time.Duration(capacity)
+	reportRateLimit(c, rateLimitQuota{
+		limit:     capacity,
+		remaining: int(math.Floor(tokens)),
+		reset:     ceilSeconds(time.Duration((float64(capacity) - tokens) * float64(perToken))),
+	})
+
+	if !allowed {
+		seconds := ceilSeconds(time.Duration((1 - tokens) * float64(perToken)))
+		if seconds < 1 {
+			seconds = 1
+		}
+		c.Set(fiber.HeaderRetryAfter, strconv.Itoa(seconds))
+		return false, rateLimitExceeded(c)
+	}
+
+	return true, nil
+}
+
+// ScopedRateLimitMiddleware applies the per-agent limit to Ed25519-authenticated
+// requests and the per-organization limit to other authenticated requests.
+// Must be used AFTER the authentication middleware.
+func ScopedRateLimitMiddleware(rl *RateLimiter) fiber.Handler {
+	return func(c fiber.Ctx) error {
+		var key string
+		var capacity int
+		if agentID, ok := c.Locals("agent_id").(uuid.UUID); ok && c.Locals("auth_method") == "ed25519" {
+			key, capacity = "agent:"+agentID.String(), rl.agentRequests
+		} else if orgID, ok := c.Locals("organization_id").(uuid.UUID); ok {
+			key, capacity = "org:"+orgID.String(), rl.orgRequests
+		} else {
+			return c.Next()
+		}
+
+		if allowed, err := rl.charge(c, key, capacity); !allowed {
+			return err
+		}
+		return c.Next()
+	}
+}
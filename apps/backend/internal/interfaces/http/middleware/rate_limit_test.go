@@ -0,0 +1,319 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeAuth sets the locals the Ed25519 and JWT middleware would set, based on test headers
+func fakeAuth(c fiber.Ctx) error {
+	if agentID := c.Get("X-Test-Agent"); agentID != "" {
+		c.Locals("agent_id", uuid.MustParse(agentID))
+		c.Locals("auth_method", "ed25519")
+	}
+	if orgID := c.Get("X-Test-Org"); orgID != "" {
+		c.Locals("organization_id", uuid.MustParse(orgID))
+	}
+	return c.Next()
+}
+
+func newRateLimitTestApp(rl *RateLimiter) *fiber.App {
+	app := fiber.New()
+	app.Use(fakeAuth)
+	app.Use(ScopedRateLimitMiddleware(rl))
+	app.Get("/", func(c fiber.Ctx) error {
+		return c.SendStatus(fiber.StatusOK)
+	})
+	return app
+}
+
+func doRateLimitRequest(t *testing.T, app *fiber.App, header, id string) (int, string) {
+	t.Helper()
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set(header, id)
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	return resp.StatusCode, resp.Header.Get(fiber.HeaderRetryAfter)
+}
+
+func TestScopedRateLimit_AgentCapDoesNotThrottleOtherAgents(t *testing.T) {
+	orgID := uuid.New()
+	agentA := uuid.New().String()
+	agentB := uuid.New().String()
+	app := newRateLimitTestApp(NewRateLimiter(2, 100, time.Minute, nil))
+
+	for i := 0; i < 2; i++ {
+		status, _ := doRateLimitRequest(t, app, "X-Test-Agent", agentA)
+		assert.Equal(t, fiber.StatusOK, status)
+	}
+
+	status, retryAfter := doRateLimitRequest(t, app, "X-Test-Agent", agentA)
+	assert.Equal(t, fiber.StatusTooManyRequests, status)
+	assert.Equal(t, "30", retryAfter) // One token refills every 30s at 2 per minute
+
+	// Agent B in the same organization still has its full quota
+	for i := 0; i < 2; i++ {
+		req := httptest.NewRequest("GET", "/", nil)
+		req.Header.Set("X-Test-Agent", agentB)
+		req.Header.Set("X-Test-Org", orgID.String())
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+	}
+}
+
+func TestScopedRateLimit_OrgCapDoesNotThrottleOtherOrgs(t *testing.T) {
+	orgA := uuid.New().String()
+	orgB := uuid.New().String()
+	app := newRateLimitTestApp(NewRateLimiter(100, 1, time.Minute, nil))
+
+	status, _ := doRateLimitRequest(t, app, "X-Test-Org", orgA)
+	assert.Equal(t, fiber.StatusOK, status)
+	status, retryAfter := doRateLimitRequest(t, app, "X-Test-Org", orgA)
+	assert.Equal(t, fiber.StatusTooManyRequests, status)
+	assert.Equal(t, "60", retryAfter)
+
+	status, _ = doRateLimitRequest(t, app, "X-Test-Org", orgB)
+	assert.Equal(t, fiber.StatusOK, status)
+}
+
+func TestScopedRateLimit_ChargesOncePerRequest(t *testing.T) {
+	rl := NewRateLimiter(2, 100, time.Minute, nil)
+	app := fiber.New()
+	app.Use(fakeAuth)
+	// Two groups sharing a prefix both apply the limiter
+	app.Use(ScopedRateLimitMiddleware(rl))
+	app.Use(ScopedRateLimitMiddleware(rl))
+	app.Get("/", func(c fiber.Ctx) error {
+		return c.SendStatus(fiber.StatusOK)
+	})
+
+	agentID := uuid.New().String()
+	for i := 0; i < 2; i++ {
+		status, _ := doRateLimitRequest(t, app, "X-Test-Agent", agentID)
+		assert.Equal(t, fiber.StatusOK, status)
+	}
+	status, _ := doRateLimitRequest(t, app, "X-Test-Agent", agentID)
+	assert.Equal(t, fiber.StatusTooManyRequests, status)
+}
+
+func TestScopedRateLimit_UnauthenticatedPassesThrough(t *testing.T) {
+	app := newRateLimitTestApp(NewRateLimiter(1, 1, time.Minute, nil))
+
+	for i := 0; i < 3; i++ {
+		resp, err := app.Test(httptest.NewRequest("GET", "/", nil))
+		require.NoError(t, err)
+		assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+	}
+}
+
+func TestMemoryTokenBucketStore_Refill(t *testing.T) {
+	store := NewMemoryTokenBucketStore()
+	ctx := context.Background()
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	for i := 0; i < 3; i++ {
+		allowed, _, _ := store.TakeToken(ctx, "agent:a", 3, 3*time.Second, now)
+		assert.True(t, allowed)
+	}
+	allowed, tokens, _ := store.TakeToken(ctx, "agent:a", 3, 3*time.Second, now)
+	assert.False(t, allowed)
+	assert.Zero(t, tokens)
+
+	// One token refills per second
+	allowed, _, _ = store.TakeToken(ctx, "agent:a", 3, 3*time.Second, now.Add(time.Second))
+	assert.True(t, allowed)
+	allowed, _, _ = store.TakeToken(ctx, "agent:a", 3, 3*time.Second, now.Add(time.Second))
+	assert.False(t, allowed)
+	_, tokens, _ = store.TakeToken(ctx, "agent:a", 3, 3*time.Second, now.Add(1500*time.Millisecond))
+	assert.InDelta(t, 0.5, tokens, 1e-9)
+
+	// The bucket never holds more than its capacity
+	later := now.Add(time.Hour)
+	for i := 0; i < 3; i++ {
+		allowed, _, _ = store.TakeToken(ctx, "agent:a", 3, 3*time.Second, later)
+		assert.True(t, allowed)
+	}
+	allowed, _, _ = store.TakeToken(ctx, "agent:a", 3, 3*time.Second, later)
+	assert.False(t, allowed)
+}
+
+func TestMemoryTokenBucketStore_EvictsIdleBuckets(t *testing.T) {
+	store := NewMemoryTokenBucketStore()
+	ctx := context.Background()
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	for i := 0; i < 100; i++ {
+		store.TakeToken(ctx, fmt.Sprintf("agent:%d", i), 3, time.Second, now)
+	}
+	store.TakeToken(ctx, "org:busy", 3, time.Hour, now)
+	assert.Len(t, store.buckets, 101)
+
+	// Buckets idle for their whole window are full again and are dropped on the next sweep
+	later := now.Add(memoryBucketSweepInterval)
+	allowed, tokens, _ := store.TakeToken(ctx, "agent:0", 3, time.Second, later)
+	assert.True(t, allowed)
+	assert.Equal(t, 2.0, tokens)
+	assert.Len(t, store.buckets, 2)
+	assert.Contains(t, store.buckets, "org:busy")
+}
+
+type failingTokenBucketStore struct{}
+
+func (failingTokenBucketStore) TakeToken(ctx context.Context, key string, capacity int, window time.Duration, now time.Time) (bool, float64, error) {
+	return false, 0, errors.New("redis: connection refused")
+}
+
+func TestRateLimiter_FallsBackToMemoryWhenStoreFails(t *testing.T) {
+	app := newRateLimitTestApp(NewRateLimiter(1, 100, time.Minute, failingTokenBucketStore{}))
+	agentID := uuid.New().String()
+
+	status, _ := doRateLimitRequest(t, app, "X-Test-Agent", agentID)
+	assert.Equal(t, fiber.StatusOK, status)
+	status, _ = doRateLimitRequest(t, app, "X-Test-Agent", agentID)
+	assert.Equal(t, fiber.StatusTooManyRequests, status)
+}
+
+func rateLimitHeaders(resp *http.Response) (limit, remaining, reset string) {
+	return resp.Header.Get("X-RateLimit-Limit"), resp.Header.Get("X-RateLimit-Remaining"), resp.Header.Get("X-RateLimit-Reset")
+}
+
+func TestScopedRateLimit_HeadersDecrementAndReset(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	rl := NewRateLimiter(2, 100, time.Minute, nil)
+	rl.now = func() time.Time { return now }
+	app := newRateLimitTestApp(rl)
+	agentID := uuid.New().String()
+
+	get := func() *http.Response {
+		req := httptest.NewRequest("GET", "/", nil)
+		req.Header.Set("X-Test-Agent", agentID)
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		return resp
+	}
+
+	resp := get()
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+	limit, remaining, reset := rateLimitHeaders(resp)
+	assert.Equal(t, "2", limit)
+	assert.Equal(t, "1", remaining)
+	assert.Equal(t, "30", reset) // One token to refill at 2 per minute
+
+	resp = get()
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+	_, remaining, reset = rateLimitHeaders(resp)
+	assert.Equal(t, "0", remaining)
+	assert.Equal(t, "60", reset)
+
+	resp = get()
+	assert.Equal(t, fiber.StatusTooManyRequests, resp.StatusCode)
+	limit, remaining, reset = rateLimitHeaders(resp)
+	assert.Equal(t, "2", limit)
+	assert.Equal(t, "0", remaining)
+	assert.Equal(t, "60", reset)
+	assert.Equal(t, "30", resp.Header.Get(fiber.HeaderRetryAfter))
+
+	// After the reset the bucket is full again
+	now = now.Add(time.Minute)
+	resp = get()
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+	_, remaining, reset = rateLimitHeaders(resp)
+	assert.Equal(t, "1", remaining)
+	assert.Equal(t, "30", reset)
+}
+
+func TestRateLimitMiddleware_HeadersOnSuccessAndLimitReached(t *testing.T) {
+	app := fiber.New()
+	app.Use(StrictRateLimitMiddleware())
+	app.Get("/", func(c fiber.Ctx) error {
+		return c.SendStatus(fiber.StatusOK)
+	})
+
+	for i := 1; i <= 10; i++ {
+		resp, err := app.Test(httptest.NewRequest("GET", "/", nil))
+		require.NoError(t, err)
+		assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+		limit, remaining, reset := rateLimitHeaders(resp)
+		assert.Equal(t, "10", limit)
+		assert.Equal(t, strconv.Itoa(10-i), remaining)
+		assert.NotEmpty(t, reset)
+	}
+
+	resp, err := app.Test(httptest.NewRequest("GET", "/", nil))
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusTooManyRequests, resp.StatusCode)
+	limit, remaining, reset := rateLimitHeaders(resp)
+	assert.Equal(t, "10", limit)
+	assert.Equal(t, "0", remaining)
+	assert.Equal(t, resp.Header.Get(fiber.HeaderRetryAfter), reset)
+}
+
+func TestRateLimitHeaders_StackedLimitersReportTightestQuota(t *testing.T) {
+	app := fiber.New()
+	app.Use(fakeAuth)
+	app.Use(RateLimitMiddleware())
+	app.Use(ScopedRateLimitMiddleware(NewRateLimiter(2, 100, time.Minute, nil)))
+	app.Get("/", func(c fiber.Ctx) error {
+		return c.SendStatus(fiber.StatusOK)
+	})
+	agentID := uuid.New().String()
+
+	get := func() *http.Response {
+		req := httptest.NewRequest("GET", "/", nil)
+		req.Header.Set("X-Test-Agent", agentID)
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		return resp
+	}
+
+	// The per-agent bucket is tighter than the 100/minute fixed window
+	resp := get()
+	limit, remaining, _ := rateLimitHeaders(resp)
+	assert.Equal(t, "2", limit)
+	assert.Equal(t, "1", remaining)
+
+	get()
+	resp = get()
+	assert.Equal(t, fiber.StatusTooManyRequests, resp.StatusCode)
+	limit, remaining, _ = rateLimitHeaders(resp)
+	assert.Equal(t, "2", limit)
+	assert.Equal(t, "0", remaining)
+}
+
+func TestRateLimiter_ChargeAgentSharesTheAgentBucket(t *testing.T) {
+	rl := NewRateLimiter(2, 100, time.Minute, nil)
+	agentID := uuid.New()
+
+	// A handler that authenticates the agent itself charges the bucket the middleware uses
+	app := newRateLimitTestApp(rl)
+	app.Post("/verifications", func(c fiber.Ctx) error {
+		if allowed, err := rl.ChargeAgent(c, agentID); !allowed {
+			return err
+		}
+		return c.SendStatus(fiber.StatusCreated)
+	})
+
+	resp, err := app.Test(httptest.NewRequest("POST", "/verifications", nil))
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusCreated, resp.StatusCode)
+	assert.Equal(t, "1", resp.Header.Get(headerRateLimitRemaining))
+
+	status, _ := doRateLimitRequest(t, app, "X-Test-Agent", agentID.String())
+	assert.Equal(t, fiber.StatusOK, status)
+
+	resp, err = app.Test(httptest.NewRequest("POST", "/verifications", nil))
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusTooManyRequests, resp.StatusCode)
+}
@@ -2,11 +2,11 @@ package middleware
 
 import (
 	"fmt"
-	"log"
 	"runtime/debug"
 
 	"github.com/gofiber/fiber/v3"
 	"github.com/gofiber/fiber/v3/middleware/recover"
+	"github.com/opena2a/identity/backend/internal/infrastructure/logging"
 )
 
 // RecoveryMiddleware recovers from panics
@@ -14,14 +14,13 @@ func RecoveryMiddleware() fiber.Handler {
 	return recover.New(recover.Config{
 		EnableStackTrace: true,
 		StackTraceHandler: func(c fiber.Ctx, e interface{}) {
-			log.Printf("\n========== PANIC RECOVERED ==========\n")
-			log.Printf("Error: %v\n", e)
-			log.Printf("Path: %s\n", c.Path())
-			log.Printf("Method: %s\n", c.Method())
-			log.Printf("\nStack Trace:\n%s\n", debug.Stack())
-			log.Printf("=====================================\n\n")
+			logging.FromContext(c.Context()).Error("panic recovered",
+				"error", fmt.Sprintf("%v", e),
+				"method", c.Method(),
+				"path", c.Path(),
+				"stack", string(debug.Stack()),
+			)
 
-			// Also log to a more structured format
 			c.Locals("panic_error", fmt.Sprintf("%v", e))
 		},
 	})
@@ -0,0 +1,66 @@
+package middleware
+
+import (
+	"strings"
+	"time"
+
+	"github.com/valyala/fasthttp"
+)
+
+// RouteLimits overrides the server's timeouts and request body limit for the routes matching
+// Path. Path segments starting with ":" match any single segment, and a trailing "/*" matches
+// the route and everything below it, e.g. "/api/v1/agents/:id/sdk" or "/api/v1/sdk/*".
+// Zero values keep the server defaults.
+type RouteLimits struct {
+	Path         string
+	ReadTimeout  time.Duration
+	WriteTimeout time.Duration
+	BodyLimit    int // bytes
+}
+
+// RouteLimitsHook returns a fasthttp HeaderReceived hook that applies the first entry of limits
+// whose path matches the request. It runs once the headers are read, so an oversized body is
+// rejected with 413 before it is read and a long write timeout covers the whole response.
+func RouteLimitsHook(limits []RouteLimits) func(header *fasthttp.RequestHeader) fasthttp.RequestConfig {
+	return func(header *fasthttp.RequestHeader) fasthttp.RequestConfig {
+		path := string(header.RequestURI())
+		if i := strings.IndexByte(path, '?'); i >= 0 {
+			path = path[:i]
+		}
+
+		for _, limit := range limits {
+			if matchRoutePath(limit.Path, path) {
+				return fasthttp.RequestConfig{
+					ReadTimeout:        limit.ReadTimeout,
+					WriteTimeout:       limit.WriteTimeout,
+					MaxRequestBodySize: limit.BodyLimit,
+				}
+			}
+		}
+		return fasthttp.RequestConfig{}
+	}
+}
+
+// matchRoutePath reports whether path matches pattern. Like the router, it ignores case and a
+// trailing slash.
+func matchRoutePath(pattern, path string) bool {
+	prefix := strings.HasSuffix(pattern, "/*")
+	patternSegments := strings.Split(strings.Trim(strings.TrimSuffix(pattern, "/*"), "/"), "/")
+	pathSegments := strings.Split(strings.Trim(path, "/"), "/")
+
+	if len(pathSegments) < len(patternSegments) || (!prefix && len(pathSegments) != len(patternSegments)) {
+		return false
+	}
+	for i, segment := range patternSegments {
+		if strings.HasPrefix(segment, ":") {
+			if pathSegments[i] == "" {
+				return false
+			}
+			continue
+		}
+		if !strings.EqualFold(segment, pathSegments[i]) {
+			return false
+		}
+	}
+	return true
+}
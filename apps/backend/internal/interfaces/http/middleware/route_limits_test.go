@@ -0,0 +1,108 @@
+package middleware
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// listenRouteLimitsTestApp serves app on a local port and returns its base URL. Unlike
+// app.Test, a real connection honors the server's deadlines and answers oversized bodies.
+func listenRouteLimitsTestApp(t *testing.T, app *fiber.App) string {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	go app.Listener(ln, fiber.ListenConfig{DisableStartupMessage: true})
+	t.Cleanup(func() { app.Shutdown() })
+	return "http://" + ln.Addr().String()
+}
+
+func TestMatchRoutePath(t *testing.T) {
+	tests := []struct {
+		pattern string
+		path    string
+		want    bool
+	}{
+		{"/api/v1/agents/bulk", "/api/v1/agents/bulk", true},
+		{"/api/v1/agents/bulk", "/API/v1/Agents/Bulk/", true},
+		{"/api/v1/agents/bulk", "/api/v1/agents", false},
+		{"/api/v1/agents/bulk", "/api/v1/agents/bulk/extra", false},
+		{"/api/v1/agents/:id/sdk", "/api/v1/agents/0b0c5c4e-4f7d-4c51-9d4f-0c2a2d3f6a11/sdk", true},
+		{"/api/v1/agents/:id/sdk", "/api/v1/agents//sdk", false},
+		{"/api/v1/sdk/*", "/api/v1/sdk", true},
+		{"/api/v1/sdk/*", "/api/v1/sdk/download", true},
+		{"/api/v1/sdk/*", "/api/v1/sdk-api/verifications", false},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, matchRoutePath(tt.pattern, tt.path), "%s ~ %s", tt.pattern, tt.path)
+	}
+}
+
+func TestRouteLimitsHook_RejectsOversizedBulkBody(t *testing.T) {
+	app := fiber.New(fiber.Config{BodyLimit: 1024})
+	app.Server().HeaderReceived = RouteLimitsHook([]RouteLimits{{Path: "/agents/bulk", BodyLimit: 4096}})
+	app.Post("/agents/bulk", func(c fiber.Ctx) error { return c.SendStatus(fiber.StatusOK) })
+	app.Post("/agents", func(c fiber.Ctx) error { return c.SendStatus(fiber.StatusOK) })
+
+	baseURL := listenRouteLimitsTestApp(t, app)
+
+	post := func(path string, size int) int {
+		resp, err := http.Post(baseURL+path, "application/json", bytes.NewReader(make([]byte, size)))
+		require.NoError(t, err)
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	// The bulk route takes bodies beyond the default limit, up to its own
+	assert.Equal(t, fiber.StatusOK, post("/agents/bulk?atomic=true", 2048))
+	assert.Equal(t, fiber.StatusRequestEntityTooLarge, post("/agents/bulk", 8192))
+	// Other routes keep the default
+	assert.Equal(t, fiber.StatusRequestEntityTooLarge, post("/agents", 2048))
+}
+
+func TestRouteLimitsHook_SlowExportIsNotCutOff(t *testing.T) {
+	app := fiber.New(fiber.Config{WriteTimeout: 50 * time.Millisecond})
+	app.Server().HeaderReceived = RouteLimitsHook([]RouteLimits{{Path: "/compliance/export", WriteTimeout: 5 * time.Second}})
+	slowExport := func(c fiber.Ctx) error {
+		c.Response().SetBodyStreamWriter(func(w *bufio.Writer) {
+			for i := 0; i < 5; i++ {
+				w.WriteString("row\n")
+				w.Flush()
+				time.Sleep(30 * time.Millisecond)
+			}
+		})
+		return nil
+	}
+	app.Get("/compliance/export", slowExport)
+	app.Get("/agents/export", slowExport)
+
+	baseURL := listenRouteLimitsTestApp(t, app)
+
+	get := func(path string) (string, error) {
+		resp, err := http.Get(baseURL + path)
+		if err != nil {
+			return "", err
+		}
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		return string(body), err
+	}
+
+	body, err := get("/compliance/export")
+	require.NoError(t, err)
+	assert.Equal(t, strings.Repeat("row\n", 5), body)
+
+	// The same response on a route with the default write timeout is cut off mid-stream
+	_, err = get("/agents/export")
+	assert.Error(t, err)
+}
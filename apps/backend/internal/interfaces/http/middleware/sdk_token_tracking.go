@@ -1,60 +1,197 @@
 package middleware
 
 import (
+	"context"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/gofiber/fiber/v3"
-	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
 	"github.com/opena2a/identity/backend/internal/domain"
+	"github.com/opena2a/identity/backend/internal/infrastructure/logging"
 )
 
-// SDKTokenTrackingMiddleware tracks SDK token usage automatically
-// Extracts JWT token from Authorization header and tracks usage by JTI claim
+// sdkTokenHeader carries the SDK token ID (the JTI of the SDK's refresh token) on SDK requests
+const sdkTokenHeader = "X-SDK-Token"
+
+const (
+	// defaultSDKTokenUsageInterval is the minimum time between two usage writes for one token
+	defaultSDKTokenUsageInterval = time.Minute
+
+	// maxTrackedUserAgentLength caps the user agent stored per token
+	maxTrackedUserAgentLength = 512
+)
+
+// sdkTokenUsageKey identifies a token as used by one caller
+type sdkTokenUsageKey struct {
+	tokenID        string
+	organizationID uuid.UUID
+	userID         uuid.UUID
+}
+
+// pendingSDKTokenUsage counts requests made with a token since its usage was last written and
+// remembers the most recent of them, so the count can be written once the token goes idle
+type pendingSDKTokenUsage struct {
+	requests    int
+	lastWritten time.Time
+	ipAddress   string
+	userAgent   string
+	usedAt      time.Time
+}
+
+// SDKTokenTrackingMiddleware records when, from where and by which client an SDK token was last
+// used. SDKs name their token in the X-SDK-Token header. Usage is only recorded once the request
+// has been authenticated, and only against a token of the authenticated organization (and user,
+// for user requests), so the header cannot be used to touch someone else's token.
+//
+// Writes are throttled to one per token per interval; requests in between are added to the
+// usage count of the next write, which StartFlusher makes once the interval has passed even if
+// the token is not used again.
 type SDKTokenTrackingMiddleware struct {
 	sdkTokenRepo domain.SDKTokenRepository
+	interval     time.Duration
+	now          func() time.Time
+	write        func(usage domain.SDKTokenUsage)
+
+	mu      sync.Mutex
+	pending map[sdkTokenUsageKey]*pendingSDKTokenUsage
 }
 
 // NewSDKTokenTrackingMiddleware creates a new SDK token tracking middleware
 func NewSDKTokenTrackingMiddleware(sdkTokenRepo domain.SDKTokenRepository) *SDKTokenTrackingMiddleware {
-	return &SDKTokenTrackingMiddleware{
+	m := &SDKTokenTrackingMiddleware{
 		sdkTokenRepo: sdkTokenRepo,
+		interval:     defaultSDKTokenUsageInterval,
+		now:          time.Now,
+		pending:      make(map[sdkTokenUsageKey]*pendingSDKTokenUsage),
 	}
+	// Record usage asynchronously to avoid blocking the request
+	m.write = func(usage domain.SDKTokenUsage) { go m.store(usage) }
+	return m
 }
 
-// Handler returns the middleware handler function
+// Handler returns the middleware handler function. It must run before the authentication
+// middleware of the routes it covers: it inspects the authenticated caller after the request.
 func (m *SDKTokenTrackingMiddleware) Handler() fiber.Handler {
 	return func(c fiber.Ctx) error {
-		// Extract Authorization header
-		authHeader := c.Get("Authorization", "")
-
-		// Extract token ID (JTI) from JWT if present
-		if authHeader != "" && strings.HasPrefix(authHeader, "Bearer ") {
-			tokenString := strings.TrimPrefix(authHeader, "Bearer ")
-
-			// Parse JWT without validation (we only need the JTI claim)
-			// Note: We're NOT validating the token here - that's done by AuthMiddleware
-			// We're just extracting the token ID for usage tracking
-			token, _, err := jwt.NewParser().ParseUnverified(tokenString, jwt.MapClaims{})
-			if err == nil {
-				if claims, ok := token.Claims.(jwt.MapClaims); ok {
-					if jti, ok := claims["jti"].(string); ok && jti != "" {
-						// Get client IP address
-						ipAddress := c.IP()
-
-						// Record usage asynchronously to avoid blocking the request
-						go func(tokenID, ip string) {
-							if err := m.sdkTokenRepo.RecordUsage(tokenID, ip); err != nil {
-								// Log error but don't fail the request
-								// In production, use proper logging
-								// log.Printf("Failed to record SDK token usage: %v", err)
-							}
-						}(jti, ipAddress)
-					}
-				}
+		err := c.Next()
+
+		tokenID := c.Get(sdkTokenHeader)
+		if tokenID == "" {
+			return err
+		}
+
+		// Only authenticated requests are tracked
+		organizationID, ok := c.Locals("organization_id").(uuid.UUID)
+		if !ok || organizationID == uuid.Nil {
+			return err
+		}
+		userID, _ := c.Locals("user_id").(uuid.UUID)
+
+		userAgent := c.Get(fiber.HeaderUserAgent)
+		if len(userAgent) > maxTrackedUserAgentLength {
+			userAgent = userAgent[:maxTrackedUserAgentLength]
+		}
+
+		// Header values point into the request buffer, which Fiber reuses: copy what is kept
+		key := sdkTokenUsageKey{tokenID: strings.Clone(tokenID), organizationID: organizationID, userID: userID}
+		m.track(key, strings.Clone(c.IP()), strings.Clone(userAgent))
+
+		return err
+	}
+}
+
+// track counts one request and writes the accumulated usage if the token's interval has passed
+func (m *SDKTokenTrackingMiddleware) track(key sdkTokenUsageKey, ipAddress, userAgent string) {
+	now := m.now()
+
+	m.mu.Lock()
+	entry, ok := m.pending[key]
+	if !ok {
+		entry = &pendingSDKTokenUsage{}
+		m.pending[key] = entry
+	}
+	entry.requests++
+	entry.ipAddress = ipAddress
+	entry.userAgent = userAgent
+	entry.usedAt = now
+	if !entry.lastWritten.IsZero() && now.Sub(entry.lastWritten) < m.interval {
+		m.mu.Unlock()
+		return
+	}
+
+	usage := takeSDKTokenUsage(key, entry, now)
+	m.mu.Unlock()
+
+	m.write(usage)
+}
+
+// StartFlusher writes the usage of throttled requests once their token's interval has passed,
+// so requests made just before a token goes idle are still counted. Whatever is pending when
+// ctx is cancelled is written before it returns.
+func (m *SDKTokenTrackingMiddleware) StartFlusher(ctx context.Context) {
+	ticker := time.NewTicker(m.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			for _, usage := range m.flush(m.now(), true) {
+				m.store(usage)
+			}
+			return
+		case <-ticker.C:
+			for _, usage := range m.flush(m.now(), false) {
+				m.write(usage)
 			}
 		}
+	}
+}
+
+// flush takes the pending usage of every token whose interval has passed (of every token when
+// all is set) and forgets tokens that have not been used for a while
+func (m *SDKTokenTrackingMiddleware) flush(now time.Time, all bool) []domain.SDKTokenUsage {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var usages []domain.SDKTokenUsage
+	for key, entry := range m.pending {
+		if entry.requests > 0 && (all || now.Sub(entry.lastWritten) >= m.interval) {
+			usages = append(usages, takeSDKTokenUsage(key, entry, now))
+			continue
+		}
+		if entry.requests == 0 && now.Sub(entry.lastWritten) > 10*m.interval {
+			delete(m.pending, key)
+		}
+	}
+	return usages
+}
+
+// takeSDKTokenUsage builds the usage write for a token's pending requests and resets the count.
+// Callers must hold m.mu.
+func takeSDKTokenUsage(key sdkTokenUsageKey, entry *pendingSDKTokenUsage, now time.Time) domain.SDKTokenUsage {
+	usage := domain.SDKTokenUsage{
+		TokenID:        key.tokenID,
+		OrganizationID: key.organizationID,
+		IPAddress:      entry.ipAddress,
+		UserAgent:      entry.userAgent,
+		UsedAt:         entry.usedAt,
+		Requests:       entry.requests,
+	}
+	if key.userID != uuid.Nil {
+		userID := key.userID
+		usage.UserID = &userID
+	}
+	entry.requests = 0
+	entry.lastWritten = now
+	return usage
+}
 
-		// Continue to next handler
-		return c.Next()
+// store writes usage to the repository, logging failures
+func (m *SDKTokenTrackingMiddleware) store(usage domain.SDKTokenUsage) {
+	if err := m.sdkTokenRepo.RecordUsage(usage); err != nil {
+		logging.FromContext(context.Background()).Warn("failed to record SDK token usage",
+			"token_id", usage.TokenID, "error", err)
 	}
 }
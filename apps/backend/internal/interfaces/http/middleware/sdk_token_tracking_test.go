@@ -0,0 +1,158 @@
+package middleware
+
+import (
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/google/uuid"
+	"github.com/opena2a/identity/backend/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingSDKTokenRepository captures RecordUsage calls; other methods are not used
+type recordingSDKTokenRepository struct {
+	domain.SDKTokenRepository
+	usages []domain.SDKTokenUsage
+}
+
+func (r *recordingSDKTokenRepository) RecordUsage(usage domain.SDKTokenUsage) error {
+	r.usages = append(r.usages, usage)
+	return nil
+}
+
+type sdkTokenTrackingFixture struct {
+	app      *fiber.App
+	repo     *recordingSDKTokenRepository
+	tracking *SDKTokenTrackingMiddleware
+	now      time.Time
+}
+
+// newSDKTokenTrackingFixture wires the tracking middleware in front of a fake authentication
+// middleware that authenticates requests carrying an Authorization header
+func newSDKTokenTrackingFixture(orgID, userID uuid.UUID) *sdkTokenTrackingFixture {
+	f := &sdkTokenTrackingFixture{
+		repo: &recordingSDKTokenRepository{},
+		now:  time.Date(2025, 3, 4, 12, 0, 0, 0, time.UTC),
+	}
+
+	tracking := NewSDKTokenTrackingMiddleware(f.repo)
+	tracking.now = func() time.Time { return f.now }
+	tracking.write = tracking.store
+	f.tracking = tracking
+
+	f.app = fiber.New()
+	f.app.Use(tracking.Handler())
+	f.app.Use(func(c fiber.Ctx) error {
+		if c.Get(fiber.HeaderAuthorization) == "" {
+			return c.SendStatus(fiber.StatusUnauthorized)
+		}
+		c.Locals("organization_id", orgID)
+		if userID != uuid.Nil {
+			c.Locals("user_id", userID)
+		}
+		return c.Next()
+	})
+	f.app.Get("/api/v1/agents", func(c fiber.Ctx) error { return c.SendStatus(fiber.StatusOK) })
+	return f
+}
+
+func (f *sdkTokenTrackingFixture) request(t *testing.T, authenticated bool, tokenID string) {
+	t.Helper()
+	req := httptest.NewRequest(fiber.MethodGet, "/api/v1/agents", nil)
+	req.Header.Set("X-Forwarded-For", "203.0.113.7")
+	req.Header.Set(fiber.HeaderUserAgent, "aim-sdk-python/1.4.0")
+	if authenticated {
+		req.Header.Set(fiber.HeaderAuthorization, "Bearer test")
+	}
+	if tokenID != "" {
+		req.Header.Set(sdkTokenHeader, tokenID)
+	}
+	_, err := f.app.Test(req)
+	require.NoError(t, err)
+}
+
+func TestSDKTokenTracking_RecordsLastUsedMetadata(t *testing.T) {
+	orgID, userID := uuid.New(), uuid.New()
+	f := newSDKTokenTrackingFixture(orgID, userID)
+
+	f.request(t, true, "jti-123")
+
+	require.Len(t, f.repo.usages, 1)
+	usage := f.repo.usages[0]
+	assert.Equal(t, "jti-123", usage.TokenID)
+	assert.Equal(t, orgID, usage.OrganizationID)
+	require.NotNil(t, usage.UserID)
+	assert.Equal(t, userID, *usage.UserID)
+	assert.NotEmpty(t, usage.IPAddress)
+	assert.Equal(t, "aim-sdk-python/1.4.0", usage.UserAgent)
+	assert.Equal(t, f.now, usage.UsedAt)
+	assert.Equal(t, 1, usage.Requests)
+}
+
+func TestSDKTokenTracking_AgentRequestsHaveNoUser(t *testing.T) {
+	f := newSDKTokenTrackingFixture(uuid.New(), uuid.Nil)
+
+	f.request(t, true, "jti-123")
+
+	require.Len(t, f.repo.usages, 1)
+	assert.Nil(t, f.repo.usages[0].UserID)
+}
+
+func TestSDKTokenTracking_IgnoresUnauthenticatedRequests(t *testing.T) {
+	f := newSDKTokenTrackingFixture(uuid.New(), uuid.New())
+
+	f.request(t, false, "jti-123")
+	f.request(t, true, "")
+
+	assert.Empty(t, f.repo.usages)
+}
+
+func TestSDKTokenTracking_ThrottlesWritesPerToken(t *testing.T) {
+	f := newSDKTokenTrackingFixture(uuid.New(), uuid.New())
+
+	f.request(t, true, "jti-123")
+	f.now = f.now.Add(10 * time.Second)
+	f.request(t, true, "jti-123")
+	f.request(t, true, "jti-123")
+	f.request(t, true, "jti-456")
+	require.Len(t, f.repo.usages, 2)
+
+	f.now = f.now.Add(defaultSDKTokenUsageInterval)
+	f.request(t, true, "jti-123")
+
+	require.Len(t, f.repo.usages, 3)
+	assert.Equal(t, "jti-456", f.repo.usages[1].TokenID)
+	assert.Equal(t, "jti-123", f.repo.usages[2].TokenID)
+	// The throttled requests are counted with the next write
+	assert.Equal(t, 3, f.repo.usages[2].Requests)
+	assert.Equal(t, f.now, f.repo.usages[2].UsedAt)
+}
+
+func TestSDKTokenTracking_FlushesThrottledRequestsOfIdleToken(t *testing.T) {
+	f := newSDKTokenTrackingFixture(uuid.New(), uuid.New())
+
+	f.request(t, true, "jti-123")
+	f.now = f.now.Add(10 * time.Second)
+	f.request(t, true, "jti-123")
+	f.request(t, true, "jti-123")
+	lastUsed := f.now
+	require.Len(t, f.repo.usages, 1)
+
+	// Nothing is due before the interval has passed
+	assert.Empty(t, f.tracking.flush(f.now, false))
+
+	// The token goes idle: the flusher writes the throttled requests once the interval has passed
+	f.now = f.now.Add(defaultSDKTokenUsageInterval)
+	usages := f.tracking.flush(f.now, false)
+	require.Len(t, usages, 1)
+	assert.Equal(t, "jti-123", usages[0].TokenID)
+	assert.Equal(t, 2, usages[0].Requests)
+	assert.Equal(t, lastUsed, usages[0].UsedAt)
+	assert.Equal(t, "aim-sdk-python/1.4.0", usages[0].UserAgent)
+
+	// Already flushed requests are not written again
+	assert.Empty(t, f.tracking.flush(f.now.Add(defaultSDKTokenUsageInterval), true))
+}
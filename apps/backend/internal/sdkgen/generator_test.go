@@ -0,0 +1,235 @@
+package sdkgen
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"flag"
+	"go/parser"
+	"go/token"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+var updateGolden = flag.Bool("update", false, "update golden files in testdata")
+
+const (
+	testAgentID    = "5f1c2d3e-4b5a-4c6d-8e7f-9a0b1c2d3e4f"
+	testPublicKey  = "Ym9ndXMtcHVibGljLWtleS1mb3ItZ29sZGVuLXRlc3Q="
+	testPrivateKey = "Ym9ndXMtcHJpdmF0ZS1rZXktZm9yLWdvbGRlbi10ZXN0LWJvZ3VzLXByaXZhdGUta2V5LWZvci1nb2xkZW4="
+	testAIMURL     = "https://aim.example.com"
+	testAgentName  = "golden-agent"
+)
+
+// unzipSDK extracts every file in the zip archive into a name -> content map
+func unzipSDK(t *testing.T, data []byte) map[string]string {
+	t.Helper()
+
+	reader, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("failed to open SDK zip: %v", err)
+	}
+
+	files := make(map[string]string, len(reader.File))
+	for _, f := range reader.File {
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatalf("failed to open %s: %v", f.Name, err)
+		}
+		content, err := io.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			t.Fatalf("failed to read %s: %v", f.Name, err)
+		}
+		files[f.Name] = string(content)
+	}
+	return files
+}
+
+// assertGolden compares content against testdata/<name>, rewriting it with -update
+func assertGolden(t *testing.T, name, content string) {
+	t.Helper()
+
+	path := filepath.Join("testdata", name)
+	if *updateGolden {
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("failed to update golden file %s: %v", path, err)
+		}
+	}
+
+	expected, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read golden file %s: %v", path, err)
+	}
+	if string(expected) != content {
+		t.Errorf("%s does not match golden file %s (run go test -update to refresh)\n--- got ---\n%s", name, path, content)
+	}
+}
+
+func assertCredentialsEmbedded(t *testing.T, filename, content string) {
+	t.Helper()
+
+	for _, value := range []string{testAgentID, testPublicKey, testPrivateKey, testAIMURL, testAgentName} {
+		if !strings.Contains(content, value) {
+			t.Errorf("%s is missing embedded value %q", filename, value)
+		}
+	}
+}
+
+func TestGenerateNodeSDK(t *testing.T) {
+	data, err := GenerateNodeSDK(NodeSDKConfig{
+		AgentID:    testAgentID,
+		PublicKey:  testPublicKey,
+		PrivateKey: testPrivateKey,
+		AIMURL:     testAIMURL,
+		AgentName:  testAgentName,
+		Version:    "1.0.0",
+	})
+	if err != nil {
+		t.Fatalf("GenerateNodeSDK() error = %v", err)
+	}
+
+	files := unzipSDK(t, data)
+	for _, name := range []string{"src/index.js", "src/client.js", "src/errors.js", "src/config.js", "package.json", "README.md", "example.js"} {
+		if _, ok := files[name]; !ok {
+			t.Errorf("SDK zip is missing %s", name)
+		}
+	}
+
+	assertCredentialsEmbedded(t, "src/config.js", files["src/config.js"])
+	assertGolden(t, "nodejs_config.js.golden", files["src/config.js"])
+
+	if !strings.Contains(files["src/client.js"], "secure(actionType") {
+		t.Error("client.js does not expose secure()")
+	}
+	if !strings.Contains(files["src/client.js"], "/api/v1/sdk-api/verifications") {
+		t.Error("client.js does not call the verification endpoint")
+	}
+}
+
+func TestGenerateGoSDK(t *testing.T) {
+	data, err := GenerateGoSDK(GoSDKConfig{
+		AgentID:    testAgentID,
+		PublicKey:  testPublicKey,
+		PrivateKey: testPrivateKey,
+		AIMURL:     testAIMURL,
+		AgentName:  testAgentName,
+		Version:    "1.0.0",
+	})
+	if err != nil {
+		t.Fatalf("GenerateGoSDK() error = %v", err)
+	}
+
+	files := unzipSDK(t, data)
+	for _, name := range []string{"go.mod", "aim/client.go", "aim/errors.go", "aim/config.go", "example/main.go", "README.md"} {
+		if _, ok := files[name]; !ok {
+			t.Errorf("SDK zip is missing %s", name)
+		}
+	}
+
+	assertCredentialsEmbedded(t, "aim/config.go", files["aim/config.go"])
+	assertGolden(t, "go_config.go.golden", files["aim/config.go"])
+
+	if !strings.Contains(files["aim/client.go"], "func (c *Client) Secure(") {
+		t.Error("client.go does not expose Secure()")
+	}
+	if !strings.Contains(files["aim/client.go"], "/api/v1/sdk-api/verifications") {
+		t.Error("client.go does not call the verification endpoint")
+	}
+}
+
+func TestGeneratePythonSDK(t *testing.T) {
+	data, err := GeneratePythonSDK(PythonSDKConfig{
+		AgentID:    testAgentID,
+		PublicKey:  testPublicKey,
+		PrivateKey: testPrivateKey,
+		AIMURL:     testAIMURL,
+		AgentName:  testAgentName,
+		Version:    "1.0.0",
+	})
+	if err != nil {
+		t.Fatalf("GeneratePythonSDK() error = %v", err)
+	}
+
+	files := unzipSDK(t, data)
+	for _, name := range []string{"aim_sdk/__init__.py", "aim_sdk/client.py", "aim_sdk/config.py", "setup.py", "README.md", ".env.example", "requirements.txt", "example.py"} {
+		if _, ok := files[name]; !ok {
+			t.Errorf("SDK zip is missing %s", name)
+		}
+	}
+
+	assertCredentialsEmbedded(t, "aim_sdk/config.py", files["aim_sdk/config.py"])
+
+	readme := files["README.md"]
+	for _, value := range []string{"# AIM Python SDK - " + testAgentName, testAIMURL, "pip install -r requirements.txt", "cp .env.example .env"} {
+		if !strings.Contains(readme, value) {
+			t.Errorf("README.md is missing %q", value)
+		}
+	}
+
+	assertGolden(t, "python_env.example.golden", files[".env.example"])
+	if strings.Contains(files[".env.example"], testPrivateKey) {
+		t.Error(".env.example must not contain the private key")
+	}
+
+	for _, line := range strings.Split(strings.TrimSpace(files["requirements.txt"]), "\n") {
+		if !strings.Contains(line, "==") {
+			t.Errorf("requirements.txt entry %q is not pinned", line)
+		}
+	}
+}
+
+func TestGenerateSDKs_QuotesAgentName(t *testing.T) {
+	const agentName = `Bob's "quoted" \ agent`
+
+	goData, err := GenerateGoSDK(GoSDKConfig{
+		AgentID:    testAgentID,
+		PublicKey:  testPublicKey,
+		PrivateKey: testPrivateKey,
+		AIMURL:     testAIMURL,
+		AgentName:  agentName,
+		Version:    "1.0.0",
+	})
+	if err != nil {
+		t.Fatalf("GenerateGoSDK() error = %v", err)
+	}
+
+	goConfig := unzipSDK(t, goData)["aim/config.go"]
+	if _, err := parser.ParseFile(token.NewFileSet(), "config.go", goConfig, 0); err != nil {
+		t.Errorf("aim/config.go does not parse: %v\n%s", err, goConfig)
+	}
+	if !strings.Contains(goConfig, strconv.Quote(agentName)) {
+		t.Errorf("aim/config.go does not contain the quoted agent name\n%s", goConfig)
+	}
+
+	nodeData, err := GenerateNodeSDK(NodeSDKConfig{
+		AgentID:    testAgentID,
+		PublicKey:  testPublicKey,
+		PrivateKey: testPrivateKey,
+		AIMURL:     testAIMURL,
+		AgentName:  agentName,
+		Version:    "1.0.0",
+	})
+	if err != nil {
+		t.Fatalf("GenerateNodeSDK() error = %v", err)
+	}
+
+	files := unzipSDK(t, nodeData)
+	if !strings.Contains(files["src/config.js"], `AGENT_NAME: "Bob's \"quoted\" \\ agent",`) {
+		t.Errorf("src/config.js does not contain the escaped agent name\n%s", files["src/config.js"])
+	}
+
+	var pkg struct {
+		Description string `json:"description"`
+	}
+	if err := json.Unmarshal([]byte(files["package.json"]), &pkg); err != nil {
+		t.Fatalf("package.json is not valid JSON: %v\n%s", err, files["package.json"])
+	}
+	if pkg.Description != "AIM SDK for agent "+agentName {
+		t.Errorf("package.json description = %q", pkg.Description)
+	}
+}
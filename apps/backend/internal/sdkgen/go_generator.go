@@ -0,0 +1,515 @@
+package sdkgen
+
+import (
+	"archive/zip"
+	"bytes"
+	"fmt"
+	"strconv"
+	"text/template"
+)
+
+// GoSDKConfig contains configuration for generating Go SDK
+type GoSDKConfig struct {
+	AgentID    string
+	PublicKey  string
+	PrivateKey string
+	AIMURL     string
+	AgentName  string
+	Version    string
+}
+
+// GenerateGoSDK generates a complete Go SDK module with embedded keys
+func GenerateGoSDK(config GoSDKConfig) ([]byte, error) {
+	buf := new(bytes.Buffer)
+	zipWriter := zip.NewWriter(buf)
+
+	// Add SDK files
+	files := map[string]string{
+		"go.mod":          goModFile,
+		"aim/client.go":   goClientFile,
+		"aim/errors.go":   goErrorsFile,
+		"aim/config.go":   generateGoConfig(config),
+		"example/main.go": generateGoExample(config),
+		"README.md":       generateGoReadme(config),
+	}
+
+	for filename, content := range files {
+		fw, err := zipWriter.Create(filename)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create %s: %w", filename, err)
+		}
+
+		if _, err := fw.Write([]byte(content)); err != nil {
+			return nil, fmt.Errorf("failed to write %s: %w", filename, err)
+		}
+	}
+
+	if err := zipWriter.Close(); err != nil {
+		return nil, fmt.Errorf("failed to close zip writer: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// generateGoConfig generates aim/config.go with embedded credentials
+func generateGoConfig(config GoSDKConfig) string {
+	tmpl := `// AIM SDK Configuration - Auto-generated by AIM
+//
+// ⚠️  SECURITY WARNING: This file contains your agent's private key!
+//   - Never commit this file to version control
+//   - Never share this file publicly
+//   - Store securely and use environment variables in production
+package aim
+
+// Agent credentials (automatically generated by AIM)
+const (
+	AgentID    = {{goString .AgentID}}
+	PublicKey  = {{goString .PublicKey}}
+	PrivateKey = {{goString .PrivateKey}}
+)
+
+// AIM server URL
+const AIMURL = {{goString .AIMURL}}
+
+// Agent metadata
+const (
+	AgentName  = {{goString .AgentName}}
+	SDKVersion = {{goString .Version}}
+)
+`
+
+	t := template.Must(template.New("config").Funcs(template.FuncMap{"goString": strconv.Quote}).Parse(tmpl))
+	var result bytes.Buffer
+	t.Execute(&result, config)
+	return result.String()
+}
+
+// generateGoExample generates example/main.go with usage demonstration
+func generateGoExample(config GoSDKConfig) string {
+	tmpl := `// Example usage of AIM SDK for agent: {{.AgentName}}
+//
+// This example demonstrates automatic identity verification.
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"aimsdk/aim"
+)
+
+func main() {
+	client, err := aim.NewClient(aim.AgentID, aim.PublicKey, aim.PrivateKey, aim.AIMURL)
+	if err != nil {
+		log.Fatalf("failed to create AIM client: %v", err)
+	}
+
+	ctx := context.Background()
+
+	// Example 1: Automatic verification with Secure
+	// AIM will sign the request, verify it, run the function and log the result.
+	err = client.Secure(ctx, "read_database", "users_table", func() error {
+		fmt.Println("Reading user data for user: 12345")
+		return nil
+	})
+	if err != nil {
+		fmt.Printf("❌ Action failed: %v\n", err)
+	}
+
+	// Example 2: Manual verification for more control
+	verification, err := client.VerifyAction(ctx, "send_email", "admin@example.com", map[string]interface{}{
+		"subject":  "System Alert",
+		"priority": "high",
+	})
+	if err != nil {
+		fmt.Printf("❌ Action failed: %v\n", err)
+		return
+	}
+	fmt.Printf("✅ Action verified by: %s\n", verification.ApprovedBy)
+	fmt.Println("Sending email...")
+	client.LogActionResult(ctx, verification.VerificationID, true, "Email sent successfully", "")
+}
+`
+
+	t := template.Must(template.New("example").Parse(tmpl))
+	var result bytes.Buffer
+	t.Execute(&result, config)
+	return result.String()
+}
+
+// generateGoReadme generates README.md with setup instructions
+func generateGoReadme(config GoSDKConfig) string {
+	tmpl := `# AIM Go SDK - {{.AgentName}}
+
+Auto-generated SDK for agent identity verification with AIM.
+
+## ⚠️  Security Notice
+
+This SDK package contains your agent's **private key** embedded in ` + "`aim/config.go`" + `.
+
+**IMPORTANT**:
+- 🔒 Never commit this package to version control
+- 🔒 Never share this package publicly
+- 🔒 Store securely and use environment variables in production
+- 🔒 Regenerate keys immediately if compromised
+
+## Quick Start
+
+### 1. Requirements
+
+Go 1.21 or newer. The SDK only uses the standard library.
+
+### 2. Run Example
+
+` + "```bash" + `
+go run ./example
+` + "```" + `
+
+### 3. Use in Your Agent
+
+` + "```go" + `
+client, err := aim.NewClient(aim.AgentID, aim.PublicKey, aim.PrivateKey, aim.AIMURL)
+if err != nil {
+	log.Fatal(err)
+}
+
+err = client.Secure(ctx, "your_action_type", "your_resource", func() error {
+	// Your agent code here
+	return nil
+})
+` + "```" + `
+
+## Agent Details
+
+- **Agent ID**: ` + "`{{.AgentID}}`" + `
+- **Agent Name**: {{.AgentName}}
+- **AIM Server**: {{.AIMURL}}
+- **SDK Version**: {{.Version}}
+
+## Features
+
+✅ Automatic cryptographic signing (Ed25519)
+✅ Seamless identity verification
+✅ Function wrapper API (` + "`Secure`" + `)
+✅ Automatic retry on transient failures
+✅ Zero third-party dependencies
+
+## Documentation
+
+For complete documentation, visit: {{.AIMURL}}/docs
+
+---
+
+Generated by AIM (Agent Identity Management)
+`
+
+	t := template.Must(template.New("readme").Parse(tmpl))
+	var result bytes.Buffer
+	t.Execute(&result, config)
+	return result.String()
+}
+
+// Go SDK file templates
+const goModFile = `module aimsdk
+
+go 1.21
+`
+
+const goErrorsFile = `package aim
+
+import "errors"
+
+var (
+	// ErrAuthentication is returned when authentication with AIM fails
+	ErrAuthentication = errors.New("aim: authentication failed")
+	// ErrVerification is returned when action verification fails or times out
+	ErrVerification = errors.New("aim: verification failed")
+	// ErrActionDenied is returned when AIM denies permission to perform an action
+	ErrActionDenied = errors.New("aim: action denied")
+	// ErrConfiguration is returned when the SDK is misconfigured
+	ErrConfiguration = errors.New("aim: invalid configuration")
+)
+`
+
+const goClientFile = `// Package aim provides automatic identity verification for AI agents.
+package aim
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Client is the AIM SDK client for automatic identity verification
+type Client struct {
+	AgentID    string
+	AIMURL     string
+	HTTPClient *http.Client
+	MaxRetries int
+
+	publicKey  string
+	privateKey ed25519.PrivateKey
+}
+
+// Verification is the result of an approved verification request
+type Verification struct {
+	Verified       bool
+	VerificationID string
+	ApprovedBy     string
+	ExpiresAt      string
+}
+
+// NewClient creates a client from base64-encoded Ed25519 keys
+func NewClient(agentID, publicKey, privateKey, aimURL string) (*Client, error) {
+	if agentID == "" || publicKey == "" || privateKey == "" || aimURL == "" {
+		return nil, fmt.Errorf("%w: agentID, publicKey, privateKey and aimURL are required", ErrConfiguration)
+	}
+
+	keyBytes, err := base64.StdEncoding.DecodeString(privateKey)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid private key encoding: %v", ErrConfiguration, err)
+	}
+
+	var signingKey ed25519.PrivateKey
+	switch len(keyBytes) {
+	case ed25519.PrivateKeySize:
+		signingKey = ed25519.PrivateKey(keyBytes)
+	case ed25519.SeedSize:
+		signingKey = ed25519.NewKeyFromSeed(keyBytes)
+	default:
+		return nil, fmt.Errorf("%w: invalid private key length: %d bytes", ErrConfiguration, len(keyBytes))
+	}
+
+	derived := base64.StdEncoding.EncodeToString(signingKey.Public().(ed25519.PublicKey))
+	if derived != publicKey {
+		return nil, fmt.Errorf("%w: public key does not match private key", ErrConfiguration)
+	}
+
+	return &Client{
+		AgentID:    agentID,
+		AIMURL:     strings.TrimRight(aimURL, "/"),
+		HTTPClient: &http.Client{Timeout: 30 * time.Second},
+		MaxRetries: 3,
+		publicKey:  publicKey,
+		privateKey: signingKey,
+	}, nil
+}
+
+// CanonicalJSON serializes v exactly like Python's
+// json.dumps(v, sort_keys=True, separators=(", ", ": ")).
+// AIM verifies signatures against this canonical form.
+func CanonicalJSON(v interface{}) (string, error) {
+	buf := new(bytes.Buffer)
+	encoder := json.NewEncoder(buf)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(v); err != nil {
+		return "", err
+	}
+	compact := strings.TrimRight(buf.String(), "\n")
+
+	var out strings.Builder
+	inString := false
+	escape := false
+	for _, char := range compact {
+		out.WriteRune(char)
+		switch {
+		case escape:
+			escape = false
+		case char == '\\':
+			escape = true
+		case char == '"':
+			inString = !inString
+		case !inString && (char == ':' || char == ','):
+			out.WriteRune(' ')
+		}
+	}
+	return out.String(), nil
+}
+
+func (c *Client) sign(message string) string {
+	return base64.StdEncoding.EncodeToString(ed25519.Sign(c.privateKey, []byte(message)))
+}
+
+func (c *Client) doRequest(ctx context.Context, method, endpoint string, data interface{}) (map[string]interface{}, error) {
+	var lastErr error
+	for attempt := 0; attempt <= c.MaxRetries; attempt++ {
+		if attempt > 0 {
+			time.Sleep(time.Duration(1<<(attempt-1)) * time.Second)
+		}
+
+		var body *bytes.Reader
+		if data != nil {
+			payload, err := json.Marshal(data)
+			if err != nil {
+				return nil, err
+			}
+			body = bytes.NewReader(payload)
+		} else {
+			body = bytes.NewReader(nil)
+		}
+
+		req, err := http.NewRequestWithContext(ctx, method, c.AIMURL+endpoint, body)
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("User-Agent", "AIM-Go-SDK/1.0.0")
+
+		resp, err := c.HTTPClient.Do(req)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+
+		result := map[string]interface{}{}
+		json.NewDecoder(resp.Body).Decode(&result)
+		resp.Body.Close()
+
+		switch {
+		case resp.StatusCode == http.StatusUnauthorized:
+			return nil, fmt.Errorf("%w: invalid agent credentials", ErrAuthentication)
+		case resp.StatusCode >= 500:
+			lastErr = fmt.Errorf("server returned status %d", resp.StatusCode)
+			continue
+		case resp.StatusCode >= 400 && resp.StatusCode != http.StatusForbidden:
+			return nil, fmt.Errorf("%w: request failed with status %d: %v", ErrVerification, resp.StatusCode, result["error"])
+		}
+		return result, nil
+	}
+	return nil, fmt.Errorf("%w: %v", ErrVerification, lastErr)
+}
+
+// VerifyAction requests verification for an action from AIM
+func (c *Client) VerifyAction(ctx context.Context, actionType, resource string, actionContext map[string]interface{}) (*Verification, error) {
+	timestamp := time.Now().UTC().Format(time.RFC3339Nano)
+	if actionContext == nil {
+		actionContext = map[string]interface{}{}
+	}
+	var resourceValue interface{}
+	if resource != "" {
+		resourceValue = resource
+	}
+
+	message, err := CanonicalJSON(map[string]interface{}{
+		"action_type": actionType,
+		"agent_id":    c.AgentID,
+		"context":     actionContext,
+		"resource":    resourceValue,
+		"timestamp":   timestamp,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%w: failed to build signature payload: %v", ErrVerification, err)
+	}
+
+	result, err := c.doRequest(ctx, http.MethodPost, "/api/v1/sdk-api/verifications", map[string]interface{}{
+		"agent_id":    c.AgentID,
+		"action_type": actionType,
+		"resource":    resourceValue,
+		"context":     actionContext,
+		"timestamp":   timestamp,
+		"signature":   c.sign(message),
+		"public_key":  c.publicKey,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	id, _ := result["id"].(string)
+	switch result["status"] {
+	case "approved":
+		return verificationFromResult(id, result), nil
+	case "denied":
+		return nil, fmt.Errorf("%w: %v", ErrActionDenied, result["denial_reason"])
+	case "pending":
+		return c.waitForApproval(ctx, id, 5*time.Minute)
+	}
+	return nil, fmt.Errorf("%w: unexpected verification status: %v", ErrVerification, result["status"])
+}
+
+func (c *Client) waitForApproval(ctx context.Context, verificationID string, timeout time.Duration) (*Verification, error) {
+	deadline := time.Now().Add(timeout)
+	pollInterval := 2 * time.Second
+
+	for time.Now().Before(deadline) {
+		result, err := c.doRequest(ctx, http.MethodGet, "/api/v1/sdk-api/verifications/"+verificationID, nil)
+		if err != nil {
+			return nil, err
+		}
+		switch result["status"] {
+		case "approved":
+			return verificationFromResult(verificationID, result), nil
+		case "denied":
+			return nil, fmt.Errorf("%w: %v", ErrActionDenied, result["denial_reason"])
+		}
+
+		time.Sleep(pollInterval)
+		if pollInterval = pollInterval * 3 / 2; pollInterval > 10*time.Second {
+			pollInterval = 10 * time.Second
+		}
+	}
+	return nil, fmt.Errorf("%w: verification timeout after %s", ErrVerification, timeout)
+}
+
+func verificationFromResult(id string, result map[string]interface{}) *Verification {
+	approvedBy, _ := result["approved_by"].(string)
+	expiresAt, _ := result["expires_at"].(string)
+	return &Verification{
+		Verified:       true,
+		VerificationID: id,
+		ApprovedBy:     approvedBy,
+		ExpiresAt:      expiresAt,
+	}
+}
+
+// LogActionResult logs the result of an action execution to AIM.
+// Failures are ignored so result logging never breaks the caller.
+func (c *Client) LogActionResult(ctx context.Context, verificationID string, success bool, resultSummary, errorMessage string) {
+	result := "failure"
+	if success {
+		result = "success"
+	}
+	c.doRequest(ctx, http.MethodPost, "/api/v1/sdk-api/verifications/"+verificationID+"/result", map[string]interface{}{
+		"result":         result,
+		"result_summary": resultSummary,
+		"error_message":  errorMessage,
+		"timestamp":      time.Now().UTC().Format(time.RFC3339Nano),
+	})
+}
+
+// RequestCapability requests an additional capability for the agent
+func (c *Client) RequestCapability(ctx context.Context, capabilityType, reason string) (map[string]interface{}, error) {
+	if capabilityType == "" {
+		return nil, fmt.Errorf("%w: capabilityType must be a non-empty string", ErrConfiguration)
+	}
+	if len(reason) < 10 {
+		return nil, fmt.Errorf("%w: reason must be at least 10 characters", ErrConfiguration)
+	}
+	return c.doRequest(ctx, http.MethodPost, "/api/v1/sdk-api/agents/"+c.AgentID+"/capability-requests", map[string]interface{}{
+		"capability_type": capabilityType,
+		"reason":          reason,
+	})
+}
+
+// Secure verifies the action with AIM, runs fn if approved and logs the outcome
+func (c *Client) Secure(ctx context.Context, actionType, resource string, fn func() error) error {
+	verification, err := c.VerifyAction(ctx, actionType, resource, nil)
+	if err != nil {
+		return err
+	}
+
+	if err := fn(); err != nil {
+		c.LogActionResult(ctx, verification.VerificationID, false, "", err.Error())
+		return err
+	}
+
+	c.LogActionResult(ctx, verification.VerificationID, true, fmt.Sprintf("Action '%s' completed successfully", actionType), "")
+	return nil
+}
+`
@@ -0,0 +1,547 @@
+package sdkgen
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"text/template"
+)
+
+// NodeSDKConfig contains configuration for generating Node.js SDK
+type NodeSDKConfig struct {
+	AgentID    string
+	PublicKey  string
+	PrivateKey string
+	AIMURL     string
+	AgentName  string
+	Version    string
+}
+
+// GenerateNodeSDK generates a complete Node.js SDK package with embedded keys
+func GenerateNodeSDK(config NodeSDKConfig) ([]byte, error) {
+	buf := new(bytes.Buffer)
+	zipWriter := zip.NewWriter(buf)
+
+	// Add SDK files
+	files := map[string]string{
+		"src/index.js":  nodeIndexFile,
+		"src/client.js": nodeClientFile,
+		"src/errors.js": nodeErrorsFile,
+		"src/config.js": generateNodeConfig(config),
+		"package.json":  generateNodePackageJSON(config),
+		"README.md":     generateNodeReadme(config),
+		"example.js":    generateNodeExample(config),
+	}
+
+	for filename, content := range files {
+		fw, err := zipWriter.Create(filename)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create %s: %w", filename, err)
+		}
+
+		if _, err := fw.Write([]byte(content)); err != nil {
+			return nil, fmt.Errorf("failed to write %s: %w", filename, err)
+		}
+	}
+
+	if err := zipWriter.Close(); err != nil {
+		return nil, fmt.Errorf("failed to close zip writer: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// nodeTemplateFuncs lets templates emit values as escaped JSON string literals,
+// which are valid in both JavaScript sources and package.json
+var nodeTemplateFuncs = template.FuncMap{
+	"jsString": func(value string) (string, error) {
+		encoded, err := json.Marshal(value)
+		return string(encoded), err
+	},
+}
+
+// generateNodeConfig generates src/config.js with embedded credentials
+func generateNodeConfig(config NodeSDKConfig) string {
+	tmpl := `/**
+ * AIM SDK Configuration - Auto-generated by AIM
+ *
+ * ⚠️  SECURITY WARNING: This file contains your agent's private key!
+ *     - Never commit this file to version control
+ *     - Never share this file publicly
+ *     - Store securely and use environment variables in production
+ */
+
+module.exports = {
+  // Agent credentials (automatically generated by AIM)
+  AGENT_ID: {{jsString .AgentID}},
+  PUBLIC_KEY: {{jsString .PublicKey}},
+  PRIVATE_KEY: {{jsString .PrivateKey}},
+
+  // AIM server URL
+  AIM_URL: {{jsString .AIMURL}},
+
+  // Agent metadata
+  AGENT_NAME: {{jsString .AgentName}},
+  SDK_VERSION: {{jsString .Version}},
+};
+`
+
+	t := template.Must(template.New("config").Funcs(nodeTemplateFuncs).Parse(tmpl))
+	var result bytes.Buffer
+	t.Execute(&result, config)
+	return result.String()
+}
+
+// generateNodePackageJSON generates package.json for the SDK
+func generateNodePackageJSON(config NodeSDKConfig) string {
+	tmpl := `{
+  "name": "aim-sdk",
+  "version": {{jsString .Version}},
+  "description": {{jsString (print "AIM SDK for agent " .AgentName)}},
+  "main": "src/index.js",
+  "engines": {
+    "node": ">=18.0.0"
+  },
+  "scripts": {
+    "example": "node example.js"
+  },
+  "private": true
+}
+`
+
+	t := template.Must(template.New("package").Funcs(nodeTemplateFuncs).Parse(tmpl))
+	var result bytes.Buffer
+	t.Execute(&result, config)
+	return result.String()
+}
+
+// generateNodeExample generates example.js with usage demonstration
+func generateNodeExample(config NodeSDKConfig) string {
+	tmpl := `/**
+ * Example usage of AIM SDK for agent: {{.AgentName}}
+ *
+ * This example demonstrates automatic identity verification.
+ */
+
+const { AIMClient } = require("./src");
+const { AGENT_ID, PUBLIC_KEY, PRIVATE_KEY, AIM_URL } = require("./src/config");
+
+const client = new AIMClient({
+  agentId: AGENT_ID,
+  publicKey: PUBLIC_KEY,
+  privateKey: PRIVATE_KEY,
+  aimUrl: AIM_URL,
+});
+
+// Example 1: Automatic verification with secure()
+// AIM will sign the request, verify it, run the function and log the result.
+const getUserData = client.secure("read_database", { resource: "users_table" }, async (userId) => {
+  console.log(` + "`Reading user data for user: ${userId}`" + `);
+  return { user_id: userId, name: "Alice", email: "alice@example.com" };
+});
+
+// Example 2: Manual verification for more control
+async function sendSensitiveEmail() {
+  try {
+    const verification = await client.verifyAction("send_email", {
+      resource: "admin@example.com",
+      context: { subject: "System Alert", priority: "high" },
+      timeoutSeconds: 300,
+    });
+
+    console.log(` + "`✅ Action verified by: ${verification.approvedBy}`" + `);
+    console.log("Sending email...");
+
+    await client.logActionResult(verification.verificationId, {
+      success: true,
+      resultSummary: "Email sent successfully",
+    });
+  } catch (err) {
+    console.error(` + "`❌ Action failed: ${err.message}`" + `);
+  }
+}
+
+async function main() {
+  console.log("Example 1: Automatic verification with secure()");
+  console.log("Result:", await getUserData("12345"));
+
+  console.log("\nExample 2: Manual verification");
+  await sendSensitiveEmail();
+}
+
+main().catch((err) => {
+  console.error(err);
+  process.exit(1);
+});
+`
+
+	t := template.Must(template.New("example").Parse(tmpl))
+	var result bytes.Buffer
+	t.Execute(&result, config)
+	return result.String()
+}
+
+// generateNodeReadme generates README.md with setup instructions
+func generateNodeReadme(config NodeSDKConfig) string {
+	tmpl := `# AIM Node.js SDK - {{.AgentName}}
+
+Auto-generated SDK for agent identity verification with AIM.
+
+## ⚠️  Security Notice
+
+This SDK package contains your agent's **private key** embedded in ` + "`src/config.js`" + `.
+
+**IMPORTANT**:
+- 🔒 Never commit this package to version control
+- 🔒 Never share this package publicly
+- 🔒 Store securely and use environment variables in production
+- 🔒 Regenerate keys immediately if compromised
+
+## Quick Start
+
+### 1. Requirements
+
+Node.js 18 or newer (uses the built-in ` + "`crypto`" + ` and ` + "`fetch`" + ` APIs, no dependencies).
+
+### 2. Run Example
+
+` + "```bash" + `
+node example.js
+` + "```" + `
+
+### 3. Use in Your Agent
+
+` + "```javascript" + `
+const { AIMClient } = require("./src");
+const { AGENT_ID, PUBLIC_KEY, PRIVATE_KEY, AIM_URL } = require("./src/config");
+
+const client = new AIMClient({
+  agentId: AGENT_ID,
+  publicKey: PUBLIC_KEY,
+  privateKey: PRIVATE_KEY,
+  aimUrl: AIM_URL,
+});
+
+const yourFunction = client.secure("your_action_type", { resource: "your_resource" }, async () => {
+  // Your agent code here
+});
+` + "```" + `
+
+## Agent Details
+
+- **Agent ID**: ` + "`{{.AgentID}}`" + `
+- **Agent Name**: {{.AgentName}}
+- **AIM Server**: {{.AIMURL}}
+- **SDK Version**: {{.Version}}
+
+## Features
+
+✅ Automatic cryptographic signing (Ed25519)
+✅ Seamless identity verification
+✅ Function wrapper API (` + "`secure()`" + `)
+✅ Automatic retry on transient failures
+✅ Zero runtime dependencies
+
+## Documentation
+
+For complete documentation, visit: {{.AIMURL}}/docs
+
+---
+
+Generated by AIM (Agent Identity Management)
+`
+
+	t := template.Must(template.New("readme").Parse(tmpl))
+	var result bytes.Buffer
+	t.Execute(&result, config)
+	return result.String()
+}
+
+// Node.js SDK file templates
+const nodeIndexFile = `/**
+ * AIM Node.js SDK - Automatic Identity Verification for AI Agents
+ */
+
+const { AIMClient, canonicalJSON } = require("./client");
+const errors = require("./errors");
+
+module.exports = {
+  AIMClient,
+  canonicalJSON,
+  ...errors,
+};
+`
+
+const nodeErrorsFile = `/**
+ * AIM SDK Error Classes
+ */
+
+class AIMError extends Error {
+  constructor(message) {
+    super(message);
+    this.name = this.constructor.name;
+  }
+}
+
+/** Raised when authentication with AIM fails */
+class AuthenticationError extends AIMError {}
+
+/** Raised when action verification fails or is rejected */
+class VerificationError extends AIMError {}
+
+/** Raised when AIM denies permission to perform an action */
+class ActionDeniedError extends AIMError {}
+
+/** Raised when SDK is misconfigured */
+class ConfigurationError extends AIMError {}
+
+module.exports = {
+  AIMError,
+  AuthenticationError,
+  VerificationError,
+  ActionDeniedError,
+  ConfigurationError,
+};
+`
+
+const nodeClientFile = `/**
+ * AIM Client - Core SDK functionality for automatic identity verification
+ */
+
+const crypto = require("crypto");
+
+const {
+  AuthenticationError,
+  VerificationError,
+  ActionDeniedError,
+  ConfigurationError,
+} = require("./errors");
+
+// PKCS#8 DER prefix for a raw 32-byte Ed25519 seed
+const ED25519_PKCS8_PREFIX = Buffer.from("302e020100300506032b657004220420", "hex");
+
+/**
+ * Serialize a value exactly like Python's
+ * json.dumps(value, sort_keys=True, separators=(", ", ": ")).
+ * AIM verifies signatures against this canonical form.
+ */
+function canonicalJSON(value) {
+  if (value === null || value === undefined) {
+    return "null";
+  }
+  if (Array.isArray(value)) {
+    return "[" + value.map(canonicalJSON).join(", ") + "]";
+  }
+  if (typeof value === "object") {
+    const keys = Object.keys(value).sort();
+    return "{" + keys.map((k) => JSON.stringify(k) + ": " + canonicalJSON(value[k])).join(", ") + "}";
+  }
+  return JSON.stringify(value);
+}
+
+const sleep = (ms) => new Promise((resolve) => setTimeout(resolve, ms));
+
+class AIMClient {
+  constructor({ agentId, publicKey, privateKey, aimUrl, timeout = 30000, autoRetry = true, maxRetries = 3 }) {
+    if (!agentId) throw new ConfigurationError("agentId is required");
+    if (!publicKey) throw new ConfigurationError("publicKey is required");
+    if (!privateKey) throw new ConfigurationError("privateKey is required");
+    if (!aimUrl) throw new ConfigurationError("aimUrl is required");
+
+    this.agentId = agentId;
+    this.aimUrl = aimUrl.replace(/\/+$/, "");
+    this.timeout = timeout;
+    this.autoRetry = autoRetry;
+    this.maxRetries = maxRetries;
+
+    const keyBytes = Buffer.from(privateKey, "base64");
+    let seed;
+    if (keyBytes.length === 64) {
+      seed = keyBytes.subarray(0, 32);
+    } else if (keyBytes.length === 32) {
+      seed = keyBytes;
+    } else {
+      throw new ConfigurationError(` + "`Invalid private key length: ${keyBytes.length} bytes`" + `);
+    }
+
+    this.signingKey = crypto.createPrivateKey({
+      key: Buffer.concat([ED25519_PKCS8_PREFIX, seed]),
+      format: "der",
+      type: "pkcs8",
+    });
+
+    const derivedPublicKey = crypto
+      .createPublicKey(this.signingKey)
+      .export({ format: "der", type: "spki" })
+      .subarray(-32)
+      .toString("base64");
+    if (derivedPublicKey !== publicKey) {
+      throw new ConfigurationError("Public key does not match private key");
+    }
+    this.publicKey = publicKey;
+  }
+
+  /** Sign a message using the Ed25519 private key. */
+  signMessage(message) {
+    return crypto.sign(null, Buffer.from(message, "utf8"), this.signingKey).toString("base64");
+  }
+
+  async makeRequest(method, endpoint, data, retryCount = 0) {
+    const retry = async () => {
+      await sleep(2 ** retryCount * 1000);
+      return this.makeRequest(method, endpoint, data, retryCount + 1);
+    };
+
+    let response;
+    try {
+      response = await fetch(this.aimUrl + endpoint, {
+        method,
+        headers: {
+          "User-Agent": "AIM-Node-SDK/1.0.0",
+          "Content-Type": "application/json",
+        },
+        body: data === undefined ? undefined : JSON.stringify(data),
+        signal: AbortSignal.timeout(this.timeout),
+      });
+    } catch (err) {
+      if (this.autoRetry && retryCount < this.maxRetries) {
+        return retry();
+      }
+      throw new VerificationError(` + "`Request failed: ${err.message}`" + `);
+    }
+
+    if (response.status === 401) {
+      throw new AuthenticationError("Authentication failed - invalid agent credentials");
+    }
+    if (response.status >= 500 && this.autoRetry && retryCount < this.maxRetries) {
+      return retry();
+    }
+
+    const body = await response.json().catch(() => ({}));
+    if (!response.ok && response.status !== 403) {
+      throw new VerificationError(` + "`Request failed with status ${response.status}: ${body.error || \"unknown error\"}`" + `);
+    }
+    return body;
+  }
+
+  /** Request verification for an action from AIM. */
+  async verifyAction(actionType, { resource = null, context = {}, timeoutSeconds = 300 } = {}) {
+    const timestamp = new Date().toISOString();
+
+    const signaturePayload = {
+      action_type: actionType,
+      agent_id: this.agentId,
+      context: context || {},
+      resource: resource || null,
+      timestamp,
+    };
+    const signature = this.signMessage(canonicalJSON(signaturePayload));
+
+    const result = await this.makeRequest("POST", "/api/v1/sdk-api/verifications", {
+      agent_id: this.agentId,
+      action_type: actionType,
+      resource: resource || null,
+      context: context || {},
+      timestamp,
+      signature,
+      public_key: this.publicKey,
+    });
+
+    if (result.status === "approved") {
+      return {
+        verified: true,
+        verificationId: result.id,
+        approvedBy: result.approved_by,
+        expiresAt: result.expires_at,
+      };
+    }
+    if (result.status === "denied") {
+      throw new ActionDeniedError(` + "`Action denied: ${result.denial_reason || \"Action denied by policy\"}`" + `);
+    }
+    if (result.status === "pending") {
+      return this.waitForApproval(result.id, timeoutSeconds);
+    }
+    throw new VerificationError(` + "`Unexpected verification status: ${result.status}`" + `);
+  }
+
+  async waitForApproval(verificationId, timeoutSeconds) {
+    const deadline = Date.now() + timeoutSeconds * 1000;
+    let pollInterval = 2000;
+
+    while (Date.now() < deadline) {
+      const result = await this.makeRequest("GET", ` + "`/api/v1/sdk-api/verifications/${verificationId}`" + `);
+      if (result.status === "approved") {
+        return {
+          verified: true,
+          verificationId,
+          approvedBy: result.approved_by,
+          expiresAt: result.expires_at,
+        };
+      }
+      if (result.status === "denied") {
+        throw new ActionDeniedError(` + "`Action denied: ${result.denial_reason || \"Action denied\"}`" + `);
+      }
+      await sleep(pollInterval);
+      pollInterval = Math.min(pollInterval * 1.5, 10000);
+    }
+
+    throw new VerificationError(` + "`Verification timeout after ${timeoutSeconds} seconds`" + `);
+  }
+
+  /** Log the result of an action execution to AIM. */
+  async logActionResult(verificationId, { success, resultSummary = null, errorMessage = null }) {
+    try {
+      await this.makeRequest("POST", ` + "`/api/v1/sdk-api/verifications/${verificationId}/result`" + `, {
+        result: success ? "success" : "failure",
+        result_summary: resultSummary,
+        error_message: errorMessage,
+        timestamp: new Date().toISOString(),
+      });
+    } catch (_) {
+      // Result logging must never break the caller
+    }
+  }
+
+  /** Request an additional capability for the agent. */
+  async requestCapability(capabilityType, reason) {
+    if (!capabilityType) throw new ConfigurationError("capabilityType must be a non-empty string");
+    if (!reason || reason.length < 10) throw new ConfigurationError("reason must be at least 10 characters");
+
+    return this.makeRequest("POST", ` + "`/api/v1/sdk-api/agents/${this.agentId}/capability-requests`" + `, {
+      capability_type: capabilityType,
+      reason,
+    });
+  }
+
+  /**
+   * Wrap a function so every call is verified by AIM before it runs
+   * and its outcome is logged afterwards.
+   */
+  secure(actionType, options, fn) {
+    if (typeof options === "function") {
+      fn = options;
+      options = {};
+    }
+    return async (...args) => {
+      const verification = await this.verifyAction(actionType, options);
+      try {
+        const result = await fn(...args);
+        await this.logActionResult(verification.verificationId, {
+          success: true,
+          resultSummary: ` + "`Action '${actionType}' completed successfully`" + `,
+        });
+        return result;
+      } catch (err) {
+        await this.logActionResult(verification.verificationId, {
+          success: false,
+          errorMessage: String(err && err.message ? err.message : err),
+        });
+        throw err;
+      }
+    };
+  }
+}
+
+module.exports = { AIMClient, canonicalJSON };
+`
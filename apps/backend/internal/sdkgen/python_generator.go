@@ -31,6 +31,7 @@ func GeneratePythonSDK(config PythonSDKConfig) ([]byte, error) {
 		"setup.py":              pythonSetupFile,
 		"README.md":             generatePythonReadme(config),
 		"requirements.txt":      pythonRequirementsFile,
+		".env.example":          generatePythonEnvExample(config),
 		"example.py":            generatePythonExample(config),
 	}
 
@@ -61,15 +62,19 @@ AIM SDK Configuration - Auto-generated by AIM
     - Never commit this file to version control
     - Never share this file publicly
     - Store securely and use environment variables in production
+
+Environment variables that are set (see .env.example) override the embedded values.
 """
 
+import os
+
 # Agent credentials (automatically generated by AIM)
-AGENT_ID = "{{.AgentID}}"
-PUBLIC_KEY = "{{.PublicKey}}"
-PRIVATE_KEY = "{{.PrivateKey}}"
+AGENT_ID = os.environ.get("AIM_AGENT_ID") or "{{.AgentID}}"
+PUBLIC_KEY = os.environ.get("AIM_PUBLIC_KEY") or "{{.PublicKey}}"
+PRIVATE_KEY = os.environ.get("AIM_PRIVATE_KEY") or "{{.PrivateKey}}"
 
 # AIM server URL
-AIM_URL = "{{.AIMURL}}"
+AIM_URL = os.environ.get("AIM_URL") or "{{.AIMURL}}"
 
 # Agent metadata
 AGENT_NAME = "{{.AgentName}}"
@@ -208,16 +213,29 @@ This SDK package contains your agent's **private key** embedded in ` + "`aim_sdk
 ### 1. Install SDK
 
 ` + "```bash" + `
+pip install -r requirements.txt
 pip install -e .
 ` + "```" + `
 
-### 2. Run Example
+### 2. Configure (optional)
+
+The credentials of **{{.AgentName}}** are embedded in ` + "`aim_sdk/config.py`" + ` and the SDK talks to
+{{.AIMURL}} out of the box. In production, keep the keys out of the code: copy
+` + "`.env.example`" + ` to ` + "`.env`" + `, fill in the keys and load it before starting your agent.
+Environment variables take precedence over the embedded values.
+
+` + "```bash" + `
+cp .env.example .env
+set -a; . ./.env; set +a
+` + "```" + `
+
+### 3. Run Example
 
 ` + "```bash" + `
 python example.py
 ` + "```" + `
 
-### 3. Use in Your Agent
+### 4. Use in Your Agent
 
 ` + "```python" + `
 from aim_sdk import AIMClient
@@ -274,6 +292,26 @@ Generated by AIM (Agent Identity Management)
 	return result.String()
 }
 
+// generatePythonEnvExample generates .env.example with the variables config.py reads. The keys are
+// left blank so the template can be shared; config.py falls back to the embedded ones.
+func generatePythonEnvExample(config PythonSDKConfig) string {
+	tmpl := `# AIM SDK environment for agent: {{.AgentName}}
+# Copy to .env and load it before starting the agent; these override aim_sdk/config.py.
+
+AIM_URL={{.AIMURL}}
+AIM_AGENT_ID={{.AgentID}}
+
+# Never commit the filled-in .env file
+AIM_PUBLIC_KEY=
+AIM_PRIVATE_KEY=
+`
+
+	t := template.Must(template.New("env").Parse(tmpl))
+	var result bytes.Buffer
+	t.Execute(&result, config)
+	return result.String()
+}
+
 // Python SDK file templates (using the actual SDK code we created)
 const pythonInitFile = `"""
 AIM Python SDK - Automatic Identity Verification for AI Agents
@@ -688,6 +726,7 @@ setup(
 )
 `
 
-const pythonRequirementsFile = `requests>=2.28.0
-PyNaCl>=1.5.0
+// pythonRequirementsFile pins the versions the SDK is tested with; setup.py keeps the minimums
+const pythonRequirementsFile = `requests==2.32.3
+PyNaCl==1.5.0
 `
@@ -0,0 +1,13 @@
+-- Migration: Add auto-verification settings to organizations
+-- Lets each organization raise the trust threshold required for automatic
+-- agent verification, or disable auto-verification entirely.
+
+ALTER TABLE organizations
+ADD COLUMN IF NOT EXISTS auto_verify_enabled BOOLEAN NOT NULL DEFAULT TRUE;
+
+ALTER TABLE organizations
+ADD COLUMN IF NOT EXISTS auto_verify_min_trust DECIMAL(4,3) NOT NULL DEFAULT 0.300
+    CHECK (auto_verify_min_trust >= 0 AND auto_verify_min_trust <= 1);
+
+COMMENT ON COLUMN organizations.auto_verify_enabled IS 'When false, newly created agents stay pending until an admin verifies them';
+COMMENT ON COLUMN organizations.auto_verify_min_trust IS 'Minimum initial trust score (0-1) required for automatic agent verification';
@@ -0,0 +1,31 @@
+-- Migration: Add retry state to webhook deliveries
+-- webhook_deliveries previously stored one row per single-shot attempt. Failed
+-- deliveries are now retried with exponential backoff, so each row tracks the
+-- delivery's current status and when the next attempt is due.
+
+ALTER TABLE webhook_deliveries
+ADD COLUMN IF NOT EXISTS status VARCHAR(20) NOT NULL DEFAULT 'succeeded'
+    CHECK (status IN ('pending', 'retrying', 'succeeded', 'failed'));
+
+ALTER TABLE webhook_deliveries
+ADD COLUMN IF NOT EXISTS error_message TEXT;
+
+ALTER TABLE webhook_deliveries
+ADD COLUMN IF NOT EXISTS next_attempt_at TIMESTAMPTZ;
+
+ALTER TABLE webhook_deliveries
+ADD COLUMN IF NOT EXISTS last_attempt_at TIMESTAMPTZ;
+
+ALTER TABLE webhook_deliveries
+ADD COLUMN IF NOT EXISTS updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP;
+
+-- Existing rows were single attempts: mark the unsuccessful ones as failed
+UPDATE webhook_deliveries SET status = 'failed' WHERE success = false;
+
+-- Retry worker looks up deliveries that are due for another attempt
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_due
+    ON webhook_deliveries(next_attempt_at)
+    WHERE status = 'retrying';
+
+COMMENT ON COLUMN webhook_deliveries.status IS 'Delivery status: pending, retrying, succeeded, failed (retries exhausted)';
+COMMENT ON COLUMN webhook_deliveries.next_attempt_at IS 'When the next retry is due (NULL once the delivery succeeded or failed)';
@@ -0,0 +1,6 @@
+-- Migration: Show webhook secrets once
+-- Webhook secrets are now shown to the user once (on creation or rotation) and are never
+-- returned by the API afterwards. The secret itself stays the HMAC key for the X-AIM-Signature
+-- header on outgoing webhook deliveries, so it is kept as is: 080 moves it into an encrypted column.
+
+COMMENT ON COLUMN webhooks.secret IS 'Webhook secret; shown once on creation or rotation, HMAC-SHA256 key for X-AIM-Signature';
@@ -0,0 +1,21 @@
+-- Migration: Soft-delete agents
+-- Deleting an agent sets deleted_at instead of removing the row so audit logs
+-- and compliance history keep pointing at a real agent.
+
+ALTER TABLE agents
+    ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMPTZ;
+
+CREATE INDEX IF NOT EXISTS idx_agents_org_not_deleted
+    ON agents(organization_id, created_at DESC)
+    WHERE deleted_at IS NULL;
+
+-- Agent names only need to be unique among live agents so a deleted agent's
+-- name can be reused
+ALTER TABLE agents
+    DROP CONSTRAINT IF EXISTS agents_organization_id_name_key;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_agents_org_name_not_deleted
+    ON agents(organization_id, name)
+    WHERE deleted_at IS NULL;
+
+COMMENT ON COLUMN agents.deleted_at IS 'Set when the agent is soft-deleted; NULL for live agents';
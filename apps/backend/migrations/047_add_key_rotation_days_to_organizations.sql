@@ -0,0 +1,10 @@
+-- Migration: Add configurable key expiration period to organizations
+-- Agent keys issued on rotation or public key registration expire after this
+-- many days. Security teams with shorter rotation policies (e.g. 90 days) can
+-- lower it.
+
+ALTER TABLE organizations
+ADD COLUMN IF NOT EXISTS key_rotation_days INTEGER NOT NULL DEFAULT 365
+    CHECK (key_rotation_days >= 7 AND key_rotation_days <= 3650);
+
+COMMENT ON COLUMN organizations.key_rotation_days IS 'Days until a newly issued agent key expires (7-3650)';
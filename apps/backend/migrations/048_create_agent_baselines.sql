@@ -0,0 +1,17 @@
+-- Migration: Create agent_baselines table
+-- Snapshot of an agent's configuration taken when it is verified. Config drift
+-- policies compare the agent's current capabilities, talks_to and key algorithm
+-- against this snapshot.
+
+CREATE TABLE IF NOT EXISTS agent_baselines (
+    agent_id UUID PRIMARY KEY REFERENCES agents(id) ON DELETE CASCADE,
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    capabilities JSONB NOT NULL DEFAULT '[]'::jsonb,
+    talks_to JSONB NOT NULL DEFAULT '[]'::jsonb,
+    key_algorithm VARCHAR(50) NOT NULL DEFAULT '',
+    captured_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_agent_baselines_organization_id ON agent_baselines(organization_id);
+
+COMMENT ON TABLE agent_baselines IS 'Agent configuration snapshot captured at verification time for config drift detection';
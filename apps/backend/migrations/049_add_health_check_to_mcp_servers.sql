@@ -0,0 +1,10 @@
+-- Migration: Add health check state to mcp_servers
+-- The health poller probes each server URL and records the outcome here.
+-- Local placeholder URLs (mcp://...) are recorded as not_checkable.
+
+ALTER TABLE mcp_servers
+ADD COLUMN IF NOT EXISTS last_health_check TIMESTAMPTZ,
+ADD COLUMN IF NOT EXISTS health_status VARCHAR(20) NOT NULL DEFAULT 'unknown';
+
+COMMENT ON COLUMN mcp_servers.last_health_check IS 'When the server URL was last probed';
+COMMENT ON COLUMN mcp_servers.health_status IS 'Result of the last probe: unknown, healthy, unhealthy, unreachable or not_checkable';
@@ -0,0 +1,21 @@
+-- Migration: Add expiry to capability requests
+-- Pending requests that are not reviewed before expires_at are rejected by the expiry sweep.
+-- admin_reminded_at records when admins were reminded about a long-pending request.
+
+ALTER TABLE capability_requests
+ADD COLUMN IF NOT EXISTS expires_at TIMESTAMPTZ,
+ADD COLUMN IF NOT EXISTS admin_reminded_at TIMESTAMPTZ;
+
+UPDATE capability_requests
+SET expires_at = requested_at + INTERVAL '7 days'
+WHERE expires_at IS NULL;
+
+ALTER TABLE capability_requests
+ALTER COLUMN expires_at SET NOT NULL,
+ALTER COLUMN expires_at SET DEFAULT NOW() + INTERVAL '7 days';
+
+CREATE INDEX IF NOT EXISTS idx_capability_requests_pending_expires_at
+ON capability_requests(expires_at) WHERE status = 'pending';
+
+COMMENT ON COLUMN capability_requests.expires_at IS 'When a pending request is automatically rejected';
+COMMENT ON COLUMN capability_requests.admin_reminded_at IS 'When admins were reminded that the request is still pending';
@@ -0,0 +1,8 @@
+-- Migration: Add per-capability expiry to agent_capabilities
+-- A grant with expires_at set stops authorizing actions once that time has passed.
+-- Grants approved with a resource scope store it under capability_scope->>'granted_scope'.
+
+ALTER TABLE agent_capabilities
+ADD COLUMN IF NOT EXISTS expires_at TIMESTAMPTZ;
+
+COMMENT ON COLUMN agent_capabilities.expires_at IS 'When the grant stops applying (NULL = never)';
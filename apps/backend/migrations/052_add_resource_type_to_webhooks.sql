@@ -0,0 +1,8 @@
+-- Migration: Add resource type filter to webhooks
+-- A webhook with resource_type set only receives events about that kind of resource
+-- (agent, mcp_server, api_key, alert, verification, organization).
+
+ALTER TABLE webhooks
+ADD COLUMN IF NOT EXISTS resource_type TEXT;
+
+COMMENT ON COLUMN webhooks.resource_type IS 'Only deliver events about this resource type (NULL = all resource types)';
@@ -0,0 +1,25 @@
+-- Migration: Create compliance_violations table
+-- Violations detected by the compliance service are stored once per check and resource,
+-- so remediation state persists across re-detection.
+
+CREATE TABLE IF NOT EXISTS compliance_violations (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    check_key VARCHAR(100) NOT NULL,
+    framework VARCHAR(50) NOT NULL,
+    severity VARCHAR(20) NOT NULL,
+    title TEXT NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    resource_type VARCHAR(50) NOT NULL,
+    resource_id UUID NOT NULL,
+    is_remediated BOOLEAN NOT NULL DEFAULT FALSE,
+    remediated_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    remediated_at TIMESTAMPTZ,
+    remediation_notes TEXT NOT NULL DEFAULT '',
+    detected_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (organization_id, check_key, resource_type, resource_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_compliance_violations_organization_id ON compliance_violations(organization_id);
+
+COMMENT ON TABLE compliance_violations IS 'Compliance violations and their remediation state';
@@ -0,0 +1,20 @@
+-- Migration: Create compliance_check_snapshots table
+-- Scheduled compliance check runs store their result per framework so the
+-- compliance score can be shown as a trend over time.
+
+CREATE TABLE IF NOT EXISTS compliance_check_snapshots (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    framework VARCHAR(50) NOT NULL,
+    passed INTEGER NOT NULL DEFAULT 0,
+    failed INTEGER NOT NULL DEFAULT 0,
+    total INTEGER NOT NULL DEFAULT 0,
+    compliance_rate DOUBLE PRECISION NOT NULL DEFAULT 0,
+    checks JSONB NOT NULL DEFAULT '[]'::jsonb,
+    run_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_compliance_check_snapshots_org_framework_run_at
+    ON compliance_check_snapshots(organization_id, framework, run_at);
+
+COMMENT ON TABLE compliance_check_snapshots IS 'Compliance check results per framework, used for compliance score history';
@@ -0,0 +1,8 @@
+-- Migration: Add scopes to API keys
+-- Scopes ("agents:read", "verifications:write", ...) restrict what an API key can call.
+-- Existing keys keep an empty scope list, which means unrestricted access.
+
+ALTER TABLE api_keys
+ADD COLUMN IF NOT EXISTS scopes TEXT[] NOT NULL DEFAULT '{}';
+
+COMMENT ON COLUMN api_keys.scopes IS 'Granted scopes (empty = unrestricted)';
@@ -0,0 +1,23 @@
+-- Migration: Create refresh_tokens table
+-- Tracks refresh token lineage for rotation: each rotated token points at its parent and shares
+-- the family ID of the token that started the session. Presenting a token that was already
+-- rotated revokes the whole family.
+
+CREATE TABLE IF NOT EXISTS refresh_tokens (
+    token_id VARCHAR(255) PRIMARY KEY,
+    family_id UUID NOT NULL,
+    parent_token_id VARCHAR(255),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    issued_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    expires_at TIMESTAMPTZ NOT NULL,
+    rotated_at TIMESTAMPTZ,
+    revoked_at TIMESTAMPTZ,
+    revoke_reason TEXT
+);
+
+CREATE INDEX IF NOT EXISTS idx_refresh_tokens_family_id ON refresh_tokens(family_id);
+CREATE INDEX IF NOT EXISTS idx_refresh_tokens_expires_at ON refresh_tokens(expires_at);
+
+COMMENT ON TABLE refresh_tokens IS 'Refresh token rotation lineage for reuse detection';
+COMMENT ON COLUMN refresh_tokens.token_id IS 'JWT ID (jti) of the refresh token';
@@ -0,0 +1,8 @@
+-- Migration: Add password_policy to organizations
+-- Per-organization password requirements (minimum length, character classes, common password check).
+-- NULL means the organization uses the default policy.
+
+ALTER TABLE organizations
+ADD COLUMN IF NOT EXISTS password_policy JSONB;
+
+COMMENT ON COLUMN organizations.password_policy IS 'Password policy overrides (NULL = default policy)';
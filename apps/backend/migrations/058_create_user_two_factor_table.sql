@@ -0,0 +1,17 @@
+-- Migration: Create user_two_factor table
+-- Stores TOTP two-factor enrollment per user. The secret is encrypted with the key vault and
+-- backup codes are kept as SHA-256 hashes. A row with enabled = false is a pending enrollment.
+
+CREATE TABLE IF NOT EXISTS user_two_factor (
+    user_id UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    encrypted_secret TEXT NOT NULL,
+    enabled BOOLEAN NOT NULL DEFAULT FALSE,
+    enabled_at TIMESTAMPTZ,
+    backup_code_hashes TEXT[] NOT NULL DEFAULT '{}',
+    last_used_step BIGINT NOT NULL DEFAULT 0,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+COMMENT ON TABLE user_two_factor IS 'TOTP two-factor authentication enrollment per user';
+COMMENT ON COLUMN user_two_factor.last_used_step IS 'TOTP time step of the last accepted code (replay protection)';
@@ -0,0 +1,12 @@
+-- Migration: Record alert resolution
+-- acknowledged_by/acknowledged_at already record who acknowledged an alert; these columns record
+-- who resolved it, when, and an optional note about how it was handled.
+
+ALTER TABLE alerts ADD COLUMN IF NOT EXISTS resolved_by UUID REFERENCES users(id) ON DELETE SET NULL;
+ALTER TABLE alerts ADD COLUMN IF NOT EXISTS resolved_at TIMESTAMPTZ;
+ALTER TABLE alerts ADD COLUMN IF NOT EXISTS resolution_note TEXT;
+
+CREATE INDEX IF NOT EXISTS idx_alerts_resolved_at ON alerts(resolved_at);
+
+COMMENT ON COLUMN alerts.resolved_by IS 'User who resolved the alert';
+COMMENT ON COLUMN alerts.resolution_note IS 'Optional note describing how the alert was resolved';
@@ -0,0 +1,15 @@
+-- Migration: Alert deduplication
+-- Identical alerts (same organization, type, resource and title) raised within the dedup window
+-- are collapsed into the open alert: occurrence_count is incremented and last_occurred_at updated
+-- instead of inserting a new row.
+
+ALTER TABLE alerts ADD COLUMN IF NOT EXISTS occurrence_count INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE alerts ADD COLUMN IF NOT EXISTS last_occurred_at TIMESTAMPTZ;
+
+-- Supports the duplicate lookup among open alerts
+CREATE INDEX IF NOT EXISTS idx_alerts_dedup
+    ON alerts(organization_id, alert_type, resource_id, created_at DESC)
+    WHERE is_acknowledged = false;
+
+COMMENT ON COLUMN alerts.occurrence_count IS 'Number of identical alerts collapsed into this one';
+COMMENT ON COLUMN alerts.last_occurred_at IS 'When the most recent duplicate occurred (NULL if none)';
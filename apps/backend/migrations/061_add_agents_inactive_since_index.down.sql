@@ -0,0 +1,5 @@
+-- Revert 061: agents "inactive since" index
+
+DROP INDEX IF EXISTS idx_agents_org_last_active;
+
+COMMENT ON COLUMN agents.last_active IS 'Timestamp of when agent last performed an action (updated on every verify-action call)';
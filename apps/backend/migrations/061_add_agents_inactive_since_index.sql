@@ -0,0 +1,10 @@
+-- Migration: Index agents for "inactive since" queries
+-- last_active is now bumped by verified actions, agent-initiated verification events and MCP
+-- attestations. Inactivity reports filter an organization's live agents by last_active, which the
+-- global idx_agents_last_active index cannot serve.
+
+CREATE INDEX IF NOT EXISTS idx_agents_org_last_active
+    ON agents(organization_id, last_active)
+    WHERE deleted_at IS NULL;
+
+COMMENT ON COLUMN agents.last_active IS 'When the agent was last seen: verified actions, agent-initiated verification events and MCP attestations';
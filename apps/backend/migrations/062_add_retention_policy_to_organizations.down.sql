@@ -0,0 +1,7 @@
+-- Revert 062: per-organization data retention
+
+DROP INDEX IF EXISTS idx_alerts_org_resolved_at;
+DROP INDEX IF EXISTS idx_verification_events_org_created_at;
+DROP INDEX IF EXISTS idx_audit_logs_org_timestamp;
+
+ALTER TABLE organizations DROP COLUMN IF EXISTS retention_policy;
@@ -0,0 +1,15 @@
+-- Migration: Per-organization data retention
+-- retention_policy holds how many days audit logs, verification events and resolved alerts are
+-- kept. NULL means the organization uses the default policy. The retention job (cmd/retention, or
+-- the in-server scheduler when DATA_RETENTION_INTERVAL is set) deletes older rows in batches.
+
+ALTER TABLE organizations
+ADD COLUMN IF NOT EXISTS retention_policy JSONB;
+
+COMMENT ON COLUMN organizations.retention_policy IS 'Data retention overrides in days (NULL = default policy)';
+
+-- Let each purge batch find an organization's oldest rows without scanning the whole table
+CREATE INDEX IF NOT EXISTS idx_audit_logs_org_timestamp ON audit_logs(organization_id, timestamp);
+CREATE INDEX IF NOT EXISTS idx_verification_events_org_created_at ON verification_events(organization_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_alerts_org_resolved_at ON alerts(organization_id, resolved_at)
+    WHERE resolved_at IS NOT NULL;
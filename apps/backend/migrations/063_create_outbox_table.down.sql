@@ -0,0 +1,3 @@
+-- Revert 063: transactional outbox
+
+DROP TABLE IF EXISTS outbox;
@@ -0,0 +1,24 @@
+-- Migration: Create outbox table
+-- Side effects of a business change (alerts, webhook events) are written here in the same
+-- transaction as the change. The outbox relay in the server dispatches pending rows and marks
+-- them delivered, so a crash between the commit and the side effect no longer loses it.
+
+CREATE TABLE IF NOT EXISTS outbox (
+    id UUID PRIMARY KEY,
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    topic VARCHAR(50) NOT NULL,
+    payload JSONB NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    attempts INTEGER NOT NULL DEFAULT 0,
+    last_error TEXT,
+    available_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    delivered_at TIMESTAMPTZ
+);
+
+-- The relay only ever looks for pending rows that are due
+CREATE INDEX IF NOT EXISTS idx_outbox_pending ON outbox(available_at)
+    WHERE status = 'pending';
+
+COMMENT ON TABLE outbox IS 'Transactional outbox: side effects awaiting dispatch by the relay';
+COMMENT ON COLUMN outbox.available_at IS 'When the relay may next claim the row (pushed back while a relay holds it)';
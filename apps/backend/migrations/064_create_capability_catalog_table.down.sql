@@ -0,0 +1,3 @@
+-- Revert 064: organization-scoped capability catalog
+
+DROP TABLE IF EXISTS capability_catalog;
@@ -0,0 +1,17 @@
+-- Migration: Organization-scoped capability catalog
+-- Lists the capability types an organization allows its agents to be granted. New organizations
+-- are seeded with the default catalog; an organization without rows (including those created
+-- before this migration) uses the default catalog until rows are added.
+
+CREATE TABLE IF NOT EXISTS capability_catalog (
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    capability_type VARCHAR(100) NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    category VARCHAR(50) NOT NULL DEFAULT '',
+    risk_level VARCHAR(20) NOT NULL DEFAULT 'medium',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (organization_id, capability_type)
+);
+
+COMMENT ON TABLE capability_catalog IS 'Capability types each organization allows to be granted to its agents';
@@ -0,0 +1,3 @@
+-- Revert 065: trust score decay half-life
+
+ALTER TABLE organizations DROP COLUMN IF EXISTS trust_decay_half_life_days;
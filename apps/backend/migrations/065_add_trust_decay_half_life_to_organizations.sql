@@ -0,0 +1,11 @@
+-- Migration: Add configurable trust score decay to organizations
+-- The activity-derived trust factors of an agent halve for every
+-- trust_decay_half_life_days it stays inactive, so an agent verified long ago
+-- that has done nothing since no longer keeps its original score. 0 disables
+-- decay and is the default, so organizations opt in.
+
+ALTER TABLE organizations
+ADD COLUMN IF NOT EXISTS trust_decay_half_life_days INTEGER NOT NULL DEFAULT 0
+    CHECK (trust_decay_half_life_days = 0 OR (trust_decay_half_life_days >= 7 AND trust_decay_half_life_days <= 3650));
+
+COMMENT ON COLUMN organizations.trust_decay_half_life_days IS 'Days of agent inactivity that halve activity-derived trust factors (0 disables, else 7-3650)';
@@ -0,0 +1,4 @@
+-- Revert 066: agent labels
+
+DROP INDEX IF EXISTS idx_agents_labels;
+ALTER TABLE agents DROP COLUMN IF EXISTS labels;
@@ -0,0 +1,12 @@
+-- Migration: Add key-value labels to agents
+-- Labels are structured metadata (e.g. {"env": "prod", "team": "payments"}) used
+-- to filter agent listings and target security policies. Unlike tags they are
+-- stored on the agent itself, one value per key.
+
+ALTER TABLE agents
+ADD COLUMN IF NOT EXISTS labels JSONB NOT NULL DEFAULT '{}'::jsonb;
+
+-- Label selectors are evaluated with labels @> '{"key": "value"}'
+CREATE INDEX IF NOT EXISTS idx_agents_labels ON agents USING GIN (labels);
+
+COMMENT ON COLUMN agents.labels IS 'Key-value labels for filtering and policy targeting, e.g. {"env": "prod"}';
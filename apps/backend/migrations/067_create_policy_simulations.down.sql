@@ -0,0 +1,3 @@
+-- Revert 067: policy simulations
+
+DROP TABLE IF EXISTS policy_simulations;
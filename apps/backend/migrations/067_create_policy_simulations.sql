@@ -0,0 +1,21 @@
+-- Migration: Create policy_simulations table
+-- Security policies with enforcement_action 'simulate' never block. Each action they would have
+-- blocked is recorded here, once per policy and audit ID, so admins can review the impact of a
+-- policy before switching it to block_and_alert.
+
+CREATE TABLE IF NOT EXISTS policy_simulations (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    policy_id UUID NOT NULL REFERENCES security_policies(id) ON DELETE CASCADE,
+    agent_id UUID NOT NULL,
+    action_type VARCHAR(255) NOT NULL,
+    resource TEXT NOT NULL DEFAULT '',
+    audit_id UUID NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (policy_id, audit_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_policy_simulations_policy_created_at
+    ON policy_simulations(policy_id, created_at DESC);
+
+COMMENT ON TABLE policy_simulations IS 'Actions that simulate-mode security policies would have blocked';
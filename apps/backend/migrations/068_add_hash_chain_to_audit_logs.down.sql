@@ -0,0 +1,7 @@
+-- Revert 068: audit log hash chain
+
+DROP INDEX IF EXISTS idx_audit_logs_org_chain_sequence;
+
+ALTER TABLE audit_logs DROP COLUMN IF EXISTS entry_hash;
+ALTER TABLE audit_logs DROP COLUMN IF EXISTS prev_hash;
+ALTER TABLE audit_logs DROP COLUMN IF EXISTS chain_sequence;
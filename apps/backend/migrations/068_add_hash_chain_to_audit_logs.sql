@@ -0,0 +1,17 @@
+-- Migration: Hash chain audit logs
+-- Each organization's audit log is a hash chain: entry_hash is the SHA-256 of the entry's fields
+-- and prev_hash, the entry_hash of the entry before it in chain_sequence order. Rows written
+-- before this migration have no chain columns and are not verified.
+
+ALTER TABLE audit_logs ADD COLUMN IF NOT EXISTS chain_sequence BIGINT;
+ALTER TABLE audit_logs ADD COLUMN IF NOT EXISTS prev_hash VARCHAR(64);
+ALTER TABLE audit_logs ADD COLUMN IF NOT EXISTS entry_hash VARCHAR(64);
+
+-- Finds the chain head on insert, walks the chain on verification and rejects forked chains
+CREATE UNIQUE INDEX IF NOT EXISTS idx_audit_logs_org_chain_sequence
+    ON audit_logs(organization_id, chain_sequence)
+    WHERE chain_sequence IS NOT NULL;
+
+COMMENT ON COLUMN audit_logs.chain_sequence IS 'Position of the entry in its organization''s hash chain, starting at 1';
+COMMENT ON COLUMN audit_logs.prev_hash IS 'entry_hash of the previous entry in the chain (empty for the first)';
+COMMENT ON COLUMN audit_logs.entry_hash IS 'SHA-256 over the entry''s fields and prev_hash';
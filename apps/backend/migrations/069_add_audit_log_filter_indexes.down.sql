@@ -0,0 +1,5 @@
+-- Revert 069: audit log filter indexes
+
+DROP INDEX IF EXISTS idx_audit_logs_org_user_timestamp;
+DROP INDEX IF EXISTS idx_audit_logs_org_resource_timestamp;
+DROP INDEX IF EXISTS idx_audit_logs_org_action_timestamp;
@@ -0,0 +1,10 @@
+-- Migration: Index audit log filters
+-- The admin audit log search filters an organization's logs by action, resource or user and
+-- pages them newest first; date ranges alone use idx_audit_logs_org_timestamp (migration 062).
+
+CREATE INDEX IF NOT EXISTS idx_audit_logs_org_action_timestamp
+    ON audit_logs(organization_id, action, timestamp DESC);
+CREATE INDEX IF NOT EXISTS idx_audit_logs_org_resource_timestamp
+    ON audit_logs(organization_id, resource_type, resource_id, timestamp DESC);
+CREATE INDEX IF NOT EXISTS idx_audit_logs_org_user_timestamp
+    ON audit_logs(organization_id, user_id, timestamp DESC);
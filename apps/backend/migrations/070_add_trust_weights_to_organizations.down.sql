@@ -0,0 +1,3 @@
+-- Revert 070: per-organization trust score weights
+
+ALTER TABLE organizations DROP COLUMN IF EXISTS trust_weights;
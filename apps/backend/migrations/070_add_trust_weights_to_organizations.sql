@@ -0,0 +1,9 @@
+-- Migration: Per-organization trust score weights
+-- trust_weights holds the weight of each of the eight trust score factors, adding up to 1.0.
+-- NULL means the organization uses the default weights. Existing scores keep their old weights
+-- until they are recalculated (POST /api/v1/admin/trust-score/recalculate-all or cmd/recalc_trust).
+
+ALTER TABLE organizations
+ADD COLUMN IF NOT EXISTS trust_weights JSONB;
+
+COMMENT ON COLUMN organizations.trust_weights IS 'Trust score factor weights adding up to 1.0 (NULL = default weights)';
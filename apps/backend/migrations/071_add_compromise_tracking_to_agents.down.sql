@@ -0,0 +1,6 @@
+-- Revert 071: compromise tracking on agents
+
+ALTER TABLE agents
+DROP COLUMN IF EXISTS compromise_reason,
+DROP COLUMN IF EXISTS compromised_by,
+DROP COLUMN IF EXISTS compromised_at;
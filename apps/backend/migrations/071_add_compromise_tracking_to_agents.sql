@@ -0,0 +1,12 @@
+-- Migration: Record who flagged an agent as compromised
+-- POST /api/v1/agents/:id/compromise sets is_compromised together with these columns;
+-- /uncompromise clears all four. The user is kept as NULL if their account is deleted.
+
+ALTER TABLE agents
+ADD COLUMN IF NOT EXISTS compromised_at TIMESTAMPTZ,
+ADD COLUMN IF NOT EXISTS compromised_by UUID REFERENCES users(id) ON DELETE SET NULL,
+ADD COLUMN IF NOT EXISTS compromise_reason TEXT;
+
+COMMENT ON COLUMN agents.compromised_at IS 'When the agent was flagged as compromised (NULL = not compromised)';
+COMMENT ON COLUMN agents.compromised_by IS 'User who flagged the agent as compromised';
+COMMENT ON COLUMN agents.compromise_reason IS 'Reason given when the agent was flagged as compromised';
@@ -0,0 +1,3 @@
+-- Revert 072: password_reset_tokens table
+
+DROP TABLE IF EXISTS password_reset_tokens;
@@ -0,0 +1,24 @@
+-- Migration: Create password_reset_tokens table
+-- Reset tokens are stored as SHA-256 hashes and redeemed at most once: used_at is set when the
+-- token resets the password, or when a newer reset request supersedes it.
+-- Plaintext tokens in users.password_reset_token are no longer read and are cleared here.
+
+CREATE TABLE IF NOT EXISTS password_reset_tokens (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    token_hash VARCHAR(64) NOT NULL UNIQUE,
+    expires_at TIMESTAMPTZ NOT NULL,
+    used_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_password_reset_tokens_user_unused
+    ON password_reset_tokens(user_id) WHERE used_at IS NULL;
+
+UPDATE users
+SET password_reset_token = NULL, password_reset_expires_at = NULL
+WHERE password_reset_token IS NOT NULL;
+
+COMMENT ON TABLE password_reset_tokens IS 'Single-use, time-boxed password reset tokens';
+COMMENT ON COLUMN password_reset_tokens.token_hash IS 'Hex SHA-256 of the token sent in the reset link';
+COMMENT ON COLUMN password_reset_tokens.used_at IS 'When the token was redeemed or superseded (NULL = outstanding)';
@@ -0,0 +1,4 @@
+-- Revert 073: last_user_agent on sdk_tokens
+
+ALTER TABLE sdk_tokens
+DROP COLUMN IF EXISTS last_user_agent;
@@ -0,0 +1,11 @@
+-- Migration: Record the client that last used an SDK token
+-- SDKTokenTrackingMiddleware updates last_used_at, last_ip_address and last_user_agent for the
+-- token named in the X-SDK-Token header of authenticated requests. user_agent keeps the client
+-- the token was issued to.
+
+ALTER TABLE sdk_tokens
+ADD COLUMN IF NOT EXISTS last_user_agent TEXT;
+
+COMMENT ON COLUMN sdk_tokens.last_used_at IS 'When the token was last used (throttled to about one write per minute)';
+COMMENT ON COLUMN sdk_tokens.last_ip_address IS 'Client IP address of the last use';
+COMMENT ON COLUMN sdk_tokens.last_user_agent IS 'User-Agent of the last use';
@@ -0,0 +1,5 @@
+-- Revert 074: report tracking on agent_mcp_detections
+
+ALTER TABLE agent_mcp_detections
+DROP COLUMN IF EXISTS report_count,
+DROP COLUMN IF EXISTS last_reported_at;
@@ -0,0 +1,21 @@
+-- Migration: Merge repeated MCP detection reports into agent_mcp_detections
+-- Every report now upserts its agent+mcp+method row instead of adding an audit row; only
+-- significant detections (first one, then at most one per deduplication window) are kept in
+-- the detections audit table. last_seen_at keeps marking the last significant detection.
+
+ALTER TABLE agent_mcp_detections
+ADD COLUMN IF NOT EXISTS last_reported_at TIMESTAMPTZ,
+ADD COLUMN IF NOT EXISTS report_count INTEGER NOT NULL DEFAULT 1;
+
+UPDATE agent_mcp_detections
+SET last_reported_at = last_seen_at
+WHERE last_reported_at IS NULL;
+
+ALTER TABLE agent_mcp_detections
+ALTER COLUMN last_reported_at SET NOT NULL,
+ALTER COLUMN last_reported_at SET DEFAULT NOW();
+
+COMMENT ON COLUMN agent_mcp_detections.confidence_score IS 'Highest confidence reported for this MCP-agent pair';
+COMMENT ON COLUMN agent_mcp_detections.last_seen_at IS 'When this MCP was most recently detected significantly (outside the deduplication window)';
+COMMENT ON COLUMN agent_mcp_detections.last_reported_at IS 'When this MCP was most recently reported, including deduplicated reports';
+COMMENT ON COLUMN agent_mcp_detections.report_count IS 'Number of reports merged into this row';
@@ -0,0 +1,3 @@
+-- Revert 075: require_capability_approval
+
+ALTER TABLE organizations DROP COLUMN IF EXISTS require_capability_approval;
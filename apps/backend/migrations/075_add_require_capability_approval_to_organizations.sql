@@ -0,0 +1,9 @@
+-- Migration: Let organizations require admin approval for capabilities declared at registration
+-- By default an agent's declared capabilities are granted when it is created. With
+-- require_capability_approval set, they are filed as pending capability requests instead, and
+-- the agent cannot perform any action until an admin approves them.
+
+ALTER TABLE organizations
+ADD COLUMN IF NOT EXISTS require_capability_approval BOOLEAN NOT NULL DEFAULT FALSE;
+
+COMMENT ON COLUMN organizations.require_capability_approval IS 'Declared capabilities of new agents become capability requests instead of auto-grants';
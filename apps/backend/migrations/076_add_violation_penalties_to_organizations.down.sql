@@ -0,0 +1,3 @@
+-- Revert 076: per-organization violation penalties
+
+ALTER TABLE organizations DROP COLUMN IF EXISTS violation_penalties;
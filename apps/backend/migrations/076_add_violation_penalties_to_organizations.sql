@@ -0,0 +1,9 @@
+-- Migration: Per-organization trust score penalties for capability violations
+-- violation_penalties holds the penalty, in trust score percentage points, of a violation of each
+-- severity, separately for blocked and alert-only violations. NULL means the organization uses
+-- the default penalties.
+
+ALTER TABLE organizations
+ADD COLUMN IF NOT EXISTS violation_penalties JSONB;
+
+COMMENT ON COLUMN organizations.violation_penalties IS 'Trust score penalties per violation severity, for blocked and alert-only violations (NULL = default penalties)';
@@ -0,0 +1,3 @@
+-- Revert 077: unscoped API keys are unrestricted again
+
+COMMENT ON COLUMN api_keys.scopes IS 'Granted scopes (empty = unrestricted)';
@@ -0,0 +1,4 @@
+-- Migration: Unscoped API keys are read-only
+-- A key without scopes may only call read endpoints; write access needs an explicit scope.
+
+COMMENT ON COLUMN api_keys.scopes IS 'Granted scopes (empty = read-only)';
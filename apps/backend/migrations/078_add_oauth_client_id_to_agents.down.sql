@@ -0,0 +1,3 @@
+-- Revert 078: agent OAuth client binding
+
+ALTER TABLE agents DROP COLUMN IF EXISTS oauth_client_id;
@@ -0,0 +1,7 @@
+-- Migration: Bind agents to an OAuth client
+-- OAuth token verification only accepts tokens issued to the agent's client.
+
+ALTER TABLE agents
+ADD COLUMN IF NOT EXISTS oauth_client_id VARCHAR(255);
+
+COMMENT ON COLUMN agents.oauth_client_id IS 'OAuth client (client_id or sub) whose tokens verify the agent';
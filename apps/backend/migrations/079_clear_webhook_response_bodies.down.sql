@@ -0,0 +1,3 @@
+-- Revert 079: cleared response bodies cannot be restored
+
+COMMENT ON COLUMN webhook_deliveries.response_body IS NULL;
@@ -0,0 +1,6 @@
+-- Migration: Stop keeping webhook receiver responses
+-- Response bodies of webhook receivers are no longer stored or returned; clear the ones already kept.
+
+UPDATE webhook_deliveries SET response_body = NULL WHERE response_body IS NOT NULL;
+
+COMMENT ON COLUMN webhook_deliveries.response_body IS 'Unused: receiver responses are not stored';
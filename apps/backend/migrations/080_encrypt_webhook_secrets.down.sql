@@ -0,0 +1,8 @@
+-- Revert 080: encrypted secrets cannot be decrypted in SQL, so webhooks whose plaintext secret was
+-- already cleared get a random one; rotate their secrets after rolling back
+
+UPDATE webhooks SET secret = md5(random()::text) WHERE secret IS NULL;
+ALTER TABLE webhooks ALTER COLUMN secret SET NOT NULL;
+ALTER TABLE webhooks DROP COLUMN IF EXISTS encrypted_secret;
+
+COMMENT ON COLUMN webhooks.secret IS 'Webhook secret; shown once on creation or rotation, HMAC-SHA256 key for X-AIM-Signature';
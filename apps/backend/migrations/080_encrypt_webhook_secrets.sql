@@ -0,0 +1,26 @@
+-- Migration: Store webhook secrets encrypted
+-- New and rotated secrets are stored encrypted with the key vault and deliveries are signed with
+-- the decrypted secret. Existing plaintext secrets are encrypted by the server at startup, which
+-- then clears the plaintext column.
+--
+-- Databases that ran the earlier version of 045 hold a SHA-256 digest instead of the secret. The
+-- digest was the HMAC key, so anyone able to read it could forge deliveries: it is dropped, and
+-- those webhooks stop delivering until their secret is rotated.
+
+DO $$
+BEGIN
+    IF EXISTS (
+        SELECT 1 FROM information_schema.columns
+        WHERE table_name = 'webhooks' AND column_name = 'secret_hash'
+    ) THEN
+        ALTER TABLE webhooks RENAME COLUMN secret_hash TO secret;
+        ALTER TABLE webhooks ALTER COLUMN secret DROP NOT NULL;
+        UPDATE webhooks SET secret = NULL;
+    END IF;
+END $$;
+
+ALTER TABLE webhooks ADD COLUMN IF NOT EXISTS encrypted_secret TEXT;
+ALTER TABLE webhooks ALTER COLUMN secret DROP NOT NULL;
+
+COMMENT ON COLUMN webhooks.encrypted_secret IS 'Webhook secret encrypted with the key vault; HMAC-SHA256 key for X-AIM-Signature';
+COMMENT ON COLUMN webhooks.secret IS 'Legacy plaintext secret; encrypted into encrypted_secret at server startup and cleared';
@@ -0,0 +1,3 @@
+-- Revert 081: Drop compliance violation cleared_at
+
+ALTER TABLE compliance_violations DROP COLUMN IF EXISTS cleared_at;
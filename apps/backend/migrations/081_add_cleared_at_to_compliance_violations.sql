@@ -0,0 +1,7 @@
+-- Migration: Track when compliance violation conditions clear
+-- A remediated violation whose condition stops firing is marked cleared; if the condition fires
+-- again later, the violation is reopened instead of staying remediated.
+
+ALTER TABLE compliance_violations ADD COLUMN IF NOT EXISTS cleared_at TIMESTAMPTZ;
+
+COMMENT ON COLUMN compliance_violations.cleared_at IS 'When detection last found the condition no longer holding; NULL while it still fires';
@@ -0,0 +1,9 @@
+-- Revert 082: Index only retrying webhook deliveries as due
+
+DROP INDEX IF EXISTS idx_webhook_deliveries_due;
+
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_due
+    ON webhook_deliveries(next_attempt_at)
+    WHERE status = 'retrying';
+
+COMMENT ON COLUMN webhook_deliveries.next_attempt_at IS 'When the next retry is due (NULL once the delivery succeeded or failed)';
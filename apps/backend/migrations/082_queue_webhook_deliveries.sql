@@ -0,0 +1,11 @@
+-- Migration: Queue webhook deliveries for the retry worker
+-- Webhook events relayed from the outbox are recorded as pending deliveries that are due
+-- immediately and sent by the retry worker, so the due-delivery index covers pending rows too.
+
+DROP INDEX IF EXISTS idx_webhook_deliveries_due;
+
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_due
+    ON webhook_deliveries(next_attempt_at)
+    WHERE status IN ('pending', 'retrying');
+
+COMMENT ON COLUMN webhook_deliveries.next_attempt_at IS 'When the next attempt is due (NULL while the first attempt is in flight and once the delivery succeeded or failed)';
@@ -0,0 +1,5 @@
+-- Revert 083: logo_url, email_locale, locale
+
+ALTER TABLE user_registration_requests DROP COLUMN IF EXISTS locale;
+ALTER TABLE organizations DROP COLUMN IF EXISTS email_locale;
+ALTER TABLE organizations DROP COLUMN IF EXISTS logo_url;
@@ -0,0 +1,18 @@
+-- Migration: Brand and localize the emails sent to an organization's users
+-- logo_url is shown in the email header next to the organization name. email_locale is the
+-- locale of emails that are not sent in response to a request carrying the recipient's
+-- Accept-Language (e.g. capability request reminders). A registration request keeps the
+-- registrant's locale so the approval email is sent in their language.
+
+ALTER TABLE organizations
+ADD COLUMN IF NOT EXISTS logo_url TEXT NOT NULL DEFAULT '';
+
+ALTER TABLE organizations
+ADD COLUMN IF NOT EXISTS email_locale VARCHAR(35) NOT NULL DEFAULT '';
+
+ALTER TABLE user_registration_requests
+ADD COLUMN IF NOT EXISTS locale VARCHAR(255) NOT NULL DEFAULT '';
+
+COMMENT ON COLUMN organizations.logo_url IS 'HTTPS URL of the logo shown in emails, empty shows no logo';
+COMMENT ON COLUMN organizations.email_locale IS 'Locale tag of emails sent to the organization''s users, empty uses the default';
+COMMENT ON COLUMN user_registration_requests.locale IS 'Registrant''s locale tag or Accept-Language list, used for the approval email';
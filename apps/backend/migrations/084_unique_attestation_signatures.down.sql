@@ -0,0 +1,3 @@
+-- Revert 084: idx_mcp_attestations_signature (removed replays are not restored)
+
+DROP INDEX IF EXISTS idx_mcp_attestations_signature;
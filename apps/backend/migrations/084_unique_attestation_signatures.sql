@@ -0,0 +1,16 @@
+-- Migration: Make agent attestation signatures unique
+-- Attestations were checked for replays before being inserted, so two concurrent submissions
+-- of the same signed attestation could both be recorded. The unique index makes the insert
+-- itself reject the replay. Manual attestations (no agent) share a placeholder signature and
+-- are not covered. Replays recorded before this migration are removed, keeping the first.
+
+DELETE FROM mcp_attestations a
+USING mcp_attestations earlier
+WHERE a.agent_id IS NOT NULL
+  AND earlier.agent_id IS NOT NULL
+  AND a.signature = earlier.signature
+  AND (earlier.created_at, earlier.id) < (a.created_at, a.id);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_mcp_attestations_signature
+    ON mcp_attestations(signature)
+    WHERE agent_id IS NOT NULL;
@@ -0,0 +1,11 @@
+-- Revert 085: trust weights per category
+-- Category weights cannot be split back into factor weights; organizations return to the defaults.
+
+UPDATE organizations SET trust_weights = NULL WHERE trust_weights ? 'verification';
+
+COMMENT ON COLUMN organizations.trust_weights IS 'Trust score factor weights adding up to 1.0 (NULL = default weights)';
+
+ALTER TABLE trust_scores
+DROP COLUMN IF EXISTS mcp_servers,
+DROP COLUMN IF EXISTS key_age,
+DROP COLUMN IF EXISTS capability_violations;
@@ -0,0 +1,29 @@
+-- Migration: Trust weights per category
+-- Organizations now weight five categories (verification, violations, key_age, mcp, alerts)
+-- instead of the eight factors. Three factors back the new categories; rows calculated before
+-- them are neutral (1.0), which is also what the default weights (0 for these categories) imply.
+
+ALTER TABLE trust_scores
+ADD COLUMN IF NOT EXISTS capability_violations DECIMAL(5,4) DEFAULT 1.0,
+ADD COLUMN IF NOT EXISTS key_age DECIMAL(5,4) DEFAULT 1.0,
+ADD COLUMN IF NOT EXISTS mcp_servers DECIMAL(5,4) DEFAULT 1.0;
+
+-- Carry configured factor weights over: the seven verification and history factors become the
+-- verification category and security alerts the alerts category.
+UPDATE organizations
+SET trust_weights = jsonb_build_object(
+    'verification', COALESCE((trust_weights->>'verificationStatus')::numeric, 0)
+        + COALESCE((trust_weights->>'uptime')::numeric, 0)
+        + COALESCE((trust_weights->>'successRate')::numeric, 0)
+        + COALESCE((trust_weights->>'compliance')::numeric, 0)
+        + COALESCE((trust_weights->>'age')::numeric, 0)
+        + COALESCE((trust_weights->>'driftDetection')::numeric, 0)
+        + COALESCE((trust_weights->>'userFeedback')::numeric, 0),
+    'violations', 0,
+    'key_age', 0,
+    'mcp', 0,
+    'alerts', COALESCE((trust_weights->>'securityAlerts')::numeric, 0)
+)
+WHERE trust_weights IS NOT NULL AND NOT trust_weights ? 'verification';
+
+COMMENT ON COLUMN organizations.trust_weights IS 'Trust score category weights (verification, violations, key_age, mcp, alerts) adding up to 1.0 (NULL = default weights)';
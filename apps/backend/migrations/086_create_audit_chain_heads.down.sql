@@ -0,0 +1,3 @@
+-- Revert 086: audit log chain heads
+
+DROP TABLE IF EXISTS audit_chain_heads;
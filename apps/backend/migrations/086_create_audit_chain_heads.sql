@@ -0,0 +1,21 @@
+-- Migration: Audit log chain heads
+-- Deleting the newest audit log entries leaves every remaining link of the hash chain intact.
+-- Each organization's chain head (last chain_sequence and entry_hash) is written with every
+-- entry, so chain verification can tell that entries are missing from the end.
+
+CREATE TABLE IF NOT EXISTS audit_chain_heads (
+    organization_id UUID PRIMARY KEY REFERENCES organizations(id) ON DELETE CASCADE,
+    chain_sequence BIGINT NOT NULL,
+    entry_hash VARCHAR(64) NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- Anchor the existing chains at their current last entry
+INSERT INTO audit_chain_heads (organization_id, chain_sequence, entry_hash)
+SELECT DISTINCT ON (organization_id) organization_id, chain_sequence, entry_hash
+FROM audit_logs
+WHERE chain_sequence IS NOT NULL
+ORDER BY organization_id, chain_sequence DESC
+ON CONFLICT (organization_id) DO NOTHING;
+
+COMMENT ON TABLE audit_chain_heads IS 'Last entry of each organization''s audit log hash chain';
@@ -0,0 +1,5 @@
+-- Revert 087: organizations still at the default threshold go back to 0.3
+
+UPDATE organizations SET auto_verify_min_trust = 0.300 WHERE auto_verify_min_trust = 0;
+
+ALTER TABLE organizations ALTER COLUMN auto_verify_min_trust SET DEFAULT 0.300;
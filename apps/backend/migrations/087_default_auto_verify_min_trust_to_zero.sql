@@ -0,0 +1,8 @@
+-- Migration: Auto-verify new agents by default
+-- Before 043 every new agent was verified on creation. Its 0.3 default threshold left agents with a
+-- low initial trust score pending in organizations that never chose a threshold; 0 restores the
+-- earlier behavior. Thresholds are set through PUT /api/v1/admin/organization/auto-verify.
+
+ALTER TABLE organizations ALTER COLUMN auto_verify_min_trust SET DEFAULT 0;
+
+UPDATE organizations SET auto_verify_min_trust = 0 WHERE auto_verify_min_trust = 0.300;
@@ -25,6 +25,14 @@ func main() {
 		"role":            "admin",
 		"exp":             time.Now().Add(24 * time.Hour).Unix(),
 		"iat":             time.Now().Unix(),
+		"iss":             "agent-identity-management",
+	}
+	// The server only accepts tokens from its configured issuer and audience
+	if issuer := os.Getenv("JWT_ISSUER"); issuer != "" {
+		claims["iss"] = issuer
+	}
+	if audience := os.Getenv("JWT_AUDIENCE"); audience != "" {
+		claims["aud"] = audience
 	}
 
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
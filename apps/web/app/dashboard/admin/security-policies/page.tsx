@@ -21,6 +21,7 @@ import {
   Eye,
   AlertOctagon,
   Info,
+  FlaskConical,
 } from "lucide-react";
 import { api } from "@/lib/api";
 import {
@@ -47,7 +48,7 @@ interface SecurityPolicy {
   name: string;
   description: string;
   policyType: string;
-  enforcementAction: "alert_only" | "block_and_alert" | "allow";
+  enforcementAction: "alert_only" | "block_and_alert" | "allow" | "simulate";
   severityThreshold: string;
   rules: Record<string, any>;
   appliesTo: string;
@@ -62,18 +63,21 @@ const enforcementColors = {
   alert_only: "bg-yellow-100 text-yellow-800 border-yellow-300",
   block_and_alert: "bg-red-100 text-red-800 border-red-300",
   allow: "bg-green-100 text-green-800 border-green-300",
+  simulate: "bg-blue-100 text-blue-800 border-blue-300",
 };
 
 const enforcementIcons = {
   alert_only: Eye,
   block_and_alert: Lock,
   allow: Check,
+  simulate: FlaskConical,
 };
 
 const enforcementLabels = {
   alert_only: "Alert Only",
   block_and_alert: "Block & Alert",
   allow: "Allow",
+  simulate: "Simulate",
 };
 
 const policyTypeLabels: Record<string, string> = {
@@ -83,6 +87,7 @@ const policyTypeLabels: Record<string, string> = {
   unusual_activity: "Unusual Activity",
   unauthorized_access: "Unauthorized Access",
   config_drift: "Configuration Drift",
+  mcp_allowlist: "MCP Allowlist",
   auth_failure: "Authentication Failure",
 };
 
@@ -126,7 +131,7 @@ export default function SecurityPoliciesPage() {
   const [pendingEnforcementChange, setPendingEnforcementChange] = useState<{
     policyId: string;
     policyName: string;
-    newAction: "alert_only" | "block_and_alert" | "allow";
+    newAction: "alert_only" | "block_and_alert" | "allow" | "simulate";
   } | null>(null);
 
   useEffect(() => {
@@ -193,7 +198,7 @@ export default function SecurityPoliciesPage() {
 
   const changeEnforcementAction = async (
     policyId: string,
-    newAction: "alert_only" | "block_and_alert" | "allow"
+    newAction: "alert_only" | "block_and_alert" | "allow" | "simulate"
   ) => {
     try {
       const policy = policies.find((p) => p.id === policyId);
@@ -226,7 +231,7 @@ export default function SecurityPoliciesPage() {
 
   const handleEnforcementChange = (
     policy: SecurityPolicy,
-    newAction: "alert_only" | "block_and_alert" | "allow"
+    newAction: "alert_only" | "block_and_alert" | "allow" | "simulate"
   ) => {
     // If changing to blocking mode, show warning
     if (newAction === "block_and_alert") {
@@ -539,7 +544,7 @@ export default function SecurityPoliciesPage() {
                         <Select
                           value={policy.enforcementAction}
                           onValueChange={(
-                            value: "alert_only" | "block_and_alert" | "allow"
+                            value: "alert_only" | "block_and_alert" | "allow" | "simulate"
                           ) => handleEnforcementChange(policy, value)}
                         >
                           <SelectTrigger className="w-full">
@@ -564,6 +569,12 @@ export default function SecurityPoliciesPage() {
                                 <span>Allow</span>
                               </div>
                             </SelectItem>
+                            <SelectItem value="simulate">
+                              <div className="flex items-center gap-2">
+                                <FlaskConical className="h-4 w-4 text-blue-600" />
+                                <span>Simulate</span>
+                              </div>
+                            </SelectItem>
                           </SelectContent>
                         </Select>
                       </div>
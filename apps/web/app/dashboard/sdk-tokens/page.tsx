@@ -273,11 +273,10 @@ export default function SDKTokensPage() {
                         <p className="text-sm font-medium">User Agent</p>
                         <p
                           className="text-sm text-muted-foreground truncate max-w-[200px]"
-                          title={token.userAgent}
+                          title={token.lastUserAgent || token.userAgent}
                         >
-                          {token.userAgent
-                            ? token.userAgent.split(" ")[0]
-                            : "Unknown"}
+                          {(token.lastUserAgent || token.userAgent)?.split(" ")[0] ||
+                            "Unknown"}
                         </p>
                       </div>
                     </div>
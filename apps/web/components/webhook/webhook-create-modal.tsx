@@ -31,8 +31,11 @@ const AVAILABLE_EVENTS = [
   { id: 'agent.verified', label: 'Agent Verified', description: 'Triggered when an agent is verified' },
   { id: 'agent.suspended', label: 'Agent Suspended', description: 'Triggered when an agent is suspended' },
   { id: 'agent.reactivated', label: 'Agent Reactivated', description: 'Triggered when an agent is reactivated' },
+  { id: 'agent.compromised', label: 'Agent Compromised', description: 'Triggered when an agent is flagged as compromised' },
+  { id: 'agent.uncompromised', label: 'Agent Uncompromised', description: 'Triggered when an agent\'s compromise flag is cleared' },
   { id: 'trust_score.changed', label: 'Trust Score Changed', description: 'Triggered when trust score changes significantly' },
   { id: 'trust_score.critical', label: 'Trust Score Critical', description: 'Triggered when trust score drops below threshold' },
+  { id: 'trust_score_drop', label: 'Trust Score Drop', description: 'Triggered when an agent\'s trust score drops sharply' },
   { id: 'alert.created', label: 'Alert Created', description: 'Triggered when a new security alert is generated' },
   { id: 'alert.acknowledged', label: 'Alert Acknowledged', description: 'Triggered when an alert is acknowledged' },
   { id: 'alert.resolved', label: 'Alert Resolved', description: 'Triggered when an alert is resolved' },
@@ -41,6 +44,7 @@ const AVAILABLE_EVENTS = [
   { id: 'api_key.expired', label: 'API Key Expired', description: 'Triggered when an API key expires' },
   { id: 'verification.failed', label: 'Verification Failed', description: 'Triggered when agent verification fails' },
   { id: 'compliance.violation', label: 'Compliance Violation', description: 'Triggered when a compliance rule is violated' },
+  { id: 'security_breach', label: 'Security Breach', description: 'Triggered when a security breach is detected' },
 ];
 
 export function WebhookCreateModal({ isOpen, onClose, onSuccess }: WebhookCreateModalProps) {
@@ -204,17 +204,33 @@ export const apiDocumentation: EndpointCategory[] = [
         method: "POST",
         path: "/api/v1/auth/sdk/recover",
         description:
-          "Recover revoked SDK tokens. Allows zero-downtime token rotation by recovering previously revoked tokens.",
+          "Recover a revoked SDK token without re-downloading the SDK. The request must be signed with the Ed25519 private key of one of the token owner's agents. Repeated failures lock recovery of the token and raise a security alert.",
         summary: "Recover revoked SDK token",
-        auth: "Bearer Token (JWT)",
-        requiresAuth: true,
+        auth: "Ed25519 proof-of-possession (agent signature)",
+        requiresAuth: false,
         tags: ["auth", "sdk", "recovery"],
         requestSchema: {
           type: "object",
           properties: {
-            token: {
+            old_refresh_token: {
+              type: "string",
+              description: "Revoked SDK refresh token to recover",
+              required: true,
+            },
+            agent_id: {
+              type: "string",
+              description: "ID of an agent registered by the token's owner",
+              required: true,
+            },
+            timestamp: {
               type: "string",
-              description: "Revoked token to recover",
+              description: "RFC 3339 signing time (must be within 5 minutes of server time)",
+              required: true,
+            },
+            signature: {
+              type: "string",
+              description:
+                'Base64 Ed25519 signature of "aim-sdk-token-recovery\\n{jti}\\n{agent_id}\\n{timestamp}"',
               required: true,
             },
           },
@@ -222,12 +238,17 @@ export const apiDocumentation: EndpointCategory[] = [
         responseSchema: {
           type: "object",
           properties: {
-            success: { type: "boolean", description: "Recovery success status" },
-            token: { type: "string", description: "Recovered token" },
+            access_token: { type: "string", description: "New access token" },
+            refresh_token: { type: "string", description: "New SDK refresh token" },
+            token_type: { type: "string", description: "Bearer" },
+            expires_in: { type: "number", description: "Access token lifetime in seconds" },
           },
         },
         example: `{
-  "token": "aim_sdk_xxxxxxxxxxxxx"
+  "old_refresh_token": "eyJhbGciOi...",
+  "agent_id": "550e8400-e29b-41d4-a716-446655440000",
+  "timestamp": "2025-03-04T12:00:00Z",
+  "signature": "base64-ed25519-signature"
 }`,
       },
       {
@@ -244,6 +265,8 @@ export const apiDocumentation: EndpointCategory[] = [
           properties: {
             id: { type: "string", description: "Organization ID" },
             name: { type: "string", description: "Organization name" },
+            max_agents: { type: "number", description: "Agent limit; creating more returns 403 (0 = unlimited)" },
+            max_users: { type: "number", description: "User limit; approving more returns 403 (0 = unlimited)" },
             settings: { type: "object", description: "Organization settings" },
           },
         },
@@ -667,6 +690,41 @@ export const apiDocumentation: EndpointCategory[] = [
         tags: ["agents", "lifecycle"],
         example: "{}",
       },
+      {
+        method: "POST",
+        path: "/api/v1/agents/:id/compromise",
+        description:
+          "Flag agent as compromised and suspend it. All verify-action calls are denied until the flag is cleared. Records who flagged it, raises a critical alert and emits an agent.compromised webhook.",
+        summary: "Mark agent compromised",
+        auth: "Bearer Token (JWT)",
+        requiresAuth: true,
+        roleRequired: "manager",
+        tags: ["agents", "security"],
+        requestSchema: {
+          type: "object",
+          properties: {
+            reason: {
+              type: "string",
+              description: "Why the agent is considered compromised",
+            },
+          },
+        },
+        example: `{
+  "reason": "Private key found in public repository"
+}`,
+      },
+      {
+        method: "POST",
+        path: "/api/v1/agents/:id/uncompromise",
+        description:
+          "Clear the compromise flag and emit an agent.uncompromised webhook. The agent stays suspended until reactivated.",
+        summary: "Clear compromise flag",
+        auth: "Bearer Token (JWT)",
+        requiresAuth: true,
+        roleRequired: "manager",
+        tags: ["agents", "security"],
+        example: "{}",
+      },
       {
         method: "POST",
         path: "/api/v1/agents/:id/rotate-credentials",
@@ -749,9 +807,9 @@ export const apiDocumentation: EndpointCategory[] = [
         method: "GET",
         path: "/api/v1/agents/:id/sdk",
         description:
-          "Download pre-configured SDK with embedded credentials. Python/Node.js/Go.",
+          "Download pre-configured SDK with embedded credentials. Python/Node.js/Go. Agents signing with their own Ed25519 credentials can only download their own SDK.",
         summary: "Download agent SDK",
-        auth: "Bearer Token (JWT)",
+        auth: "Ed25519 (Agent Signature) or Bearer Token (JWT)",
         requiresAuth: true,
         tags: ["agents", "sdk"],
         example: "No request body required",
@@ -159,6 +159,7 @@ export interface SDKToken {
   userAgent?: string;
   lastUsedAt?: string;
   lastIpAddress?: string;
+  lastUserAgent?: string;
   usageCount: number;
   createdAt: string;
   expiresAt: string;
@@ -654,6 +655,24 @@ class APIClient {
     return this.request(`/api/v1/agents/${id}/reactivate`, { method: "POST" });
   }
 
+  async compromiseAgent(
+    id: string,
+    reason?: string
+  ): Promise<{ success: boolean; message: string }> {
+    return this.request(`/api/v1/agents/${id}/compromise`, {
+      method: "POST",
+      body: JSON.stringify({ reason }),
+    });
+  }
+
+  async uncompromiseAgent(
+    id: string
+  ): Promise<{ success: boolean; message: string }> {
+    return this.request(`/api/v1/agents/${id}/uncompromise`, {
+      method: "POST",
+    });
+  }
+
   async rotateAgentCredentials(
     id: string
   ): Promise<{ apiKey: string; message: string }> {
@@ -1677,6 +1696,33 @@ class APIClient {
     );
   }
 
+  async getAgentViolationStats(
+    agentId: string,
+    days: number = 30
+  ): Promise<{
+    agent_id: string;
+    since: string;
+    until: string;
+    total_violations: number;
+    blocked: number;
+    by_severity: Record<string, number>;
+    timeline: Array<{
+      date: string;
+      total: number;
+      by_severity: Record<string, number>;
+    }>;
+    top_attempted: Array<{
+      capability: string;
+      count: number;
+      blocked: number;
+      last_attempt_at: string;
+    }>;
+  }> {
+    return this.request(
+      `/api/v1/agents/${agentId}/violations/stats?days=${days}`
+    );
+  }
+
   async getAgentKeyVault(agentId: string): Promise<any> {
     return this.request(`/api/v1/agents/${agentId}/key-vault`);
   }
@@ -7,6 +7,7 @@ import functools
 import hashlib
 import json
 import time
+import uuid
 from typing import Any, Callable, Optional, Dict, List
 from datetime import datetime, timezone
 
@@ -367,9 +368,12 @@ class AIMClient:
 
             # Prepare headers - NO AUTH TOKENS for verification endpoint!
             # Verification uses cryptographic signature authentication (Ed25519)
+            # Idempotency-Key lets transport-level retries reuse the original
+            # verification instead of creating a duplicate event
             headers = {
                 'Content-Type': 'application/json',
-                'User-Agent': f'AIM-Python-SDK/1.0.0'
+                'User-Agent': f'AIM-Python-SDK/1.0.0',
+                'Idempotency-Key': str(uuid.uuid4())
             }
 
             # Add SDK token header if available (for usage tracking only, not auth)
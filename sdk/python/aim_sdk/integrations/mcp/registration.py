@@ -380,6 +380,7 @@ def attest_mcp_server(
     # Build attestation payload
     attestation_data = {
         "agent_id": str(aim_client.agent_id),
+        "mcp_id": server_id,
         "mcp_url": mcp_url,
         "mcp_name": mcp_name,
         "capabilities_found": capabilities_found,
@@ -198,6 +198,50 @@ class OAuthTokenManager:
         # Need to refresh token
         return self._refresh_token()
 
+    def _sign_recovery(self, refresh_token: str) -> Optional[Dict[str, str]]:
+        """
+        Sign a recovery request for a revoked refresh token with the agent's Ed25519 key.
+
+        The server only recovers tokens for callers that prove they hold the private key of
+        one of the user's agents. The signed message joins "aim-sdk-token-recovery", the
+        token's jti, the agent ID and an ISO 8601 timestamp with newlines.
+
+        Args:
+            refresh_token: The revoked refresh token
+
+        Returns:
+            agent_id, timestamp and signature fields, or None if no agent key is available
+        """
+        agent_id = self.credentials.get('agent_id') if self.credentials else None
+        private_key = self.credentials.get('private_key') if self.credentials else None
+        if not agent_id or not private_key:
+            return None
+
+        try:
+            import base64
+            from datetime import datetime, timezone
+            from nacl.signing import SigningKey
+
+            payload_part = refresh_token.split('.')[1]
+            payload_part += '=' * (-len(payload_part) % 4)
+            token_id = json.loads(base64.urlsafe_b64decode(payload_part)).get('jti')
+            if not token_id:
+                return None
+
+            # Keys are stored as the 32-byte seed or the 64-byte seed + public key
+            signing_key = SigningKey(base64.b64decode(private_key)[:32])
+            timestamp = datetime.now(timezone.utc).isoformat()
+            message = "\n".join(["aim-sdk-token-recovery", token_id, agent_id, timestamp])
+            signature = signing_key.sign(message.encode('utf-8')).signature
+
+            return {
+                "agent_id": agent_id,
+                "timestamp": timestamp,
+                "signature": base64.b64encode(signature).decode('utf-8'),
+            }
+        except Exception:
+            return None
+
     def _refresh_token(self) -> Optional[str]:
         """
         Refresh access token using refresh token.
@@ -235,15 +279,19 @@ class OAuthTokenManager:
                     print("🔄 Token was revoked - attempting automatic recovery...")
 
                     # Try token recovery endpoint (new feature - zero downtime!)
+                    # The server requires the request to be signed by one of our agents
                     recovery_url = f"{aim_url.rstrip('/')}/api/v1/auth/sdk/recover"
                     try:
-                        recovery_response = requests.post(
-                            recovery_url,
-                            json={"old_refresh_token": refresh_token},
-                            timeout=10
-                        )
-
-                        if recovery_response.status_code == 200:
+                        recovery_proof = self._sign_recovery(refresh_token)
+                        recovery_response = None
+                        if recovery_proof:
+                            recovery_response = requests.post(
+                                recovery_url,
+                                json={"old_refresh_token": refresh_token, **recovery_proof},
+                                timeout=10
+                            )
+
+                        if recovery_response is not None and recovery_response.status_code == 200:
                             recovery_data = recovery_response.json()
                             self.access_token = recovery_data.get('access_token')
                             new_refresh_token = recovery_data.get('refresh_token')